@@ -238,6 +238,42 @@ int32_t getPoint(void* paramQueue, int32_t index, int32_t* sampleOffset, double*
     return result;
 }
 
+void* addParameterData(void* outputParameterChanges, uint32_t paramId) {
+    if (!outputParameterChanges) {
+        DBG_LOG("addParameterData: outputParameterChanges is NULL");
+        return NULL;
+    }
+    
+    struct Steinberg_Vst_IParameterChanges* changes = (struct Steinberg_Vst_IParameterChanges*)outputParameterChanges;
+    if (!changes->lpVtbl || !changes->lpVtbl->addParameterData) {
+        DBG_LOG("addParameterData: vtable or method is NULL");
+        return NULL;
+    }
+    
+    Steinberg_int32 index = 0;
+    struct Steinberg_Vst_IParamValueQueue* queue = changes->lpVtbl->addParameterData(changes, &paramId, &index);
+    DBG_LOG("addParameterData: paramId=%u, index=%d, returning queue=%p", paramId, index, queue);
+    return queue;
+}
+
+int32_t addPoint(void* paramQueue, int32_t sampleOffset, double value) {
+    if (!paramQueue) {
+        DBG_LOG("addPoint: paramQueue is NULL");
+        return 1; // kResultFalse
+    }
+    
+    struct Steinberg_Vst_IParamValueQueue* queue = (struct Steinberg_Vst_IParamValueQueue*)paramQueue;
+    if (!queue->lpVtbl || !queue->lpVtbl->addPoint) {
+        DBG_LOG("addPoint: vtable or method is NULL");
+        return 1; // kResultFalse
+    }
+    
+    Steinberg_int32 index = 0;
+    Steinberg_tresult result = queue->lpVtbl->addPoint(queue, sampleOffset, value, &index);
+    DBG_LOG("addPoint: sampleOffset=%d, value=%.6f, result=%d", sampleOffset, value, result);
+    return result;
+}
+
 // Event processing helper functions
 int32_t getEventCount(void* eventList) {
     if (!eventList) {
@@ -289,3 +325,346 @@ struct Steinberg_Vst_NoteOnEvent* getNoteOnEvent(struct Steinberg_Vst_Event* eve
 struct Steinberg_Vst_NoteOffEvent* getNoteOffEvent(struct Steinberg_Vst_Event* event) {
     return &event->Steinberg_Vst_Event_noteOff;
 }
+
+// Output event helpers
+static int32_t addEvent(void* eventList, struct Steinberg_Vst_Event* event) {
+    if (!eventList) {
+        DBG_LOG("addEvent: eventList is NULL");
+        return 1; // kResultFalse
+    }
+    
+    struct Steinberg_Vst_IEventList* list = (struct Steinberg_Vst_IEventList*)eventList;
+    if (!list->lpVtbl || !list->lpVtbl->addEvent) {
+        DBG_LOG("addEvent: vtable or method is NULL");
+        return 1; // kResultFalse
+    }
+    
+    Steinberg_tresult result = list->lpVtbl->addEvent(list, event);
+    DBG_LOG("addEvent: type=%d, sampleOffset=%d, result=%d", event->type, event->sampleOffset, result);
+    return result;
+}
+
+int32_t addNoteOnEvent(void* eventList, int32_t busIndex, int32_t sampleOffset, int16_t channel, int16_t pitch, float velocity, int32_t noteId) {
+    struct Steinberg_Vst_Event event;
+    memset(&event, 0, sizeof(event));
+    event.busIndex = busIndex;
+    event.sampleOffset = sampleOffset;
+    event.type = Steinberg_Vst_Event_EventTypes_kNoteOnEvent;
+    event.Steinberg_Vst_Event_noteOn.channel = channel;
+    event.Steinberg_Vst_Event_noteOn.pitch = pitch;
+    event.Steinberg_Vst_Event_noteOn.velocity = velocity;
+    event.Steinberg_Vst_Event_noteOn.noteId = noteId;
+    return addEvent(eventList, &event);
+}
+
+int32_t addNoteOffEvent(void* eventList, int32_t busIndex, int32_t sampleOffset, int16_t channel, int16_t pitch, float velocity, int32_t noteId) {
+    struct Steinberg_Vst_Event event;
+    memset(&event, 0, sizeof(event));
+    event.busIndex = busIndex;
+    event.sampleOffset = sampleOffset;
+    event.type = Steinberg_Vst_Event_EventTypes_kNoteOffEvent;
+    event.Steinberg_Vst_Event_noteOff.channel = channel;
+    event.Steinberg_Vst_Event_noteOff.pitch = pitch;
+    event.Steinberg_Vst_Event_noteOff.velocity = velocity;
+    event.Steinberg_Vst_Event_noteOff.noteId = noteId;
+    return addEvent(eventList, &event);
+}
+
+int32_t addPolyPressureEvent(void* eventList, int32_t busIndex, int32_t sampleOffset, int16_t channel, int16_t pitch, float pressure, int32_t noteId) {
+    struct Steinberg_Vst_Event event;
+    memset(&event, 0, sizeof(event));
+    event.busIndex = busIndex;
+    event.sampleOffset = sampleOffset;
+    event.type = Steinberg_Vst_Event_EventTypes_kPolyPressureEvent;
+    event.Steinberg_Vst_Event_polyPressure.channel = channel;
+    event.Steinberg_Vst_Event_polyPressure.pitch = pitch;
+    event.Steinberg_Vst_Event_polyPressure.pressure = pressure;
+    event.Steinberg_Vst_Event_polyPressure.noteId = noteId;
+    return addEvent(eventList, &event);
+}
+
+int32_t addMidiCCOutEvent(void* eventList, int32_t busIndex, int32_t sampleOffset, uint8_t controlNumber, int8_t channel, int8_t value, int8_t value2) {
+    struct Steinberg_Vst_Event event;
+    memset(&event, 0, sizeof(event));
+    event.busIndex = busIndex;
+    event.sampleOffset = sampleOffset;
+    event.type = Steinberg_Vst_Event_EventTypes_kLegacyMIDICCOutEvent;
+    event.Steinberg_Vst_Event_midiCCOut.controlNumber = controlNumber;
+    event.Steinberg_Vst_Event_midiCCOut.channel = channel;
+    event.Steinberg_Vst_Event_midiCCOut.value = value;
+    event.Steinberg_Vst_Event_midiCCOut.value2 = value2;
+    return addEvent(eventList, &event);
+}
+
+// Message helpers
+const char* getMessageID(void* message) {
+    if (!message) {
+        DBG_LOG("getMessageID: message is NULL");
+        return NULL;
+    }
+    
+    struct Steinberg_Vst_IMessage* msg = (struct Steinberg_Vst_IMessage*)message;
+    if (!msg->lpVtbl || !msg->lpVtbl->getMessageID) {
+        DBG_LOG("getMessageID: vtable or method is NULL");
+        return NULL;
+    }
+    
+    return msg->lpVtbl->getMessageID(msg);
+}
+
+int32_t getMessageBinary(void* message, const char* attribute, const void** data, uint32_t* size) {
+    if (!message || !data || !size) {
+        DBG_LOG("getMessageBinary: message, data or size is NULL");
+        return 1; // kResultFalse
+    }
+    
+    struct Steinberg_Vst_IMessage* msg = (struct Steinberg_Vst_IMessage*)message;
+    if (!msg->lpVtbl || !msg->lpVtbl->getAttributes) {
+        DBG_LOG("getMessageBinary: vtable or method is NULL");
+        return 1; // kResultFalse
+    }
+    
+    struct Steinberg_Vst_IAttributeList* attributes = msg->lpVtbl->getAttributes(msg);
+    if (!attributes || !attributes->lpVtbl || !attributes->lpVtbl->getBinary) {
+        DBG_LOG("getMessageBinary: message has no attribute list");
+        return 1; // kResultFalse
+    }
+    
+    Steinberg_tresult result = attributes->lpVtbl->getBinary(attributes, attribute, data, size);
+    DBG_LOG("getMessageBinary: attribute=%s, size=%u, result=%d", attribute, *size, result);
+    return result;
+}
+
+void* createMessage(void* hostContext) {
+    if (!hostContext) {
+        DBG_LOG("createMessage: hostContext is NULL");
+        return NULL;
+    }
+    
+    struct Steinberg_FUnknown* context = (struct Steinberg_FUnknown*)hostContext;
+    if (!context->lpVtbl || !context->lpVtbl->queryInterface) {
+        DBG_LOG("createMessage: vtable or method is NULL");
+        return NULL;
+    }
+    
+    struct Steinberg_Vst_IHostApplication* host = NULL;
+    if (context->lpVtbl->queryInterface(context, Steinberg_Vst_IHostApplication_iid, (void**)&host) != 0 || !host) {
+        DBG_LOG("createMessage: context is not an IHostApplication");
+        return NULL;
+    }
+    
+    void* message = NULL;
+    if (host->lpVtbl->createInstance) {
+        host->lpVtbl->createInstance(host, (char*)Steinberg_Vst_IMessage_iid, (char*)Steinberg_Vst_IMessage_iid, &message);
+    }
+    host->lpVtbl->release(host);
+    DBG_LOG("createMessage: returning message=%p", message);
+    return message;
+}
+
+int32_t sendMessage(void* connection, void* message, const char* id, const char* attribute, const void* data, uint32_t size) {
+    if (!connection || !message) {
+        DBG_LOG("sendMessage: connection or message is NULL");
+        return 1; // kResultFalse
+    }
+    
+    struct Steinberg_Vst_IConnectionPoint* peer = (struct Steinberg_Vst_IConnectionPoint*)connection;
+    struct Steinberg_Vst_IMessage* msg = (struct Steinberg_Vst_IMessage*)message;
+    if (!peer->lpVtbl || !peer->lpVtbl->notify || !msg->lpVtbl || !msg->lpVtbl->setMessageID || !msg->lpVtbl->getAttributes) {
+        DBG_LOG("sendMessage: vtable or method is NULL");
+        return 1; // kResultFalse
+    }
+    
+    msg->lpVtbl->setMessageID(msg, id);
+    struct Steinberg_Vst_IAttributeList* attributes = msg->lpVtbl->getAttributes(msg);
+    if (attributes && attributes->lpVtbl && attributes->lpVtbl->setBinary) {
+        attributes->lpVtbl->setBinary(attributes, attribute, data, size);
+    }
+    
+    Steinberg_tresult result = peer->lpVtbl->notify(peer, msg);
+    DBG_LOG("sendMessage: id=%s, size=%u, result=%d", id, size, result);
+    return result;
+}
+
+uint32_t addRefObject(void* object) {
+    struct Steinberg_FUnknown* unknown = (struct Steinberg_FUnknown*)object;
+    if (!unknown || !unknown->lpVtbl || !unknown->lpVtbl->addRef) {
+        return 0;
+    }
+    return unknown->lpVtbl->addRef(unknown);
+}
+
+uint32_t releaseObject(void* object) {
+    struct Steinberg_FUnknown* unknown = (struct Steinberg_FUnknown*)object;
+    if (!unknown || !unknown->lpVtbl || !unknown->lpVtbl->release) {
+        return 0;
+    }
+    return unknown->lpVtbl->release(unknown);
+}
+
+// Context menu helpers
+void* createContextMenu(void* componentHandler, void* plugView, uint32_t paramId) {
+    if (!componentHandler) {
+        DBG_LOG("createContextMenu: componentHandler is NULL");
+        return NULL;
+    }
+    
+    struct Steinberg_Vst_IComponentHandler* handler = (struct Steinberg_Vst_IComponentHandler*)componentHandler;
+    if (!handler->lpVtbl || !handler->lpVtbl->queryInterface) {
+        DBG_LOG("createContextMenu: vtable or method is NULL");
+        return NULL;
+    }
+    
+    struct Steinberg_Vst_IComponentHandler3* handler3 = NULL;
+    if (handler->lpVtbl->queryInterface(handler, Steinberg_Vst_IComponentHandler3_iid, (void**)&handler3) != 0 || !handler3) {
+        DBG_LOG("createContextMenu: host has no IComponentHandler3");
+        return NULL;
+    }
+    
+    struct Steinberg_Vst_IContextMenu* menu = NULL;
+    if (handler3->lpVtbl->createContextMenu) {
+        Steinberg_Vst_ParamID id = paramId;
+        menu = handler3->lpVtbl->createContextMenu(handler3, (struct Steinberg_IPlugView*)plugView, &id);
+    }
+    handler3->lpVtbl->release(handler3);
+    DBG_LOG("createContextMenu: paramId=%u, returning menu=%p", paramId, menu);
+    return menu;
+}
+
+int32_t addContextMenuItem(void* contextMenu, const uint16_t* name, int32_t tag, int32_t flags, void* target) {
+    if (!contextMenu) {
+        DBG_LOG("addContextMenuItem: contextMenu is NULL");
+        return 1; // kResultFalse
+    }
+    
+    struct Steinberg_Vst_IContextMenu* menu = (struct Steinberg_Vst_IContextMenu*)contextMenu;
+    if (!menu->lpVtbl || !menu->lpVtbl->addItem) {
+        DBG_LOG("addContextMenuItem: vtable or method is NULL");
+        return 1; // kResultFalse
+    }
+    
+    struct Steinberg_Vst_IContextMenuItem item;
+    memset(&item, 0, sizeof(item));
+    for (int i = 0; name && name[i] && i < 127; i++) {
+        item.name[i] = (Steinberg_char16)name[i];
+    }
+    item.tag = tag;
+    item.flags = flags;
+    
+    Steinberg_tresult result = menu->lpVtbl->addItem(menu, &item, (struct Steinberg_Vst_IContextMenuTarget*)target);
+    DBG_LOG("addContextMenuItem: tag=%d, flags=%d, result=%d", tag, flags, result);
+    return result;
+}
+
+int32_t popupContextMenu(void* contextMenu, int32_t x, int32_t y) {
+    if (!contextMenu) {
+        DBG_LOG("popupContextMenu: contextMenu is NULL");
+        return 1; // kResultFalse
+    }
+    
+    struct Steinberg_Vst_IContextMenu* menu = (struct Steinberg_Vst_IContextMenu*)contextMenu;
+    if (!menu->lpVtbl || !menu->lpVtbl->popup) {
+        DBG_LOG("popupContextMenu: vtable or method is NULL");
+        return 1; // kResultFalse
+    }
+    
+    Steinberg_tresult result = menu->lpVtbl->popup(menu, x, y);
+    DBG_LOG("popupContextMenu: x=%d, y=%d, result=%d", x, y, result);
+    return result;
+}
+
+// Context menu target, calling back into Go when one of the plugin's menu
+// items is chosen
+typedef struct {
+    struct Steinberg_Vst_IContextMenuTargetVtbl* lpVtbl;
+    int refCount;
+    void* goTarget;
+} ContextMenuTarget;
+
+static Steinberg_uint32 SMTG_STDMETHODCALLTYPE menuTarget_addRef(void* thisInterface) {
+    ContextMenuTarget* target = (ContextMenuTarget*)thisInterface;
+    return ++target->refCount;
+}
+
+static Steinberg_uint32 SMTG_STDMETHODCALLTYPE menuTarget_release(void* thisInterface) {
+    ContextMenuTarget* target = (ContextMenuTarget*)thisInterface;
+    if (--target->refCount == 0) {
+        GoContextMenuRelease(target->goTarget);
+        free(target);
+        return 0;
+    }
+    return target->refCount;
+}
+
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE menuTarget_queryInterface(void* thisInterface, const Steinberg_TUID iid, void** obj) {
+    if (memcmp(iid, Steinberg_FUnknown_iid, sizeof(Steinberg_TUID)) == 0 ||
+        memcmp(iid, Steinberg_Vst_IContextMenuTarget_iid, sizeof(Steinberg_TUID)) == 0) {
+        *obj = thisInterface;
+        menuTarget_addRef(thisInterface);
+        return ((Steinberg_tresult)0);
+    }
+    *obj = NULL;
+    return ((Steinberg_tresult)-1);
+}
+
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE menuTarget_executeMenuItem(void* thisInterface, Steinberg_int32 tag) {
+    ContextMenuTarget* target = (ContextMenuTarget*)thisInterface;
+    DBG_LOG("menuTarget_executeMenuItem: tag=%d", tag);
+    return GoContextMenuExecute(target->goTarget, tag);
+}
+
+static struct Steinberg_Vst_IContextMenuTargetVtbl contextMenuTargetVtbl = {
+    menuTarget_queryInterface,
+    menuTarget_addRef,
+    menuTarget_release,
+    menuTarget_executeMenuItem
+};
+
+void* createContextMenuTarget(void* goTarget) {
+    ContextMenuTarget* target = (ContextMenuTarget*)malloc(sizeof(ContextMenuTarget));
+    if (!target) {
+        DBG_LOG("createContextMenuTarget: Failed to allocate memory");
+        return NULL;
+    }
+    target->lpVtbl = &contextMenuTargetVtbl;
+    target->refCount = 1;
+    target->goTarget = goTarget;
+    return target;
+}
+
+// Floating-point mode helpers
+#if defined(__SSE__) || defined(__x86_64__)
+#include <xmmintrin.h>
+#define VST3GO_FTZ_DAZ 0x8040 // MXCSR flush-to-zero (bit 15) and denormals-are-zero (bit 6)
+
+uint64_t enableFlushToZero(void) {
+    unsigned int csr = _mm_getcsr();
+    _mm_setcsr(csr | VST3GO_FTZ_DAZ);
+    return csr;
+}
+
+void restoreFloatMode(uint64_t mode) {
+    _mm_setcsr((unsigned int)mode);
+}
+#elif defined(__aarch64__)
+#define VST3GO_FPCR_FZ (1ULL << 24) // FPCR flush-to-zero, covering inputs and results
+
+uint64_t enableFlushToZero(void) {
+    uint64_t fpcr;
+    __asm__ __volatile__("mrs %0, fpcr" : "=r"(fpcr));
+    __asm__ __volatile__("msr fpcr, %0" : : "r"(fpcr | VST3GO_FPCR_FZ));
+    return fpcr;
+}
+
+void restoreFloatMode(uint64_t mode) {
+    __asm__ __volatile__("msr fpcr, %0" : : "r"(mode));
+}
+#else
+uint64_t enableFlushToZero(void) {
+    return 0;
+}
+
+void restoreFloatMode(uint64_t mode) {
+    (void)mode;
+}
+#endif
@@ -11,12 +11,18 @@ extern int32_t GoCountClasses();
 extern void GoGetClassInfo(int32_t index, char* cid, int32_t* cardinality, char* category, char* name);
 extern void* GoCreateInstance(char* cid, char* iid);
 
+// Context menu target callbacks
+extern Steinberg_tresult GoContextMenuExecute(void* target, int32_t tag);
+extern void GoContextMenuRelease(void* target);
+
 // Parameter automation helper functions
 int32_t getParameterChangeCount(void* inputParameterChanges);
 void* getParameterData(void* inputParameterChanges, int32_t index);
 uint32_t getParameterId(void* paramQueue);
 int32_t getPointCount(void* paramQueue);
 int32_t getPoint(void* paramQueue, int32_t index, int32_t* sampleOffset, double* value);
+void* addParameterData(void* outputParameterChanges, uint32_t paramId);
+int32_t addPoint(void* paramQueue, int32_t sampleOffset, double value);
 
 // Event processing helper functions
 int32_t getEventCount(void* eventList);
@@ -25,4 +31,40 @@ uint16_t getEventType(struct Steinberg_Vst_Event* event);
 struct Steinberg_Vst_NoteOnEvent* getNoteOnEvent(struct Steinberg_Vst_Event* event);
 struct Steinberg_Vst_NoteOffEvent* getNoteOffEvent(struct Steinberg_Vst_Event* event);
 
+// Output event helpers. Each builds an event and appends it to the host's
+// output event list, returning the host's result.
+int32_t addNoteOnEvent(void* eventList, int32_t busIndex, int32_t sampleOffset, int16_t channel, int16_t pitch, float velocity, int32_t noteId);
+int32_t addNoteOffEvent(void* eventList, int32_t busIndex, int32_t sampleOffset, int16_t channel, int16_t pitch, float velocity, int32_t noteId);
+int32_t addPolyPressureEvent(void* eventList, int32_t busIndex, int32_t sampleOffset, int16_t channel, int16_t pitch, float pressure, int32_t noteId);
+int32_t addMidiCCOutEvent(void* eventList, int32_t busIndex, int32_t sampleOffset, uint8_t controlNumber, int8_t channel, int8_t value, int8_t value2);
+
+// Message helpers for IConnectionPoint. createMessage asks the host
+// context for a new IMessage; sendMessage fills in its ID and a binary
+// attribute and passes it to the peer's notify. The caller releases the
+// message with releaseObject.
+const char* getMessageID(void* message);
+int32_t getMessageBinary(void* message, const char* attribute, const void** data, uint32_t* size);
+void* createMessage(void* hostContext);
+int32_t sendMessage(void* connection, void* message, const char* id, const char* attribute, const void* data, uint32_t size);
+
+// Context menu helpers for IComponentHandler3. createContextMenu returns
+// the host's menu for a parameter, or NULL if the host has none; items are
+// added with a target created by createContextMenuTarget, which calls
+// GoContextMenuExecute when one is chosen and GoContextMenuRelease when the
+// host lets go of it.
+void* createContextMenu(void* componentHandler, void* plugView, uint32_t paramId);
+int32_t addContextMenuItem(void* contextMenu, const uint16_t* name, int32_t tag, int32_t flags, void* target);
+int32_t popupContextMenu(void* contextMenu, int32_t x, int32_t y);
+void* createContextMenuTarget(void* goTarget);
+
+// Reference counting for host objects kept beyond a call
+uint32_t addRefObject(void* object);
+uint32_t releaseObject(void* object);
+
+// Floating-point mode helpers for the audio thread. enableFlushToZero turns
+// on flush-to-zero and denormals-are-zero and returns the previous mode for
+// restoreFloatMode.
+uint64_t enableFlushToZero(void);
+void restoreFloatMode(uint64_t mode);
+
 #endif // VST3GO_BRIDGE_H
\ No newline at end of file
@@ -1,4 +1,5 @@
 #include "component.h"
+#include "bridge.h"
 #include <string.h>
 #include <stdlib.h>
 #include <stdio.h>
@@ -25,6 +26,12 @@ typedef struct {
     Component* component;
 } EditControllerInterface;
 
+// Connection point interface wrapper
+typedef struct {
+    struct Steinberg_Vst_IConnectionPointVtbl* lpVtbl;
+    Component* component;
+} ConnectionPointInterface;
+
 // Component implementation that wraps Go component
 struct Component {
     // IComponent vtable pointer must be first for COM compatibility
@@ -33,6 +40,8 @@ struct Component {
     AudioProcessorInterface audioProcessor;
     // Edit controller interface
     EditControllerInterface editController;
+    // Connection point interface
+    ConnectionPointInterface connectionPoint;
     // Reference count
     int refCount;
     // Go component handle
@@ -94,6 +103,14 @@ static Steinberg_tresult SMTG_STDMETHODCALLTYPE controller_setParamNormalized(vo
 static Steinberg_tresult SMTG_STDMETHODCALLTYPE controller_setComponentHandler(void* thisInterface, struct Steinberg_Vst_IComponentHandler* handler);
 static struct Steinberg_IPlugView* SMTG_STDMETHODCALLTYPE controller_createView(void* thisInterface, Steinberg_FIDString name);
 
+// Forward declarations for IConnectionPoint methods
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE connection_queryInterface(void* thisInterface, const Steinberg_TUID iid, void** obj);
+static Steinberg_uint32 SMTG_STDMETHODCALLTYPE connection_addRef(void* thisInterface);
+static Steinberg_uint32 SMTG_STDMETHODCALLTYPE connection_release(void* thisInterface);
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE connection_connect(void* thisInterface, struct Steinberg_Vst_IConnectionPoint* other);
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE connection_disconnect(void* thisInterface, struct Steinberg_Vst_IConnectionPoint* other);
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE connection_notify(void* thisInterface, struct Steinberg_Vst_IMessage* message);
+
 // IComponent vtable
 static struct Steinberg_Vst_IComponentVtbl componentVtbl = {
     component_queryInterface,
@@ -149,6 +166,16 @@ static struct Steinberg_Vst_IEditControllerVtbl editControllerVtbl = {
     controller_createView
 };
 
+// IConnectionPoint vtable
+static struct Steinberg_Vst_IConnectionPointVtbl connectionPointVtbl = {
+    connection_queryInterface,
+    connection_addRef,
+    connection_release,
+    connection_connect,
+    connection_disconnect,
+    connection_notify
+};
+
 // Create a new component instance
 void* createComponent(void* goComponent) {
     DBG_LOG("createComponent: Creating component with Go handle %p", goComponent);
@@ -163,6 +190,8 @@ void* createComponent(void* goComponent) {
     component->audioProcessor.component = component;
     component->editController.lpVtbl = &editControllerVtbl;
     component->editController.component = component;
+    component->connectionPoint.lpVtbl = &connectionPointVtbl;
+    component->connectionPoint.component = component;
     component->refCount = 1;
     component->goComponent = goComponent;
     
@@ -198,6 +227,13 @@ static Steinberg_tresult SMTG_STDMETHODCALLTYPE component_queryInterface(void* t
         return ((Steinberg_tresult)0);
     }
     
+    if (memcmp(iid, Steinberg_Vst_IConnectionPoint_iid, sizeof(Steinberg_TUID)) == 0) {
+        DBG_LOG("component_queryInterface: Returning IConnectionPoint");
+        *obj = &component->connectionPoint; // Return connection point interface
+        component_addRef(thisInterface);
+        return ((Steinberg_tresult)0);
+    }
+    
     DBG_LOG("component_queryInterface: Interface not found");
     *obj = NULL;
     return ((Steinberg_tresult)-1);
@@ -329,7 +365,12 @@ static Steinberg_tresult SMTG_STDMETHODCALLTYPE audio_setProcessing(void* thisIn
 
 static Steinberg_tresult SMTG_STDMETHODCALLTYPE audio_process(void* thisInterface, struct Steinberg_Vst_ProcessData* data) {
     AudioProcessorInterface* audioProc = (AudioProcessorInterface*)thisInterface;
-    return GoAudioProcess(audioProc->component->goComponent, data);
+    // Decaying tails must not hit subnormal floats; the host's own mode is
+    // put back before returning
+    uint64_t mode = enableFlushToZero();
+    Steinberg_tresult result = GoAudioProcess(audioProc->component->goComponent, data);
+    restoreFloatMode(mode);
+    return result;
 }
 
 static Steinberg_uint32 SMTG_STDMETHODCALLTYPE audio_getTailSamples(void* thisInterface) {
@@ -428,4 +469,38 @@ static Steinberg_tresult SMTG_STDMETHODCALLTYPE controller_setComponentHandler(v
 static struct Steinberg_IPlugView* SMTG_STDMETHODCALLTYPE controller_createView(void* thisInterface, Steinberg_FIDString name) {
     EditControllerInterface* controller = (EditControllerInterface*)thisInterface;
     return GoEditControllerCreateView(controller->component->goComponent, (char*)name);
-}
\ No newline at end of file
+}
+
+// IConnectionPoint IUnknown implementation
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE connection_queryInterface(void* thisInterface, const Steinberg_TUID iid, void** obj) {
+    ConnectionPointInterface* connection = (ConnectionPointInterface*)thisInterface;
+    return component_queryInterface(connection->component, iid, obj);
+}
+
+static Steinberg_uint32 SMTG_STDMETHODCALLTYPE connection_addRef(void* thisInterface) {
+    ConnectionPointInterface* connection = (ConnectionPointInterface*)thisInterface;
+    return component_addRef(connection->component);
+}
+
+static Steinberg_uint32 SMTG_STDMETHODCALLTYPE connection_release(void* thisInterface) {
+    ConnectionPointInterface* connection = (ConnectionPointInterface*)thisInterface;
+    return component_release(connection->component);
+}
+
+// IConnectionPoint implementation
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE connection_connect(void* thisInterface, struct Steinberg_Vst_IConnectionPoint* other) {
+    ConnectionPointInterface* connection = (ConnectionPointInterface*)thisInterface;
+    DBG_LOG("connection_connect: component=%p, other=%p", connection->component, other);
+    return GoConnectionConnect(connection->component->goComponent, other);
+}
+
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE connection_disconnect(void* thisInterface, struct Steinberg_Vst_IConnectionPoint* other) {
+    ConnectionPointInterface* connection = (ConnectionPointInterface*)thisInterface;
+    DBG_LOG("connection_disconnect: component=%p, other=%p", connection->component, other);
+    return GoConnectionDisconnect(connection->component->goComponent, other);
+}
+
+static Steinberg_tresult SMTG_STDMETHODCALLTYPE connection_notify(void* thisInterface, struct Steinberg_Vst_IMessage* message) {
+    ConnectionPointInterface* connection = (ConnectionPointInterface*)thisInterface;
+    return GoConnectionNotify(connection->component->goComponent, message);
+}
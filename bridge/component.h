@@ -43,6 +43,11 @@ extern Steinberg_tresult GoEditControllerSetParamNormalized(void* component, uin
 extern Steinberg_tresult GoEditControllerSetComponentHandler(void* component, void* handler);
 extern void* GoEditControllerCreateView(void* component, char* name);
 
+// Go callback declarations for IConnectionPoint
+extern Steinberg_tresult GoConnectionConnect(void* component, void* other);
+extern Steinberg_tresult GoConnectionDisconnect(void* component, void* other);
+extern Steinberg_tresult GoConnectionNotify(void* component, void* message);
+
 // Go component lifecycle
 extern void GoReleaseComponent(void* component);
 
@@ -0,0 +1,27 @@
+// Command vst3go-docs writes user-facing reference documentation for
+// registered Go plugins. Each visible parameter is listed with its range,
+// default, unit and description, followed by the plugin's buses, so manuals
+// can be regenerated whenever the code changes.
+//
+// Plugins register themselves from init, so the tool only knows the plugins
+// imported in plugins.go. Projects usually copy this command and import their
+// own plugin packages. The VST3 bridge is not needed, so build it with
+// CGO_ENABLED=0:
+//
+//	CGO_ENABLED=0 go run ./cmd/vst3go-docs -plugin "My Plugin" -out manual/params.md
+//
+// The format follows the -out extension (.md, .html or .json) or the -format
+// flag. JSON carries the full parameter metadata for other tools.
+//
+// At packaging time -cards renders a PNG per factory preset for preset
+// browsers and product pages. A processor that can draw its own editor
+// supplies the image; otherwise the card summarizes the preset's values:
+//
+//	CGO_ENABLED=0 go run ./cmd/vst3go-docs -plugin "My Plugin" -cards dist/presets
+package main
+
+import "github.com/justyntemme/vst3go/pkg/docs"
+
+func main() {
+	docs.Main()
+}
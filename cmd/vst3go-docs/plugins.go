@@ -0,0 +1,6 @@
+package main
+
+// Import plugin packages here to make them available to the tool. A plugin
+// package registers itself by calling plugin.Register from init:
+//
+//	import _ "example.com/myplugin"
@@ -0,0 +1,26 @@
+// Command vst3go-render runs registered Go plugins without a DAW. It streams
+// a WAV or AIFF file through a plugin at a chosen sample rate and block size,
+// applies parameter automation from a JSON timeline and writes the result,
+// for offline processing and DSP regression tests in CI.
+//
+// Plugins register themselves from init, so the tool only knows the plugins
+// imported in plugins.go. Projects usually copy this command and import their
+// own plugin packages. The VST3 bridge is not needed, so build it with
+// CGO_ENABLED=0:
+//
+//	CGO_ENABLED=0 go run ./cmd/vst3go-render -in dry.wav -out wet.wav \
+//		-automation sweep.json -param Gain=-6 -block 256 -rate 48000
+//
+// Run with -list to print the registered plugins and their parameters. Add
+// -meters log.csv (or .json) to log loudness, true peak and the plugin's
+// read-only meters such as gain reduction during the render, for charting:
+//
+//	CGO_ENABLED=0 go run ./cmd/vst3go-render -in ref.wav -out wet.wav \
+//		-meters meters.csv -meter momentary -meter "Gain Reduction" -meter-interval 0.1
+package main
+
+import "github.com/justyntemme/vst3go/pkg/host"
+
+func main() {
+	host.Main()
+}
@@ -0,0 +1,30 @@
+// Command vst3go-state reads and rewrites the state of Go plugins outside a
+// DAW, for debugging state issues and writing state migration tests. It
+// reads .vstpreset files, the bare component state plugins save into host
+// projects, either of those as base64 text, and its own JSON dumps:
+//
+//	CGO_ENABLED=0 go run ./cmd/vst3go-state dump Init.vstpreset
+//	CGO_ENABLED=0 go run ./cmd/vst3go-state dump -json state.b64
+//
+// convert writes state in the format the output extension selects, so a
+// preset can be extracted to bare state, dumped to JSON for editing and
+// turned back into a preset. Bare state does not name its plugin; pass
+// -plugin or -class when writing a .vstpreset file:
+//
+//	CGO_ENABLED=0 go run ./cmd/vst3go-state convert -out edit.json Init.vstpreset
+//	CGO_ENABLED=0 go run ./cmd/vst3go-state convert -out Init.vstpreset -plugin Gain state.bin
+//
+// inject replaces the parameter values and custom state of a preset with
+// those of another state file, keeping the preset's metadata:
+//
+//	CGO_ENABLED=0 go run ./cmd/vst3go-state inject -state edit.json -out Fixed.vstpreset Init.vstpreset
+//
+// Plugins registered in plugins.go name and format the parameter values;
+// state is read and written without them.
+package main
+
+import "github.com/justyntemme/vst3go/pkg/statetool"
+
+func main() {
+	statetool.Main()
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	
-	"github.com/justyntemme/vst3go/pkg/dsp"
 	"github.com/justyntemme/vst3go/pkg/dsp/dynamics"
 	"github.com/justyntemme/vst3go/pkg/dsp/filter"
 	"github.com/justyntemme/vst3go/pkg/dsp/gain"
@@ -106,13 +105,13 @@ const (
 
 // DrumBusProcessor implements the audio processing
 type DrumBusProcessor struct {
-	// DSP - Parallel compression path
+	// DSP - Parallel compression, mixed in by the compressors
 	parallelCompL, parallelCompR *dynamics.Compressor
 	hpfL, hpfR                   *filter.Biquad
 	
 	// DSP - Main path
-	transientShaperL, transientShaperR *dynamics.Expander
-	glueCompL, glueCompR               *dynamics.Compressor
+	transientShaper      *dynamics.TransientShaper
+	glueCompL, glueCompR *dynamics.Compressor
 	
 	// Parameters
 	params *param.Registry
@@ -129,9 +128,9 @@ type DrumBusProcessor struct {
 	sampleRate float64
 	active     bool
 	
-	// Buffers for parallel processing
-	parallelBufferL []float32
-	parallelBufferR []float32
+	// High-passed sidechain for the parallel compressors
+	sidechainL []float32
+	sidechainR []float32
 }
 
 // NewDrumBusProcessor creates a new processor
@@ -336,14 +335,14 @@ func (p *DrumBusProcessor) Initialize(sampleRate float64, maxBlockSize int32) er
 	p.hpfR = filter.NewBiquad(1)
 	
 	// Create main path processors
-	p.transientShaperL = dynamics.NewExpander(sampleRate)
-	p.transientShaperR = dynamics.NewExpander(sampleRate)
+	p.transientShaper = dynamics.NewTransientShaper(sampleRate)
+	p.transientShaper.SetSidechainFilter(true, 60.0) // Kick sub-bass shouldn't mask the hits
 	p.glueCompL = dynamics.NewCompressor(sampleRate)
 	p.glueCompR = dynamics.NewCompressor(sampleRate)
 	
-	// Allocate parallel processing buffers
-	p.parallelBufferL = make([]float32, maxBlockSize)
-	p.parallelBufferR = make([]float32, maxBlockSize)
+	// Allocate sidechain buffers
+	p.sidechainL = make([]float32, maxBlockSize)
+	p.sidechainR = make([]float32, maxBlockSize)
 	
 	// Configure processors
 	p.configureProcessors()
@@ -358,6 +357,8 @@ func (p *DrumBusProcessor) configureProcessors() {
 	p.parallelCompL.SetMakeupGain(10.0) // Heavy makeup gain for parallel
 	p.parallelCompR.SetKnee(dynamics.KneeHard, 0.0)
 	p.parallelCompR.SetMakeupGain(10.0)
+	p.parallelCompL.SetMix(p.parallelMix)
+	p.parallelCompR.SetMix(p.parallelMix)
 	
 	// Configure HPF for sidechain
 	p.updateHPF()
@@ -376,41 +377,11 @@ func (p *DrumBusProcessor) updateHPF() {
 	p.hpfR.SetHighpass(p.sampleRate, p.hpfFreq, 0.7)
 }
 
-// configureTransientShapers configures the expanders for transient shaping
+// configureTransientShapers maps the attack and sustain amounts (-1 to 1)
+// to shaper gains of up to 12 dB
 func (p *DrumBusProcessor) configureTransientShapers() {
-	// Use expanders creatively for transient enhancement
-	// Positive attack = emphasize transients
-	// Negative attack = reduce transients
-	
-	if p.transientAttack >= 0 {
-		// Enhance transients
-		threshold := -30.0 + (p.transientAttack * 20.0)
-		ratio := 1.5 + (p.transientAttack * 2.5)
-		
-		p.transientShaperL.SetThreshold(threshold)
-		p.transientShaperL.SetRatio(ratio)
-		p.transientShaperL.SetAttack(0.0001)
-		p.transientShaperL.SetRelease(0.02)
-		
-		p.transientShaperR.SetThreshold(threshold)
-		p.transientShaperR.SetRatio(ratio)
-		p.transientShaperR.SetAttack(0.0001)
-		p.transientShaperR.SetRelease(0.02)
-	} else {
-		// Reduce transients (use as upward compressor)
-		threshold := -10.0 + (p.transientAttack * 20.0)
-		ratio := 1.5 - (p.transientAttack * 0.4)
-		
-		p.transientShaperL.SetThreshold(threshold)
-		p.transientShaperL.SetRatio(ratio)
-		p.transientShaperL.SetAttack(0.0001)
-		p.transientShaperL.SetRelease(0.05)
-		
-		p.transientShaperR.SetThreshold(threshold)
-		p.transientShaperR.SetRatio(ratio)
-		p.transientShaperR.SetAttack(0.0001)
-		p.transientShaperR.SetRelease(0.05)
-	}
+	p.transientShaper.SetAttack(p.transientAttack * 12.0)
+	p.transientShaper.SetSustain(p.transientSustain * 12.0)
 }
 
 // ProcessAudio processes audio
@@ -429,43 +400,26 @@ func (p *DrumBusProcessor) ProcessAudio(ctx *process.Context) {
 		return
 	}
 	
-	// 1. Copy input for parallel processing
-	copy(p.parallelBufferL[:numSamples], ctx.Input[0][:numSamples])
-	copy(p.parallelBufferR[:numSamples], ctx.Input[1][:numSamples])
-	
-	// 2. Apply HPF to parallel path (sidechain only)
-	// We need filtered signal for detection but process original
-	filteredL := make([]float32, numSamples)
-	filteredR := make([]float32, numSamples)
-	copy(filteredL, p.parallelBufferL[:numSamples])
-	copy(filteredR, p.parallelBufferR[:numSamples])
-	p.hpfL.Process(filteredL, 0)
-	p.hpfR.Process(filteredR, 0)
-	
-	// Apply parallel compression
-	// Note: We can't do true sidechain with current API, so we'll compress normally
-	// but the HPF gives us the frequency-conscious behavior we want
-	p.parallelCompL.ProcessBuffer(p.parallelBufferL[:numSamples], p.parallelBufferL[:numSamples])
-	p.parallelCompR.ProcessBuffer(p.parallelBufferR[:numSamples], p.parallelBufferR[:numSamples])
-	
-	// 3. Main path: Copy input to output
-	copy(ctx.Output[0][:numSamples], ctx.Input[0][:numSamples])
-	copy(ctx.Output[1][:numSamples], ctx.Input[1][:numSamples])
-	
-	// 4. Apply transient shaping
-	p.transientShaperL.ProcessBuffer(ctx.Output[0][:numSamples], ctx.Output[0][:numSamples])
-	p.transientShaperR.ProcessBuffer(ctx.Output[1][:numSamples], ctx.Output[1][:numSamples])
-	
-	// 5. Apply glue compression
+	// 1. High-pass a copy of the input so the kick doesn't dominate detection
+	copy(p.sidechainL[:numSamples], ctx.Input[0][:numSamples])
+	copy(p.sidechainR[:numSamples], ctx.Input[1][:numSamples])
+	p.hpfL.Process(p.sidechainL[:numSamples], 0)
+	p.hpfR.Process(p.sidechainR[:numSamples], 0)
+	
+	// 2. Parallel compression, blended with the dry signal by the mix
+	p.parallelCompL.ProcessSidechain(ctx.Input[0][:numSamples], p.sidechainL[:numSamples], ctx.Output[0][:numSamples])
+	p.parallelCompR.ProcessSidechain(ctx.Input[1][:numSamples], p.sidechainR[:numSamples], ctx.Output[1][:numSamples])
+	
+	// 3. Apply transient shaping
+	p.transientShaper.ProcessStereo(ctx.Output[0][:numSamples], ctx.Output[1][:numSamples],
+		ctx.Output[0][:numSamples], ctx.Output[1][:numSamples])
+	
+	// 4. Apply glue compression
 	p.glueCompL.ProcessBuffer(ctx.Output[0][:numSamples], ctx.Output[0][:numSamples])
 	p.glueCompR.ProcessBuffer(ctx.Output[1][:numSamples], ctx.Output[1][:numSamples])
 	
-	// 6. Mix parallel with main
-	dsp.AddScaled(ctx.Output[0][:numSamples], p.parallelBufferL[:numSamples], float32(p.parallelMix))
-	dsp.AddScaled(ctx.Output[1][:numSamples], p.parallelBufferR[:numSamples], float32(p.parallelMix))
-	
-	// 7. Apply output gain and sustain adjustment
-	gainValue := float32(p.outputGain * (1.0 + p.transientSustain*0.5))
+	// 5. Apply output gain
+	gainValue := float32(p.outputGain)
 	gain.ApplyBuffer(ctx.Output[0][:numSamples], gainValue)
 	gain.ApplyBuffer(ctx.Output[1][:numSamples], gainValue)
 	
@@ -507,13 +461,13 @@ func (p *DrumBusProcessor) updateParameters(ctx *process.Context) {
 	
 	// Transient shaper parameters
 	newTransientAttack := ctx.ParamPlain(ParamTransientAttack)
-	if newTransientAttack != p.transientAttack {
+	newTransientSustain := ctx.ParamPlain(ParamTransientSustain)
+	if newTransientAttack != p.transientAttack || newTransientSustain != p.transientSustain {
 		p.transientAttack = newTransientAttack
+		p.transientSustain = newTransientSustain
 		p.configureTransientShapers()
 	}
 	
-	p.transientSustain = ctx.ParamPlain(ParamTransientSustain)
-	
 	// Glue compressor parameters
 	glueThreshold := ctx.ParamPlain(ParamGlueThreshold)
 	p.glueCompL.SetThreshold(glueThreshold)
@@ -533,6 +487,8 @@ func (p *DrumBusProcessor) updateParameters(ctx *process.Context) {
 	
 	// Mix and output
 	p.parallelMix = ctx.Param(ParamParallelMix)
+	p.parallelCompL.SetMix(p.parallelMix)
+	p.parallelCompR.SetMix(p.parallelMix)
 	
 	outputGainDB := ctx.ParamPlain(ParamOutputGain)
 	p.outputGain = gain.DbToLinear(outputGainDB)
@@ -561,9 +517,8 @@ func (p *DrumBusProcessor) SetActive(active bool) error {
 			p.hpfL.Reset()
 			p.hpfR.Reset()
 		}
-		if p.transientShaperL != nil {
-			p.transientShaperL.Reset()
-			p.transientShaperR.Reset()
+		if p.transientShaper != nil {
+			p.transientShaper.Reset()
 		}
 		if p.glueCompL != nil {
 			p.glueCompL.Reset()
@@ -571,9 +526,9 @@ func (p *DrumBusProcessor) SetActive(active bool) error {
 		}
 		
 		// Clear buffers
-		for i := range p.parallelBufferL {
-			p.parallelBufferL[i] = 0
-			p.parallelBufferR[i] = 0
+		for i := range p.sidechainL {
+			p.sidechainL[i] = 0
+			p.sidechainR[i] = 0
 		}
 	}
 	return nil
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+	"github.com/justyntemme/vst3go/pkg/dsp/sampler"
+)
+
+// numPads is the number of drum pads
+const numPads = 8
+
+// chokeNone is the choke group of pads that ring freely; pads that share
+// any other group cut each other off
+const chokeNone = 0
+
+// chokeRelease is how quickly a choked pad fades out, in seconds
+const chokeRelease = 0.01
+
+// padInfo describes one pad of the kit
+type padInfo struct {
+	name   string
+	note   uint8 // General MIDI drum note
+	output int   // Default output: 0 is Main, 1-4 the aux buses
+	choke  int   // Default choke group
+	render func(sampleRate float64) []float32
+}
+
+// pads is the kit, laid out on the General MIDI drum map
+var pads = [numPads]padInfo{
+	{"Kick", 36, 1, chokeNone, renderKick},
+	{"Rim", 37, 4, chokeNone, renderRim},
+	{"Snare", 38, 2, chokeNone, renderSnare},
+	{"Clap", 39, 2, chokeNone, renderClap},
+	{"Closed Hat", 42, 3, 1, renderClosedHat},
+	{"Open Hat", 46, 3, 1, renderOpenHat},
+	{"Low Tom", 45, 4, chokeNone, renderLowTom},
+	{"High Tom", 50, 4, chokeNone, renderHighTom},
+}
+
+// padForNote returns the pad played by a MIDI note, or -1
+func padForNote(note uint8) int {
+	for i := range pads {
+		if pads[i].note == note {
+			return i
+		}
+	}
+	return -1
+}
+
+// Pad is one drum pad: a one-shot sample played by its own sampler voice,
+// with level, pan and output routing applied on the way out
+type Pad struct {
+	voice *sampler.Voice
+	note  uint8
+
+	// Settings
+	level  float32
+	left   float32 // Pan gains
+	right  float32
+	output int
+	choke  int
+}
+
+// NewPad renders the pad's sound and creates the voice that plays it
+func NewPad(info padInfo, sampleRate float64) *Pad {
+	sample := sampler.NewSample([][]float32{info.render(sampleRate)}, sampleRate)
+	keyMap := sampler.NewKeyMap()
+	keyMap.Add(sampler.NewZone(sample, info.note, info.note, info.note))
+
+	p := &Pad{
+		voice:  sampler.NewVoice(keyMap, sampleRate),
+		note:   info.note,
+		level:  1,
+		output: info.output,
+		choke:  info.choke,
+	}
+	p.SetPan(0)
+	p.SetDecay(2)
+	return p
+}
+
+// Trigger plays the pad from the start
+func (p *Pad) Trigger(velocity uint8) {
+	p.voice.TriggerNote(p.note, velocity)
+}
+
+// Choke fades the pad out quickly
+func (p *Pad) Choke() {
+	if p.voice.IsActive() {
+		p.voice.ReleaseNote()
+	}
+}
+
+// IsActive returns true while the pad is sounding
+func (p *Pad) IsActive() bool {
+	return p.voice.IsActive()
+}
+
+// SetLevel sets the pad gain
+func (p *Pad) SetLevel(gain float32) {
+	p.level = gain
+}
+
+// SetPan sets the pad position (-1 to 1) with a constant power law
+func (p *Pad) SetPan(position float32) {
+	p.left, p.right = pan.MonoToStereo(position, pan.ConstantPower)
+}
+
+// SetTune sets the pad pitch in semitones
+func (p *Pad) SetTune(semitones float64) {
+	p.voice.SetPitchBend(semitones)
+}
+
+// SetDecay sets the decay time constant in seconds. The voice has no
+// sustain, so the sound dies away at this rate even if the sample is longer.
+func (p *Pad) SetDecay(seconds float64) {
+	p.voice.Envelope().SetADSR(0.0005, seconds, 0, chokeRelease)
+}
+
+// SetOutput selects the output bus
+func (p *Pad) SetOutput(output int) {
+	p.output = output
+}
+
+// SetChoke sets the choke group
+func (p *Pad) SetChoke(group int) {
+	p.choke = group
+}
+
+// Process renders the pad into scratch and mixes it into left and right
+func (p *Pad) Process(scratch, left, right []float32) {
+	if !p.voice.IsActive() {
+		return
+	}
+	p.voice.Process(scratch)
+	gl, gr := p.left*p.level, p.right*p.level
+	for i, x := range scratch {
+		left[i] += x * gl
+		right[i] += x * gr
+	}
+}
+
+// Stop silences the pad
+func (p *Pad) Stop() {
+	p.voice.Stop()
+}
+
+// noise is a small xorshift generator, so the kit renders the same every
+// time it is loaded
+type noise uint32
+
+// next returns white noise in -1 to 1
+func (n *noise) next() float32 {
+	x := uint32(*n)
+	x ^= x << 13
+	x ^= x >> 17
+	x ^= x << 5
+	*n = noise(x)
+	return float32(int32(x)) / math.MaxInt32
+}
+
+// sweep renders a sine whose pitch falls exponentially from start to end
+// Hz, with an exponential amplitude decay
+func sweep(buf []float32, sampleRate, start, end, pitchTime, ampTime float64) {
+	phase := 0.0
+	for i := range buf {
+		t := float64(i) / sampleRate
+		freq := end + (start-end)*math.Exp(-t/pitchTime)
+		phase += 2 * math.Pi * freq / sampleRate
+		buf[i] += float32(math.Sin(phase) * math.Exp(-t/ampTime))
+	}
+}
+
+// decayingNoise adds white noise with an exponential decay, high passed by
+// a one-pole filter at cutoff Hz (0 for none)
+func decayingNoise(buf []float32, sampleRate, ampTime, cutoff, gain float64, seed uint32) {
+	n := noise(seed)
+	a := 0.0
+	if cutoff > 0 {
+		a = math.Exp(-2 * math.Pi * cutoff / sampleRate)
+	}
+	var lp float64
+	for i := range buf {
+		t := float64(i) / sampleRate
+		x := float64(n.next())
+		lp = x + (lp-x)*a
+		if cutoff > 0 {
+			x -= lp
+		}
+		buf[i] += float32(x * gain * math.Exp(-t/ampTime))
+	}
+}
+
+// render allocates a sound of the given length in seconds
+func render(seconds, sampleRate float64) []float32 {
+	return make([]float32, int(seconds*sampleRate))
+}
+
+func renderKick(sampleRate float64) []float32 {
+	buf := render(0.6, sampleRate)
+	sweep(buf, sampleRate, 150, 50, 0.04, 0.15)
+	return buf
+}
+
+func renderRim(sampleRate float64) []float32 {
+	buf := render(0.08, sampleRate)
+	sweep(buf, sampleRate, 1700, 1600, 1, 0.008)
+	decayingNoise(buf, sampleRate, 0.004, 2000, 0.5, 0x2f6b)
+	return buf
+}
+
+func renderSnare(sampleRate float64) []float32 {
+	buf := render(0.35, sampleRate)
+	sweep(buf, sampleRate, 260, 185, 0.02, 0.06)
+	decayingNoise(buf, sampleRate, 0.07, 1500, 0.7, 0x51a3)
+	return buf
+}
+
+// renderClap layers three short noise bursts ahead of a longer tail
+func renderClap(sampleRate float64) []float32 {
+	buf := render(0.4, sampleRate)
+	gap := int(0.011 * sampleRate)
+	for burst := 0; burst < 3; burst++ {
+		decayingNoise(buf[burst*gap:], sampleRate, 0.004, 900, 0.8, 0x7c15+uint32(burst))
+	}
+	decayingNoise(buf[3*gap:], sampleRate, 0.08, 900, 0.6, 0x1e9d)
+	return buf
+}
+
+func renderClosedHat(sampleRate float64) []float32 {
+	buf := render(0.15, sampleRate)
+	decayingNoise(buf, sampleRate, 0.02, 7000, 0.8, 0x3b27)
+	return buf
+}
+
+func renderOpenHat(sampleRate float64) []float32 {
+	buf := render(0.8, sampleRate)
+	decayingNoise(buf, sampleRate, 0.18, 7000, 0.7, 0x4d91)
+	return buf
+}
+
+func renderLowTom(sampleRate float64) []float32 {
+	buf := render(0.6, sampleRate)
+	sweep(buf, sampleRate, 160, 95, 0.06, 0.16)
+	return buf
+}
+
+func renderHighTom(sampleRate float64) []float32 {
+	buf := render(0.5, sampleRate)
+	sweep(buf, sampleRate, 240, 150, 0.05, 0.12)
+	return buf
+}
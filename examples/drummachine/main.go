@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/gain"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/midi"
+	vst3plugin "github.com/justyntemme/vst3go/pkg/plugin"
+
+	// Import C bridge - required for VST3 plugin to work
+	_ "github.com/justyntemme/vst3go/pkg/plugin/cbridge"
+)
+
+func init() {
+	vst3plugin.SetFactoryInfo(vst3plugin.FactoryInfo{
+		Vendor: "VST3Go Examples",
+		URL:    "https://github.com/vst3go/examples",
+		Email:  "examples@vst3go.com",
+	})
+
+	vst3plugin.Register(&DrumMachinePlugin{})
+}
+
+// Required for c-shared build mode
+func main() {}
+
+// DrumMachinePlugin implements the Plugin interface
+type DrumMachinePlugin struct{}
+
+func (p *DrumMachinePlugin) GetInfo() plugin.Info {
+	return plugin.Info{
+		ID:       "com.vst3go.examples.drummachine",
+		Name:     "Drum Machine",
+		Version:  "1.0.0",
+		Vendor:   "VST3Go Examples",
+		Category: "Instrument|Drum",
+	}
+}
+
+func (p *DrumMachinePlugin) CreateProcessor() vst3plugin.Processor {
+	return NewDrumMachineProcessor()
+}
+
+// numOutputs is the number of stereo output buses
+const numOutputs = 5
+
+// outputNames are the output buses: the main mix first, then the aux
+// outputs pads can be routed to
+var outputNames = [numOutputs]string{"Main", "Kick", "Snare", "Hats", "Perc"}
+
+// numChokeGroups is the number of choke groups besides None
+const numChokeGroups = 3
+
+// Per-pad parameters. Pad n uses IDs n*paramsPerPad + offset.
+const (
+	paramLevel uint32 = iota
+	paramPan
+	paramTune
+	paramDecay
+	paramOutput
+	paramChoke
+	paramsPerPad
+)
+
+// Global parameters, after the per-pad parameters
+const (
+	ParamVolume uint32 = numPads*paramsPerPad + iota
+	ParamSequencer
+	ParamPattern
+	ParamSwing
+)
+
+// Sequencer modes
+const (
+	sequencerOff  = 0
+	sequencerHost = 1
+)
+
+// maxTriggers bounds the pad hits handled in one block
+const maxTriggers = 256
+
+// DrumMachineProcessor is an eight pad drum machine. Each pad is a one-shot
+// sampler voice with its own level, pan, tuning, decay, output bus and choke
+// group. Pads play from MIDI notes and from a step sequencer that follows
+// the host transport.
+type DrumMachineProcessor struct {
+	params *param.Registry
+	buses  *bus.Configuration
+
+	pads       [numPads]*Pad
+	sequencer  *Sequencer
+	sampleRate float64
+	active     bool
+
+	// Per-block state, allocated once
+	routes   [numOutputs][][]float32
+	triggers []trigger
+	scratch  []float32
+}
+
+// NewDrumMachineProcessor creates a new processor
+func NewDrumMachineProcessor() *DrumMachineProcessor {
+	builder := bus.NewBuilder().WithStereoOutput(outputNames[0])
+	for _, name := range outputNames[1:] {
+		builder.WithAuxOutput(name, 2)
+	}
+	builder.WithEventInput("MIDI In")
+
+	p := &DrumMachineProcessor{
+		params: param.NewRegistry(),
+		buses:  builder.MustBuild(),
+	}
+
+	outputs := make([]param.ChoiceOption, numOutputs)
+	for i, name := range outputNames {
+		outputs[i] = param.ChoiceOption{Value: float64(i), Name: name}
+	}
+	chokes := []param.ChoiceOption{{Value: chokeNone, Name: "None", Aliases: []string{"off"}}}
+	for g := 1; g <= numChokeGroups; g++ {
+		chokes = append(chokes, param.ChoiceOption{Value: float64(g), Name: fmt.Sprintf("Group %d", g)})
+	}
+
+	for i, info := range pads {
+		base := uint32(i) * paramsPerPad
+		p.params.Add(
+			param.New(base+paramLevel, info.name+" Level").
+				Range(-48, 6).
+				Default(0).
+				Unit("dB").
+				Formatter(param.DecibelFormatter, param.DecibelParser).
+				Group(info.name).
+				Build(),
+			param.PanParameter(base+paramPan, info.name+" Pan").Group(info.name).Build(),
+			param.New(base+paramTune, info.name+" Tune").
+				Range(-12, 12).
+				Default(0).
+				Unit("st").
+				Group(info.name).
+				Build(),
+			param.TimeParameter(base+paramDecay, info.name+" Decay", 10, 2000, 2000).Group(info.name).Build(),
+			param.Choice(base+paramOutput, info.name+" Output", outputs).
+				Default(float64(info.output)).
+				Group(info.name).
+				Build(),
+			param.Choice(base+paramChoke, info.name+" Choke", chokes).
+				Default(float64(info.choke)).
+				Group(info.name).
+				Build(),
+		)
+	}
+
+	names := make([]param.ChoiceOption, len(patterns))
+	for i, pat := range patterns {
+		names[i] = param.ChoiceOption{Value: float64(i), Name: pat.name}
+	}
+	p.params.Add(
+		param.New(ParamVolume, "Volume").
+			Range(-48, 6).
+			Default(-6).
+			Unit("dB").
+			Formatter(param.DecibelFormatter, param.DecibelParser).
+			Group("Master").
+			Build(),
+		param.Choice(ParamSequencer, "Sequencer", []param.ChoiceOption{
+			{Value: sequencerOff, Name: "Off"},
+			{Value: sequencerHost, Name: "Host Sync", Aliases: []string{"on", "sync"}},
+		}).Default(sequencerHost).Group("Sequencer").Build(),
+		param.Choice(ParamPattern, "Pattern", names).Group("Sequencer").Build(),
+		param.New(ParamSwing, "Swing").
+			Range(0, 100).
+			Default(0).
+			Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).
+			Group("Sequencer").
+			Build(),
+	)
+
+	return p
+}
+
+// Initialize is called when the plugin is created. The kit is rendered
+// here, at the host's sample rate.
+func (p *DrumMachineProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	p.sampleRate = sampleRate
+	for i, info := range pads {
+		p.pads[i] = NewPad(info, sampleRate)
+	}
+	p.sequencer = NewSequencer(sampleRate)
+	p.triggers = make([]trigger, 0, maxTriggers)
+	p.scratch = make([]float32, maxBlockSize)
+	return nil
+}
+
+// updateParameters applies the block's parameter values to the pads and
+// the sequencer
+func (p *DrumMachineProcessor) updateParameters(ctx *process.Context) {
+	volume := gain.DbToLinear32(float32(ctx.ParamPlain(ParamVolume)))
+	for i, pad := range p.pads {
+		base := uint32(i) * paramsPerPad
+		pad.SetLevel(gain.DbToLinear32(float32(ctx.ParamPlain(base+paramLevel))) * volume)
+		pad.SetPan(float32(ctx.ParamPlain(base+paramPan) / 100.0))
+		pad.SetTune(ctx.ParamPlain(base + paramTune))
+		pad.SetDecay(ctx.ParamPlain(base+paramDecay) / 1000.0)
+		pad.SetOutput(int(ctx.ParamPlain(base + paramOutput)))
+		pad.SetChoke(int(ctx.ParamPlain(base + paramChoke)))
+	}
+
+	p.sequencer.SetPattern(int(ctx.ParamPlain(ParamPattern)))
+	p.sequencer.SetSwing(ctx.ParamPlain(ParamSwing) / 100.0)
+}
+
+// updateRoutes resolves each output to the channels it writes. An aux bus
+// the host has not activated, or gave no buffers for, falls back to the
+// main output so no pad goes silent.
+func (p *DrumMachineProcessor) updateRoutes(ctx *process.Context) {
+	main := ctx.OutputBus(0)
+	for i := range p.routes {
+		p.routes[i] = main
+		if i == 0 {
+			continue
+		}
+		info := p.buses.GetBusInfo(bus.MediaTypeAudio, bus.DirectionOutput, int32(i))
+		if out := ctx.OutputBus(i); info != nil && info.IsActive && len(out) >= 2 {
+			p.routes[i] = out
+		}
+	}
+}
+
+// collectTriggers gathers the block's pad hits from the sequencer and the
+// MIDI input, in sample order
+func (p *DrumMachineProcessor) collectTriggers(ctx *process.Context) []trigger {
+	triggers := p.triggers[:0]
+	if int(ctx.ParamPlain(ParamSequencer)) == sequencerHost {
+		triggers = p.sequencer.Process(ctx, triggers)
+	} else {
+		p.sequencer.Reset()
+	}
+
+	numSamples := ctx.NumSamples()
+	for _, event := range ctx.GetAllInputEvents() {
+		// Drums are one-shots, so note offs are ignored
+		e, ok := event.(midi.NoteOnEvent)
+		if !ok || e.Velocity == 0 || len(triggers) == cap(triggers) {
+			continue
+		}
+		if pad := padForNote(e.NoteNumber); pad >= 0 {
+			offset := max(0, min(numSamples-1, int(e.SampleOffset())))
+			triggers = append(triggers, trigger{offset: offset, pad: pad, velocity: e.Velocity})
+		}
+	}
+	ctx.ClearInputEvents()
+
+	// Sequencer steps and MIDI notes each arrive in order; merge them
+	slices.SortStableFunc(triggers, func(a, b trigger) int {
+		return a.offset - b.offset
+	})
+	return triggers
+}
+
+// trigger plays a pad, first choking the other pads in its group
+func (p *DrumMachineProcessor) trigger(t trigger) {
+	pad := p.pads[t.pad]
+	if pad.choke != chokeNone {
+		for i, other := range p.pads {
+			if i != t.pad && other.choke == pad.choke {
+				other.Choke()
+			}
+		}
+	}
+	pad.Trigger(t.velocity)
+}
+
+// render mixes every sounding pad into its output between two offsets
+func (p *DrumMachineProcessor) render(from, to int) {
+	if from >= to {
+		return
+	}
+	scratch := p.scratch[from:to]
+	for _, pad := range p.pads {
+		out := p.routes[max(0, min(len(p.routes)-1, pad.output))]
+		pad.Process(scratch, out[0][from:to], out[1][from:to])
+	}
+}
+
+// ProcessAudio renders the pads, splitting the block at each hit so the
+// sequencer and MIDI notes land on the exact sample
+func (p *DrumMachineProcessor) ProcessAudio(ctx *process.Context) {
+	ctx.Clear()
+	if !p.active {
+		ctx.ClearInputEvents()
+		return
+	}
+
+	numSamples := ctx.NumSamples()
+	if numSamples == 0 || len(ctx.OutputBus(0)) < 2 {
+		ctx.ClearInputEvents()
+		return
+	}
+	p.updateParameters(ctx)
+	p.updateRoutes(ctx)
+
+	pos := 0
+	for _, t := range p.collectTriggers(ctx) {
+		p.render(pos, t.offset)
+		pos = max(pos, t.offset)
+		p.trigger(t)
+	}
+	p.render(pos, numSamples)
+}
+
+// GetParameters returns the parameter registry
+func (p *DrumMachineProcessor) GetParameters() *param.Registry {
+	return p.params
+}
+
+// GetBuses returns the bus configuration
+func (p *DrumMachineProcessor) GetBuses() *bus.Configuration {
+	return p.buses
+}
+
+// SetActive is called when processing starts/stops
+func (p *DrumMachineProcessor) SetActive(active bool) error {
+	p.active = active
+	if !active && p.sequencer != nil {
+		for _, pad := range p.pads {
+			pad.Stop()
+		}
+		p.sequencer.Reset()
+	}
+	return nil
+}
+
+// GetLatencySamples returns the plugin latency in samples
+func (p *DrumMachineProcessor) GetLatencySamples() int32 {
+	return 0
+}
+
+// GetTailSamples returns the length of the longest sound
+func (p *DrumMachineProcessor) GetTailSamples() int32 {
+	return int32(p.sampleRate)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+// Step sequencer layout
+const (
+	numSteps     = 16
+	stepQuarters = 0.25    // A 16th note
+	maxSwing     = 1.0 / 3 // Swing at 100% delays the off-beat 16ths to a triplet feel
+)
+
+// Step velocities: 'X' is an accent, 'x' a normal hit, anything else a rest
+const (
+	accentVelocity = 127
+	normalVelocity = 100
+)
+
+// pattern is a one-bar groove, one 16-step string per pad
+type pattern struct {
+	name  string
+	steps [numPads]string
+}
+
+// patterns are the built-in grooves, in the order of the Pattern choices.
+// Pads are in kit order: Kick, Rim, Snare, Clap, Closed Hat, Open Hat, Low
+// Tom, High Tom.
+var patterns = []pattern{
+	{"Rock", [numPads]string{
+		"X.......x.x.....",
+		"................",
+		"....X.......X...",
+		"................",
+		"x.x.x.x.x.x.x.x.",
+		"................",
+		"................",
+		"................",
+	}},
+	{"House", [numPads]string{
+		"X...X...X...X...",
+		"................",
+		"................",
+		"....x.......x...",
+		"x.x...x.x.x...x.",
+		"....x.......x...",
+		"................",
+		"................",
+	}},
+	{"Breakbeat", [numPads]string{
+		"X.........x.....",
+		"...x......x...x.",
+		"....X..x.x..X...",
+		"................",
+		"x.x.x.x.x.x.x.x.",
+		"..............x.",
+		"................",
+		"................",
+	}},
+	{"Half Time", [numPads]string{
+		"X.......x.....x.",
+		"................",
+		"........X.......",
+		"................",
+		"x.xxx.x.x.xxx.x.",
+		"................",
+		"...........x....",
+		"..........x.....",
+	}},
+	{"Fill", [numPads]string{
+		"X...............",
+		"................",
+		"x.x.x.xx........",
+		"................",
+		"................",
+		"................",
+		"............X.xx",
+		"........X.xx....",
+	}},
+}
+
+// trigger is a pad hit at a sample offset within the block
+type trigger struct {
+	offset   int
+	pad      int
+	velocity uint8
+}
+
+// Sequencer plays a pattern locked to the host transport. Each step's time
+// is worked out from the song position in quarter notes, so the pattern
+// stays on the grid when the host loops or jumps.
+type Sequencer struct {
+	sampleRate float64
+	pattern    int
+	swing      float64 // 0-1
+
+	lastStep int64   // Index of the last step played, counted from the song start
+	nextPos  float64 // Song position expected at the start of the next block
+	running  bool
+}
+
+// NewSequencer creates a sequencer
+func NewSequencer(sampleRate float64) *Sequencer {
+	return &Sequencer{sampleRate: sampleRate}
+}
+
+// SetPattern selects a built-in pattern
+func (s *Sequencer) SetPattern(index int) {
+	s.pattern = max(0, min(len(patterns)-1, index))
+}
+
+// SetSwing sets the swing amount (0-1)
+func (s *Sequencer) SetSwing(swing float64) {
+	s.swing = math.Max(0, math.Min(1, swing))
+}
+
+// stepTime returns the song position of a step in quarter notes; swing
+// pushes every second 16th later
+func (s *Sequencer) stepTime(step int64) float64 {
+	t := float64(step) * stepQuarters
+	if step%2 != 0 {
+		t += s.swing * maxSwing * stepQuarters
+	}
+	return t
+}
+
+// Process appends the block's steps to triggers and returns it. Nothing
+// plays unless the host transport is running with a tempo and song position.
+func (s *Sequencer) Process(ctx *process.Context, triggers []trigger) []trigger {
+	t := ctx.Transport
+	numSamples := ctx.NumSamples()
+	if t == nil || !t.IsPlaying || !t.HasTempo || !t.HasMusicalTime || t.Tempo <= 0 || numSamples == 0 {
+		s.running = false
+		return triggers
+	}
+
+	samplesPerQuarter := 60.0 / t.Tempo * s.sampleRate
+	start := t.ProjectTimeMusic
+	end := start + float64(numSamples)/samplesPerQuarter
+
+	// A jump or loop restarts the step count from the new position
+	if !s.running || math.Abs(start-s.nextPos) > stepQuarters {
+		s.lastStep = int64(math.Ceil(start/stepQuarters)) - 2
+		s.running = true
+	}
+	s.nextPos = end
+
+	steps := &patterns[s.pattern].steps
+	first := max(s.lastStep+1, int64(math.Floor(start/stepQuarters))-1)
+	for step := first; ; step++ {
+		pos := s.stepTime(step)
+		if pos >= end {
+			break
+		}
+		if pos < start {
+			continue
+		}
+		s.lastStep = step
+
+		offset := min(numSamples-1, int((pos-start)*samplesPerQuarter))
+		index := int(((step % numSteps) + numSteps) % numSteps)
+		for pad := range steps {
+			var velocity uint8
+			switch steps[pad][index] {
+			case 'X':
+				velocity = accentVelocity
+			case 'x':
+				velocity = normalVelocity
+			default:
+				continue
+			}
+			triggers = append(triggers, trigger{offset: offset, pad: pad, velocity: velocity})
+		}
+	}
+	return triggers
+}
+
+// Reset stops the sequencer until the transport next starts
+func (s *Sequencer) Reset() {
+	s.running = false
+}
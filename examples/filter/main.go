@@ -79,7 +79,7 @@ func NewFilterProcessor() *FilterProcessor {
 		param.New(ParamFilterType, "Filter Type").
 			Range(0, 3).
 			Default(0).
-			Steps(4).
+			Steps(3).
 			Formatter(param.FilterTypeFormatter, param.FilterTypeParser).
 			Build(),
 
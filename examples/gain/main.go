@@ -6,9 +6,11 @@ import (
 	"github.com/justyntemme/vst3go/pkg/dsp"
 	"github.com/justyntemme/vst3go/pkg/dsp/gain"
 	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/debug"
 	"github.com/justyntemme/vst3go/pkg/framework/param"
 	"github.com/justyntemme/vst3go/pkg/framework/plugin"
 	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/framework/remote"
 	vst3plugin "github.com/justyntemme/vst3go/pkg/plugin"
 	
 	// Import C bridge - required for VST3 plugin to work
@@ -40,6 +42,9 @@ type GainProcessor struct {
 	// Optional parameter smoothing
 	smoother *param.ParameterSmoother
 	sampleRate float64
+	
+	// OSC control for external tools, nil unless enabled
+	remote *remote.Server
 }
 
 const (
@@ -86,7 +91,7 @@ func NewGainProcessor() *GainProcessor {
 	
 	// Add bypass parameter
 	p.params.Add(
-		param.BypassParameter(ParamBypass, "Bypass").Build(),
+		param.BypassParameter(ParamBypass, "Bypass").Bypass().Build(),
 	)
 	
 	// Add smoothing control parameters
@@ -108,13 +113,24 @@ func NewGainProcessor() *GainProcessor {
 	
 	// Add gain to smoother (will only be used if smoothing is enabled)
 	p.smoother.Add(ParamGain, gainParam, param.ExponentialSmoothing, 0.999)
-
+	
 	return p
 }
 
 func (p *GainProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
 	p.sampleRate = sampleRate
 	
+	// Serve the parameters over OSC when VST3GO_REMOTE is set, e.g. to drive
+	// the plugin from a test harness inside a DAW. Only instances the host
+	// initializes listen, not ones created for scans; further instances find
+	// the port taken and run without.
+	if p.remote == nil {
+		var err error
+		if p.remote, err = remote.ListenFromEnv(p.params); err != nil {
+			debug.Warn("[REMOTE] OSC control disabled: %v", err)
+		}
+	}
+	
 	// Update smoother sample rate
 	if sp, ok := p.smoother.Get(ParamGain); ok {
 		smoothingTime := p.params.Get(ParamSmoothingTime).GetValue()
@@ -124,16 +140,9 @@ func (p *GainProcessor) Initialize(sampleRate float64, maxBlockSize int32) error
 	return nil
 }
 
+// ProcessAudio applies the gain. The bypass parameter needs no handling:
+// the framework crossfades to the dry signal when the host bypasses.
 func (p *GainProcessor) ProcessAudio(ctx *process.Context) {
-	// Handle bypass
-	if bypassParam := p.params.Get(ParamBypass); bypassParam != nil && bypassParam.GetValue() > 0.5 {
-		// Copy input to output
-		for ch := 0; ch < ctx.NumInputChannels() && ch < ctx.NumOutputChannels(); ch++ {
-			copy(ctx.Output[ch], ctx.Input[ch])
-		}
-		return
-	}
-	
 	// Check if smoothing is enabled
 	smoothingEnabled := p.params.Get(ParamSmoothingEnabled).GetValue() > 0.5
 	
@@ -231,6 +240,11 @@ func (p *GainProcessor) GetTailSamples() int32 {
 	return 0
 }
 
+// Remote returns the OSC server, closed by the framework on Terminate
+func (p *GainProcessor) Remote() *remote.Server {
+	return p.remote
+}
+
 func init() {
 	// Set factory info
 	vst3plugin.SetFactoryInfo(vst3plugin.FactoryInfo{
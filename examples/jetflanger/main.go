@@ -202,6 +202,14 @@ func (p *JetFlangerProcessor) Initialize(sampleRate float64, maxBlockSize int32)
 	return nil
 }
 
+// SampleRateChanged follows a new host sample rate without rebuilding the
+// flanger, so its sweep keeps its phase
+func (p *JetFlangerProcessor) SampleRateChanged(sampleRate float64, maxBlockSize int32) error {
+	p.sampleRate = sampleRate
+	p.flanger.SetSampleRate(sampleRate)
+	return nil
+}
+
 // configureFlanger sets up the flanger with current parameters
 func (p *JetFlangerProcessor) configureFlanger() {
 	p.flanger.SetRate(p.rate)
@@ -316,9 +324,9 @@ func (p *JetFlangerProcessor) GetBuses() *bus.Configuration {
 func (p *JetFlangerProcessor) SetActive(active bool) error {
 	p.active = active
 	if !active {
-		// Reset flanger when deactivated
+		// Silence the flanger when deactivated; the sweep keeps its phase
 		if p.flanger != nil {
-			p.flanger.Reset()
+			p.flanger.Clear()
 		}
 	}
 	return nil
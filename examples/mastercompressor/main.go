@@ -8,6 +8,7 @@ import (
 	"github.com/justyntemme/vst3go/pkg/dsp/filter"
 	"github.com/justyntemme/vst3go/pkg/dsp/gain"
 	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/message"
 	"github.com/justyntemme/vst3go/pkg/framework/param"
 	"github.com/justyntemme/vst3go/pkg/framework/plugin"
 	"github.com/justyntemme/vst3go/pkg/framework/process"
@@ -49,8 +50,18 @@ type MasterCompressorProcessor struct {
 	tempL           []float32
 	tempR           []float32
 	linkedSidechain []float32
+
+	// Meters sent to the controller at control rate: the deepest gain
+	// reduction and highest output peak in dB since the last message
+	messages      *message.Channel
+	meterInterval *message.Interval
+	meterValues   [2]float32
 }
 
+// MeterMessageID identifies the meter messages, two float32 values holding
+// the gain reduction and output peak in dB
+const MeterMessageID = "meters"
+
 // Parameter IDs
 const (
 	ParamThreshold = iota
@@ -68,8 +79,9 @@ const (
 
 func NewMasterCompressorProcessor() *MasterCompressorProcessor {
 	p := &MasterCompressorProcessor{
-		params: param.NewRegistry(),
-		buses:  bus.NewStereoConfiguration(),
+		params:   param.NewRegistry(),
+		buses:    bus.NewStereoConfiguration(),
+		messages: message.NewChannel(16, 8),
 	}
 
 	// Add parameters
@@ -131,7 +143,6 @@ func NewMasterCompressorProcessor() *MasterCompressorProcessor {
 			Range(0, 1).
 			Default(0).
 			Formatter(param.OnOffFormatter, param.OnOffParser).
-			Flags(param.IsBypass).
 			Build(),
 	)
 
@@ -190,6 +201,9 @@ func (p *MasterCompressorProcessor) Initialize(sampleRate float64, maxBlockSize
 	p.tempR = make([]float32, maxBlockSize)
 	p.linkedSidechain = make([]float32, maxBlockSize)
 	
+	p.meterInterval = message.NewInterval(message.DefaultRate, sampleRate)
+	p.meterValues = [2]float32{0, -60}
+	
 	return nil
 }
 
@@ -337,6 +351,19 @@ func (p *MasterCompressorProcessor) ProcessAudio(ctx *process.Context) {
 		peakDB = -60
 	}
 	p.params.Get(ParamOutputLevel).SetValue(p.params.Get(ParamOutputLevel).Normalize(float64(peakDB)))
+	
+	// Hold the meters between messages so short peaks are not missed
+	p.meterValues[0] = min(p.meterValues[0], grDB)
+	p.meterValues[1] = max(p.meterValues[1], peakDB)
+	if p.meterInterval.Due(ctx.NumSamples()) {
+		p.messages.SendFloats(MeterMessageID, p.meterValues[:])
+		p.meterValues = [2]float32{0, -60}
+	}
+}
+
+// Messages returns the channel carrying meter messages to the controller
+func (p *MasterCompressorProcessor) Messages() *message.Channel {
+	return p.messages
 }
 
 func (p *MasterCompressorProcessor) GetParameters() *param.Registry {
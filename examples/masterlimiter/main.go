@@ -56,9 +56,8 @@ const (
 
 // MasterLimiterProcessor implements the audio processing
 type MasterLimiterProcessor struct {
-	// DSP
-	limiterL *dynamics.Limiter
-	limiterR *dynamics.Limiter
+	// DSP: one limiter for both channels, so they share a gain computer
+	limiter *dynamics.Limiter
 	
 	// Parameters
 	params *param.Registry
@@ -73,9 +72,6 @@ type MasterLimiterProcessor struct {
 	// State
 	sampleRate float64
 	active     bool
-	
-	// Stereo linking
-	stereoBuffer []float32
 }
 
 // NewMasterLimiterProcessor creates a new processor
@@ -167,30 +163,21 @@ func (p *MasterLimiterProcessor) initializeParameters() {
 func (p *MasterLimiterProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
 	p.sampleRate = sampleRate
 	
-	// Create limiters for stereo processing
-	p.limiterL = dynamics.NewLimiter(sampleRate)
-	p.limiterR = dynamics.NewLimiter(sampleRate)
-	
-	// Configure limiters
-	p.configureLimiters()
+	// Create a linked stereo limiter
+	p.limiter = dynamics.NewLimiterN(sampleRate, 2)
 	
-	// Allocate stereo buffer for linked processing
-	p.stereoBuffer = make([]float32, maxBlockSize*2)
+	// Configure limiter
+	p.configureLimiter()
 	
 	return nil
 }
 
-// configureLimiters sets up the limiters with current parameters
-func (p *MasterLimiterProcessor) configureLimiters() {
-	p.limiterL.SetThreshold(p.ceiling)
-	p.limiterL.SetRelease(p.release)
-	p.limiterL.SetTruePeak(p.truePeak)
-	p.limiterL.SetLookahead(p.lookahead)
-	
-	p.limiterR.SetThreshold(p.ceiling)
-	p.limiterR.SetRelease(p.release)
-	p.limiterR.SetTruePeak(p.truePeak)
-	p.limiterR.SetLookahead(p.lookahead)
+// configureLimiter sets up the limiter with current parameters
+func (p *MasterLimiterProcessor) configureLimiter() {
+	p.limiter.SetThreshold(p.ceiling)
+	p.limiter.SetRelease(p.release)
+	p.limiter.SetTruePeak(p.truePeak)
+	p.limiter.SetLookahead(p.lookahead)
 }
 
 // ProcessAudio processes audio
@@ -209,19 +196,14 @@ func (p *MasterLimiterProcessor) ProcessAudio(ctx *process.Context) {
 		return
 	}
 	
-	// Process stereo with linked limiting
-	// The limiter has stereo processing but we need to handle it properly
-	for i := 0; i < numSamples; i++ {
-		// Process each channel with its own limiter but linked detection
-		ctx.Output[0][i] = p.limiterL.Process(ctx.Input[0][i])
-		ctx.Output[1][i] = p.limiterR.Process(ctx.Input[1][i])
-	}
+	// Process stereo with linked limiting: both channels get the same gain,
+	// so peaks on one side do not shift the image
+	copy(ctx.Output[0][:numSamples], ctx.Input[0][:numSamples])
+	copy(ctx.Output[1][:numSamples], ctx.Input[1][:numSamples])
+	p.limiter.ProcessChannels(ctx.Output[:2])
 	
-	// Update gain reduction meter
-	// Use the maximum gain reduction from both channels
-	grL := p.limiterL.GetGainReduction()
-	grR := p.limiterR.GetGainReduction()
-	maxGR := -max(grL, grR) // Negate because GR is positive but we display negative
+	// Update gain reduction meter with the block's deepest reduction
+	maxGR := -p.limiter.GetMaxGainReduction() // Negate because GR is positive but we display negative
 	
 	if grParam := p.params.Get(ParamGainReduction); grParam != nil {
 		grParam.SetPlainValue(maxGR)
@@ -234,16 +216,14 @@ func (p *MasterLimiterProcessor) updateParameters(ctx *process.Context) {
 	newCeiling := ctx.ParamPlain(ParamCeiling)
 	if newCeiling != p.ceiling {
 		p.ceiling = newCeiling
-		p.limiterL.SetThreshold(p.ceiling)
-		p.limiterR.SetThreshold(p.ceiling)
+		p.limiter.SetThreshold(p.ceiling)
 	}
 	
 	// Check release parameter
 	newRelease := ctx.ParamPlain(ParamRelease)
 	if newRelease != p.release {
 		p.release = newRelease
-		p.limiterL.SetRelease(p.release)
-		p.limiterR.SetRelease(p.release)
+		p.limiter.SetRelease(p.release)
 	}
 	
 	// Check true peak parameter
@@ -251,16 +231,14 @@ func (p *MasterLimiterProcessor) updateParameters(ctx *process.Context) {
 	newTruePeak := truePeakValue > 0.5
 	if newTruePeak != p.truePeak {
 		p.truePeak = newTruePeak
-		p.limiterL.SetTruePeak(p.truePeak)
-		p.limiterR.SetTruePeak(p.truePeak)
+		p.limiter.SetTruePeak(p.truePeak)
 	}
 	
 	// Check lookahead parameter
 	newLookahead := ctx.ParamPlain(ParamLookahead)
 	if newLookahead != p.lookahead {
 		p.lookahead = newLookahead
-		p.limiterL.SetLookahead(p.lookahead)
-		p.limiterR.SetLookahead(p.lookahead)
+		p.limiter.SetLookahead(p.lookahead)
 	}
 }
 
@@ -278,12 +256,9 @@ func (p *MasterLimiterProcessor) GetBuses() *bus.Configuration {
 func (p *MasterLimiterProcessor) SetActive(active bool) error {
 	p.active = active
 	if !active {
-		// Reset limiters when deactivated
-		if p.limiterL != nil {
-			p.limiterL.Reset()
-		}
-		if p.limiterR != nil {
-			p.limiterR.Reset()
+		// Reset limiter when deactivated
+		if p.limiter != nil {
+			p.limiter.Reset()
 		}
 	}
 	return nil
@@ -292,7 +267,10 @@ func (p *MasterLimiterProcessor) SetActive(active bool) error {
 // GetLatencySamples returns the plugin latency in samples
 func (p *MasterLimiterProcessor) GetLatencySamples() int32 {
 	// Return lookahead time in samples
-	return int32(p.lookahead * p.sampleRate)
+	if p.limiter == nil {
+		return 0
+	}
+	return int32(p.limiter.GetLatencySamples())
 }
 
 // GetTailSamples returns the tail length in samples
@@ -301,10 +279,3 @@ func (p *MasterLimiterProcessor) GetTailSamples() int32 {
 	return int32(p.release * p.sampleRate)
 }
 
-// max returns the maximum of two float64 values
-func max(a, b float64) float64 {
-	if a > b {
-		return a
-	}
-	return b
-}
\ No newline at end of file
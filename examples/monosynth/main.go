@@ -0,0 +1,396 @@
+package main
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/gain"
+	"github.com/justyntemme/vst3go/pkg/dsp/modulation"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/framework/tempo"
+	"github.com/justyntemme/vst3go/pkg/framework/voice"
+	"github.com/justyntemme/vst3go/pkg/midi"
+	vst3plugin "github.com/justyntemme/vst3go/pkg/plugin"
+
+	// Import C bridge - required for VST3 plugin to work
+	_ "github.com/justyntemme/vst3go/pkg/plugin/cbridge"
+)
+
+func init() {
+	vst3plugin.SetFactoryInfo(vst3plugin.FactoryInfo{
+		Vendor: "VST3Go Examples",
+		URL:    "https://github.com/vst3go/examples",
+		Email:  "examples@vst3go.com",
+	})
+
+	vst3plugin.Register(&MonoSynthPlugin{})
+}
+
+// Required for c-shared build mode
+func main() {}
+
+// MonoSynthPlugin implements the Plugin interface
+type MonoSynthPlugin struct{}
+
+func (p *MonoSynthPlugin) GetInfo() plugin.Info {
+	return plugin.Info{
+		ID:       "com.vst3go.examples.monosynth",
+		Name:     "Mono Synth",
+		Version:  "1.0.0",
+		Vendor:   "VST3Go Examples",
+		Category: "Instrument|Synth",
+	}
+}
+
+func (p *MonoSynthPlugin) CreateProcessor() vst3plugin.Processor {
+	return NewMonoSynthProcessor()
+}
+
+// Parameter IDs
+const (
+	// Oscillator
+	ParamWaveform uint32 = iota
+	ParamSubLevel
+	ParamGlide
+	ParamTrigger
+	ParamBendRange
+
+	// Filter
+	ParamCutoff
+	ParamResonance
+	ParamEnvAmount
+	ParamKeyTrack
+	ParamFilterAttack
+	ParamFilterDecay
+	ParamFilterSustain
+	ParamFilterRelease
+
+	// Amplifier
+	ParamAmpAttack
+	ParamAmpDecay
+	ParamAmpSustain
+	ParamAmpRelease
+	ParamVolume
+
+	// LFO
+	ParamLFOWaveform
+	ParamLFORate
+	ParamLFOCutoff
+	ParamLFOPitch
+)
+
+// lfoDivisions are the tempo-synced LFO cycle lengths in quarter notes, in
+// the order of the LFO Rate choices
+var lfoDivisions = []struct {
+	name     string
+	quarters float64
+}{
+	{"2 Bars", 8},
+	{"1 Bar", 4},
+	{"1/2", 2},
+	{"1/4", 1},
+	{"1/4 T", 2.0 / 3},
+	{"1/8 D", 0.75},
+	{"1/8", 0.5},
+	{"1/8 T", 1.0 / 3},
+	{"1/16", 0.25},
+}
+
+// defaultLFODivision is the index of 1/4 in lfoDivisions
+const defaultLFODivision = 3
+
+// modWheelVibrato is the vibrato the mod wheel adds at full travel, in cents
+const modWheelVibrato = 50.0
+
+// MonoSynthProcessor is a monophonic synth: one voice driven by the voice
+// allocator in legato mode, a tempo-synced LFO, and sample-accurate MIDI
+type MonoSynthProcessor struct {
+	params *param.Registry
+	buses  *bus.Configuration
+
+	voice      *MonoVoice
+	allocator  *voice.Allocator
+	tempoSync  *tempo.Sync
+	sampleRate float64
+	active     bool
+
+	// MIDI controller state
+	bendRange float64 // Semitones at full bend
+	bend      float64 // -1 to 1
+	modWheel  float64 // 0 to 1
+	lfoCutoff float64 // Octaves
+	lfoPitch  float64 // Vibrato set by the LFO Pitch parameter, in cents
+
+	buffer []float32
+}
+
+// NewMonoSynthProcessor creates a new processor
+func NewMonoSynthProcessor() *MonoSynthProcessor {
+	p := &MonoSynthProcessor{
+		params:    param.NewRegistry(),
+		buses:     bus.NewGenerator(), // Stereo output + MIDI input
+		bendRange: 2,
+	}
+
+	divisions := make([]param.ChoiceOption, len(lfoDivisions))
+	for i, d := range lfoDivisions {
+		divisions[i] = param.ChoiceOption{Value: float64(i), Name: d.name}
+	}
+
+	p.params.Add(
+		param.Choice(ParamWaveform, "Waveform", []param.ChoiceOption{
+			{Value: waveSaw, Name: "Saw"},
+			{Value: waveTriangle, Name: "Triangle", Aliases: []string{"tri"}},
+		}).Group("Oscillator").Build(),
+		param.New(ParamSubLevel, "Sub").
+			Range(0, 100).
+			Default(30).
+			Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).
+			Group("Oscillator").
+			Build(),
+		param.TimeParameter(ParamGlide, "Glide", 0, 2000, 60).Group("Oscillator").Build(),
+		param.Choice(ParamTrigger, "Trigger", []param.ChoiceOption{
+			{Value: triggerLegato, Name: "Legato"},
+			{Value: triggerRetrigger, Name: "Retrigger", Aliases: []string{"multi"}},
+		}).Group("Oscillator").Build(),
+		param.New(ParamBendRange, "Bend Range").
+			Range(0, 12).
+			Default(2).
+			Unit("st").
+			Steps(12).
+			Group("Oscillator").
+			Build(),
+	)
+
+	p.params.Add(
+		param.FrequencyParameter(ParamCutoff, "Cutoff", 20, 20000, 800).Group("Filter").Build(),
+		param.ResonanceParameter(ParamResonance, "Resonance").Default(0.3).Group("Filter").Build(),
+		param.New(ParamEnvAmount, "Env Amount").
+			Range(-5, 5).
+			Default(3).
+			Unit("oct").
+			Group("Filter").
+			Build(),
+		param.New(ParamKeyTrack, "Key Track").
+			Range(0, 100).
+			Default(50).
+			Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).
+			Group("Filter").
+			Build(),
+		param.TimeParameter(ParamFilterAttack, "Filter Attack", 1, 5000, 5).Group("Filter").Build(),
+		param.TimeParameter(ParamFilterDecay, "Filter Decay", 1, 5000, 300).Group("Filter").Build(),
+		param.New(ParamFilterSustain, "Filter Sustain").
+			Range(0, 100).
+			Default(20).
+			Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).
+			Group("Filter").
+			Build(),
+		param.TimeParameter(ParamFilterRelease, "Filter Release", 1, 5000, 200).Group("Filter").Build(),
+	)
+
+	p.params.Add(
+		param.TimeParameter(ParamAmpAttack, "Amp Attack", 1, 5000, 3).Group("Amplifier").Build(),
+		param.TimeParameter(ParamAmpDecay, "Amp Decay", 1, 5000, 200).Group("Amplifier").Build(),
+		param.New(ParamAmpSustain, "Amp Sustain").
+			Range(0, 100).
+			Default(80).
+			Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).
+			Group("Amplifier").
+			Build(),
+		param.TimeParameter(ParamAmpRelease, "Amp Release", 1, 5000, 150).Group("Amplifier").Build(),
+		param.New(ParamVolume, "Volume").
+			Range(-48, 6).
+			Default(-6).
+			Unit("dB").
+			Formatter(param.DecibelFormatter, param.DecibelParser).
+			Group("Amplifier").
+			Build(),
+	)
+
+	p.params.Add(
+		param.Choice(ParamLFOWaveform, "LFO Waveform", []param.ChoiceOption{
+			{Value: float64(modulation.WaveformSine), Name: "Sine"},
+			{Value: float64(modulation.WaveformTriangle), Name: "Triangle", Aliases: []string{"tri"}},
+			{Value: float64(modulation.WaveformSquare), Name: "Square"},
+			{Value: float64(modulation.WaveformSawtooth), Name: "Saw"},
+			{Value: float64(modulation.WaveformRandom), Name: "Random", Aliases: []string{"s&h"}},
+		}).Group("LFO").Build(),
+		param.Choice(ParamLFORate, "LFO Rate", divisions).Default(defaultLFODivision).Group("LFO").Build(),
+		param.New(ParamLFOCutoff, "LFO Cutoff").
+			Range(0, 4).
+			Default(0).
+			Unit("oct").
+			Group("LFO").
+			Build(),
+		param.New(ParamLFOPitch, "LFO Pitch").
+			Range(0, 100).
+			Default(0).
+			Unit("ct").
+			Group("LFO").
+			Build(),
+	)
+
+	return p
+}
+
+// Initialize is called when the plugin is created
+func (p *MonoSynthProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	p.sampleRate = sampleRate
+	p.buffer = make([]float32, maxBlockSize)
+
+	// One voice in legato mode; the allocator keeps the held keys and
+	// returns to the last one still down when the top note is released
+	p.voice = NewMonoVoice(sampleRate)
+	p.allocator = voice.NewAllocator([]voice.Voice{p.voice})
+	p.allocator.SetMode(voice.ModeLegato)
+	p.voice.allocator = p.allocator
+
+	// An instrument has no input to detect a tempo from
+	p.tempoSync = tempo.NewSync(sampleRate)
+	p.tempoSync.SetAudioFallback(false)
+	return nil
+}
+
+// updateParameters applies the block's parameter values to the voice
+func (p *MonoSynthProcessor) updateParameters(ctx *process.Context) {
+	v := p.voice
+	v.SetWaveform(int(ctx.ParamPlain(ParamWaveform)))
+	v.SetSubLevel(ctx.ParamPlain(ParamSubLevel) / 100.0)
+	v.SetTrigger(int(ctx.ParamPlain(ParamTrigger)))
+	v.SetGlide(ctx.ParamPlain(ParamGlide) / 1000.0)
+	p.bendRange = ctx.ParamPlain(ParamBendRange)
+
+	v.SetFilter(ctx.ParamPlain(ParamCutoff), ctx.ParamPlain(ParamResonance))
+	v.SetFilterModulation(ctx.ParamPlain(ParamEnvAmount), ctx.ParamPlain(ParamKeyTrack)/100.0)
+	v.SetFilterEnvelope(
+		ctx.ParamPlain(ParamFilterAttack)/1000.0,
+		ctx.ParamPlain(ParamFilterDecay)/1000.0,
+		ctx.ParamPlain(ParamFilterSustain)/100.0,
+		ctx.ParamPlain(ParamFilterRelease)/1000.0,
+	)
+	v.SetAmpEnvelope(
+		ctx.ParamPlain(ParamAmpAttack)/1000.0,
+		ctx.ParamPlain(ParamAmpDecay)/1000.0,
+		ctx.ParamPlain(ParamAmpSustain)/100.0,
+		ctx.ParamPlain(ParamAmpRelease)/1000.0,
+	)
+	v.SetVolume(gain.DbToLinear32(float32(ctx.ParamPlain(ParamVolume))))
+
+	p.lfoCutoff = ctx.ParamPlain(ParamLFOCutoff)
+	p.lfoPitch = ctx.ParamPlain(ParamLFOPitch)
+	v.LFO().SetWaveform(modulation.Waveform(ctx.ParamPlain(ParamLFOWaveform)))
+	p.updateModulation()
+}
+
+// updateModulation applies the controller state: the mod wheel adds
+// vibrato on top of the LFO Pitch setting
+func (p *MonoSynthProcessor) updateModulation() {
+	p.voice.SetLFODepths(p.lfoCutoff, p.lfoPitch+p.modWheel*modWheelVibrato)
+	p.voice.SetPitchBend(p.bend * p.bendRange)
+}
+
+// syncLFO sets the LFO rate from the tempo. While the host plays, the LFO
+// phase follows the song position so it lands on the beat; when it is
+// stopped the LFO runs free and restarts with each new phrase.
+func (p *MonoSynthProcessor) syncLFO(ctx *process.Context) {
+	division := int(ctx.ParamPlain(ParamLFORate))
+	division = max(0, min(len(lfoDivisions)-1, division))
+	quarters := lfoDivisions[division].quarters
+
+	lfo := p.voice.LFO()
+	bpm := p.tempoSync.Process(ctx)
+	lfo.SetFrequency(bpm / 60.0 / quarters)
+
+	t := ctx.Transport
+	locked := t != nil && t.IsPlaying && t.HasMusicalTime
+	lfo.EnableSync(!locked, 0)
+	if locked {
+		lfo.SetPhase(t.ProjectTimeMusic / quarters)
+	}
+}
+
+// handleEvent applies one MIDI event
+func (p *MonoSynthProcessor) handleEvent(event midi.Event) {
+	switch e := event.(type) {
+	case midi.PitchBendEvent:
+		p.bend = math.Max(-1, math.Min(1, e.NormalizedValue()))
+		p.updateModulation()
+	case midi.ControlChangeEvent:
+		if e.Controller == midi.CCModWheel {
+			p.modWheel = float64(e.Value) / 127.0
+			p.updateModulation()
+			return
+		}
+		p.allocator.ProcessEvent(event)
+	default:
+		p.allocator.ProcessEvent(event)
+	}
+}
+
+// ProcessAudio renders the voice, splitting the block at each MIDI event so
+// notes start on the sample the host scheduled them
+func (p *MonoSynthProcessor) ProcessAudio(ctx *process.Context) {
+	ctx.Clear()
+	if !p.active {
+		ctx.ClearInputEvents()
+		return
+	}
+
+	numSamples := ctx.NumSamples()
+	if numSamples == 0 || len(ctx.Output) < 2 {
+		ctx.ClearInputEvents()
+		return
+	}
+	p.updateParameters(ctx)
+	p.syncLFO(ctx)
+
+	out := p.buffer[:numSamples]
+	pos := 0
+	for _, event := range ctx.GetAllInputEvents() {
+		offset := max(pos, min(numSamples, int(event.SampleOffset())))
+		p.voice.Process(out[pos:offset])
+		pos = offset
+		p.handleEvent(event)
+	}
+	p.voice.Process(out[pos:])
+	ctx.ClearInputEvents()
+
+	copy(ctx.Output[0][:numSamples], out)
+	copy(ctx.Output[1][:numSamples], out)
+}
+
+// GetParameters returns the parameter registry
+func (p *MonoSynthProcessor) GetParameters() *param.Registry {
+	return p.params
+}
+
+// GetBuses returns the bus configuration
+func (p *MonoSynthProcessor) GetBuses() *bus.Configuration {
+	return p.buses
+}
+
+// SetActive is called when processing starts/stops
+func (p *MonoSynthProcessor) SetActive(active bool) error {
+	p.active = active
+	if !active && p.allocator != nil {
+		p.allocator.Reset()
+		p.tempoSync.Reset()
+	}
+	return nil
+}
+
+// GetLatencySamples returns the plugin latency in samples
+func (p *MonoSynthProcessor) GetLatencySamples() int32 {
+	return 0
+}
+
+// GetTailSamples returns the longest amplitude release in samples
+func (p *MonoSynthProcessor) GetTailSamples() int32 {
+	return int32(5 * p.sampleRate)
+}
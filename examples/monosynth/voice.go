@@ -0,0 +1,263 @@
+package main
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/envelope"
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+	"github.com/justyntemme/vst3go/pkg/dsp/modulation"
+	"github.com/justyntemme/vst3go/pkg/dsp/oscillator"
+	"github.com/justyntemme/vst3go/pkg/framework/voice"
+)
+
+// Oscillator waveforms
+const (
+	waveSaw      = 0
+	waveTriangle = 1
+)
+
+// Trigger modes
+const (
+	triggerLegato    = 0 // Overlapping notes glide without restarting the envelopes
+	triggerRetrigger = 1 // Every note restarts the envelopes
+)
+
+// keyTrackCenter is the pitch, as log2 of middle C, where key tracking
+// leaves the cutoff unchanged
+var keyTrackCenter = math.Log2(261.6256)
+
+// MonoVoice is the single voice of the synth: a band-limited oscillator
+// and a sine sub oscillator into a modulated state variable lowpass, with
+// separate filter and amplitude envelopes. Pitch is kept as log2 of the
+// frequency so glide, pitch bend and vibrato all move in octaves.
+type MonoVoice struct {
+	sampleRate float64
+	allocator  *voice.Allocator
+
+	// Sound generators
+	saw    *oscillator.BandLimitedSaw
+	osc    *oscillator.Oscillator // Triangle
+	sub    *oscillator.Oscillator // Sine, one octave down
+	filter *filter.ModulatedSVF
+	ampEnv *envelope.ADSR
+	filEnv *envelope.ADSR
+	lfo    *modulation.LFO
+
+	// Settings
+	waveform    int
+	subLevel    float32
+	trigger     int
+	envAmount   float32 // Octaves at full filter envelope
+	keyTrack    float32 // 0-1, 1 follows the keyboard
+	lfoCutoff   float32 // Octaves at full LFO
+	lfoPitch    float64 // Octaves of vibrato at full LFO
+	bend        float64 // Octaves of pitch bend
+	glideCoeff  float64
+	volume      float32
+	velocityAmp float32
+
+	// Voice state
+	note        uint8
+	velocity    uint8
+	active      bool
+	age         int64
+	pitch       float64 // Current pitch as log2(Hz)
+	targetPitch float64 // Pitch the glide is heading for
+}
+
+// NewMonoVoice creates the voice
+func NewMonoVoice(sampleRate float64) *MonoVoice {
+	v := &MonoVoice{
+		sampleRate: sampleRate,
+		saw:        oscillator.NewBandLimitedSaw(sampleRate),
+		osc:        oscillator.New(sampleRate),
+		sub:        oscillator.New(sampleRate),
+		filter:     filter.NewModulatedSVF(1, sampleRate),
+		ampEnv:     envelope.New(sampleRate),
+		filEnv:     envelope.New(sampleRate),
+		lfo:        modulation.NewLFO(sampleRate),
+		volume:     1,
+	}
+	v.filter.SetModulationDepth(1) // Cutoff modulation is in octaves
+	v.SetGlide(0)
+	return v
+}
+
+// Voice interface implementation
+
+func (v *MonoVoice) IsActive() bool {
+	return v.active
+}
+
+func (v *MonoVoice) GetNote() uint8 {
+	return v.note
+}
+
+func (v *MonoVoice) GetVelocity() uint8 {
+	return v.velocity
+}
+
+func (v *MonoVoice) GetAmplitude() float64 {
+	return float64(v.velocityAmp)
+}
+
+func (v *MonoVoice) GetAge() int64 {
+	return v.age
+}
+
+// TriggerNote starts a note. A note that starts while the last one is
+// still sounding glides to its pitch, and the envelopes restart from where
+// they are, so a retrigger does not click.
+func (v *MonoVoice) TriggerNote(note uint8, velocity uint8) {
+	v.setNote(note, velocity, v.active)
+	if !v.active {
+		v.lfo.Sync()
+	}
+	v.active = true
+	v.age = 0
+	v.ampEnv.Trigger()
+	v.filEnv.Trigger()
+}
+
+// GlideTo moves to a new note while a key is still held. In legato mode
+// the envelopes carry on; in retrigger mode they start again.
+func (v *MonoVoice) GlideTo(note uint8, velocity uint8) {
+	if v.trigger == triggerRetrigger || !v.active {
+		v.TriggerNote(note, velocity)
+		return
+	}
+	v.setNote(note, velocity, true)
+}
+
+// setNote sets the pitch and velocity for a note, jumping straight to the
+// pitch unless glide is set
+func (v *MonoVoice) setNote(note uint8, velocity uint8, glide bool) {
+	v.note = note
+	v.velocity = velocity
+	v.velocityAmp = float32(velocity) / 127.0
+
+	freq := v.allocator.NoteToFrequency(note)
+	v.targetPitch = math.Log2(freq)
+	if !glide {
+		v.pitch = v.targetPitch
+	}
+}
+
+func (v *MonoVoice) ReleaseNote() {
+	v.ampEnv.Release()
+	v.filEnv.Release()
+}
+
+func (v *MonoVoice) Stop() {
+	v.active = false
+	v.ampEnv.Reset()
+	v.filEnv.Reset()
+	v.filter.Reset()
+	v.age = 0
+}
+
+// Process renders the voice into output, replacing its contents
+func (v *MonoVoice) Process(output []float32) {
+	if !v.active {
+		clear(output)
+		return
+	}
+
+	for i := range output {
+		// Pitch: glide toward the target, then bend and vibrato on top
+		v.pitch = v.targetPitch + (v.pitch-v.targetPitch)*v.glideCoeff
+		lfo := float32(v.lfo.Process())
+		freq := math.Exp2(v.pitch + v.bend + v.lfoPitch*float64(lfo))
+
+		var x float32
+		if v.waveform == waveTriangle {
+			v.osc.SetFrequency(freq)
+			x = v.osc.Triangle()
+		} else {
+			v.saw.SetFrequency(freq)
+			x = v.saw.Next()
+		}
+		v.sub.SetFrequency(freq / 2)
+		x += v.subLevel * v.sub.Sine()
+
+		// Filter: envelope, key tracking and LFO move the cutoff in octaves
+		filEnv := v.filEnv.Next()
+		keyOctaves := v.keyTrack * float32(v.pitch-keyTrackCenter)
+		cutoffMod := v.envAmount*filEnv*v.velocityAmp + keyOctaves + v.lfoCutoff*lfo
+		x = v.filter.ProcessSample(x, cutoffMod, 0, 0).Lowpass
+
+		output[i] = x * v.ampEnv.Next() * v.velocityAmp * v.volume
+		v.age++
+	}
+
+	if v.ampEnv.GetStage() == envelope.StageIdle {
+		v.active = false
+		v.filEnv.Reset()
+	}
+}
+
+// SetWaveform selects the main oscillator waveform
+func (v *MonoVoice) SetWaveform(waveform int) {
+	v.waveform = waveform
+}
+
+// SetSubLevel sets the sub oscillator level (0-1)
+func (v *MonoVoice) SetSubLevel(level float64) {
+	v.subLevel = float32(level)
+}
+
+// SetTrigger selects legato or retrigger mode
+func (v *MonoVoice) SetTrigger(mode int) {
+	v.trigger = mode
+}
+
+// SetGlide sets the glide time in seconds
+func (v *MonoVoice) SetGlide(seconds float64) {
+	v.glideCoeff = coeff.TimeConstant(seconds, v.sampleRate)
+}
+
+// SetFilter sets the cutoff in Hz and the resonance (0-1)
+func (v *MonoVoice) SetFilter(cutoff, resonance float64) {
+	v.filter.SetFrequency(cutoff)
+	v.filter.SetResonance(resonance)
+}
+
+// SetFilterModulation sets the envelope amount in octaves and key tracking
+// (0-1)
+func (v *MonoVoice) SetFilterModulation(envOctaves, keyTrack float64) {
+	v.envAmount = float32(envOctaves)
+	v.keyTrack = float32(keyTrack)
+}
+
+// SetFilterEnvelope sets the filter ADSR
+func (v *MonoVoice) SetFilterEnvelope(attack, decay, sustain, release float64) {
+	v.filEnv.SetADSR(attack, decay, sustain, release)
+}
+
+// SetAmpEnvelope sets the amplitude ADSR
+func (v *MonoVoice) SetAmpEnvelope(attack, decay, sustain, release float64) {
+	v.ampEnv.SetADSR(attack, decay, sustain, release)
+}
+
+// LFO returns the voice's LFO for the processor to clock
+func (v *MonoVoice) LFO() *modulation.LFO {
+	return v.lfo
+}
+
+// SetLFODepths sets how far the LFO moves the cutoff, in octaves, and the
+// pitch, in cents
+func (v *MonoVoice) SetLFODepths(cutoffOctaves, pitchCents float64) {
+	v.lfoCutoff = float32(cutoffOctaves)
+	v.lfoPitch = pitchCents / 1200
+}
+
+// SetPitchBend sets the bend in semitones
+func (v *MonoVoice) SetPitchBend(semitones float64) {
+	v.bend = semitones / 12
+}
+
+// SetVolume sets the output gain
+func (v *MonoVoice) SetVolume(gain float32) {
+	v.volume = gain
+}
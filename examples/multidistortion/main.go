@@ -202,7 +202,7 @@ func NewMultiDistortionProcessor() *MultiDistortionProcessor {
 		param.New(ParamBitDepth, "Bit Depth").
 			Range(1, 32).
 			Default(8).
-			Steps(32).
+			StepSize(1).
 			Unit("bits").
 			Build(),
 
@@ -226,7 +226,7 @@ func NewMultiDistortionProcessor() *MultiDistortionProcessor {
 		param.New(ParamAntiAlias, "Anti-Alias").
 			Range(0, 1).
 			Default(1).
-			Steps(2).
+			Steps(1).
 			Formatter(param.OnOffFormatter, param.OnOffParser).
 			Build(),
 	)
@@ -236,11 +236,26 @@ func NewMultiDistortionProcessor() *MultiDistortionProcessor {
 
 func (p *MultiDistortionProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
 	p.sampleRate = sampleRate
+	p.waveshaper.SetSampleRate(sampleRate)
+	p.waveshaper.SetDCCompensation(true) // The asymmetry control adds DC
 	p.tape = distortion.NewTapeSaturation(sampleRate)
 	p.bitcrusher = distortion.NewBitcrusher(sampleRate)
 	return nil
 }
 
+// SampleRateChanged follows a new host sample rate without rebuilding the
+// tape, so its flutter keeps its phase
+func (p *MultiDistortionProcessor) SampleRateChanged(sampleRate float64, maxBlockSize int32) error {
+	if sampleRate == p.sampleRate {
+		return nil
+	}
+	p.sampleRate = sampleRate
+	p.waveshaper.SetSampleRate(sampleRate)
+	p.tape.SetSampleRate(sampleRate)
+	p.bitcrusher = distortion.NewBitcrusher(sampleRate)
+	return nil
+}
+
 func (p *MultiDistortionProcessor) ProcessAudio(ctx *process.Context) {
 	numChannels := ctx.NumInputChannels()
 	if ctx.NumOutputChannels() < numChannels {
@@ -287,13 +302,8 @@ func (p *MultiDistortionProcessor) processWaveshaper(ctx *process.Context, drive
 	p.waveshaper.SetOutput(float64(outputGain))
 
 	// Process each channel
-	for ch := 0; ch < ctx.NumInputChannels() && ch < ctx.NumOutputChannels(); ch++ {
-		input := ctx.Input[ch]
-		output := ctx.Output[ch]
-
-		for i := range input {
-			output[i] = float32(p.waveshaper.Process(float64(input[i])))
-		}
+	for ch := 0; ch < ctx.NumInputChannels() && ch < ctx.NumOutputChannels() && ch < distortion.MaxWaveshaperChannels; ch++ {
+		p.waveshaper.ProcessBuffer32(ctx.Input[ch], ctx.Output[ch], ch)
 	}
 }
 
@@ -312,16 +322,16 @@ func (p *MultiDistortionProcessor) processTube(ctx *process.Context, drive, mix,
 	p.tube.SetMix(float64(mix))
 	p.tube.SetOutput(float64(outputGain))
 
-	// Process each channel
-	for ch := 0; ch < ctx.NumInputChannels() && ch < ctx.NumOutputChannels(); ch++ {
+	// Process each channel, applying drive as input gain in place
+	inputGain := 1.0 + drive*2.0
+	for ch := 0; ch < ctx.NumInputChannels() && ch < ctx.NumOutputChannels() && ch < 2; ch++ {
 		input := ctx.Input[ch]
 		output := ctx.Output[ch]
 
 		for i := range input {
-			// Apply drive as input gain
-			driven := input[i] * (1.0 + drive*2.0)
-			output[i] = float32(p.tube.Process(float64(driven)))
+			output[i] = input[i] * inputGain
 		}
+		p.tube.ProcessBuffer32(output, output, ch)
 	}
 }
 
@@ -342,33 +352,10 @@ func (p *MultiDistortionProcessor) processTape(ctx *process.Context, drive, mix,
 
 	// Process stereo
 	if ctx.NumInputChannels() >= 2 && ctx.NumOutputChannels() >= 2 {
-		inputL := make([]float64, len(ctx.Input[0]))
-		inputR := make([]float64, len(ctx.Input[1]))
-		outputL := make([]float64, len(ctx.Output[0]))
-		outputR := make([]float64, len(ctx.Output[1]))
-
-		// Convert to float64
-		for i := range inputL {
-			inputL[i] = float64(ctx.Input[0][i])
-			inputR[i] = float64(ctx.Input[1][i])
-		}
-
-		// Process
-		p.tape.ProcessStereo(inputL, inputR, outputL, outputR)
-
-		// Convert back
-		for i := range outputL {
-			ctx.Output[0][i] = float32(outputL[i])
-			ctx.Output[1][i] = float32(outputR[i])
-		}
+		p.tape.ProcessStereo32(ctx.Input[0], ctx.Input[1], ctx.Output[0], ctx.Output[1])
 	} else if ctx.NumInputChannels() >= 1 && ctx.NumOutputChannels() >= 1 {
 		// Mono processing
-		input := ctx.Input[0]
-		output := ctx.Output[0]
-
-		for i := range input {
-			output[i] = float32(p.tape.Process(float64(input[i])))
-		}
+		p.tape.ProcessBuffer32(ctx.Input[0], ctx.Output[0], 0)
 	}
 }
 
@@ -389,33 +376,10 @@ func (p *MultiDistortionProcessor) processBitcrusher(ctx *process.Context, drive
 
 	// Process stereo
 	if ctx.NumInputChannels() >= 2 && ctx.NumOutputChannels() >= 2 {
-		inputL := make([]float64, len(ctx.Input[0]))
-		inputR := make([]float64, len(ctx.Input[1]))
-		outputL := make([]float64, len(ctx.Output[0]))
-		outputR := make([]float64, len(ctx.Output[1]))
-
-		// Convert to float64
-		for i := range inputL {
-			inputL[i] = float64(ctx.Input[0][i])
-			inputR[i] = float64(ctx.Input[1][i])
-		}
-
-		// Process
-		p.bitcrusher.ProcessStereo(inputL, inputR, outputL, outputR)
-
-		// Convert back
-		for i := range outputL {
-			ctx.Output[0][i] = float32(outputL[i])
-			ctx.Output[1][i] = float32(outputR[i])
-		}
+		p.bitcrusher.ProcessStereo32(ctx.Input[0], ctx.Input[1], ctx.Output[0], ctx.Output[1])
 	} else if ctx.NumInputChannels() >= 1 && ctx.NumOutputChannels() >= 1 {
 		// Mono processing
-		input := ctx.Input[0]
-		output := ctx.Output[0]
-
-		for i := range input {
-			output[i] = float32(p.bitcrusher.Process(float64(input[i])))
-		}
+		p.bitcrusher.ProcessBuffer32(ctx.Input[0], ctx.Output[0], 0)
 	}
 }
 
@@ -429,9 +393,9 @@ func (p *MultiDistortionProcessor) GetBuses() *bus.Configuration {
 
 func (p *MultiDistortionProcessor) SetActive(active bool) error {
 	if !active {
-		p.waveshaper = distortion.NewWaveshaper()
+		p.waveshaper.Reset()
 		p.tube.Reset()
-		p.tape.Reset()
+		p.tape.Clear() // Keeps the flutter phase
 		p.bitcrusher.Reset()
 	}
 	return nil
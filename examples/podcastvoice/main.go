@@ -0,0 +1,170 @@
+package main
+
+import (
+	"github.com/justyntemme/vst3go/pkg/dsp/gain"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	fwdsp "github.com/justyntemme/vst3go/pkg/framework/dsp"
+	"github.com/justyntemme/vst3go/pkg/framework/modmatrix"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	vst3plugin "github.com/justyntemme/vst3go/pkg/plugin"
+
+	// Import C bridge - required for VST3 plugin to work
+	_ "github.com/justyntemme/vst3go/pkg/plugin/cbridge"
+)
+
+func init() {
+	vst3plugin.SetFactoryInfo(vst3plugin.FactoryInfo{
+		Vendor: "VST3Go Examples",
+		URL:    "https://github.com/vst3go/examples",
+		Email:  "examples@vst3go.com",
+	})
+
+	vst3plugin.Register(&PodcastVoicePlugin{})
+}
+
+// Required for c-shared build mode
+func main() {}
+
+// PodcastVoicePlugin implements the Plugin interface
+type PodcastVoicePlugin struct{}
+
+func (p *PodcastVoicePlugin) GetInfo() plugin.Info {
+	return plugin.Info{
+		ID:       "com.vst3go.examples.podcastvoice",
+		Name:     "Podcast Voice",
+		Version:  "1.0.0",
+		Vendor:   "VST3Go Examples",
+		Category: "Fx|Dynamics",
+	}
+}
+
+func (p *PodcastVoicePlugin) CreateProcessor() vst3plugin.Processor {
+	return NewPodcastVoiceProcessor()
+}
+
+// Parameter IDs
+const (
+	ParamAmount uint32 = iota
+	ParamOutput
+	ParamDeEss
+	ParamLevel
+	ParamLimit
+)
+
+// PodcastVoiceProcessor runs the speech stack from one Amount knob, mapped
+// to every stage through a modulation matrix
+type PodcastVoiceProcessor struct {
+	stack  *fwdsp.SpeechStack
+	matrix *modmatrix.Matrix
+	params *param.Registry
+	buses  *bus.Configuration
+
+	active bool
+}
+
+// NewPodcastVoiceProcessor creates a new processor
+func NewPodcastVoiceProcessor() *PodcastVoiceProcessor {
+	p := &PodcastVoiceProcessor{
+		params: param.NewRegistry(),
+		buses:  bus.NewStereoConfiguration(),
+	}
+
+	p.params.Add(
+		param.MixParameter(ParamAmount, "Amount").Default(50).Build(),
+		param.GainParameter(ParamOutput, "Output").Build(),
+
+		// Meters
+		param.New(ParamDeEss, "De-Ess").
+			Range(-20, 0).
+			Default(0).
+			Unit("dB").
+			Flags(param.IsReadOnly).
+			Build(),
+		param.New(ParamLevel, "Level").
+			Range(-24, 24).
+			Default(0).
+			Unit("dB").
+			Flags(param.IsReadOnly).
+			Build(),
+		param.New(ParamLimit, "Limit").
+			Range(-20, 0).
+			Default(0).
+			Unit("dB").
+			Flags(param.IsReadOnly).
+			Build(),
+	)
+
+	return p
+}
+
+// Initialize is called when the plugin is created
+func (p *PodcastVoiceProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	p.stack = fwdsp.NewSpeechStack(sampleRate, fwdsp.PodcastPreset)
+	p.matrix = modmatrix.New(p.params)
+	amount := p.matrix.AddSource("Amount", modmatrix.NewMacroSource(ParamAmount, false))
+	return p.stack.ConnectMacro(p.matrix, amount)
+}
+
+// ProcessAudio processes audio
+func (p *PodcastVoiceProcessor) ProcessAudio(ctx *process.Context) {
+	if !p.active {
+		ctx.PassThrough()
+		return
+	}
+
+	numSamples := ctx.NumSamples()
+	if numSamples == 0 || len(ctx.Input) < 2 || len(ctx.Output) < 2 {
+		return
+	}
+
+	// The macro sets every stage for this block
+	p.matrix.Process(ctx)
+
+	left, right := ctx.Output[0][:numSamples], ctx.Output[1][:numSamples]
+	copy(left, ctx.Input[0][:numSamples])
+	copy(right, ctx.Input[1][:numSamples])
+	p.stack.ProcessStereo(left, right)
+
+	if output := gain.DbToLinear32(float32(ctx.ParamPlain(ParamOutput))); output != 1 {
+		gain.ApplyBuffer(left, output)
+		gain.ApplyBuffer(right, output)
+	}
+
+	p.params.Get(ParamDeEss).SetPlainValue(-p.stack.DeEsser().GetMaxGainReduction())
+	p.params.Get(ParamLevel).SetPlainValue(p.stack.Leveler().GetGain())
+	p.params.Get(ParamLimit).SetPlainValue(-p.stack.Limiter().GetMaxGainReduction())
+}
+
+// GetParameters returns the parameter registry
+func (p *PodcastVoiceProcessor) GetParameters() *param.Registry {
+	return p.params
+}
+
+// GetBuses returns the bus configuration
+func (p *PodcastVoiceProcessor) GetBuses() *bus.Configuration {
+	return p.buses
+}
+
+// SetActive is called when processing starts/stops
+func (p *PodcastVoiceProcessor) SetActive(active bool) error {
+	p.active = active
+	if !active && p.stack != nil {
+		p.stack.Reset()
+	}
+	return nil
+}
+
+// GetLatencySamples returns the leveler and limiter look-ahead in samples
+func (p *PodcastVoiceProcessor) GetLatencySamples() int32 {
+	if p.stack == nil {
+		return 0
+	}
+	return int32(p.stack.GetLatencySamples())
+}
+
+// GetTailSamples returns the tail length in samples
+func (p *PodcastVoiceProcessor) GetTailSamples() int32 {
+	return p.GetLatencySamples()
+}
@@ -109,7 +109,7 @@ func NewSidechainProcessor() *SidechainProcessor {
 		param.New(ParamSidechainActive, "Sidechain Active").
 			Range(0, 1).
 			Default(1).
-			Steps(2).
+			Steps(1).
 			Formatter(param.OnOffFormatter, param.OnOffParser).
 			Build(),
 
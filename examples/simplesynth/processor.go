@@ -119,6 +119,9 @@ func (p *SimpleSynthProcessor) Initialize(sampleRate float64, maxBlockSize int32
 	p.voiceAlloc = voice.NewAllocator(p.voices)
 	p.voiceAlloc.SetMode(voice.ModePoly)
 	p.voiceAlloc.SetStealingMode(voice.StealOldest)
+	for _, v := range p.voices {
+		v.(*SynthVoice).allocator = p.voiceAlloc
+	}
 	
 	// Update voice parameters
 	p.updateVoiceParameters()
@@ -25,6 +25,9 @@ type SynthVoice struct {
 	
 	// Sample rate
 	sampleRate float64
+
+	// Allocator providing the tuning
+	allocator *voice.Allocator
 }
 
 // NewSynthVoice creates a new synth voice
@@ -61,7 +64,11 @@ func (v *SynthVoice) GetAge() int64 {
 func (v *SynthVoice) TriggerNote(note uint8, velocity uint8) {
 	v.note = note
 	v.velocity = velocity
-	v.frequency = midi.NoteToFrequency(note, 440.0)
+	if v.allocator != nil {
+		v.frequency = v.allocator.NoteToFrequency(note)
+	} else {
+		v.frequency = midi.NoteToFrequency(note, 440.0)
+	}
 	v.amplitude = float64(velocity) / 127.0
 	v.active = true
 	v.age = 0
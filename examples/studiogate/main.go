@@ -131,7 +131,6 @@ func NewStudioGateProcessor() *StudioGateProcessor {
 			Range(0, 1).
 			Default(0).
 			Formatter(param.OnOffFormatter, param.OnOffParser).
-			Flags(param.IsBypass).
 			Build(),
 	)
 
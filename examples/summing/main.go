@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/gain"
+	"github.com/justyntemme/vst3go/pkg/dsp/mix"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	vst3plugin "github.com/justyntemme/vst3go/pkg/plugin"
+
+	// Import C bridge - required for VST3 plugin to work
+	_ "github.com/justyntemme/vst3go/pkg/plugin/cbridge"
+)
+
+func init() {
+	vst3plugin.SetFactoryInfo(vst3plugin.FactoryInfo{
+		Vendor: "VST3Go Examples",
+		URL:    "https://github.com/vst3go/examples",
+		Email:  "examples@vst3go.com",
+	})
+
+	vst3plugin.Register(&SummingPlugin{})
+}
+
+// Required for c-shared build mode
+func main() {}
+
+// SummingPlugin implements the Plugin interface
+type SummingPlugin struct{}
+
+func (p *SummingPlugin) GetInfo() plugin.Info {
+	return plugin.Info{
+		ID:       "com.vst3go.examples.summing",
+		Name:     "Summing Mixer",
+		Version:  "1.0.0",
+		Vendor:   "VST3Go Examples",
+		Category: "Fx|Mixing",
+	}
+}
+
+func (p *SummingPlugin) CreateProcessor() vst3plugin.Processor {
+	return NewSummingProcessor()
+}
+
+// numInputs is the number of stereo input buses summed to the output
+const numInputs = 4
+
+// maxLatency is the longest path latency the mixer compensates, in seconds
+const maxLatency = 0.1
+
+// Per-input parameters. Input n uses IDs n*paramsPerInput + offset.
+const (
+	paramTrim uint32 = iota
+	paramPan
+	paramLatency
+	paramsPerInput
+)
+
+// ParamOutput is the output level, after the per-input parameters
+const ParamOutput = numInputs * paramsPerInput
+
+// SummingProcessor sums several input buses with per-input trim, pan and
+// latency compensation
+type SummingProcessor struct {
+	summing *mix.Summing
+	params  *param.Registry
+	buses   *bus.Configuration
+
+	// Per-bus channel views, reused every block
+	inputs [numInputs][][]float32
+
+	sampleRate float64
+	active     bool
+}
+
+// NewSummingProcessor creates a new processor
+func NewSummingProcessor() *SummingProcessor {
+	builder := bus.NewBuilder().WithStereoInput("Input 1")
+	for i := 2; i <= numInputs; i++ {
+		builder.WithAuxInput(fmt.Sprintf("Input %d", i), 2)
+	}
+	builder.WithStereoOutput("Output")
+
+	// A summing mixer wants every input, so the aux buses start active
+	for i := 1; i < numInputs; i++ {
+		builder.SetBusActive(bus.MediaTypeAudio, bus.DirectionInput, int32(i), true)
+	}
+
+	p := &SummingProcessor{
+		params: param.NewRegistry(),
+		buses:  builder.MustBuild(),
+	}
+
+	for i := 0; i < numInputs; i++ {
+		base := uint32(i) * paramsPerInput
+		p.params.Add(
+			param.GainParameter(base+paramTrim, fmt.Sprintf("Trim %d", i+1)).Build(),
+			param.PanParameter(base+paramPan, fmt.Sprintf("Pan %d", i+1)).Build(),
+
+			// The latency of the path feeding the input, as the host reports
+			// for the plugins on it
+			param.New(base+paramLatency, fmt.Sprintf("Latency %d", i+1)).
+				Range(0, maxLatency*1000).
+				Default(0).
+				Unit("ms").
+				Formatter(param.TimeFormatter, param.TimeParser).
+				Build(),
+		)
+	}
+	p.params.Add(param.GainParameter(ParamOutput, "Output").Build())
+
+	return p
+}
+
+// Initialize is called when the plugin is created
+func (p *SummingProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	p.sampleRate = sampleRate
+	p.summing = mix.NewSumming(numInputs, maxLatency, sampleRate)
+	return nil
+}
+
+// updateParameters applies the block's parameter values to the mixer
+func (p *SummingProcessor) updateParameters(ctx *process.Context) {
+	for i := 0; i < numInputs; i++ {
+		base := uint32(i) * paramsPerInput
+		trim := ctx.ParamPlain(base + paramTrim)
+		if trim <= -80 {
+			trim = math.Inf(-1)
+		}
+		p.summing.SetTrim(i, trim)
+		p.summing.SetPan(i, float32(ctx.ParamPlain(base+paramPan)/100.0))
+		latency := int(math.Round(ctx.ParamPlain(base+paramLatency) / 1000.0 * p.sampleRate))
+		p.summing.SetLatency(i, latency)
+	}
+}
+
+// ProcessAudio processes audio
+func (p *SummingProcessor) ProcessAudio(ctx *process.Context) {
+	if !p.active {
+		ctx.PassThrough()
+		return
+	}
+
+	out := ctx.OutputBus(0)
+	numSamples := ctx.NumSamples()
+	if numSamples == 0 || len(out) < 2 {
+		return
+	}
+	p.updateParameters(ctx)
+
+	// Inputs the host did not connect are silent; their compensation
+	// delays keep running
+	for i := range p.inputs {
+		p.inputs[i] = ctx.InputBus(i)
+	}
+	p.summing.Process(p.inputs[:], out[0][:numSamples], out[1][:numSamples])
+
+	if level := ctx.ParamPlain(ParamOutput); level != 0 {
+		outGain := float32(0)
+		if level > -80 {
+			outGain = gain.DbToLinear32(float32(level))
+		}
+		gain.ApplyBuffer(out[0][:numSamples], outGain)
+		gain.ApplyBuffer(out[1][:numSamples], outGain)
+	}
+}
+
+// GetParameters returns the parameter registry
+func (p *SummingProcessor) GetParameters() *param.Registry {
+	return p.params
+}
+
+// GetBuses returns the bus configuration
+func (p *SummingProcessor) GetBuses() *bus.Configuration {
+	return p.buses
+}
+
+// SetActive is called when processing starts/stops
+func (p *SummingProcessor) SetActive(active bool) error {
+	p.active = active
+	if !active && p.summing != nil {
+		p.summing.Reset()
+	}
+	return nil
+}
+
+// GetLatencySamples returns the compensation delay of the earliest input
+func (p *SummingProcessor) GetLatencySamples() int32 {
+	if p.summing == nil {
+		return 0
+	}
+	return int32(p.summing.GetLatencySamples())
+}
+
+// GetTailSamples returns the tail length in samples
+func (p *SummingProcessor) GetTailSamples() int32 {
+	return p.GetLatencySamples()
+}
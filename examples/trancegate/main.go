@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/envelope"
+	"github.com/justyntemme/vst3go/pkg/dsp/gain"
+	"github.com/justyntemme/vst3go/pkg/dsp/modulation"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/midi"
+	vst3plugin "github.com/justyntemme/vst3go/pkg/plugin"
+
+	// Import C bridge - required for VST3 plugin to work
+	_ "github.com/justyntemme/vst3go/pkg/plugin/cbridge"
+)
+
+func init() {
+	vst3plugin.SetFactoryInfo(vst3plugin.FactoryInfo{
+		Vendor: "VST3Go Examples",
+		URL:    "https://github.com/vst3go/examples",
+		Email:  "examples@vst3go.com",
+	})
+
+	vst3plugin.Register(&TranceGatePlugin{})
+}
+
+// Required for c-shared build mode
+func main() {}
+
+// TranceGatePlugin implements the Plugin interface
+type TranceGatePlugin struct{}
+
+func (p *TranceGatePlugin) GetInfo() plugin.Info {
+	return plugin.Info{
+		ID:       "com.vst3go.examples.trancegate",
+		Name:     "Trance Gate",
+		Version:  "1.0.0",
+		Vendor:   "VST3Go Examples",
+		Category: "Fx|Modulation",
+	}
+}
+
+func (p *TranceGatePlugin) CreateProcessor() vst3plugin.Processor {
+	return NewTranceGateProcessor()
+}
+
+// ParamStep is the first step level; step n uses ParamStep + n
+const ParamStep uint32 = 0
+
+// Parameter IDs, after the step levels
+const (
+	ParamSteps uint32 = ParamStep + modulation.MaxGateSteps + iota
+	ParamRate
+	ParamSwing
+	ParamAttack
+	ParamRelease
+	ParamDepth
+	ParamTrigger
+	ParamThreshold
+)
+
+// Trigger modes
+const (
+	triggerHost      = 0 // Follow the host song position
+	triggerMIDI      = 1 // Restart the pattern on every MIDI note
+	triggerSidechain = 2 // Restart the pattern on every sidechain hit
+)
+
+// stepRates are the step lengths in quarter notes, in the order of the
+// Rate choices
+var stepRates = []struct {
+	name     string
+	quarters float64
+}{
+	{"1/8", 0.5},
+	{"1/8 T", 1.0 / 3},
+	{"1/16", 0.25},
+	{"1/16 T", 1.0 / 6},
+	{"1/32", 0.125},
+}
+
+// defaultStepRate is the index of 1/16 in stepRates
+const defaultStepRate = 2
+
+// keyRearm is how far below the threshold, in dB, the sidechain must fall
+// before it can trigger again
+const keyRearm = 6.0
+
+// TranceGateProcessor chops its input with a step pattern. The pattern
+// follows the host tempo and song position, or restarts on MIDI notes or
+// hits on the sidechain input.
+type TranceGateProcessor struct {
+	params *param.Registry
+	buses  *bus.Configuration
+
+	gate       *modulation.StepGate
+	key        *envelope.Detector
+	keyArmed   bool
+	sampleRate float64
+	active     bool
+}
+
+// NewTranceGateProcessor creates a new processor
+func NewTranceGateProcessor() *TranceGateProcessor {
+	p := &TranceGateProcessor{
+		params: param.NewRegistry(),
+		buses: bus.NewBuilder().
+			WithStereoInput("Stereo In").
+			WithStereoOutput("Stereo Out").
+			WithSidechain("Sidechain In").
+			WithEventInput("MIDI In").
+			MustBuild(),
+	}
+
+	// A classic pattern to start from: the off-beat 16ths closed
+	for i := 0; i < modulation.MaxGateSteps; i++ {
+		level := 100.0
+		if i%2 == 1 {
+			level = 0
+		}
+		p.params.Add(param.New(ParamStep+uint32(i), fmt.Sprintf("Step %d", i+1)).
+			Range(0, 100).
+			Default(level).
+			Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).
+			Group("Pattern").
+			Build())
+	}
+
+	rates := make([]param.ChoiceOption, len(stepRates))
+	for i, r := range stepRates {
+		rates[i] = param.ChoiceOption{Value: float64(i), Name: r.name}
+	}
+
+	p.params.Add(
+		param.Choice(ParamSteps, "Steps", []param.ChoiceOption{
+			{Value: 16, Name: "16"},
+			{Value: 32, Name: "32"},
+		}).Default(16).Group("Pattern").Build(),
+		param.Choice(ParamRate, "Rate", rates).Default(defaultStepRate).Group("Pattern").Build(),
+		param.New(ParamSwing, "Swing").
+			Range(0, 100).
+			Default(0).
+			Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).
+			Group("Pattern").
+			Build(),
+		param.TimeParameter(ParamAttack, "Attack", 0.1, 50, 1).Group("Shape").Build(),
+		param.TimeParameter(ParamRelease, "Release", 1, 200, 10).Group("Shape").Build(),
+		param.New(ParamDepth, "Depth").
+			Range(0, 100).
+			Default(100).
+			Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).
+			Smooth(20*time.Millisecond).
+			Group("Shape").
+			Build(),
+		param.Choice(ParamTrigger, "Trigger", []param.ChoiceOption{
+			{Value: triggerHost, Name: "Host Sync", Aliases: []string{"host", "sync"}},
+			{Value: triggerMIDI, Name: "MIDI Note", Aliases: []string{"midi", "note"}},
+			{Value: triggerSidechain, Name: "Sidechain", Aliases: []string{"key"}},
+		}).Group("Trigger").Build(),
+		param.ThresholdParameter(ParamThreshold, "Key Threshold", -60, 0, -24).Group("Trigger").Build(),
+	)
+
+	return p
+}
+
+// Initialize is called when the plugin is created
+func (p *TranceGateProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	p.sampleRate = sampleRate
+	p.gate = modulation.NewStepGate(sampleRate)
+	p.key = envelope.NewDetector(sampleRate, envelope.ModePeak)
+	p.key.SetTimeConstants(0.0005, 0.05)
+	p.keyArmed = true
+	return nil
+}
+
+// updateParameters applies the block's parameter values to the gate
+func (p *TranceGateProcessor) updateParameters(ctx *process.Context) {
+	g := p.gate
+	for i := 0; i < modulation.MaxGateSteps; i++ {
+		g.SetStep(i, ctx.ParamPlain(ParamStep+uint32(i))/100.0)
+	}
+	g.SetSteps(int(ctx.ParamPlain(ParamSteps)))
+
+	rate := max(0, min(len(stepRates)-1, int(ctx.ParamPlain(ParamRate))))
+	g.SetStepLength(stepRates[rate].quarters)
+	g.SetSwing(ctx.ParamPlain(ParamSwing) / 100.0)
+	g.SetSmoothing(ctx.ParamPlain(ParamAttack)/1000.0, ctx.ParamPlain(ParamRelease)/1000.0)
+	g.SetDepth(ctx.ParamPlain(ParamDepth) / 100.0)
+}
+
+// followTransport takes the tempo from the host and, in Host Sync mode,
+// the pattern position from the song position. When the host is stopped
+// the pattern keeps running at the last tempo.
+func (p *TranceGateProcessor) followTransport(ctx *process.Context, mode int) {
+	t := ctx.Transport
+	if t == nil {
+		return
+	}
+	if t.HasTempo && t.Tempo > 0 {
+		p.gate.SetTempo(t.Tempo)
+	}
+	if mode == triggerHost && t.IsPlaying && t.HasMusicalTime {
+		p.gate.SetPosition(t.ProjectTimeMusic)
+	}
+}
+
+// gateRange runs the gate over part of the block
+func (p *TranceGateProcessor) gateRange(in, out [][]float32, from, to int) {
+	if from >= to {
+		return
+	}
+	if len(in) >= 2 && len(out) >= 2 {
+		p.gate.ProcessStereoBuffer(in[0][from:to], in[1][from:to], out[0][from:to], out[1][from:to])
+		return
+	}
+	p.gate.ProcessBuffer(in[0][from:to], out[0][from:to])
+}
+
+// processMIDI restarts the pattern at every note on, on the sample the
+// note arrives
+func (p *TranceGateProcessor) processMIDI(ctx *process.Context, in, out [][]float32, numSamples int) {
+	pos := 0
+	for _, event := range ctx.GetAllInputEvents() {
+		e, ok := event.(midi.NoteOnEvent)
+		if !ok || e.Velocity == 0 {
+			continue
+		}
+		offset := max(pos, min(numSamples, int(e.SampleOffset())))
+		p.gateRange(in, out, pos, offset)
+		pos = offset
+		p.gate.Restart()
+	}
+	p.gateRange(in, out, pos, numSamples)
+}
+
+// processSidechain restarts the pattern whenever the sidechain level rises
+// through the threshold. The key must drop keyRearm dB below the threshold
+// before it can trigger again, so one hit only restarts the pattern once.
+func (p *TranceGateProcessor) processSidechain(ctx *process.Context, in, out [][]float32, numSamples int) {
+	key := ctx.InputBus(1)
+	if len(key) == 0 {
+		p.gateRange(in, out, 0, numSamples)
+		return
+	}
+
+	threshold := gain.DbToLinear32(float32(ctx.ParamPlain(ParamThreshold)))
+	rearm := threshold * gain.DbToLinear32(-keyRearm)
+	pos := 0
+	for i := 0; i < numSamples; i++ {
+		x := key[0][i]
+		if len(key) >= 2 {
+			x = float32(math.Max(math.Abs(float64(x)), math.Abs(float64(key[1][i]))))
+		}
+		level := p.key.Detect(x)
+		if p.keyArmed && level >= threshold {
+			p.gateRange(in, out, pos, i)
+			pos = i
+			p.gate.Restart()
+			p.keyArmed = false
+		} else if !p.keyArmed && level < rearm {
+			p.keyArmed = true
+		}
+	}
+	p.gateRange(in, out, pos, numSamples)
+}
+
+// ProcessAudio gates the main input
+func (p *TranceGateProcessor) ProcessAudio(ctx *process.Context) {
+	if !p.active {
+		ctx.PassThrough()
+		ctx.ClearInputEvents()
+		return
+	}
+
+	in := ctx.InputBus(0)
+	out := ctx.OutputBus(0)
+	numSamples := ctx.NumSamples()
+	if numSamples == 0 || len(in) == 0 || len(out) == 0 {
+		ctx.ClearInputEvents()
+		return
+	}
+	p.updateParameters(ctx)
+
+	mode := int(ctx.ParamPlain(ParamTrigger))
+	p.followTransport(ctx, mode)
+	switch mode {
+	case triggerMIDI:
+		p.processMIDI(ctx, in, out, numSamples)
+	case triggerSidechain:
+		p.processSidechain(ctx, in, out, numSamples)
+	default:
+		p.gateRange(in, out, 0, numSamples)
+	}
+	ctx.ClearInputEvents()
+}
+
+// GetParameters returns the parameter registry
+func (p *TranceGateProcessor) GetParameters() *param.Registry {
+	return p.params
+}
+
+// GetBuses returns the bus configuration
+func (p *TranceGateProcessor) GetBuses() *bus.Configuration {
+	return p.buses
+}
+
+// SetActive is called when processing starts/stops
+func (p *TranceGateProcessor) SetActive(active bool) error {
+	p.active = active
+	if !active && p.gate != nil {
+		p.gate.Reset()
+		p.key.Reset()
+		p.keyArmed = true
+	}
+	return nil
+}
+
+// GetLatencySamples returns the plugin latency in samples
+func (p *TranceGateProcessor) GetLatencySamples() int32 {
+	return 0
+}
+
+// GetTailSamples returns the tail length in samples
+func (p *TranceGateProcessor) GetTailSamples() int32 {
+	return 0
+}
@@ -55,11 +55,16 @@ const (
 	ParamGainReduction
 )
 
+// Transient shaper settings
+const (
+	transientMaxGain     = 12.0 // dB at 100% attack or sustain
+	transientDetectorHPF = 60.0 // Hz
+)
+
 // TransientShaperProcessor implements the audio processing
 type TransientShaperProcessor struct {
 	// DSP
-	expanderL *dynamics.Expander
-	expanderR *dynamics.Expander
+	shaper *dynamics.TransientShaper
 	
 	// Parameters
 	params *param.Registry
@@ -87,8 +92,8 @@ func NewTransientShaperProcessor() *TransientShaperProcessor {
 	p := &TransientShaperProcessor{
 		params:     param.NewRegistry(),
 		buses:      bus.NewStereoConfiguration(),
-		attack:     0.5,
-		sustain:    0.5,
+		attack:     0.0,
+		sustain:    0.0,
 		mix:        1.0,
 		outputGain: 1.0,
 	}
@@ -174,12 +179,10 @@ func (p *TransientShaperProcessor) initializeParameters() {
 func (p *TransientShaperProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
 	p.sampleRate = sampleRate
 	
-	// Create expanders for stereo processing
-	p.expanderL = dynamics.NewExpander(sampleRate)
-	p.expanderR = dynamics.NewExpander(sampleRate)
-	
-	// Configure expanders for transient shaping
-	p.configureExpanders()
+	// Create a linked stereo shaper that ignores sub-bass in detection
+	p.shaper = dynamics.NewTransientShaper(sampleRate)
+	p.shaper.SetSidechainFilter(true, transientDetectorHPF)
+	p.configureShaper()
 	
 	// Allocate processing buffers
 	bufferSize := int(maxBlockSize)
@@ -191,55 +194,10 @@ func (p *TransientShaperProcessor) Initialize(sampleRate float64, maxBlockSize i
 	return nil
 }
 
-// configureExpanders sets up the expanders for transient shaping
-func (p *TransientShaperProcessor) configureExpanders() {
-	// Attack: Use expander to enhance transients
-	// Positive attack = lower threshold to expand quiet parts after transients
-	// Negative attack = higher threshold to reduce transients
-	
-	attackFactor := p.attack
-	
-	if attackFactor >= 0 {
-		// Enhance transients: expand the quiet parts after the transient
-		threshold := -30.0 + (attackFactor * 20.0) // -30dB to -10dB
-		ratio := 1.5 + (attackFactor * 2.5)        // 1.5:1 to 4:1
-		attack := 0.0001                           // Very fast attack (0.1ms)
-		release := 0.01 + (attackFactor * 0.04)    // 10ms to 50ms
-		
-		p.expanderL.SetThreshold(threshold)
-		p.expanderL.SetRatio(ratio)
-		p.expanderL.SetAttack(attack)
-		p.expanderL.SetRelease(release)
-		p.expanderL.SetKnee(2.0)
-		p.expanderL.SetRange(-20.0)
-		
-		p.expanderR.SetThreshold(threshold)
-		p.expanderR.SetRatio(ratio)
-		p.expanderR.SetAttack(attack)
-		p.expanderR.SetRelease(release)
-		p.expanderR.SetKnee(2.0)
-		p.expanderR.SetRange(-20.0)
-	} else {
-		// Reduce transients: use as upward expander (inverted)
-		threshold := -10.0 + (attackFactor * 20.0) // -10dB to -30dB
-		ratio := 1.5 - (attackFactor * 0.4)        // 1.5:1 to 1.1:1
-		attack := 0.0001                           // Very fast attack
-		release := 0.05                            // 50ms release
-		
-		p.expanderL.SetThreshold(threshold)
-		p.expanderL.SetRatio(ratio)
-		p.expanderL.SetAttack(attack)
-		p.expanderL.SetRelease(release)
-		p.expanderL.SetKnee(2.0)
-		p.expanderL.SetRange(-10.0)
-		
-		p.expanderR.SetThreshold(threshold)
-		p.expanderR.SetRatio(ratio)
-		p.expanderR.SetAttack(attack)
-		p.expanderR.SetRelease(release)
-		p.expanderR.SetKnee(2.0)
-		p.expanderR.SetRange(-10.0)
-	}
+// configureShaper maps the attack and sustain amounts to shaper gains
+func (p *TransientShaperProcessor) configureShaper() {
+	p.shaper.SetAttack(p.attack * transientMaxGain)
+	p.shaper.SetSustain(p.sustain * transientMaxGain)
 }
 
 // ProcessAudio processes audio
@@ -269,12 +227,9 @@ func (p *TransientShaperProcessor) ProcessAudio(ctx *process.Context) {
 	copy(p.dryBufferL[:numSamples], ctx.Input[0][:numSamples])
 	copy(p.dryBufferR[:numSamples], ctx.Input[1][:numSamples])
 	
-	// Process through expanders
-	p.expanderL.ProcessBuffer(ctx.Input[0][:numSamples], p.wetBufferL[:numSamples])
-	p.expanderR.ProcessBuffer(ctx.Input[1][:numSamples], p.wetBufferR[:numSamples])
-	
-	// Apply sustain control (simple gain adjustment on the processed signal)
-	sustainGain := float32(1.0 + p.sustain*0.5) // 0.5 to 1.5
+	// Shape transients and sustain with one linked detector
+	p.shaper.ProcessStereo(ctx.Input[0][:numSamples], ctx.Input[1][:numSamples],
+		p.wetBufferL[:numSamples], p.wetBufferR[:numSamples])
 	
 	// Mix dry and wet signals
 	mix := float32(p.mix)
@@ -282,38 +237,28 @@ func (p *TransientShaperProcessor) ProcessAudio(ctx *process.Context) {
 	outputGain := float32(p.outputGain)
 	
 	for i := 0; i < numSamples; i++ {
-		// Apply sustain to wet signal
-		p.wetBufferL[i] *= sustainGain
-		p.wetBufferR[i] *= sustainGain
-		
 		// Mix and apply output gain
 		ctx.Output[0][i] = (p.dryBufferL[i]*dryMix + p.wetBufferL[i]*mix) * outputGain
 		ctx.Output[1][i] = (p.dryBufferR[i]*dryMix + p.wetBufferR[i]*mix) * outputGain
 	}
 	
-	// Update gain reduction meter (use average of both channels)
-	grL := p.expanderL.GetGainReduction()
-	grR := p.expanderR.GetGainReduction()
-	avgGR := (grL + grR) / 2.0
-	
 	// For read-only parameters, we update the value directly
 	if grParam := p.params.Get(ParamGainReduction); grParam != nil {
-		grParam.SetPlainValue(avgGR)
+		grParam.SetPlainValue(-p.shaper.GetGainReduction())
 	}
 }
 
 // updateParameters checks for parameter changes
 func (p *TransientShaperProcessor) updateParameters(ctx *process.Context) {
-	// Check attack parameter
+	// Check attack and sustain parameters
 	newAttack := ctx.ParamPlain(ParamAttack)
-	if newAttack != p.attack {
+	newSustain := ctx.ParamPlain(ParamSustain)
+	if newAttack != p.attack || newSustain != p.sustain {
 		p.attack = newAttack
-		p.configureExpanders()
+		p.sustain = newSustain
+		p.configureShaper()
 	}
 	
-	// Check sustain parameter
-	p.sustain = ctx.ParamPlain(ParamSustain)
-	
 	// Check mix parameter
 	p.mix = ctx.Param(ParamMix)
 	
@@ -335,12 +280,9 @@ func (p *TransientShaperProcessor) GetBuses() *bus.Configuration {
 func (p *TransientShaperProcessor) SetActive(active bool) error {
 	p.active = active
 	if !active {
-		// Reset expanders when deactivated
-		if p.expanderL != nil {
-			p.expanderL.Reset()
-		}
-		if p.expanderR != nil {
-			p.expanderR.Reset()
+		// Reset the shaper when deactivated
+		if p.shaper != nil {
+			p.shaper.Reset()
 		}
 	}
 	return nil
@@ -353,6 +295,6 @@ func (p *TransientShaperProcessor) GetLatencySamples() int32 {
 
 // GetTailSamples returns the tail length in samples
 func (p *TransientShaperProcessor) GetTailSamples() int32 {
-	// Return release time in samples
-	return int32(0.05 * p.sampleRate) // 50ms tail
+	// The gain follows the input with no release
+	return 0
 }
\ No newline at end of file
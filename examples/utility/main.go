@@ -0,0 +1,185 @@
+package main
+
+import (
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+	"github.com/justyntemme/vst3go/pkg/dsp/utility"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	vst3plugin "github.com/justyntemme/vst3go/pkg/plugin"
+
+	// Import C bridge - required for VST3 plugin to work
+	_ "github.com/justyntemme/vst3go/pkg/plugin/cbridge"
+)
+
+// UtilityPlugin implements the Plugin interface
+type UtilityPlugin struct{}
+
+func (u *UtilityPlugin) GetInfo() plugin.Info {
+	return plugin.Info{
+		ID:       "com.vst3go.examples.utility",
+		Name:     "Utility",
+		Version:  "1.0.0",
+		Vendor:   "VST3Go Examples",
+		Category: "Fx|Tools",
+	}
+}
+
+func (u *UtilityPlugin) CreateProcessor() vst3plugin.Processor {
+	return NewUtilityProcessor()
+}
+
+const (
+	ParamGain uint32 = iota
+	ParamTrimLeft
+	ParamTrimRight
+	ParamInvertLeft
+	ParamInvertRight
+	ParamSwap
+	ParamMono
+	ParamWidth
+	ParamMid
+	ParamSide
+	ParamPan
+	ParamPanLaw
+	ParamBypass
+	ParamMeters // First of the output meter parameters
+)
+
+// UtilityProcessor wraps utility.ChannelUtility
+type UtilityProcessor struct {
+	params  *param.Registry
+	buses   *bus.Configuration
+	channel *utility.ChannelUtility
+	bypass  *process.SoftBypass
+	meters  *process.OutputMeters
+}
+
+func NewUtilityProcessor() *UtilityProcessor {
+	p := &UtilityProcessor{
+		params: param.NewRegistry(),
+		buses:  bus.NewStereoConfiguration(),
+	}
+
+	p.params.Add(
+		param.GainParameter(ParamGain, "Gain").Build(),
+		param.New(ParamTrimLeft, "Trim L").Range(-24, 24).Default(0).Unit("dB").
+			Formatter(param.DecibelFormatter, param.DecibelParser).Build(),
+		param.New(ParamTrimRight, "Trim R").Range(-24, 24).Default(0).Unit("dB").
+			Formatter(param.DecibelFormatter, param.DecibelParser).Build(),
+		param.New(ParamInvertLeft, "Invert L").Toggle().Build(),
+		param.New(ParamInvertRight, "Invert R").Toggle().Build(),
+		param.New(ParamSwap, "Swap L/R").Toggle().Build(),
+		param.New(ParamMono, "Mono").Toggle().Build(),
+		param.New(ParamWidth, "Width").Range(0, 200).Default(100).Unit("%").
+			Formatter(param.PercentFormatter, param.PercentParser).Build(),
+		param.New(ParamMid, "Mid").Range(-24, 24).Default(0).Unit("dB").
+			Formatter(param.DecibelFormatter, param.DecibelParser).Build(),
+		param.New(ParamSide, "Side").Range(-24, 24).Default(0).Unit("dB").
+			Formatter(param.DecibelFormatter, param.DecibelParser).Build(),
+		param.PanParameter(ParamPan, "Balance").Build(),
+		param.Choice(ParamPanLaw, "Pan Law", []param.ChoiceOption{
+			{Value: 0, Name: "Linear", Aliases: []string{"-6 dB"}},
+			{Value: 1, Name: "Constant Power", Aliases: []string{"-3 dB", "equal power"}},
+			{Value: 2, Name: "Balanced", Aliases: []string{"-4.5 dB"}},
+		}).Default(1).Build(),
+		param.BypassParameter(ParamBypass, "Bypass").Bypass().Build(),
+	)
+
+	// Peak and clip indicators for both output channels
+	p.meters, _ = process.NewOutputMeters(p.params, ParamMeters, 2, 44100)
+
+	return p
+}
+
+func (p *UtilityProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	p.channel = utility.NewChannelUtility(sampleRate)
+	p.bypass = process.NewSoftBypass(2, int(maxBlockSize), sampleRate)
+	p.bypass.BindParameter(p.params.Get(ParamBypass))
+	p.meters.SetSampleRate(sampleRate)
+	return nil
+}
+
+func (p *UtilityProcessor) ProcessAudio(ctx *process.Context) {
+	p.bypass.Process(ctx, p.processUtility)
+	p.meters.Process(ctx)
+}
+
+// HandlesBypass keeps the framework's bypass out of the way: the utility
+// bypasses itself so the output meters keep running while bypassed
+func (p *UtilityProcessor) HandlesBypass() bool {
+	return true
+}
+
+func (p *UtilityProcessor) processUtility(ctx *process.Context) {
+	p.updateSettings(ctx)
+
+	if ctx.NumInputChannels() < 2 || ctx.NumOutputChannels() < 2 {
+		ctx.PassThrough()
+		return
+	}
+	n := ctx.NumSamples()
+	left, right := ctx.Output[0][:n], ctx.Output[1][:n]
+	copy(left, ctx.Input[0][:n])
+	copy(right, ctx.Input[1][:n])
+	p.channel.ProcessStereo(left, right)
+}
+
+// updateSettings copies the block's parameter values into the utility
+func (p *UtilityProcessor) updateSettings(ctx *process.Context) {
+	u := p.channel
+	u.SetGain(ctx.ParamPlain(ParamGain))
+	u.SetTrim(0, ctx.ParamPlain(ParamTrimLeft))
+	u.SetTrim(1, ctx.ParamPlain(ParamTrimRight))
+	u.SetInvert(0, ctx.ParamPlain(ParamInvertLeft) > 0.5)
+	u.SetInvert(1, ctx.ParamPlain(ParamInvertRight) > 0.5)
+	u.SetSwap(ctx.ParamPlain(ParamSwap) > 0.5)
+	u.SetMono(ctx.ParamPlain(ParamMono) > 0.5)
+	u.SetWidth(ctx.ParamPlain(ParamWidth) / 100)
+	u.SetMidSideGain(ctx.ParamPlain(ParamMid), ctx.ParamPlain(ParamSide))
+	u.SetPan(float32(ctx.ParamPlain(ParamPan) / 100))
+	u.SetPanLaw(pan.Law(ctx.ParamPlain(ParamPanLaw)))
+}
+
+func (p *UtilityProcessor) GetParameters() *param.Registry {
+	return p.params
+}
+
+func (p *UtilityProcessor) GetBuses() *bus.Configuration {
+	return p.buses
+}
+
+func (p *UtilityProcessor) SetActive(active bool) error {
+	if active {
+		if p.bypass != nil {
+			p.bypass.Reset()
+		}
+		if p.channel != nil {
+			p.channel.Reset()
+		}
+		p.meters.Reset()
+	}
+	return nil
+}
+
+func (p *UtilityProcessor) GetLatencySamples() int32 {
+	return 0
+}
+
+func (p *UtilityProcessor) GetTailSamples() int32 {
+	return 0
+}
+
+func init() {
+	vst3plugin.SetFactoryInfo(vst3plugin.FactoryInfo{
+		Vendor: "VST3Go Examples",
+		URL:    "https://github.com/vst3go/examples",
+		Email:  "examples@vst3go.com",
+	})
+
+	vst3plugin.Register(&UtilityPlugin{})
+}
+
+// Required for c-shared build mode
+func main() {}
@@ -181,6 +181,7 @@ func (p *VintageChorusProcessor) initializeParameters() {
 		param.New(ParamVoices, "Voices").
 			Range(1.0, 4.0).
 			Default(2.0).
+			StepSize(1).
 			Unit("").
 			Formatter(func(value float64) string {
 				return fmt.Sprintf("%d", int(value))
@@ -209,6 +210,14 @@ func (p *VintageChorusProcessor) Initialize(sampleRate float64, maxBlockSize int
 	return nil
 }
 
+// SampleRateChanged follows a new host sample rate without rebuilding the
+// chorus, so its LFOs keep their phase
+func (p *VintageChorusProcessor) SampleRateChanged(sampleRate float64, maxBlockSize int32) error {
+	p.sampleRate = sampleRate
+	p.chorus.SetSampleRate(sampleRate)
+	return nil
+}
+
 // configureChorus sets up the chorus with current parameters
 func (p *VintageChorusProcessor) configureChorus() {
 	p.chorus.SetRate(p.rate)
@@ -311,9 +320,9 @@ func (p *VintageChorusProcessor) GetBuses() *bus.Configuration {
 func (p *VintageChorusProcessor) SetActive(active bool) error {
 	p.active = active
 	if !active {
-		// Reset chorus when deactivated
+		// Silence the chorus when deactivated; the LFOs keep their phase
 		if p.chorus != nil {
-			p.chorus.Reset()
+			p.chorus.Clear()
 		}
 	}
 	return nil
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"github.com/justyntemme/vst3go/pkg/dsp/dynamics"
+	"github.com/justyntemme/vst3go/pkg/dsp/gain"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	vst3plugin "github.com/justyntemme/vst3go/pkg/plugin"
+
+	// Import C bridge - required for VST3 plugin to work
+	_ "github.com/justyntemme/vst3go/pkg/plugin/cbridge"
+)
+
+func init() {
+	vst3plugin.SetFactoryInfo(vst3plugin.FactoryInfo{
+		Vendor: "VST3Go Examples",
+		URL:    "https://github.com/vst3go/examples",
+		Email:  "examples@vst3go.com",
+	})
+
+	vst3plugin.Register(&VocalRiderPlugin{})
+}
+
+// Required for c-shared build mode
+func main() {}
+
+// VocalRiderPlugin implements the Plugin interface
+type VocalRiderPlugin struct{}
+
+func (p *VocalRiderPlugin) GetInfo() plugin.Info {
+	return plugin.Info{
+		ID:       "com.vst3go.examples.vocalrider",
+		Name:     "Vocal Rider",
+		Version:  "1.0.0",
+		Vendor:   "VST3Go Examples",
+		Category: "Fx|Dynamics",
+	}
+}
+
+func (p *VocalRiderPlugin) CreateProcessor() vst3plugin.Processor {
+	return NewVocalRiderProcessor()
+}
+
+// Parameter IDs
+const (
+	ParamTarget uint32 = iota
+	ParamRange
+	ParamSpeed
+	ParamLookahead
+	ParamFloor
+	ParamDetector
+	ParamAutomation
+	ParamRide
+)
+
+// Automation modes
+const (
+	automationOff   = 0 // Ride without reporting the gain
+	automationWrite = 1 // Ride and send the gain to the host to record
+	automationRead  = 2 // Apply the recorded Ride automation instead
+)
+
+// rideChunk is how often, in samples, the ride is written to the host
+const rideChunk = 64
+
+// VocalRiderProcessor rides the level of a vocal toward a target
+type VocalRiderProcessor struct {
+	rider  *dynamics.Rider
+	params *param.Registry
+	buses  *bus.Configuration
+
+	sampleRate float64
+	active     bool
+}
+
+// NewVocalRiderProcessor creates a new processor
+func NewVocalRiderProcessor() *VocalRiderProcessor {
+	p := &VocalRiderProcessor{
+		params: param.NewRegistry(),
+		buses:  bus.NewStereoConfiguration(),
+	}
+
+	p.params.Add(
+		param.ThresholdParameter(ParamTarget, "Target", -40, 0, dynamics.DefaultRiderTarget).Build(),
+		param.New(ParamRange, "Range").
+			Range(0, 24).
+			Default(dynamics.DefaultRiderRange).
+			Unit("dB").
+			Formatter(param.DecibelFormatter, param.DecibelParser).
+			Build(),
+		param.TimeParameter(ParamSpeed, "Speed", 50, 5000, dynamics.DefaultRiderSpeed*1000).Build(),
+		param.TimeParameter(ParamLookahead, "Lookahead", 0, 200, dynamics.DefaultRiderLookahead*1000).Build(),
+		param.ThresholdParameter(ParamFloor, "Floor", -80, -20, dynamics.DefaultRiderFloor).Build(),
+		param.Choice(ParamDetector, "Detector", []param.ChoiceOption{
+			{Value: 0, Name: "RMS"},
+			{Value: 1, Name: "LUFS", Aliases: []string{"loudness"}},
+		}).Build(),
+		param.Choice(ParamAutomation, "Automation", []param.ChoiceOption{
+			{Value: automationOff, Name: "Off"},
+			{Value: automationWrite, Name: "Write", Aliases: []string{"record"}},
+			{Value: automationRead, Name: "Read", Aliases: []string{"play"}},
+		}).Build(),
+
+		// The applied gain. Writable so the host can record and play it
+		// back as automation.
+		param.New(ParamRide, "Ride").
+			Range(-24, 24).
+			Default(0).
+			Unit("dB").
+			Formatter(param.DecibelFormatter, param.DecibelParser).
+			Build(),
+	)
+
+	return p
+}
+
+// Initialize is called when the plugin is created
+func (p *VocalRiderProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	p.sampleRate = sampleRate
+	p.rider = dynamics.NewRider(sampleRate, 2)
+	return nil
+}
+
+// updateParameters applies the block's parameter values to the rider
+func (p *VocalRiderProcessor) updateParameters(ctx *process.Context) {
+	p.rider.SetTarget(ctx.ParamPlain(ParamTarget))
+	p.rider.SetRange(ctx.ParamPlain(ParamRange))
+	p.rider.SetSpeed(ctx.ParamPlain(ParamSpeed) / 1000.0)
+	p.rider.SetLookahead(ctx.ParamPlain(ParamLookahead) / 1000.0)
+	p.rider.SetFloor(ctx.ParamPlain(ParamFloor))
+	p.rider.SetDetector(dynamics.RiderDetector(ctx.ParamPlain(ParamDetector)))
+}
+
+// ProcessAudio processes audio
+func (p *VocalRiderProcessor) ProcessAudio(ctx *process.Context) {
+	if !p.active {
+		ctx.PassThrough()
+		return
+	}
+
+	numSamples := ctx.NumSamples()
+	if numSamples == 0 || len(ctx.Input) < 2 || len(ctx.Output) < 2 {
+		return
+	}
+	p.updateParameters(ctx)
+	copy(ctx.Output[0][:numSamples], ctx.Input[0][:numSamples])
+	copy(ctx.Output[1][:numSamples], ctx.Input[1][:numSamples])
+
+	mode := int(ctx.ParamPlain(ParamAutomation))
+	if mode == automationRead {
+		// Play back the recorded ride. With no range the rider only adds its
+		// look-ahead delay, which keeps the reported latency true.
+		p.rider.SetRange(0)
+		p.rider.ProcessChannels(ctx.Output[:2])
+		rideGain := gain.DbToLinear32(float32(ctx.ParamPlain(ParamRide)))
+		for ch := 0; ch < 2; ch++ {
+			for i, v := range ctx.Output[ch][:numSamples] {
+				ctx.Output[ch][i] = v * rideGain
+			}
+		}
+		return
+	}
+
+	// Ride in chunks so the gain can be reported at a steady rate
+	ctx.SetModulationOutput(mode == automationWrite)
+	var chunk [2][]float32
+	for start := 0; start < numSamples; start += rideChunk {
+		end := min(start+rideChunk, numSamples)
+		chunk[0] = ctx.Output[0][start:end]
+		chunk[1] = ctx.Output[1][start:end]
+		p.rider.ProcessChannels(chunk[:])
+		ctx.WriteModulationPlain(ParamRide, p.rider.GetGain(), start)
+	}
+}
+
+// GetParameters returns the parameter registry
+func (p *VocalRiderProcessor) GetParameters() *param.Registry {
+	return p.params
+}
+
+// GetBuses returns the bus configuration
+func (p *VocalRiderProcessor) GetBuses() *bus.Configuration {
+	return p.buses
+}
+
+// SetActive is called when processing starts/stops
+func (p *VocalRiderProcessor) SetActive(active bool) error {
+	p.active = active
+	if !active && p.rider != nil {
+		p.rider.Reset()
+	}
+	return nil
+}
+
+// GetLatencySamples returns the look-ahead delay in samples
+func (p *VocalRiderProcessor) GetLatencySamples() int32 {
+	if p.rider == nil {
+		return 0
+	}
+	return int32(p.rider.GetLatencySamples())
+}
+
+// GetTailSamples returns the tail length in samples
+func (p *VocalRiderProcessor) GetTailSamples() int32 {
+	return p.GetLatencySamples()
+}
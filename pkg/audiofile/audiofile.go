@@ -0,0 +1,163 @@
+// Package audiofile reads and writes WAV and AIFF files, either streamed in
+// blocks or loaded into memory, with optional sample-rate conversion on load.
+package audiofile
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for files or encodings that cannot be handled
+	ErrUnsupportedFormat = errors.New("unsupported audio format")
+	// ErrClosed is returned when using a closed reader or writer
+	ErrClosed = errors.New("audio file closed")
+	// ErrChannelMismatch is returned when a buffer has the wrong number of channels
+	ErrChannelMismatch = errors.New("channel count mismatch")
+)
+
+// FileFormat is an audio container format
+type FileFormat int
+
+const (
+	// FormatWAV is RIFF/WAVE
+	FormatWAV FileFormat = iota
+	// FormatAIFF is AIFF, or AIFF-C for floating point data
+	FormatAIFF
+)
+
+// String returns the format name
+func (f FileFormat) String() string {
+	switch f {
+	case FormatWAV:
+		return "WAV"
+	case FormatAIFF:
+		return "AIFF"
+	default:
+		return "unknown"
+	}
+}
+
+// FormatFromPath guesses the file format from a file extension
+func FormatFromPath(path string) (FileFormat, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav", ".wave":
+		return FormatWAV, true
+	case ".aif", ".aiff", ".aifc":
+		return FormatAIFF, true
+	}
+	return FormatWAV, false
+}
+
+// Encoding is the sample encoding within a file
+type Encoding int
+
+const (
+	PCM8    Encoding = iota // 8-bit integer (unsigned in WAV, signed in AIFF)
+	PCM16                   // 16-bit integer
+	PCM24                   // 24-bit integer
+	PCM32                   // 32-bit integer
+	Float32                 // 32-bit IEEE float
+	Float64                 // 64-bit IEEE float
+)
+
+// BitDepth returns the number of bits per sample
+func (e Encoding) BitDepth() int {
+	switch e {
+	case PCM8:
+		return 8
+	case PCM16:
+		return 16
+	case PCM24:
+		return 24
+	case PCM32, Float32:
+		return 32
+	case Float64:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// BytesPerSample returns the size of one sample in bytes
+func (e Encoding) BytesPerSample() int {
+	return e.BitDepth() / 8
+}
+
+// IsFloat returns true for floating point encodings
+func (e Encoding) IsFloat() bool {
+	return e == Float32 || e == Float64
+}
+
+// String returns the encoding name
+func (e Encoding) String() string {
+	switch e {
+	case PCM8:
+		return "PCM8"
+	case PCM16:
+		return "PCM16"
+	case PCM24:
+		return "PCM24"
+	case PCM32:
+		return "PCM32"
+	case Float32:
+		return "Float32"
+	case Float64:
+		return "Float64"
+	default:
+		return "unknown"
+	}
+}
+
+// Info describes an audio file
+type Info struct {
+	Format     FileFormat
+	Encoding   Encoding
+	SampleRate float64
+	Channels   int
+	Frames     int64 // Length in sample frames
+}
+
+// Duration returns the length in seconds
+func (i Info) Duration() float64 {
+	if i.SampleRate <= 0 {
+		return 0
+	}
+	return float64(i.Frames) / i.SampleRate
+}
+
+// Instrument is sampler metadata stored with the audio
+type Instrument struct {
+	RootKey   int   // MIDI unity note, -1 if unknown
+	LoopStart int64 // Loop start in frames, -1 if none
+	LoopEnd   int64 // Loop end in frames (exclusive), -1 if none
+}
+
+// Audio holds decoded audio in memory
+type Audio struct {
+	Data       [][]float32 // One slice per channel
+	SampleRate float64
+}
+
+// NewAudio allocates silent audio with the given layout
+func NewAudio(channels, frames int, sampleRate float64) *Audio {
+	data := make([][]float32, channels)
+	for ch := range data {
+		data[ch] = make([]float32, frames)
+	}
+	return &Audio{Data: data, SampleRate: sampleRate}
+}
+
+// Len returns the length in sample frames
+func (a *Audio) Len() int {
+	if len(a.Data) == 0 {
+		return 0
+	}
+	return len(a.Data[0])
+}
+
+// NumChannels returns the number of channels
+func (a *Audio) NumChannels() int {
+	return len(a.Data)
+}
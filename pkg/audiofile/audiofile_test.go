@@ -0,0 +1,288 @@
+package audiofile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// memFile is an in-memory io.ReadWriteSeeker
+type memFile struct {
+	data []byte
+	pos  int64
+}
+
+func (m *memFile) Write(p []byte) (int, error) {
+	if end := m.pos + int64(len(p)); end > int64(len(m.data)) {
+		m.data = append(m.data, make([]byte, end-int64(len(m.data)))...)
+	}
+	copy(m.data[m.pos:], p)
+	m.pos += int64(len(p))
+	return len(p), nil
+}
+
+func (m *memFile) Read(p []byte) (int, error) {
+	if m.pos >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[m.pos:])
+	m.pos += int64(n)
+	return n, nil
+}
+
+func (m *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += m.pos
+	case io.SeekEnd:
+		offset += int64(len(m.data))
+	}
+	m.pos = offset
+	return offset, nil
+}
+
+// testSignal returns a stereo sine pair, including full-scale peaks
+func testSignal(frames int) [][]float32 {
+	data := [][]float32{make([]float32, frames), make([]float32, frames)}
+	for i := 0; i < frames; i++ {
+		data[0][i] = float32(0.9 * math.Sin(2*math.Pi*float64(i)/50))
+		data[1][i] = float32(-0.5 * math.Sin(2*math.Pi*float64(i)/20))
+	}
+	data[0][0], data[1][0] = 1, -1
+	return data
+}
+
+func TestRoundTrip(t *testing.T) {
+	src := testSignal(1001) // Odd length exercises chunk padding
+
+	tolerances := map[Encoding]float64{
+		PCM8:    1.0 / 64,
+		PCM16:   1.0 / 16384,
+		PCM24:   1.0 / 4194304,
+		PCM32:   1e-7,
+		Float32: 0,
+		Float64: 0,
+	}
+
+	for _, format := range []FileFormat{FormatWAV, FormatAIFF} {
+		for enc, tol := range tolerances {
+			f := &memFile{}
+			w, err := NewWriter(f, format, enc, 44100, 2)
+			if err != nil {
+				t.Fatalf("%s %s: NewWriter failed: %v", format, enc, err)
+			}
+			if err := w.Write(src); err != nil {
+				t.Fatalf("%s %s: Write failed: %v", format, enc, err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("%s %s: Close failed: %v", format, enc, err)
+			}
+			if len(f.data)%2 != 0 {
+				t.Errorf("%s %s: file length %d is not padded", format, enc, len(f.data))
+			}
+
+			f.pos = 0
+			audio, err := Decode(f)
+			if err != nil {
+				t.Fatalf("%s %s: Decode failed: %v", format, enc, err)
+			}
+			if audio.SampleRate != 44100 || audio.NumChannels() != 2 || audio.Len() != len(src[0]) {
+				t.Fatalf("%s %s: got %g Hz, %d channels, %d frames", format, enc,
+					audio.SampleRate, audio.NumChannels(), audio.Len())
+			}
+
+			for ch := range src {
+				for i := range src[ch] {
+					// Integer encodings clip +1.0 to just under full scale
+					want := math.Min(float64(src[ch][i]), 1-tol)
+					if diff := math.Abs(float64(audio.Data[ch][i]) - want); diff > tol*1.01 {
+						t.Fatalf("%s %s: channel %d frame %d: got %f, want %f", format, enc,
+							ch, i, audio.Data[ch][i], src[ch][i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestStreamingRead(t *testing.T) {
+	src := testSignal(1000)
+	f := &memFile{}
+	w, _ := NewWriter(f, FormatWAV, Float32, 48000, 2)
+	w.Write(src)
+	w.Close()
+
+	f.pos = 0
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	if info := r.Info(); info.Frames != 1000 || info.Encoding != Float32 || info.Duration() != 1000.0/48000 {
+		t.Fatalf("Unexpected info: %+v", info)
+	}
+
+	block := [][]float32{make([]float32, 128), make([]float32, 128)}
+	total := 0
+	for {
+		n, err := r.Read(block)
+		for i := 0; i < n; i++ {
+			if block[1][i] != src[1][total+i] {
+				t.Fatalf("Frame %d mismatch", total+i)
+			}
+		}
+		total += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+	}
+	if total != 1000 {
+		t.Errorf("Expected 1000 frames, read %d", total)
+	}
+
+	if err := r.SeekFrame(900); err != nil {
+		t.Fatalf("SeekFrame failed: %v", err)
+	}
+	if n, _ := r.Read(block); n != 100 || block[0][0] != src[0][900] {
+		t.Errorf("Expected 100 frames from 900, got %d", n)
+	}
+
+	if _, err := r.Read(block[:1]); !errors.Is(err, ErrChannelMismatch) {
+		t.Errorf("Expected ErrChannelMismatch, got %v", err)
+	}
+}
+
+func TestReadUnpatchedHeader(t *testing.T) {
+	// A recorder that crashed leaves zero sizes in the header
+	f := &memFile{}
+	w, _ := NewWriter(f, FormatWAV, PCM16, 44100, 2)
+	w.Write(testSignal(500))
+
+	audio, err := Decode(bytes.NewReader(f.data))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if audio.Len() != 0 {
+		t.Errorf("Expected the zero data size to be honoured, got %d frames", audio.Len())
+	}
+
+	// An oversized data chunk is clamped to the end of the file
+	data := append([]byte(nil), f.data...)
+	copy(data[40:44], []byte{0xFF, 0xFF, 0xFF, 0xFF})
+	audio, err = Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if audio.Len() != 500 {
+		t.Errorf("Expected 500 frames, got %d", audio.Len())
+	}
+}
+
+func TestMetadataChunks(t *testing.T) {
+	f := &memFile{}
+	w, _ := NewWriter(f, FormatWAV, PCM16, 44100, 1)
+	w.Write([][]float32{make([]float32, 100)})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Append a smpl chunk with root key 60 and a loop over frames 10-49
+	smpl := make([]byte, 36+24)
+	binary.LittleEndian.PutUint32(smpl[12:16], 60)
+	binary.LittleEndian.PutUint32(smpl[28:32], 1)
+	binary.LittleEndian.PutUint32(smpl[36+8:36+12], 10)
+	binary.LittleEndian.PutUint32(smpl[36+12:36+16], 49)
+	data := append(f.data, "smpl"...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(smpl)))
+	data = append(data, smpl...)
+
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst := r.Instrument(); inst.RootKey != 60 || inst.LoopStart != 10 || inst.LoopEnd != 50 {
+		t.Errorf("Instrument = %+v", inst)
+	}
+	if !bytes.Equal(r.Chunk("smpl"), smpl) || r.Chunk("clm ") != nil {
+		t.Error("Chunk did not return the stored metadata")
+	}
+
+	// Loops past the end of the audio are ignored
+	binary.LittleEndian.PutUint32(data[len(data)-24+12:], 100)
+	r, _ = NewReader(bytes.NewReader(data))
+	if inst := r.Instrument(); inst.RootKey != 60 || inst.LoopStart != -1 {
+		t.Errorf("Instrument with a bad loop = %+v", inst)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if _, err := Decode(bytes.NewReader([]byte("OggS\x00\x00\x00\x00\x00\x00\x00\x00"))); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+	if _, err := NewWriter(&memFile{}, FormatWAV, PCM16, 44100, 0); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for zero channels, got %v", err)
+	}
+}
+
+func TestResample(t *testing.T) {
+	// 1 kHz at 44.1 kHz converted to 48 kHz keeps its frequency and level
+	const frames = 4410
+	src := make([]float32, frames)
+	for i := range src {
+		src[i] = float32(0.5 * math.Sin(2*math.Pi*1000*float64(i)/44100))
+	}
+
+	out := Resample(src, 44100, 48000)
+	if len(out) != 4800 {
+		t.Fatalf("Expected 4800 frames, got %d", len(out))
+	}
+	for i := 100; i < len(out)-100; i++ {
+		want := 0.5 * math.Sin(2*math.Pi*1000*float64(i)/48000)
+		if math.Abs(float64(out[i])-want) > 1e-3 {
+			t.Fatalf("Frame %d: got %f, want %f", i, out[i], want)
+		}
+	}
+
+	// Content above the new Nyquist frequency is removed when downsampling
+	for i := range src {
+		src[i] = float32(0.5 * math.Sin(2*math.Pi*20000*float64(i)/44100))
+	}
+	out = Resample(src, 44100, 22050)
+	var peak float64
+	for _, v := range out[100 : len(out)-100] {
+		peak = math.Max(peak, math.Abs(float64(v)))
+	}
+	if peak > 0.01 {
+		t.Errorf("Expected 20 kHz to be filtered out, peak %f", peak)
+	}
+}
+
+func TestSaveLoadResampled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.aiff")
+	src := &Audio{Data: testSignal(441), SampleRate: 44100}
+	if err := Save(path, src, PCM24); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	audio, err := LoadResampled(path, 88200)
+	if err != nil {
+		t.Fatalf("LoadResampled failed: %v", err)
+	}
+	if audio.SampleRate != 88200 || audio.Len() != 882 {
+		t.Errorf("Expected 882 frames at 88.2 kHz, got %d at %g", audio.Len(), audio.SampleRate)
+	}
+
+	if err := Save(filepath.Join(t.TempDir(), "test.mp3"), src, PCM16); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for unknown extension, got %v", err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.wav")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package audiofile
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// sampleCodec converts between float32 samples and their byte encoding
+type sampleCodec struct {
+	enc       Encoding
+	order     binary.ByteOrder
+	unsigned8 bool // WAV stores 8-bit samples as unsigned
+}
+
+// newSampleCodec returns the codec for an encoding in a file format
+func newSampleCodec(format FileFormat, enc Encoding, littleEndian bool) sampleCodec {
+	c := sampleCodec{enc: enc, order: binary.BigEndian}
+	if littleEndian {
+		c.order = binary.LittleEndian
+	}
+	c.unsigned8 = format == FormatWAV
+	return c
+}
+
+// decode converts one encoded sample to float32
+func (c sampleCodec) decode(b []byte) float32 {
+	switch c.enc {
+	case PCM8:
+		if c.unsigned8 {
+			return (float32(b[0]) - 128) / 128.0
+		}
+		return float32(int8(b[0])) / 128.0
+	case PCM16:
+		return float32(int16(c.order.Uint16(b))) / 32768.0
+	case PCM24:
+		var v int32
+		if c.order == binary.LittleEndian {
+			v = int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		} else {
+			v = int32(int8(b[0]))<<16 | int32(b[1])<<8 | int32(b[2])
+		}
+		return float32(v) / 8388608.0
+	case PCM32:
+		return float32(float64(int32(c.order.Uint32(b))) / 2147483648.0)
+	case Float32:
+		return math.Float32frombits(c.order.Uint32(b))
+	case Float64:
+		return float32(math.Float64frombits(c.order.Uint64(b)))
+	}
+	return 0
+}
+
+// encode writes one float32 sample into b, clipping integer encodings
+func (c sampleCodec) encode(b []byte, v float32) {
+	switch c.enc {
+	case PCM8:
+		s := quantize(v, 127)
+		if c.unsigned8 {
+			b[0] = byte(s + 128)
+		} else {
+			b[0] = byte(int8(s))
+		}
+	case PCM16:
+		c.order.PutUint16(b, uint16(int16(quantize(v, 32767))))
+	case PCM24:
+		s := quantize(v, 8388607)
+		if c.order == binary.LittleEndian {
+			b[0], b[1], b[2] = byte(s), byte(s>>8), byte(s>>16)
+		} else {
+			b[0], b[1], b[2] = byte(s>>16), byte(s>>8), byte(s)
+		}
+	case PCM32:
+		c.order.PutUint32(b, uint32(int32(quantize(v, 2147483647))))
+	case Float32:
+		c.order.PutUint32(b, math.Float32bits(v))
+	case Float64:
+		c.order.PutUint64(b, math.Float64bits(float64(v)))
+	}
+}
+
+// quantize scales v to an integer range, rounding and clipping to [-max-1, max]
+func quantize(v float32, max int64) int64 {
+	s := int64(math.Round(float64(v) * float64(max+1)))
+	if s > max {
+		return max
+	}
+	if s < -max-1 {
+		return -max - 1
+	}
+	return s
+}
+
+// float64ToExtended encodes a positive value as an 80-bit IEEE extended float
+func float64ToExtended(v float64) [10]byte {
+	var b [10]byte
+	if v <= 0 {
+		return b
+	}
+	frac, exp := math.Frexp(v) // v = frac * 2^exp, frac in [0.5, 1)
+	binary.BigEndian.PutUint16(b[0:2], uint16(exp-1+16383))
+	binary.BigEndian.PutUint64(b[2:10], uint64(frac*(1<<64)))
+	return b
+}
+
+// extendedToFloat64 decodes an 80-bit IEEE extended float
+func extendedToFloat64(b []byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:2]) & 0x7FFF)
+	mant := binary.BigEndian.Uint64(b[2:10])
+	if exp == 0 && mant == 0 {
+		return 0
+	}
+	v := math.Ldexp(float64(mant), exp-16383-63)
+	if b[0]&0x80 != 0 {
+		v = -v
+	}
+	return v
+}
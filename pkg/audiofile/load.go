@@ -0,0 +1,72 @@
+package audiofile
+
+import (
+	"fmt"
+	"io"
+)
+
+// Load reads a whole WAV or AIFF file into memory
+func Load(path string) (*Audio, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadAll(r)
+}
+
+// LoadResampled reads a whole file and converts it to sampleRate if needed
+func LoadResampled(path string, sampleRate float64) (*Audio, error) {
+	audio, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if audio.SampleRate == sampleRate {
+		return audio, nil
+	}
+	return audio.Resample(sampleRate), nil
+}
+
+// Decode reads a whole WAV or AIFF stream into memory
+func Decode(rs io.ReadSeeker) (*Audio, error) {
+	r, err := NewReader(rs)
+	if err != nil {
+		return nil, err
+	}
+	return ReadAll(r)
+}
+
+// ReadAll reads the remaining frames of a reader into memory
+func ReadAll(r *Reader) (*Audio, error) {
+	info := r.Info()
+	audio := NewAudio(info.Channels, int(info.Frames-r.Position()), info.SampleRate)
+
+	n, err := r.Read(audio.Data)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// A short file leaves fewer frames than the header promised
+	for ch := range audio.Data {
+		audio.Data[ch] = audio.Data[ch][:n]
+	}
+	return audio, nil
+}
+
+// Save writes audio to a file. The format is taken from the file extension.
+func Save(path string, audio *Audio, enc Encoding) error {
+	format, ok := FormatFromPath(path)
+	if !ok {
+		return fmt.Errorf("%w: unknown extension in %s", ErrUnsupportedFormat, path)
+	}
+
+	w, err := Create(path, format, enc, audio.SampleRate, audio.NumChannels())
+	if err != nil {
+		return err
+	}
+	if err := w.Write(audio.Data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
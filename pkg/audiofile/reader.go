@@ -0,0 +1,442 @@
+package audiofile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WAV format tags
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// maxMetaChunkSize is the largest metadata chunk kept for Chunk; larger
+// ones are skipped
+const maxMetaChunkSize = 1 << 16
+
+// Reader streams sample frames from a WAV or AIFF file
+type Reader struct {
+	r      io.ReadSeeker
+	closer io.Closer
+	info   Info
+	codec  sampleCodec
+
+	dataStart int64 // Byte offset of the first frame
+	frameSize int   // Bytes per frame
+	pos       int64 // Current frame
+	buf       []byte
+	closed    bool
+
+	meta map[string][]byte // Small chunks other than format and data
+}
+
+// Open opens a file for streaming
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.closer = f
+	return r, nil
+}
+
+// NewReader parses the header of a WAV or AIFF stream and positions it at
+// the first sample frame
+func NewReader(rs io.ReadSeeker) (*Reader, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(rs, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: short header", ErrUnsupportedFormat)
+	}
+
+	r := &Reader{r: rs}
+	var err error
+	switch {
+	case string(header[0:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		err = r.parseWAV()
+	case string(header[0:4]) == "FORM" && (string(header[8:12]) == "AIFF" || string(header[8:12]) == "AIFC"):
+		err = r.parseAIFF(string(header[8:12]) == "AIFC")
+	default:
+		err = fmt.Errorf("%w: not a WAV or AIFF file", ErrUnsupportedFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.frameSize = r.info.Encoding.BytesPerSample() * r.info.Channels
+	if _, err := rs.Seek(r.dataStart, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// chunk is a chunk header found while scanning a file
+type chunk struct {
+	id     string
+	size   int64
+	offset int64 // Offset of the chunk body
+}
+
+// nextChunk reads the next chunk header, returning io.EOF at the end of the file
+func (r *Reader) nextChunk(order binary.ByteOrder) (chunk, error) {
+	var h [8]byte
+	if _, err := io.ReadFull(r.r, h[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return chunk{}, err
+	}
+	offset, err := r.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return chunk{}, err
+	}
+	return chunk{id: string(h[0:4]), size: int64(order.Uint32(h[4:8])), offset: offset}, nil
+}
+
+// skipChunk seeks past a chunk body and its pad byte
+func (r *Reader) skipChunk(c chunk) error {
+	_, err := r.r.Seek(c.offset+c.size+c.size%2, io.SeekStart)
+	return err
+}
+
+// readChunkBody reads a small chunk body into memory
+func (r *Reader) readChunkBody(c chunk, maxSize int64) ([]byte, error) {
+	if c.size > maxSize {
+		return nil, fmt.Errorf("%w: %q chunk too large", ErrUnsupportedFormat, c.id)
+	}
+	body := make([]byte, c.size)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return nil, fmt.Errorf("%w: truncated %q chunk", ErrUnsupportedFormat, c.id)
+	}
+	return body, r.skipChunk(c)
+}
+
+// keepChunk stores a small metadata chunk for Chunk and skips larger ones
+func (r *Reader) keepChunk(c chunk) error {
+	if c.size > maxMetaChunkSize {
+		return r.skipChunk(c)
+	}
+	body := make([]byte, c.size)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		// A truncated metadata chunk is dropped, as if it had been skipped
+		return r.skipChunk(c)
+	}
+	if r.meta == nil {
+		r.meta = make(map[string][]byte)
+	}
+	if _, dup := r.meta[c.id]; !dup {
+		r.meta[c.id] = body
+	}
+	return r.skipChunk(c)
+}
+
+// dataSize clamps a data chunk size to the end of the stream, which tolerates
+// files written by recorders that never patched the header
+func (r *Reader) dataSize(c chunk) (int64, error) {
+	end, err := r.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if c.offset+c.size > end {
+		return end - c.offset, nil
+	}
+	return c.size, nil
+}
+
+// parseWAV scans RIFF chunks for the format and data
+func (r *Reader) parseWAV() error {
+	var (
+		haveFormat bool
+		haveData   bool
+		dataBytes  int64
+	)
+	r.info.Format = FormatWAV
+
+	for {
+		c, err := r.nextChunk(binary.LittleEndian)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch c.id {
+		case "fmt ":
+			body, err := r.readChunkBody(c, 1024)
+			if err != nil {
+				return err
+			}
+			if len(body) < 16 {
+				return fmt.Errorf("%w: short fmt chunk", ErrUnsupportedFormat)
+			}
+			formatTag := binary.LittleEndian.Uint16(body[0:2])
+			if formatTag == wavFormatExtensible && len(body) >= 26 {
+				formatTag = binary.LittleEndian.Uint16(body[24:26])
+			}
+			enc, ok := encodingFor(formatTag == wavFormatFloat, int(binary.LittleEndian.Uint16(body[14:16])))
+			if !ok || (formatTag != wavFormatPCM && formatTag != wavFormatFloat) {
+				return fmt.Errorf("%w: WAV format %d with %d bits", ErrUnsupportedFormat,
+					formatTag, binary.LittleEndian.Uint16(body[14:16]))
+			}
+			r.info.Encoding = enc
+			r.info.Channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			r.info.SampleRate = float64(binary.LittleEndian.Uint32(body[4:8]))
+			haveFormat = true
+		case "data":
+			r.dataStart = c.offset
+			if dataBytes, err = r.dataSize(c); err != nil {
+				return err
+			}
+			haveData = true
+			if err := r.skipChunk(c); err != nil {
+				return err
+			}
+		default:
+			if err := r.keepChunk(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !haveFormat || !haveData || r.info.Channels < 1 {
+		return fmt.Errorf("%w: missing fmt or data chunk", ErrUnsupportedFormat)
+	}
+	r.codec = newSampleCodec(FormatWAV, r.info.Encoding, true)
+	r.info.Frames = dataBytes / int64(r.info.Encoding.BytesPerSample()*r.info.Channels)
+	return nil
+}
+
+// parseAIFF scans IFF chunks for the COMM and SSND chunks
+func (r *Reader) parseAIFF(isAIFC bool) error {
+	var (
+		haveComm  bool
+		haveData  bool
+		dataBytes int64
+		numFrames int64
+		bits      int
+	)
+	compression := "NONE"
+	r.info.Format = FormatAIFF
+
+	for {
+		c, err := r.nextChunk(binary.BigEndian)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch c.id {
+		case "COMM":
+			body, err := r.readChunkBody(c, 1024)
+			if err != nil {
+				return err
+			}
+			if len(body) < 18 {
+				return fmt.Errorf("%w: short COMM chunk", ErrUnsupportedFormat)
+			}
+			r.info.Channels = int(binary.BigEndian.Uint16(body[0:2]))
+			numFrames = int64(binary.BigEndian.Uint32(body[2:6]))
+			bits = int(binary.BigEndian.Uint16(body[6:8]))
+			r.info.SampleRate = extendedToFloat64(body[8:18])
+			if isAIFC && len(body) >= 22 {
+				compression = string(body[18:22])
+			}
+			haveComm = true
+		case "SSND":
+			var head [8]byte
+			if _, err := io.ReadFull(r.r, head[:]); err != nil {
+				return fmt.Errorf("%w: short SSND chunk", ErrUnsupportedFormat)
+			}
+			offset := int64(binary.BigEndian.Uint32(head[0:4]))
+			size, err := r.dataSize(c)
+			if err != nil {
+				return err
+			}
+			r.dataStart = c.offset + 8 + offset
+			dataBytes = size - 8 - offset
+			if dataBytes < 0 {
+				return fmt.Errorf("%w: bad SSND offset", ErrUnsupportedFormat)
+			}
+			haveData = true
+			if err := r.skipChunk(c); err != nil {
+				return err
+			}
+		default:
+			if err := r.keepChunk(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !haveComm || !haveData || r.info.Channels < 1 {
+		return fmt.Errorf("%w: missing COMM or SSND chunk", ErrUnsupportedFormat)
+	}
+
+	littleEndian := false
+	isFloat := false
+	switch compression {
+	case "NONE", "twos":
+	case "sowt":
+		littleEndian = true
+	case "fl32", "FL32":
+		isFloat, bits = true, 32
+	case "fl64", "FL64":
+		isFloat, bits = true, 64
+	default:
+		return fmt.Errorf("%w: AIFF-C compression %q", ErrUnsupportedFormat, compression)
+	}
+
+	enc, ok := encodingFor(isFloat, bits)
+	if !ok {
+		return fmt.Errorf("%w: AIFF with %d bits", ErrUnsupportedFormat, bits)
+	}
+	r.info.Encoding = enc
+	r.codec = newSampleCodec(FormatAIFF, enc, littleEndian)
+
+	// Trust COMM for the length, but never read past the data
+	r.info.Frames = min(numFrames, dataBytes/int64(enc.BytesPerSample()*r.info.Channels))
+	return nil
+}
+
+// encodingFor maps a bit depth to an encoding
+func encodingFor(isFloat bool, bits int) (Encoding, bool) {
+	if isFloat {
+		switch bits {
+		case 32:
+			return Float32, true
+		case 64:
+			return Float64, true
+		}
+		return 0, false
+	}
+	switch bits {
+	case 8:
+		return PCM8, true
+	case 16:
+		return PCM16, true
+	case 24:
+		return PCM24, true
+	case 32:
+		return PCM32, true
+	}
+	return 0, false
+}
+
+// Info returns the file description
+func (r *Reader) Info() Info {
+	return r.info
+}
+
+// Chunk returns the body of a metadata chunk, such as "smpl", "LIST" or
+// Serum's "clm ", or nil if the file has none. Chunks over 64 KB are not
+// kept. The returned slice must not be modified.
+func (r *Reader) Chunk(id string) []byte {
+	return r.meta[id]
+}
+
+// Instrument returns the sampler metadata of a WAV file's smpl chunk
+func (r *Reader) Instrument() Instrument {
+	inst := Instrument{RootKey: -1, LoopStart: -1, LoopEnd: -1}
+
+	// smpl: 36-byte header, then 24-byte loop records
+	smpl := r.meta["smpl"]
+	if r.info.Format != FormatWAV || len(smpl) < 36 {
+		return inst
+	}
+	inst.RootKey = int(binary.LittleEndian.Uint32(smpl[12:16]))
+	if numLoops := binary.LittleEndian.Uint32(smpl[28:32]); numLoops > 0 && len(smpl) >= 36+24 {
+		loop := smpl[36:]
+		start := int64(binary.LittleEndian.Uint32(loop[8:12]))
+		end := int64(binary.LittleEndian.Uint32(loop[12:16])) + 1 // smpl end is inclusive
+		if end > start && end <= r.info.Frames {
+			inst.LoopStart, inst.LoopEnd = start, end
+		}
+	}
+	return inst
+}
+
+// Position returns the current frame
+func (r *Reader) Position() int64 {
+	return r.pos
+}
+
+// Read reads up to len(dst[0]) frames into dst, one slice per channel, and
+// returns the number of frames read. It returns io.EOF once all frames are read.
+func (r *Reader) Read(dst [][]float32) (int, error) {
+	if r.closed {
+		return 0, ErrClosed
+	}
+	if len(dst) != r.info.Channels {
+		return 0, fmt.Errorf("%w: file has %d, buffer has %d", ErrChannelMismatch, r.info.Channels, len(dst))
+	}
+
+	frames := int64(len(dst[0]))
+	if remaining := r.info.Frames - r.pos; frames > remaining {
+		frames = remaining
+	}
+	if frames <= 0 {
+		return 0, io.EOF
+	}
+
+	need := int(frames) * r.frameSize
+	if cap(r.buf) < need {
+		r.buf = make([]byte, need)
+	}
+	buf := r.buf[:need]
+
+	n, err := io.ReadFull(r.r, buf)
+	got := n / r.frameSize
+	bps := r.info.Encoding.BytesPerSample()
+	for i := 0; i < got; i++ {
+		frame := buf[i*r.frameSize:]
+		for ch := range dst {
+			dst[ch][i] = r.codec.decode(frame[ch*bps:])
+		}
+	}
+	r.pos += int64(got)
+
+	if err == io.ErrUnexpectedEOF || (err == io.EOF && got > 0) {
+		// The file is shorter than its header claims
+		r.info.Frames = r.pos
+		err = nil
+	}
+	return got, err
+}
+
+// SeekFrame moves to a frame position
+func (r *Reader) SeekFrame(frame int64) error {
+	if r.closed {
+		return ErrClosed
+	}
+	if frame < 0 || frame > r.info.Frames {
+		return fmt.Errorf("seek to frame %d outside 0-%d", frame, r.info.Frames)
+	}
+	if _, err := r.r.Seek(r.dataStart+frame*int64(r.frameSize), io.SeekStart); err != nil {
+		return err
+	}
+	r.pos = frame
+	return nil
+}
+
+// Close closes the underlying file if the reader opened it
+func (r *Reader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package audiofile
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+const (
+	// resampleZeroCrossings is the number of sinc zero crossings on each side of the kernel
+	resampleZeroCrossings = 16
+	// resampleResolution is the number of table entries per zero crossing
+	resampleResolution = 256
+	// resampleKaiserBeta sets the window's stopband attenuation (about 90 dB)
+	resampleKaiserBeta = 9.0
+)
+
+// resampleTable holds one side of a Kaiser-windowed sinc kernel
+var resampleTable = buildResampleTable()
+
+// buildResampleTable precomputes the windowed sinc for x in [0, resampleZeroCrossings]
+func buildResampleTable() []float64 {
+	table := make([]float64, resampleZeroCrossings*resampleResolution+2)
+	norm := filter.BesselI0(resampleKaiserBeta)
+
+	for i := range table {
+		x := float64(i) / resampleResolution
+		if x > resampleZeroCrossings {
+			break
+		}
+		sinc := 1.0
+		if x != 0 {
+			sinc = math.Sin(math.Pi*x) / (math.Pi * x)
+		}
+		r := x / resampleZeroCrossings
+		table[i] = sinc * filter.BesselI0(resampleKaiserBeta*math.Sqrt(1-r*r)) / norm
+	}
+	return table
+}
+
+// resampleKernel evaluates the windowed sinc at |x| by table interpolation
+func resampleKernel(x float64) float64 {
+	if x < 0 {
+		x = -x
+	}
+	if x >= resampleZeroCrossings {
+		return 0
+	}
+	pos := x * resampleResolution
+	i := int(pos)
+	frac := pos - float64(i)
+	return resampleTable[i] + (resampleTable[i+1]-resampleTable[i])*frac
+}
+
+// Resample converts a channel from one sample rate to another with
+// windowed-sinc interpolation. When downsampling, the kernel is widened to
+// filter out content above the new Nyquist frequency. This allocates the
+// result and is meant for offline use, such as converting files on load.
+func Resample(data []float32, fromRate, toRate float64) []float32 {
+	if fromRate <= 0 || toRate <= 0 || fromRate == toRate {
+		out := make([]float32, len(data))
+		copy(out, data)
+		return out
+	}
+
+	ratio := toRate / fromRate
+	cutoff := math.Min(1, ratio)
+	step := 1 / ratio
+	span := int(math.Ceil(resampleZeroCrossings / cutoff))
+
+	out := make([]float32, int(math.Ceil(float64(len(data))*ratio)))
+	for i := range out {
+		pos := float64(i) * step
+		center := int(math.Floor(pos))
+		frac := pos - float64(center)
+
+		lo := max(center-span+1, 0)
+		hi := min(center+span, len(data)-1)
+
+		var sum float64
+		for k := lo; k <= hi; k++ {
+			sum += float64(data[k]) * resampleKernel((float64(k-center)-frac)*cutoff)
+		}
+		out[i] = float32(sum * cutoff)
+	}
+	return out
+}
+
+// Resample returns a copy of the audio converted to another sample rate
+func (a *Audio) Resample(sampleRate float64) *Audio {
+	out := &Audio{Data: make([][]float32, len(a.Data)), SampleRate: sampleRate}
+	for ch, data := range a.Data {
+		out.Data[ch] = Resample(data, a.SampleRate, sampleRate)
+	}
+	return out
+}
@@ -0,0 +1,280 @@
+package audiofile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Header field offsets patched when a writer closes
+const (
+	wavRIFFSizeOffset  = 4
+	aiffFORMSizeOffset = 4
+)
+
+// Writer streams sample frames to a WAV or AIFF file. Sizes in the header
+// are filled in by Close.
+type Writer struct {
+	w      io.WriteSeeker
+	closer io.Closer
+	info   Info
+	codec  sampleCodec
+
+	frameSize      int
+	dataSizeOffset int64 // Offset of the data/SSND chunk size field
+	framesOffset   int64 // Offset of the frame count field (fact or COMM), -1 if none
+	headerSize     int64
+	buf            []byte
+	closed         bool
+}
+
+// Create creates a file for streaming
+func Create(path string, format FileFormat, enc Encoding, sampleRate float64, channels int) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(f, format, enc, sampleRate, channels)
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	w.closer = f
+	return w, nil
+}
+
+// NewWriter writes a file header to ws and returns a writer for the sample frames
+func NewWriter(ws io.WriteSeeker, format FileFormat, enc Encoding, sampleRate float64, channels int) (*Writer, error) {
+	if channels < 1 || channels > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: %d channels", ErrUnsupportedFormat, channels)
+	}
+	if sampleRate <= 0 || sampleRate > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: sample rate %g", ErrUnsupportedFormat, sampleRate)
+	}
+	if enc < PCM8 || enc > Float64 {
+		return nil, fmt.Errorf("%w: cannot write %s", ErrUnsupportedFormat, enc)
+	}
+
+	w := &Writer{
+		w: ws,
+		info: Info{
+			Format:     format,
+			Encoding:   enc,
+			SampleRate: sampleRate,
+			Channels:   channels,
+		},
+		frameSize:    enc.BytesPerSample() * channels,
+		framesOffset: -1,
+	}
+
+	var header []byte
+	switch format {
+	case FormatWAV:
+		header = w.wavHeader()
+		w.codec = newSampleCodec(FormatWAV, enc, true)
+	case FormatAIFF:
+		header = w.aiffHeader()
+		w.codec = newSampleCodec(FormatAIFF, enc, false)
+	default:
+		return nil, fmt.Errorf("%w: format %d", ErrUnsupportedFormat, format)
+	}
+
+	if _, err := ws.Write(header); err != nil {
+		return nil, err
+	}
+	w.headerSize = int64(len(header))
+	return w, nil
+}
+
+// wavHeader builds a RIFF header with zero sizes
+func (w *Writer) wavHeader() []byte {
+	le := binary.LittleEndian
+	enc := w.info.Encoding
+	formatTag := uint16(wavFormatPCM)
+	if enc.IsFloat() {
+		formatTag = wavFormatFloat
+	}
+
+	h := []byte("RIFF\x00\x00\x00\x00WAVE")
+
+	fmtSize := 16
+	if enc.IsFloat() {
+		fmtSize = 18 // Non-PCM formats carry a cbSize field
+	}
+	h = append(h, "fmt "...)
+	h = le.AppendUint32(h, uint32(fmtSize))
+	h = le.AppendUint16(h, formatTag)
+	h = le.AppendUint16(h, uint16(w.info.Channels))
+	h = le.AppendUint32(h, uint32(w.info.SampleRate))
+	h = le.AppendUint32(h, uint32(w.info.SampleRate)*uint32(w.frameSize))
+	h = le.AppendUint16(h, uint16(w.frameSize))
+	h = le.AppendUint16(h, uint16(enc.BitDepth()))
+	if enc.IsFloat() {
+		h = le.AppendUint16(h, 0)
+
+		// Non-PCM formats also need a fact chunk with the frame count
+		h = append(h, "fact"...)
+		h = le.AppendUint32(h, 4)
+		w.framesOffset = int64(len(h))
+		h = le.AppendUint32(h, 0)
+	}
+
+	h = append(h, "data"...)
+	w.dataSizeOffset = int64(len(h))
+	h = le.AppendUint32(h, 0)
+	return h
+}
+
+// aiffHeader builds an AIFF header, or AIFF-C for float data, with zero sizes
+func (w *Writer) aiffHeader() []byte {
+	be := binary.BigEndian
+	enc := w.info.Encoding
+
+	h := []byte("FORM\x00\x00\x00\x00")
+	if enc.IsFloat() {
+		h = append(h, "AIFC"...)
+		h = append(h, "FVER"...)
+		h = be.AppendUint32(h, 4)
+		h = be.AppendUint32(h, 0xA2805140) // AIFF-C version 1
+	} else {
+		h = append(h, "AIFF"...)
+	}
+
+	commSize := 18
+	if enc.IsFloat() {
+		commSize = 24 // Compression type plus an empty, padded name
+	}
+	h = append(h, "COMM"...)
+	h = be.AppendUint32(h, uint32(commSize))
+	h = be.AppendUint16(h, uint16(w.info.Channels))
+	w.framesOffset = int64(len(h))
+	h = be.AppendUint32(h, 0)
+	h = be.AppendUint16(h, uint16(enc.BitDepth()))
+	rate := float64ToExtended(w.info.SampleRate)
+	h = append(h, rate[:]...)
+	if enc.IsFloat() {
+		if enc == Float32 {
+			h = append(h, "fl32"...)
+		} else {
+			h = append(h, "fl64"...)
+		}
+		h = append(h, 0, 0)
+	}
+
+	h = append(h, "SSND"...)
+	w.dataSizeOffset = int64(len(h))
+	h = be.AppendUint32(h, 0)
+	h = be.AppendUint32(h, 0) // Offset
+	h = be.AppendUint32(h, 0) // Block size
+	return h
+}
+
+// Info returns the file description, with Frames counting frames written so far
+func (w *Writer) Info() Info {
+	return w.info
+}
+
+// Write appends frames from src, one slice per channel. Integer encodings
+// are clipped to [-1, 1).
+func (w *Writer) Write(src [][]float32) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if len(src) != w.info.Channels {
+		return fmt.Errorf("%w: file has %d, buffer has %d", ErrChannelMismatch, w.info.Channels, len(src))
+	}
+
+	frames := len(src[0])
+	need := frames * w.frameSize
+	if cap(w.buf) < need {
+		w.buf = make([]byte, need)
+	}
+	buf := w.buf[:need]
+
+	bps := w.info.Encoding.BytesPerSample()
+	for i := 0; i < frames; i++ {
+		frame := buf[i*w.frameSize:]
+		for ch := range src {
+			w.codec.encode(frame[ch*bps:], src[ch][i])
+		}
+	}
+
+	if _, err := w.w.Write(buf); err != nil {
+		return err
+	}
+	w.info.Frames += int64(frames)
+	return nil
+}
+
+// Close pads the data, patches the header sizes and closes the file if the
+// writer created it
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	err := w.finish()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// headerPatch is a 32-bit header field filled in on close
+type headerPatch struct {
+	offset int64
+	value  uint32
+}
+
+// finish writes the pad byte and final chunk sizes
+func (w *Writer) finish() error {
+	dataBytes := w.info.Frames * int64(w.frameSize)
+	if dataBytes+w.headerSize > math.MaxUint32 {
+		return fmt.Errorf("%w: file exceeds 4 GB", ErrUnsupportedFormat)
+	}
+
+	fileSize := w.headerSize + dataBytes
+	if dataBytes%2 == 1 {
+		if _, err := w.w.Write([]byte{0}); err != nil {
+			return err
+		}
+		fileSize++
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	sizeOffset := int64(wavRIFFSizeOffset)
+	chunkData := dataBytes
+	if w.info.Format == FormatAIFF {
+		order = binary.BigEndian
+		sizeOffset = aiffFORMSizeOffset
+		chunkData += 8 // SSND offset and block size
+	}
+
+	patches := []headerPatch{
+		{sizeOffset, uint32(fileSize - 8)},
+		{w.dataSizeOffset, uint32(chunkData)},
+	}
+	if w.framesOffset >= 0 {
+		patches = append(patches, headerPatch{w.framesOffset, uint32(w.info.Frames)})
+	}
+
+	var b [4]byte
+	for _, p := range patches {
+		order.PutUint32(b[:], p.value)
+		if _, err := w.w.Seek(p.offset, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(b[:]); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.w.Seek(fileSize, io.SeekStart)
+	return err
+}
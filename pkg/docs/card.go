@@ -0,0 +1,266 @@
+package docs
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/host"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// Card layout, in image pixels
+const (
+	cardWidth    = 720
+	cardMargin   = 24
+	textScale    = 2 // Image pixels per font pixel for body text
+	titleScale   = 3
+	rowHeight    = 24
+	groupHeight  = 36
+	headerHeight = 100
+	nameWidth    = 260
+	barWidth     = 200
+	barHeight    = 10
+)
+
+// defaultPresetName names the card of a plugin without factory presets
+const defaultPresetName = "Default"
+
+// Card colours
+var (
+	cardBackground = color.RGBA{0x1e, 0x21, 0x27, 0xff}
+	cardText       = color.RGBA{0xe6, 0xe6, 0xe6, 0xff}
+	cardDim        = color.RGBA{0x9a, 0xa0, 0xa6, 0xff}
+	cardAccent     = color.RGBA{0x4f, 0xc3, 0xf7, 0xff}
+	cardTrack      = color.RGBA{0x3a, 0x3f, 0x47, 0xff}
+	cardRule       = color.RGBA{0x2e, 0x33, 0x3b, 0xff}
+)
+
+// cardSymbols replaces the symbols parameter formatters commonly use that the
+// card font does not have
+var cardSymbols = strings.NewReplacer("∞", "inf", "µ", "u", "°", " deg")
+
+// Presets returns the factory presets of p, or a single preset of default
+// values named "Default" when it ships none
+func Presets(p plugin.Plugin) ([]plugin.Preset, error) {
+	h, err := host.New(p, host.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.GetInfo().Name, err)
+	}
+	if pp, ok := h.Processor().(plugin.PresetProcessor); ok {
+		if presets := pp.FactoryPresets(); len(presets) > 0 {
+			return presets, nil
+		}
+	}
+	return []plugin.Preset{{Name: defaultPresetName}}, nil
+}
+
+// RenderCard loads a preset into a new instance of p and renders its card.
+// A processor that implements plugin.ScreenshotProcessor supplies its own
+// image; otherwise the card is a generated summary of the visible
+// parameters, with each value written out and drawn as a bar.
+func RenderCard(p plugin.Plugin, preset plugin.Preset) (image.Image, error) {
+	h, err := host.New(p, host.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.GetInfo().Name, err)
+	}
+	params := h.Parameters()
+	for id, plain := range preset.Values {
+		if params == nil || params.Get(id) == nil {
+			return nil, fmt.Errorf("preset %q: %w: %d", preset.Name, host.ErrUnknownParameter, id)
+		}
+		if err := h.SetParameter(id, params.Get(id).Normalize(plain)); err != nil {
+			return nil, err
+		}
+	}
+
+	if sp, ok := h.Processor().(plugin.ScreenshotProcessor); ok {
+		img, err := sp.Screenshot()
+		if err != nil {
+			return nil, fmt.Errorf("preset %q: %w", preset.Name, err)
+		}
+		return img, nil
+	}
+	return drawCard(p, preset, params), nil
+}
+
+// WriteCards renders a PNG card for every factory preset of p into dir and
+// returns the paths written. Files are named after the plugin and preset,
+// such as "my-delay-tape-echo.png".
+func WriteCards(p plugin.Plugin, dir string) ([]string, error) {
+	presets, err := Presets(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(presets))
+	for _, preset := range presets {
+		img, err := RenderCard(p, preset)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, slug(p.GetInfo().Name)+"-"+slug(preset.Name)+".png")
+		if err := writePNG(path, img); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// writePNG encodes an image to a file
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// slug turns a name into a lower case file name part
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// drawCard draws the parameter summary card
+func drawCard(p plugin.Plugin, preset plugin.Preset, params *param.Registry) image.Image {
+	var visible []*param.Parameter
+	if params != nil {
+		for _, prm := range params.Ordered() {
+			if prm.Flags&param.IsHidden == 0 {
+				visible = append(visible, prm)
+			}
+		}
+	}
+
+	groups := 0
+	for i, prm := range visible {
+		if prm.Group != "" && (i == 0 || visible[i-1].Group != prm.Group) {
+			groups++
+		}
+	}
+	height := 2*cardMargin + headerHeight + groups*groupHeight + len(visible)*rowHeight
+
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, height))
+	fillRect(img, img.Bounds(), cardBackground)
+
+	// Header: plugin, preset, then vendor details
+	info := p.GetInfo()
+	textWidth := cardWidth - 2*cardMargin
+	y := cardMargin
+	drawText(img, cardMargin, y, fit(info.Name, textWidth, titleScale), titleScale, cardText)
+	y += 36
+	title := preset.Name
+	if preset.Category != "" {
+		title += " (" + preset.Category + ")"
+	}
+	drawText(img, cardMargin, y, fit(title, textWidth, textScale), textScale, cardAccent)
+	y += 24
+	var details []string
+	for _, s := range []string{info.Vendor, info.Category, info.Version} {
+		if s != "" {
+			details = append(details, s)
+		}
+	}
+	drawText(img, cardMargin, y, fit(strings.Join(details, " - "), textWidth, textScale), textScale, cardDim)
+	y = cardMargin + headerHeight - 12
+	fillRect(img, image.Rect(cardMargin, y, cardWidth-cardMargin, y+2), cardRule)
+	y += 12
+
+	barX := cardMargin + nameWidth
+	valueX := barX + barWidth + 16
+	for i, prm := range visible {
+		if prm.Group != "" && (i == 0 || visible[i-1].Group != prm.Group) {
+			drawText(img, cardMargin, y+groupHeight-rowHeight, fit(prm.Group, textWidth, textScale), textScale, cardAccent)
+			y += groupHeight
+		}
+
+		textY := y + (rowHeight-glyphHeight*textScale)/2
+		drawText(img, cardMargin, textY, fit(prm.Name, nameWidth-12, textScale), textScale, cardText)
+
+		barY := y + (rowHeight-barHeight)/2
+		fillRect(img, image.Rect(barX, barY, barX+barWidth, barY+barHeight), cardTrack)
+		from, to := barSpan(prm)
+		fillRect(img, image.Rect(barX+int(from*barWidth), barY, barX+int(to*barWidth+0.5), barY+barHeight), cardAccent)
+
+		value := withUnit(prm.FormatValue(prm.GetValue()), prm.Unit)
+		drawText(img, valueX, textY, fit(value, cardWidth-cardMargin-valueX, textScale), textScale, cardText)
+		y += rowHeight
+	}
+	return img
+}
+
+// barSpan returns the filled part of a value bar in 0-1. Ranges that cross
+// zero, such as pan, fill from zero.
+func barSpan(p *param.Parameter) (from, to float64) {
+	value := p.GetValue()
+	if p.Min < 0 && p.Max > 0 {
+		zero := p.Normalize(0)
+		return min(zero, value), max(zero, value)
+	}
+	return 0, value
+}
+
+// fit shortens text to fit a width in pixels at a scale
+func fit(text string, width, scale int) string {
+	text = cardSymbols.Replace(strings.TrimSpace(text))
+	runes := []rune(text)
+	limit := (width + scale) / ((glyphWidth + 1) * scale)
+	if len(runes) <= limit {
+		return text
+	}
+	if limit < 3 {
+		return ""
+	}
+	return string(runes[:limit-2]) + ".."
+}
+
+// drawText draws text with its top left corner at x, y
+func drawText(img *image.RGBA, x, y int, text string, scale int, c color.RGBA) {
+	for _, r := range text {
+		g := glyph(r)
+		for col := 0; col < glyphWidth; col++ {
+			for row := 0; row < glyphHeight; row++ {
+				if g[col]&(1<<row) != 0 {
+					px := x + col*scale
+					py := y + row*scale
+					fillRect(img, image.Rect(px, py, px+scale, py+scale), c)
+				}
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
+
+// fillRect fills a rectangle, clipped to the image
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package docs
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/host"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// presetPlugin is testPlugin with factory presets
+type presetPlugin struct {
+	testPlugin
+	screenshot bool
+}
+
+func (p *presetPlugin) CreateProcessor() plugin.Processor {
+	proc := &presetProcessor{testProcessor: p.testPlugin.CreateProcessor().(*testProcessor)}
+	if p.screenshot {
+		return &screenshotProcessor{proc}
+	}
+	return proc
+}
+
+type presetProcessor struct {
+	*testProcessor
+}
+
+func (p *presetProcessor) FactoryPresets() []plugin.Preset {
+	return []plugin.Preset{
+		{Name: "Gentle", Category: "Mix", Values: map[uint32]float64{0: -10}},
+		{Name: "Slam / Hard", Values: map[uint32]float64{0: -40, 1: 1}},
+	}
+}
+
+// screenshotProcessor draws an image as wide as the threshold is deep, so
+// tests can see which values it was drawn with
+type screenshotProcessor struct {
+	*presetProcessor
+}
+
+func (p *screenshotProcessor) Screenshot() (image.Image, error) {
+	width := 1 - int(p.params.Get(0).GetPlainValue())
+	return image.NewRGBA(image.Rect(0, 0, width, 10)), nil
+}
+
+func TestWriteCards(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := WriteCards(&presetPlugin{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"docs-test-gentle.png", "docs-test-slam-hard.png"}
+	if len(paths) != len(want) {
+		t.Fatalf("Wrote %v, want %v", paths, want)
+	}
+
+	var images []image.Image
+	for i, path := range paths {
+		if filepath.Base(path) != want[i] {
+			t.Errorf("Card %d named %q, want %q", i, filepath.Base(path), want[i])
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if b := img.Bounds(); b.Dx() != cardWidth || b.Dy() < headerHeight {
+			t.Errorf("%s is %v", path, b)
+		}
+		images = append(images, img)
+	}
+
+	// The presets set different values, so the cards differ
+	if sameImage(images[0], images[1]) {
+		t.Error("Cards of different presets are identical")
+	}
+}
+
+func TestCardDefaultPreset(t *testing.T) {
+	presets, err := Presets(&testPlugin{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != 1 || presets[0].Name != defaultPresetName || len(presets[0].Values) != 0 {
+		t.Errorf("Presets without factory presets = %+v", presets)
+	}
+
+	// Hidden parameters take no rows: three visible rows and one group
+	img, err := RenderCard(&testPlugin{}, presets[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 2*cardMargin + headerHeight + groupHeight + 3*rowHeight
+	if img.Bounds().Dy() != want {
+		t.Errorf("Card height = %d, want %d", img.Bounds().Dy(), want)
+	}
+}
+
+func TestCardUnknownParameter(t *testing.T) {
+	_, err := RenderCard(&testPlugin{}, plugin.Preset{Name: "Broken", Values: map[uint32]float64{99: 1}})
+	if !errors.Is(err, host.ErrUnknownParameter) {
+		t.Errorf("err = %v, want ErrUnknownParameter", err)
+	}
+}
+
+func TestCardScreenshot(t *testing.T) {
+	p := &presetPlugin{screenshot: true}
+	img, err := RenderCard(p, p.CreateProcessor().(plugin.PresetProcessor).FactoryPresets()[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Drawn by the processor after the preset's -40 dB threshold was applied
+	if img.Bounds().Dx() != 41 {
+		t.Errorf("Screenshot width = %d, want 41", img.Bounds().Dx())
+	}
+}
+
+func TestSlug(t *testing.T) {
+	for name, want := range map[string]string{
+		"Docs Test":        "docs-test",
+		"  Slam / Hard!  ": "slam-hard",
+		"Lead 2 (Bright)":  "lead-2-bright",
+	} {
+		if got := slug(name); got != want {
+			t.Errorf("slug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func sameImage(a, b image.Image) bool {
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+	r := a.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if a.At(x, y) != b.At(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
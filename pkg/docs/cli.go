@@ -0,0 +1,135 @@
+package docs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/host"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// Main runs the docs command line on the plugins registered in this program
+// and exits on error. A docs tool is a main package that imports the plugin
+// packages and calls Main:
+//
+//	import (
+//		"github.com/justyntemme/vst3go/pkg/docs"
+//		_ "example.com/myplugin"
+//	)
+//
+//	func main() { docs.Main() }
+func Main() {
+	if err := Run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// Run parses docs command line arguments and writes the reference of the
+// chosen plugin, or of every registered plugin, to -out or stdout. With
+// -cards it writes a PNG card per factory preset to that directory instead.
+func Run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("vst3go-docs", flag.ContinueOnError)
+	var (
+		name   = fs.String("plugin", "", "plugin name or ID; all registered plugins when empty")
+		format = fs.String("format", "", "markdown, html or json; follows the -out extension when empty")
+		out    = fs.String("out", "", "output file; stdout when empty")
+		cards  = fs.String("cards", "", "directory to write a PNG card per factory preset to, instead of the reference")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *cards != "" {
+		return runCards(*name, *cards, stdout)
+	}
+
+	f, err := outputFormat(*format, *out)
+	if err != nil {
+		return err
+	}
+
+	var refs []*Reference
+	if *name == "" {
+		if refs, err = BuildAll(); err != nil {
+			return err
+		}
+	} else {
+		p, err := host.FindPlugin(*name)
+		if err != nil {
+			return err
+		}
+		ref, err := Build(p)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, ref)
+	}
+
+	if *out == "" {
+		return Write(stdout, f, refs...)
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, f, refs...); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote %d plugin reference(s) to %s\n", len(refs), *out)
+	return nil
+}
+
+// runCards writes the preset cards of the chosen plugin, or of every
+// registered plugin, to dir
+func runCards(name, dir string, stdout io.Writer) error {
+	plugins := plugin.Registered()
+	if name != "" {
+		p, err := host.FindPlugin(name)
+		if err != nil {
+			return err
+		}
+		plugins = []plugin.Plugin{p}
+	}
+	if len(plugins) == 0 {
+		return host.ErrNoPlugin
+	}
+
+	written := 0
+	for _, p := range plugins {
+		paths, err := WriteCards(p, dir)
+		written += len(paths)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "wrote %d preset card(s) to %s\n", written, dir)
+	return nil
+}
+
+// outputFormat resolves the format flag, falling back to the output file
+// extension and then Markdown
+func outputFormat(name, out string) (Format, error) {
+	if name != "" {
+		return ParseFormat(name)
+	}
+	switch strings.ToLower(filepath.Ext(out)) {
+	case ".html", ".htm":
+		return HTML, nil
+	case ".json":
+		return JSON, nil
+	default:
+		return Markdown, nil
+	}
+}
@@ -0,0 +1,270 @@
+// Package docs generates user-facing reference documentation for plugins.
+// It reads the parameters and buses a processor declares, so plugin manuals
+// are produced from the code instead of drifting away from it.
+//
+// A reference lists each visible parameter with its range, default, unit
+// and the description set with param.Builder.Description, in display order
+// and under group headings, followed by the audio and event buses. It is
+// written as Markdown, as a standalone HTML page, or as JSON for tooling.
+//
+// It also renders a card image for each factory preset, for preset browsers
+// and store pages, with RenderCard and WriteCards.
+package docs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	fwplugin "github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/host"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// Format selects the output format
+type Format int
+
+const (
+	Markdown Format = iota
+	HTML
+	JSON
+)
+
+// maxListedValues is the largest step count whose values are listed
+// individually instead of as a range
+const maxListedValues = 32
+
+// ErrUnknownFormat is returned for format names other than markdown, html
+// or json
+var ErrUnknownFormat = errors.New("unknown documentation format")
+
+// String returns the format name
+func (f Format) String() string {
+	switch f {
+	case Markdown:
+		return "markdown"
+	case HTML:
+		return "html"
+	case JSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// ParseFormat converts a format name such as "md" or "html"
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "markdown", "md":
+		return Markdown, nil
+	case "html", "htm":
+		return HTML, nil
+	case "json":
+		return JSON, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+	}
+}
+
+// Reference describes one plugin for its documentation
+type Reference struct {
+	Info       fwplugin.Info
+	Parameters []Parameter // Visible parameters in display order
+	Buses      []Bus
+	Latency    int32 // Samples at the default sample rate
+
+	metadata []param.Metadata // Every parameter, for JSON output
+}
+
+// ParameterGroup is a run of parameters that share a group
+type ParameterGroup struct {
+	Name       string // Empty for ungrouped parameters
+	Parameters []Parameter
+}
+
+// Groups returns the parameters split into their groups, in the order the
+// groups first appear
+func (r *Reference) Groups() []ParameterGroup {
+	var groups []ParameterGroup
+	for _, p := range r.Parameters {
+		i := slices.IndexFunc(groups, func(g ParameterGroup) bool { return g.Name == p.Group })
+		if i < 0 {
+			groups = append(groups, ParameterGroup{Name: p.Group})
+			i = len(groups) - 1
+		}
+		groups[i].Parameters = append(groups[i].Parameters, p)
+	}
+	return groups
+}
+
+// Parameter describes one parameter in display form
+type Parameter struct {
+	ID          uint32
+	Name        string
+	Group       string
+	Unit        string
+	Min         string   // Formatted minimum
+	Max         string   // Formatted maximum
+	Default     string   // Formatted default
+	Values      []string // Every value of a stepped parameter with few steps
+	Description string
+	Automatable bool
+	ReadOnly    bool
+}
+
+// Range returns the values a parameter takes, as a list for stepped
+// parameters and as "min to max" otherwise
+func (p Parameter) Range() string {
+	if len(p.Values) > 0 {
+		return strings.Join(p.Values, ", ")
+	}
+	return p.Min + " to " + p.Max
+}
+
+// Bus describes one audio or event bus
+type Bus struct {
+	Name        string `json:"name"`
+	Direction   string `json:"direction"` // "Input" or "Output"
+	Media       string `json:"media"`     // "Audio" or "Event"
+	Main        bool   `json:"main"`      // False for aux buses such as sidechains
+	Channels    int32  `json:"channels"`
+	Arrangement string `json:"arrangement,omitempty"` // Speaker arrangement, empty for event buses
+	Active      bool   `json:"active"`                // Active by default
+}
+
+// Kind returns "Main" or "Aux"
+func (b Bus) Kind() string {
+	if b.Main {
+		return "Main"
+	}
+	return "Aux"
+}
+
+// Build creates a processor from p and collects its reference
+func Build(p plugin.Plugin) (*Reference, error) {
+	h, err := host.New(p, host.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.GetInfo().Name, err)
+	}
+	processor := h.Processor()
+	ref := &Reference{
+		Info:    p.GetInfo(),
+		Latency: processor.GetLatencySamples(),
+	}
+	if params := h.Parameters(); params != nil {
+		ref.metadata = params.Metadata()
+		for _, prm := range params.Ordered() {
+			if prm.Flags&param.IsHidden != 0 {
+				continue
+			}
+			ref.Parameters = append(ref.Parameters, describeParameter(prm))
+		}
+	}
+	if buses := processor.GetBuses(); buses != nil {
+		ref.Buses = describeBuses(buses)
+	}
+	return ref, nil
+}
+
+// BuildAll collects the references of every registered plugin
+func BuildAll() ([]*Reference, error) {
+	plugins := plugin.Registered()
+	if len(plugins) == 0 {
+		return nil, host.ErrNoPlugin
+	}
+	refs := make([]*Reference, 0, len(plugins))
+	for _, p := range plugins {
+		ref, err := Build(p)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// describeParameter formats a parameter for display
+func describeParameter(p *param.Parameter) Parameter {
+	d := Parameter{
+		ID:          p.ID,
+		Name:        p.Name,
+		Group:       p.Group,
+		Unit:        p.Unit,
+		Min:         withUnit(p.FormatValue(0), p.Unit),
+		Max:         withUnit(p.FormatValue(1), p.Unit),
+		Default:     withUnit(p.FormatValue(p.DefaultValue), p.Unit),
+		Description: p.Description,
+		Automatable: p.Flags&param.CanAutomate != 0,
+		ReadOnly:    p.Flags&param.IsReadOnly != 0,
+	}
+	if p.StepCount > 0 && p.StepCount <= maxListedValues {
+		for i := int32(0); i <= p.StepCount; i++ {
+			value := withUnit(p.FormatValue(float64(i)/float64(p.StepCount)), p.Unit)
+			// Neighbouring steps can format alike, as choices do between
+			// their options
+			if n := len(d.Values); n == 0 || d.Values[n-1] != value {
+				d.Values = append(d.Values, value)
+			}
+		}
+	}
+	return d
+}
+
+// withUnit appends the unit unless the formatter already included it
+func withUnit(value, unit string) string {
+	if unit == "" || strings.Contains(value, unit) {
+		return value
+	}
+	return value + " " + unit
+}
+
+// describeBuses lists the audio buses followed by the event buses
+func describeBuses(c *bus.Configuration) []Bus {
+	var buses []Bus
+	for _, media := range []bus.MediaType{bus.MediaTypeAudio, bus.MediaTypeEvent} {
+		for _, direction := range []bus.Direction{bus.DirectionInput, bus.DirectionOutput} {
+			for i := int32(0); i < c.GetBusCount(media, direction); i++ {
+				info := c.GetBusInfo(media, direction, i)
+				if info == nil {
+					continue
+				}
+				b := Bus{
+					Name:      info.Name,
+					Direction: "Input",
+					Media:     "Audio",
+					Main:      info.BusType == bus.TypeMain,
+					Channels:  info.ChannelCount,
+					Active:    info.IsActive,
+				}
+				if direction == bus.DirectionOutput {
+					b.Direction = "Output"
+				}
+				if media == bus.MediaTypeEvent {
+					b.Media = "Event"
+				} else {
+					b.Arrangement = info.SpeakerArrangement().String()
+				}
+				buses = append(buses, b)
+			}
+		}
+	}
+	return buses
+}
+
+// Write writes the references in the given format
+func Write(w io.Writer, format Format, refs ...*Reference) error {
+	switch format {
+	case Markdown:
+		return WriteMarkdown(w, refs...)
+	case HTML:
+		return WriteHTML(w, refs...)
+	case JSON:
+		return WriteJSON(w, refs...)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
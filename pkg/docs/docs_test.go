@@ -0,0 +1,215 @@
+package docs
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	fwplugin "github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// testPlugin declares a few parameter kinds and a sidechain
+type testPlugin struct{}
+
+func (p *testPlugin) GetInfo() fwplugin.Info {
+	return fwplugin.Info{
+		ID:       "com.vst3go.test.docs",
+		Name:     "Docs Test",
+		Version:  "1.2.0",
+		Vendor:   "VST3Go",
+		Category: "Fx|Dynamics",
+	}
+}
+
+func (p *testPlugin) CreateProcessor() plugin.Processor {
+	params := param.NewRegistry()
+	params.Add(
+		param.ThresholdParameter(0, "Threshold", -60, 0, -20).
+			Description("Level above which | the signal is compressed").
+			Build(),
+		param.Choice(1, "Mode", []param.ChoiceOption{
+			{Value: 0, Name: "Peak"},
+			{Value: 1, Name: "RMS"},
+		}).Build(),
+		param.New(2, "Release").Range(1, 1000).Default(100).Unit("ms").Group("Timing").Build(),
+		param.New(3, "Internal").Hidden().Build(),
+	)
+	return &testProcessor{
+		params: params,
+		buses: bus.NewBuilder().
+			WithStereoInput("Input").
+			WithSidechain("Sidechain").
+			WithStereoOutput("Output").
+			WithEventInput("MIDI In").
+			MustBuild(),
+	}
+}
+
+type testProcessor struct {
+	params *param.Registry
+	buses  *bus.Configuration
+}
+
+func (p *testProcessor) Initialize(sampleRate float64, maxBlockSize int32) error { return nil }
+func (p *testProcessor) ProcessAudio(ctx *process.Context)                       { ctx.PassThrough() }
+func (p *testProcessor) GetParameters() *param.Registry                          { return p.params }
+func (p *testProcessor) GetBuses() *bus.Configuration                            { return p.buses }
+func (p *testProcessor) SetActive(active bool) error                             { return nil }
+func (p *testProcessor) GetLatencySamples() int32                                { return 64 }
+func (p *testProcessor) GetTailSamples() int32                                   { return 0 }
+
+func TestBuildReference(t *testing.T) {
+	ref, err := Build(&testPlugin{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Latency != 64 {
+		t.Errorf("Latency = %d, want 64", ref.Latency)
+	}
+
+	// Hidden parameters are left out of user docs
+	if len(ref.Parameters) != 3 {
+		t.Fatalf("Got %d parameters, want 3", len(ref.Parameters))
+	}
+	threshold, mode, release := ref.Parameters[0], ref.Parameters[1], ref.Parameters[2]
+	if threshold.Default != "-20.0 dB" || !strings.Contains(threshold.Description, "compressed") {
+		t.Errorf("Threshold = %+v", threshold)
+	}
+	if got := mode.Range(); got != "Peak, RMS" {
+		t.Errorf("Mode range = %q, want the option names", got)
+	}
+	if got := release.Range(); got != "1.00 ms to 1000.00 ms" {
+		t.Errorf("Release range = %q", got)
+	}
+
+	if len(ref.Buses) != 4 {
+		t.Fatalf("Got %d buses, want 4", len(ref.Buses))
+	}
+	sidechain := ref.Buses[1]
+	if sidechain.Name != "Sidechain" || sidechain.Main || sidechain.Direction != "Input" || sidechain.Arrangement != "stereo" {
+		t.Errorf("Sidechain = %+v", sidechain)
+	}
+	if midi := ref.Buses[3]; midi.Media != "Event" || midi.Arrangement != "" {
+		t.Errorf("Event bus = %+v", midi)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	ref, err := Build(&testPlugin{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := Write(&b, Markdown, ref); err != nil {
+		t.Fatal(err)
+	}
+	doc := b.String()
+	for _, want := range []string{
+		"# Docs Test\n",
+		"Version 1.2.0 by VST3Go. Category: Fx\\|Dynamics. Latency: 64 samples.\n",
+		"| Threshold | -∞ dB to 0.0 dB | -20.0 dB | Level above which \\| the signal is compressed |\n",
+		"| Mode | Peak, RMS | Peak |  |\n",
+		"| Sidechain | Audio input | Aux | 2 (stereo) | No |\n",
+		"| MIDI In | Event input | Main | - | Yes |\n",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("Markdown is missing %q:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, "Internal") {
+		t.Error("Hidden parameter was documented")
+	}
+}
+
+func TestGroupsAndJSON(t *testing.T) {
+	ref, err := Build(&testPlugin{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := ref.Groups()
+	if len(groups) != 2 || groups[0].Name != "" || len(groups[0].Parameters) != 2 || groups[1].Name != "Timing" {
+		t.Fatalf("Groups = %+v", groups)
+	}
+
+	var b strings.Builder
+	if err := Write(&b, Markdown, ref); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "\n### Timing\n\n| Parameter |") {
+		t.Errorf("Markdown is missing the Timing heading:\n%s", b.String())
+	}
+
+	// JSON carries every parameter, hidden ones included, for tooling
+	b.Reset()
+	if err := Write(&b, JSON, ref); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []struct {
+		Parameters []param.Metadata `json:"parameters"`
+		Buses      []Bus            `json:"buses"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || len(decoded[0].Parameters) != 4 || len(decoded[0].Buses) != 4 {
+		t.Fatalf("JSON = %s", b.String())
+	}
+	if release := decoded[0].Parameters[2]; release.Group != "Timing" || release.Default != 100 {
+		t.Errorf("Release metadata = %+v", release)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	ref, err := Build(&testPlugin{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref.Parameters[0].Description = "<b>loud</b>"
+	var b strings.Builder
+	if err := Write(&b, HTML, ref); err != nil {
+		t.Fatal(err)
+	}
+	doc := b.String()
+	if !strings.Contains(doc, "<h1>Docs Test</h1>") || !strings.Contains(doc, "<td>Peak, RMS</td>") {
+		t.Errorf("HTML is missing content:\n%s", doc)
+	}
+	if strings.Contains(doc, "<b>loud</b>") {
+		t.Error("Descriptions should be escaped")
+	}
+}
+
+func TestRunCommandLine(t *testing.T) {
+	plugin.Register(&testPlugin{})
+
+	out := filepath.Join(t.TempDir(), "params.html")
+	var stdout strings.Builder
+	if err := Run([]string{"-plugin", "docs test", "-out", out}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "<!DOCTYPE html>") {
+		t.Error("The .html extension should select HTML")
+	}
+
+	stdout.Reset()
+	if err := Run([]string{"-plugin", "com.vst3go.test.docs"}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stdout.String(), "# Docs Test") {
+		t.Errorf("Expected Markdown on stdout, got %q", stdout.String())
+	}
+
+	if err := Run([]string{"-format", "pdf"}, &stdout); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Unknown format: %v", err)
+	}
+}
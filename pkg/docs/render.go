@@ -0,0 +1,133 @@
+package docs
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	fwplugin "github.com/justyntemme/vst3go/pkg/framework/plugin"
+)
+
+// markdownTemplate lays out one or more references as Markdown
+const markdownTemplate = `{{range $i, $ref := .}}{{if $i}}
+---
+
+{{end}}# {{md .Info.Name}}
+
+{{with .Info.Version}}Version {{md .}}{{end}}{{with .Info.Vendor}} by {{md .}}{{end}}{{with .Info.Category}}. Category: {{md .}}{{end}}.
+{{- if .Latency}} Latency: {{.Latency}} samples.{{end}}
+{{if .Parameters}}
+## Parameters
+{{range .Groups}}{{with .Name}}
+### {{md .}}
+{{end}}
+| Parameter | Range | Default | Description |
+| --- | --- | --- | --- |
+{{range .Parameters}}| {{md .Name}}{{if .ReadOnly}} (read only){{end}} | {{md .Range}} | {{md .Default}} | {{md .Description}} |
+{{end}}{{end}}{{end}}{{if .Buses}}
+## Buses
+
+| Bus | Direction | Type | Channels | Active |
+| --- | --- | --- | --- | --- |
+{{range .Buses}}| {{md .Name}} | {{.Media}} {{.Direction | lower}} | {{.Kind}} | {{if eq .Media "Audio"}}{{.Channels}} ({{.Arrangement}}){{else}}-{{end}} | {{if .Active}}Yes{{else}}No{{end}} |
+{{end}}{{end}}{{end}}`
+
+// htmlTemplate lays out one or more references as a standalone page
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{range $i, $ref := .}}{{if $i}}, {{end}}{{.Info.Name}}{{end}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+</style>
+</head>
+<body>
+{{range .}}<section id="{{.Info.ID}}">
+<h1>{{.Info.Name}}</h1>
+<p>{{with .Info.Version}}Version {{.}}{{end}}{{with .Info.Vendor}} by {{.}}{{end}}{{with .Info.Category}}. Category: {{.}}{{end}}.{{if .Latency}} Latency: {{.Latency}} samples.{{end}}</p>
+{{if .Parameters}}<h2>Parameters</h2>
+{{range .Groups}}{{with .Name}}<h3>{{.}}</h3>
+{{end}}<table>
+<tr><th>Parameter</th><th>Range</th><th>Default</th><th>Description</th></tr>
+{{range .Parameters}}<tr><td>{{.Name}}{{if .ReadOnly}} (read only){{end}}</td><td>{{.Range}}</td><td>{{.Default}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{if .Buses}}<h2>Buses</h2>
+<table>
+<tr><th>Bus</th><th>Direction</th><th>Type</th><th>Channels</th><th>Active</th></tr>
+{{range .Buses}}<tr><td>{{.Name}}</td><td>{{.Media}} {{.Direction | lower}}</td><td>{{.Kind}}</td><td>{{if eq .Media "Audio"}}{{.Channels}} ({{.Arrangement}}){{else}}-{{end}}</td><td>{{if .Active}}Yes{{else}}No{{end}}</td></tr>
+{{end}}</table>
+{{end}}</section>
+{{end}}</body>
+</html>
+`
+
+var (
+	markdownDoc = template.Must(template.New("markdown").Funcs(template.FuncMap{
+		"md":    escapeMarkdown,
+		"lower": strings.ToLower,
+	}).Parse(markdownTemplate))
+
+	htmlDoc = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap{
+		"lower": strings.ToLower,
+	}).Parse(htmlTemplate))
+)
+
+// markdownEscaper escapes the characters that would break table cells or
+// start inline formatting
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"|", "\\|",
+	"*", "\\*",
+	"_", "\\_",
+	"`", "\\`",
+	"<", "&lt;",
+	"\r\n", " ",
+	"\n", " ",
+)
+
+// escapeMarkdown makes text safe inside a Markdown table cell
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(strings.TrimSpace(text))
+}
+
+// WriteMarkdown writes the references as Markdown, separated by rules
+func WriteMarkdown(w io.Writer, refs ...*Reference) error {
+	return markdownDoc.Execute(w, refs)
+}
+
+// WriteHTML writes the references as a standalone HTML page
+func WriteHTML(w io.Writer, refs ...*Reference) error {
+	return htmlDoc.Execute(w, refs)
+}
+
+// jsonReference is the JSON layout of a reference. Parameters carry the
+// full metadata, including hidden ones, for tools rather than readers.
+type jsonReference struct {
+	Info       fwplugin.Info    `json:"info"`
+	Latency    int32            `json:"latency"`
+	Parameters []param.Metadata `json:"parameters"`
+	Buses      []Bus            `json:"buses"`
+}
+
+// WriteJSON writes the references as an indented JSON array
+func WriteJSON(w io.Writer, refs ...*Reference) error {
+	out := make([]jsonReference, len(refs))
+	for i, ref := range refs {
+		out[i] = jsonReference{
+			Info:       ref.Info,
+			Latency:    ref.Latency,
+			Parameters: ref.metadata,
+			Buses:      ref.Buses,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
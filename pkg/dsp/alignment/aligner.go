@@ -0,0 +1,186 @@
+// Package alignment time-aligns a signal to a reference, such as a close
+// microphone to an overhead, by delaying it by the offset found with
+// cross-correlation
+package alignment
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis"
+	"github.com/justyntemme/vst3go/pkg/dsp/delay"
+)
+
+// Aligner defaults
+const (
+	DefaultMinConfidence = 0.5
+	crossfadeSeconds     = 0.01 // Crossfade when the correction changes
+)
+
+// Aligner measures how far a signal lags a reference and delays the signal
+// to line it up. Without latency compensation it can only delay, so only a
+// signal that leads the reference is corrected. With compensation the
+// signal always runs maxDelay samples late, which the plugin reports as
+// latency; the host then delays the reference track by the same amount and
+// the signal can be shifted either way.
+type Aligner struct {
+	sampleRate    float64
+	maxDelay      int
+	estimator     *analysis.DelayEstimator
+	lines         []*delay.Line
+	compensate    bool
+	auto          bool
+	minConfidence float64
+
+	correction int // Samples the signal is moved earlier
+	current    int // Delay line taps
+	previous   int
+	fadePos    int
+	fadeLen    int
+}
+
+// NewAligner creates an aligner for a signal with the given number of
+// channels and offsets up to maxDelayMs in either direction. Automatic
+// correction is on and latency compensation is off.
+func NewAligner(channels int, maxDelayMs, sampleRate float64) *Aligner {
+	maxDelay := max(1, int(math.Ceil(maxDelayMs*sampleRate/1000)))
+	a := &Aligner{
+		sampleRate:    sampleRate,
+		maxDelay:      maxDelay,
+		estimator:     analysis.NewDelayEstimator(maxDelay, sampleRate),
+		lines:         make([]*delay.Line, channels),
+		auto:          true,
+		minConfidence: DefaultMinConfidence,
+		fadeLen:       max(1, int(crossfadeSeconds*sampleRate)),
+	}
+	a.fadePos = a.fadeLen
+	// Room for the compensation offset plus the full correction range
+	capacity := (float64(2*a.maxDelay) + 0.5) / sampleRate
+	for i := range a.lines {
+		a.lines[i] = delay.New(capacity, sampleRate)
+	}
+	return a
+}
+
+// Estimator returns the delay estimator, e.g. to display the measured offset
+func (a *Aligner) Estimator() *analysis.DelayEstimator {
+	return a.estimator
+}
+
+// SetAuto sets whether the correction follows the measured delay
+func (a *Aligner) SetAuto(enabled bool) {
+	a.auto = enabled
+}
+
+// SetMinConfidence sets the estimator confidence needed before an automatic
+// correction is applied (0-1)
+func (a *Aligner) SetMinConfidence(confidence float64) {
+	a.minConfidence = math.Max(0, math.Min(1, confidence))
+}
+
+// SetLatencyCompensation sets whether the signal runs MaxDelay samples late
+// so it can be moved earlier. The plugin must report the new Latency to the
+// host when this changes.
+func (a *Aligner) SetLatencyCompensation(enabled bool) {
+	a.compensate = enabled
+	a.setTap(a.tapFor(a.correction))
+}
+
+// Latency returns the latency in samples the plugin must report
+func (a *Aligner) Latency() int {
+	if a.compensate {
+		return a.maxDelay
+	}
+	return 0
+}
+
+// MaxDelay returns the largest correction in samples
+func (a *Aligner) MaxDelay() int {
+	return a.maxDelay
+}
+
+// SetCorrection sets how many samples the signal is moved earlier, negative
+// to move it later, e.g. from a manual control or a stored measurement
+func (a *Aligner) SetCorrection(samples int) {
+	samples = max(-a.maxDelay, min(a.maxDelay, samples))
+	a.correction = samples
+	a.setTap(a.tapFor(samples))
+}
+
+// Correction returns the correction set by SetCorrection or the last
+// automatic update
+func (a *Aligner) Correction() int {
+	return a.correction
+}
+
+// CorrectionMs returns the correction in milliseconds
+func (a *Aligner) CorrectionMs() float64 {
+	return float64(a.correction) * 1000 / a.sampleRate
+}
+
+// AppliedDelay returns the delay in samples applied to the signal, including
+// the latency compensation offset
+func (a *Aligner) AppliedDelay() int {
+	return a.current
+}
+
+// tapFor returns the delay line tap for a correction. Without compensation
+// a signal that lags cannot be moved earlier.
+func (a *Aligner) tapFor(correction int) int {
+	return max(0, a.Latency()-correction)
+}
+
+// setTap moves the delay line tap, crossfading from the old one
+func (a *Aligner) setTap(tap int) {
+	if tap == a.current {
+		return
+	}
+	a.previous = a.current
+	a.current = tap
+	a.fadePos = 0
+}
+
+// Process analyzes the first signal channel against the reference and
+// delays the signal channels in place
+func (a *Aligner) Process(signal [][]float32, reference []float32) {
+	if len(signal) > 0 && a.estimator.Process(signal[0], reference) && a.auto &&
+		a.estimator.Confidence() >= a.minConfidence {
+		a.SetCorrection(a.estimator.DelaySamples())
+	}
+
+	fadeStart := a.fadePos
+	for ch, samples := range signal {
+		if ch >= len(a.lines) {
+			break
+		}
+		line := a.lines[ch]
+		fade := fadeStart
+		for i, x := range samples {
+			y := read(line, x, a.current)
+			if fade < a.fadeLen {
+				g := float32(fade) / float32(a.fadeLen)
+				y = g*y + (1-g)*read(line, x, a.previous)
+				fade++
+			}
+			line.Write(x)
+			samples[i] = y
+		}
+		a.fadePos = fade
+	}
+}
+
+// read returns the sample tap samples before x, which is not yet written
+func read(line *delay.Line, x float32, tap int) float32 {
+	if tap == 0 {
+		return x
+	}
+	return line.Read(float64(tap))
+}
+
+// Reset clears the delay lines and the estimate and keeps the correction
+func (a *Aligner) Reset() {
+	a.estimator.Reset()
+	for _, line := range a.lines {
+		line.Reset()
+	}
+	a.fadePos = a.fadeLen
+}
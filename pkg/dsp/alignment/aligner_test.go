@@ -0,0 +1,107 @@
+package alignment
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// offsetPair returns a noise reference and a signal lagging it by lag samples
+func offsetPair(n, lag int) (signal, reference []float32) {
+	rng := rand.New(rand.NewSource(3))
+	noise := make([]float32, n+200)
+	for i := range noise {
+		noise[i] = float32(rng.Float64()*2 - 1)
+	}
+	reference = noise[100 : 100+n]
+	signal = make([]float32, n)
+	for i := range signal {
+		signal[i] = noise[100+i-lag]
+	}
+	return signal, reference
+}
+
+// run processes the pair in blocks and returns the aligned signal
+func run(a *Aligner, signal, reference []float32) []float32 {
+	out := append([]float32(nil), signal...)
+	const block = 128
+	for i := 0; i+block <= len(out); i += block {
+		a.Process([][]float32{out[i : i+block]}, reference[i:i+block])
+	}
+	return out
+}
+
+// checkAligned verifies out[i] == reference[i-latency] over the last samples
+func checkAligned(t *testing.T, out, reference []float32, latency int) {
+	t.Helper()
+	for i := len(out) - 2000; i < len(out)-128; i++ {
+		if math.Abs(float64(out[i]-reference[i-latency])) > 1e-6 {
+			t.Fatalf("sample %d not aligned: %g vs %g", i, out[i], reference[i-latency])
+		}
+	}
+}
+
+func TestAlignerLeadingSignal(t *testing.T) {
+	a := NewAligner(1, 2, 48000)
+	signal, reference := offsetPair(16384, -15)
+
+	out := run(a, signal, reference)
+	if a.Correction() != -15 || a.AppliedDelay() != 15 || a.Latency() != 0 {
+		t.Fatalf("correction %d, delay %d, latency %d", a.Correction(), a.AppliedDelay(), a.Latency())
+	}
+	checkAligned(t, out, reference, 0)
+}
+
+func TestAlignerLatencyCompensation(t *testing.T) {
+	a := NewAligner(1, 2, 48000)
+	a.SetLatencyCompensation(true)
+	signal, reference := offsetPair(16384, 20)
+
+	out := run(a, signal, reference)
+	if a.Latency() != a.MaxDelay() || a.Correction() != 20 {
+		t.Fatalf("latency %d, correction %d", a.Latency(), a.Correction())
+	}
+	if math.Abs(a.CorrectionMs()-20.0/48) > 1e-9 {
+		t.Errorf("correction %g ms", a.CorrectionMs())
+	}
+	checkAligned(t, out, reference, a.Latency())
+}
+
+func TestAlignerLaggingWithoutCompensation(t *testing.T) {
+	a := NewAligner(1, 2, 48000)
+	signal, reference := offsetPair(16384, 20)
+
+	run(a, signal, reference)
+	if a.Correction() != 20 {
+		t.Errorf("expected the lag to be measured, got %d", a.Correction())
+	}
+	if a.AppliedDelay() != 0 {
+		t.Errorf("a lagging signal cannot be moved earlier without compensation, delay %d", a.AppliedDelay())
+	}
+}
+
+func TestAlignerManual(t *testing.T) {
+	a := NewAligner(2, 2, 48000)
+	a.SetAuto(false)
+	a.SetCorrection(-1000)
+	if a.Correction() != -a.MaxDelay() {
+		t.Errorf("correction should be clamped to %d, got %d", -a.MaxDelay(), a.Correction())
+	}
+
+	a.SetCorrection(-10)
+	signal, reference := offsetPair(8192, 0)
+	left := append([]float32(nil), signal...)
+	right := append([]float32(nil), signal...)
+	const block = 128
+	for i := 0; i+block <= len(left); i += block {
+		a.Process([][]float32{left[i : i+block], right[i : i+block]}, reference[i:i+block])
+	}
+	if a.Correction() != -10 {
+		t.Errorf("manual correction changed to %d", a.Correction())
+	}
+	for i := 4000; i < 4100; i++ {
+		if left[i] != signal[i-10] || right[i] != signal[i-10] {
+			t.Fatalf("sample %d not delayed by 10", i)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package analysis
+
+import (
+	"math"
+	"sync"
+)
+
+// BandCorrelationMeter measures stereo correlation per frequency band using
+// the FFT cross-spectrum, showing where in the spectrum phase problems occur
+type BandCorrelationMeter struct {
+	fftSize    int
+	hopSize    int
+	sampleRate float64
+	fft        *FFT
+
+	// Input buffers
+	bufferL  []float64
+	bufferR  []float64
+	writePos int
+
+	// Spectrum scratch (pre-allocated)
+	realL, imagL []float64
+
+	// Band layout
+	centerFreqs []float64
+	lowerBins   []int
+	upperBins   []int
+
+	// Averaged per-band statistics
+	cross     []float64 // Re{L * conj(R)}
+	powerL    []float64
+	powerR    []float64
+	smoothing float64
+	hasData   bool
+
+	mu sync.Mutex
+}
+
+// NewBandCorrelationMeter creates a per-band correlation meter. Band edges lie
+// halfway (geometrically) between adjacent center frequencies, so any band
+// layout works, e.g. StandardOctaveBands or StandardThirdOctaveBands.
+func NewBandCorrelationMeter(fftSize int, sampleRate float64, centerFreqs []float64) *BandCorrelationMeter {
+	numBands := len(centerFreqs)
+	bcm := &BandCorrelationMeter{
+		fftSize:     fftSize,
+		hopSize:     fftSize / 2,
+		sampleRate:  sampleRate,
+		fft:         NewFFT(fftSize, HannWindow),
+		bufferL:     make([]float64, fftSize),
+		bufferR:     make([]float64, fftSize),
+		realL:       make([]float64, fftSize/2+1),
+		imagL:       make([]float64, fftSize/2+1),
+		centerFreqs: make([]float64, numBands),
+		lowerBins:   make([]int, numBands),
+		upperBins:   make([]int, numBands),
+		cross:       make([]float64, numBands),
+		powerL:      make([]float64, numBands),
+		powerR:      make([]float64, numBands),
+		smoothing:   0.8,
+	}
+	copy(bcm.centerFreqs, centerFreqs)
+	bcm.calculateBandBins()
+
+	return bcm
+}
+
+// calculateBandBins maps band edges onto FFT bins
+func (bcm *BandCorrelationMeter) calculateBandBins() {
+	n := len(bcm.centerFreqs)
+	binWidth := bcm.sampleRate / float64(bcm.fftSize)
+	maxBin := bcm.fftSize / 2
+
+	for i, center := range bcm.centerFreqs {
+		var lower, upper float64
+		switch {
+		case n == 1:
+			lower, upper = center/math.Sqrt2, center*math.Sqrt2
+		case i == 0:
+			upper = math.Sqrt(center * bcm.centerFreqs[i+1])
+			lower = center * center / upper
+		case i == n-1:
+			lower = math.Sqrt(bcm.centerFreqs[i-1] * center)
+			upper = center * center / lower
+		default:
+			lower = math.Sqrt(bcm.centerFreqs[i-1] * center)
+			upper = math.Sqrt(center * bcm.centerFreqs[i+1])
+		}
+
+		lo := int(math.Ceil(lower / binWidth))
+		hi := int(math.Floor(upper / binWidth))
+		if lo < 1 {
+			lo = 1 // Skip DC
+		}
+		if hi > maxBin {
+			hi = maxBin
+		}
+		if hi < lo {
+			// Narrow band at low frequency: use the nearest bin
+			nearest := int(math.Round(center / binWidth))
+			if nearest < 1 {
+				nearest = 1
+			}
+			if nearest > maxBin {
+				nearest = maxBin
+			}
+			lo, hi = nearest, nearest
+		}
+
+		bcm.lowerBins[i] = lo
+		bcm.upperBins[i] = hi
+	}
+}
+
+// SetSmoothing sets the exponential averaging factor between FFT frames (0-1)
+func (bcm *BandCorrelationMeter) SetSmoothing(smoothing float64) {
+	bcm.mu.Lock()
+	defer bcm.mu.Unlock()
+
+	if smoothing >= 0 && smoothing < 1 {
+		bcm.smoothing = smoothing
+	}
+}
+
+// SetHopSize sets the number of samples between FFT frames
+func (bcm *BandCorrelationMeter) SetHopSize(hopSize int) {
+	bcm.mu.Lock()
+	defer bcm.mu.Unlock()
+
+	if hopSize > 0 && hopSize <= bcm.fftSize {
+		bcm.hopSize = hopSize
+	}
+}
+
+// Process adds stereo samples and returns true when new band values are available
+func (bcm *BandCorrelationMeter) Process(samplesL, samplesR []float64) bool {
+	bcm.mu.Lock()
+	defer bcm.mu.Unlock()
+
+	if len(samplesL) != len(samplesR) {
+		return false
+	}
+
+	updated := false
+	for i := range samplesL {
+		bcm.bufferL[bcm.writePos] = samplesL[i]
+		bcm.bufferR[bcm.writePos] = samplesR[i]
+		bcm.writePos++
+
+		if bcm.writePos >= bcm.fftSize {
+			bcm.analyzeFrame()
+			updated = true
+
+			// Keep the overlap for the next frame
+			if bcm.hopSize < bcm.fftSize {
+				copy(bcm.bufferL, bcm.bufferL[bcm.hopSize:])
+				copy(bcm.bufferR, bcm.bufferR[bcm.hopSize:])
+				bcm.writePos = bcm.fftSize - bcm.hopSize
+			} else {
+				bcm.writePos = 0
+			}
+		}
+	}
+
+	return updated
+}
+
+// analyzeFrame computes the cross-spectrum of the current frame and updates band averages
+func (bcm *BandCorrelationMeter) analyzeFrame() {
+	magL, phaseL := bcm.fft.Forward(bcm.bufferL)
+	for k := range bcm.realL {
+		bcm.realL[k] = magL[k] * math.Cos(phaseL[k])
+		bcm.imagL[k] = magL[k] * math.Sin(phaseL[k])
+	}
+
+	magR, phaseR := bcm.fft.Forward(bcm.bufferR)
+
+	alpha := bcm.smoothing
+	if !bcm.hasData {
+		alpha = 0
+		bcm.hasData = true
+	}
+
+	for b := range bcm.centerFreqs {
+		cross, pl, pr := 0.0, 0.0, 0.0
+		for k := bcm.lowerBins[b]; k <= bcm.upperBins[b]; k++ {
+			realR := magR[k] * math.Cos(phaseR[k])
+			imagR := magR[k] * math.Sin(phaseR[k])
+
+			cross += bcm.realL[k]*realR + bcm.imagL[k]*imagR
+			pl += bcm.realL[k]*bcm.realL[k] + bcm.imagL[k]*bcm.imagL[k]
+			pr += magR[k] * magR[k]
+		}
+
+		bcm.cross[b] = alpha*bcm.cross[b] + (1-alpha)*cross
+		bcm.powerL[b] = alpha*bcm.powerL[b] + (1-alpha)*pl
+		bcm.powerR[b] = alpha*bcm.powerR[b] + (1-alpha)*pr
+	}
+}
+
+// bandCorrelation returns the correlation for a band (0 when silent)
+func (bcm *BandCorrelationMeter) bandCorrelation(b int) float64 {
+	denom := math.Sqrt(bcm.powerL[b] * bcm.powerR[b])
+	if denom < 1e-20 {
+		return 0
+	}
+	return math.Max(-1, math.Min(1, bcm.cross[b]/denom))
+}
+
+// GetCorrelations returns the correlation coefficient (-1 to +1) of each band
+func (bcm *BandCorrelationMeter) GetCorrelations() []float64 {
+	bcm.mu.Lock()
+	defer bcm.mu.Unlock()
+
+	result := make([]float64, len(bcm.centerFreqs))
+	for b := range result {
+		result[b] = bcm.bandCorrelation(b)
+	}
+	return result
+}
+
+// GetPhaseStatus returns the qualitative phase status of each band
+func (bcm *BandCorrelationMeter) GetPhaseStatus() []PhaseStatus {
+	correlations := bcm.GetCorrelations()
+	status := make([]PhaseStatus, len(correlations))
+	for b, corr := range correlations {
+		status[b] = phaseStatusFor(corr)
+	}
+	return status
+}
+
+// GetBandLevelsDB returns the combined L+R level of each band in dB, useful for
+// hiding correlation readings of bands that carry no signal
+func (bcm *BandCorrelationMeter) GetBandLevelsDB() []float64 {
+	bcm.mu.Lock()
+	defer bcm.mu.Unlock()
+
+	levels := make([]float64, len(bcm.centerFreqs))
+	for b := range levels {
+		power := bcm.powerL[b] + bcm.powerR[b]
+		if power > 0 {
+			levels[b] = 10.0 * math.Log10(power)
+		} else {
+			levels[b] = -120.0
+		}
+	}
+	return levels
+}
+
+// GetCenterFrequencies returns the band center frequencies
+func (bcm *BandCorrelationMeter) GetCenterFrequencies() []float64 {
+	result := make([]float64, len(bcm.centerFreqs))
+	copy(result, bcm.centerFreqs)
+	return result
+}
+
+// GetWorstBand returns the index and correlation of the most out-of-phase band
+// among bands within rangeDB of the loudest band
+func (bcm *BandCorrelationMeter) GetWorstBand(rangeDB float64) (int, float64) {
+	correlations := bcm.GetCorrelations()
+	levels := bcm.GetBandLevelsDB()
+
+	loudest := -math.MaxFloat64
+	for _, level := range levels {
+		loudest = math.Max(loudest, level)
+	}
+
+	worst, worstCorr := -1, 1.0
+	for b, corr := range correlations {
+		if levels[b] < loudest-rangeDB {
+			continue
+		}
+		if worst < 0 || corr < worstCorr {
+			worst, worstCorr = b, corr
+		}
+	}
+	return worst, worstCorr
+}
+
+// Reset clears all buffers and averages
+func (bcm *BandCorrelationMeter) Reset() {
+	bcm.mu.Lock()
+	defer bcm.mu.Unlock()
+
+	for i := range bcm.bufferL {
+		bcm.bufferL[i] = 0
+		bcm.bufferR[i] = 0
+	}
+	for b := range bcm.cross {
+		bcm.cross[b] = 0
+		bcm.powerL[b] = 0
+		bcm.powerR[b] = 0
+	}
+	bcm.writePos = 0
+	bcm.hasData = false
+}
@@ -157,8 +157,11 @@ func (cm *CorrelationMeter) GetPeakHold() float64 {
 
 // GetPhaseStatus returns a qualitative phase status
 func (cm *CorrelationMeter) GetPhaseStatus() PhaseStatus {
-	corr := cm.GetCorrelation()
-	
+	return phaseStatusFor(cm.GetCorrelation())
+}
+
+// phaseStatusFor maps a correlation coefficient to a qualitative status
+func phaseStatusFor(corr float64) PhaseStatus {
 	if corr > 0.9 {
 		return PhaseInPhase
 	} else if corr > 0.5 {
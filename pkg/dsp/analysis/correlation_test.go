@@ -373,4 +373,57 @@ func BenchmarkStereoFieldAnalyzer(b *testing.B) {
 		sfa.Process(samplesL, samplesR)
 		sfa.GetAnalysis()
 	}
-}
\ No newline at end of file
+}
+
+func TestBandCorrelationMeter(t *testing.T) {
+	sampleRate := 48000.0
+	bcm := NewBandCorrelationMeter(2048, sampleRate, StandardOctaveBands())
+
+	// 250 Hz in phase, 4 kHz inverted on the right channel
+	n := 16384
+	left := make([]float64, n)
+	right := make([]float64, n)
+	for i := 0; i < n; i++ {
+		tm := float64(i) / sampleRate
+		low := math.Sin(2 * math.Pi * 250 * tm)
+		high := 0.5 * math.Sin(2*math.Pi*4000*tm)
+		left[i] = low + high
+		right[i] = low - high
+	}
+
+	if !bcm.Process(left, right) {
+		t.Fatal("Expected band correlations to be updated")
+	}
+
+	freqs := bcm.GetCenterFrequencies()
+	correlations := bcm.GetCorrelations()
+	status := bcm.GetPhaseStatus()
+
+	for b, f := range freqs {
+		switch f {
+		case 250:
+			if correlations[b] < 0.95 {
+				t.Errorf("250 Hz band should be in phase, got %f", correlations[b])
+			}
+		case 4000:
+			if correlations[b] > -0.95 {
+				t.Errorf("4 kHz band should be out of phase, got %f", correlations[b])
+			}
+			if status[b] != PhaseOutOfPhase {
+				t.Errorf("4 kHz band status should be out of phase, got %v", status[b])
+			}
+		}
+	}
+
+	worst, corr := bcm.GetWorstBand(20)
+	if worst < 0 || freqs[worst] != 4000 || corr > -0.95 {
+		t.Errorf("Worst band should be 4 kHz, got index %d (%f)", worst, corr)
+	}
+
+	bcm.Reset()
+	for _, c := range bcm.GetCorrelations() {
+		if c != 0 {
+			t.Errorf("Expected zero correlation after reset, got %f", c)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package analysis
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/justyntemme/vst3go/pkg/rt"
+)
+
+// Delay estimator defaults
+const (
+	minDelayWindow         = 2048
+	defaultDelayMinLevelDB = -60.0
+)
+
+// DelayEstimator finds the time offset between a signal and a reference,
+// such as two microphones on one source, by FFT cross-correlation. It
+// analyzes a window of at least four times the largest delay, every half
+// window. All buffers are allocated up front, so Process is safe on the
+// audio thread; the results may be read from any goroutine.
+type DelayEstimator struct {
+	sampleRate float64
+	maxLag     int
+	window     int
+	hop        int
+	minEnergy  float64 // Per-sample mean square below which a window is skipped
+
+	// Input history
+	signal    []float64
+	reference []float64
+	writePos  int
+	filled    int
+	sinceLast int
+
+	// Correlation scratch
+	fft       *FFT
+	a, b      []float64
+	corr, tmp []float64
+
+	delay      rt.Float64
+	confidence rt.Float64
+	inverted   atomic.Bool
+	valid      atomic.Bool
+}
+
+// NewDelayEstimator creates an estimator for delays up to maxDelaySamples in
+// either direction
+func NewDelayEstimator(maxDelaySamples int, sampleRate float64) *DelayEstimator {
+	maxLag := max(1, maxDelaySamples)
+	window := minDelayWindow
+	for window < 4*maxLag {
+		window <<= 1
+	}
+
+	de := &DelayEstimator{
+		sampleRate: sampleRate,
+		maxLag:     maxLag,
+		window:     window,
+		hop:        window / 2,
+		signal:     make([]float64, window),
+		reference:  make([]float64, window),
+		fft:        NewFFT(2*window, RectangularWindow),
+		a:          make([]float64, window),
+		b:          make([]float64, window),
+		corr:       make([]float64, 2*window),
+		tmp:        make([]float64, 2*window),
+	}
+	de.SetMinLevel(defaultDelayMinLevelDB)
+	return de
+}
+
+// SetMinLevel sets the RMS level in dBFS below which either input counts as
+// silent and the estimate is left unchanged (default -60 dB)
+func (de *DelayEstimator) SetMinLevel(db float64) {
+	rms := math.Pow(10, db/20)
+	de.minEnergy = rms * rms
+}
+
+// MaxDelay returns the largest delay in samples that can be detected
+func (de *DelayEstimator) MaxDelay() int {
+	return de.maxLag
+}
+
+// Process adds a block of the signal and the reference and returns true when
+// a new estimate was made
+func (de *DelayEstimator) Process(signal, reference []float32) bool {
+	n := min(len(signal), len(reference))
+	updated := false
+	for i := 0; i < n; i++ {
+		de.signal[de.writePos] = float64(signal[i])
+		de.reference[de.writePos] = float64(reference[i])
+		de.writePos++
+		if de.writePos == de.window {
+			de.writePos = 0
+		}
+		if de.filled < de.window {
+			de.filled++
+		}
+
+		de.sinceLast++
+		if de.filled == de.window && de.sinceLast >= de.hop {
+			de.sinceLast = 0
+			if de.estimate() {
+				updated = true
+			}
+		}
+	}
+	return updated
+}
+
+// estimate correlates the current window and publishes the strongest lag
+func (de *DelayEstimator) estimate() bool {
+	// Unroll the rings, oldest sample first
+	energyA, energyB := 0.0, 0.0
+	for i := 0; i < de.window; i++ {
+		j := (de.writePos + i) % de.window
+		de.a[i] = de.reference[j]
+		de.b[i] = de.signal[j]
+		energyA += de.a[i] * de.a[i]
+		energyB += de.b[i] * de.b[i]
+	}
+	minEnergy := de.minEnergy * float64(de.window)
+	if energyA < minEnergy || energyB < minEnergy {
+		return false
+	}
+
+	// corr[k] peaks where the signal lags the reference by k samples
+	de.fft.crossCorrelate(de.a, de.b, de.corr, de.tmp)
+	size := len(de.corr)
+	at := func(lag int) float64 {
+		if lag < 0 {
+			lag += size
+		}
+		return de.corr[lag]
+	}
+
+	bestLag := 0
+	best := 0.0
+	for lag := -de.maxLag; lag <= de.maxLag; lag++ {
+		if v := at(lag); math.Abs(v) > math.Abs(best) {
+			best = v
+			bestLag = lag
+		}
+	}
+
+	// Refine to a fractional lag with a parabola through the neighbors
+	delay := float64(bestLag)
+	if bestLag > -de.maxLag && bestLag < de.maxLag {
+		sign := math.Copysign(1, best)
+		l, c, r := sign*at(bestLag-1), sign*at(bestLag), sign*at(bestLag+1)
+		if d := l - 2*c + r; d < 0 {
+			delay += math.Max(-0.5, math.Min(0.5, 0.5*(l-r)/d))
+		}
+	}
+
+	confidence := math.Min(1, math.Abs(best)/math.Sqrt(energyA*energyB))
+	de.delay.Store(delay)
+	de.confidence.Store(confidence)
+	de.inverted.Store(best < 0)
+	de.valid.Store(true)
+	return true
+}
+
+// Delay returns how many samples the signal lags the reference, negative if
+// it leads, with sub-sample precision
+func (de *DelayEstimator) Delay() float64 {
+	return de.delay.Load()
+}
+
+// DelaySamples returns the delay rounded to whole samples
+func (de *DelayEstimator) DelaySamples() int {
+	return int(math.Round(de.Delay()))
+}
+
+// DelayMs returns the delay in milliseconds
+func (de *DelayEstimator) DelayMs() float64 {
+	return de.Delay() * 1000 / de.sampleRate
+}
+
+// Confidence returns the normalized correlation at the delay, from 0 to 1
+func (de *DelayEstimator) Confidence() float64 {
+	return de.confidence.Load()
+}
+
+// Inverted reports whether the signal correlates best with the reference
+// in opposite polarity
+func (de *DelayEstimator) Inverted() bool {
+	return de.inverted.Load()
+}
+
+// HasEstimate reports whether an estimate has been made since the last reset
+func (de *DelayEstimator) HasEstimate() bool {
+	return de.valid.Load()
+}
+
+// Reset clears the history and the current estimate
+func (de *DelayEstimator) Reset() {
+	for i := range de.signal {
+		de.signal[i] = 0
+		de.reference[i] = 0
+	}
+	de.writePos, de.filled, de.sinceLast = 0, 0, 0
+	de.delay.Store(0)
+	de.confidence.Store(0)
+	de.inverted.Store(false)
+	de.valid.Store(false)
+}
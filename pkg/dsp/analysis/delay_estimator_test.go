@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// delayedPair returns noise and a copy delayed by delay samples (negative
+// delays lead) and scaled by gain
+func delayedPair(n, delay int, gain float32) (signal, reference []float32) {
+	rng := rand.New(rand.NewSource(1))
+	noise := make([]float32, n+200)
+	for i := range noise {
+		noise[i] = float32(rng.Float64()*2 - 1)
+	}
+	reference = noise[100 : 100+n]
+	signal = make([]float32, n)
+	for i := range signal {
+		signal[i] = gain * noise[100+i-delay]
+	}
+	return signal, reference
+}
+
+func TestDelayEstimator(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    int
+		gain     float32
+		inverted bool
+	}{
+		{"lagging", 37, 0.5, false},
+		{"leading", -12, 1, false},
+		{"aligned", 0, 1, false},
+		{"inverted", 23, -0.8, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			de := NewDelayEstimator(64, 48000)
+			signal, reference := delayedPair(8192, tt.delay, tt.gain)
+
+			updated := false
+			for i := 0; i < len(signal); i += 256 {
+				if de.Process(signal[i:i+256], reference[i:i+256]) {
+					updated = true
+				}
+			}
+			if !updated || !de.HasEstimate() {
+				t.Fatal("no estimate made")
+			}
+			if de.DelaySamples() != tt.delay || math.Abs(de.Delay()-float64(tt.delay)) > 0.1 {
+				t.Errorf("delay %g, want %d", de.Delay(), tt.delay)
+			}
+			if de.Inverted() != tt.inverted {
+				t.Errorf("inverted %v, want %v", de.Inverted(), tt.inverted)
+			}
+			if de.Confidence() < 0.9 {
+				t.Errorf("confidence %g too low for a pure delay", de.Confidence())
+			}
+			if want := float64(tt.delay) / 48; math.Abs(de.DelayMs()-want) > 0.01 {
+				t.Errorf("delay %g ms, want %g", de.DelayMs(), want)
+			}
+		})
+	}
+}
+
+func TestDelayEstimatorSilence(t *testing.T) {
+	de := NewDelayEstimator(64, 48000)
+	_, reference := delayedPair(8192, 0, 1)
+	silence := make([]float32, len(reference))
+
+	de.Process(silence, reference)
+	if de.HasEstimate() {
+		t.Error("a silent input should not produce an estimate")
+	}
+
+	de.Reset()
+	if de.Delay() != 0 || de.Confidence() != 0 {
+		t.Error("reset should clear the estimate")
+	}
+}
+
+func TestCrossCorrelationLag(t *testing.T) {
+	signal, reference := delayedPair(256, 9, 1)
+	a := make([]float64, len(reference))
+	b := make([]float64, len(signal))
+	for i := range a {
+		a[i] = float64(reference[i])
+		b[i] = float64(signal[i])
+	}
+
+	corr := CrossCorrelation(a, b)
+	peak := 0
+	for i := range corr {
+		if corr[i] > corr[peak] {
+			peak = i
+		}
+	}
+	if lag := peak - (len(a) - 1); lag != 9 {
+		t.Errorf("peak at lag %d, want 9", lag)
+	}
+
+	// Lag zero equals the dot product
+	dot := 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	if math.Abs(corr[len(a)-1]-dot) > 1e-9 {
+		t.Errorf("zero lag %g, want %g", corr[len(a)-1], dot)
+	}
+}
@@ -6,18 +6,28 @@
 // FFT and Spectral Analysis:
 //   - FFT with multiple window functions (Hann, Hamming, Blackman, etc.)
 //   - Real-time spectrum analyzer with averaging modes
+//   - Lock-free spectrum hand-off to the UI with log-spaced display bins
 //   - Octave and third-octave band analysis
 //   - Cross-correlation using FFT
+//   - Delay estimation between a signal and a reference for time alignment
+//
+// Perceptual Filter Banks:
+//   - Gammatone filter bank with Bark or ERB band spacing
+//   - Per-band envelope levels and band-split signals
 //
 // Level Metering:
 //   - Peak meter with hold and decay
+//   - VU, PPM Type I/II and EBU PPM ballistics on peak and RMS meters
 //   - RMS (Root Mean Square) meter
 //   - LUFS meter (ITU-R BS.1770-4 compliant)
 //   - Momentary, short-term, and integrated loudness
 //   - Loudness range (LRA) measurement
+//   - True-peak meter (ITU-R BS.1770-4 4x oversampling, dBTP)
+//   - EBU R128 compliance report with configurable delivery targets
 //
 // Stereo Field Analysis:
 //   - Correlation meter for phase relationships
+//   - Per-band correlation from the FFT cross-spectrum
 //   - Balance meter for L/R power distribution
 //   - Stereo width meter using M/S analysis
 //   - Mono compatibility checking
@@ -28,6 +38,11 @@
 //   - Vector scope with graticule
 //   - Polar coordinate display
 //
+// Rhythm Analysis:
+//   - Tempo detection from onset strength autocorrelation
+//   - Onset detection (energy or spectral flux) with sample-accurate triggers
+//   - Transient and sustain separation (subpackage transient)
+//
 // All analysis tools are designed for real-time operation with minimal
 // allocations and thread-safe access.
 //
@@ -2,7 +2,8 @@ package analysis
 
 import (
 	"math"
-	"math/cmplx"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
 )
 
 // FFT performs a Fast Fourier Transform on the input data
@@ -90,7 +91,7 @@ func (f *FFT) calculateWindow() {
 		beta := 8.6
 		for i := 0; i < f.size; i++ {
 			x := 2.0*float64(i)/(n-1.0) - 1.0
-			f.windowData[i] = bessel0(beta*math.Sqrt(1.0-x*x)) / bessel0(beta)
+			f.windowData[i] = filter.BesselI0(beta*math.Sqrt(1.0-x*x)) / filter.BesselI0(beta)
 		}
 	
 	case FlatTopWindow:
@@ -255,28 +256,6 @@ func (f *FFT) GetFrequencyBin(bin int, sampleRate float64) float64 {
 	return float64(bin) * sampleRate / float64(f.size)
 }
 
-// bessel0 computes the modified Bessel function of the first kind, order 0
-func bessel0(x float64) float64 {
-	if x == 0.0 {
-		return 1.0
-	}
-	
-	ax := math.Abs(x)
-	
-	if ax < 3.75 {
-		y := x / 3.75
-		y *= y
-		return 1.0 + y*(3.5156229+y*(3.0899424+y*(1.2067492+
-			y*(0.2659732+y*(0.360768e-1+y*0.45813e-2)))))
-	}
-	
-	y := 3.75 / ax
-	return (math.Exp(ax) / math.Sqrt(ax)) * (0.39894228 + y*(0.1328592e-1+
-		y*(0.225319e-2+y*(-0.157565e-2+y*(0.916281e-2+
-		y*(-0.2057706e-1+y*(0.2635537e-1+y*(-0.1647633e-1+
-		y*0.392377e-2))))))))
-}
-
 // PowerSpectrum calculates the power spectrum from magnitude spectrum
 func PowerSpectrum(magnitude []float64) []float64 {
 	power := make([]float64, len(magnitude))
@@ -297,7 +276,9 @@ func ApplyHannWindow(data []float64) []float64 {
 	return windowed
 }
 
-// CrossCorrelation computes the cross-correlation between two signals using FFT
+// CrossCorrelation computes the cross-correlation between two signals using
+// FFT. result[n-1+k] is the correlation of a[i] with b[i+k], so a peak at
+// positive k means b lags a by k samples.
 func CrossCorrelation(a, b []float64) []float64 {
 	n := len(a)
 	if len(b) != n {
@@ -311,42 +292,58 @@ func CrossCorrelation(a, b []float64) []float64 {
 	}
 	
 	fft := NewFFT(size, RectangularWindow)
-	
-	// Zero-pad inputs
-	paddedA := make([]float64, size)
-	paddedB := make([]float64, size)
-	copy(paddedA, a)
-	copy(paddedB, b)
-	
-	// Forward FFT
-	magA, phaseA := fft.Forward(paddedA)
-	magB, phaseB := fft.Forward(paddedB)
-	
-	// Multiply A with conjugate of B
-	realResult := make([]float64, size)
-	imagResult := make([]float64, size)
-	for i := 0; i < size; i++ {
-		// Convert to complex, multiply, then back to real/imag
-		aComplex := cmplx.Rect(magA[i%len(magA)], phaseA[i%len(phaseA)])
-		bComplex := cmplx.Rect(magB[i%len(magB)], -phaseB[i%len(phaseB)]) // conjugate
-		result := aComplex * bComplex
-		realResult[i] = real(result)
-		imagResult[i] = imag(result)
-	}
-	
-	// Inverse FFT
-	correlation := fft.Inverse(realResult, imagResult)
+	re := make([]float64, size)
+	im := make([]float64, size)
+	fft.crossCorrelate(a, b, re, im)
 	
 	// Extract valid correlation values
 	result := make([]float64, 2*n-1)
 	// Negative lags
 	for i := 0; i < n-1; i++ {
-		result[i] = correlation[size-n+1+i]
+		result[i] = re[size-n+1+i]
 	}
 	// Zero and positive lags
 	for i := 0; i < n; i++ {
-		result[n-1+i] = correlation[i]
+		result[n-1+i] = re[i]
 	}
 	
 	return result
-}
\ No newline at end of file
+}
+
+// crossCorrelate computes the circular cross-correlation of a and b into re,
+// with re[k] = sum of a[i]*b[i+k]. Inputs shorter than the FFT size are
+// zero-padded; pad to at least twice their length to avoid wrap-around. im is
+// scratch. Both signals share one forward transform, as the real and
+// imaginary parts of a complex input, and nothing is allocated.
+func (f *FFT) crossCorrelate(a, b, re, im []float64) {
+	n := f.size
+	for i := 0; i < n; i++ {
+		re[i], im[i] = 0, 0
+	}
+	copy(re[:n], a)
+	copy(im[:n], b)
+	f.fft(re, im)
+	
+	// Split Z = A + iB using the symmetry of real spectra, then form conj(A)*B.
+	// The result is real, so C[n-k] = conj(C[k]).
+	for k := 0; k <= n/2; k++ {
+		j := (n - k) % n
+		zr, zi := re[k], im[k]
+		wr, wi := re[j], im[j]
+		ar, ai := (zr+wr)/2, (zi-wi)/2
+		br, bi := (zi+wi)/2, (wr-zr)/2
+		cr, ci := ar*br+ai*bi, ar*bi-ai*br
+		re[k], im[k] = cr, ci
+		re[j], im[j] = cr, -ci
+	}
+	
+	// Inverse transform via conjugation
+	for i := 0; i < n; i++ {
+		im[i] = -im[i]
+	}
+	f.fft(re, im)
+	scale := 1.0 / float64(n)
+	for i := 0; i < n; i++ {
+		re[i] *= scale
+	}
+}
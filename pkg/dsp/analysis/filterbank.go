@@ -0,0 +1,300 @@
+package analysis
+
+import (
+	"math"
+	"sync"
+)
+
+// FrequencyScale defines the perceptual scale used to space filter bank bands
+type FrequencyScale int
+
+const (
+	// BarkScale spaces bands by critical band rate (Zwicker)
+	BarkScale FrequencyScale = iota
+	// ERBScale spaces bands by equivalent rectangular bandwidth (Glasberg & Moore)
+	ERBScale
+)
+
+// gammatoneOrder is the number of cascaded complex one-pole stages per band
+const gammatoneOrder = 4
+
+// HzToBark converts frequency in Hz to the Bark scale (Traunmüller)
+func HzToBark(freq float64) float64 {
+	return 26.81*freq/(1960.0+freq) - 0.53
+}
+
+// BarkToHz converts a Bark value back to frequency in Hz
+func BarkToHz(bark float64) float64 {
+	return 1960.0 * (bark + 0.53) / (26.28 - bark)
+}
+
+// CriticalBandwidth returns the Zwicker critical bandwidth in Hz at freq
+func CriticalBandwidth(freq float64) float64 {
+	khz := freq / 1000.0
+	return 25.0 + 75.0*math.Pow(1.0+1.4*khz*khz, 0.69)
+}
+
+// HzToERBRate converts frequency in Hz to the ERB-rate scale (ERB number)
+func HzToERBRate(freq float64) float64 {
+	return 21.4 * math.Log10(1.0+0.00437*freq)
+}
+
+// ERBRateToHz converts an ERB number back to frequency in Hz
+func ERBRateToHz(erb float64) float64 {
+	return (math.Pow(10.0, erb/21.4) - 1.0) / 0.00437
+}
+
+// ERBBandwidth returns the equivalent rectangular bandwidth in Hz at freq
+func ERBBandwidth(freq float64) float64 {
+	return 24.7 * (4.37*freq/1000.0 + 1.0)
+}
+
+// gammatoneBand is a single complex gammatone channel
+type gammatoneBand struct {
+	centerFreq float64
+	phaseInc   float64 // radians per sample
+	phase      float64
+	coeff      float64 // one-pole feedback coefficient
+	stateRe    [gammatoneOrder]float64
+	stateIm    [gammatoneOrder]float64
+	envelope   float64 // last instantaneous envelope
+	level      float64 // smoothed envelope
+}
+
+// FilterBank splits audio into perceptually spaced bands using gammatone filters.
+// Each band reports a smoothed envelope level, and the band signals themselves
+// can be retrieved for masking-aware multiband processing.
+type FilterBank struct {
+	scale      FrequencyScale
+	sampleRate float64
+	minFreq    float64
+	maxFreq    float64
+	bands      []gammatoneBand
+
+	// Envelope smoothing
+	attackCoeff  float64
+	releaseCoeff float64
+
+	mu sync.Mutex
+}
+
+// NewFilterBank creates a filter bank with numBands spaced evenly on the given
+// perceptual scale between minFreq and maxFreq
+func NewFilterBank(scale FrequencyScale, numBands int, minFreq, maxFreq, sampleRate float64) *FilterBank {
+	if numBands < 1 {
+		numBands = 1
+	}
+	maxFreq = math.Min(maxFreq, sampleRate*0.45)
+	minFreq = math.Max(1.0, math.Min(minFreq, maxFreq))
+
+	fb := &FilterBank{
+		scale:      scale,
+		sampleRate: sampleRate,
+		minFreq:    minFreq,
+		maxFreq:    maxFreq,
+		bands:      make([]gammatoneBand, numBands),
+	}
+
+	lo, hi := fb.toScale(minFreq), fb.toScale(maxFreq)
+	for i := range fb.bands {
+		pos := lo
+		if numBands > 1 {
+			pos = lo + (hi-lo)*float64(i)/float64(numBands-1)
+		}
+		fb.setupBand(&fb.bands[i], fb.fromScale(pos))
+	}
+
+	fb.SetTimeConstants(5.0, 50.0)
+
+	return fb
+}
+
+// toScale maps Hz onto the filter bank's perceptual scale
+func (fb *FilterBank) toScale(freq float64) float64 {
+	if fb.scale == BarkScale {
+		return HzToBark(freq)
+	}
+	return HzToERBRate(freq)
+}
+
+// fromScale maps a perceptual scale value back to Hz
+func (fb *FilterBank) fromScale(value float64) float64 {
+	if fb.scale == BarkScale {
+		return BarkToHz(value)
+	}
+	return ERBRateToHz(value)
+}
+
+// bandwidth returns the band's bandwidth for the current scale
+func (fb *FilterBank) bandwidth(freq float64) float64 {
+	if fb.scale == BarkScale {
+		return CriticalBandwidth(freq)
+	}
+	return ERBBandwidth(freq)
+}
+
+// setupBand computes the gammatone coefficients for a center frequency
+func (fb *FilterBank) setupBand(band *gammatoneBand, centerFreq float64) {
+	// 1.019 scales the ERB to the gammatone's -3 dB bandwidth
+	b := 1.019 * fb.bandwidth(centerFreq)
+
+	band.centerFreq = centerFreq
+	band.phaseInc = 2.0 * math.Pi * centerFreq / fb.sampleRate
+	band.coeff = math.Exp(-2.0 * math.Pi * b / fb.sampleRate)
+}
+
+// SetTimeConstants sets the envelope attack and release times in milliseconds
+func (fb *FilterBank) SetTimeConstants(attackMs, releaseMs float64) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	fb.attackCoeff = timeConstantCoeff(attackMs, fb.sampleRate)
+	fb.releaseCoeff = timeConstantCoeff(releaseMs, fb.sampleRate)
+}
+
+// timeConstantCoeff converts a time in milliseconds to a one-pole coefficient
+func timeConstantCoeff(ms, sampleRate float64) float64 {
+	if ms <= 0 {
+		return 0
+	}
+	return math.Exp(-1.0 / (ms * 0.001 * sampleRate))
+}
+
+// processBand runs one sample through a gammatone band and returns its output
+func (fb *FilterBank) processBand(band *gammatoneBand, x float64) float64 {
+	// Shift the band down to DC
+	sin, cos := math.Sincos(band.phase)
+	re := x * cos
+	im := -x * sin
+
+	// Cascade of identical one-pole lowpass filters (unity DC gain)
+	g := 1.0 - band.coeff
+	for s := 0; s < gammatoneOrder; s++ {
+		band.stateRe[s] = g*re + band.coeff*band.stateRe[s]
+		band.stateIm[s] = g*im + band.coeff*band.stateIm[s]
+		re = band.stateRe[s]
+		im = band.stateIm[s]
+	}
+
+	band.phase += band.phaseInc
+	if band.phase >= 2.0*math.Pi {
+		band.phase -= 2.0 * math.Pi
+	}
+
+	// The analytic signal magnitude is the band envelope
+	band.envelope = 2.0 * math.Sqrt(re*re+im*im)
+
+	// Shift back up and keep the real part (x2 for the discarded image)
+	return 2.0 * (re*cos - im*sin)
+}
+
+// updateLevel applies envelope ballistics to a band
+func (fb *FilterBank) updateLevel(band *gammatoneBand) {
+	coeff := fb.releaseCoeff
+	if band.envelope > band.level {
+		coeff = fb.attackCoeff
+	}
+	band.level = band.envelope + coeff*(band.level-band.envelope)
+}
+
+// Process analyzes a block of mono samples and updates band levels
+func (fb *FilterBank) Process(samples []float64) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	for _, x := range samples {
+		for b := range fb.bands {
+			band := &fb.bands[b]
+			fb.processBand(band, x)
+			fb.updateLevel(band)
+		}
+	}
+}
+
+// ProcessBands splits samples into per-band signals. outputs must hold one
+// slice per band, each at least as long as samples. Band levels are updated too.
+func (fb *FilterBank) ProcessBands(samples []float64, outputs [][]float64) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	for i, x := range samples {
+		for b := range fb.bands {
+			band := &fb.bands[b]
+			y := fb.processBand(band, x)
+			fb.updateLevel(band)
+			if b < len(outputs) {
+				outputs[b][i] = y
+			}
+		}
+	}
+}
+
+// NumBands returns the number of bands
+func (fb *FilterBank) NumBands() int {
+	return len(fb.bands)
+}
+
+// Scale returns the perceptual scale the bands are spaced on
+func (fb *FilterBank) Scale() FrequencyScale {
+	return fb.scale
+}
+
+// GetCenterFrequencies returns the center frequency of each band in Hz
+func (fb *FilterBank) GetCenterFrequencies() []float64 {
+	freqs := make([]float64, len(fb.bands))
+	for i := range fb.bands {
+		freqs[i] = fb.bands[i].centerFreq
+	}
+	return freqs
+}
+
+// GetBandwidths returns the bandwidth of each band in Hz
+func (fb *FilterBank) GetBandwidths() []float64 {
+	widths := make([]float64, len(fb.bands))
+	for i := range fb.bands {
+		widths[i] = fb.bandwidth(fb.bands[i].centerFreq)
+	}
+	return widths
+}
+
+// GetBandLevels returns the smoothed envelope level of each band (linear)
+func (fb *FilterBank) GetBandLevels() []float64 {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	levels := make([]float64, len(fb.bands))
+	for i := range fb.bands {
+		levels[i] = fb.bands[i].level
+	}
+	return levels
+}
+
+// GetBandLevelsDB returns the smoothed envelope level of each band in dB
+func (fb *FilterBank) GetBandLevelsDB() []float64 {
+	levels := fb.GetBandLevels()
+	for i, level := range levels {
+		if level > 0 {
+			levels[i] = 20.0 * math.Log10(level)
+		} else {
+			levels[i] = -120.0
+		}
+	}
+	return levels
+}
+
+// Reset clears all filter and envelope state
+func (fb *FilterBank) Reset() {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	for i := range fb.bands {
+		band := &fb.bands[i]
+		band.phase = 0
+		band.envelope = 0
+		band.level = 0
+		for s := 0; s < gammatoneOrder; s++ {
+			band.stateRe[s] = 0
+			band.stateIm[s] = 0
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPerceptualScaleConversions(t *testing.T) {
+	if bark := HzToBark(1000); math.Abs(bark-8.527) > 0.01 {
+		t.Errorf("1 kHz should be about 8.53 Bark, got %f", bark)
+	}
+	if erb := ERBBandwidth(1000); math.Abs(erb-132.6) > 0.1 {
+		t.Errorf("ERB at 1 kHz should be about 132.6 Hz, got %f", erb)
+	}
+
+	for _, freq := range []float64{100, 1000, 8000} {
+		if f := BarkToHz(HzToBark(freq)); math.Abs(f-freq) > 0.01 {
+			t.Errorf("Bark round trip failed for %f Hz: got %f", freq, f)
+		}
+		if f := ERBRateToHz(HzToERBRate(freq)); math.Abs(f-freq) > 0.01 {
+			t.Errorf("ERB round trip failed for %f Hz: got %f", freq, f)
+		}
+	}
+}
+
+func TestFilterBankBandSpacing(t *testing.T) {
+	fb := NewFilterBank(ERBScale, 24, 50, 16000, 48000)
+	freqs := fb.GetCenterFrequencies()
+
+	if len(freqs) != 24 || fb.NumBands() != 24 {
+		t.Fatalf("Expected 24 bands, got %d", len(freqs))
+	}
+	if math.Abs(freqs[0]-50) > 0.01 || math.Abs(freqs[23]-16000) > 0.1 {
+		t.Errorf("Band edges should be 50 Hz and 16 kHz, got %f and %f", freqs[0], freqs[23])
+	}
+
+	// Even spacing on the ERB scale
+	step := HzToERBRate(freqs[1]) - HzToERBRate(freqs[0])
+	for i := 2; i < len(freqs); i++ {
+		d := HzToERBRate(freqs[i]) - HzToERBRate(freqs[i-1])
+		if math.Abs(d-step) > 1e-9 {
+			t.Errorf("Band %d not evenly spaced on ERB scale: %f vs %f", i, d, step)
+		}
+	}
+}
+
+func TestFilterBankTone(t *testing.T) {
+	sampleRate := 48000.0
+	for _, scale := range []FrequencyScale{BarkScale, ERBScale} {
+		fb := NewFilterBank(scale, 20, 100, 10000, sampleRate)
+
+		samples := make([]float64, 9600)
+		for i := range samples {
+			samples[i] = math.Sin(2 * math.Pi * 1000 * float64(i) / sampleRate)
+		}
+		fb.Process(samples)
+
+		levels := fb.GetBandLevels()
+		freqs := fb.GetCenterFrequencies()
+
+		loudest := 0
+		for i := range levels {
+			if levels[i] > levels[loudest] {
+				loudest = i
+			}
+		}
+
+		// The loudest band should be the one closest to 1 kHz
+		closest := 0
+		for i := range freqs {
+			if math.Abs(freqs[i]-1000) < math.Abs(freqs[closest]-1000) {
+				closest = i
+			}
+		}
+		if loudest != closest {
+			t.Errorf("Scale %d: loudest band %d (%.0f Hz), expected %d (%.0f Hz)",
+				scale, loudest, freqs[loudest], closest, freqs[closest])
+		}
+
+		// Far away bands should be strongly attenuated
+		db := fb.GetBandLevelsDB()
+		if db[0] > db[loudest]-40 {
+			t.Errorf("Scale %d: lowest band only %.1f dB below peak", scale, db[loudest]-db[0])
+		}
+	}
+}
+
+func TestFilterBankCenterGain(t *testing.T) {
+	sampleRate := 48000.0
+	fb := NewFilterBank(ERBScale, 1, 1000, 1000, sampleRate)
+
+	n := 9600
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = math.Sin(2 * math.Pi * 1000 * float64(i) / sampleRate)
+	}
+	outputs := [][]float64{make([]float64, n)}
+	fb.ProcessBands(samples, outputs)
+
+	peak := 0.0
+	for _, v := range outputs[0][n/2:] {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	if math.Abs(peak-1.0) > 0.02 {
+		t.Errorf("Expected unity gain at band center, got %f", peak)
+	}
+
+	fb.Reset()
+	if levels := fb.GetBandLevels(); levels[0] != 0 {
+		t.Errorf("Expected zero level after reset, got %f", levels[0])
+	}
+}
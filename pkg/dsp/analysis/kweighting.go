@@ -0,0 +1,28 @@
+package analysis
+
+// KWeighting is the ITU-R BS.1770 K-weighting filter for one channel: the
+// head-related shelf followed by the low-cut the loudness meters apply
+// before measuring mean square. Use it to build loudness-driven processors.
+type KWeighting struct {
+	pre   BiquadFilter
+	shelf BiquadFilter
+}
+
+// NewKWeighting creates a K-weighting filter for the sample rate
+func NewKWeighting(sampleRate float64) *KWeighting {
+	return &KWeighting{
+		pre:   *kWeightingPreFilter(sampleRate),
+		shelf: *kWeightingHighShelf(sampleRate),
+	}
+}
+
+// Process filters one sample
+func (k *KWeighting) Process(x float64) float64 {
+	return k.shelf.processBiquad(k.pre.processBiquad(x))
+}
+
+// Reset clears the filter state
+func (k *KWeighting) Reset() {
+	k.pre.x1, k.pre.x2, k.pre.y1, k.pre.y2 = 0, 0, 0, 0
+	k.shelf.x1, k.shelf.x2, k.shelf.y1, k.shelf.y2 = 0, 0, 0, 0
+}
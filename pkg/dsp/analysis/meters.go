@@ -13,6 +13,7 @@ type PeakMeter struct {
 	decayRate  float64
 	sampleRate float64
 	holdCount  int
+	ballistics meterBallistics
 	mu         sync.Mutex
 }
 
@@ -39,6 +40,22 @@ func (pm *PeakMeter) SetDecayRate(dbPerSecond float64) {
 	pm.decayRate = dbPerSecond
 }
 
+// SetBallistics selects a standardized meter response (VU, PPM, etc.).
+// BallisticsDefault restores the block peak with linear dB decay.
+func (pm *PeakMeter) SetBallistics(mode Ballistics) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.ballistics.configure(mode, pm.sampleRate)
+	pm.ballistics.level = pm.peak
+}
+
+// GetBallistics returns the current meter response mode
+func (pm *PeakMeter) GetBallistics() Ballistics {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.ballistics.mode
+}
+
 // Process updates the peak meter with new samples
 func (pm *PeakMeter) Process(samples []float64) {
 	pm.mu.Lock()
@@ -46,21 +63,31 @@ func (pm *PeakMeter) Process(samples []float64) {
 	
 	// Find peak in current block
 	blockPeak := 0.0
-	for _, sample := range samples {
-		absSample := math.Abs(sample)
-		if absSample > blockPeak {
-			blockPeak = absSample
+	if pm.ballistics.mode != BallisticsDefault {
+		// Standardized ballistics integrate per sample; hold tracks the reading
+		for _, sample := range samples {
+			if reading := pm.ballistics.process(sample); reading > blockPeak {
+				blockPeak = reading
+			}
+		}
+		pm.peak = pm.ballistics.level
+	} else {
+		for _, sample := range samples {
+			absSample := math.Abs(sample)
+			if absSample > blockPeak {
+				blockPeak = absSample
+			}
+		}
+
+		// Update peak with decay
+		samplesPerSecond := pm.sampleRate
+		decayPerSample := pm.decayRate / samplesPerSecond / 20.0 * math.Log(10) // Convert dB to linear
+		pm.peak *= math.Exp(-decayPerSample * float64(len(samples)))
+
+		// Update peak if new value is higher
+		if blockPeak > pm.peak {
+			pm.peak = blockPeak
 		}
-	}
-	
-	// Update peak with decay
-	samplesPerSecond := pm.sampleRate
-	decayPerSample := pm.decayRate / samplesPerSecond / 20.0 * math.Log(10) // Convert dB to linear
-	pm.peak *= math.Exp(-decayPerSample * float64(len(samples)))
-	
-	// Update peak if new value is higher
-	if blockPeak > pm.peak {
-		pm.peak = blockPeak
 	}
 	
 	// Update hold
@@ -117,6 +144,7 @@ func (pm *PeakMeter) Reset() {
 	pm.peak = 0
 	pm.hold = 0
 	pm.holdCount = 0
+	pm.ballistics.reset()
 }
 
 // RMSMeter measures RMS (Root Mean Square) levels
@@ -126,6 +154,7 @@ type RMSMeter struct {
 	writePos   int
 	sum        float64
 	count      int
+	ballistics meterBallistics
 	mu         sync.Mutex
 }
 
@@ -137,11 +166,35 @@ func NewRMSMeter(windowSizeSamples int) *RMSMeter {
 	}
 }
 
+// SetBallistics selects a standardized meter response. The RMS meter is
+// window based, so the sample rate is needed to derive the time constants.
+// BallisticsDefault restores the sliding window RMS.
+func (rm *RMSMeter) SetBallistics(mode Ballistics, sampleRate float64) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.ballistics.configure(mode, sampleRate)
+	rm.ballistics.reset()
+}
+
+// GetBallistics returns the current meter response mode
+func (rm *RMSMeter) GetBallistics() Ballistics {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.ballistics.mode
+}
+
 // Process updates the RMS meter with new samples
 func (rm *RMSMeter) Process(samples []float64) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 	
+	if rm.ballistics.mode != BallisticsDefault {
+		for _, sample := range samples {
+			rm.ballistics.process(sample)
+		}
+		return
+	}
+	
 	for _, sample := range samples {
 		// Remove old value from sum
 		oldValue := rm.buffer[rm.writePos]
@@ -164,6 +217,10 @@ func (rm *RMSMeter) GetRMS() float64 {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 	
+	if rm.ballistics.mode != BallisticsDefault {
+		return rm.ballistics.level
+	}
+	
 	if rm.count == 0 {
 		return 0
 	}
@@ -191,6 +248,7 @@ func (rm *RMSMeter) Reset() {
 	rm.sum = 0
 	rm.count = 0
 	rm.writePos = 0
+	rm.ballistics.reset()
 }
 
 // LUFSMeter implements ITU-R BS.1770-4 loudness measurement
@@ -245,10 +303,10 @@ func NewLUFSMeter(sampleRate float64, channels int) *LUFSMeter {
 	// Initialize K-weighting filters for each channel
 	for ch := 0; ch < channels; ch++ {
 		// Pre-filter (high-pass)
-		lm.preFilter[ch] = []*BiquadFilter{lm.createKWeightingPreFilter()}
+		lm.preFilter[ch] = []*BiquadFilter{kWeightingPreFilter(sampleRate)}
 		
 		// High shelf filter
-		lm.highShelf[ch] = []*BiquadFilter{lm.createKWeightingHighShelf()}
+		lm.highShelf[ch] = []*BiquadFilter{kWeightingHighShelf(sampleRate)}
 	}
 	
 	// Momentary loudness: 400ms window, 100ms update
@@ -287,13 +345,13 @@ func NewLUFSMeter(sampleRate float64, channels int) *LUFSMeter {
 	return lm
 }
 
-// createKWeightingPreFilter creates the K-weighting pre-filter (high-pass)
-func (lm *LUFSMeter) createKWeightingPreFilter() *BiquadFilter {
+// kWeightingPreFilter creates the K-weighting pre-filter (high-pass)
+func kWeightingPreFilter(sampleRate float64) *BiquadFilter {
 	// ITU-R BS.1770-4 pre-filter coefficients
 	f0 := 1681.974450955533
 	G := 3.999843853973347
 	Q := 0.7071752369554196
-	K := math.Tan(math.Pi * f0 / lm.sampleRate)
+	K := math.Tan(math.Pi * f0 / sampleRate)
 	Vh := math.Pow(10.0, G/20.0)
 	Vb := math.Pow(Vh, 0.4996667741545416)
 	
@@ -308,12 +366,12 @@ func (lm *LUFSMeter) createKWeightingPreFilter() *BiquadFilter {
 	}
 }
 
-// createKWeightingHighShelf creates the K-weighting high shelf filter
-func (lm *LUFSMeter) createKWeightingHighShelf() *BiquadFilter {
+// kWeightingHighShelf creates the K-weighting high shelf filter
+func kWeightingHighShelf(sampleRate float64) *BiquadFilter {
 	// ITU-R BS.1770-4 high shelf coefficients
 	f0 := 38.13547087602444
 	Q := 0.5003270373238773
-	K := math.Tan(math.Pi * f0 / lm.sampleRate)
+	K := math.Tan(math.Pi * f0 / sampleRate)
 	
 	a0 := 1.0 + K/Q + K*K
 	
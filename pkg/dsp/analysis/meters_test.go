@@ -340,4 +340,85 @@ func BenchmarkLUFSMeter(b *testing.B) {
 		lm.Process(samples)
 		lm.GetMomentaryLUFS()
 	}
-}
\ No newline at end of file
+}
+
+func TestMeterBallisticsVU(t *testing.T) {
+	sampleRate := 48000.0
+	rm := NewRMSMeter(1024)
+	rm.SetBallistics(BallisticsVU, sampleRate)
+
+	if rm.GetBallistics() != BallisticsVU {
+		t.Fatalf("Expected VU ballistics, got %v", rm.GetBallistics())
+	}
+
+	sine := func(n int) []float64 {
+		s := make([]float64, n)
+		for i := range s {
+			s[i] = math.Sin(2.0 * math.Pi * 1000.0 * float64(i) / sampleRate)
+		}
+		return s
+	}
+
+	// After 300 ms the needle should be within 1% of its final reading
+	rm.Process(sine(int(0.3 * sampleRate)))
+	reading := rm.GetRMS()
+	target := 1.0 / math.Sqrt2
+	if math.Abs(reading-target)/target > 0.02 {
+		t.Errorf("VU after 300ms: expected ~%.3f, got %.3f", target, reading)
+	}
+
+	// Steady state reads the RMS of a sine
+	rm.Process(sine(int(sampleRate)))
+	if math.Abs(rm.GetRMS()-target) > 0.01 {
+		t.Errorf("VU steady state: expected %.3f, got %.3f", target, rm.GetRMS())
+	}
+
+	rm.Reset()
+	if rm.GetRMS() != 0 {
+		t.Errorf("Expected 0 after reset, got %f", rm.GetRMS())
+	}
+}
+
+func TestMeterBallisticsPPM(t *testing.T) {
+	sampleRate := 48000.0
+
+	burst := make([]float64, int(0.010*sampleRate))
+	for i := range burst {
+		burst[i] = 1.0
+	}
+
+	// Type II: a 10 ms burst reads 2 dB under steady state
+	pm := NewPeakMeter(sampleRate)
+	pm.SetBallistics(BallisticsPPMTypeII)
+	pm.Process(burst)
+	if db := pm.GetPeakDB(); math.Abs(db-(-2.0)) > 0.1 {
+		t.Errorf("PPM Type II 10ms burst: expected -2 dB, got %.2f dB", db)
+	}
+
+	// Then falls 24 dB in 2.8 seconds
+	start := pm.GetPeakDB()
+	pm.Process(make([]float64, int(2.8*sampleRate)))
+	if fall := start - pm.GetPeakDB(); math.Abs(fall-24.0) > 0.1 {
+		t.Errorf("PPM Type II: expected 24 dB fall, got %.2f dB", fall)
+	}
+
+	// EBU digital PPM captures sample peaks instantly
+	ebu := NewPeakMeter(sampleRate)
+	ebu.SetBallistics(BallisticsEBUPPM)
+	ebu.Process([]float64{0, 0.5, 0})
+	if peak := ebu.GetPeak(); math.Abs(peak-0.5) > 0.001 {
+		t.Errorf("EBU PPM: expected instant peak of 0.5, got %f", peak)
+	}
+
+	// Type I integrates faster than Type II
+	typeI := NewPeakMeter(sampleRate)
+	typeI.SetBallistics(BallisticsPPMTypeI)
+	typeI.Process(burst[:int(0.005*sampleRate)])
+	if db := typeI.GetPeakDB(); math.Abs(db-(-2.0)) > 0.1 {
+		t.Errorf("PPM Type I 5ms burst: expected -2 dB, got %.2f dB", db)
+	}
+
+	if BallisticsEBUPPM.String() != "EBU PPM" {
+		t.Errorf("Unexpected name %q", BallisticsEBUPPM.String())
+	}
+}
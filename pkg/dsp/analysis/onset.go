@@ -0,0 +1,304 @@
+package analysis
+
+import "math"
+
+// Onset detector defaults
+const (
+	defaultOnsetSensitivity = 0.5
+	defaultOnsetDebounce    = 0.05  // Seconds between triggers
+	defaultOnsetFloorDB     = -50.0 // Level below which nothing triggers
+	onsetFastAttack         = 0.0005
+	onsetSlowAttack         = 0.015
+	onsetRelease            = 0.02
+	onsetFrameSeconds       = 0.02 // Spectral flux frame, rounded to a power of two
+	onsetFluxHistory        = 8    // Frames averaged into the adaptive threshold
+	onsetFluxCompression    = 100.0
+)
+
+// OnsetMethod selects how an OnsetDetector finds onsets
+type OnsetMethod int
+
+const (
+	// OnsetEnergy compares envelopes with a fast and a slow attack. It
+	// reacts within a millisecond and suits drums and other percussive
+	// sources.
+	OnsetEnergy OnsetMethod = iota
+	// OnsetSpectralFlux measures how much new energy appears across the
+	// spectrum between FFT frames. It also finds note changes that barely
+	// change the level, at the cost of a frame of latency.
+	OnsetSpectralFlux
+)
+
+// String returns the method name
+func (m OnsetMethod) String() string {
+	switch m {
+	case OnsetEnergy:
+		return "Energy"
+	case OnsetSpectralFlux:
+		return "Spectral Flux"
+	default:
+		return "Unknown"
+	}
+}
+
+// Onset is a trigger found in a block
+type Onset struct {
+	Offset   int     // Sample offset within the block
+	Strength float64 // Onset function relative to the threshold, 1 or more
+	Level    float64 // Envelope level when it triggered, linear
+}
+
+// OnsetDetector finds the starts of notes and hits in an audio stream and
+// reports them with sample offsets, for drum replacement or for sending
+// MIDI from audio. Sensitivity lowers the threshold, the debounce time
+// stops one hit from triggering twice, and hits quieter than the floor are
+// ignored. The envelope it follows is available for envelope-to-CC use.
+// All buffers are allocated up front, so Process is safe on the audio thread.
+type OnsetDetector struct {
+	sampleRate  float64
+	method      OnsetMethod
+	sensitivity float64
+	debounce    int     // Samples
+	floor       float64 // Linear
+
+	// Envelopes. The slow one follows the fast one with a longer attack.
+	fastAttack float64
+	slowAttack float64
+	release    float64
+	fast       float64
+	slow       float64
+
+	// Energy method
+	ratio float64 // Fast to slow ratio that triggers
+	armed bool
+
+	// Spectral flux method
+	fft       *FFT
+	frame     []float64 // Input ring, frame size
+	linear    []float64
+	prevMag   []float64
+	writePos  int
+	hop       int
+	hopPos    int
+	fluxes    [onsetFluxHistory]float64
+	fluxPos   int
+	fluxLast  [2]float64 // Flux of the previous two frames, newest first
+	fluxLevel float64    // Envelope level at the candidate frame
+	fluxScale float64    // Threshold multiplier
+	fluxDelta float64    // Threshold floor
+
+	since int // Samples since the last trigger
+}
+
+// NewOnsetDetector creates an energy-based detector at medium sensitivity
+func NewOnsetDetector(sampleRate float64) *OnsetDetector {
+	size := 1 << int(math.Round(math.Log2(sampleRate*onsetFrameSeconds)))
+	size = max(256, size)
+
+	od := &OnsetDetector{
+		sampleRate: sampleRate,
+		fastAttack: 1 - math.Exp(-1/(onsetFastAttack*sampleRate)),
+		slowAttack: 1 - math.Exp(-1/(onsetSlowAttack*sampleRate)),
+		release:    1 - math.Exp(-1/(onsetRelease*sampleRate)),
+		armed:      true,
+		fft:        NewFFT(size, HannWindow),
+		frame:      make([]float64, size),
+		linear:     make([]float64, size),
+		prevMag:    make([]float64, size/2+1),
+		hop:        size / 4,
+	}
+	od.SetSensitivity(defaultOnsetSensitivity)
+	od.SetDebounce(defaultOnsetDebounce)
+	od.SetFloor(defaultOnsetFloorDB)
+	od.Reset()
+	return od
+}
+
+// SetMethod selects the detection method
+func (od *OnsetDetector) SetMethod(method OnsetMethod) {
+	if method != OnsetEnergy && method != OnsetSpectralFlux {
+		method = OnsetEnergy
+	}
+	od.method = method
+}
+
+// GetMethod returns the detection method
+func (od *OnsetDetector) GetMethod() OnsetMethod {
+	return od.method
+}
+
+// SetSensitivity sets how readily onsets trigger (0-1). At 0 only sharp
+// hits well above the recent level trigger; at 1 small changes do.
+func (od *OnsetDetector) SetSensitivity(sensitivity float64) {
+	od.sensitivity = math.Max(0, math.Min(1, sensitivity))
+	insensitivity := 1 - od.sensitivity
+
+	// The fast envelope must rise 3-18 dB above the slow one
+	od.ratio = math.Pow(10, (3+15*insensitivity)/20)
+	od.fluxScale = 1 + 2*insensitivity
+	od.fluxDelta = 0.002 + 0.03*insensitivity
+}
+
+// GetSensitivity returns the sensitivity
+func (od *OnsetDetector) GetSensitivity() float64 {
+	return od.sensitivity
+}
+
+// SetDebounce sets the shortest time between triggers in seconds
+func (od *OnsetDetector) SetDebounce(seconds float64) {
+	od.debounce = max(1, int(math.Max(0, seconds)*od.sampleRate))
+}
+
+// GetDebounce returns the shortest time between triggers in seconds
+func (od *OnsetDetector) GetDebounce() float64 {
+	return float64(od.debounce) / od.sampleRate
+}
+
+// SetFloor sets the level in dB below which nothing triggers
+func (od *OnsetDetector) SetFloor(db float64) {
+	od.floor = math.Pow(10, db/20)
+}
+
+// GetFloor returns the trigger floor in dB
+func (od *OnsetDetector) GetFloor() float64 {
+	return 20 * math.Log10(od.floor)
+}
+
+// Latency returns how many samples onsets are reported after they happen:
+// none for the energy method, half a frame plus a hop for spectral flux
+func (od *OnsetDetector) Latency() int {
+	if od.method == OnsetSpectralFlux {
+		return len(od.frame)/2 + od.hop
+	}
+	return 0
+}
+
+// Envelope returns the level of the fast envelope, linear. It follows the
+// input with a 0.5 ms attack and 20 ms release.
+func (od *OnsetDetector) Envelope() float64 {
+	return od.fast
+}
+
+// Process analyzes a block of mono samples and appends the onsets found to
+// onsets. It does not allocate once onsets has room.
+func (od *OnsetDetector) Process(samples []float32, onsets []Onset) []Onset {
+	for i, s := range samples {
+		onsets = od.processSample(float64(s), i, onsets)
+	}
+	return onsets
+}
+
+// ProcessStereo analyzes the mono sum of a stereo block
+func (od *OnsetDetector) ProcessStereo(left, right []float32, onsets []Onset) []Onset {
+	n := min(len(left), len(right))
+	for i := 0; i < n; i++ {
+		onsets = od.processSample(0.5*float64(left[i]+right[i]), i, onsets)
+	}
+	return onsets
+}
+
+// processSample updates the envelopes and runs the selected method
+func (od *OnsetDetector) processSample(x float64, offset int, onsets []Onset) []Onset {
+	rect := math.Abs(x)
+	if rect > od.fast {
+		od.fast += od.fastAttack * (rect - od.fast)
+	} else {
+		od.fast += od.release * (rect - od.fast)
+	}
+	if od.fast > od.slow {
+		od.slow += od.slowAttack * (od.fast - od.slow)
+	} else {
+		od.slow = od.fast
+	}
+	od.since++
+
+	if od.method == OnsetSpectralFlux {
+		return od.processFlux(x, offset, onsets)
+	}
+
+	// Energy: trigger on the fast envelope jumping above the slow one, and
+	// re-arm once the slow one has caught up to half the ratio in dB
+	threshold := od.ratio * (od.slow + 1e-9)
+	if !od.armed {
+		if od.fast < math.Sqrt(od.ratio)*(od.slow+1e-9) {
+			od.armed = true
+		}
+		return onsets
+	}
+	if od.fast > threshold && od.fast > od.floor && od.since >= od.debounce {
+		od.armed = false
+		od.since = 0
+		onsets = append(onsets, Onset{Offset: offset, Strength: od.fast / threshold, Level: od.fast})
+	}
+	return onsets
+}
+
+// processFlux collects samples into frames and peak-picks the spectral flux
+// against an adaptive threshold. A frame is reported once the next one shows
+// the flux has peaked.
+func (od *OnsetDetector) processFlux(x float64, offset int, onsets []Onset) []Onset {
+	size := len(od.frame)
+	od.frame[od.writePos] = x
+	od.writePos = (od.writePos + 1) % size
+
+	od.hopPos++
+	if od.hopPos < od.hop {
+		return onsets
+	}
+	od.hopPos = 0
+
+	// Unroll the ring, oldest sample first
+	copy(od.linear, od.frame[od.writePos:])
+	copy(od.linear[size-od.writePos:], od.frame[:od.writePos])
+	magnitude, _ := od.fft.Forward(od.linear)
+
+	// Half-wave rectified difference of log-compressed magnitudes
+	norm := 2 / float64(size)
+	flux := 0.0
+	for i, m := range magnitude {
+		compressed := math.Log1p(onsetFluxCompression * m * norm)
+		if d := compressed - od.prevMag[i]; d > 0 {
+			flux += d
+		}
+		od.prevMag[i] = compressed
+	}
+	flux /= float64(len(magnitude))
+
+	// The previous frame is an onset if it peaks above the mean of the
+	// frames before it
+	candidate, before := od.fluxLast[0], od.fluxLast[1]
+	mean := 0.0
+	for _, f := range od.fluxes {
+		mean += f
+	}
+	mean /= onsetFluxHistory
+	threshold := od.fluxScale*mean + od.fluxDelta
+	if candidate > threshold && candidate > before && candidate >= flux &&
+		od.fluxLevel > od.floor && od.since >= od.debounce {
+		od.since = 0
+		onsets = append(onsets, Onset{Offset: offset, Strength: candidate / threshold, Level: od.fluxLevel})
+	}
+
+	od.fluxes[od.fluxPos] = candidate
+	od.fluxPos = (od.fluxPos + 1) % onsetFluxHistory
+	od.fluxLast[1], od.fluxLast[0] = candidate, flux
+	od.fluxLevel = od.fast
+	return onsets
+}
+
+// Reset clears the envelopes and analysis history
+func (od *OnsetDetector) Reset() {
+	od.fast, od.slow = 0, 0
+	od.armed = true
+	od.since = od.debounce
+	for i := range od.frame {
+		od.frame[i] = 0
+	}
+	for i := range od.prevMag {
+		od.prevMag[i] = 0
+	}
+	od.fluxes = [onsetFluxHistory]float64{}
+	od.fluxLast = [2]float64{}
+	od.fluxLevel = 0
+	od.writePos, od.hopPos, od.fluxPos = 0, 0, 0
+}
@@ -0,0 +1,164 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+// detectOnsets runs a detector over audio in blocks and returns the
+// absolute positions of the onsets
+func detectOnsets(od *OnsetDetector, audio []float32) []int {
+	var positions []int
+	onsets := make([]Onset, 0, 16)
+	for i := 0; i < len(audio); i += 512 {
+		onsets = od.Process(audio[i:min(i+512, len(audio))], onsets[:0])
+		for _, o := range onsets {
+			positions = append(positions, i+o.Offset)
+		}
+	}
+	return positions
+}
+
+// tones renders sine segments of equal level, each freqs[i] for seconds
+func tones(sampleRate, seconds, amplitude float64, freqs ...float64) []float32 {
+	per := int(seconds * sampleRate)
+	out := make([]float32, per*len(freqs))
+	phase := 0.0
+	for i := range out {
+		phase += 2 * math.Pi * freqs[i/per] / sampleRate
+		out[i] = float32(amplitude * math.Sin(phase))
+	}
+	return out
+}
+
+func TestOnsetDetectorClicks(t *testing.T) {
+	const sampleRate = 48000.0
+	audio := clickTrack(120, sampleRate, 4)
+
+	for _, tc := range []struct {
+		method    OnsetMethod
+		tolerance int
+	}{
+		{OnsetEnergy, 48},
+		{OnsetSpectralFlux, 0},
+	} {
+		od := NewOnsetDetector(sampleRate)
+		od.SetMethod(tc.method)
+		tolerance := tc.tolerance
+		if tolerance == 0 {
+			tolerance = od.Latency() + od.hop
+		}
+
+		got := detectOnsets(od, audio)
+		if len(got) != 8 {
+			t.Errorf("%v: %d onsets at %v, want 8", tc.method, len(got), got)
+			continue
+		}
+		for beat, pos := range got {
+			if d := pos - beat*24000; d < 0 || d > tolerance {
+				t.Errorf("%v: beat %d detected at %d, %d samples late", tc.method, beat, pos, d)
+			}
+		}
+	}
+}
+
+func TestOnsetDetectorDebounce(t *testing.T) {
+	const sampleRate = 48000.0
+	// Two hits 30 ms apart
+	audio := make([]float32, 9600)
+	for _, start := range []int{0, 1440} {
+		for i := 0; i < 240; i++ {
+			audio[start+i] = float32(math.Exp(-float64(i)/40)) * float32(1-2*(i%2))
+		}
+	}
+
+	od := NewOnsetDetector(sampleRate)
+	if got := detectOnsets(od, audio); len(got) != 1 {
+		t.Errorf("50 ms debounce: onsets at %v, want one", got)
+	}
+
+	od.SetDebounce(0.01)
+	od.Reset()
+	if got := detectOnsets(od, audio); len(got) != 2 {
+		t.Errorf("10 ms debounce: onsets at %v, want two", got)
+	}
+}
+
+func TestOnsetDetectorFloorAndSensitivity(t *testing.T) {
+	const sampleRate = 48000.0
+	quiet := clickTrack(120, sampleRate, 2)
+	for i := range quiet {
+		quiet[i] *= 0.0005 // About -66 dB
+	}
+	od := NewOnsetDetector(sampleRate)
+	if got := detectOnsets(od, quiet); len(got) != 0 {
+		t.Errorf("onsets below the floor at %v", got)
+	}
+	if od.GetFloor() != defaultOnsetFloorDB {
+		t.Errorf("floor %g dB", od.GetFloor())
+	}
+
+	// A steady tone triggers once; a 9 dB step only at high sensitivity
+	audio := tones(sampleRate, 0.5, 0.1, 440, 440)
+	for i := len(audio) / 2; i < len(audio); i++ {
+		audio[i] *= 2.8
+	}
+	for _, tc := range []struct {
+		sensitivity float64
+		want        int
+	}{{0, 1}, {1, 2}} {
+		od := NewOnsetDetector(sampleRate)
+		od.SetSensitivity(tc.sensitivity)
+		if got := detectOnsets(od, audio); len(got) != tc.want {
+			t.Errorf("sensitivity %g: onsets at %v, want %d", tc.sensitivity, got, tc.want)
+		}
+	}
+}
+
+func TestOnsetDetectorPitchChange(t *testing.T) {
+	const sampleRate = 48000.0
+	// A note change at a constant level, at 0.25 s
+	audio := tones(sampleRate, 0.25, 0.5, 440, 660)
+
+	energy := NewOnsetDetector(sampleRate)
+	if got := detectOnsets(energy, audio); len(got) != 1 {
+		t.Errorf("energy method: onsets at %v, want only the start", got)
+	}
+
+	flux := NewOnsetDetector(sampleRate)
+	flux.SetMethod(OnsetSpectralFlux)
+	got := detectOnsets(flux, audio)
+	if len(got) != 2 || got[1] < 12000 || got[1] > 12000+flux.Latency()+flux.hop {
+		t.Errorf("spectral flux: onsets at %v, want the start and the change at 12000", got)
+	}
+}
+
+func TestOnsetDetectorEnvelope(t *testing.T) {
+	od := NewOnsetDetector(48000)
+	od.Process(tones(48000, 0.1, 0.5, 1000), nil)
+	if env := od.Envelope(); env < 0.45 || env > 0.5 {
+		t.Errorf("envelope %g for a 0.5 sine, want close to its peak", env)
+	}
+	od.Reset()
+	if od.Envelope() != 0 {
+		t.Error("envelope not cleared by Reset")
+	}
+}
+
+func TestOnsetDetectorDoesNotAllocate(t *testing.T) {
+	audio := clickTrack(120, 48000, 1)
+	for _, method := range []OnsetMethod{OnsetEnergy, OnsetSpectralFlux} {
+		od := NewOnsetDetector(48000)
+		od.SetMethod(method)
+		onsets := make([]Onset, 0, 64)
+		block := 0
+		allocs := testing.AllocsPerRun(50, func() {
+			start := block * 512 % (len(audio) - 512)
+			onsets = od.Process(audio[start:start+512], onsets[:0])
+			block++
+		})
+		if allocs != 0 {
+			t.Errorf("%v: Process allocates %g times", method, allocs)
+		}
+	}
+}
@@ -20,8 +20,21 @@ type PhaseScope struct {
 	scale        float64
 	persistence  float64
 	mu           sync.Mutex
+
+	// Decimated display stream: every bucket of audio-rate points is
+	// reduced to its peak and the point farthest from it
+	bucket      []PhasePoint // Points of the open bucket
+	bucketSize  int
+	decimated   []PhasePoint // Ring of bucket extremes
+	decBright   []float64
+	decWritePos int
+	decCount    int
 }
 
+// DefaultPhaseScopeDecimation is the default number of samples reduced to
+// at most two display points
+const DefaultPhaseScopeDecimation = 8
+
 // PhasePoint represents a point in the phase display
 type PhasePoint struct {
 	X, Y float64
@@ -38,7 +51,7 @@ const (
 
 // NewPhaseScope creates a new phase scope
 func NewPhaseScope(bufferSize int) *PhaseScope {
-	return &PhaseScope{
+	ps := &PhaseScope{
 		bufferSize:  bufferSize,
 		bufferL:     make([]float64, bufferSize),
 		bufferR:     make([]float64, bufferSize),
@@ -50,6 +63,27 @@ func NewPhaseScope(bufferSize int) *PhaseScope {
 		decay:       0.95,
 		persistence: 0.8,
 	}
+	ps.setDecimation(DefaultPhaseScopeDecimation)
+	return ps
+}
+
+// SetDecimation sets how many samples form one bucket of the decimated
+// point stream (each bucket yields at most two points)
+func (ps *PhaseScope) SetDecimation(samples int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.setDecimation(samples)
+}
+
+// setDecimation reallocates the decimated stream; ps.mu must be held
+func (ps *PhaseScope) setDecimation(samples int) {
+	ps.bucketSize = max(1, samples)
+	ps.bucket = make([]PhasePoint, 0, ps.bucketSize)
+	capacity := 2 * ((ps.bufferSize + ps.bucketSize - 1) / ps.bucketSize)
+	ps.decimated = make([]PhasePoint, max(2, capacity))
+	ps.decBright = make([]float64, len(ps.decimated))
+	ps.decWritePos = 0
+	ps.decCount = 0
 }
 
 // SetMode sets the display mode
@@ -110,6 +144,9 @@ func (ps *PhaseScope) Process(samplesL, samplesR []float64) {
 	for i := range ps.brightness {
 		ps.brightness[i] *= ps.decay
 	}
+	for i := range ps.decBright {
+		ps.decBright[i] *= ps.decay
+	}
 	
 	// Add new samples
 	for i := 0; i < len(samplesL) && i < len(samplesR); i++ {
@@ -133,6 +170,12 @@ func (ps *PhaseScope) Process(samplesL, samplesR []float64) {
 			// Lissajous mode: X=L, Y=R
 			ps.points[ps.writePos] = PhasePoint{X: l, Y: r}
 		}
+
+		// Feed the decimated stream
+		ps.bucket = append(ps.bucket, ps.points[ps.writePos])
+		if len(ps.bucket) == ps.bucketSize {
+			ps.closeBucket()
+		}
 		
 		// Set brightness to maximum for new points
 		ps.brightness[ps.writePos] = 1.0
@@ -144,10 +187,67 @@ func (ps *PhaseScope) Process(samplesL, samplesR []float64) {
 	}
 }
 
-// GetPoints returns the current display points with brightness
-func (ps *PhaseScope) GetPoints() ([]PhasePoint, []float64) {
+// closeBucket reduces the open bucket to its extremes and appends them to
+// the decimated stream in time order
+func (ps *PhaseScope) closeBucket() {
+	first, second, ok := bucketExtremes(ps.bucket)
+	ps.pushDecimated(first, 1)
+	if ok {
+		ps.pushDecimated(second, 1)
+	}
+	ps.bucket = ps.bucket[:0]
+}
+
+// pushDecimated appends a point to the decimated ring
+func (ps *PhaseScope) pushDecimated(p PhasePoint, brightness float64) {
+	ps.decimated[ps.decWritePos] = p
+	ps.decBright[ps.decWritePos] = brightness
+	ps.decWritePos = (ps.decWritePos + 1) % len(ps.decimated)
+	if ps.decCount < len(ps.decimated) {
+		ps.decCount++
+	}
+}
+
+// bucketExtremes returns the point of largest radius and the point farthest
+// from it, in their original order. ok is false if the bucket has only one
+// distinct extreme.
+func bucketExtremes(points []PhasePoint) (first, second PhasePoint, ok bool) {
+	peak := 0
+	peakR := -1.0
+	for i, p := range points {
+		if r := p.X*p.X + p.Y*p.Y; r > peakR {
+			peak, peakR = i, r
+		}
+	}
+	far := peak
+	farD := 0.0
+	for i, p := range points {
+		dx, dy := p.X-points[peak].X, p.Y-points[peak].Y
+		if d := dx*dx + dy*dy; d > farD {
+			far, farD = i, d
+		}
+	}
+	if far == peak {
+		return points[peak], PhasePoint{}, false
+	}
+	if far < peak {
+		return points[far], points[peak], true
+	}
+	return points[peak], points[far], true
+}
+
+// GetPoints returns the display points with brightness, oldest first.
+// With maxPoints > 0 the points come from the decimated stream, further
+// reduced with the same peak-preserving buckets to at most maxPoints, so
+// drawing cost is bounded whatever the buffer size. maxPoints <= 0 returns
+// every audio-rate point.
+func (ps *PhaseScope) GetPoints(maxPoints int) ([]PhasePoint, []float64) {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
+
+	if maxPoints > 0 {
+		return ps.decimatedPoints(maxPoints)
+	}
 	
 	// Return copies to avoid race conditions
 	points := make([]PhasePoint, ps.count)
@@ -170,6 +270,50 @@ func (ps *PhaseScope) GetPoints() ([]PhasePoint, []float64) {
 	return points, brightness
 }
 
+// decimatedPoints reduces the decimated stream to at most maxPoints;
+// ps.mu must be held
+func (ps *PhaseScope) decimatedPoints(maxPoints int) ([]PhasePoint, []float64) {
+	n := ps.decCount
+	start := (ps.decWritePos - n + len(ps.decimated)) % len(ps.decimated)
+	at := func(i int) int { return (start + i) % len(ps.decimated) }
+
+	if n <= maxPoints {
+		points := make([]PhasePoint, n)
+		brightness := make([]float64, n)
+		for i := range points {
+			points[i] = ps.decimated[at(i)]
+			brightness[i] = ps.decBright[at(i)]
+		}
+		return points, brightness
+	}
+
+	// Merge groups of stream points, two output points per group
+	groups := max(1, maxPoints/2)
+	points := make([]PhasePoint, 0, 2*groups)
+	brightness := make([]float64, 0, 2*groups)
+	group := make([]PhasePoint, 0, n/groups+1)
+	for g := 0; g < groups; g++ {
+		lo, hi := g*n/groups, (g+1)*n/groups
+		group = group[:0]
+		bright := 0.0
+		for i := lo; i < hi; i++ {
+			group = append(group, ps.decimated[at(i)])
+			bright = math.Max(bright, ps.decBright[at(i)])
+		}
+		if len(group) == 0 {
+			continue
+		}
+		first, second, ok := bucketExtremes(group)
+		points = append(points, first)
+		brightness = append(brightness, bright)
+		if ok && len(points) < maxPoints {
+			points = append(points, second)
+			brightness = append(brightness, bright)
+		}
+	}
+	return points, brightness
+}
+
 // GetPolarData returns data formatted for polar display
 func (ps *PhaseScope) GetPolarData() ([]float64, []float64, []float64) {
 	ps.mu.Lock()
@@ -308,6 +452,11 @@ func (ps *PhaseScope) Reset() {
 	// Reset counters
 	ps.writePos = 0
 	ps.count = 0
+
+	// Clear the decimated stream
+	ps.bucket = ps.bucket[:0]
+	ps.decWritePos = 0
+	ps.decCount = 0
 }
 
 // VectorScope provides a vector scope display with graticule
@@ -386,7 +535,7 @@ func (vs *VectorScope) Process(samplesL, samplesR []float64) {
 
 // GetDisplay returns points, brightness, grid, and labels
 func (vs *VectorScope) GetDisplay() (points []PhasePoint, brightness []float64, grid []PhasePoint, labels []VectorScopeLabel) {
-	points, brightness = vs.phaseScope.GetPoints()
+	points, brightness = vs.phaseScope.GetPoints(0)
 	return points, brightness, vs.grid, vs.labels
 }
 
@@ -21,7 +21,7 @@ func TestPhaseScope(t *testing.T) {
 	
 	ps.Process(samplesL, samplesR)
 	
-	points, brightness := ps.GetPoints()
+	points, brightness := ps.GetPoints(0)
 	
 	// Check that points exist
 	if len(points) != 100 {
@@ -65,7 +65,7 @@ func TestPhaseScopeGoniometer(t *testing.T) {
 	
 	ps.Process(samplesL, samplesR)
 	
-	points, _ := ps.GetPoints()
+	points, _ := ps.GetPoints(0)
 	
 	// In goniometer mode with L=-R (pure side), points should be horizontal
 	for i, pt := range points {
@@ -187,7 +187,7 @@ func TestPhaseScopeDecay(t *testing.T) {
 	ps.Process(samplesL, samplesR)
 	
 	// Get initial brightness
-	_, brightness1 := ps.GetPoints()
+	_, brightness1 := ps.GetPoints(0)
 	initialBright := brightness1[0]
 	
 	// Process more samples (zeros)
@@ -195,7 +195,7 @@ func TestPhaseScopeDecay(t *testing.T) {
 	ps.Process(zeros, zeros)
 	
 	// Check brightness has decayed
-	_, brightness2 := ps.GetPoints()
+	_, brightness2 := ps.GetPoints(0)
 	decayedBright := brightness2[0]
 	
 	if decayedBright >= initialBright {
@@ -225,7 +225,7 @@ func TestPhaseScopeReset(t *testing.T) {
 	ps.Process(samplesL, samplesR)
 	
 	// Verify data exists
-	points, _ := ps.GetPoints()
+	points, _ := ps.GetPoints(0)
 	if len(points) == 0 {
 		t.Error("No points before reset")
 	}
@@ -234,7 +234,7 @@ func TestPhaseScopeReset(t *testing.T) {
 	ps.Reset()
 	
 	// Check everything is cleared
-	points, brightness := ps.GetPoints()
+	points, brightness := ps.GetPoints(0)
 	if len(points) != 0 {
 		t.Errorf("Points not cleared after reset: %d points remain", len(points))
 	}
@@ -305,7 +305,7 @@ func TestPhaseScopeScale(t *testing.T) {
 	
 	ps.Process(samplesL, samplesR)
 	
-	points, _ := ps.GetPoints()
+	points, _ := ps.GetPoints(0)
 	
 	// With scale=2, points should be doubled
 	for i, pt := range points {
@@ -333,7 +333,7 @@ func BenchmarkPhaseScope(b *testing.B) {
 	
 	for i := 0; i < b.N; i++ {
 		ps.Process(samplesL, samplesR)
-		ps.GetPoints()
+		ps.GetPoints(0)
 	}
 }
 
@@ -353,4 +353,75 @@ func BenchmarkVectorScope(b *testing.B) {
 		vs.Process(samplesL, samplesR)
 		vs.GetDisplay()
 	}
-}
\ No newline at end of file
+}
+
+func TestPhaseScopeDecimatedPoints(t *testing.T) {
+	ps := NewPhaseScope(4096)
+
+	// Low-level noise-like signal with one transient peak
+	samplesL := make([]float64, 4096)
+	samplesR := make([]float64, 4096)
+	for i := range samplesL {
+		samplesL[i] = 0.1 * math.Sin(float64(i)*0.37)
+		samplesR[i] = 0.1 * math.Cos(float64(i)*0.53)
+	}
+	samplesL[1234] = 0.99
+	samplesR[1234] = -0.99
+	ps.Process(samplesL, samplesR)
+
+	for _, maxPoints := range []int{2000, 256, 31} {
+		points, brightness := ps.GetPoints(maxPoints)
+		if len(points) == 0 || len(points) > maxPoints {
+			t.Errorf("maxPoints %d: got %d points", maxPoints, len(points))
+		}
+		if len(brightness) != len(points) {
+			t.Errorf("maxPoints %d: %d brightness values for %d points", maxPoints, len(brightness), len(points))
+		}
+		found := false
+		for _, p := range points {
+			if p.X == 0.99 && p.Y == -0.99 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("maxPoints %d: transient peak was lost", maxPoints)
+		}
+	}
+
+	// The decimated stream is bounded by the buffer, not the input length
+	for i := 0; i < 10; i++ {
+		ps.Process(samplesL, samplesR)
+	}
+	if points, _ := ps.GetPoints(1 << 20); len(points) > 2*4096/DefaultPhaseScopeDecimation {
+		t.Errorf("Decimated stream grew to %d points", len(points))
+	}
+
+	ps.Reset()
+	if points, _ := ps.GetPoints(100); len(points) != 0 {
+		t.Errorf("Reset should clear the decimated stream, got %d points", len(points))
+	}
+}
+
+func TestPhaseScopeSetDecimation(t *testing.T) {
+	ps := NewPhaseScope(1024)
+	ps.SetDecimation(64)
+
+	samplesL := make([]float64, 1024)
+	samplesR := make([]float64, 1024)
+	for i := range samplesL {
+		samplesL[i] = math.Sin(2 * math.Pi * float64(i) / 100)
+		samplesR[i] = samplesL[i]
+	}
+	ps.Process(samplesL, samplesR)
+
+	points, _ := ps.GetPoints(1000)
+	if len(points) > 2*1024/64 {
+		t.Errorf("Expected at most %d points, got %d", 2*1024/64, len(points))
+	}
+	// A mono sine stays on the diagonal
+	for _, p := range points {
+		if math.Abs(p.X-p.Y) > 1e-12 {
+			t.Fatalf("Point off the diagonal: %+v", p)
+		}
+	}
+}
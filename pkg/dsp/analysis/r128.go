@@ -0,0 +1,174 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// R128Targets are the delivery limits a programme is checked against
+type R128Targets struct {
+	IntegratedLUFS float64 // Target integrated loudness
+	Tolerance      float64 // Allowed deviation from the target in LU
+	MaxTruePeakDB  float64 // Highest allowed true peak in dBTP
+	MaxLRA         float64 // Highest allowed loudness range in LU, 0 to skip
+}
+
+// DefaultR128Targets returns the EBU R128 broadcast limits: -23 LUFS ±1 LU
+// and -1 dBTP
+func DefaultR128Targets() R128Targets {
+	return R128Targets{
+		IntegratedLUFS: -23.0,
+		Tolerance:      1.0,
+		MaxTruePeakDB:  -1.0,
+	}
+}
+
+// R128Report is the loudness compliance summary of a programme
+type R128Report struct {
+	Duration         float64 // Seconds analyzed
+	IntegratedLUFS   float64
+	LoudnessRange    float64 // LU
+	MaxMomentaryLUFS float64
+	MaxShortTermLUFS float64
+	MaxTruePeakDB    float64 // dBTP over all channels
+
+	Targets        R128Targets
+	IntegratedPass bool
+	TruePeakPass   bool
+	LRAPass        bool
+	Pass           bool
+	Failures       []string // Human-readable reason for each failed check
+}
+
+// R128Analyzer measures a whole programme for EBU R128 delivery. It feeds
+// a LUFSMeter in 100ms chunks, whatever the host block size, tracks the
+// maximum momentary and short-term loudness and the true peak, and checks
+// the results against configurable targets.
+type R128Analyzer struct {
+	sampleRate float64
+	channels   int
+	targets    R128Targets
+
+	lufs     *LUFSMeter
+	truePeak *TruePeakMeter
+
+	// Pending interleaved input until a 100ms chunk is complete
+	chunk    []float64
+	chunkPos int
+
+	frames       int64 // Frames analyzed
+	maxMomentary float64
+	maxShortTerm float64
+	mu           sync.Mutex
+}
+
+// NewR128Analyzer creates an analyzer with the default R128 targets
+func NewR128Analyzer(sampleRate float64, channels int) *R128Analyzer {
+	return &R128Analyzer{
+		sampleRate:   sampleRate,
+		channels:     channels,
+		targets:      DefaultR128Targets(),
+		lufs:         NewLUFSMeter(sampleRate, channels),
+		truePeak:     NewTruePeakMeter(sampleRate, channels),
+		chunk:        make([]float64, int(0.1*sampleRate)*channels),
+		maxMomentary: math.Inf(-1),
+		maxShortTerm: math.Inf(-1),
+	}
+}
+
+// SetTargets sets the limits used by Report
+func (r *R128Analyzer) SetTargets(targets R128Targets) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets = targets
+}
+
+// GetTargets returns the limits used by Report
+func (r *R128Analyzer) GetTargets() R128Targets {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.targets
+}
+
+// Process analyzes interleaved samples: [ch0, ch1, ch0, ch1, ...]
+func (r *R128Analyzer) Process(samples []float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.truePeak.Process(samples)
+	for len(samples) > 0 {
+		n := copy(r.chunk[r.chunkPos:], samples)
+		r.chunkPos += n
+		samples = samples[n:]
+		if r.chunkPos == len(r.chunk) {
+			r.flush()
+		}
+	}
+}
+
+// flush hands a complete chunk to the loudness meter and updates the
+// momentary and short-term maxima once their windows are filled
+func (r *R128Analyzer) flush() {
+	r.lufs.Process(r.chunk)
+	r.frames += int64(len(r.chunk) / r.channels)
+	r.chunkPos = 0
+
+	elapsed := float64(r.frames) / r.sampleRate
+	if elapsed >= 0.4 {
+		r.maxMomentary = math.Max(r.maxMomentary, r.lufs.GetMomentaryLUFS())
+	}
+	if elapsed >= 3.0 {
+		r.maxShortTerm = math.Max(r.maxShortTerm, r.lufs.GetShortTermLUFS())
+	}
+}
+
+// Report returns the measurements so far and checks them against the
+// targets. Input short of a complete 100ms chunk is not yet included in
+// the loudness values.
+func (r *R128Analyzer) Report() R128Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := R128Report{
+		Duration:         float64(r.frames) / r.sampleRate,
+		IntegratedLUFS:   r.lufs.GetIntegratedLUFS(),
+		LoudnessRange:    r.lufs.GetLoudnessRange(),
+		MaxMomentaryLUFS: r.maxMomentary,
+		MaxShortTermLUFS: r.maxShortTerm,
+		MaxTruePeakDB:    r.truePeak.GetMaxTruePeakDB(),
+		Targets:          r.targets,
+	}
+
+	t := r.targets
+	rep.IntegratedPass = math.Abs(rep.IntegratedLUFS-t.IntegratedLUFS) <= t.Tolerance
+	if !rep.IntegratedPass {
+		rep.Failures = append(rep.Failures, fmt.Sprintf("integrated loudness %.1f LUFS outside %.1f ±%.1f LU",
+			rep.IntegratedLUFS, t.IntegratedLUFS, t.Tolerance))
+	}
+	rep.TruePeakPass = rep.MaxTruePeakDB <= t.MaxTruePeakDB
+	if !rep.TruePeakPass {
+		rep.Failures = append(rep.Failures, fmt.Sprintf("true peak %.1f dBTP above %.1f dBTP",
+			rep.MaxTruePeakDB, t.MaxTruePeakDB))
+	}
+	rep.LRAPass = t.MaxLRA <= 0 || rep.LoudnessRange <= t.MaxLRA
+	if !rep.LRAPass {
+		rep.Failures = append(rep.Failures, fmt.Sprintf("loudness range %.1f LU above %.1f LU",
+			rep.LoudnessRange, t.MaxLRA))
+	}
+	rep.Pass = rep.IntegratedPass && rep.TruePeakPass && rep.LRAPass
+
+	return rep
+}
+
+// Reset clears all measurements, keeping the targets
+func (r *R128Analyzer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lufs.Reset()
+	r.truePeak.Reset()
+	r.chunkPos = 0
+	r.frames = 0
+	r.maxMomentary = math.Inf(-1)
+	r.maxShortTerm = math.Inf(-1)
+}
@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+// feedTone runs a stereo 997 Hz sine through the analyzer in host-sized
+// blocks
+func feedTone(r *R128Analyzer, amplitude, seconds float64) {
+	const block = 512
+	sampleRate := 48000.0
+	frames := int(seconds * sampleRate)
+	buf := make([]float64, 2*block)
+	for start := 0; start < frames; start += block {
+		for i := 0; i < block; i++ {
+			v := amplitude * math.Sin(2*math.Pi*997*float64(start+i)/sampleRate)
+			buf[2*i] = v
+			buf[2*i+1] = v
+		}
+		r.Process(buf)
+	}
+}
+
+func TestR128AnalyzerCompliant(t *testing.T) {
+	r := NewR128Analyzer(48000, 2)
+
+	// A stereo sine at -23 dBFS peak reads -23 LUFS
+	feedTone(r, math.Pow(10, -23.0/20), 5)
+	rep := r.Report()
+
+	if math.Abs(rep.IntegratedLUFS+23) > 0.2 {
+		t.Errorf("Integrated loudness should be -23 LUFS, got %.2f", rep.IntegratedLUFS)
+	}
+	if math.Abs(rep.MaxMomentaryLUFS+23) > 0.2 || math.Abs(rep.MaxShortTermLUFS+23) > 0.2 {
+		t.Errorf("Max momentary/short-term should be -23 LUFS, got %.2f / %.2f",
+			rep.MaxMomentaryLUFS, rep.MaxShortTermLUFS)
+	}
+	if rep.LoudnessRange > 0.5 {
+		t.Errorf("Steady tone should have almost no LRA, got %.2f", rep.LoudnessRange)
+	}
+	if math.Abs(rep.Duration-5) > 0.1 {
+		t.Errorf("Duration should be about 5 s, got %.2f", rep.Duration)
+	}
+	if !rep.Pass || len(rep.Failures) != 0 {
+		t.Errorf("Report should pass, failures: %v", rep.Failures)
+	}
+}
+
+func TestR128AnalyzerFailures(t *testing.T) {
+	r := NewR128Analyzer(48000, 2)
+	feedTone(r, 0.99, 4)
+	rep := r.Report()
+
+	if rep.IntegratedPass || rep.TruePeakPass || rep.Pass {
+		t.Errorf("Loud programme should fail loudness and true peak: %+v", rep)
+	}
+	if len(rep.Failures) != 2 {
+		t.Errorf("Expected 2 failures, got %v", rep.Failures)
+	}
+	if rep.MaxTruePeakDB < -0.5 {
+		t.Errorf("True peak should be near 0 dBTP, got %.2f", rep.MaxTruePeakDB)
+	}
+
+	// Custom targets that match the programme pass
+	r.SetTargets(R128Targets{IntegratedLUFS: rep.IntegratedLUFS, Tolerance: 1, MaxTruePeakDB: 0.5, MaxLRA: 10})
+	if rep := r.Report(); !rep.Pass {
+		t.Errorf("Custom targets should pass, failures: %v", rep.Failures)
+	}
+
+	r.Reset()
+	rep = r.Report()
+	if rep.Duration != 0 || !math.IsInf(rep.IntegratedLUFS, -1) || !math.IsInf(rep.MaxMomentaryLUFS, -1) {
+		t.Errorf("Reset should clear measurements: %+v", rep)
+	}
+}
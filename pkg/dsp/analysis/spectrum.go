@@ -3,6 +3,7 @@ package analysis
 import (
 	"math"
 	"sync"
+	"sync/atomic"
 )
 
 // SpectrumAnalyzer provides real-time spectral analysis
@@ -24,6 +25,9 @@ type SpectrumAnalyzer struct {
 	maxBin       int
 	outputBuffer []float64
 	mu           sync.Mutex
+
+	// Lock-free hand-off of finished spectra to the UI
+	display atomic.Pointer[spectrumDisplay]
 }
 
 // AveragingMode defines how the spectrum is averaged over time
@@ -52,6 +56,7 @@ func NewSpectrumAnalyzer(fftSize int, sampleRate float64, window WindowFunc) *Sp
 	}
 	
 	sa.updateFrequencyRange()
+	sa.display.Store(sa.newFullDisplay())
 	
 	return sa
 }
@@ -130,6 +135,7 @@ func (sa *SpectrumAnalyzer) Process(samples []float64) bool {
 			
 			// Apply averaging
 			sa.applyAveraging(magnitude)
+			sa.publish()
 			
 			// Shift buffer by hop size
 			if sa.hopSize < sa.fftSize {
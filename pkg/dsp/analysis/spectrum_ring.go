@@ -0,0 +1,170 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/rt"
+)
+
+// spectrumFloorDB is the level reported for empty bins
+const spectrumFloorDB = -120.0
+
+// spectrumRing hands complete spectra from one producer (the audio thread)
+// to one consumer (the UI) through a triple buffer, so neither side waits
+// and a spectrum is never read half written
+type spectrumRing struct {
+	size int
+	buf  *rt.TripleBuffer[[]float32]
+	seen bool // Consumer owned: a spectrum was read
+}
+
+func newSpectrumRing(size int) *spectrumRing {
+	return &spectrumRing{
+		size: size,
+		buf:  rt.NewTripleBuffer(func() []float32 { return make([]float32, size) }),
+	}
+}
+
+// write publishes a spectrum (producer only)
+func (r *spectrumRing) write(values []float32) {
+	copy(*r.buf.Back(), values)
+	r.buf.Publish()
+}
+
+// read copies the newest spectrum into dst and returns the number of
+// values copied, 0 if nothing was published yet (consumer only)
+func (r *spectrumRing) read(dst []float32) int {
+	values, fresh := r.buf.Read()
+	r.seen = r.seen || fresh
+	if !r.seen {
+		return 0
+	}
+	return copy(dst, *values)
+}
+
+// displayBin maps one log-spaced display bin onto FFT bins
+type displayBin struct {
+	lo, hi int     // FFT bins inside the display bin, hi exclusive
+	center float64 // Fractional FFT bin at the display bin center
+}
+
+// spectrumDisplay describes how the UI ring is filled
+type spectrumDisplay struct {
+	ring        *spectrumRing
+	bins        []displayBin // nil for the full spectrum
+	frequencies []float64    // Center frequency of each value
+	scratch     []float32    // Producer-side staging
+}
+
+// newFullDisplay publishes every FFT bin
+func (sa *SpectrumAnalyzer) newFullDisplay() *spectrumDisplay {
+	n := sa.fftSize/2 + 1
+	d := &spectrumDisplay{
+		ring:        newSpectrumRing(n),
+		frequencies: make([]float64, n),
+		scratch:     make([]float32, n),
+	}
+	for i := range d.frequencies {
+		d.frequencies[i] = sa.GetFrequencyForBin(i)
+	}
+	return d
+}
+
+// SetDisplayBins decimates the spectrum published for the UI to count
+// log-spaced bins from minFreq to maxFreq. Each bin shows the loudest FFT
+// bin inside it, or an interpolated value where FFT bins are wider than
+// the display bin. A count of 0 publishes the full spectrum. This
+// allocates; call it from the setup or UI thread.
+func (sa *SpectrumAnalyzer) SetDisplayBins(count int, minFreq, maxFreq float64) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if count <= 0 {
+		sa.display.Store(sa.newFullDisplay())
+		return
+	}
+
+	nyquist := sa.sampleRate / 2
+	minFreq = math.Max(1, math.Min(minFreq, nyquist))
+	maxFreq = math.Max(minFreq, math.Min(maxFreq, nyquist))
+	binWidth := sa.sampleRate / float64(sa.fftSize)
+	lastBin := sa.fftSize / 2
+
+	d := &spectrumDisplay{
+		ring:        newSpectrumRing(count),
+		bins:        make([]displayBin, count),
+		frequencies: make([]float64, count),
+		scratch:     make([]float32, count),
+	}
+	ratio := maxFreq / minFreq
+	for i := range d.bins {
+		lowFreq := minFreq * math.Pow(ratio, float64(i)/float64(count))
+		highFreq := minFreq * math.Pow(ratio, float64(i+1)/float64(count))
+		center := math.Sqrt(lowFreq * highFreq)
+		d.frequencies[i] = center
+		d.bins[i] = displayBin{
+			lo:     min(lastBin+1, int(math.Ceil(lowFreq/binWidth))),
+			hi:     min(lastBin+1, int(math.Ceil(highFreq/binWidth))),
+			center: math.Min(float64(lastBin), center/binWidth),
+		}
+	}
+	sa.display.Store(d)
+}
+
+// GetDisplayFrequencies returns the center frequency of each value
+// returned by GetLatestSpectrum
+func (sa *SpectrumAnalyzer) GetDisplayFrequencies() []float64 {
+	d := sa.display.Load()
+	result := make([]float64, len(d.frequencies))
+	copy(result, d.frequencies)
+	return result
+}
+
+// GetLatestSpectrum copies the newest spectrum in dB into dst without
+// blocking the audio thread and returns the number of values copied, 0
+// before the first spectrum. Safe to call from one UI thread while
+// Process runs; size dst with len(GetDisplayFrequencies()).
+func (sa *SpectrumAnalyzer) GetLatestSpectrum(dst []float32) int {
+	return sa.display.Load().ring.read(dst)
+}
+
+// publish decimates the averaged spectrum and writes it to the UI ring;
+// sa.mu must be held
+func (sa *SpectrumAnalyzer) publish() {
+	d := sa.display.Load()
+	if d.bins == nil {
+		for i, mag := range sa.outputBuffer {
+			d.scratch[i] = magnitudeDB(mag)
+		}
+		d.ring.write(d.scratch)
+		return
+	}
+
+	for i, b := range d.bins {
+		if b.hi > b.lo {
+			peak := 0.0
+			for _, mag := range sa.outputBuffer[b.lo:b.hi] {
+				peak = math.Max(peak, mag)
+			}
+			d.scratch[i] = magnitudeDB(peak)
+			continue
+		}
+		// Narrower than an FFT bin: interpolate at the center
+		j := int(b.center)
+		frac := b.center - float64(j)
+		mag := sa.outputBuffer[j]
+		if j+1 < len(sa.outputBuffer) {
+			mag += (sa.outputBuffer[j+1] - mag) * frac
+		}
+		d.scratch[i] = magnitudeDB(mag)
+	}
+	d.ring.write(d.scratch)
+}
+
+// magnitudeDB converts a linear magnitude to dB with a -120 dB floor
+func magnitudeDB(mag float64) float32 {
+	if mag > 0 {
+		return float32(math.Max(spectrumFloorDB, 20.0*math.Log10(mag)))
+	}
+	return spectrumFloorDB
+}
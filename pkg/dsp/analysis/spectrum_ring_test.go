@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func sineBlock(freq, sampleRate float64, start, n int) []float64 {
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = math.Sin(2 * math.Pi * freq * float64(start+i) / sampleRate)
+	}
+	return samples
+}
+
+func TestSpectrumLatestFull(t *testing.T) {
+	sa := NewSpectrumAnalyzer(1024, 44100, HannWindow)
+
+	dst := make([]float32, len(sa.GetDisplayFrequencies()))
+	if n := sa.GetLatestSpectrum(dst); n != 0 {
+		t.Errorf("No spectrum should be available yet, got %d values", n)
+	}
+
+	sa.Process(sineBlock(1000, 44100, 0, 2048))
+	if n := sa.GetLatestSpectrum(dst); n != 513 {
+		t.Fatalf("Expected 513 values, got %d", n)
+	}
+
+	// The published spectrum matches GetSpectrumDB
+	db := sa.GetSpectrumDB()
+	peak := sa.GetBinForFrequency(1000)
+	if math.Abs(float64(dst[peak])-db[peak]) > 1e-3 {
+		t.Errorf("Published value %f differs from %f", dst[peak], db[peak])
+	}
+}
+
+func TestSpectrumDisplayBins(t *testing.T) {
+	sa := NewSpectrumAnalyzer(2048, 48000, HannWindow)
+	sa.SetDisplayBins(64, 20, 20000)
+
+	freqs := sa.GetDisplayFrequencies()
+	if len(freqs) != 64 {
+		t.Fatalf("Expected 64 display frequencies, got %d", len(freqs))
+	}
+	for i := 1; i < len(freqs); i++ {
+		if ratio := freqs[i] / freqs[i-1]; math.Abs(ratio-math.Pow(1000, 1.0/64)) > 1e-9 {
+			t.Fatalf("Display bins are not log-spaced: ratio %f at %d", ratio, i)
+		}
+	}
+
+	sa.Process(sineBlock(1000, 48000, 0, 4096))
+	dst := make([]float32, len(freqs))
+	if n := sa.GetLatestSpectrum(dst); n != 64 {
+		t.Fatalf("Expected 64 values, got %d", n)
+	}
+
+	loudest := 0
+	for i, v := range dst {
+		if v > dst[loudest] {
+			loudest = i
+		}
+	}
+	if freqs[loudest] < 900 || freqs[loudest] > 1100 {
+		t.Errorf("Loudest display bin should be near 1 kHz, got %.0f Hz", freqs[loudest])
+	}
+	// Low bins are narrower than an FFT bin and still get a value
+	for i, v := range dst[:8] {
+		if math.IsNaN(float64(v)) || v < spectrumFloorDB {
+			t.Errorf("Display bin %d has no value: %f", i, v)
+		}
+	}
+}
+
+func TestSpectrumLatestConcurrent(t *testing.T) {
+	sa := NewSpectrumAnalyzer(512, 48000, HannWindow)
+	sa.SetDisplayBins(32, 20, 20000)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for block := 0; block < 200; block++ {
+			sa.Process(sineBlock(440, 48000, block*256, 256))
+		}
+	}()
+
+	dst := make([]float32, 32)
+	for i := 0; i < 1000; i++ {
+		if n := sa.GetLatestSpectrum(dst); n != 0 && n != 32 {
+			t.Errorf("Unexpected value count %d", n)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,269 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/rt"
+)
+
+// Tempo detector defaults
+const (
+	defaultMinBPM        = 60.0
+	defaultMaxBPM        = 200.0
+	tempoHopSeconds      = 0.01 // Onset function resolution
+	tempoHistorySeconds  = 8.0  // Onset history used for autocorrelation
+	tempoUpdateSeconds   = 1.0  // Time between tempo estimates
+	tempoBandSplitHz     = 150.0
+	tempoPreferredBPM    = 120.0
+	tempoWeightOctaves   = 1.0 // Width of the preference around tempoPreferredBPM
+	tempoSwitchEstimates = 2   // Consistent estimates needed to jump to a new tempo
+)
+
+// tempoSmoothing is a Gaussian kernel (sigma 1.5 frames) applied to the onset
+// history
+var tempoSmoothing = [...]float64{0.0702, 0.1311, 0.1907, 0.2161, 0.1907, 0.1311, 0.0702}
+
+// TempoDetector estimates the tempo of an audio stream for hosts that do not
+// report one. It computes a two-band onset strength signal and finds the
+// strongest beat period by autocorrelation, preferring tempos near 120 BPM
+// when half or double time is equally likely; SetRange can force a choice.
+// All buffers are allocated up front, so Process is safe on the audio thread;
+// Tempo and Confidence may be read from any goroutine.
+type TempoDetector struct {
+	sampleRate float64
+	hop        int
+	frameRate  float64 // Onset frames per second
+	minBPM     float64
+	maxBPM     float64
+
+	// Onset strength
+	lowState  float64
+	lowCoeff  float64
+	hopPos    int
+	lowEnergy float64
+	hiEnergy  float64
+	prevLow   float64
+	prevHigh  float64
+
+	// Onset history ring and analysis scratch
+	onsets      []float64
+	writePos    int
+	filled      int
+	linear      []float64
+	acf         []float64
+	framesSince int
+	updateEvery int
+
+	// Estimate tracking
+	candidate      float64
+	candidateCount int
+	tempo          rt.Float64
+	confidence     rt.Float64
+}
+
+// NewTempoDetector creates a detector for 60-200 BPM
+func NewTempoDetector(sampleRate float64) *TempoDetector {
+	hop := int(math.Max(1, math.Round(sampleRate*tempoHopSeconds)))
+	frameRate := sampleRate / float64(hop)
+	history := int(tempoHistorySeconds * frameRate)
+
+	td := &TempoDetector{
+		sampleRate:  sampleRate,
+		hop:         hop,
+		frameRate:   frameRate,
+		minBPM:      defaultMinBPM,
+		maxBPM:      defaultMaxBPM,
+		lowCoeff:    math.Exp(-2 * math.Pi * tempoBandSplitHz / sampleRate),
+		onsets:      make([]float64, history),
+		linear:      make([]float64, history),
+		acf:         make([]float64, history),
+		updateEvery: int(tempoUpdateSeconds * frameRate),
+	}
+	return td
+}
+
+// SetRange sets the tempo range in BPM. Values outside 20-400 BPM are clamped.
+func (td *TempoDetector) SetRange(minBPM, maxBPM float64) {
+	minBPM = math.Max(20, math.Min(400, minBPM))
+	maxBPM = math.Max(20, math.Min(400, maxBPM))
+	if minBPM > maxBPM {
+		minBPM, maxBPM = maxBPM, minBPM
+	}
+	td.minBPM = minBPM
+	td.maxBPM = maxBPM
+}
+
+// Process analyzes a block of mono samples
+func (td *TempoDetector) Process(samples []float32) {
+	for _, s := range samples {
+		td.processSample(float64(s))
+	}
+}
+
+// ProcessStereo analyzes the mono sum of a stereo block
+func (td *TempoDetector) ProcessStereo(left, right []float32) {
+	n := min(len(left), len(right))
+	for i := 0; i < n; i++ {
+		td.processSample(0.5 * float64(left[i]+right[i]))
+	}
+}
+
+// processSample accumulates band energies and emits an onset value per hop
+func (td *TempoDetector) processSample(x float64) {
+	td.lowState = x + td.lowCoeff*(td.lowState-x)
+	high := x - td.lowState
+	td.lowEnergy += td.lowState * td.lowState
+	td.hiEnergy += high * high
+
+	td.hopPos++
+	if td.hopPos < td.hop {
+		return
+	}
+	td.hopPos = 0
+
+	// Half-wave rectified log-energy flux in each band
+	low := math.Log(1e-9 + td.lowEnergy/float64(td.hop))
+	hi := math.Log(1e-9 + td.hiEnergy/float64(td.hop))
+	onset := math.Max(0, low-td.prevLow) + math.Max(0, hi-td.prevHigh)
+	td.prevLow, td.prevHigh = low, hi
+	td.lowEnergy, td.hiEnergy = 0, 0
+
+	td.onsets[td.writePos] = onset
+	td.writePos = (td.writePos + 1) % len(td.onsets)
+	if td.filled < len(td.onsets) {
+		td.filled++
+	}
+
+	td.framesSince++
+	if td.framesSince >= td.updateEvery {
+		td.framesSince = 0
+		td.estimate()
+	}
+}
+
+// estimate finds the beat period in the onset history
+func (td *TempoDetector) estimate() {
+	n := td.filled
+	minLag := int(math.Floor(60 * td.frameRate / td.maxBPM))
+	maxLag := int(math.Ceil(60 * td.frameRate / td.minBPM))
+	if minLag < 1 {
+		minLag = 1
+	}
+	// Need several beats of history before estimating
+	if n < 3*maxLag {
+		return
+	}
+
+	// Unroll the newest n frames, smoothing so beat periods between frames
+	// still correlate, and remove the mean
+	start := td.writePos - n
+	if start < 0 {
+		start += len(td.onsets)
+	}
+	size := len(td.onsets)
+	mean := 0.0
+	for i := 0; i < n; i++ {
+		v := 0.0
+		for k, w := range tempoSmoothing {
+			j := min(max(i+k-len(tempoSmoothing)/2, 0), n-1)
+			v += w * td.onsets[(start+j)%size]
+		}
+		td.linear[i] = v
+		mean += v
+	}
+	mean /= float64(n)
+	for i := 0; i < n; i++ {
+		td.linear[i] -= mean
+	}
+
+	// Autocorrelation up to twice the longest lag for the harmonic term
+	lagLimit := min(2*maxLag+1, n-1)
+	for lag := 0; lag <= lagLimit; lag++ {
+		sum := 0.0
+		for i := lag; i < n; i++ {
+			sum += td.linear[i] * td.linear[i-lag]
+		}
+		td.acf[lag] = sum / float64(n-lag)
+	}
+	if td.acf[0] <= 1e-12 {
+		return // Silence or a static signal
+	}
+
+	// Pick the lag with the strongest weighted periodicity, reinforced by its double
+	preferredLag := 60 * td.frameRate / tempoPreferredBPM
+	bestLag := -1
+	bestScore := 0.0
+	for lag := minLag; lag <= maxLag && lag < lagLimit; lag++ {
+		score := td.acf[lag]
+		if 2*lag <= lagLimit {
+			score += 0.5 * td.acf[2*lag]
+		}
+		octaves := math.Log2(float64(lag) / preferredLag)
+		score *= math.Exp(-0.5 * (octaves / tempoWeightOctaves) * (octaves / tempoWeightOctaves))
+		if score > bestScore {
+			bestScore = score
+			bestLag = lag
+		}
+	}
+	if bestLag < 0 {
+		return
+	}
+
+	// Refine to a fractional lag with a parabola through the neighbors
+	lag := float64(bestLag)
+	if bestLag > 0 && bestLag+1 <= lagLimit {
+		a, b, c := td.acf[bestLag-1], td.acf[bestLag], td.acf[bestLag+1]
+		if d := a - 2*b + c; d < 0 && b >= a && b >= c {
+			lag += math.Max(-0.5, math.Min(0.5, 0.5*(a-c)/d))
+		}
+	}
+
+	bpm := 60 * td.frameRate / lag
+	confidence := math.Max(0, math.Min(1, td.acf[bestLag]/td.acf[0]))
+	td.confidence.Store(confidence)
+	td.track(bpm)
+}
+
+// track smooths estimates and only jumps to a new tempo once it repeats
+func (td *TempoDetector) track(bpm float64) {
+	current := td.Tempo()
+	if current > 0 && math.Abs(bpm-current)/current < 0.04 {
+		td.tempo.Store(0.7*current + 0.3*bpm)
+		td.candidateCount = 0
+		return
+	}
+
+	if td.candidate > 0 && math.Abs(bpm-td.candidate)/td.candidate < 0.04 {
+		td.candidateCount++
+	} else {
+		td.candidate = bpm
+		td.candidateCount = 1
+	}
+	if current == 0 || td.candidateCount >= tempoSwitchEstimates {
+		td.tempo.Store(bpm)
+		td.candidateCount = 0
+	}
+}
+
+// Tempo returns the detected tempo in BPM, or 0 before the first estimate
+func (td *TempoDetector) Tempo() float64 {
+	return td.tempo.Load()
+}
+
+// Confidence returns the periodicity strength of the last estimate from 0 to 1
+func (td *TempoDetector) Confidence() float64 {
+	return td.confidence.Load()
+}
+
+// Reset clears the history and the current estimate
+func (td *TempoDetector) Reset() {
+	td.lowState, td.lowEnergy, td.hiEnergy = 0, 0, 0
+	td.prevLow, td.prevHigh = 0, 0
+	td.hopPos, td.writePos, td.filled, td.framesSince = 0, 0, 0, 0
+	td.candidate, td.candidateCount = 0, 0
+	for i := range td.onsets {
+		td.onsets[i] = 0
+	}
+	td.tempo.Store(0)
+	td.confidence.Store(0)
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+// clickTrack renders decaying noise bursts at bpm, with accented downbeats
+func clickTrack(bpm, sampleRate, seconds float64) []float32 {
+	out := make([]float32, int(seconds*sampleRate))
+	period := 60 / bpm * sampleRate
+	seed := uint32(1)
+	for beat := 0; ; beat++ {
+		start := int(float64(beat) * period)
+		if start >= len(out) {
+			break
+		}
+		gain := 0.5
+		if beat%4 == 0 {
+			gain = 1
+		}
+		for i := 0; i < 2000 && start+i < len(out); i++ {
+			seed = seed*1664525 + 1013904223
+			noise := float64(seed>>8)/float64(1<<24)*2 - 1
+			out[start+i] = float32(gain * noise * math.Exp(-float64(i)/300))
+		}
+	}
+	return out
+}
+
+func TestTempoDetector(t *testing.T) {
+	const sampleRate = 44100.0
+	for _, bpm := range []float64{90, 128, 160} {
+		td := NewTempoDetector(sampleRate)
+		audio := clickTrack(bpm, sampleRate, 15)
+		for i := 0; i < len(audio); i += 512 {
+			td.Process(audio[i:min(i+512, len(audio))])
+		}
+		if got := td.Tempo(); math.Abs(got-bpm) > 1 {
+			t.Errorf("%g BPM: detected %g (confidence %.2f)", bpm, got, td.Confidence())
+		}
+		if td.Confidence() <= 0.2 {
+			t.Errorf("%g BPM: low confidence %g", bpm, td.Confidence())
+		}
+	}
+}
+
+func TestTempoDetectorSilenceAndReset(t *testing.T) {
+	td := NewTempoDetector(48000)
+	td.Process(make([]float32, 48000*10))
+	if td.Tempo() != 0 {
+		t.Errorf("silence should not produce a tempo, got %g", td.Tempo())
+	}
+
+	audio := clickTrack(120, 48000, 12)
+	td.ProcessStereo(audio, audio)
+	if math.Abs(td.Tempo()-120) > 1 {
+		t.Errorf("stereo: detected %g", td.Tempo())
+	}
+	td.Reset()
+	if td.Tempo() != 0 || td.Confidence() != 0 {
+		t.Error("Reset should clear the estimate")
+	}
+}
+
+func TestTempoDetectorRange(t *testing.T) {
+	// Restricting the range to 60-120 BPM reports 174 BPM as half time
+	td := NewTempoDetector(44100)
+	td.SetRange(120, 60)
+	audio := clickTrack(174, 44100, 15)
+	td.Process(audio)
+	if got := td.Tempo(); math.Abs(got-87) > 1 {
+		t.Errorf("expected 87 BPM, got %g", got)
+	}
+}
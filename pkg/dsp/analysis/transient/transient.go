@@ -0,0 +1,250 @@
+// Package transient separates the attack of a sound from its sustain.
+//
+// A Detector follows the level of a signal with one fast envelope and two
+// slower ones that chase it: one rises slowly and so lags behind attacks,
+// the other falls slowly and so stays above decays. How far the fast
+// envelope leads the first gives the transient strength; how far it trails
+// the second gives the sustain strength. Both are 0-1 and independent of
+// level, so transient shapers can apply them directly as gain envelopes,
+// and Split uses them to divide a signal into transient and tonal parts
+// that sum back to the input.
+package transient
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+)
+
+// Detector defaults
+const (
+	DefaultFastAttack    = 0.0005 // Seconds
+	DefaultFastRelease   = 0.02
+	DefaultAttackWindow  = 0.02
+	DefaultSustainWindow = 0.3
+)
+
+// Detector measures transient and sustain strength sample by sample
+type Detector struct {
+	sampleRate float64
+
+	// Times in seconds
+	fastAttack    float64
+	fastRelease   float64
+	attackWindow  float64
+	sustainWindow float64
+	highPass      float64 // Detection highpass in Hz, 0 when off
+
+	// One-pole coefficients
+	fastAttackCoeff  float64
+	fastReleaseCoeff float64
+	slowAttackCoeff  float64
+	slowReleaseCoeff float64
+	hpCoeff          float64
+
+	// State
+	fast    float64 // Follows the detection level
+	rising  float64 // Chases fast upwards slowly, drops with it
+	falling float64 // Chases fast downwards slowly, rises with it
+	hpState [2]float64
+	hpPrev  [2]float64
+	attack  float64
+	sustain float64
+	peakAtk float64 // Peaks of the last block
+	peakSus float64
+}
+
+// NewDetector creates a detector with a 20 ms attack window and a 300 ms
+// sustain window
+func NewDetector(sampleRate float64) *Detector {
+	d := &Detector{
+		sampleRate:    sampleRate,
+		fastAttack:    DefaultFastAttack,
+		fastRelease:   DefaultFastRelease,
+		attackWindow:  DefaultAttackWindow,
+		sustainWindow: DefaultSustainWindow,
+	}
+	d.updateCoefficients()
+	return d
+}
+
+// SetSampleRate sets the sample rate
+func (d *Detector) SetSampleRate(sampleRate float64) {
+	d.sampleRate = sampleRate
+	d.updateCoefficients()
+}
+
+// SetSmoothing sets the attack and release of the fast envelope in seconds.
+// Longer times smooth the strengths but blunt very short hits.
+func (d *Detector) SetSmoothing(attack, release float64) {
+	d.fastAttack = math.Max(0, attack)
+	d.fastRelease = math.Max(0, release)
+	d.updateCoefficients()
+}
+
+// SetAttackWindow sets how long a transient lasts in seconds: the rise time
+// of the envelope that trails attacks
+func (d *Detector) SetAttackWindow(seconds float64) {
+	d.attackWindow = math.Max(0.0001, seconds)
+	d.updateCoefficients()
+}
+
+// GetAttackWindow returns the attack window in seconds
+func (d *Detector) GetAttackWindow() float64 {
+	return d.attackWindow
+}
+
+// SetSustainWindow sets how long sustain is detected after the level
+// starts falling, in seconds
+func (d *Detector) SetSustainWindow(seconds float64) {
+	d.sustainWindow = math.Max(0.001, seconds)
+	d.updateCoefficients()
+}
+
+// GetSustainWindow returns the sustain window in seconds
+func (d *Detector) GetSustainWindow() float64 {
+	return d.sustainWindow
+}
+
+// SetHighPass filters the detection signal so low frequencies, such as a
+// kick's body, do not mask transients. A frequency of 0 turns it off.
+func (d *Detector) SetHighPass(frequency float64) {
+	d.highPass = math.Max(0, frequency)
+	d.updateCoefficients()
+}
+
+// updateCoefficients computes the one-pole coefficients
+func (d *Detector) updateCoefficients() {
+	d.fastAttackCoeff = coeff.TimeConstant(d.fastAttack, d.sampleRate)
+	d.fastReleaseCoeff = coeff.TimeConstant(d.fastRelease, d.sampleRate)
+	d.slowAttackCoeff = coeff.TimeConstant(d.attackWindow, d.sampleRate)
+	d.slowReleaseCoeff = coeff.TimeConstant(d.sustainWindow, d.sampleRate)
+	d.hpCoeff = 0
+	if d.highPass > 0 {
+		d.hpCoeff = coeff.OnePole(d.highPass, d.sampleRate)
+	}
+}
+
+// filter runs the detection highpass for a channel
+func (d *Detector) filter(ch int, x float64) float64 {
+	if d.hpCoeff == 0 {
+		return x
+	}
+	// One-pole highpass: y[n] = c·(y[n-1] + x[n] - x[n-1])
+	y := d.hpCoeff * (d.hpState[ch] + x - d.hpPrev[ch])
+	d.hpPrev[ch], d.hpState[ch] = x, y
+	return y
+}
+
+// detect advances the envelopes with a rectified detection level
+func (d *Detector) detect(level float64) (attack, sustain float32) {
+	c := d.fastReleaseCoeff
+	if level > d.fast {
+		c = d.fastAttackCoeff
+	}
+	d.fast = level + (d.fast-level)*c
+
+	if d.fast > d.rising {
+		d.rising = d.fast + (d.rising-d.fast)*d.slowAttackCoeff
+	} else {
+		d.rising = d.fast
+	}
+	if d.fast < d.falling {
+		d.falling = d.fast + (d.falling-d.fast)*d.slowReleaseCoeff
+	} else {
+		d.falling = d.fast
+	}
+
+	d.attack, d.sustain = 0, 0
+	if d.fast > 1e-9 {
+		d.attack = 1 - d.rising/d.fast
+	}
+	if d.falling > 1e-9 {
+		d.sustain = 1 - d.fast/d.falling
+	}
+	d.peakAtk = math.Max(d.peakAtk, d.attack)
+	d.peakSus = math.Max(d.peakSus, d.sustain)
+	return float32(d.attack), float32(d.sustain)
+}
+
+// Process detects one mono sample and returns its transient and sustain
+// strengths
+func (d *Detector) Process(input float32) (attack, sustain float32) {
+	return d.detect(math.Abs(d.filter(0, float64(input))))
+}
+
+// ProcessStereo detects a stereo sample from the louder channel, so both
+// channels share one envelope
+func (d *Detector) ProcessStereo(left, right float32) (attack, sustain float32) {
+	l := math.Abs(d.filter(0, float64(left)))
+	r := math.Abs(d.filter(1, float64(right)))
+	return d.detect(math.Max(l, r))
+}
+
+// ProcessBuffer writes the per-sample strengths of a block to attack and
+// sustain, either of which may be nil, and starts a new block for Strength
+func (d *Detector) ProcessBuffer(input, attack, sustain []float32) {
+	d.peakAtk, d.peakSus = 0, 0
+	for i, x := range input {
+		a, s := d.Process(x)
+		if attack != nil {
+			attack[i] = a
+		}
+		if sustain != nil {
+			sustain[i] = s
+		}
+	}
+}
+
+// Split divides a block into its transient and tonal parts, which sum to
+// the input, and starts a new block for Strength. Any of the slices may
+// alias input.
+func (d *Detector) Split(input, transient, tonal []float32) {
+	d.peakAtk, d.peakSus = 0, 0
+	for i, x := range input {
+		a, _ := d.Process(x)
+		t := x * a
+		transient[i], tonal[i] = t, x-t
+	}
+}
+
+// SplitStereo splits a stereo block with one linked detector
+func (d *Detector) SplitStereo(inputL, inputR, transientL, transientR, tonalL, tonalR []float32) {
+	d.peakAtk, d.peakSus = 0, 0
+	for i := range inputL {
+		l, r := inputL[i], inputR[i]
+		a, _ := d.ProcessStereo(l, r)
+		tl, tr := l*a, r*a
+		transientL[i], tonalL[i] = tl, l-tl
+		transientR[i], tonalR[i] = tr, r-tr
+	}
+}
+
+// Attack returns the transient strength of the last sample (0-1)
+func (d *Detector) Attack() float64 {
+	return d.attack
+}
+
+// Sustain returns the sustain strength of the last sample (0-1)
+func (d *Detector) Sustain() float64 {
+	return d.sustain
+}
+
+// Strength returns the peak transient and sustain strengths of the last
+// block passed to ProcessBuffer or Split, for metering
+func (d *Detector) Strength() (attack, sustain float64) {
+	return d.peakAtk, d.peakSus
+}
+
+// Level returns the fast envelope, linear
+func (d *Detector) Level() float64 {
+	return d.fast
+}
+
+// Reset clears the envelopes and filter state
+func (d *Detector) Reset() {
+	d.fast, d.rising, d.falling = 0, 0, 0
+	d.hpState, d.hpPrev = [2]float64{}, [2]float64{}
+	d.attack, d.sustain = 0, 0
+	d.peakAtk, d.peakSus = 0, 0
+}
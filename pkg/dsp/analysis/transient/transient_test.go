@@ -0,0 +1,149 @@
+package transient
+
+import (
+	"math"
+	"testing"
+)
+
+const sampleRate = 48000.0
+
+// hit renders a decaying noise burst at gain, padded with silence
+func hit(gain float64) []float32 {
+	out := make([]float32, 24000)
+	seed := uint32(1)
+	for i := 0; i < 12000; i++ {
+		seed = seed*1664525 + 1013904223
+		noise := float64(seed>>8)/float64(1<<24)*2 - 1
+		out[1000+i] = float32(gain * noise * math.Exp(-float64(i)/2400))
+	}
+	return out
+}
+
+func TestDetectorHit(t *testing.T) {
+	var peaks [2]float64
+	for i, gain := range []float64{1, 0.1} {
+		d := NewDetector(sampleRate)
+		audio := hit(gain)
+		attack := make([]float32, len(audio))
+		sustain := make([]float32, len(audio))
+		d.ProcessBuffer(audio, attack, sustain)
+		peaks[i], _ = d.Strength()
+
+		if peaks[i] < 0.5 {
+			t.Errorf("gain %g: peak transient strength %g", gain, peaks[i])
+		}
+		// The transient is over 50 ms into the hit, where the tail sustains
+		if a := attack[1000+2400]; a > 0.05 {
+			t.Errorf("gain %g: transient strength %g during the decay", gain, a)
+		}
+		if s := sustain[1000+2400]; s < 0.05 {
+			t.Errorf("gain %g: sustain strength %g during the decay", gain, s)
+		}
+		if s := sustain[1000+20]; s > 0.05 {
+			t.Errorf("gain %g: sustain strength %g at the attack", gain, s)
+		}
+	}
+	if math.Abs(peaks[0]-peaks[1]) > 0.01 {
+		t.Errorf("strength depends on level: %g at 0 dB, %g at -20 dB", peaks[0], peaks[1])
+	}
+}
+
+func TestDetectorSteadyTone(t *testing.T) {
+	d := NewDetector(sampleRate)
+	var maxAttack, maxSustain float32
+	for i := 0; i < int(sampleRate); i++ {
+		a, s := d.Process(float32(0.5 * math.Sin(2*math.Pi*1000*float64(i)/sampleRate)))
+		if i > int(sampleRate)/2 {
+			maxAttack = max(maxAttack, a)
+			maxSustain = max(maxSustain, s)
+		}
+	}
+	if maxAttack > 0.05 || maxSustain > 0.05 {
+		t.Errorf("steady tone reads transient %g and sustain %g", maxAttack, maxSustain)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	d := NewDetector(sampleRate)
+	audio := hit(1)
+	transient := make([]float32, len(audio))
+	tonal := make([]float32, len(audio))
+	d.Split(audio, transient, tonal)
+
+	var early, late float64
+	for i := range audio {
+		if sum := transient[i] + tonal[i]; math.Abs(float64(sum-audio[i])) > 1e-6 {
+			t.Fatalf("parts sum to %g at %d, want %g", sum, i, audio[i])
+		}
+		e := float64(transient[i] * transient[i])
+		if i < 1000+480 {
+			early += e
+		} else {
+			late += e
+		}
+	}
+	if early < 2*late {
+		t.Errorf("transient energy %g in the first 10 ms, %g after", early, late)
+	}
+
+	// In place
+	copyAudio := append([]float32(nil), audio...)
+	d.Reset()
+	d.Split(copyAudio, copyAudio, tonal)
+	for i := range copyAudio {
+		if copyAudio[i] != transient[i] {
+			t.Fatalf("in-place split differs at %d", i)
+		}
+	}
+}
+
+func TestSplitStereoLinked(t *testing.T) {
+	d := NewDetector(sampleRate)
+	left := hit(1)
+	right := make([]float32, len(left))
+	for i := range right {
+		right[i] = 0.25 * left[i]
+	}
+	trL := make([]float32, len(left))
+	trR := make([]float32, len(left))
+	toL := make([]float32, len(left))
+	toR := make([]float32, len(left))
+	d.SplitStereo(left, right, trL, trR, toL, toR)
+
+	// One envelope scales both sides alike
+	for i := range left {
+		if math.Abs(float64(trR[i]-0.25*trL[i])) > 1e-6 {
+			t.Fatalf("sides split differently at %d: %g and %g", i, trL[i], trR[i])
+		}
+	}
+	if a, _ := d.Strength(); a < 0.5 {
+		t.Errorf("stereo peak strength %g", a)
+	}
+}
+
+func TestHighPass(t *testing.T) {
+	// A 30 Hz tone barely registers once the detector is filtered
+	tone := make([]float32, 9600)
+	for i := range tone {
+		tone[i] = float32(math.Sin(2 * math.Pi * 30 * float64(i) / sampleRate))
+	}
+	d := NewDetector(sampleRate)
+	d.ProcessBuffer(tone, nil, nil)
+	unfiltered := d.Level()
+
+	d.SetHighPass(300)
+	d.Reset()
+	d.ProcessBuffer(tone, nil, nil)
+	if d.Level() > unfiltered/5 {
+		t.Errorf("filtered level %g, unfiltered %g", d.Level(), unfiltered)
+	}
+}
+
+func TestProcessDoesNotAllocate(t *testing.T) {
+	d := NewDetector(sampleRate)
+	audio := hit(1)[:512]
+	out := make([]float32, 512)
+	if allocs := testing.AllocsPerRun(100, func() { d.Split(audio, out, out) }); allocs != 0 {
+		t.Errorf("Split allocates %g times", allocs)
+	}
+}
@@ -0,0 +1,121 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTruePeakMeterIntersamplePeak(t *testing.T) {
+	sampleRate := 48000.0
+	tp := NewTruePeakMeter(sampleRate, 1)
+
+	// A full-scale sine at fs/4 sampled 45 degrees off its crests: every
+	// sample is at -3 dBFS while the waveform reaches 0 dBFS between them
+	samples := make([]float64, 4800)
+	for i := range samples {
+		samples[i] = math.Sin(2*math.Pi*float64(i)/4 + math.Pi/4)
+	}
+	tp.Process(samples)
+
+	if sp := tp.GetSamplePeakDB(0); math.Abs(sp+3.01) > 0.05 {
+		t.Errorf("Sample peak should be -3.01 dBFS, got %.2f", sp)
+	}
+	if tpDB := tp.GetTruePeakDB(0); tpDB < -0.5 || tpDB > 0.5 {
+		t.Errorf("True peak should be about 0 dBTP, got %.2f", tpDB)
+	}
+	if isp := tp.GetIntersamplePeakDB(0); isp < 2.5 {
+		t.Errorf("Intersample peak should be about 3 dB, got %.2f", isp)
+	}
+}
+
+func TestTruePeakMeterLowFrequency(t *testing.T) {
+	sampleRate := 48000.0
+	tp := NewTruePeakMeter(sampleRate, 1)
+
+	// At low frequencies true peak and sample peak agree
+	samples := make([]float64, 48000)
+	for i := range samples {
+		samples[i] = 0.5 * math.Sin(2*math.Pi*997*float64(i)/sampleRate)
+	}
+	tp.Process(samples)
+
+	want := 20 * math.Log10(0.5)
+	if got := tp.GetTruePeakDB(0); math.Abs(got-want) > 0.1 {
+		t.Errorf("True peak of a 997 Hz sine should be %.2f dBTP, got %.2f", want, got)
+	}
+}
+
+func TestTruePeakMeterChannels(t *testing.T) {
+	tp := NewTruePeakMeter(48000, 2)
+
+	// Interleaved: left loud, right quiet
+	samples := make([]float64, 2000)
+	for i := 0; i < len(samples); i += 2 {
+		phase := 2 * math.Pi * 1000 * float64(i/2) / 48000
+		samples[i] = 0.8 * math.Sin(phase)
+		samples[i+1] = 0.1 * math.Sin(phase)
+	}
+	tp.Process(samples)
+
+	left := tp.GetTruePeak(0)
+	right := tp.GetTruePeak(1)
+	if math.Abs(left-0.8) > 0.02 || math.Abs(right-0.1) > 0.01 {
+		t.Errorf("Per-channel true peaks wrong: L=%.3f R=%.3f", left, right)
+	}
+	if peak := tp.GetMaxTruePeakDB(); math.Abs(peak-tp.GetTruePeakDB(0)) > 1e-9 {
+		t.Errorf("Max true peak should follow the loudest channel, got %.2f", peak)
+	}
+
+	// Planar input gives the same result
+	planar := NewTruePeakMeter(48000, 2)
+	left64 := make([]float64, 1000)
+	right64 := make([]float64, 1000)
+	for i := range left64 {
+		left64[i] = samples[2*i]
+		right64[i] = samples[2*i+1]
+	}
+	planar.ProcessChannels([][]float64{left64, right64})
+	if planar.GetTruePeak(0) != left || planar.GetTruePeak(1) != right {
+		t.Error("Planar and interleaved processing disagree")
+	}
+
+	tp.Reset()
+	if !math.IsInf(tp.GetMaxTruePeakDB(), -1) {
+		t.Errorf("Reset should clear peaks, got %.2f", tp.GetMaxTruePeakDB())
+	}
+}
+
+func TestTruePeakDetectorMatchesMeter(t *testing.T) {
+	samples := make([]float64, 2000)
+	for i := range samples {
+		samples[i] = 0.8 * math.Sin(2*math.Pi*float64(i)/4.3+0.7)
+	}
+
+	meter := NewTruePeakMeter(48000, 1)
+	meter.Process(samples)
+
+	var d TruePeakDetector
+	peak := 0.0
+	for _, s := range samples {
+		peak = math.Max(peak, d.Process(s))
+	}
+	if math.Abs(peak-meter.GetTruePeak(0)) > 1e-12 {
+		t.Errorf("Detector peak %f differs from meter %f", peak, meter.GetTruePeak(0))
+	}
+
+	// An impulse shows up TruePeakDetectorDelay samples later
+	d.Reset()
+	first := -1
+	for i := 0; i < 20; i++ {
+		in := 0.0
+		if i == 0 {
+			in = 1
+		}
+		if d.Process(in) > 0.5 && first < 0 {
+			first = i
+		}
+	}
+	if first != TruePeakDetectorDelay {
+		t.Errorf("Impulse detected after %d samples, want %d", first, TruePeakDetectorDelay)
+	}
+}
@@ -1,7 +1,12 @@
 // Package dsp provides digital signal processing utilities for audio
 package dsp
 
-import "math"
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+	"github.com/justyntemme/vst3go/pkg/dsp/vector"
+)
 
 // Buffer utilities for common audio operations
 
@@ -30,48 +35,22 @@ func Add(dst, src []float32) {
 
 // AddScaled adds scaled source to destination - no allocations
 func AddScaled(dst, src []float32, scale float32) {
-	n := len(dst)
-	if len(src) < n {
-		n = len(src)
-	}
-	for i := 0; i < n; i++ {
-		dst[i] += src[i] * scale
-	}
+	vector.AddScaled(dst, src, scale)
 }
 
 // Scale multiplies buffer by a constant - no allocations
 func Scale(buffer []float32, scale float32) {
-	for i := range buffer {
-		buffer[i] *= scale
-	}
+	vector.Mul(buffer, buffer, scale)
 }
 
 // Mix blends two buffers with a mix factor (0=all src1, 1=all src2)
 func Mix(dst, src1, src2 []float32, mix float32) {
-	n := len(dst)
-	if len(src1) < n {
-		n = len(src1)
-	}
-	if len(src2) < n {
-		n = len(src2)
-	}
-
-	invMix := 1.0 - mix
-	for i := 0; i < n; i++ {
-		dst[i] = src1[i]*invMix + src2[i]*mix
-	}
+	vector.Blend(dst, src1, src2, 1.0-mix, mix)
 }
 
 // Peak finds the maximum absolute value in a buffer
 func Peak(buffer []float32) float32 {
-	peak := float32(0)
-	for _, sample := range buffer {
-		abs := float32(math.Abs(float64(sample)))
-		if abs > peak {
-			peak = abs
-		}
-	}
-	return peak
+	return vector.PeakAbs(buffer)
 }
 
 // RMS calculates the root mean square of a buffer
@@ -104,9 +83,9 @@ func SoftClip(buffer []float32, threshold float32) {
 	for i := range buffer {
 		sample := buffer[i]
 		if sample > threshold {
-			buffer[i] = threshold + (1.0-threshold)*float32(math.Tanh(float64(sample-threshold)))
+			buffer[i] = threshold + (1.0-threshold)*fastmath.High.Tanh(sample-threshold)
 		} else if sample < -threshold {
-			buffer[i] = -threshold + (-1.0+threshold)*float32(math.Tanh(float64(sample+threshold)))
+			buffer[i] = -threshold + (-1.0+threshold)*fastmath.High.Tanh(sample+threshold)
 		}
 	}
 }
@@ -0,0 +1,160 @@
+// Package coeff computes the filter and envelope coefficients that setters
+// recalculate whenever a parameter moves. Under dense automation the exp
+// and tan calls behind attack, release and cutoff settings show up in
+// profiles, so the package can switch globally from the math package to
+// interpolated lookup tables.
+//
+// The tables are indexed by normalized frequency and by the exponent itself,
+// so one set serves every sample rate. They are built once, on first use
+// in Table mode. Results stay within TableTanError and TableExpError of the
+// exact values.
+package coeff
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// Mode selects how coefficients are computed
+type Mode int32
+
+const (
+	Exact Mode = iota // The math package, the default
+	Table             // Interpolated lookup tables
+)
+
+// Table accuracy, as the largest relative error against the math package
+const (
+	TableTanError = 1e-7 // Prewarp and the tangent behind SinCos
+	TableExpError = 1e-7 // ExpNeg and TimeConstant; relative to 1-e^-x for small x
+)
+
+// Table sizes and ranges
+const (
+	tanTableSize = 2048            // Intervals over normalized frequency 0-0.5
+	expTableSize = 4096            // Intervals over one octave of 2^-f
+	maxTanRatio  = 0.499           // Above this normalized frequency Prewarp is exact
+	expSeriesMax = 0.25            // Below this ExpNeg uses its Taylor series
+	expUnderflow = 1100 * math.Ln2 // ExpNeg is 0 beyond this
+)
+
+// expSeries holds the Taylor coefficients of e^-x used below expSeriesMax,
+// highest order first
+var expSeries = [...]float64{
+	1.0 / 40320, -1.0 / 5040, 1.0 / 720, -1.0 / 120, 1.0 / 24, -1.0 / 6, 0.5, -1, 1,
+}
+
+var (
+	mode atomic.Int32
+
+	tablesOnce sync.Once
+	tanTable   []float64 // tan(πx)·(1-2x)/(πx), smooth over 0-0.5
+	expTable   []float64 // 2^-f over 0-1
+)
+
+// SetMode selects exact or table coefficients for every setter in the
+// library. It is safe to call at any time; setters pick it up on their next
+// call.
+func SetMode(m Mode) {
+	if m == Table {
+		tablesOnce.Do(buildTables)
+	}
+	mode.Store(int32(m))
+}
+
+// GetMode returns the current mode
+func GetMode() Mode {
+	return Mode(mode.Load())
+}
+
+// buildTables fills the lookup tables
+func buildTables() {
+	tanTable = make([]float64, tanTableSize+2)
+	for i := range tanTable {
+		x := 0.5 * float64(i) / tanTableSize
+		switch {
+		case i == 0:
+			tanTable[i] = 1
+		case i >= tanTableSize:
+			// Limit at the Nyquist frequency, where tan has its pole
+			tanTable[i] = 4 / (math.Pi * math.Pi)
+		default:
+			tanTable[i] = math.Tan(math.Pi*x) * (1 - 2*x) / (math.Pi * x)
+		}
+	}
+
+	expTable = make([]float64, expTableSize+2)
+	for i := range expTable {
+		expTable[i] = math.Exp2(-float64(i) / expTableSize)
+	}
+}
+
+// lookup linearly interpolates table at a position in table steps
+func lookup(table []float64, pos float64) float64 {
+	i := int(pos)
+	frac := pos - float64(i)
+	return table[i] + (table[i+1]-table[i])*frac
+}
+
+// Prewarp returns tan(π·frequency/sampleRate), the bilinear prewarped
+// cutoff used by state variable and first order allpass filters
+func Prewarp(frequency, sampleRate float64) float64 {
+	x := frequency / sampleRate
+	if GetMode() != Table || !(x >= 0 && x <= maxTanRatio) {
+		return math.Tan(math.Pi * x)
+	}
+	// tan(πx) has a pole at x = 0.5 and vanishes at 0; the table holds the
+	// smooth remainder once both are divided out
+	return math.Pi * x * lookup(tanTable, 2*x*tanTableSize) / (1 - 2*x)
+}
+
+// SinCos returns the sine and cosine of ω = 2π·frequency/sampleRate, as
+// used by the RBJ biquad designs. In Table mode they come from the
+// prewarped tangent of ω/2.
+func SinCos(frequency, sampleRate float64) (sin, cos float64) {
+	x := frequency / sampleRate
+	if GetMode() != Table || !(x >= 0 && x <= maxTanRatio) {
+		return math.Sincos(2 * math.Pi * x)
+	}
+	t := Prewarp(frequency, sampleRate)
+	d := 1 / (1 + t*t)
+	return 2 * t * d, (1 - t*t) * d
+}
+
+// ExpNeg returns e^-x
+func ExpNeg(x float64) float64 {
+	if GetMode() != Table || !(x >= 0) {
+		return math.Exp(-x)
+	}
+	if x < expSeriesMax {
+		// Keeps 1-e^-x accurate for long time constants
+		sum := 0.0
+		for _, c := range expSeries {
+			sum = sum*x + c
+		}
+		return sum
+	}
+	if x > expUnderflow {
+		return 0
+	}
+	y := x * math.Log2E
+	n := int(y)
+	return math.Ldexp(lookup(expTable, (y-float64(n))*expTableSize), -n)
+}
+
+// TimeConstant returns the one-pole coefficient e^(-1/(seconds·sampleRate))
+// that reaches 1-1/e of a step in the given time. Times at or below zero
+// return 0, an instant response.
+func TimeConstant(seconds, sampleRate float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return ExpNeg(1 / (seconds * sampleRate))
+}
+
+// OnePole returns the one-pole lowpass feedback coefficient
+// e^(-2π·frequency/sampleRate)
+func OnePole(frequency, sampleRate float64) float64 {
+	return ExpNeg(2 * math.Pi * frequency / sampleRate)
+}
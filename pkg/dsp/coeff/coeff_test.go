@@ -0,0 +1,121 @@
+package coeff
+
+import (
+	"math"
+	"testing"
+)
+
+// withMode runs fn in a mode and restores the previous one
+func withMode(m Mode, fn func()) {
+	prev := GetMode()
+	SetMode(m)
+	defer SetMode(prev)
+	fn()
+}
+
+func relError(got, want float64) float64 {
+	if want == 0 {
+		return math.Abs(got)
+	}
+	return math.Abs(got-want) / math.Abs(want)
+}
+
+func TestPrewarpAccuracy(t *testing.T) {
+	withMode(Table, func() {
+		worst := 0.0
+		for f := 1.0; f <= 0.499*48000; f *= 1.0007 {
+			worst = math.Max(worst, relError(Prewarp(f, 48000), math.Tan(math.Pi*f/48000)))
+		}
+		if worst > TableTanError {
+			t.Errorf("Prewarp error %.2e, want at most %.0e", worst, TableTanError)
+		}
+
+		worst = 0
+		for f := 1.0; f <= 0.499*44100; f *= 1.0007 {
+			sin, cos := SinCos(f, 44100)
+			wantSin, wantCos := math.Sincos(2 * math.Pi * f / 44100)
+			worst = math.Max(worst, math.Max(math.Abs(sin-wantSin), math.Abs(cos-wantCos)))
+		}
+		if worst > TableTanError {
+			t.Errorf("SinCos error %.2e, want at most %.0e", worst, TableTanError)
+		}
+
+		// Out of range frequencies fall back to the exact value
+		if Prewarp(0.6*48000, 48000) != math.Tan(math.Pi*0.6) {
+			t.Error("Prewarp above the table should be exact")
+		}
+	})
+}
+
+func TestExpAccuracy(t *testing.T) {
+	withMode(Table, func() {
+		worst := 0.0
+		for x := 1e-9; x < 700; x *= 1.0003 {
+			got, want := ExpNeg(x), math.Exp(-x)
+			// Small arguments feed 1-e^-x coefficients, so measure that
+			if x < 1 {
+				got, want = 1-got, -math.Expm1(-x)
+			}
+			worst = math.Max(worst, relError(got, want))
+		}
+		if worst > TableExpError {
+			t.Errorf("ExpNeg error %.2e, want at most %.0e", worst, TableExpError)
+		}
+		if ExpNeg(1e6) != 0 || TimeConstant(0, 48000) != 0 {
+			t.Error("ExpNeg should underflow to 0 and zero times should be instant")
+		}
+	})
+}
+
+func TestModeSelection(t *testing.T) {
+	if GetMode() != Exact {
+		t.Fatal("Default mode should be Exact")
+	}
+	if TimeConstant(0.01, 48000) != math.Exp(-1/480.0) || OnePole(1000, 48000) != math.Exp(-2*math.Pi/48) {
+		t.Error("Exact mode should match the math package")
+	}
+	withMode(Table, func() {
+		if GetMode() != Table {
+			t.Error("Mode should be Table")
+		}
+		if relError(TimeConstant(0.01, 48000), math.Exp(-1/480.0)) > TableExpError {
+			t.Error("Table time constant out of bounds")
+		}
+	})
+}
+
+var sink float64
+
+func benchmarkPrewarp(b *testing.B, m Mode) {
+	withMode(m, func() {
+		for i := 0; i < b.N; i++ {
+			sink += Prewarp(20+float64(i&4095)*4, 48000)
+		}
+	})
+}
+
+func BenchmarkPrewarpExact(b *testing.B) { benchmarkPrewarp(b, Exact) }
+func BenchmarkPrewarpTable(b *testing.B) { benchmarkPrewarp(b, Table) }
+
+func benchmarkSinCos(b *testing.B, m Mode) {
+	withMode(m, func() {
+		for i := 0; i < b.N; i++ {
+			s, c := SinCos(20+float64(i&4095)*4, 48000)
+			sink += s + c
+		}
+	})
+}
+
+func BenchmarkSinCosExact(b *testing.B) { benchmarkSinCos(b, Exact) }
+func BenchmarkSinCosTable(b *testing.B) { benchmarkSinCos(b, Table) }
+
+func benchmarkTimeConstant(b *testing.B, m Mode) {
+	withMode(m, func() {
+		for i := 0; i < b.N; i++ {
+			sink += TimeConstant(0.0001+float64(i&4095)*0.0005, 48000)
+		}
+	})
+}
+
+func BenchmarkTimeConstantExact(b *testing.B) { benchmarkTimeConstant(b, Exact) }
+func BenchmarkTimeConstantTable(b *testing.B) { benchmarkTimeConstant(b, Table) }
@@ -0,0 +1,72 @@
+package debug
+
+import "time"
+
+// ViolationKind identifies a real-time safety rule broken by audited code
+type ViolationKind int
+
+const (
+	// ViolationAllocation means the audited call allocated on the heap
+	ViolationAllocation ViolationKind = iota
+	// ViolationMutexWait means goroutines waited on a mutex longer than allowed
+	ViolationMutexWait
+	// ViolationSyscall means the audio thread blocked in the kernel, e.g. for
+	// file or network I/O, sleeping or waiting on a lock
+	ViolationSyscall
+)
+
+// String returns the violation name
+func (k ViolationKind) String() string {
+	switch k {
+	case ViolationAllocation:
+		return "allocation"
+	case ViolationMutexWait:
+		return "mutex wait"
+	case ViolationSyscall:
+		return "blocking syscall"
+	default:
+		return "unknown"
+	}
+}
+
+// AuditConfig sets the limits checked by the real-time safety audit
+type AuditConfig struct {
+	MaxAllocations   uint64        // Heap objects allowed per call
+	MaxMutexWait     time.Duration // Mutex wait allowed per call
+	CheckSyscalls    bool          // Report blocking syscalls where supported (Linux)
+	PanicOnViolation bool          // Panic instead of recording, for tests
+}
+
+// DefaultAuditConfig allows no allocations and 100µs of mutex wait per call
+func DefaultAuditConfig() AuditConfig {
+	return AuditConfig{
+		MaxMutexWait:  100 * time.Microsecond,
+		CheckSyscalls: true,
+	}
+}
+
+// AuditViolation aggregates the violations of one kind at one call site
+type AuditViolation struct {
+	Kind       ViolationKind
+	Name       string // Name passed to AuditProcess
+	CallSite   string // Function and line that called AuditProcess
+	StackTrace string // Stack of the first violation
+	Count      uint64 // Calls that broke the rule
+	Total      uint64 // Objects, nanoseconds waited or context switches, summed
+	Max        uint64 // Largest amount in a single call
+}
+
+// GCPressure summarizes garbage collector activity for audited calls and
+// the process as a whole
+type GCPressure struct {
+	AuditedCalls   uint64        // Calls made through AuditProcess
+	Allocations    uint64        // Heap objects allocated during audited calls
+	AllocatedBytes uint64        // Heap bytes allocated during audited calls
+	GCCycles       uint64        // GC cycles that completed during audited calls
+	TotalGCCycles  uint32        // GC cycles since the process started
+	PauseTotal     time.Duration // Stop-the-world pause time since the process started
+	LastPause      time.Duration
+	HeapAlloc      uint64  // Live heap bytes
+	NextGC         uint64  // Heap size that triggers the next cycle
+	GCCPUFraction  float64 // Share of CPU time used by the GC
+}
@@ -0,0 +1,328 @@
+//go:build debug
+// +build debug
+
+package debug
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// blockRecords is the number of block profile records read per call; stacks
+// beyond it are ignored
+const blockRecords = 512
+
+// auditSamples holds one call's readings so measuring does not allocate
+// inside the audited window
+type auditSamples struct {
+	memBefore runtime.MemStats
+	memAfter  runtime.MemStats
+	blocks    []runtime.BlockProfileRecord
+}
+
+// siteKey identifies a call site and violation kind
+type siteKey struct {
+	pc   uintptr
+	name string
+	kind ViolationKind
+}
+
+// realtimeAuditor records real-time safety violations of audited calls
+type realtimeAuditor struct {
+	enabled atomic.Bool
+	config  atomic.Pointer[AuditConfig]
+	samples sync.Pool
+
+	calls      atomic.Uint64
+	allocs     atomic.Uint64
+	allocBytes atomic.Uint64
+	gcCycles   atomic.Uint64
+	mu         sync.Mutex // Only taken when recording a violation
+	sites      map[siteKey]*AuditViolation
+}
+
+var globalAuditor = newRealtimeAuditor()
+
+// auditProcessEntry is the entry PC of AuditProcess, used to find block
+// profile stacks that passed through an audited call
+var auditProcessEntry uintptr
+
+// init enables the audit with default limits when VST3GO_RT_AUDIT=1
+func init() {
+	auditProcessEntry = reflect.ValueOf(AuditProcess).Pointer()
+	if os.Getenv("VST3GO_RT_AUDIT") == "1" {
+		EnableRealtimeAudit(DefaultAuditConfig())
+	}
+}
+
+func newRealtimeAuditor() *realtimeAuditor {
+	a := &realtimeAuditor{sites: make(map[siteKey]*AuditViolation)}
+	a.samples.New = func() interface{} {
+		return &auditSamples{blocks: make([]runtime.BlockProfileRecord, blockRecords)}
+	}
+	cfg := DefaultAuditConfig()
+	a.config.Store(&cfg)
+	return a
+}
+
+// EnableRealtimeAudit starts checking calls made through AuditProcess. It
+// turns on the runtime block profile to attribute waits, replacing any rate
+// set with runtime.SetBlockProfileRate.
+func EnableRealtimeAudit(config AuditConfig) {
+	globalAuditor.config.Store(&config)
+	runtime.SetBlockProfileRate(1)
+	globalAuditor.enabled.Store(true)
+}
+
+// DisableRealtimeAudit stops checking calls; recorded violations are kept
+func DisableRealtimeAudit() {
+	globalAuditor.enabled.Store(false)
+	runtime.SetBlockProfileRate(0)
+}
+
+// RealtimeAuditEnabled reports whether calls are being audited
+func RealtimeAuditEnabled() bool {
+	return globalAuditor.enabled.Load()
+}
+
+// ResetRealtimeAudit clears recorded violations and statistics
+func ResetRealtimeAudit() {
+	globalAuditor.mu.Lock()
+	defer globalAuditor.mu.Unlock()
+
+	globalAuditor.sites = make(map[siteKey]*AuditViolation)
+	globalAuditor.calls.Store(0)
+	globalAuditor.allocs.Store(0)
+	globalAuditor.allocBytes.Store(0)
+	globalAuditor.gcCycles.Store(0)
+}
+
+// AuditProcess runs fn, typically a ProcessAudio call, and checks that it did
+// not allocate, wait on mutexes beyond the configured limit or block in a
+// syscall. Violations are aggregated per call site of AuditProcess and name;
+// nest calls to narrow down the offending code.
+//
+// Measuring stops the world twice per call, so audited code runs much slower.
+// Allocation counts are process-wide, so audit with the host otherwise idle.
+// Wait times come from the thread's CPU time and are only exact on Linux;
+// elsewhere the whole call duration is used when the call blocked.
+func AuditProcess(name string, fn func()) {
+	a := globalAuditor
+	if !a.enabled.Load() {
+		fn()
+		return
+	}
+	cfg := a.config.Load()
+
+	s := a.samples.Get().(*auditSamples)
+	defer a.samples.Put(s)
+
+	// Pin the goroutine so time and context switches are measured on one
+	// thread. Host callbacks are already pinned; the lock nests.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	blocksBefore := auditedBlockEvents(s.blocks)
+	runtime.ReadMemStats(&s.memBefore)
+	cpuBefore, switchesBefore, haveUsage := threadUsage()
+	start := time.Now()
+
+	fn()
+
+	elapsed := time.Since(start)
+	cpuAfter, switchesAfter, _ := threadUsage()
+	runtime.ReadMemStats(&s.memAfter)
+	blocks := auditedBlockEvents(s.blocks) - blocksBefore
+
+	allocs := s.memAfter.Mallocs - s.memBefore.Mallocs
+	bytes := s.memAfter.TotalAlloc - s.memBefore.TotalAlloc
+	a.calls.Add(1)
+	a.allocs.Add(allocs)
+	a.allocBytes.Add(bytes)
+	a.gcCycles.Add(uint64(s.memAfter.NumGC - s.memBefore.NumGC))
+
+	if allocs > cfg.MaxAllocations {
+		a.violation(cfg, ViolationAllocation, name, allocs,
+			fmt.Sprintf("%d allocations (%d bytes)", allocs, bytes))
+	}
+
+	// Time off the CPU while blocked; preemption alone is not a switch
+	blocked := elapsed
+	switches := uint64(0)
+	if haveUsage {
+		switches = switchesAfter - switchesBefore
+		blocked = 0
+		if switches > 0 && elapsed > cpuAfter-cpuBefore {
+			blocked = elapsed - (cpuAfter - cpuBefore)
+		}
+	}
+
+	switch {
+	case blocks > 0 && blocked > cfg.MaxMutexWait:
+		a.violation(cfg, ViolationMutexWait, name, uint64(blocked),
+			fmt.Sprintf("waited %v on mutexes", blocked))
+	case blocks == 0 && cfg.CheckSyscalls && switches > 0:
+		a.violation(cfg, ViolationSyscall, name, switches,
+			fmt.Sprintf("thread blocked %d times for %v", switches, blocked))
+	}
+}
+
+// auditedBlockEvents counts block profile events, such as mutex and channel
+// waits, recorded on stacks that pass through AuditProcess
+func auditedBlockEvents(records []runtime.BlockProfileRecord) int64 {
+	n, ok := runtime.BlockProfile(records)
+	if !ok {
+		n = len(records)
+	}
+	total := int64(0)
+	for _, r := range records[:n] {
+		for _, pc := range r.Stack() {
+			if f := runtime.FuncForPC(pc - 1); f != nil && f.Entry() == auditProcessEntry {
+				total += r.Count
+				break
+			}
+		}
+	}
+	return total
+}
+
+// violation records a broken rule against the caller of AuditProcess
+func (a *realtimeAuditor) violation(cfg *AuditConfig, kind ViolationKind, name string, amount uint64, detail string) {
+	// Skip runtime.Callers, violation and AuditProcess
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+
+	if cfg.PanicOnViolation {
+		panic(fmt.Sprintf("real-time violation in %s: %s\n%s", name, detail, formatStack(pcs[:n])))
+	}
+
+	key := siteKey{name: name, kind: kind}
+	if n > 0 {
+		key.pc = pcs[0]
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	v, exists := a.sites[key]
+	if !exists {
+		v = &AuditViolation{
+			Kind:       kind,
+			Name:       name,
+			CallSite:   callSite(pcs[:n]),
+			StackTrace: formatStack(pcs[:n]),
+		}
+		a.sites[key] = v
+	}
+	v.Count++
+	v.Total += amount
+	if amount > v.Max {
+		v.Max = amount
+	}
+}
+
+// callSite returns the function and line of the first frame
+func callSite(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return "unknown"
+	}
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+}
+
+// formatStack renders program counters like runtime.Stack
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// GetAuditViolations returns the recorded violations, most frequent first
+func GetAuditViolations() []AuditViolation {
+	globalAuditor.mu.Lock()
+	defer globalAuditor.mu.Unlock()
+
+	result := make([]AuditViolation, 0, len(globalAuditor.sites))
+	for _, v := range globalAuditor.sites {
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].CallSite < result[j].CallSite
+	})
+	return result
+}
+
+// GetGCPressure returns GC statistics. It stops the world briefly, so call
+// it from a non-audio thread.
+func GetGCPressure() GCPressure {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	p := GCPressure{
+		AuditedCalls:   globalAuditor.calls.Load(),
+		Allocations:    globalAuditor.allocs.Load(),
+		AllocatedBytes: globalAuditor.allocBytes.Load(),
+		GCCycles:       globalAuditor.gcCycles.Load(),
+		TotalGCCycles:  m.NumGC,
+		PauseTotal:     time.Duration(m.PauseTotalNs),
+		HeapAlloc:      m.HeapAlloc,
+		NextGC:         m.NextGC,
+		GCCPUFraction:  m.GCCPUFraction,
+	}
+	if m.NumGC > 0 {
+		p.LastPause = time.Duration(m.PauseNs[(m.NumGC+255)%256])
+	}
+	return p
+}
+
+// GetAuditReport returns a report of GC pressure and all recorded violations
+func GetAuditReport() string {
+	p := GetGCPressure()
+	violations := GetAuditViolations()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== Real-Time Safety Audit ===\n")
+	fmt.Fprintf(&b, "Audited Calls: %d\n", p.AuditedCalls)
+	fmt.Fprintf(&b, "Allocations: %d (%d bytes)\n", p.Allocations, p.AllocatedBytes)
+	fmt.Fprintf(&b, "GC Cycles During Audited Calls: %d\n", p.GCCycles)
+	fmt.Fprintf(&b, "GC Cycles: %d, Pause Total: %v, Last Pause: %v\n", p.TotalGCCycles, p.PauseTotal, p.LastPause)
+	fmt.Fprintf(&b, "Heap: %d bytes, Next GC: %d bytes, GC CPU: %.2f%%\n", p.HeapAlloc, p.NextGC, p.GCCPUFraction*100)
+
+	if len(violations) == 0 {
+		fmt.Fprintf(&b, "\nNo violations\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\nViolations:\n")
+	for _, v := range violations {
+		fmt.Fprintf(&b, "\n[%s] %s at %s\n", v.Kind, v.Name, v.CallSite)
+		fmt.Fprintf(&b, "  Count: %d\n", v.Count)
+		switch v.Kind {
+		case ViolationMutexWait:
+			fmt.Fprintf(&b, "  Total Wait: %v, Max: %v\n", time.Duration(v.Total), time.Duration(v.Max))
+		case ViolationSyscall:
+			fmt.Fprintf(&b, "  Context Switches: %d, Max: %d\n", v.Total, v.Max)
+		default:
+			fmt.Fprintf(&b, "  Objects: %d, Max: %d\n", v.Total, v.Max)
+		}
+		fmt.Fprintf(&b, "  Stack Trace:\n%s", v.StackTrace)
+	}
+	return b.String()
+}
@@ -0,0 +1,173 @@
+//go:build debug
+// +build debug
+
+package debug
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var auditSink []float32
+
+func TestAuditProcessClean(t *testing.T) {
+	ResetRealtimeAudit()
+	EnableRealtimeAudit(DefaultAuditConfig())
+	defer DisableRealtimeAudit()
+	defer ResetRealtimeAudit()
+
+	buffer := make([]float32, 512)
+	runtime.GC()
+	for i := 0; i < 100; i++ {
+		AuditProcess("clean", func() {
+			for j := range buffer {
+				buffer[j] *= 0.5
+			}
+		})
+	}
+
+	for _, v := range GetAuditViolations() {
+		if v.Name == "clean" && v.Kind != ViolationSyscall {
+			// Preemption can show up as a context switch; anything else is a false positive
+			t.Errorf("Unexpected %s violation: %+v", v.Kind, v)
+		}
+	}
+	if p := GetGCPressure(); p.AuditedCalls != 100 {
+		t.Errorf("Expected 100 audited calls, got %d", p.AuditedCalls)
+	}
+}
+
+func TestAuditProcessAllocation(t *testing.T) {
+	ResetRealtimeAudit()
+	EnableRealtimeAudit(DefaultAuditConfig())
+	defer DisableRealtimeAudit()
+	defer ResetRealtimeAudit()
+
+	for i := 0; i < 3; i++ {
+		AuditProcess("alloc", func() {
+			auditSink = make([]float32, 1024)
+		})
+	}
+
+	var found *AuditViolation
+	for _, v := range GetAuditViolations() {
+		if v.Kind == ViolationAllocation && v.Name == "alloc" {
+			v := v
+			found = &v
+		}
+	}
+	if found == nil {
+		t.Fatal("Expected an allocation violation")
+	}
+	if found.Count != 3 {
+		t.Errorf("Expected violations aggregated into one site with count 3, got %d", found.Count)
+	}
+	if !strings.Contains(found.CallSite, "TestAuditProcessAllocation") {
+		t.Errorf("Call site should name the caller, got %s", found.CallSite)
+	}
+	if found.StackTrace == "" {
+		t.Error("Expected a stack trace")
+	}
+
+	report := GetAuditReport()
+	if !strings.Contains(report, "[allocation] alloc") {
+		t.Errorf("Report missing violation:\n%s", report)
+	}
+}
+
+func TestAuditProcessMutexWait(t *testing.T) {
+	ResetRealtimeAudit()
+	EnableRealtimeAudit(DefaultAuditConfig())
+	defer DisableRealtimeAudit()
+	defer ResetRealtimeAudit()
+
+	var mu sync.Mutex
+	mu.Lock()
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		mu.Unlock()
+	}()
+
+	AuditProcess("lock", func() {
+		mu.Lock()
+		mu.Unlock() //nolint:staticcheck
+	})
+
+	for _, v := range GetAuditViolations() {
+		if v.Kind == ViolationMutexWait && v.Name == "lock" {
+			if time.Duration(v.Max) < time.Millisecond {
+				t.Errorf("Expected a wait of several milliseconds, got %v", time.Duration(v.Max))
+			}
+			return
+		}
+	}
+	t.Error("Expected a mutex wait violation")
+}
+
+func TestAuditProcessSyscall(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Syscall detection is only supported on Linux")
+	}
+	ResetRealtimeAudit()
+	EnableRealtimeAudit(DefaultAuditConfig())
+	defer DisableRealtimeAudit()
+	defer ResetRealtimeAudit()
+
+	AuditProcess("sleep", func() {
+		ts := syscall.NsecToTimespec(int64(time.Millisecond))
+		syscall.Nanosleep(&ts, nil)
+	})
+
+	for _, v := range GetAuditViolations() {
+		if v.Kind == ViolationSyscall && v.Name == "sleep" {
+			return
+		}
+	}
+	t.Error("Expected a blocking syscall violation")
+}
+
+func TestAuditProcessPanic(t *testing.T) {
+	cfg := DefaultAuditConfig()
+	cfg.PanicOnViolation = true
+	EnableRealtimeAudit(cfg)
+	defer DisableRealtimeAudit()
+	defer ResetRealtimeAudit()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Expected panic for allocation")
+		}
+		if !strings.Contains(r.(string), "real-time violation in alloc") {
+			t.Errorf("Unexpected panic message: %v", r)
+		}
+	}()
+	AuditProcess("alloc", func() {
+		auditSink = make([]float32, 1024)
+	})
+}
+
+func TestAuditProcessDisabled(t *testing.T) {
+	ResetRealtimeAudit()
+	DisableRealtimeAudit()
+	if RealtimeAuditEnabled() {
+		t.Error("Audit still enabled after DisableRealtimeAudit")
+	}
+
+	called := false
+	AuditProcess("disabled", func() {
+		called = true
+		auditSink = make([]float32, 1024)
+	})
+
+	if !called {
+		t.Error("Function was not called")
+	}
+	if len(GetAuditViolations()) != 0 {
+		t.Error("Disabled audit should not record violations")
+	}
+}
@@ -0,0 +1,23 @@
+//go:build debug && linux
+// +build debug,linux
+
+package debug
+
+import (
+	"syscall"
+	"time"
+)
+
+// rusageThread is RUSAGE_THREAD, which the syscall package does not export
+const rusageThread = 1
+
+// threadUsage returns the CPU time and voluntary context switches of the
+// calling thread. A voluntary switch means the thread blocked in the kernel.
+func threadUsage() (cpu time.Duration, switches uint64, ok bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(rusageThread, &ru); err != nil {
+		return 0, 0, false
+	}
+	cpu = time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+	return cpu, uint64(ru.Nvcsw), true
+}
@@ -0,0 +1,12 @@
+//go:build debug && !linux
+// +build debug,!linux
+
+package debug
+
+import "time"
+
+// threadUsage is unsupported on this platform, so blocking syscalls are not
+// reported
+func threadUsage() (cpu time.Duration, switches uint64, ok bool) {
+	return 0, 0, false
+}
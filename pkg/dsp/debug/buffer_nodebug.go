@@ -40,4 +40,38 @@ func VerifyBufferReuse(buffer []float32, name string, expectedPtr uintptr) uintp
 // DetectAllocation is a no-op when not in debug mode
 func DetectAllocation(fn func()) {
 	fn()
-}
\ No newline at end of file
+}
+
+// EnableRealtimeAudit is a no-op when not in debug mode
+func EnableRealtimeAudit(config AuditConfig) {}
+
+// DisableRealtimeAudit is a no-op when not in debug mode
+func DisableRealtimeAudit() {}
+
+// RealtimeAuditEnabled returns false when not in debug mode
+func RealtimeAuditEnabled() bool {
+	return false
+}
+
+// ResetRealtimeAudit is a no-op when not in debug mode
+func ResetRealtimeAudit() {}
+
+// AuditProcess calls fn directly when not in debug mode
+func AuditProcess(name string, fn func()) {
+	fn()
+}
+
+// GetAuditViolations returns nil when not in debug mode
+func GetAuditViolations() []AuditViolation {
+	return nil
+}
+
+// GetGCPressure returns zero statistics when not in debug mode
+func GetGCPressure() GCPressure {
+	return GCPressure{}
+}
+
+// GetAuditReport returns empty string when not in debug mode
+func GetAuditReport() string {
+	return ""
+}
@@ -28,6 +28,23 @@
 //   - Buffer reuse verification to ensure buffers aren't reallocated
 //   - Detailed allocation reports with stack traces
 //   - A debug buffer pool for testing
+//   - A real-time safety audit of ProcessAudio calls
+//
+// Real-time safety audit:
+//
+// Debug builds wrap every ProcessAudio call made by the plugin component in
+// AuditProcess. With VST3GO_RT_AUDIT=1 set, it reports heap allocations,
+// mutex waits longer than AuditConfig.MaxMutexWait and, on Linux, blocking
+// syscalls. Violations are aggregated per call site with a stack trace and
+// logged with GC pressure statistics when the plugin is unloaded. The audit
+// slows processing down a lot, so it is off otherwise; audit your own code
+// with:
+//
+//	debug.EnableRealtimeAudit(debug.DefaultAuditConfig())
+//	debug.AuditProcess("reverb", func() {
+//	    reverb.Process(buffer)
+//	})
+//	fmt.Print(debug.GetAuditReport())
 //
 // When building without the 'debug' tag, all functions become no-ops
 // with zero overhead.
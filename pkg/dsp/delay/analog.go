@@ -0,0 +1,275 @@
+package delay
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/distortion"
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+// Saturator is the nonlinearity in an AnalogDelay's feedback loop. The
+// distortion package's Waveshaper, TubeSaturation and TapeSaturation all
+// satisfy it.
+type Saturator interface {
+	Process(input float64) float64
+}
+
+// Voicing selects the analog device an AnalogDelay imitates
+type Voicing int
+
+const (
+	// VoicingTape has a fixed bandwidth set by SetTone, and wow and flutter
+	// from the transport
+	VoicingTape Voicing = iota
+
+	// VoicingBBD imitates a bucket brigade chip, whose clock slows down for
+	// longer delays, so longer echoes are darker
+	VoicingBBD
+)
+
+// String returns the name of the voicing
+func (v Voicing) String() string {
+	switch v {
+	case VoicingTape:
+		return "Tape"
+	case VoicingBBD:
+		return "BBD"
+	default:
+		return "Unknown"
+	}
+}
+
+// Analog delay limits
+const (
+	MaxAnalogFeedback = 1.2    // Past 1 the loop self-oscillates into the saturator
+	MaxWow            = 0.002  // Wow depth in seconds at SetWow(1)
+	MaxFlutter        = 0.0002 // Flutter depth in seconds at SetFlutter(1)
+	wowRate           = 0.55   // Hz
+	flutterRate       = 6.3    // Hz
+	bbdStages         = 4096   // Bucket count of the imitated BBD chip
+	driftTime         = 0.3    // Smoothing of the random wow drift, in seconds
+)
+
+// AnalogDelay is a mono echo voiced like a tape machine or bucket brigade
+// (BBD) delay. Its feedback loop runs through bandwidth-limiting filters and
+// a configurable saturator, so repeats get darker and softer and can be
+// pushed into self-oscillation without running away. Wow and flutter
+// modulate the delay time like an uneven tape transport.
+type AnalogDelay struct {
+	line       *Line
+	sampleRate float64
+
+	time    float64 // Seconds
+	maxTime float64 // Seconds
+	target  float64 // Samples
+	current float64 // Gliding delay in samples
+	glide   float64
+
+	voicing   Voicing
+	feedback  float64
+	drive     float64
+	saturator Saturator
+	mix       float32
+
+	// Loop filters
+	tone   float64 // Tape lowpass in Hz
+	lowCut float64 // Highpass in Hz; 0 is off
+	lp     *filter.SVF
+	hp     *filter.SVF
+
+	// Wow and flutter
+	wow          float64 // Depth in samples
+	flutter      float64 // Depth in samples
+	wowPhase     float64
+	flutterPhase float64
+	drift        float64 // Smoothed random wow
+	driftCoeff   float64
+	driftGain    float64 // Scales the drift to about ±1
+	seed         uint32
+}
+
+// NewAnalogDelay creates a tape voiced delay with a 300 ms echo, 45%
+// feedback, a 5 kHz tone, a gentle arctangent saturator and light wow and
+// flutter
+func NewAnalogDelay(maxDelaySeconds, sampleRate float64) *AnalogDelay {
+	margin := 2*(MaxWow+MaxFlutter) + 4/sampleRate
+	d := &AnalogDelay{
+		line:       New(maxDelaySeconds+margin, sampleRate),
+		sampleRate: sampleRate,
+		maxTime:    maxDelaySeconds,
+		glide:      coeff.TimeConstant(timeGlide, sampleRate),
+		feedback:   0.45,
+		drive:      1,
+		mix:        0.5,
+		tone:       5000,
+		lowCut:     80,
+		lp:         filter.NewSVF(1),
+		hp:         filter.NewSVF(1),
+		driftCoeff: coeff.TimeConstant(driftTime, sampleRate),
+		seed:       0x2545f491,
+	}
+
+	// Smoothed uniform noise has a deviation of sqrt((1-c)/(3(1+c)))
+	c := d.driftCoeff
+	d.driftGain = 0.5 * math.Sqrt(3*(1+c)/(1-c))
+
+	shaper := distortion.NewWaveshaper()
+	shaper.SetCurveType(distortion.CurveSaturate)
+	d.saturator = shaper
+
+	d.SetTime(0.3)
+	d.SetWow(0.2)
+	d.SetFlutter(0.2)
+	d.updateFilters()
+	d.current = d.target
+	return d
+}
+
+// SetTime sets the delay time in seconds. Changes glide, bending the pitch
+// of the echoes like a tape machine changing speed.
+func (d *AnalogDelay) SetTime(seconds float64) {
+	d.time = max(1/d.sampleRate, min(d.maxTime, seconds))
+	d.target = d.time * d.sampleRate
+	if d.voicing == VoicingBBD {
+		d.updateFilters()
+	}
+}
+
+// GetTime returns the delay time in seconds
+func (d *AnalogDelay) GetTime() float64 {
+	return d.time
+}
+
+// SetVoicing sets the device the delay imitates
+func (d *AnalogDelay) SetVoicing(v Voicing) {
+	d.voicing = v
+	d.updateFilters()
+}
+
+// GetVoicing returns the device the delay imitates
+func (d *AnalogDelay) GetVoicing() Voicing {
+	return d.voicing
+}
+
+// SetFeedback sets the loop gain (0-MaxAnalogFeedback). Above 1 the
+// echoes build until the saturator holds them.
+func (d *AnalogDelay) SetFeedback(feedback float64) {
+	d.feedback = max(0, min(MaxAnalogFeedback, feedback))
+}
+
+// SetDrive sets how hard the loop drives the saturator (1-20). The level is
+// scaled back down after it, so drive changes the grit, not the volume.
+func (d *AnalogDelay) SetDrive(drive float64) {
+	d.drive = max(1, min(20, drive))
+}
+
+// SetSaturator replaces the loop nonlinearity; nil makes the loop clean
+func (d *AnalogDelay) SetSaturator(s Saturator) {
+	d.saturator = s
+}
+
+// SetTone sets the tape voicing's lowpass cutoff in Hz. The BBD voicing
+// derives its cutoff from the delay time instead.
+func (d *AnalogDelay) SetTone(hz float64) {
+	d.tone = max(200, hz)
+	d.updateFilters()
+}
+
+// SetLowCut sets the loop highpass cutoff in Hz; 0 turns it off
+func (d *AnalogDelay) SetLowCut(hz float64) {
+	d.lowCut = max(0, hz)
+	d.updateFilters()
+}
+
+// SetWow sets the depth of slow, drifting pitch wander (0-1)
+func (d *AnalogDelay) SetWow(depth float64) {
+	d.wow = max(0, min(1, depth)) * MaxWow * d.sampleRate
+}
+
+// SetFlutter sets the depth of fast pitch wobble (0-1)
+func (d *AnalogDelay) SetFlutter(depth float64) {
+	d.flutter = max(0, min(1, depth)) * MaxFlutter * d.sampleRate
+}
+
+// SetMix sets the dry/wet balance (0 = dry, 1 = wet)
+func (d *AnalogDelay) SetMix(mix float64) {
+	d.mix = float32(max(0, min(1, mix)))
+}
+
+// Bandwidth returns the loop lowpass cutoff in Hz
+func (d *AnalogDelay) Bandwidth() float64 {
+	if d.voicing == VoicingBBD {
+		// The chip samples at its clock, which fills every bucket in one
+		// delay time, and passes up to half of that
+		return max(1000, min(0.45*d.sampleRate, bbdStages/(4*d.time)))
+	}
+	return min(d.tone, 0.45*d.sampleRate)
+}
+
+// updateFilters sets the loop filters from the voicing and settings
+func (d *AnalogDelay) updateFilters() {
+	d.lp.SetFrequencyAndQ(d.sampleRate, d.Bandwidth(), filterQ)
+	if d.lowCut > 0 {
+		d.hp.SetFrequencyAndQ(d.sampleRate, min(d.lowCut, 0.45*d.sampleRate), filterQ)
+	}
+}
+
+// modulation returns the wow and flutter offset of the delay in samples
+func (d *AnalogDelay) modulation() float64 {
+	d.wowPhase += wowRate / d.sampleRate
+	if d.wowPhase >= 1 {
+		d.wowPhase--
+	}
+	d.flutterPhase += flutterRate / d.sampleRate
+	if d.flutterPhase >= 1 {
+		d.flutterPhase--
+	}
+
+	// Wow drifts: half a steady cycle, half smoothed noise
+	d.seed ^= d.seed << 13
+	d.seed ^= d.seed >> 17
+	d.seed ^= d.seed << 5
+	noise := float64(d.seed)/(1<<31) - 1
+	d.drift = noise + (d.drift-noise)*d.driftCoeff
+
+	wow := 0.5*sine(d.wowPhase) + 0.5*max(-1, min(1, d.drift*d.driftGain))
+	return d.wow*(1+wow) + d.flutter*(1+sine(d.flutterPhase))
+}
+
+// Process runs one sample through the delay
+func (d *AnalogDelay) Process(input float32) float32 {
+	d.current = d.target + (d.current-d.target)*d.glide
+	wet := d.line.ReadHermite(d.current + d.modulation())
+
+	if d.lowCut > 0 {
+		wet = d.hp.ProcessSample(wet, 0).Highpass
+	}
+	wet = d.lp.ProcessSample(wet, 0).Lowpass
+
+	rec := float64(input) + d.feedback*float64(wet)
+	if d.saturator != nil {
+		rec = d.saturator.Process(rec*d.drive) / d.drive
+	}
+	d.line.Write(float32(rec))
+
+	return input*(1-d.mix) + wet*d.mix
+}
+
+// ProcessBuffer processes a buffer in place - no allocations
+func (d *AnalogDelay) ProcessBuffer(buffer []float32) {
+	for i := range buffer {
+		buffer[i] = d.Process(buffer[i])
+	}
+}
+
+// Reset clears the line and filters and settles the delay time
+func (d *AnalogDelay) Reset() {
+	d.line.Reset()
+	d.lp.Reset()
+	d.hp.Reset()
+	d.current = d.target
+	d.drift = 0
+	d.wowPhase = 0
+	d.flutterPhase = 0
+}
@@ -0,0 +1,134 @@
+package delay
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/distortion"
+)
+
+// cleanAnalog returns a wet analog delay without modulation, for
+// measuring echoes
+func cleanAnalog(time float64) *AnalogDelay {
+	d := NewAnalogDelay(1.0, 48000)
+	d.SetTime(time)
+	d.SetWow(0)
+	d.SetFlutter(0)
+	d.SetMix(1)
+	d.Reset()
+	return d
+}
+
+func TestAnalogDelayEcho(t *testing.T) {
+	d := cleanAnalog(0.01)
+	d.SetSaturator(nil)
+	d.SetFeedback(0.5)
+
+	// A low tone passes the loop filters almost untouched, so each echo is
+	// about half the one before
+	sampleRate := 48000.0
+	n := 480
+	buf := make([]float32, 6*n)
+	for i := range buf[:n/2] {
+		buf[i] = float32(math.Sin(2 * math.Pi * 500 * float64(i) / sampleRate))
+	}
+	d.ProcessBuffer(buf)
+
+	peak := func(k int) float64 {
+		var p float64
+		for _, x := range buf[k*n : (k+1)*n] {
+			p = max(p, math.Abs(float64(x)))
+		}
+		return p
+	}
+	for k := 2; k < 5; k++ {
+		if ratio := peak(k) / peak(k-1); math.Abs(ratio-0.5) > 0.05 {
+			t.Errorf("Echo %d/%d ratio %f, want about 0.5", k, k-1, ratio)
+		}
+	}
+}
+
+func TestAnalogDelaySelfOscillation(t *testing.T) {
+	d := cleanAnalog(0.05)
+	d.SetFeedback(MaxAnalogFeedback)
+	d.SetDrive(4)
+
+	// With the loop gain above 1 the echoes build until the saturator
+	// holds them
+	var peak float64
+	for i := 0; i < 5*48000; i++ {
+		in := float32(0)
+		if i < 100 {
+			in = 0.5
+		}
+		x := math.Abs(float64(d.Process(in)))
+		if math.IsNaN(x) || x > 2 {
+			t.Fatalf("Runaway output %f at sample %d", x, i)
+		}
+		if i > 4*48000 {
+			peak = max(peak, x)
+		}
+	}
+	if peak < 0.05 {
+		t.Errorf("Loop died away at feedback %f: peak %f", MaxAnalogFeedback, peak)
+	}
+}
+
+func TestAnalogDelayBBDBandwidth(t *testing.T) {
+	d := NewAnalogDelay(1.0, 48000)
+	d.SetVoicing(VoicingBBD)
+	d.SetTime(0.1)
+	short := d.Bandwidth()
+	d.SetTime(0.6)
+	long := d.Bandwidth()
+	if long >= short {
+		t.Errorf("Longer BBD delays should be darker: %f Hz at 600 ms, %f Hz at 100 ms", long, short)
+	}
+
+	d.SetVoicing(VoicingTape)
+	d.SetTone(3000)
+	if d.Bandwidth() != 3000 {
+		t.Errorf("Tape bandwidth %f, want the tone setting", d.Bandwidth())
+	}
+}
+
+func TestAnalogDelayWowFlutter(t *testing.T) {
+	// A steady tone through a modulated delay changes pitch, so the wet
+	// signal drifts away from a clean copy
+	clean, wobbly := cleanAnalog(0.1), cleanAnalog(0.1)
+	wobbly.SetWow(1)
+	wobbly.SetFlutter(1)
+	for _, d := range []*AnalogDelay{clean, wobbly} {
+		d.SetFeedback(0)
+		d.SetSaturator(nil)
+	}
+
+	var diff float64
+	for i := 0; i < 48000; i++ {
+		in := float32(math.Sin(2 * math.Pi * 1000 * float64(i) / 48000))
+		a, b := clean.Process(in), wobbly.Process(in)
+		if i > 24000 {
+			diff = max(diff, math.Abs(float64(a-b)))
+		}
+	}
+	if diff < 0.1 {
+		t.Errorf("Wow and flutter barely changed the echo: max difference %f", diff)
+	}
+}
+
+func TestAnalogDelaySaturator(t *testing.T) {
+	// Any distortion processor can sit in the loop
+	var _ Saturator = distortion.NewTubeSaturation()
+	var _ Saturator = distortion.NewTapeSaturation(48000)
+
+	d := cleanAnalog(0.01)
+	d.SetSaturator(distortion.NewTubeSaturation())
+	buf := make([]float32, 4800)
+	buf[0] = 1
+	d.ProcessBuffer(buf)
+	for i, x := range buf {
+		if math.IsNaN(float64(x)) {
+			t.Fatalf("NaN at sample %d", i)
+		}
+	}
+}
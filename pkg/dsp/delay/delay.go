@@ -1,7 +1,11 @@
 // Package delay provides delay line implementations for audio effects
 package delay
 
-import "math"
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/interpolation"
+)
 
 // Line implements a basic delay line with linear interpolation
 type Line struct {
@@ -45,6 +49,10 @@ func (d *Line) Read(delaySamples float64) float32 {
 	readPos := float64(d.writePos) - delaySamples
 	if readPos < 0 {
 		readPos += float64(d.bufferSize)
+		// A delay a hair above writePos rounds up to the buffer size
+		if readPos >= float64(d.bufferSize) {
+			readPos = 0
+		}
 	}
 
 	// Linear interpolation
@@ -59,6 +67,28 @@ func (d *Line) Read(delaySamples float64) float32 {
 	return s1*(1.0-frac) + s2*frac
 }
 
+// ReadHermite gets a delayed sample with 4-point Hermite interpolation.
+// It keeps more of the top end than Read when the delay is fractional or
+// modulated, at the cost of two more reads. Delays under one sample read
+// as one sample.
+func (d *Line) ReadHermite(delaySamples float64) float32 {
+	delaySamples = max(1, delaySamples)
+	readPos := float64(d.writePos) - delaySamples
+	if readPos < 0 {
+		readPos += float64(d.bufferSize)
+	}
+
+	i := int(readPos)
+	frac := float32(readPos - float64(i))
+	if i >= d.bufferSize {
+		i -= d.bufferSize
+	}
+	at := func(k int) float32 {
+		return d.buffer[(k+d.bufferSize)%d.bufferSize]
+	}
+	return interpolation.Hermite(at(i-1), at(i), at(i+1), at(i+2), frac)
+}
+
 // ReadMs gets a delayed sample (delay in milliseconds)
 func (d *Line) ReadMs(delayMs float64) float32 {
 	delaySamples := delayMs * d.sampleRate / 1000.0
@@ -184,55 +214,6 @@ func (c *CombDelay) ProcessBuffer(buffer []float32, delaySamples float64) {
 	}
 }
 
-// MultiTapDelay provides multiple delay taps
-type MultiTapDelay struct {
-	Line
-	numTaps int
-}
-
-// TapOutput represents a single tap configuration
-type TapOutput struct {
-	DelaySamples float64
-	Gain         float32
-	Pan          float32 // -1 (left) to 1 (right)
-}
-
-// NewMultiTap creates a multi-tap delay
-func NewMultiTap(maxDelaySeconds, sampleRate float64, numTaps int) *MultiTapDelay {
-	return &MultiTapDelay{
-		Line:    *New(maxDelaySeconds, sampleRate),
-		numTaps: numTaps,
-	}
-}
-
-// ProcessMultiTap processes with multiple taps - returns stereo output
-func (m *MultiTapDelay) ProcessMultiTap(input float32, taps []TapOutput, outL, outR *float32) {
-	// Write input to delay line
-	m.Write(input)
-
-	// Clear outputs
-	*outL = 0
-	*outR = 0
-
-	// Sum all taps
-	for i := range taps {
-		if i >= m.numTaps {
-			break
-		}
-
-		tap := &taps[i]
-		delayed := m.Tap(tap.DelaySamples) * tap.Gain
-
-		// Pan (constant power)
-		panAngle := (tap.Pan + 1.0) * 0.25 * math.Pi // 0 to π/2
-		leftGain := float32(math.Cos(float64(panAngle)))
-		rightGain := float32(math.Sin(float64(panAngle)))
-
-		*outL += delayed * leftGain
-		*outR += delayed * rightGain
-	}
-}
-
 // ModulatedDelay implements a delay with LFO modulation
 type ModulatedDelay struct {
 	Line
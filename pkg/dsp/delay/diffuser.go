@@ -0,0 +1,204 @@
+package delay
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+)
+
+// Diffuser limits
+const (
+	MaxDiffuserStages = 12
+	MaxDiffusion      = 0.8   // Allpass coefficient at full density
+	MaxDiffuserDepth  = 5.0   // Modulation depth in ms
+	defaultStages     = 6     // Stages of a new diffuser
+	stereoSpread      = 0.047 // Right stage length offset, as a fraction
+)
+
+// stageRatios sets each stage's length relative to the size. The ratios
+// are not multiples of each other, so the stages' echoes do not line up
+// and reinforce.
+var stageRatios = [MaxDiffuserStages]float64{
+	1.0, 0.7713, 0.6137, 0.4771, 0.3797, 0.2963,
+	0.2311, 0.1847, 0.1433, 0.1129, 0.0887, 0.0691,
+}
+
+// diffuserStage is one modulated Schroeder allpass
+type diffuserStage struct {
+	line    *Line
+	target  float64 // Delay in samples
+	current float64 // Gliding delay in samples
+	phase   float64 // Modulation phase (0-1)
+	rate    float64 // Modulation phase increment per sample
+}
+
+// Diffuser smears transients into a dense wash with a chain of modulated
+// Schroeder allpasses. Each stage passes every frequency at unity gain, so
+// the diffuser changes the texture of a sound without colouring its tone.
+// It is the building block for reverb input diffusion, shimmer and smeared
+// delays. The left and right chains have slightly different lengths, so a
+// mono source comes out decorrelated.
+type Diffuser struct {
+	sampleRate float64
+	maxSize    float64
+	stages     [2][MaxDiffuserStages]diffuserStage
+	numStages  int
+	size       float64 // Longest stage in seconds
+	diffusion  float32 // Allpass coefficient
+	depth      float64 // Modulation depth in samples
+	modRate    float64 // Hz
+	glide      float64
+}
+
+// NewDiffuser creates a diffuser whose longest stage can be up to maxSize
+// seconds. It starts with 6 stages, a 50 ms size, full density and gentle
+// modulation.
+func NewDiffuser(maxSize, sampleRate float64) *Diffuser {
+	d := &Diffuser{
+		sampleRate: sampleRate,
+		maxSize:    maxSize,
+		numStages:  defaultStages,
+		glide:      coeff.TimeConstant(timeGlide, sampleRate),
+	}
+
+	maxDepth := MaxDiffuserDepth / 1000.0
+	for ch := range d.stages {
+		for i := range d.stages[ch] {
+			length := maxSize*stageRatios[i]*(1+stereoSpread) + 2*maxDepth + 3/sampleRate
+			st := &d.stages[ch][i]
+			st.line = New(length, sampleRate)
+			// Spread the LFO phases so the stages do not move together
+			st.phase = float64(ch*MaxDiffuserStages+i) * 0.618034
+			st.phase -= math.Floor(st.phase)
+		}
+	}
+
+	d.SetSize(0.05)
+	d.SetDensity(1)
+	d.SetModulation(0.5, 0.3)
+	d.Reset()
+	return d
+}
+
+// SetStages sets how many allpass stages run (1-MaxDiffuserStages). More
+// stages give a smoother, denser smear.
+func (d *Diffuser) SetStages(n int) {
+	d.numStages = max(1, min(MaxDiffuserStages, n))
+}
+
+// GetStages returns the number of stages
+func (d *Diffuser) GetStages() int {
+	return d.numStages
+}
+
+// SetSize sets the length of the longest stage in seconds; the others are
+// shorter. Small sizes smear a sound, large ones blur it into echoes.
+// Changes glide, so the size can be automated.
+func (d *Diffuser) SetSize(seconds float64) {
+	d.size = max(0.001, min(d.maxSize, seconds))
+	d.updateDelays()
+}
+
+// GetSize returns the size in seconds
+func (d *Diffuser) GetSize() float64 {
+	return d.size
+}
+
+// SetDensity sets how strongly each stage diffuses (0-1). It scales the
+// allpass coefficient up to MaxDiffusion; at 0 the diffuser is a plain
+// delay.
+func (d *Diffuser) SetDensity(density float64) {
+	d.diffusion = float32(max(0, min(1, density)) * MaxDiffusion)
+}
+
+// SetModulation sets the rate in Hz and depth in ms (0-MaxDiffuserDepth)
+// of the stage length modulation. Modulation breaks up the metallic ring
+// of a static allpass chain.
+func (d *Diffuser) SetModulation(rateHz, depthMs float64) {
+	d.modRate = max(0, rateHz)
+	d.depth = max(0, min(MaxDiffuserDepth, depthMs)) / 1000.0 * d.sampleRate
+	for ch := range d.stages {
+		for i := range d.stages[ch] {
+			// Detune the stages' rates so they drift against each other
+			d.stages[ch][i].rate = d.modRate * (1 + 0.13*float64(i) + 0.07*float64(ch)) / d.sampleRate
+		}
+	}
+}
+
+// updateDelays sets each stage's target length from the size
+func (d *Diffuser) updateDelays() {
+	for ch := range d.stages {
+		for i := range d.stages[ch] {
+			length := d.size * stageRatios[i]
+			if ch == 1 {
+				length *= 1 + stereoSpread*float64(1-2*(i%2))
+			}
+			// Whole samples, so an unmodulated stage does not interpolate
+			d.stages[ch][i].target = max(1, math.Round(length*d.sampleRate))
+		}
+	}
+}
+
+// sine approximates sin(2π·phase) with two parabolas
+func sine(phase float64) float64 {
+	x := 2*phase - 1
+	return -4 * x * (1 - math.Abs(x))
+}
+
+// process runs one sample through a channel's chain
+func (d *Diffuser) process(input float32, ch int) float32 {
+	g := d.diffusion
+	x := input
+	for i := 0; i < d.numStages; i++ {
+		st := &d.stages[ch][i]
+		st.current = st.target + (st.current-st.target)*d.glide
+		st.phase += st.rate
+		if st.phase >= 1 {
+			st.phase--
+		}
+
+		delayed := st.line.ReadHermite(st.current + d.depth*(1+sine(st.phase)))
+		w := x + g*delayed
+		st.line.Write(w)
+		x = delayed - g*w
+	}
+	return x
+}
+
+// Process diffuses one sample through the left chain
+func (d *Diffuser) Process(input float32) float32 {
+	return d.process(input, 0)
+}
+
+// ProcessStereo diffuses one stereo sample
+func (d *Diffuser) ProcessStereo(inL, inR float32) (outL, outR float32) {
+	return d.process(inL, 0), d.process(inR, 1)
+}
+
+// ProcessBuffer diffuses a buffer in place through the left chain - no
+// allocations
+func (d *Diffuser) ProcessBuffer(buffer []float32) {
+	for i := range buffer {
+		buffer[i] = d.process(buffer[i], 0)
+	}
+}
+
+// ProcessStereoBuffer diffuses stereo buffers in place - no allocations
+func (d *Diffuser) ProcessStereoBuffer(left, right []float32) {
+	n := min(len(left), len(right))
+	for i := 0; i < n; i++ {
+		left[i] = d.process(left[i], 0)
+		right[i] = d.process(right[i], 1)
+	}
+}
+
+// Reset clears the stages and settles their lengths
+func (d *Diffuser) Reset() {
+	for ch := range d.stages {
+		for i := range d.stages[ch] {
+			st := &d.stages[ch][i]
+			st.line.Reset()
+			st.current = st.target
+		}
+	}
+}
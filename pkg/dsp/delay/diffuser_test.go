@@ -0,0 +1,121 @@
+package delay
+
+import (
+	"math"
+	"testing"
+)
+
+// diffuseImpulse returns the left and right response to an impulse on both
+// inputs
+func diffuseImpulse(d *Diffuser, n int) (left, right []float32) {
+	left = make([]float32, n)
+	right = make([]float32, n)
+	left[0], right[0] = 1, 1
+	d.ProcessStereoBuffer(left, right)
+	return left, right
+}
+
+func TestDiffuserEnergy(t *testing.T) {
+	d := NewDiffuser(0.1, 48000)
+	d.SetModulation(0, 0)
+	d.Reset()
+
+	// A chain of allpasses keeps the energy of an impulse, only spread out
+	left, _ := diffuseImpulse(d, 48000)
+	var energy, peak float64
+	for _, x := range left {
+		energy += float64(x * x)
+		peak = max(peak, math.Abs(float64(x)))
+	}
+	if math.Abs(energy-1) > 0.01 {
+		t.Errorf("Impulse energy %f, want 1", energy)
+	}
+	if peak > 0.5 {
+		t.Errorf("Impulse peak %f, want it smeared below 0.5", peak)
+	}
+}
+
+func TestDiffuserDensity(t *testing.T) {
+	// count returns how many samples of the impulse response are audible
+	count := func(density float64, stages int) int {
+		d := NewDiffuser(0.1, 48000)
+		d.SetModulation(0, 0)
+		d.SetDensity(density)
+		d.SetStages(stages)
+		left, _ := diffuseImpulse(d, 9600)
+		n := 0
+		for _, x := range left {
+			if math.Abs(float64(x)) > 1e-3 {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := count(0, 6); n != 1 {
+		t.Errorf("Zero density should be a plain delay, got %d echoes", n)
+	}
+	if sparse, dense := count(0.5, 6), count(1, 6); dense <= sparse {
+		t.Errorf("Density 1 gave %d echoes, density 0.5 %d", dense, sparse)
+	}
+	if few, many := count(1, 2), count(1, 8); many <= few {
+		t.Errorf("8 stages gave %d echoes, 2 stages %d", many, few)
+	}
+}
+
+func TestDiffuserStereo(t *testing.T) {
+	d := NewDiffuser(0.1, 48000)
+	left, right := diffuseImpulse(d, 9600)
+
+	// The chains differ, so the same input comes out decorrelated
+	var lr, ll, rr float64
+	for i := range left {
+		lr += float64(left[i] * right[i])
+		ll += float64(left[i] * left[i])
+		rr += float64(right[i] * right[i])
+	}
+	if c := lr / math.Sqrt(ll*rr); c > 0.5 {
+		t.Errorf("Left/right correlation %f, want below 0.5", c)
+	}
+}
+
+func TestDiffuserSettings(t *testing.T) {
+	d := NewDiffuser(0.1, 48000)
+	d.SetStages(100)
+	if d.GetStages() != MaxDiffuserStages {
+		t.Errorf("Stages = %d, want %d", d.GetStages(), MaxDiffuserStages)
+	}
+	d.SetSize(1)
+	if d.GetSize() != 0.1 {
+		t.Errorf("Size = %f, want clamp to 0.1", d.GetSize())
+	}
+
+	// Sweeping the size and modulating hard stays bounded
+	d.SetModulation(5, MaxDiffuserDepth)
+	for i := 0; i < 48000; i++ {
+		if i%4800 == 0 {
+			d.SetSize(0.01 + 0.09*float64(i)/48000)
+		}
+		x := d.Process(float32(math.Sin(float64(i) * 0.05)))
+		if math.IsNaN(float64(x)) || math.Abs(float64(x)) > 4 {
+			t.Fatalf("Unbounded output %f at sample %d", x, i)
+		}
+	}
+
+	d.Reset()
+	if x := d.Process(0); x != 0 {
+		t.Errorf("Output after reset %f", x)
+	}
+}
+
+func TestDiffuserNoAllocs(t *testing.T) {
+	d := NewDiffuser(0.1, 48000)
+	left := make([]float32, 512)
+	right := make([]float32, 512)
+	allocs := testing.AllocsPerRun(10, func() {
+		d.ProcessStereoBuffer(left, right)
+	})
+	if allocs != 0 {
+		t.Errorf("ProcessStereoBuffer allocated %f times", allocs)
+	}
+}
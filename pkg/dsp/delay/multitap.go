@@ -0,0 +1,190 @@
+package delay
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+// timeGlide is how long, in seconds, delay times take to follow a change.
+// Gliding instead of jumping bends the pitch of the echoes briefly, like a
+// tape machine, rather than clicking.
+const timeGlide = 0.05
+
+// filterQ is the Q of the tap and feedback filters, a Butterworth response
+const filterQ = 0.7071
+
+// Tap configures one tap of a MultiTapDelay
+type Tap struct {
+	Time     float64 // Delay in seconds
+	Gain     float32 // Linear output gain
+	Pan      float32 // -1 (left) to 1 (right)
+	Feedback float32 // Share of the tap fed back into the line (0-1)
+	Lowpass  float64 // Lowpass cutoff in Hz; 0 leaves the top open
+	Highpass float64 // Highpass cutoff in Hz; 0 leaves the bottom open
+}
+
+// tapState is a tap with its smoothed delay, pan gains and filters
+type tapState struct {
+	Tap
+	target  float64 // Delay in samples
+	current float64 // Gliding delay in samples
+	gainL   float32
+	gainR   float32
+	lp      *filter.SVF
+	hp      *filter.SVF
+}
+
+// MultiTapDelay provides multiple delay taps. Each tap configured with
+// SetTap has its own time, gain, pan and filters, and can feed back into
+// the line, so the taps build rhythmic patterns that repeat and darken.
+type MultiTapDelay struct {
+	Line
+	numTaps int
+	taps    []tapState
+	glide   float64
+}
+
+// TapOutput represents a single tap configuration
+type TapOutput struct {
+	DelaySamples float64
+	Gain         float32
+	Pan          float32 // -1 (left) to 1 (right)
+}
+
+// NewMultiTap creates a multi-tap delay. The taps start silent; configure
+// them with SetTap.
+func NewMultiTap(maxDelaySeconds, sampleRate float64, numTaps int) *MultiTapDelay {
+	numTaps = max(1, numTaps)
+	m := &MultiTapDelay{
+		Line:    *New(maxDelaySeconds, sampleRate),
+		numTaps: numTaps,
+		taps:    make([]tapState, numTaps),
+		glide:   coeff.TimeConstant(timeGlide, sampleRate),
+	}
+	for i := range m.taps {
+		m.taps[i].lp = filter.NewSVF(1)
+		m.taps[i].hp = filter.NewSVF(1)
+		m.SetTap(i, Tap{})
+	}
+	return m
+}
+
+// NumTaps returns the number of taps
+func (m *MultiTapDelay) NumTaps() int {
+	return m.numTaps
+}
+
+// SetTap configures tap i. The time is limited to the length of the line
+// and cutoffs to below Nyquist. Time changes glide over about 50 ms,
+// except on a tap that was silent.
+func (m *MultiTapDelay) SetTap(i int, tap Tap) {
+	if i < 0 || i >= m.numTaps {
+		return
+	}
+	t := &m.taps[i]
+	silent := t.Gain == 0 && t.Feedback == 0
+	tap.Feedback = max(0, min(1, tap.Feedback))
+	tap.Pan = max(-1, min(1, tap.Pan))
+	t.Tap = tap
+	t.target = max(1, min(float64(m.bufferSize-1), tap.Time*m.sampleRate))
+	if silent {
+		// Nothing to glide from; a tap coming in starts at its time
+		t.current = t.target
+	}
+
+	// Pan (constant power)
+	panAngle := float64(tap.Pan+1) * 0.25 * math.Pi
+	t.gainL = float32(math.Cos(panAngle)) * tap.Gain
+	t.gainR = float32(math.Sin(panAngle)) * tap.Gain
+
+	if tap.Lowpass > 0 {
+		t.lp.SetFrequencyAndQ(m.sampleRate, min(tap.Lowpass, 0.45*m.sampleRate), filterQ)
+	}
+	if tap.Highpass > 0 {
+		t.hp.SetFrequencyAndQ(m.sampleRate, min(tap.Highpass, 0.45*m.sampleRate), filterQ)
+	}
+}
+
+// GetTap returns the configuration of tap i
+func (m *MultiTapDelay) GetTap(i int) Tap {
+	if i < 0 || i >= m.numTaps {
+		return Tap{}
+	}
+	return m.taps[i].Tap
+}
+
+// Process runs one sample through the configured taps and returns the
+// panned sum. Tap feedback is soft limited, so it cannot run away even when
+// the feedback of all taps adds up past 1.
+func (m *MultiTapDelay) Process(input float32) (left, right float32) {
+	var feedback float32
+	for i := range m.taps {
+		t := &m.taps[i]
+		t.current = t.target + (t.current-t.target)*m.glide
+
+		delayed := m.Read(t.current)
+		if t.Highpass > 0 {
+			delayed = t.hp.ProcessSample(delayed, 0).Highpass
+		}
+		if t.Lowpass > 0 {
+			delayed = t.lp.ProcessSample(delayed, 0).Lowpass
+		}
+
+		feedback += delayed * t.Feedback
+		left += delayed * t.gainL
+		right += delayed * t.gainR
+	}
+	m.Write(input + fastmath.Medium.Tanh(feedback))
+	return left, right
+}
+
+// ProcessBuffer runs a mono buffer through the taps into a stereo pair -
+// no allocations
+func (m *MultiTapDelay) ProcessBuffer(input, outL, outR []float32) {
+	n := min(len(input), len(outL), len(outR))
+	for i := 0; i < n; i++ {
+		outL[i], outR[i] = m.Process(input[i])
+	}
+}
+
+// Reset clears the line and tap filters and settles the tap times
+func (m *MultiTapDelay) Reset() {
+	m.Line.Reset()
+	for i := range m.taps {
+		t := &m.taps[i]
+		t.current = t.target
+		t.lp.Reset()
+		t.hp.Reset()
+	}
+}
+
+// ProcessMultiTap processes with multiple taps - returns stereo output
+func (m *MultiTapDelay) ProcessMultiTap(input float32, taps []TapOutput, outL, outR *float32) {
+	// Write input to delay line
+	m.Write(input)
+
+	// Clear outputs
+	*outL = 0
+	*outR = 0
+
+	// Sum all taps
+	for i := range taps {
+		if i >= m.numTaps {
+			break
+		}
+
+		tap := &taps[i]
+		delayed := m.Tap(tap.DelaySamples) * tap.Gain
+
+		// Pan (constant power)
+		panAngle := (tap.Pan + 1.0) * 0.25 * math.Pi // 0 to π/2
+		leftGain := float32(math.Cos(float64(panAngle)))
+		rightGain := float32(math.Sin(float64(panAngle)))
+
+		*outL += delayed * leftGain
+		*outR += delayed * rightGain
+	}
+}
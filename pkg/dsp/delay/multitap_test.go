@@ -0,0 +1,96 @@
+package delay
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMultiTapTimesAndPan(t *testing.T) {
+	sampleRate := 48000.0
+	m := NewMultiTap(1.0, sampleRate, 2)
+	m.SetTap(0, Tap{Time: 0.01, Gain: 1, Pan: -1})
+	m.SetTap(1, Tap{Time: 0.02, Gain: 0.5, Pan: 1})
+
+	// An impulse comes back at each tap's time on its side
+	for i := 0; i < 1000; i++ {
+		in := float32(0)
+		if i == 0 {
+			in = 1
+		}
+		l, r := m.Process(in)
+		switch i {
+		case 480:
+			if math.Abs(float64(l-1)) > 1e-4 || math.Abs(float64(r)) > 1e-4 {
+				t.Errorf("tap 1: got %f/%f, want 1/0", l, r)
+			}
+		case 960:
+			if math.Abs(float64(l)) > 1e-4 || math.Abs(float64(r-0.5)) > 1e-4 {
+				t.Errorf("tap 2: got %f/%f, want 0/0.5", l, r)
+			}
+		default:
+			if l != 0 || r != 0 {
+				t.Fatalf("sample %d: unexpected output %f/%f", i, l, r)
+			}
+		}
+	}
+}
+
+func TestMultiTapFeedback(t *testing.T) {
+	m := NewMultiTap(1.0, 48000, 1)
+	m.SetTap(0, Tap{Time: 0.001, Gain: 1, Feedback: 0.5})
+
+	// Each repeat is half the one before
+	var peaks []float32
+	for i := 0; i < 200; i++ {
+		in := float32(0)
+		if i == 0 {
+			in = 1
+		}
+		l, r := m.Process(in)
+		if i > 0 && i%48 == 0 {
+			peaks = append(peaks, l+r)
+		}
+	}
+	for i := 1; i < len(peaks); i++ {
+		if ratio := peaks[i] / peaks[i-1]; math.Abs(float64(ratio)-0.5) > 0.05 {
+			t.Errorf("repeat %d: ratio %f, want 0.5", i, ratio)
+		}
+	}
+}
+
+func TestMultiTapFilter(t *testing.T) {
+	sampleRate := 48000.0
+	m := NewMultiTap(1.0, sampleRate, 1)
+	m.SetTap(0, Tap{Time: 0.001, Gain: 1, Lowpass: 500})
+
+	// A tone well above the cutoff is mostly removed
+	var peak float32
+	for i := 0; i < 4800; i++ {
+		in := float32(math.Sin(2 * math.Pi * 8000 * float64(i) / sampleRate))
+		l, r := m.Process(in)
+		if i > 2400 {
+			peak = max(peak, l*l+r*r)
+		}
+	}
+	if peak > 0.01 {
+		t.Errorf("lowpassed tap peak power %f, want below 0.01", peak)
+	}
+}
+
+func TestMultiTapStability(t *testing.T) {
+	m := NewMultiTap(0.1, 48000, 4)
+	for i := 0; i < m.NumTaps(); i++ {
+		m.SetTap(i, Tap{Time: 0.003 * float64(i+1), Gain: 1, Feedback: 1})
+	}
+	for i := 0; i < 48000; i++ {
+		l, r := m.Process(1)
+		if math.IsNaN(float64(l)) || math.Abs(float64(l)) > 100 || math.Abs(float64(r)) > 100 {
+			t.Fatalf("unbounded output %f at sample %d", l, i)
+		}
+	}
+
+	m.Reset()
+	if l, r := m.Process(0); l != 0 || r != 0 {
+		t.Errorf("output after reset: %f/%f", l, r)
+	}
+}
@@ -0,0 +1,236 @@
+package delay
+
+import (
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+// MaxFeedback is the highest feedback a StereoDelay accepts
+const MaxFeedback = 0.99
+
+// Mode selects how a StereoDelay routes its input and feedback
+type Mode int
+
+const (
+	// ModeStereo delays each channel on its own; crossfeed blends some of
+	// each channel's feedback into the other
+	ModeStereo Mode = iota
+
+	// ModePingPong feeds the input, summed to mono, into the left line and
+	// crosses all feedback, so the echoes bounce from side to side
+	ModePingPong
+)
+
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModeStereo:
+		return "Stereo"
+	case ModePingPong:
+		return "Ping-Pong"
+	default:
+		return "Unknown"
+	}
+}
+
+// SyncedTime returns the length in seconds of a number of beats at a tempo
+// in BPM, or 0 for a tempo or length that is not positive
+func SyncedTime(bpm, beats float64) float64 {
+	if bpm <= 0 || beats <= 0 {
+		return 0
+	}
+	return beats * 60.0 / bpm
+}
+
+// StereoDelay is a stereo echo with ping-pong and crossfeed routing. Its
+// feedback path runs through a highpass and lowpass filter, so each repeat
+// is thinner and darker than the one before, and through a soft limiter.
+// Times can be set in seconds or locked to a tempo as a number of beats.
+type StereoDelay struct {
+	sampleRate float64
+	lines      [2]*Line
+
+	// Delay times
+	times   [2]float64 // Seconds
+	target  [2]float64 // Samples
+	current [2]float64 // Gliding delay in samples
+	glide   float64
+
+	// Tempo sync
+	synced bool
+	tempo  float64    // BPM
+	beats  [2]float64 // Beats per echo, per channel
+
+	// Routing
+	mode      Mode
+	feedback  float32
+	crossfeed float32
+	mix       float32
+
+	// Feedback filters
+	lowpass  float64 // Hz; 0 is off
+	highpass float64 // Hz; 0 is off
+	lp       *filter.SVF
+	hp       *filter.SVF
+}
+
+// NewStereoDelay creates a stereo delay with a 375 ms echo on both sides,
+// 40% feedback and an even mix
+func NewStereoDelay(maxDelaySeconds, sampleRate float64) *StereoDelay {
+	d := &StereoDelay{
+		sampleRate: sampleRate,
+		lines:      [2]*Line{New(maxDelaySeconds, sampleRate), New(maxDelaySeconds, sampleRate)},
+		glide:      coeff.TimeConstant(timeGlide, sampleRate),
+		tempo:      120.0,
+		beats:      [2]float64{0.75, 0.75},
+		feedback:   0.4,
+		mix:        0.5,
+		lp:         filter.NewSVF(2),
+		hp:         filter.NewSVF(2),
+	}
+	d.SetTime(0.375, 0.375)
+	d.current = d.target
+	return d
+}
+
+// SetTime sets the left and right delay times in seconds and turns tempo
+// sync off
+func (d *StereoDelay) SetTime(left, right float64) {
+	d.synced = false
+	d.setTimes(left, right)
+}
+
+// SetSync locks the delay times to a tempo in BPM, as a number of beats per
+// echo on each side. Dotted and triplet times are fractions of a beat, such
+// as 0.75 for a dotted eighth.
+func (d *StereoDelay) SetSync(bpm, beatsLeft, beatsRight float64) {
+	if bpm <= 0 || beatsLeft <= 0 || beatsRight <= 0 {
+		return
+	}
+	d.synced = true
+	d.tempo = bpm
+	d.beats = [2]float64{beatsLeft, beatsRight}
+	d.setTimes(SyncedTime(bpm, beatsLeft), SyncedTime(bpm, beatsRight))
+}
+
+// SetTempo updates the tempo of synced delay times; free times are kept
+func (d *StereoDelay) SetTempo(bpm float64) {
+	if d.synced && bpm > 0 && bpm != d.tempo {
+		d.SetSync(bpm, d.beats[0], d.beats[1])
+	}
+}
+
+// IsSynced reports whether the delay times follow a tempo
+func (d *StereoDelay) IsSynced() bool {
+	return d.synced
+}
+
+// setTimes limits the times to the length of the lines
+func (d *StereoDelay) setTimes(left, right float64) {
+	maxSamples := float64(d.lines[0].bufferSize - 1)
+	for ch, t := range [2]float64{left, right} {
+		d.target[ch] = max(1, min(maxSamples, t*d.sampleRate))
+		d.times[ch] = d.target[ch] / d.sampleRate
+	}
+}
+
+// GetTime returns the left and right delay times in seconds
+func (d *StereoDelay) GetTime() (left, right float64) {
+	return d.times[0], d.times[1]
+}
+
+// SetMode sets the routing
+func (d *StereoDelay) SetMode(mode Mode) {
+	d.mode = mode
+}
+
+// GetMode returns the routing
+func (d *StereoDelay) GetMode() Mode {
+	return d.mode
+}
+
+// SetFeedback sets how much of each echo is fed back (0-MaxFeedback)
+func (d *StereoDelay) SetFeedback(feedback float64) {
+	d.feedback = float32(max(0, min(MaxFeedback, feedback)))
+}
+
+// SetCrossfeed sets how much of each channel's feedback goes to the other
+// side in stereo mode (0-1). At 1 the echoes alternate sides like
+// ping-pong, but keep the input's stereo image.
+func (d *StereoDelay) SetCrossfeed(amount float64) {
+	d.crossfeed = float32(max(0, min(1, amount)))
+}
+
+// SetFilter sets the highpass and lowpass cutoffs of the feedback path in
+// Hz; 0 turns a filter off
+func (d *StereoDelay) SetFilter(highpass, lowpass float64) {
+	d.highpass = max(0, min(highpass, 0.45*d.sampleRate))
+	d.lowpass = max(0, min(lowpass, 0.45*d.sampleRate))
+	if d.highpass > 0 {
+		d.hp.SetFrequencyAndQ(d.sampleRate, d.highpass, filterQ)
+	}
+	if d.lowpass > 0 {
+		d.lp.SetFrequencyAndQ(d.sampleRate, d.lowpass, filterQ)
+	}
+}
+
+// SetMix sets the dry/wet balance (0 = dry, 1 = wet)
+func (d *StereoDelay) SetMix(mix float64) {
+	d.mix = float32(max(0, min(1, mix)))
+}
+
+// filter runs an echo through the feedback filters
+func (d *StereoDelay) filter(x float32, ch int) float32 {
+	if d.highpass > 0 {
+		x = d.hp.ProcessSample(x, ch).Highpass
+	}
+	if d.lowpass > 0 {
+		x = d.lp.ProcessSample(x, ch).Lowpass
+	}
+	return x
+}
+
+// ProcessStereo processes one stereo sample. The wet signal is taken after
+// the feedback filters, so the first echo is already filtered.
+func (d *StereoDelay) ProcessStereo(inL, inR float32) (outL, outR float32) {
+	for ch := range d.current {
+		d.current[ch] = d.target[ch] + (d.current[ch]-d.target[ch])*d.glide
+	}
+	wetL := d.filter(d.lines[0].Read(d.current[0]), 0)
+	wetR := d.filter(d.lines[1].Read(d.current[1]), 1)
+
+	var writeL, writeR float32
+	switch d.mode {
+	case ModePingPong:
+		writeL = 0.5*(inL+inR) + fastmath.Medium.Tanh(wetR*d.feedback)
+		writeR = fastmath.Medium.Tanh(wetL * d.feedback)
+	default:
+		x := d.crossfeed
+		writeL = inL + fastmath.Medium.Tanh(((1-x)*wetL+x*wetR)*d.feedback)
+		writeR = inR + fastmath.Medium.Tanh(((1-x)*wetR+x*wetL)*d.feedback)
+	}
+	d.lines[0].Write(writeL)
+	d.lines[1].Write(writeR)
+
+	dry := 1 - d.mix
+	return inL*dry + wetL*d.mix, inR*dry + wetR*d.mix
+}
+
+// ProcessStereoBuffer processes stereo buffers in place - no allocations
+func (d *StereoDelay) ProcessStereoBuffer(left, right []float32) {
+	n := min(len(left), len(right))
+	for i := 0; i < n; i++ {
+		left[i], right[i] = d.ProcessStereo(left[i], right[i])
+	}
+}
+
+// Reset clears the lines and filters and settles the delay times
+func (d *StereoDelay) Reset() {
+	for _, line := range d.lines {
+		line.Reset()
+	}
+	d.current = d.target
+	d.lp.Reset()
+	d.hp.Reset()
+}
@@ -0,0 +1,131 @@
+package delay
+
+import (
+	"math"
+	"testing"
+)
+
+// impulseResponse runs an impulse on the left input through a fully wet
+// delay and returns the outputs
+func impulseResponse(d *StereoDelay, n int) (left, right []float32) {
+	d.SetMix(1)
+	left = make([]float32, n)
+	right = make([]float32, n)
+	left[0] = 1
+	d.ProcessStereoBuffer(left, right)
+	return left, right
+}
+
+func TestSyncedTime(t *testing.T) {
+	if got := SyncedTime(120, 0.75); got != 0.375 {
+		t.Errorf("dotted eighth at 120 BPM = %f s, want 0.375", got)
+	}
+	if got := SyncedTime(0, 1); got != 0 {
+		t.Errorf("zero tempo = %f, want 0", got)
+	}
+}
+
+func TestStereoDelaySync(t *testing.T) {
+	d := NewStereoDelay(2.0, 48000)
+	d.SetSync(100, 1, 0.5)
+	if l, r := d.GetTime(); l != 0.6 || r != 0.3 {
+		t.Errorf("times at 100 BPM = %f/%f, want 0.6/0.3", l, r)
+	}
+	d.SetTempo(120)
+	if l, r := d.GetTime(); l != 0.5 || r != 0.25 {
+		t.Errorf("times at 120 BPM = %f/%f, want 0.5/0.25", l, r)
+	}
+
+	d.SetTime(0.1, 0.2)
+	d.SetTempo(60)
+	if l, _ := d.GetTime(); d.IsSynced() || l != 0.1 {
+		t.Errorf("free time followed the tempo: %f", l)
+	}
+}
+
+func TestStereoDelayPingPong(t *testing.T) {
+	sampleRate := 48000.0
+	d := NewStereoDelay(1.0, sampleRate)
+	d.SetMode(ModePingPong)
+	d.SetTime(0.01, 0.01)
+	d.SetFeedback(0.5)
+	d.Reset()
+
+	// The echoes alternate sides, starting on the left
+	left, right := impulseResponse(d, 2000)
+	for k, want := range []struct{ l, r float32 }{{0.5, 0}, {0, 0.25}, {0.125, 0}} {
+		i := 480 * (k + 1)
+		if math.Abs(float64(left[i]-want.l)) > 0.01 || math.Abs(float64(right[i]-want.r)) > 0.01 {
+			t.Errorf("echo %d: %f/%f, want %f/%f", k+1, left[i], right[i], want.l, want.r)
+		}
+	}
+}
+
+func TestStereoDelayCrossfeed(t *testing.T) {
+	d := NewStereoDelay(1.0, 48000)
+	d.SetTime(0.01, 0.01)
+	d.SetFeedback(0.5)
+	d.Reset()
+
+	// Without crossfeed the left echo stays on the left
+	_, right := impulseResponse(d, 1500)
+	for i, x := range right {
+		if x != 0 {
+			t.Fatalf("stereo mode leaked to the right at sample %d", i)
+		}
+	}
+
+	d.Reset()
+	d.SetCrossfeed(0.5)
+	left, right := impulseResponse(d, 1500)
+	if math.Abs(float64(left[960]-0.25)) > 0.01 || math.Abs(float64(right[960]-0.25)) > 0.01 {
+		t.Errorf("second echo with half crossfeed: %f/%f, want 0.25 on both", left[960], right[960])
+	}
+}
+
+func TestStereoDelayFeedbackFilter(t *testing.T) {
+	sampleRate := 48000.0
+	n := int(0.05 * sampleRate)
+
+	// energy returns the energy of each echo of a high tone burst
+	energy := func(lowpass float64) []float64 {
+		d := NewStereoDelay(1.0, sampleRate)
+		d.SetTime(0.05, 0.05)
+		d.SetFeedback(0.9)
+		d.SetFilter(0, lowpass)
+		d.SetMix(1)
+		d.Reset()
+
+		left := make([]float32, 4*n)
+		for i := 0; i < n/2; i++ {
+			left[i] = float32(math.Sin(2 * math.Pi * 4000 * float64(i) / sampleRate))
+		}
+		right := make([]float32, len(left))
+		d.ProcessStereoBuffer(left, right)
+
+		echoes := make([]float64, 4)
+		for i, x := range left {
+			echoes[i/n] += float64(x * x)
+		}
+		return echoes
+	}
+
+	// The lowpass in the loop takes the tone out of every echo
+	open, filtered := energy(0), energy(1000)
+	for k := 1; k < 4; k++ {
+		if filtered[k] > open[k]*0.1 {
+			t.Errorf("echo %d: filtered energy %g, open %g", k, filtered[k], open[k])
+		}
+	}
+}
+
+func TestStereoDelayDry(t *testing.T) {
+	d := NewStereoDelay(1.0, 48000)
+	d.SetMix(0)
+	for i := 0; i < 1000; i++ {
+		in := float32(math.Sin(float64(i) * 0.1))
+		if l, r := d.ProcessStereo(in, -in); l != in || r != -in {
+			t.Fatalf("dry output changed the signal at %d", i)
+		}
+	}
+}
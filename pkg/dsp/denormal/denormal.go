@@ -0,0 +1,96 @@
+// Package denormal keeps subnormal floats out of audio processing. Feedback
+// paths such as filter states, reverb tails and envelope followers decay
+// towards zero and pass through the subnormal range on the way, where most
+// CPUs slow down by one or two orders of magnitude.
+//
+// The VST3 bridge turns on flush-to-zero and denormals-are-zero for every
+// process call, so plugins built on the framework need no setup. Enable and
+// Restore do the same for pure-Go hosts and tests, and Flush and
+// AddDenormalNoise cover code that must not depend on the FPU mode.
+package denormal
+
+import (
+	"math"
+	"runtime"
+)
+
+const (
+	// Threshold is the magnitude below which Flush returns zero, about
+	// -300 dB
+	Threshold = 1e-15
+
+	// NoiseLevel is the offset AddDenormalNoise adds, about -360 dB. It is
+	// a normal number in float32, so states it feeds never go subnormal.
+	NoiseLevel = 1e-18
+)
+
+// State is the floating-point control state saved by Enable
+type State struct {
+	control uintptr
+}
+
+// Supported reports whether Enable can change the FPU mode on this
+// platform. Elsewhere Enable and Restore only pin the goroutine to its
+// thread, and code should fall back on Flush.
+func Supported() bool {
+	return supported
+}
+
+// Enable turns on flush-to-zero and denormals-are-zero for the calling
+// thread and returns the previous mode. The goroutine stays locked to its
+// OS thread until the matching Restore, since the mode belongs to the
+// thread - call both on the same goroutine, typically around one process
+// call.
+func Enable() State {
+	runtime.LockOSThread()
+	control := getControl()
+	setControl(control | flushBits)
+	return State{control: control}
+}
+
+// Restore puts back the mode saved by Enable and unlocks the thread
+func Restore(s State) {
+	setControl(s.control)
+	runtime.UnlockOSThread()
+}
+
+// Enabled reports whether flush-to-zero is on for the calling thread
+func Enabled() bool {
+	return supported && getControl()&flushBits == flushBits
+}
+
+// Flush returns zero for values smaller than Threshold. Apply it to
+// recursive state once per block or per sample.
+func Flush(x float64) float64 {
+	if math.Abs(x) < Threshold {
+		return 0
+	}
+	return x
+}
+
+// Flush32 is the float32 version of Flush
+func Flush32(x float32) float32 {
+	if x < Threshold && x > -Threshold {
+		return 0
+	}
+	return x
+}
+
+// FlushBuffer zeroes every sample smaller than Threshold - no allocations
+func FlushBuffer(buffer []float32) {
+	for i, x := range buffer {
+		if x < Threshold && x > -Threshold {
+			buffer[i] = 0
+		}
+	}
+}
+
+// AddDenormalNoise adds NoiseLevel to every sample, keeping the input of
+// lowpass filters, delay lines and reverbs out of the subnormal range. The
+// offset is far below anything audible or representable next to a normal
+// signal; highpass paths block it, so use Flush on their states instead.
+func AddDenormalNoise(buffer []float32) {
+	for i := range buffer {
+		buffer[i] += NoiseLevel
+	}
+}
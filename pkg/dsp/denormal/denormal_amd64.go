@@ -0,0 +1,15 @@
+//go:build amd64 && !purego
+
+package denormal
+
+const supported = true
+
+// flushBits are the MXCSR flush-to-zero (bit 15) and denormals-are-zero
+// (bit 6) flags
+const flushBits = 1<<15 | 1<<6
+
+// getControl returns MXCSR
+func getControl() uintptr
+
+// setControl loads MXCSR
+func setControl(control uintptr)
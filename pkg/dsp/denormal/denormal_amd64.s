@@ -0,0 +1,17 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// func getControl() uintptr
+TEXT ·getControl(SB), NOSPLIT, $8-8
+	STMXCSR 0(SP)
+	MOVL    0(SP), AX
+	MOVQ    AX, ret+0(FP)
+	RET
+
+// func setControl(control uintptr)
+TEXT ·setControl(SB), NOSPLIT, $8-8
+	MOVQ    control+0(FP), AX
+	MOVL    AX, 0(SP)
+	LDMXCSR 0(SP)
+	RET
@@ -0,0 +1,15 @@
+//go:build arm64 && !purego
+
+package denormal
+
+const supported = true
+
+// flushBits is the FPCR flush-to-zero flag (bit 24), which on AArch64
+// covers both inputs and results
+const flushBits = 1 << 24
+
+// getControl returns FPCR
+func getControl() uintptr
+
+// setControl loads FPCR
+func setControl(control uintptr)
@@ -0,0 +1,15 @@
+//go:build arm64 && !purego
+
+#include "textflag.h"
+
+// func getControl() uintptr
+TEXT ·getControl(SB), NOSPLIT, $0-8
+	MRS  FPCR, R0
+	MOVD R0, ret+0(FP)
+	RET
+
+// func setControl(control uintptr)
+TEXT ·setControl(SB), NOSPLIT, $0-8
+	MOVD control+0(FP), R0
+	MSR  R0, FPCR
+	RET
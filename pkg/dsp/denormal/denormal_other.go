@@ -0,0 +1,15 @@
+//go:build (!amd64 && !arm64) || purego
+
+package denormal
+
+// Without a known FPU control register the mode is left alone
+
+const supported = false
+
+const flushBits = 0
+
+func getControl() uintptr {
+	return 0
+}
+
+func setControl(control uintptr) {}
@@ -0,0 +1,103 @@
+package denormal
+
+import (
+	"math"
+	"testing"
+)
+
+// halve keeps the multiply out of constant folding
+//
+//go:noinline
+func halve(x float32) float32 {
+	return x * 0.5
+}
+
+func TestEnableFlushesSubnormals(t *testing.T) {
+	const tiny = 1e-39 // Subnormal in float32
+	if got := halve(tiny); got == 0 {
+		t.Fatal("subnormal arithmetic already flushes before Enable")
+	}
+
+	state := Enable()
+	if Supported() {
+		if !Enabled() {
+			t.Error("Enabled = false after Enable")
+		}
+		if got := halve(tiny); got != 0 {
+			t.Errorf("tiny/2 = %g with flush-to-zero, want 0", got)
+		}
+	}
+	Restore(state)
+
+	if Enabled() {
+		t.Error("Enabled = true after Restore")
+	}
+	if got := halve(tiny); got == 0 {
+		t.Error("subnormal arithmetic still flushes after Restore")
+	}
+}
+
+func TestEnableNests(t *testing.T) {
+	outer := Enable()
+	inner := Enable()
+	Restore(inner)
+	if Supported() && !Enabled() {
+		t.Error("inner Restore turned flush-to-zero off")
+	}
+	Restore(outer)
+	if Enabled() {
+		t.Error("outer Restore left flush-to-zero on")
+	}
+}
+
+func TestFlush(t *testing.T) {
+	for _, tc := range []struct{ in, want float64 }{
+		{0, 0},
+		{1e-20, 0},
+		{-1e-300, 0},
+		{5e-324, 0},
+		{1e-12, 1e-12},
+		{-0.5, -0.5},
+	} {
+		if got := Flush(tc.in); got != tc.want {
+			t.Errorf("Flush(%g) = %g, want %g", tc.in, got, tc.want)
+		}
+		if got := Flush32(float32(tc.in)); got != float32(tc.want) {
+			t.Errorf("Flush32(%g) = %g, want %g", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestFlushBuffer(t *testing.T) {
+	buf := []float32{1e-40, -1e-20, 0.25, -1e-10, float32(math.Inf(-1))}
+	FlushBuffer(buf)
+	want := []float32{0, 0, 0.25, -1e-10, float32(math.Inf(-1))}
+	for i := range buf {
+		if buf[i] != want[i] {
+			t.Errorf("sample %d = %g, want %g", i, buf[i], want[i])
+		}
+	}
+}
+
+func TestAddDenormalNoise(t *testing.T) {
+	buf := []float32{0, 1e-40, 0.5, -1}
+	AddDenormalNoise(buf)
+	if buf[0] != NoiseLevel || buf[1] < NoiseLevel {
+		t.Errorf("silence became %g and %g, want about %g", buf[0], buf[1], NoiseLevel)
+	}
+	// Audible samples are below float32 resolution of the offset
+	if buf[2] != 0.5 || buf[3] != -1 {
+		t.Errorf("signal changed to %g and %g", buf[2], buf[3])
+	}
+
+	// A one-pole lowpass fed by the offset never decays below it
+	var state float32 = 1
+	for i := 0; i < 100000; i++ {
+		in := []float32{0}
+		AddDenormalNoise(in)
+		state = in[0] + 0.99*state
+		if state != 0 && math.Abs(float64(state)) < 1.1754944e-38 {
+			t.Fatalf("state went subnormal after %d samples", i)
+		}
+	}
+}
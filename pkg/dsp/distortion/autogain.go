@@ -0,0 +1,78 @@
+package distortion
+
+import "math"
+
+// Auto gain calibration settings
+const (
+	// AutoGainReferenceRMS is the calibration level, a sine at -18 dBFS RMS
+	AutoGainReferenceRMS = 0.125892541
+
+	autoGainCalibrationPoints = 256
+	autoGainMaxCompensation   = 15.848932 // +/-24 dB
+	autoGainSmoothing         = 0.002     // Per-sample one-pole coefficient
+)
+
+// MeasureCompensation runs one cycle of a sine at the reference level
+// through shape and returns the linear gain that restores the input RMS.
+// DC added by asymmetric curves is ignored. The result is limited to
+// +/-24 dB.
+func MeasureCompensation(shape func(x float64) float64) float64 {
+	peak := AutoGainReferenceRMS * math.Sqrt2
+	sum, sumSquares := 0.0, 0.0
+	for i := 0; i < autoGainCalibrationPoints; i++ {
+		y := shape(peak * math.Sin(2*math.Pi*float64(i)/autoGainCalibrationPoints))
+		sum += y
+		sumSquares += y * y
+	}
+	mean := sum / autoGainCalibrationPoints
+	rms := math.Sqrt(math.Max(0, sumSquares/autoGainCalibrationPoints-mean*mean))
+	if rms == 0 {
+		return autoGainMaxCompensation
+	}
+	return math.Max(1/autoGainMaxCompensation, math.Min(autoGainMaxCompensation, AutoGainReferenceRMS/rms))
+}
+
+// autoGain level-matches the wet path of a distortion module. Each module
+// supplies its static curve for the current settings; the compensation is
+// recalculated whenever a setting changes and glides to the new value.
+type autoGain struct {
+	enabled bool
+	shape   func(x float64) float64 // Module calibration curve
+	target  float64
+	gain    float64
+}
+
+func newAutoGain(shape func(x float64) float64) autoGain {
+	return autoGain{shape: shape, target: 1, gain: 1}
+}
+
+// setEnabled switches compensation on or off
+func (a *autoGain) setEnabled(enabled bool) {
+	a.enabled = enabled
+	a.update()
+}
+
+// update recalibrates after a setting change
+func (a *autoGain) update() {
+	if !a.enabled {
+		a.target = 1
+		return
+	}
+	a.target = MeasureCompensation(a.shape)
+}
+
+// next returns the smoothed compensation for one sample
+func (a *autoGain) next() float64 {
+	if a.gain != a.target {
+		a.gain += (a.target - a.gain) * autoGainSmoothing
+		if math.Abs(a.target-a.gain) < 1e-6 {
+			a.gain = a.target
+		}
+	}
+	return a.gain
+}
+
+// reset jumps to the target compensation
+func (a *autoGain) reset() {
+	a.gain = a.target
+}
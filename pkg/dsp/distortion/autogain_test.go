@@ -0,0 +1,99 @@
+package distortion
+
+import (
+	"math"
+	"testing"
+)
+
+// outputLevelDB returns the RMS in dB of a settled -18 dBFS 1 kHz sine
+// through process
+func outputLevelDB(process func(float64) float64) float64 {
+	const sr = 48000.0
+	sum, count := 0.0, 0
+	for i := 0; i < 24000; i++ {
+		x := AutoGainReferenceRMS * math.Sqrt2 * math.Sin(2*math.Pi*1000*float64(i)/sr)
+		y := process(x)
+		if i >= 12000 {
+			sum += y * y
+			count++
+		}
+	}
+	return 10 * math.Log10(sum/float64(count))
+}
+
+func TestMeasureCompensation(t *testing.T) {
+	if g := MeasureCompensation(func(x float64) float64 { return x }); math.Abs(g-1) > 1e-9 {
+		t.Errorf("identity: %g", g)
+	}
+	if g := MeasureCompensation(func(x float64) float64 { return 4 * x }); math.Abs(g-0.25) > 1e-9 {
+		t.Errorf("+12 dB: %g", g)
+	}
+	// DC offsets do not count as level
+	if g := MeasureCompensation(func(x float64) float64 { return x + 0.5 }); math.Abs(g-1) > 1e-9 {
+		t.Errorf("offset: %g", g)
+	}
+	if g := MeasureCompensation(func(float64) float64 { return 0 }); g != autoGainMaxCompensation {
+		t.Errorf("silence: %g", g)
+	}
+}
+
+func TestWaveshaperAutoGain(t *testing.T) {
+	for _, curve := range []CurveType{CurveHardClip, CurveSoftClip, CurveSaturate, CurveSine, CurveExponential} {
+		levels := []float64{}
+		for _, drive := range []float64{1, 5, 20, 80} {
+			w := NewWaveshaper()
+			w.SetCurveType(curve)
+			w.SetDrive(drive)
+			w.SetAutoGain(true)
+			levels = append(levels, outputLevelDB(w.Process))
+		}
+		for i, db := range levels {
+			if math.Abs(db-levels[0]) > 1 {
+				t.Errorf("curve %d: level %d is %.2f dB, want %.2f dB", curve, i, db, levels[0])
+			}
+		}
+	}
+
+	// Without compensation drive raises the level
+	w := NewWaveshaper()
+	w.SetDrive(20)
+	if db := outputLevelDB(w.Process); db < -10 {
+		t.Errorf("uncompensated drive 20 level %.2f dB", db)
+	}
+	if w.Compensation() != 1 {
+		t.Errorf("compensation %g while disabled", w.Compensation())
+	}
+}
+
+func TestSaturationAutoGain(t *testing.T) {
+	const ref = -18.0
+	for _, saturation := range []float64{0, 0.5, 1} {
+		tape := NewTapeSaturation(48000)
+		tape.SetFlutter(0)
+		tape.SetSaturation(saturation)
+		tape.SetAutoGain(true)
+		if db := outputLevelDB(tape.Process); math.Abs(db-ref) > 1.5 {
+			t.Errorf("tape saturation %g: %.2f dB", saturation, db)
+		}
+	}
+	for _, harmonics := range []float64{0, 0.5, 1} {
+		tube := NewTubeSaturation()
+		tube.SetHarmonics(harmonics)
+		tube.SetBias(0.5)
+		tube.SetAutoGain(true)
+		if db := outputLevelDB(tube.Process); math.Abs(db-ref) > 1.5 {
+			t.Errorf("tube harmonics %g: %.2f dB", harmonics, db)
+		}
+	}
+}
+
+func TestAutoGainGlides(t *testing.T) {
+	w := NewWaveshaper()
+	w.SetAutoGain(true)
+	w.SetDrive(50)
+	before := w.autoGain.gain
+	w.Process(0)
+	if w.autoGain.gain == w.autoGain.target || w.autoGain.gain == before {
+		t.Errorf("compensation jumped from %g to %g", before, w.autoGain.gain)
+	}
+}
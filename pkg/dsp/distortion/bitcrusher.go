@@ -25,8 +25,8 @@ type Bitcrusher struct {
 	originalSampleRate float64
 
 	// Sample rate reduction state
-	sampleHoldCounter float64
-	lastSample        float64
+	sampleHoldCounter [2]float64 // Stereo
+	lastSample        [2]float64
 
 	// Anti-aliasing filter state (simple one-pole)
 	filterState [2]float64 // Stereo
@@ -84,7 +84,7 @@ func (b *Bitcrusher) processChannel(input float64, channel int) float64 {
 	}
 
 	// Sample rate reduction
-	processed = b.applySampleRateReduction(processed)
+	processed = b.applySampleRateReduction(processed, channel)
 
 	// Bit depth reduction
 	processed = b.applyBitReduction(processed)
@@ -108,6 +108,25 @@ func (b *Bitcrusher) ProcessStereo(inputL, inputR, outputL, outputR []float64) {
 	}
 }
 
+// ProcessBuffer32 crushes a float32 buffer on channel 0 or 1, so plugins
+// can pass their channel buffers directly. input and output may be the same
+// slice - no allocations
+func (b *Bitcrusher) ProcessBuffer32(input, output []float32, ch int) {
+	n := min(len(input), len(output))
+	for i := 0; i < n; i++ {
+		output[i] = float32(b.processChannel(float64(input[i]), ch))
+	}
+}
+
+// ProcessStereo32 crushes a pair of float32 buffers - no allocations
+func (b *Bitcrusher) ProcessStereo32(inputL, inputR, outputL, outputR []float32) {
+	n := min(len(inputL), len(inputR), len(outputL), len(outputR))
+	for i := 0; i < n; i++ {
+		outputL[i] = float32(b.processChannel(float64(inputL[i]), 0))
+		outputR[i] = float32(b.processChannel(float64(inputR[i]), 1))
+	}
+}
+
 func (b *Bitcrusher) applyBitReduction(x float64) float64 {
 	if b.bitDepth >= 32.0 {
 		return x
@@ -129,25 +148,25 @@ func (b *Bitcrusher) applyBitReduction(x float64) float64 {
 	return math.Max(-1.0, math.Min(1.0, quantized))
 }
 
-func (b *Bitcrusher) applySampleRateReduction(x float64) float64 {
+func (b *Bitcrusher) applySampleRateReduction(x float64, channel int) float64 {
 	if b.sampleRateReduce <= 1.0 {
 		return x
 	}
 
 	// Check if we should update the held sample
-	if b.sampleHoldCounter == 0.0 {
-		b.lastSample = x
+	if b.sampleHoldCounter[channel] == 0.0 {
+		b.lastSample[channel] = x
 	}
 
 	// Increment counter
-	b.sampleHoldCounter += 1.0
+	b.sampleHoldCounter[channel] += 1.0
 
 	// Reset counter when we reach the reduction factor
-	if b.sampleHoldCounter >= b.sampleRateReduce {
-		b.sampleHoldCounter = 0.0
+	if b.sampleHoldCounter[channel] >= b.sampleRateReduce {
+		b.sampleHoldCounter[channel] = 0.0
 	}
 
-	return b.lastSample
+	return b.lastSample[channel]
 }
 
 func (b *Bitcrusher) applyAntiAliasFilter(x float64, channel int) float64 {
@@ -183,8 +202,8 @@ func (b *Bitcrusher) generateDither() float64 {
 }
 
 func (b *Bitcrusher) Reset() {
-	b.sampleHoldCounter = 0.0
-	b.lastSample = 0.0
+	b.sampleHoldCounter = [2]float64{}
+	b.lastSample = [2]float64{}
 	b.filterState[0] = 0.0
 	b.filterState[1] = 0.0
 	b.ditherState = 0.0
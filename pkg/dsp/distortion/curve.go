@@ -0,0 +1,84 @@
+package distortion
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidCurve is returned for transfer curves that cannot be used
+var ErrInvalidCurve = errors.New("invalid transfer curve")
+
+// DefaultCurveSize is the table length CurveFromFunc uses for a size of 0
+const DefaultCurveSize = 4096
+
+// TransferCurve is a user-supplied waveshaper curve stored as a lookup
+// table. The table samples the curve at evenly spaced inputs from -1 to 1
+// and is read with cubic Hermite interpolation, so coarse tables from a
+// curve editor stay smooth. Inputs outside [-1, 1] hold the end values.
+type TransferCurve struct {
+	table []float64
+	scale float64 // Table index per unit of input
+}
+
+// NewTransferCurve creates a curve from the outputs at evenly spaced inputs
+// from -1 to 1. It needs at least two finite values; the slice is copied.
+func NewTransferCurve(values []float64) (*TransferCurve, error) {
+	if len(values) < 2 {
+		return nil, fmt.Errorf("%w: need at least 2 points, got %d", ErrInvalidCurve, len(values))
+	}
+	for i, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%w: point %d is %g", ErrInvalidCurve, i, v)
+		}
+	}
+
+	// Pad one point each side so every lookup has four neighbours; the
+	// padding continues the end slopes, so the ends interpolate as well as
+	// the middle
+	n := len(values)
+	table := make([]float64, n+2)
+	copy(table[1:], values)
+	table[0] = 2*values[0] - values[1]
+	table[n+1] = 2*values[n-1] - values[n-2]
+	return &TransferCurve{table: table, scale: float64(n-1) / 2}, nil
+}
+
+// CurveFromFunc samples fn from -1 to 1 into a table of size points,
+// DefaultCurveSize if size is 0
+func CurveFromFunc(fn func(x float64) float64, size int) (*TransferCurve, error) {
+	if size == 0 {
+		size = DefaultCurveSize
+	}
+	if size < 2 {
+		return nil, fmt.Errorf("%w: need at least 2 points, got %d", ErrInvalidCurve, size)
+	}
+	values := make([]float64, size)
+	for i := range values {
+		values[i] = fn(2*float64(i)/float64(size-1) - 1)
+	}
+	return NewTransferCurve(values)
+}
+
+// Len returns the number of points in the table
+func (c *TransferCurve) Len() int {
+	return len(c.table) - 2
+}
+
+// Lookup returns the curve's output for x
+func (c *TransferCurve) Lookup(x float64) float64 {
+	pos := (max(-1, min(1, x)) + 1) * c.scale
+	i := int(pos)
+	if i >= len(c.table)-3 {
+		// x = 1, the last point
+		return c.table[len(c.table)-2]
+	}
+	frac := pos - float64(i)
+
+	y0, y1, y2, y3 := c.table[i], c.table[i+1], c.table[i+2], c.table[i+3]
+	c0 := y1
+	c1 := 0.5 * (y2 - y0)
+	c2 := y0 - 2.5*y1 + 2*y2 - 0.5*y3
+	c3 := 0.5*(y3-y0) + 1.5*(y1-y2)
+	return ((c3*frac+c2)*frac+c1)*frac + c0
+}
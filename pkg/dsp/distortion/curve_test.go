@@ -0,0 +1,149 @@
+package distortion
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTransferCurveLookup(t *testing.T) {
+	curve, err := NewTransferCurve([]float64{-1, -0.8, 0, 0.5, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The table points come back exactly, and inputs past the ends hold
+	for x, want := range map[float64]float64{-2: -1, -1: -1, -0.5: -0.8, 0: 0, 0.5: 0.5, 1: 1, 3: 1} {
+		if got := curve.Lookup(x); math.Abs(got-want) > 1e-12 {
+			t.Errorf("Lookup(%g) = %g, want %g", x, got, want)
+		}
+	}
+
+	// A sampled tanh interpolates to well within a coarse table's spacing
+	tanh, err := CurveFromFunc(math.Tanh, 33)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := -1.0; x <= 1; x += 0.013 {
+		if got := tanh.Lookup(x); math.Abs(got-math.Tanh(x)) > 2e-4 {
+			t.Fatalf("Lookup(%g) = %g, want %g", x, got, math.Tanh(x))
+		}
+	}
+	if n := tanh.Len(); n != 33 {
+		t.Errorf("Len = %d, want 33", n)
+	}
+}
+
+func TestTransferCurveErrors(t *testing.T) {
+	for name, values := range map[string][]float64{
+		"one point": {0},
+		"NaN":       {0, math.NaN(), 1},
+		"infinite":  {math.Inf(-1), 1},
+	} {
+		if _, err := NewTransferCurve(values); !errors.Is(err, ErrInvalidCurve) {
+			t.Errorf("%s: err = %v, want ErrInvalidCurve", name, err)
+		}
+	}
+}
+
+func TestWaveshaperCustomCurve(t *testing.T) {
+	// Half-wave rectifier, the most asymmetric curve there is
+	curve, err := CurveFromFunc(func(x float64) float64 { return max(0, x) }, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws := NewWaveshaper()
+	ws.SetSampleRate(48000)
+	ws.SetCurve(curve)
+
+	mean := func() float64 {
+		sum := 0.0
+		for i := 0; i < 48000; i++ {
+			y := ws.Process(0.8 * math.Sin(2*math.Pi*100*float64(i)/48000))
+			if i >= 24000 {
+				sum += y
+			}
+		}
+		return sum / 24000
+	}
+	if dc := mean(); math.Abs(dc-0.8/math.Pi) > 0.01 {
+		t.Errorf("Rectified DC = %.4f, want %.4f", dc, 0.8/math.Pi)
+	}
+
+	ws.SetDCCompensation(true)
+	if dc := mean(); math.Abs(dc) > 0.002 {
+		t.Errorf("DC with compensation = %.4f, want 0", dc)
+	}
+}
+
+// toneLevel returns the level of a frequency in x with the Goertzel
+// algorithm
+func toneLevel(x []float64, freq, sampleRate float64) float64 {
+	w := 2 * math.Pi * freq / sampleRate
+	c := 2 * math.Cos(w)
+	var s1, s2 float64
+	for _, v := range x {
+		s1, s2 = v+c*s1-s2, s1
+	}
+	return math.Sqrt(s1*s1+s2*s2-c*s1*s2) * 2 / float64(len(x))
+}
+
+func TestWaveshaperOversampling(t *testing.T) {
+	const sampleRate = 48000.0
+	// Harmonics of 5 kHz above Nyquist fold back to 3, 7, 13 and 17 kHz
+	aliases := func(factor int) float64 {
+		ws := NewWaveshaper()
+		ws.SetCurveType(CurveHardClip)
+		ws.SetDrive(10)
+		if err := ws.SetOversampling(factor); err != nil {
+			t.Fatal(err)
+		}
+		out := make([]float64, 9600)
+		for i := range out {
+			out[i] = ws.Process(0.5 * math.Sin(2*math.Pi*5000*float64(i)/sampleRate))
+		}
+		out = out[4800:]
+		sum := 0.0
+		for _, f := range []float64{3000, 7000, 13000, 17000} {
+			sum += toneLevel(out, f, sampleRate)
+		}
+		return sum
+	}
+
+	plain, oversampled := aliases(1), aliases(4)
+	if oversampled > plain/10 {
+		t.Errorf("Aliases %.4f at 4x, want below a tenth of %.4f at 1x", oversampled, plain)
+	}
+	if err := NewWaveshaper().SetOversampling(3); err == nil {
+		t.Error("SetOversampling(3) should fail")
+	}
+}
+
+func TestWaveshaperOversampledDryAligned(t *testing.T) {
+	for _, factor := range []int{2, 4, 8} {
+		ws := NewWaveshaper()
+		ws.SetMix(0)
+		if err := ws.SetOversampling(factor); err != nil {
+			t.Fatal(err)
+		}
+		latency := ws.GetLatencySamples()
+		if latency <= 0 || latency >= dryDelaySize {
+			t.Fatalf("%dx latency = %d", factor, latency)
+		}
+
+		// A dry-only mix is the input delayed by the latency, on each
+		// channel independently
+		in := make([]float32, 200)
+		in[10] = 1
+		out := make([]float32, len(in))
+		ws.ProcessBuffer32(in, out, 1)
+		for i, y := range out {
+			want := float32(0)
+			if i == 10+latency {
+				want = 1
+			}
+			if y != want {
+				t.Fatalf("%dx: out[%d] = %g, want %g", factor, i, y, want)
+			}
+		}
+	}
+}
@@ -35,12 +35,14 @@ type TapeSaturation struct {
 
 	// Noise generator for tape hiss
 	noiseLevel float64
+
+	autoGain autoGain
 }
 
 func NewTapeSaturation(sampleRate float64) *TapeSaturation {
 	bufferSize := int(sampleRate * 0.01) // 10ms max delay for flutter
 
-	return &TapeSaturation{
+	t := &TapeSaturation{
 		saturation:      0.5,
 		compression:     0.5,
 		flutter:         0.0,
@@ -53,10 +55,43 @@ func NewTapeSaturation(sampleRate float64) *TapeSaturation {
 		flutterRate:     0.3 + rand.Float64()*0.2, // 0.3-0.5 Hz
 		noiseLevel:      0.0001,
 	}
+	t.autoGain = newAutoGain(t.calibrationCurve)
+	return t
+}
+
+// SetSampleRate changes the sample rate, keeping the flutter phase so the
+// wow does not restart. The flutter delay is resized and cleared.
+func (t *TapeSaturation) SetSampleRate(sampleRate float64) {
+	if sampleRate <= 0 || sampleRate == t.sampleRate {
+		return
+	}
+	t.sampleRate = sampleRate
+	t.delayBufferSize = int(sampleRate * 0.01)
+	t.delayBuffer = make([]float64, t.delayBufferSize)
+	t.delayWritePos = 0
+}
+
+// SetAutoGain enables output compensation that keeps the level constant as
+// the saturation changes
+func (t *TapeSaturation) SetAutoGain(enabled bool) {
+	t.autoGain.setEnabled(enabled)
+	t.autoGain.reset()
+}
+
+// Compensation returns the auto gain target as a linear gain
+func (t *TapeSaturation) Compensation() float64 {
+	return t.autoGain.target
+}
+
+// calibrationCurve is the saturation stage; the reference level stays below
+// the compression threshold
+func (t *TapeSaturation) calibrationCurve(x float64) float64 {
+	return t.tapeSaturate(x)
 }
 
 func (t *TapeSaturation) SetSaturation(saturation float64) {
 	t.saturation = math.Max(0.0, math.Min(1.0, saturation))
+	t.autoGain.update()
 }
 
 func (t *TapeSaturation) SetCompression(compression float64) {
@@ -100,7 +135,7 @@ func (t *TapeSaturation) processChannel(input float64, channel int) float64 {
 	withNoise := fluttered + (rand.Float64()*2.0-1.0)*t.noiseLevel*t.saturation
 
 	// De-emphasis (cut highs after saturation)
-	deEmphasized := t.deEmphasis(withNoise, channel)
+	deEmphasized := t.deEmphasis(withNoise, channel) * t.autoGain.next()
 
 	// Mix with dry signal
 	mixed := deEmphasized*t.mix + input*(1.0-t.mix)
@@ -121,6 +156,25 @@ func (t *TapeSaturation) ProcessStereo(inputL, inputR, outputL, outputR []float6
 	}
 }
 
+// ProcessBuffer32 saturates a float32 buffer on channel 0 or 1, so plugins
+// can pass their channel buffers directly. input and output may be the same
+// slice - no allocations
+func (t *TapeSaturation) ProcessBuffer32(input, output []float32, ch int) {
+	n := min(len(input), len(output))
+	for i := 0; i < n; i++ {
+		output[i] = float32(t.processChannel(float64(input[i]), ch))
+	}
+}
+
+// ProcessStereo32 saturates a pair of float32 buffers - no allocations
+func (t *TapeSaturation) ProcessStereo32(inputL, inputR, outputL, outputR []float32) {
+	n := min(len(inputL), len(inputR), len(outputL), len(outputR))
+	for i := 0; i < n; i++ {
+		outputL[i] = float32(t.processChannel(float64(inputL[i]), 0))
+		outputR[i] = float32(t.processChannel(float64(inputR[i]), 1))
+	}
+}
+
 func (t *TapeSaturation) tapeSaturate(x float64) float64 {
 	// Tape saturation characteristics
 	// Soft saturation with 3rd harmonic emphasis
@@ -237,13 +291,20 @@ func (t *TapeSaturation) deEmphasis(x float64, channel int) float64 {
 }
 
 func (t *TapeSaturation) Reset() {
+	t.Clear()
+	t.flutterPhase = 0.0
+}
+
+// Clear silences the filters and flutter delay but keeps the flutter phase,
+// so the wow carries on where it was when processing resumes
+func (t *TapeSaturation) Clear() {
 	t.preEmphasisState[0] = 0.0
 	t.preEmphasisState[1] = 0.0
 	t.deEmphasisState[0] = 0.0
 	t.deEmphasisState[1] = 0.0
 	t.envelope = 0.0
-	t.flutterPhase = 0.0
 	t.delayWritePos = 0
+	t.autoGain.reset()
 
 	// Clear delay buffer
 	for i := range t.delayBuffer {
@@ -222,3 +222,25 @@ func BenchmarkTapeSaturation(b *testing.B) {
 		tape.ProcessBlock(input, output)
 	}
 }
+
+func TestTapeSetSampleRateKeepsFlutter(t *testing.T) {
+	tape := NewTapeSaturation(44100)
+	tape.SetFlutter(0.5)
+	for i := 0; i < 20000; i++ {
+		tape.Process(0.3)
+	}
+	phase := tape.flutterPhase
+
+	tape.SetSampleRate(96000)
+	if tape.flutterPhase != phase {
+		t.Errorf("Flutter phase %g after SetSampleRate, want %g", tape.flutterPhase, phase)
+	}
+	if len(tape.delayBuffer) != 960 {
+		t.Errorf("Flutter delay %d samples at 96 kHz, want 960", len(tape.delayBuffer))
+	}
+	for i := 0; i < 1000; i++ {
+		if y := tape.Process(0.3); math.IsNaN(y) || math.Abs(y) > 2 {
+			t.Fatalf("Sample %d = %g after the rate change", i, y)
+		}
+	}
+}
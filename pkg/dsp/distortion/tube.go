@@ -13,16 +13,18 @@ type TubeSaturation struct {
 	output     float64
 
 	// Internal state for hysteresis
-	prevInput  float64
-	prevOutput float64
+	prevInput  [2]float64 // Stereo state
+	prevOutput [2]float64
 
 	// Pre-emphasis/de-emphasis filters for warmth
-	preEmphasisState float64
-	deEmphasisState  float64
+	preEmphasisState [2]float64
+	deEmphasisState  [2]float64
+
+	autoGain autoGain
 }
 
 func NewTubeSaturation() *TubeSaturation {
-	return &TubeSaturation{
+	t := &TubeSaturation{
 		warmth:     0.5,
 		harmonics:  0.5,
 		bias:       0.0,
@@ -30,6 +32,26 @@ func NewTubeSaturation() *TubeSaturation {
 		mix:        1.0,
 		output:     1.0,
 	}
+	t.autoGain = newAutoGain(t.calibrationCurve)
+	return t
+}
+
+// SetAutoGain enables output compensation that keeps the level constant as
+// harmonics and bias change
+func (t *TubeSaturation) SetAutoGain(enabled bool) {
+	t.autoGain.setEnabled(enabled)
+	t.autoGain.reset()
+}
+
+// Compensation returns the auto gain target as a linear gain
+func (t *TubeSaturation) Compensation() float64 {
+	return t.autoGain.target
+}
+
+// calibrationCurve is the static part of the wet path; the emphasis filters
+// and hysteresis are close to unity gain in the midrange
+func (t *TubeSaturation) calibrationCurve(x float64) float64 {
+	return t.tubeSaturate(x + t.bias*0.1)
 }
 
 func (t *TubeSaturation) SetWarmth(warmth float64) {
@@ -38,10 +60,12 @@ func (t *TubeSaturation) SetWarmth(warmth float64) {
 
 func (t *TubeSaturation) SetHarmonics(harmonics float64) {
 	t.harmonics = math.Max(0.0, math.Min(1.0, harmonics))
+	t.autoGain.update()
 }
 
 func (t *TubeSaturation) SetBias(bias float64) {
 	t.bias = math.Max(-1.0, math.Min(1.0, bias))
+	t.autoGain.update()
 }
 
 func (t *TubeSaturation) SetHysteresis(hysteresis float64) {
@@ -57,20 +81,24 @@ func (t *TubeSaturation) SetOutput(output float64) {
 }
 
 func (t *TubeSaturation) Process(input float64) float64 {
+	return t.processChannel(input, 0)
+}
+
+func (t *TubeSaturation) processChannel(input float64, channel int) float64 {
 	// Pre-emphasis for warmth (boost highs before saturation)
-	emphasized := t.preEmphasis(input)
+	emphasized := t.preEmphasis(input, channel)
 
 	// Apply tube bias
 	biased := emphasized + t.bias*0.1
 
 	// Apply hysteresis (magnetic-like behavior)
-	withHysteresis := t.applyHysteresis(biased)
+	withHysteresis := t.applyHysteresis(biased, channel)
 
 	// Tube saturation with harmonic generation
 	saturated := t.tubeSaturate(withHysteresis)
 
 	// De-emphasis (reduce highs after saturation for warmth)
-	deEmphasized := t.deEmphasis(saturated)
+	deEmphasized := t.deEmphasis(saturated, channel) * t.autoGain.next()
 
 	// Mix with dry signal
 	mixed := deEmphasized*t.mix + input*(1.0-t.mix)
@@ -86,8 +114,27 @@ func (t *TubeSaturation) ProcessBlock(input, output []float64) {
 
 func (t *TubeSaturation) ProcessStereo(inputL, inputR, outputL, outputR []float64) {
 	for i := range inputL {
-		outputL[i] = t.Process(inputL[i])
-		outputR[i] = t.Process(inputR[i])
+		outputL[i] = t.processChannel(inputL[i], 0)
+		outputR[i] = t.processChannel(inputR[i], 1)
+	}
+}
+
+// ProcessBuffer32 saturates a float32 buffer on channel 0 or 1, so plugins
+// can pass their channel buffers directly. input and output may be the same
+// slice - no allocations
+func (t *TubeSaturation) ProcessBuffer32(input, output []float32, ch int) {
+	n := min(len(input), len(output))
+	for i := 0; i < n; i++ {
+		output[i] = float32(t.processChannel(float64(input[i]), ch))
+	}
+}
+
+// ProcessStereo32 saturates a pair of float32 buffers - no allocations
+func (t *TubeSaturation) ProcessStereo32(inputL, inputR, outputL, outputR []float32) {
+	n := min(len(inputL), len(inputR), len(outputL), len(outputR))
+	for i := 0; i < n; i++ {
+		outputL[i] = float32(t.processChannel(float64(inputL[i]), 0))
+		outputR[i] = float32(t.processChannel(float64(inputR[i]), 1))
 	}
 }
 
@@ -148,47 +195,49 @@ func (t *TubeSaturation) tubeCompressionCurve(x float64) float64 {
 	return math.Copysign(compressed, x)
 }
 
-func (t *TubeSaturation) applyHysteresis(x float64) float64 {
+func (t *TubeSaturation) applyHysteresis(x float64, channel int) float64 {
 	// Simple hysteresis model
-	diff := x - t.prevInput
+	diff := x - t.prevInput[channel]
 
 	// Hysteresis effect based on input change direction
+	prev := t.prevOutput[channel]
 	if diff > 0 {
 		// Rising input
-		t.prevOutput = t.prevOutput + (x-t.prevOutput)*(1.0-t.hysteresis*0.3)
+		t.prevOutput[channel] = prev + (x-prev)*(1.0-t.hysteresis*0.3)
 	} else {
 		// Falling input
-		t.prevOutput = t.prevOutput + (x-t.prevOutput)*(1.0-t.hysteresis*0.5)
+		t.prevOutput[channel] = prev + (x-prev)*(1.0-t.hysteresis*0.5)
 	}
 
-	t.prevInput = x
-	return t.prevOutput
+	t.prevInput[channel] = x
+	return t.prevOutput[channel]
 }
 
-func (t *TubeSaturation) preEmphasis(x float64) float64 {
+func (t *TubeSaturation) preEmphasis(x float64, channel int) float64 {
 	// Simple high-frequency boost before saturation
 	// First-order high-pass filter
 	cutoff := 0.1 + t.warmth*0.4 // Higher warmth = higher cutoff = more pre-emphasis
 
-	output := x - t.preEmphasisState
-	t.preEmphasisState += output * cutoff
+	output := x - t.preEmphasisState[channel]
+	t.preEmphasisState[channel] += output * cutoff
 
 	// Mix between filtered and original based on warmth
 	return x + output*t.warmth*0.5
 }
 
-func (t *TubeSaturation) deEmphasis(x float64) float64 {
+func (t *TubeSaturation) deEmphasis(x float64, channel int) float64 {
 	// Simple high-frequency cut after saturation
 	// First-order low-pass filter
 	cutoff := 0.9 - t.warmth*0.6 // Higher warmth = lower cutoff = more de-emphasis
 
-	t.deEmphasisState += (x - t.deEmphasisState) * cutoff
-	return t.deEmphasisState
+	t.deEmphasisState[channel] += (x - t.deEmphasisState[channel]) * cutoff
+	return t.deEmphasisState[channel]
 }
 
 func (t *TubeSaturation) Reset() {
-	t.prevInput = 0.0
-	t.prevOutput = 0.0
-	t.preEmphasisState = 0.0
-	t.deEmphasisState = 0.0
+	t.prevInput = [2]float64{}
+	t.prevOutput = [2]float64{}
+	t.preEmphasisState = [2]float64{}
+	t.deEmphasisState = [2]float64{}
+	t.autoGain.reset()
 }
@@ -2,6 +2,8 @@ package distortion
 
 import (
 	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
 )
 
 type CurveType int
@@ -14,32 +16,146 @@ const (
 	CurveAsymmetric
 	CurveSine
 	CurveExponential
+	CurveCustom // A TransferCurve set with SetCurve
+)
+
+// Waveshaper limits
+const (
+	MaxWaveshaperChannels = 2   // Channels with their own DC and oversampling state
+	dcCutoff              = 5.0 // DC blocker cutoff in Hz
+	dryDelaySize          = 64  // Longest dry path delay, above any oversampler latency
+	defaultSampleRate     = 44100.0
 )
 
+// dcBlocker is a first-order highpass that removes the offset asymmetric
+// curves add
+type dcBlocker struct {
+	x1, y1 float64
+}
+
 type Waveshaper struct {
 	curveType CurveType
 	drive     float64
 	mix       float64
 	output    float64
 	asymmetry float64 // For asymmetric curve
+	autoGain  autoGain
+	curve     *TransferCurve // For CurveCustom
+
+	// DC compensation
+	sampleRate float64
+	dcBlock    bool
+	dcCoeff    float64
+	dc         [MaxWaveshaperChannels]dcBlocker
+
+	// Oversampling, with the dry path delayed to match
+	oversampling int
+	oversamplers [MaxWaveshaperChannels]*filter.Oversampler
+	dry          [MaxWaveshaperChannels][dryDelaySize]float64
+	dryPos       [MaxWaveshaperChannels]int
+	latency      int
+	shape        func(x float64) float64 // calibrationCurve, bound once
 }
 
 func NewWaveshaper() *Waveshaper {
-	return &Waveshaper{
-		curveType: CurveSoftClip,
-		drive:     1.0,
-		mix:       1.0,
-		output:    1.0,
-		asymmetry: 0.0,
+	w := &Waveshaper{
+		curveType:    CurveSoftClip,
+		drive:        1.0,
+		mix:          1.0,
+		output:       1.0,
+		asymmetry:    0.0,
+		oversampling: 1,
 	}
+	w.shape = w.calibrationCurve
+	w.autoGain = newAutoGain(w.shape)
+	w.SetSampleRate(defaultSampleRate)
+	return w
+}
+
+// SetAutoGain enables output compensation that keeps the level constant as
+// drive, curve and asymmetry change
+func (w *Waveshaper) SetAutoGain(enabled bool) {
+	w.autoGain.setEnabled(enabled)
+	w.autoGain.reset()
+}
+
+// Compensation returns the auto gain target as a linear gain
+func (w *Waveshaper) Compensation() float64 {
+	return w.autoGain.target
+}
+
+// calibrationCurve is the wet path without dry mix or output gain
+func (w *Waveshaper) calibrationCurve(x float64) float64 {
+	return w.applyCurve(x * w.drive)
 }
 
 func (w *Waveshaper) SetCurveType(curve CurveType) {
 	w.curveType = curve
+	w.autoGain.update()
+}
+
+// SetCurve shapes with a user-supplied transfer curve and selects
+// CurveCustom. With a nil curve CurveCustom passes the signal unchanged.
+func (w *Waveshaper) SetCurve(curve *TransferCurve) {
+	w.curve = curve
+	w.curveType = CurveCustom
+	w.autoGain.update()
+}
+
+// SetSampleRate sets the sample rate the DC blocker is tuned for; the
+// default is 44.1 kHz
+func (w *Waveshaper) SetSampleRate(sampleRate float64) {
+	w.sampleRate = sampleRate
+	w.dcCoeff = math.Exp(-2 * math.Pi * dcCutoff / sampleRate)
+}
+
+// SetDCCompensation enables a 5 Hz highpass after the curve. Asymmetric
+// and custom curves shift the waveform's centre by an amount that depends
+// on the level; the highpass follows it and removes it.
+func (w *Waveshaper) SetDCCompensation(enabled bool) {
+	w.dcBlock = enabled
+	w.dc = [MaxWaveshaperChannels]dcBlocker{}
+}
+
+// SetOversampling runs the curve at 1, 2, 4 or 8 times the sample rate,
+// which keeps the harmonics it adds from aliasing. Oversampling delays the
+// output, see GetLatencySamples; the dry signal is delayed to match.
+func (w *Waveshaper) SetOversampling(factor int) error {
+	if factor == w.oversampling {
+		return nil
+	}
+	for ch := range w.oversamplers {
+		o, err := filter.NewOversampler(factor)
+		if err != nil {
+			return err
+		}
+		w.oversamplers[ch] = o
+	}
+	if factor == 1 {
+		w.oversamplers = [MaxWaveshaperChannels]*filter.Oversampler{}
+	}
+	w.oversampling = factor
+	w.latency = 0
+	if w.oversamplers[0] != nil {
+		w.latency = w.oversamplers[0].Latency()
+	}
+	w.dry = [MaxWaveshaperChannels][dryDelaySize]float64{}
+	return nil
+}
+
+// GetOversampling returns the oversampling factor
+func (w *Waveshaper) GetOversampling() int {
+	return w.oversampling
+}
+
+// GetLatencySamples returns the delay oversampling adds
+func (w *Waveshaper) GetLatencySamples() int {
+	return w.latency
 }
 
 func (w *Waveshaper) SetDrive(drive float64) {
 	w.drive = math.Max(1.0, math.Min(100.0, drive))
+	w.autoGain.update()
 }
 
 func (w *Waveshaper) SetMix(mix float64) {
@@ -52,12 +168,37 @@ func (w *Waveshaper) SetOutput(output float64) {
 
 func (w *Waveshaper) SetAsymmetry(asymmetry float64) {
 	w.asymmetry = math.Max(-1.0, math.Min(1.0, asymmetry))
+	w.autoGain.update()
 }
 
+// Process shapes one sample on channel 0
 func (w *Waveshaper) Process(input float64) float64 {
-	driven := input * w.drive
-	shaped := w.applyCurve(driven)
-	return (shaped*w.mix + input*(1.0-w.mix)) * w.output
+	return w.ProcessChannel(input, 0)
+}
+
+// ProcessChannel shapes one sample with the DC blocker and oversampler of
+// a channel, below MaxWaveshaperChannels
+func (w *Waveshaper) ProcessChannel(input float64, ch int) float64 {
+	dry := input
+	var shaped float64
+	if o := w.oversamplers[ch]; o != nil {
+		shaped = o.Process(input, w.shape)
+		pos := (w.dryPos[ch] + 1) % dryDelaySize
+		w.dryPos[ch] = pos
+		w.dry[ch][pos] = input
+		dry = w.dry[ch][(pos-w.latency+dryDelaySize)%dryDelaySize]
+	} else {
+		shaped = w.shape(input)
+	}
+	shaped *= w.autoGain.next()
+
+	if w.dcBlock {
+		dc := &w.dc[ch]
+		y := shaped - dc.x1 + w.dcCoeff*dc.y1
+		dc.x1, dc.y1 = shaped, y
+		shaped = y
+	}
+	return (shaped*w.mix + dry*(1.0-w.mix)) * w.output
 }
 
 func (w *Waveshaper) ProcessBlock(input, output []float64) {
@@ -68,11 +209,42 @@ func (w *Waveshaper) ProcessBlock(input, output []float64) {
 
 func (w *Waveshaper) ProcessStereo(inputL, inputR, outputL, outputR []float64) {
 	for i := range inputL {
-		outputL[i] = w.Process(inputL[i])
-		outputR[i] = w.Process(inputR[i])
+		outputL[i] = w.ProcessChannel(inputL[i], 0)
+		outputR[i] = w.ProcessChannel(inputR[i], 1)
 	}
 }
 
+// ProcessBuffer32 shapes a float32 buffer on a channel, so plugins can pass
+// their channel buffers directly. input and output may be the same slice -
+// no allocations
+func (w *Waveshaper) ProcessBuffer32(input, output []float32, ch int) {
+	n := min(len(input), len(output))
+	for i := 0; i < n; i++ {
+		output[i] = float32(w.ProcessChannel(float64(input[i]), ch))
+	}
+}
+
+// ProcessStereo32 shapes a pair of float32 buffers - no allocations
+func (w *Waveshaper) ProcessStereo32(inputL, inputR, outputL, outputR []float32) {
+	n := min(len(inputL), len(inputR), len(outputL), len(outputR))
+	for i := 0; i < n; i++ {
+		outputL[i] = float32(w.ProcessChannel(float64(inputL[i]), 0))
+		outputR[i] = float32(w.ProcessChannel(float64(inputR[i]), 1))
+	}
+}
+
+// Reset clears the DC blockers and oversampling filters
+func (w *Waveshaper) Reset() {
+	w.dc = [MaxWaveshaperChannels]dcBlocker{}
+	for _, o := range w.oversamplers {
+		if o != nil {
+			o.Reset()
+		}
+	}
+	w.dry = [MaxWaveshaperChannels][dryDelaySize]float64{}
+	w.autoGain.reset()
+}
+
 func (w *Waveshaper) applyCurve(x float64) float64 {
 	switch w.curveType {
 	case CurveHardClip:
@@ -89,6 +261,11 @@ func (w *Waveshaper) applyCurve(x float64) float64 {
 		return w.sine(x)
 	case CurveExponential:
 		return w.exponential(x)
+	case CurveCustom:
+		if w.curve != nil {
+			return w.curve.Lookup(x)
+		}
+		return x
 	default:
 		return x
 	}
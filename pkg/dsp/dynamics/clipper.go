@@ -0,0 +1,239 @@
+package dynamics
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+// ClipCurve selects the transfer curve a Clipper uses above its knee
+type ClipCurve int
+
+const (
+	ClipHard ClipCurve = iota // Straight to the ceiling, rounded by the knee
+	ClipSoft                  // tanh saturation approaching the ceiling
+	ClipSine                  // Quarter sine that reaches the ceiling exactly
+)
+
+// DefaultClipperKnee is the default knee width in dB below the ceiling
+const DefaultClipperKnee = 0.0
+
+// Clipper is a sample-accurate peak clipper for "clip-to-zero" mastering
+// chains. It has no attack or release: everything below the knee passes
+// untouched and everything above is shaped by the curve so it never exceeds
+// the ceiling. Oversampling reduces the aliasing of the clipped overtones.
+// Band-limiting a clipped signal brings back overshoot between samples, so
+// true-peak mode follows the clipper with a short look-ahead guard that
+// holds intersample peaks at the ceiling (dBTP).
+type Clipper struct {
+	sampleRate float64
+
+	// Parameters
+	ceiling    float64 // Ceiling in dBFS or dBTP
+	knee       float64 // Knee width in dB below the ceiling
+	curve      ClipCurve
+	oversample bool
+	truePeak   bool
+
+	// Linear curve thresholds, derived from ceiling and knee
+	ceilingLin float64 // Output never exceeds this
+	kneeLin    float64 // Samples below this pass unchanged
+
+	// Per-channel 4x oversamplers, the clip curve bound once so processing
+	// does not allocate
+	oversamplers [2]*filter.Oversampler
+	clipFn       func(float64) float64
+
+	// Per-channel intersample peak guards for true-peak mode
+	guards [2]*truePeakGuard
+
+	// State
+	gainReduction float64 // Largest reduction since the last read, in dB
+}
+
+// NewClipper creates a hard clipper with a 0 dBFS ceiling
+func NewClipper(sampleRate float64) *Clipper {
+	c := &Clipper{
+		sampleRate: sampleRate,
+		knee:       DefaultClipperKnee,
+		curve:      ClipHard,
+	}
+	c.oversamplers[0], _ = filter.NewOversampler(4)
+	c.oversamplers[1], _ = filter.NewOversampler(4)
+	c.guards[0] = newTruePeakGuard(sampleRate)
+	c.guards[1] = newTruePeakGuard(sampleRate)
+	c.clipFn = c.clip
+	c.updateThresholds()
+	return c
+}
+
+// SetCeiling sets the output ceiling in dB. It is read as dBTP in true-peak
+// mode and as dBFS otherwise.
+func (c *Clipper) SetCeiling(dB float64) {
+	c.ceiling = math.Max(-60.0, math.Min(0.0, dB))
+	c.updateThresholds()
+}
+
+// GetCeiling returns the output ceiling in dB
+func (c *Clipper) GetCeiling() float64 {
+	return c.ceiling
+}
+
+// SetKnee sets how far below the ceiling, in dB, the curve starts bending.
+// A hard clipper with a zero knee is a plain clamp.
+func (c *Clipper) SetKnee(dB float64) {
+	c.knee = math.Max(0.0, math.Min(24.0, dB))
+	c.updateThresholds()
+}
+
+// GetKnee returns the knee width in dB
+func (c *Clipper) GetKnee() float64 {
+	return c.knee
+}
+
+// SetCurve sets the clip curve
+func (c *Clipper) SetCurve(curve ClipCurve) {
+	c.curve = curve
+}
+
+// GetCurve returns the clip curve
+func (c *Clipper) GetCurve() ClipCurve {
+	return c.curve
+}
+
+// SetOversampling enables 4x oversampled clipping. This adds latency, see
+// GetLatencySamples.
+func (c *Clipper) SetOversampling(enabled bool) {
+	if enabled != c.oversample {
+		c.oversample = enabled
+		c.resetOversamplers()
+	}
+}
+
+// SetTruePeak enables dBTP-aware clipping: the curve runs oversampled
+// regardless of SetOversampling and the intersample peak guard follows it
+func (c *Clipper) SetTruePeak(enabled bool) {
+	if enabled != c.truePeak {
+		c.truePeak = enabled
+		c.resetOversamplers()
+	}
+}
+
+// IsOversampled reports whether the clip curve runs at 4x
+func (c *Clipper) IsOversampled() bool {
+	return c.oversample || c.truePeak
+}
+
+// GetLatencySamples returns the delay oversampling and the true-peak guard
+// add, in samples
+func (c *Clipper) GetLatencySamples() int {
+	latency := 0
+	if c.IsOversampled() {
+		latency += c.oversamplers[0].Latency()
+	}
+	if c.truePeak {
+		latency += truePeakGuardDelay
+	}
+	return latency
+}
+
+// GetGainReduction returns the largest gain reduction in dB since the
+// previous call, so meters polled once per block still see single-sample
+// clips
+func (c *Clipper) GetGainReduction() float64 {
+	gr := c.gainReduction
+	c.gainReduction = 0
+	return gr
+}
+
+// updateThresholds recomputes the linear thresholds
+func (c *Clipper) updateThresholds() {
+	c.ceilingLin = math.Pow(10.0, c.ceiling/20.0)
+	c.kneeLin = c.ceilingLin * math.Pow(10.0, -c.knee/20.0)
+}
+
+// clip applies the curve to one sample
+func (c *Clipper) clip(x float64) float64 {
+	a := math.Abs(x)
+	if a <= c.kneeLin {
+		return x
+	}
+
+	// Above the knee the curve leaves with unity slope and bends into the
+	// ceiling over the remaining headroom
+	t, d := c.kneeLin, c.ceilingLin-c.kneeLin
+	var y float64
+	switch {
+	case d <= 0:
+		y = c.ceilingLin
+	case c.curve == ClipSoft:
+		y = t + d*math.Tanh((a-t)/d)
+	case c.curve == ClipSine:
+		u := (a - t) / d
+		if u >= math.Pi/2 {
+			y = c.ceilingLin
+		} else {
+			y = t + d*math.Sin(u)
+		}
+	default:
+		// Quadratic knee that meets the ceiling with zero slope
+		if a >= t+2*d {
+			y = c.ceilingLin
+		} else {
+			y = a - (a-t)*(a-t)/(4*d)
+		}
+	}
+
+	if gr := 20.0 * math.Log10(a/y); gr > c.gainReduction {
+		c.gainReduction = gr
+	}
+	return math.Copysign(y, x)
+}
+
+// processChannel clips one sample of a channel
+func (c *Clipper) processChannel(ch int, input float32) float32 {
+	if !c.IsOversampled() {
+		return float32(c.clip(float64(input)))
+	}
+
+	y := c.oversamplers[ch].Process(float64(input), c.clipFn)
+	if c.truePeak {
+		y = c.guards[ch].process(y, c.ceilingLin)
+	}
+	return float32(y)
+}
+
+// Process processes a single sample
+func (c *Clipper) Process(input float32) float32 {
+	return c.processChannel(0, input)
+}
+
+// ProcessBuffer processes a buffer of samples
+func (c *Clipper) ProcessBuffer(input, output []float32) {
+	for i := range input {
+		output[i] = c.processChannel(0, input[i])
+	}
+}
+
+// ProcessStereo clips both channels independently. Clipping has no
+// envelope, so unlike the compressors there is nothing to link.
+func (c *Clipper) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
+	for i := range inputL {
+		outputL[i] = c.processChannel(0, inputL[i])
+		outputR[i] = c.processChannel(1, inputR[i])
+	}
+}
+
+// resetOversamplers clears the oversampling filters and peak guards
+func (c *Clipper) resetOversamplers() {
+	for ch := range c.oversamplers {
+		c.oversamplers[ch].Reset()
+		c.guards[ch].reset()
+	}
+}
+
+// Reset resets the clipper state
+func (c *Clipper) Reset() {
+	c.resetOversamplers()
+	c.gainReduction = 0
+}
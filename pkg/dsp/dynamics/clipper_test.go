@@ -0,0 +1,172 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis"
+)
+
+func TestClipperCurves(t *testing.T) {
+	for _, curve := range []ClipCurve{ClipHard, ClipSoft, ClipSine} {
+		c := NewClipper(48000)
+		c.SetCurve(curve)
+		c.SetCeiling(-6)
+		c.SetKnee(6)
+		ceiling := math.Pow(10, -6.0/20)
+		knee := math.Pow(10, -12.0/20)
+
+		// Below the knee the signal is untouched
+		if got := c.Process(float32(knee * 0.9)); math.Abs(float64(got)-knee*0.9) > 1e-6 {
+			t.Errorf("curve %d: %f below the knee changed to %f", curve, knee*0.9, got)
+		}
+
+		// The curve is monotonic, odd-symmetric and never passes the ceiling
+		prev := 0.0
+		for x := 0.0; x < 8; x += 0.01 {
+			y := float64(c.Process(float32(x)))
+			if y > ceiling+1e-6 {
+				t.Fatalf("curve %d: %f clipped to %f, above the %f ceiling", curve, x, y, ceiling)
+			}
+			if y < prev-1e-6 {
+				t.Fatalf("curve %d: not monotonic at %f", curve, x)
+			}
+			if neg := float64(c.Process(float32(-x))); math.Abs(neg+y) > 1e-6 {
+				t.Fatalf("curve %d: %f and %f are not symmetric", curve, y, neg)
+			}
+			prev = y
+		}
+		if curve != ClipSoft && math.Abs(prev-ceiling) > 1e-6 {
+			t.Errorf("curve %d: loud input reached %f, want the %f ceiling", curve, prev, ceiling)
+		}
+	}
+}
+
+func TestClipperHardClamp(t *testing.T) {
+	c := NewClipper(48000)
+	c.SetCeiling(-3)
+
+	ceiling := float32(math.Pow(10, -3.0/20))
+	if got := c.Process(2); math.Abs(float64(got-ceiling)) > 1e-6 {
+		t.Errorf("Process(2) = %f, want %f", got, ceiling)
+	}
+	if got := c.Process(0.5); got != 0.5 {
+		t.Errorf("Process(0.5) = %f, want it unchanged", got)
+	}
+	if gr := c.GetGainReduction(); math.Abs(gr-(6.0206+3)) > 0.01 {
+		t.Errorf("Gain reduction = %f dB, want about 9.02", gr)
+	}
+	if gr := c.GetGainReduction(); gr != 0 {
+		t.Errorf("Gain reduction after read = %f, want 0", gr)
+	}
+}
+
+func TestClipperOversamplingLatency(t *testing.T) {
+	c := NewClipper(48000)
+	if c.GetLatencySamples() != 0 {
+		t.Errorf("Latency without oversampling = %d, want 0", c.GetLatencySamples())
+	}
+	c.SetOversampling(true)
+	latency := c.GetLatencySamples()
+	if latency <= 0 {
+		t.Fatalf("Oversampled latency = %d, want > 0", latency)
+	}
+
+	// A quiet impulse passes unclipped and comes out exactly latency late
+	out := make([]float32, 128)
+	for i := range out {
+		in := float32(0)
+		if i == 0 {
+			in = 0.5
+		}
+		out[i] = c.Process(in)
+	}
+	peak := 0
+	for i := range out {
+		if math.Abs(float64(out[i])) > math.Abs(float64(out[peak])) {
+			peak = i
+		}
+	}
+	if peak != latency {
+		t.Errorf("Impulse peak at %d, want reported latency %d", peak, latency)
+	}
+	if math.Abs(float64(out[peak])-0.5) > 0.05 {
+		t.Errorf("Impulse peak = %f, want about 0.5", out[peak])
+	}
+}
+
+func TestClipperTruePeak(t *testing.T) {
+	const sampleRate = 48000.0
+
+	// Clipped sines, driven hard enough that band-limiting the clipped
+	// waveform overshoots between samples. The fade-in keeps the meter's
+	// own step response out of the reading.
+	for _, freq := range []float64{997, 7001, 11025.5} {
+		c := NewClipper(sampleRate)
+		c.SetCeiling(-1)
+		c.SetTruePeak(true)
+		if !c.IsOversampled() {
+			t.Fatal("True-peak mode should oversample")
+		}
+
+		n := int(sampleRate / 2)
+		in := make([]float32, n)
+		for i := range in {
+			fade := math.Min(1, float64(i)/2000)
+			in[i] = float32(2 * fade * math.Sin(2*math.Pi*freq*float64(i)/sampleRate+0.3))
+		}
+		out := make([]float32, n)
+		c.ProcessBuffer(in, out)
+
+		meter := analysis.NewTruePeakMeter(sampleRate, 1)
+		for _, v := range out {
+			meter.Process([]float64{float64(v)})
+		}
+		if tp := meter.GetTruePeakDB(0); tp > -1+0.01 {
+			t.Errorf("%g Hz: true peak = %.3f dBTP, above the -1 dBTP ceiling", freq, tp)
+		}
+		if tp := meter.GetTruePeakDB(0); tp < -1.5 {
+			t.Errorf("%g Hz: true peak = %.3f dBTP, want the output near the ceiling", freq, tp)
+		}
+	}
+}
+
+func TestClipperStereoReset(t *testing.T) {
+	c := NewClipper(48000)
+	c.SetOversampling(true)
+	inL := []float32{0.9, -0.9, 0.9, -0.9}
+	inR := make([]float32, 4)
+	outL := make([]float32, 4)
+	outR := make([]float32, 4)
+	c.ProcessStereo(inL, inR, outL, outR)
+
+	// The silent right channel stays silent: channels do not share filters
+	for i, v := range outR {
+		if v != 0 {
+			t.Errorf("Right output %d = %f, want 0", i, v)
+		}
+	}
+
+	c.Reset()
+	c.ProcessStereo(inR, inR, outL, outR)
+	for i, v := range outL {
+		if v != 0 {
+			t.Errorf("Left output %d after reset = %f, want 0", i, v)
+		}
+	}
+}
+
+func BenchmarkClipperOversampled(b *testing.B) {
+	c := NewClipper(48000)
+	c.SetCurve(ClipSoft)
+	c.SetOversampling(true)
+	buf := make([]float32, 512)
+	for i := range buf {
+		buf[i] = float32(1.5 * math.Sin(float64(i)*0.1))
+	}
+	out := make([]float32, 512)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.ProcessBuffer(buf, out)
+	}
+}
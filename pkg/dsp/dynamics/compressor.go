@@ -4,7 +4,9 @@ package dynamics
 import (
 	"math"
 
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
 	"github.com/justyntemme/vst3go/pkg/dsp/envelope"
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
 )
 
 // KneeType defines the compressor knee characteristic
@@ -17,6 +19,58 @@ const (
 	KneeSoft
 )
 
+// DetectorType selects how the compressor measures level
+type DetectorType int
+
+const (
+	// DetectorPeak follows the peaks of the signal and catches every
+	// transient
+	DetectorPeak DetectorType = iota
+	// DetectorRMS follows the average power over a short window, so it
+	// reacts to loudness rather than peaks
+	DetectorRMS
+	// DetectorHybrid sits halfway between the peak and RMS levels in dB, so
+	// transients register without dominating
+	DetectorHybrid
+)
+
+// Topology selects where the compressor takes its detection signal from
+type Topology int
+
+const (
+	// TopologyFeedForward detects from the input, so the ratio is exact
+	TopologyFeedForward Topology = iota
+	// TopologyFeedback detects from the compressor's own output, like many
+	// vintage designs. The gain reduction softens the signal it is derived
+	// from, so steady signals are compressed at no more than 2:1 and the
+	// response to transients is smoother.
+	TopologyFeedback
+)
+
+// Direction selects which way a compressor or expander changes the level
+type Direction int
+
+const (
+	// DirectionDownward turns the level down: a compressor reduces signals
+	// above the threshold and an expander those below it
+	DirectionDownward Direction = iota
+	// DirectionUpward turns the level up: a compressor raises signals below
+	// the threshold toward it and an expander raises those above it, each
+	// by at most the range
+	DirectionUpward
+)
+
+// DefaultUpwardRange is the most upward compression raises quiet signals, in
+// dB
+const DefaultUpwardRange = 12.0
+
+// Detector and auto release settings
+const (
+	compressorRMSWindow = 10.0 // Milliseconds
+	autoReleaseCharge   = 0.2  // Seconds of sustained reduction that charge the slow release
+	autoReleaseSlow     = 1.5  // Seconds, release after sustained reduction
+)
+
 // Compressor implements a feed-forward compressor with flexible controls
 type Compressor struct {
 	sampleRate float64
@@ -31,9 +85,32 @@ type Compressor struct {
 	kneeType   KneeType // Knee type
 	lookahead  float64  // Lookahead time in seconds
 
+	detectorType DetectorType
+	topology     Topology
+	autoRelease  bool
+	direction    Direction
+	upwardRange  float64 // Most upward compression boosts, in dB
+	mix          float64 // Dry/wet mix, 0-1
+
 	// Envelope detector
 	detector *envelope.Detector
 
+	// Per-channel detectors for partially linked stereo
+	channelDetectors [2]*envelope.Detector
+	link             float64 // Stereo link amount, 0-1
+
+	// RMS detectors, the linked one first and then each channel
+	rmsDetectors [3]*envelope.Detector
+
+	// Auto release: gain reduction of each channel charged and released
+	// slowly, in dB
+	chargeCoeff      float64
+	slowReleaseCoeff float64
+	slowReduction    [2]float64
+
+	// Feedback topology: last output of each channel before makeup gain
+	feedback [2]float32
+
 	// Lookahead delay line
 	delayBuffer  []float32
 	delayIndex   int
@@ -55,11 +132,25 @@ func NewCompressor(sampleRate float64) *Compressor {
 		makeupGain: 0.0,
 		kneeType:   KneeSoft,
 		detector:   envelope.NewDetector(sampleRate, envelope.ModePeak),
+		link:       DefaultStereoLink,
+
+		upwardRange: DefaultUpwardRange,
+		mix:         1.0,
+	}
+	c.channelDetectors[0] = envelope.NewDetector(sampleRate, envelope.ModePeak)
+	c.channelDetectors[1] = envelope.NewDetector(sampleRate, envelope.ModePeak)
+	for i := range c.rmsDetectors {
+		c.rmsDetectors[i] = envelope.NewDetector(sampleRate, envelope.ModeRMS)
+		c.rmsDetectors[i].SetRMSWindow(compressorRMSWindow)
 	}
+	c.chargeCoeff = coeff.TimeConstant(autoReleaseCharge, sampleRate)
+	c.slowReleaseCoeff = coeff.TimeConstant(autoReleaseSlow, sampleRate)
 
-	// Configure detector for compressor use
-	c.detector.SetType(envelope.TypeLogarithmic) // More musical response
-	c.detector.SetTimeConstants(c.attack, c.release)
+	// Configure detectors for compressor use
+	for _, d := range c.detectors() {
+		d.SetType(envelope.TypeLogarithmic) // More musical response
+		d.SetTimeConstants(c.attack, c.release)
+	}
 
 	return c
 }
@@ -77,13 +168,153 @@ func (c *Compressor) SetRatio(ratio float64) {
 // SetAttack sets the attack time in seconds
 func (c *Compressor) SetAttack(seconds float64) {
 	c.attack = math.Max(0.0001, seconds)
-	c.detector.SetAttack(c.attack)
+	for _, d := range c.detectors() {
+		d.SetAttack(c.attack)
+	}
 }
 
-// SetRelease sets the release time in seconds
+// SetRelease sets the release time in seconds. With auto release on it sets
+// the release after brief reduction.
 func (c *Compressor) SetRelease(seconds float64) {
 	c.release = math.Max(0.001, seconds)
-	c.detector.SetRelease(c.release)
+	for _, d := range c.detectors() {
+		d.SetRelease(c.release)
+	}
+}
+
+// SetDetector selects peak, RMS or hybrid level detection
+func (c *Compressor) SetDetector(detectorType DetectorType) {
+	if detectorType < DetectorPeak || detectorType > DetectorHybrid {
+		detectorType = DetectorPeak
+	}
+	c.detectorType = detectorType
+}
+
+// GetDetector returns the detector type
+func (c *Compressor) GetDetector() DetectorType {
+	return c.detectorType
+}
+
+// SetAutoRelease turns program-dependent release on or off. Brief gain
+// reduction, such as from a single drum hit, recovers at the release time,
+// while reduction sustained for longer charges a slow stage that recovers
+// over about 1.5 seconds, so dense material does not pump.
+func (c *Compressor) SetAutoRelease(enabled bool) {
+	if !enabled {
+		c.slowReduction = [2]float64{}
+	}
+	c.autoRelease = enabled
+}
+
+// GetAutoRelease reports whether auto release is on
+func (c *Compressor) GetAutoRelease() bool {
+	return c.autoRelease
+}
+
+// SetTopology selects feed-forward or feedback detection
+func (c *Compressor) SetTopology(topology Topology) {
+	if topology != TopologyFeedback {
+		topology = TopologyFeedForward
+	}
+	c.topology = topology
+}
+
+// GetTopology returns the detection topology
+func (c *Compressor) GetTopology() Topology {
+	return c.topology
+}
+
+// SetStereoLink sets how strongly ProcessStereo links the channels, from 0
+// (each channel compressed on its own level) to 1 (both follow the louder
+// channel, the default)
+func (c *Compressor) SetStereoLink(amount float64) {
+	c.link = clampLink(amount)
+}
+
+// GetStereoLink returns the stereo link amount (0-1)
+func (c *Compressor) GetStereoLink() float64 {
+	return c.link
+}
+
+// SetDirection selects downward compression, which reduces signals above
+// the threshold, or upward compression, which raises signals below it by
+// up to the range. Auto release only applies downward.
+func (c *Compressor) SetDirection(direction Direction) {
+	if direction != DirectionUpward {
+		direction = DirectionDownward
+	}
+	c.direction = direction
+}
+
+// GetDirection returns the compression direction
+func (c *Compressor) GetDirection() Direction {
+	return c.direction
+}
+
+// SetRange sets the most upward compression raises quiet signals, in dB
+func (c *Compressor) SetRange(dB float64) {
+	c.upwardRange = math.Max(0.0, dB)
+}
+
+// GetRange returns the upward compression range in dB
+func (c *Compressor) GetRange() float64 {
+	return c.upwardRange
+}
+
+// SetMix sets the dry/wet mix (0-1) for parallel compression. The dry
+// signal is delayed by the same lookahead as the compressed one, so the two
+// stay aligned.
+func (c *Compressor) SetMix(amount float64) {
+	c.mix = clampMix(amount)
+}
+
+// GetMix returns the dry/wet mix (0-1)
+func (c *Compressor) GetMix() float64 {
+	return c.mix
+}
+
+// detectors returns the linked and channel detectors, peak and RMS
+func (c *Compressor) detectors() [6]*envelope.Detector {
+	return [6]*envelope.Detector{
+		c.detector, c.channelDetectors[0], c.channelDetectors[1],
+		c.rmsDetectors[0], c.rmsDetectors[1], c.rmsDetectors[2],
+	}
+}
+
+// level runs the peak and RMS detectors of a path (0 linked, 1 left, 2
+// right) and returns the level in dB for the detector type. Both always run
+// so the type can change without jumps.
+func (c *Compressor) level(path int, input float32) float64 {
+	peak := c.detector
+	if path > 0 {
+		peak = c.channelDetectors[path-1]
+	}
+	peakDB := levelDB(peak.Detect(input))
+	rmsDB := levelDB(c.rmsDetectors[path].Detect(input))
+	switch c.detectorType {
+	case DetectorRMS:
+		return rmsDB
+	case DetectorHybrid:
+		return 0.5 * (peakDB + rmsDB)
+	default:
+		return peakDB
+	}
+}
+
+// applyRelease holds back the recovery of a channel's gain reduction in
+// dB when auto release is on
+func (c *Compressor) applyRelease(ch int, reduction float64) float64 {
+	if !c.autoRelease || c.direction == DirectionUpward {
+		return reduction
+	}
+	slow := c.slowReduction[ch]
+	if reduction > slow {
+		slow = reduction + (slow-reduction)*c.chargeCoeff
+	} else {
+		slow = reduction + (slow-reduction)*c.slowReleaseCoeff
+	}
+	c.slowReduction[ch] = slow
+	return math.Max(reduction, slow)
 }
 
 // SetKnee sets the knee type and width
@@ -114,11 +345,22 @@ func (c *Compressor) SetLookahead(seconds float64) {
 	}
 }
 
-// GetGainReduction returns the current gain reduction in dB (for metering)
+// GetGainReduction returns the current gain reduction in dB (for metering).
+// Upward compression reports its boost as a negative reduction.
 func (c *Compressor) GetGainReduction() float64 {
 	return c.lastGainReduction
 }
 
+// targetReduction calculates the gain reduction in dB for a level in the
+// current direction. Upward compression is the downward curve mirrored
+// around the threshold, turned into a boost and limited to the range.
+func (c *Compressor) targetReduction(inputDB float64) float64 {
+	if c.direction == DirectionUpward {
+		return -math.Min(c.upwardRange, c.computeGain(2*c.threshold-inputDB))
+	}
+	return c.computeGain(inputDB)
+}
+
 // computeGain calculates the gain reduction for a given input level
 func (c *Compressor) computeGain(inputDB float64) float64 {
 	// Below threshold - knee: no compression
@@ -167,26 +409,24 @@ func (c *Compressor) Process(input float32) float32 {
 		c.delayBuffer[c.delayIndex] = input
 		c.delayIndex = (c.delayIndex + 1) % c.delaySamples
 	}
-
-	// Get envelope of detection signal
-	envelope := c.detector.Detect(detectionSignal)
-
-	// Convert to dB
-	inputDB := float64(-96.0)
-	if envelope > 0 {
-		inputDB = 20.0 * math.Log10(float64(envelope))
+	if c.topology == TopologyFeedback {
+		detectionSignal = c.feedback[0]
 	}
 
-	// Calculate gain reduction
-	gainReductionDB := c.computeGain(inputDB)
+	// Calculate gain reduction from the detected level in dB
+	gainReductionDB := c.applyRelease(0, c.targetReduction(c.level(0, detectionSignal)))
 	c.lastGainReduction = gainReductionDB
+	if c.topology == TopologyFeedback {
+		c.feedback[0] = processSignal * fastmath.High.DbToLinear(float32(-gainReductionDB))
+	}
 
 	// Convert gain reduction to linear and apply with makeup gain
 	totalGainDB := -gainReductionDB + c.makeupGain
-	gain := math.Pow(10.0, totalGainDB/20.0)
+	gain := fastmath.High.DbToLinear(float32(totalGainDB))
 
-	// Apply gain to delayed signal
-	return processSignal * float32(gain)
+	// Apply gain to delayed signal, blended with the equally delayed dry
+	// signal
+	return processSignal * parallelGain(c.mix, gain)
 }
 
 // ProcessBuffer processes a buffer of samples
@@ -196,62 +436,64 @@ func (c *Compressor) ProcessBuffer(input, output []float32) {
 	}
 }
 
-// ProcessStereo processes stereo buffers with linked compression
+// ProcessStereo processes stereo buffers with linked compression. The
+// channel detectors always run so the link amount can change without jumps.
 func (c *Compressor) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
 	for i := range inputL {
+		detectL, detectR := inputL[i], inputR[i]
+		if c.topology == TopologyFeedback {
+			detectL, detectR = c.feedback[0], c.feedback[1]
+		}
+
 		// Get max of both channels for linked compression
-		maxInput := float32(math.Max(math.Abs(float64(inputL[i])), math.Abs(float64(inputR[i]))))
+		maxInput := float32(math.Max(math.Abs(float64(detectL)), math.Abs(float64(detectR))))
 
 		// Get envelope from combined signal
-		envelope := c.detector.Detect(maxInput)
-
-		// Convert to dB
-		inputDB := float64(-96.0)
-		if envelope > 0 {
-			inputDB = 20.0 * math.Log10(float64(envelope))
-		}
+		linkedDB := c.level(0, maxInput)
+		levelL := linkLevel(c.link, linkedDB, c.level(1, detectL))
+		levelR := linkLevel(c.link, linkedDB, c.level(2, detectR))
 
 		// Calculate gain reduction
-		gainReductionDB := c.computeGain(inputDB)
-		c.lastGainReduction = gainReductionDB
+		reductionL := c.applyRelease(0, c.targetReduction(levelL))
+		reductionR := c.applyRelease(1, c.targetReduction(levelR))
+		c.lastGainReduction = math.Max(reductionL, reductionR)
+		if c.direction == DirectionUpward {
+			c.lastGainReduction = math.Min(reductionL, reductionR)
+		}
+		if c.topology == TopologyFeedback {
+			c.feedback[0] = inputL[i] * fastmath.High.DbToLinear(float32(-reductionL))
+			c.feedback[1] = inputR[i] * fastmath.High.DbToLinear(float32(-reductionR))
+		}
 
 		// Convert to linear gain
-		totalGainDB := -gainReductionDB + c.makeupGain
-		gain := float32(math.Pow(10.0, totalGainDB/20.0))
-
-		// Apply same gain to both channels
-		outputL[i] = inputL[i] * gain
-		outputR[i] = inputR[i] * gain
+		outputL[i] = inputL[i] * parallelGain(c.mix, fastmath.High.DbToLinear(float32(-reductionL+c.makeupGain)))
+		outputR[i] = inputR[i] * parallelGain(c.mix, fastmath.High.DbToLinear(float32(-reductionR+c.makeupGain)))
 	}
 }
 
-// ProcessSidechain processes input using a sidechain signal for detection
+// ProcessSidechain processes input using a sidechain signal for detection.
+// The sidechain is detected whatever the topology.
 func (c *Compressor) ProcessSidechain(input, sidechain, output []float32) {
 	for i := range input {
-		// Detect from sidechain
-		envelope := c.detector.Detect(sidechain[i])
-
-		// Convert to dB
-		inputDB := float64(-96.0)
-		if envelope > 0 {
-			inputDB = 20.0 * math.Log10(float64(envelope))
-		}
-
-		// Calculate gain reduction
-		gainReductionDB := c.computeGain(inputDB)
+		// Calculate gain reduction from the sidechain level
+		gainReductionDB := c.applyRelease(0, c.targetReduction(c.level(0, sidechain[i])))
 		c.lastGainReduction = gainReductionDB
 
 		// Apply to input signal
 		totalGainDB := -gainReductionDB + c.makeupGain
-		gain := float32(math.Pow(10.0, totalGainDB/20.0))
-		output[i] = input[i] * gain
+		gain := fastmath.High.DbToLinear(float32(totalGainDB))
+		output[i] = input[i] * parallelGain(c.mix, gain)
 	}
 }
 
 // Reset resets the compressor state
 func (c *Compressor) Reset() {
-	c.detector.Reset()
+	for _, d := range c.detectors() {
+		d.Reset()
+	}
 	c.lastGainReduction = 0.0
+	c.slowReduction = [2]float64{}
+	c.feedback = [2]float32{}
 	c.delayIndex = 0
 
 	// Clear delay buffer
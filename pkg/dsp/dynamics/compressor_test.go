@@ -186,6 +186,194 @@ func TestCompressorReset(t *testing.T) {
 	}
 }
 
+func TestCompressorDetectorTypes(t *testing.T) {
+	// Short pulses have peaks well above their RMS level
+	input := make([]float32, 48000)
+	for i := range input {
+		if i%480 < 48 {
+			input[i] = 0.5
+		}
+	}
+	output := make([]float32, len(input))
+
+	var reduction [3]float64
+	for i, detectorType := range []DetectorType{DetectorPeak, DetectorHybrid, DetectorRMS} {
+		c := NewCompressor(48000.0)
+		c.SetThreshold(-30.0)
+		c.SetKnee(KneeHard, 0.0)
+		c.SetDetector(detectorType)
+		if c.GetDetector() != detectorType {
+			t.Fatalf("detector %d, want %d", c.GetDetector(), detectorType)
+		}
+		c.ProcessBuffer(input, output)
+		reduction[i] = c.GetGainReduction()
+	}
+	if !(reduction[0] > reduction[1] && reduction[1] > reduction[2] && reduction[2] > 0) {
+		t.Errorf("peak, hybrid and RMS gain reduction %v, want decreasing", reduction)
+	}
+
+	c := NewCompressor(48000.0)
+	c.SetDetector(DetectorType(7))
+	if c.GetDetector() != DetectorPeak {
+		t.Errorf("invalid detector type gave %d, want peak", c.GetDetector())
+	}
+}
+
+func TestCompressorAutoRelease(t *testing.T) {
+	silence := make([]float32, 4800) // 100ms
+	output := make([]float32, 48000)
+
+	// Reduction left 100ms after a burst of the given length
+	afterBurst := func(autoRelease bool, samples int) float64 {
+		c := NewCompressor(48000.0)
+		c.SetThreshold(-30.0)
+		c.SetRelease(0.02)
+		c.SetAutoRelease(autoRelease)
+		burst := make([]float32, samples)
+		for i := range burst {
+			burst[i] = 0.9 * float32(math.Sin(2*math.Pi*1000*float64(i)/48000))
+		}
+		c.ProcessBuffer(burst, output)
+		c.ProcessBuffer(silence, output)
+		return c.GetGainReduction()
+	}
+
+	if gr := afterBurst(false, 48000); gr > 0.5 {
+		t.Errorf("%.1f dB reduction left without auto release", gr)
+	}
+	if gr := afterBurst(true, 240); gr > 3 {
+		t.Errorf("%.1f dB reduction left after a 5ms burst with auto release", gr)
+	}
+	if gr := afterBurst(true, 48000); gr < 10 {
+		t.Errorf("%.1f dB reduction left after a 1s burst, want a slow release", gr)
+	}
+
+	c := NewCompressor(48000.0)
+	c.SetAutoRelease(true)
+	if !c.GetAutoRelease() {
+		t.Error("auto release not reported on")
+	}
+}
+
+func TestCompressorFeedback(t *testing.T) {
+	input := make([]float32, 24000)
+	for i := range input {
+		input[i] = float32(math.Sin(2 * math.Pi * 1000 * float64(i) / 48000))
+	}
+
+	// Settled output peak of a 0 dB sine, 20 dB over the threshold
+	settled := func(topology Topology) float64 {
+		c := NewCompressor(48000.0)
+		c.SetThreshold(-20.0)
+		c.SetRatio(4.0)
+		c.SetKnee(KneeHard, 0.0)
+		c.SetTopology(topology)
+		output := make([]float32, len(input))
+		c.ProcessBuffer(input, output)
+		peak := 0.0
+		for _, x := range output[len(output)-480:] {
+			peak = math.Max(peak, math.Abs(float64(x)))
+		}
+		return 20 * math.Log10(peak)
+	}
+
+	// Feed-forward gives 4:1; feedback about 1.75:1
+	if db := settled(TopologyFeedForward); math.Abs(db-(-15.0)) > 1.0 {
+		t.Errorf("feed-forward output %.1f dB, want about -15", db)
+	}
+	if db := settled(TopologyFeedback); math.Abs(db-(-8.6)) > 1.5 {
+		t.Errorf("feedback output %.1f dB, want about -8.6", db)
+	}
+
+	c := NewCompressor(48000.0)
+	c.SetTopology(TopologyFeedback)
+	if c.GetTopology() != TopologyFeedback {
+		t.Error("feedback topology not set")
+	}
+	c.Process(1.0)
+	c.Reset()
+	if c.feedback != [2]float32{} {
+		t.Error("feedback state not reset")
+	}
+}
+
+func TestCompressorUpward(t *testing.T) {
+	c := NewCompressor(48000.0)
+	c.SetThreshold(-20.0)
+	c.SetRatio(2.0)
+	c.SetKnee(KneeHard, 0.0)
+	c.SetDirection(DirectionUpward)
+	if c.GetDirection() != DirectionUpward {
+		t.Fatal("upward direction not set")
+	}
+
+	// settled returns the steady gain in dB for a constant level
+	settled := func(level float32) float64 {
+		var output float32
+		for i := 0; i < 4800; i++ {
+			output = c.Process(level)
+		}
+		return 20 * math.Log10(float64(output/level))
+	}
+
+	// 10 dB under the threshold at 2:1 is raised by 5 dB
+	if db := settled(0.0316); math.Abs(db-5.0) > 0.1 {
+		t.Errorf("upward compression %.2f dB, want 5", db)
+	}
+	if gr := c.GetGainReduction(); math.Abs(gr+5.0) > 0.1 {
+		t.Errorf("metered %.2f dB, want -5 for a boost", gr)
+	}
+
+	// The range limits the boost of very quiet signals
+	if db := settled(0.0001); math.Abs(db-DefaultUpwardRange) > 0.1 {
+		t.Errorf("quiet signal raised %.2f dB, want the %g dB range", db, DefaultUpwardRange)
+	}
+	c.SetRange(6.0)
+	if db := settled(0.0001); math.Abs(db-6.0) > 0.1 {
+		t.Errorf("quiet signal raised %.2f dB with a 6 dB range", db)
+	}
+
+	// Signals above the threshold are left alone
+	if db := settled(0.5); math.Abs(db) > 0.01 {
+		t.Errorf("loud signal changed %.2f dB", db)
+	}
+}
+
+func TestCompressorMix(t *testing.T) {
+	// A fully dry compressor with lookahead delays the input exactly
+	c := NewCompressor(48000.0)
+	c.SetLookahead(0.001)
+	c.SetMix(0)
+	input := make([]float32, 480)
+	for i := range input {
+		input[i] = float32(math.Sin(2 * math.Pi * 1000 * float64(i) / 48000))
+	}
+	output := make([]float32, len(input))
+	c.ProcessBuffer(input, output)
+	for i := 48; i < len(input); i++ {
+		if output[i] != input[i-48] {
+			t.Fatalf("dry sample %d is %g, want the delayed input %g", i, output[i], input[i-48])
+		}
+	}
+
+	// Half wet blends the gain halfway to unity
+	c = NewCompressor(48000.0)
+	c.SetThreshold(-20.0)
+	c.SetRatio(math.Inf(1))
+	c.SetKnee(KneeHard, 0.0)
+	c.SetMix(0.5)
+	if c.GetMix() != 0.5 {
+		t.Fatalf("mix %g, want 0.5", c.GetMix())
+	}
+	var out float32
+	for i := 0; i < 4800; i++ {
+		out = c.Process(1.0)
+	}
+	if want := float32(0.5 + 0.5*0.1); math.Abs(float64(out-want)) > 1e-3 {
+		t.Errorf("half wet output %g, want %g", out, want)
+	}
+}
+
 // Benchmark single sample processing
 func BenchmarkCompressor(b *testing.B) {
 	c := NewCompressor(48000.0)
@@ -0,0 +1,211 @@
+package dynamics
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+// Default de-esser settings
+const (
+	DefaultDeEsserFrequency = 6000.0 // Hz
+	DefaultDeEsserThreshold = -30.0  // dB
+	DefaultDeEsserRange     = 10.0   // dB, the most it turns sibilance down
+	DefaultDeEsserRatio     = 4.0
+)
+
+// De-esser time constants, fast enough to catch single consonants
+const (
+	deEsserAttack  = 0.001 // Seconds
+	deEsserRelease = 0.060 // Seconds
+)
+
+// DeEsser turns down sibilance. A Linkwitz-Riley crossover splits off the
+// band above the set frequency; when its level passes the threshold only
+// that band is reduced, by up to the range, and summed back with the rest
+// of the signal, so the voice keeps its body. The two bands stay in phase,
+// so with no reduction the magnitude response is flat. All channels share
+// one detector.
+type DeEsser struct {
+	sampleRate float64
+	channels   int
+
+	// Parameters
+	frequency float64 // Hz
+	threshold float64 // dB
+	rangeDB   float64 // dB
+	ratio     float64
+
+	attackCoeff  float64
+	releaseCoeff float64
+
+	// Fourth-order Linkwitz-Riley crossover, two Butterworth stages a side
+	lowpass  [2]*filter.Biquad
+	highpass [2]*filter.Biquad
+	band     [][]float32 // Sibilance band of each channel, grown to the block
+
+	envelope       float64 // Linked band level, linear
+	gainReduction  float64 // dB
+	blockReduction float64 // Largest gain reduction in the current block
+}
+
+// NewDeEsser creates a de-esser for the given number of channels
+func NewDeEsser(sampleRate float64, channels int) *DeEsser {
+	channels = max(1, channels)
+	d := &DeEsser{
+		sampleRate: sampleRate,
+		channels:   channels,
+		frequency:  DefaultDeEsserFrequency,
+		threshold:  DefaultDeEsserThreshold,
+		rangeDB:    DefaultDeEsserRange,
+		ratio:      DefaultDeEsserRatio,
+		band:       make([][]float32, channels),
+	}
+	for i := range d.lowpass {
+		d.lowpass[i] = filter.NewBiquad(channels)
+		d.highpass[i] = filter.NewBiquad(channels)
+	}
+	d.attackCoeff = coeff.TimeConstant(deEsserAttack, sampleRate)
+	d.releaseCoeff = coeff.TimeConstant(deEsserRelease, sampleRate)
+	d.updateFilter()
+	return d
+}
+
+// Channels returns the number of channels ProcessChannels expects
+func (d *DeEsser) Channels() int {
+	return d.channels
+}
+
+// SetFrequency sets the lower edge of the sibilance band in Hz
+func (d *DeEsser) SetFrequency(hz float64) {
+	d.frequency = math.Max(1000, math.Min(hz, 0.45*d.sampleRate))
+	d.updateFilter()
+}
+
+// GetFrequency returns the lower edge of the sibilance band in Hz
+func (d *DeEsser) GetFrequency() float64 {
+	return d.frequency
+}
+
+// SetThreshold sets the band level in dB above which sibilance is reduced
+func (d *DeEsser) SetThreshold(dB float64) {
+	d.threshold = dB
+}
+
+// GetThreshold returns the threshold in dB
+func (d *DeEsser) GetThreshold() float64 {
+	return d.threshold
+}
+
+// SetRange sets the largest reduction of the band in dB
+func (d *DeEsser) SetRange(dB float64) {
+	d.rangeDB = math.Max(0, dB)
+}
+
+// GetRange returns the largest reduction in dB
+func (d *DeEsser) GetRange() float64 {
+	return d.rangeDB
+}
+
+// SetRatio sets how strongly the band is reduced above the threshold
+func (d *DeEsser) SetRatio(ratio float64) {
+	d.ratio = math.Max(1, ratio)
+}
+
+// GetGainReduction returns the current reduction of the band in dB
+func (d *DeEsser) GetGainReduction() float64 {
+	return d.gainReduction
+}
+
+// GetMaxGainReduction returns the largest reduction in dB during the last
+// ProcessBuffer, ProcessStereo or ProcessChannels call
+func (d *DeEsser) GetMaxGainReduction() float64 {
+	return d.blockReduction
+}
+
+// updateFilter designs the crossover
+func (d *DeEsser) updateFilter() {
+	for i := range d.lowpass {
+		d.lowpass[i].SetLowpass(d.sampleRate, d.frequency, math.Sqrt2/2)
+		d.highpass[i].SetHighpass(d.sampleRate, d.frequency, math.Sqrt2/2)
+	}
+}
+
+// ProcessBuffer de-esses a mono buffer on the first channel
+func (d *DeEsser) ProcessBuffer(input, output []float32) {
+	copy(output, input)
+	d.ProcessChannels([][]float32{output})
+}
+
+// ProcessStereo de-esses stereo buffers with a linked detector
+func (d *DeEsser) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
+	copy(outputL, inputL)
+	copy(outputR, inputR)
+	d.ProcessChannels([][]float32{outputL, outputR})
+}
+
+// ProcessChannels de-esses one buffer per channel in place. Buffers beyond
+// Channels are left untouched.
+func (d *DeEsser) ProcessChannels(buffers [][]float32) {
+	d.blockReduction = 0
+	n := min(len(buffers), d.channels)
+	if n == 0 {
+		return
+	}
+	frames := len(buffers[0])
+
+	// Split off the sibilance band, leaving the rest in the buffer
+	for ch := 0; ch < n; ch++ {
+		if cap(d.band[ch]) < frames {
+			d.band[ch] = make([]float32, frames)
+		}
+		band := d.band[ch][:frames]
+		copy(band, buffers[ch])
+		for i := range d.highpass {
+			d.highpass[i].Process(band, ch)
+			d.lowpass[i].Process(buffers[ch], ch)
+		}
+	}
+
+	for i := 0; i < frames; i++ {
+		peak := 0.0
+		for ch := 0; ch < n; ch++ {
+			peak = math.Max(peak, math.Abs(float64(d.band[ch][i])))
+		}
+		if peak > d.envelope {
+			d.envelope = peak + d.attackCoeff*(d.envelope-peak)
+		} else {
+			d.envelope = peak + d.releaseCoeff*(d.envelope-peak)
+		}
+
+		reduction := 0.0
+		if d.envelope > 0 {
+			if over := float64(fastmath.High.LinearToDb(float32(d.envelope))) - d.threshold; over > 0 {
+				reduction = math.Min(d.rangeDB, over*(1-1/d.ratio))
+			}
+		}
+		d.gainReduction = reduction
+		d.blockReduction = math.Max(d.blockReduction, reduction)
+
+		gain := float32(1)
+		if reduction > 0 {
+			gain = fastmath.High.DbToLinear(float32(-reduction))
+		}
+		for ch := 0; ch < n; ch++ {
+			buffers[ch][i] += gain * d.band[ch][i]
+		}
+	}
+}
+
+// Reset clears the filter and detector state
+func (d *DeEsser) Reset() {
+	for i := range d.lowpass {
+		d.lowpass[i].Reset()
+		d.highpass[i].Reset()
+	}
+	d.envelope = 0
+	d.gainReduction = 0
+	d.blockReduction = 0
+}
@@ -0,0 +1,68 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// tone returns n samples of a sine at hz
+func deEsserTone(hz, amplitude, sampleRate float64, n int) []float32 {
+	x := make([]float32, n)
+	for i := range x {
+		x[i] = float32(amplitude * math.Sin(2*math.Pi*hz*float64(i)/sampleRate))
+	}
+	return x
+}
+
+func rmsOf(x []float32) float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += float64(v) * float64(v)
+	}
+	return math.Sqrt(sum / float64(len(x)))
+}
+
+func TestDeEsserTransparentBelowThreshold(t *testing.T) {
+	const sr = 48000
+	d := NewDeEsser(sr, 1)
+	// Quiet tones around the crossover keep their level
+	for _, hz := range []float64{200, 4000, 6000, 8000, 15000} {
+		d.Reset()
+		in := deEsserTone(hz, 0.001, sr, 4800) // -60 dB
+		out := make([]float32, len(in))
+		d.ProcessBuffer(in, out)
+		if db := 20 * math.Log10(rmsOf(out[2400:])/rmsOf(in[2400:])); math.Abs(db) > 0.05 {
+			t.Errorf("%g Hz changed by %.3f dB below the threshold", hz, db)
+		}
+	}
+	if d.GetMaxGainReduction() != 0 {
+		t.Errorf("gain reduction %g dB below the threshold", d.GetMaxGainReduction())
+	}
+}
+
+func TestDeEsserReducesOnlySibilance(t *testing.T) {
+	const sr = 48000
+	d := NewDeEsser(sr, 2)
+	d.SetThreshold(-30)
+	d.SetRange(12)
+
+	// Loud sibilance is reduced by up to the range
+	hiss := deEsserTone(9000, 0.5, sr, 9600)
+	outL, outR := make([]float32, len(hiss)), make([]float32, len(hiss))
+	d.ProcessStereo(hiss, hiss, outL, outR)
+	settled := rmsOf(outL[4800:]) / rmsOf(hiss[4800:])
+	if db := 20 * math.Log10(settled); db > -8 || db < -12.5 {
+		t.Errorf("sibilance reduced by %.1f dB, want close to the 12 dB range", -db)
+	}
+	if gr := d.GetMaxGainReduction(); gr != 12 {
+		t.Errorf("max gain reduction %g dB, want the 12 dB range", gr)
+	}
+
+	// An equally loud vowel passes
+	d.Reset()
+	vowel := deEsserTone(300, 0.5, sr, 9600)
+	d.ProcessStereo(vowel, vowel, outL, outR)
+	if db := 20 * math.Log10(rmsOf(outL[4800:])/rmsOf(vowel[4800:])); math.Abs(db) > 0.5 {
+		t.Errorf("300 Hz changed by %.2f dB", db)
+	}
+}
@@ -3,10 +3,14 @@ package dynamics
 import (
 	"math"
 
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
 	"github.com/justyntemme/vst3go/pkg/dsp/envelope"
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
 )
 
-// Expander implements a downward expander for reducing low-level signals
+// Expander implements a downward expander for reducing low-level signals,
+// or in upward mode an upward expander that raises signals above the
+// threshold
 type Expander struct {
 	sampleRate float64
 
@@ -17,14 +21,25 @@ type Expander struct {
 	release   float64 // Release time in seconds
 	knee      float64 // Knee width in dB
 	range_    float64 // Maximum expansion range in dB
+	rangeKnee float64 // Width in dB over which expansion eases into the range
+	hold      float64 // Hold time in seconds
+	direction Direction
+	mix       float64 // Dry/wet mix, 0-1
 
 	// Envelope detection
 	detector *envelope.Detector
 
+	// Detector high-pass (optional)
+	hpfEnabled   bool
+	hpfFrequency float64
+	hpf          *filter.SVF
+
 	// Smoothing
 	currentGain  float64
 	attackCoeff  float64
 	releaseCoeff float64
+	holdSamples  int
+	holdCounter  int
 
 	// State
 	gainReduction float64 // Current gain reduction in dB (negative for expansion)
@@ -40,8 +55,10 @@ func NewExpander(sampleRate float64) *Expander {
 		release:     0.100, // 100ms default
 		knee:        2.0,   // 2dB soft knee
 		range_:      -40.0, // Max 40dB expansion
+		mix:         1.0,
 		currentGain: 1.0,
 		detector:    envelope.NewDetector(sampleRate, envelope.ModePeak),
+		hpf:         filter.NewSVF(2),
 	}
 
 	// Configure detector
@@ -78,12 +95,59 @@ func (e *Expander) SetKnee(dB float64) {
 	e.knee = math.Max(0.0, dB)
 }
 
-// SetRange sets the maximum expansion range in dB
+// SetRange sets the maximum expansion range in dB. Upward expansion boosts
+// by at most the same number of dB.
 func (e *Expander) SetRange(dB float64) {
 	e.range_ = math.Min(0.0, dB)
 }
 
-// GetGainReduction returns the current gain reduction in dB
+// SetRangeKnee sets the width in dB over which expansion eases into the
+// range instead of stopping at it abruptly (0 for a hard stop)
+func (e *Expander) SetRangeKnee(dB float64) {
+	e.rangeKnee = math.Max(0.0, dB)
+}
+
+// SetHold sets how long in seconds the gain is held after the level drops
+// before expansion starts, so transients keep their tail
+func (e *Expander) SetHold(seconds float64) {
+	e.hold = math.Max(0.0, seconds)
+	e.holdSamples = int(e.hold * e.sampleRate)
+}
+
+// SetDirection selects downward expansion, which reduces signals below the
+// threshold, or upward expansion, which raises signals above it
+func (e *Expander) SetDirection(direction Direction) {
+	if direction != DirectionUpward {
+		direction = DirectionDownward
+	}
+	e.direction = direction
+}
+
+// GetDirection returns the expansion direction
+func (e *Expander) GetDirection() Direction {
+	return e.direction
+}
+
+// SetMix sets the dry/wet mix (0-1)
+func (e *Expander) SetMix(amount float64) {
+	e.mix = clampMix(amount)
+}
+
+// GetMix returns the dry/wet mix (0-1)
+func (e *Expander) GetMix() float64 {
+	return e.mix
+}
+
+// SetSidechainFilter enables/disables the detector high-pass filter, which
+// stops low frequencies from holding the expander open
+func (e *Expander) SetSidechainFilter(enabled bool, frequency float64) {
+	e.hpfEnabled = enabled
+	e.hpfFrequency = math.Max(20.0, math.Min(frequency, e.sampleRate*0.45))
+	e.hpf.SetFrequencyAndQ(e.sampleRate, e.hpfFrequency, 0.707)
+}
+
+// GetGainReduction returns the current gain reduction in dB, negative for
+// downward expansion and positive for an upward boost
 func (e *Expander) GetGainReduction() float64 {
 	return e.gainReduction
 }
@@ -95,18 +159,28 @@ func (e *Expander) updateTimeConstants() {
 
 	// Smoothing coefficients
 	if e.attack > 0 {
-		e.attackCoeff = math.Exp(-1.0 / (e.attack * e.sampleRate))
+		e.attackCoeff = coeff.TimeConstant(e.attack, e.sampleRate)
 	} else {
 		e.attackCoeff = 0.0
 	}
 
 	if e.release > 0 {
-		e.releaseCoeff = math.Exp(-1.0 / (e.release * e.sampleRate))
+		e.releaseCoeff = coeff.TimeConstant(e.release, e.sampleRate)
 	} else {
 		e.releaseCoeff = 0.0
 	}
 }
 
+// targetGain calculates the gain in dB for a level in the current
+// direction. Upward expansion is the downward curve mirrored around the
+// threshold and turned into a boost.
+func (e *Expander) targetGain(inputDB float64) float64 {
+	if e.direction == DirectionUpward {
+		return -e.computeGain(2*e.threshold - inputDB)
+	}
+	return e.computeGain(inputDB)
+}
+
 // computeGain calculates the gain for a given input level
 func (e *Expander) computeGain(inputDB float64) float64 {
 	// Above threshold: no expansion
@@ -120,12 +194,7 @@ func (e *Expander) computeGain(inputDB float64) float64 {
 		// This gives negative gain (reduction) for signals below threshold
 		gain := (inputDB - e.threshold) * (e.ratio - 1.0)
 
-		// Limit to range
-		if gain < e.range_ {
-			gain = e.range_
-		}
-
-		return gain
+		return e.limitRange(gain)
 	}
 
 	// In knee region: interpolate
@@ -138,37 +207,68 @@ func (e *Expander) computeGain(inputDB float64) float64 {
 		fullGain := (inputDB - e.threshold) * (e.ratio - 1.0)
 
 		// Quadratic interpolation
-		return kneePos * kneePos * fullGain
+		return e.limitRange(kneePos * kneePos * fullGain)
 	}
 
 	return 0.0
 }
 
-// Process processes a single sample
-func (e *Expander) Process(input float32) float32 {
-	// Get envelope
-	envelope := e.detector.Detect(input)
+// limitRange limits expansion to the range, with a quadratic knee of
+// rangeKnee dB that meets the range with zero slope
+func (e *Expander) limitRange(gain float64) float64 {
+	top := e.range_ + e.rangeKnee/2
+	switch {
+	case gain >= top:
+		return gain
+	case gain <= e.range_-e.rangeKnee/2:
+		return e.range_
+	default:
+		d := top - gain
+		return gain + d*d/(2*e.rangeKnee)
+	}
+}
 
-	// Convert to dB
-	inputDB := float64(-96.0)
+// detect returns the detector level in dB for a detection sample
+func (e *Expander) detect(input float32) float64 {
+	envelope := e.detector.Detect(input)
 	if envelope > 0 {
-		inputDB = 20.0 * math.Log10(float64(envelope))
+		return 20.0 * math.Log10(float64(envelope))
+	}
+	return -96.0
+}
+
+// filterDetection applies the optional detector high-pass to one channel
+func (e *Expander) filterDetection(input float32, channel int) float32 {
+	if !e.hpfEnabled {
+		return input
 	}
+	return e.hpf.ProcessSample(input, channel).Highpass
+}
 
+// updateGain moves the current gain toward the gain computed for a level,
+// observing hold, and returns it
+func (e *Expander) updateGain(inputDB float64) float32 {
 	// Calculate target gain
-	targetGainDB := e.computeGain(inputDB)
+	targetGainDB := e.targetGain(inputDB)
 	targetGain := math.Pow(10.0, targetGainDB/20.0)
 
 	// Smooth gain changes
-	if e.currentGain > targetGain {
-		// Decreasing gain (attack - expanding)
-		if e.attackCoeff == 0 {
+	expanding := e.currentGain > targetGain
+	if e.direction == DirectionUpward {
+		expanding = e.currentGain < targetGain
+	}
+	if expanding {
+		// Moving away from unity (attack - expanding), after the hold time
+		if e.holdCounter > 0 {
+			e.holdCounter--
+		} else if e.attackCoeff == 0 {
 			e.currentGain = targetGain
 		} else {
 			e.currentGain = targetGain + (e.currentGain-targetGain)*e.attackCoeff
 		}
 	} else {
-		// Increasing gain (release - returning to unity)
+		// Moving back toward unity (release)
+		e.holdCounter = e.holdSamples
 		if e.releaseCoeff == 0 {
 			e.currentGain = targetGain
 		} else {
@@ -177,14 +277,20 @@ func (e *Expander) Process(input float32) float32 {
 	}
 
 	// Update gain reduction for metering
-	if e.currentGain < 1.0 {
+	if e.currentGain != 1.0 {
 		e.gainReduction = 20.0 * math.Log10(e.currentGain)
 	} else {
 		e.gainReduction = 0.0
 	}
+	return float32(e.currentGain)
+}
 
-	// Apply gain
-	return input * float32(e.currentGain)
+// Process processes a single sample
+func (e *Expander) Process(input float32) float32 {
+	gain := e.updateGain(e.detect(e.filterDetection(input, 0)))
+
+	// Apply gain, blended with the dry signal
+	return input * parallelGain(e.mix, gain)
 }
 
 // ProcessBuffer processes a buffer of samples
@@ -197,46 +303,13 @@ func (e *Expander) ProcessBuffer(input, output []float32) {
 // ProcessStereo processes stereo buffers with linked expansion
 func (e *Expander) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
 	for i := range inputL {
-		// Use maximum of both channels for detection
-		maxInput := float32(math.Max(math.Abs(float64(inputL[i])), math.Abs(float64(inputR[i]))))
-
-		// Get envelope
-		envelope := e.detector.Detect(maxInput)
-
-		// Convert to dB
-		inputDB := float64(-96.0)
-		if envelope > 0 {
-			inputDB = 20.0 * math.Log10(float64(envelope))
-		}
-
-		// Calculate target gain
-		targetGainDB := e.computeGain(inputDB)
-		targetGain := math.Pow(10.0, targetGainDB/20.0)
-
-		// Smooth gain changes
-		if e.currentGain > targetGain {
-			if e.attackCoeff == 0 {
-				e.currentGain = targetGain
-			} else {
-				e.currentGain = targetGain + (e.currentGain-targetGain)*e.attackCoeff
-			}
-		} else {
-			if e.releaseCoeff == 0 {
-				e.currentGain = targetGain
-			} else {
-				e.currentGain = targetGain + (e.currentGain-targetGain)*e.releaseCoeff
-			}
-		}
-
-		// Update gain reduction
-		if e.currentGain < 1.0 {
-			e.gainReduction = 20.0 * math.Log10(e.currentGain)
-		} else {
-			e.gainReduction = 0.0
-		}
+		// Use maximum of both (filtered) channels for detection
+		detL := e.filterDetection(inputL[i], 0)
+		detR := e.filterDetection(inputR[i], 1)
+		maxInput := float32(math.Max(math.Abs(float64(detL)), math.Abs(float64(detR))))
 
 		// Apply same gain to both channels
-		gain := float32(e.currentGain)
+		gain := parallelGain(e.mix, e.updateGain(e.detect(maxInput)))
 		outputL[i] = inputL[i] * gain
 		outputR[i] = inputR[i] * gain
 	}
@@ -245,6 +318,8 @@ func (e *Expander) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
 // Reset resets the expander state
 func (e *Expander) Reset() {
 	e.detector.Reset()
+	e.hpf.Reset()
 	e.currentGain = 1.0
 	e.gainReduction = 0.0
+	e.holdCounter = 0
 }
@@ -253,3 +253,151 @@ func BenchmarkExpanderStereo(b *testing.B) {
 		e.ProcessStereo(inputL, inputR, outputL, outputR)
 	}
 }
+
+func TestExpanderHold(t *testing.T) {
+	sampleRate := 48000.0
+	e := NewExpander(sampleRate)
+	e.SetThreshold(-30.0)
+	e.SetRatio(4.0)
+	e.SetAttack(0.0)
+	e.SetRelease(0.0)
+	e.SetHold(0.005)
+
+	for i := 0; i < 100; i++ {
+		e.Process(0.5)
+	}
+	// Level drops: the gain stays at unity for the hold time
+	e.detector.Reset()
+	holdSamples := int(0.005 * sampleRate)
+	for i := 0; i < holdSamples; i++ {
+		if out := e.Process(0.001); out != 0.001 {
+			t.Fatalf("sample %d expanded during hold: %g", i, out)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		e.Process(0.001)
+	}
+	if e.GetGainReduction() > -10 {
+		t.Errorf("no expansion after hold: %g dB", e.GetGainReduction())
+	}
+}
+
+func TestExpanderRangeKnee(t *testing.T) {
+	e := NewExpander(48000.0)
+	e.SetThreshold(-20.0)
+	e.SetRatio(2.0)
+	e.SetKnee(0.0)
+	e.SetRange(-20.0)
+	e.SetRangeKnee(6.0)
+
+	// Continuous and monotonic through the knee, reaching the range exactly
+	prev := e.computeGain(-20.0)
+	for in := -20.1; in > -60; in -= 0.1 {
+		g := e.computeGain(in)
+		if g > prev+1e-9 || prev-g > 0.2+1e-9 {
+			t.Fatalf("gain jumps at %g dB input: %g -> %g", in, prev, g)
+		}
+		prev = g
+	}
+	if prev != -20.0 {
+		t.Errorf("deep gain %g, want the range", prev)
+	}
+	if g := e.computeGain(-36.0); math.Abs(g+16.0) > 1e-9 {
+		t.Errorf("above the knee gain %g, want -16", g)
+	}
+	if g := e.computeGain(-40.0); math.Abs(g+19.25) > 1e-9 {
+		t.Errorf("range knee midpoint %g", g)
+	}
+}
+
+func TestExpanderSidechainFilter(t *testing.T) {
+	sampleRate := 48000.0
+	run := func(filtered bool) float64 {
+		e := NewExpander(sampleRate)
+		e.SetThreshold(-30.0)
+		e.SetRatio(4.0)
+		e.SetSidechainFilter(filtered, 500)
+		// A loud 40 Hz signal
+		for i := 0; i < 48000; i++ {
+			e.Process(float32(0.5 * math.Sin(2*math.Pi*40*float64(i)/sampleRate)))
+		}
+		return e.GetGainReduction()
+	}
+	if gr := run(false); gr < -1 {
+		t.Errorf("unfiltered detector expanded a loud signal: %g dB", gr)
+	}
+	if gr := run(true); gr > -3 {
+		t.Errorf("filtered detector should ignore the bass: %g dB", gr)
+	}
+}
+
+func TestExpanderUpward(t *testing.T) {
+	e := NewExpander(48000.0)
+	e.SetThreshold(-20.0)
+	e.SetRatio(1.5)
+	e.SetKnee(0.0)
+	e.SetAttack(0.0)
+	e.SetRelease(0.0)
+	e.SetDirection(DirectionUpward)
+	if e.GetDirection() != DirectionUpward {
+		t.Fatal("upward direction not set")
+	}
+
+	// A level 10 dB over the threshold is raised by 5 dB
+	loud := float32(math.Pow(10, -10.0/20))
+	var output float32
+	for i := 0; i < 4800; i++ {
+		output = e.Process(loud)
+	}
+	if db := 20 * math.Log10(float64(output/loud)); math.Abs(db-5.0) > 0.1 {
+		t.Errorf("upward expansion %.2f dB, want 5", db)
+	}
+	if gr := e.GetGainReduction(); math.Abs(gr-5.0) > 0.1 {
+		t.Errorf("metered %.2f dB, want a 5 dB boost", gr)
+	}
+
+	// Signals below the threshold are left alone
+	quiet := float32(0.01)
+	for i := 0; i < 4800; i++ {
+		output = e.Process(quiet)
+	}
+	if math.Abs(float64(output-quiet)) > 1e-6 {
+		t.Errorf("upward expander changed a quiet signal: %g to %g", quiet, output)
+	}
+
+	// The range limits the boost
+	e.SetRange(-3.0)
+	for i := 0; i < 4800; i++ {
+		output = e.Process(loud)
+	}
+	if db := 20 * math.Log10(float64(output/loud)); math.Abs(db-3.0) > 0.1 {
+		t.Errorf("upward expansion %.2f dB with a 3 dB range", db)
+	}
+}
+
+func TestExpanderMix(t *testing.T) {
+	e := NewExpander(48000.0)
+	e.SetThreshold(-20.0)
+	e.SetRange(-20.0)
+	e.SetAttack(0.0)
+	e.SetRelease(0.0)
+	e.SetMix(0.5)
+	if e.GetMix() != 0.5 {
+		t.Fatalf("mix %g, want 0.5", e.GetMix())
+	}
+
+	// Half of a signal turned down 20 dB plus half of the dry signal
+	quiet := float32(0.001)
+	var output float32
+	for i := 0; i < 4800; i++ {
+		output = e.Process(quiet)
+	}
+	if want := quiet * 0.55; math.Abs(float64(output-want)) > 1e-5 {
+		t.Errorf("mixed output %g, want %g", output, want)
+	}
+
+	e.SetMix(0)
+	if output = e.Process(quiet); output != quiet {
+		t.Errorf("dry output %g, want the input %g", output, quiet)
+	}
+}
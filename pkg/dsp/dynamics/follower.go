@@ -0,0 +1,141 @@
+package dynamics
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/phase"
+)
+
+// FollowerMode selects how an EnvelopeFollower measures level
+type FollowerMode int
+
+const (
+	// FollowerPeak follows the rectified signal
+	FollowerPeak FollowerMode = iota
+	// FollowerRMS follows the mean square and reports its root
+	FollowerRMS
+	// FollowerHilbert follows the magnitude of the analytic signal, which
+	// has little ripple at low frequencies so release can be short
+	FollowerHilbert
+)
+
+// EnvelopeFollower tracks the level of a signal with separate attack and
+// release times. It is the detector the dynamics processors use, exposed
+// for building transient shapers, auto-wahs and sidechain duckers.
+type EnvelopeFollower struct {
+	sampleRate float64
+	mode       FollowerMode
+	attack     float64 // Seconds
+	release    float64 // Seconds
+
+	attackCoeff  float64
+	releaseCoeff float64
+
+	hilbert  *phase.Hilbert
+	envelope float64 // Level, or mean square in RMS mode
+}
+
+// NewEnvelopeFollower creates a peak follower with 1 ms attack and 100 ms
+// release
+func NewEnvelopeFollower(sampleRate float64) *EnvelopeFollower {
+	f := &EnvelopeFollower{
+		sampleRate: sampleRate,
+		mode:       FollowerPeak,
+		attack:     0.001,
+		release:    0.100,
+		hilbert:    phase.NewHilbert(),
+	}
+	f.updateCoefficients()
+	return f
+}
+
+// SetMode selects peak, RMS or Hilbert detection
+func (f *EnvelopeFollower) SetMode(mode FollowerMode) {
+	if mode != f.mode {
+		f.mode = mode
+		f.Reset()
+	}
+}
+
+// SetAttack sets the attack time in seconds (0 for instant)
+func (f *EnvelopeFollower) SetAttack(seconds float64) {
+	f.attack = math.Max(0.0, seconds)
+	f.updateCoefficients()
+}
+
+// SetRelease sets the release time in seconds (0 for instant)
+func (f *EnvelopeFollower) SetRelease(seconds float64) {
+	f.release = math.Max(0.0, seconds)
+	f.updateCoefficients()
+}
+
+// SetTimeConstants sets attack and release in one call
+func (f *EnvelopeFollower) SetTimeConstants(attack, release float64) {
+	f.attack = math.Max(0.0, attack)
+	f.release = math.Max(0.0, release)
+	f.updateCoefficients()
+}
+
+// updateCoefficients computes the one-pole coefficients; the envelope
+// covers 1-1/e of a step within the time constant
+func (f *EnvelopeFollower) updateCoefficients() {
+	f.attackCoeff = 0.0
+	if f.attack > 0 {
+		f.attackCoeff = coeff.TimeConstant(f.attack, f.sampleRate)
+	}
+	f.releaseCoeff = 0.0
+	if f.release > 0 {
+		f.releaseCoeff = coeff.TimeConstant(f.release, f.sampleRate)
+	}
+}
+
+// Process follows one sample and returns the envelope (linear)
+func (f *EnvelopeFollower) Process(input float32) float32 {
+	x := float64(input)
+	var level float64
+	switch f.mode {
+	case FollowerRMS:
+		level = x * x
+	case FollowerHilbert:
+		i, q := f.hilbert.Process(input)
+		level = math.Hypot(float64(i), float64(q))
+	default:
+		level = math.Abs(x)
+	}
+
+	coeff := f.releaseCoeff
+	if level > f.envelope {
+		coeff = f.attackCoeff
+	}
+	f.envelope = level + (f.envelope-level)*coeff
+
+	return f.Level()
+}
+
+// ProcessBuffer writes the envelope of input to output, which may be the
+// same slice
+func (f *EnvelopeFollower) ProcessBuffer(input, output []float32) {
+	for i := range input {
+		output[i] = f.Process(input[i])
+	}
+}
+
+// Level returns the current envelope (linear)
+func (f *EnvelopeFollower) Level() float32 {
+	if f.mode == FollowerRMS {
+		return float32(math.Sqrt(f.envelope))
+	}
+	return float32(f.envelope)
+}
+
+// LevelDB returns the current envelope in dB with a -96 dB floor
+func (f *EnvelopeFollower) LevelDB() float64 {
+	return levelDB(f.Level())
+}
+
+// Reset clears the envelope and detector state
+func (f *EnvelopeFollower) Reset() {
+	f.envelope = 0
+	f.hilbert.Reset()
+}
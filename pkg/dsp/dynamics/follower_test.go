@@ -0,0 +1,66 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEnvelopeFollowerModes(t *testing.T) {
+	const sr = 48000.0
+	tests := []struct {
+		mode FollowerMode
+		want float64 // Settled level of a unit sine
+		tol  float64 // Allowed ripple
+	}{
+		{FollowerPeak, 1, 0.05},
+		{FollowerRMS, 1 / math.Sqrt2, 0.02},
+		{FollowerHilbert, 1, 0.02},
+	}
+	for _, tt := range tests {
+		f := NewEnvelopeFollower(sr)
+		f.SetMode(tt.mode)
+		f.SetTimeConstants(0.001, 0.2)
+		if tt.mode == FollowerRMS {
+			f.SetTimeConstants(0.05, 0.05)
+		}
+		buffer := make([]float32, 48000)
+		for i := range buffer {
+			buffer[i] = float32(math.Sin(2 * math.Pi * 200 * float64(i) / sr))
+		}
+		f.ProcessBuffer(buffer, buffer)
+		for i := 24000; i < len(buffer); i++ {
+			if math.Abs(float64(buffer[i])-tt.want) > tt.tol {
+				t.Fatalf("mode %d: sample %d level %g, want %g", tt.mode, i, buffer[i], tt.want)
+			}
+		}
+	}
+}
+
+func TestEnvelopeFollowerTimeConstants(t *testing.T) {
+	const sr = 48000.0
+	f := NewEnvelopeFollower(sr)
+	f.SetAttack(0.010)
+	f.SetRelease(0.100)
+
+	// After one attack time a step reaches 1-1/e
+	var level float32
+	for i := 0; i < 480; i++ {
+		level = f.Process(1)
+	}
+	if math.Abs(float64(level)-(1-1/math.E)) > 0.01 {
+		t.Errorf("level after attack time %g", level)
+	}
+
+	// Instant attack and release follow the rectified input
+	f.SetTimeConstants(0, 0)
+	if got := f.Process(-0.5); got != 0.5 {
+		t.Errorf("instant follower %g", got)
+	}
+	if db := f.LevelDB(); math.Abs(db+6.02) > 0.01 {
+		t.Errorf("LevelDB %g", db)
+	}
+	f.Reset()
+	if f.Level() != 0 || f.LevelDB() != -96 {
+		t.Errorf("after reset: %g", f.Level())
+	}
+}
@@ -3,10 +3,14 @@ package dynamics
 import (
 	"math"
 
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
 	"github.com/justyntemme/vst3go/pkg/dsp/envelope"
 )
 
-// Gate implements a noise gate with hysteresis and smooth operation
+// Gate implements a noise gate with hysteresis and smooth operation. It
+// detects on its own input or, through the WithSidechain methods, on an
+// external key, and in ducking mode it inverts to attenuate while the key
+// is above the threshold.
 type Gate struct {
 	sampleRate float64
 
@@ -17,6 +21,9 @@ type Gate struct {
 	hold       float64 // Hold time in seconds
 	release    float64 // Release time in seconds
 	range_     float64 // Range in dB (max attenuation when closed)
+	rangeGain  float64 // Linear gain of the range
+	lookahead  float64 // Look-behind time in seconds
+	ducking    bool    // Attenuate while open instead of while closed
 
 	// Side-chain filter (optional)
 	hpfEnabled   bool
@@ -26,12 +33,21 @@ type Gate struct {
 	// Envelope detection (not currently used, using instant detection)
 	detector *envelope.Detector
 
-	// Gate state machine
-	state       gateState
-	holdCounter int
+	// Gate state machine; the embedded voice serves mono and linked stereo
+	gateVoice
+	rightVoice  gateVoice // Right channel while stereo is not fully linked
 	holdSamples int
-	currentGain float64
-	targetGain  float64
+
+	// Stereo link amount (0-1) and per-channel sidechain filter state
+	link             float64
+	channelLastInput [2]float32
+	channelHPFState  [2]float64
+
+	// Look-behind delay: detection runs ahead of the audio so the gate is
+	// already open when a transient reaches the output
+	delayBuffers [2][]float32
+	delayIndex   [2]int
+	delaySamples int
 
 	// Smooth gain transitions
 	attackCoeff  float64
@@ -39,10 +55,18 @@ type Gate struct {
 
 	// State
 	lastInput     float32
-	gateOpen      bool
 	gainReduction float64 // For metering
 }
 
+// gateVoice is the state machine and gain of one gated channel
+type gateVoice struct {
+	state       gateState
+	holdCounter int
+	currentGain float64
+	targetGain  float64
+	gateOpen    bool
+}
+
 // gateState represents the current state of the gate
 type gateState int
 
@@ -64,14 +88,13 @@ func NewGate(sampleRate float64) *Gate {
 		hold:       0.010, // 10ms hold
 		release:    0.100, // 100ms release
 		range_:     -80.0, // -80 dB range (practically mute)
-		state:      gateStateClosed,
+		rangeGain:  math.Pow(10.0, -80.0/20.0),
 		detector:   envelope.NewDetector(sampleRate, envelope.ModePeak),
+		link:       DefaultStereoLink,
 	}
 
 	// Initialize gain to closed state
-	g.currentGain = math.Pow(10.0, g.range_/20.0)
-	g.targetGain = g.currentGain
-	g.gainReduction = g.range_
+	g.closeVoices()
 
 	// Configure detector
 	g.detector.SetType(envelope.TypeLinear)
@@ -116,15 +139,100 @@ func (g *Gate) SetRelease(seconds float64) {
 // SetRange sets the gate range (max attenuation) in dB
 func (g *Gate) SetRange(dB float64) {
 	g.range_ = math.Min(0.0, dB) // Can't be positive
+	g.rangeGain = math.Pow(10.0, g.range_/20.0)
 
 	// Update current gain if gate is closed
+	closed := g.rangeGain
+	for _, v := range []*gateVoice{&g.gateVoice, &g.rightVoice} {
+		if v.state == gateStateClosed {
+			v.currentGain = closed
+			v.targetGain = closed
+		}
+	}
 	if g.state == gateStateClosed {
-		g.currentGain = math.Pow(10.0, g.range_/20.0)
-		g.targetGain = g.currentGain
-		g.gainReduction = g.range_
+		g.gainReduction = g.closedReduction()
+	}
+}
+
+// SetDucking inverts the gate: the signal is attenuated by the range while
+// the detector is above the threshold and recovers when it falls below.
+// Keyed from another bus this ducks one source under another, such as a
+// bass under the kick. Attack then sets how fast the signal ducks and
+// release how fast it recovers.
+func (g *Gate) SetDucking(enabled bool) {
+	g.ducking = enabled
+	if g.state == gateStateClosed {
+		g.gainReduction = g.closedReduction()
+	}
+}
+
+// IsDucking returns true if the gate is inverted
+func (g *Gate) IsDucking() bool {
+	return g.ducking
+}
+
+// applied maps a voice gain to the gain applied to the audio, inverting it
+// between the range and unity in ducking mode
+func (g *Gate) applied(gain float64) float64 {
+	if g.ducking {
+		return 1.0 + g.rangeGain - gain
+	}
+	return gain
+}
+
+// SetLookahead sets the look-behind time in seconds (0 to disable, max
+// 10ms). The audio is delayed by this amount while detection sees the
+// undelayed input, so the attack of a drum hit is not cut off. Report
+// GetLatencySamples to the host.
+func (g *Gate) SetLookahead(seconds float64) {
+	g.lookahead = math.Max(0.0, math.Min(0.010, seconds)) // Max 10ms
+	newDelaySamples := int(g.lookahead * g.sampleRate)
+
+	// Resize delay buffers if needed
+	if newDelaySamples != g.delaySamples {
+		g.delaySamples = newDelaySamples
+		for ch := range g.delayBuffers {
+			if g.delaySamples > 0 {
+				g.delayBuffers[ch] = make([]float32, g.delaySamples)
+			} else {
+				g.delayBuffers[ch] = nil
+			}
+			g.delayIndex[ch] = 0
+		}
 	}
 }
 
+// GetLatencySamples returns the latency added by the look-behind delay
+func (g *Gate) GetLatencySamples() int {
+	return g.delaySamples
+}
+
+// delay pushes input into a channel's look-behind buffer and returns the
+// delayed sample
+func (g *Gate) delay(channel int, input float32) float32 {
+	if g.delaySamples == 0 {
+		return input
+	}
+	buf := g.delayBuffers[channel]
+	idx := g.delayIndex[channel]
+	out := buf[idx]
+	buf[idx] = input
+	g.delayIndex[channel] = (idx + 1) % g.delaySamples
+	return out
+}
+
+// SetStereoLink sets how strongly ProcessStereo links the channels, from 0
+// (each channel gated on its own level) to 1 (both follow the louder
+// channel, the default)
+func (g *Gate) SetStereoLink(amount float64) {
+	g.link = clampLink(amount)
+}
+
+// GetStereoLink returns the stereo link amount (0-1)
+func (g *Gate) GetStereoLink() float64 {
+	return g.link
+}
+
 // SetSidechainFilter enables/disables the sidechain high-pass filter
 func (g *Gate) SetSidechainFilter(enabled bool, frequency float64) {
 	g.hpfEnabled = enabled
@@ -136,13 +244,13 @@ func (g *Gate) updateCoefficients() {
 	// Attack and release coefficients for smooth gain changes
 	// Using one-pole smoothing: coeff = exp(-1 / (time * sampleRate))
 	if g.attack > 0 {
-		g.attackCoeff = math.Exp(-1.0 / (g.attack * g.sampleRate))
+		g.attackCoeff = coeff.TimeConstant(g.attack, g.sampleRate)
 	} else {
 		g.attackCoeff = 0.0 // Instant attack
 	}
 
 	if g.release > 0 {
-		g.releaseCoeff = math.Exp(-1.0 / (g.release * g.sampleRate))
+		g.releaseCoeff = coeff.TimeConstant(g.release, g.sampleRate)
 	} else {
 		g.releaseCoeff = 0.0 // Instant release
 	}
@@ -150,6 +258,11 @@ func (g *Gate) updateCoefficients() {
 
 // applySidechainFilter applies optional high-pass filtering to the sidechain signal
 func (g *Gate) applySidechainFilter(input float32) float32 {
+	return g.filterSidechain(input, &g.lastInput, &g.hpfState)
+}
+
+// filterSidechain runs the sidechain high-pass with the given filter state
+func (g *Gate) filterSidechain(input float32, lastInput *float32, state *float64) float32 {
 	if !g.hpfEnabled {
 		return input
 	}
@@ -157,119 +270,129 @@ func (g *Gate) applySidechainFilter(input float32) float32 {
 	// Simple 1-pole high-pass filter
 	// H(z) = (1 - z^-1) / (1 - a*z^-1)
 	// Where a = exp(-2*pi*fc/fs)
-	a := math.Exp(-2.0 * math.Pi * g.hpfFrequency / g.sampleRate)
+	a := coeff.OnePole(g.hpfFrequency, g.sampleRate)
 
 	// Difference equation: y[n] = (1+a)/2 * (x[n] - x[n-1]) + a*y[n-1]
-	output := float32((1+a)/2)*(input-g.lastInput) + float32(a)*float32(g.hpfState)
+	output := float32((1+a)/2)*(input-*lastInput) + float32(a)*float32(*state)
 
-	g.lastInput = input
-	g.hpfState = float64(output)
+	*lastInput = input
+	*state = float64(output)
 
 	return output
 }
 
 // Process processes a single sample
 func (g *Gate) Process(input float32) float32 {
+	return g.processKeyed(input, input)
+}
+
+// processKeyed gates one sample on the level of key
+func (g *Gate) processKeyed(input, key float32) float32 {
 	// Apply sidechain filter if enabled
-	detection := g.applySidechainFilter(input)
+	detection := g.applySidechainFilter(key)
 
 	// Get envelope - for gate, we want fast detection
 	envelope := float32(math.Abs(float64(detection)))
 
-	// Convert to dB
-	inputDB := float64(-96.0)
-	if envelope > 0 {
-		inputDB = 20.0 * math.Log10(float64(envelope))
-	}
+	gain := g.applied(g.step(&g.gateVoice, levelDB(envelope)))
+	g.updateGainReduction(gain)
+
+	// Apply gain to the delayed signal
+	return g.delay(0, input) * float32(gain)
+}
 
+// step advances the state machine and gain smoothing of one voice for a
+// detector level in dB and returns the voice's gain
+func (g *Gate) step(v *gateVoice, inputDB float64) float64 {
 	// State machine logic
-	switch g.state {
+	switch v.state {
 	case gateStateClosed:
 		if inputDB > g.threshold {
 			// Open gate
-			g.state = gateStateAttack
-			g.targetGain = 1.0
+			v.state = gateStateAttack
+			v.targetGain = 1.0
 		}
 
 	case gateStateAttack:
-		if g.currentGain >= 0.99 {
+		if v.currentGain >= 0.99 {
 			// Fully open
-			g.state = gateStateOpen
-			g.gateOpen = true
+			v.state = gateStateOpen
+			v.gateOpen = true
 		} else if inputDB < g.threshold-g.hysteresis {
 			// Signal dropped during attack, start closing
-			g.state = gateStateRelease
-			g.targetGain = math.Pow(10.0, g.range_/20.0)
+			v.state = gateStateRelease
+			v.targetGain = math.Pow(10.0, g.range_/20.0)
 		}
 
 	case gateStateOpen:
 		if inputDB < g.threshold-g.hysteresis {
 			// Start hold period
-			g.state = gateStateHold
-			g.holdCounter = g.holdSamples
+			v.state = gateStateHold
+			v.holdCounter = g.holdSamples
 		}
 
 	case gateStateHold:
 		if inputDB > g.threshold-g.hysteresis {
 			// Signal came back up, stay open
-			g.state = gateStateOpen
-		} else if g.holdCounter > 0 {
-			g.holdCounter--
+			v.state = gateStateOpen
+		} else if v.holdCounter > 0 {
+			v.holdCounter--
 		} else {
 			// Hold period expired, start closing
-			g.state = gateStateRelease
-			g.targetGain = math.Pow(10.0, g.range_/20.0)
-			g.gateOpen = false
+			v.state = gateStateRelease
+			v.targetGain = math.Pow(10.0, g.range_/20.0)
+			v.gateOpen = false
 		}
 
 	case gateStateRelease:
 		if inputDB > g.threshold {
 			// Signal came back up, reopen
-			g.state = gateStateAttack
-			g.targetGain = 1.0
-		} else if g.currentGain <= g.targetGain*1.01 {
+			v.state = gateStateAttack
+			v.targetGain = 1.0
+		} else if v.currentGain <= v.targetGain*1.01 {
 			// Fully closed
-			g.state = gateStateClosed
+			v.state = gateStateClosed
 		}
 	}
 
 	// Smooth gain transitions
-	if g.currentGain < g.targetGain {
+	if v.currentGain < v.targetGain {
 		// Opening (attack)
 		if g.attackCoeff == 0 {
-			g.currentGain = g.targetGain // Instant
+			v.currentGain = v.targetGain // Instant
 		} else {
-			g.currentGain = g.targetGain + (g.currentGain-g.targetGain)*g.attackCoeff
+			v.currentGain = v.targetGain + (v.currentGain-v.targetGain)*g.attackCoeff
 		}
-	} else if g.currentGain > g.targetGain {
+	} else if v.currentGain > v.targetGain {
 		// Closing (release)
 		if g.releaseCoeff == 0 {
-			g.currentGain = g.targetGain // Instant
+			v.currentGain = v.targetGain // Instant
 		} else {
-			g.currentGain = g.targetGain + (g.currentGain-g.targetGain)*g.releaseCoeff
+			v.currentGain = v.targetGain + (v.currentGain-v.targetGain)*g.releaseCoeff
 		}
 	}
 
 	// Check if we've reached open state after gain update
-	if g.state == gateStateAttack && g.currentGain >= 0.99 {
-		g.state = gateStateOpen
-		g.gateOpen = true
-	} else if g.state == gateStateRelease && g.currentGain <= g.targetGain*1.01 {
-		g.state = gateStateClosed
+	if v.state == gateStateAttack && v.currentGain >= 0.99 {
+		v.state = gateStateOpen
+		v.gateOpen = true
+	} else if v.state == gateStateRelease && v.currentGain <= v.targetGain*1.01 {
+		v.state = gateStateClosed
 	}
 
-	// Calculate gain reduction for metering
-	if g.currentGain > 0 {
-		g.gainReduction = 20.0 * math.Log10(g.currentGain)
+	return v.currentGain
+}
+
+// updateGainReduction calculates gain reduction for metering
+func (g *Gate) updateGainReduction(gain float64) {
+	if gain > 0 {
+		g.gainReduction = 20.0 * math.Log10(gain)
 		if g.gainReduction > -0.1 {
 			g.gainReduction = 0.0
 		}
 	} else {
 		g.gainReduction = g.range_
 	}
-
-	// Apply gain
-	return input * float32(g.currentGain)
 }
 
 // ProcessBuffer processes a buffer of samples
@@ -279,105 +402,64 @@ func (g *Gate) ProcessBuffer(input, output []float32) {
 	}
 }
 
-// ProcessStereo processes stereo buffers with linked gating
+// ProcessWithSidechain gates input on the level of an external sidechain
+// signal, through the sidechain filter, instead of its own
+func (g *Gate) ProcessWithSidechain(input, sidechain, output []float32) {
+	for i := range input {
+		output[i] = g.processKeyed(input[i], sidechain[i])
+	}
+}
+
+// ProcessStereo processes stereo buffers with linked gating. Below full
+// link each channel runs its own state machine on a blend of its own and
+// the linked level.
 func (g *Gate) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
 	for i := range inputL {
-		// Use maximum of both channels for detection
-		maxInput := float32(math.Max(math.Abs(float64(inputL[i])), math.Abs(float64(inputR[i]))))
-
-		// Apply sidechain filter
-		detection := g.applySidechainFilter(maxInput)
-
-		// Get envelope - for gate, we want fast detection
-		envelope := float32(math.Abs(float64(detection)))
-
-		// Convert to dB
-		inputDB := float64(-96.0)
-		if envelope > 0 {
-			inputDB = 20.0 * math.Log10(float64(envelope))
-		}
-
-		// Run state machine (same as Process method)
-		switch g.state {
-		case gateStateClosed:
-			if inputDB > g.threshold {
-				g.state = gateStateAttack
-				g.targetGain = 1.0
-			}
-
-		case gateStateAttack:
-			if g.currentGain >= 0.99 {
-				g.state = gateStateOpen
-				g.gateOpen = true
-			} else if inputDB < g.threshold-g.hysteresis {
-				g.state = gateStateRelease
-				g.targetGain = math.Pow(10.0, g.range_/20.0)
-			}
-
-		case gateStateOpen:
-			if inputDB < g.threshold-g.hysteresis {
-				g.state = gateStateHold
-				g.holdCounter = g.holdSamples
-			}
+		outputL[i], outputR[i] = g.processStereoKeyed(inputL[i], inputR[i], inputL[i], inputR[i])
+	}
+}
 
-		case gateStateHold:
-			if inputDB > g.threshold-g.hysteresis {
-				g.state = gateStateOpen
-			} else if g.holdCounter > 0 {
-				g.holdCounter--
-			} else {
-				g.state = gateStateRelease
-				g.targetGain = math.Pow(10.0, g.range_/20.0)
-				g.gateOpen = false
-			}
+// ProcessStereoWithSidechain gates stereo buffers on a stereo sidechain,
+// linked as in ProcessStereo: the left key drives the left channel and the
+// right key the right one, blended by the stereo link. Pass the same
+// buffer twice for a mono key.
+func (g *Gate) ProcessStereoWithSidechain(inputL, inputR, sidechainL, sidechainR, outputL, outputR []float32) {
+	for i := range inputL {
+		outputL[i], outputR[i] = g.processStereoKeyed(inputL[i], inputR[i], sidechainL[i], sidechainR[i])
+	}
+}
 
-		case gateStateRelease:
-			if inputDB > g.threshold {
-				g.state = gateStateAttack
-				g.targetGain = 1.0
-			} else if g.currentGain <= g.targetGain*1.01 {
-				g.state = gateStateClosed
-			}
-		}
+// processStereoKeyed gates one stereo frame on the level of the key frame
+func (g *Gate) processStereoKeyed(inputL, inputR, keyL, keyR float32) (float32, float32) {
+	// Use maximum of both channels for detection
+	maxKey := float32(math.Max(math.Abs(float64(keyL)), math.Abs(float64(keyR))))
 
-		// Smooth gain transitions
-		if g.currentGain < g.targetGain {
-			if g.attackCoeff == 0 {
-				g.currentGain = g.targetGain
-			} else {
-				g.currentGain = g.targetGain + (g.currentGain-g.targetGain)*g.attackCoeff
-			}
-		} else if g.currentGain > g.targetGain {
-			if g.releaseCoeff == 0 {
-				g.currentGain = g.targetGain
-			} else {
-				g.currentGain = g.targetGain + (g.currentGain-g.targetGain)*g.releaseCoeff
-			}
-		}
+	// Apply sidechain filter
+	detection := g.applySidechainFilter(maxKey)
+	linkedDB := levelDB(float32(math.Abs(float64(detection))))
 
-		// Check if we've reached open state after gain update
-		if g.state == gateStateAttack && g.currentGain >= 0.99 {
-			g.state = gateStateOpen
-			g.gateOpen = true
-		} else if g.state == gateStateRelease && g.currentGain <= g.targetGain*1.01 {
-			g.state = gateStateClosed
-		}
+	// Per-channel detection keeps running so the link can change smoothly
+	ownL := g.filterSidechain(keyL, &g.channelLastInput[0], &g.channelHPFState[0])
+	ownR := g.filterSidechain(keyR, &g.channelLastInput[1], &g.channelHPFState[1])
 
-		// Update gain reduction
-		if g.currentGain > 0 {
-			g.gainReduction = 20.0 * math.Log10(g.currentGain)
-			if g.gainReduction > -0.1 {
-				g.gainReduction = 0.0
-			}
-		} else {
-			g.gainReduction = g.range_
-		}
+	// Gain is applied to the delayed signal when look-behind is on
+	inL := g.delay(0, inputL)
+	inR := g.delay(1, inputR)
 
+	if g.link >= 1 {
 		// Apply same gain to both channels
-		gain := float32(g.currentGain)
-		outputL[i] = inputL[i] * gain
-		outputR[i] = inputR[i] * gain
+		gain := g.applied(g.step(&g.gateVoice, linkedDB))
+		g.rightVoice = g.gateVoice
+		g.updateGainReduction(gain)
+		return inL * float32(gain), inR * float32(gain)
 	}
+
+	levelL := linkLevel(g.link, linkedDB, levelDB(float32(math.Abs(float64(ownL)))))
+	levelR := linkLevel(g.link, linkedDB, levelDB(float32(math.Abs(float64(ownR)))))
+	gainL := g.applied(g.step(&g.gateVoice, levelL))
+	gainR := g.applied(g.step(&g.rightVoice, levelR))
+	g.updateGainReduction(math.Min(gainL, gainR))
+	return inL * float32(gainL), inR * float32(gainR)
 }
 
 // GetGainReduction returns the current gain reduction in dB
@@ -385,9 +467,9 @@ func (g *Gate) GetGainReduction() float64 {
 	return g.gainReduction
 }
 
-// IsOpen returns true if the gate is currently open
+// IsOpen returns true if the gate is currently open on either channel
 func (g *Gate) IsOpen() bool {
-	return g.gateOpen
+	return g.gateOpen || g.rightVoice.gateOpen
 }
 
 // GetState returns the current gate state for debugging
@@ -411,12 +493,33 @@ func (g *Gate) GetState() string {
 // Reset resets the gate state
 func (g *Gate) Reset() {
 	g.detector.Reset()
-	g.state = gateStateClosed
-	g.currentGain = math.Pow(10.0, g.range_/20.0)
-	g.targetGain = g.currentGain
-	g.holdCounter = 0
-	g.gateOpen = false
-	g.gainReduction = g.range_
+	g.closeVoices()
 	g.hpfState = 0.0
 	g.lastInput = 0.0
+	g.channelHPFState = [2]float64{}
+	g.channelLastInput = [2]float32{}
+
+	// Clear delay buffers
+	for ch := range g.delayBuffers {
+		for i := range g.delayBuffers[ch] {
+			g.delayBuffers[ch][i] = 0
+		}
+		g.delayIndex[ch] = 0
+	}
+}
+
+// closeVoices puts both voices in the fully closed state
+func (g *Gate) closeVoices() {
+	closed := math.Pow(10.0, g.range_/20.0)
+	g.gateVoice = gateVoice{state: gateStateClosed, currentGain: closed, targetGain: closed}
+	g.rightVoice = g.gateVoice
+	g.gainReduction = g.closedReduction()
+}
+
+// closedReduction is the gain reduction in dB while the gate is closed
+func (g *Gate) closedReduction() float64 {
+	if g.ducking {
+		return 0.0
+	}
+	return g.range_
 }
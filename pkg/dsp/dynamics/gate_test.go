@@ -329,6 +329,161 @@ func TestGateStates(t *testing.T) {
 	}
 }
 
+func TestGateLookahead(t *testing.T) {
+	sampleRate := 48000.0
+	g := NewGate(sampleRate)
+	g.SetThreshold(-30.0)
+	g.SetAttack(0.0005)
+
+	if g.GetLatencySamples() != 0 {
+		t.Errorf("Latency should be 0 without look-behind, got %d", g.GetLatencySamples())
+	}
+
+	g.SetLookahead(0.002)
+	latency := g.GetLatencySamples()
+	if latency != 96 {
+		t.Fatalf("Latency should be 96 samples, got %d", latency)
+	}
+
+	// Silence followed by a drum-like step
+	input := make([]float32, 400)
+	for i := 200; i < len(input); i++ {
+		input[i] = 0.5
+	}
+	output := make([]float32, len(input))
+	g.ProcessBuffer(input, output)
+
+	// Nothing may arrive before the delayed hit
+	for i := 0; i < 200+latency; i++ {
+		if output[i] != 0 {
+			t.Fatalf("Output %d should be silent, got %f", i, output[i])
+		}
+	}
+
+	// The first sample of the hit passes nearly unattenuated
+	if output[200+latency] < 0.45 {
+		t.Errorf("First sample of the hit was clipped: got %f, want ~0.5", output[200+latency])
+	}
+
+	// Without look-behind the same hit starts attenuated
+	plain := NewGate(sampleRate)
+	plain.SetThreshold(-30.0)
+	plain.SetAttack(0.0005)
+	plain.ProcessBuffer(input, output)
+	if output[200] >= 0.45 {
+		t.Errorf("Expected attenuated onset without look-behind, got %f", output[200])
+	}
+}
+
+func TestGateLookaheadStereo(t *testing.T) {
+	g := NewGate(48000.0)
+	g.SetThreshold(-30.0)
+	g.SetAttack(0.0005)
+	g.SetLookahead(0.002)
+	g.SetStereoLink(0.5)
+	latency := g.GetLatencySamples()
+
+	inputL := make([]float32, 400)
+	inputR := make([]float32, 400)
+	for i := 200; i < len(inputL); i++ {
+		inputL[i] = 0.5
+		inputR[i] = -0.5
+	}
+	outputL := make([]float32, 400)
+	outputR := make([]float32, 400)
+	g.ProcessStereo(inputL, inputR, outputL, outputR)
+
+	if outputL[200+latency-1] != 0 || outputR[200+latency-1] != 0 {
+		t.Error("Hit arrived before the reported latency")
+	}
+	if outputL[200+latency] < 0.45 || outputR[200+latency] > -0.45 {
+		t.Errorf("Stereo hit was clipped: L=%f R=%f", outputL[200+latency], outputR[200+latency])
+	}
+
+	// Reset clears the delayed audio
+	g.Reset()
+	g.ProcessStereo(make([]float32, 10), make([]float32, 10), outputL[:10], outputR[:10])
+	for i := 0; i < 10; i++ {
+		if outputL[i] != 0 || outputR[i] != 0 {
+			t.Fatal("Delay buffers not cleared by Reset")
+		}
+	}
+}
+
+func TestGateExternalSidechain(t *testing.T) {
+	g := NewGate(48000.0)
+	g.SetThreshold(-20.0)
+	g.SetHold(0.0)
+	g.SetRelease(0.001)
+
+	// A loud input with a silent key stays closed
+	input := make([]float32, 480)
+	for i := range input {
+		input[i] = 0.5
+	}
+	key := make([]float32, len(input))
+	output := make([]float32, len(input))
+	g.ProcessWithSidechain(input, key, output)
+	if output[len(output)-1] > 0.001 {
+		t.Errorf("Gate opened without a key: output %f", output[len(output)-1])
+	}
+
+	// A loud key opens it for a quiet input
+	for i := range input {
+		input[i] = 0.01
+		key[i] = 0.5
+	}
+	g.ProcessWithSidechain(input, key, output)
+	if math.Abs(float64(output[len(output)-1]-0.01)) > 0.001 {
+		t.Errorf("Keyed gate output %f, want the input passed", output[len(output)-1])
+	}
+	if !g.IsOpen() {
+		t.Error("Expected the keyed gate to be open")
+	}
+}
+
+func TestGateDucking(t *testing.T) {
+	g := NewGate(48000.0)
+	g.SetThreshold(-20.0)
+	g.SetRange(-12.0)
+	g.SetHold(0.0)
+	g.SetRelease(0.005)
+	g.SetDucking(true)
+	if !g.IsDucking() {
+		t.Fatal("Expected ducking mode")
+	}
+	if g.GetGainReduction() != 0 {
+		t.Errorf("Closed ducker reduces by %f dB, want 0", g.GetGainReduction())
+	}
+
+	n := 4800
+	bass := make([]float32, n)
+	kick := make([]float32, n)
+	for i := range bass {
+		bass[i] = 0.5
+		if i < n/2 {
+			kick[i] = 0.8
+		}
+	}
+	outL := make([]float32, n)
+	outR := make([]float32, n)
+	g.ProcessStereoWithSidechain(bass, bass, kick, kick, outL, outR)
+
+	// Ducked by the range while the kick sounds
+	ducked := 0.5 * math.Pow(10, -12.0/20)
+	if math.Abs(float64(outL[n/2-1])-ducked) > 0.01 || outL[n/2-1] != outR[n/2-1] {
+		t.Errorf("Ducked output %f/%f, want %f", outL[n/2-1], outR[n/2-1], ducked)
+	}
+
+	// Recovered after it
+	if math.Abs(float64(outL[n-1])-0.5) > 0.01 {
+		t.Errorf("Recovered output %f, want 0.5", outL[n-1])
+	}
+	if gr := g.GetGainReduction(); gr != 0 {
+		t.Errorf("Gain reduction %f dB after recovery, want 0", gr)
+	}
+}
+
 // Benchmark gate processing
 func BenchmarkGate(b *testing.B) {
 	g := NewGate(48000.0)
@@ -3,72 +3,160 @@ package dynamics
 import (
 	"math"
 
-	"github.com/justyntemme/vst3go/pkg/dsp/envelope"
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
 )
 
-// Limiter implements a brick-wall limiter with optional true peak detection
+// Default dual-stage release settings
+const (
+	DefaultLimiterSlowRelease = 0.5 // Slow release stage in seconds
+	limiterSlowAttack         = 0.1 // Time for sustained limiting to charge the slow stage
+)
+
+// Limiter implements a brick-wall lookahead limiter with optional true peak
+// detection. All channels share one gain computer, so with the default full
+// stereo link a peak on any channel turns every channel down by the same
+// amount and the image does not shift.
+//
+// The release runs in two stages: a fast stage set by SetRelease recovers
+// quickly from isolated peaks, while a slow stage charges up only under
+// sustained limiting and holds the gain steady, so dense material does not
+// pump.
 type Limiter struct {
 	sampleRate float64
+	channels   int
 
 	// Parameters
-	threshold float64 // Ceiling threshold in dB
-	release   float64 // Release time in seconds
-	lookahead float64 // Lookahead time in seconds
-	truePeak  bool    // Enable true peak detection
-
-	// Envelope detection
-	detector     *envelope.Detector
-	peakDetector *envelope.Detector // For true peak detection
-
-	// Lookahead delay
-	delayBuffer  []float32
+	threshold   float64 // Ceiling threshold in dB
+	release     float64 // Fast release time in seconds
+	slowRelease float64 // Slow release time in seconds, 0 disables the stage
+	lookahead   float64 // Lookahead time in seconds
+	truePeak    bool    // Enable true peak detection
+	link        float64 // Stereo link amount, 0-1
+
+	// Per-channel state; the embedded channel is channel 0
+	limiterChannel
+	others []limiterChannel
+
+	// Lookahead delay, shared position for all channels
 	delayIndex   int
 	delaySamples int
 
-	// True peak oversampling (simple 2x for now)
-	lastSample float32
+	// Per-frame scratch so processing does not allocate
+	frame  []float32
+	levels []float64
+
+	// Gain computer coefficients
+	fastReleaseCoeff float64
+	slowReleaseCoeff float64
+	slowAttackCoeff  float64
 
 	// State
-	gainReduction float64 // Current gain reduction in dB
+	gainReduction  float64 // Current gain reduction in dB
+	blockReduction float64 // Largest gain reduction in the current block
 }
 
-// NewLimiter creates a new brick-wall limiter
+// limiterChannel is the delay line, true peak state and gain computer
+// state of one channel
+type limiterChannel struct {
+	delayBuffer []float32
+	lastSample  float32 // Previous input for true peak estimation
+
+	held      slidingMax // Gain reduction held over the lookahead window
+	fastGR    float64    // Fast release stage in dB
+	slowGR    float64    // Slow release stage in dB
+	appliedGR float64    // Larger of the two stages
+}
+
+// NewLimiter creates a brick-wall limiter for mono (Process) or stereo
+// (ProcessStereo) signals
 func NewLimiter(sampleRate float64) *Limiter {
+	return NewLimiterN(sampleRate, 2)
+}
+
+// NewLimiterN creates a brick-wall limiter for any number of channels
+// processed together with ProcessChannels
+func NewLimiterN(sampleRate float64, channels int) *Limiter {
+	channels = max(1, channels)
 	l := &Limiter{
-		sampleRate:   sampleRate,
-		threshold:    -0.3,  // -0.3 dB default ceiling
-		release:      0.050, // 50ms default release
-		lookahead:    0.005, // 5ms default lookahead
-		truePeak:     true,  // True peak detection enabled by default
-		detector:     envelope.NewDetector(sampleRate, envelope.ModePeak),
-		peakDetector: envelope.NewDetector(sampleRate, envelope.ModePeak),
-	}
-
-	// Configure main detector for limiting (very fast attack)
-	l.detector.SetType(envelope.TypeLinear)
-	l.detector.SetAttack(0.0001) // 0.1ms attack
-	l.detector.SetRelease(l.release)
-
-	// Configure peak detector for instant response
-	l.peakDetector.SetType(envelope.TypeLinear)
-	l.peakDetector.SetAttack(0.0)    // Instant
-	l.peakDetector.SetRelease(0.001) // 1ms
-
-	// Initialize lookahead
-	l.updateLookahead()
+		sampleRate:  sampleRate,
+		channels:    channels,
+		threshold:   -0.3,  // -0.3 dB default ceiling
+		release:     0.050, // 50ms default release
+		slowRelease: DefaultLimiterSlowRelease,
+		lookahead:   0.005, // 5ms default lookahead
+		truePeak:    true,  // True peak detection enabled by default
+		link:        DefaultStereoLink,
+	}
 
+	// ProcessStereo is always available, so keep at least two channels
+	states := max(2, channels)
+	l.others = make([]limiterChannel, states-1)
+	l.frame = make([]float32, states)
+	l.levels = make([]float64, states)
+
+	l.updateCoefficients()
+	l.updateLookahead()
 	return l
 }
 
+// Channels returns the number of channels ProcessChannels expects
+func (l *Limiter) Channels() int {
+	return l.channels
+}
+
+// channel returns the state of channel ch
+func (l *Limiter) channel(ch int) *limiterChannel {
+	if ch == 0 {
+		return &l.limiterChannel
+	}
+	return &l.others[ch-1]
+}
+
 // SetThreshold sets the limiter ceiling in dB
 func (l *Limiter) SetThreshold(dB float64) {
 	l.threshold = math.Min(0.0, dB) // Can't be positive
 }
 
-// SetRelease sets the release time in seconds
+// SetRelease sets the fast release time in seconds
 func (l *Limiter) SetRelease(seconds float64) {
 	l.release = math.Max(0.001, seconds)
-	l.detector.SetRelease(l.release)
+	l.updateCoefficients()
+}
+
+// SetSlowRelease sets the slow release stage in seconds. The slow stage
+// only builds up under sustained limiting; 0 disables it, leaving a single
+// release stage.
+func (l *Limiter) SetSlowRelease(seconds float64) {
+	l.slowRelease = math.Max(0.0, seconds)
+	l.updateCoefficients()
+}
+
+// GetSlowRelease returns the slow release time in seconds
+func (l *Limiter) GetSlowRelease() float64 {
+	return l.slowRelease
+}
+
+// updateCoefficients recomputes the release stage coefficients
+func (l *Limiter) updateCoefficients() {
+	l.fastReleaseCoeff = coeff.TimeConstant(l.release, l.sampleRate)
+	l.slowAttackCoeff = coeff.TimeConstant(limiterSlowAttack, l.sampleRate)
+	l.slowReleaseCoeff = 0
+	if l.slowRelease > 0 {
+		l.slowReleaseCoeff = coeff.TimeConstant(l.slowRelease, l.sampleRate)
+	}
+}
+
+// SetStereoLink sets how strongly the channels are linked, from 0 (each
+// channel limited on its own level) to 1 (all follow the loudest channel,
+// the default). No channel exceeds the ceiling at any setting.
+func (l *Limiter) SetStereoLink(amount float64) {
+	l.link = clampLink(amount)
+}
+
+// GetStereoLink returns the stereo link amount (0-1)
+func (l *Limiter) GetStereoLink() float64 {
+	return l.link
 }
 
 // SetLookahead sets the lookahead time in seconds
@@ -82,17 +170,26 @@ func (l *Limiter) SetTruePeak(enabled bool) {
 	l.truePeak = enabled
 }
 
-// updateLookahead updates the lookahead buffer
+// GetLatencySamples returns the lookahead delay in samples
+func (l *Limiter) GetLatencySamples() int {
+	return l.delaySamples
+}
+
+// updateLookahead updates the lookahead buffers
 func (l *Limiter) updateLookahead() {
 	newDelaySamples := int(l.lookahead * l.sampleRate)
 
 	if newDelaySamples != l.delaySamples {
 		l.delaySamples = newDelaySamples
-		if l.delaySamples > 0 {
-			l.delayBuffer = make([]float32, l.delaySamples)
-			l.delayIndex = 0
-		} else {
-			l.delayBuffer = nil
+		l.delayIndex = 0
+		for ch := 0; ch <= len(l.others); ch++ {
+			c := l.channel(ch)
+			if l.delaySamples > 0 {
+				c.delayBuffer = make([]float32, l.delaySamples)
+			} else {
+				c.delayBuffer = nil
+			}
+			c.held = newSlidingMax(l.delaySamples + 1)
 		}
 	}
 }
@@ -102,136 +199,214 @@ func (l *Limiter) GetGainReduction() float64 {
 	return l.gainReduction
 }
 
+// GetMaxGainReduction returns the largest gain reduction in dB during the
+// last ProcessBuffer, ProcessStereo or ProcessChannels call, so a meter
+// updated once per block still shows short peaks. Process accumulates into
+// the current block.
+func (l *Limiter) GetMaxGainReduction() float64 {
+	return l.blockReduction
+}
+
+// beginBlock starts a new metering block
+func (l *Limiter) beginBlock() {
+	l.blockReduction = 0
+}
+
 // estimateTruePeak estimates the true peak using simple linear interpolation
-func (l *Limiter) estimateTruePeak(current float32) float32 {
+func (c *limiterChannel) estimateTruePeak(current float32) float32 {
 	// Simple 2x oversampling estimation
 	// Interpolate between last and current sample
-	midSample := (l.lastSample + current) * 0.5
+	midSample := (c.lastSample + current) * 0.5
 
 	// Find peak among last, mid, and current
-	peak := float32(math.Max(math.Abs(float64(l.lastSample)), math.Abs(float64(current))))
+	peak := float32(math.Max(math.Abs(float64(c.lastSample)), math.Abs(float64(current))))
 	peak = float32(math.Max(float64(peak), math.Abs(float64(midSample))))
 
-	l.lastSample = current
+	c.lastSample = current
 	return peak
 }
 
-// Process processes a single sample
-func (l *Limiter) Process(input float32) float32 {
-	// Detection signal (with true peak if enabled)
-	detectionSignal := input
+// detect returns the detection level of one input sample in dB
+func (l *Limiter) detect(c *limiterChannel, input float32) float64 {
+	peak := float32(math.Abs(float64(input)))
 	if l.truePeak {
-		detectionSignal = l.estimateTruePeak(input)
+		peak = c.estimateTruePeak(input)
 	}
+	return levelDB(peak)
+}
 
-	// Handle lookahead
-	processSignal := input
-	if l.delaySamples > 0 && l.delayBuffer != nil {
-		// Get delayed signal
-		processSignal = l.delayBuffer[l.delayIndex]
-
-		// Store current input
-		l.delayBuffer[l.delayIndex] = input
-		l.delayIndex = (l.delayIndex + 1) % l.delaySamples
+// computeGain runs the shared gain computer for a detection level in dB
+// and returns the linear gain. Attack is instant and held for the
+// lookahead window so the delayed peak is reduced before it leaves.
+func (l *Limiter) computeGain(c *limiterChannel, levelDB float64) float32 {
+	held := c.held.push(math.Max(0, levelDB-l.threshold))
+
+	// Fast stage: instant attack, short release
+	if held > c.fastGR {
+		c.fastGR = held
+	} else {
+		c.fastGR = held + (c.fastGR-held)*l.fastReleaseCoeff
+	}
 
-		// For true peak detection in lookahead mode,
-		// we need to check the peak of the delayed signal too
-		if l.truePeak {
-			// Use peak detector for instant response
-			detectionSignal = float32(math.Max(float64(detectionSignal),
-				math.Abs(float64(l.peakDetector.Detect(processSignal)))))
+	// Slow stage: charges under sustained limiting, lets go slowly
+	if l.slowRelease > 0 {
+		coeff := l.slowReleaseCoeff
+		if held > c.slowGR {
+			coeff = l.slowAttackCoeff
 		}
+		c.slowGR = held + (c.slowGR-held)*coeff
 	}
 
-	// Get envelope
-	envelope := l.detector.Detect(detectionSignal)
+	c.appliedGR = math.Max(c.fastGR, c.slowGR)
+	l.gainReduction = c.appliedGR
+	l.blockReduction = math.Max(l.blockReduction, c.appliedGR)
+	return fastmath.High.DbToLinear(float32(-c.appliedGR))
+}
 
-	// Convert to dB
-	inputDB := float64(-96.0)
-	if envelope > 0 {
-		inputDB = 20.0 * math.Log10(float64(envelope))
+// delay stores input in a channel's lookahead line and returns the sample
+// leaving it; the caller advances the shared position once per frame
+func (l *Limiter) delay(c *limiterChannel, input float32) float32 {
+	if l.delaySamples == 0 {
+		return input
 	}
+	out := c.delayBuffer[l.delayIndex]
+	c.delayBuffer[l.delayIndex] = input
+	return out
+}
 
-	// Calculate gain reduction (infinite ratio)
-	gainReductionDB := 0.0
-	if inputDB > l.threshold {
-		gainReductionDB = inputDB - l.threshold
+// advance moves the shared lookahead position after a frame
+func (l *Limiter) advance() {
+	if l.delaySamples > 0 {
+		l.delayIndex = (l.delayIndex + 1) % l.delaySamples
 	}
-	l.gainReduction = gainReductionDB
-
-	// Apply gain reduction
-	gain := float32(math.Pow(10.0, -gainReductionDB/20.0))
+}
 
-	return processSignal * gain
+// Process processes a single sample
+func (l *Limiter) Process(input float32) float32 {
+	c := &l.limiterChannel
+	gain := l.computeGain(c, l.detect(c, input))
+	out := l.delay(c, input) * gain
+	l.advance()
+	return out
 }
 
 // ProcessBuffer processes a buffer of samples
 func (l *Limiter) ProcessBuffer(input, output []float32) {
+	l.beginBlock()
 	for i := range input {
 		output[i] = l.Process(input[i])
 	}
 }
 
-// ProcessStereo processes stereo buffers with linked limiting
+// ProcessStereo processes stereo buffers, linked by SetStereoLink
 func (l *Limiter) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
+	l.beginBlock()
+	frame := l.frame[:2]
 	for i := range inputL {
-		// Get true peak of both channels
-		peakL := inputL[i]
-		peakR := inputR[i]
-
-		if l.truePeak {
-			peakL = l.estimateTruePeak(inputL[i])
-			// Need separate true peak estimation for right channel
-			// For simplicity, using max of both
-			peakR = float32(math.Max(math.Abs(float64(inputR[i])), float64(peakR)))
-		}
-
-		// Use maximum for detection
-		maxPeak := float32(math.Max(math.Abs(float64(peakL)), math.Abs(float64(peakR))))
+		frame[0], frame[1] = inputL[i], inputR[i]
+		l.processFrame(frame)
+		outputL[i], outputR[i] = frame[0], frame[1]
+	}
+}
 
-		// Process with lookahead
-		var processL, processR float32 = inputL[i], inputR[i]
-		if l.delaySamples > 0 && l.delayBuffer != nil {
-			// We need stereo delay buffers for proper stereo processing
-			// For now, using mono detection with stereo processing
-			processL = inputL[i]
-			processR = inputR[i]
+// ProcessChannels processes one buffer per channel in place through the
+// shared gain computer. Buffers beyond Channels are left untouched.
+func (l *Limiter) ProcessChannels(buffers [][]float32) {
+	l.beginBlock()
+	n := min(len(buffers), l.channels)
+	if n == 0 {
+		return
+	}
+	frame := l.frame[:n]
+	for i := range buffers[0] {
+		for ch := 0; ch < n; ch++ {
+			frame[ch] = buffers[ch][i]
 		}
+		l.processFrame(frame)
+		for ch := 0; ch < n; ch++ {
+			buffers[ch][i] = frame[ch]
+		}
+	}
+}
 
-		// Detect from combined peak
-		envelope := l.detector.Detect(maxPeak)
+// processFrame limits one sample per channel in place
+func (l *Limiter) processFrame(frame []float32) {
+	// Detection levels, and the loudest for the linked part
+	levels := l.levels[:len(frame)]
+	linkedDB := math.Inf(-1)
+	for ch, x := range frame {
+		levels[ch] = l.detect(l.channel(ch), x)
+		linkedDB = math.Max(linkedDB, levels[ch])
+	}
 
-		// Calculate limiting
-		inputDB := float64(-96.0)
-		if envelope > 0 {
-			inputDB = 20.0 * math.Log10(float64(envelope))
+	if l.link >= 1 {
+		// Fully linked: one gain computer drives every channel
+		gain := l.computeGain(&l.limiterChannel, linkedDB)
+		for ch, x := range frame {
+			frame[ch] = l.delay(l.channel(ch), x) * gain
 		}
-
-		gainReductionDB := 0.0
-		if inputDB > l.threshold {
-			gainReductionDB = inputDB - l.threshold
+	} else {
+		reduction := 0.0
+		for ch, x := range frame {
+			c := l.channel(ch)
+			gain := l.computeGain(c, linkLevel(l.link, linkedDB, levels[ch]))
+			reduction = math.Max(reduction, c.appliedGR)
+			frame[ch] = l.delay(c, x) * gain
 		}
-		l.gainReduction = gainReductionDB
-
-		// Apply same gain to both channels
-		gain := float32(math.Pow(10.0, -gainReductionDB/20.0))
-		outputL[i] = processL * gain
-		outputR[i] = processR * gain
+		l.gainReduction = reduction
 	}
+	l.advance()
 }
 
 // Reset resets the limiter state
 func (l *Limiter) Reset() {
-	l.detector.Reset()
-	l.peakDetector.Reset()
+	for ch := 0; ch <= len(l.others); ch++ {
+		c := l.channel(ch)
+		clear(c.delayBuffer)
+		c.held.reset()
+		*c = limiterChannel{delayBuffer: c.delayBuffer, held: c.held}
+	}
 	l.gainReduction = 0.0
-	l.lastSample = 0.0
+	l.blockReduction = 0.0
 	l.delayIndex = 0
+}
 
-	// Clear delay buffer
-	if l.delayBuffer != nil {
-		for i := range l.delayBuffer {
-			l.delayBuffer[i] = 0
-		}
+// slidingMax tracks the maximum of the last window values with a
+// monotonic queue in a fixed ring, so pushes do not allocate
+type slidingMax struct {
+	values []float64
+	times  []int
+	head   int
+	size   int
+	now    int
+}
+
+// newSlidingMax creates a sliding maximum over window values
+func newSlidingMax(window int) slidingMax {
+	window = max(1, window)
+	return slidingMax{values: make([]float64, window), times: make([]int, window)}
+}
+
+// push adds a value and returns the maximum of the window
+func (m *slidingMax) push(v float64) float64 {
+	n := len(m.values)
+	m.now++
+
+	// Drop queued values the new one dominates, then expired ones
+	for m.size > 0 && m.values[(m.head+m.size-1)%n] <= v {
+		m.size--
+	}
+	for m.size > 0 && m.times[m.head] <= m.now-n {
+		m.head = (m.head + 1) % n
+		m.size--
 	}
+	tail := (m.head + m.size) % n
+	m.values[tail], m.times[tail] = v, m.now
+	m.size++
+	return m.values[m.head]
+}
+
+// reset empties the window
+func (m *slidingMax) reset() {
+	m.head, m.size, m.now = 0, 0, 0
 }
@@ -195,6 +195,109 @@ func TestLimiterReset(t *testing.T) {
 	}
 }
 
+func TestLimiterNSharedGain(t *testing.T) {
+	l := NewLimiterN(48000, 4)
+	l.SetThreshold(-6)
+	l.SetTruePeak(false)
+	if l.Channels() != 4 {
+		t.Fatalf("Channels() = %d, want 4", l.Channels())
+	}
+
+	// A burst on one channel must not move the others differently
+	n := 4800
+	buffers := make([][]float32, 4)
+	for ch := range buffers {
+		buffers[ch] = make([]float32, n)
+		for i := range buffers[ch] {
+			buffers[ch][i] = 0.1 * float32(ch+1)
+			if ch == 0 && i >= 1000 && i < 1200 {
+				buffers[ch][i] = 1.0
+			}
+		}
+	}
+	inputs := make([][]float32, 4)
+	for ch := range inputs {
+		inputs[ch] = append([]float32(nil), buffers[ch]...)
+	}
+	l.ProcessChannels(buffers)
+
+	ceiling := float32(math.Pow(10, -6.0/20))
+	latency := l.GetLatencySamples()
+	for i := latency; i < n; i++ {
+		ref := buffers[1][i] / inputs[1][i-latency]
+		for ch := range buffers {
+			if buffers[ch][i] > ceiling*1.0001 {
+				t.Fatalf("Channel %d sample %d = %f above the ceiling", ch, i, buffers[ch][i])
+			}
+			if gain := buffers[ch][i] / inputs[ch][i-latency]; math.Abs(float64(gain-ref)) > 1e-5 {
+				t.Fatalf("Channel %d gain %f differs from %f at %d", ch, gain, ref, i)
+			}
+		}
+	}
+	if gr := l.GetMaxGainReduction(); gr < 5.9 || gr > 6.1 {
+		t.Errorf("Block max gain reduction = %f, want about 6 dB", gr)
+	}
+}
+
+func TestLimiterLookaheadBrickWall(t *testing.T) {
+	l := NewLimiter(48000)
+	l.SetThreshold(-3)
+	l.SetTruePeak(false)
+	l.SetRelease(0.001)
+	l.SetSlowRelease(0)
+
+	// Isolated spikes of different heights, closer together than the
+	// lookahead, all stay under the ceiling
+	ceiling := math.Pow(10, -3.0/20)
+	input := make([]float32, 2000)
+	for i := range input {
+		input[i] = 0.2
+	}
+	input[500], input[560], input[600] = 1.0, 0.9, -0.8
+	output := make([]float32, len(input))
+	l.ProcessBuffer(input, output)
+	for i, v := range output {
+		if math.Abs(float64(v)) > ceiling*1.0001 {
+			t.Errorf("Sample %d = %f above the %f ceiling", i, v, ceiling)
+		}
+	}
+	if l.GetGainReduction() > 0.01 {
+		t.Errorf("Gain reduction %f should have released", l.GetGainReduction())
+	}
+	if gr := l.GetMaxGainReduction(); math.Abs(gr-3) > 0.01 {
+		t.Errorf("Block max gain reduction = %f, want the spike's 3 dB", gr)
+	}
+}
+
+func TestLimiterDualStageRelease(t *testing.T) {
+	// recovery returns the gain reduction left 20 ms after a loud section
+	recovery := func(slow float64, loud int) float64 {
+		l := NewLimiter(48000)
+		l.SetThreshold(-6)
+		l.SetTruePeak(false)
+		l.SetLookahead(0)
+		l.SetRelease(0.005)
+		l.SetSlowRelease(slow)
+		for i := 0; i < loud; i++ {
+			l.Process(1.0)
+		}
+		for i := 0; i < 960; i++ {
+			l.Process(0.1)
+		}
+		return l.GetGainReduction()
+	}
+
+	// Sustained limiting charges the slow stage and holds the gain
+	if single, dual := recovery(0, 48000), recovery(0.5, 48000); dual < single+2 {
+		t.Errorf("After sustained limiting: %f dB left with the slow stage, %f without", dual, single)
+	}
+
+	// A short peak barely charges it, so recovery stays quick
+	if dual := recovery(0.5, 48); dual > 0.5 {
+		t.Errorf("After a short peak %f dB is left, want a fast recovery", dual)
+	}
+}
+
 // Benchmark limiter
 func BenchmarkLimiter(b *testing.B) {
 	l := NewLimiter(48000.0)
@@ -0,0 +1,28 @@
+package dynamics
+
+import "math"
+
+// DefaultStereoLink fully links the channels, matching the behavior of
+// ProcessStereo before the link amount was adjustable
+const DefaultStereoLink = 1.0
+
+// clampLink limits a stereo link amount to 0-1
+func clampLink(amount float64) float64 {
+	return math.Max(0.0, math.Min(1.0, amount))
+}
+
+// linkLevel blends a channel's own detector level with the linked level,
+// both in dB. The linked level is the louder channel's, so the result never
+// falls below the channel's own level and partial linking can only add
+// gain reduction.
+func linkLevel(link, linkedDB, ownDB float64) float64 {
+	return ownDB + (linkedDB-ownDB)*link
+}
+
+// levelDB converts a detector envelope to dB with a -96 dB floor
+func levelDB(envelope float32) float64 {
+	if envelope > 0 {
+		return 20.0 * math.Log10(float64(envelope))
+	}
+	return -96.0
+}
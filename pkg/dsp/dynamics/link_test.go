@@ -0,0 +1,81 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// stereoGains runs a loud left and a quiet right channel through process
+// and returns the settled gain of each channel
+func stereoGains(process func(inL, inR, outL, outR []float32)) (left, right float64) {
+	n := 4800
+	inL, inR := make([]float32, n), make([]float32, n)
+	for i := range inL {
+		inL[i], inR[i] = 0.9, 0.05
+	}
+	outL, outR := make([]float32, n), make([]float32, n)
+	process(inL, inR, outL, outR)
+	return float64(outL[n-1] / inL[n-1]), float64(outR[n-1] / inR[n-1])
+}
+
+func TestCompressorStereoLink(t *testing.T) {
+	var rightGains []float64
+	for _, link := range []float64{0, 0.5, 1} {
+		c := NewCompressor(48000)
+		c.SetThreshold(-20)
+		c.SetRatio(4)
+		c.SetStereoLink(link)
+		l, r := stereoGains(c.ProcessStereo)
+		if l >= 1 {
+			t.Errorf("link %g: left not compressed (%g)", link, l)
+		}
+		rightGains = append(rightGains, r)
+		if link == 1 && math.Abs(l-r) > 1e-6 {
+			t.Errorf("fully linked gains differ: %g vs %g", l, r)
+		}
+	}
+	if math.Abs(rightGains[0]-1) > 1e-6 {
+		t.Errorf("unlinked quiet channel gain %g, want 1", rightGains[0])
+	}
+	if !(rightGains[0] > rightGains[1] && rightGains[1] > rightGains[2]) {
+		t.Errorf("right gain should fall as link rises: %v", rightGains)
+	}
+}
+
+func TestGateStereoLink(t *testing.T) {
+	for _, tc := range []struct {
+		link      float64
+		rightOpen bool
+	}{{0, false}, {1, true}} {
+		g := NewGate(48000)
+		g.SetThreshold(-20)
+		g.SetAttack(0)
+		g.SetStereoLink(tc.link)
+		l, r := stereoGains(g.ProcessStereo)
+		if l < 0.99 {
+			t.Errorf("link %g: loud channel gated (%g)", tc.link, l)
+		}
+		if open := r > 0.99; open != tc.rightOpen {
+			t.Errorf("link %g: quiet channel gain %g", tc.link, r)
+		}
+	}
+}
+
+func TestLimiterStereoLink(t *testing.T) {
+	ceiling := math.Pow(10, -6.0/20)
+	for _, link := range []float64{0, 0.3, 1} {
+		lim := NewLimiter(48000)
+		lim.SetThreshold(-6)
+		lim.SetStereoLink(link)
+		l, r := stereoGains(lim.ProcessStereo)
+		if l*0.9 > ceiling*1.01 {
+			t.Errorf("link %g: left %g above the ceiling", link, l*0.9)
+		}
+		if link == 0 && math.Abs(r-1) > 1e-6 {
+			t.Errorf("unlinked quiet channel gain %g, want 1", r)
+		}
+		if link == 1 && math.Abs(l-r) > 1e-6 {
+			t.Errorf("fully linked gains differ: %g vs %g", l, r)
+		}
+	}
+}
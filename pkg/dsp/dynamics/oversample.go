@@ -0,0 +1,92 @@
+package dynamics
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis"
+)
+
+// Intersample peak guard timing in base-rate samples. Every estimate the
+// detector makes depends on truePeakGuardSpan input samples, and each of
+// them must already be turned down by the gain that estimate needs. The
+// gain ramps down over the attack so the gain change adds no overshoot.
+const (
+	truePeakGuardSpan   = 2 * (analysis.TruePeakDetectorDelay + 1)
+	truePeakGuardAttack = 8
+	truePeakGuardDelay  = truePeakGuardAttack + truePeakGuardSpan - 2
+)
+
+// truePeakGuard is a brick-wall gain stage that estimates intersample
+// peaks with the BS.1770 interpolator and ramps the gain down before they
+// reach the output. The ramp is a moving average of a windowed minimum, so
+// it reaches each peak's required gain in time.
+type truePeakGuard struct {
+	detector analysis.TruePeakDetector
+
+	delay    [truePeakGuardDelay]float64
+	delayPos int
+
+	required [truePeakGuardAttack + truePeakGuardSpan - 1]float64 // Gain each estimate needs
+	held     [truePeakGuardAttack]float64                         // Windowed minimum of required
+	ringPos  int
+
+	gain         float64
+	releaseCoeff float64
+}
+
+// newTruePeakGuard creates a guard with a 5 ms release
+func newTruePeakGuard(sampleRate float64) *truePeakGuard {
+	g := &truePeakGuard{releaseCoeff: math.Exp(-1.0 / (0.005 * sampleRate))}
+	g.reset()
+	return g
+}
+
+// process delays x by truePeakGuardDelay samples and scales it so neither
+// the samples nor the estimated peaks between them exceed ceiling
+func (g *truePeakGuard) process(x, ceiling float64) float64 {
+	required := 1.0
+	if peak := g.detector.Process(x); peak > ceiling {
+		required = ceiling / peak
+	}
+
+	// Hold the lowest requirement across the attack plus the detector span,
+	// then average the held values into a ramp
+	g.required[g.ringPos%len(g.required)] = required
+	held := 1.0
+	for _, v := range g.required {
+		held = math.Min(held, v)
+	}
+	g.held[g.ringPos%len(g.held)] = held
+	g.ringPos = (g.ringPos + 1) % (len(g.required) * len(g.held))
+	target := 0.0
+	for _, v := range g.held {
+		target += v
+	}
+	target /= float64(len(g.held))
+
+	if target < g.gain {
+		g.gain = target
+	} else {
+		g.gain = target + (g.gain-target)*g.releaseCoeff
+	}
+
+	out := g.delay[g.delayPos] * g.gain
+	g.delay[g.delayPos] = x
+	g.delayPos = (g.delayPos + 1) % truePeakGuardDelay
+	return math.Max(-ceiling, math.Min(ceiling, out))
+}
+
+// reset clears the history and restores unity gain
+func (g *truePeakGuard) reset() {
+	g.detector.Reset()
+	g.delay = [truePeakGuardDelay]float64{}
+	g.delayPos = 0
+	for i := range g.required {
+		g.required[i] = 1
+	}
+	for i := range g.held {
+		g.held[i] = 1
+	}
+	g.ringPos = 0
+	g.gain = 1
+}
@@ -0,0 +1,16 @@
+package dynamics
+
+import "math"
+
+// clampMix limits a dry/wet mix to 0-1
+func clampMix(amount float64) float64 {
+	return math.Max(0.0, math.Min(1.0, amount))
+}
+
+// parallelGain blends a processor's gain toward unity by the dry/wet mix.
+// The processors here only scale their input, delayed by any lookahead, so
+// mixing in the equally delayed dry signal is the same as blending the gain,
+// and the dry path needs no delay line of its own.
+func parallelGain(mix float64, gain float32) float32 {
+	return 1 + float32(mix)*(gain-1)
+}
@@ -0,0 +1,365 @@
+package dynamics
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis"
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+)
+
+// RiderDetector selects how a Rider measures level
+type RiderDetector int
+
+const (
+	RiderRMS  RiderDetector = iota // Unweighted RMS in dBFS
+	RiderLUFS                      // K-weighted loudness in LUFS, as BS.1770
+)
+
+// Default rider settings
+const (
+	DefaultRiderTarget    = -18.0 // dB
+	DefaultRiderRange     = 6.0   // dB of boost or cut
+	DefaultRiderSpeed     = 0.5   // Seconds
+	DefaultRiderWindow    = 0.4   // Seconds, the momentary loudness window
+	DefaultRiderLookahead = 0.05  // Seconds
+	DefaultRiderFloor     = -50.0 // dB
+)
+
+// Rider limits
+const (
+	maxRiderWindow    = 3.0   // Seconds, the short-term loudness window
+	maxRiderLookahead = 0.5   // Seconds
+	riderGateTime     = 0.002 // Seconds, the floor detector's time constant
+)
+
+// Rider is an automatic level rider, the "vocal rider" that moves a fader
+// slowly toward a target level instead of compressing. It measures RMS or
+// K-weighted loudness over a sliding window, computes the gain that brings
+// the level to the target within a range, and moves toward it at the set
+// speed. Look-ahead delays the audio so the gain arrives with the phrase
+// rather than after it, and signals below the floor hold the gain so
+// pauses and breaths are not ridden up. All channels share one gain.
+type Rider struct {
+	sampleRate float64
+	channels   int
+
+	// Parameters
+	detector  RiderDetector
+	target    float64 // dB
+	rangeDB   float64 // Maximum boost or cut in dB
+	speed     float64 // Seconds
+	window    float64 // Seconds
+	lookahead float64 // Seconds
+	floor     float64 // dB
+
+	speedCoeff float64
+	gateCoeff  float64
+
+	// Level detector: per-channel K-weighting and a running sum of the
+	// summed channel power over the window
+	weighting   []*analysis.KWeighting
+	power       []float64
+	powerPos    int
+	powerSum    float64
+	windowLen   int
+	powerFilled int
+	gatePower   float64 // Fast mean square the floor is checked against
+
+	// Look-ahead delay, one ring per channel
+	delays       [][]float32
+	delayPos     int
+	delaySamples int
+
+	// State
+	frame  []float32 // ProcessChannels scratch
+	level  float64   // Measured level in dB
+	gainDB float64   // Applied gain in dB
+}
+
+// NewRider creates a rider for the given number of channels with the
+// default settings and RMS detection. Process and ProcessStereo work
+// whatever the channel count.
+func NewRider(sampleRate float64, channels int) *Rider {
+	channels = max(1, channels)
+	states := max(2, channels)
+	r := &Rider{
+		sampleRate: sampleRate,
+		channels:   channels,
+		detector:   RiderRMS,
+		target:     DefaultRiderTarget,
+		rangeDB:    DefaultRiderRange,
+		floor:      DefaultRiderFloor,
+		weighting:  make([]*analysis.KWeighting, states),
+		power:      make([]float64, int(maxRiderWindow*sampleRate)+1),
+		delays:     make([][]float32, states),
+		frame:      make([]float32, channels),
+		level:      -96.0,
+		gateCoeff:  math.Exp(-1.0 / (riderGateTime * sampleRate)),
+	}
+	for ch := range r.delays {
+		r.weighting[ch] = analysis.NewKWeighting(sampleRate)
+		r.delays[ch] = make([]float32, int(maxRiderLookahead*sampleRate)+1)
+	}
+	r.SetSpeed(DefaultRiderSpeed)
+	r.SetWindow(DefaultRiderWindow)
+	r.SetLookahead(DefaultRiderLookahead)
+	return r
+}
+
+// Channels returns the number of channels ProcessChannels expects
+func (r *Rider) Channels() int {
+	return r.channels
+}
+
+// SetDetector selects RMS or loudness detection
+func (r *Rider) SetDetector(detector RiderDetector) {
+	if detector != r.detector {
+		r.detector = detector
+		r.resetDetector()
+	}
+}
+
+// GetDetector returns the detector
+func (r *Rider) GetDetector() RiderDetector {
+	return r.detector
+}
+
+// SetTarget sets the target level in dB (-60 to 0), read as LUFS with the
+// loudness detector
+func (r *Rider) SetTarget(dB float64) {
+	r.target = math.Max(-60.0, math.Min(0.0, dB))
+}
+
+// GetTarget returns the target level in dB
+func (r *Rider) GetTarget() float64 {
+	return r.target
+}
+
+// SetRange sets how far the rider may boost or cut, in dB (0-24)
+func (r *Rider) SetRange(dB float64) {
+	r.rangeDB = math.Max(0.0, math.Min(24.0, dB))
+}
+
+// GetRange returns the range in dB
+func (r *Rider) GetRange() float64 {
+	return r.rangeDB
+}
+
+// SetSpeed sets the time constant of the gain movement in seconds
+// (0.01-10). Shorter times ride syllables, longer times ride phrases.
+func (r *Rider) SetSpeed(seconds float64) {
+	r.speed = math.Max(0.01, math.Min(10.0, seconds))
+	r.speedCoeff = coeff.TimeConstant(r.speed, r.sampleRate)
+}
+
+// GetSpeed returns the speed in seconds
+func (r *Rider) GetSpeed() float64 {
+	return r.speed
+}
+
+// SetWindow sets the level measurement window in seconds (0.05-3)
+func (r *Rider) SetWindow(seconds float64) {
+	r.window = math.Max(0.05, math.Min(maxRiderWindow, seconds))
+	windowLen := max(1, int(r.window*r.sampleRate))
+	if windowLen != r.windowLen {
+		r.windowLen = windowLen
+		r.resetDetector()
+	}
+}
+
+// GetWindow returns the measurement window in seconds
+func (r *Rider) GetWindow() float64 {
+	return r.window
+}
+
+// SetLookahead sets how far the detector runs ahead of the audio, in
+// seconds (0-0.5). This is the rider's latency.
+func (r *Rider) SetLookahead(seconds float64) {
+	r.lookahead = math.Max(0.0, math.Min(maxRiderLookahead, seconds))
+	delaySamples := int(r.lookahead * r.sampleRate)
+	if delaySamples != r.delaySamples {
+		r.delaySamples = delaySamples
+		for _, d := range r.delays {
+			clear(d)
+		}
+		r.delayPos = 0
+	}
+}
+
+// GetLookahead returns the look-ahead time in seconds
+func (r *Rider) GetLookahead() float64 {
+	return r.lookahead
+}
+
+// SetFloor sets the level in dB below which the rider holds its gain
+// (-90 to 0)
+func (r *Rider) SetFloor(dB float64) {
+	r.floor = math.Max(-90.0, math.Min(0.0, dB))
+}
+
+// GetFloor returns the hold floor in dB
+func (r *Rider) GetFloor() float64 {
+	return r.floor
+}
+
+// GetLatencySamples returns the look-ahead delay in samples
+func (r *Rider) GetLatencySamples() int {
+	return r.delaySamples
+}
+
+// GetGain returns the applied gain in dB. Plugins can report it as an
+// output parameter to record the ride as host automation.
+func (r *Rider) GetGain() float64 {
+	return r.gainDB
+}
+
+// GetLevel returns the measured level in dB or LUFS
+func (r *Rider) GetLevel() float64 {
+	return r.level
+}
+
+// measure adds one frame to the level detector and returns the level
+func (r *Rider) measure(frame []float32) float64 {
+	power := 0.0
+	for ch, x := range frame {
+		v := float64(x)
+		if r.detector == RiderLUFS {
+			v = r.weighting[ch].Process(v)
+		}
+		power += v * v
+	}
+
+	// Running sum over the window, recomputed at each wrap so rounding
+	// errors cannot accumulate
+	r.powerSum += power - r.power[r.powerPos]
+	r.power[r.powerPos] = power
+	r.powerPos++
+	if r.powerPos >= r.windowLen {
+		r.powerPos = 0
+		r.powerSum = 0
+		for _, p := range r.power[:r.windowLen] {
+			r.powerSum += p
+		}
+	}
+	r.powerFilled = min(r.powerFilled+1, r.windowLen)
+	r.gatePower = power + (r.gatePower-power)*r.gateCoeff
+
+	// Until the window fills, the mean is over what has been seen
+	meanSquare := r.powerSum / float64(r.powerFilled)
+	if meanSquare <= 1e-10 {
+		return -96.0
+	}
+	if r.detector == RiderLUFS {
+		return -0.691 + 10.0*math.Log10(meanSquare)
+	}
+	// Average channel power, so a stereo signal reads like its channels
+	return 10.0 * math.Log10(meanSquare/float64(len(frame)))
+}
+
+// gateLevel returns the fast detector level in dB, on the same scale as
+// the windowed level
+func (r *Rider) gateLevel(channels int) float64 {
+	if r.gatePower <= 1e-10 {
+		return -96.0
+	}
+	if r.detector == RiderLUFS {
+		return -0.691 + 10.0*math.Log10(r.gatePower)
+	}
+	return 10.0 * math.Log10(r.gatePower/float64(channels))
+}
+
+// processFrame rides one frame of samples in place
+func (r *Rider) processFrame(frame []float32) {
+	r.level = r.measure(frame)
+
+	// Hold through pauses, otherwise ride toward the target within the
+	// range. The floor is checked against a fast detector so the gain
+	// stops as soon as the signal does, not a window later.
+	desired := r.gainDB
+	if r.gateLevel(len(frame)) >= r.floor {
+		desired = math.Max(-r.rangeDB, math.Min(r.rangeDB, r.target-r.level))
+	}
+	r.gainDB = desired + (r.gainDB-desired)*r.speedCoeff
+	gain := fastmath.High.DbToLinear(float32(r.gainDB))
+
+	for ch, x := range frame {
+		if r.delaySamples > 0 {
+			d := r.delays[ch]
+			d[r.delayPos], x = x, d[r.delayPos]
+		}
+		frame[ch] = x * gain
+	}
+	if r.delaySamples > 0 {
+		r.delayPos++
+		if r.delayPos >= r.delaySamples {
+			r.delayPos = 0
+		}
+	}
+}
+
+// Process processes a single sample of a mono rider
+func (r *Rider) Process(input float32) float32 {
+	frame := [1]float32{input}
+	r.processFrame(frame[:])
+	return frame[0]
+}
+
+// ProcessBuffer processes a buffer of a mono rider
+func (r *Rider) ProcessBuffer(input, output []float32) {
+	for i := range input {
+		output[i] = r.Process(input[i])
+	}
+}
+
+// ProcessStereo processes stereo buffers with one shared gain
+func (r *Rider) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
+	var frame [2]float32
+	for i := range inputL {
+		frame[0], frame[1] = inputL[i], inputR[i]
+		r.processFrame(frame[:])
+		outputL[i], outputR[i] = frame[0], frame[1]
+	}
+}
+
+// ProcessChannels processes one buffer per channel in place. Extra
+// buffers beyond Channels are left untouched.
+func (r *Rider) ProcessChannels(buffers [][]float32) {
+	channels := min(len(buffers), r.channels)
+	if channels == 0 {
+		return
+	}
+	f := r.frame[:channels]
+	for i := range buffers[0] {
+		for ch := range f {
+			f[ch] = buffers[ch][i]
+		}
+		r.processFrame(f)
+		for ch := range f {
+			buffers[ch][i] = f[ch]
+		}
+	}
+}
+
+// resetDetector clears the level measurement
+func (r *Rider) resetDetector() {
+	for _, w := range r.weighting {
+		w.Reset()
+	}
+	clear(r.power)
+	r.powerPos = 0
+	r.powerSum = 0
+	r.powerFilled = 0
+	r.gatePower = 0
+	r.level = -96.0
+}
+
+// Reset clears the detector, the look-ahead delay and the applied gain
+func (r *Rider) Reset() {
+	r.resetDetector()
+	for _, d := range r.delays {
+		clear(d)
+	}
+	r.delayPos = 0
+	r.gainDB = 0
+}
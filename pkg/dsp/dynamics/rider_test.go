@@ -0,0 +1,125 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// rideSine runs seconds of a 1 kHz sine with the given peak amplitude
+// through the rider and returns the output
+func rideSine(r *Rider, amplitude, seconds float64) []float32 {
+	n := int(seconds * r.sampleRate)
+	out := make([]float32, n)
+	for i := range out {
+		out[i] = r.Process(float32(amplitude * math.Sin(2*math.Pi*1000*float64(i)/r.sampleRate)))
+	}
+	return out
+}
+
+func TestRiderReachesTarget(t *testing.T) {
+	r := NewRider(48000, 1)
+	r.SetTarget(-18)
+	r.SetRange(24)
+	r.SetSpeed(0.2)
+
+	// -30 dBFS RMS is ridden up 12 dB
+	amplitude := math.Pow(10, -30.0/20) * math.Sqrt2
+	rideSine(r, amplitude, 3)
+	if math.Abs(r.GetLevel()+30) > 0.1 {
+		t.Errorf("Level = %.2f dB, want -30", r.GetLevel())
+	}
+	if math.Abs(r.GetGain()-12) > 0.1 {
+		t.Errorf("Gain = %.2f dB, want 12", r.GetGain())
+	}
+
+	// Louder input is cut toward the target
+	rideSine(r, math.Pow(10, -10.0/20)*math.Sqrt2, 3)
+	if math.Abs(r.GetGain()+8) > 0.1 {
+		t.Errorf("Gain = %.2f dB, want -8", r.GetGain())
+	}
+}
+
+func TestRiderRangeAndFloor(t *testing.T) {
+	r := NewRider(48000, 1)
+	r.SetTarget(-18)
+	r.SetRange(6)
+	r.SetSpeed(0.1)
+
+	rideSine(r, 0.01, 2)
+	if math.Abs(r.GetGain()-6) > 0.05 {
+		t.Errorf("Gain = %.2f dB, want the 6 dB range", r.GetGain())
+	}
+
+	// Silence is below the floor, so the gain holds rather than rising,
+	// apart from the few milliseconds the floor detector takes to fall
+	r.SetRange(24)
+	rideSine(r, 0, 2)
+	if math.Abs(r.GetGain()-6) > 1 {
+		t.Errorf("Gain after a pause = %.2f dB, want it held at 6", r.GetGain())
+	}
+}
+
+func TestRiderLookahead(t *testing.T) {
+	r := NewRider(48000, 1)
+	r.SetLookahead(0.01)
+	latency := r.GetLatencySamples()
+	if latency != 480 {
+		t.Fatalf("Latency = %d, want 480", latency)
+	}
+
+	out := make([]float32, 1000)
+	for i := range out {
+		in := float32(0)
+		if i == 0 {
+			in = 0.5
+		}
+		out[i] = r.Process(in)
+	}
+	for i, v := range out {
+		if (i == latency) != (v != 0) {
+			t.Fatalf("Output %d = %f, want the impulse only at %d", i, v, latency)
+		}
+	}
+}
+
+func TestRiderLoudness(t *testing.T) {
+	// A full-scale 997 Hz sine measures -3.01 LUFS (BS.1770), so -20 dBFS
+	// peak measures -23.01
+	r := NewRider(48000, 1)
+	r.SetDetector(RiderLUFS)
+	n := 48000
+	for i := 0; i < n; i++ {
+		r.Process(float32(0.1 * math.Sin(2*math.Pi*997*float64(i)/48000)))
+	}
+	if math.Abs(r.GetLevel()+23.01) > 0.1 {
+		t.Errorf("Loudness = %.2f LUFS, want -23.01", r.GetLevel())
+	}
+}
+
+func TestRiderSharedGain(t *testing.T) {
+	r := NewRider(48000, 2)
+	r.SetLookahead(0)
+	n := 4800
+	left := make([]float32, n)
+	right := make([]float32, n)
+	for i := range left {
+		left[i] = float32(0.05 * math.Sin(float64(i)*0.1))
+		right[i] = left[i] / 2
+	}
+	r.ProcessChannels([][]float32{left, right})
+
+	// One gain for both channels keeps their balance
+	for i := range left {
+		if math.Abs(float64(left[i]-2*right[i])) > 1e-6 {
+			t.Fatalf("Sample %d: left %f, right %f, want left twice right", i, left[i], right[i])
+		}
+	}
+	if r.GetGain() <= 0 {
+		t.Errorf("Gain = %.2f dB, want a boost toward the target", r.GetGain())
+	}
+
+	r.Reset()
+	if r.GetGain() != 0 || r.GetLevel() != -96 {
+		t.Error("Reset should clear the gain and level")
+	}
+}
@@ -0,0 +1,223 @@
+package dynamics
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis/transient"
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+)
+
+// MaxTransientGain is the most a transient shaper boosts or cuts the attack
+// or sustain, in dB
+const MaxTransientGain = 24.0
+
+// TransientShaper changes the attack and sustain of a sound independently of
+// its level. A transient.Detector measures how strongly each sample belongs
+// to an attack or to a decay, and the shaper applies the attack and sustain
+// gains scaled by those strengths, so a +6 dB attack boosts the start of
+// every hit by up to 6 dB whether it is played loud or soft. There is no
+// threshold to set and no latency.
+type TransientShaper struct {
+	sampleRate float64
+
+	// Parameters
+	attackGain  float64 // dB at full transient strength
+	sustainGain float64 // dB at full sustain strength
+	link        float64 // Stereo link amount, 0-1
+
+	// Linked detector and per-channel detectors for partially linked stereo
+	detector         *transient.Detector
+	channelDetectors [2]*transient.Detector
+
+	// Metering
+	gain      float64 // dB applied to the last sample
+	blockCut  float64 // Deepest cut in the current block, positive dB
+	blockGain float64 // Largest boost in the current block, dB
+}
+
+// NewTransientShaper creates a transient shaper with no attack or sustain
+// change and fully linked stereo
+func NewTransientShaper(sampleRate float64) *TransientShaper {
+	ts := &TransientShaper{
+		sampleRate: sampleRate,
+		link:       DefaultStereoLink,
+		detector:   transient.NewDetector(sampleRate),
+	}
+	for i := range ts.channelDetectors {
+		ts.channelDetectors[i] = transient.NewDetector(sampleRate)
+	}
+	return ts
+}
+
+// detectors returns the linked detector and both channel detectors
+func (ts *TransientShaper) detectors() [3]*transient.Detector {
+	return [3]*transient.Detector{ts.detector, ts.channelDetectors[0], ts.channelDetectors[1]}
+}
+
+// SetAttack sets the gain applied to transients in dB, positive to
+// sharpen them and negative to soften them
+func (ts *TransientShaper) SetAttack(dB float64) {
+	ts.attackGain = math.Max(-MaxTransientGain, math.Min(MaxTransientGain, dB))
+}
+
+// GetAttack returns the attack gain in dB
+func (ts *TransientShaper) GetAttack() float64 {
+	return ts.attackGain
+}
+
+// SetSustain sets the gain applied to the decay of sounds in dB, positive
+// to bring up the tail and room and negative to tighten it
+func (ts *TransientShaper) SetSustain(dB float64) {
+	ts.sustainGain = math.Max(-MaxTransientGain, math.Min(MaxTransientGain, dB))
+}
+
+// GetSustain returns the sustain gain in dB
+func (ts *TransientShaper) GetSustain() float64 {
+	return ts.sustainGain
+}
+
+// SetSmoothing sets the attack and release of the detector's level follower
+// in seconds. Longer times give smoother gain changes on bass-heavy material.
+func (ts *TransientShaper) SetSmoothing(attack, release float64) {
+	for _, d := range ts.detectors() {
+		d.SetSmoothing(attack, release)
+	}
+}
+
+// SetAttackWindow sets how long the attack gain acts after a hit, in seconds
+func (ts *TransientShaper) SetAttackWindow(seconds float64) {
+	for _, d := range ts.detectors() {
+		d.SetAttackWindow(seconds)
+	}
+}
+
+// GetAttackWindow returns the attack window in seconds
+func (ts *TransientShaper) GetAttackWindow() float64 {
+	return ts.detector.GetAttackWindow()
+}
+
+// SetSustainWindow sets how long the sustain gain acts once the level starts
+// falling, in seconds
+func (ts *TransientShaper) SetSustainWindow(seconds float64) {
+	for _, d := range ts.detectors() {
+		d.SetSustainWindow(seconds)
+	}
+}
+
+// GetSustainWindow returns the sustain window in seconds
+func (ts *TransientShaper) GetSustainWindow() float64 {
+	return ts.detector.GetSustainWindow()
+}
+
+// SetSidechainFilter enables/disables the detector high-pass filter, which
+// stops low frequencies such as a kick's body from masking transients
+func (ts *TransientShaper) SetSidechainFilter(enabled bool, frequency float64) {
+	if !enabled {
+		frequency = 0
+	} else {
+		frequency = math.Max(20.0, math.Min(frequency, ts.sampleRate*0.45))
+	}
+	for _, d := range ts.detectors() {
+		d.SetHighPass(frequency)
+	}
+}
+
+// SetStereoLink sets how strongly ProcessStereo links the channels, from 0
+// (each channel shaped on its own) to 1 (both share one detector, the
+// default)
+func (ts *TransientShaper) SetStereoLink(amount float64) {
+	ts.link = clampLink(amount)
+}
+
+// GetStereoLink returns the stereo link amount (0-1)
+func (ts *TransientShaper) GetStereoLink() float64 {
+	return ts.link
+}
+
+// GetGain returns the gain applied to the last sample in dB, positive when
+// boosting
+func (ts *TransientShaper) GetGain() float64 {
+	return ts.gain
+}
+
+// GetGainReduction returns the deepest cut in dB during the last
+// ProcessBuffer or ProcessStereo call, for metering
+func (ts *TransientShaper) GetGainReduction() float64 {
+	return ts.blockCut
+}
+
+// GetMaxGain returns the largest boost in dB during the last ProcessBuffer
+// or ProcessStereo call, for metering
+func (ts *TransientShaper) GetMaxGain() float64 {
+	return ts.blockGain
+}
+
+// shape returns the gain in dB for a transient and sustain strength
+func (ts *TransientShaper) shape(attack, sustain float32) float64 {
+	return ts.attackGain*float64(attack) + ts.sustainGain*float64(sustain)
+}
+
+// meter records the gain of a sample
+func (ts *TransientShaper) meter(gainDB float64) {
+	ts.gain = gainDB
+	ts.blockCut = math.Max(ts.blockCut, -gainDB)
+	ts.blockGain = math.Max(ts.blockGain, gainDB)
+}
+
+// toLinear converts a gain in dB to a linear factor
+func toLinear(gainDB float64) float32 {
+	if gainDB == 0 {
+		return 1
+	}
+	return fastmath.High.DbToLinear(float32(gainDB))
+}
+
+// Process shapes a single sample
+func (ts *TransientShaper) Process(input float32) float32 {
+	gainDB := ts.shape(ts.detector.Process(input))
+	ts.meter(gainDB)
+	return input * toLinear(gainDB)
+}
+
+// ProcessBuffer shapes a mono buffer
+func (ts *TransientShaper) ProcessBuffer(input, output []float32) {
+	ts.blockCut, ts.blockGain = 0, 0
+	for i, x := range input {
+		output[i] = ts.Process(x)
+	}
+}
+
+// ProcessStereo shapes stereo buffers. With full linking both channels get
+// the gain of one detector following the louder channel, so the stereo image
+// does not shift on hits panned to one side.
+func (ts *TransientShaper) ProcessStereo(inputL, inputR, outputL, outputR []float32) {
+	ts.blockCut, ts.blockGain = 0, 0
+	for i := range inputL {
+		l, r := inputL[i], inputR[i]
+		// The channel detectors always run so changing the link mid-note
+		// does not start them from silence
+		linked := ts.shape(ts.detector.ProcessStereo(l, r))
+		ownL := ts.shape(ts.channelDetectors[0].Process(l))
+		ownR := ts.shape(ts.channelDetectors[1].Process(r))
+		gainL := ownL + (linked-ownL)*ts.link
+		gainR := ownR + (linked-ownR)*ts.link
+
+		// Meter the channel changed most
+		ts.meter(gainR)
+		if math.Abs(gainL) >= math.Abs(gainR) {
+			ts.meter(gainL)
+		}
+
+		outputL[i] = l * toLinear(gainL)
+		outputR[i] = r * toLinear(gainR)
+	}
+}
+
+// Reset clears the detectors and meters
+func (ts *TransientShaper) Reset() {
+	for _, d := range ts.detectors() {
+		d.Reset()
+	}
+	ts.gain = 0
+	ts.blockCut, ts.blockGain = 0, 0
+}
@@ -0,0 +1,147 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// drumHits renders decaying noise-like hits every period samples
+func drumHits(n, period int, amplitude float64) []float32 {
+	x := make([]float32, n)
+	for i := range x {
+		t := i % period
+		x[i] = float32(amplitude*math.Exp(-float64(t)/2400)) * float32(1-2*(i%2))
+	}
+	return x
+}
+
+// ratioDB returns the level change from in to out over a range of samples
+func ratioDB(in, out []float32, from, to int) float64 {
+	return 20 * math.Log10(rmsOf(out[from:to])/rmsOf(in[from:to]))
+}
+
+func TestTransientShaperNeutral(t *testing.T) {
+	ts := NewTransientShaper(48000)
+	in := drumHits(9600, 4800, 0.5)
+	out := make([]float32, len(in))
+	ts.ProcessBuffer(in, out)
+	for i := range in {
+		if in[i] != out[i] {
+			t.Fatalf("sample %d changed from %g to %g with no attack or sustain", i, in[i], out[i])
+		}
+	}
+	if ts.GetGainReduction() != 0 || ts.GetMaxGain() != 0 {
+		t.Errorf("metered %g dB cut and %g dB boost", ts.GetGainReduction(), ts.GetMaxGain())
+	}
+}
+
+func TestTransientShaperAttackAndSustain(t *testing.T) {
+	const sr = 48000
+	in := drumHits(9600, 9600, 0.5)
+	out := make([]float32, len(in))
+
+	// The attack gain acts on the first milliseconds of the hit
+	ts := NewTransientShaper(sr)
+	ts.SetAttack(6)
+	ts.ProcessBuffer(in, out)
+	if db := ratioDB(in, out, 0, 240); db < 2 {
+		t.Errorf("attack boosted by %.1f dB, want a clear boost", db)
+	}
+	if db := ratioDB(in, out, 7200, 9600); math.Abs(db) > 0.5 {
+		t.Errorf("tail changed by %.1f dB with only attack set", db)
+	}
+	if gain := ts.GetMaxGain(); gain <= 2 || gain > 6 {
+		t.Errorf("max gain %g dB, want up to the 6 dB attack", gain)
+	}
+
+	// The sustain gain acts on the decay
+	ts = NewTransientShaper(sr)
+	ts.SetSustain(-12)
+	ts.ProcessBuffer(in, out)
+	if db := ratioDB(in, out, 0, 48); db < -1 {
+		t.Errorf("hit start cut by %.1f dB with only sustain set", -db)
+	}
+	if db := ratioDB(in, out, 4800, 9600); db > -4 {
+		t.Errorf("tail cut by %.1f dB, want a clear cut", -db)
+	}
+	if gr := ts.GetGainReduction(); gr <= 4 || gr > 12 {
+		t.Errorf("gain reduction %g dB, want up to the 12 dB sustain", gr)
+	}
+}
+
+func TestTransientShaperLevelIndependent(t *testing.T) {
+	// Loud and soft hits get the same shaping
+	var changes [2]float64
+	for i, amplitude := range []float64{0.8, 0.01} {
+		ts := NewTransientShaper(48000)
+		ts.SetAttack(-6)
+		in := drumHits(4800, 4800, amplitude)
+		out := make([]float32, len(in))
+		ts.ProcessBuffer(in, out)
+		changes[i] = ratioDB(in, out, 0, 480)
+	}
+	if math.Abs(changes[0]-changes[1]) > 0.1 {
+		t.Errorf("attack changed by %.2f dB when loud and %.2f dB when soft", changes[0], changes[1])
+	}
+}
+
+func TestTransientShaperStereoLink(t *testing.T) {
+	const sr = 48000
+	// A hit on the left only, over a steady tone on the right
+	hitL := drumHits(4800, 4800, 0.5)
+	toneR := make([]float32, len(hitL))
+	for i := range toneR {
+		toneR[i] = float32(0.1 * math.Sin(2*math.Pi*1000*float64(i)/sr))
+	}
+	outL, outR := make([]float32, len(hitL)), make([]float32, len(hitL))
+
+	ts := NewTransientShaper(sr)
+	ts.SetAttack(9)
+	ts.ProcessStereo(hitL, toneR, outL, outR)
+	if ratioDB(toneR, outR, 0, 480) < 2 {
+		t.Error("linked right channel not boosted with the left hit")
+	}
+
+	// Unlinked, the right channel is shaped as if on its own
+	mono := NewTransientShaper(sr)
+	mono.SetAttack(9)
+	alone := make([]float32, len(toneR))
+	mono.ProcessBuffer(toneR, alone)
+
+	ts = NewTransientShaper(sr)
+	ts.SetAttack(9)
+	ts.SetStereoLink(0)
+	ts.ProcessStereo(hitL, toneR, outL, outR)
+	for i := range outR {
+		if outR[i] != alone[i] {
+			t.Fatalf("unlinked right sample %d is %g, %g on its own", i, outR[i], alone[i])
+		}
+	}
+	if ratioDB(hitL, outL, 0, 240) < 2 {
+		t.Error("unlinked left hit not boosted")
+	}
+}
+
+func TestTransientShaperSettings(t *testing.T) {
+	ts := NewTransientShaper(48000)
+	ts.SetAttack(100)
+	ts.SetSustain(-100)
+	if ts.GetAttack() != MaxTransientGain || ts.GetSustain() != -MaxTransientGain {
+		t.Errorf("gains %g and %g dB, want clamped to %g", ts.GetAttack(), ts.GetSustain(), MaxTransientGain)
+	}
+	ts.SetAttackWindow(0.05)
+	ts.SetSustainWindow(0.5)
+	if ts.GetAttackWindow() != 0.05 || ts.GetSustainWindow() != 0.5 {
+		t.Errorf("windows %g and %g s", ts.GetAttackWindow(), ts.GetSustainWindow())
+	}
+	ts.SetStereoLink(2)
+	if ts.GetStereoLink() != 1 {
+		t.Errorf("stereo link %g, want clamped to 1", ts.GetStereoLink())
+	}
+
+	ts.Process(0.5)
+	ts.Reset()
+	if ts.GetGain() != 0 {
+		t.Errorf("gain %g dB after Reset", ts.GetGain())
+	}
+}
@@ -3,6 +3,8 @@ package envelope
 
 import (
 	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
 )
 
 // DetectorMode defines the envelope detection mode
@@ -140,18 +142,18 @@ func (d *Detector) updateCoefficients() {
 	switch d.detType {
 	case TypeLinear:
 		// Linear coefficients - for one-pole filter approach
-		d.attackCoef = 1.0 - math.Exp(-1.0/(d.attack*d.sampleRate))
-		d.releaseCoef = 1.0 - math.Exp(-1.0/(d.release*d.sampleRate))
+		d.attackCoef = 1.0 - coeff.TimeConstant(d.attack, d.sampleRate)
+		d.releaseCoef = 1.0 - coeff.TimeConstant(d.release, d.sampleRate)
 
 	case TypeLogarithmic:
 		// Logarithmic coefficients (more musical) - faster attack
-		d.attackCoef = 1.0 - math.Exp(-2.2/(d.attack*d.sampleRate))
-		d.releaseCoef = 1.0 - math.Exp(-2.2/(d.release*d.sampleRate))
+		d.attackCoef = 1.0 - coeff.ExpNeg(2.2/(d.attack*d.sampleRate))
+		d.releaseCoef = 1.0 - coeff.ExpNeg(2.2/(d.release*d.sampleRate))
 
 	case TypeAnalog:
 		// Analog-style coefficients - exponential decay
-		d.attackCoef = math.Exp(-1.0 / (d.attack * d.sampleRate))
-		d.releaseCoef = math.Exp(-1.0 / (d.release * d.sampleRate))
+		d.attackCoef = coeff.TimeConstant(d.attack, d.sampleRate)
+		d.releaseCoef = coeff.TimeConstant(d.release, d.sampleRate)
 	}
 }
 
@@ -1,7 +1,11 @@
 // Package envelope provides envelope generators for audio synthesis
 package envelope
 
-import "math"
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+)
 
 // Stage represents the current envelope stage
 type Stage int
@@ -99,10 +103,7 @@ func (e *ADSR) updateCoefficients() {
 
 // calcCoef calculates exponential coefficient for a given time
 func calcCoef(timeSeconds, sampleRate float64) float64 {
-	if timeSeconds <= 0.0 {
-		return 0.0
-	}
-	return math.Exp(-1.0 / (timeSeconds * sampleRate))
+	return coeff.TimeConstant(timeSeconds, sampleRate)
 }
 
 // Trigger starts the envelope (note on)
@@ -0,0 +1,348 @@
+// Package fastmath provides float32 approximations of the transcendental
+// functions that gain, dynamics and saturation code evaluates per sample:
+// exponentials, logarithms, decibel conversion and tanh.
+//
+// Each function is available at four accuracy tiers. Exact calls the math
+// package in float64; the others are branch-light polynomial and rational
+// approximations on float32 bit patterns, so the buffer versions compile to
+// tight loops the compiler can unroll. The largest errors, checked by the
+// package tests over every binade, are:
+//
+//	Tier    Exp2/Exp/DbToLinear  Log2  LinearToDb  Tanh
+//	High    3e-7 relative        2e-7  2e-5 dB     3e-7
+//	Medium  8e-5 relative        4e-5  3e-4 dB     1e-4
+//	Low     2e-3 relative        2e-3  1e-2 dB     3e-2
+//
+// Tanh errors are absolute. Log2 errors are absolute up to |log2 x| = 1
+// and relative beyond, where float32 itself runs out of digits, and
+// LinearToDb errors hold from -120 to +24 dB.
+//
+// High is as close as float32 gets; Medium is inaudible for gain and
+// envelope work; Low suits meters and soft clipping where only the shape
+// matters.
+package fastmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tier selects the accuracy and cost of an approximation
+type Tier int
+
+const (
+	Exact  Tier = iota // The math package in float64
+	High               // Float32 precision
+	Medium             // About 1e-4
+	Low                // About 1e-2, the cheapest
+)
+
+// Conversion constants
+const (
+	log2E      = 1.4426950408889634 // log2(e)
+	dbToLog2   = 0.1660964047443681 // log2(10)/20
+	log2ToDb   = 6.020599913279624  // 20·log10(2)
+	minNormal  = 1.17549435e-38     // Smallest normal float32
+	roundMagic = 6755399441055744   // 1.5·2^52, rounds float64 to integer
+	sqrtHalf   = 0x3f3504f3         // Bits of sqrt(0.5)
+)
+
+// String returns the tier name
+func (t Tier) String() string {
+	switch t {
+	case Exact:
+		return "Exact"
+	case High:
+		return "High"
+	case Medium:
+		return "Medium"
+	case Low:
+		return "Low"
+	default:
+		return fmt.Sprintf("Tier(%d)", int(t))
+	}
+}
+
+// Exp2 returns 2^x
+func (t Tier) Exp2(x float32) float32 {
+	return t.exp2(float64(x))
+}
+
+// Exp returns e^x
+func (t Tier) Exp(x float32) float32 {
+	return t.exp2(float64(x) * log2E)
+}
+
+// exp2 returns 2^y. The argument stays in float64 so scaling into base 2
+// keeps the bits the range reduction needs.
+func (t Tier) exp2(y float64) float32 {
+	switch t {
+	case High:
+		return exp2High(y)
+	case Medium:
+		return exp2Medium(y)
+	case Low:
+		return exp2Low(y)
+	default:
+		return float32(math.Exp2(y))
+	}
+}
+
+// Log2 returns log2(x). Zero, negative and subnormal inputs are handled by
+// the math package.
+func (t Tier) Log2(x float32) float32 {
+	switch t {
+	case High:
+		return log2High(x)
+	case Medium:
+		return log2Medium(x)
+	case Low:
+		return log2Low(x)
+	default:
+		return float32(math.Log2(float64(x)))
+	}
+}
+
+// DbToLinear converts decibels to a linear gain
+func (t Tier) DbToLinear(db float32) float32 {
+	if t == Exact {
+		return float32(math.Pow(10, float64(db)/20))
+	}
+	return t.exp2(float64(db) * dbToLog2)
+}
+
+// LinearToDb converts a linear gain to decibels; zero returns -Inf
+func (t Tier) LinearToDb(linear float32) float32 {
+	if t == Exact {
+		return float32(20 * math.Log10(float64(linear)))
+	}
+	return t.Log2(linear) * log2ToDb
+}
+
+// Tanh returns the hyperbolic tangent of x
+func (t Tier) Tanh(x float32) float32 {
+	switch t {
+	case High:
+		return tanhHigh(x)
+	case Medium:
+		return tanhMedium(x)
+	case Low:
+		return tanhLow(x)
+	default:
+		return float32(math.Tanh(float64(x)))
+	}
+}
+
+// DbToLinearBuffer converts src from decibels to linear gains in dst
+func (t Tier) DbToLinearBuffer(dst, src []float32) {
+	n := min(len(dst), len(src))
+	dst, src = dst[:n], src[:n]
+	switch t {
+	case High:
+		for i, v := range src {
+			dst[i] = exp2High(float64(v) * dbToLog2)
+		}
+	case Medium:
+		for i, v := range src {
+			dst[i] = exp2Medium(float64(v) * dbToLog2)
+		}
+	case Low:
+		for i, v := range src {
+			dst[i] = exp2Low(float64(v) * dbToLog2)
+		}
+	default:
+		for i, v := range src {
+			dst[i] = float32(math.Pow(10, float64(v)/20))
+		}
+	}
+}
+
+// LinearToDbBuffer converts src from linear gains to decibels in dst
+func (t Tier) LinearToDbBuffer(dst, src []float32) {
+	n := min(len(dst), len(src))
+	dst, src = dst[:n], src[:n]
+	switch t {
+	case High:
+		for i, v := range src {
+			dst[i] = log2High(v) * log2ToDb
+		}
+	case Medium:
+		for i, v := range src {
+			dst[i] = log2Medium(v) * log2ToDb
+		}
+	case Low:
+		for i, v := range src {
+			dst[i] = log2Low(v) * log2ToDb
+		}
+	default:
+		for i, v := range src {
+			dst[i] = float32(20 * math.Log10(float64(v)))
+		}
+	}
+}
+
+// TanhBuffer applies tanh to src, writing to dst; they may be the same
+// slice
+func (t Tier) TanhBuffer(dst, src []float32) {
+	n := min(len(dst), len(src))
+	dst, src = dst[:n], src[:n]
+	switch t {
+	case High:
+		for i, v := range src {
+			dst[i] = tanhHigh(v)
+		}
+	case Medium:
+		for i, v := range src {
+			dst[i] = tanhMedium(v)
+		}
+	case Low:
+		for i, v := range src {
+			dst[i] = tanhLow(v)
+		}
+	default:
+		for i, v := range src {
+			dst[i] = float32(math.Tanh(float64(v)))
+		}
+	}
+}
+
+// exp2Split splits y into the nearest integer n and a remainder in
+// [-0.5, 0.5]. ok is false outside the range the bit scaling handles.
+func exp2Split(y float64) (n int32, f float32, ok bool) {
+	if !(y > -125 && y < 127) {
+		return 0, 0, false
+	}
+	r := (y + roundMagic) - roundMagic
+	return int32(r), float32(y - r), true
+}
+
+// scale multiplies p by 2^n through the exponent bits
+func scale(p float32, n int32) float32 {
+	return math.Float32frombits(uint32(int32(math.Float32bits(p)) + n<<23))
+}
+
+// exp2Range handles inputs outside the bit scaling range
+func exp2Range(y float64) float32 {
+	if y <= -125 {
+		return 0
+	}
+	return float32(math.Exp2(y))
+}
+
+func exp2High(y float64) float32 {
+	n, f, ok := exp2Split(y)
+	if !ok {
+		return exp2Range(y)
+	}
+	p := 1.000000071 + f*(0.6931469492+f*(0.2402212175+f*(0.05550742616+f*(0.009675459746+f*0.001326697053))))
+	return scale(p, n)
+}
+
+func exp2Medium(y float64) float32 {
+	n, f, ok := exp2Split(y)
+	if !ok {
+		return exp2Range(y)
+	}
+	p := 0.9999289404 + f*(0.6932762417+f*(0.2426040515+f*0.05508868381))
+	return scale(p, n)
+}
+
+func exp2Low(y float64) float32 {
+	n, f, ok := exp2Split(y)
+	if !ok {
+		return exp2Range(y)
+	}
+	p := 1.000513701 + f*(0.7033965231+f*0.2378304183)
+	return scale(p, n)
+}
+
+// log2Split splits x into an exponent e and t = m-1, with the mantissa m in
+// [sqrt(0.5), sqrt(2)). ok is false for inputs the math package handles.
+func log2Split(x float32) (e, t float32, ok bool) {
+	if !(x >= minNormal && x <= math.MaxFloat32) {
+		return 0, 0, false
+	}
+	bits := int32(math.Float32bits(x))
+	exp := (bits - sqrtHalf) >> 23
+	m := math.Float32frombits(uint32(bits - exp<<23))
+	return float32(exp), m - 1, true
+}
+
+func log2High(x float32) float32 {
+	e, t, ok := log2Split(x)
+	if !ok {
+		return float32(math.Log2(float64(x)))
+	}
+	g := 1.442694958 + t*(-0.7213527588+t*(0.4809240385+t*(-0.3602419853+t*(0.2870756138+t*(-0.2488218158+t*(0.234209833+t*-0.1462034788))))))
+	return e + t*g
+}
+
+func log2Medium(x float32) float32 {
+	e, t, ok := log2Split(x)
+	if !ok {
+		return float32(math.Log2(float64(x)))
+	}
+	g := 1.442647575 + t*(-0.7205412109+t*(0.4852140572+t*(-0.391123173+t*0.2556668716)))
+	return e + t*g
+}
+
+func log2Low(x float32) float32 {
+	e, t, ok := log2Split(x)
+	if !ok {
+		return float32(math.Log2(float64(x)))
+	}
+	g := 1.444060392 + t*(-0.7514093485+t*0.4516402171)
+	return e + t*g
+}
+
+// tanhHigh follows the Cephes single precision tanh: an odd polynomial
+// near zero and the exponential form elsewhere
+func tanhHigh(x float32) float32 {
+	a := x
+	if a < 0 {
+		a = -a
+	}
+	if a < 0.625 {
+		z := x * x
+		return x + x*z*((((-5.70498872745e-3*z+2.06390887954e-2)*z-5.37397155531e-2)*z+1.33314422036e-1)*z-3.33332819422e-1)
+	}
+	if a > 9 {
+		return float32(math.Copysign(1, float64(x)))
+	}
+	y := 1 - 2/(exp2High(2*log2E*float64(a))+1)
+	if x < 0 {
+		return -y
+	}
+	return y
+}
+
+// tanhMedium is the 7/6 order Lambert continued fraction, which reaches 1
+// at about 4.97
+func tanhMedium(x float32) float32 {
+	if x > 4.97 {
+		return 1
+	}
+	if x < -4.97 {
+		return -1
+	}
+	x2 := x * x
+	return clampUnit(x * (135135 + x2*(17325+x2*(378+x2))) / (135135 + x2*(62370+x2*(3150+28*x2))))
+}
+
+// tanhLow is the 3/2 order Padé approximant, which reaches 1 at 3
+func tanhLow(x float32) float32 {
+	if x > 3 {
+		return 1
+	}
+	if x < -3 {
+		return -1
+	}
+	x2 := x * x
+	return clampUnit(x * (27 + x2) / (27 + 9*x2))
+}
+
+// clampUnit limits rounding overshoot of the rational tanh forms near
+// their clipping points
+func clampUnit(y float32) float32 {
+	return max(-1, min(1, y))
+}
@@ -0,0 +1,180 @@
+package fastmath
+
+import (
+	"math"
+	"testing"
+)
+
+// sweep calls fn for float32 values from lo to hi, stepping through the
+// bit patterns so every binade is covered evenly. Outside short mode the
+// stride visits about 4 million values per sign.
+func sweep(t *testing.T, lo, hi float32, fn func(x float32)) {
+	stride := uint32(1)
+	count := func(a, b float32) uint32 { return math.Float32bits(b) - math.Float32bits(a) }
+	var span uint32
+	if lo < 0 {
+		span = count(0, -lo)
+	}
+	if hi > 0 {
+		span = max(span, count(0, hi))
+	}
+	target := uint32(1 << 22)
+	if testing.Short() {
+		target = 1 << 16
+	}
+	if span > target {
+		stride = span / target
+	}
+	visit := func(from, to float32, sign float32) {
+		for b := math.Float32bits(from); b <= math.Float32bits(to); b += stride {
+			fn(sign * math.Float32frombits(b))
+		}
+	}
+	if lo < 0 {
+		visit(max(0, -hi), -lo, -1)
+	}
+	if hi > 0 {
+		visit(max(0, lo), hi, 1)
+	}
+}
+
+type bounds struct {
+	exp2, log2, db, tanh float64
+}
+
+var tierBounds = map[Tier]bounds{
+	High:   {exp2: 3e-7, log2: 2e-7, db: 2e-5, tanh: 3e-7},
+	Medium: {exp2: 8e-5, log2: 4e-5, db: 3e-4, tanh: 1e-4},
+	Low:    {exp2: 2e-3, log2: 2e-3, db: 1e-2, tanh: 3e-2},
+}
+
+func TestExp2Accuracy(t *testing.T) {
+	for tier, b := range tierBounds {
+		worst := 0.0
+		sweep(t, -124, 126, func(x float32) {
+			want := math.Exp2(float64(x))
+			worst = math.Max(worst, math.Abs(float64(tier.Exp2(x))-want)/want)
+		})
+		if worst > b.exp2 {
+			t.Errorf("%v Exp2 relative error %.2e, want at most %.0e", tier, worst, b.exp2)
+		}
+		worst = 0
+		sweep(t, -120, 24, func(db float32) {
+			want := math.Pow(10, float64(db)/20)
+			worst = math.Max(worst, math.Abs(float64(tier.DbToLinear(db))-want)/want)
+		})
+		if worst > b.exp2 {
+			t.Errorf("%v DbToLinear relative error %.2e, want at most %.0e", tier, worst, b.exp2)
+		}
+	}
+}
+
+func TestLog2Accuracy(t *testing.T) {
+	for tier, b := range tierBounds {
+		// Float32 results carry about 7 digits, so large logarithms are
+		// measured relative to their size
+		worstLog, worstDB := 0.0, 0.0
+		sweep(t, minNormal, math.MaxFloat32, func(x float32) {
+			want := math.Log2(float64(x))
+			worstLog = math.Max(worstLog, math.Abs(float64(tier.Log2(x))-want)/math.Max(1, math.Abs(want)))
+		})
+		sweep(t, 1e-6, 16, func(x float32) {
+			worstDB = math.Max(worstDB, math.Abs(float64(tier.LinearToDb(x))-20*math.Log10(float64(x))))
+		})
+		if worstLog > b.log2 {
+			t.Errorf("%v Log2 error %.2e, want at most %.0e", tier, worstLog, b.log2)
+		}
+		if worstDB > b.db {
+			t.Errorf("%v LinearToDb error %.2e dB, want at most %.0e", tier, worstDB, b.db)
+		}
+	}
+}
+
+func TestTanhAccuracy(t *testing.T) {
+	for tier, b := range tierBounds {
+		worst := 0.0
+		sweep(t, -20, 20, func(x float32) {
+			got := tier.Tanh(x)
+			if got > 1 || got < -1 {
+				t.Fatalf("%v Tanh(%g) = %g, outside -1 to 1", tier, x, got)
+			}
+			worst = math.Max(worst, math.Abs(float64(got)-math.Tanh(float64(x))))
+		})
+		if worst > b.tanh {
+			t.Errorf("%v Tanh error %.2e, want at most %.0e", tier, worst, b.tanh)
+		}
+	}
+}
+
+func TestSpecialValues(t *testing.T) {
+	inf := float32(math.Inf(1))
+	for _, tier := range []Tier{Exact, High, Medium, Low} {
+		if got := tier.Exp2(-200); got != 0 {
+			t.Errorf("%v Exp2(-200) = %g, want 0", tier, got)
+		}
+		if got := tier.Exp2(200); got != inf {
+			t.Errorf("%v Exp2(200) = %g, want +Inf", tier, got)
+		}
+		if got := tier.Log2(0); got != -inf {
+			t.Errorf("%v Log2(0) = %g, want -Inf", tier, got)
+		}
+		if got := tier.LinearToDb(1); math.Abs(float64(got)) > 1e-3 {
+			t.Errorf("%v LinearToDb(1) = %g, want 0", tier, got)
+		}
+		if got := tier.Tanh(float32(math.NaN())); got == got {
+			t.Errorf("%v Tanh(NaN) = %g, want NaN", tier, got)
+		}
+		if got := tier.Exp(1); math.Abs(float64(got)-math.E) > 3e-3 {
+			t.Errorf("%v Exp(1) = %g, want e", tier, got)
+		}
+	}
+}
+
+func TestBuffers(t *testing.T) {
+	src := []float32{-60, -6, 0, 6}
+	dst := make([]float32, len(src))
+	for _, tier := range []Tier{Exact, High, Medium, Low} {
+		tier.DbToLinearBuffer(dst, src)
+		for i, v := range src {
+			if dst[i] != tier.DbToLinear(v) {
+				t.Errorf("%v DbToLinearBuffer[%d] = %g, want %g", tier, i, dst[i], tier.DbToLinear(v))
+			}
+		}
+		tier.LinearToDbBuffer(dst, dst)
+		tier.TanhBuffer(dst, dst[:2])
+		if dst[2] != tier.LinearToDb(tier.DbToLinear(0)) {
+			t.Errorf("%v TanhBuffer should stop at the shorter slice", tier)
+		}
+	}
+}
+
+var sink float32
+
+func benchmarkTier(b *testing.B, fn func(x float32) float32, from float32) {
+	for i := 0; i < b.N; i++ {
+		sink += fn(from + float32(i&1023)*0.01)
+	}
+}
+
+func BenchmarkDbToLinearExact(b *testing.B)  { benchmarkTier(b, Exact.DbToLinear, -5) }
+func BenchmarkDbToLinearHigh(b *testing.B)   { benchmarkTier(b, High.DbToLinear, -5) }
+func BenchmarkDbToLinearMedium(b *testing.B) { benchmarkTier(b, Medium.DbToLinear, -5) }
+func BenchmarkDbToLinearLow(b *testing.B)    { benchmarkTier(b, Low.DbToLinear, -5) }
+func BenchmarkLinearToDbExact(b *testing.B)  { benchmarkTier(b, Exact.LinearToDb, 0.001) }
+func BenchmarkLinearToDbHigh(b *testing.B)   { benchmarkTier(b, High.LinearToDb, 0.001) }
+func BenchmarkLinearToDbLow(b *testing.B)    { benchmarkTier(b, Low.LinearToDb, 0.001) }
+func BenchmarkTanhExact(b *testing.B)        { benchmarkTier(b, Exact.Tanh, -5) }
+func BenchmarkTanhHigh(b *testing.B)         { benchmarkTier(b, High.Tanh, -5) }
+func BenchmarkTanhMedium(b *testing.B)       { benchmarkTier(b, Medium.Tanh, -5) }
+func BenchmarkTanhLow(b *testing.B)          { benchmarkTier(b, Low.Tanh, -5) }
+
+func BenchmarkTanhBufferHigh(b *testing.B) {
+	buf := make([]float32, 512)
+	for i := range buf {
+		buf[i] = float32(i)*0.02 - 5
+	}
+	b.SetBytes(int64(len(buf) * 4))
+	for i := 0; i < b.N; i++ {
+		High.TanhBuffer(buf, buf)
+	}
+}
@@ -1,7 +1,13 @@
 // Package filter provides digital signal processing filters
 package filter
 
-import "math"
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/vector"
+)
 
 // Biquad implements a second-order IIR filter (biquad)
 // Direct Form I implementation with pre-allocated state
@@ -79,22 +85,18 @@ func (b *Biquad) Process(buffer []float32, channel int) {
 	b.y2[channel] = y2
 }
 
-// ProcessMulti applies the filter to multiple channels - no allocations
+// ProcessMulti applies the filter to multiple channels, up to four at a
+// time with SIMD where available - no allocations
 func (b *Biquad) ProcessMulti(buffers [][]float32) {
-	for ch, buffer := range buffers {
-		if ch < len(b.x1) {
-			b.Process(buffer, ch)
-		}
-	}
+	buffers = buffers[:min(len(buffers), len(b.x1))]
+	vector.Biquad(buffers, b.b0, b.b1, b.b2, b.a1, b.a2, b.x1, b.x2, b.y1, b.y2)
 }
 
 // Design functions for common filter types
 
 // SetLowpass configures as a lowpass filter
 func (b *Biquad) SetLowpass(sampleRate, frequency, q float64) {
-	omega := 2.0 * math.Pi * frequency / sampleRate
-	sinOmega := math.Sin(omega)
-	cosOmega := math.Cos(omega)
+	sinOmega, cosOmega := coeff.SinCos(frequency, sampleRate)
 	alpha := sinOmega / (2.0 * q)
 
 	b0 := (1.0 - cosOmega) / 2.0
@@ -110,9 +112,7 @@ func (b *Biquad) SetLowpass(sampleRate, frequency, q float64) {
 
 // SetHighpass configures as a highpass filter
 func (b *Biquad) SetHighpass(sampleRate, frequency, q float64) {
-	omega := 2.0 * math.Pi * frequency / sampleRate
-	sinOmega := math.Sin(omega)
-	cosOmega := math.Cos(omega)
+	sinOmega, cosOmega := coeff.SinCos(frequency, sampleRate)
 	alpha := sinOmega / (2.0 * q)
 
 	b0 := (1.0 + cosOmega) / 2.0
@@ -128,9 +128,7 @@ func (b *Biquad) SetHighpass(sampleRate, frequency, q float64) {
 
 // SetBandpass configures as a bandpass filter (constant skirt gain)
 func (b *Biquad) SetBandpass(sampleRate, frequency, q float64) {
-	omega := 2.0 * math.Pi * frequency / sampleRate
-	sinOmega := math.Sin(omega)
-	cosOmega := math.Cos(omega)
+	sinOmega, cosOmega := coeff.SinCos(frequency, sampleRate)
 	alpha := sinOmega / (2.0 * q)
 
 	b0 := alpha
@@ -146,9 +144,7 @@ func (b *Biquad) SetBandpass(sampleRate, frequency, q float64) {
 
 // SetNotch configures as a notch (band-reject) filter
 func (b *Biquad) SetNotch(sampleRate, frequency, q float64) {
-	omega := 2.0 * math.Pi * frequency / sampleRate
-	sinOmega := math.Sin(omega)
-	cosOmega := math.Cos(omega)
+	sinOmega, cosOmega := coeff.SinCos(frequency, sampleRate)
 	alpha := sinOmega / (2.0 * q)
 
 	b0 := 1.0
@@ -164,9 +160,7 @@ func (b *Biquad) SetNotch(sampleRate, frequency, q float64) {
 
 // SetAllpass configures as an allpass filter
 func (b *Biquad) SetAllpass(sampleRate, frequency, q float64) {
-	omega := 2.0 * math.Pi * frequency / sampleRate
-	sinOmega := math.Sin(omega)
-	cosOmega := math.Cos(omega)
+	sinOmega, cosOmega := coeff.SinCos(frequency, sampleRate)
 	alpha := sinOmega / (2.0 * q)
 
 	b0 := 1.0 - alpha
@@ -182,9 +176,7 @@ func (b *Biquad) SetAllpass(sampleRate, frequency, q float64) {
 
 // SetPeakingEQ configures as a peaking EQ filter
 func (b *Biquad) SetPeakingEQ(sampleRate, frequency, q, gainDB float64) {
-	omega := 2.0 * math.Pi * frequency / sampleRate
-	sinOmega := math.Sin(omega)
-	cosOmega := math.Cos(omega)
+	sinOmega, cosOmega := coeff.SinCos(frequency, sampleRate)
 	A := math.Pow(10.0, gainDB/40.0)
 	alpha := sinOmega / (2.0 * q)
 
@@ -201,9 +193,7 @@ func (b *Biquad) SetPeakingEQ(sampleRate, frequency, q, gainDB float64) {
 
 // SetLowShelf configures as a low shelf filter
 func (b *Biquad) SetLowShelf(sampleRate, frequency, q, gainDB float64) {
-	omega := 2.0 * math.Pi * frequency / sampleRate
-	sinOmega := math.Sin(omega)
-	cosOmega := math.Cos(omega)
+	sinOmega, cosOmega := coeff.SinCos(frequency, sampleRate)
 	A := math.Pow(10.0, gainDB/40.0)
 	alpha := sinOmega / (2.0 * q)
 
@@ -223,9 +213,7 @@ func (b *Biquad) SetLowShelf(sampleRate, frequency, q, gainDB float64) {
 
 // SetHighShelf configures as a high shelf filter
 func (b *Biquad) SetHighShelf(sampleRate, frequency, q, gainDB float64) {
-	omega := 2.0 * math.Pi * frequency / sampleRate
-	sinOmega := math.Sin(omega)
-	cosOmega := math.Cos(omega)
+	sinOmega, cosOmega := coeff.SinCos(frequency, sampleRate)
 	A := math.Pow(10.0, gainDB/40.0)
 	alpha := sinOmega / (2.0 * q)
 
@@ -242,3 +230,13 @@ func (b *Biquad) SetHighShelf(sampleRate, frequency, q, gainDB float64) {
 	b.SetCoefficients(float32(b0), float32(b1), float32(b2),
 		float32(a0), float32(a1), float32(a2))
 }
+
+// Response returns the complex frequency response at a frequency in Hz
+func (b *Biquad) Response(sampleRate, frequency float64) complex128 {
+	w := 2.0 * math.Pi * frequency / sampleRate
+	z1 := cmplx.Exp(complex(0, -w))
+	z2 := z1 * z1
+	num := complex(float64(b.b0), 0) + complex(float64(b.b1), 0)*z1 + complex(float64(b.b2), 0)*z2
+	den := 1 + complex(float64(b.a1), 0)*z1 + complex(float64(b.a2), 0)*z2
+	return num / den
+}
@@ -0,0 +1,229 @@
+package filter
+
+import "math"
+
+// Convolver filters audio with long FIR filters or impulse responses using
+// uniformly partitioned FFT convolution (overlap-save). The filter is split
+// into partitions of the block size, so the cost per sample grows with the
+// logarithm of the block size rather than the filter length. Output is
+// delayed by one block; see Latency. Processing does not allocate.
+type Convolver struct {
+	blockSize  int
+	partitions int
+	fft        *fftPlan
+
+	// Filter partition spectra, 2*blockSize bins each
+	hRe, hIm [][]float64
+
+	channels []*convolverChannel
+
+	// Scratch for one block, shared by all channels
+	re, im []float64
+}
+
+// convolverChannel holds the streaming state of one channel
+type convolverChannel struct {
+	input  []float64   // Previous and current input block
+	output []float32   // Output block being played
+	pos    int         // Position within the current block
+	xRe    [][]float64 // Input spectra, a ring of partitions
+	xIm    [][]float64
+	head   int // Ring slot of the newest spectrum
+}
+
+// NewConvolver creates a convolver for the given filter coefficients. The
+// block size is rounded up to a power of two.
+func NewConvolver(h []float64, blockSize, channels int) *Convolver {
+	size := 1
+	for size < max(1, blockSize) {
+		size <<= 1
+	}
+	partitions := max(1, (len(h)+size-1)/size)
+	n := 2 * size
+
+	c := &Convolver{
+		blockSize:  size,
+		partitions: partitions,
+		fft:        newFFTPlan(n),
+		hRe:        make([][]float64, partitions),
+		hIm:        make([][]float64, partitions),
+		channels:   make([]*convolverChannel, channels),
+		re:         make([]float64, n),
+		im:         make([]float64, n),
+	}
+
+	for p := range c.hRe {
+		re := make([]float64, n)
+		im := make([]float64, n)
+		if p*size < len(h) {
+			copy(re, h[p*size:min(len(h), (p+1)*size)])
+		}
+		c.fft.transform(re, im, false)
+		c.hRe[p], c.hIm[p] = re, im
+	}
+
+	for ch := range c.channels {
+		state := &convolverChannel{
+			input:  make([]float64, n),
+			output: make([]float32, size),
+			xRe:    make([][]float64, partitions),
+			xIm:    make([][]float64, partitions),
+		}
+		for p := range state.xRe {
+			state.xRe[p] = make([]float64, n)
+			state.xIm[p] = make([]float64, n)
+		}
+		c.channels[ch] = state
+	}
+	return c
+}
+
+// Latency returns the delay in samples added by block processing. For a
+// linear-phase filter add FIRLatency of its length.
+func (c *Convolver) Latency() int {
+	return c.blockSize
+}
+
+// BlockSize returns the partition size in samples
+func (c *Convolver) BlockSize() int {
+	return c.blockSize
+}
+
+// ProcessSample filters one sample on the given channel
+func (c *Convolver) ProcessSample(input float32, channel int) float32 {
+	s := c.channels[channel]
+	s.input[c.blockSize+s.pos] = float64(input)
+	out := s.output[s.pos]
+	s.pos++
+	if s.pos == c.blockSize {
+		c.processBlock(s)
+		s.pos = 0
+	}
+	return out
+}
+
+// ProcessBuffer filters a buffer in place on the given channel
+func (c *Convolver) ProcessBuffer(buffer []float32, channel int) {
+	if channel >= len(c.channels) {
+		return
+	}
+	for i, x := range buffer {
+		buffer[i] = c.ProcessSample(x, channel)
+	}
+}
+
+// processBlock convolves the completed input block
+func (c *Convolver) processBlock(s *convolverChannel) {
+	n := 2 * c.blockSize
+
+	// Spectrum of the last two input blocks
+	s.head = (s.head + 1) % c.partitions
+	xRe, xIm := s.xRe[s.head], s.xIm[s.head]
+	copy(xRe, s.input)
+	clear(xIm)
+	c.fft.transform(xRe, xIm, false)
+
+	// Multiply-accumulate each partition with the matching older input
+	clear(c.re)
+	clear(c.im)
+	for p := 0; p < c.partitions; p++ {
+		slot := (s.head - p + c.partitions) % c.partitions
+		ar, ai := s.xRe[slot], s.xIm[slot]
+		br, bi := c.hRe[p], c.hIm[p]
+		for k := 0; k < n; k++ {
+			c.re[k] += ar[k]*br[k] - ai[k]*bi[k]
+			c.im[k] += ar[k]*bi[k] + ai[k]*br[k]
+		}
+	}
+	c.fft.transform(c.re, c.im, true)
+
+	// The second half is free of circular wrap-around
+	for i := range s.output {
+		s.output[i] = float32(c.re[c.blockSize+i])
+	}
+	copy(s.input, s.input[c.blockSize:])
+}
+
+// Reset clears the input history of every channel
+func (c *Convolver) Reset() {
+	for _, s := range c.channels {
+		clear(s.input)
+		clear(s.output)
+		for p := range s.xRe {
+			clear(s.xRe[p])
+			clear(s.xIm[p])
+		}
+		s.pos, s.head = 0, 0
+	}
+}
+
+// fftPlan is a radix-2 complex FFT with precomputed tables
+type fftPlan struct {
+	n        int
+	cos, sin []float64
+	rev      []int
+}
+
+func newFFTPlan(n int) *fftPlan {
+	p := &fftPlan{
+		n:   n,
+		cos: make([]float64, n/2),
+		sin: make([]float64, n/2),
+		rev: make([]int, n),
+	}
+	for i := range p.cos {
+		p.cos[i] = math.Cos(2 * math.Pi * float64(i) / float64(n))
+		p.sin[i] = math.Sin(2 * math.Pi * float64(i) / float64(n))
+	}
+	bits := 0
+	for 1<<bits < n {
+		bits++
+	}
+	for i := range p.rev {
+		r := 0
+		for b := 0; b < bits; b++ {
+			r |= (i >> b & 1) << (bits - 1 - b)
+		}
+		p.rev[i] = r
+	}
+	return p
+}
+
+// transform runs the FFT in place; the inverse is scaled by 1/n
+func (p *fftPlan) transform(re, im []float64, inverse bool) {
+	n := p.n
+	for i, r := range p.rev {
+		if i < r {
+			re[i], re[r] = re[r], re[i]
+			im[i], im[r] = im[r], im[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		step := n / size
+		for start := 0; start < n; start += size {
+			for j := 0; j < half; j++ {
+				wr, wi := p.cos[j*step], sign*p.sin[j*step]
+				a, b := start+j, start+j+half
+				tr := wr*re[b] - wi*im[b]
+				ti := wr*im[b] + wi*re[b]
+				re[b], im[b] = re[a]-tr, im[a]-ti
+				re[a] += tr
+				im[a] += ti
+			}
+		}
+	}
+
+	if inverse {
+		scale := 1 / float64(n)
+		for i := range re {
+			re[i] *= scale
+			im[i] *= scale
+		}
+	}
+}
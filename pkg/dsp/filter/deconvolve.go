@@ -0,0 +1,238 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidDeconvolution is returned for signals or options that
+// Deconvolve, InverseFilter and MatchEQ cannot work with
+var ErrInvalidDeconvolution = errors.New("invalid deconvolution")
+
+const (
+	// DefaultRegularization keeps inverse filtering 40 dB below the peak
+	// power of the reference
+	DefaultRegularization = 1e-4
+
+	// DefaultMatchTaps is the MatchEQ filter length when none is given
+	DefaultMatchTaps = 2047
+
+	// DefaultMatchSmoothing is the MatchEQ smoothing in octaves
+	DefaultMatchSmoothing = 1.0 / 3
+)
+
+// DeconvolveOptions configures Deconvolve, InverseFilter and MatchEQ
+type DeconvolveOptions struct {
+	// Taps is the length of the result. Zero uses the longer of the two
+	// signals for Deconvolve and InverseFilter, and DefaultMatchTaps for
+	// MatchEQ, which needs an odd length.
+	Taps int
+
+	// Delay shifts the result later by this many samples, leaving room
+	// for pre-ringing and for the non-causal part of an inverse
+	Delay int
+
+	// Regularization is the noise floor added to the reference power,
+	// relative to its peak. Larger values trade accuracy for less boost
+	// where the reference is weak; zero uses DefaultRegularization.
+	Regularization float64
+
+	// LowHz and HighHz limit the inversion to a band. Outside it the floor
+	// rises to the peak power, so the result cannot boost frequencies the
+	// reference never excited. Zero leaves that side open; both need
+	// SampleRate.
+	LowHz, HighHz float64
+	SampleRate    float64
+
+	// Smoothing is the MatchEQ averaging width in octaves; zero uses
+	// DefaultMatchSmoothing
+	Smoothing float64
+}
+
+// Deconvolve derives the impulse response h that turns reference into
+// recorded, recorded = reference * h, by regularized inverse filtering in
+// the frequency domain. Use it to capture an IR from a sweep or noise
+// played through a device, then load the result into a Convolver. The
+// signals must be aligned to within Delay samples.
+func Deconvolve(reference, recorded []float64, opts DeconvolveOptions) ([]float64, error) {
+	taps := opts.Taps
+	if taps == 0 {
+		taps = max(len(reference), len(recorded))
+	}
+	if err := checkDeconvolution(reference, recorded, taps, opts); err != nil {
+		return nil, err
+	}
+
+	// Long enough that the linear convolution does not wrap around, and
+	// that an inverse has room to decay before it does
+	n := 1
+	for n < max(len(reference)+len(recorded), 2*taps)+opts.Delay {
+		n <<= 1
+	}
+	plan := newFFTPlan(n)
+	xRe, xIm := spectrum(plan, reference)
+	yRe, yIm := spectrum(plan, recorded)
+	floor := regularization(plan, xRe, xIm, opts)
+
+	// H = Y X* / (|X|^2 + floor)
+	for k := range xRe {
+		d := xRe[k]*xRe[k] + xIm[k]*xIm[k] + floor[k]
+		re := (yRe[k]*xRe[k] + yIm[k]*xIm[k]) / d
+		im := (yIm[k]*xRe[k] - yRe[k]*xIm[k]) / d
+		xRe[k], xIm[k] = re, im
+	}
+	plan.transform(xRe, xIm, true)
+
+	// Negative times sit at the end of the circular result
+	h := make([]float64, taps)
+	for i := range h {
+		h[i] = xRe[((i-opts.Delay)%n+n)%n]
+	}
+	return h, nil
+}
+
+// InverseFilter designs a filter that undoes h, so that h * inverse is an
+// impulse at Delay samples, e.g. to correct a speaker or room response
+// measured with Deconvolve. Minimum-phase responses invert with no delay;
+// others need Delay of about half of Taps.
+func InverseFilter(h []float64, opts DeconvolveOptions) ([]float64, error) {
+	return Deconvolve(h, []float64{1}, opts)
+}
+
+// MatchEQ designs a linear-phase filter that gives reference the spectral
+// balance of recorded: the ratio of their power spectra, smoothed over
+// Smoothing octaves. The signals need not be aligned or even the same
+// material, so it matches one mix or instrument to another. Outside
+// LowHz..HighHz the filter keeps unity gain.
+func MatchEQ(reference, recorded []float64, opts DeconvolveOptions) ([]float64, error) {
+	taps := opts.Taps
+	if taps == 0 {
+		taps = DefaultMatchTaps
+	}
+	if err := checkDeconvolution(reference, recorded, taps, opts); err != nil {
+		return nil, err
+	}
+	smoothing := opts.Smoothing
+	if smoothing == 0 {
+		smoothing = DefaultMatchSmoothing
+	}
+	if smoothing < 0 {
+		return nil, fmt.Errorf("%w: smoothing of %g octaves", ErrInvalidDeconvolution, smoothing)
+	}
+
+	n := 1
+	for n < max(len(reference), len(recorded), 2*taps) {
+		n <<= 1
+	}
+	plan := newFFTPlan(n)
+	refPower := smoothedPower(plan, reference, smoothing)
+	recPower := smoothedPower(plan, recorded, smoothing)
+
+	// Both signals are compared at equal loudness per sample
+	scale := float64(len(reference)) / float64(len(recorded))
+	peak := 0.0
+	for _, p := range refPower {
+		peak = max(peak, p)
+	}
+	floor := peak * regularizationOrDefault(opts.Regularization)
+
+	sampleRate := opts.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 2 // Frequencies relative to Nyquist
+	}
+	gains := make([]float64, len(refPower))
+	for k := range gains {
+		hz := float64(k) * sampleRate / float64(n)
+		if (opts.LowHz > 0 && hz < opts.LowHz) || (opts.HighHz > 0 && hz > opts.HighHz) {
+			gains[k] = 1
+			continue
+		}
+		gains[k] = math.Sqrt(recPower[k] * scale / (refPower[k] + floor))
+	}
+
+	gain := func(hz float64) float64 {
+		pos := hz / sampleRate * float64(n)
+		k := min(int(pos), len(gains)-2)
+		frac := pos - float64(k)
+		return gains[k] + frac*(gains[k+1]-gains[k])
+	}
+	return FIRFromResponse(taps, gain, sampleRate, nil)
+}
+
+// checkDeconvolution validates the common inputs
+func checkDeconvolution(reference, recorded []float64, taps int, opts DeconvolveOptions) error {
+	switch {
+	case len(reference) == 0 || len(recorded) == 0:
+		return fmt.Errorf("%w: empty signal", ErrInvalidDeconvolution)
+	case taps < 1 || opts.Delay < 0 || opts.Delay >= taps:
+		return fmt.Errorf("%w: %d taps with a delay of %d", ErrInvalidDeconvolution, taps, opts.Delay)
+	case opts.Regularization < 0:
+		return fmt.Errorf("%w: negative regularization %g", ErrInvalidDeconvolution, opts.Regularization)
+	case (opts.LowHz != 0 || opts.HighHz != 0) && opts.SampleRate <= 0:
+		return fmt.Errorf("%w: band limits need a sample rate", ErrInvalidDeconvolution)
+	case opts.LowHz < 0 || (opts.HighHz != 0 && opts.HighHz <= opts.LowHz):
+		return fmt.Errorf("%w: band %g-%g Hz", ErrInvalidDeconvolution, opts.LowHz, opts.HighHz)
+	}
+	return nil
+}
+
+func regularizationOrDefault(r float64) float64 {
+	if r == 0 {
+		return DefaultRegularization
+	}
+	return r
+}
+
+// spectrum returns the zero-padded transform of x
+func spectrum(plan *fftPlan, x []float64) (re, im []float64) {
+	re = make([]float64, plan.n)
+	im = make([]float64, plan.n)
+	copy(re, x)
+	plan.transform(re, im, false)
+	return re, im
+}
+
+// regularization returns the floor for every bin of the reference spectrum
+func regularization(plan *fftPlan, re, im []float64, opts DeconvolveOptions) []float64 {
+	peak := 0.0
+	for k := range re {
+		peak = max(peak, re[k]*re[k]+im[k]*im[k])
+	}
+	inBand := peak * regularizationOrDefault(opts.Regularization)
+
+	floor := make([]float64, plan.n)
+	for k := range floor {
+		// Bins above n/2 mirror the negative frequencies
+		bin := min(k, plan.n-k)
+		hz := float64(bin) * opts.SampleRate / float64(plan.n)
+		if (opts.LowHz > 0 && hz < opts.LowHz) || (opts.HighHz > 0 && hz > opts.HighHz) {
+			floor[k] = peak
+		} else {
+			floor[k] = inBand
+		}
+	}
+	return floor
+}
+
+// smoothedPower returns the power spectrum of x from DC to Nyquist,
+// averaged over a band of the given width in octaves around each bin
+func smoothedPower(plan *fftPlan, x []float64, octaves float64) []float64 {
+	re, im := spectrum(plan, x)
+	bins := plan.n/2 + 1
+
+	// Prefix sums make every band average O(1)
+	sums := make([]float64, bins+1)
+	for k := 0; k < bins; k++ {
+		sums[k+1] = sums[k] + re[k]*re[k] + im[k]*im[k]
+	}
+
+	half := math.Exp2(octaves / 2)
+	power := make([]float64, bins)
+	for k := range power {
+		lo := int(math.Floor(float64(k) / half))
+		hi := min(bins-1, int(math.Ceil(float64(k)*half)))
+		power[k] = (sums[hi+1] - sums[lo]) / float64(hi-lo+1)
+	}
+	return power
+}
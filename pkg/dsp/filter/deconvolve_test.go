@@ -0,0 +1,177 @@
+package filter
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// convolve returns the full linear convolution of x and h
+func convolve(x, h []float64) []float64 {
+	y := make([]float64, len(x)+len(h)-1)
+	for i, a := range x {
+		for j, b := range h {
+			y[i+j] += a * b
+		}
+	}
+	return y
+}
+
+func whiteNoise(n int, seed int64) []float64 {
+	r := rand.New(rand.NewSource(seed))
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = r.NormFloat64()
+	}
+	return x
+}
+
+func TestDeconvolveRecoversIR(t *testing.T) {
+	// A decaying, ringing response
+	ir := make([]float64, 64)
+	for i := range ir {
+		ir[i] = math.Exp(-float64(i)/10) * math.Cos(0.7*float64(i))
+	}
+	reference := whiteNoise(4096, 1)
+	recorded := convolve(reference, ir)
+
+	got, err := Deconvolve(reference, recorded, DeconvolveOptions{Taps: 80, Regularization: 1e-9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range append(ir, make([]float64, 16)...) {
+		if math.Abs(got[i]-want) > 1e-3 {
+			t.Fatalf("tap %d = %g, want %g", i, got[i], want)
+		}
+	}
+
+	// Delay shifts the result, keeping taps before the reference
+	delayed, err := Deconvolve(reference, recorded, DeconvolveOptions{Taps: 80, Delay: 5, Regularization: 1e-9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 75; i++ {
+		if math.Abs(delayed[i+5]-got[i]) > 1e-9 {
+			t.Fatalf("delayed tap %d = %g, want %g", i+5, delayed[i+5], got[i])
+		}
+	}
+}
+
+func TestInverseFilter(t *testing.T) {
+	// Minimum phase, so the inverse is causal: 1 / (1 + 0.5 z^-1)
+	h := []float64{1, 0.5}
+	inv, err := InverseFilter(h, DeconvolveOptions{Taps: 32, Regularization: 1e-12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := convolve(h, inv)
+	for i, v := range y[:32] {
+		want := 0.0
+		if i == 0 {
+			want = 1
+		}
+		if math.Abs(v-want) > 1e-6 {
+			t.Fatalf("h * inverse at %d = %g, want %g", i, v, want)
+		}
+	}
+
+	// Maximum phase needs delay: 0.5 + z^-1
+	h = []float64{0.5, 1}
+	inv, err = InverseFilter(h, DeconvolveOptions{Taps: 64, Delay: 32, Regularization: 1e-12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	y = convolve(h, inv)
+	if math.Abs(y[32]-1) > 1e-6 || math.Abs(y[10]) > 1e-6 || math.Abs(y[50]) > 1e-6 {
+		t.Errorf("h * inverse = %g at the delay, %g and %g elsewhere", y[32], y[10], y[50])
+	}
+}
+
+func TestDeconvolveBandLimit(t *testing.T) {
+	// A reference with no energy above a quarter of the sample rate
+	lowpass, err := FIRLowpass(127, 8000, 48000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference := convolve(whiteNoise(8192, 2), lowpass)
+	recorded := convolve(reference, []float64{1})
+
+	// The band edge rings on both sides, so leave room before it
+	open, err := Deconvolve(reference, recorded, DeconvolveOptions{Taps: 256, Delay: 128, Regularization: 1e-12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	banded, err := Deconvolve(reference, recorded, DeconvolveOptions{
+		Taps: 256, Delay: 128, Regularization: 1e-12, HighHz: 10000, SampleRate: 48000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db := FIRMagnitudeDB(banded, 1000, 48000); math.Abs(db) > 0.1 {
+		t.Errorf("in-band gain %.2f dB, want 0", db)
+	}
+	// Without the limit the stopband is amplified noise; with it the
+	// result stays at or below the reference's level there
+	if open, banded := FIRMagnitudeDB(open, 18000, 48000), FIRMagnitudeDB(banded, 18000, 48000); banded > -3 || banded >= open {
+		t.Errorf("gain at 18 kHz %.1f dB banded, %.1f dB open", banded, open)
+	}
+}
+
+func TestMatchEQ(t *testing.T) {
+	// recorded is reference through a high shelf, and only loosely related
+	// sample by sample
+	shelf, err := FIRFromResponse(255, func(hz float64) float64 {
+		if hz > 4000 {
+			return 0.5
+		}
+		return 1
+	}, 48000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference := whiteNoise(1<<16, 3)
+	recorded := convolve(whiteNoise(1<<15, 4), shelf)
+
+	h, err := MatchEQ(reference, recorded, DeconvolveOptions{Taps: 511, SampleRate: 48000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 511 {
+		t.Fatalf("%d taps, want 511", len(h))
+	}
+	for _, tc := range []struct{ hz, db float64 }{{500, 0}, {1500, 0}, {10000, -6}, {16000, -6}} {
+		if got := FIRMagnitudeDB(h, tc.hz, 48000); math.Abs(got-tc.db) > 1 {
+			t.Errorf("match at %g Hz = %.2f dB, want %g", tc.hz, got, tc.db)
+		}
+	}
+
+	// Unity outside the band
+	h, err = MatchEQ(reference, recorded, DeconvolveOptions{Taps: 511, SampleRate: 48000, HighHz: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FIRMagnitudeDB(h, 12000, 48000); math.Abs(got) > 0.5 {
+		t.Errorf("gain above the band %.2f dB, want 0", got)
+	}
+}
+
+func TestDeconvolveErrors(t *testing.T) {
+	x := []float64{1, 2, 3}
+	for name, opts := range map[string]DeconvolveOptions{
+		"delay past taps":   {Taps: 4, Delay: 4},
+		"negative reg":      {Regularization: -1},
+		"band without rate": {HighHz: 1000},
+		"inverted band":     {LowHz: 2000, HighHz: 1000, SampleRate: 48000},
+	} {
+		if _, err := Deconvolve(x, x, opts); !errors.Is(err, ErrInvalidDeconvolution) {
+			t.Errorf("%s: %v, want ErrInvalidDeconvolution", name, err)
+		}
+	}
+	if _, err := Deconvolve(nil, x, DeconvolveOptions{}); !errors.Is(err, ErrInvalidDeconvolution) {
+		t.Errorf("empty reference: %v", err)
+	}
+	if _, err := MatchEQ(x, x, DeconvolveOptions{Taps: 4}); !errors.Is(err, ErrInvalidDesign) {
+		t.Errorf("even MatchEQ taps: %v, want ErrInvalidDesign", err)
+	}
+}
@@ -0,0 +1,234 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// BandType selects the shape of a ParametricEQ band
+type BandType int
+
+const (
+	BandPeak BandType = iota
+	BandLowShelf
+	BandHighShelf
+	BandNotch
+	BandLowpass
+	BandHighpass
+	BandBandpass
+)
+
+// String returns the name of the band type
+func (t BandType) String() string {
+	switch t {
+	case BandPeak:
+		return "Peak"
+	case BandLowShelf:
+		return "Low Shelf"
+	case BandHighShelf:
+		return "High Shelf"
+	case BandNotch:
+		return "Notch"
+	case BandLowpass:
+		return "Low-pass"
+	case BandHighpass:
+		return "High-pass"
+	case BandBandpass:
+		return "Band-pass"
+	default:
+		return "Unknown"
+	}
+}
+
+// EQBand describes one band of a ParametricEQ. GainDB is only used by
+// peaks and shelves.
+type EQBand struct {
+	Type      BandType
+	Frequency float64 // Hz
+	Q         float64
+	GainDB    float64
+	Enabled   bool
+}
+
+// FrequencyResponse is the combined response of an EQ at a set of
+// frequencies
+type FrequencyResponse struct {
+	Frequencies []float64
+	Magnitude   []float64 // dB
+	Phase       []float64 // Radians, wrapped to ±π
+}
+
+// ParametricEQ is a chain of biquad bands with per-band enable and a
+// frequency response for drawing the curve
+type ParametricEQ struct {
+	sampleRate float64
+	bands      []EQBand
+	filters    []*Biquad
+}
+
+// NewParametricEQ creates an EQ with the given number of disabled peak
+// bands, spread logarithmically from 100 Hz to 10 kHz
+func NewParametricEQ(bands, channels int, sampleRate float64) *ParametricEQ {
+	eq := &ParametricEQ{
+		sampleRate: sampleRate,
+		bands:      make([]EQBand, bands),
+		filters:    make([]*Biquad, bands),
+	}
+	for i := range eq.bands {
+		freq := 1000.0
+		if bands > 1 {
+			freq = 100 * math.Pow(100, float64(i)/float64(bands-1))
+		}
+		eq.bands[i] = EQBand{Type: BandPeak, Frequency: freq, Q: 0.707}
+		eq.filters[i] = NewBiquad(channels)
+		eq.design(i)
+	}
+	return eq
+}
+
+// NumBands returns the number of bands
+func (eq *ParametricEQ) NumBands() int {
+	return len(eq.bands)
+}
+
+// Band returns the settings of a band
+func (eq *ParametricEQ) Band(index int) EQBand {
+	if index < 0 || index >= len(eq.bands) {
+		return EQBand{}
+	}
+	return eq.bands[index]
+}
+
+// SetBand replaces the settings of a band
+func (eq *ParametricEQ) SetBand(index int, band EQBand) {
+	if index < 0 || index >= len(eq.bands) {
+		return
+	}
+	wasEnabled := eq.bands[index].Enabled
+	eq.bands[index] = band
+	eq.design(index)
+	if band.Enabled && !wasEnabled {
+		// Don't replay stale state from before the band was switched off
+		eq.filters[index].Reset()
+	}
+}
+
+// SetEnabled switches a band on or off
+func (eq *ParametricEQ) SetEnabled(index int, enabled bool) {
+	if index < 0 || index >= len(eq.bands) {
+		return
+	}
+	band := eq.bands[index]
+	band.Enabled = enabled
+	eq.SetBand(index, band)
+}
+
+// SetSampleRate redesigns every band for a new sample rate
+func (eq *ParametricEQ) SetSampleRate(sampleRate float64) {
+	eq.sampleRate = sampleRate
+	for i := range eq.bands {
+		eq.design(i)
+	}
+}
+
+// design computes the coefficients of one band
+func (eq *ParametricEQ) design(index int) {
+	band := eq.bands[index]
+	f := eq.filters[index]
+	freq := math.Max(1, math.Min(band.Frequency, eq.sampleRate*0.49))
+	q := band.Q
+	if q <= 0 {
+		q = 0.707
+	}
+
+	switch band.Type {
+	case BandLowShelf:
+		f.SetLowShelf(eq.sampleRate, freq, q, band.GainDB)
+	case BandHighShelf:
+		f.SetHighShelf(eq.sampleRate, freq, q, band.GainDB)
+	case BandNotch:
+		f.SetNotch(eq.sampleRate, freq, q)
+	case BandLowpass:
+		f.SetLowpass(eq.sampleRate, freq, q)
+	case BandHighpass:
+		f.SetHighpass(eq.sampleRate, freq, q)
+	case BandBandpass:
+		f.SetBandpass(eq.sampleRate, freq, q)
+	default:
+		f.SetPeakingEQ(eq.sampleRate, freq, q, band.GainDB)
+	}
+}
+
+// Process applies the enabled bands to a buffer (single channel) - no
+// allocations
+func (eq *ParametricEQ) Process(buffer []float32, channel int) {
+	for i, band := range eq.bands {
+		if band.Enabled {
+			eq.filters[i].Process(buffer, channel)
+		}
+	}
+}
+
+// ProcessMulti applies the enabled bands to multiple channels - no
+// allocations
+func (eq *ParametricEQ) ProcessMulti(buffers [][]float32) {
+	for i, band := range eq.bands {
+		if band.Enabled {
+			eq.filters[i].ProcessMulti(buffers)
+		}
+	}
+}
+
+// Reset clears the state of every band
+func (eq *ParametricEQ) Reset() {
+	for _, f := range eq.filters {
+		f.Reset()
+	}
+}
+
+// Response returns the complex response of the enabled bands at a
+// frequency in Hz
+func (eq *ParametricEQ) Response(frequency float64) complex128 {
+	h := complex(1, 0)
+	for i, band := range eq.bands {
+		if band.Enabled {
+			h *= eq.filters[i].Response(eq.sampleRate, frequency)
+		}
+	}
+	return h
+}
+
+// GetFrequencyResponse evaluates the magnitude and phase of the enabled
+// bands at each frequency, for drawing the EQ curve
+func (eq *ParametricEQ) GetFrequencyResponse(freqs []float64) FrequencyResponse {
+	r := FrequencyResponse{
+		Frequencies: freqs,
+		Magnitude:   make([]float64, len(freqs)),
+		Phase:       make([]float64, len(freqs)),
+	}
+	for i, f := range freqs {
+		h := eq.Response(f)
+		mag := cmplx.Abs(h)
+		if mag <= 1e-6 {
+			r.Magnitude[i] = -120.0
+		} else {
+			r.Magnitude[i] = 20.0 * math.Log10(mag)
+		}
+		r.Phase[i] = cmplx.Phase(h)
+	}
+	return r
+}
+
+// LogFrequencies returns n frequencies spaced logarithmically from low to
+// high, the usual x axis of an EQ display
+func LogFrequencies(low, high float64, n int) []float64 {
+	freqs := make([]float64, n)
+	if n == 1 {
+		freqs[0] = low
+		return freqs
+	}
+	for i := range freqs {
+		freqs[i] = low * math.Pow(high/low, float64(i)/float64(n-1))
+	}
+	return freqs
+}
@@ -0,0 +1,69 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParametricEQResponse(t *testing.T) {
+	const sr = 48000.0
+	eq := NewParametricEQ(4, 1, sr)
+
+	// All bands disabled: flat
+	flat := eq.GetFrequencyResponse(LogFrequencies(20, 20000, 32))
+	for i, db := range flat.Magnitude {
+		if db != 0 || flat.Phase[i] != 0 {
+			t.Fatalf("disabled EQ: %g dB, %g rad at %g Hz", db, flat.Phase[i], flat.Frequencies[i])
+		}
+	}
+
+	eq.SetBand(0, EQBand{Type: BandHighpass, Frequency: 40, Q: 0.707, Enabled: true})
+	eq.SetBand(1, EQBand{Type: BandPeak, Frequency: 1000, Q: 2, GainDB: 6, Enabled: true})
+	eq.SetBand(2, EQBand{Type: BandHighShelf, Frequency: 8000, Q: 0.707, GainDB: -4, Enabled: true})
+	eq.SetBand(3, EQBand{Type: BandNotch, Frequency: 3000, Q: 10, Enabled: false})
+
+	r := eq.GetFrequencyResponse([]float64{10, 1000, 3000, 20000})
+	if r.Magnitude[0] > -20 {
+		t.Errorf("10 Hz below the high-pass: %g dB", r.Magnitude[0])
+	}
+	if math.Abs(r.Magnitude[1]-6) > 0.2 {
+		t.Errorf("peak center: %g dB", r.Magnitude[1])
+	}
+	if math.Abs(r.Magnitude[2]) > 1 {
+		t.Errorf("disabled notch: %g dB", r.Magnitude[2])
+	}
+	if math.Abs(r.Magnitude[3]+4) > 0.5 {
+		t.Errorf("high shelf: %g dB", r.Magnitude[3])
+	}
+
+	eq.SetEnabled(3, true)
+	if db := eq.GetFrequencyResponse([]float64{3000}).Magnitude[0]; db > -40 {
+		t.Errorf("enabled notch: %g dB", db)
+	}
+}
+
+func TestParametricEQMatchesProcessing(t *testing.T) {
+	const sr = 48000.0
+	eq := NewParametricEQ(2, 1, sr)
+	eq.SetBand(0, EQBand{Type: BandPeak, Frequency: 500, Q: 1, GainDB: -9, Enabled: true})
+	eq.SetBand(1, EQBand{Type: BandLowShelf, Frequency: 200, Q: 0.707, GainDB: 5, Enabled: true})
+
+	for _, freq := range []float64{100, 500, 2000} {
+		eq.Reset()
+		buffer := make([]float32, 48000)
+		for i := range buffer {
+			buffer[i] = float32(math.Sin(2 * math.Pi * freq * float64(i) / sr))
+		}
+		eq.Process(buffer, 0)
+
+		sum := 0.0
+		for _, y := range buffer[24000:] {
+			sum += float64(y) * float64(y)
+		}
+		measured := 10 * math.Log10(sum/24000/0.5)
+		predicted := eq.GetFrequencyResponse([]float64{freq}).Magnitude[0]
+		if math.Abs(measured-predicted) > 0.1 {
+			t.Errorf("%g Hz: measured %g dB, predicted %g dB", freq, measured, predicted)
+		}
+	}
+}
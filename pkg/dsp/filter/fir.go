@@ -0,0 +1,230 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// ErrInvalidDesign is returned for FIR specifications that cannot be met
+var ErrInvalidDesign = errors.New("invalid FIR design")
+
+// WindowType selects the window for window-method FIR design
+type WindowType int
+
+const (
+	WindowRectangular WindowType = iota // Sharpest transition, 21 dB stopband
+	WindowHann                          // 44 dB stopband
+	WindowHamming                       // 53 dB stopband
+	WindowBlackman                      // 74 dB stopband
+	WindowKaiser                        // Adjustable with beta, see KaiserBeta
+)
+
+// Window returns n window coefficients. beta is only used by WindowKaiser.
+func Window(kind WindowType, n int, beta float64) []float64 {
+	w := make([]float64, n)
+	if n == 1 {
+		w[0] = 1
+		return w
+	}
+	m := float64(n - 1)
+	for i := range w {
+		x := 2 * math.Pi * float64(i) / m
+		switch kind {
+		case WindowHann:
+			w[i] = 0.5 - 0.5*math.Cos(x)
+		case WindowHamming:
+			w[i] = 0.54 - 0.46*math.Cos(x)
+		case WindowBlackman:
+			w[i] = 0.42 - 0.5*math.Cos(x) + 0.08*math.Cos(2*x)
+		case WindowKaiser:
+			r := 2*float64(i)/m - 1
+			w[i] = BesselI0(beta*math.Sqrt(1-r*r)) / BesselI0(beta)
+		default:
+			w[i] = 1
+		}
+	}
+	return w
+}
+
+// BesselI0 is the zeroth-order modified Bessel function of the first kind,
+// the basis of the Kaiser window. It is shared by the windowed-sinc tables
+// elsewhere in the module.
+func BesselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+// KaiserBeta returns the Kaiser window beta for a stopband attenuation in dB
+func KaiserBeta(attenuationDB float64) float64 {
+	switch {
+	case attenuationDB > 50:
+		return 0.1102 * (attenuationDB - 8.7)
+	case attenuationDB >= 21:
+		return 0.5842*math.Pow(attenuationDB-21, 0.4) + 0.07886*(attenuationDB-21)
+	default:
+		return 0
+	}
+}
+
+// KaiserTaps estimates the odd number of taps a Kaiser-windowed filter needs
+// for an attenuation in dB over a transition band of transitionHz
+func KaiserTaps(attenuationDB, transitionHz, sampleRate float64) int {
+	dw := 2 * math.Pi * transitionHz / sampleRate
+	n := int(math.Ceil((attenuationDB-7.95)/(2.285*dw))) + 1
+	n = max(3, n)
+	if n%2 == 0 {
+		n++
+	}
+	return n
+}
+
+// FIRLatency returns the delay in samples of a linear-phase filter with the
+// given number of taps; odd lengths delay by a whole number of samples
+func FIRLatency(taps int) float64 {
+	return float64(taps-1) / 2
+}
+
+// FIRLowpass designs a linear-phase low-pass filter with the window method.
+// window must have taps coefficients; nil uses a Blackman window.
+func FIRLowpass(taps int, cutoff, sampleRate float64, window []float64) ([]float64, error) {
+	return firWindowed(taps, window, sampleRate, cutoff, 0, false)
+}
+
+// FIRHighpass designs a linear-phase high-pass filter. taps must be odd.
+func FIRHighpass(taps int, cutoff, sampleRate float64, window []float64) ([]float64, error) {
+	return firWindowed(taps, window, sampleRate, cutoff, 0, true)
+}
+
+// FIRBandpass designs a linear-phase band-pass filter between low and high
+func FIRBandpass(taps int, low, high, sampleRate float64, window []float64) ([]float64, error) {
+	return firWindowed(taps, window, sampleRate, low, high, false)
+}
+
+// FIRBandstop designs a linear-phase band-stop filter. taps must be odd.
+func FIRBandstop(taps int, low, high, sampleRate float64, window []float64) ([]float64, error) {
+	return firWindowed(taps, window, sampleRate, low, high, true)
+}
+
+// firWindowed builds a windowed-sinc low-pass (high == 0) or band-pass,
+// spectrally inverted when invert is set
+func firWindowed(taps int, window []float64, sampleRate, low, high float64, invert bool) ([]float64, error) {
+	if taps < 1 {
+		return nil, fmt.Errorf("%w: %d taps", ErrInvalidDesign, taps)
+	}
+	if invert && taps%2 == 0 {
+		return nil, fmt.Errorf("%w: high-pass and band-stop filters need an odd number of taps", ErrInvalidDesign)
+	}
+	nyquist := sampleRate / 2
+	if low <= 0 || low >= nyquist || (high != 0 && (high <= low || high >= nyquist)) {
+		return nil, fmt.Errorf("%w: cutoff %g-%g Hz at %g Hz", ErrInvalidDesign, low, high, sampleRate)
+	}
+	if window == nil {
+		window = Window(WindowBlackman, taps, 0)
+	}
+	if len(window) != taps {
+		return nil, fmt.Errorf("%w: window has %d coefficients for %d taps", ErrInvalidDesign, len(window), taps)
+	}
+
+	// Ideal response as the difference of two low-passes
+	sinc := func(fc, t float64) float64 {
+		if t == 0 {
+			return 2 * fc
+		}
+		return math.Sin(2*math.Pi*fc*t) / (math.Pi * t)
+	}
+	f1, f2 := low/sampleRate, high/sampleRate
+	center := FIRLatency(taps)
+	h := make([]float64, taps)
+	for i := range h {
+		t := float64(i) - center
+		if high == 0 {
+			h[i] = sinc(f1, t)
+		} else {
+			h[i] = sinc(f2, t) - sinc(f1, t)
+		}
+		h[i] *= window[i]
+	}
+
+	// Unity gain at DC for low-pass, at the band center for band-pass
+	ref := 0.0
+	if high != 0 {
+		ref = (low + high) / 2
+	}
+	if g := cmplx.Abs(firResponse(h, ref, sampleRate)); g > 0 {
+		for i := range h {
+			h[i] /= g
+		}
+	}
+
+	if invert {
+		for i := range h {
+			h[i] = -h[i]
+		}
+		h[taps/2]++
+	}
+	return h, nil
+}
+
+// FIRFromResponse designs a linear-phase filter that follows an arbitrary
+// magnitude response by frequency sampling, e.g. a linear-phase EQ curve.
+// gain returns the linear gain at a frequency in Hz. taps must be odd;
+// window nil uses a Blackman window.
+func FIRFromResponse(taps int, gain func(hz float64) float64, sampleRate float64, window []float64) ([]float64, error) {
+	if taps < 1 || taps%2 == 0 {
+		return nil, fmt.Errorf("%w: frequency sampling needs an odd number of taps, got %d", ErrInvalidDesign, taps)
+	}
+	if window == nil {
+		window = Window(WindowBlackman, taps, 0)
+	}
+	if len(window) != taps {
+		return nil, fmt.Errorf("%w: window has %d coefficients for %d taps", ErrInvalidDesign, len(window), taps)
+	}
+
+	// Sample the zero-phase response densely and take the inverse cosine
+	// transform, centered on the middle tap
+	grid := 8 * taps
+	samples := make([]float64, grid+1)
+	for k := range samples {
+		samples[k] = gain(float64(k) / float64(grid) * sampleRate / 2)
+	}
+
+	center := taps / 2
+	h := make([]float64, taps)
+	for i := range h {
+		n := float64(i - center)
+		sum := samples[0] + samples[grid]*math.Cos(math.Pi*n)
+		for k := 1; k < grid; k++ {
+			sum += 2 * samples[k] * math.Cos(math.Pi*float64(k)*n/float64(grid))
+		}
+		h[i] = sum / float64(2*grid) * window[i]
+	}
+	return h, nil
+}
+
+// firResponse evaluates the complex response of an FIR filter
+func firResponse(h []float64, freq, sampleRate float64) complex128 {
+	w := 2 * math.Pi * freq / sampleRate
+	var sum complex128
+	for i, c := range h {
+		sum += complex(c, 0) * cmplx.Exp(complex(0, -w*float64(i)))
+	}
+	return sum
+}
+
+// FIRMagnitudeDB returns the gain in dB of FIR coefficients at a frequency
+func FIRMagnitudeDB(h []float64, freq, sampleRate float64) float64 {
+	mag := cmplx.Abs(firResponse(h, freq, sampleRate))
+	if mag <= 0 {
+		return -120.0
+	}
+	return 20.0 * math.Log10(mag)
+}
@@ -0,0 +1,204 @@
+package filter
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkSymmetric verifies the linear-phase symmetry of FIR coefficients
+func checkSymmetric(t *testing.T, h []float64) {
+	t.Helper()
+	for i := range h {
+		if math.Abs(h[i]-h[len(h)-1-i]) > 1e-9 {
+			t.Fatalf("coefficients not symmetric at %d: %g vs %g", i, h[i], h[len(h)-1-i])
+		}
+	}
+}
+
+func TestFIRWindowDesigns(t *testing.T) {
+	const sr = 48000.0
+	tests := []struct {
+		name   string
+		design func() ([]float64, error)
+		pass   []float64 // Frequencies within 0.1 dB of unity
+		stop   []float64 // Frequencies below -60 dB
+	}{
+		{"lowpass", func() ([]float64, error) { return FIRLowpass(255, 2000, sr, nil) },
+			[]float64{0, 500, 1500}, []float64{3000, 10000, 20000}},
+		{"highpass", func() ([]float64, error) { return FIRHighpass(255, 2000, sr, nil) },
+			[]float64{3000, 10000, 20000}, []float64{0, 500, 1000}},
+		{"bandpass", func() ([]float64, error) { return FIRBandpass(255, 2000, 6000, sr, nil) },
+			[]float64{3000, 4000, 5000}, []float64{0, 500, 8000, 15000}},
+		{"bandstop", func() ([]float64, error) { return FIRBandstop(255, 2000, 6000, sr, nil) },
+			[]float64{0, 500, 8000, 15000}, []float64{3500, 4000, 4500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := tt.design()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkSymmetric(t, h)
+			for _, f := range tt.pass {
+				if db := FIRMagnitudeDB(h, f, sr); math.Abs(db) > 0.1 {
+					t.Errorf("%g Hz: %g dB in the passband", f, db)
+				}
+			}
+			for _, f := range tt.stop {
+				if db := FIRMagnitudeDB(h, f, sr); db > -60 {
+					t.Errorf("%g Hz: %g dB in the stopband", f, db)
+				}
+			}
+		})
+	}
+}
+
+func TestFIRDesignErrors(t *testing.T) {
+	if _, err := FIRHighpass(64, 1000, 48000, nil); !errors.Is(err, ErrInvalidDesign) {
+		t.Errorf("even high-pass: %v", err)
+	}
+	if _, err := FIRLowpass(63, 30000, 48000, nil); !errors.Is(err, ErrInvalidDesign) {
+		t.Errorf("cutoff above Nyquist: %v", err)
+	}
+	if _, err := FIRLowpass(63, 1000, 48000, make([]float64, 10)); !errors.Is(err, ErrInvalidDesign) {
+		t.Errorf("window length mismatch: %v", err)
+	}
+	if _, err := FIRRemez(31, []RemezBand{{0, 5000, 1, 1}, {4000, 24000, 0, 1}}, 48000); !errors.Is(err, ErrInvalidDesign) {
+		t.Errorf("overlapping bands: %v", err)
+	}
+}
+
+func TestFIRKaiser(t *testing.T) {
+	const sr = 48000.0
+	taps := KaiserTaps(80, 1000, sr)
+	if taps%2 == 0 {
+		t.Fatalf("KaiserTaps returned even length %d", taps)
+	}
+	h, err := FIRLowpass(taps, 5500, sr, Window(WindowKaiser, taps, KaiserBeta(80)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for f := 6000.0; f < sr/2; f += 250 {
+		if db := FIRMagnitudeDB(h, f, sr); db > -78 {
+			t.Fatalf("%g Hz: %g dB, want at least 80 dB of attenuation", f, db)
+		}
+	}
+	if FIRLatency(taps) != float64(taps-1)/2 {
+		t.Errorf("latency %g", FIRLatency(taps))
+	}
+}
+
+func TestFIRRemez(t *testing.T) {
+	const sr = 48000.0
+	h, err := FIRRemez(101, []RemezBand{
+		{Low: 0, High: 4000, Gain: 1, Weight: 1},
+		{Low: 5000, High: 24000, Gain: 0, Weight: 10},
+	}, sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSymmetric(t, h)
+
+	passRipple, stopPeak := 0.0, -200.0
+	for f := 0.0; f <= 4000; f += 50 {
+		passRipple = math.Max(passRipple, math.Abs(FIRMagnitudeDB(h, f, sr)))
+	}
+	for f := 5000.0; f <= 24000; f += 50 {
+		stopPeak = math.Max(stopPeak, FIRMagnitudeDB(h, f, sr))
+	}
+	if passRipple > 0.25 {
+		t.Errorf("passband ripple %g dB", passRipple)
+	}
+	if stopPeak > -50 {
+		t.Errorf("stopband peak %g dB", stopPeak)
+	}
+
+	// Equiripple: the stopband error reaches its peak many times
+	peaks := 0
+	for f := 5000.0; f <= 24000; f += 10 {
+		db := FIRMagnitudeDB(h, f, sr)
+		if db > stopPeak-0.5 {
+			peaks++
+		}
+	}
+	if peaks < 5 {
+		t.Errorf("stopband ripple is not equal: %d peaks near the maximum", peaks)
+	}
+}
+
+func TestFIRFromResponse(t *testing.T) {
+	const sr = 48000.0
+	// A +6 dB high shelf above 4 kHz
+	gain := func(hz float64) float64 {
+		if hz >= 4000 {
+			return 2
+		}
+		return 1
+	}
+	h, err := FIRFromResponse(511, gain, sr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSymmetric(t, h)
+	for _, tc := range []struct{ f, want float64 }{{100, 0}, {2000, 0}, {8000, 6.02}, {16000, 6.02}} {
+		if db := FIRMagnitudeDB(h, tc.f, sr); math.Abs(db-tc.want) > 0.1 {
+			t.Errorf("%g Hz: %g dB, want %g", tc.f, db, tc.want)
+		}
+	}
+
+	// A flat response is a delayed impulse
+	flat, _ := FIRFromResponse(31, func(float64) float64 { return 1 }, sr, Window(WindowRectangular, 31, 0))
+	for i, c := range flat {
+		want := 0.0
+		if i == 15 {
+			want = 1
+		}
+		if math.Abs(c-want) > 1e-9 {
+			t.Fatalf("flat response tap %d = %g", i, c)
+		}
+	}
+}
+
+func TestConvolver(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for _, tc := range []struct{ taps, block int }{{1, 64}, {100, 64}, {1000, 128}, {300, 1000}} {
+		h := make([]float64, tc.taps)
+		for i := range h {
+			h[i] = rng.Float64()*2 - 1
+		}
+		input := make([]float32, 5000)
+		for i := range input {
+			input[i] = float32(rng.Float64()*2 - 1)
+		}
+
+		c := NewConvolver(h, tc.block, 2)
+		latency := c.Latency()
+		output := append([]float32(nil), input...)
+		// Irregular host blocks
+		for i := 0; i < len(output); {
+			n := min(len(output)-i, 1+rng.Intn(300))
+			c.ProcessBuffer(output[i:i+n], 1)
+			i += n
+		}
+
+		for i := latency; i < len(output); i++ {
+			want := 0.0
+			for k, coef := range h {
+				if j := i - latency - k; j >= 0 {
+					want += coef * float64(input[j])
+				}
+			}
+			if math.Abs(float64(output[i])-want) > 1e-4 {
+				t.Fatalf("%d taps, block %d: sample %d = %g, want %g", tc.taps, tc.block, i, output[i], want)
+			}
+		}
+		for i := 0; i < latency && i < len(output); i++ {
+			if output[i] != 0 {
+				t.Fatalf("output before the latency should be silent, sample %d = %g", i, output[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+)
+
+// HalfBandPhase selects the phase response of a half-band filter
+type HalfBandPhase int
+
+const (
+	// HalfBandLinear is symmetric with a constant delay; every other tap
+	// is zero, so it costs about a quarter of its length per output
+	HalfBandLinear HalfBandPhase = iota
+	// HalfBandMinimum has the same magnitude response with most of the
+	// delay removed, at the cost of phase shift near the band edge
+	HalfBandMinimum
+)
+
+// DesignHalfBand designs a half-band low-pass with its cutoff at a quarter
+// of the (higher) sample rate, for 2x decimation and interpolation. taps
+// must be odd; lengths of the form 4k+3 use every coefficient. attenuationDB
+// sets the Kaiser window's stopband rejection.
+func DesignHalfBand(taps int, attenuationDB float64, phase HalfBandPhase) ([]float64, error) {
+	if taps < 3 || taps%2 == 0 {
+		return nil, fmt.Errorf("%w: half-band filters need an odd number of taps, got %d", ErrInvalidDesign, taps)
+	}
+	h, err := FIRLowpass(taps, 0.25, 1, Window(WindowKaiser, taps, KaiserBeta(attenuationDB)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean up the structural zeros, symmetry and center tap so the
+	// polyphase filters can skip the zeros exactly, then restore unity gain
+	// at DC through the remaining taps
+	center := taps / 2
+	sum := 0.0
+	for i := 0; i < center; i++ {
+		if (center-i)%2 == 0 {
+			h[i], h[taps-1-i] = 0, 0
+			continue
+		}
+		c := (h[i] + h[taps-1-i]) / 2
+		h[i], h[taps-1-i] = c, c
+		sum += 2 * c
+	}
+	for i := range h {
+		if i != center {
+			h[i] *= 0.5 / sum
+		}
+	}
+	h[center] = 0.5
+
+	if phase == HalfBandMinimum {
+		h = minimumPhase(h)
+	}
+	return h, nil
+}
+
+// minimumPhase converts a linear-phase FIR to the minimum-phase filter with
+// the same magnitude response, using the real cepstrum
+func minimumPhase(h []float64) []float64 {
+	n := 1
+	for n < 64*len(h) {
+		n <<= 1
+	}
+	plan := newFFTPlan(n)
+	re := make([]float64, n)
+	im := make([]float64, n)
+	copy(re, h)
+	plan.transform(re, im, false)
+
+	// Log magnitude with a floor for the stopband zeros on the unit circle
+	for k := range re {
+		re[k] = math.Log(math.Max(math.Hypot(re[k], im[k]), 1e-10))
+		im[k] = 0
+	}
+	plan.transform(re, im, true)
+
+	// Fold the cepstrum onto positive quefrencies
+	for k := 1; k < n/2; k++ {
+		re[k] *= 2
+		re[n-k] = 0
+	}
+	clear(im)
+	plan.transform(re, im, false)
+
+	// Back to the frequency response, then the impulse response
+	for k := range re {
+		mag := math.Exp(re[k])
+		re[k], im[k] = mag*math.Cos(im[k]), mag*math.Sin(im[k])
+	}
+	plan.transform(re, im, true)
+
+	out := make([]float64, len(h))
+	copy(out, re)
+
+	// Unity gain at DC, as for the linear-phase design
+	sum := 0.0
+	for _, c := range out {
+		sum += c
+	}
+	for i := range out {
+		out[i] /= sum
+	}
+	return out
+}
+
+// halfBandBranch is one polyphase branch with its zero taps removed. The
+// history is written twice so the newest len(history)/2 samples are always
+// contiguous.
+type halfBandBranch struct {
+	coeffs  []float64
+	offsets []int // Age in branch samples of the input each coefficient weights
+	history []float64
+	pos     int
+}
+
+// newHalfBandBranch keeps the nonzero coefficients of h[phase::2], scaled
+func newHalfBandBranch(h []float64, phase int, scale float64) halfBandBranch {
+	b := halfBandBranch{}
+	length := 0
+	for i := phase; i < len(h); i += 2 {
+		length++
+		if h[i] != 0 {
+			b.coeffs = append(b.coeffs, h[i]*scale)
+			b.offsets = append(b.offsets, length-1)
+		}
+	}
+	b.history = make([]float64, 2*max(1, length))
+	return b
+}
+
+// push adds a sample to the branch history
+func (b *halfBandBranch) push(x float64) {
+	n := len(b.history) / 2
+	b.pos--
+	if b.pos < 0 {
+		b.pos = n - 1
+	}
+	b.history[b.pos] = x
+	b.history[b.pos+n] = x
+}
+
+// output convolves the branch with its history
+func (b *halfBandBranch) output() float64 {
+	hist := b.history[b.pos : b.pos+len(b.history)/2]
+	offsets := b.offsets[:len(b.coeffs)]
+	sum := 0.0
+	for i, c := range b.coeffs {
+		sum += c * hist[offsets[i]]
+	}
+	return sum
+}
+
+// reset clears the history
+func (b *halfBandBranch) reset() {
+	clear(b.history)
+	b.pos = 0
+}
+
+// halfBandLatency returns the delay of h in samples: exact for symmetric
+// filters, the group delay at DC otherwise
+func halfBandLatency(h []float64) float64 {
+	sum, moment := 0.0, 0.0
+	for i, c := range h {
+		sum += c
+		moment += float64(i) * c
+	}
+	if sum == 0 {
+		return FIRLatency(len(h))
+	}
+	return moment / sum
+}
+
+// HalfBandInterpolator doubles the sample rate of one channel. It runs the
+// half-band filter as two polyphase branches at the input rate, so no work
+// is spent on the zero-stuffed samples or the filter's zero taps.
+type HalfBandInterpolator struct {
+	h         []float64
+	even, odd halfBandBranch
+	latency   float64
+}
+
+// NewHalfBandInterpolator creates an interpolator from DesignHalfBand
+// coefficients
+func NewHalfBandInterpolator(h []float64) *HalfBandInterpolator {
+	// Zero-stuffing halves the level; the branches make it up
+	return &HalfBandInterpolator{
+		h:       h,
+		even:    newHalfBandBranch(h, 0, 2),
+		odd:     newHalfBandBranch(h, 1, 2),
+		latency: halfBandLatency(h),
+	}
+}
+
+// Process returns the two output samples for one input sample
+func (f *HalfBandInterpolator) Process(x float64) (float64, float64) {
+	f.even.push(x)
+	f.odd.push(x)
+	return f.even.output(), f.odd.output()
+}
+
+// ProcessBuffer interpolates input into output, which must hold twice as
+// many samples
+func (f *HalfBandInterpolator) ProcessBuffer(input, output []float32) {
+	for i, x := range input {
+		y0, y1 := f.Process(float64(x))
+		output[2*i] = float32(y0)
+		output[2*i+1] = float32(y1)
+	}
+}
+
+// Latency returns the delay in output (higher rate) samples
+func (f *HalfBandInterpolator) Latency() float64 {
+	return f.latency
+}
+
+// Coefficients returns the filter coefficients
+func (f *HalfBandInterpolator) Coefficients() []float64 {
+	return f.h
+}
+
+// Reset clears the filter history
+func (f *HalfBandInterpolator) Reset() {
+	f.even.reset()
+	f.odd.reset()
+}
+
+// HalfBandDecimator halves the sample rate of one channel. It keeps the
+// first sample of each input pair and runs the half-band filter as two
+// polyphase branches at the output rate.
+type HalfBandDecimator struct {
+	h         []float64
+	even, odd halfBandBranch
+	latency   float64
+}
+
+// NewHalfBandDecimator creates a decimator from DesignHalfBand coefficients
+func NewHalfBandDecimator(h []float64) *HalfBandDecimator {
+	d := &HalfBandDecimator{
+		h:       h,
+		even:    newHalfBandBranch(h, 0, 1),
+		odd:     newHalfBandBranch(h, 1, 1),
+		latency: halfBandLatency(h),
+	}
+	return d
+}
+
+// Process returns one output sample for a pair of input samples
+func (f *HalfBandDecimator) Process(x0, x1 float64) float64 {
+	// The odd branch weights odd inputs up to the previous pair's
+	f.even.push(x0)
+	y := f.even.output() + f.odd.output()
+	f.odd.push(x1)
+	return y
+}
+
+// ProcessBuffer decimates input into output, which must hold half as many
+// samples. A trailing odd input sample is ignored.
+func (f *HalfBandDecimator) ProcessBuffer(input, output []float32) {
+	for i := 0; i+1 < len(input); i += 2 {
+		output[i/2] = float32(f.Process(float64(input[i]), float64(input[i+1])))
+	}
+}
+
+// Latency returns the delay in input (higher rate) samples
+func (f *HalfBandDecimator) Latency() float64 {
+	return f.latency
+}
+
+// Coefficients returns the filter coefficients
+func (f *HalfBandDecimator) Coefficients() []float64 {
+	return f.h
+}
+
+// Reset clears the filter history
+func (f *HalfBandDecimator) Reset() {
+	f.even.reset()
+	f.odd.reset()
+}
@@ -0,0 +1,183 @@
+package filter
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDesignHalfBandLinear(t *testing.T) {
+	h, err := DesignHalfBand(63, 90, HalfBandLinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	center := len(h) / 2
+	if h[center] != 0.5 {
+		t.Errorf("Center tap = %f, want 0.5", h[center])
+	}
+	for i := range h {
+		if d := i - center; d != 0 && d%2 == 0 && h[i] != 0 {
+			t.Errorf("Tap %d = %g, want an exact zero", i, h[i])
+		}
+		if h[i] != h[len(h)-1-i] {
+			t.Fatalf("Tap %d is not symmetric", i)
+		}
+	}
+
+	if g := FIRMagnitudeDB(h, 0.05, 1); math.Abs(g) > 0.01 {
+		t.Errorf("Passband gain = %.3f dB, want 0", g)
+	}
+	if g := FIRMagnitudeDB(h, 0.25, 1); math.Abs(g+6.02) > 0.05 {
+		t.Errorf("Gain at the band edge = %.2f dB, want -6.02", g)
+	}
+	for _, f := range []float64{0.35, 0.4, 0.45, 0.5} {
+		if g := FIRMagnitudeDB(h, f, 1); g > -85 {
+			t.Errorf("Stopband gain at %.2f = %.1f dB, want below -85", f, g)
+		}
+	}
+}
+
+func TestDesignHalfBandMinimumPhase(t *testing.T) {
+	lin, _ := DesignHalfBand(63, 90, HalfBandLinear)
+	minPhase, err := DesignHalfBand(63, 90, HalfBandMinimum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same magnitude response
+	for _, f := range []float64{0, 0.05, 0.1, 0.15, 0.2} {
+		a, b := FIRMagnitudeDB(lin, f, 1), FIRMagnitudeDB(minPhase, f, 1)
+		if math.Abs(a-b) > 0.05 {
+			t.Errorf("Gain at %.2f: minimum phase %.3f dB, linear %.3f dB", f, b, a)
+		}
+	}
+	for _, f := range []float64{0.35, 0.45} {
+		if g := FIRMagnitudeDB(minPhase, f, 1); g > -75 {
+			t.Errorf("Minimum-phase stopband at %.2f = %.1f dB", f, g)
+		}
+	}
+
+	// With far less delay
+	if l := NewHalfBandDecimator(minPhase).Latency(); l > FIRLatency(63)/3 {
+		t.Errorf("Minimum-phase latency %.1f, want well below %.1f", l, FIRLatency(63))
+	}
+}
+
+func TestDesignHalfBandInvalid(t *testing.T) {
+	for _, taps := range []int{0, 1, 2, 64} {
+		if _, err := DesignHalfBand(taps, 80, HalfBandLinear); !errors.Is(err, ErrInvalidDesign) {
+			t.Errorf("%d taps: error %v, want ErrInvalidDesign", taps, err)
+		}
+	}
+}
+
+// directConvolve filters x with h as a plain FIR
+func directConvolve(h, x []float64) []float64 {
+	y := make([]float64, len(x))
+	for n := range x {
+		for k, c := range h {
+			if n-k >= 0 {
+				y[n] += c * x[n-k]
+			}
+		}
+	}
+	return y
+}
+
+func TestHalfBandPolyphaseMatchesDirect(t *testing.T) {
+	for _, phase := range []HalfBandPhase{HalfBandLinear, HalfBandMinimum} {
+		h, _ := DesignHalfBand(31, 80, phase)
+		x := make([]float64, 200)
+		for i := range x {
+			x[i] = math.Sin(float64(i)*0.37) + 0.3*math.Cos(float64(i)*1.9)
+		}
+
+		// Interpolation is zero-stuffing followed by the filter at 2x gain
+		stuffed := make([]float64, 2*len(x))
+		for i, v := range x {
+			stuffed[2*i] = 2 * v
+		}
+		want := directConvolve(h, stuffed)
+		up := NewHalfBandInterpolator(h)
+		for i, v := range x {
+			y0, y1 := up.Process(v)
+			if math.Abs(y0-want[2*i]) > 1e-12 || math.Abs(y1-want[2*i+1]) > 1e-12 {
+				t.Fatalf("phase %d: interpolated sample %d differs from direct form", phase, i)
+			}
+		}
+
+		// Decimation is the filter followed by keeping every other sample
+		want = directConvolve(h, x)
+		down := NewHalfBandDecimator(h)
+		for i := 0; i+1 < len(x); i += 2 {
+			if y := down.Process(x[i], x[i+1]); math.Abs(y-want[i]) > 1e-12 {
+				t.Fatalf("phase %d: decimated sample %d = %f, want %f", phase, i/2, y, want[i])
+			}
+		}
+	}
+}
+
+func TestHalfBandRoundTrip(t *testing.T) {
+	h, _ := DesignHalfBand(63, 90, HalfBandLinear)
+	up := NewHalfBandInterpolator(h)
+	down := NewHalfBandDecimator(h)
+
+	// The round trip delays by both filters at the higher rate
+	delay := int(math.Round(up.Latency()+down.Latency())) / 2
+	in := make([]float32, 1000)
+	for i := range in {
+		in[i] = float32(math.Sin(2 * math.Pi * 0.05 * float64(i)))
+	}
+	twice := make([]float32, 2*len(in))
+	out := make([]float32, len(in))
+	up.ProcessBuffer(in, twice)
+	down.ProcessBuffer(twice, out)
+
+	for i := 200; i < len(out); i++ {
+		if math.Abs(float64(out[i]-in[i-delay])) > 1e-3 {
+			t.Fatalf("Sample %d = %f, want %f delayed by %d", i, out[i], in[i-delay], delay)
+		}
+	}
+
+	up.Reset()
+	if y0, y1 := up.Process(0); y0 != 0 || y1 != 0 {
+		t.Error("Reset should clear the interpolator history")
+	}
+}
+
+func benchmarkHalfBand(b *testing.B, phase HalfBandPhase, decimate bool) {
+	h, _ := DesignHalfBand(63, 90, phase)
+	in := make([]float32, 1024)
+	for i := range in {
+		in[i] = float32(math.Sin(float64(i) * 0.1))
+	}
+	out := make([]float32, 2048)
+	up := NewHalfBandInterpolator(h)
+	down := NewHalfBandDecimator(h)
+
+	b.SetBytes(int64(len(in) * 4))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if decimate {
+			down.ProcessBuffer(in, out)
+		} else {
+			up.ProcessBuffer(in, out)
+		}
+	}
+}
+
+func BenchmarkHalfBandInterpolatorLinear(b *testing.B) {
+	benchmarkHalfBand(b, HalfBandLinear, false)
+}
+
+func BenchmarkHalfBandInterpolatorMinimum(b *testing.B) {
+	benchmarkHalfBand(b, HalfBandMinimum, false)
+}
+
+func BenchmarkHalfBandDecimatorLinear(b *testing.B) {
+	benchmarkHalfBand(b, HalfBandLinear, true)
+}
+
+func BenchmarkHalfBandDecimatorMinimum(b *testing.B) {
+	benchmarkHalfBand(b, HalfBandMinimum, true)
+}
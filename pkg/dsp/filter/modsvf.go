@@ -0,0 +1,169 @@
+package filter
+
+import "math"
+
+// Modulated SVF cutoff table settings
+const (
+	modSVFMinFrequency   = 10.0 // Lowest cutoff in Hz
+	modSVFMaxRatio       = 0.49 // Highest cutoff as a fraction of the sample rate
+	modSVFStepsPerOctave = 64
+	modSVFMinDamping     = 0.01 // Damping at full resonance, just short of self-oscillation
+	modSVFDefaultDamping = 1.41421356
+	modSVFMaxModOctaves  = 10.0
+)
+
+// ModulatedSVF is a Cytomic trapezoidal state variable filter whose cutoff
+// and resonance can change every sample, for filter FM and envelope sweeps.
+// The trapezoidal form stays stable under audio-rate modulation. The costly
+// tan() of the cutoff is read from a table indexed in octaves, so
+// modulation costs an interpolated lookup and one division per sample.
+type ModulatedSVF struct {
+	pitch     float32 // Base cutoff in table steps above modSVFMinFrequency
+	resonance float32 // 0-1
+	depth     float32 // Table steps per unit of cutoff modulation
+
+	// g = tan(π·f/fs) at modSVFStepsPerOctave steps per octave
+	table []float32
+
+	// State variables (per-channel)
+	ic1eq []float32
+	ic2eq []float32
+}
+
+// NewModulatedSVF creates a modulatable filter at 1 kHz with a Butterworth
+// response and a modulation depth of one octave
+func NewModulatedSVF(channels int, sampleRate float64) *ModulatedSVF {
+	octaves := math.Log2(modSVFMaxRatio * sampleRate / modSVFMinFrequency)
+	steps := int(math.Ceil(octaves*modSVFStepsPerOctave)) + 1
+	table := make([]float32, steps+1)
+	for i := range table {
+		f := math.Min(modSVFMinFrequency*math.Exp2(float64(i)/modSVFStepsPerOctave), modSVFMaxRatio*sampleRate)
+		table[i] = float32(math.Tan(math.Pi * f / sampleRate))
+	}
+
+	s := &ModulatedSVF{
+		table:     table,
+		resonance: float32(1 - modSVFDefaultDamping/2),
+		ic1eq:     make([]float32, channels),
+		ic2eq:     make([]float32, channels),
+	}
+	s.SetFrequency(1000)
+	s.SetModulationDepth(1)
+	return s
+}
+
+// SetFrequency sets the base cutoff in Hz
+func (s *ModulatedSVF) SetFrequency(frequency float64) {
+	frequency = math.Max(modSVFMinFrequency, frequency)
+	s.pitch = float32(math.Log2(frequency/modSVFMinFrequency) * modSVFStepsPerOctave)
+}
+
+// SetResonance sets the base resonance from 0 (damped) to 1 (on the edge
+// of self-oscillation)
+func (s *ModulatedSVF) SetResonance(resonance float64) {
+	s.resonance = float32(math.Max(0, math.Min(1, resonance)))
+}
+
+// SetQ sets the base resonance as a Q factor
+func (s *ModulatedSVF) SetQ(q float64) {
+	s.SetResonance(1 - 1/(2*q))
+}
+
+// SetModulationDepth sets how many octaves a cutoff modulation of 1.0 moves
+// the cutoff
+func (s *ModulatedSVF) SetModulationDepth(octaves float64) {
+	octaves = math.Max(-modSVFMaxModOctaves, math.Min(modSVFMaxModOctaves, octaves))
+	s.depth = float32(octaves * modSVFStepsPerOctave)
+}
+
+// coefficients returns g and k for the given modulation values
+func (s *ModulatedSVF) coefficients(cutoffMod, resonanceMod float32) (g, k float32) {
+	pos := s.pitch + cutoffMod*s.depth
+	last := len(s.table) - 1
+	switch {
+	case pos <= 0:
+		g = s.table[0]
+	case pos >= float32(last):
+		g = s.table[last]
+	default:
+		i := int(pos)
+		frac := pos - float32(i)
+		g = s.table[i] + (s.table[i+1]-s.table[i])*frac
+	}
+
+	r := s.resonance + resonanceMod
+	r = max(0, min(1, r))
+	k = max(modSVFMinDamping, 2*(1-r))
+	return g, k
+}
+
+// ProcessSample filters one sample. cutoffMod is scaled by the modulation
+// depth in octaves; resonanceMod is added to the resonance.
+func (s *ModulatedSVF) ProcessSample(input, cutoffMod, resonanceMod float32, channel int) SVFOutputs {
+	g, k := s.coefficients(cutoffMod, resonanceMod)
+	a1 := 1.0 / (1.0 + g*(g+k))
+	a2 := g * a1
+	a3 := g * a2
+
+	ic1eq := s.ic1eq[channel]
+	ic2eq := s.ic2eq[channel]
+	v3 := input - ic2eq
+	v1 := a1*ic1eq + a2*v3
+	v2 := ic2eq + a2*ic1eq + a3*v3
+	s.ic1eq[channel] = 2.0*v1 - ic1eq
+	s.ic2eq[channel] = 2.0*v2 - ic2eq
+
+	return SVFOutputs{
+		Lowpass:  v2,
+		Bandpass: v1,
+		Highpass: input - k*v1 - v2,
+		Notch:    input - k*v1,
+	}
+}
+
+// process filters a buffer in place, keeping the output selected by pick.
+// Either modulation buffer may be nil.
+func (s *ModulatedSVF) process(buffer, cutoffMod, resonanceMod []float32, channel int, pick func(SVFOutputs) float32) {
+	var cm, rm float32
+	for i := range buffer {
+		if i < len(cutoffMod) {
+			cm = cutoffMod[i]
+		}
+		if i < len(resonanceMod) {
+			rm = resonanceMod[i]
+		}
+		buffer[i] = pick(s.ProcessSample(buffer[i], cm, rm, channel))
+	}
+}
+
+// ProcessLowpass processes buffer as lowpass filter with per-sample
+// modulation - no allocations
+func (s *ModulatedSVF) ProcessLowpass(buffer, cutoffMod, resonanceMod []float32, channel int) {
+	s.process(buffer, cutoffMod, resonanceMod, channel, func(o SVFOutputs) float32 { return o.Lowpass })
+}
+
+// ProcessHighpass processes buffer as highpass filter with per-sample
+// modulation - no allocations
+func (s *ModulatedSVF) ProcessHighpass(buffer, cutoffMod, resonanceMod []float32, channel int) {
+	s.process(buffer, cutoffMod, resonanceMod, channel, func(o SVFOutputs) float32 { return o.Highpass })
+}
+
+// ProcessBandpass processes buffer as bandpass filter with per-sample
+// modulation - no allocations
+func (s *ModulatedSVF) ProcessBandpass(buffer, cutoffMod, resonanceMod []float32, channel int) {
+	s.process(buffer, cutoffMod, resonanceMod, channel, func(o SVFOutputs) float32 { return o.Bandpass })
+}
+
+// ProcessNotch processes buffer as notch filter with per-sample modulation
+// - no allocations
+func (s *ModulatedSVF) ProcessNotch(buffer, cutoffMod, resonanceMod []float32, channel int) {
+	s.process(buffer, cutoffMod, resonanceMod, channel, func(o SVFOutputs) float32 { return o.Notch })
+}
+
+// Reset clears the filter state
+func (s *ModulatedSVF) Reset() {
+	for i := range s.ic1eq {
+		s.ic1eq[i] = 0
+		s.ic2eq[i] = 0
+	}
+}
@@ -0,0 +1,79 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestModulatedSVFMatchesSVF(t *testing.T) {
+	const sr = 48000.0
+	for _, tc := range []struct {
+		freq, mod float64
+	}{{1000, 0}, {1000, 1}, {250, -0.5}, {5000, 0.25}} {
+		m := NewModulatedSVF(1, sr)
+		m.SetFrequency(tc.freq)
+		m.SetQ(2)
+
+		ref := NewSVF(1)
+		ref.SetFrequencyAndQ(sr, tc.freq*math.Exp2(tc.mod), 2)
+
+		for i := 0; i < 2000; i++ {
+			x := float32(math.Sin(float64(i) * 0.37))
+			got := m.ProcessSample(x, float32(tc.mod), 0, 0)
+			want := ref.ProcessSample(x, 0)
+			if math.Abs(float64(got.Lowpass-want.Lowpass)) > 1e-3 ||
+				math.Abs(float64(got.Highpass-want.Highpass)) > 1e-3 {
+				t.Fatalf("%g Hz, mod %g: sample %d got %+v, want %+v", tc.freq, tc.mod, i, got, want)
+			}
+		}
+	}
+}
+
+func TestModulatedSVFAudioRateStability(t *testing.T) {
+	const sr = 48000.0
+	m := NewModulatedSVF(1, sr)
+	m.SetFrequency(800)
+	m.SetResonance(1)
+	m.SetModulationDepth(6)
+
+	n := 48000
+	buffer := make([]float32, n)
+	cutoffMod := make([]float32, n)
+	resonanceMod := make([]float32, n)
+	for i := range buffer {
+		buffer[i] = float32(math.Sin(2 * math.Pi * 110 * float64(i) / sr))
+		cutoffMod[i] = float32(math.Sin(2 * math.Pi * 440 * float64(i) / sr)) // Audio-rate FM
+		resonanceMod[i] = float32(math.Sin(2*math.Pi*3*float64(i)/sr)) * 0.5
+	}
+	m.ProcessLowpass(buffer, cutoffMod, resonanceMod, 0)
+	for i, y := range buffer {
+		if math.IsNaN(float64(y)) || math.Abs(float64(y)) > 100 {
+			t.Fatalf("sample %d = %g", i, y)
+		}
+	}
+
+	// Sweeping far out of range clamps rather than blowing up
+	m.Reset()
+	for i := range cutoffMod {
+		cutoffMod[i] = 50
+	}
+	m.ProcessHighpass(buffer, cutoffMod, nil, 0)
+	for i, y := range buffer {
+		if math.IsNaN(float64(y)) || math.IsInf(float64(y), 0) {
+			t.Fatalf("clamped sample %d = %g", i, y)
+		}
+	}
+}
+
+func BenchmarkModulatedSVF(b *testing.B) {
+	m := NewModulatedSVF(1, 48000)
+	buffer := make([]float32, 512)
+	mod := make([]float32, 512)
+	for i := range mod {
+		mod[i] = float32(math.Sin(float64(i) * 0.1))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ProcessLowpass(buffer, mod, nil, 0)
+	}
+}
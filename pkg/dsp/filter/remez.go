@@ -0,0 +1,239 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+)
+
+// Remez algorithm settings
+const (
+	remezGridDensity   = 16
+	remezMaxIterations = 40
+	remezTolerance     = 1e-6
+)
+
+// RemezBand is one band of a Parks-McClellan specification
+type RemezBand struct {
+	Low, High float64 // Edges in Hz
+	Gain      float64 // Desired linear gain
+	Weight    float64 // Relative error weight; zero uses 1
+}
+
+// FIRRemez designs an optimal equiripple linear-phase filter with the
+// Parks-McClellan algorithm. Bands must be in order, must not overlap and
+// leave gaps between them as transition bands. taps must be odd.
+func FIRRemez(taps int, bands []RemezBand, sampleRate float64) ([]float64, error) {
+	if taps < 3 || taps%2 == 0 {
+		return nil, fmt.Errorf("%w: Remez design needs an odd number of taps, got %d", ErrInvalidDesign, taps)
+	}
+	if len(bands) == 0 {
+		return nil, fmt.Errorf("%w: no bands", ErrInvalidDesign)
+	}
+	prev := -1.0
+	for _, b := range bands {
+		if b.Low < 0 || b.High > sampleRate/2 || b.High < b.Low || b.Low <= prev {
+			return nil, fmt.Errorf("%w: band %g-%g Hz", ErrInvalidDesign, b.Low, b.High)
+		}
+		prev = b.High
+	}
+
+	r := taps/2 + 1 // Cosine terms in the amplitude response
+	grid, desired, weight, band := remezGrid(bands, sampleRate, r)
+	if len(grid) < r+1 {
+		return nil, fmt.Errorf("%w: bands too narrow for %d taps", ErrInvalidDesign, taps)
+	}
+
+	// Start from evenly spaced extremal frequencies
+	ext := make([]int, r+1)
+	for i := range ext {
+		ext[i] = i * (len(grid) - 1) / r
+	}
+
+	x := make([]float64, len(grid))
+	for i, f := range grid {
+		x[i] = math.Cos(2 * math.Pi * f)
+	}
+
+	var interp *remezInterpolator
+	errs := make([]float64, len(grid))
+	for iter := 0; iter < remezMaxIterations; iter++ {
+		interp = newRemezInterpolator(x, desired, weight, ext)
+
+		maxErr := 0.0
+		for i := range grid {
+			errs[i] = weight[i] * (desired[i] - interp.eval(x[i]))
+			maxErr = math.Max(maxErr, math.Abs(errs[i]))
+		}
+		if maxErr == 0 || (maxErr-math.Abs(interp.delta))/maxErr < remezTolerance {
+			break
+		}
+
+		next := remezExtrema(errs, band, r+1)
+		if next == nil {
+			break
+		}
+		ext = next
+	}
+
+	// The amplitude response is a cosine series of degree taps/2; sampling it
+	// at taps frequencies gives the impulse response exactly
+	m := taps / 2
+	amp := make([]float64, m+1)
+	for k := range amp {
+		amp[k] = interp.eval(math.Cos(2 * math.Pi * float64(k) / float64(taps)))
+	}
+	h := make([]float64, taps)
+	for n := range h {
+		sum := amp[0]
+		for k := 1; k <= m; k++ {
+			sum += 2 * amp[k] * math.Cos(2*math.Pi*float64(k*(n-m))/float64(taps))
+		}
+		h[n] = sum / float64(taps)
+	}
+	return h, nil
+}
+
+// remezGrid samples the bands in normalized frequency (cycles per sample),
+// recording which band each point belongs to
+func remezGrid(bands []RemezBand, sampleRate float64, r int) (grid, desired, weight []float64, band []int) {
+	step := 0.5 / float64(remezGridDensity*r)
+	for j, b := range bands {
+		lo, hi := b.Low/sampleRate, b.High/sampleRate
+		w := b.Weight
+		if w == 0 {
+			w = 1
+		}
+		n := max(1, int(math.Ceil((hi-lo)/step)))
+		for i := 0; i <= n; i++ {
+			grid = append(grid, lo+(hi-lo)*float64(i)/float64(n))
+			desired = append(desired, b.Gain)
+			weight = append(weight, w)
+			band = append(band, j)
+		}
+	}
+	return grid, desired, weight, band
+}
+
+// remezInterpolator is the barycentric Lagrange form of the amplitude
+// response through the current extremal frequencies
+type remezInterpolator struct {
+	delta float64
+	xs    []float64 // Interpolation nodes, cos(2πf)
+	cs    []float64 // Values at the nodes
+	ws    []float64 // Barycentric weights
+}
+
+// barycentric returns the weights 1/Π(x_k - x_i), scaled to avoid overflow
+func barycentric(xs []float64) []float64 {
+	ws := make([]float64, len(xs))
+	for k := range xs {
+		w := 1.0
+		for i := range xs {
+			if i != k {
+				w *= 2 * (xs[k] - xs[i])
+			}
+		}
+		ws[k] = 1 / w
+	}
+	return ws
+}
+
+func newRemezInterpolator(x, desired, weight []float64, ext []int) *remezInterpolator {
+	xs := make([]float64, len(ext))
+	for k, i := range ext {
+		xs[k] = x[i]
+	}
+	bs := barycentric(xs)
+
+	// Deviation that makes the error alternate over all extremals
+	num, den := 0.0, 0.0
+	sign := 1.0
+	for k, i := range ext {
+		num += bs[k] * desired[i]
+		den += sign * bs[k] / weight[i]
+		sign = -sign
+	}
+	delta := num / den
+
+	// Interpolate through all but the last extremal
+	n := len(ext) - 1
+	p := &remezInterpolator{delta: delta, xs: xs[:n], cs: make([]float64, n)}
+	sign = 1.0
+	for k := 0; k < n; k++ {
+		i := ext[k]
+		p.cs[k] = desired[i] - sign*delta/weight[i]
+		sign = -sign
+	}
+	p.ws = barycentric(p.xs)
+	return p
+}
+
+func (p *remezInterpolator) eval(x float64) float64 {
+	num, den := 0.0, 0.0
+	for k, xk := range p.xs {
+		d := x - xk
+		if math.Abs(d) < 1e-14 {
+			return p.cs[k]
+		}
+		t := p.ws[k] / d
+		num += t * p.cs[k]
+		den += t
+	}
+	return num / den
+}
+
+// remezExtrema picks count alternating error extrema, or nil if there are
+// too few
+func remezExtrema(errs []float64, band []int, count int) []int {
+	var ext []int
+	for i := range errs {
+		e := errs[i]
+		if e == 0 {
+			continue
+		}
+		// Band edges count as extrema
+		leftEdge := i == 0 || band[i-1] != band[i]
+		rightEdge := i == len(errs)-1 || band[i+1] != band[i]
+		// Compare with the sign of e so an extremum next to a sign change counts
+		s := math.Copysign(1, e)
+		isMax := (leftEdge || s*e >= s*errs[i-1]) && (rightEdge || s*e > s*errs[i+1])
+		if !isMax {
+			continue
+		}
+
+		// Keep alternation: of neighbours with the same sign keep the larger
+		if n := len(ext); n > 0 && math.Signbit(errs[ext[n-1]]) == math.Signbit(e) {
+			if math.Abs(e) > math.Abs(errs[ext[n-1]]) {
+				ext[n-1] = i
+			}
+			continue
+		}
+		ext = append(ext, i)
+	}
+
+	for len(ext) > count {
+		// Dropping an end keeps alternation; so does dropping an interior pair
+		smallest := 0
+		for k := range ext {
+			if math.Abs(errs[ext[k]]) < math.Abs(errs[ext[smallest]]) {
+				smallest = k
+			}
+		}
+		switch {
+		case smallest == 0 || len(ext)-count == 1:
+			if math.Abs(errs[ext[0]]) < math.Abs(errs[ext[len(ext)-1]]) {
+				ext = ext[1:]
+			} else {
+				ext = ext[:len(ext)-1]
+			}
+		case smallest == len(ext)-1:
+			ext = ext[:len(ext)-1]
+		default:
+			ext = append(ext[:smallest], ext[smallest+2:]...)
+		}
+	}
+	if len(ext) < count {
+		return nil
+	}
+	return ext
+}
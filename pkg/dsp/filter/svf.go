@@ -1,7 +1,11 @@
 // Package filter provides digital signal processing filters
 package filter
 
-import "math"
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+)
 
 // SVF implements a state variable filter
 // Provides simultaneous lowpass, highpass, bandpass, and notch outputs
@@ -43,8 +47,7 @@ func (s *SVF) Reset() {
 // SetFrequency sets the filter frequency
 func (s *SVF) SetFrequency(sampleRate, frequency float64) {
 	// Pre-warp the frequency for the bilinear transform
-	omega := math.Tan(math.Pi * frequency / sampleRate)
-	s.g = float32(omega)
+	s.g = float32(coeff.Prewarp(frequency, sampleRate))
 }
 
 // SetQ sets the filter resonance (Q factor)
@@ -0,0 +1,329 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// WeightingType selects a standard frequency weighting curve
+type WeightingType int
+
+const (
+	// WeightingA is the IEC 61672 A-weighting curve (low-level loudness)
+	WeightingA WeightingType = iota
+	// WeightingC is the IEC 61672 C-weighting curve (high-level loudness)
+	WeightingC
+	// WeightingK is the ITU-R BS.1770 K-weighting curve used for LUFS
+	WeightingK
+)
+
+// Analog pole frequencies for A and C weighting (IEC 61672)
+const (
+	weightingF1 = 20.598997
+	weightingF2 = 107.65265
+	weightingF3 = 737.86223
+	weightingF4 = 12194.217
+)
+
+// weightingSection holds one normalized second-order section
+type weightingSection struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+}
+
+// Weighting applies a frequency weighting curve as a cascade of biquads.
+// State is kept in float64 since the A/C curves place poles very close to DC.
+type Weighting struct {
+	weightingType WeightingType
+	sampleRate    float64
+	sections      []weightingSection
+	gain          float64
+
+	// Per-channel, per-section state (Direct Form II transposed)
+	z1, z2 [][]float64
+}
+
+// NewWeighting creates a weighting filter for the specified number of channels
+func NewWeighting(weightingType WeightingType, sampleRate float64, channels int) *Weighting {
+	w := &Weighting{
+		weightingType: weightingType,
+		sampleRate:    sampleRate,
+	}
+	w.design()
+
+	w.z1 = make([][]float64, channels)
+	w.z2 = make([][]float64, channels)
+	for ch := 0; ch < channels; ch++ {
+		w.z1[ch] = make([]float64, len(w.sections))
+		w.z2[ch] = make([]float64, len(w.sections))
+	}
+
+	return w
+}
+
+// Type returns the weighting curve type
+func (w *Weighting) Type() WeightingType {
+	return w.weightingType
+}
+
+// design builds the filter sections for the current type and sample rate
+func (w *Weighting) design() {
+	fs := w.sampleRate
+	w1 := 2.0 * math.Pi * weightingF1
+	w2 := 2.0 * math.Pi * weightingF2
+	w3 := 2.0 * math.Pi * weightingF3
+	w4 := 2.0 * math.Pi * weightingF4
+
+	switch w.weightingType {
+	case WeightingA:
+		// s^4 / ((s+w1)^2 (s+w2)(s+w3)(s+w4)^2)
+		w.sections = []weightingSection{
+			bilinearSection(1, 0, 0, 1, 2*w1, w1*w1, fs),
+			bilinearSection(1, 0, 0, 1, w2+w3, w2*w3, fs),
+			bilinearSection(0, 0, 1, 1, 2*w4, w4*w4, fs),
+		}
+	case WeightingC:
+		// s^2 / ((s+w1)^2 (s+w4)^2)
+		w.sections = []weightingSection{
+			bilinearSection(1, 0, 0, 1, 2*w1, w1*w1, fs),
+			bilinearSection(0, 0, 1, 1, 2*w4, w4*w4, fs),
+		}
+	case WeightingK:
+		w.sections = []weightingSection{
+			kWeightingShelf(fs),
+			kWeightingHighpass(fs),
+		}
+	}
+
+	// A and C are normalized to 0 dB at 1 kHz; K keeps its BS.1770 gain
+	w.gain = 1.0
+	if w.weightingType != WeightingK {
+		w.gain = 1.0 / cmplx.Abs(w.response(1000.0))
+	}
+}
+
+// bilinearSection maps an analog section (b2 s^2 + b1 s + b0)/(a2 s^2 + a1 s + a0)
+// to the z-plane using the bilinear transform
+func bilinearSection(b2, b1, b0, a2, a1, a0, sampleRate float64) weightingSection {
+	k := 2.0 * sampleRate
+	k2 := k * k
+
+	nb0 := b2*k2 + b1*k + b0
+	nb1 := 2.0*b0 - 2.0*b2*k2
+	nb2 := b2*k2 - b1*k + b0
+	na0 := a2*k2 + a1*k + a0
+	na1 := 2.0*a0 - 2.0*a2*k2
+	na2 := a2*k2 - a1*k + a0
+
+	return weightingSection{
+		b0: nb0 / na0,
+		b1: nb1 / na0,
+		b2: nb2 / na0,
+		a1: na1 / na0,
+		a2: na2 / na0,
+	}
+}
+
+// kWeightingShelf is the BS.1770 head-related high shelf, redesigned for any sample rate
+func kWeightingShelf(sampleRate float64) weightingSection {
+	f0 := 1681.974450955533
+	g := 3.999843853973347
+	q := 0.7071752369554196
+
+	k := math.Tan(math.Pi * f0 / sampleRate)
+	vh := math.Pow(10.0, g/20.0)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1.0 + k/q + k*k
+
+	return weightingSection{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2.0 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2.0 * (k*k - 1.0) / a0,
+		a2: (1.0 - k/q + k*k) / a0,
+	}
+}
+
+// kWeightingHighpass is the BS.1770 RLB high-pass, redesigned for any sample rate
+func kWeightingHighpass(sampleRate float64) weightingSection {
+	f0 := 38.13547087602444
+	q := 0.5003270373238773
+
+	k := math.Tan(math.Pi * f0 / sampleRate)
+	a0 := 1.0 + k/q + k*k
+
+	return weightingSection{
+		b0: 1.0,
+		b1: -2.0,
+		b2: 1.0,
+		a1: 2.0 * (k*k - 1.0) / a0,
+		a2: (1.0 - k/q + k*k) / a0,
+	}
+}
+
+// response evaluates the unscaled digital response at a frequency
+func (w *Weighting) response(freq float64) complex128 {
+	omega := 2.0 * math.Pi * freq / w.sampleRate
+	z1 := cmplx.Exp(complex(0, -omega))
+	z2 := z1 * z1
+
+	h := complex(1, 0)
+	for _, s := range w.sections {
+		num := complex(s.b0, 0) + complex(s.b1, 0)*z1 + complex(s.b2, 0)*z2
+		den := complex(1, 0) + complex(s.a1, 0)*z1 + complex(s.a2, 0)*z2
+		h *= num / den
+	}
+	return h
+}
+
+// GetMagnitudeDB returns the filter's gain in dB at the given frequency
+func (w *Weighting) GetMagnitudeDB(freq float64) float64 {
+	mag := cmplx.Abs(w.response(freq)) * w.gain
+	if mag <= 0 {
+		return -120.0
+	}
+	return 20.0 * math.Log10(mag)
+}
+
+// ProcessSample filters a single sample on the given channel
+func (w *Weighting) ProcessSample(input float32, channel int) float32 {
+	z1 := w.z1[channel]
+	z2 := w.z2[channel]
+
+	x := float64(input) * w.gain
+	for i := range w.sections {
+		s := &w.sections[i]
+		y := s.b0*x + z1[i]
+		z1[i] = s.b1*x - s.a1*y + z2[i]
+		z2[i] = s.b2*x - s.a2*y
+		x = y
+	}
+
+	return float32(x)
+}
+
+// Process applies the weighting to a buffer (single channel) - no allocations
+func (w *Weighting) Process(buffer []float32, channel int) {
+	for i := range buffer {
+		buffer[i] = w.ProcessSample(buffer[i], channel)
+	}
+}
+
+// ProcessMulti applies the weighting to multiple channels - no allocations
+func (w *Weighting) ProcessMulti(buffers [][]float32) {
+	for ch, buffer := range buffers {
+		if ch < len(w.z1) {
+			w.Process(buffer, ch)
+		}
+	}
+}
+
+// Reset clears the filter state
+func (w *Weighting) Reset() {
+	for ch := range w.z1 {
+		for i := range w.z1[ch] {
+			w.z1[ch][i] = 0
+			w.z2[ch][i] = 0
+		}
+	}
+}
+
+// AWeightingDB returns the analog IEC 61672 A-weighting gain in dB
+func AWeightingDB(freq float64) float64 {
+	f2 := freq * freq
+	ra := (weightingF4 * weightingF4 * f2 * f2) /
+		((f2 + weightingF1*weightingF1) *
+			math.Sqrt((f2+weightingF2*weightingF2)*(f2+weightingF3*weightingF3)) *
+			(f2 + weightingF4*weightingF4))
+	if ra <= 0 {
+		return -120.0
+	}
+	return 20.0*math.Log10(ra) + 2.0
+}
+
+// CWeightingDB returns the analog IEC 61672 C-weighting gain in dB
+func CWeightingDB(freq float64) float64 {
+	f2 := freq * freq
+	rc := (weightingF4 * weightingF4 * f2) /
+		((f2 + weightingF1*weightingF1) * (f2 + weightingF4*weightingF4))
+	if rc <= 0 {
+		return -120.0
+	}
+	return 20.0*math.Log10(rc) + 0.06
+}
+
+// ISO 226:2003 equal-loudness parameters
+var (
+	iso226Freqs = []float64{
+		20, 25, 31.5, 40, 50, 63, 80, 100, 125, 160, 200, 250, 315, 400, 500,
+		630, 800, 1000, 1250, 1600, 2000, 2500, 3150, 4000, 5000, 6300, 8000, 10000, 12500,
+	}
+	iso226Af = []float64{
+		0.532, 0.506, 0.480, 0.455, 0.432, 0.409, 0.387, 0.367, 0.349, 0.330, 0.315, 0.301, 0.288, 0.276, 0.267,
+		0.259, 0.253, 0.250, 0.246, 0.244, 0.243, 0.243, 0.243, 0.242, 0.242, 0.245, 0.254, 0.271, 0.301,
+	}
+	iso226Lu = []float64{
+		-31.6, -27.2, -23.0, -19.1, -15.9, -13.0, -10.3, -8.1, -6.2, -4.5, -3.1, -2.0, -1.1, -0.4, 0.0,
+		0.3, 0.5, 0.0, -2.7, -4.1, -1.0, 1.7, 2.5, 1.2, -2.1, -7.1, -11.2, -10.7, -3.1,
+	}
+	iso226Tf = []float64{
+		78.5, 68.7, 59.5, 51.1, 44.0, 37.5, 31.5, 26.5, 22.1, 17.9, 14.4, 11.4, 8.6, 6.2, 4.4,
+		3.0, 2.2, 2.4, 3.5, 1.7, -1.3, -4.2, -6.0, -5.4, -1.5, 6.0, 12.6, 13.9, 12.3,
+	}
+)
+
+// ISO226Frequencies returns the frequencies the ISO 226 contours are defined at
+func ISO226Frequencies() []float64 {
+	freqs := make([]float64, len(iso226Freqs))
+	copy(freqs, iso226Freqs)
+	return freqs
+}
+
+// ISO226Contour returns the sound pressure level (dB SPL) of the equal-loudness
+// contour for the given loudness level (0-90 phon) at each ISO 226 frequency
+func ISO226Contour(phon float64) []float64 {
+	phon = math.Max(0.0, math.Min(90.0, phon))
+
+	spl := make([]float64, len(iso226Freqs))
+	for i := range iso226Freqs {
+		af := iso226Af[i]
+		a := 4.47e-3*(math.Pow(10.0, 0.025*phon)-1.15) +
+			math.Pow(0.4*math.Pow(10.0, (iso226Tf[i]+iso226Lu[i])/10.0-9.0), af)
+		spl[i] = (10.0/af)*math.Log10(a) - iso226Lu[i] + 94.0
+	}
+	return spl
+}
+
+// ISO226SPL returns the contour level at an arbitrary frequency, interpolating
+// on a log-frequency axis and clamping outside the 20 Hz - 12.5 kHz range
+func ISO226SPL(phon, freq float64) float64 {
+	return interpolateContour(ISO226Contour(phon), freq)
+}
+
+// ISO226WeightingDB returns the relative sensitivity in dB of the ear at freq
+// for the given loudness level, normalized to 0 dB at 1 kHz. It can be used
+// to weight spectra the way the A/C curves do, but for any listening level.
+func ISO226WeightingDB(phon, freq float64) float64 {
+	contour := ISO226Contour(phon)
+	return interpolateContour(contour, 1000.0) - interpolateContour(contour, freq)
+}
+
+// interpolateContour interpolates contour values on a log-frequency axis
+func interpolateContour(contour []float64, freq float64) float64 {
+	last := len(iso226Freqs) - 1
+	if freq <= iso226Freqs[0] {
+		return contour[0]
+	}
+	if freq >= iso226Freqs[last] {
+		return contour[last]
+	}
+
+	for i := 0; i < last; i++ {
+		f0, f1 := iso226Freqs[i], iso226Freqs[i+1]
+		if freq <= f1 {
+			t := math.Log(freq/f0) / math.Log(f1/f0)
+			return contour[i] + t*(contour[i+1]-contour[i])
+		}
+	}
+	return contour[last]
+}
@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWeightingResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		weighting WeightingType
+		freq      float64
+		expected  float64
+		tolerance float64
+	}{
+		{"A 1kHz", WeightingA, 1000, 0.0, 0.01},
+		{"A 100Hz", WeightingA, 100, -19.1, 0.3},
+		{"A 50Hz", WeightingA, 50, -30.2, 0.3},
+		{"C 1kHz", WeightingC, 1000, 0.0, 0.01},
+		{"C 31.5Hz", WeightingC, 31.5, -3.0, 0.3},
+		{"K 1kHz", WeightingK, 1000, 0.69, 0.1},
+		{"K 20Hz", WeightingK, 20, -13.0, 2.0},
+	}
+
+	for _, tt := range tests {
+		w := NewWeighting(tt.weighting, 48000, 1)
+		got := w.GetMagnitudeDB(tt.freq)
+		if math.Abs(got-tt.expected) > tt.tolerance {
+			t.Errorf("%s: expected %.2f dB, got %.2f dB", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestWeightingAnalogCurves(t *testing.T) {
+	if db := AWeightingDB(1000); math.Abs(db) > 0.01 {
+		t.Errorf("A-weighting at 1kHz should be 0 dB, got %.3f", db)
+	}
+	if db := AWeightingDB(10000); math.Abs(db-(-2.5)) > 0.1 {
+		t.Errorf("A-weighting at 10kHz should be -2.5 dB, got %.3f", db)
+	}
+	if db := CWeightingDB(1000); math.Abs(db) > 0.01 {
+		t.Errorf("C-weighting at 1kHz should be 0 dB, got %.3f", db)
+	}
+}
+
+func TestWeightingProcessSine(t *testing.T) {
+	sampleRate := 48000.0
+	w := NewWeighting(WeightingA, sampleRate, 2)
+
+	// A 100 Hz tone should come out roughly 19 dB quieter
+	n := 48000
+	buffer := make([]float32, n)
+	for i := range buffer {
+		buffer[i] = float32(math.Sin(2 * math.Pi * 100 * float64(i) / sampleRate))
+	}
+	w.Process(buffer, 0)
+
+	peak := float32(0)
+	for _, s := range buffer[n/2:] {
+		if s > peak {
+			peak = s
+		}
+	}
+
+	db := 20 * math.Log10(float64(peak))
+	if math.Abs(db-(-19.1)) > 0.5 {
+		t.Errorf("Expected about -19.1 dB at 100 Hz, got %.2f dB", db)
+	}
+
+	w.Reset()
+	if out := w.ProcessSample(0, 0); out != 0 {
+		t.Errorf("Expected silence after reset, got %f", out)
+	}
+}
+
+func TestISO226Contour(t *testing.T) {
+	freqs := ISO226Frequencies()
+	contour := ISO226Contour(40)
+	if len(contour) != len(freqs) {
+		t.Fatalf("Expected %d contour points, got %d", len(freqs), len(contour))
+	}
+
+	// By definition the contour passes through the phon level at 1 kHz
+	if spl := ISO226SPL(40, 1000); math.Abs(spl-40) > 0.1 {
+		t.Errorf("40 phon contour at 1kHz should be 40 dB SPL, got %.2f", spl)
+	}
+
+	// The ear is far less sensitive at low frequencies
+	if spl := ISO226SPL(40, 100); spl < 55 || spl > 70 {
+		t.Errorf("40 phon contour at 100Hz should be around 62 dB SPL, got %.2f", spl)
+	}
+
+	if db := ISO226WeightingDB(40, 1000); math.Abs(db) > 1e-9 {
+		t.Errorf("Relative weighting at 1kHz should be 0 dB, got %.3f", db)
+	}
+	if db := ISO226WeightingDB(40, 3150); db <= 0 {
+		t.Errorf("Ear should be more sensitive around 3 kHz, got %.3f dB", db)
+	}
+}
@@ -3,6 +3,9 @@ package gain
 
 import (
 	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+	"github.com/justyntemme/vst3go/pkg/dsp/vector"
 )
 
 // Constants for dB conversion
@@ -37,7 +40,7 @@ func LinearToDb32(linear float32) float32 {
 	if linear <= 0 {
 		return MinDB
 	}
-	return 20.0 * float32(math.Log10(float64(linear)))
+	return fastmath.High.LinearToDb(linear)
 }
 
 // DbToLinear32 is the float32 version of DbToLinear.
@@ -45,7 +48,7 @@ func DbToLinear32(db float32) float32 {
 	if db <= MinDB {
 		return 0
 	}
-	return float32(math.Pow(10.0, float64(db)/20.0))
+	return fastmath.High.DbToLinear(db)
 }
 
 // Apply applies a gain factor to a sample.
@@ -60,9 +63,7 @@ func ApplyDb(sample float32, db float32) float32 {
 
 // ApplyBuffer applies gain to an entire buffer in-place.
 func ApplyBuffer(buffer []float32, gain float32) {
-	for i := range buffer {
-		buffer[i] *= gain
-	}
+	vector.Mul(buffer, buffer, gain)
 }
 
 // ApplyDbBuffer applies dB gain to an entire buffer in-place.
@@ -73,14 +74,7 @@ func ApplyDbBuffer(buffer []float32, db float32) {
 
 // ApplyBufferTo applies gain to a buffer and stores in destination.
 func ApplyBufferTo(src []float32, gain float32, dst []float32) {
-	length := len(src)
-	if len(dst) < length {
-		length = len(dst)
-	}
-	
-	for i := 0; i < length; i++ {
-		dst[i] = src[i] * gain
-	}
+	vector.Mul(dst, src, gain)
 }
 
 // Fade applies a linear fade between two gain values.
@@ -120,7 +114,7 @@ func SoftClip(input, threshold float32) float32 {
 	if absInput <= threshold {
 		return input
 	}
-	return threshold * fastTanh32(input/threshold)
+	return threshold * fastmath.Low.Tanh(input/threshold)
 }
 
 // SoftClipBuffer applies soft clipping to an entire buffer.
@@ -147,15 +141,3 @@ func HardClipBuffer(buffer []float32, threshold float32) {
 		buffer[i] = HardClip(buffer[i], threshold)
 	}
 }
-
-// fastTanh32 approximates tanh for soft clipping.
-func fastTanh32(x float32) float32 {
-	if x < -3 {
-		return -1
-	}
-	if x > 3 {
-		return 1
-	}
-	x2 := x * x
-	return x * (27 + x2) / (27 + 9*x2)
-}
\ No newline at end of file
@@ -0,0 +1,352 @@
+// Package granular provides a granular delay: a scheduler that plays short,
+// windowed grains out of a circular buffer of recent input, for textures,
+// clouds and ambient delays.
+package granular
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+	"github.com/justyntemme/vst3go/pkg/dsp/interpolation"
+)
+
+// Parameter limits
+const (
+	MaxGrains      = 64    // Grains that can sound at once
+	MinSize        = 0.01  // Grain length in seconds
+	MaxSize        = 1.0   // Grain length in seconds
+	MinDensity     = 1.0   // Grains per second
+	MaxDensity     = 200.0 // Grains per second
+	MaxPitch       = 24.0  // Semitones either way, including jitter
+	MaxPitchJitter = 12.0  // Semitones
+	MaxFeedback    = 0.95  // Feedback gain
+	maxRate        = 4.0   // Playback rate at MaxPitch
+	guard          = 4     // Samples kept clear of the write head for interpolation
+)
+
+// grain is one playing grain
+type grain struct {
+	active   bool
+	pos      float64 // Read position in the buffer, in samples
+	inc      float64 // Read increment; negative plays in reverse
+	phase    float64 // Window position (0-1)
+	phaseInc float64
+	window   []float32
+	gainL    float32
+	gainR    float32
+}
+
+// Granulator is a granular delay. Input is written into a circular stereo
+// buffer; grains are started at a steady density, each reading a short
+// stretch of the buffer from around the delay time, at its own pitch,
+// direction and stereo position, shaped by a window. The grain pool is
+// fixed, so processing does not allocate.
+type Granulator struct {
+	sampleRate float64
+
+	// Circular buffer
+	buffer   [2][]float32
+	length   int
+	writePos int
+	frozen   bool
+
+	// Parameters
+	delay       float64 // Seconds
+	spray       float64 // Seconds of random delay added per grain
+	size        float64 // Seconds
+	density     float64 // Grains per second
+	pitch       float64 // Semitones
+	pitchJitter float64 // Semitones
+	reverse     float64 // Probability (0-1)
+	spread      float64 // 0-1
+	window      Window
+	feedback    float32
+	mix         float32
+
+	// Scheduler
+	grains    [MaxGrains]grain
+	untilNext float64 // Samples until the next grain
+	norm      float32 // Output gain for the grain overlap
+	rand      *rand.Rand
+
+	// Previous wet output, for feedback
+	lastL float32
+	lastR float32
+}
+
+// NewGranulator creates a granulator that can reach back maxDelay seconds
+// (delay plus spray)
+func NewGranulator(maxDelay, sampleRate float64) *Granulator {
+	length := int((maxDelay+MaxSize*(1+maxRate))*sampleRate) + 4*guard
+	g := &Granulator{
+		sampleRate: sampleRate,
+		length:     length,
+		delay:      math.Min(0.25, maxDelay),
+		size:       0.1,
+		density:    20,
+		window:     WindowHann,
+		mix:        0.5,
+		rand:       rand.New(rand.NewSource(rand.Int63())),
+	}
+	for ch := range g.buffer {
+		g.buffer[ch] = make([]float32, length)
+	}
+	g.updateNorm()
+	return g
+}
+
+// maxDelay returns the longest delay plus spray the buffer holds
+func (g *Granulator) maxDelay() float64 {
+	return float64(g.length-4*guard)/g.sampleRate - MaxSize*(1+maxRate)
+}
+
+// SetDelay sets how far back grains start reading, in seconds
+func (g *Granulator) SetDelay(seconds float64) {
+	g.delay = math.Max(0, math.Min(g.maxDelay(), seconds))
+}
+
+// SetSpray sets the random extra delay given to each grain, in seconds,
+// which blurs the grains in time
+func (g *Granulator) SetSpray(seconds float64) {
+	g.spray = math.Max(0, math.Min(g.maxDelay(), seconds))
+}
+
+// SetSize sets the grain length in seconds (0.01-1)
+func (g *Granulator) SetSize(seconds float64) {
+	g.size = math.Max(MinSize, math.Min(MaxSize, seconds))
+	g.updateNorm()
+}
+
+// SetDensity sets how many grains start per second (1-200)
+func (g *Granulator) SetDensity(grainsPerSecond float64) {
+	g.density = math.Max(MinDensity, math.Min(MaxDensity, grainsPerSecond))
+	g.updateNorm()
+}
+
+// SetPitch sets the grain transposition in semitones (-24 to 24)
+func (g *Granulator) SetPitch(semitones float64) {
+	g.pitch = math.Max(-MaxPitch, math.Min(MaxPitch, semitones))
+}
+
+// SetPitchJitter sets the random transposition range per grain, in
+// semitones either way (0-12)
+func (g *Granulator) SetPitchJitter(semitones float64) {
+	g.pitchJitter = math.Max(0, math.Min(MaxPitchJitter, semitones))
+}
+
+// SetReverse sets the probability that a grain plays backwards (0-1)
+func (g *Granulator) SetReverse(probability float64) {
+	g.reverse = math.Max(0, math.Min(1, probability))
+}
+
+// SetSpread sets how far grains are scattered across the stereo field
+// (0 = all centred, 1 = anywhere from left to right)
+func (g *Granulator) SetSpread(spread float64) {
+	g.spread = math.Max(0, math.Min(1, spread))
+}
+
+// SetWindow sets the grain window; grains already playing keep theirs
+func (g *Granulator) SetWindow(window Window) {
+	if window >= 0 && window < numWindows {
+		g.window = window
+	}
+}
+
+// SetFeedback sets how much of the output is fed back into the buffer
+// (0-0.95)
+func (g *Granulator) SetFeedback(feedback float64) {
+	g.feedback = float32(math.Max(0, math.Min(MaxFeedback, feedback)))
+}
+
+// SetMix sets the wet/dry mix (0=dry, 1=wet)
+func (g *Granulator) SetMix(mix float64) {
+	g.mix = float32(math.Max(0, math.Min(1, mix)))
+}
+
+// SetFreeze stops writing to the buffer, so the grains keep playing the
+// audio captured so far
+func (g *Granulator) SetFreeze(frozen bool) {
+	g.frozen = frozen
+}
+
+// IsFrozen returns true while the buffer is frozen
+func (g *Granulator) IsFrozen() bool {
+	return g.frozen
+}
+
+// SetSeed seeds the grain randomization for reproducible output
+func (g *Granulator) SetSeed(seed int64) {
+	g.rand = rand.New(rand.NewSource(seed))
+}
+
+// ActiveGrains returns the number of grains playing
+func (g *Granulator) ActiveGrains() int {
+	count := 0
+	for i := range g.grains {
+		if g.grains[i].active {
+			count++
+		}
+	}
+	return count
+}
+
+// updateNorm scales the output by the expected grain overlap. Grains are
+// uncorrelated, so their levels add as powers.
+func (g *Granulator) updateNorm() {
+	overlap := math.Max(1, g.density*g.size)
+	g.norm = float32(1 / math.Sqrt(overlap))
+}
+
+// spawn starts a grain in a free slot; when every slot is busy the grain is
+// skipped
+func (g *Granulator) spawn() {
+	var gr *grain
+	for i := range g.grains {
+		if !g.grains[i].active {
+			gr = &g.grains[i]
+			break
+		}
+	}
+	if gr == nil {
+		return
+	}
+
+	semitones := g.pitch + g.pitchJitter*(2*g.rand.Float64()-1)
+	semitones = math.Max(-MaxPitch, math.Min(MaxPitch, semitones))
+	rate := math.Exp2(semitones / 12)
+	reverse := g.rand.Float64() < g.reverse
+	sizeSamples := g.size * g.sampleRate
+
+	// A forward grain faster than the write head must start far enough
+	// back that it never overtakes it
+	headSpeed := 1.0
+	if g.frozen {
+		headSpeed = 0
+	}
+	start := (g.delay + g.spray*g.rand.Float64()) * g.sampleRate
+	if !reverse {
+		start = math.Max(start, (rate-headSpeed)*sizeSamples+guard)
+	}
+	start = math.Max(guard, math.Min(float64(g.length)-(1+rate)*sizeSamples-2*guard, start))
+
+	gr.pos = float64(g.writePos) - start
+	if gr.pos < 0 {
+		gr.pos += float64(g.length)
+	}
+	gr.inc = rate
+	if reverse {
+		gr.inc = -rate
+	}
+	gr.phase = 0
+	gr.phaseInc = 1 / sizeSamples
+	gr.window = windowTables[g.window]
+
+	// Constant power pan, unity at the centre
+	p := g.spread * (2*g.rand.Float64() - 1)
+	angle := (p + 1) * math.Pi / 4
+	gr.gainL = float32(math.Cos(angle) * math.Sqrt2)
+	gr.gainR = float32(math.Sin(angle) * math.Sqrt2)
+	gr.active = true
+}
+
+// read reads a channel of the buffer at a fractional position with Hermite
+// interpolation
+func (g *Granulator) read(ch int, pos float64) float32 {
+	buf := g.buffer[ch]
+	i := int(pos)
+	frac := float32(pos - float64(i))
+	i0 := i - 1
+	if i0 < 0 {
+		i0 += g.length
+	}
+	i1 := i
+	i2 := i + 1
+	if i2 >= g.length {
+		i2 -= g.length
+	}
+	i3 := i2 + 1
+	if i3 >= g.length {
+		i3 -= g.length
+	}
+	return interpolation.Hermite(buf[i0], buf[i1], buf[i2], buf[i3], frac)
+}
+
+// Process processes mono input into a stereo pair
+func (g *Granulator) Process(input float32) (outputL, outputR float32) {
+	return g.ProcessStereo(input, input)
+}
+
+// ProcessStereo processes stereo input
+func (g *Granulator) ProcessStereo(inputL, inputR float32) (outputL, outputR float32) {
+	if !g.frozen {
+		g.buffer[0][g.writePos] = inputL + fastmath.Medium.Tanh(g.lastL*g.feedback)
+		g.buffer[1][g.writePos] = inputR + fastmath.Medium.Tanh(g.lastR*g.feedback)
+		g.writePos++
+		if g.writePos >= g.length {
+			g.writePos = 0
+		}
+	}
+
+	g.untilNext--
+	if g.untilNext <= 0 {
+		g.spawn()
+		g.untilNext += g.sampleRate / g.density
+	}
+
+	var wetL, wetR float32
+	length := float64(g.length)
+	for i := range g.grains {
+		gr := &g.grains[i]
+		if !gr.active {
+			continue
+		}
+		w := lookup(gr.window, gr.phase)
+		wetL += g.read(0, gr.pos) * w * gr.gainL
+		wetR += g.read(1, gr.pos) * w * gr.gainR
+
+		gr.pos += gr.inc
+		if gr.pos >= length {
+			gr.pos -= length
+		} else if gr.pos < 0 {
+			gr.pos += length
+		}
+		gr.phase += gr.phaseInc
+		if gr.phase >= 1 {
+			gr.active = false
+		}
+	}
+	wetL *= g.norm
+	wetR *= g.norm
+	g.lastL, g.lastR = wetL, wetR
+
+	dry := 1 - g.mix
+	return inputL*dry + wetL*g.mix, inputR*dry + wetR*g.mix
+}
+
+// ProcessBuffer processes a mono buffer into stereo buffers
+func (g *Granulator) ProcessBuffer(input, outputL, outputR []float32) {
+	for i := range input {
+		outputL[i], outputR[i] = g.Process(input[i])
+	}
+}
+
+// ProcessStereoBuffer processes stereo buffers
+func (g *Granulator) ProcessStereoBuffer(inputL, inputR, outputL, outputR []float32) {
+	for i := range inputL {
+		outputL[i], outputR[i] = g.ProcessStereo(inputL[i], inputR[i])
+	}
+}
+
+// Reset clears the buffer and stops every grain
+func (g *Granulator) Reset() {
+	for ch := range g.buffer {
+		clear(g.buffer[ch])
+	}
+	for i := range g.grains {
+		g.grains[i].active = false
+	}
+	g.writePos = 0
+	g.untilNext = 0
+	g.lastL, g.lastR = 0, 0
+}
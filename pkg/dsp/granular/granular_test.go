@@ -0,0 +1,268 @@
+package granular
+
+import (
+	"math"
+	"testing"
+)
+
+const testSampleRate = 48000.0
+
+// sine returns a sine buffer
+func sine(freq float64, n int) []float32 {
+	out := make([]float32, n)
+	for i := range out {
+		out[i] = float32(math.Sin(2 * math.Pi * freq * float64(i) / testSampleRate))
+	}
+	return out
+}
+
+// zeroCrossingFreq estimates the frequency of a signal from its rising zero
+// crossings
+func zeroCrossingFreq(x []float32) float64 {
+	first, last, count := -1, -1, 0
+	for i := 1; i < len(x); i++ {
+		if x[i-1] < 0 && x[i] >= 0 {
+			if first < 0 {
+				first = i
+			} else {
+				count++
+			}
+			last = i
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(count) * testSampleRate / float64(last-first)
+}
+
+func TestWindows(t *testing.T) {
+	for w := Window(0); w < numWindows; w++ {
+		table := windowTables[w]
+		if table[0] > 1e-6 || table[windowSize] > 1e-6 {
+			t.Errorf("%v: ends %f, %f, want 0", w, table[0], table[windowSize])
+		}
+		if mid := lookup(table, 0.5); math.Abs(float64(mid)-1) > 1e-3 {
+			t.Errorf("%v: centre %f, want 1", w, mid)
+		}
+	}
+}
+
+func TestGranulatorDry(t *testing.T) {
+	g := NewGranulator(1, testSampleRate)
+	g.SetMix(0)
+	in := sine(440, 4800)
+	for i, x := range in {
+		l, r := g.Process(x)
+		if l != x || r != x {
+			t.Fatalf("sample %d: got %f/%f, want %f", i, l, r, x)
+		}
+	}
+}
+
+func TestGranulatorDelay(t *testing.T) {
+	g := NewGranulator(1, testSampleRate)
+	g.SetSeed(1)
+	g.SetMix(1)
+	g.SetDelay(0.2)
+	g.SetSize(0.05)
+	g.SetDensity(100)
+	g.SetWindow(WindowTukey)
+
+	// A burst of tone must not reach the output before the delay time
+	burst := int(0.05 * testSampleRate)
+	first := -1
+	for i := 0; i < int(0.5*testSampleRate); i++ {
+		var x float32
+		if i < burst {
+			x = float32(math.Sin(2 * math.Pi * 1000 * float64(i) / testSampleRate))
+		}
+		l, _ := g.Process(x)
+		if first < 0 && math.Abs(float64(l)) > 0.01 {
+			first = i
+		}
+	}
+	delay := int(0.2 * testSampleRate)
+	if first < delay-burst || first > delay+burst {
+		t.Errorf("first output at %d samples, want near %d", first, delay)
+	}
+}
+
+func TestGranulatorPitch(t *testing.T) {
+	for _, semitones := range []float64{-12, 0, 12} {
+		g := NewGranulator(1, testSampleRate)
+		g.SetSeed(1)
+		g.SetMix(1)
+		g.SetDelay(0.1)
+		g.SetSize(0.2)
+		g.SetDensity(10)
+		g.SetWindow(WindowTukey)
+		g.SetPitch(semitones)
+
+		in := sine(440, int(2*testSampleRate))
+		out := make([]float32, len(in))
+		for i, x := range in {
+			out[i], _ = g.Process(x)
+		}
+
+		want := 440 * math.Exp2(semitones/12)
+		got := zeroCrossingFreq(out[len(out)/2:])
+		if math.Abs(got-want)/want > 0.03 {
+			t.Errorf("pitch %+.0f: got %.1f Hz, want %.1f Hz", semitones, got, want)
+		}
+	}
+}
+
+func TestGranulatorReverse(t *testing.T) {
+	// A rising ramp played backwards falls
+	g := NewGranulator(1, testSampleRate)
+	g.SetSeed(1)
+	g.SetMix(1)
+	g.SetReverse(1)
+	g.SetSize(0.2)
+	g.SetDensity(2)
+	g.SetWindow(WindowTukey)
+
+	n := int(1.5 * testSampleRate)
+	var rising, falling int
+	var prev float32
+	for i := 0; i < n; i++ {
+		l, _ := g.Process(float32(i) / float32(n))
+		if g.ActiveGrains() > 0 && l != 0 && prev != 0 {
+			if l > prev {
+				rising++
+			} else if l < prev {
+				falling++
+			}
+		}
+		prev = l
+	}
+	if falling <= rising {
+		t.Errorf("reverse grains: %d falling, %d rising samples", falling, rising)
+	}
+}
+
+func TestGranulatorSpread(t *testing.T) {
+	in := sine(220, int(testSampleRate))
+
+	g := NewGranulator(1, testSampleRate)
+	g.SetSeed(1)
+	g.SetMix(1)
+	for _, x := range in {
+		if l, r := g.Process(x); l != r {
+			t.Fatal("grains without spread should be centred")
+		}
+	}
+
+	g = NewGranulator(1, testSampleRate)
+	g.SetSeed(1)
+	g.SetMix(1)
+	g.SetSpread(1)
+	var diff float64
+	for _, x := range in {
+		l, r := g.Process(x)
+		diff += math.Abs(float64(l - r))
+	}
+	if diff < 1 {
+		t.Errorf("full spread left and right differ by only %f", diff)
+	}
+}
+
+func TestGranulatorDensity(t *testing.T) {
+	g := NewGranulator(1, testSampleRate)
+	g.SetSize(0.1)
+	g.SetDensity(50)
+	in := sine(440, int(testSampleRate))
+	most := 0
+	for _, x := range in {
+		g.Process(x)
+		most = max(most, g.ActiveGrains())
+	}
+	// 50 grains a second of 100 ms each overlap five deep
+	if most < 4 || most > 6 {
+		t.Errorf("%d grains at once, want about 5", most)
+	}
+
+	g.SetDensity(MaxDensity)
+	g.SetSize(MaxSize)
+	for _, x := range in {
+		g.Process(x)
+	}
+	if g.ActiveGrains() > MaxGrains {
+		t.Errorf("%d grains, pool is %d", g.ActiveGrains(), MaxGrains)
+	}
+}
+
+func TestGranulatorStability(t *testing.T) {
+	g := NewGranulator(2, testSampleRate)
+	g.SetSeed(1)
+	g.SetMix(1)
+	g.SetFeedback(MaxFeedback)
+	g.SetPitchJitter(MaxPitchJitter)
+	g.SetReverse(0.5)
+	g.SetSpread(1)
+	g.SetSpray(0.5)
+	g.SetDensity(MaxDensity)
+	in := sine(330, int(5*testSampleRate))
+	for i, x := range in {
+		l, r := g.Process(x)
+		if math.IsNaN(float64(l)) || math.IsNaN(float64(r)) || math.Abs(float64(l)) > 10 || math.Abs(float64(r)) > 10 {
+			t.Fatalf("sample %d: unstable output %f/%f", i, l, r)
+		}
+	}
+}
+
+func TestGranulatorFreeze(t *testing.T) {
+	g := NewGranulator(1, testSampleRate)
+	g.SetSeed(1)
+	g.SetMix(1)
+	g.SetSize(0.05)
+	for _, x := range sine(440, int(0.5*testSampleRate)) {
+		g.Process(x)
+	}
+
+	// Frozen, the grains keep playing the captured tone with silent input
+	g.SetFreeze(true)
+	var energy float64
+	for i := 0; i < int(testSampleRate); i++ {
+		l, _ := g.Process(0)
+		energy += float64(l * l)
+	}
+	if energy < 100 {
+		t.Errorf("frozen output energy %f, want the captured tone", energy)
+	}
+}
+
+func TestGranulatorNoAllocs(t *testing.T) {
+	g := NewGranulator(1, testSampleRate)
+	g.SetDensity(MaxDensity)
+	g.SetPitchJitter(5)
+	g.SetReverse(0.5)
+	g.SetSpread(1)
+	in := sine(440, 512)
+	outL := make([]float32, len(in))
+	outR := make([]float32, len(in))
+	allocs := testing.AllocsPerRun(100, func() {
+		g.ProcessBuffer(in, outL, outR)
+	})
+	if allocs != 0 {
+		t.Errorf("%v allocations per block, want 0", allocs)
+	}
+}
+
+func TestGranulatorReset(t *testing.T) {
+	g := NewGranulator(1, testSampleRate)
+	g.SetMix(1)
+	for _, x := range sine(440, 9600) {
+		g.Process(x)
+	}
+	g.Reset()
+	if g.ActiveGrains() != 0 {
+		t.Fatal("grains still playing after reset")
+	}
+	for i := 0; i < 9600; i++ {
+		if l, r := g.Process(0); l != 0 || r != 0 {
+			t.Fatalf("sample %d: got %f/%f after reset", i, l, r)
+		}
+	}
+}
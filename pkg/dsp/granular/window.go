@@ -0,0 +1,90 @@
+package granular
+
+import "math"
+
+// Window is the amplitude envelope of a grain
+type Window int
+
+const (
+	// WindowHann is a raised cosine: smooth, the classic grain shape
+	WindowHann Window = iota
+	// WindowTriangle rises and falls linearly
+	WindowTriangle
+	// WindowTukey is flat in the middle with cosine fades over the outer
+	// quarters, which keeps more of the source's level and attack
+	WindowTukey
+	// WindowGaussian is narrow and bell shaped, for soft, pointillistic clouds
+	WindowGaussian
+	numWindows
+)
+
+// String returns the window name
+func (w Window) String() string {
+	switch w {
+	case WindowHann:
+		return "Hann"
+	case WindowTriangle:
+		return "Triangle"
+	case WindowTukey:
+		return "Tukey"
+	case WindowGaussian:
+		return "Gaussian"
+	default:
+		return "Unknown"
+	}
+}
+
+// windowSize is the length of a window table; one guard point follows it
+const windowSize = 1024
+
+// windowTables are the window shapes, built once
+var windowTables = buildWindows()
+
+// buildWindows computes every window table
+func buildWindows() [numWindows][]float32 {
+	var tables [numWindows][]float32
+	for w := range tables {
+		table := make([]float32, windowSize+1)
+		for i := range table {
+			table[i] = float32(windowValue(Window(w), float64(i)/windowSize))
+		}
+		tables[w] = table
+	}
+	return tables
+}
+
+// windowValue evaluates a window at x in 0-1
+func windowValue(w Window, x float64) float64 {
+	switch w {
+	case WindowTriangle:
+		return 1 - math.Abs(2*x-1)
+	case WindowTukey:
+		const taper = 0.25
+		if x < taper {
+			return 0.5 - 0.5*math.Cos(math.Pi*x/taper)
+		}
+		if x > 1-taper {
+			return 0.5 - 0.5*math.Cos(math.Pi*(1-x)/taper)
+		}
+		return 1
+	case WindowGaussian:
+		// Shifted and scaled so the ends reach exactly zero
+		const sigma = 0.15
+		edge := math.Exp(-0.5 * (0.5 / sigma) * (0.5 / sigma))
+		d := (x - 0.5) / sigma
+		return (math.Exp(-0.5*d*d) - edge) / (1 - edge)
+	default:
+		return 0.5 - 0.5*math.Cos(2*math.Pi*x)
+	}
+}
+
+// lookup reads a window table at x in 0-1 with linear interpolation
+func lookup(table []float32, x float64) float32 {
+	pos := x * windowSize
+	i := int(pos)
+	if i >= windowSize {
+		return table[windowSize]
+	}
+	frac := float32(pos - float64(i))
+	return table[i] + (table[i+1]-table[i])*frac
+}
@@ -3,6 +3,8 @@ package mix
 
 import (
 	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/vector"
 )
 
 // DryWet performs a dry/wet mix between two signals.
@@ -14,36 +16,13 @@ func DryWet(dry, wet, amount float32) float32 {
 // DryWetBuffer performs in-place dry/wet mixing on audio buffers.
 // amount parameter: 0.0 = 100% dry, 1.0 = 100% wet
 func DryWetBuffer(dry, wet []float32, amount float32) {
-	dryGain := 1.0 - amount
-	wetGain := amount
-	
-	length := len(dry)
-	if len(wet) < length {
-		length = len(wet)
-	}
-	
-	for i := 0; i < length; i++ {
-		dry[i] = dry[i]*dryGain + wet[i]*wetGain
-	}
+	vector.Blend(dry, dry, wet, 1.0-amount, amount)
 }
 
 // DryWetBufferTo performs dry/wet mixing into a destination buffer.
 // amount parameter: 0.0 = 100% dry, 1.0 = 100% wet
 func DryWetBufferTo(dry, wet []float32, amount float32, dst []float32) {
-	dryGain := 1.0 - amount
-	wetGain := amount
-	
-	length := len(dry)
-	if len(wet) < length {
-		length = len(wet)
-	}
-	if len(dst) < length {
-		length = len(dst)
-	}
-	
-	for i := 0; i < length; i++ {
-		dst[i] = dry[i]*dryGain + wet[i]*wetGain
-	}
+	vector.Blend(dst, dry, wet, 1.0-amount, amount)
 }
 
 // CrossfadeCosine performs an equal-power cosine crossfade.
@@ -0,0 +1,227 @@
+package mix
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+)
+
+// Summing mixes several input buses to a stereo output, like the channel
+// strips of a summing mixer. Each bus has a trim, a pan and the latency of
+// the path that feeds it. Buses that arrive earlier are delayed to line up
+// with the latest one, so parallel paths sum without comb filtering.
+//
+// Mono buses are panned with a constant power law. Stereo buses are
+// balanced, and buses with more channels contribute their first two.
+// Trim and pan changes ramp across the next block to avoid zipper noise.
+type Summing struct {
+	buses      []summingBus
+	maxLatency int // Longest compensation delay the buses can hold
+	latency    int // Latency of the latest bus
+	minLatency int // Latency of the earliest bus
+}
+
+// summingBus is one input strip
+type summingBus struct {
+	trimDB  float64
+	pan     float32
+	latency int
+
+	// Target gains for a mono and a stereo source, and the gains applied
+	// at the end of the last block
+	mono    [2]float32
+	balance [2]float32
+	gain    [2]float32
+	stereo  bool // Layout of the last block
+
+	// Compensation delay, one ring per channel
+	delay [2][]float32
+	pos   int
+}
+
+// NewSumming creates a summing mixer for the given number of buses that can
+// compensate path latencies up to maxLatencySeconds
+func NewSumming(buses int, maxLatencySeconds, sampleRate float64) *Summing {
+	s := &Summing{
+		buses:      make([]summingBus, max(1, buses)),
+		maxLatency: max(0, int(maxLatencySeconds*sampleRate)),
+	}
+	for i := range s.buses {
+		b := &s.buses[i]
+		for ch := range b.delay {
+			b.delay[ch] = make([]float32, s.maxLatency+1)
+		}
+		s.updateGains(i)
+		b.stereo = true
+		b.gain = b.balance
+	}
+	return s
+}
+
+// NumBuses returns the number of input buses
+func (s *Summing) NumBuses() int {
+	return len(s.buses)
+}
+
+// SetTrim sets the gain of a bus in dB (-inf to +24)
+func (s *Summing) SetTrim(bus int, dB float64) {
+	if bus < 0 || bus >= len(s.buses) {
+		return
+	}
+	s.buses[bus].trimDB = math.Min(24.0, dB)
+	s.updateGains(bus)
+}
+
+// GetTrim returns the gain of a bus in dB
+func (s *Summing) GetTrim(bus int) float64 {
+	if bus < 0 || bus >= len(s.buses) {
+		return 0
+	}
+	return s.buses[bus].trimDB
+}
+
+// SetPan sets the pan of a bus, or its balance when it is stereo.
+// -1.0 = hard left, 0.0 = center, 1.0 = hard right
+func (s *Summing) SetPan(bus int, position float32) {
+	if bus < 0 || bus >= len(s.buses) {
+		return
+	}
+	s.buses[bus].pan = max(-1.0, min(1.0, position))
+	s.updateGains(bus)
+}
+
+// GetPan returns the pan of a bus
+func (s *Summing) GetPan(bus int) float32 {
+	if bus < 0 || bus >= len(s.buses) {
+		return 0
+	}
+	return s.buses[bus].pan
+}
+
+// SetLatency sets the latency in samples of the path feeding a bus, as
+// reported by the plugins on it. Latencies beyond the maximum are clamped.
+func (s *Summing) SetLatency(bus, samples int) {
+	if bus < 0 || bus >= len(s.buses) {
+		return
+	}
+	s.buses[bus].latency = max(0, min(s.maxLatency, samples))
+	s.latency, s.minLatency = 0, s.maxLatency
+	for i := range s.buses {
+		s.latency = max(s.latency, s.buses[i].latency)
+		s.minLatency = min(s.minLatency, s.buses[i].latency)
+	}
+}
+
+// GetLatency returns the path latency of a bus in samples
+func (s *Summing) GetLatency(bus int) int {
+	if bus < 0 || bus >= len(s.buses) {
+		return 0
+	}
+	return s.buses[bus].latency
+}
+
+// GetCompensation returns the delay in samples added to a bus to line it
+// up with the latest one
+func (s *Summing) GetCompensation(bus int) int {
+	if bus < 0 || bus >= len(s.buses) {
+		return 0
+	}
+	return s.latency - s.buses[bus].latency
+}
+
+// GetLatencySamples returns the longest compensation delay, the latency a
+// plugin reports for the earliest bus to reach the output
+func (s *Summing) GetLatencySamples() int {
+	return s.latency - s.minLatency
+}
+
+// updateGains recomputes the target gains of a bus from its trim and pan.
+// Mono buses pan with constant power; balance would only turn one side
+// down.
+func (s *Summing) updateGains(bus int) {
+	b := &s.buses[bus]
+	trim := float32(math.Pow(10.0, b.trimDB/20.0))
+	l, r := pan.MonoToStereo(b.pan, pan.ConstantPower)
+	b.mono = [2]float32{trim * l, trim * r}
+	b.balance = [2]float32{trim * min(1.0, 1.0-b.pan), trim * min(1.0, 1.0+b.pan)}
+}
+
+// target returns the gains a bus ramps toward for its layout
+func (b *summingBus) target() [2]float32 {
+	if b.stereo {
+		return b.balance
+	}
+	return b.mono
+}
+
+// Process sums the buses into outL and outR, overwriting them. inputs holds
+// the channels of each bus; missing or empty buses are silent but keep their
+// delays running so they stay aligned when they return.
+func (s *Summing) Process(inputs [][][]float32, outL, outR []float32) {
+	n := min(len(outL), len(outR))
+	clear(outL[:n])
+	clear(outR[:n])
+	if n == 0 {
+		return
+	}
+	ramp := 1.0 / float32(n)
+
+	for i := range s.buses {
+		b := &s.buses[i]
+		var channels [][]float32
+		if i < len(inputs) {
+			channels = inputs[i]
+		}
+		compensation := s.latency - b.latency
+		size := len(b.delay[0])
+
+		b.stereo = len(channels) >= 2
+		start, end := b.gain, b.target()
+		stepL, stepR := (end[0]-start[0])*ramp, (end[1]-start[1])*ramp
+
+		gainL, gainR := start[0], start[1]
+		for j := 0; j < n; j++ {
+			var l, r float32
+			if len(channels) > 0 && j < len(channels[0]) {
+				l = channels[0][j]
+				r = l
+				if b.stereo && j < len(channels[1]) {
+					r = channels[1][j]
+				}
+			}
+
+			// The rings always run so a compensation change reads audio
+			// rather than stale samples
+			b.delay[0][b.pos], b.delay[1][b.pos] = l, r
+			if compensation > 0 {
+				read := b.pos - compensation
+				if read < 0 {
+					read += size
+				}
+				l, r = b.delay[0][read], b.delay[1][read]
+			}
+			b.pos++
+			if b.pos >= size {
+				b.pos = 0
+			}
+
+			gainL += stepL
+			gainR += stepR
+			outL[j] += l * gainL
+			outR[j] += r * gainR
+		}
+		b.gain = end
+	}
+}
+
+// Reset clears the compensation delays and jumps the gains to their targets
+func (s *Summing) Reset() {
+	for i := range s.buses {
+		b := &s.buses[i]
+		for ch := range b.delay {
+			clear(b.delay[ch])
+		}
+		b.pos = 0
+		b.gain = b.target()
+	}
+}
@@ -0,0 +1,106 @@
+package mix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSummingLatencyCompensation(t *testing.T) {
+	s := NewSumming(3, 0.1, 48000)
+	s.SetLatency(0, 0)
+	s.SetLatency(1, 64)
+	s.SetLatency(2, 256)
+	if s.GetLatencySamples() != 256 {
+		t.Errorf("Latency = %d, want 256", s.GetLatencySamples())
+	}
+	if s.GetCompensation(0) != 256 || s.GetCompensation(1) != 192 || s.GetCompensation(2) != 0 {
+		t.Errorf("Compensation = %d, %d, %d, want 256, 192, 0",
+			s.GetCompensation(0), s.GetCompensation(1), s.GetCompensation(2))
+	}
+
+	// The same impulse through three paths with different latencies lines
+	// up at the latest one
+	n := 512
+	inputs := make([][][]float32, 3)
+	for bus, latency := range []int{0, 64, 256} {
+		left, right := make([]float32, n), make([]float32, n)
+		left[latency], right[latency] = 1, 1
+		inputs[bus] = [][]float32{left, right}
+	}
+	outL, outR := make([]float32, n), make([]float32, n)
+	s.Process(inputs, outL, outR)
+	for i := range outL {
+		want := float32(0)
+		if i == 256 {
+			want = 3
+		}
+		if math.Abs(float64(outL[i]-want)) > 1e-6 || math.Abs(float64(outR[i]-want)) > 1e-6 {
+			t.Fatalf("Sample %d = %f, %f, want %f", i, outL[i], outR[i], want)
+		}
+	}
+}
+
+func TestSummingTrimAndPan(t *testing.T) {
+	s := NewSumming(2, 0, 48000)
+	s.SetTrim(0, -6)
+	s.SetPan(1, -1)
+
+	n := 64
+	ones := make([]float32, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	inputs := [][][]float32{{ones, ones}, {ones}}
+	outL, outR := make([]float32, n), make([]float32, n)
+
+	// The first block ramps from the defaults, the second holds the targets
+	s.Process(inputs, outL, outR)
+	s.Process(inputs, outL, outR)
+
+	// Stereo bus 0 at -6 dB, mono bus 1 hard left at full level
+	trim := float32(math.Pow(10, -6.0/20))
+	for i := range outL {
+		if math.Abs(float64(outL[i]-(trim+1))) > 1e-5 {
+			t.Fatalf("Left %d = %f, want %f", i, outL[i], trim+1)
+		}
+		if math.Abs(float64(outR[i]-trim)) > 1e-5 {
+			t.Fatalf("Right %d = %f, want %f", i, outR[i], trim)
+		}
+	}
+
+	// A centered mono bus follows the constant power law
+	s.SetTrim(0, math.Inf(-1))
+	s.SetPan(1, 0)
+	s.Reset()
+	s.Process(inputs, outL, outR)
+	if math.Abs(float64(outL[0])-math.Sqrt(0.5)) > 1e-5 || outL[0] != outR[0] {
+		t.Errorf("Centered mono = %f, %f, want %f", outL[0], outR[0], math.Sqrt(0.5))
+	}
+}
+
+func TestSummingMissingBus(t *testing.T) {
+	s := NewSumming(2, 0.01, 48000)
+	s.SetLatency(1, 10)
+	in := []float32{1, 2, 3, 4}
+	outL, outR := make([]float32, 4), make([]float32, 4)
+
+	// Bus 1 is absent; bus 0 is still delayed to line up with it
+	s.Process([][][]float32{{in, in}}, outL, outR)
+	for i := range outL {
+		if outL[i] != 0 || outR[i] != 0 {
+			t.Fatalf("Sample %d = %f, want silence within the compensation delay", i, outL[i])
+		}
+	}
+	silence := make([]float32, 4)
+	s.Process([][][]float32{{silence, silence}}, outL, outR)
+	s.Process([][][]float32{{silence, silence}}, outL, outR)
+	want := []float32{0, 0, 1, 2}
+	for i := range want {
+		if outL[i] != want[i] {
+			t.Errorf("Sample %d = %f, want %f", 8+i, outL[i], want[i])
+		}
+	}
+	if s.GetCompensation(5) != 0 || s.GetTrim(-1) != 0 {
+		t.Error("Out of range buses should read as zero")
+	}
+}
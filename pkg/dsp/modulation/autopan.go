@@ -0,0 +1,162 @@
+package modulation
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+)
+
+// AutoPan moves a signal between the speakers with an LFO. Its rate can be
+// set in Hz or locked to a tempo as a number of beats per cycle, and in
+// sync mode SetPosition aligns the sweep with the song position. Mono
+// input is panned with the pan law; stereo input is balanced with the same
+// law normalised to unity at the centre.
+type AutoPan struct {
+	sampleRate float64
+
+	// Parameters
+	rate     float64  // LFO rate in Hz
+	depth    float64  // Pan width (0-1)
+	waveform Waveform // LFO waveform
+	law      pan.Law
+
+	// Tempo sync
+	synced bool
+	tempo  float64 // BPM
+	beats  float64 // Beats per cycle
+
+	lfo *LFO
+
+	// Smoothing for square and random waves to avoid clicks
+	smoothing   bool
+	smoothCoeff float64
+	position    float64 // Current pan position (-1 to 1)
+
+	// Centre gains of the pan law, for normalising stereo balance
+	centerL float32
+	centerR float32
+}
+
+// NewAutoPan creates an auto-panner at 1 Hz and full width with the
+// constant power law
+func NewAutoPan(sampleRate float64) *AutoPan {
+	a := &AutoPan{
+		sampleRate:  sampleRate,
+		rate:        1.0,
+		depth:       1.0,
+		waveform:    WaveformSine,
+		tempo:       120.0,
+		beats:       1.0,
+		lfo:         NewLFO(sampleRate),
+		smoothCoeff: math.Exp(-1.0 / (0.005 * sampleRate)), // 5 ms
+	}
+	a.lfo.SetFrequency(a.rate)
+	a.SetLaw(pan.ConstantPower)
+	return a
+}
+
+// SetRate sets the rate in Hz (0.01-20) and turns tempo sync off
+func (a *AutoPan) SetRate(hz float64) {
+	a.synced = false
+	a.rate = math.Max(0.01, math.Min(20.0, hz))
+	a.lfo.SetFrequency(a.rate)
+}
+
+// SetSync locks the rate to a tempo in BPM, one cycle every beats beats
+func (a *AutoPan) SetSync(bpm, beats float64) {
+	if bpm <= 0 || beats <= 0 {
+		return
+	}
+	a.synced = true
+	a.tempo = bpm
+	a.beats = beats
+	a.rate = math.Max(0.01, math.Min(20.0, SyncedRate(bpm, beats)))
+	a.lfo.SetFrequency(a.rate)
+}
+
+// IsSynced reports whether the rate follows a tempo
+func (a *AutoPan) IsSynced() bool {
+	return a.synced
+}
+
+// SetPosition aligns the sweep with a song position in beats, so the pan
+// lands on the same part of the bar every time the host plays it. It does
+// nothing unless the rate is synced.
+func (a *AutoPan) SetPosition(beats float64) {
+	if a.synced {
+		a.lfo.SetPhase(beats / a.beats)
+	}
+}
+
+// GetRate returns the rate in Hz, derived from the tempo when synced
+func (a *AutoPan) GetRate() float64 {
+	return a.rate
+}
+
+// SetDepth sets the pan width (0-1), 1 sweeping fully between the speakers
+func (a *AutoPan) SetDepth(depth float64) {
+	a.depth = math.Max(0.0, math.Min(1.0, depth))
+}
+
+// SetWaveform sets the LFO waveform. Square and random waves are smoothed
+// over 5 ms so the jumps do not click.
+func (a *AutoPan) SetWaveform(waveform Waveform) {
+	a.waveform = waveform
+	a.lfo.SetWaveform(waveform)
+	a.smoothing = waveform == WaveformSquare || waveform == WaveformRandom
+}
+
+// SetLaw sets the pan law
+func (a *AutoPan) SetLaw(law pan.Law) {
+	a.law = law
+	a.centerL, a.centerR = pan.MonoToStereo(0, law)
+}
+
+// GetPosition returns the current pan position (-1 left to 1 right), for
+// display
+func (a *AutoPan) GetPosition() float64 {
+	return a.position
+}
+
+// next advances the LFO and returns the pan position
+func (a *AutoPan) next() float32 {
+	target := a.lfo.Process() * a.depth
+	if a.smoothing {
+		a.position = target + (a.position-target)*a.smoothCoeff
+	} else {
+		a.position = target
+	}
+	return float32(a.position)
+}
+
+// Process pans a mono sample to stereo
+func (a *AutoPan) Process(input float32) (outputL, outputR float32) {
+	gainL, gainR := pan.MonoToStereo(a.next(), a.law)
+	return input * gainL, input * gainR
+}
+
+// ProcessStereo balances stereo input
+func (a *AutoPan) ProcessStereo(inputL, inputR float32) (outputL, outputR float32) {
+	gainL, gainR := pan.MonoToStereo(a.next(), a.law)
+	return inputL * gainL / a.centerL, inputR * gainR / a.centerR
+}
+
+// ProcessBuffer pans a mono buffer into stereo buffers
+func (a *AutoPan) ProcessBuffer(input, outputL, outputR []float32) {
+	for i := range input {
+		outputL[i], outputR[i] = a.Process(input[i])
+	}
+}
+
+// ProcessStereoBuffer processes stereo buffers
+func (a *AutoPan) ProcessStereoBuffer(inputL, inputR, outputL, outputR []float32) {
+	for i := range inputL {
+		outputL[i], outputR[i] = a.ProcessStereo(inputL[i], inputR[i])
+	}
+}
+
+// Reset restarts the LFO at the centre
+func (a *AutoPan) Reset() {
+	a.lfo.Reset()
+	a.position = 0
+}
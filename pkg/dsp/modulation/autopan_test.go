@@ -0,0 +1,99 @@
+package modulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+)
+
+func TestSyncedRate(t *testing.T) {
+	if got := SyncedRate(120, 1); got != 2 {
+		t.Errorf("One beat at 120 BPM should be 2 Hz, got %f", got)
+	}
+	if got := SyncedRate(120, 4); got != 0.5 {
+		t.Errorf("One bar at 120 BPM should be 0.5 Hz, got %f", got)
+	}
+	if got := SyncedRate(0, 1); got != 0 {
+		t.Errorf("No tempo should give 0 Hz, got %f", got)
+	}
+}
+
+func TestAutoPanSweep(t *testing.T) {
+	sampleRate := 48000.0
+	a := NewAutoPan(sampleRate)
+	a.SetRate(1.0)
+
+	// A sine LFO pans right at a quarter cycle and left at three quarters
+	var l, r float32
+	for i := 0; i <= int(sampleRate/4); i++ {
+		l, r = a.Process(1.0)
+	}
+	if r < 0.99 || l > 0.01 {
+		t.Errorf("Expected hard right at a quarter cycle, got L=%f R=%f", l, r)
+	}
+	for i := 0; i < int(sampleRate/2); i++ {
+		l, r = a.Process(1.0)
+	}
+	if l < 0.99 || r > 0.01 {
+		t.Errorf("Expected hard left at three quarters, got L=%f R=%f", l, r)
+	}
+}
+
+func TestAutoPanStereoUnityAtCenter(t *testing.T) {
+	for _, law := range []pan.Law{pan.Linear, pan.ConstantPower, pan.Balanced} {
+		a := NewAutoPan(48000.0)
+		a.SetLaw(law)
+		a.SetDepth(0)
+
+		l, r := a.ProcessStereo(0.5, -0.25)
+		if math.Abs(float64(l-0.5)) > 1e-6 || math.Abs(float64(r+0.25)) > 1e-6 {
+			t.Errorf("Law %d: centred stereo should pass unchanged, got %f/%f", law, l, r)
+		}
+	}
+}
+
+func TestAutoPanSync(t *testing.T) {
+	a := NewAutoPan(48000.0)
+	a.SetSync(120, 2)
+	if !a.IsSynced() {
+		t.Fatal("Rate should be synced")
+	}
+	if a.GetRate() != 1.0 {
+		t.Errorf("Two beats at 120 BPM should be 1 Hz, got %f", a.GetRate())
+	}
+
+	// Half a beat into a two beat cycle is a quarter cycle: hard right
+	a.SetPosition(8.5)
+	l, r := a.Process(1.0)
+	if r < 0.99 || l > 0.01 {
+		t.Errorf("Expected hard right at beat 8.5, got L=%f R=%f", l, r)
+	}
+
+	// A free rate ignores the song position
+	a.SetRate(3.0)
+	a.Reset()
+	a.SetPosition(8.5)
+	if l, r := a.Process(1.0); math.Abs(float64(l-r)) > 1e-6 {
+		t.Errorf("Free running pan should start centred, got L=%f R=%f", l, r)
+	}
+}
+
+func TestAutoPanSquareSmoothing(t *testing.T) {
+	a := NewAutoPan(48000.0)
+	a.SetWaveform(WaveformSquare)
+	a.SetRate(5.0)
+
+	var last float32
+	maxStep := 0.0
+	for i := 0; i < 48000; i++ {
+		l, _ := a.Process(1.0)
+		if i > 0 {
+			maxStep = math.Max(maxStep, math.Abs(float64(l-last)))
+		}
+		last = l
+	}
+	if maxStep > 0.01 {
+		t.Errorf("Square wave panning should be smoothed, largest step %f", maxStep)
+	}
+}
@@ -51,6 +51,19 @@ func NewChorus(sampleRate float64) *Chorus {
 	return c
 }
 
+// SetSampleRate changes the sample rate, keeping the voices' LFO phases.
+// The delay lines are resized and cleared.
+func (c *Chorus) SetSampleRate(sampleRate float64) {
+	if sampleRate <= 0 || sampleRate == c.sampleRate {
+		return
+	}
+	c.sampleRate = sampleRate
+	for _, lfo := range c.lfos {
+		lfo.SetSampleRate(sampleRate)
+	}
+	c.updateDelayLines()
+}
+
 // SetRate sets the LFO rate in Hz
 func (c *Chorus) SetRate(hz float64) {
 	c.rate = math.Max(0.01, math.Min(10.0, hz))
@@ -227,7 +240,17 @@ func (c *Chorus) ProcessStereoBuffer(inputL, inputR, outputL, outputR []float32)
 
 // Reset resets the chorus state
 func (c *Chorus) Reset() {
-	// Clear delay lines
+	c.Clear()
+
+	// Reset LFOs
+	for _, lfo := range c.lfos {
+		lfo.Reset()
+	}
+}
+
+// Clear silences the delay lines and feedback but keeps the LFO phases, so
+// the modulation carries on where it was when processing resumes
+func (c *Chorus) Clear() {
 	for v := 0; v < c.voices; v++ {
 		for i := range c.delayLinesL[v] {
 			c.delayLinesL[v][i] = 0
@@ -235,11 +258,6 @@ func (c *Chorus) Reset() {
 		}
 	}
 
-	// Reset LFOs
-	for _, lfo := range c.lfos {
-		lfo.Reset()
-	}
-
 	c.delayIndex = 0
 	c.feedbackL = 0
 	c.feedbackR = 0
@@ -0,0 +1,175 @@
+package modulation
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+	"github.com/justyntemme/vst3go/pkg/dsp/delay"
+	"github.com/justyntemme/vst3go/pkg/dsp/fastmath"
+)
+
+// Ensemble voicing, after the string machine ensembles of the 1970s
+const (
+	ensembleVoices     = 3
+	ensembleBaseDelay  = 7.0  // ms
+	ensembleMaxChorus  = 3.0  // ms of delay swing at full depth
+	ensembleMaxVibrato = 0.35 // ms of delay swing at full vibrato
+	ensembleMaxTone    = 12000.0
+	ensembleMinTone    = 2000.0
+)
+
+// Ensemble is a string machine style chorus: three bucket-brigade delay
+// lines per channel, each swept by a slow chorus LFO plus a faster vibrato
+// LFO, with the three lines 120 degrees apart so the pitch shifts never
+// line up. The right channel reads its lines 60 degrees further on, which
+// widens a mono source. The delay lines are band limited and softly
+// saturated like the BBD chips they stand in for.
+type Ensemble struct {
+	sampleRate float64
+
+	// Parameters
+	rate        float64 // Chorus LFO rate in Hz
+	depth       float64 // Chorus depth (0-1)
+	vibratoRate float64 // Vibrato LFO rate in Hz
+	vibrato     float64 // Vibrato depth (0-1)
+	tone        float64 // BBD lowpass cutoff in Hz
+	mix         float64 // Wet/dry mix (0-1)
+
+	// Delay lines and BBD filters per channel
+	lines  [2]*delay.Line
+	bbd    [2]ensembleFilter
+	lfo    *LFO // Chorus phase source
+	vibLFO *LFO // Vibrato phase source
+}
+
+// ensembleFilter is the two-pole lowpass that band limits a BBD line
+type ensembleFilter struct {
+	coeff float64
+	s1    float64
+	s2    float64
+}
+
+// process runs one sample through both poles
+func (f *ensembleFilter) process(x float64) float64 {
+	f.s1 += (x - f.s1) * f.coeff
+	f.s2 += (f.s1 - f.s2) * f.coeff
+	return f.s2
+}
+
+// NewEnsemble creates an ensemble with a classic string machine setting
+func NewEnsemble(sampleRate float64) *Ensemble {
+	maxDelay := (ensembleBaseDelay + ensembleMaxChorus + ensembleMaxVibrato + 1) / 1000
+	e := &Ensemble{
+		sampleRate:  sampleRate,
+		rate:        0.6,
+		depth:       0.7,
+		vibratoRate: 6.0,
+		vibrato:     0.4,
+		mix:         0.5,
+		lfo:         NewLFO(sampleRate),
+		vibLFO:      NewLFO(sampleRate),
+	}
+	for ch := range e.lines {
+		e.lines[ch] = delay.New(maxDelay, sampleRate)
+	}
+	e.lfo.SetFrequency(e.rate)
+	e.vibLFO.SetFrequency(e.vibratoRate)
+	e.SetTone(6000)
+	return e
+}
+
+// SetRate sets the chorus LFO rate in Hz (0.1-2)
+func (e *Ensemble) SetRate(hz float64) {
+	e.rate = math.Max(0.1, math.Min(2.0, hz))
+	e.lfo.SetFrequency(e.rate)
+}
+
+// SetDepth sets the chorus depth (0-1)
+func (e *Ensemble) SetDepth(depth float64) {
+	e.depth = math.Max(0.0, math.Min(1.0, depth))
+}
+
+// SetVibratoRate sets the vibrato LFO rate in Hz (2-10)
+func (e *Ensemble) SetVibratoRate(hz float64) {
+	e.vibratoRate = math.Max(2.0, math.Min(10.0, hz))
+	e.vibLFO.SetFrequency(e.vibratoRate)
+}
+
+// SetVibrato sets the vibrato depth (0-1)
+func (e *Ensemble) SetVibrato(depth float64) {
+	e.vibrato = math.Max(0.0, math.Min(1.0, depth))
+}
+
+// SetTone sets the BBD lowpass cutoff in Hz (2000-12000); lower is darker
+// and more vintage
+func (e *Ensemble) SetTone(hz float64) {
+	e.tone = math.Max(ensembleMinTone, math.Min(ensembleMaxTone, hz))
+	c := 1 - coeff.OnePole(e.tone, e.sampleRate)
+	for ch := range e.bbd {
+		e.bbd[ch].coeff = c
+	}
+}
+
+// SetMix sets the wet/dry mix (0=dry, 1=wet)
+func (e *Ensemble) SetMix(mix float64) {
+	e.mix = math.Max(0.0, math.Min(1.0, mix))
+}
+
+// Process processes mono input into a widened stereo pair
+func (e *Ensemble) Process(input float32) (outputL, outputR float32) {
+	return e.ProcessStereo(input, input)
+}
+
+// ProcessStereo processes stereo input
+func (e *Ensemble) ProcessStereo(inputL, inputR float32) (outputL, outputR float32) {
+	// Both LFOs only provide phase; the voices read them at their offsets
+	chorusPhase := e.lfo.GetPhase()
+	vibratoPhase := e.vibLFO.GetPhase()
+	e.lfo.Process()
+	e.vibLFO.Process()
+
+	inputs := [2]float32{inputL, inputR}
+	var outputs [2]float32
+	for ch, line := range e.lines {
+		var wet float64
+		for v := 0; v < ensembleVoices; v++ {
+			// 120 degrees between voices, right channel 60 degrees on
+			offset := float64(v)/ensembleVoices + float64(ch)/(2*ensembleVoices)
+			chorus := math.Sin(2 * math.Pi * (chorusPhase + offset))
+			vib := math.Sin(2 * math.Pi * (vibratoPhase + offset))
+			delayMs := ensembleBaseDelay + ensembleMaxChorus*e.depth*chorus + ensembleMaxVibrato*e.vibrato*vib
+			wet += float64(line.Read(delayMs * e.sampleRate / 1000))
+		}
+		wet = e.bbd[ch].process(wet / ensembleVoices)
+
+		// The BBD input stage saturates gently
+		line.Write(fastmath.Medium.Tanh(inputs[ch]))
+
+		outputs[ch] = inputs[ch]*float32(1-e.mix) + float32(wet*e.mix)
+	}
+	return outputs[0], outputs[1]
+}
+
+// ProcessBuffer processes a mono buffer into stereo buffers
+func (e *Ensemble) ProcessBuffer(input, outputL, outputR []float32) {
+	for i := range input {
+		outputL[i], outputR[i] = e.Process(input[i])
+	}
+}
+
+// ProcessStereoBuffer processes stereo buffers
+func (e *Ensemble) ProcessStereoBuffer(inputL, inputR, outputL, outputR []float32) {
+	for i := range inputL {
+		outputL[i], outputR[i] = e.ProcessStereo(inputL[i], inputR[i])
+	}
+}
+
+// Reset clears the delay lines and restarts the LFOs
+func (e *Ensemble) Reset() {
+	for ch := range e.lines {
+		e.lines[ch].Reset()
+		e.bbd[ch].s1, e.bbd[ch].s2 = 0, 0
+	}
+	e.lfo.Reset()
+	e.vibLFO.Reset()
+}
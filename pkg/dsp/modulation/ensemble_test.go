@@ -0,0 +1,112 @@
+package modulation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEnsembleDrySignal(t *testing.T) {
+	e := NewEnsemble(48000.0)
+	e.SetMix(0.0)
+
+	for i := 0; i < 1000; i++ {
+		input := float32(math.Sin(2 * math.Pi * 440 * float64(i) / 48000))
+		l, r := e.Process(input)
+		if l != input || r != input {
+			t.Fatalf("Dry signal altered at sample %d: input %f, output %f/%f", i, input, l, r)
+		}
+	}
+}
+
+func TestEnsembleDelay(t *testing.T) {
+	sampleRate := 48000.0
+	e := NewEnsemble(sampleRate)
+	e.SetMix(1.0)
+	e.SetDepth(0.0)
+	e.SetVibrato(0.0)
+
+	// With no modulation all voices sit at the base delay
+	outL := make([]float32, 1000)
+	outR := make([]float32, 1000)
+	input := make([]float32, 1000)
+	input[0] = 1.0
+	e.ProcessBuffer(input, outL, outR)
+
+	peak := 0
+	for i := range outL {
+		if math.Abs(float64(outL[i])) > math.Abs(float64(outL[peak])) {
+			peak = i
+		}
+	}
+	want := int(ensembleBaseDelay * sampleRate / 1000)
+	// The BBD lowpass adds a few samples of group delay
+	if peak < want || peak > want+10 {
+		t.Errorf("Impulse peak at sample %d, want about %d", peak, want)
+	}
+}
+
+func TestEnsembleStereoWidth(t *testing.T) {
+	e := NewEnsemble(48000.0)
+	e.SetMix(1.0)
+
+	n := 48000
+	input := make([]float32, n)
+	for i := range input {
+		input[i] = 0.5 * float32(math.Sin(2*math.Pi*440*float64(i)/48000))
+	}
+	outL := make([]float32, n)
+	outR := make([]float32, n)
+	e.ProcessBuffer(input, outL, outR)
+
+	// The right channel's voices are offset, so a mono input comes out wide
+	diff := 0.0
+	for i := range outL {
+		diff = math.Max(diff, math.Abs(float64(outL[i]-outR[i])))
+	}
+	if diff < 0.01 {
+		t.Errorf("Mono input not widened: largest L/R difference %f", diff)
+	}
+}
+
+func TestEnsembleStability(t *testing.T) {
+	e := NewEnsemble(48000.0)
+	e.SetMix(1.0)
+	e.SetDepth(1.0)
+	e.SetVibrato(1.0)
+	e.SetRate(2.0)
+	e.SetVibratoRate(10.0)
+	e.SetTone(2000)
+
+	n := 48000
+	inL := make([]float32, n)
+	inR := make([]float32, n)
+	for i := range inL {
+		inL[i] = float32(math.Sin(2 * math.Pi * 220 * float64(i) / 48000))
+		inR[i] = float32(math.Sin(2 * math.Pi * 330 * float64(i) / 48000))
+	}
+	outL := make([]float32, n)
+	outR := make([]float32, n)
+	e.ProcessStereoBuffer(inL, inR, outL, outR)
+
+	for i := range outL {
+		if math.IsNaN(float64(outL[i])) || math.IsNaN(float64(outR[i])) ||
+			math.Abs(float64(outL[i])) > 1.0 || math.Abs(float64(outR[i])) > 1.0 {
+			t.Fatalf("Output out of range at sample %d: %f/%f", i, outL[i], outR[i])
+		}
+	}
+}
+
+func TestEnsembleReset(t *testing.T) {
+	e := NewEnsemble(48000.0)
+	e.SetMix(1.0)
+
+	for i := 0; i < 1000; i++ {
+		e.Process(0.8)
+	}
+	e.Reset()
+
+	l, r := e.Process(0)
+	if l != 0 || r != 0 {
+		t.Errorf("Output after reset should be silent, got %f/%f", l, r)
+	}
+}
@@ -55,6 +55,17 @@ func NewFlanger(sampleRate float64) *Flanger {
 	return f
 }
 
+// SetSampleRate changes the sample rate, keeping the LFO phase. The delay
+// line is resized and cleared.
+func (f *Flanger) SetSampleRate(sampleRate float64) {
+	if sampleRate <= 0 || sampleRate == f.sampleRate {
+		return
+	}
+	f.sampleRate = sampleRate
+	f.lfo.SetSampleRate(sampleRate)
+	f.updateDelayLine()
+}
+
 // SetRate sets the LFO rate in Hz
 func (f *Flanger) SetRate(hz float64) {
 	f.rate = math.Max(0.01, math.Min(20.0, hz))
@@ -200,15 +211,19 @@ func (f *Flanger) ProcessStereoBuffer(inputL, inputR, outputL, outputR []float32
 
 // Reset resets the flanger state
 func (f *Flanger) Reset() {
-	// Clear delay line
+	f.Clear()
+
+	// Reset LFO
+	f.lfo.Reset()
+}
+
+// Clear silences the delay line and feedback but keeps the LFO phase, so
+// the sweep carries on where it was when processing resumes
+func (f *Flanger) Clear() {
 	for i := range f.delayLine {
 		f.delayLine[i] = 0
 	}
 
-	// Reset state
 	f.delayIndex = 0
 	f.feedbackSample = 0
-
-	// Reset LFO
-	f.lfo.Reset()
 }
@@ -66,6 +66,19 @@ func (l *LFO) SetFrequency(hz float64) {
 	l.updatePhaseIncrement()
 }
 
+// SetSampleRate changes the sample rate without restarting the LFO: the
+// phase carries on and only the increment is rescaled, so re-initializing
+// a plugin mid-session does not make the modulation jump
+func (l *LFO) SetSampleRate(sampleRate float64) {
+	if sampleRate <= 0 || sampleRate == l.sampleRate {
+		return
+	}
+	// Keep the random waveform's hold at the same point in time
+	l.randomCounter = int(float64(l.randomCounter) * sampleRate / l.sampleRate)
+	l.sampleRate = sampleRate
+	l.updatePhaseIncrement()
+}
+
 // SetWaveform sets the LFO waveform
 func (l *LFO) SetWaveform(waveform Waveform) {
 	l.waveform = waveform
@@ -105,6 +118,15 @@ func (l *LFO) Sync() {
 	}
 }
 
+// SyncedRate returns the rate in Hz of an LFO that completes one cycle every
+// beats beats at the given tempo in BPM
+func SyncedRate(bpm, beats float64) float64 {
+	if bpm <= 0 || beats <= 0 {
+		return 0
+	}
+	return bpm / 60.0 / beats
+}
+
 // updatePhaseIncrement updates the phase increment based on frequency
 func (l *LFO) updatePhaseIncrement() {
 	l.phaseInc = l.frequency / l.sampleRate
@@ -183,6 +205,13 @@ func (l *LFO) ProcessBuffer(output []float64) {
 	}
 }
 
+// ProcessBuffer32 fills a float32 buffer with LFO values
+func (l *LFO) ProcessBuffer32(output []float32) {
+	for i := range output {
+		output[i] = float32(l.Process())
+	}
+}
+
 // GetPhase returns the current phase (0-1)
 func (l *LFO) GetPhase() float64 {
 	return l.phase
@@ -259,6 +259,67 @@ func TestLFOReset(t *testing.T) {
 }
 
 // Benchmark LFO
+func TestLFOProcessBuffer32(t *testing.T) {
+	a := NewLFO(48000.0)
+	b := NewLFO(48000.0)
+	a.SetFrequency(5)
+	b.SetFrequency(5)
+
+	want := make([]float64, 1000)
+	got := make([]float32, 1000)
+	a.ProcessBuffer(want)
+	b.ProcessBuffer32(got)
+	for i := range want {
+		if got[i] != float32(want[i]) {
+			t.Fatalf("Sample %d: %g, want %g", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLFOSetSampleRateKeepsPhase(t *testing.T) {
+	lfo := NewLFO(44100.0)
+	lfo.SetFrequency(2)
+	for i := 0; i < 10000; i++ {
+		lfo.Process()
+	}
+	phase := lfo.GetPhase()
+
+	// The phase carries over and advances at the same rate in Hz
+	lfo.SetSampleRate(96000.0)
+	if lfo.GetPhase() != phase {
+		t.Fatalf("Phase %g after SetSampleRate, want %g", lfo.GetPhase(), phase)
+	}
+	for i := 0; i < 9600; i++ {
+		lfo.Process()
+	}
+	want := math.Mod(phase+0.2, 1) // 0.1 s at 2 Hz
+	if math.Abs(lfo.GetPhase()-want) > 1e-9 {
+		t.Errorf("Phase %g after 0.1 s at 96 kHz, want %g", lfo.GetPhase(), want)
+	}
+}
+
+func TestChorusSetSampleRateKeepsPhase(t *testing.T) {
+	c := NewChorus(44100.0)
+	c.SetVoices(3)
+	for i := 0; i < 5000; i++ {
+		c.Process(0.5)
+	}
+	phases := make([]float64, len(c.lfos))
+	for i, lfo := range c.lfos {
+		phases[i] = lfo.GetPhase()
+	}
+
+	c.SetSampleRate(48000.0)
+	for i, lfo := range c.lfos {
+		if lfo.GetPhase() != phases[i] {
+			t.Errorf("Voice %d phase %g after SetSampleRate, want %g", i, lfo.GetPhase(), phases[i])
+		}
+	}
+	if need := int((c.delay + c.depth) * 48); c.maxDelaySamples < need {
+		t.Errorf("Delay line %d samples at 48 kHz, need %d", c.maxDelaySamples, need)
+	}
+}
+
 func BenchmarkLFO(b *testing.B) {
 	lfo := NewLFO(48000.0)
 	lfo.SetFrequency(5.0)
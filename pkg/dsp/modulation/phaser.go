@@ -2,6 +2,8 @@ package modulation
 
 import (
 	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
 )
 
 // AllPassFilter implements a first-order all-pass filter for phaser stages
@@ -19,7 +21,7 @@ func NewAllPassFilter() *AllPassFilter {
 func (f *AllPassFilter) SetFrequency(freq, sampleRate float64) {
 	// Calculate coefficient for all-pass filter
 	// Using bilinear transform: a1 = (1 - tan(pi*fc/fs)) / (1 + tan(pi*fc/fs))
-	tanFreq := math.Tan(math.Pi * freq / sampleRate)
+	tanFreq := coeff.Prewarp(freq, sampleRate)
 	f.a1 = (1.0 - tanFreq) / (1.0 + tanFreq)
 }
 
@@ -54,16 +56,20 @@ type Phaser struct {
 	centerFreq float64 // Center frequency for modulation
 	feedback   float64 // Feedback amount (-1 to 1)
 	mix        float64 // Wet/dry mix (0-1)
-	stages     int     // Number of all-pass stages (2, 4, 6, or 8)
+	stages     int     // Number of all-pass stages (2-12, even)
+	spread     float64 // Right channel LFO offset (0-1 = 0-180 degrees)
 
-	// All-pass filter stages
-	filters []*AllPassFilter
+	// All-pass filter stages, left (and mono) and right
+	filters  []*AllPassFilter
+	filtersR []*AllPassFilter
 
-	// LFO
-	lfo *LFO
+	// LFOs, the right one running spread/2 cycles ahead
+	lfo  *LFO
+	lfoR *LFO
 
 	// Feedback state
-	feedbackSample float32
+	feedbackSample  float32
+	feedbackSampleR float32
 
 	// Frequency range for modulation
 	minFreq float64
@@ -80,14 +86,19 @@ func NewPhaser(sampleRate float64) *Phaser {
 		feedback:   0.5,    // 50% feedback
 		mix:        0.5,    // 50% wet
 		stages:     4,      // 4 stages default
+		spread:     0.5,    // 90 degrees between channels
 		minFreq:    200.0,  // 200Hz minimum
 		maxFreq:    2000.0, // 2kHz maximum
 	}
 
-	// Create LFO
+	// Create LFOs
 	p.lfo = NewLFO(sampleRate)
 	p.lfo.SetWaveform(WaveformSine)
 	p.lfo.SetFrequency(p.rate)
+	p.lfoR = NewLFO(sampleRate)
+	p.lfoR.SetWaveform(WaveformSine)
+	p.lfoR.SetFrequency(p.rate)
+	p.lfoR.SetPhase(p.spread / 2)
 
 	// Initialize filters
 	p.updateStages()
@@ -99,6 +110,7 @@ func NewPhaser(sampleRate float64) *Phaser {
 func (p *Phaser) SetRate(hz float64) {
 	p.rate = math.Max(0.01, math.Min(10.0, hz))
 	p.lfo.SetFrequency(p.rate)
+	p.lfoR.SetFrequency(p.rate)
 }
 
 // SetDepth sets the modulation depth (0-1)
@@ -129,13 +141,26 @@ func (p *Phaser) SetMix(mix float64) {
 	p.mix = math.Max(0.0, math.Min(1.0, mix))
 }
 
-// SetStages sets the number of all-pass stages (2, 4, 6, or 8)
+// SetStereoSpread sets the LFO phase offset of the right channel, from 0
+// (both sweep together) to 1 (opposite, 180 degrees)
+func (p *Phaser) SetStereoSpread(spread float64) {
+	p.spread = math.Max(0.0, math.Min(1.0, spread))
+	p.lfoR.SetPhase(p.lfo.GetPhase() + p.spread/2)
+}
+
+// GetStereoSpread returns the right channel LFO offset (0-1)
+func (p *Phaser) GetStereoSpread() float64 {
+	return p.spread
+}
+
+// SetStages sets the number of all-pass stages (2-12). Each pair of stages
+// adds a notch.
 func (p *Phaser) SetStages(stages int) {
-	// Limit to even numbers between 2 and 8
+	// Limit to even numbers between 2 and 12
 	if stages < 2 {
 		stages = 2
-	} else if stages > 8 {
-		stages = 8
+	} else if stages > 12 {
+		stages = 12
 	} else if stages%2 != 0 {
 		stages = stages - 1 // Make even
 	}
@@ -147,8 +172,10 @@ func (p *Phaser) SetStages(stages int) {
 // updateStages creates the all-pass filter stages
 func (p *Phaser) updateStages() {
 	p.filters = make([]*AllPassFilter, p.stages)
+	p.filtersR = make([]*AllPassFilter, p.stages)
 	for i := 0; i < p.stages; i++ {
 		p.filters[i] = NewAllPassFilter()
+		p.filtersR[i] = NewAllPassFilter()
 	}
 	p.updateFrequencyRange()
 }
@@ -167,24 +194,45 @@ func (p *Phaser) updateFrequencyRange() {
 
 // Process processes a mono sample
 func (p *Phaser) Process(input float32) float32 {
-	// Get LFO modulation (-1 to 1)
-	lfoValue := p.lfo.Process()
+	freq := p.sweepFrequency(p.lfo.Process())
+	wetSignal := p.processCascade(p.filters, &p.feedbackSample, input, freq)
+
+	// Mix dry and wet signals
+	return input*float32(1-p.mix) + wetSignal*float32(p.mix)
+}
+
+// ProcessStereo processes a stereo sample pair, each channel through its
+// own cascade with the right LFO offset by the stereo spread
+func (p *Phaser) ProcessStereo(inputL, inputR float32) (outputL, outputR float32) {
+	freqL := p.sweepFrequency(p.lfo.Process())
+	freqR := p.sweepFrequency(p.lfoR.Process())
+	wetL := p.processCascade(p.filters, &p.feedbackSample, inputL, freqL)
+	wetR := p.processCascade(p.filtersR, &p.feedbackSampleR, inputR, freqR)
 
-	// Map LFO to frequency range
+	dry, wet := float32(1-p.mix), float32(p.mix)
+	return inputL*dry + wetL*wet, inputR*dry + wetR*wet
+}
+
+// sweepFrequency maps an LFO value (-1 to 1) onto the sweep range
+func (p *Phaser) sweepFrequency(lfoValue float64) float64 {
 	// Use exponential scaling for more musical response
 	normalizedLFO := (lfoValue + 1.0) / 2.0 // 0 to 1
 	logMin := math.Log(p.minFreq)
 	logMax := math.Log(p.maxFreq)
 	logFreq := logMin + (logMax-logMin)*normalizedLFO
-	freq := math.Exp(logFreq)
+	return math.Exp(logFreq)
+}
 
+// processCascade runs one channel's all-pass stages with feedback and
+// returns the wet signal
+func (p *Phaser) processCascade(filters []*AllPassFilter, feedbackSample *float32, input float32, freq float64) float32 {
 	// Update all-pass filter frequencies
-	for _, filter := range p.filters {
+	for _, filter := range filters {
 		filter.SetFrequency(freq, p.sampleRate)
 	}
 
 	// Process through all-pass cascade with feedback
-	wetSignal := input + p.feedbackSample*float32(p.feedback)
+	wetSignal := input + *feedbackSample*float32(p.feedback)
 
 	// Limit to prevent runaway feedback
 	if wetSignal > 1.0 {
@@ -194,34 +242,13 @@ func (p *Phaser) Process(input float32) float32 {
 	}
 
 	// Process through all-pass stages
-	for _, filter := range p.filters {
+	for _, filter := range filters {
 		wetSignal = filter.Process(wetSignal)
 	}
 
 	// Store for feedback
-	p.feedbackSample = wetSignal
-
-	// Mix dry and wet signals
-	output := input*float32(1-p.mix) + wetSignal*float32(p.mix)
-
-	return output
-}
-
-// ProcessStereo processes stereo input with phase-shifted modulation
-func (p *Phaser) ProcessStereo(inputL, inputR float32) (outputL, outputR float32) {
-	// For stereo, we could use two separate phasers with phase-shifted LFOs
-	// For now, use the same processing but with inverted wet signal on right
-	outputL = p.Process(inputL)
-
-	// Get the wet component
-	wetL := outputL - inputL*float32(1-p.mix)
-
-	// Create right channel with slightly different phasing
-	// Process right input normally but invert the wet signal
-	dryR := inputR * float32(1-p.mix)
-	outputR = dryR - wetL // Inverted wet creates stereo width
-
-	return outputL, outputR
+	*feedbackSample = wetSignal
+	return wetSignal
 }
 
 // ProcessBuffer processes a buffer of samples
@@ -244,10 +271,16 @@ func (p *Phaser) Reset() {
 	for _, filter := range p.filters {
 		filter.Reset()
 	}
+	for _, filter := range p.filtersR {
+		filter.Reset()
+	}
 
 	// Reset state
 	p.feedbackSample = 0
+	p.feedbackSampleR = 0
 
-	// Reset LFO
+	// Reset LFOs
 	p.lfo.Reset()
+	p.lfoR.Reset()
+	p.lfoR.SetPhase(p.spread / 2)
 }
@@ -91,15 +91,17 @@ func TestPhaserStages(t *testing.T) {
 		set      int
 		expected int
 	}{
-		{1, 2},  // Rounded up to 2
-		{2, 2},  // Valid
-		{3, 2},  // Rounded down to even
-		{4, 4},  // Valid
-		{5, 4},  // Rounded down to even
-		{6, 6},  // Valid
-		{7, 6},  // Rounded down to even
-		{8, 8},  // Valid
-		{10, 8}, // Clamped to max
+		{1, 2},   // Rounded up to 2
+		{2, 2},   // Valid
+		{3, 2},   // Rounded down to even
+		{4, 4},   // Valid
+		{5, 4},   // Rounded down to even
+		{6, 6},   // Valid
+		{7, 6},   // Rounded down to even
+		{8, 8},   // Valid
+		{10, 10}, // Valid
+		{12, 12}, // Valid
+		{13, 12}, // Clamped to max
 	}
 
 	for _, tc := range testStages {
@@ -264,20 +266,26 @@ func TestPhaserStereo(t *testing.T) {
 	phaser.SetDepth(0.5)
 	phaser.SetFeedback(0.5)
 
-	// Process some samples to stabilize
-	for i := 0; i < 1000; i++ {
-		phaser.ProcessStereo(0.5, 0.5)
+	// Identical tones come out different, as the channels sweep apart
+	difference := func() float64 {
+		phaser.Reset()
+		maxDiff := 0.0
+		for i := 0; i < 48000; i++ {
+			x := float32(0.5 * math.Sin(2*math.Pi*800*float64(i)/48000))
+			outputL, outputR := phaser.ProcessStereo(x, x)
+			maxDiff = math.Max(maxDiff, math.Abs(float64(outputL-outputR)))
+		}
+		return maxDiff
 	}
-
-	// Process identical inputs
-	inputL := float32(0.7)
-	inputR := float32(0.7)
-	outputL, outputR := phaser.ProcessStereo(inputL, inputR)
-
-	// Outputs should be different for stereo effect
-	if math.Abs(float64(outputL-outputR)) < 0.01 {
+	if difference() < 0.01 {
 		t.Error("No stereo difference in phaser output")
 	}
+
+	// Without spread both channels match
+	phaser.SetStereoSpread(0)
+	if d := difference(); d > 1e-6 {
+		t.Errorf("Channels differ by %f without stereo spread", d)
+	}
 }
 
 func TestPhaserReset(t *testing.T) {
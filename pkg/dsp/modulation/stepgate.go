@@ -0,0 +1,209 @@
+package modulation
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+)
+
+// MaxGateSteps is the longest step gate pattern
+const MaxGateSteps = 32
+
+// maxGateSwing is how far swing at 100% delays the off-beat steps, as a
+// fraction of a step: a third gives a triplet feel
+const maxGateSwing = 1.0 / 3
+
+// StepGate is a rhythmic gate, often called a trance gate: a pattern of
+// step levels played in time with a tempo. Each step sets the gain until
+// the next, and the gain moves between steps with separate attack and
+// release times, so the gate can be anything from a hard chop to a soft
+// pulse. The pattern position follows the song position when it is set
+// every block, and can be restarted by a note or a sidechain hit.
+type StepGate struct {
+	sampleRate float64
+
+	// Pattern
+	levels    [MaxGateSteps]float32
+	steps     int
+	stepBeats float64 // Length of a step in beats
+	swing     float64 // 0-1
+
+	// Parameters
+	bpm         float64
+	depth       float32
+	attackCoef  float32
+	releaseCoef float32
+
+	// State
+	position float64 // Beats since the pattern start
+	gain     float32
+}
+
+// NewStepGate creates a 16-step gate of 16th notes at 120 BPM, with every
+// other step closed
+func NewStepGate(sampleRate float64) *StepGate {
+	g := &StepGate{
+		sampleRate: sampleRate,
+		steps:      16,
+		stepBeats:  0.25,
+		bpm:        120,
+		depth:      1,
+		gain:       1,
+	}
+	for i := range g.levels {
+		if i%2 == 0 {
+			g.levels[i] = 1
+		}
+	}
+	g.SetSmoothing(0.002, 0.01)
+	return g
+}
+
+// SetSteps sets the pattern length in steps (1-32)
+func (g *StepGate) SetSteps(steps int) {
+	g.steps = max(1, min(MaxGateSteps, steps))
+}
+
+// GetSteps returns the pattern length in steps
+func (g *StepGate) GetSteps() int {
+	return g.steps
+}
+
+// SetStep sets the level of one step (0-1)
+func (g *StepGate) SetStep(step int, level float64) {
+	if step >= 0 && step < MaxGateSteps {
+		g.levels[step] = float32(math.Max(0, math.Min(1, level)))
+	}
+}
+
+// GetStep returns the level of one step
+func (g *StepGate) GetStep(step int) float64 {
+	if step < 0 || step >= MaxGateSteps {
+		return 0
+	}
+	return float64(g.levels[step])
+}
+
+// SetPattern sets the step levels from the start of the pattern and the
+// length to match
+func (g *StepGate) SetPattern(levels []float64) {
+	for i, level := range levels[:min(len(levels), MaxGateSteps)] {
+		g.SetStep(i, level)
+	}
+	g.SetSteps(len(levels))
+}
+
+// SetTempo sets the tempo in BPM
+func (g *StepGate) SetTempo(bpm float64) {
+	if bpm > 0 {
+		g.bpm = bpm
+	}
+}
+
+// SetStepLength sets the length of a step in beats, e.g. 0.25 for 16th
+// notes
+func (g *StepGate) SetStepLength(beats float64) {
+	if beats > 0 {
+		g.stepBeats = beats
+	}
+}
+
+// SetSwing delays every second step (0-1); full swing moves it a third of
+// a step later
+func (g *StepGate) SetSwing(swing float64) {
+	g.swing = math.Max(0, math.Min(1, swing))
+}
+
+// SetDepth sets how far closed steps pull the gain down (0-1)
+func (g *StepGate) SetDepth(depth float64) {
+	g.depth = float32(math.Max(0, math.Min(1, depth)))
+}
+
+// SetSmoothing sets the time constants, in seconds, of the gain rising into
+// a louder step and falling into a quieter one
+func (g *StepGate) SetSmoothing(attack, release float64) {
+	g.attackCoef = float32(coeff.TimeConstant(attack, g.sampleRate))
+	g.releaseCoef = float32(coeff.TimeConstant(release, g.sampleRate))
+}
+
+// SetPosition sets the pattern position from a song position in beats, so
+// the pattern stays in time with the host
+func (g *StepGate) SetPosition(beats float64) {
+	g.position = beats
+}
+
+// GetPosition returns the position in beats
+func (g *StepGate) GetPosition() float64 {
+	return g.position
+}
+
+// Restart moves back to the first step, for a note or sidechain trigger
+func (g *StepGate) Restart() {
+	g.position = 0
+}
+
+// CurrentStep returns the step playing at the current position
+func (g *StepGate) CurrentStep() int {
+	return g.stepAt(g.position)
+}
+
+// stepAt returns the step playing at a position in beats. Steps come in
+// pairs; swing moves the start of the second step of each pair later.
+func (g *StepGate) stepAt(beats float64) int {
+	pos := beats / g.stepBeats
+	pair := math.Floor(pos / 2)
+	step := int64(pair) * 2
+	if pos-2*pair >= 1+g.swing*maxGateSwing {
+		step++
+	}
+	n := int64(g.steps)
+	return int(((step % n) + n) % n)
+}
+
+// GetCurrentGain returns the gain being applied (for visualization)
+func (g *StepGate) GetCurrentGain() float64 {
+	return float64(g.gain)
+}
+
+// next advances one sample and returns the gain
+func (g *StepGate) next() float32 {
+	target := 1 - g.depth*(1-g.levels[g.stepAt(g.position)])
+	c := g.releaseCoef
+	if target > g.gain {
+		c = g.attackCoef
+	}
+	g.gain = target + (g.gain-target)*c
+	g.position += g.bpm / 60 / g.sampleRate
+	return g.gain
+}
+
+// Process gates a mono sample
+func (g *StepGate) Process(input float32) float32 {
+	return input * g.next()
+}
+
+// ProcessStereo gates a stereo pair with the same gain
+func (g *StepGate) ProcessStereo(inputL, inputR float32) (outputL, outputR float32) {
+	gain := g.next()
+	return inputL * gain, inputR * gain
+}
+
+// ProcessBuffer processes a buffer of samples
+func (g *StepGate) ProcessBuffer(input, output []float32) {
+	for i := range input {
+		output[i] = g.Process(input[i])
+	}
+}
+
+// ProcessStereoBuffer processes stereo buffers
+func (g *StepGate) ProcessStereoBuffer(inputL, inputR, outputL, outputR []float32) {
+	for i := range inputL {
+		outputL[i], outputR[i] = g.ProcessStereo(inputL[i], inputR[i])
+	}
+}
+
+// Reset returns to the first step with the gain open
+func (g *StepGate) Reset() {
+	g.position = 0
+	g.gain = 1
+}
@@ -0,0 +1,116 @@
+package modulation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStepGatePattern(t *testing.T) {
+	sampleRate := 48000.0
+	g := NewStepGate(sampleRate)
+	g.SetTempo(120)
+	g.SetSmoothing(0.0001, 0.0001)
+
+	// At 120 BPM a 16th note is 6000 samples; the default pattern
+	// alternates open and closed steps
+	stepSamples := 6000
+	for step := 0; step < 4; step++ {
+		var out float32
+		for i := 0; i < stepSamples; i++ {
+			out = g.Process(1)
+		}
+		want := float32(1)
+		if step%2 == 1 {
+			want = 0
+		}
+		if math.Abs(float64(out-want)) > 1e-3 {
+			t.Errorf("step %d: gain %f, want %f", step, out, want)
+		}
+	}
+}
+
+func TestStepGateSteps(t *testing.T) {
+	g := NewStepGate(48000)
+	g.SetPattern([]float64{1, 0.5, 0})
+	if g.GetSteps() != 3 {
+		t.Fatalf("steps = %d, want 3", g.GetSteps())
+	}
+
+	// The pattern wraps after its last step
+	for beats, want := range map[float64]int{0: 0, 0.25: 1, 0.5: 2, 0.75: 0, 1.0: 1} {
+		g.SetPosition(beats)
+		if got := g.CurrentStep(); got != want {
+			t.Errorf("position %.2f: step %d, want %d", beats, got, want)
+		}
+	}
+
+	g.SetPosition(-0.25)
+	if got := g.CurrentStep(); got != 2 {
+		t.Errorf("negative position: step %d, want 2", got)
+	}
+
+	g.SetSteps(100)
+	if g.GetSteps() != MaxGateSteps {
+		t.Errorf("steps = %d, want clamp to %d", g.GetSteps(), MaxGateSteps)
+	}
+}
+
+func TestStepGateSwing(t *testing.T) {
+	g := NewStepGate(48000)
+	g.SetSwing(1)
+
+	// Full swing starts the second step a third of a step late
+	g.SetPosition(0.25 * 1.2)
+	if g.CurrentStep() != 0 {
+		t.Errorf("swung step started early: step %d", g.CurrentStep())
+	}
+	g.SetPosition(0.25 * 1.4)
+	if g.CurrentStep() != 1 {
+		t.Errorf("swung step missing: step %d", g.CurrentStep())
+	}
+	g.SetPosition(0.25 * 2)
+	if g.CurrentStep() != 2 {
+		t.Errorf("swing moved an on-beat step: step %d", g.CurrentStep())
+	}
+}
+
+func TestStepGateDepth(t *testing.T) {
+	g := NewStepGate(48000)
+	g.SetSmoothing(0.0001, 0.0001)
+	g.SetDepth(0.5)
+	g.SetPosition(0.3) // A closed step
+
+	var out float32
+	for i := 0; i < 1000; i++ {
+		out = g.Process(1)
+	}
+	if math.Abs(float64(out)-0.5) > 1e-3 {
+		t.Errorf("half depth on a closed step: gain %f, want 0.5", out)
+	}
+}
+
+func TestStepGateSmoothing(t *testing.T) {
+	sampleRate := 48000.0
+	g := NewStepGate(sampleRate)
+	g.SetSmoothing(0.001, 0.05)
+	g.SetPosition(0.25) // Into a closed step
+
+	// After one release time constant the gain has fallen to about 1/e
+	n := int(0.05 * sampleRate)
+	var out float32
+	for i := 0; i < n; i++ {
+		out = g.Process(1)
+	}
+	if math.Abs(float64(out)-math.Exp(-1)) > 0.02 {
+		t.Errorf("gain after one release time %f, want %f", out, math.Exp(-1))
+	}
+}
+
+func TestStepGateRestart(t *testing.T) {
+	g := NewStepGate(48000)
+	g.SetPosition(3.3)
+	g.Restart()
+	if g.GetPosition() != 0 || g.CurrentStep() != 0 {
+		t.Errorf("restart left position %f, step %d", g.GetPosition(), g.CurrentStep())
+	}
+}
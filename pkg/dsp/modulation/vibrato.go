@@ -0,0 +1,151 @@
+package modulation
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/delay"
+)
+
+// maxVibratoSwing is the largest delay swing either side of the centre, in
+// seconds. It bounds the depth at slow rates.
+const maxVibratoSwing = 0.02
+
+// Vibrato is pure pitch modulation: the input is read from a delay line
+// whose length an LFO sweeps, with no dry signal. The depth is set in
+// cents and the sweep is scaled by the rate, so the pitch deviation stays
+// the same whatever the speed. The delay line adds a fixed latency of
+// GetLatencySamples.
+type Vibrato struct {
+	sampleRate float64
+
+	// Parameters
+	rate        float64  // LFO rate in Hz
+	depth       float64  // Peak pitch deviation in cents
+	waveform    Waveform // Sine or triangle
+	stereoPhase float64  // Right channel LFO offset (0-1)
+
+	swing  float64 // Delay swing either side of the centre, in samples
+	center float64 // Centre delay in samples
+
+	lines [2]*delay.Line
+	lfoL  *LFO
+	lfoR  *LFO
+}
+
+// NewVibrato creates a vibrato at 5 Hz and 20 cents
+func NewVibrato(sampleRate float64) *Vibrato {
+	v := &Vibrato{
+		sampleRate: sampleRate,
+		rate:       5.0,
+		depth:      20.0,
+		waveform:   WaveformSine,
+		center:     math.Ceil(maxVibratoSwing*sampleRate) + 1,
+		lfoL:       NewLFO(sampleRate),
+		lfoR:       NewLFO(sampleRate),
+	}
+	for ch := range v.lines {
+		v.lines[ch] = delay.New(2*maxVibratoSwing+0.001, sampleRate)
+	}
+	v.lfoL.SetFrequency(v.rate)
+	v.lfoR.SetFrequency(v.rate)
+	v.updateSwing()
+	return v
+}
+
+// SetRate sets the vibrato rate in Hz (0.1-20)
+func (v *Vibrato) SetRate(hz float64) {
+	v.rate = math.Max(0.1, math.Min(20.0, hz))
+	v.lfoL.SetFrequency(v.rate)
+	v.lfoR.SetFrequency(v.rate)
+	v.updateSwing()
+}
+
+// SetDepth sets the peak pitch deviation in cents (0-200). At slow rates
+// the depth is limited by the length of the delay line.
+func (v *Vibrato) SetDepth(cents float64) {
+	v.depth = math.Max(0.0, math.Min(200.0, cents))
+	v.updateSwing()
+}
+
+// SetWaveform sets the LFO waveform. Only sine and triangle sweep the delay
+// smoothly; other shapes select sine.
+func (v *Vibrato) SetWaveform(waveform Waveform) {
+	if waveform != WaveformTriangle {
+		waveform = WaveformSine
+	}
+	v.waveform = waveform
+	v.lfoL.SetWaveform(waveform)
+	v.lfoR.SetWaveform(waveform)
+	v.updateSwing()
+}
+
+// SetStereoPhase sets the right channel's LFO offset (0-1), 0.5 for
+// opposite pitch movement in the two channels
+func (v *Vibrato) SetStereoPhase(phase float64) {
+	v.stereoPhase = math.Max(0.0, math.Min(1.0, phase))
+	v.lfoR.SetPhase(v.lfoL.GetPhase() + v.stereoPhase)
+}
+
+// GetLatencySamples returns the centre delay, the latency the vibrato adds
+func (v *Vibrato) GetLatencySamples() int {
+	return int(v.center)
+}
+
+// updateSwing converts the depth in cents to a delay swing. Reading a
+// delay that changes at rate dD/dt shifts the pitch by a factor 1-dD/dt,
+// and the steepest slope of a sweep of amplitude A is 2πfA for a sine and
+// 4fA for a triangle.
+func (v *Vibrato) updateSwing() {
+	slope := math.Exp2(v.depth/1200) - 1
+	var swing float64
+	if v.waveform == WaveformTriangle {
+		swing = slope / (4 * v.rate)
+	} else {
+		swing = slope / (2 * math.Pi * v.rate)
+	}
+	v.swing = math.Min(swing, maxVibratoSwing) * v.sampleRate
+}
+
+// process runs one channel through its delay line
+func (v *Vibrato) process(ch int, input float32, lfo float64) float32 {
+	line := v.lines[ch]
+	output := line.Read(v.center + v.swing*lfo)
+	line.Write(input)
+	return output
+}
+
+// Process processes a mono sample
+func (v *Vibrato) Process(input float32) float32 {
+	return v.process(0, input, v.lfoL.Process())
+}
+
+// ProcessStereo processes stereo input
+func (v *Vibrato) ProcessStereo(inputL, inputR float32) (outputL, outputR float32) {
+	outputL = v.process(0, inputL, v.lfoL.Process())
+	outputR = v.process(1, inputR, v.lfoR.Process())
+	return outputL, outputR
+}
+
+// ProcessBuffer processes a buffer of samples
+func (v *Vibrato) ProcessBuffer(input, output []float32) {
+	for i := range input {
+		output[i] = v.Process(input[i])
+	}
+}
+
+// ProcessStereoBuffer processes stereo buffers
+func (v *Vibrato) ProcessStereoBuffer(inputL, inputR, outputL, outputR []float32) {
+	for i := range inputL {
+		outputL[i], outputR[i] = v.ProcessStereo(inputL[i], inputR[i])
+	}
+}
+
+// Reset clears the delay lines and restarts the LFOs
+func (v *Vibrato) Reset() {
+	for _, line := range v.lines {
+		line.Reset()
+	}
+	v.lfoL.Reset()
+	v.lfoR.Reset()
+	v.lfoR.SetPhase(v.stereoPhase)
+}
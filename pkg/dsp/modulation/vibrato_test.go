@@ -0,0 +1,107 @@
+package modulation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVibratoLatency(t *testing.T) {
+	v := NewVibrato(48000.0)
+	v.SetDepth(0)
+
+	latency := v.GetLatencySamples()
+	for i := 0; i <= latency+10; i++ {
+		input := float32(0)
+		if i == 0 {
+			input = 1
+		}
+		output := v.Process(input)
+		if i == latency && math.Abs(float64(output)-1) > 1e-6 {
+			t.Errorf("Impulse should arrive after %d samples, got %f", latency, output)
+		}
+		if i != latency && output != 0 {
+			t.Errorf("Unexpected output %f at sample %d", output, i)
+		}
+	}
+}
+
+// peakPitchDeviation runs a sine through the vibrato and returns the largest
+// deviation of its instantaneous frequency, in cents
+func peakPitchDeviation(v *Vibrato, freq, sampleRate float64) float64 {
+	n := int(2 * sampleRate)
+	var last float32
+	lastCrossing := -1.0
+	peak := 0.0
+	for i := 0; i < n; i++ {
+		x := v.Process(float32(math.Sin(2 * math.Pi * freq * float64(i) / sampleRate)))
+		if i > n/4 && last < 0 && x >= 0 {
+			crossing := float64(i-1) + float64(-last/(x-last))
+			if lastCrossing >= 0 {
+				period := crossing - lastCrossing
+				cents := 1200 * math.Log2(sampleRate/period/freq)
+				peak = math.Max(peak, math.Abs(cents))
+			}
+			lastCrossing = crossing
+		}
+		last = x
+	}
+	return peak
+}
+
+func TestVibratoDepth(t *testing.T) {
+	sampleRate := 48000.0
+
+	tests := []struct {
+		name     string
+		waveform Waveform
+		rate     float64
+		cents    float64
+	}{
+		{"Sine slow", WaveformSine, 2, 30},
+		{"Sine fast", WaveformSine, 8, 30},
+		{"Triangle", WaveformTriangle, 5, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVibrato(sampleRate)
+			v.SetWaveform(tt.waveform)
+			v.SetRate(tt.rate)
+			v.SetDepth(tt.cents)
+
+			// Each period averages the pitch over a cycle of the tone, so
+			// allow some shortfall at the peak
+			got := peakPitchDeviation(v, 1000, sampleRate)
+			if got < tt.cents*0.85 || got > tt.cents*1.1 {
+				t.Errorf("Peak deviation %.1f cents, want about %.0f", got, tt.cents)
+			}
+		})
+	}
+}
+
+func TestVibratoStereoPhase(t *testing.T) {
+	v := NewVibrato(48000.0)
+	v.SetDepth(50)
+	v.SetStereoPhase(0.5)
+
+	maxDiff := 0.0
+	for i := 0; i < 48000; i++ {
+		x := float32(math.Sin(2 * math.Pi * 440 * float64(i) / 48000))
+		l, r := v.ProcessStereo(x, x)
+		maxDiff = math.Max(maxDiff, math.Abs(float64(l-r)))
+	}
+	if maxDiff < 0.1 {
+		t.Errorf("Channels should differ with a stereo phase offset, largest difference %f", maxDiff)
+	}
+}
+
+func TestVibratoReset(t *testing.T) {
+	v := NewVibrato(48000.0)
+	for i := 0; i < 1000; i++ {
+		v.Process(0.5)
+	}
+	v.Reset()
+	if out := v.Process(0); out != 0 {
+		t.Errorf("Output after reset should be silent, got %f", out)
+	}
+}
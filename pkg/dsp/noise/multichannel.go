@@ -0,0 +1,106 @@
+package noise
+
+import "math"
+
+// Multichannel produces noise on several channels at once. Each channel has
+// its own generator, so at zero correlation the channels are uncorrelated
+// and stereo noise sounds wide instead of sitting in the middle. Raising the
+// correlation blends in a generator shared by all channels, narrowing the
+// image down to mono at 1.
+type Multichannel struct {
+	channels []Generator
+	shared   Generator
+
+	correlation float64
+	own         float32 // Gain of each channel's generator
+	common      float32 // Gain of the shared generator
+}
+
+// NewMultichannel creates uncorrelated noise of a color on a number of
+// channels. The channels' seeds are derived from seed.
+func NewMultichannel(color Color, channels int, seed uint64) *Multichannel {
+	m := &Multichannel{channels: make([]Generator, max(1, channels))}
+	m.shared.SetColor(color)
+	for ch := range m.channels {
+		m.channels[ch].SetColor(color)
+	}
+	m.Seed(seed)
+	m.SetCorrelation(0)
+	return m
+}
+
+// Channels returns the number of channels
+func (m *Multichannel) Channels() int {
+	return len(m.channels)
+}
+
+// SetColor changes the color of every channel
+func (m *Multichannel) SetColor(color Color) {
+	m.shared.SetColor(color)
+	for ch := range m.channels {
+		m.channels[ch].SetColor(color)
+	}
+}
+
+// GetColor returns the color
+func (m *Multichannel) GetColor() Color {
+	return m.shared.GetColor()
+}
+
+// Seed restarts every channel from seeds derived from seed
+func (m *Multichannel) Seed(seed uint64) {
+	seeds := NewRand(seed)
+	m.shared.Seed(seeds.Uint64())
+	for ch := range m.channels {
+		m.channels[ch].Seed(seeds.Uint64())
+	}
+}
+
+// SetCorrelation sets the correlation between the channels (0-1). The mix
+// keeps each channel at Level, so the width changes but not the loudness.
+func (m *Multichannel) SetCorrelation(correlation float64) {
+	m.correlation = max(0, min(1, correlation))
+	m.common = float32(math.Sqrt(m.correlation))
+	m.own = float32(math.Sqrt(1 - m.correlation))
+}
+
+// GetCorrelation returns the correlation between the channels
+func (m *Multichannel) GetCorrelation() float64 {
+	return m.correlation
+}
+
+// Next writes one sample per channel to frame, up to its length
+func (m *Multichannel) Next(frame []float32) {
+	common := m.shared.Next() * m.common
+	n := min(len(frame), len(m.channels))
+	for ch := 0; ch < n; ch++ {
+		frame[ch] = common + m.channels[ch].Next()*m.own
+	}
+}
+
+// Generate fills one buffer per channel with noise - no allocations
+func (m *Multichannel) Generate(buffers [][]float32) {
+	channels := min(len(buffers), len(m.channels))
+	if channels == 0 {
+		return
+	}
+	frames := len(buffers[0])
+	for _, b := range buffers[1:channels] {
+		frames = min(frames, len(b))
+	}
+
+	for i := 0; i < frames; i++ {
+		common := m.shared.Next() * m.common
+		for ch := 0; ch < channels; ch++ {
+			buffers[ch][i] = common + m.channels[ch].Next()*m.own
+		}
+	}
+}
+
+// Reset restarts every channel from its seed
+func (m *Multichannel) Reset() {
+	m.shared.Reset()
+	for ch := range m.channels {
+		m.channels[ch].Reset()
+	}
+}
@@ -0,0 +1,151 @@
+// Package noise provides seedable noise generators for synthesis, dither
+// and test signals: white, pink, brown, blue and violet noise, multichannel
+// noise with adjustable correlation between the channels, and sparse velvet
+// noise for reverb and diffusion.
+package noise
+
+import "math"
+
+// Level is the RMS level of every color, -12 dBFS. Colors are level
+// matched, so switching between them does not jump in volume. Filtered
+// colors are not bounded, but their peaks rarely pass ±1.
+const Level = 0.25
+
+// Color selects the spectrum of a Generator
+type Color int
+
+const (
+	White  Color = iota // Flat spectrum
+	Pink                // -3 dB per octave, equal energy per octave
+	Brown               // -6 dB per octave, a random walk
+	Blue                // +3 dB per octave
+	Violet              // +6 dB per octave
+)
+
+// String returns the name of the color
+func (c Color) String() string {
+	switch c {
+	case White:
+		return "White"
+	case Pink:
+		return "Pink"
+	case Brown:
+		return "Brown"
+	case Blue:
+		return "Blue"
+	case Violet:
+		return "Violet"
+	default:
+		return "Unknown"
+	}
+}
+
+// Normalizing gains bring each color to Level. Uniform white noise in
+// [-1, 1) has an RMS of 1/√3; the pink and blue gains are measured.
+const (
+	whiteGain  = Level * 1.7320508
+	violetGain = whiteGain / math.Sqrt2 // A difference of two samples doubles the power
+	pinkGain   = Level / 1.76522
+	blueGain   = Level / 1.04714
+	brownLeak  = 0.998 // Flattens the spectrum below about 15 Hz at 48 kHz
+)
+
+// brownInput keeps the leaky integrator's output at the white noise level
+var brownInput = float32(math.Sqrt(1 - brownLeak*brownLeak))
+
+// Generator produces one channel of colored noise. Generators with the
+// same seed produce the same noise.
+type Generator struct {
+	color Color
+	seed  uint64
+	rng   Rand
+
+	pink  [7]float32 // Pinking filter states
+	brown float32    // Integrator state
+	last  float32    // Previous sample, for the differentiated colors
+}
+
+// NewGenerator creates a noise generator of a color
+func NewGenerator(color Color, seed uint64) *Generator {
+	g := &Generator{color: color}
+	g.Seed(seed)
+	return g
+}
+
+// SetColor changes the color
+func (g *Generator) SetColor(color Color) {
+	g.color = color
+}
+
+// GetColor returns the color
+func (g *Generator) GetColor() Color {
+	return g.color
+}
+
+// Seed restarts the generator's sequence from a seed
+func (g *Generator) Seed(seed uint64) {
+	g.seed = seed
+	g.Reset()
+}
+
+// Reset restarts the sequence from the current seed and clears the filters
+func (g *Generator) Reset() {
+	g.rng.Seed(g.seed)
+	g.pink = [7]float32{}
+	g.brown = 0
+	g.last = 0
+}
+
+// pinkSample runs white noise through Paul Kellet's pinking filter, a set
+// of one-pole lowpasses that sums to -3 dB per octave within 0.05 dB from
+// 9 Hz up
+func (g *Generator) pinkSample(white float32) float32 {
+	b := &g.pink
+	b[0] = 0.99886*b[0] + white*0.0555179
+	b[1] = 0.99332*b[1] + white*0.0750759
+	b[2] = 0.96900*b[2] + white*0.1538520
+	b[3] = 0.86650*b[3] + white*0.3104856
+	b[4] = 0.55000*b[4] + white*0.5329522
+	b[5] = -0.7616*b[5] - white*0.0168980
+	out := b[0] + b[1] + b[2] + b[3] + b[4] + b[5] + b[6] + white*0.5362
+	b[6] = white * 0.115926
+	return out
+}
+
+// Next returns the next noise sample
+func (g *Generator) Next() float32 {
+	white := g.rng.Bipolar()
+	switch g.color {
+	case Pink:
+		return g.pinkSample(white) * pinkGain
+	case Brown:
+		g.brown = brownLeak*g.brown + brownInput*white
+		return g.brown * whiteGain
+	case Blue:
+		// Differentiated pink noise rises 3 dB per octave
+		pink := g.pinkSample(white)
+		out := pink - g.last
+		g.last = pink
+		return out * blueGain
+	case Violet:
+		out := white - g.last
+		g.last = white
+		return out * violetGain
+	default:
+		return white * whiteGain
+	}
+}
+
+// Generate fills a buffer with noise - no allocations
+func (g *Generator) Generate(buffer []float32) {
+	for i := range buffer {
+		buffer[i] = g.Next()
+	}
+}
+
+// GenerateAdd adds noise at a gain to a buffer - no allocations
+func (g *Generator) GenerateAdd(buffer []float32, gain float32) {
+	for i := range buffer {
+		buffer[i] += g.Next() * gain
+	}
+}
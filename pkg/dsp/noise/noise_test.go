@@ -0,0 +1,134 @@
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+var colors = []Color{Brown, Pink, White, Blue, Violet}
+
+// rms returns the RMS level of a buffer
+func rms(x []float32) float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += float64(v) * float64(v)
+	}
+	return math.Sqrt(sum / float64(len(x)))
+}
+
+// brightness returns the RMS of the first difference relative to the RMS,
+// which grows with the share of high frequencies
+func brightness(x []float32) float64 {
+	diff := make([]float32, len(x)-1)
+	for i := range diff {
+		diff[i] = x[i+1] - x[i]
+	}
+	return rms(diff) / rms(x)
+}
+
+func TestColorsLevelAndTilt(t *testing.T) {
+	buf := make([]float32, 1<<20)
+	prev := 0.0
+	for _, c := range colors {
+		NewGenerator(c, 7).Generate(buf)
+		if level := rms(buf); math.Abs(level-Level) > 0.02 {
+			t.Errorf("%v noise RMS = %.3f, want %.3f", c, level, Level)
+		}
+		// Each color in the list is brighter than the one before
+		b := brightness(buf)
+		if b <= prev {
+			t.Errorf("%v noise brightness %.3f, not above the previous color's %.3f", c, b, prev)
+		}
+		prev = b
+	}
+}
+
+func TestGeneratorSeed(t *testing.T) {
+	a := make([]float32, 256)
+	b := make([]float32, 256)
+	for _, c := range colors {
+		g := NewGenerator(c, 42)
+		g.Generate(a)
+		NewGenerator(c, 42).Generate(b)
+		if !equal(a, b) {
+			t.Errorf("%v noise differs between generators with the same seed", c)
+		}
+
+		// Reset replays the sequence; another seed does not
+		g.Reset()
+		g.Generate(b)
+		if !equal(a, b) {
+			t.Errorf("%v noise differs after Reset", c)
+		}
+		NewGenerator(c, 43).Generate(b)
+		if equal(a, b) {
+			t.Errorf("%v noise identical for different seeds", c)
+		}
+	}
+}
+
+func TestRandRange(t *testing.T) {
+	var r Rand
+	lo, hi := float32(1), float32(-1)
+	for i := 0; i < 100000; i++ {
+		x := r.Bipolar()
+		lo, hi = min(lo, x), max(hi, x)
+		if u := r.Float64(); u < 0 || u >= 1 {
+			t.Fatalf("Float64 = %g, out of [0, 1)", u)
+		}
+	}
+	if lo < -1 || hi >= 1 || lo > -0.999 || hi < 0.999 {
+		t.Errorf("Bipolar range [%g, %g], want [-1, 1)", lo, hi)
+	}
+}
+
+func TestMultichannelCorrelation(t *testing.T) {
+	const frames = 1 << 18
+	buffers := [][]float32{make([]float32, frames), make([]float32, frames)}
+	for _, want := range []float64{0, 0.5, 1} {
+		m := NewMultichannel(Pink, 2, 1)
+		m.SetCorrelation(want)
+		m.Generate(buffers)
+
+		if got := correlation(buffers[0], buffers[1]); math.Abs(got-want) > 0.05 {
+			t.Errorf("Correlation %.3f, want %.2f", got, want)
+		}
+		for ch, b := range buffers {
+			if level := rms(b); math.Abs(level-Level) > 0.02 {
+				t.Errorf("Correlation %.2f: channel %d RMS = %.3f, want %.3f", want, ch, level, Level)
+			}
+		}
+	}
+
+	// Next produces the same frames as Generate
+	m := NewMultichannel(White, 2, 9)
+	m.Generate(buffers)
+	m.Reset()
+	frame := make([]float32, 2)
+	for i := 0; i < 64; i++ {
+		m.Next(frame)
+		if frame[0] != buffers[0][i] || frame[1] != buffers[1][i] {
+			t.Fatalf("Frame %d = %v, Generate gave [%g %g]", i, frame, buffers[0][i], buffers[1][i])
+		}
+	}
+}
+
+// correlation returns the Pearson correlation of two signals
+func correlation(a, b []float32) float64 {
+	var ab, aa, bb float64
+	for i := range a {
+		ab += float64(a[i]) * float64(b[i])
+		aa += float64(a[i]) * float64(a[i])
+		bb += float64(b[i]) * float64(b[i])
+	}
+	return ab / math.Sqrt(aa*bb)
+}
+
+func equal(a, b []float32) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
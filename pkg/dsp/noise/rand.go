@@ -0,0 +1,38 @@
+package noise
+
+// Rand is a small, fast SplitMix64 random number generator. Unlike
+// math/rand it holds no locks and does not allocate, so it can run on the
+// audio thread, and a seed always gives the same sequence. The zero value is
+// a generator seeded with 0.
+type Rand struct {
+	state uint64
+}
+
+// NewRand creates a generator with the given seed
+func NewRand(seed uint64) *Rand {
+	return &Rand{state: seed}
+}
+
+// Seed restarts the sequence from a seed
+func (r *Rand) Seed(seed uint64) {
+	r.state = seed
+}
+
+// Uint64 returns the next 64 random bits
+func (r *Rand) Uint64() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Float64 returns a uniform value in [0, 1)
+func (r *Rand) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Bipolar returns a uniform value in [-1, 1)
+func (r *Rand) Bipolar() float32 {
+	return float32(int32(r.Uint64()>>32)) / (1 << 31)
+}
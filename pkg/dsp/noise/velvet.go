@@ -0,0 +1,119 @@
+package noise
+
+// Velvet noise limits
+const (
+	MinVelvetDensity     = 1.0    // Pulses per second
+	DefaultVelvetDensity = 2000.0 // Pulses per second; sounds as smooth as white noise
+)
+
+// Pulse is one impulse of a velvet noise sequence
+type Pulse struct {
+	Position int     // Sample offset
+	Sign     float32 // +1 or -1
+}
+
+// Velvet generates velvet noise: a sparse train of +1 and -1 impulses, one
+// at a random place in each period of a grid. Around 2000 pulses per second
+// it sounds as smooth as white noise while most samples are zero, so
+// convolving with it only costs an addition per pulse. That makes it the
+// usual source for decorrelation filters and artificial reverb tails.
+type Velvet struct {
+	sampleRate float64
+	density    float64 // Pulses per second
+	period     float64 // Samples per pulse
+	seed       uint64
+	rng        Rand
+
+	n     int64   // Samples generated
+	grid  float64 // End of the current period, fractional
+	end   int64   // End of the current period
+	pulse int64   // Position of the current period's pulse
+	sign  float32
+}
+
+// NewVelvet creates a velvet noise generator with a density in pulses per
+// second
+func NewVelvet(density, sampleRate float64, seed uint64) *Velvet {
+	v := &Velvet{sampleRate: sampleRate, seed: seed}
+	v.SetDensity(density)
+	v.Reset()
+	return v
+}
+
+// SetDensity sets the pulses per second, at most one per sample. A change
+// takes effect from the next grid period.
+func (v *Velvet) SetDensity(density float64) {
+	v.density = max(MinVelvetDensity, min(v.sampleRate, density))
+	v.period = v.sampleRate / v.density
+}
+
+// GetDensity returns the pulses per second
+func (v *Velvet) GetDensity() float64 {
+	return v.density
+}
+
+// Seed restarts the sequence from a seed
+func (v *Velvet) Seed(seed uint64) {
+	v.seed = seed
+	v.Reset()
+}
+
+// Reset restarts the sequence from the current seed
+func (v *Velvet) Reset() {
+	v.rng.Seed(v.seed)
+	v.n = 0
+	v.grid = 0
+	v.end = 0
+	v.pulse = -1
+}
+
+// advance starts the next grid period and places its pulse
+func (v *Velvet) advance() {
+	start := v.end
+	v.grid += v.period
+	v.end = max(start+1, int64(v.grid))
+	v.pulse = start + int64(v.rng.Float64()*float64(v.end-start))
+	v.sign = 1
+	if v.rng.Uint64()&1 == 0 {
+		v.sign = -1
+	}
+}
+
+// Next returns the next sample: the pulse's sign where a pulse falls, 0
+// everywhere else
+func (v *Velvet) Next() float32 {
+	if v.n == v.end {
+		v.advance()
+	}
+	n := v.n
+	v.n++
+	if n == v.pulse {
+		return v.sign
+	}
+	return 0
+}
+
+// Generate fills a buffer with velvet noise - no allocations
+func (v *Velvet) Generate(buffer []float32) {
+	for i := range buffer {
+		buffer[i] = v.Next()
+	}
+}
+
+// Pulses returns the pulses of the next length samples without generating
+// the zeros between them, for sparse convolution. It allocates the result.
+func (v *Velvet) Pulses(length int) []Pulse {
+	pulses := make([]Pulse, 0, int(float64(length)/v.period)+1)
+	start := v.n
+	stop := start + int64(length)
+	for v.n < stop {
+		if v.n == v.end {
+			v.advance()
+		}
+		if v.pulse >= v.n && v.pulse < stop {
+			pulses = append(pulses, Pulse{Position: int(v.pulse - start), Sign: v.sign})
+		}
+		v.n = min(stop, v.end)
+	}
+	return pulses
+}
@@ -0,0 +1,66 @@
+package noise
+
+import "testing"
+
+func TestVelvetOnePulsePerPeriod(t *testing.T) {
+	const sampleRate = 48000.0
+	v := NewVelvet(1000, sampleRate, 3)
+	buf := make([]float32, 48000)
+	v.Generate(buf)
+
+	// Exactly one ±1 pulse in each 48 sample period, zeros elsewhere
+	positions := make(map[int]bool)
+	for period := 0; period < len(buf)/48; period++ {
+		pulses := 0
+		for i := period * 48; i < (period+1)*48; i++ {
+			switch buf[i] {
+			case 0:
+			case 1, -1:
+				pulses++
+				positions[i-period*48] = true
+			default:
+				t.Fatalf("Sample %d = %g, want 0 or ±1", i, buf[i])
+			}
+		}
+		if pulses != 1 {
+			t.Fatalf("Period %d has %d pulses, want 1", period, pulses)
+		}
+	}
+	// The pulses wander through the period
+	if len(positions) < 40 {
+		t.Errorf("Pulses used %d of 48 positions", len(positions))
+	}
+}
+
+func TestVelvetPulses(t *testing.T) {
+	v := NewVelvet(DefaultVelvetDensity, 44100, 11)
+	buf := make([]float32, 10000)
+	v.Generate(buf)
+
+	// Pulses lists the same sequence without the zeros, across calls
+	v.Reset()
+	pulses := v.Pulses(3001)
+	for _, p := range v.Pulses(6999) {
+		p.Position += 3001
+		pulses = append(pulses, p)
+	}
+	var want []Pulse
+	for i, x := range buf {
+		if x != 0 {
+			want = append(want, Pulse{Position: i, Sign: x})
+		}
+	}
+	if len(pulses) != len(want) {
+		t.Fatalf("%d pulses, want %d", len(pulses), len(want))
+	}
+	for i, p := range pulses {
+		if p != want[i] {
+			t.Fatalf("Pulse %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+
+	// 2000 pulses per second at 44.1 kHz is one per 22.05 samples
+	if n := len(want); n < 452 || n > 454 {
+		t.Errorf("%d pulses in 10000 samples at 2000/s, want 453", n)
+	}
+}
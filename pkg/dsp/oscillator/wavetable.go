@@ -0,0 +1,285 @@
+package oscillator
+
+import (
+	"errors"
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis"
+)
+
+// ErrInvalidFrameSize is returned when wavetable frames are not a power of two
+var ErrInvalidFrameSize = errors.New("wavetable frame size must be a power of two >= 4")
+
+// ErrNoFrames is returned when a wavetable has no frames
+var ErrNoFrames = errors.New("wavetable has no frames")
+
+// Wavetable holds single-cycle frames with one band-limited copy per octave
+type Wavetable struct {
+	frameSize int
+	numFrames int
+	// mips[level][frame] holds frameSize+1 samples (last one wraps for interpolation).
+	// Level n keeps at most frameSize/2 >> n harmonics.
+	mips [][][]float32
+}
+
+// NewWavetable creates a wavetable from single-cycle frames of equal,
+// power-of-two length and builds its mip-map. This allocates and runs FFTs,
+// so call it off the audio thread.
+func NewWavetable(frames [][]float32) (*Wavetable, error) {
+	if len(frames) == 0 {
+		return nil, ErrNoFrames
+	}
+	frameSize := len(frames[0])
+	if frameSize < 4 || frameSize&(frameSize-1) != 0 {
+		return nil, ErrInvalidFrameSize
+	}
+	for _, frame := range frames {
+		if len(frame) != frameSize {
+			return nil, ErrInvalidFrameSize
+		}
+	}
+
+	// One level per octave down to a single harmonic
+	numLevels := 0
+	for h := frameSize / 2; h >= 1; h >>= 1 {
+		numLevels++
+	}
+
+	wt := &Wavetable{
+		frameSize: frameSize,
+		numFrames: len(frames),
+		mips:      make([][][]float32, numLevels),
+	}
+	for level := range wt.mips {
+		wt.mips[level] = make([][]float32, len(frames))
+	}
+
+	fft := analysis.NewFFT(frameSize, analysis.RectangularWindow)
+	input := make([]complex128, frameSize)
+	re := make([]float64, frameSize)
+	im := make([]float64, frameSize)
+
+	for f, frame := range frames {
+		for i, s := range frame {
+			input[i] = complex(float64(s), 0)
+		}
+		spectrum := fft.ForwardComplex(input)
+
+		for level := 0; level < numLevels; level++ {
+			maxHarmonic := (frameSize / 2) >> level
+
+			// Keep DC and harmonics up to maxHarmonic (and their mirror images)
+			for k := 0; k < frameSize; k++ {
+				harmonic := k
+				if k > frameSize/2 {
+					harmonic = frameSize - k
+				}
+				if harmonic <= maxHarmonic {
+					re[k], im[k] = real(spectrum[k]), imag(spectrum[k])
+				} else {
+					re[k], im[k] = 0, 0
+				}
+			}
+
+			// Remove DC so frames don't introduce offsets
+			re[0], im[0] = 0, 0
+
+			samples := fft.Inverse(re, im)
+			table := make([]float32, frameSize+1)
+			for i := 0; i < frameSize; i++ {
+				table[i] = float32(samples[i])
+			}
+			table[frameSize] = table[0]
+			wt.mips[level][f] = table
+		}
+	}
+
+	return wt, nil
+}
+
+// FrameSize returns the number of samples per frame
+func (wt *Wavetable) FrameSize() int {
+	return wt.frameSize
+}
+
+// NumFrames returns the number of frames
+func (wt *Wavetable) NumFrames() int {
+	return wt.numFrames
+}
+
+// NumLevels returns the number of band-limited mip levels
+func (wt *Wavetable) NumLevels() int {
+	return len(wt.mips)
+}
+
+// levelFor returns the mip level that keeps harmonics below Nyquist for a
+// phase increment. Negative increments play the table backwards and need the
+// same band limit.
+func (wt *Wavetable) levelFor(phaseInc float64) int {
+	phaseInc = math.Abs(phaseInc)
+	if phaseInc == 0 {
+		return 0
+	}
+	// Highest harmonic that stays below Nyquist
+	maxHarmonic := 0.5 / phaseInc
+	level := 0
+	for level < len(wt.mips)-1 && float64((wt.frameSize/2)>>level) > maxHarmonic {
+		level++
+	}
+	return level
+}
+
+// WavetableOscillator plays a wavetable with frame morphing and band limiting
+type WavetableOscillator struct {
+	table      *Wavetable
+	sampleRate float64
+	frequency  float64
+	phase      float64
+	phaseInc   float64
+	position   float64 // 0-1 across frames
+	level      int
+}
+
+// NewWavetableOscillator creates a new wavetable oscillator
+func NewWavetableOscillator(sampleRate float64) *WavetableOscillator {
+	o := &WavetableOscillator{
+		sampleRate: sampleRate,
+	}
+	o.SetFrequency(440.0)
+	return o
+}
+
+// SetTable sets the wavetable to play
+func (o *WavetableOscillator) SetTable(table *Wavetable) {
+	o.table = table
+	o.updateLevel()
+}
+
+// SetFrequency sets the oscillator frequency
+func (o *WavetableOscillator) SetFrequency(freq float64) {
+	o.frequency = freq
+	o.phaseInc = freq / o.sampleRate
+	o.updateLevel()
+}
+
+// SetPosition sets the wavetable position (0-1), morphing between frames
+func (o *WavetableOscillator) SetPosition(position float64) {
+	o.position = clampUnit(position)
+}
+
+// GetPosition returns the wavetable position (0-1)
+func (o *WavetableOscillator) GetPosition() float64 {
+	return o.position
+}
+
+// SetPhase sets the oscillator phase (0-1)
+func (o *WavetableOscillator) SetPhase(phase float64) {
+	o.phase = wrapPhase(phase)
+}
+
+// Reset resets the oscillator phase to 0
+func (o *WavetableOscillator) Reset() {
+	o.phase = 0
+}
+
+// updateLevel selects the mip level for the current frequency
+func (o *WavetableOscillator) updateLevel() {
+	if o.table != nil {
+		o.level = o.table.levelFor(o.phaseInc)
+	}
+}
+
+// clampUnit limits a value to 0-1
+func clampUnit(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x > 1 {
+		return 1
+	}
+	return x
+}
+
+// sampleAt reads the table at the current phase and a given position
+func (o *WavetableOscillator) sampleAt(position float64) float32 {
+	frames := o.table.mips[o.level]
+
+	// Frame interpolation
+	framePos := position * float64(len(frames)-1)
+	f0 := int(framePos)
+	f1 := f0 + 1
+	if f1 >= len(frames) {
+		f1 = len(frames) - 1
+	}
+	frameFrac := float32(framePos - float64(f0))
+
+	// Phase interpolation
+	idx := o.phase * float64(o.table.frameSize)
+	i := int(idx)
+	frac := float32(idx - float64(i))
+
+	a := frames[f0][i] + (frames[f0][i+1]-frames[f0][i])*frac
+	if f1 == f0 || frameFrac == 0 {
+		return a
+	}
+	b := frames[f1][i] + (frames[f1][i+1]-frames[f1][i])*frac
+	return a + (b-a)*frameFrac
+}
+
+// advance moves the phase forward by one sample. Negative frequencies, e.g.
+// from FM or pitch modulation, move it backwards.
+func (o *WavetableOscillator) advance() {
+	o.phase += o.phaseInc
+	if o.phase >= 1.0 || o.phase < 0 {
+		o.phase = wrapPhase(o.phase)
+	}
+}
+
+// wrapPhase wraps a phase into [0, 1)
+func wrapPhase(phase float64) float64 {
+	phase = math.Mod(phase, 1)
+	if phase < 0 {
+		phase++
+	}
+	if phase >= 1 || math.IsNaN(phase) {
+		// A tiny negative phase rounds up to 1
+		return 0
+	}
+	return phase
+}
+
+// Next generates the next sample
+func (o *WavetableOscillator) Next() float32 {
+	if o.table == nil {
+		return 0
+	}
+	sample := o.sampleAt(o.position)
+	o.advance()
+	return sample
+}
+
+// Process fills buffer with samples
+func (o *WavetableOscillator) Process(buffer []float32) {
+	for i := range buffer {
+		buffer[i] = o.Next()
+	}
+}
+
+// ProcessModulated fills buffer with samples while adding positionMod (e.g. an
+// LFO output, in position units) to the base position on each sample
+func (o *WavetableOscillator) ProcessModulated(buffer []float32, positionMod []float32) {
+	if o.table == nil {
+		for i := range buffer {
+			buffer[i] = 0
+		}
+		return
+	}
+	for i := range buffer {
+		position := o.position
+		if i < len(positionMod) {
+			position = clampUnit(position + float64(positionMod[i]))
+		}
+		buffer[i] = o.sampleAt(position)
+		o.advance()
+	}
+}
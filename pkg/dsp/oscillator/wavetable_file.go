@@ -0,0 +1,202 @@
+package oscillator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/audiofile"
+)
+
+// DefaultWavetableFrameSize is the frame size assumed for WAV wavetables without a clm chunk
+const DefaultWavetableFrameSize = 2048
+
+// maxWAVSize limits WAV wavetables read from streams that cannot seek (256 MB)
+const maxWAVSize = 1 << 28
+
+// Limits on .wt headers, which are read before the data they describe
+const (
+	maxWTFrameSize = 1 << 14
+	maxWTFrames    = 1024
+	maxWTSamples   = 1 << 21 // 8 MB of float32 before mip-mapping
+)
+
+// ErrUnsupportedFormat is returned for wavetable files that cannot be decoded
+var ErrUnsupportedFormat = errors.New("unsupported wavetable format")
+
+// LoadWavetableFile loads a wavetable from a .wav or .wt file
+func LoadWavetableFile(path string) (*Wavetable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wt":
+		return LoadWavetableWT(bytes.NewReader(data))
+	case ".wav", ".wave":
+		return LoadWavetableWAV(bytes.NewReader(data), 0)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, filepath.Ext(path))
+	}
+}
+
+// LoadWavetableWAV loads a wavetable from a WAV file whose first channel holds
+// consecutive single-cycle frames. If frameSize is 0 it is taken from a
+// Serum-style "clm " chunk, falling back to DefaultWavetableFrameSize.
+func LoadWavetableWAV(r io.Reader, frameSize int) (*Wavetable, error) {
+	samples, clmSize, err := readWAVMono(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if frameSize <= 0 {
+		frameSize = clmSize
+	}
+	if frameSize <= 0 {
+		frameSize = DefaultWavetableFrameSize
+	}
+	if len(samples) < frameSize {
+		return nil, ErrNoFrames
+	}
+
+	numFrames := len(samples) / frameSize
+	frames := make([][]float32, numFrames)
+	for f := range frames {
+		frames[f] = samples[f*frameSize : (f+1)*frameSize]
+	}
+	return NewWavetable(frames)
+}
+
+// LoadWavetableWT loads a wavetable in the .wt format used by Surge:
+// "vawt", uint32 frame size, uint16 frame count, uint16 flags, then samples
+// as float32 or, when flag 0x4 is set, int16.
+func LoadWavetableWT(r io.Reader) (*Wavetable, error) {
+	var header struct {
+		Magic     [4]byte
+		FrameSize uint32
+		NumFrames uint16
+		Flags     uint16
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read wt header: %w", err)
+	}
+	if string(header.Magic[:]) != "vawt" {
+		return nil, fmt.Errorf("%w: missing vawt header", ErrUnsupportedFormat)
+	}
+	if header.NumFrames == 0 {
+		return nil, ErrNoFrames
+	}
+	frameSize := int(header.FrameSize)
+	if frameSize < 4 || frameSize > maxWTFrameSize || frameSize&(frameSize-1) != 0 {
+		return nil, ErrInvalidFrameSize
+	}
+	if header.NumFrames > maxWTFrames || frameSize*int(header.NumFrames) > maxWTSamples {
+		return nil, fmt.Errorf("%w: %d frames of %d samples is too large", ErrUnsupportedFormat, header.NumFrames, frameSize)
+	}
+
+	// Refuse truncated files before allocating for them
+	const flagInt16 = 0x4
+	sampleBytes := int64(4)
+	if header.Flags&flagInt16 != 0 {
+		sampleBytes = 2
+	}
+	need := int64(frameSize) * int64(header.NumFrames) * sampleBytes
+	if remaining, ok := remainingBytes(r); ok && remaining < need {
+		return nil, fmt.Errorf("%w: %d bytes of samples, header needs %d", ErrUnsupportedFormat, remaining, need)
+	}
+
+	frames := make([][]float32, header.NumFrames)
+
+	for f := range frames {
+		frames[f] = make([]float32, frameSize)
+		if header.Flags&flagInt16 != 0 {
+			raw := make([]int16, frameSize)
+			if err := binary.Read(r, binary.LittleEndian, raw); err != nil {
+				return nil, fmt.Errorf("failed to read wt frame %d: %w", f, err)
+			}
+			for i, v := range raw {
+				frames[f][i] = float32(v) / 32768.0
+			}
+		} else if err := binary.Read(r, binary.LittleEndian, frames[f]); err != nil {
+			return nil, fmt.Errorf("failed to read wt frame %d: %w", f, err)
+		}
+	}
+
+	return NewWavetable(frames)
+}
+
+// remainingBytes returns how many bytes are left in r when r can tell
+func remainingBytes(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case io.Seeker:
+		current, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err := v.Seek(current, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - current, true
+	}
+	return 0, false
+}
+
+// readWAVMono decodes the first channel of a WAV file and returns the frame
+// size from a "clm " chunk if present
+func readWAVMono(r io.Reader) ([]float32, int, error) {
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(io.LimitReader(r, maxWAVSize+1))
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(data) > maxWAVSize {
+			return nil, 0, fmt.Errorf("%w: WAV file too large", ErrUnsupportedFormat)
+		}
+		rs = bytes.NewReader(data)
+	}
+
+	wav, err := audiofile.NewReader(rs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+	if info := wav.Info(); info.Format != audiofile.FormatWAV {
+		return nil, 0, fmt.Errorf("%w: not a WAVE file", ErrUnsupportedFormat)
+	}
+	audio, err := audiofile.ReadAll(wav)
+	if err != nil {
+		return nil, 0, err
+	}
+	return audio.Data[0], clmFrameSize(wav.Chunk("clm ")), nil
+}
+
+// clmFrameSize parses the frame size from a Serum "clm " chunk, which reads
+// e.g. "<!>2048 01000000 wavetable (www.xferrecords.com)", or returns 0
+func clmFrameSize(body []byte) int {
+	text := string(body)
+	if !strings.HasPrefix(text, "<!>") {
+		return 0
+	}
+	fields := strings.Fields(text[3:])
+	if len(fields) == 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0
+	}
+	return n
+}
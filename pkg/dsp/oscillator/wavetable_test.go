@@ -0,0 +1,199 @@
+package oscillator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// testFrames returns a sine frame followed by a naive saw frame
+func testFrames(frameSize int) [][]float32 {
+	sine := make([]float32, frameSize)
+	saw := make([]float32, frameSize)
+	for i := range sine {
+		phase := float64(i) / float64(frameSize)
+		sine[i] = float32(math.Sin(2 * math.Pi * phase))
+		saw[i] = float32(2*phase - 1)
+	}
+	return [][]float32{sine, saw}
+}
+
+func TestWavetableMipLevels(t *testing.T) {
+	wt, err := NewWavetable(testFrames(256))
+	if err != nil {
+		t.Fatalf("NewWavetable failed: %v", err)
+	}
+	if wt.NumFrames() != 2 || wt.FrameSize() != 256 {
+		t.Errorf("Unexpected table layout: %d frames of %d", wt.NumFrames(), wt.FrameSize())
+	}
+	if wt.NumLevels() != 8 {
+		t.Errorf("Expected 8 mip levels, got %d", wt.NumLevels())
+	}
+
+	// The top level keeps only the fundamental, so the saw becomes a sine
+	top := wt.mips[wt.NumLevels()-1][1]
+	for i := 0; i < 256; i += 16 {
+		expected := -2 / math.Pi * math.Sin(2*math.Pi*float64(i)/256)
+		if math.Abs(float64(top[i])-expected) > 0.01 {
+			t.Errorf("Sample %d: expected %f, got %f", i, expected, top[i])
+		}
+	}
+
+	if _, err := NewWavetable([][]float32{make([]float32, 100)}); err != ErrInvalidFrameSize {
+		t.Errorf("Expected ErrInvalidFrameSize, got %v", err)
+	}
+}
+
+func TestWavetableOscillatorBandLimiting(t *testing.T) {
+	wt, _ := NewWavetable(testFrames(2048))
+	osc := NewWavetableOscillator(48000)
+	osc.SetTable(wt)
+
+	// Low notes use the full table, high notes drop harmonics above Nyquist
+	osc.SetFrequency(10)
+	if osc.level != 0 {
+		t.Errorf("Expected level 0 at 10 Hz, got %d", osc.level)
+	}
+	osc.SetFrequency(5000)
+	maxHarmonic := (wt.FrameSize() / 2) >> osc.level
+	if float64(maxHarmonic)*5000 > 24000 {
+		t.Errorf("Level %d keeps %d harmonics, aliasing at 5 kHz", osc.level, maxHarmonic)
+	}
+}
+
+func TestWavetableOscillatorMorph(t *testing.T) {
+	wt, _ := NewWavetable(testFrames(256))
+	osc := NewWavetableOscillator(48000)
+	osc.SetTable(wt)
+	osc.SetFrequency(48000.0 / 256) // One table sample per output sample
+
+	// Position 0 plays the sine frame
+	buffer := make([]float32, 256)
+	osc.Process(buffer)
+	if math.Abs(float64(buffer[64])-1) > 0.01 {
+		t.Errorf("Expected sine peak at quarter cycle, got %f", buffer[64])
+	}
+
+	// Modulating to position 1 plays the saw frame
+	mod := make([]float32, 256)
+	for i := range mod {
+		mod[i] = 1
+	}
+	osc.Reset()
+	osc.ProcessModulated(buffer, mod)
+	sawFrame := wt.mips[0][1]
+	if math.Abs(float64(buffer[64]-sawFrame[64])) > 1e-5 {
+		t.Errorf("Expected saw frame sample %f, got %f", sawFrame[64], buffer[64])
+	}
+}
+
+func TestLoadWavetableWAV(t *testing.T) {
+	frames := testFrames(256)
+	clm := []byte("<!>256 01000000 wavetable")
+
+	var data bytes.Buffer
+	for _, frame := range frames {
+		for _, s := range frame {
+			binary.Write(&data, binary.LittleEndian, int16(s*32767))
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+16+8+len(clm)+1+8+data.Len()))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1))     // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(1))     // Mono
+	binary.Write(&buf, binary.LittleEndian, uint32(44100)) // Sample rate
+	binary.Write(&buf, binary.LittleEndian, uint32(88200)) // Byte rate
+	binary.Write(&buf, binary.LittleEndian, uint16(2))     // Block align
+	binary.Write(&buf, binary.LittleEndian, uint16(16))    // Bits
+	buf.WriteString("clm ")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(clm)))
+	buf.Write(clm)
+	buf.WriteByte(0) // Pad to even size
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(data.Len()))
+	buf.Write(data.Bytes())
+
+	wt, err := LoadWavetableWAV(&buf, 0)
+	if err != nil {
+		t.Fatalf("LoadWavetableWAV failed: %v", err)
+	}
+	if wt.FrameSize() != 256 || wt.NumFrames() != 2 {
+		t.Errorf("Expected 2 frames of 256 from clm chunk, got %d of %d", wt.NumFrames(), wt.FrameSize())
+	}
+}
+
+func TestLoadWavetableWT(t *testing.T) {
+	frames := testFrames(128)
+
+	var buf bytes.Buffer
+	buf.WriteString("vawt")
+	binary.Write(&buf, binary.LittleEndian, uint32(128))
+	binary.Write(&buf, binary.LittleEndian, uint16(len(frames)))
+	binary.Write(&buf, binary.LittleEndian, uint16(0)) // float32 samples
+	for _, frame := range frames {
+		binary.Write(&buf, binary.LittleEndian, frame)
+	}
+
+	wt, err := LoadWavetableWT(&buf)
+	if err != nil {
+		t.Fatalf("LoadWavetableWT failed: %v", err)
+	}
+	if wt.FrameSize() != 128 || wt.NumFrames() != 2 {
+		t.Errorf("Expected 2 frames of 128, got %d of %d", wt.NumFrames(), wt.FrameSize())
+	}
+}
+
+func TestLoadWavetableWTRejectsBadHeaders(t *testing.T) {
+	header := func(frameSize uint32, numFrames uint16) *bytes.Buffer {
+		var buf bytes.Buffer
+		buf.WriteString("vawt")
+		binary.Write(&buf, binary.LittleEndian, frameSize)
+		binary.Write(&buf, binary.LittleEndian, numFrames)
+		binary.Write(&buf, binary.LittleEndian, uint16(0))
+		return &buf
+	}
+
+	// Huge tables are refused from the header alone
+	if _, err := LoadWavetableWT(header(1<<16, 65535)); err == nil {
+		t.Error("Expected error for oversized frame size")
+	}
+	if _, err := LoadWavetableWT(header(2048, 65535)); err == nil {
+		t.Error("Expected error for too many frames")
+	}
+
+	// A header promising more samples than the file holds
+	truncated := header(256, 4)
+	binary.Write(truncated, binary.LittleEndian, make([]float32, 256))
+	if _, err := LoadWavetableWT(truncated); err == nil {
+		t.Error("Expected error for truncated sample data")
+	}
+}
+
+func TestWavetableOscillatorNegativeFrequency(t *testing.T) {
+	wt, _ := NewWavetable(testFrames(256))
+	osc := NewWavetableOscillator(48000)
+	osc.SetTable(wt)
+	osc.SetFrequency(-3000)
+
+	// Runs backwards through the table without leaving it
+	buf := make([]float32, 4096)
+	osc.Process(buf)
+	if osc.phase < 0 || osc.phase >= 1 {
+		t.Errorf("Phase %g out of range", osc.phase)
+	}
+	if osc.level != wt.levelFor(3000.0/48000) {
+		t.Errorf("Negative frequency used mip level %d", osc.level)
+	}
+
+	osc.SetPhase(-1e-20)
+	if osc.phase < 0 || osc.phase >= 1 {
+		t.Errorf("Tiny negative phase wrapped to %g", osc.phase)
+	}
+	osc.Next()
+}
@@ -0,0 +1,158 @@
+package phase
+
+import (
+	"math"
+	"testing"
+)
+
+// sine returns n samples of a sine at freq
+func sine(freq, sampleRate float64, n int) []float32 {
+	buf := make([]float32, n)
+	for i := range buf {
+		buf[i] = float32(math.Sin(2 * math.Pi * freq * float64(i) / sampleRate))
+	}
+	return buf
+}
+
+// phaseOf measures the phase in degrees of buf at freq, skipping the first
+// skip samples
+func phaseOf(buf []float32, freq, sampleRate float64, skip int) (phase, amplitude float64) {
+	var re, im float64
+	for i := skip; i < len(buf); i++ {
+		w := 2 * math.Pi * freq * float64(i) / sampleRate
+		re += float64(buf[i]) * math.Sin(w)
+		im += float64(buf[i]) * math.Cos(w)
+	}
+	n := float64(len(buf) - skip)
+	return math.Atan2(im, re) * 180 / math.Pi, 2 * math.Hypot(re, im) / n
+}
+
+// angleDiff returns a-b wrapped to (-180, 180]
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(a-b, 360)
+	if d > 180 {
+		d -= 360
+	} else if d <= -180 {
+		d += 360
+	}
+	return d
+}
+
+func TestPolarity(t *testing.T) {
+	buf := []float32{1, -0.5, 0.25}
+	Invert(buf)
+	if buf[0] != -1 || buf[1] != 0.5 || buf[2] != -0.25 {
+		t.Errorf("Invert gave %v", buf)
+	}
+
+	p := NewPolarity(48000)
+	ones := make([]float32, 1000)
+	for i := range ones {
+		ones[i] = 1
+	}
+	p.SetInverted(true)
+	p.ProcessBuffer(ones)
+	for i := 1; i < len(ones); i++ {
+		if ones[i] > ones[i-1] || ones[i-1]-ones[i] > 0.01 {
+			t.Fatalf("polarity switch is not a smooth ramp at %d: %g -> %g", i, ones[i-1], ones[i])
+		}
+	}
+	if ones[len(ones)-1] != -1 || !p.Inverted() {
+		t.Errorf("settled at %g, want -1", ones[len(ones)-1])
+	}
+
+	p.SetInverted(false)
+	p.Reset()
+	if p.Process(0.5) != 0.5 {
+		t.Error("Reset should jump to the settled gain")
+	}
+}
+
+func TestRotator(t *testing.T) {
+	const sr = 48000.0
+	r := NewRotator(2, sr)
+	r.SetFrequency(1000)
+
+	if got := r.PhaseAt(1000); math.Abs(got+180) > 1e-6 {
+		t.Errorf("two stages should shift -180 degrees at the corner, got %g", got)
+	}
+	if got := r.PhaseAt(10); got > -1 || got < -3 {
+		t.Errorf("phase far below the corner should be small, got %g", got)
+	}
+
+	for _, freq := range []float64{200, 1000, 5000} {
+		r.Reset()
+		in := sine(freq, sr, 9600)
+		out := append([]float32(nil), in...)
+		r.ProcessBuffer(out)
+
+		inPhase, _ := phaseOf(in, freq, sr, 4800)
+		outPhase, amp := phaseOf(out, freq, sr, 4800)
+		if math.Abs(amp-1) > 0.01 {
+			t.Errorf("%g Hz: amplitude %g, want 1", freq, amp)
+		}
+		if d := angleDiff(outPhase-inPhase, r.PhaseAt(freq)); math.Abs(d) > 0.5 {
+			t.Errorf("%g Hz: measured %g degrees, PhaseAt %g", freq, outPhase-inPhase, r.PhaseAt(freq))
+		}
+	}
+
+	r.SetStages(100)
+	if r.Stages() != MaxRotatorStages {
+		t.Errorf("stages %d, want the maximum %d", r.Stages(), MaxRotatorStages)
+	}
+}
+
+func TestHilbertQuadrature(t *testing.T) {
+	const sr = 44100.0
+	for _, freq := range []float64{50, 440, 5000, 15000} {
+		h := NewHilbert()
+		in := sine(freq, sr, 44100)
+		i := make([]float32, len(in))
+		q := make([]float32, len(in))
+		for n, x := range in {
+			i[n], q[n] = h.Process(x)
+		}
+
+		iPhase, iAmp := phaseOf(i, freq, sr, 22050)
+		qPhase, qAmp := phaseOf(q, freq, sr, 22050)
+		if math.Abs(iAmp-1) > 0.01 || math.Abs(qAmp-1) > 0.01 {
+			t.Errorf("%g Hz: amplitudes %g and %g, want 1", freq, iAmp, qAmp)
+		}
+		if d := angleDiff(iPhase, qPhase); math.Abs(d-90) > 1 {
+			t.Errorf("%g Hz: Q lags I by %g degrees, want 90", freq, d)
+		}
+	}
+}
+
+func TestVariablePhase(t *testing.T) {
+	const sr = 48000.0
+	const freq = 1000.0
+	in := sine(freq, sr, 24000)
+
+	ref := NewVariablePhase(sr)
+	refOut := append([]float32(nil), in...)
+	ref.ProcessBuffer(refOut)
+	refPhase, _ := phaseOf(refOut, freq, sr, 12000)
+
+	for _, degrees := range []float64{45, 90, 180, 270, -90} {
+		v := NewVariablePhase(sr)
+		v.SetPhase(degrees)
+		v.Reset()
+		out := append([]float32(nil), in...)
+		v.ProcessBuffer(out)
+
+		outPhase, amp := phaseOf(out, freq, sr, 12000)
+		if math.Abs(amp-1) > 0.01 {
+			t.Errorf("%g degrees: amplitude %g", degrees, amp)
+		}
+		if d := angleDiff(refPhase-outPhase, degrees); math.Abs(d) > 1 {
+			t.Errorf("%g degrees: output lags by %g", degrees, refPhase-outPhase)
+		}
+	}
+
+	v := NewVariablePhase(sr)
+	v.SetPhase(-90)
+	if v.Phase() != 270 {
+		t.Errorf("phase %g, want 270", v.Phase())
+	}
+}
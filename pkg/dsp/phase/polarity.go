@@ -0,0 +1,80 @@
+// Package phase provides polarity and phase tools for mixing: polarity
+// inversion, all-pass phase rotation and a variable 0-360 degree phase shift.
+package phase
+
+import "math"
+
+// Polarity switch ramp time in seconds, long enough to avoid a click
+const polarityRampSeconds = 0.005
+
+// Invert flips the polarity of a buffer in place
+func Invert(buffer []float32) {
+	for i := range buffer {
+		buffer[i] = -buffer[i]
+	}
+}
+
+// Polarity is a click-free polarity switch. Toggling ramps the gain through
+// zero instead of jumping from +1 to -1.
+type Polarity struct {
+	inverted bool
+	gain     float32 // Current gain, +1 or -1 when settled
+	step     float32 // Gain change per sample while ramping
+}
+
+// NewPolarity creates a polarity switch, not inverted
+func NewPolarity(sampleRate float64) *Polarity {
+	return &Polarity{
+		gain: 1,
+		step: float32(2 / math.Max(1, polarityRampSeconds*sampleRate)),
+	}
+}
+
+// SetInverted sets whether the polarity is flipped
+func (p *Polarity) SetInverted(inverted bool) {
+	p.inverted = inverted
+}
+
+// Inverted returns whether the polarity is flipped
+func (p *Polarity) Inverted() bool {
+	return p.inverted
+}
+
+// target returns the settled gain
+func (p *Polarity) target() float32 {
+	if p.inverted {
+		return -1
+	}
+	return 1
+}
+
+// Process applies the polarity to one sample
+func (p *Polarity) Process(input float32) float32 {
+	if target := p.target(); p.gain != target {
+		if p.gain < target {
+			p.gain = min(target, p.gain+p.step)
+		} else {
+			p.gain = max(target, p.gain-p.step)
+		}
+	}
+	return input * p.gain
+}
+
+// ProcessBuffer applies the polarity to a buffer in place
+func (p *Polarity) ProcessBuffer(buffer []float32) {
+	target := p.target()
+	if p.gain == target {
+		if p.inverted {
+			Invert(buffer)
+		}
+		return
+	}
+	for i, x := range buffer {
+		buffer[i] = p.Process(x)
+	}
+}
+
+// Reset jumps to the settled gain
+func (p *Polarity) Reset() {
+	p.gain = p.target()
+}
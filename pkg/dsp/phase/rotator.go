@@ -0,0 +1,115 @@
+package phase
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/coeff"
+)
+
+// Rotator limits
+const (
+	MaxRotatorStages        = 16
+	DefaultRotatorStages    = 4
+	DefaultRotatorFreq      = 150.0 // Hz, typical for taming asymmetric vocals
+	rotatorSmoothingSeconds = 0.01  // Coefficient smoothing time
+)
+
+// Rotator rotates phase with a cascade of first-order all-pass filters
+// sharing one corner frequency. Each stage shifts the phase by 90 degrees at
+// the corner, falling to 0 below and 180 above, without changing the level.
+// Moving the corner sweeps the rotation; changes are smoothed so the
+// frequency can be automated.
+type Rotator struct {
+	sampleRate float64
+	freq       float64
+	stages     int
+
+	coef   float64 // Current all-pass coefficient
+	target float64 // Coefficient for freq
+	smooth float64 // One-pole smoothing factor
+
+	x1 [MaxRotatorStages]float64 // Stage inputs, one sample back
+	y1 [MaxRotatorStages]float64 // Stage outputs, one sample back
+}
+
+// NewRotator creates a rotator with the given number of stages at
+// DefaultRotatorFreq
+func NewRotator(stages int, sampleRate float64) *Rotator {
+	r := &Rotator{
+		sampleRate: sampleRate,
+		smooth:     1 - math.Exp(-1/(rotatorSmoothingSeconds*sampleRate)),
+	}
+	r.SetStages(stages)
+	r.SetFrequency(DefaultRotatorFreq)
+	r.coef = r.target
+	return r
+}
+
+// coefficient returns the all-pass coefficient for a corner frequency
+func coefficient(freq, sampleRate float64) float64 {
+	t := coeff.Prewarp(freq, sampleRate)
+	return (t - 1) / (t + 1)
+}
+
+// SetFrequency sets the corner frequency in Hz
+func (r *Rotator) SetFrequency(hz float64) {
+	r.freq = math.Max(10, math.Min(r.sampleRate*0.49, hz))
+	r.target = coefficient(r.freq, r.sampleRate)
+}
+
+// Frequency returns the corner frequency in Hz
+func (r *Rotator) Frequency() float64 {
+	return r.freq
+}
+
+// SetStages sets the number of all-pass stages (1-16)
+func (r *Rotator) SetStages(stages int) {
+	stages = max(1, min(MaxRotatorStages, stages))
+	for i := r.stages; i < stages; i++ {
+		r.x1[i], r.y1[i] = 0, 0
+	}
+	r.stages = stages
+}
+
+// Stages returns the number of all-pass stages
+func (r *Rotator) Stages() int {
+	return r.stages
+}
+
+// Process rotates one sample
+func (r *Rotator) Process(input float32) float32 {
+	r.coef += (r.target - r.coef) * r.smooth
+	a := r.coef
+	x := float64(input)
+	for i := 0; i < r.stages; i++ {
+		// H(z) = (a + z^-1) / (1 + a z^-1)
+		y := a*x + r.x1[i] - a*r.y1[i]
+		r.x1[i], r.y1[i] = x, y
+		x = y
+	}
+	return float32(x)
+}
+
+// ProcessBuffer rotates a buffer in place
+func (r *Rotator) ProcessBuffer(buffer []float32) {
+	for i, x := range buffer {
+		buffer[i] = r.Process(x)
+	}
+}
+
+// PhaseAt returns the phase shift in degrees at a frequency, negative as the
+// rotation lags the input
+func (r *Rotator) PhaseAt(hz float64) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*hz/r.sampleRate))
+	a := complex(r.target, 0)
+	h := (a + z) / (1 + a*z)
+	return float64(r.stages) * cmplx.Phase(h) * 180 / math.Pi
+}
+
+// Reset clears the filter state and jumps to the target frequency
+func (r *Rotator) Reset() {
+	r.x1 = [MaxRotatorStages]float64{}
+	r.y1 = [MaxRotatorStages]float64{}
+	r.coef = r.target
+}
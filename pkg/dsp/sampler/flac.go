@@ -0,0 +1,511 @@
+package sampler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errFLACEnd signals that the bit reader ran out of data
+var errFLACEnd = errors.New("flac: unexpected end of data")
+
+// bitReader reads big-endian bit fields from a byte slice
+type bitReader struct {
+	data []byte
+	pos  int // bit position
+}
+
+// read returns the next n bits (n <= 64) as an unsigned value
+func (br *bitReader) read(n int) (uint64, error) {
+	if br.pos+n > len(br.data)*8 {
+		return 0, errFLACEnd
+	}
+	var v uint64
+	for n > 0 {
+		byteIdx := br.pos >> 3
+		bitOff := br.pos & 7
+		avail := 8 - bitOff
+		take := avail
+		if take > n {
+			take = n
+		}
+		bits := (uint64(br.data[byteIdx]) >> uint(avail-take)) & ((1 << uint(take)) - 1)
+		v = v<<uint(take) | bits
+		br.pos += take
+		n -= take
+	}
+	return v, nil
+}
+
+// readSigned returns the next n bits as a two's complement value
+func (br *bitReader) readSigned(n int) (int64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	v, err := br.read(n)
+	if err != nil {
+		return 0, err
+	}
+	shift := uint(64 - n)
+	return int64(v<<shift) >> shift, nil
+}
+
+// readUnary counts zero bits up to the next one bit
+func (br *bitReader) readUnary() (int, error) {
+	count := 0
+	for {
+		if br.pos >= len(br.data)*8 {
+			return 0, errFLACEnd
+		}
+		bit := (br.data[br.pos>>3] >> uint(7-br.pos&7)) & 1
+		br.pos++
+		if bit == 1 {
+			return count, nil
+		}
+		count++
+	}
+}
+
+// alignByte skips to the next byte boundary
+func (br *bitReader) alignByte() {
+	br.pos = (br.pos + 7) &^ 7
+}
+
+// flacStreamInfo holds the fields of the STREAMINFO block used for decoding
+type flacStreamInfo struct {
+	sampleRate    int
+	channels      int
+	bitsPerSample int
+	totalSamples  int64
+}
+
+// decodeFLAC decodes a complete FLAC stream into memory
+func decodeFLAC(data []byte) (*Sample, error) {
+	br := &bitReader{data: data, pos: 32} // Skip "fLaC"
+
+	var info flacStreamInfo
+	haveInfo := false
+
+	// Metadata blocks
+	for {
+		last, err := br.read(1)
+		if err != nil {
+			return nil, fmt.Errorf("%w: truncated metadata", ErrUnsupportedFormat)
+		}
+		blockType, _ := br.read(7)
+		length, err := br.read(24)
+		if err != nil {
+			return nil, fmt.Errorf("%w: truncated metadata", ErrUnsupportedFormat)
+		}
+		start := br.pos
+
+		if blockType == 0 {
+			br.read(16) // Min block size
+			br.read(16) // Max block size
+			br.read(24) // Min frame size
+			br.read(24) // Max frame size
+			sr, _ := br.read(20)
+			ch, _ := br.read(3)
+			bps, _ := br.read(5)
+			total, err := br.read(36)
+			if err != nil {
+				return nil, fmt.Errorf("%w: truncated STREAMINFO", ErrUnsupportedFormat)
+			}
+			info = flacStreamInfo{
+				sampleRate:    int(sr),
+				channels:      int(ch) + 1,
+				bitsPerSample: int(bps) + 1,
+				totalSamples:  int64(total),
+			}
+			haveInfo = true
+		}
+
+		br.pos = start + int(length)*8
+		if br.pos > len(data)*8 {
+			return nil, fmt.Errorf("%w: truncated metadata", ErrUnsupportedFormat)
+		}
+		if last == 1 {
+			break
+		}
+	}
+
+	if !haveInfo {
+		return nil, fmt.Errorf("%w: missing STREAMINFO", ErrUnsupportedFormat)
+	}
+
+	// Pre-size output when the total length is known (guard against bogus headers)
+	capacity := 0
+	if info.totalSamples > 0 && info.totalSamples < int64(len(data))*8 {
+		capacity = int(info.totalSamples)
+	}
+	out := make([][]float32, info.channels)
+	for ch := range out {
+		out[ch] = make([]float32, 0, capacity)
+	}
+
+	scale := 1.0 / float64(int64(1)<<uint(info.bitsPerSample-1))
+	var block [][]int64
+
+	// Audio frames
+	for br.pos+16 <= len(data)*8 {
+		if info.totalSamples > 0 && int64(len(out[0])) >= info.totalSamples {
+			break
+		}
+
+		var err error
+		block, err = decodeFLACFrame(br, &info, block)
+		if err != nil {
+			if errors.Is(err, errFLACEnd) && len(out[0]) > 0 {
+				break // Truncated final frame
+			}
+			return nil, err
+		}
+
+		for ch := range out {
+			for _, s := range block[ch] {
+				out[ch] = append(out[ch], float32(float64(s)*scale))
+			}
+		}
+	}
+
+	if info.totalSamples > 0 && int64(len(out[0])) > info.totalSamples {
+		for ch := range out {
+			out[ch] = out[ch][:info.totalSamples]
+		}
+	}
+
+	return NewSample(out, float64(info.sampleRate)), nil
+}
+
+// decodeFLACFrame decodes one frame, reusing the per-channel buffers in block
+func decodeFLACFrame(br *bitReader, info *flacStreamInfo, block [][]int64) ([][]int64, error) {
+	sync, err := br.read(14)
+	if err != nil {
+		return nil, err
+	}
+	if sync != 0x3FFE {
+		return nil, fmt.Errorf("%w: lost FLAC frame sync", ErrUnsupportedFormat)
+	}
+	br.read(1) // Reserved
+	br.read(1) // Blocking strategy
+
+	blockSizeCode, _ := br.read(4)
+	sampleRateCode, _ := br.read(4)
+	channelAssignment, _ := br.read(4)
+	sampleSizeCode, _ := br.read(3)
+	if _, err := br.read(1); err != nil {
+		return nil, err
+	}
+
+	// UTF-8 style coded frame or sample number
+	first, err := br.read(8)
+	if err != nil {
+		return nil, err
+	}
+	for mask := uint64(0x80); first&mask != 0 && mask > 1; mask >>= 1 {
+		if mask != 0x80 {
+			br.read(8)
+		}
+	}
+
+	var blockSize int
+	switch {
+	case blockSizeCode == 1:
+		blockSize = 192
+	case blockSizeCode >= 2 && blockSizeCode <= 5:
+		blockSize = 576 << (blockSizeCode - 2)
+	case blockSizeCode == 6:
+		v, err := br.read(8)
+		if err != nil {
+			return nil, err
+		}
+		blockSize = int(v) + 1
+	case blockSizeCode == 7:
+		v, err := br.read(16)
+		if err != nil {
+			return nil, err
+		}
+		blockSize = int(v) + 1
+	case blockSizeCode >= 8:
+		blockSize = 256 << (blockSizeCode - 8)
+	default:
+		return nil, fmt.Errorf("%w: reserved FLAC block size", ErrUnsupportedFormat)
+	}
+
+	switch sampleRateCode {
+	case 12:
+		br.read(8)
+	case 13, 14:
+		br.read(16)
+	}
+
+	bps := info.bitsPerSample
+	switch sampleSizeCode {
+	case 1:
+		bps = 8
+	case 2:
+		bps = 12
+	case 4:
+		bps = 16
+	case 5:
+		bps = 20
+	case 6:
+		bps = 24
+	case 7:
+		bps = 32
+	}
+
+	br.read(8) // Header CRC-8
+
+	channels := int(channelAssignment) + 1
+	if channelAssignment >= 8 {
+		if channelAssignment > 10 {
+			return nil, fmt.Errorf("%w: reserved FLAC channel assignment", ErrUnsupportedFormat)
+		}
+		channels = 2
+	}
+	if channels != info.channels {
+		return nil, fmt.Errorf("%w: FLAC channel count changed", ErrUnsupportedFormat)
+	}
+
+	if len(block) != channels {
+		block = make([][]int64, channels)
+	}
+	for ch := range block {
+		if cap(block[ch]) < blockSize {
+			block[ch] = make([]int64, blockSize)
+		}
+		block[ch] = block[ch][:blockSize]
+
+		// The side channel carries one extra bit
+		chBps := bps
+		if (channelAssignment == 8 && ch == 1) || (channelAssignment == 9 && ch == 0) ||
+			(channelAssignment == 10 && ch == 1) {
+			chBps++
+		}
+		if err := decodeFLACSubframe(br, block[ch], chBps); err != nil {
+			return nil, err
+		}
+	}
+
+	// Undo inter-channel decorrelation
+	switch channelAssignment {
+	case 8: // Left/side
+		for i := range block[0] {
+			block[1][i] = block[0][i] - block[1][i]
+		}
+	case 9: // Side/right
+		for i := range block[0] {
+			block[0][i] += block[1][i]
+		}
+	case 10: // Mid/side
+		for i := range block[0] {
+			mid := block[0][i]<<1 | block[1][i]&1
+			side := block[1][i]
+			block[0][i] = (mid + side) >> 1
+			block[1][i] = (mid - side) >> 1
+		}
+	}
+
+	br.alignByte()
+	if _, err := br.read(16); err != nil { // Frame CRC-16
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// fixedCoefficients are the FLAC fixed predictors for orders 0-4
+var fixedCoefficients = [5][]int64{
+	{},
+	{1},
+	{2, -1},
+	{3, -3, 1},
+	{4, -6, 4, -1},
+}
+
+// decodeFLACSubframe decodes one channel of a frame into out
+func decodeFLACSubframe(br *bitReader, out []int64, bps int) error {
+	if _, err := br.read(1); err != nil { // Zero padding
+		return err
+	}
+	subType, _ := br.read(6)
+	hasWasted, err := br.read(1)
+	if err != nil {
+		return err
+	}
+
+	wasted := 0
+	if hasWasted == 1 {
+		k, err := br.readUnary()
+		if err != nil {
+			return err
+		}
+		wasted = k + 1
+		bps -= wasted
+	}
+
+	switch {
+	case subType == 0: // Constant
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		for i := range out {
+			out[i] = v
+		}
+
+	case subType == 1: // Verbatim
+		for i := range out {
+			v, err := br.readSigned(bps)
+			if err != nil {
+				return err
+			}
+			out[i] = v
+		}
+
+	case subType >= 8 && subType <= 12: // Fixed
+		order := int(subType - 8)
+		if err := readWarmup(br, out, order, bps); err != nil {
+			return err
+		}
+		if err := decodeResidual(br, out, order); err != nil {
+			return err
+		}
+		predict(out, fixedCoefficients[order], order, 0)
+
+	case subType >= 32: // LPC
+		order := int(subType-32) + 1
+		if err := readWarmup(br, out, order, bps); err != nil {
+			return err
+		}
+		precision, _ := br.read(4)
+		if precision == 15 {
+			return fmt.Errorf("%w: invalid FLAC LPC precision", ErrUnsupportedFormat)
+		}
+		shift, err := br.readSigned(5)
+		if err != nil {
+			return err
+		}
+		if shift < 0 {
+			return fmt.Errorf("%w: negative FLAC LPC shift", ErrUnsupportedFormat)
+		}
+		coeffs := make([]int64, order)
+		for i := range coeffs {
+			c, err := br.readSigned(int(precision) + 1)
+			if err != nil {
+				return err
+			}
+			coeffs[i] = c
+		}
+		if err := decodeResidual(br, out, order); err != nil {
+			return err
+		}
+		predict(out, coeffs, order, uint(shift))
+
+	default:
+		return fmt.Errorf("%w: reserved FLAC subframe type %d", ErrUnsupportedFormat, subType)
+	}
+
+	if wasted > 0 {
+		for i := range out {
+			out[i] <<= uint(wasted)
+		}
+	}
+	return nil
+}
+
+// readWarmup reads the unencoded samples that seed a predictor
+func readWarmup(br *bitReader, out []int64, order, bps int) error {
+	if order > len(out) {
+		return fmt.Errorf("%w: FLAC predictor order exceeds block size", ErrUnsupportedFormat)
+	}
+	for i := 0; i < order; i++ {
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		out[i] = v
+	}
+	return nil
+}
+
+// decodeResidual reads Rice-coded residuals into out[order:]
+func decodeResidual(br *bitReader, out []int64, order int) error {
+	method, err := br.read(2)
+	if err != nil {
+		return err
+	}
+	if method > 1 {
+		return fmt.Errorf("%w: reserved FLAC residual coding", ErrUnsupportedFormat)
+	}
+	paramBits := 4
+	if method == 1 {
+		paramBits = 5
+	}
+	escape := uint64(1)<<uint(paramBits) - 1
+
+	partitionOrder, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	partitionSize := len(out) >> partitionOrder
+	if partitionSize<<partitionOrder != len(out) || partitionSize < order {
+		return fmt.Errorf("%w: invalid FLAC partition order", ErrUnsupportedFormat)
+	}
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		n := partitionSize
+		if p == 0 {
+			n -= order
+		}
+
+		param, err := br.read(paramBits)
+		if err != nil {
+			return err
+		}
+
+		if param == escape {
+			rawBits, err := br.read(5)
+			if err != nil {
+				return err
+			}
+			for j := 0; j < n; j++ {
+				v, err := br.readSigned(int(rawBits))
+				if err != nil {
+					return err
+				}
+				out[i] = v
+				i++
+			}
+			continue
+		}
+
+		for j := 0; j < n; j++ {
+			q, err := br.readUnary()
+			if err != nil {
+				return err
+			}
+			low, err := br.read(int(param))
+			if err != nil {
+				return err
+			}
+			u := uint64(q)<<param | low
+			out[i] = int64(u>>1) ^ -int64(u&1) // Zigzag decode
+			i++
+		}
+	}
+	return nil
+}
+
+// predict restores samples from residuals in place using a linear predictor
+func predict(out []int64, coeffs []int64, order int, shift uint) {
+	for i := order; i < len(out); i++ {
+		var sum int64
+		for j, c := range coeffs {
+			sum += c * out[i-j-1]
+		}
+		out[i] += sum >> shift
+	}
+}
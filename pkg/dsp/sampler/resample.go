@@ -0,0 +1,70 @@
+package sampler
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+const (
+	// sincZeroCrossings is the number of sinc zero crossings on each side of the kernel
+	sincZeroCrossings = 8
+	// sincResolution is the number of table entries per zero crossing
+	sincResolution = 512
+	// sincKaiserBeta sets the window's stopband attenuation (about 80 dB)
+	sincKaiserBeta = 8.0
+	// minSincCutoff limits kernel stretching when pitching up (two octaves)
+	minSincCutoff = 0.25
+)
+
+// sincTable holds one side of a Kaiser-windowed sinc kernel
+var sincTable = buildSincTable()
+
+// buildSincTable precomputes the windowed sinc for x in [0, sincZeroCrossings]
+func buildSincTable() []float32 {
+	n := sincZeroCrossings*sincResolution + 2
+	table := make([]float32, n)
+	norm := filter.BesselI0(sincKaiserBeta)
+
+	for i := range table {
+		x := float64(i) / sincResolution
+		if x > sincZeroCrossings {
+			break
+		}
+		sinc := 1.0
+		if x != 0 {
+			sinc = math.Sin(math.Pi*x) / (math.Pi * x)
+		}
+		r := x / sincZeroCrossings
+		window := filter.BesselI0(sincKaiserBeta*math.Sqrt(1-r*r)) / norm
+		table[i] = float32(sinc * window)
+	}
+	return table
+}
+
+// sincKernel evaluates the windowed sinc at |x| by table interpolation
+func sincKernel(x float64) float32 {
+	if x < 0 {
+		x = -x
+	}
+	if x >= sincZeroCrossings {
+		return 0
+	}
+	pos := x * sincResolution
+	i := int(pos)
+	frac := float32(pos - float64(i))
+	return sincTable[i] + (sincTable[i+1]-sincTable[i])*frac
+}
+
+// sincCutoff returns the normalized cutoff for a playback increment, lowering
+// it when pitching up so the resampled signal does not alias
+func sincCutoff(increment float64) float64 {
+	if increment <= 1 {
+		return 1
+	}
+	c := 1 / increment
+	if c < minSincCutoff {
+		c = minSincCutoff
+	}
+	return c
+}
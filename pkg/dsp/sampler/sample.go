@@ -0,0 +1,117 @@
+// Package sampler provides sample playback for drum samplers and rompler-style instruments
+package sampler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/justyntemme/vst3go/pkg/audiofile"
+)
+
+// ErrUnsupportedFormat is returned for audio files that cannot be decoded.
+// It is the audiofile package's error, which decodes WAV and AIFF files.
+var ErrUnsupportedFormat = audiofile.ErrUnsupportedFormat
+
+// Sample holds decoded audio in memory
+type Sample struct {
+	Data       [][]float32 // One slice per channel
+	SampleRate float64
+
+	// Metadata from the file, if present
+	RootKey   int // MIDI unity note, -1 if unknown
+	LoopStart int // Loop start in samples, -1 if none
+	LoopEnd   int // Loop end in samples (exclusive), -1 if none
+}
+
+// NewSample creates a sample from per-channel data
+func NewSample(data [][]float32, sampleRate float64) *Sample {
+	return &Sample{
+		Data:       data,
+		SampleRate: sampleRate,
+		RootKey:    -1,
+		LoopStart:  -1,
+		LoopEnd:    -1,
+	}
+}
+
+// Len returns the length in samples
+func (s *Sample) Len() int {
+	if len(s.Data) == 0 {
+		return 0
+	}
+	return len(s.Data[0])
+}
+
+// NumChannels returns the number of channels
+func (s *Sample) NumChannels() int {
+	return len(s.Data)
+}
+
+// HasLoop returns true if the file defined a loop
+func (s *Sample) HasLoop() bool {
+	return s.LoopStart >= 0 && s.LoopEnd > s.LoopStart
+}
+
+// LoadFile decodes a WAV, AIFF or FLAC file into memory
+func LoadFile(path string) (*Sample, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBytes(data)
+}
+
+// Decode reads a WAV, AIFF or FLAC stream into memory, detecting the format
+func Decode(r io.Reader) (*Sample, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBytes(data)
+}
+
+// decodeBytes dispatches on the file signature
+func decodeBytes(data []byte) (*Sample, error) {
+	switch {
+	case len(data) >= 12 && bytes.Equal(data[0:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE")),
+		len(data) >= 12 && bytes.Equal(data[0:4], []byte("FORM")) &&
+			(bytes.Equal(data[8:12], []byte("AIFF")) || bytes.Equal(data[8:12], []byte("AIFC"))):
+		return decodeAudioFile(data)
+	case len(data) >= 4 && bytes.Equal(data[0:4], []byte("fLaC")):
+		return decodeFLAC(data)
+	default:
+		return nil, fmt.Errorf("%w: unknown file signature", ErrUnsupportedFormat)
+	}
+}
+
+// decodeAudioFile decodes a WAV or AIFF file, including the loop and root
+// key of a WAV smpl chunk
+func decodeAudioFile(data []byte) (*Sample, error) {
+	r, err := audiofile.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	audio, err := audiofile.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := NewSample(audio.Data, audio.SampleRate)
+	inst := r.Instrument()
+	sample.RootKey = inst.RootKey
+	if inst.LoopStart >= 0 {
+		sample.LoopStart, sample.LoopEnd = int(inst.LoopStart), int(inst.LoopEnd)
+	}
+	return sample, nil
+}
+
+// allocChannels allocates per-channel sample storage
+func allocChannels(channels, length int) [][]float32 {
+	data := make([][]float32, channels)
+	for ch := range data {
+		data[ch] = make([]float32, length)
+	}
+	return data
+}
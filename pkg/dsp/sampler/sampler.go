@@ -0,0 +1,116 @@
+package sampler
+
+import (
+	"github.com/justyntemme/vst3go/pkg/framework/voice"
+	"github.com/justyntemme/vst3go/pkg/midi"
+)
+
+// Sampler is a polyphonic sample player built from sampler voices and the
+// framework voice allocator
+type Sampler struct {
+	keyMap    *KeyMap
+	voices    []*Voice
+	allocator *voice.Allocator
+
+	// Pre-allocated render buffers
+	bufL []float32
+	bufR []float32
+}
+
+// NewSampler creates a sampler with numVoices voices playing zones from keyMap
+func NewSampler(keyMap *KeyMap, sampleRate float64, numVoices, maxBlockSize int) *Sampler {
+	s := &Sampler{
+		keyMap: keyMap,
+		voices: make([]*Voice, numVoices),
+		bufL:   make([]float32, maxBlockSize),
+		bufR:   make([]float32, maxBlockSize),
+	}
+
+	allocVoices := make([]voice.Voice, numVoices)
+	for i := range s.voices {
+		s.voices[i] = NewVoice(keyMap, sampleRate)
+		allocVoices[i] = s.voices[i]
+	}
+	s.allocator = voice.NewAllocator(allocVoices)
+
+	return s
+}
+
+// KeyMap returns the sampler's key map
+func (s *Sampler) KeyMap() *KeyMap {
+	return s.keyMap
+}
+
+// Allocator returns the voice allocator for mode and stealing configuration
+func (s *Sampler) Allocator() *voice.Allocator {
+	return s.allocator
+}
+
+// SetADSR sets the amplitude envelope of all voices
+func (s *Sampler) SetADSR(attack, decay, sustain, release float64) {
+	for _, v := range s.voices {
+		v.Envelope().SetADSR(attack, decay, sustain, release)
+	}
+}
+
+// SetPitchBend sets the pitch offset of all voices in semitones
+func (s *Sampler) SetPitchBend(semitones float64) {
+	for _, v := range s.voices {
+		v.SetPitchBend(semitones)
+	}
+}
+
+// NoteOn starts a note
+func (s *Sampler) NoteOn(note, velocity uint8) {
+	s.allocator.NoteOn(note, velocity)
+}
+
+// NoteOff releases a note
+func (s *Sampler) NoteOff(note uint8) {
+	s.allocator.NoteOff(note, 0)
+}
+
+// ProcessEvent handles a MIDI event
+func (s *Sampler) ProcessEvent(event midi.Event) {
+	s.allocator.ProcessEvent(event)
+}
+
+// Process renders all active voices into left and right, overwriting them
+func (s *Sampler) Process(left, right []float32) {
+	clear(left)
+	clear(right)
+
+	n := min(len(left), len(right), len(s.bufL))
+	bufL, bufR := s.bufL[:n], s.bufR[:n]
+
+	for _, v := range s.voices {
+		if !v.IsActive() {
+			continue
+		}
+		v.ProcessStereo(bufL, bufR)
+		for i := 0; i < n; i++ {
+			left[i] += bufL[i]
+			right[i] += bufR[i]
+		}
+	}
+}
+
+// GetActiveVoiceCount returns the number of sounding voices
+func (s *Sampler) GetActiveVoiceCount() int {
+	count := 0
+	for _, v := range s.voices {
+		if v.IsActive() {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset stops all voices and restarts round robins
+func (s *Sampler) Reset() {
+	s.allocator.Reset()
+	s.keyMap.ResetRoundRobin()
+	for _, v := range s.voices {
+		v.Stop()
+	}
+}
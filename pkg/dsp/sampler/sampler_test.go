@@ -0,0 +1,493 @@
+package sampler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"slices"
+	"testing"
+)
+
+// sineData returns n samples of a sine with the given period in samples
+func sineData(n int, period float64) []float32 {
+	data := make([]float32, n)
+	for i := range data {
+		data[i] = float32(0.5 * math.Sin(2*math.Pi*float64(i)/period))
+	}
+	return data
+}
+
+// buildWAV writes 16-bit PCM WAV data with an optional smpl loop
+func buildWAV(channels [][]float32, sampleRate int, loopStart, loopEnd int) []byte {
+	var pcm bytes.Buffer
+	for i := range channels[0] {
+		for _, ch := range channels {
+			binary.Write(&pcm, binary.LittleEndian, int16(ch[i]*32767))
+		}
+	}
+
+	var chunks bytes.Buffer
+	chunks.WriteString("fmt ")
+	binary.Write(&chunks, binary.LittleEndian, uint32(16))
+	binary.Write(&chunks, binary.LittleEndian, uint16(1))
+	binary.Write(&chunks, binary.LittleEndian, uint16(len(channels)))
+	binary.Write(&chunks, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&chunks, binary.LittleEndian, uint32(sampleRate*2*len(channels)))
+	binary.Write(&chunks, binary.LittleEndian, uint16(2*len(channels)))
+	binary.Write(&chunks, binary.LittleEndian, uint16(16))
+
+	if loopEnd > loopStart {
+		smpl := make([]uint32, 9+6)
+		smpl[3] = 60 // MIDI unity note
+		smpl[7] = 1  // One loop
+		smpl[9+2] = uint32(loopStart)
+		smpl[9+3] = uint32(loopEnd - 1)
+		chunks.WriteString("smpl")
+		binary.Write(&chunks, binary.LittleEndian, uint32(len(smpl)*4))
+		binary.Write(&chunks, binary.LittleEndian, smpl)
+	}
+
+	chunks.WriteString("data")
+	binary.Write(&chunks, binary.LittleEndian, uint32(pcm.Len()))
+	chunks.Write(pcm.Bytes())
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+chunks.Len()))
+	buf.WriteString("WAVE")
+	buf.Write(chunks.Bytes())
+	return buf.Bytes()
+}
+
+func TestDecodeWAV(t *testing.T) {
+	left := sineData(1000, 100)
+	right := sineData(1000, 50)
+	sample, err := Decode(bytes.NewReader(buildWAV([][]float32{left, right}, 44100, 200, 600)))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if sample.NumChannels() != 2 || sample.Len() != 1000 || sample.SampleRate != 44100 {
+		t.Fatalf("Unexpected sample layout: %d channels, %d samples, %f Hz",
+			sample.NumChannels(), sample.Len(), sample.SampleRate)
+	}
+	if math.Abs(float64(sample.Data[1][10]-right[10])) > 1e-3 {
+		t.Errorf("Right channel mismatch: %f vs %f", sample.Data[1][10], right[10])
+	}
+	if sample.RootKey != 60 || sample.LoopStart != 200 || sample.LoopEnd != 600 {
+		t.Errorf("Unexpected smpl metadata: root %d, loop %d-%d", sample.RootKey, sample.LoopStart, sample.LoopEnd)
+	}
+}
+
+func TestDecodeAIFF(t *testing.T) {
+	data := sineData(500, 100)
+
+	var ssnd bytes.Buffer
+	binary.Write(&ssnd, binary.BigEndian, uint32(0)) // Offset
+	binary.Write(&ssnd, binary.BigEndian, uint32(0)) // Block size
+	for _, s := range data {
+		binary.Write(&ssnd, binary.BigEndian, int16(s*32767))
+	}
+
+	var comm bytes.Buffer
+	binary.Write(&comm, binary.BigEndian, uint16(1))
+	binary.Write(&comm, binary.BigEndian, uint32(len(data)))
+	binary.Write(&comm, binary.BigEndian, uint16(16))
+	// 48000 as an 80-bit extended float
+	comm.Write([]byte{0x40, 0x0E, 0xBB, 0x80, 0, 0, 0, 0, 0, 0})
+
+	var buf bytes.Buffer
+	buf.WriteString("FORM")
+	binary.Write(&buf, binary.BigEndian, uint32(4+8+comm.Len()+8+ssnd.Len()))
+	buf.WriteString("AIFF")
+	buf.WriteString("COMM")
+	binary.Write(&buf, binary.BigEndian, uint32(comm.Len()))
+	buf.Write(comm.Bytes())
+	buf.WriteString("SSND")
+	binary.Write(&buf, binary.BigEndian, uint32(ssnd.Len()))
+	buf.Write(ssnd.Bytes())
+
+	sample, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if sample.SampleRate != 48000 || sample.Len() != len(data) {
+		t.Fatalf("Unexpected sample: %f Hz, %d samples", sample.SampleRate, sample.Len())
+	}
+	if math.Abs(float64(sample.Data[0][25]-data[25])) > 1e-3 {
+		t.Errorf("Sample mismatch: %f vs %f", sample.Data[0][25], data[25])
+	}
+}
+
+func TestZoneMapping(t *testing.T) {
+	soft := NewSample([][]float32{{0.1}}, 44100)
+	loud := NewSample([][]float32{{0.9}}, 44100)
+
+	softZone := NewZone(soft, 36, 48, 36)
+	softZone.SetVelocityRange(1, 63)
+	loudZone := NewZone(loud, 36, 48, 36)
+	loudZone.SetVelocityRange(64, 127)
+
+	km := NewKeyMap()
+	km.Add(softZone, loudZone)
+
+	if km.Find(40, 30) != softZone || km.Find(40, 100) != loudZone {
+		t.Error("Velocity layers mapped incorrectly")
+	}
+	if km.Find(60, 100) != nil {
+		t.Error("Expected no zone outside the key range")
+	}
+	if zones := km.FindAll(36, 64, nil); len(zones) != 1 {
+		t.Errorf("Expected 1 layered zone, got %d", len(zones))
+	}
+}
+
+// estimatePeriod measures the average zero-crossing period of a signal
+func estimatePeriod(data []float32) float64 {
+	first, last, crossings := -1, -1, 0
+	for i := 1; i < len(data); i++ {
+		if data[i-1] < 0 && data[i] >= 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			crossings++
+		}
+	}
+	if crossings < 2 {
+		return 0
+	}
+	return float64(last-first) / float64(crossings-1)
+}
+
+func TestVoicePitchTracking(t *testing.T) {
+	sample := NewSample([][]float32{sineData(8000, 100)}, 48000)
+	km := NewKeyMap()
+	km.Add(NewZone(sample, 0, 127, 60))
+
+	v := NewVoice(km, 48000)
+	out := make([]float32, 2000)
+
+	// Root key plays at the original pitch and level
+	v.TriggerNote(60, 127)
+	v.Process(out)
+	if p := estimatePeriod(out[100:]); math.Abs(p-100) > 0.5 {
+		t.Errorf("Expected period 100 at root key, got %f", p)
+	}
+	if math.Abs(float64(out[1025])-0.5) > 0.01 {
+		t.Errorf("Expected peak 0.5 at quarter cycle, got %f", out[1025])
+	}
+
+	// An octave up halves the period
+	v.TriggerNote(72, 127)
+	v.Process(out)
+	if p := estimatePeriod(out[100:]); math.Abs(p-50) > 0.5 {
+		t.Errorf("Expected period 50 an octave up, got %f", p)
+	}
+}
+
+func TestLoopCrossfade(t *testing.T) {
+	// A ramp makes any discontinuity at the loop point obvious
+	ramp := make([]float32, 1000)
+	for i := range ramp {
+		ramp[i] = float32(i) / 1000
+	}
+	sample := NewSample([][]float32{ramp}, 48000)
+
+	zone := NewZone(sample, 0, 127, 60)
+	if err := zone.SetLoop(LoopForward, 400, 800, 100); err != nil {
+		t.Fatalf("SetLoop failed: %v", err)
+	}
+
+	// The last sample of the loop must lead smoothly into the loop start
+	data := zone.data[0]
+	if math.Abs(float64(data[799]-ramp[399])) > 0.002 {
+		t.Errorf("Expected loop end to match pre-loop audio, got %f vs %f", data[799], ramp[399])
+	}
+	if data[650] != ramp[650] {
+		t.Error("Audio before the crossfade should be untouched")
+	}
+
+	if err := zone.SetLoop(LoopForward, 900, 1200, 0); err != ErrInvalidLoop {
+		t.Errorf("Expected ErrInvalidLoop, got %v", err)
+	}
+
+	// A looping voice keeps playing past the end of the sample
+	km := NewKeyMap()
+	zone.SetLoop(LoopForward, 400, 800, 100)
+	km.Add(zone)
+	v := NewVoice(km, 48000)
+	v.TriggerNote(60, 127)
+	out := make([]float32, 3000)
+	v.Process(out)
+	if !v.IsActive() {
+		t.Error("Looping voice should still be active")
+	}
+}
+
+func TestSamplerPolyphony(t *testing.T) {
+	sample := NewSample([][]float32{sineData(4800, 100)}, 48000)
+	km := NewKeyMap()
+	km.Add(NewZone(sample, 0, 127, 60))
+
+	s := NewSampler(km, 48000, 4, 512)
+	s.NoteOn(60, 100)
+	s.NoteOn(64, 100)
+
+	left := make([]float32, 512)
+	right := make([]float32, 512)
+	s.Process(left, right)
+
+	if s.GetActiveVoiceCount() != 2 {
+		t.Errorf("Expected 2 active voices, got %d", s.GetActiveVoiceCount())
+	}
+	if left[100] != right[100] {
+		t.Error("Mono sample should play equally on both channels")
+	}
+
+	// One-shot voices end after the sample
+	for i := 0; i < 20; i++ {
+		s.Process(left, right)
+	}
+	if s.GetActiveVoiceCount() != 0 {
+		t.Errorf("Expected voices to finish, got %d active", s.GetActiveVoiceCount())
+	}
+}
+
+// flacWriter packs bits MSB-first for building test FLAC streams
+type flacWriter struct {
+	buf   []byte
+	nbits int
+}
+
+func (w *flacWriter) write(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.nbits%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		if (v>>uint(i))&1 == 1 {
+			w.buf[len(w.buf)-1] |= 1 << uint(7-w.nbits%8)
+		}
+		w.nbits++
+	}
+}
+
+func (w *flacWriter) align() {
+	if r := w.nbits % 8; r != 0 {
+		w.write(0, 8-r)
+	}
+}
+
+// writeFixed2 writes a FIXED order-2 subframe with one Rice partition
+func (w *flacWriter) writeFixed2(x []int64, bps int) {
+	w.write(0, 1)
+	w.write(8+2, 6)
+	w.write(0, 1)
+	w.write(uint64(x[0]), bps)
+	w.write(uint64(x[1]), bps)
+
+	const param = 8
+	w.write(0, 2) // Rice, 4-bit parameters
+	w.write(0, 4) // Partition order 0
+	w.write(param, 4)
+	for i := 2; i < len(x); i++ {
+		r := x[i] - 2*x[i-1] + x[i-2]
+		u := uint64(r<<1) ^ uint64(r>>63)
+		w.write(0, int(u>>param))
+		w.write(1, 1)
+		w.write(u, param)
+	}
+}
+
+func TestDecodeFLAC(t *testing.T) {
+	const blockSize = 64
+	const frames = 2
+
+	left := make([]int64, blockSize*frames)
+	right := make([]int64, blockSize*frames)
+	for i := range left {
+		left[i] = int64(16000 * math.Sin(2*math.Pi*float64(i)/32))
+		right[i] = int64(-8000 * math.Sin(2*math.Pi*float64(i)/16))
+	}
+
+	w := &flacWriter{}
+	w.write('f', 8)
+	w.write('L', 8)
+	w.write('a', 8)
+	w.write('C', 8)
+
+	// STREAMINFO
+	w.write(1, 1)
+	w.write(0, 7)
+	w.write(34, 24)
+	w.write(blockSize, 16)
+	w.write(blockSize, 16)
+	w.write(0, 24)
+	w.write(0, 24)
+	w.write(44100, 20)
+	w.write(1, 3)  // Two channels
+	w.write(15, 5) // 16 bits
+	w.write(uint64(len(left)), 36)
+	w.write(0, 64)
+	w.write(0, 64) // MD5
+
+	for f := 0; f < frames; f++ {
+		l := left[f*blockSize : (f+1)*blockSize]
+		r := right[f*blockSize : (f+1)*blockSize]
+
+		w.write(0x3FFE, 14)
+		w.write(0, 2)
+		w.write(6, 4) // 8-bit block size follows
+		w.write(0, 4) // Sample rate from STREAMINFO
+		w.write(8, 4) // Left/side
+		w.write(4, 3) // 16 bits
+		w.write(0, 1)
+		w.write(uint64(f), 8)
+		w.write(blockSize-1, 8)
+		w.write(0, 8) // CRC-8
+
+		w.writeFixed2(l, 16)
+
+		// Side channel as verbatim with one extra bit
+		w.write(0, 1)
+		w.write(1, 6)
+		w.write(0, 1)
+		for i := range l {
+			w.write(uint64(l[i]-r[i]), 17)
+		}
+
+		w.align()
+		w.write(0, 16) // CRC-16
+	}
+
+	sample, err := Decode(bytes.NewReader(w.buf))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if sample.NumChannels() != 2 || sample.Len() != len(left) || sample.SampleRate != 44100 {
+		t.Fatalf("Unexpected sample layout: %d channels, %d samples, %f Hz",
+			sample.NumChannels(), sample.Len(), sample.SampleRate)
+	}
+	for i := range left {
+		if got := int64(math.Round(float64(sample.Data[0][i]) * 32768)); got != left[i] {
+			t.Fatalf("Left sample %d: expected %d, got %d", i, left[i], got)
+		}
+		if got := int64(math.Round(float64(sample.Data[1][i]) * 32768)); got != right[i] {
+			t.Fatalf("Right sample %d: expected %d, got %d", i, right[i], got)
+		}
+	}
+}
+
+// constantSample returns a sample of n samples at a fixed level
+func constantSample(n int, level float32) *Sample {
+	data := make([]float32, n)
+	for i := range data {
+		data[i] = level
+	}
+	return NewSample([][]float32{data}, 48000)
+}
+
+func TestRoundRobin(t *testing.T) {
+	km := NewKeyMap()
+	zones := make([]*Zone, 3)
+	for i := range zones {
+		zones[i] = NewZone(constantSample(1000, 0.1*float32(i+1)), 36, 36, 36)
+		zones[i].SetRoundRobin(i, len(zones))
+		km.Add(zones[i])
+	}
+	always := NewZone(constantSample(1000, 0.5), 36, 36, 36)
+	km.Add(always)
+
+	// The alternatives take turns; a zone without round robin always plays
+	v := NewVoice(km, 48000)
+	for n := 0; n < 7; n++ {
+		v.TriggerNote(36, 100)
+		got := v.Zones()
+		if len(got) != 2 || got[0] != zones[n%3] || got[1] != always {
+			t.Fatalf("Note %d played %v, want round robin zone %d and the fixed zone", n, got, n%3)
+		}
+	}
+
+	// Counts are per key, and reset starts over
+	km.ResetRoundRobin()
+	v.TriggerNote(36, 100)
+	if got := v.Zones(); got[0] != zones[0] {
+		t.Error("Round robin did not restart after reset")
+	}
+}
+
+func TestVelocityCrossfade(t *testing.T) {
+	soft := NewZone(constantSample(1000, 1), 0, 127, 60)
+	soft.SetVelocityRange(1, 80)
+	soft.SetVelocityCrossfade(0, 20)
+	loud := NewZone(constantSample(1000, 1), 0, 127, 60)
+	loud.SetVelocityRange(61, 127)
+	loud.SetVelocityCrossfade(20, 0)
+
+	// The layers blend with equal power through the overlap
+	for vel := uint8(1); vel <= 127; vel++ {
+		s, l := soft.VelocityGain(vel), loud.VelocityGain(vel)
+		if power := s*s + l*l; math.Abs(power-1) > 1e-9 {
+			t.Fatalf("Velocity %d: gains %f and %f, power %f", vel, s, l, power)
+		}
+	}
+	if soft.VelocityGain(40) != 1 || loud.VelocityGain(40) != 0 || loud.VelocityGain(100) != 1 {
+		t.Error("Gains outside the overlap should be 0 or 1")
+	}
+
+	// A voice in the overlap plays both layers
+	km := NewKeyMap()
+	km.Add(soft, loud)
+	v := NewVoice(km, 48000)
+	v.TriggerNote(60, 70)
+	if len(v.Zones()) != 2 {
+		t.Fatalf("Expected 2 layers at velocity 70, got %d", len(v.Zones()))
+	}
+	out := make([]float32, 600)
+	v.Process(out)
+	want := float32((soft.VelocityGain(70) + loud.VelocityGain(70)) * 70 / 127)
+	if math.Abs(float64(out[500]-want)) > 1e-3 {
+		t.Errorf("Layered level %f, want %f", out[500], want)
+	}
+}
+
+func TestSustainLoopRelease(t *testing.T) {
+	// A ramp makes any discontinuity obvious
+	ramp := make([]float32, 2000)
+	for i := range ramp {
+		ramp[i] = float32(i) / 2000
+	}
+	zone := NewZone(NewSample([][]float32{ramp}, 48000), 0, 127, 60)
+	if err := zone.SetLoop(LoopSustain, 400, 800, 100); err != nil {
+		t.Fatal(err)
+	}
+	km := NewKeyMap()
+	km.Add(zone)
+	v := NewVoice(km, 48000)
+	v.Envelope().SetADSR(0, 0, 1, 1)
+
+	// Hold through a few loops, then release inside the crossfade
+	v.TriggerNote(60, 127)
+	held := make([]float32, 1150)
+	v.Process(held)
+	v.ReleaseNote()
+	tail := make([]float32, 1500)
+	v.Process(tail)
+
+	// The release leaves the loop, which peaks at 0.4, and plays on through
+	// the original audio after it with no jump larger than the ramp's slope
+	out := append(held, tail...)
+	maxStep := float32(0)
+	for i := len(held); i < len(out); i++ {
+		step := out[i] - out[i-1]
+		if out[i-1] > 0.1 && out[i] > 0.1 {
+			maxStep = max(maxStep, float32(math.Abs(float64(step))))
+		}
+	}
+	if maxStep > 0.01 {
+		t.Errorf("Largest step after release %f", maxStep)
+	}
+	if peak := slices.Max(tail); peak < 0.5 {
+		t.Errorf("Release never reached the audio after the loop: peak %f", peak)
+	}
+}
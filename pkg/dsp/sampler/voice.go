@@ -0,0 +1,309 @@
+package sampler
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/envelope"
+)
+
+// MaxLayers is the most zones one voice plays at once
+const MaxLayers = 4
+
+// layer is one zone sounding in a voice
+type layer struct {
+	zone      *Zone
+	position  float64
+	increment float64
+	cutoff    float64
+	gain      float32 // Zone gain, velocity and velocity crossfade
+	leftLoop  bool    // Released out of a sustain loop
+	done      bool
+}
+
+// Voice plays one note from a key map with windowed-sinc pitch shifting.
+// A note plays every zone the key map selects for it, up to MaxLayers, so
+// velocity layers can crossfade and round robin zones take turns.
+// It implements voice.Voice so it can be driven by the framework allocator.
+type Voice struct {
+	keyMap     *KeyMap
+	sampleRate float64
+	env        *envelope.ADSR
+
+	layers    [MaxLayers]layer
+	numLayers int
+	selected  []*Zone // Scratch for KeyMap.Select
+
+	note      uint8
+	velocity  uint8
+	pitchBend float64 // Semitones
+
+	active    bool
+	released  bool
+	age       int64
+	amplitude float64
+}
+
+// NewVoice creates a sampler voice playing zones from keyMap
+func NewVoice(keyMap *KeyMap, sampleRate float64) *Voice {
+	env := envelope.New(sampleRate)
+	env.SetADSR(0, 0, 1, 0.05)
+
+	return &Voice{
+		keyMap:     keyMap,
+		sampleRate: sampleRate,
+		env:        env,
+		selected:   make([]*Zone, 0, 16),
+	}
+}
+
+// Envelope returns the amplitude envelope
+func (v *Voice) Envelope() *envelope.ADSR {
+	return v.env
+}
+
+// SetPitchBend sets a pitch offset in semitones
+func (v *Voice) SetPitchBend(semitones float64) {
+	v.pitchBend = semitones
+	v.updateIncrement()
+}
+
+// IsActive returns true if the voice is playing
+func (v *Voice) IsActive() bool {
+	return v.active
+}
+
+// GetNote returns the MIDI note being played
+func (v *Voice) GetNote() uint8 {
+	return v.note
+}
+
+// GetVelocity returns the note velocity
+func (v *Voice) GetVelocity() uint8 {
+	return v.velocity
+}
+
+// GetAmplitude returns the current envelope level
+func (v *Voice) GetAmplitude() float64 {
+	return v.amplitude
+}
+
+// GetAge returns the number of samples played
+func (v *Voice) GetAge() int64 {
+	return v.age
+}
+
+// Zones returns the zones the current note plays. It allocates; use it off
+// the audio thread.
+func (v *Voice) Zones() []*Zone {
+	zones := make([]*Zone, v.numLayers)
+	for i := range zones {
+		zones[i] = v.layers[i].zone
+	}
+	return zones
+}
+
+// TriggerNote starts the zones the key map selects for the note and velocity
+func (v *Voice) TriggerNote(note, velocity uint8) {
+	v.selected = v.keyMap.Select(note, velocity, v.selected[:0])
+	v.numLayers = 0
+	for _, zone := range v.selected {
+		if v.numLayers == MaxLayers {
+			break
+		}
+		gain := zone.Gain * zone.VelocityGain(velocity) * float64(velocity) / 127.0
+		if zone.Sample.Len() == 0 || gain == 0 {
+			continue
+		}
+		v.layers[v.numLayers] = layer{zone: zone, gain: float32(gain)}
+		v.numLayers++
+	}
+	if v.numLayers == 0 {
+		v.active = false
+		return
+	}
+
+	v.note = note
+	v.velocity = velocity
+	v.active = true
+	v.released = false
+	v.age = 0
+	v.updateIncrement()
+	v.env.Reset()
+	v.env.Trigger()
+}
+
+// ReleaseNote starts the envelope release and leaves sustain loops
+func (v *Voice) ReleaseNote() {
+	v.released = true
+	for i := 0; i < v.numLayers; i++ {
+		l := &v.layers[i]
+		// Outside the loop crossfade the original audio is the same, so
+		// the layer can leave at once; inside it, it leaves at the wrap
+		if l.position < float64(l.zone.loopEnd-l.zone.loopCrossfade) {
+			l.leftLoop = true
+		}
+	}
+	v.env.Release()
+}
+
+// Stop silences the voice immediately
+func (v *Voice) Stop() {
+	v.active = false
+	v.env.Reset()
+	v.amplitude = 0
+}
+
+// updateIncrement computes the playback rate of each layer for the note,
+// tuning and sample rate
+func (v *Voice) updateIncrement() {
+	for i := 0; i < v.numLayers; i++ {
+		l := &v.layers[i]
+		semitones := float64(int(v.note)-int(l.zone.RootKey)) + l.zone.Tune/100.0 + v.pitchBend
+		l.increment = math.Pow(2, semitones/12.0) * l.zone.Sample.SampleRate / v.sampleRate
+		l.cutoff = sincCutoff(l.increment)
+	}
+}
+
+// looping returns true while the layer's loop region should repeat
+func (l *layer) looping() bool {
+	return l.zone.loopMode == LoopForward || (l.zone.loopMode == LoopSustain && !l.leftLoop)
+}
+
+// channels returns the audio the layer reads: the loop crossfaded data
+// while it loops, and the original once it has left a sustain loop
+func (l *layer) channels() [][]float32 {
+	if l.leftLoop {
+		return l.zone.Sample.Data
+	}
+	return l.zone.data
+}
+
+// fetch reads a sample index, wrapping into the loop and returning 0 outside the data
+func (l *layer) fetch(data []float32, idx int) float32 {
+	if idx >= l.zone.loopEnd && l.looping() {
+		loopLen := l.zone.loopEnd - l.zone.loopStart
+		idx = l.zone.loopStart + (idx-l.zone.loopStart)%loopLen
+	}
+	if idx < 0 || idx >= len(data) {
+		return 0
+	}
+	return data[idx]
+}
+
+// interpolate reads data at the current position with windowed-sinc interpolation
+func (l *layer) interpolate(data []float32) float32 {
+	center := int(math.Floor(l.position))
+	frac := l.position - float64(center)
+	span := int(math.Ceil(sincZeroCrossings / l.cutoff))
+
+	var sum float32
+	for k := -span + 1; k <= span; k++ {
+		if w := sincKernel((float64(k) - frac) * l.cutoff); w != 0 {
+			sum += w * l.fetch(data, center+k)
+		}
+	}
+	return sum * float32(l.cutoff)
+}
+
+// advance moves the play position and marks the layer done past the end
+func (l *layer) advance(released bool) {
+	l.position += l.increment
+
+	if l.looping() && l.position >= float64(l.zone.loopEnd) {
+		loopLen := float64(l.zone.loopEnd - l.zone.loopStart)
+		l.position -= loopLen * math.Floor((l.position-float64(l.zone.loopStart))/loopLen)
+		if released && l.zone.loopMode == LoopSustain {
+			l.leftLoop = true
+		}
+	}
+
+	// Let the kernel ring out past the last sample
+	if l.position >= float64(l.zone.Sample.Len()+sincZeroCrossings) {
+		l.done = true
+	}
+}
+
+// advance moves every layer on and stops the voice when all have ended
+func (v *Voice) advance() {
+	v.age++
+	sounding := false
+	for i := 0; i < v.numLayers; i++ {
+		l := &v.layers[i]
+		if !l.done {
+			l.advance(v.released)
+			sounding = sounding || !l.done
+		}
+	}
+	if !sounding {
+		v.active = false
+	}
+}
+
+// nextGain returns the envelope level and stops the voice when done
+func (v *Voice) nextGain() float32 {
+	env := v.env.Next()
+	v.amplitude = float64(env)
+	if !v.env.IsActive() {
+		v.active = false
+	}
+	return env
+}
+
+// Process renders the voice as mono (channels averaged), overwriting output
+func (v *Voice) Process(output []float32) {
+	for i := range output {
+		if !v.active {
+			output[i] = 0
+			continue
+		}
+		var sum float32
+		for j := 0; j < v.numLayers; j++ {
+			l := &v.layers[j]
+			if l.done {
+				continue
+			}
+			data := l.channels()
+			var mix float32
+			for _, ch := range data {
+				mix += l.interpolate(ch)
+			}
+			sum += mix / float32(len(data)) * l.gain
+		}
+		output[i] = sum * v.nextGain()
+		v.advance()
+	}
+}
+
+// ProcessStereo renders the voice into left and right, overwriting them.
+// Mono samples play on both channels; channels beyond two are ignored.
+func (v *Voice) ProcessStereo(left, right []float32) {
+	n := min(len(left), len(right))
+	clear(left[n:])
+	clear(right[n:])
+
+	for i := 0; i < n; i++ {
+		if !v.active {
+			left[i], right[i] = 0, 0
+			continue
+		}
+		var sumL, sumR float32
+		for j := 0; j < v.numLayers; j++ {
+			l := &v.layers[j]
+			if l.done {
+				continue
+			}
+			data := l.channels()
+			x := l.interpolate(data[0]) * l.gain
+			sumL += x
+			if len(data) == 1 {
+				sumR += x
+			} else {
+				sumR += l.interpolate(data[1]) * l.gain
+			}
+		}
+		gain := v.nextGain()
+		left[i] = sumL * gain
+		right[i] = sumR * gain
+		v.advance()
+	}
+}
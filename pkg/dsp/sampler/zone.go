@@ -0,0 +1,260 @@
+package sampler
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrInvalidLoop is returned for loop points outside the sample
+var ErrInvalidLoop = errors.New("invalid loop points")
+
+// LoopMode controls how a zone loops
+type LoopMode int
+
+const (
+	// LoopNone plays the sample once
+	LoopNone LoopMode = iota
+	// LoopForward loops continuously, including after note off
+	LoopForward
+	// LoopSustain loops while the note is held, then plays to the end
+	LoopSustain
+)
+
+// Zone maps a sample onto a key and velocity range
+type Zone struct {
+	Sample *Sample
+
+	LowKey, HighKey           uint8
+	LowVelocity, HighVelocity uint8
+	RootKey                   uint8
+
+	Tune float64 // Fine tuning in cents
+	Gain float64 // Linear gain
+
+	loopMode      LoopMode
+	loopStart     int
+	loopEnd       int
+	loopCrossfade int
+	data          [][]float32 // Sample data with the loop crossfade applied
+
+	// Round robin: the zone plays on note ons where the key's count modulo
+	// rrLength is rrPosition
+	rrPosition int
+	rrLength   int
+
+	// Velocities over which the zone fades in above LowVelocity and out
+	// below HighVelocity
+	fadeIn  uint8
+	fadeOut uint8
+}
+
+// NewZone creates a zone covering lowKey-highKey at all velocities. The sample
+// plays at its original pitch at rootKey. A loop stored in the sample file is
+// applied as a sustain loop.
+func NewZone(sample *Sample, lowKey, highKey, rootKey uint8) *Zone {
+	z := &Zone{
+		Sample:       sample,
+		LowKey:       lowKey,
+		HighKey:      highKey,
+		LowVelocity:  1,
+		HighVelocity: 127,
+		RootKey:      rootKey,
+		Gain:         1.0,
+		data:         sample.Data,
+	}
+	if sample.HasLoop() {
+		z.SetLoop(LoopSustain, sample.LoopStart, sample.LoopEnd, 0)
+	}
+	return z
+}
+
+// SetVelocityRange sets the velocities the zone responds to
+func (z *Zone) SetVelocityRange(low, high uint8) {
+	z.LowVelocity = low
+	z.HighVelocity = high
+}
+
+// SetVelocityCrossfade sets how many velocities the zone fades in over from
+// its low velocity, and out over up to its high velocity. Overlapping
+// velocity layers with matching fades blend with equal power instead of
+// switching at one velocity:
+//
+//	soft.SetVelocityRange(1, 80)
+//	soft.SetVelocityCrossfade(0, 20)
+//	loud.SetVelocityRange(61, 127)
+//	loud.SetVelocityCrossfade(20, 0)
+func (z *Zone) SetVelocityCrossfade(fadeIn, fadeOut uint8) {
+	z.fadeIn = fadeIn
+	z.fadeOut = fadeOut
+}
+
+// VelocityGain returns the zone's crossfade gain at a velocity: 1 outside
+// the fades and 0 outside the velocity range
+func (z *Zone) VelocityGain(velocity uint8) float64 {
+	if velocity < z.LowVelocity || velocity > z.HighVelocity {
+		return 0
+	}
+	gain := 1.0
+	if z.fadeIn > 0 {
+		t := (float64(velocity-z.LowVelocity) + 0.5) / float64(z.fadeIn)
+		if t < 1 {
+			gain *= math.Sin(0.5 * math.Pi * t)
+		}
+	}
+	if z.fadeOut > 0 {
+		t := (float64(z.HighVelocity-velocity) + 0.5) / float64(z.fadeOut)
+		if t < 1 {
+			gain *= math.Sin(0.5 * math.Pi * t)
+		}
+	}
+	return gain
+}
+
+// SetRoundRobin makes the zone one of length alternatives for its keys. It
+// plays on every length-th note on of a key, starting with the note whose
+// count is position, so zones with positions 0 to length-1 take turns. A
+// length of 1 or less plays the zone on every note.
+func (z *Zone) SetRoundRobin(position, length int) {
+	if length <= 1 {
+		z.rrPosition, z.rrLength = 0, 0
+		return
+	}
+	z.rrPosition = ((position % length) + length) % length
+	z.rrLength = length
+}
+
+// RoundRobin returns the zone's round robin position and length
+func (z *Zone) RoundRobin() (position, length int) {
+	return z.rrPosition, z.rrLength
+}
+
+// playsOn reports whether the zone's round robin turn includes a key's
+// note count
+func (z *Zone) playsOn(count uint32) bool {
+	return z.rrLength <= 1 || int(count%uint32(z.rrLength)) == z.rrPosition
+}
+
+// Contains returns true if the zone responds to a key and velocity
+func (z *Zone) Contains(key, velocity uint8) bool {
+	return key >= z.LowKey && key <= z.HighKey &&
+		velocity >= z.LowVelocity && velocity <= z.HighVelocity
+}
+
+// SetLoop sets the loop region [start, end) and a crossfade length in samples.
+// The crossfade blends the end of the loop with the audio leading into the
+// loop start, so the jump back is seamless. A sustain loop plays the
+// crossfade only while it repeats; once released, the voice leaves the loop
+// where the original audio continues unaltered. This allocates a copy of the
+// sample data when crossfading; call it off the audio thread.
+func (z *Zone) SetLoop(mode LoopMode, start, end, crossfade int) error {
+	if mode == LoopNone {
+		z.loopMode = LoopNone
+		z.loopCrossfade = 0
+		z.data = z.Sample.Data
+		return nil
+	}
+	if start < 0 || end <= start || end > z.Sample.Len() {
+		return ErrInvalidLoop
+	}
+
+	z.loopMode = mode
+	z.loopStart = start
+	z.loopEnd = end
+
+	// The crossfade needs audio before the loop start
+	crossfade = min(crossfade, start, end-start)
+	if crossfade <= 0 {
+		z.loopCrossfade = 0
+		z.data = z.Sample.Data
+		return nil
+	}
+	z.loopCrossfade = crossfade
+
+	z.data = make([][]float32, len(z.Sample.Data))
+	for ch, src := range z.Sample.Data {
+		dst := make([]float32, len(src))
+		copy(dst, src)
+
+		for j := 0; j < crossfade; j++ {
+			g := float32(j+1) / float32(crossfade)
+			e := end - crossfade + j
+			s := start - crossfade + j
+			dst[e] = src[e]*(1-g) + src[s]*g
+		}
+		z.data[ch] = dst
+	}
+	return nil
+}
+
+// LoopMode returns the zone's loop mode
+func (z *Zone) LoopMode() LoopMode {
+	return z.loopMode
+}
+
+// LoopPoints returns the loop start and end in samples
+func (z *Zone) LoopPoints() (start, end int) {
+	return z.loopStart, z.loopEnd
+}
+
+// KeyMap holds the zones of an instrument
+type KeyMap struct {
+	zones []*Zone
+
+	// Note ons per key, for round robin
+	counts [128]uint32
+}
+
+// NewKeyMap creates an empty key map
+func NewKeyMap() *KeyMap {
+	return &KeyMap{}
+}
+
+// Add adds zones to the key map
+func (m *KeyMap) Add(zones ...*Zone) {
+	m.zones = append(m.zones, zones...)
+}
+
+// Find returns the first zone matching a key and velocity, or nil
+func (m *KeyMap) Find(key, velocity uint8) *Zone {
+	for _, z := range m.zones {
+		if z.Contains(key, velocity) {
+			return z
+		}
+	}
+	return nil
+}
+
+// FindAll appends every zone matching a key and velocity to dst (for layering)
+func (m *KeyMap) FindAll(key, velocity uint8, dst []*Zone) []*Zone {
+	for _, z := range m.zones {
+		if z.Contains(key, velocity) {
+			dst = append(dst, z)
+		}
+	}
+	return dst
+}
+
+// Select appends the zones a note on plays to dst: every zone matching the
+// key and velocity whose round robin turn it is. Each call counts a note on
+// of the key, so the next call moves on to the next round robin zones.
+func (m *KeyMap) Select(key, velocity uint8, dst []*Zone) []*Zone {
+	key &= 0x7f
+	count := m.counts[key]
+	m.counts[key]++
+	for _, z := range m.zones {
+		if z.Contains(key, velocity) && z.playsOn(count) {
+			dst = append(dst, z)
+		}
+	}
+	return dst
+}
+
+// ResetRoundRobin starts every key's round robin over from position 0
+func (m *KeyMap) ResetRoundRobin() {
+	m.counts = [128]uint32{}
+}
+
+// Zones returns all zones
+func (m *KeyMap) Zones() []*Zone {
+	return m.zones
+}
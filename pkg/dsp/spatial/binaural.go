@@ -0,0 +1,123 @@
+package spatial
+
+// binauralCrossfade is the time in seconds to crossfade between responses
+// when the source moves
+const binauralCrossfade = 0.01
+
+// Binaural places a mono source around the listener by convolving it with
+// the left and right ear responses of the nearest measured direction. Moving
+// the source crossfades from the old responses to the new ones so position
+// automation does not click.
+type Binaural struct {
+	set       *HRIRSet
+	azimuth   float64
+	elevation float64
+
+	current *HRIR
+	next    *HRIR // Target of a crossfade in progress, nil otherwise
+	fadePos int
+	fadeLen int
+
+	// history holds the input twice over so the last length samples are
+	// always contiguous from pos
+	history []float32
+	pos     int
+}
+
+// NewBinaural creates a panner using the responses in set, which also sets
+// the sample rate. The source starts in front of the listener.
+func NewBinaural(set *HRIRSet) *Binaural {
+	return &Binaural{
+		set:     set,
+		current: set.Nearest(0, 0),
+		fadeLen: max(1, int(binauralCrossfade*set.SampleRate())),
+		history: make([]float32, 2*set.Length()),
+	}
+}
+
+// SetPosition moves the source, with azimuth in degrees counterclockwise
+// from the front and elevation in degrees up from the horizontal plane
+func (b *Binaural) SetPosition(azimuth, elevation float64) {
+	b.azimuth, b.elevation = azimuth, elevation
+	target := b.set.Nearest(azimuth, elevation)
+	if target == b.current && b.next == nil {
+		return
+	}
+	if b.next != nil {
+		// Restart from the response already being faded to
+		b.current = b.next
+	}
+	b.next, b.fadePos = nil, 0
+	if target != b.current {
+		b.next = target
+	}
+}
+
+// GetAzimuth returns the source azimuth in degrees
+func (b *Binaural) GetAzimuth() float64 {
+	return b.azimuth
+}
+
+// GetElevation returns the source elevation in degrees
+func (b *Binaural) GetElevation() float64 {
+	return b.elevation
+}
+
+// Process processes one mono sample into a left and right ear sample
+func (b *Binaural) Process(input float32) (float32, float32) {
+	if b.current == nil {
+		return input, input
+	}
+	n := len(b.history) / 2
+	b.pos--
+	if b.pos < 0 {
+		b.pos = n - 1
+	}
+	b.history[b.pos] = input
+	b.history[b.pos+n] = input
+	window := b.history[b.pos : b.pos+n]
+
+	left, right := convolve(window, b.current)
+	if b.next == nil {
+		return left, right
+	}
+
+	nextLeft, nextRight := convolve(window, b.next)
+	mix := float32(b.fadePos) / float32(b.fadeLen)
+	left += (nextLeft - left) * mix
+	right += (nextRight - right) * mix
+	b.fadePos++
+	if b.fadePos >= b.fadeLen {
+		b.current, b.next, b.fadePos = b.next, nil, 0
+	}
+	return left, right
+}
+
+// convolve applies both ear responses to the input history, newest first
+func convolve(window []float32, h *HRIR) (float32, float32) {
+	var left, right float32
+	for k, x := range window {
+		left += h.Left[k] * x
+		right += h.Right[k] * x
+	}
+	return left, right
+}
+
+// ProcessBuffer processes a mono buffer into left and right ear buffers
+func (b *Binaural) ProcessBuffer(input, left, right []float32) {
+	length := min(len(input), len(left), len(right))
+	for i := 0; i < length; i++ {
+		left[i], right[i] = b.Process(input[i])
+	}
+}
+
+// Reset clears the input history and completes any crossfade
+func (b *Binaural) Reset() {
+	for i := range b.history {
+		b.history[i] = 0
+	}
+	b.pos = 0
+	if b.next != nil {
+		b.current, b.next, b.fadePos = b.next, nil, 0
+	}
+}
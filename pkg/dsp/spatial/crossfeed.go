@@ -0,0 +1,137 @@
+// Package spatial provides headphone monitoring and binaural positioning:
+// a Bauer style crossfeed and an HRIR based binaural panner.
+package spatial
+
+import "math"
+
+// CrossfeedPreset selects a common crossfeed setting
+type CrossfeedPreset int
+
+const (
+	CrossfeedDefault CrossfeedPreset = iota // 700 Hz, 4.5 dB: close to natural speaker listening
+	CrossfeedChuMoy                         // 700 Hz, 6 dB: the Chu Moy headphone amplifier
+	CrossfeedMeier                          // 650 Hz, 9.5 dB: Jan Meier's lighter setting
+)
+
+// Crossfeed limits
+const (
+	MinCrossfeedCutoff = 300.0  // Hz
+	MaxCrossfeedCutoff = 2000.0 // Hz
+	MinCrossfeedLevel  = 1.0    // dB
+	MaxCrossfeedLevel  = 15.0   // dB
+)
+
+// Crossfeed blends a lowpassed, slightly delayed copy of each channel into
+// the other, as happens acoustically with speakers, so hard-panned mixes
+// are less tiring on headphones. It follows Bauer's stereophonic-to-binaural
+// network as popularised by bs2b: the cross path is a first order lowpass
+// whose group delay approximates the interaural time difference, and the
+// direct path gets a matching high shelf so the summed response stays flat
+// for centered sources.
+//
+// Level is the difference in dB between the direct and crossfed signal at
+// low frequencies; higher levels mean less crossfeed.
+type Crossfeed struct {
+	sampleRate float64
+	cutoff     float64
+	level      float64
+
+	// Cross path lowpass and direct path high shelf coefficients
+	loA0, loB1        float64
+	hiA0, hiA1, hiB1  float64
+	gain              float64 // Normalizes mono to unity
+	lo, hi, prevInput [2]float64
+}
+
+// NewCrossfeed creates a crossfeed with the default preset
+func NewCrossfeed(sampleRate float64) *Crossfeed {
+	c := &Crossfeed{sampleRate: sampleRate}
+	c.SetPreset(CrossfeedDefault)
+	return c
+}
+
+// SetPreset applies one of the common settings
+func (c *Crossfeed) SetPreset(preset CrossfeedPreset) {
+	switch preset {
+	case CrossfeedChuMoy:
+		c.cutoff, c.level = 700, 6.0
+	case CrossfeedMeier:
+		c.cutoff, c.level = 650, 9.5
+	default:
+		c.cutoff, c.level = 700, 4.5
+	}
+	c.update()
+}
+
+// SetCutoff sets the cross path cutoff in Hz (300-2000)
+func (c *Crossfeed) SetCutoff(hz float64) {
+	c.cutoff = math.Max(MinCrossfeedCutoff, math.Min(MaxCrossfeedCutoff, hz))
+	c.update()
+}
+
+// GetCutoff returns the cross path cutoff in Hz
+func (c *Crossfeed) GetCutoff() float64 {
+	return c.cutoff
+}
+
+// SetLevel sets the low frequency level difference between the direct and
+// crossfed signal in dB (1-15)
+func (c *Crossfeed) SetLevel(dB float64) {
+	c.level = math.Max(MinCrossfeedLevel, math.Min(MaxCrossfeedLevel, dB))
+	c.update()
+}
+
+// GetLevel returns the crossfeed level in dB
+func (c *Crossfeed) GetLevel() float64 {
+	return c.level
+}
+
+// update recomputes the filters from the cutoff and level
+func (c *Crossfeed) update() {
+	// Low and high frequency gains of the cross and direct paths, split so
+	// the level difference sits at low frequencies
+	loDB := -c.level*5.0/6.0 - 3.0
+	hiDB := c.level/6.0 - 3.0
+	loGain := math.Pow(10, loDB/20)
+	hiGain := 1 - math.Pow(10, hiDB/20)
+
+	// The shelf corner sits where its boost meets the lowpass level
+	hiCutoff := c.cutoff * math.Pow(2, (loDB-20*math.Log10(hiGain))/12)
+
+	x := math.Exp(-2 * math.Pi * c.cutoff / c.sampleRate)
+	c.loA0 = loGain * (1 - x)
+	c.loB1 = x
+
+	x = math.Exp(-2 * math.Pi * hiCutoff / c.sampleRate)
+	c.hiA0 = 1 - hiGain*(1-x)
+	c.hiA1 = -x
+	c.hiB1 = x
+
+	c.gain = 1 / (1 - hiGain + loGain)
+}
+
+// Process processes one stereo sample pair
+func (c *Crossfeed) Process(left, right float32) (float32, float32) {
+	in := [2]float64{float64(left), float64(right)}
+	for ch := range in {
+		c.lo[ch] = c.loA0*in[ch] + c.loB1*c.lo[ch]
+		c.hi[ch] = c.hiA0*in[ch] + c.hiA1*c.prevInput[ch] + c.hiB1*c.hi[ch]
+		c.prevInput[ch] = in[ch]
+	}
+	return float32((c.hi[0] + c.lo[1]) * c.gain), float32((c.hi[1] + c.lo[0]) * c.gain)
+}
+
+// ProcessStereo processes stereo buffers in place
+func (c *Crossfeed) ProcessStereo(left, right []float32) {
+	length := min(len(left), len(right))
+	for i := 0; i < length; i++ {
+		left[i], right[i] = c.Process(left[i], right[i])
+	}
+}
+
+// Reset clears the filter state
+func (c *Crossfeed) Reset() {
+	c.lo = [2]float64{}
+	c.hi = [2]float64{}
+	c.prevInput = [2]float64{}
+}
@@ -0,0 +1,212 @@
+package spatial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidHRIR is returned for empty or inconsistent HRIR data
+var ErrInvalidHRIR = errors.New("invalid HRIR data")
+
+// Spherical head model constants (Brown and Duda, 1998)
+const (
+	headRadius     = 0.0875 // Meters
+	speedOfSound   = 343.0  // Meters per second
+	shadowMinGain  = 0.1    // High frequency gain deep in the head shadow
+	shadowMinAngle = 150.0  // Degrees from the ear where the shadow is deepest
+	sphericalStep  = 5.0    // Degrees between generated azimuths
+)
+
+// HRIR is the pair of head-related impulse responses measured for one
+// source direction. Azimuth is in degrees counterclockwise from the front,
+// so 90 is left, and elevation is in degrees up from the horizontal plane,
+// as in the SOFA conventions.
+type HRIR struct {
+	Azimuth   float64
+	Elevation float64
+	Left      []float32
+	Right     []float32
+
+	direction [3]float64 // Unit vector, for nearest neighbour lookup
+}
+
+// HRIRSet holds the measurements a binaural panner chooses from. All
+// responses share one length and sample rate.
+type HRIRSet struct {
+	sampleRate float64
+	length     int
+	hrirs      []HRIR
+}
+
+// NewHRIRSet creates an empty set for responses at the given sample rate
+func NewHRIRSet(sampleRate float64) *HRIRSet {
+	return &HRIRSet{sampleRate: sampleRate}
+}
+
+// Add adds the responses for one direction. Shorter responses are padded
+// with silence to the longest in the set.
+func (s *HRIRSet) Add(azimuth, elevation float64, left, right []float32) error {
+	if len(left) == 0 || len(right) == 0 {
+		return fmt.Errorf("%w: empty response at azimuth %g, elevation %g", ErrInvalidHRIR, azimuth, elevation)
+	}
+	s.length = max(s.length, len(left), len(right))
+	s.hrirs = append(s.hrirs, HRIR{
+		Azimuth:   azimuth,
+		Elevation: elevation,
+		Left:      append([]float32(nil), left...),
+		Right:     append([]float32(nil), right...),
+		direction: directionVector(azimuth, elevation),
+	})
+	for i := range s.hrirs {
+		h := &s.hrirs[i]
+		h.Left = padTo(h.Left, s.length)
+		h.Right = padTo(h.Right, s.length)
+	}
+	return nil
+}
+
+// padTo extends ir with zeros to n samples
+func padTo(ir []float32, n int) []float32 {
+	if len(ir) >= n {
+		return ir
+	}
+	return append(ir, make([]float32, n-len(ir))...)
+}
+
+// directionVector converts SOFA spherical angles in degrees to a unit vector
+// with x to the front, y to the left and z up
+func directionVector(azimuth, elevation float64) [3]float64 {
+	az, el := azimuth*math.Pi/180, elevation*math.Pi/180
+	return [3]float64{math.Cos(el) * math.Cos(az), math.Cos(el) * math.Sin(az), math.Sin(el)}
+}
+
+// SampleRate returns the sample rate of the responses
+func (s *HRIRSet) SampleRate() float64 {
+	return s.sampleRate
+}
+
+// Length returns the length of the responses in samples
+func (s *HRIRSet) Length() int {
+	return s.length
+}
+
+// Count returns the number of measured directions
+func (s *HRIRSet) Count() int {
+	return len(s.hrirs)
+}
+
+// Nearest returns the measurement closest to a direction, or nil for an
+// empty set
+func (s *HRIRSet) Nearest(azimuth, elevation float64) *HRIR {
+	target := directionVector(azimuth, elevation)
+	var best *HRIR
+	bestDot := math.Inf(-1)
+	for i := range s.hrirs {
+		d := &s.hrirs[i].direction
+		if dot := d[0]*target[0] + d[1]*target[1] + d[2]*target[2]; dot > bestDot {
+			best, bestDot = &s.hrirs[i], dot
+		}
+	}
+	return best
+}
+
+// SOFAData is the content of a SOFA file following the SimpleFreeFieldHRIR
+// convention (AES69). Reading the netCDF/HDF5 container is left to an HDF5
+// library; fill this from its SamplingRate, SourcePosition and Data.IR
+// variables and pass it to LoadSOFA.
+type SOFAData struct {
+	SamplingRate   float64
+	SourcePosition [][3]float64   // Azimuth and elevation in degrees, then distance, per measurement
+	DataIR         [][2][]float64 // Left and right responses per measurement
+}
+
+// LoadSOFA builds an HRIR set from SOFA data
+func LoadSOFA(data *SOFAData) (*HRIRSet, error) {
+	if data == nil || data.SamplingRate <= 0 {
+		return nil, fmt.Errorf("%w: missing sampling rate", ErrInvalidHRIR)
+	}
+	if len(data.SourcePosition) == 0 || len(data.SourcePosition) != len(data.DataIR) {
+		return nil, fmt.Errorf("%w: %d source positions for %d responses",
+			ErrInvalidHRIR, len(data.SourcePosition), len(data.DataIR))
+	}
+	set := NewHRIRSet(data.SamplingRate)
+	for i, pos := range data.SourcePosition {
+		if err := set.Add(pos[0], pos[1], toFloat32(data.DataIR[i][0]), toFloat32(data.DataIR[i][1])); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// toFloat32 converts a response to single precision
+func toFloat32(ir []float64) []float32 {
+	out := make([]float32, len(ir))
+	for i, v := range ir {
+		out[i] = float32(v)
+	}
+	return out
+}
+
+// SphericalHead generates a horizontal-plane HRIR set from a rigid sphere
+// head model: each ear gets the interaural delay around the sphere and a
+// one-pole, one-zero head shadow filter that lifts highs facing the source
+// and dulls them behind the head. It has no pinna cues, so it places
+// sources left and right convincingly but not above or behind; load
+// measured responses for that.
+func SphericalHead(sampleRate float64) *HRIRSet {
+	set := NewHRIRSet(sampleRate)
+	length := max(32, int(math.Ceil(0.003*sampleRate)))
+	ears := [2][3]float64{{0, 1, 0}, {0, -1, 0}}
+	for az := 0.0; az < 360; az += sphericalStep {
+		source := directionVector(az, 0)
+		var irs [2][]float32
+		for ear, axis := range ears {
+			incidence := math.Acos(source[0]*axis[0] + source[1]*axis[1] + source[2]*axis[2])
+			irs[ear] = sphericalEar(incidence, sampleRate, length)
+		}
+		set.Add(az, 0, irs[0], irs[1])
+	}
+	return set
+}
+
+// sphericalEar returns the response of one ear to a source at the given
+// angle in radians from the ear axis
+func sphericalEar(incidence, sampleRate float64, length int) []float32 {
+	// Delay around the sphere, offset so the nearest possible path is zero
+	radiusTime := headRadius / speedOfSound
+	delay := radiusTime * (1 - math.Cos(incidence))
+	if incidence >= math.Pi/2 {
+		delay = radiusTime * (1 + incidence - math.Pi/2)
+	}
+	delaySamples := delay*sampleRate + 1
+
+	// Head shadow: H(s) = (1 + αs/2ω0) / (1 + s/2ω0), via the bilinear
+	// transform
+	minAngle := shadowMinAngle * math.Pi / 180
+	alpha := (1 + shadowMinGain/2) + (1-shadowMinGain/2)*math.Cos(incidence/minAngle*math.Pi)
+	w0 := 2 * speedOfSound / headRadius
+	k := 2 * sampleRate
+	a0 := w0 + k
+	b0, b1 := (w0+alpha*k)/a0, (w0-alpha*k)/a0
+	a1 := (w0 - k) / a0
+
+	// Fractionally delayed impulse through the shadow filter
+	ir := make([]float32, length)
+	i := int(delaySamples)
+	frac := delaySamples - float64(i)
+	var x1, y1 float64
+	for n := range ir {
+		x := 0.0
+		switch n {
+		case i:
+			x = 1 - frac
+		case i + 1:
+			x = frac
+		}
+		y := b0*x + b1*x1 - a1*y1
+		x1, y1 = x, y
+		ir[n] = float32(y)
+	}
+	return ir
+}
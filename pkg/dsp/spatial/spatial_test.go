@@ -0,0 +1,144 @@
+package spatial
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCrossfeedMonoIsUnity(t *testing.T) {
+	c := NewCrossfeed(48000)
+	var l, r float32
+	for i := 0; i < 48000; i++ {
+		l, r = c.Process(0.5, 0.5)
+	}
+	if math.Abs(float64(l-0.5)) > 1e-3 || math.Abs(float64(r-0.5)) > 1e-3 {
+		t.Errorf("Mono DC = %f, %f, want 0.5", l, r)
+	}
+}
+
+// crossfeedLeak returns the right to left level ratio of a hard left sine
+func crossfeedLeak(c *Crossfeed, freq float64) float64 {
+	var left, right float64
+	for i := 0; i < 48000; i++ {
+		x := float32(math.Sin(2 * math.Pi * freq * float64(i) / 48000))
+		l, r := c.Process(x, 0)
+		if i >= 24000 {
+			left += float64(l * l)
+			right += float64(r * r)
+		}
+	}
+	return math.Sqrt(right / left)
+}
+
+func TestCrossfeedLeaksLows(t *testing.T) {
+	c := NewCrossfeed(48000)
+	low := crossfeedLeak(c, 100)
+	c.Reset()
+	high := crossfeedLeak(c, 8000)
+	if low < 0.3 || high > low/3 {
+		t.Errorf("Leak at 100 Hz = %.3f and 8 kHz = %.3f, want strong lows only", low, high)
+	}
+
+	// Meier crossfeeds less than the default
+	c.SetPreset(CrossfeedMeier)
+	c.Reset()
+	if meier := crossfeedLeak(c, 100); meier >= low {
+		t.Errorf("Meier leak = %.3f, want below the default %.3f", meier, low)
+	}
+	if c.GetCutoff() != 650 || c.GetLevel() != 9.5 {
+		t.Errorf("Meier preset = %f Hz, %f dB", c.GetCutoff(), c.GetLevel())
+	}
+	c.SetLevel(100)
+	if c.GetLevel() != MaxCrossfeedLevel {
+		t.Errorf("Level = %f, want it clamped to %f", c.GetLevel(), MaxCrossfeedLevel)
+	}
+}
+
+// earResponse returns the energy and onset of an impulse response
+func earResponse(ir []float32) (energy float64, onset int) {
+	onset = -1
+	for i, v := range ir {
+		energy += float64(v * v)
+		if onset < 0 && math.Abs(float64(v)) > 0.05 {
+			onset = i
+		}
+	}
+	return energy, onset
+}
+
+func TestBinauralSphericalHead(t *testing.T) {
+	b := NewBinaural(SphericalHead(48000))
+	n := 256
+	impulse := make([]float32, n)
+	impulse[0] = 1
+	left, right := make([]float32, n), make([]float32, n)
+
+	// A source on the left is louder and earlier in the left ear
+	b.SetPosition(90, 0)
+	b.Reset()
+	b.ProcessBuffer(impulse, left, right)
+	le, lt := earResponse(left)
+	re, rt := earResponse(right)
+	if le <= re || lt >= rt {
+		t.Errorf("Left source: left ear %.3f at %d, right ear %.3f at %d", le, lt, re, rt)
+	}
+
+	// A source in front reaches both ears alike
+	b.SetPosition(0, 0)
+	b.Reset()
+	b.ProcessBuffer(impulse, left, right)
+	for i := range left {
+		if math.Abs(float64(left[i]-right[i])) > 1e-6 {
+			t.Fatalf("Front source sample %d = %f, %f, want equal ears", i, left[i], right[i])
+		}
+	}
+}
+
+func TestBinauralCrossfade(t *testing.T) {
+	b := NewBinaural(SphericalHead(48000))
+	for i := 0; i < 1000; i++ {
+		b.Process(1)
+	}
+	b.SetPosition(-90, 0)
+	prevL, prevR := b.Process(1)
+	for i := 0; i < 1000; i++ {
+		l, r := b.Process(1)
+		if math.Abs(float64(l-prevL)) > 0.01 || math.Abs(float64(r-prevR)) > 0.01 {
+			t.Fatalf("Sample %d jumps from %f, %f to %f, %f", i, prevL, prevR, l, r)
+		}
+		prevL, prevR = l, r
+	}
+	if b.GetAzimuth() != -90 {
+		t.Errorf("Azimuth = %f, want -90", b.GetAzimuth())
+	}
+}
+
+func TestLoadSOFA(t *testing.T) {
+	set, err := LoadSOFA(&SOFAData{
+		SamplingRate:   44100,
+		SourcePosition: [][3]float64{{0, 0, 1.2}, {90, 0, 1.2}, {0, 90, 1.2}},
+		DataIR: [][2][]float64{
+			{{1}, {1}},
+			{{1, 0.5}, {0, 0.2}},
+			{{0.5}, {0.5}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Count() != 3 || set.Length() != 2 || set.SampleRate() != 44100 {
+		t.Errorf("Set = %d responses of %d samples at %f", set.Count(), set.Length(), set.SampleRate())
+	}
+	if h := set.Nearest(80, 10); h.Azimuth != 90 || len(h.Left) != 2 {
+		t.Errorf("Nearest to 80, 10 = %f, %f", h.Azimuth, h.Elevation)
+	}
+	if h := set.Nearest(30, 70); h.Elevation != 90 {
+		t.Errorf("Nearest to 30, 70 = %f, %f", h.Azimuth, h.Elevation)
+	}
+
+	_, err = LoadSOFA(&SOFAData{SamplingRate: 48000, SourcePosition: [][3]float64{{0, 0, 1}}})
+	if !errors.Is(err, ErrInvalidHRIR) {
+		t.Errorf("Mismatched data error = %v, want ErrInvalidHRIR", err)
+	}
+}
@@ -0,0 +1,118 @@
+package stereo
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/delay"
+)
+
+// Haas delay limits and default in seconds. Beyond about 40 ms the delayed
+// side is heard as an echo instead of a wider image.
+const (
+	MaxHaasDelay     = 0.04
+	DefaultHaasDelay = 0.015
+)
+
+// HaasChannel selects which channel a Haas widener delays
+type HaasChannel int
+
+const (
+	HaasRight HaasChannel = iota // Delay the right channel, image leans left
+	HaasLeft                     // Delay the left channel, image leans right
+)
+
+// Haas widens a signal with the precedence effect by delaying one channel a
+// few milliseconds. Mix blends the delayed channel with its dry signal; a
+// lower mix trades width for better mono compatibility, since the summed
+// channels comb filter less.
+type Haas struct {
+	sampleRate float64
+	channel    HaasChannel
+	delayTime  float64 // Seconds
+	mix        float32
+
+	line *delay.Line
+}
+
+// NewHaas creates a widener that fully delays the right channel by the
+// default time
+func NewHaas(sampleRate float64) *Haas {
+	return &Haas{
+		sampleRate: sampleRate,
+		channel:    HaasRight,
+		delayTime:  DefaultHaasDelay,
+		mix:        1,
+		line:       delay.New(MaxHaasDelay+2/sampleRate, sampleRate),
+	}
+}
+
+// SetDelay sets the delay time in seconds (0-0.04)
+func (h *Haas) SetDelay(seconds float64) {
+	h.delayTime = math.Max(0, math.Min(MaxHaasDelay, seconds))
+}
+
+// GetDelay returns the delay time in seconds
+func (h *Haas) GetDelay() float64 {
+	return h.delayTime
+}
+
+// SetChannel selects the delayed channel
+func (h *Haas) SetChannel(channel HaasChannel) {
+	if channel != h.channel {
+		h.channel = channel
+		h.line.Reset()
+	}
+}
+
+// GetChannel returns the delayed channel
+func (h *Haas) GetChannel() HaasChannel {
+	return h.channel
+}
+
+// SetMix sets how much of the delayed channel replaces its dry signal (0-1)
+func (h *Haas) SetMix(mix float32) {
+	h.mix = max(0, min(1, mix))
+}
+
+// GetMix returns the delayed channel mix
+func (h *Haas) GetMix() float32 {
+	return h.mix
+}
+
+// delayed writes a sample and reads it back delayed. Writing first makes a
+// read one sample back the current sample, so delays below one sample work.
+func (h *Haas) delayed(x float32) float32 {
+	h.line.Write(x)
+	return h.line.Read(h.delayTime*h.sampleRate + 1)
+}
+
+// Process processes one stereo sample pair
+func (h *Haas) Process(left, right float32) (float32, float32) {
+	if h.channel == HaasLeft {
+		left += (h.delayed(left) - left) * h.mix
+	} else {
+		right += (h.delayed(right) - right) * h.mix
+	}
+	return left, right
+}
+
+// ProcessStereo processes stereo buffers in place
+func (h *Haas) ProcessStereo(left, right []float32) {
+	length := min(len(left), len(right))
+	for i := 0; i < length; i++ {
+		left[i], right[i] = h.Process(left[i], right[i])
+	}
+}
+
+// ProcessMono widens a mono buffer into a stereo pair
+func (h *Haas) ProcessMono(mono, leftOut, rightOut []float32) {
+	length := min(len(mono), len(leftOut), len(rightOut))
+	for i := 0; i < length; i++ {
+		leftOut[i], rightOut[i] = h.Process(mono[i], mono[i])
+	}
+}
+
+// Reset clears the delay line
+func (h *Haas) Reset() {
+	h.line.Reset()
+}
@@ -0,0 +1,212 @@
+package stereo
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+// MonoMaker defaults
+const (
+	DefaultMonoFrequency = 120.0
+	DefaultMonoThreshold = 0.3
+)
+
+// MonoMaker time constants in seconds: the correlation window, how fast the
+// automatic safety narrows the bass and how slowly it lets go
+const (
+	monoWindowTime  = 0.1
+	monoAttackTime  = 0.01
+	monoReleaseTime = 0.3
+	monoSmoothTime  = 0.01
+)
+
+// MonoMaker collapses the stereo image toward mono below a crossover
+// frequency. Amount sets a fixed narrowing; with Auto enabled it also acts
+// as a safety limiter, measuring the correlation of the low band and
+// narrowing only as far as needed to hold it at the threshold.
+//
+// As in Width, only the side signal is split, with a 4th order
+// Linkwitz-Riley crossover, so the mid signal and therefore the mono sum
+// are never touched. The split runs at every setting, so moving Amount or
+// the automatic narrowing never switches the phase of the side signal.
+type MonoMaker struct {
+	sampleRate float64
+	frequency  float64
+	amount     float64
+	threshold  float64
+	auto       bool
+
+	// Lowpass on side channels 0 and 1, highpass on side channels 2 and 3,
+	// lowpass on mid channels 4 and 5 for the correlation measurement
+	split *filter.SVF
+
+	// Low band mid and side power and their cross term
+	midPower   float64
+	sidePower  float64
+	crossPower float64
+
+	autoGain  float64 // Side gain allowed by the safety
+	width     float64 // Smoothed low band width in use
+	minWidth  float64 // Narrowest width since the last GetMinWidth
+	window    float64
+	attack    float64
+	release   float64
+	smoothing float64
+}
+
+// NewMonoMaker creates a mono maker at the default frequency with no
+// narrowing and the safety off
+func NewMonoMaker(sampleRate float64) *MonoMaker {
+	m := &MonoMaker{
+		sampleRate: sampleRate,
+		threshold:  DefaultMonoThreshold,
+		split:      filter.NewSVF(6),
+		autoGain:   1,
+		width:      1,
+		minWidth:   1,
+		window:     monoCoeff(monoWindowTime, sampleRate),
+		attack:     monoCoeff(monoAttackTime, sampleRate),
+		release:    monoCoeff(monoReleaseTime, sampleRate),
+		smoothing:  monoCoeff(monoSmoothTime, sampleRate),
+	}
+	m.SetFrequency(DefaultMonoFrequency)
+	return m
+}
+
+// monoCoeff returns the one-pole coefficient for a time constant
+func monoCoeff(seconds, sampleRate float64) float64 {
+	return math.Exp(-1.0 / (seconds * sampleRate))
+}
+
+// SetFrequency sets the frequency below which the image is narrowed in Hz
+// (20-2000)
+func (m *MonoMaker) SetFrequency(hz float64) {
+	m.frequency = math.Max(MinCrossover, math.Min(MaxCrossover, hz))
+	m.split.SetFrequencyAndQ(m.sampleRate, m.frequency, math.Sqrt2/2)
+}
+
+// GetFrequency returns the crossover frequency in Hz
+func (m *MonoMaker) GetFrequency() float64 {
+	return m.frequency
+}
+
+// SetAmount sets the fixed narrowing of the low band: 0 leaves it
+// unchanged, 1 makes it mono
+func (m *MonoMaker) SetAmount(amount float64) {
+	m.amount = math.Max(0, math.Min(1, amount))
+}
+
+// GetAmount returns the fixed narrowing of the low band
+func (m *MonoMaker) GetAmount() float64 {
+	return m.amount
+}
+
+// SetAuto enables the correlation safety
+func (m *MonoMaker) SetAuto(auto bool) {
+	m.auto = auto
+	if !auto {
+		m.autoGain = 1
+	}
+}
+
+// IsAuto returns whether the correlation safety is enabled
+func (m *MonoMaker) IsAuto() bool {
+	return m.auto
+}
+
+// SetThreshold sets the lowest low band correlation the safety allows (0-1)
+func (m *MonoMaker) SetThreshold(correlation float64) {
+	m.threshold = math.Max(0, math.Min(1, correlation))
+}
+
+// GetThreshold returns the correlation threshold of the safety
+func (m *MonoMaker) GetThreshold() float64 {
+	return m.threshold
+}
+
+// GetCorrelation returns the correlation of the low band at the input,
+// from -1 (out of phase) to 1 (mono)
+func (m *MonoMaker) GetCorrelation() float64 {
+	sum := m.midPower + m.sidePower
+	denom := sum*sum - 4*m.crossPower*m.crossPower
+	if denom <= 1e-20 {
+		return 1
+	}
+	return math.Max(-1, math.Min(1, (m.midPower-m.sidePower)/math.Sqrt(denom)))
+}
+
+// GetWidth returns the low band width in use: 1 is unchanged, 0 is mono
+func (m *MonoMaker) GetWidth() float64 {
+	return m.width
+}
+
+// GetMinWidth returns the narrowest low band width since the last call and
+// resets it, for metering
+func (m *MonoMaker) GetMinWidth() float64 {
+	w := m.minWidth
+	m.minWidth = m.width
+	return w
+}
+
+// safetyGain returns the side gain that holds the low band correlation at
+// the threshold. With output mid power M and side power g²S the correlation
+// is at least (M - g²S)/(M + g²S), which the cross term only raises for
+// positive values, so solving that for g keeps the output at or above the
+// threshold.
+func (m *MonoMaker) safetyGain() float64 {
+	if m.sidePower <= 1e-20 {
+		return 1
+	}
+	g2 := m.midPower * (1 - m.threshold) / (m.sidePower * (1 + m.threshold))
+	if g2 >= 1 {
+		return 1
+	}
+	return math.Sqrt(g2)
+}
+
+// Process processes one stereo sample pair
+func (m *MonoMaker) Process(left, right float32) (float32, float32) {
+	mid, side := Encode(left, right)
+
+	low := m.split.ProcessSample(m.split.ProcessSample(side, 0).Lowpass, 1).Lowpass
+	high := m.split.ProcessSample(m.split.ProcessSample(side, 2).Highpass, 3).Highpass
+	midLow := float64(m.split.ProcessSample(m.split.ProcessSample(mid, 4).Lowpass, 5).Lowpass)
+
+	sideLow := float64(low)
+	m.midPower = midLow*midLow + m.window*(m.midPower-midLow*midLow)
+	m.sidePower = sideLow*sideLow + m.window*(m.sidePower-sideLow*sideLow)
+	m.crossPower = midLow*sideLow + m.window*(m.crossPower-midLow*sideLow)
+
+	target := 1 - m.amount
+	if m.auto {
+		gain := m.safetyGain()
+		coeff := m.release
+		if gain < m.autoGain {
+			coeff = m.attack
+		}
+		m.autoGain = gain + coeff*(m.autoGain-gain)
+		target = math.Min(target, m.autoGain)
+	}
+	m.width = target + m.smoothing*(m.width-target)
+	m.minWidth = math.Min(m.minWidth, m.width)
+
+	return Decode(mid, low*float32(m.width)+high)
+}
+
+// ProcessStereo processes stereo buffers in place
+func (m *MonoMaker) ProcessStereo(left, right []float32) {
+	length := min(len(left), len(right))
+	for i := 0; i < length; i++ {
+		left[i], right[i] = m.Process(left[i], right[i])
+	}
+}
+
+// Reset clears the crossover and measurement state
+func (m *MonoMaker) Reset() {
+	m.split.Reset()
+	m.midPower, m.sidePower, m.crossPower = 0, 0, 0
+	m.autoGain = 1
+	m.width = 1 - m.amount
+	m.minWidth = m.width
+}
@@ -0,0 +1,109 @@
+package stereo
+
+import (
+	"math"
+	"testing"
+)
+
+// runMonoMaker feeds a sine pair with the given right channel gain and phase
+// offset and returns the correlation of the output over the second half
+func runMonoMaker(m *MonoMaker, freq, rightGain, offset float64) float64 {
+	const sampleRate = 48000.0
+	n := 48000
+	var ll, rr, lr float64
+	for i := 0; i < n; i++ {
+		phase := 2 * math.Pi * freq * float64(i) / sampleRate
+		l, r := m.Process(float32(math.Sin(phase)), float32(rightGain*math.Sin(phase+offset)))
+		if i >= n/2 {
+			ll += float64(l * l)
+			rr += float64(r * r)
+			lr += float64(l * r)
+		}
+	}
+	return lr / math.Sqrt(ll*rr)
+}
+
+func TestMonoMakerAmount(t *testing.T) {
+	reference := sidePower(NewWidth(48000), 1000)
+
+	m := NewMonoMaker(48000)
+	m.SetFrequency(200)
+	m.SetAmount(1)
+
+	power := func(freq float64) float64 {
+		m.Reset()
+		total := 0.0
+		n := 48000
+		for i := 0; i < n; i++ {
+			phase := 2 * math.Pi * freq * float64(i) / 48000
+			l, r := m.Process(float32(math.Sin(phase)), float32(math.Sin(phase+math.Pi/2)))
+			if i >= n/2 {
+				_, side := Encode(l, r)
+				total += float64(side * side)
+			}
+		}
+		return total / float64(n/2)
+	}
+	if got := power(30) / reference; got > 0.01 {
+		t.Errorf("Side power at 30 Hz = %.3f of the input, want it removed", got)
+	}
+	if got := power(5000) / reference; math.Abs(got-1) > 0.05 {
+		t.Errorf("Side power at 5 kHz = %.3f of the input, want it unchanged", got)
+	}
+	if m.GetWidth() > 0.01 {
+		t.Errorf("Width = %f, want 0", m.GetWidth())
+	}
+}
+
+func TestMonoMakerMonoSum(t *testing.T) {
+	m := NewMonoMaker(48000)
+	m.SetAuto(true)
+	m.SetAmount(0.5)
+	for i := 0; i < 4800; i++ {
+		l := float32(math.Sin(float64(i) * 0.01))
+		r := float32(math.Sin(float64(i)*0.013 + 1))
+		outL, outR := m.Process(l, r)
+		if math.Abs(float64((outL+outR)-(l+r))) > 1e-5 {
+			t.Fatalf("Sample %d: mono sum %f, want %f", i, outL+outR, l+r)
+		}
+	}
+}
+
+func TestMonoMakerAuto(t *testing.T) {
+	// Nearly anti-phase bass is pulled up to the threshold
+	m := NewMonoMaker(48000)
+	m.SetAuto(true)
+	m.SetThreshold(0.5)
+	in := runMonoMaker(NewMonoMaker(48000), 50, 0.8, math.Pi*0.8)
+	if in > 0 {
+		t.Fatalf("Input correlation = %f, want it negative", in)
+	}
+	if got := runMonoMaker(m, 50, 0.8, math.Pi*0.8); got < 0.45 {
+		t.Errorf("Output correlation = %f, want at least the 0.5 threshold", got)
+	}
+	if m.GetCorrelation() > 0 {
+		t.Errorf("Measured input correlation = %f, want it negative", m.GetCorrelation())
+	}
+	if m.GetMinWidth() >= 1 {
+		t.Error("Safety should have narrowed the bass")
+	}
+
+	// A correlated low end is left alone
+	m.Reset()
+	runMonoMaker(m, 50, 0.8, 0.3)
+	if m.GetWidth() < 0.99 {
+		t.Errorf("Width = %f for correlated bass, want 1", m.GetWidth())
+	}
+
+	// Highs above the crossover never trigger the safety
+	m.Reset()
+	if got := runMonoMaker(m, 5000, 1, math.Pi); got > -0.95 {
+		t.Errorf("Anti-phase highs correlation = %f, want them untouched", got)
+	}
+
+	m.SetAuto(false)
+	m.Reset()
+	if got := runMonoMaker(m, 50, 0.8, math.Pi*0.8); got > 0 {
+		t.Errorf("Correlation with the safety off = %f, want it unchanged", got)
+	}
+}
@@ -0,0 +1,85 @@
+// Package stereo provides stereo imaging operations: mid/side encoding,
+// frequency-dependent width, a mono maker for the low end, rotation,
+// balance and a Haas widener.
+package stereo
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+)
+
+// Encode converts a left/right sample pair to mid/side.
+// mid = (L+R)/2, side = (L-R)/2, so Decode restores the input exactly.
+func Encode(left, right float32) (mid, side float32) {
+	return (left + right) * 0.5, (left - right) * 0.5
+}
+
+// Decode converts a mid/side sample pair back to left/right
+func Decode(mid, side float32) (left, right float32) {
+	return mid + side, mid - side
+}
+
+// EncodeBuffer converts left/right buffers to mid/side. The outputs may be
+// the input buffers for in-place conversion.
+func EncodeBuffer(left, right, mid, side []float32) {
+	length := min(len(left), len(right), len(mid), len(side))
+	for i := 0; i < length; i++ {
+		mid[i], side[i] = Encode(left[i], right[i])
+	}
+}
+
+// DecodeBuffer converts mid/side buffers to left/right. The outputs may be
+// the input buffers for in-place conversion.
+func DecodeBuffer(mid, side, left, right []float32) {
+	length := min(len(mid), len(side), len(left), len(right))
+	for i := 0; i < length; i++ {
+		left[i], right[i] = Decode(mid[i], side[i])
+	}
+}
+
+// Rotate turns the stereo image by an angle in degrees. Positive angles move
+// the image toward the right: a center source at 45 degrees lands hard
+// right, and at 90 degrees left and right trade places with the left
+// polarity inverted. The outputs may be the inputs.
+func Rotate(leftIn, rightIn []float32, degrees float64, leftOut, rightOut []float32) {
+	sin, cos := math.Sincos(degrees * math.Pi / 180.0)
+	s, c := float32(sin), float32(cos)
+	length := min(len(leftIn), len(rightIn), len(leftOut), len(rightOut))
+	for i := 0; i < length; i++ {
+		l, r := leftIn[i], rightIn[i]
+		leftOut[i] = l*c - r*s
+		rightOut[i] = l*s + r*c
+	}
+}
+
+// Balance shifts the image toward one side while keeping the near channel
+// at unity. The far channel follows the pan law relative to its center
+// gain, so a half balance is a smooth step toward silence rather than the
+// linear fade of pan.Balance.
+// balance: -1.0 = left only, 0.0 = centered, 1.0 = right only
+func Balance(leftIn, rightIn []float32, balance float32, law pan.Law, leftOut, rightOut []float32) {
+	leftGain, rightGain := BalanceGains(balance, law)
+	length := min(len(leftIn), len(rightIn), len(leftOut), len(rightOut))
+	for i := 0; i < length; i++ {
+		leftOut[i] = leftIn[i] * leftGain
+		rightOut[i] = rightIn[i] * rightGain
+	}
+}
+
+// BalanceGains returns the channel gains Balance applies
+func BalanceGains(balance float32, law pan.Law) (left, right float32) {
+	balance = max(-1.0, min(1.0, balance))
+	left, right = 1, 1
+	if balance == 0 {
+		return
+	}
+	l, r := pan.MonoToStereo(balance, law)
+	cl, cr := pan.MonoToStereo(0, law)
+	if balance > 0 {
+		left = max(0, l/cl)
+	} else {
+		right = max(0, r/cr)
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package stereo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+)
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestMidSideRoundTrip(t *testing.T) {
+	left := []float32{1, 0.5, -0.25, 0}
+	right := []float32{0, 0.5, 0.75, -1}
+	wantLeft := append([]float32(nil), left...)
+	wantRight := append([]float32(nil), right...)
+
+	// In place: left becomes mid and right becomes side
+	EncodeBuffer(left, right, left, right)
+	if left[0] != 0.5 || right[0] != 0.5 || right[1] != 0 {
+		t.Errorf("Encode = %v, %v", left, right)
+	}
+	DecodeBuffer(left, right, left, right)
+	for i := range left {
+		if !near(left[i], wantLeft[i]) || !near(right[i], wantRight[i]) {
+			t.Errorf("Sample %d = %f, %f, want %f, %f", i, left[i], right[i], wantLeft[i], wantRight[i])
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	// A center source rotated 45 degrees lands hard right
+	left, right := []float32{1}, []float32{1}
+	Rotate(left, right, 45, left, right)
+	if !near(left[0], 0) || !near(right[0], float32(math.Sqrt2)) {
+		t.Errorf("Rotated center = %f, %f, want 0, %f", left[0], right[0], math.Sqrt2)
+	}
+
+	// Rotation keeps the total power
+	left, right = []float32{0.3}, []float32{-0.8}
+	Rotate(left, right, -30, left, right)
+	if !near(left[0]*left[0]+right[0]*right[0], 0.73) {
+		t.Errorf("Power after rotation = %f, want 0.73", left[0]*left[0]+right[0]*right[0])
+	}
+}
+
+func TestBalance(t *testing.T) {
+	left, right := []float32{1}, []float32{1}
+	Balance(left, right, 1, pan.ConstantPower, left, right)
+	if !near(left[0], 0) || right[0] != 1 {
+		t.Errorf("Hard right balance = %f, %f, want 0, 1", left[0], right[0])
+	}
+
+	// The near side stays at unity
+	l, r := BalanceGains(-0.5, pan.ConstantPower)
+	if l != 1 || r <= 0 || r >= 1 {
+		t.Errorf("Half left gains = %f, %f", l, r)
+	}
+}
+
+// sidePower measures the side signal power of a stereo sine pair with the
+// given phase offset between the channels, after the width control
+func sidePower(w *Width, freq float64) float64 {
+	const sampleRate = 48000.0
+	n := 48000
+	power := 0.0
+	for i := 0; i < n; i++ {
+		phase := 2 * math.Pi * freq * float64(i) / sampleRate
+		l, r := w.Process(float32(math.Sin(phase)), float32(math.Sin(phase+math.Pi/2)))
+		if i >= n/2 {
+			_, side := Encode(l, r)
+			power += float64(side * side)
+		}
+	}
+	return power / float64(n/2)
+}
+
+func TestWidthBands(t *testing.T) {
+	reference := sidePower(NewWidth(48000), 1000)
+
+	// Mono bass with doubled highs
+	w := NewWidth(48000)
+	w.SetCrossover(200)
+	w.SetLowWidth(0)
+	w.SetHighWidth(2)
+	if got := sidePower(w, 30) / reference; got > 0.01 {
+		t.Errorf("Side power at 30 Hz = %.3f of the input, want it removed", got)
+	}
+	w.Reset()
+	if got := sidePower(w, 5000) / reference; math.Abs(got-4) > 0.1 {
+		t.Errorf("Side power at 5 kHz = %.3f of the input, want 4", got)
+	}
+
+	// Equal widths are a plain width control
+	w.SetWidth(0.5)
+	left, right := []float32{1, 0.2}, []float32{0, 0.6}
+	w.ProcessStereo(left, right)
+	if !near(left[0], 0.75) || !near(right[0], 0.25) {
+		t.Errorf("Half width = %f, %f, want 0.75, 0.25", left[0], right[0])
+	}
+	if w.GetHighWidth() != 0.5 || w.GetLowWidth() != 0.5 {
+		t.Error("SetWidth should set both bands")
+	}
+	w.SetCrossover(1)
+	if w.GetCrossover() != MinCrossover {
+		t.Errorf("Crossover = %f, want it clamped to %f", w.GetCrossover(), MinCrossover)
+	}
+}
+
+func TestHaas(t *testing.T) {
+	h := NewHaas(48000)
+	h.SetDelay(0.01)
+	n := 1000
+	left, right := make([]float32, n), make([]float32, n)
+	left[0], right[0] = 1, 1
+	h.ProcessStereo(left, right)
+	if left[0] != 1 {
+		t.Errorf("Left = %f, want the undelayed impulse", left[0])
+	}
+	for i, v := range right {
+		if (i == 480) != (v != 0) {
+			t.Fatalf("Right %d = %f, want the impulse only at 480", i, v)
+		}
+	}
+
+	// No delay passes audio through, and half mix blends in the dry signal
+	h.SetChannel(HaasLeft)
+	h.SetDelay(0)
+	h.SetMix(0.5)
+	mono := []float32{0.5, 0.25}
+	h.ProcessMono(mono, left[:2], right[:2])
+	if !near(left[0], 0.5) || !near(left[1], 0.25) || right[0] != 0.5 {
+		t.Errorf("Zero delay = %v, %v, want the input", left[:2], right[:2])
+	}
+}
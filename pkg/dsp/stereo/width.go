@@ -0,0 +1,115 @@
+package stereo
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+)
+
+// Width limits; 1 leaves the stereo image unchanged
+const (
+	MinWidth = 0.0
+	MaxWidth = 2.0
+)
+
+// Crossover limits and default for Width in Hz
+const (
+	MinCrossover     = 20.0
+	MaxCrossover     = 2000.0
+	DefaultCrossover = 150.0
+)
+
+// Width scales the side signal separately below and above a crossover
+// frequency. The usual setting narrows the low end toward mono, keeping
+// bass centered and mono compatible, while widening the top.
+//
+// The side signal is split with a 4th order Linkwitz-Riley crossover, whose
+// bands sum flat with an allpass phase shift; the mid signal is untouched.
+// Equal low and high widths bypass the crossover and act as a plain width
+// control.
+type Width struct {
+	sampleRate float64
+	lowWidth   float32
+	highWidth  float32
+	crossover  float64
+
+	// Two cascaded Butterworth sections per band: lowpass on channels 0
+	// and 1, highpass on channels 2 and 3
+	split *filter.SVF
+}
+
+// NewWidth creates a width control that leaves the image unchanged
+func NewWidth(sampleRate float64) *Width {
+	w := &Width{
+		sampleRate: sampleRate,
+		lowWidth:   1,
+		highWidth:  1,
+		split:      filter.NewSVF(4),
+	}
+	w.SetCrossover(DefaultCrossover)
+	return w
+}
+
+// SetWidth sets the width of both bands: 0 is mono, 1 unchanged, 2 doubles
+// the side signal
+func (w *Width) SetWidth(width float64) {
+	w.SetLowWidth(width)
+	w.SetHighWidth(width)
+}
+
+// SetLowWidth sets the width below the crossover (0-2)
+func (w *Width) SetLowWidth(width float64) {
+	w.lowWidth = float32(math.Max(MinWidth, math.Min(MaxWidth, width)))
+}
+
+// GetLowWidth returns the width below the crossover
+func (w *Width) GetLowWidth() float64 {
+	return float64(w.lowWidth)
+}
+
+// SetHighWidth sets the width above the crossover (0-2)
+func (w *Width) SetHighWidth(width float64) {
+	w.highWidth = float32(math.Max(MinWidth, math.Min(MaxWidth, width)))
+}
+
+// GetHighWidth returns the width above the crossover
+func (w *Width) GetHighWidth() float64 {
+	return float64(w.highWidth)
+}
+
+// SetCrossover sets the split frequency in Hz (20-2000)
+func (w *Width) SetCrossover(hz float64) {
+	w.crossover = math.Max(MinCrossover, math.Min(MaxCrossover, hz))
+	w.split.SetFrequencyAndQ(w.sampleRate, w.crossover, math.Sqrt2/2)
+}
+
+// GetCrossover returns the split frequency in Hz
+func (w *Width) GetCrossover() float64 {
+	return w.crossover
+}
+
+// Process processes one stereo sample pair
+func (w *Width) Process(left, right float32) (float32, float32) {
+	mid, side := Encode(left, right)
+
+	// The crossover runs even when bypassed so a later split starts settled
+	low := w.split.ProcessSample(w.split.ProcessSample(side, 0).Lowpass, 1).Lowpass
+	high := w.split.ProcessSample(w.split.ProcessSample(side, 2).Highpass, 3).Highpass
+	if w.lowWidth == w.highWidth {
+		return Decode(mid, side*w.highWidth)
+	}
+	return Decode(mid, low*w.lowWidth+high*w.highWidth)
+}
+
+// ProcessStereo processes stereo buffers in place
+func (w *Width) ProcessStereo(left, right []float32) {
+	length := min(len(left), len(right))
+	for i := 0; i < length; i++ {
+		left[i], right[i] = w.Process(left[i], right[i])
+	}
+}
+
+// Reset clears the crossover state
+func (w *Width) Reset() {
+	w.split.Reset()
+}
@@ -0,0 +1,481 @@
+// Package stretch provides offline-quality time stretching. It works on
+// whole buffers rather than blocks, so it belongs in offline renders (see
+// process.Context.IsOffline and the host package) rather than in a
+// realtime process call.
+package stretch
+
+import (
+	"math"
+	"sort"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis"
+)
+
+// Stretch ratio limits
+const (
+	MinRatio = 0.25
+	MaxRatio = 4.0
+)
+
+// DefaultTransientThreshold is the normalized spectral flux above which a
+// frame counts as a transient
+const DefaultTransientThreshold = 0.25
+
+// Stretcher is a phase vocoder that changes the length of audio without
+// changing its pitch. It uses identity phase locking (Laroche and Dolson):
+// only spectral peaks have their phase advanced, and the bins around each
+// peak keep their phase relative to it, which avoids the "phasiness" of a
+// plain vocoder. Transients are played at their original speed from a
+// phase reset, so attacks stay sharp instead of smearing over a window, and
+// the stretch is made up between them.
+type Stretcher struct {
+	sampleRate float64
+	fftSize    int
+	hop        int // Synthesis hop, a quarter of the FFT size
+	ratio      float64
+
+	transients bool
+	threshold  float64
+
+	fft    *analysis.FFT
+	frame  []float64 // Analysis scratch
+	window []float64 // Periodic Hann, used for analysis and synthesis
+	norm   float64   // Overlap-add gain of the squared window
+}
+
+// NewStretcher creates a stretcher with a ratio of 1 and an FFT of about
+// 40 ms at the given sample rate
+func NewStretcher(sampleRate float64) *Stretcher {
+	size := 512
+	for float64(size) < sampleRate*0.04 {
+		size <<= 1
+	}
+
+	s := &Stretcher{
+		sampleRate: sampleRate,
+		fftSize:    size,
+		hop:        size / 4,
+		ratio:      1,
+		transients: true,
+		threshold:  DefaultTransientThreshold,
+		fft:        analysis.NewFFT(size, analysis.RectangularWindow),
+		frame:      make([]float64, size),
+		window:     make([]float64, size),
+	}
+	sum := 0.0
+	for i := range s.window {
+		s.window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size))
+		sum += s.window[i] * s.window[i]
+	}
+	s.norm = sum / float64(s.hop)
+	return s
+}
+
+// SetRatio sets the output length as a multiple of the input length;
+// 2 plays twice as long, 0.5 twice as fast
+func (s *Stretcher) SetRatio(ratio float64) {
+	s.ratio = math.Max(MinRatio, math.Min(MaxRatio, ratio))
+}
+
+// GetRatio returns the stretch ratio
+func (s *Stretcher) GetRatio() float64 {
+	return s.ratio
+}
+
+// SetTransientPreservation enables the transient handling
+func (s *Stretcher) SetTransientPreservation(enabled bool) {
+	s.transients = enabled
+}
+
+// IsTransientPreserving reports whether transients are preserved
+func (s *Stretcher) IsTransientPreserving() bool {
+	return s.transients
+}
+
+// SetTransientThreshold sets the normalized spectral flux, from 0.01 to 1,
+// a frame needs to count as a transient. Lower values catch softer attacks.
+func (s *Stretcher) SetTransientThreshold(threshold float64) {
+	s.threshold = math.Max(0.01, math.Min(1.0, threshold))
+}
+
+// GetTransientThreshold returns the transient threshold
+func (s *Stretcher) GetTransientThreshold() float64 {
+	return s.threshold
+}
+
+// FFTSize returns the analysis frame length in samples
+func (s *Stretcher) FFTSize() int {
+	return s.fftSize
+}
+
+// OutputLength returns the length Stretch produces for n input samples
+func (s *Stretcher) OutputLength(n int) int {
+	return int(math.Round(float64(n) * s.ratio))
+}
+
+// Stretch time-stretches one channel
+func (s *Stretcher) Stretch(input []float32) []float32 {
+	return s.StretchChannels([][]float32{input})[0]
+}
+
+// StretchChannels time-stretches several channels of equal length. The
+// channels share their transient decisions so a stereo image does not
+// shift at attacks.
+func (s *Stretcher) StretchChannels(inputs [][]float32) [][]float32 {
+	length := 0
+	for _, in := range inputs {
+		length = max(length, len(in))
+	}
+	outLength := s.OutputLength(length)
+	outputs := make([][]float32, len(inputs))
+	for ch := range outputs {
+		outputs[ch] = make([]float32, outLength)
+	}
+	if length == 0 {
+		return outputs
+	}
+
+	// Frames are centered on multiples of the synthesis hop in the output,
+	// starting early enough that the first samples get a full overlap, and
+	// on the matching positions of the time map in the input
+	lead := s.fftSize / (2 * s.hop)
+	frames := (outLength+s.fftSize/2)/s.hop + lead + 1
+	tm := timeMap{anchors: []anchor{{0, 0}, {length, outLength}}, ratio: s.ratio}
+	if s.transients {
+		tm = s.transientMap(inputs, length, outLength)
+	}
+	positions := make([]int, frames)
+	reset := make([]bool, frames)
+	reset[0] = true
+	for m := range positions {
+		positions[m] = tm.input((m - lead) * s.hop)
+	}
+	for z := 1; z+1 < len(tm.anchors); z += 2 {
+		if m := ceilDiv(tm.anchors[z].out, s.hop) + lead; m < frames {
+			reset[m] = true
+		}
+	}
+
+	bins := s.fftSize/2 + 1
+	states := make([]channelState, len(inputs))
+	for ch := range states {
+		states[ch] = newChannelState(bins, frames*s.hop+s.fftSize)
+	}
+	re := make([]float64, s.fftSize)
+	im := make([]float64, s.fftSize)
+
+	for m, pos := range positions {
+		analysisHop := 0.0
+		if m > 0 {
+			analysisHop = float64(pos - positions[m-1])
+		}
+
+		for ch, in := range inputs {
+			st := &states[ch]
+			mag, phase := s.analyze(in, pos)
+			if reset[m] || analysisHop == 0 {
+				copy(st.synth, phase)
+			} else {
+				s.lockPhases(st, mag, phase, analysisHop)
+			}
+			copy(st.prevPhase, phase)
+
+			// Back to the time domain with the new phases
+			for k := 0; k < bins; k++ {
+				re[k] = mag[k] * math.Cos(st.synth[k])
+				im[k] = mag[k] * math.Sin(st.synth[k])
+			}
+			for k := bins; k < s.fftSize; k++ {
+				re[k] = re[s.fftSize-k]
+				im[k] = -im[s.fftSize-k]
+			}
+			frame := s.fft.Inverse(re, im)
+
+			// Undo the analysis rotation and overlap-add
+			start := m * s.hop
+			half := s.fftSize / 2
+			for i := range frame {
+				st.accum[start+i] += frame[(i+half)%s.fftSize] * s.window[i]
+			}
+		}
+	}
+
+	offset := s.fftSize/2 + lead*s.hop
+	for ch, st := range states {
+		out := outputs[ch]
+		for i := range out {
+			out[i] = float32(st.accum[offset+i] / s.norm)
+		}
+	}
+	return outputs
+}
+
+// channelState holds the vocoder state of one channel
+type channelState struct {
+	prevPhase []float64 // Analysis phases of the previous frame
+	synth     []float64 // Synthesis phases of the current frame
+	peaks     []int
+	accum     []float64 // Overlap-add output, shifted by the frames before zero
+}
+
+// newChannelState allocates the state for a channel
+func newChannelState(bins, length int) channelState {
+	return channelState{
+		prevPhase: make([]float64, bins),
+		synth:     make([]float64, bins),
+		peaks:     make([]int, 0, bins/2),
+		accum:     make([]float64, length),
+	}
+}
+
+// analyze returns the magnitude and phase of the windowed frame centered on
+// pos. Samples outside the input are zero. The slices belong to the FFT and
+// are only valid until the next call.
+func (s *Stretcher) analyze(in []float32, pos int) (mag, phase []float64) {
+	frame := s.frame
+	clear(frame)
+	start := pos - s.fftSize/2
+	for i := range frame {
+		if j := start + i; j >= 0 && j < len(in) {
+			frame[i] = float64(in[j]) * s.window[i]
+		}
+	}
+
+	// Rotate so the frame center sits at time zero, which keeps the
+	// analysis phases of a steady sinusoid independent of the window
+	half := s.fftSize / 2
+	for i := 0; i < half; i++ {
+		frame[i], frame[i+half] = frame[i+half], frame[i]
+	}
+	mag, phase = s.fft.Forward(frame)
+	return mag, phase
+}
+
+// lockPhases advances the phase of each spectral peak by its instantaneous
+// frequency over one synthesis hop and locks the bins in the peak's region
+// of influence to it
+func (s *Stretcher) lockPhases(st *channelState, mag, phase []float64, analysisHop float64) {
+	bins := len(mag)
+	synthesisHop := float64(s.hop)
+	st.peaks = findPeaks(mag, st.peaks[:0])
+
+	advance := func(k int) float64 {
+		omega := 2 * math.Pi * float64(k) / float64(s.fftSize)
+		delta := princarg(phase[k] - st.prevPhase[k] - omega*analysisHop)
+		return st.synth[k] + (omega+delta/analysisHop)*synthesisHop
+	}
+
+	// Without peaks (silence, pure noise floor) every bin runs freely
+	if len(st.peaks) == 0 {
+		for k := 0; k < bins; k++ {
+			st.synth[k] = advance(k)
+		}
+		return
+	}
+
+	// Each peak owns the bins up to the magnitude minimum between it and
+	// the next peak
+	start := 0
+	for i, p := range st.peaks {
+		end := bins
+		if i+1 < len(st.peaks) {
+			next := st.peaks[i+1]
+			end = p + 1
+			for k := p + 1; k < next; k++ {
+				if mag[k] < mag[end] {
+					end = k
+				}
+			}
+			end++
+		}
+
+		peakPhase := advance(p)
+		rotation := peakPhase - phase[p]
+		for k := start; k < end; k++ {
+			st.synth[k] = phase[k] + rotation
+		}
+		st.synth[p] = peakPhase
+		start = end
+	}
+}
+
+// findPeaks appends the bins larger than their two neighbors on each side
+// and above the noise floor of the frame
+func findPeaks(mag []float64, peaks []int) []int {
+	maxMag := 0.0
+	for _, m := range mag {
+		maxMag = math.Max(maxMag, m)
+	}
+	floor := maxMag * 1e-5
+	if maxMag == 0 {
+		return peaks
+	}
+
+	for k, m := range mag {
+		if m <= floor {
+			continue
+		}
+		// Strictly above the lower neighbors so a plateau counts once
+		isPeak := true
+		for d := -2; d <= 2 && isPeak; d++ {
+			j := k + d
+			if d == 0 || j < 0 || j >= len(mag) {
+				continue
+			}
+			isPeak = mag[j] < m || (d > 0 && mag[j] == m)
+		}
+		if isPeak {
+			peaks = append(peaks, k)
+		}
+	}
+	return peaks
+}
+
+// anchor ties an input position to an output position
+type anchor struct {
+	in, out int
+}
+
+// timeMap maps output positions to input positions, piecewise linear
+// between anchors and at the stretch ratio outside them
+type timeMap struct {
+	anchors []anchor // Sorted; the first is {0, 0}, the last the buffer ends
+	ratio   float64
+}
+
+// input returns the input position for an output position
+func (t timeMap) input(out int) int {
+	first, last := t.anchors[0], t.anchors[len(t.anchors)-1]
+	if out <= first.out {
+		return int(math.Round(float64(out) / t.ratio))
+	}
+	if out >= last.out {
+		return last.in + int(math.Round(float64(out-last.out)/t.ratio))
+	}
+	i := sort.Search(len(t.anchors), func(i int) bool { return t.anchors[i].out > out }) - 1
+	a, b := t.anchors[i], t.anchors[i+1]
+	return a.in + int(math.Round(float64(out-a.out)*float64(b.in-a.in)/float64(b.out-a.out)))
+}
+
+// transientMap builds a time map that plays the region around each
+// transient at its original speed, from half a frame before the onset to a
+// frame after it, and makes up the stretch between transients. With equal
+// hops and a phase reset at the start of the region the vocoder
+// reconstructs the attack exactly, without the pre-echo of frames that
+// would otherwise smear it. Onsets keep their stretched time.
+func (s *Stretcher) transientMap(inputs [][]float32, length, outLength int) timeMap {
+	tm := timeMap{anchors: []anchor{{0, 0}}, ratio: s.ratio}
+	before, after := s.fftSize/2, s.fftSize
+	for _, onset := range s.detectOnsets(inputs, length) {
+		out := int(math.Round(float64(onset) * s.ratio))
+		start := anchor{onset - before, out - before}
+		end := anchor{onset + after, out + after}
+		if s.fitsGap(tm.anchors[len(tm.anchors)-1], start) && s.fitsGap(end, anchor{length, outLength}) {
+			tm.anchors = append(tm.anchors, start, end)
+		}
+	}
+	tm.anchors = append(tm.anchors, anchor{length, outLength})
+	return tm
+}
+
+// fitsGap reports whether the stretch between two anchors is at least a
+// hop long and within twice the ratio limits
+func (s *Stretcher) fitsGap(a, b anchor) bool {
+	in, out := b.in-a.in, b.out-a.out
+	if in < s.hop || out < s.hop {
+		return false
+	}
+	ratio := float64(out) / float64(in)
+	return ratio >= MinRatio/2 && ratio <= MaxRatio*2
+}
+
+// detectOnsets returns the input positions of transients, in order. A
+// transient is a peak of the spectral flux above the threshold, summed over
+// channels and normalized by the frame's magnitude, refined to the block of
+// samples where the energy rises most.
+func (s *Stretcher) detectOnsets(inputs [][]float32, length int) []int {
+	bins := s.fftSize/2 + 1
+	prev := make([][]float64, len(inputs))
+	for ch := range prev {
+		prev[ch] = make([]float64, bins)
+	}
+
+	hop := s.hop / 2
+	flux := make([]float64, length/hop+1)
+	for m := range flux {
+		rise, total := 0.0, 0.0
+		for ch, in := range inputs {
+			mag, _ := s.analyze(in, m*hop)
+			for k, v := range mag {
+				rise += math.Max(0, v-prev[ch][k])
+				total += v
+			}
+			copy(prev[ch], mag)
+		}
+		if total > 0 {
+			flux[m] = rise / total
+		}
+	}
+
+	var onsets []int
+	for m, f := range flux {
+		if f < s.threshold {
+			continue
+		}
+		if (m == 0 || f >= flux[m-1]) && (m+1 == len(flux) || f > flux[m+1]) {
+			onsets = append(onsets, refineOnset(inputs, m*hop, s.fftSize))
+		}
+	}
+	return onsets
+}
+
+// onsetBlock is the resolution of refineOnset in samples
+const onsetBlock = 64
+
+// refineOnset finds the block with the largest energy rise within the
+// frame of the given size centered on pos, then the first sample around it
+// that reaches half the block's peak
+func refineOnset(inputs [][]float32, pos, size int) int {
+	best, bestRise, prevEnergy := pos, 0.0, 0.0
+	for start := pos - size/2; start < pos+size/2; start += onsetBlock {
+		energy := 0.0
+		for _, in := range inputs {
+			for i := max(start, 0); i < min(start+onsetBlock, len(in)); i++ {
+				energy += float64(in[i]) * float64(in[i])
+			}
+		}
+		if rise := energy - prevEnergy; rise > bestRise {
+			best, bestRise = start, rise
+		}
+		prevEnergy = energy
+	}
+
+	from, to := max(best-onsetBlock, 0), best+onsetBlock
+	peak := 0.0
+	for _, in := range inputs {
+		for i := from; i < min(to, len(in)); i++ {
+			peak = math.Max(peak, math.Abs(float64(in[i])))
+		}
+	}
+	for i := from; i < to; i++ {
+		for _, in := range inputs {
+			if i < len(in) && math.Abs(float64(in[i])) >= peak/2 {
+				return i
+			}
+		}
+	}
+	return max(best, 0)
+}
+
+// ceilDiv returns a/b rounded up for positive b
+func ceilDiv(a, b int) int {
+	if a <= 0 {
+		return -(-a / b)
+	}
+	return (a + b - 1) / b
+}
+
+// princarg wraps a phase to [-π, π)
+func princarg(phase float64) float64 {
+	return phase - 2*math.Pi*math.Floor((phase+math.Pi)/(2*math.Pi))
+}
@@ -0,0 +1,164 @@
+package stretch
+
+import (
+	"math"
+	"testing"
+)
+
+func sine(freq, sampleRate float64, n int) []float32 {
+	out := make([]float32, n)
+	for i := range out {
+		out[i] = float32(0.5 * math.Sin(2*math.Pi*freq*float64(i)/sampleRate))
+	}
+	return out
+}
+
+func TestStretcherLength(t *testing.T) {
+	s := NewStretcher(48000)
+	if s.FFTSize() != 2048 {
+		t.Errorf("FFT size at 48 kHz = %d, want 2048", s.FFTSize())
+	}
+	in := sine(440, 48000, 10000)
+	for _, ratio := range []float64{0.25, 0.5, 1.5, 2, 4} {
+		s.SetRatio(ratio)
+		if got, want := len(s.Stretch(in)), int(math.Round(10000*ratio)); got != want {
+			t.Errorf("ratio %g: %d samples, want %d", ratio, got, want)
+		}
+	}
+
+	s.SetRatio(10)
+	if s.GetRatio() != MaxRatio {
+		t.Errorf("Ratio = %g, want it clamped to %g", s.GetRatio(), MaxRatio)
+	}
+	if out := s.Stretch(nil); len(out) != 0 {
+		t.Errorf("Empty input gave %d samples", len(out))
+	}
+}
+
+func TestStretcherUnityRatio(t *testing.T) {
+	// With equal hops the locked phases are the analysis phases, so the
+	// vocoder reconstructs its input
+	s := NewStretcher(48000)
+	in := sine(1000, 48000, 8000)
+	out := s.Stretch(in)
+	for i := range in {
+		if math.Abs(float64(out[i]-in[i])) > 1e-4 {
+			t.Fatalf("Sample %d = %f, want %f", i, out[i], in[i])
+		}
+	}
+}
+
+func TestStretcherKeepsPitch(t *testing.T) {
+	const sampleRate = 48000.0
+	for _, ratio := range []float64{0.7, 1.5, 2.5} {
+		s := NewStretcher(sampleRate)
+		s.SetRatio(ratio)
+		out := s.Stretch(sine(1000, sampleRate, 24000))
+
+		// Count zero crossings and check the level away from the edges
+		crossings, peak := 0, 0.0
+		from, to := len(out)/4, 3*len(out)/4
+		for i := from + 1; i < to; i++ {
+			if (out[i-1] < 0) != (out[i] < 0) {
+				crossings++
+			}
+			peak = math.Max(peak, math.Abs(float64(out[i])))
+		}
+		freq := float64(crossings) / 2 / (float64(to-from) / sampleRate)
+		if math.Abs(freq-1000) > 5 {
+			t.Errorf("ratio %g: frequency %.1f Hz, want 1000", ratio, freq)
+		}
+		if math.Abs(peak-0.5) > 0.05 {
+			t.Errorf("ratio %g: peak %.3f, want about 0.5", ratio, peak)
+		}
+	}
+}
+
+// clicks returns decaying noise bursts starting at the given positions
+func clicks(n int, starts ...int) []float32 {
+	out := make([]float32, n)
+	seed := uint32(1)
+	for _, start := range starts {
+		for i := 0; i < 2000 && start+i < n; i++ {
+			seed = seed*1664525 + 1013904223
+			noise := float64(seed)/float64(1<<31) - 1
+			out[start+i] += float32(noise * math.Exp(-float64(i)/300))
+		}
+	}
+	return out
+}
+
+// attackEnergy returns the energy in the 1024 samples before pos relative
+// to the 1024 samples after it
+func attackEnergy(x []float32, pos int) float64 {
+	before, after := 0.0, 0.0
+	for i := 1; i <= 1024; i++ {
+		before += float64(x[pos-i] * x[pos-i])
+		after += float64(x[pos+i-1] * x[pos+i-1])
+	}
+	return before / after
+}
+
+func TestStretcherTransients(t *testing.T) {
+	in := clicks(48000, 10000, 30000)
+
+	s := NewStretcher(48000)
+	s.SetRatio(2)
+	sharp := s.Stretch(in)
+	s.SetTransientPreservation(false)
+	smeared := s.Stretch(in)
+
+	// The attacks land at their stretched positions with little pre-echo,
+	// and less than without transient preservation
+	for _, onset := range []int{20000, 60000} {
+		peak := onset
+		for i := onset - 2048; i < onset+2048; i++ {
+			if math.Abs(float64(sharp[i])) > math.Abs(float64(sharp[peak])) {
+				peak = i
+			}
+		}
+		if peak < onset || peak > onset+256 {
+			t.Errorf("Attack at %d peaks at %d", onset, peak)
+		}
+
+		pre, preSmeared := attackEnergy(sharp, onset), attackEnergy(smeared, onset)
+		if pre > 1e-3 {
+			t.Errorf("Attack at %d: pre-echo %.3f of the attack energy", onset, pre)
+		}
+		if pre >= preSmeared {
+			t.Errorf("Attack at %d: pre-echo %.4f with transient reset, %.4f without", onset, pre, preSmeared)
+		}
+	}
+}
+
+func TestStretcherChannels(t *testing.T) {
+	s := NewStretcher(44100)
+	s.SetRatio(1.25)
+	left := sine(300, 44100, 5000)
+	right := clicks(5000, 1000)
+	outs := s.StretchChannels([][]float32{left, right})
+	if len(outs) != 2 || len(outs[0]) != len(outs[1]) {
+		t.Fatalf("Got %d channels", len(outs))
+	}
+
+	// A channel stretched alone matches its multichannel result apart from
+	// the shared transient decisions, which the sine does not need
+	s.SetTransientPreservation(false)
+	alone := s.Stretch(left)
+	together := s.StretchChannels([][]float32{left, right})[0]
+	for i := range alone {
+		if alone[i] != together[i] {
+			t.Fatalf("Sample %d differs: %f alone, %f with a second channel", i, alone[i], together[i])
+		}
+	}
+}
+
+func BenchmarkStretcher(b *testing.B) {
+	s := NewStretcher(48000)
+	s.SetRatio(1.5)
+	in := clicks(48000, 0, 24000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Stretch(in)
+	}
+}
@@ -0,0 +1,196 @@
+package utility
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+)
+
+// Channel utility smoothing time in seconds
+const channelUtilitySmoothingSeconds = 0.01
+
+// Width limits; 1 leaves the stereo image unchanged
+const (
+	MinWidth = 0.0
+	MaxWidth = 2.0
+)
+
+// ChannelUtility is the stereo channel strip every session needs: output
+// gain, per-channel trim and polarity, L/R swap, mid/side gain, width, mono
+// fold and balance with a selectable pan law. All stages are linear, so they
+// collapse into one 2x2 matrix which is smoothed per sample; every change,
+// including swap and polarity flips, is click-free.
+type ChannelUtility struct {
+	gainDB float64
+	trimDB [2]float64
+	invert [2]bool
+	swap   bool
+	mono   bool
+	width  float64
+	midDB  float64
+	sideDB float64
+	pan    float32
+	panLaw pan.Law
+
+	current [4]float32 // Matrix in use: ll, rl, lr, rr
+	target  [4]float32
+	smooth  float32 // One-pole smoothing factor
+}
+
+// NewChannelUtility creates a utility that passes audio unchanged
+func NewChannelUtility(sampleRate float64) *ChannelUtility {
+	u := &ChannelUtility{
+		width:  1,
+		panLaw: pan.ConstantPower,
+		smooth: float32(1 - math.Exp(-1/(channelUtilitySmoothingSeconds*sampleRate))),
+	}
+	u.update()
+	u.current = u.target
+	return u
+}
+
+// SetGain sets the output gain in dB
+func (u *ChannelUtility) SetGain(db float64) {
+	u.gainDB = db
+	u.update()
+}
+
+// SetTrim sets the input trim of one channel (0 = left, 1 = right) in dB
+func (u *ChannelUtility) SetTrim(channel int, db float64) {
+	if channel < 0 || channel > 1 {
+		return
+	}
+	u.trimDB[channel] = db
+	u.update()
+}
+
+// SetInvert flips the polarity of one channel (0 = left, 1 = right)
+func (u *ChannelUtility) SetInvert(channel int, inverted bool) {
+	if channel < 0 || channel > 1 {
+		return
+	}
+	u.invert[channel] = inverted
+	u.update()
+}
+
+// SetSwap exchanges the left and right channels
+func (u *ChannelUtility) SetSwap(swap bool) {
+	u.swap = swap
+	u.update()
+}
+
+// SetMono folds the signal to mono, overriding the width
+func (u *ChannelUtility) SetMono(mono bool) {
+	u.mono = mono
+	u.update()
+}
+
+// SetWidth sets the stereo width: 0 is mono, 1 unchanged, 2 doubles the side
+// signal
+func (u *ChannelUtility) SetWidth(width float64) {
+	u.width = math.Max(MinWidth, math.Min(MaxWidth, width))
+	u.update()
+}
+
+// SetMidSideGain sets the mid and side levels in dB
+func (u *ChannelUtility) SetMidSideGain(midDB, sideDB float64) {
+	u.midDB, u.sideDB = midDB, sideDB
+	u.update()
+}
+
+// SetPan sets the balance from -1 (left) to 1 (right). The near channel
+// stays at unity and the far channel follows the pan law relative to its
+// center gain.
+func (u *ChannelUtility) SetPan(p float32) {
+	u.pan = max(-1, min(1, p))
+	u.update()
+}
+
+// SetPanLaw selects the law the balance follows
+func (u *ChannelUtility) SetPanLaw(law pan.Law) {
+	u.panLaw = law
+	u.update()
+}
+
+// update rebuilds the target matrix: gain · balance · mid/side · trim ·
+// polarity · swap
+func (u *ChannelUtility) update() {
+	// Swap, polarity and trim give each output of the input stage a source
+	// channel and a gain
+	src := [2]int{0, 1}
+	if u.swap {
+		src = [2]int{1, 0}
+	}
+	var pre [2]float64
+	for ch := range pre {
+		pre[ch] = dbToLinear(u.trimDB[ch])
+		if u.invert[ch] {
+			pre[ch] = -pre[ch]
+		}
+	}
+
+	// Mid/side: L = M + S, R = M - S with M = (L+R)/2 and S = (L-R)/2
+	width := u.width
+	if u.mono {
+		width = 0
+	}
+	mid := dbToLinear(u.midDB)
+	side := dbToLinear(u.sideDB) * width
+	ms := [2][2]float64{
+		{(mid + side) / 2, (mid - side) / 2},
+		{(mid - side) / 2, (mid + side) / 2},
+	}
+
+	// Balance attenuates the far channel only
+	balance := [2]float64{1, 1}
+	if u.pan != 0 {
+		l, r := pan.MonoToStereo(u.pan, u.panLaw)
+		cl, cr := pan.MonoToStereo(0, u.panLaw)
+		if u.pan > 0 {
+			balance[0] = float64(l / cl)
+		} else {
+			balance[1] = float64(r / cr)
+		}
+	}
+
+	gain := dbToLinear(u.gainDB)
+	var m [2][2]float64 // m[out][in]
+	for out := 0; out < 2; out++ {
+		for k := 0; k < 2; k++ {
+			m[out][src[k]] += gain * balance[out] * ms[out][k] * pre[src[k]]
+		}
+	}
+	u.target = [4]float32{float32(m[0][0]), float32(m[1][0]), float32(m[0][1]), float32(m[1][1])}
+}
+
+// Process runs one stereo sample pair through the utility
+func (u *ChannelUtility) Process(left, right float32) (float32, float32) {
+	if u.current != u.target {
+		for i := range u.current {
+			u.current[i] += (u.target[i] - u.current[i]) * u.smooth
+			if math.Abs(float64(u.target[i]-u.current[i])) < 1e-4 {
+				u.current[i] = u.target[i]
+			}
+		}
+	}
+	m := &u.current
+	return m[0]*left + m[2]*right, m[1]*left + m[3]*right
+}
+
+// ProcessStereo processes a stereo pair of buffers in place
+func (u *ChannelUtility) ProcessStereo(left, right []float32) {
+	n := min(len(left), len(right))
+	for i := 0; i < n; i++ {
+		left[i], right[i] = u.Process(left[i], right[i])
+	}
+}
+
+// Reset jumps to the target settings without smoothing
+func (u *ChannelUtility) Reset() {
+	u.current = u.target
+}
+
+// dbToLinear converts decibels to a linear gain
+func dbToLinear(db float64) float64 {
+	return math.Pow(10, db/20)
+}
@@ -0,0 +1,92 @@
+package utility
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/pan"
+)
+
+// settle runs enough samples for the smoothing to reach its target
+func settle(u *ChannelUtility) {
+	for i := 0; i < 48000; i++ {
+		u.Process(0, 0)
+	}
+}
+
+func TestChannelUtilityMatrix(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(u *ChannelUtility)
+		wantL, wantR float32 // Output for the input pair (1, 0.5)
+	}{
+		{"unity", func(u *ChannelUtility) {}, 1, 0.5},
+		{"gain", func(u *ChannelUtility) { u.SetGain(-6.0206) }, 0.5, 0.25},
+		{"trim", func(u *ChannelUtility) { u.SetTrim(1, 6.0206) }, 1, 1},
+		{"invert left", func(u *ChannelUtility) { u.SetInvert(0, true) }, -1, 0.5},
+		{"swap", func(u *ChannelUtility) { u.SetSwap(true) }, 0.5, 1},
+		{"swap keeps input trim", func(u *ChannelUtility) {
+			u.SetSwap(true)
+			u.SetInvert(0, true)
+		}, 0.5, -1},
+		{"mono", func(u *ChannelUtility) { u.SetMono(true) }, 0.75, 0.75},
+		{"width 0", func(u *ChannelUtility) { u.SetWidth(0) }, 0.75, 0.75},
+		{"width 2", func(u *ChannelUtility) { u.SetWidth(2) }, 1.25, 0.25},
+		{"side only", func(u *ChannelUtility) { u.SetMidSideGain(-200, 0) }, 0.25, -0.25},
+		{"hard right", func(u *ChannelUtility) { u.SetPan(1) }, 0, 0.5},
+		{"hard left", func(u *ChannelUtility) { u.SetPan(-1) }, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewChannelUtility(48000)
+			tt.setup(u)
+			u.Reset()
+			l, r := u.Process(1, 0.5)
+			if math.Abs(float64(l-tt.wantL)) > 1e-3 || math.Abs(float64(r-tt.wantR)) > 1e-3 {
+				t.Errorf("got (%g, %g), want (%g, %g)", l, r, tt.wantL, tt.wantR)
+			}
+		})
+	}
+}
+
+func TestChannelUtilityPanLaw(t *testing.T) {
+	for _, law := range []pan.Law{pan.Linear, pan.ConstantPower, pan.Balanced} {
+		u := NewChannelUtility(48000)
+		u.SetPanLaw(law)
+		u.SetPan(0.5)
+		u.Reset()
+		l, r := u.Process(1, 1)
+		gl, _ := pan.MonoToStereo(0.5, law)
+		cl, _ := pan.MonoToStereo(0, law)
+		if r != 1 || math.Abs(float64(l-gl/cl)) > 1e-6 {
+			t.Errorf("law %d: got (%g, %g)", law, l, r)
+		}
+	}
+}
+
+func TestChannelUtilitySmoothing(t *testing.T) {
+	u := NewChannelUtility(48000)
+	u.SetInvert(0, true)
+	u.SetSwap(true)
+
+	// The change must ramp rather than jump
+	prev, _ := u.Process(1, 1)
+	if prev < 0.9 {
+		t.Fatalf("first sample jumped to %g", prev)
+	}
+	for i := 0; i < 2000; i++ {
+		l, _ := u.Process(1, 1)
+		if math.Abs(float64(l-prev)) > 0.01 {
+			t.Fatalf("sample %d stepped from %g to %g", i, prev, l)
+		}
+		prev = l
+	}
+
+	settle(u)
+	left, right := []float32{1, 1}, []float32{0.5, 0.5}
+	u.ProcessStereo(left, right)
+	if left[1] != 0.5 || right[1] != -1 {
+		t.Errorf("settled output (%g, %g), want (0.5, -1)", left[1], right[1])
+	}
+}
@@ -22,7 +22,9 @@ const (
 	VioletNoise
 )
 
-// NoiseGenerator generates various types of noise.
+// NoiseGenerator generates various types of noise. Package noise has
+// level-matched, allocation-free generators with decorrelated multichannel
+// output and velvet noise.
 type NoiseGenerator struct {
 	noiseType NoiseType
 	
@@ -0,0 +1,144 @@
+// Package vector provides the buffer kernels that dominate plugin CPU time:
+// gain, scaled accumulation, two-way blends, peak scans and multichannel
+// biquads. On amd64 with AVX they run as assembly, selected once at startup
+// from the CPU's feature flags; everywhere else, and under the purego build
+// tag, they run as plain Go. Both paths give bit-identical results: the
+// assembly uses no fused multiply-add, and the Go loops convert each product
+// explicitly so the compiler cannot fuse them either.
+package vector
+
+// simdMin is the shortest run worth handing to the assembly kernels
+const simdMin = 16
+
+// Accelerated reports whether the AVX kernels are in use. The biquad
+// kernel only needs SSE2, which every amd64 CPU has.
+func Accelerated() bool {
+	return useAVX
+}
+
+// Mul sets dst[i] = src[i] * gain over the shorter of the two slices. dst
+// may be src; it must not partially overlap it - no allocations
+func Mul(dst, src []float32, gain float32) {
+	n := min(len(dst), len(src))
+	i := 0
+	if useAVX && n >= simdMin {
+		i = n &^ 7
+		mulAVX(&dst[0], &src[0], i, gain)
+	}
+	for ; i < n; i++ {
+		dst[i] = src[i] * gain
+	}
+}
+
+// AddScaled adds src[i] * gain to dst[i] over the shorter of the two
+// slices - no allocations
+func AddScaled(dst, src []float32, gain float32) {
+	n := min(len(dst), len(src))
+	i := 0
+	if useAVX && n >= simdMin {
+		i = n &^ 7
+		addScaledAVX(&dst[0], &src[0], i, gain)
+	}
+	for ; i < n; i++ {
+		dst[i] += float32(src[i] * gain)
+	}
+}
+
+// Blend sets dst[i] = a[i]*gainA + b[i]*gainB over the shortest slice, the
+// core of dry/wet mixes and crossfades. dst may be a or b - no allocations
+func Blend(dst, a, b []float32, gainA, gainB float32) {
+	n := min(len(dst), len(a), len(b))
+	i := 0
+	if useAVX && n >= simdMin {
+		i = n &^ 7
+		blendAVX(&dst[0], &a[0], &b[0], i, gainA, gainB)
+	}
+	for ; i < n; i++ {
+		dst[i] = float32(a[i]*gainA) + float32(b[i]*gainB)
+	}
+}
+
+// PeakAbs returns the largest absolute value in x, 0 for an empty slice.
+// NaNs are skipped.
+func PeakAbs(x []float32) float32 {
+	n := len(x)
+	i := 0
+	peak := float32(0)
+	if useAVX && n >= simdMin {
+		i = n &^ 7
+		peak = peakAVX(&x[0], i)
+	}
+	for ; i < n; i++ {
+		v := x[i]
+		if v < 0 {
+			v = -v
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+	return peak
+}
+
+// Biquad runs one Direct Form I biquad with a0 normalized to 1 over every
+// buffer in place, four channels at a time. x1, x2, y1 and y2 hold each
+// channel's history, indexed like buffers, and are updated. Buffers shorter
+// or longer than the first are filtered on their own - no allocations
+func Biquad(buffers [][]float32, b0, b1, b2, a1, a2 float32, x1, x2, y1, y2 []float32) {
+	if len(buffers) == 0 {
+		return
+	}
+	coeffs := [5]float32{b0, b1, b2, a1, a2}
+	n := len(buffers[0])
+	ch := 0
+	if useSSE && n > 0 && len(buffers) > 1 {
+		for ch+1 < len(buffers) {
+			// Gather up to four channels of equal length
+			lanes := 0
+			for lanes < 4 && ch+lanes < len(buffers) && len(buffers[ch+lanes]) == n {
+				lanes++
+			}
+			if lanes < 2 {
+				break
+			}
+			biquadLanes(buffers[ch:ch+lanes], &coeffs, x1[ch:], x2[ch:], y1[ch:], y2[ch:])
+			ch += lanes
+		}
+	}
+	for ; ch < len(buffers); ch++ {
+		biquadGeneric(buffers[ch], &coeffs, &x1[ch], &x2[ch], &y1[ch], &y2[ch])
+	}
+}
+
+// biquadLanes filters two to four equal-length buffers with the four-lane
+// kernel. Unused lanes repeat the first channel with a copy of its state,
+// so they compute and store exactly what lane 0 does.
+func biquadLanes(buffers [][]float32, coeffs *[5]float32, x1, x2, y1, y2 []float32) {
+	var ptrs [4]*float32
+	var state [16]float32 // x1, x2, y1 and y2 of each lane
+	for lane := 0; lane < 4; lane++ {
+		ch := lane
+		if lane >= len(buffers) {
+			ch = 0
+		}
+		ptrs[lane] = &buffers[ch][0]
+		state[lane], state[4+lane], state[8+lane], state[12+lane] = x1[ch], x2[ch], y1[ch], y2[ch]
+	}
+	biquad4(&ptrs, len(buffers[0]), coeffs, &state)
+	for ch := range buffers {
+		x1[ch], x2[ch], y1[ch], y2[ch] = state[ch], state[4+ch], state[8+ch], state[12+ch]
+	}
+}
+
+// biquadGeneric filters one buffer in place
+func biquadGeneric(buffer []float32, c *[5]float32, x1, x2, y1, y2 *float32) {
+	b0, b1, b2, a1, a2 := c[0], c[1], c[2], c[3], c[4]
+	sx1, sx2, sy1, sy2 := *x1, *x2, *y1, *y2
+	for i, x0 := range buffer {
+		y0 := float32(b0*x0) + float32(b1*sx1) + float32(b2*sx2) - float32(a1*sy1) - float32(a2*sy2)
+		sx2, sx1 = sx1, x0
+		sy2, sy1 = sy1, y0
+		buffer[i] = y0
+	}
+	*x1, *x2, *y1, *y2 = sx1, sx2, sy1, sy2
+}
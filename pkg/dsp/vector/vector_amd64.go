@@ -0,0 +1,53 @@
+//go:build amd64 && !purego
+
+package vector
+
+// useAVX is set when both the CPU and the OS support AVX
+var useAVX = hasAVX()
+
+// useSSE selects the biquad kernel; SSE2 is part of every amd64 CPU
+const useSSE = true
+
+// hasAVX checks the CPUID AVX and OSXSAVE flags and that the OS saves the
+// YMM registers on a context switch
+func hasAVX() bool {
+	const (
+		osxsave = 1 << 27
+		avx     = 1 << 28
+		xmmYmm  = 1<<1 | 1<<2 // XCR0 bits for the SSE and AVX state
+	)
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 1 {
+		return false
+	}
+	_, _, ecx, _ := cpuid(1, 0)
+	if ecx&(osxsave|avx) != osxsave|avx {
+		return false
+	}
+	eax, _ := xgetbv()
+	return eax&xmmYmm == xmmYmm
+}
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func xgetbv() (eax, edx uint32)
+
+// The AVX kernels process n elements, a multiple of 8
+
+//go:noescape
+func mulAVX(dst, src *float32, n int, gain float32)
+
+//go:noescape
+func addScaledAVX(dst, src *float32, n int, gain float32)
+
+//go:noescape
+func blendAVX(dst, a, b *float32, n int, gainA, gainB float32)
+
+//go:noescape
+func peakAVX(x *float32, n int) float32
+
+// biquad4 filters n samples of four buffers in place. coeffs holds b0, b1,
+// b2, a1 and a2; state holds the x1, x2, y1 and y2 of each lane.
+//
+//go:noescape
+func biquad4(buffers *[4]*float32, n int, coeffs *[5]float32, state *[16]float32)
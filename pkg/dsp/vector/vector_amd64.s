@@ -0,0 +1,211 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// Clears the sign bit of a float32
+DATA absmask<>+0(SB)/4, $0x7fffffff
+GLOBL absmask<>(SB), RODATA|NOPTR, $4
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
+
+// func mulAVX(dst, src *float32, n int, gain float32)
+TEXT ·mulAVX(SB), NOSPLIT, $0-28
+	MOVQ dst+0(FP), DI
+	MOVQ src+8(FP), SI
+	MOVQ n+16(FP), CX
+	VBROADCASTSS gain+24(FP), Y1
+	SHRQ $3, CX
+	JZ   mul_done
+
+mul_loop:
+	VMOVUPS (SI), Y0
+	VMULPS  Y1, Y0, Y0
+	VMOVUPS Y0, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     mul_loop
+
+mul_done:
+	VZEROUPPER
+	RET
+
+// func addScaledAVX(dst, src *float32, n int, gain float32)
+TEXT ·addScaledAVX(SB), NOSPLIT, $0-28
+	MOVQ dst+0(FP), DI
+	MOVQ src+8(FP), SI
+	MOVQ n+16(FP), CX
+	VBROADCASTSS gain+24(FP), Y1
+	SHRQ $3, CX
+	JZ   add_done
+
+add_loop:
+	VMOVUPS (SI), Y0
+	VMULPS  Y1, Y0, Y0
+	VADDPS  (DI), Y0, Y0
+	VMOVUPS Y0, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     add_loop
+
+add_done:
+	VZEROUPPER
+	RET
+
+// func blendAVX(dst, a, b *float32, n int, gainA, gainB float32)
+TEXT ·blendAVX(SB), NOSPLIT, $0-40
+	MOVQ dst+0(FP), DI
+	MOVQ a+8(FP), SI
+	MOVQ b+16(FP), DX
+	MOVQ n+24(FP), CX
+	VBROADCASTSS gainA+32(FP), Y2
+	VBROADCASTSS gainB+36(FP), Y3
+	SHRQ $3, CX
+	JZ   blend_done
+
+blend_loop:
+	VMOVUPS (SI), Y0
+	VMULPS  Y2, Y0, Y0
+	VMOVUPS (DX), Y1
+	VMULPS  Y3, Y1, Y1
+	VADDPS  Y1, Y0, Y0
+	VMOVUPS Y0, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, DX
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     blend_loop
+
+blend_done:
+	VZEROUPPER
+	RET
+
+// func peakAVX(x *float32, n int) float32
+TEXT ·peakAVX(SB), NOSPLIT, $0-20
+	MOVQ x+0(FP), SI
+	MOVQ n+8(FP), CX
+	VBROADCASTSS absmask<>(SB), Y2
+	VXORPS Y0, Y0, Y0
+	SHRQ $3, CX
+	JZ   peak_reduce
+
+peak_loop:
+	VANDPS (SI), Y2, Y1
+	// With a NaN in Y1, VMAXPS returns its last source, the running peak
+	VMAXPS Y0, Y1, Y0
+	ADDQ   $32, SI
+	DECQ   CX
+	JNZ    peak_loop
+
+peak_reduce:
+	VEXTRACTF128 $1, Y0, X1
+	VMAXPS       X1, X0, X0
+	VMOVHLPS     X0, X0, X1
+	VMAXPS       X1, X0, X0
+	VMOVSHDUP    X0, X1
+	VMAXPS       X1, X0, X0
+	VMOVSS       X0, ret+16(FP)
+	VZEROUPPER
+	RET
+
+// func biquad4(buffers *[4]*float32, n int, coeffs *[5]float32, state *[16]float32)
+//
+// One lane per channel. Each sample is evaluated in the scalar order,
+// b0*x0 + b1*x1 + b2*x2 - a1*y1 - a2*y2, so every lane matches the Go loop.
+TEXT ·biquad4(SB), NOSPLIT, $0-32
+	MOVQ buffers+0(FP), AX
+	MOVQ 0(AX), R8
+	MOVQ 8(AX), R9
+	MOVQ 16(AX), R10
+	MOVQ 24(AX), R11
+	MOVQ n+8(FP), CX
+	MOVQ coeffs+16(FP), DX
+	MOVQ state+24(FP), BX
+
+	MOVSS  0(DX), X8
+	SHUFPS $0, X8, X8
+	MOVSS  4(DX), X9
+	SHUFPS $0, X9, X9
+	MOVSS  8(DX), X10
+	SHUFPS $0, X10, X10
+	MOVSS  12(DX), X11
+	SHUFPS $0, X11, X11
+	MOVSS  16(DX), X12
+	SHUFPS $0, X12, X12
+
+	MOVUPS 0(BX), X1  // x1
+	MOVUPS 16(BX), X2 // x2
+	MOVUPS 32(BX), X3 // y1
+	MOVUPS 48(BX), X4 // y2
+
+	XORQ  SI, SI
+	TESTQ CX, CX
+	JZ    biquad_done
+
+biquad_loop:
+	// Gather x0 from the four channels
+	MOVSS    (R8)(SI*1), X0
+	MOVSS    (R9)(SI*1), X5
+	MOVSS    (R10)(SI*1), X6
+	MOVSS    (R11)(SI*1), X7
+	UNPCKLPS X5, X0
+	UNPCKLPS X7, X6
+	MOVLHPS  X6, X0
+
+	MOVAPS X0, X13
+	MULPS  X8, X13
+	MOVAPS X1, X14
+	MULPS  X9, X14
+	ADDPS  X14, X13
+	MOVAPS X2, X14
+	MULPS  X10, X14
+	ADDPS  X14, X13
+	MOVAPS X3, X14
+	MULPS  X11, X14
+	SUBPS  X14, X13
+	MOVAPS X4, X14
+	MULPS  X12, X14
+	SUBPS  X14, X13
+
+	MOVAPS X1, X2
+	MOVAPS X0, X1
+	MOVAPS X3, X4
+	MOVAPS X13, X3
+
+	// Scatter y0, rotating each lane down to the bottom
+	MOVSS  X13, (R8)(SI*1)
+	SHUFPS $0x39, X13, X13
+	MOVSS  X13, (R9)(SI*1)
+	SHUFPS $0x39, X13, X13
+	MOVSS  X13, (R10)(SI*1)
+	SHUFPS $0x39, X13, X13
+	MOVSS  X13, (R11)(SI*1)
+
+	ADDQ $4, SI
+	DECQ CX
+	JNZ  biquad_loop
+
+biquad_done:
+	MOVUPS X1, 0(BX)
+	MOVUPS X2, 16(BX)
+	MOVUPS X3, 32(BX)
+	MOVUPS X4, 48(BX)
+	RET
@@ -0,0 +1,31 @@
+//go:build !amd64 || purego
+
+package vector
+
+// Without assembly every kernel runs its Go loop; the stubs below are never
+// called
+
+// useAVX is a variable so tests can toggle it on every platform
+var useAVX = false
+
+const useSSE = false
+
+func mulAVX(dst, src *float32, n int, gain float32) {
+	panic("vector: no assembly kernels")
+}
+
+func addScaledAVX(dst, src *float32, n int, gain float32) {
+	panic("vector: no assembly kernels")
+}
+
+func blendAVX(dst, a, b *float32, n int, gainA, gainB float32) {
+	panic("vector: no assembly kernels")
+}
+
+func peakAVX(x *float32, n int) float32 {
+	panic("vector: no assembly kernels")
+}
+
+func biquad4(buffers *[4]*float32, n int, coeffs *[5]float32, state *[16]float32) {
+	panic("vector: no assembly kernels")
+}
@@ -0,0 +1,181 @@
+package vector
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// lengths covers empty, scalar-only and SIMD runs with every tail length
+var lengths = []int{0, 1, 7, 15, 16, 17, 23, 64, 100, 1031}
+
+// signal returns n samples of noise with a few awkward values mixed in
+func signal(n int, seed int64) []float32 {
+	r := rand.New(rand.NewSource(seed))
+	x := make([]float32, n)
+	for i := range x {
+		x[i] = float32(r.NormFloat64())
+	}
+	if n > 10 {
+		x[3] = float32(math.Copysign(0, -1))
+		x[5] = 1e-40 // Denormal
+		x[9] = -7.5
+	}
+	return x
+}
+
+// withGeneric runs fn with the assembly kernels disabled
+func withGeneric(t *testing.T, fn func()) {
+	t.Helper()
+	if !useAVX {
+		fn()
+		return
+	}
+	useAVX = false
+	defer func() { useAVX = true }()
+	fn()
+}
+
+func equalBits(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Float32bits(a[i]) != math.Float32bits(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestKernelsMatchGeneric(t *testing.T) {
+	t.Logf("AVX kernels: %v", Accelerated())
+	for _, n := range lengths {
+		a, b := signal(n, 1), signal(n, 2)
+
+		run := func() [][]float32 {
+			mul := make([]float32, n)
+			Mul(mul, a, 0.707)
+			inPlace := append([]float32(nil), a...)
+			Mul(inPlace, inPlace, -2.5)
+			add := append([]float32(nil), b...)
+			AddScaled(add, a, 0.3)
+			blend := append([]float32(nil), a...)
+			Blend(blend, blend, b, 0.25, 0.75)
+			return [][]float32{mul, inPlace, add, blend, {PeakAbs(a)}}
+		}
+		fast := run()
+		var slow [][]float32
+		withGeneric(t, func() { slow = run() })
+
+		for k, name := range []string{"Mul", "Mul in place", "AddScaled", "Blend", "PeakAbs"} {
+			if !equalBits(fast[k], slow[k]) {
+				t.Errorf("%s over %d samples differs from the Go loop", name, n)
+			}
+		}
+	}
+}
+
+func TestPeakAbs(t *testing.T) {
+	x := make([]float32, 40)
+	x[33] = -0.9
+	x[12] = 0.5
+	x[20] = float32(math.NaN())
+	if got := PeakAbs(x); got != 0.9 {
+		t.Errorf("PeakAbs = %g, want 0.9", got)
+	}
+	if got := PeakAbs(nil); got != 0 {
+		t.Errorf("PeakAbs(nil) = %g, want 0", got)
+	}
+}
+
+func TestBiquadMatchesGeneric(t *testing.T) {
+	// A resonant lowpass; its feedback shows any mismatch quickly
+	const b0, b1, b2, a1, a2 = 0.0200834, 0.0401667, 0.0200834, -1.5610181, 0.6413515
+
+	for _, channels := range []int{1, 2, 3, 4, 5, 8} {
+		input := make([][]float32, channels)
+		for ch := range input {
+			input[ch] = signal(257, int64(ch))
+		}
+		// Uneven lengths take the single-channel path for the odd one out
+		if channels == 5 {
+			input[4] = input[4][:100]
+		}
+
+		run := func() ([][]float32, []float32) {
+			buffers := make([][]float32, channels)
+			for ch := range buffers {
+				buffers[ch] = append([]float32(nil), input[ch]...)
+			}
+			state := make([]float32, 4*channels)
+			x1, x2, y1, y2 := state[:channels], state[channels:2*channels], state[2*channels:3*channels], state[3*channels:]
+			// Two calls carry the history across blocks
+			for _, half := range [][2]int{{0, 128}, {128, 257}} {
+				blocks := make([][]float32, channels)
+				for ch, buf := range buffers {
+					blocks[ch] = buf[min(half[0], len(buf)):min(half[1], len(buf))]
+				}
+				Biquad(blocks, b0, b1, b2, a1, a2, x1, x2, y1, y2)
+			}
+			return buffers, state
+		}
+
+		fast, fastState := run()
+
+		// The Go loop, one channel at a time
+		slow := make([][]float32, channels)
+		slowState := make([]float32, 4*channels)
+		c := [5]float32{b0, b1, b2, a1, a2}
+		for ch := range slow {
+			buf := append([]float32(nil), input[ch]...)
+			s := slowState[4*ch : 4*ch+4]
+			biquadGeneric(buf[:min(128, len(buf))], &c, &s[0], &s[1], &s[2], &s[3])
+			biquadGeneric(buf[min(128, len(buf)):], &c, &s[0], &s[1], &s[2], &s[3])
+			slow[ch] = buf
+		}
+
+		for ch := range fast {
+			if !equalBits(fast[ch], slow[ch]) {
+				t.Errorf("%d channels: channel %d differs from the Go loop", channels, ch)
+			}
+			got := []float32{fastState[ch], fastState[channels+ch], fastState[2*channels+ch], fastState[3*channels+ch]}
+			if !equalBits(got, slowState[4*ch:4*ch+4]) {
+				t.Errorf("%d channels: channel %d history %v, want %v", channels, ch, got, slowState[4*ch:4*ch+4])
+			}
+		}
+	}
+}
+
+func BenchmarkMul(b *testing.B) {
+	x, y := signal(512, 1), make([]float32, 512)
+	b.SetBytes(512 * 4)
+	for i := 0; i < b.N; i++ {
+		Mul(y, x, 0.999)
+	}
+}
+
+func BenchmarkBlend(b *testing.B) {
+	x, y := signal(512, 1), signal(512, 2)
+	b.SetBytes(512 * 4)
+	for i := 0; i < b.N; i++ {
+		Blend(x, x, y, 0.5, 0.5)
+	}
+}
+
+func BenchmarkPeakAbs(b *testing.B) {
+	x := signal(512, 1)
+	b.SetBytes(512 * 4)
+	for i := 0; i < b.N; i++ {
+		PeakAbs(x)
+	}
+}
+
+func BenchmarkBiquadStereo(b *testing.B) {
+	buffers := [][]float32{signal(512, 1), signal(512, 2)}
+	var x1, x2, y1, y2 [2]float32
+	b.SetBytes(2 * 512 * 4)
+	for i := 0; i < b.N; i++ {
+		Biquad(buffers, 0.02, 0.04, 0.02, -1.56, 0.64, x1[:], x2[:], y1[:], y2[:])
+	}
+}
@@ -0,0 +1,194 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrArrangementRejected is returned when a requested speaker arrangement is not supported
+var ErrArrangementRejected = errors.New("speaker arrangement rejected")
+
+// SpeakerArrangement is a VST3 speaker arrangement: a bit set of speakers
+type SpeakerArrangement uint64
+
+// Speaker bits
+const (
+	SpeakerL   SpeakerArrangement = 1 << 0  // Left
+	SpeakerR   SpeakerArrangement = 1 << 1  // Right
+	SpeakerC   SpeakerArrangement = 1 << 2  // Center
+	SpeakerLfe SpeakerArrangement = 1 << 3  // Subbass
+	SpeakerLs  SpeakerArrangement = 1 << 4  // Left surround
+	SpeakerRs  SpeakerArrangement = 1 << 5  // Right surround
+	SpeakerLc  SpeakerArrangement = 1 << 6  // Left of center
+	SpeakerRc  SpeakerArrangement = 1 << 7  // Right of center
+	SpeakerS   SpeakerArrangement = 1 << 8  // Surround
+	SpeakerSl  SpeakerArrangement = 1 << 9  // Side left
+	SpeakerSr  SpeakerArrangement = 1 << 10 // Side right
+	SpeakerM   SpeakerArrangement = 1 << 19 // Mono
+)
+
+// Common arrangements
+const (
+	ArrangementEmpty  SpeakerArrangement = 0
+	ArrangementMono                      = SpeakerM
+	ArrangementStereo                    = SpeakerL | SpeakerR
+	ArrangementLCR                       = SpeakerL | SpeakerR | SpeakerC
+	ArrangementQuad                      = SpeakerL | SpeakerR | SpeakerLs | SpeakerRs
+	Arrangement50                        = SpeakerL | SpeakerR | SpeakerC | SpeakerLs | SpeakerRs
+	Arrangement51                        = Arrangement50 | SpeakerLfe
+	Arrangement71                        = Arrangement51 | SpeakerSl | SpeakerSr
+	Arrangement71Cine                    = Arrangement51 | SpeakerLc | SpeakerRc
+)
+
+// ChannelCount returns the number of speakers in the arrangement
+func (a SpeakerArrangement) ChannelCount() int32 {
+	return int32(bits.OnesCount64(uint64(a)))
+}
+
+// String returns the common name of the arrangement
+func (a SpeakerArrangement) String() string {
+	switch a {
+	case ArrangementEmpty:
+		return "empty"
+	case ArrangementMono:
+		return "mono"
+	case ArrangementStereo:
+		return "stereo"
+	case ArrangementLCR:
+		return "LCR"
+	case ArrangementQuad:
+		return "quad"
+	case Arrangement50:
+		return "5.0"
+	case Arrangement51:
+		return "5.1"
+	case Arrangement71:
+		return "7.1"
+	case Arrangement71Cine:
+		return "7.1 cine"
+	default:
+		return fmt.Sprintf("%d channels (0x%x)", a.ChannelCount(), uint64(a))
+	}
+}
+
+// DefaultArrangement returns the usual arrangement for a channel count
+func DefaultArrangement(channels int32) SpeakerArrangement {
+	switch channels {
+	case 0:
+		return ArrangementEmpty
+	case 1:
+		return ArrangementMono
+	case 2:
+		return ArrangementStereo
+	case 3:
+		return ArrangementLCR
+	case 4:
+		return ArrangementQuad
+	case 5:
+		return Arrangement50
+	case 6:
+		return Arrangement51
+	case 8:
+		return Arrangement71
+	}
+	// Otherwise use the first channels speaker bits
+	if channels < 0 || channels > 64 {
+		return ArrangementEmpty
+	}
+	return SpeakerArrangement(1<<uint(channels) - 1)
+}
+
+// ArrangementHandler decides whether a set of arrangements, one per audio bus
+// in bus order, is supported. Processors use it to accept layouts other than
+// the configured ones and adapt their channel counts.
+type ArrangementHandler func(inputs, outputs []SpeakerArrangement) bool
+
+// SpeakerArrangement returns the bus arrangement, derived from the channel
+// count if none was set
+func (i *Info) SpeakerArrangement() SpeakerArrangement {
+	if i.Arrangement != ArrangementEmpty || i.ChannelCount == 0 {
+		return i.Arrangement
+	}
+	return DefaultArrangement(i.ChannelCount)
+}
+
+// GetArrangement returns the arrangement of an audio bus
+func (c *Configuration) GetArrangement(direction Direction, index int32) (SpeakerArrangement, error) {
+	info := c.GetBusInfo(MediaTypeAudio, direction, index)
+	if info == nil {
+		return ArrangementEmpty, fmt.Errorf("bus not found: direction=%d, index=%d", direction, index)
+	}
+	return info.SpeakerArrangement(), nil
+}
+
+// Arrangements returns the arrangements of all audio buses in a direction
+func (c *Configuration) Arrangements(direction Direction) []SpeakerArrangement {
+	var arrs []SpeakerArrangement
+	for i := range c.audioBuses {
+		if c.audioBuses[i].Direction == direction {
+			arrs = append(arrs, c.audioBuses[i].SpeakerArrangement())
+		}
+	}
+	return arrs
+}
+
+// SetArrangementHandler sets the function that accepts or rejects
+// arrangements requested by the host. Without a handler only the current
+// arrangements are accepted.
+func (c *Configuration) SetArrangementHandler(handler ArrangementHandler) {
+	c.arrangementHandler = handler
+}
+
+// NegotiateArrangements applies the host's requested arrangements, one per
+// audio bus, if the handler accepts them. On rejection the configuration is
+// unchanged and the host is expected to query the current arrangements.
+func (c *Configuration) NegotiateArrangements(inputs, outputs []SpeakerArrangement) error {
+	if int32(len(inputs)) != c.GetBusCount(MediaTypeAudio, DirectionInput) ||
+		int32(len(outputs)) != c.GetBusCount(MediaTypeAudio, DirectionOutput) {
+		return fmt.Errorf("%w: %d inputs and %d outputs requested for %d and %d buses",
+			ErrArrangementRejected, len(inputs), len(outputs),
+			c.GetBusCount(MediaTypeAudio, DirectionInput), c.GetBusCount(MediaTypeAudio, DirectionOutput))
+	}
+
+	accepted := false
+	if c.arrangementHandler != nil {
+		accepted = c.arrangementHandler(inputs, outputs)
+	} else {
+		accepted = equalArrangements(inputs, c.Arrangements(DirectionInput)) &&
+			equalArrangements(outputs, c.Arrangements(DirectionOutput))
+	}
+	if !accepted {
+		return fmt.Errorf("%w: inputs %v, outputs %v", ErrArrangementRejected, inputs, outputs)
+	}
+
+	c.applyArrangements(DirectionInput, inputs)
+	c.applyArrangements(DirectionOutput, outputs)
+	return nil
+}
+
+// applyArrangements sets arrangements and channel counts of one direction
+func (c *Configuration) applyArrangements(direction Direction, arrs []SpeakerArrangement) {
+	n := 0
+	for i := range c.audioBuses {
+		if c.audioBuses[i].Direction != direction {
+			continue
+		}
+		c.audioBuses[i].Arrangement = arrs[n]
+		c.audioBuses[i].ChannelCount = arrs[n].ChannelCount()
+		n++
+	}
+}
+
+// equalArrangements compares two arrangement lists
+func equalArrangements(a, b []SpeakerArrangement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
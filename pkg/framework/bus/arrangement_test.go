@@ -0,0 +1,116 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSpeakerArrangementChannelCount(t *testing.T) {
+	tests := []struct {
+		arr      SpeakerArrangement
+		channels int32
+		name     string
+	}{
+		{ArrangementMono, 1, "mono"},
+		{ArrangementStereo, 2, "stereo"},
+		{Arrangement51, 6, "5.1"},
+		{Arrangement71, 8, "7.1"},
+		{Arrangement71Cine, 8, "7.1 cine"},
+	}
+	for _, tt := range tests {
+		if got := tt.arr.ChannelCount(); got != tt.channels {
+			t.Errorf("%s: got %d channels, want %d", tt.name, got, tt.channels)
+		}
+		if tt.arr.String() != tt.name {
+			t.Errorf("got name %q, want %q", tt.arr.String(), tt.name)
+		}
+		if tt.arr != Arrangement71Cine && DefaultArrangement(tt.channels) != tt.arr {
+			t.Errorf("default for %d channels: got %v", tt.channels, DefaultArrangement(tt.channels))
+		}
+	}
+	if DefaultArrangement(12).ChannelCount() != 12 {
+		t.Error("default arrangement should cover unusual channel counts")
+	}
+}
+
+func TestNegotiateArrangementsDefault(t *testing.T) {
+	config := NewEffectStereoSidechain()
+
+	arr, err := config.GetArrangement(DirectionOutput, 0)
+	if err != nil || arr != ArrangementStereo {
+		t.Fatalf("got %v, %v", arr, err)
+	}
+	if _, err := config.GetArrangement(DirectionOutput, 1); err == nil {
+		t.Error("expected error for a missing bus")
+	}
+
+	// Without a handler only the current layout is accepted
+	current := []SpeakerArrangement{ArrangementStereo, ArrangementStereo}
+	if err := config.NegotiateArrangements(current, []SpeakerArrangement{ArrangementStereo}); err != nil {
+		t.Errorf("current layout rejected: %v", err)
+	}
+	err = config.NegotiateArrangements(
+		[]SpeakerArrangement{ArrangementMono, ArrangementStereo},
+		[]SpeakerArrangement{ArrangementMono})
+	if !errors.Is(err, ErrArrangementRejected) {
+		t.Errorf("expected rejection, got %v", err)
+	}
+	if err := config.NegotiateArrangements(current, nil); !errors.Is(err, ErrArrangementRejected) {
+		t.Errorf("expected rejection for wrong bus count, got %v", err)
+	}
+}
+
+func TestNegotiateArrangementsHandler(t *testing.T) {
+	var seen []SpeakerArrangement
+	config := NewBuilder().
+		WithStereoInput("In").
+		WithStereoOutput("Out").
+		WithArrangementHandler(func(inputs, outputs []SpeakerArrangement) bool {
+			seen = outputs
+			// Any matching layout up to 7.1
+			return inputs[0] == outputs[0] && outputs[0].ChannelCount() <= 8
+		}).
+		MustBuild()
+
+	if err := config.NegotiateArrangements(
+		[]SpeakerArrangement{Arrangement51},
+		[]SpeakerArrangement{Arrangement51}); err != nil {
+		t.Fatalf("5.1 rejected: %v", err)
+	}
+	if len(seen) != 1 || seen[0] != Arrangement51 {
+		t.Errorf("handler saw %v", seen)
+	}
+
+	info := config.GetBusInfo(MediaTypeAudio, DirectionOutput, 0)
+	if info.ChannelCount != 6 || info.SpeakerArrangement() != Arrangement51 {
+		t.Errorf("bus not updated: %d channels, %v", info.ChannelCount, info.SpeakerArrangement())
+	}
+	if config.GetActiveInputChannelCount() != 6 {
+		t.Errorf("expected 6 active input channels, got %d", config.GetActiveInputChannelCount())
+	}
+
+	// A rejected request leaves the configuration unchanged
+	err := config.NegotiateArrangements(
+		[]SpeakerArrangement{ArrangementStereo},
+		[]SpeakerArrangement{Arrangement51})
+	if !errors.Is(err, ErrArrangementRejected) {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+	if arr, _ := config.GetArrangement(DirectionInput, 0); arr != Arrangement51 {
+		t.Errorf("rejected request changed input to %v", arr)
+	}
+}
+
+func TestBuilderWithArrangement(t *testing.T) {
+	config := NewBuilder().
+		WithArrangementInput("In", Arrangement71Cine).
+		WithArrangementOutput("Out", ArrangementLCR).
+		MustBuild()
+
+	if arrs := config.Arrangements(DirectionInput); len(arrs) != 1 || arrs[0] != Arrangement71Cine {
+		t.Errorf("input arrangements %v", arrs)
+	}
+	if info := config.GetBusInfo(MediaTypeAudio, DirectionOutput, 0); info.ChannelCount != 3 {
+		t.Errorf("expected 3 output channels, got %d", info.ChannelCount)
+	}
+}
@@ -130,11 +130,25 @@ func (b *Builder) WithQuadInput(name string) *Builder {
 	return b.WithAudioInput(name, 4)
 }
 
+// WithArrangementInput adds an audio input with a specific speaker arrangement
+func (b *Builder) WithArrangementInput(name string, arr SpeakerArrangement) *Builder {
+	b.WithAudioInput(name, arr.ChannelCount())
+	b.config.audioBuses[len(b.config.audioBuses)-1].Arrangement = arr
+	return b
+}
+
 // WithQuadOutput adds a quadraphonic (4-channel) output
 func (b *Builder) WithQuadOutput(name string) *Builder {
 	return b.WithAudioOutput(name, 4)
 }
 
+// WithArrangementOutput adds an audio output with a specific speaker arrangement
+func (b *Builder) WithArrangementOutput(name string, arr SpeakerArrangement) *Builder {
+	b.WithAudioOutput(name, arr.ChannelCount())
+	b.config.audioBuses[len(b.config.audioBuses)-1].Arrangement = arr
+	return b
+}
+
 // With5_1Input adds a 5.1 surround input
 func (b *Builder) With5_1Input(name string) *Builder {
 	return b.WithAudioInput(name, 6)
@@ -177,6 +191,13 @@ func (b *Builder) SetBusActive(mediaType MediaType, direction Direction, index i
 	return b
 }
 
+// WithArrangementHandler sets the function deciding which speaker
+// arrangements requested by the host are accepted
+func (b *Builder) WithArrangementHandler(handler ArrangementHandler) *Builder {
+	b.config.arrangementHandler = handler
+	return b
+}
+
 // Validate checks if the configuration is valid
 func (b *Builder) Validate() error {
 	// Check for errors accumulated during building
@@ -41,12 +41,14 @@ type Info struct {
 	Name         string
 	BusType      Type
 	IsActive     bool
+	Arrangement  SpeakerArrangement // Zero derives the default from ChannelCount
 }
 
 // Configuration manages audio and event buses
 type Configuration struct {
-	audioBuses []Info
-	eventBuses []Info
+	audioBuses         []Info
+	eventBuses         []Info
+	arrangementHandler ArrangementHandler
 }
 
 // NewStereoConfiguration creates a standard stereo I/O configuration
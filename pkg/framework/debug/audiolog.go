@@ -0,0 +1,76 @@
+package debug
+
+import (
+	"sync/atomic"
+
+	"github.com/justyntemme/vst3go/pkg/rt"
+)
+
+// AudioLogMaxValues is the number of values one audio log entry can carry.
+const AudioLogMaxValues = 4
+
+// audioLogEntry is one message queued by the audio thread.
+type audioLogEntry struct {
+	level  LogLevel
+	format string
+	values [AudioLogMaxValues]float64
+	count  int
+}
+
+// AudioLog queues log messages from the audio thread without formatting,
+// locking or allocating, and writes them to a Logger when Flush is called
+// from another thread. Messages carry a format string and up to four
+// float64 values; messages that find the queue full are counted and dropped.
+type AudioLog struct {
+	logger  *Logger
+	queue   *rt.Ring[audioLogEntry]
+	dropped atomic.Uint64
+	args    []interface{}
+}
+
+// NewAudioLog creates an audio log that holds at least capacity messages
+// between flushes. A nil logger writes to the default logger.
+func NewAudioLog(logger *Logger, capacity int) *AudioLog {
+	if logger == nil {
+		logger = Default()
+	}
+	return &AudioLog{
+		logger: logger,
+		queue:  rt.NewRing[audioLogEntry](capacity),
+		args:   make([]interface{}, 0, AudioLogMaxValues),
+	}
+}
+
+// Log queues a message from the audio thread. The format string must be a
+// constant or otherwise outlive the flush; values beyond AudioLogMaxValues
+// are ignored.
+func (a *AudioLog) Log(level LogLevel, format string, values ...float64) {
+	entry := audioLogEntry{level: level, format: format}
+	entry.count = copy(entry.values[:], values)
+	if !a.queue.Push(entry) {
+		a.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of messages lost to a full queue.
+func (a *AudioLog) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Flush writes the queued messages to the logger and returns how many were
+// written. Call it from one non-audio thread, e.g. a timer.
+func (a *AudioLog) Flush() int {
+	written := 0
+	for {
+		entry, ok := a.queue.Pop()
+		if !ok {
+			return written
+		}
+		a.args = a.args[:0]
+		for _, v := range entry.values[:entry.count] {
+			a.args = append(a.args, v)
+		}
+		a.logger.log(entry.level, entry.format, a.args...)
+		written++
+	}
+}
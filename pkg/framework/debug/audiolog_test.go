@@ -0,0 +1,37 @@
+package debug
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAudioLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", FlagLevel)
+	log := NewAudioLog(logger, 2)
+
+	log.Log(LogLevelWarn, "clip at %.0f: %.2f", 12, 1.5)
+	log.Log(LogLevelInfo, "latency %.0f", 64)
+	log.Log(LogLevelInfo, "dropped")
+	if log.Dropped() != 1 {
+		t.Errorf("Dropped = %d, want 1", log.Dropped())
+	}
+	if n := log.Flush(); n != 2 {
+		t.Errorf("Flush wrote %d messages, want 2", n)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "[WARN] clip at 12: 1.50") || !strings.Contains(output, "latency 64") {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestAudioLogNoAllocs(t *testing.T) {
+	log := NewAudioLog(New(&bytes.Buffer{}, "", 0), 64)
+	allocs := testing.AllocsPerRun(32, func() {
+		log.Log(LogLevelDebug, "gain %.2f", 0.5)
+	})
+	if allocs != 0 {
+		t.Errorf("Log allocates %.1f times", allocs)
+	}
+}
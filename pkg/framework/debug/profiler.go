@@ -6,6 +6,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/justyntemme/vst3go/pkg/rt"
 )
 
 // Profiler provides performance profiling for audio processing.
@@ -241,7 +243,7 @@ type AudioProcessProfiler struct {
 	*Profiler
 	bufferSize    int
 	sampleRate    float64
-	cpuLoadPercent atomic.Uint64
+	cpuLoadPercent rt.Float64
 }
 
 // NewAudioProcessProfiler creates a profiler specialized for audio processing.
@@ -267,13 +269,12 @@ func (a *AudioProcessProfiler) UpdateCPULoad() {
 	avgProcessTime := m.Average()
 	cpuLoad := float64(avgProcessTime) / float64(bufferDuration) * 100.0
 	
-	// Store as fixed-point (multiply by 100 for 2 decimal places)
-	a.cpuLoadPercent.Store(uint64(cpuLoad * 100))
+	a.cpuLoadPercent.Store(cpuLoad)
 }
 
 // GetCPULoad returns the current CPU load percentage.
 func (a *AudioProcessProfiler) GetCPULoad() float64 {
-	return float64(a.cpuLoadPercent.Load()) / 100.0
+	return a.cpuLoadPercent.Load()
 }
 
 // AudioReport generates an audio-specific performance report.
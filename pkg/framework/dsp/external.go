@@ -0,0 +1,266 @@
+package dsp
+
+import (
+	"errors"
+	"io"
+)
+
+// Float64Processor is the shape of most third-party Go DSP code: in-place
+// processing of float64 samples. Implementations may also have a Reset()
+// method, which the adapters forward.
+type Float64Processor interface {
+	Process(buffer []float64)
+}
+
+// Float64Func allows using a function as a Float64Processor.
+type Float64Func func(buffer []float64)
+
+func (f Float64Func) Process(buffer []float64) {
+	f(buffer)
+}
+
+// resetter is implemented by external processors with state to clear
+type resetter interface {
+	Reset()
+}
+
+// resetIfPossible resets an external processor if it supports it
+func resetIfPossible(v interface{}) {
+	if r, ok := v.(resetter); ok {
+		r.Reset()
+	}
+}
+
+// Float64Adapter adapts a Float64Processor to the Processor interface. The
+// conversion buffer is allocated once; larger buffers are processed in
+// chunks of maxBlockSize.
+type Float64Adapter struct {
+	proc    Float64Processor
+	scratch []float64
+}
+
+// NewFloat64Adapter creates an adapter converting up to maxBlockSize
+// samples at a time.
+func NewFloat64Adapter(p Float64Processor, maxBlockSize int) *Float64Adapter {
+	return &Float64Adapter{
+		proc:    p,
+		scratch: make([]float64, max(1, maxBlockSize)),
+	}
+}
+
+func (a *Float64Adapter) Process(buffer []float32) {
+	for len(buffer) > 0 {
+		n := min(len(buffer), len(a.scratch))
+		chunk := a.scratch[:n]
+		for i := range chunk {
+			chunk[i] = float64(buffer[i])
+		}
+		a.proc.Process(chunk)
+		for i, v := range chunk {
+			buffer[i] = float32(v)
+		}
+		buffer = buffer[n:]
+	}
+}
+
+func (a *Float64Adapter) Reset() {
+	resetIfPossible(a.proc)
+}
+
+// BlockAdapter adapts a Float64Processor that only works on blocks of a
+// fixed size, such as FFT-based code, to the Processor interface. Input is
+// collected until a block is complete, so the output is delayed by one
+// block; report Latency to the host.
+type BlockAdapter struct {
+	proc      Float64Processor
+	input     []float64 // Block being collected
+	output    []float64 // Last processed block being played out
+	pos       int
+	blockSize int
+}
+
+// NewBlockAdapter creates an adapter that calls p with exactly blockSize
+// samples.
+func NewBlockAdapter(p Float64Processor, blockSize int) *BlockAdapter {
+	blockSize = max(1, blockSize)
+	return &BlockAdapter{
+		proc:      p,
+		input:     make([]float64, blockSize),
+		output:    make([]float64, blockSize),
+		blockSize: blockSize,
+	}
+}
+
+func (a *BlockAdapter) Process(buffer []float32) {
+	for i, x := range buffer {
+		buffer[i] = float32(a.output[a.pos])
+		a.input[a.pos] = float64(x)
+		a.pos++
+		if a.pos == a.blockSize {
+			a.proc.Process(a.input)
+			a.input, a.output = a.output, a.input
+			a.pos = 0
+		}
+	}
+}
+
+// Latency returns the delay added by the block buffering in samples.
+func (a *BlockAdapter) Latency() int {
+	return a.blockSize
+}
+
+func (a *BlockAdapter) Reset() {
+	for i := range a.input {
+		a.input[i] = 0
+		a.output[i] = 0
+	}
+	a.pos = 0
+	resetIfPossible(a.proc)
+}
+
+// StreamProcessor is an io.Reader/io.Writer-style DSP stream: input is
+// written, and processed output becomes readable whenever the stream has
+// produced it, possibly in different amounts than were written.
+type StreamProcessor interface {
+	Write(samples []float64) (int, error)
+	Read(samples []float64) (int, error)
+}
+
+// ErrStreamOverrun is reported when a stream produces more output than the
+// adapter can buffer.
+var ErrStreamOverrun = errors.New("stream produced more output than buffered")
+
+// StreamAdapter adapts a StreamProcessor to the Processor interface. Output
+// is delayed by a fixed latency so a stream that produces output in bursts
+// still plays without gaps; if the stream falls further behind, silence is
+// output and Underruns counts the missing samples. Stream errors other
+// than io.EOF are kept in Err.
+type StreamAdapter struct {
+	stream  StreamProcessor
+	scratch []float64
+	fifo    []float64 // Ring of output waiting to be played
+	head    int       // Next sample to play
+	count   int       // Samples in the ring
+	latency int
+
+	underruns int
+	err       error
+}
+
+// NewStreamAdapter creates an adapter with the given output latency in
+// samples for blocks of up to maxBlockSize samples.
+func NewStreamAdapter(s StreamProcessor, latency, maxBlockSize int) *StreamAdapter {
+	a := &StreamAdapter{
+		stream:  s,
+		scratch: make([]float64, max(1, maxBlockSize)),
+		fifo:    make([]float64, max(0, latency)+2*max(1, maxBlockSize)),
+		latency: max(0, latency),
+	}
+	a.count = a.latency // Start with the latency as silence
+	return a
+}
+
+func (a *StreamAdapter) Process(buffer []float32) {
+	for len(buffer) > 0 {
+		n := min(len(buffer), len(a.scratch))
+		a.processChunk(buffer[:n])
+		buffer = buffer[n:]
+	}
+}
+
+// processChunk feeds one chunk to the stream and plays the same amount
+func (a *StreamAdapter) processChunk(buffer []float32) {
+	chunk := a.scratch[:len(buffer)]
+	for i, x := range buffer {
+		chunk[i] = float64(x)
+	}
+	if _, err := a.stream.Write(chunk); err != nil && a.err == nil && !errors.Is(err, io.EOF) {
+		a.err = err
+	}
+	a.drain()
+
+	for i := range buffer {
+		if a.count == 0 {
+			buffer[i] = 0
+			a.underruns++
+			continue
+		}
+		buffer[i] = float32(a.fifo[a.head])
+		a.head = (a.head + 1) % len(a.fifo)
+		a.count--
+	}
+}
+
+// drain reads all available output of the stream into the ring
+func (a *StreamAdapter) drain() {
+	for {
+		free := len(a.fifo) - a.count
+		if free == 0 {
+			// Drop what the ring can't hold rather than block
+			if n, _ := a.stream.Read(a.scratch); n > 0 && a.err == nil {
+				a.err = ErrStreamOverrun
+			}
+			return
+		}
+		tail := (a.head + a.count) % len(a.fifo)
+		span := min(free, len(a.fifo)-tail)
+		n, err := a.stream.Read(a.fifo[tail : tail+span])
+		a.count += n
+		if err != nil {
+			if a.err == nil && !errors.Is(err, io.EOF) {
+				a.err = err
+			}
+			return
+		}
+		if n < span {
+			return
+		}
+	}
+}
+
+// Latency returns the output delay in samples.
+func (a *StreamAdapter) Latency() int {
+	return a.latency
+}
+
+// Underruns returns the number of silent samples output because the stream
+// had no output ready.
+func (a *StreamAdapter) Underruns() int {
+	return a.underruns
+}
+
+// Err returns the first error reported by the stream, if any.
+func (a *StreamAdapter) Err() error {
+	return a.err
+}
+
+func (a *StreamAdapter) Reset() {
+	for i := range a.fifo {
+		a.fifo[i] = 0
+	}
+	a.head = 0
+	a.count = a.latency
+	a.underruns = 0
+	a.err = nil
+	resetIfPossible(a.stream)
+}
+
+// DualMonoAdapter runs one mono Processor per channel as a StereoProcessor.
+type DualMonoAdapter struct {
+	left, right Processor
+}
+
+// NewDualMonoAdapter creates a stereo processor from two mono processors.
+func NewDualMonoAdapter(left, right Processor) *DualMonoAdapter {
+	return &DualMonoAdapter{left: left, right: right}
+}
+
+func (a *DualMonoAdapter) ProcessStereo(left, right []float32) {
+	a.left.Process(left)
+	a.right.Process(right)
+}
+
+func (a *DualMonoAdapter) Reset() {
+	a.left.Reset()
+	a.right.Reset()
+}
@@ -0,0 +1,193 @@
+package dsp
+
+import (
+	"errors"
+	"testing"
+)
+
+// gainF64 is a stand-in for third-party float64 DSP with state
+type gainF64 struct {
+	gain  float64
+	calls int
+	reset bool
+}
+
+func (g *gainF64) Process(buffer []float64) {
+	g.calls++
+	for i := range buffer {
+		buffer[i] *= g.gain
+	}
+}
+
+func (g *gainF64) Reset() { g.reset = true }
+
+func TestFloat64Adapter(t *testing.T) {
+	ext := &gainF64{gain: 0.5}
+	a := NewFloat64Adapter(ext, 64)
+
+	buffer := make([]float32, 150)
+	for i := range buffer {
+		buffer[i] = 1
+	}
+	a.Process(buffer)
+
+	if ext.calls != 3 {
+		t.Errorf("Expected 3 chunks, got %d", ext.calls)
+	}
+	for i, v := range buffer {
+		if v != 0.5 {
+			t.Fatalf("Sample %d: got %f, want 0.5", i, v)
+		}
+	}
+
+	a.Reset()
+	if !ext.reset {
+		t.Error("Reset should be forwarded")
+	}
+
+	// Plain functions work too and can go in a chain
+	chain := NewChain("ext").Add(NewFloat64Adapter(Float64Func(func(b []float64) {
+		for i := range b {
+			b[i] += 1
+		}
+	}), 16))
+	chain.Process(buffer)
+	if buffer[0] != 1.5 {
+		t.Errorf("Chained function: got %f, want 1.5", buffer[0])
+	}
+	chain.Reset()
+}
+
+func TestBlockAdapter(t *testing.T) {
+	var sizes []int
+	a := NewBlockAdapter(Float64Func(func(b []float64) {
+		sizes = append(sizes, len(b))
+		for i := range b {
+			b[i] *= 2
+		}
+	}), 32)
+
+	if a.Latency() != 32 {
+		t.Errorf("Latency should be 32, got %d", a.Latency())
+	}
+
+	// Feed a ramp in odd block sizes
+	var out []float32
+	n := 0
+	for _, size := range []int{7, 50, 13, 30} {
+		buffer := make([]float32, size)
+		for i := range buffer {
+			n++
+			buffer[i] = float32(n)
+		}
+		a.Process(buffer)
+		out = append(out, buffer...)
+	}
+
+	for _, s := range sizes {
+		if s != 32 {
+			t.Fatalf("Processor called with %d samples", s)
+		}
+	}
+	for i, v := range out {
+		want := float32(0)
+		if i >= 32 {
+			want = float32(2 * (i - 31))
+		}
+		if v != want {
+			t.Fatalf("Sample %d: got %f, want %f", i, v, want)
+		}
+	}
+}
+
+// burstStream returns its input doubled, but only in bursts of 48 samples
+type burstStream struct {
+	pending []float64
+	failAt  int
+	writes  int
+}
+
+func (s *burstStream) Write(samples []float64) (int, error) {
+	s.writes++
+	if s.failAt > 0 && s.writes == s.failAt {
+		return 0, errors.New("stream failed")
+	}
+	for _, x := range samples {
+		s.pending = append(s.pending, 2*x)
+	}
+	return len(samples), nil
+}
+
+func (s *burstStream) Read(samples []float64) (int, error) {
+	avail := len(s.pending) / 48 * 48
+	n := copy(samples, s.pending[:avail])
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+func TestStreamAdapter(t *testing.T) {
+	stream := &burstStream{}
+	a := NewStreamAdapter(stream, 48, 16)
+
+	var out []float32
+	n := 0
+	for block := 0; block < 20; block++ {
+		buffer := make([]float32, 16)
+		for i := range buffer {
+			n++
+			buffer[i] = float32(n)
+		}
+		a.Process(buffer)
+		out = append(out, buffer...)
+	}
+
+	if a.Underruns() != 0 || a.Err() != nil {
+		t.Fatalf("Unexpected underruns %d, err %v", a.Underruns(), a.Err())
+	}
+	for i, v := range out {
+		want := float32(0)
+		if i >= a.Latency() {
+			want = float32(2 * (i - a.Latency() + 1))
+		}
+		if v != want {
+			t.Fatalf("Sample %d: got %f, want %f", i, v, want)
+		}
+	}
+}
+
+func TestStreamAdapterUnderrunAndError(t *testing.T) {
+	// Without latency the bursty stream can't keep up
+	stream := &burstStream{failAt: 3}
+	a := NewStreamAdapter(stream, 0, 16)
+	for block := 0; block < 4; block++ {
+		a.Process(make([]float32, 16))
+	}
+	if a.Underruns() == 0 {
+		t.Error("Expected underruns without latency")
+	}
+	if a.Err() == nil {
+		t.Error("Expected the stream error to be kept")
+	}
+
+	a.Reset()
+	if a.Underruns() != 0 || a.Err() != nil {
+		t.Error("Reset should clear underruns and errors")
+	}
+}
+
+func TestDualMonoAdapter(t *testing.T) {
+	left := &gainF64{gain: 0.5}
+	right := &gainF64{gain: 2}
+	s := NewDualMonoAdapter(NewFloat64Adapter(left, 8), NewFloat64Adapter(right, 8))
+
+	l := []float32{1, 1}
+	r := []float32{1, 1}
+	NewStereoChain("stereo").Add(s).ProcessStereo(l, r)
+	if l[0] != 0.5 || r[1] != 2 {
+		t.Errorf("Got L=%v R=%v", l, r)
+	}
+	s.Reset()
+	if !left.reset || !right.reset {
+		t.Error("Reset should reach both channels")
+	}
+}
@@ -0,0 +1,212 @@
+package dsp
+
+import (
+	"github.com/justyntemme/vst3go/pkg/dsp/dynamics"
+	"github.com/justyntemme/vst3go/pkg/dsp/filter"
+	"github.com/justyntemme/vst3go/pkg/dsp/gain"
+	"github.com/justyntemme/vst3go/pkg/framework/modmatrix"
+)
+
+// SpeechPreset configures a SpeechStack
+type SpeechPreset struct {
+	HighpassQ       float64 // Resonance of the rumble filter
+	DeEssFrequency  float64 // Hz, lower edge of the sibilance band
+	DeEssRange      float64 // dB, the most sibilance is turned down
+	LevelTarget     float64 // dB, the level the leveler rides toward
+	LevelSpeed      float64 // Seconds
+	Ceiling         float64 // dB, the limiter ceiling
+	LimiterRelease  float64 // Seconds, fast release stage
+	LimiterSustain  float64 // Seconds, slow release stage under sustained limiting
+	LimiterTruePeak bool
+}
+
+// PodcastPreset suits spoken word for streaming: a -16 dB leveler target
+// under a -1 dB true peak ceiling
+var PodcastPreset = SpeechPreset{
+	HighpassQ:       0.707,
+	DeEssFrequency:  5500,
+	DeEssRange:      8,
+	LevelTarget:     -16,
+	LevelSpeed:      0.4,
+	Ceiling:         -1,
+	LimiterRelease:  0.05,
+	LimiterSustain:  0.8,
+	LimiterTruePeak: true,
+}
+
+// speechMacro is one setting the Amount macro moves, from min at 0 to max
+// at 1 through curve
+type speechMacro struct {
+	name     string
+	min, max float64
+	curve    modmatrix.Curve
+	set      func(s *SpeechStack, plain float64)
+}
+
+// speechMacros spreads one Amount knob across the stack: more amount
+// filters more rumble, de-esses earlier, rides over a wider range and
+// drives the limiter harder
+var speechMacros = []speechMacro{
+	{"Highpass", 40, 120, modmatrix.CurveLogarithmic, func(s *SpeechStack, hz float64) {
+		s.highpass.SetHighpass(s.sampleRate, hz, s.preset.HighpassQ)
+	}},
+	{"De-Ess Threshold", -20, -38, modmatrix.CurveLinear, func(s *SpeechStack, dB float64) {
+		s.deEsser.SetThreshold(dB)
+	}},
+	{"Level Range", 2, 12, modmatrix.CurveLinear, func(s *SpeechStack, dB float64) {
+		s.leveler.SetRange(dB)
+	}},
+	{"Limiter Drive", 0, 6, modmatrix.CurveExponential, func(s *SpeechStack, dB float64) {
+		s.drive = float32(gain.DbToLinear(dB))
+	}},
+}
+
+// SpeechStack is a ready-made voice chain for podcasts and dialogue: a
+// highpass for rumble, a de-esser, a slow leveler that evens out the
+// speaker, and a lookahead limiter whose release lengthens under sustained
+// gain reduction so it does not pump. One Amount setting, 0 to 1, drives
+// the whole stack, directly through SetAmount or as a macro through a
+// modulation matrix with ConnectMacro.
+type SpeechStack struct {
+	sampleRate float64
+	preset     SpeechPreset
+	chain      *StereoChain
+
+	highpass *filter.Biquad
+	deEsser  *dynamics.DeEsser
+	leveler  *dynamics.Rider
+	limiter  *dynamics.Limiter
+	drive    float32 // Gain into the limiter
+}
+
+// NewSpeechStack creates a stereo speech stack with the Amount macro at 0.5
+func NewSpeechStack(sampleRate float64, preset SpeechPreset) *SpeechStack {
+	s := &SpeechStack{
+		sampleRate: sampleRate,
+		preset:     preset,
+		highpass:   filter.NewBiquad(2),
+		deEsser:    dynamics.NewDeEsser(sampleRate, 2),
+		leveler:    dynamics.NewRider(sampleRate, 2),
+		limiter:    dynamics.NewLimiter(sampleRate),
+		drive:      1,
+	}
+	s.deEsser.SetFrequency(preset.DeEssFrequency)
+	s.deEsser.SetRange(preset.DeEssRange)
+	s.leveler.SetDetector(dynamics.RiderLUFS)
+	s.leveler.SetTarget(preset.LevelTarget)
+	s.leveler.SetSpeed(preset.LevelSpeed)
+	s.limiter.SetThreshold(preset.Ceiling)
+	s.limiter.SetRelease(preset.LimiterRelease)
+	s.limiter.SetSlowRelease(preset.LimiterSustain)
+	s.limiter.SetTruePeak(preset.LimiterTruePeak)
+	s.SetAmount(0.5)
+
+	s.chain = NewStereoChain("Speech").
+		Add(&biquadStage{s.highpass}).
+		Add(&stereoStage{s.deEsser.ProcessStereo, s.deEsser.Reset}).
+		Add(&stereoStage{s.leveler.ProcessStereo, s.leveler.Reset}).
+		Add(&driveStage{&s.drive}).
+		Add(&stereoStage{s.limiter.ProcessStereo, s.limiter.Reset})
+	return s
+}
+
+// SetAmount sets every stage from one 0-1 amount, as the macro would
+func (s *SpeechStack) SetAmount(amount float64) {
+	amount = max(0, min(1, amount))
+	for _, m := range speechMacros {
+		m.set(s, m.min+m.curve.Apply(amount)*(m.max-m.min))
+	}
+}
+
+// ConnectMacro adds the stack's settings to a modulation matrix as setter
+// destinations, each connected to macro at full depth with its own curve.
+// The matrix then drives the stack on every Process; the bases sit at the
+// zero end, so the macro source should be unipolar.
+func (s *SpeechStack) ConnectMacro(m *modmatrix.Matrix, macro modmatrix.SourceID) error {
+	for _, sm := range speechMacros {
+		set := sm.set
+		dst := m.AddSetter(sm.name, sm.min, sm.max, sm.min, func(plain float64) { set(s, plain) })
+		if _, err := m.Connect(macro, dst, 1, sm.curve); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessStereo processes stereo audio in place
+func (s *SpeechStack) ProcessStereo(left, right []float32) {
+	s.chain.ProcessStereo(left, right)
+}
+
+// Reset clears the state of every stage
+func (s *SpeechStack) Reset() {
+	s.chain.Reset()
+}
+
+// SetBypass passes audio through unprocessed; the latency remains
+func (s *SpeechStack) SetBypass(bypass bool) {
+	s.chain.SetBypass(bypass)
+}
+
+// GetLatencySamples returns the delay of the leveler and limiter
+// lookaheads
+func (s *SpeechStack) GetLatencySamples() int {
+	return s.leveler.GetLatencySamples() + s.limiter.GetLatencySamples()
+}
+
+// DeEsser returns the de-esser stage for metering or further settings
+func (s *SpeechStack) DeEsser() *dynamics.DeEsser {
+	return s.deEsser
+}
+
+// Leveler returns the leveler stage
+func (s *SpeechStack) Leveler() *dynamics.Rider {
+	return s.leveler
+}
+
+// Limiter returns the limiter stage
+func (s *SpeechStack) Limiter() *dynamics.Limiter {
+	return s.limiter
+}
+
+// biquadStage runs a two-channel biquad as a stereo stage
+type biquadStage struct {
+	filter *filter.Biquad
+}
+
+func (b *biquadStage) ProcessStereo(left, right []float32) {
+	b.filter.Process(left, 0)
+	b.filter.Process(right, 1)
+}
+
+func (b *biquadStage) Reset() {
+	b.filter.Reset()
+}
+
+// stereoStage adapts an in/out stereo processor to process in place
+type stereoStage struct {
+	process func(inputL, inputR, outputL, outputR []float32)
+	reset   func()
+}
+
+func (s *stereoStage) ProcessStereo(left, right []float32) {
+	s.process(left, right, left, right)
+}
+
+func (s *stereoStage) Reset() {
+	s.reset()
+}
+
+// driveStage applies a gain the macro sets
+type driveStage struct {
+	gain *float32
+}
+
+func (d *driveStage) ProcessStereo(left, right []float32) {
+	if g := *d.gain; g != 1 {
+		gain.ApplyBuffer(left, g)
+		gain.ApplyBuffer(right, g)
+	}
+}
+
+func (d *driveStage) Reset() {}
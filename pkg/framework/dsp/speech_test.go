@@ -0,0 +1,73 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/modmatrix"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+func TestSpeechStackCeiling(t *testing.T) {
+	const sr = 48000
+	s := NewSpeechStack(sr, PodcastPreset)
+	s.SetAmount(1)
+
+	// Loud, bursty noise standing in for an excited speaker
+	r := rand.New(rand.NewSource(1))
+	left, right := make([]float32, sr), make([]float32, sr)
+	for i := range left {
+		env := float32(1 + 3*math.Abs(math.Sin(2*math.Pi*3*float64(i)/sr)))
+		left[i] = env * float32(r.NormFloat64())
+		right[i] = env * float32(r.NormFloat64())
+	}
+	for i := 0; i < len(left); i += 480 {
+		s.ProcessStereo(left[i:i+480], right[i:i+480])
+	}
+
+	ceiling := float32(math.Pow(10, PodcastPreset.Ceiling/20)) * 1.01
+	for i := range left {
+		if math.Abs(float64(left[i])) > float64(ceiling) || math.Abs(float64(right[i])) > float64(ceiling) {
+			t.Fatalf("sample %d at %g/%g is over the %g dB ceiling", i, left[i], right[i], PodcastPreset.Ceiling)
+		}
+	}
+	if s.GetLatencySamples() <= 0 {
+		t.Error("no latency reported for the lookaheads")
+	}
+}
+
+func TestSpeechStackMacro(t *testing.T) {
+	const paramAmount = 0
+	params := param.NewRegistry()
+	if err := params.Add(param.New(paramAmount, "Amount").Default(0).Build()); err != nil {
+		t.Fatal(err)
+	}
+	ctx := process.NewContext(64, params)
+	ctx.Input = [][]float32{make([]float32, 64)}
+
+	s := NewSpeechStack(48000, PodcastPreset)
+	m := modmatrix.New(params)
+	if err := s.ConnectMacro(m, m.AddSource("Amount", modmatrix.NewMacroSource(paramAmount, false))); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, amount := range []float64{0, 0.5, 1} {
+		params.Get(paramAmount).SetValue(amount)
+		ctx.CaptureParams()
+		m.Process(ctx)
+
+		direct := NewSpeechStack(48000, PodcastPreset)
+		direct.SetAmount(amount)
+		if got, want := s.DeEsser().GetThreshold(), direct.DeEsser().GetThreshold(); math.Abs(got-want) > 1e-9 {
+			t.Errorf("amount %g: de-ess threshold %g through the matrix, %g direct", amount, got, want)
+		}
+		if got, want := s.Leveler().GetRange(), direct.Leveler().GetRange(); math.Abs(got-want) > 1e-9 {
+			t.Errorf("amount %g: level range %g through the matrix, %g direct", amount, got, want)
+		}
+	}
+	if got := s.Leveler().GetRange(); got != 12 {
+		t.Errorf("level range %g at full amount, want 12", got)
+	}
+}
@@ -0,0 +1,63 @@
+package message
+
+import "math"
+
+// Interval paces message sending at control rate from the audio thread.
+// Call Due once per block with the block length; it reports true about rate
+// times a second, independently of the block size.
+type Interval struct {
+	rate       float64
+	sampleRate float64
+	period     float64 // Samples between sends
+	elapsed    float64 // Samples since the last send
+}
+
+// NewInterval creates an interval that is due rate times a second at the
+// given sample rate
+func NewInterval(rate, sampleRate float64) *Interval {
+	iv := &Interval{rate: rate}
+	iv.SetSampleRate(sampleRate)
+	return iv
+}
+
+// SetSampleRate updates the sample rate
+func (iv *Interval) SetSampleRate(sampleRate float64) {
+	iv.sampleRate = sampleRate
+	iv.update()
+}
+
+// SetRate sets how many times a second the interval is due
+func (iv *Interval) SetRate(rate float64) {
+	iv.rate = rate
+	iv.update()
+}
+
+// GetRate returns how many times a second the interval is due
+func (iv *Interval) GetRate() float64 {
+	return iv.rate
+}
+
+// update recomputes the period, at least one sample
+func (iv *Interval) update() {
+	iv.period = 1
+	if iv.rate > 0 {
+		iv.period = math.Max(1, iv.sampleRate/iv.rate)
+	}
+}
+
+// Due advances the interval by a block of samples and reports whether a
+// message should be sent after it. Blocks longer than the period are due
+// once; the missed sends are not made up.
+func (iv *Interval) Due(samples int) bool {
+	iv.elapsed += float64(samples)
+	if iv.elapsed < iv.period {
+		return false
+	}
+	iv.elapsed = math.Mod(iv.elapsed, iv.period)
+	return true
+}
+
+// Reset restarts the interval so the next send is a full period away
+func (iv *Interval) Reset() {
+	iv.elapsed = 0
+}
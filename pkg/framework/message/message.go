@@ -0,0 +1,157 @@
+// Package message carries metering and analysis data such as spectrum
+// frames, gain reduction curves and loudness readings from the audio
+// processor to the edit controller or GUI. The processor sends small
+// binary messages from the audio thread without allocating or locking, and
+// the controller side drains them at control rate. On the VST3 side the
+// messages travel as IMessage notifications through IConnectionPoint,
+// so read-only parameters are no longer needed to get meter data out of
+// the processor.
+package message
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/justyntemme/vst3go/pkg/rt"
+)
+
+// DataAttribute is the IMessage attribute that holds a message's data
+const DataAttribute = "data"
+
+// DefaultRate is the control rate in Hz at which messages are normally sent
+// and delivered; faster than the eye follows a meter and far slower than
+// the audio rate
+const DefaultRate = 30.0
+
+// Message is a binary message with an ID telling the receiver how to decode
+// its data
+type Message struct {
+	ID   string
+	Data []byte
+}
+
+// slot is a preallocated message buffer
+type slot struct {
+	id   string
+	data []byte
+}
+
+// Channel is a bounded queue of messages from the audio thread to one or
+// more controller-side readers. Sending never blocks or allocates: a full
+// channel or an oversized message drops the message and counts it.
+type Channel struct {
+	slots   []slot
+	free    *rt.Ring[int32] // Slots the sender may fill
+	ready   *rt.Ring[int32] // Filled slots in send order
+	maxSize int
+
+	receiveMu sync.Mutex // Serializes readers, never taken by the sender
+	dropped   atomic.Uint64
+}
+
+// NewChannel creates a channel holding up to capacity messages of at most
+// maxSize bytes each
+func NewChannel(capacity, maxSize int) *Channel {
+	capacity = max(capacity, 1)
+	c := &Channel{
+		slots:   make([]slot, capacity),
+		free:    rt.NewRing[int32](capacity),
+		ready:   rt.NewRing[int32](capacity),
+		maxSize: max(maxSize, 0),
+	}
+	for i := range c.slots {
+		c.slots[i].data = make([]byte, 0, c.maxSize)
+		c.free.Push(int32(i))
+	}
+	return c
+}
+
+// MaxSize returns the largest message data in bytes the channel accepts
+func (c *Channel) MaxSize() int {
+	return c.maxSize
+}
+
+// Dropped returns the number of messages dropped because the channel was
+// full or the data too large
+func (c *Channel) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// acquire takes a free slot for a message of size bytes, counting a drop
+// when there is none or the message does not fit
+func (c *Channel) acquire(size int) (int32, bool) {
+	if size > c.maxSize {
+		c.dropped.Add(1)
+		return 0, false
+	}
+	index, ok := c.free.Pop()
+	if !ok {
+		c.dropped.Add(1)
+		return 0, false
+	}
+	return index, true
+}
+
+// Send queues a copy of data under id and returns false if the message was
+// dropped. It is safe on the audio thread; only one goroutine may send.
+func (c *Channel) Send(id string, data []byte) bool {
+	index, ok := c.acquire(len(data))
+	if !ok {
+		return false
+	}
+	s := &c.slots[index]
+	s.id = id
+	s.data = append(s.data[:0], data...)
+	c.ready.Push(index)
+	return true
+}
+
+// SendFloats queues values as little-endian float32 data under id, the
+// format Floats decodes. Like Send it is safe on the audio thread.
+func (c *Channel) SendFloats(id string, values []float32) bool {
+	index, ok := c.acquire(4 * len(values))
+	if !ok {
+		return false
+	}
+	s := &c.slots[index]
+	s.id = id
+	s.data = s.data[:4*len(values)]
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(s.data[4*i:], math.Float32bits(v))
+	}
+	c.ready.Push(index)
+	return true
+}
+
+// Receive calls fn for every queued message in send order and returns the
+// number of messages delivered. The message data is only valid during the
+// call; copy it to keep it. Receive must not be called from the audio
+// thread.
+func (c *Channel) Receive(fn func(Message)) int {
+	c.receiveMu.Lock()
+	defer c.receiveMu.Unlock()
+
+	n := 0
+	for {
+		index, ok := c.ready.Pop()
+		if !ok {
+			return n
+		}
+		s := &c.slots[index]
+		fn(Message{ID: s.id, Data: s.data})
+		c.free.Push(index)
+		n++
+	}
+}
+
+// Floats decodes little-endian float32 data written by SendFloats into dst,
+// growing it as needed, and returns the values
+func Floats(data []byte, dst []float32) []float32 {
+	dst = dst[:0]
+	for i := 0; i+4 <= len(data); i += 4 {
+		dst = append(dst, math.Float32frombits(binary.LittleEndian.Uint32(data[i:])))
+	}
+	return dst
+}
@@ -0,0 +1,134 @@
+package message
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestChannelSendReceive(t *testing.T) {
+	c := NewChannel(4, 16)
+	if !c.Send("gr", []byte{1, 2, 3}) {
+		t.Fatal("send failed on an empty channel")
+	}
+	if !c.SendFloats("spectrum", []float32{0.5, -1, 2}) {
+		t.Fatal("float send failed")
+	}
+
+	var ids []string
+	var floats []float32
+	n := c.Receive(func(m Message) {
+		ids = append(ids, m.ID)
+		switch m.ID {
+		case "gr":
+			if !bytes.Equal(m.Data, []byte{1, 2, 3}) {
+				t.Errorf("gr data %v", m.Data)
+			}
+		case "spectrum":
+			floats = Floats(m.Data, floats)
+		}
+	})
+	if n != 2 || len(ids) != 2 || ids[0] != "gr" || ids[1] != "spectrum" {
+		t.Fatalf("received %d messages %v, want gr then spectrum", n, ids)
+	}
+	if len(floats) != 3 || floats[0] != 0.5 || floats[1] != -1 || floats[2] != 2 {
+		t.Errorf("decoded floats %v", floats)
+	}
+	if c.Receive(func(Message) {}) != 0 {
+		t.Error("messages delivered twice")
+	}
+}
+
+func TestChannelDrops(t *testing.T) {
+	c := NewChannel(2, 8)
+	c.Send("a", nil)
+	c.Send("b", nil)
+	if c.Send("c", nil) {
+		t.Error("send succeeded on a full channel")
+	}
+	if c.Send("d", make([]byte, 9)) {
+		t.Error("send succeeded with data over the maximum size")
+	}
+	if c.SendFloats("e", make([]float32, 3)) {
+		t.Error("float send succeeded with data over the maximum size")
+	}
+	if c.Dropped() != 3 {
+		t.Errorf("dropped %d, want 3", c.Dropped())
+	}
+
+	// Receiving frees the slots again
+	c.Receive(func(Message) {})
+	if !c.Send("f", make([]byte, 8)) {
+		t.Error("send failed after the channel was drained")
+	}
+}
+
+func TestChannelZeroAlloc(t *testing.T) {
+	c := NewChannel(4, 64)
+	data := make([]byte, 32)
+	values := make([]float32, 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		c.Send("gr", data)
+		c.SendFloats("spectrum", values)
+		c.Receive(func(Message) {})
+	})
+	if allocs != 0 {
+		t.Errorf("%g allocations per send and receive", allocs)
+	}
+}
+
+func TestChannelConcurrent(t *testing.T) {
+	const total = 10000
+	c := NewChannel(8, 8)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; {
+			if c.Send("n", []byte{byte(i)}) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	next := 0
+	for next < total {
+		n := c.Receive(func(m Message) {
+			if m.Data[0] != byte(next) {
+				t.Fatalf("message %d carried %d", next, m.Data[0])
+			}
+			next++
+		})
+		if n == 0 {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+}
+
+func TestInterval(t *testing.T) {
+	iv := NewInterval(30, 48000)
+	sends := 0
+	for i := 0; i < 48000/64; i++ {
+		if iv.Due(64) {
+			sends++
+		}
+	}
+	if sends != 30 {
+		t.Errorf("due %d times in a second, want 30", sends)
+	}
+
+	// A block longer than the period is due once
+	iv.Reset()
+	if !iv.Due(48000) || iv.Due(1) {
+		t.Error("long block not due exactly once")
+	}
+
+	iv.SetRate(0)
+	if !iv.Due(1) {
+		t.Error("zero rate not due every block")
+	}
+}
@@ -0,0 +1,341 @@
+// Package midilearn maps incoming MIDI controllers to parameters at
+// runtime. Controllers are learned by moving them after Learn, can be 7-bit
+// CCs, 14-bit CC pairs or NRPNs, and take over parameters by jumping,
+// picking up the current value or scaling toward it. Mappings are saved in
+// the plugin's custom state.
+package midilearn
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/rt"
+)
+
+// Errors returned by Learner
+var (
+	ErrUnknownParameter = errors.New("unknown parameter")
+	ErrInvalidMapping   = errors.New("invalid MIDI mapping")
+	ErrInvalidState     = errors.New("invalid MIDI mapping state")
+)
+
+// OmniChannel makes a source respond to every MIDI channel
+const OmniChannel = -1
+
+// Kind is the kind of controller message a source listens to
+type Kind uint8
+
+const (
+	// KindCC is a 7-bit continuous controller
+	KindCC Kind = iota
+	// KindCC14 is a 14-bit controller: CC 0-31 carries the coarse value
+	// and the controller 32 above it the fine value
+	KindCC14
+	// KindNRPN is a 14-bit non-registered parameter number, selected with
+	// CC 99 and 98 and set with data entry CC 6 and 38
+	KindNRPN
+)
+
+// String returns the kind name
+func (k Kind) String() string {
+	switch k {
+	case KindCC:
+		return "CC"
+	case KindCC14:
+		return "CC14"
+	case KindNRPN:
+		return "NRPN"
+	default:
+		return "Unknown"
+	}
+}
+
+// Source identifies a controller
+type Source struct {
+	Kind    Kind
+	Channel int    // 0-15 or OmniChannel
+	Number  uint16 // Controller 0-127 (0-31 for KindCC14) or NRPN 0-16383
+}
+
+// String returns a short description such as "CC 74 ch 1"
+func (s Source) String() string {
+	if s.Channel == OmniChannel {
+		return fmt.Sprintf("%s %d", s.Kind, s.Number)
+	}
+	return fmt.Sprintf("%s %d ch %d", s.Kind, s.Number, s.Channel+1)
+}
+
+// valid reports whether the source can be received
+func (s Source) valid() bool {
+	if s.Channel < OmniChannel || s.Channel > 15 {
+		return false
+	}
+	switch s.Kind {
+	case KindCC:
+		return s.Number < 128
+	case KindCC14:
+		return s.Number < 32
+	case KindNRPN:
+		return s.Number < 16384
+	}
+	return false
+}
+
+// matches reports whether a message on channel hits the source
+func (s Source) matches(kind Kind, channel uint8, number uint16) bool {
+	return s.Kind == kind && s.Number == number && (s.Channel == OmniChannel || s.Channel == int(channel))
+}
+
+// Mode is how a controller takes over a parameter whose value differs from
+// the controller position
+type Mode uint8
+
+const (
+	// ModeJump sets the parameter to the controller position at once
+	ModeJump Mode = iota
+	// ModePickup ignores the controller until it reaches the parameter
+	// value, then follows it
+	ModePickup
+	// ModeScaled moves the parameter in the direction of the controller
+	// by the share of the remaining range, so both arrive at the end
+	// together and then move as one
+	ModeScaled
+)
+
+// String returns the mode name
+func (m Mode) String() string {
+	switch m {
+	case ModeJump:
+		return "Jump"
+	case ModePickup:
+		return "Pickup"
+	case ModeScaled:
+		return "Scaled"
+	default:
+		return "Unknown"
+	}
+}
+
+// Mapping connects a controller to a parameter
+type Mapping struct {
+	Source  Source
+	ParamID uint32
+	// Min and Max are the normalized parameter values at the controller's
+	// lowest and highest positions; Min above Max inverts the controller
+	Min, Max float64
+	Mode     Mode
+}
+
+// entry is a mapping in the published table. The mapping is immutable;
+// the takeover state is owned by the audio thread.
+type entry struct {
+	Mapping
+	param *param.Parameter
+
+	engaged bool    // Pickup reached the parameter value
+	sent    float64 // Value last set, to notice changes made elsewhere
+	last    float64 // Previous controller position 0-1
+	hasLast bool
+}
+
+// table is an immutable set of mappings, republished on every change
+type table struct {
+	entries []*entry
+}
+
+// learned is a controller found by the audio thread while learning
+type learned struct {
+	source  Source
+	paramID uint32
+}
+
+// Learner maps MIDI controllers to the parameters of a registry. Process
+// runs on the audio thread; everything else is for the UI or controller
+// thread.
+type Learner struct {
+	registry *param.Registry
+	mode     Mode
+	omni     bool
+
+	mu    sync.Mutex // Serializes table changes
+	index atomic.Pointer[table]
+
+	// Learn target, the parameter ID plus one; 0 when not learning
+	target  atomic.Uint64
+	learned *rt.Ring[learned]
+
+	parser parser // Audio thread only
+}
+
+// NewLearner creates a learner with no mappings. Learned mappings use
+// ModePickup and the channel they were learned on.
+func NewLearner(registry *param.Registry) *Learner {
+	l := &Learner{
+		registry: registry,
+		mode:     ModePickup,
+		learned:  rt.NewRing[learned](16),
+	}
+	l.parser.reset()
+	l.index.Store(&table{})
+	return l
+}
+
+// SetLearnMode sets the takeover mode of mappings made by learning
+func (l *Learner) SetLearnMode(mode Mode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mode = mode
+}
+
+// SetLearnOmni makes learned mappings respond on every channel instead of
+// only the channel they were learned on
+func (l *Learner) SetLearnOmni(omni bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.omni = omni
+}
+
+// Learn maps the next controller that moves to a parameter, replacing any
+// mapping the parameter or controller had. Call Poll afterwards to add the
+// learned mapping.
+func (l *Learner) Learn(paramID uint32) error {
+	if l.registry.Get(paramID) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownParameter, paramID)
+	}
+	l.target.Store(uint64(paramID) + 1)
+	return nil
+}
+
+// CancelLearn stops waiting for a controller
+func (l *Learner) CancelLearn() {
+	l.target.Store(0)
+}
+
+// Learning returns the parameter waiting for a controller, if any
+func (l *Learner) Learning() (uint32, bool) {
+	t := l.target.Load()
+	return uint32(t - 1), t != 0
+}
+
+// Poll adds mappings learned by the audio thread and returns them. Call it
+// regularly from the UI thread, e.g. on a timer, while learning.
+func (l *Learner) Poll() []Mapping {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pollLocked()
+}
+
+// pollLocked drains the learned ring; l.mu must be held
+func (l *Learner) pollLocked() []Mapping {
+	var added []Mapping
+	for {
+		found, ok := l.learned.Pop()
+		if !ok {
+			return added
+		}
+		m := Mapping{Source: found.source, ParamID: found.paramID, Min: 0, Max: 1, Mode: l.mode}
+		if l.omni {
+			m.Source.Channel = OmniChannel
+		}
+		if l.mapLocked(m, true) == nil {
+			added = append(added, m)
+		}
+	}
+}
+
+// Map adds a mapping, replacing any mapping of the same controller
+func (l *Learner) Map(m Mapping) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pollLocked()
+	return l.mapLocked(m, false)
+}
+
+// mapLocked publishes a table with the mapping added. Learned mappings also
+// replace the parameter's other mappings. l.mu must be held.
+func (l *Learner) mapLocked(m Mapping, learnedMapping bool) error {
+	if !m.Source.valid() || m.Min < 0 || m.Min > 1 || m.Max < 0 || m.Max > 1 || m.Mode > ModeScaled {
+		return fmt.Errorf("%w: %s", ErrInvalidMapping, m.Source)
+	}
+	p := l.registry.Get(m.ParamID)
+	if p == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownParameter, m.ParamID)
+	}
+
+	old := l.index.Load().entries
+	entries := make([]*entry, 0, len(old)+1)
+	for _, e := range old {
+		if e.Source == m.Source || (learnedMapping && e.ParamID == m.ParamID) {
+			continue
+		}
+		entries = append(entries, e.fresh())
+	}
+	entries = append(entries, &entry{Mapping: m, param: p})
+	l.index.Store(&table{entries: entries})
+	return nil
+}
+
+// fresh copies an entry's mapping without its takeover state
+func (e *entry) fresh() *entry {
+	return &entry{Mapping: e.Mapping, param: e.param}
+}
+
+// Unmap removes every mapping of a parameter and returns how many there were
+func (l *Learner) Unmap(paramID uint32) int {
+	return l.remove(func(m Mapping) bool { return m.ParamID == paramID })
+}
+
+// UnmapSource removes the mapping of a controller
+func (l *Learner) UnmapSource(source Source) bool {
+	return l.remove(func(m Mapping) bool { return m.Source == source }) > 0
+}
+
+// Clear removes every mapping
+func (l *Learner) Clear() {
+	l.remove(func(Mapping) bool { return true })
+}
+
+// remove publishes a table without the matching mappings
+func (l *Learner) remove(match func(Mapping) bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pollLocked()
+
+	old := l.index.Load().entries
+	entries := make([]*entry, 0, len(old))
+	for _, e := range old {
+		if !match(e.Mapping) {
+			entries = append(entries, e.fresh())
+		}
+	}
+	l.index.Store(&table{entries: entries})
+	return len(old) - len(entries)
+}
+
+// Mappings returns the current mappings in the order they were made
+func (l *Learner) Mappings() []Mapping {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pollLocked()
+
+	entries := l.index.Load().entries
+	mappings := make([]Mapping, len(entries))
+	for i, e := range entries {
+		mappings[i] = e.Mapping
+	}
+	return mappings
+}
+
+// MappingsFor returns the mappings of one parameter
+func (l *Learner) MappingsFor(paramID uint32) []Mapping {
+	var result []Mapping
+	for _, m := range l.Mappings() {
+		if m.ParamID == paramID {
+			result = append(result, m)
+		}
+	}
+	return result
+}
@@ -0,0 +1,205 @@
+package midilearn
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/midi"
+)
+
+const (
+	paramCutoff uint32 = iota
+	paramMix
+	paramMode
+)
+
+func newTestLearner(t *testing.T) (*Learner, *process.Context, *param.Registry) {
+	t.Helper()
+	params := param.NewRegistry()
+	params.Add(
+		param.New(paramCutoff, "Cutoff").Range(0, 1000).Default(500).Build(),
+		param.New(paramMix, "Mix").Range(0, 100).Default(0).Build(),
+		param.New(paramMode, "Mode").Range(0, 3).Steps(3).Default(0).Build(),
+	)
+	ctx := process.NewContext(64, params)
+	ctx.Input = [][]float32{make([]float32, 64)}
+	ctx.CaptureParams()
+	return NewLearner(params), ctx, params
+}
+
+// send runs one block with the given controller messages on a channel
+func send(l *Learner, ctx *process.Context, channel uint8, pairs ...uint8) {
+	ctx.ClearInputEvents()
+	for i := 0; i+1 < len(pairs); i += 2 {
+		ctx.AddInputEvent(midi.ControlChangeEvent{
+			BaseEvent:  midi.BaseEvent{EventChannel: channel, Offset: int32(i / 2)},
+			Controller: pairs[i],
+			Value:      pairs[i+1],
+		})
+	}
+	l.Process(ctx)
+}
+
+func TestLearnAndJump(t *testing.T) {
+	l, ctx, params := newTestLearner(t)
+	l.SetLearnMode(ModeJump)
+	if err := l.Learn(99); !errors.Is(err, ErrUnknownParameter) {
+		t.Errorf("Learn unknown = %v", err)
+	}
+
+	l.Learn(paramMix)
+	if id, ok := l.Learning(); !ok || id != paramMix {
+		t.Fatal("should be learning Mix")
+	}
+	send(l, ctx, 2, 74, 10)
+	if _, ok := l.Learning(); ok {
+		t.Fatal("learning should end on the first controller")
+	}
+	added := l.Poll()
+	want := Source{Kind: KindCC, Channel: 2, Number: 74}
+	if len(added) != 1 || added[0].Source != want || added[0].ParamID != paramMix {
+		t.Fatalf("learned %+v", added)
+	}
+
+	send(l, ctx, 2, 74, 127)
+	if params.Get(paramMix).GetValue() != 1 {
+		t.Errorf("Mix = %g, want 1", params.Get(paramMix).GetValue())
+	}
+	send(l, ctx, 3, 74, 0)
+	if params.Get(paramMix).GetValue() != 1 {
+		t.Error("other channels should be ignored")
+	}
+}
+
+func TestFourteenBitAndNRPN(t *testing.T) {
+	l, ctx, params := newTestLearner(t)
+	l.SetLearnMode(ModeJump)
+
+	// Coarse and fine halves in one block learn a 14-bit controller
+	l.Learn(paramCutoff)
+	send(l, ctx, 0, 1, 64, 33, 0)
+	if m := l.Poll(); len(m) != 1 || m[0].Source.Kind != KindCC14 || m[0].Source.Number != 1 {
+		t.Fatalf("learned %+v, want CC14 1", m)
+	}
+	send(l, ctx, 0, 1, 127, 33, 127)
+	if params.Get(paramCutoff).GetValue() != 1 {
+		t.Errorf("Cutoff = %g, want 1", params.Get(paramCutoff).GetValue())
+	}
+	send(l, ctx, 0, 1, 64, 33, 0)
+	if got := params.Get(paramCutoff).GetValue(); math.Abs(got-8192.0/16383) > 1e-12 {
+		t.Errorf("Cutoff = %g, want %g", got, 8192.0/16383)
+	}
+
+	// NRPN 0x0102 on channel 5
+	l.Learn(paramMix)
+	send(l, ctx, 5, 99, 1, 98, 2, 6, 32)
+	m := l.Poll()
+	if len(m) != 1 || m[0].Source != (Source{Kind: KindNRPN, Channel: 5, Number: 130}) {
+		t.Fatalf("learned %+v, want NRPN 130", m)
+	}
+	send(l, ctx, 5, 99, 1, 98, 2, 6, 127, 38, 127)
+	if params.Get(paramMix).GetValue() != 1 {
+		t.Errorf("Mix = %g, want 1", params.Get(paramMix).GetValue())
+	}
+	// Data entry for another NRPN is not ours
+	send(l, ctx, 5, 99, 1, 98, 3, 6, 0)
+	if params.Get(paramMix).GetValue() != 1 {
+		t.Error("other NRPN changed Mix")
+	}
+}
+
+func TestPickup(t *testing.T) {
+	l, ctx, params := newTestLearner(t)
+	source := Source{Kind: KindCC, Channel: OmniChannel, Number: 10}
+	l.Map(Mapping{Source: source, ParamID: paramCutoff, Min: 0, Max: 1, Mode: ModePickup})
+
+	// Cutoff sits at 0.5; the controller below it is ignored
+	send(l, ctx, 0, 10, 20)
+	send(l, ctx, 0, 10, 40)
+	if params.Get(paramCutoff).GetValue() != 0.5 {
+		t.Fatal("pickup should wait for the controller")
+	}
+	// Crossing the value picks it up
+	send(l, ctx, 0, 10, 80)
+	if got := params.Get(paramCutoff).GetValue(); got != 80.0/127 {
+		t.Errorf("Cutoff = %g after pickup, want %g", got, 80.0/127)
+	}
+	send(l, ctx, 0, 10, 20)
+	if got := params.Get(paramCutoff).GetValue(); got != 20.0/127 {
+		t.Errorf("Cutoff = %g, want it to follow", got)
+	}
+
+	// A change from elsewhere has to be picked up again
+	params.Get(paramCutoff).SetValue(0.9)
+	send(l, ctx, 0, 10, 30)
+	if params.Get(paramCutoff).GetValue() != 0.9 {
+		t.Error("pickup should release after an outside change")
+	}
+}
+
+func TestScaled(t *testing.T) {
+	l, ctx, params := newTestLearner(t)
+	l.Map(Mapping{Source: Source{Kind: KindCC, Channel: OmniChannel, Number: 7}, ParamID: paramCutoff, Max: 1, Mode: ModeScaled})
+
+	send(l, ctx, 0, 7, 0) // Establishes the controller position
+	if params.Get(paramCutoff).GetValue() != 0.5 {
+		t.Fatal("first message should not move the parameter")
+	}
+	send(l, ctx, 0, 7, 127)
+	if got := params.Get(paramCutoff).GetValue(); got != 1 {
+		t.Errorf("Cutoff = %g, want both at the top", got)
+	}
+	send(l, ctx, 0, 7, 0)
+	if got := params.Get(paramCutoff).GetValue(); got != 0 {
+		t.Errorf("Cutoff = %g, want both at the bottom", got)
+	}
+}
+
+func TestMappingsAndState(t *testing.T) {
+	l, _, _ := newTestLearner(t)
+	cc := Source{Kind: KindCC, Channel: 0, Number: 74}
+	if err := l.Map(Mapping{Source: Source{Kind: KindCC14, Number: 40}, ParamID: paramMix, Max: 1}); !errors.Is(err, ErrInvalidMapping) {
+		t.Errorf("CC14 above 31: err = %v", err)
+	}
+	l.Map(Mapping{Source: cc, ParamID: paramCutoff, Min: 1, Max: 0, Mode: ModeScaled})
+	l.Map(Mapping{Source: Source{Kind: KindNRPN, Channel: OmniChannel, Number: 300}, ParamID: paramMix, Max: 0.5})
+	l.Map(Mapping{Source: cc, ParamID: paramMode, Max: 1}) // Replaces the first
+	if m := l.Mappings(); len(m) != 2 || m[1].ParamID != paramMode || len(l.MappingsFor(paramMix)) != 1 {
+		t.Fatalf("Mappings = %+v", m)
+	}
+
+	var buf bytes.Buffer
+	if err := l.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored, _, _ := newTestLearner(t)
+	if err := restored.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	got, want := restored.Mappings(), l.Mappings()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("restored %+v, want %+v", got, want)
+	}
+	if err := restored.Load(bytes.NewReader([]byte("nope"))); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("bad state: err = %v", err)
+	}
+
+	if !l.UnmapSource(cc) || l.Unmap(paramMix) != 1 || len(l.Mappings()) != 0 {
+		t.Error("unmapping should remove both mappings")
+	}
+}
+
+func TestProcessNoAllocs(t *testing.T) {
+	l, ctx, _ := newTestLearner(t)
+	l.Map(Mapping{Source: Source{Kind: KindCC, Channel: OmniChannel, Number: 1}, ParamID: paramCutoff, Max: 1})
+	ctx.AddInputEvent(midi.ControlChangeEvent{Controller: 1, Value: 64})
+	l.Process(ctx)
+	allocs := testing.AllocsPerRun(50, func() { l.Process(ctx) })
+	if allocs != 0 {
+		t.Errorf("Process allocates %.1f times", allocs)
+	}
+}
@@ -0,0 +1,213 @@
+package midilearn
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/midi"
+)
+
+// Controllers with a fixed meaning in NRPN and RPN messages
+const (
+	ccDataEntry    = 6
+	ccDataEntryLSB = 38
+	ccNRPNLSB      = 98
+	ccNRPNMSB      = 99
+	ccRPNLSB       = 100
+	ccRPNMSB       = 101
+)
+
+// parser tracks 14-bit and NRPN message state per channel (audio thread)
+type parser struct {
+	coarse  [16][32]int16 // Last coarse value of each 14-bit controller, -1 before the first
+	nrpnMSB [16]int16     // Selected NRPN coarse number, -1 if none
+	nrpn    [16]int32     // Selected NRPN, -1 if none or an RPN is selected
+	dataMSB [16]int16     // Last data entry coarse value, -1 before the first
+
+	// Coarse controller waiting to see if its fine half follows while learning
+	pending    bool
+	pendingSrc Source
+	events     []midi.Event // Reused each block
+}
+
+// reset forgets all message state
+func (p *parser) reset() {
+	for ch := range p.coarse {
+		for i := range p.coarse[ch] {
+			p.coarse[ch][i] = -1
+		}
+		p.nrpnMSB[ch] = -1
+		p.nrpn[ch] = -1
+		p.dataMSB[ch] = -1
+	}
+	p.pending = false
+}
+
+// Reset forgets partial 14-bit and NRPN messages and the takeover state of
+// every mapping. Call from the audio thread or while processing is stopped.
+func (l *Learner) Reset() {
+	l.parser.reset()
+	for _, e := range l.index.Load().entries {
+		e.engaged, e.hasLast = false, false
+	}
+}
+
+// Process applies this block's controller messages to the mapped parameters
+// and finds the controller to learn. Values set are reported to the host
+// with WriteModulation. Call once per process call on the audio thread; it
+// does not allocate once the event buffer has grown.
+func (l *Learner) Process(ctx *process.Context) {
+	p := &l.parser
+	p.events = ctx.AppendInputEvents(p.events[:0])
+	t := l.index.Load()
+
+	for _, event := range p.events {
+		cc, ok := event.(midi.ControlChangeEvent)
+		if !ok {
+			continue
+		}
+		ch := cc.Channel() & 15
+		num := uint16(cc.Controller & 127)
+		v := int32(cc.Value & 127)
+		offset := int(cc.SampleOffset())
+
+		l.dispatch(ctx, t, KindCC, ch, num, float64(v)/127, offset)
+
+		// Data entry belongs to the selected NRPN, if any
+		nrpn := p.nrpn[ch]
+		if nrpn >= 0 && (num == ccDataEntry || num == ccDataEntryLSB) {
+			if num == ccDataEntry {
+				p.dataMSB[ch] = int16(v)
+				v <<= 7
+			} else if p.dataMSB[ch] >= 0 {
+				v |= int32(p.dataMSB[ch]) << 7
+			} else {
+				continue
+			}
+			l.dispatch(ctx, t, KindNRPN, ch, uint16(nrpn), float64(v)/16383, offset)
+			l.learn(Source{Kind: KindNRPN, Channel: int(ch), Number: uint16(nrpn)})
+			continue
+		}
+
+		switch {
+		case num < 32:
+			p.coarse[ch][num] = int16(v)
+			l.dispatch(ctx, t, KindCC14, ch, num, float64(v<<7)/16383, offset)
+		case num < 64:
+			if coarse := p.coarse[ch][num-32]; coarse >= 0 {
+				l.dispatch(ctx, t, KindCC14, ch, num-32, float64(int32(coarse)<<7|v)/16383, offset)
+			}
+		case num == ccNRPNMSB:
+			p.nrpnMSB[ch] = int16(v)
+			p.nrpn[ch] = -1
+			continue
+		case num == ccNRPNLSB:
+			if p.nrpnMSB[ch] >= 0 {
+				p.nrpn[ch] = int32(p.nrpnMSB[ch])<<7 | v
+				p.dataMSB[ch] = -1
+			}
+			continue
+		case num == ccRPNMSB || num == ccRPNLSB:
+			p.nrpnMSB[ch], p.nrpn[ch] = -1, -1
+			continue
+		}
+		l.learnCC(ch, num)
+	}
+
+	// A coarse controller without a fine half is a plain CC
+	if p.pending {
+		p.pending = false
+		l.learn(p.pendingSrc)
+	}
+}
+
+// dispatch applies a controller position to every mapping of the source
+func (l *Learner) dispatch(ctx *process.Context, t *table, kind Kind, ch uint8, number uint16, pos float64, offset int) {
+	for _, e := range t.entries {
+		if e.Source.matches(kind, ch, number) {
+			e.apply(ctx, pos, offset)
+		}
+	}
+}
+
+// learnCC learns a controller, waiting for the fine half of CC 0-31
+func (l *Learner) learnCC(ch uint8, num uint16) {
+	if l.target.Load() == 0 {
+		return
+	}
+	p := &l.parser
+	switch {
+	case num < 32:
+		p.pending = true
+		p.pendingSrc = Source{Kind: KindCC, Channel: int(ch), Number: num}
+	case num < 64 && p.pending && p.pendingSrc.Channel == int(ch) && p.pendingSrc.Number == num-32:
+		p.pending = false
+		l.learn(Source{Kind: KindCC14, Channel: int(ch), Number: num - 32})
+	default:
+		p.pending = false
+		l.learn(Source{Kind: KindCC, Channel: int(ch), Number: num})
+	}
+}
+
+// learn hands a controller to Poll if a parameter is waiting for one
+func (l *Learner) learn(source Source) {
+	target := l.target.Load()
+	if target == 0 {
+		return
+	}
+	l.parser.pending = false
+	if l.target.CompareAndSwap(target, 0) {
+		l.learned.Push(learned{source: source, paramID: uint32(target - 1)})
+	}
+}
+
+// apply moves the parameter toward a controller position 0-1
+func (e *entry) apply(ctx *process.Context, pos float64, offset int) {
+	p := e.param
+	current := p.GetValue()
+	span := e.Max - e.Min
+	target := e.Min + pos*span
+	tolerance := math.Max(math.Abs(span)/127, 1e-9)
+
+	var value float64
+	switch e.Mode {
+	case ModePickup:
+		if e.engaged && current != e.sent {
+			e.engaged = false // Changed elsewhere since: pick it up again
+		}
+		if !e.engaged {
+			crossed := e.hasLast && (e.Min+e.last*span-current)*(target-current) <= 0
+			if !crossed && math.Abs(target-current) > tolerance {
+				e.last, e.hasLast = pos, true
+				return
+			}
+			e.engaged = true
+		}
+		value = target
+
+	case ModeScaled:
+		if !e.hasLast || span == 0 {
+			e.last, e.hasLast = pos, true
+			return
+		}
+		at := math.Max(0, math.Min(1, (current-e.Min)/span))
+		if delta := pos - e.last; delta > 0 && e.last < 1 {
+			at += delta * (1 - at) / (1 - e.last)
+		} else if delta < 0 && e.last > 0 {
+			at += delta * at / e.last
+		}
+		value = e.Min + at*span
+
+	default:
+		value = target
+	}
+	e.last, e.hasLast = pos, true
+
+	if snapshots := ctx.ParamSnapshots(); snapshots != nil {
+		snapshots.Set(p.ID, value)
+	} else {
+		p.SetValue(value)
+	}
+	e.sent = p.GetValue()
+	ctx.WriteModulation(p.ID, e.sent, offset)
+}
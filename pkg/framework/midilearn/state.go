@@ -0,0 +1,101 @@
+package midilearn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// stateMagic and stateVersion identify saved mappings
+const (
+	stateMagic   = "MLRN"
+	stateVersion = 1
+)
+
+// savedMapping is the fixed-size form of a mapping in saved state
+type savedMapping struct {
+	Kind     uint8
+	Channel  int8
+	Number   uint16
+	ParamID  uint32
+	Min, Max float64
+	Mode     uint8
+}
+
+// Save writes the mappings, for a processor's SaveCustomState
+func (l *Learner) Save(w io.Writer) error {
+	mappings := l.Mappings()
+	if _, err := w.Write([]byte(stateMagic)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(stateVersion)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(mappings))); err != nil {
+		return err
+	}
+	for _, m := range mappings {
+		saved := savedMapping{
+			Kind:    uint8(m.Source.Kind),
+			Channel: int8(m.Source.Channel),
+			Number:  m.Source.Number,
+			ParamID: m.ParamID,
+			Min:     m.Min,
+			Max:     m.Max,
+			Mode:    uint8(m.Mode),
+		}
+		if err := binary.Write(w, binary.LittleEndian, &saved); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load replaces the mappings with ones written by Save, for a processor's
+// LoadCustomState. Mappings of parameters the plugin no longer has are
+// dropped.
+func (l *Learner) Load(r io.Reader) error {
+	header := make([]byte, len(stateMagic))
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if string(header) != stateMagic {
+		return ErrInvalidState
+	}
+	var version, count uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return err
+	}
+	if version > stateVersion {
+		return fmt.Errorf("%w: version %d is newer than supported version %d", ErrInvalidState, version, stateVersion)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return err
+	}
+
+	mappings := make([]Mapping, 0, min(count, 1024))
+	for i := uint32(0); i < count; i++ {
+		var saved savedMapping
+		if err := binary.Read(r, binary.LittleEndian, &saved); err != nil {
+			return err
+		}
+		mappings = append(mappings, Mapping{
+			Source:  Source{Kind: Kind(saved.Kind), Channel: int(saved.Channel), Number: saved.Number},
+			ParamID: saved.ParamID,
+			Min:     saved.Min,
+			Max:     saved.Max,
+			Mode:    Mode(saved.Mode),
+		})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pollLocked()
+	l.index.Store(&table{})
+	for _, m := range mappings {
+		if err := l.mapLocked(m, false); err != nil && l.registry.Get(m.ParamID) != nil {
+			return err
+		}
+	}
+	return nil
+}
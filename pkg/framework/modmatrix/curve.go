@@ -0,0 +1,49 @@
+package modmatrix
+
+import "math"
+
+// Curve shapes a source value before it is scaled by a connection's depth.
+// Curves act on the magnitude, so bipolar sources keep their sign.
+type Curve int
+
+const (
+	// CurveLinear passes the source through
+	CurveLinear Curve = iota
+	// CurveExponential squares the source, for fine control near zero
+	CurveExponential
+	// CurveLogarithmic takes the square root, rising quickly from zero
+	CurveLogarithmic
+	// CurveSCurve eases in and out with a smoothstep
+	CurveSCurve
+)
+
+// String returns the curve name
+func (c Curve) String() string {
+	switch c {
+	case CurveLinear:
+		return "Linear"
+	case CurveExponential:
+		return "Exponential"
+	case CurveLogarithmic:
+		return "Logarithmic"
+	case CurveSCurve:
+		return "S-Curve"
+	default:
+		return "Unknown"
+	}
+}
+
+// Apply shapes x, which is clamped to -1..1
+func (c Curve) Apply(x float64) float64 {
+	x = clamp(x, -1, 1)
+	mag := math.Abs(x)
+	switch c {
+	case CurveExponential:
+		mag *= mag
+	case CurveLogarithmic:
+		mag = math.Sqrt(mag)
+	case CurveSCurve:
+		mag = mag * mag * (3 - 2*mag)
+	}
+	return math.Copysign(mag, x)
+}
@@ -0,0 +1,367 @@
+// Package modmatrix routes modulation sources such as LFOs, envelopes,
+// macros, MIDI controllers and note expression to destinations: parameters
+// or the setters of DSP modules. Each connection has its own depth and
+// curve.
+//
+// The matrix is evaluated once per block: every source produces one value,
+// connections sum into their destinations, and each destination's setter
+// is called with its modulated plain value. Build the matrix when the
+// processor is created; Process, the depth and curve setters and the
+// accessors do not allocate.
+//
+//	m := modmatrix.New(params)
+//	lfo := m.AddSource("LFO 1", modmatrix.NewLFOSource(lfo1))
+//	cutoff, _ := m.AddParameter(ParamCutoff, filter.SetCutoff)
+//	m.Connect(lfo, cutoff, 0.25, modmatrix.CurveLinear)
+//
+//	// In ProcessAudio
+//	m.Process(ctx)
+package modmatrix
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+var (
+	// ErrUnknownSource is returned for source IDs the matrix does not have
+	ErrUnknownSource = errors.New("unknown modulation source")
+	// ErrUnknownDestination is returned for destination IDs or parameters
+	// the matrix does not have
+	ErrUnknownDestination = errors.New("unknown modulation destination")
+	// ErrUnknownConnection is returned for connection IDs the matrix does
+	// not have
+	ErrUnknownConnection = errors.New("unknown modulation connection")
+)
+
+// Source produces one modulation value per block
+type Source interface {
+	// Value advances the source over a block of n samples and returns its
+	// value for the block: -1 to 1 for bipolar sources, 0 to 1 for
+	// unipolar ones
+	Value(ctx *process.Context, n int) float64
+}
+
+// SourceFunc adapts a function to the Source interface
+type SourceFunc func(ctx *process.Context, n int) float64
+
+// Value calls f
+func (f SourceFunc) Value(ctx *process.Context, n int) float64 {
+	return f(ctx, n)
+}
+
+// SourceID identifies a source within a matrix
+type SourceID int
+
+// DestinationID identifies a destination within a matrix
+type DestinationID int
+
+// ConnectionID identifies a connection within a matrix
+type ConnectionID int
+
+type source struct {
+	name  string
+	src   Source
+	value float64
+}
+
+type destination struct {
+	name string
+
+	// Parameter destinations take their base value from the parameter
+	param *param.Parameter
+
+	// Setter destinations have a fixed range and a base set by SetBase
+	min, max float64
+	base     float64 // Normalized
+
+	set    func(plain float64)
+	last   float64 // Plain value last passed to set
+	called bool
+
+	amount     float64 // Sum of this block's connections
+	normalized float64 // Modulated value, 0-1
+	plain      float64
+}
+
+type connection struct {
+	source      SourceID
+	destination DestinationID
+	depth       float64
+	curve       Curve
+	enabled     bool
+
+	// Depth from a parameter instead, mapped from 0-1 to -1..1
+	depthParam    uint32
+	hasDepthParam bool
+}
+
+// Matrix connects modulation sources to destinations
+type Matrix struct {
+	params       *param.Registry
+	sources      []source
+	destinations []destination
+	connections  []connection
+}
+
+// New creates an empty matrix. params resolves parameter destinations and
+// may be nil for a matrix with setter destinations only.
+func New(params *param.Registry) *Matrix {
+	return &Matrix{params: params}
+}
+
+// AddSource adds a source and returns its ID
+func (m *Matrix) AddSource(name string, src Source) SourceID {
+	m.sources = append(m.sources, source{name: name, src: src})
+	return SourceID(len(m.sources) - 1)
+}
+
+// AddParameter adds a parameter as a destination. Its current value is the
+// base that modulation moves, in normalized units, and set receives the
+// modulated plain value; set may be nil when only Value is read. With
+// modulation output enabled on the context, the modulated value is also
+// reported to the host.
+func (m *Matrix) AddParameter(id uint32, set func(plain float64)) (DestinationID, error) {
+	var p *param.Parameter
+	if m.params != nil {
+		p = m.params.Get(id)
+	}
+	if p == nil {
+		return 0, fmt.Errorf("%w: parameter %d", ErrUnknownDestination, id)
+	}
+	m.destinations = append(m.destinations, destination{name: p.Name, param: p, set: set})
+	return DestinationID(len(m.destinations) - 1), nil
+}
+
+// AddSetter adds a destination that is not a parameter, such as a DSP
+// module setter. Modulation moves the value within min..max, starting from
+// base; depth 1 spans the whole range.
+func (m *Matrix) AddSetter(name string, min, max, base float64, set func(plain float64)) DestinationID {
+	d := destination{name: name, min: min, max: max, set: set}
+	d.base = d.normalize(base)
+	m.destinations = append(m.destinations, d)
+	return DestinationID(len(m.destinations) - 1)
+}
+
+// Connect routes a source to a destination. depth is in normalized units of
+// the destination, -1 to 1, and curve shapes the source value first.
+func (m *Matrix) Connect(src SourceID, dst DestinationID, depth float64, curve Curve) (ConnectionID, error) {
+	if src < 0 || int(src) >= len(m.sources) {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownSource, src)
+	}
+	if dst < 0 || int(dst) >= len(m.destinations) {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownDestination, dst)
+	}
+	m.connections = append(m.connections, connection{
+		source:      src,
+		destination: dst,
+		depth:       clamp(depth, -1, 1),
+		curve:       curve,
+		enabled:     true,
+	})
+	return ConnectionID(len(m.connections) - 1), nil
+}
+
+// SetDepth changes the depth of a connection
+func (m *Matrix) SetDepth(c ConnectionID, depth float64) error {
+	conn, err := m.connection(c)
+	if err != nil {
+		return err
+	}
+	conn.depth = clamp(depth, -1, 1)
+	conn.hasDepthParam = false
+	return nil
+}
+
+// SetDepthParameter makes a parameter control the depth of a connection,
+// so it can be automated: 0 is depth -1, 0.5 no modulation and 1 depth 1
+func (m *Matrix) SetDepthParameter(c ConnectionID, id uint32) error {
+	conn, err := m.connection(c)
+	if err != nil {
+		return err
+	}
+	if m.params == nil || m.params.Get(id) == nil {
+		return fmt.Errorf("%w: parameter %d", ErrUnknownDestination, id)
+	}
+	conn.depthParam, conn.hasDepthParam = id, true
+	return nil
+}
+
+// SetCurve changes the curve of a connection
+func (m *Matrix) SetCurve(c ConnectionID, curve Curve) error {
+	conn, err := m.connection(c)
+	if err != nil {
+		return err
+	}
+	conn.curve = curve
+	return nil
+}
+
+// SetEnabled turns a connection on or off
+func (m *Matrix) SetEnabled(c ConnectionID, enabled bool) error {
+	conn, err := m.connection(c)
+	if err != nil {
+		return err
+	}
+	conn.enabled = enabled
+	return nil
+}
+
+// SetBase sets the unmodulated plain value of a setter destination
+func (m *Matrix) SetBase(dst DestinationID, plain float64) error {
+	d, err := m.destination(dst)
+	if err != nil {
+		return err
+	}
+	if d.param != nil {
+		return fmt.Errorf("%w: %q takes its base from its parameter", ErrUnknownDestination, d.name)
+	}
+	d.base = d.normalize(plain)
+	return nil
+}
+
+func (m *Matrix) connection(c ConnectionID) (*connection, error) {
+	if c < 0 || int(c) >= len(m.connections) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownConnection, c)
+	}
+	return &m.connections[c], nil
+}
+
+func (m *Matrix) destination(dst DestinationID) (*destination, error) {
+	if dst < 0 || int(dst) >= len(m.destinations) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownDestination, dst)
+	}
+	return &m.destinations[dst], nil
+}
+
+// Process evaluates the matrix for the current block and calls the setters
+// of destinations whose value changed - no allocations
+func (m *Matrix) Process(ctx *process.Context) {
+	n := ctx.NumSamples()
+	for i := range m.sources {
+		s := &m.sources[i]
+		s.value = s.src.Value(ctx, n)
+	}
+
+	for i := range m.destinations {
+		m.destinations[i].amount = 0
+	}
+	for i := range m.connections {
+		c := &m.connections[i]
+		if !c.enabled {
+			continue
+		}
+		depth := c.depth
+		if c.hasDepthParam {
+			depth = 2*ctx.Param(c.depthParam) - 1
+		}
+		m.destinations[c.destination].amount += depth * c.curve.Apply(m.sources[c.source].value)
+	}
+
+	for i := range m.destinations {
+		d := &m.destinations[i]
+		base := d.base
+		if d.param != nil {
+			base = ctx.Param(d.param.ID)
+		}
+		d.normalized = clamp(base+d.amount, 0, 1)
+		if d.param != nil {
+			d.plain = d.param.Denormalize(d.normalized)
+			ctx.WriteModulation(d.param.ID, d.normalized, 0)
+		} else {
+			d.plain = d.min + d.normalized*(d.max-d.min)
+		}
+
+		if d.set != nil && (!d.called || d.plain != d.last) {
+			d.set(d.plain)
+			d.last, d.called = d.plain, true
+		}
+	}
+}
+
+// Value returns the modulated plain value of a destination after the last
+// Process
+func (m *Matrix) Value(dst DestinationID) float64 {
+	if dst < 0 || int(dst) >= len(m.destinations) {
+		return 0
+	}
+	return m.destinations[dst].plain
+}
+
+// Normalized returns the modulated 0-1 value of a destination after the
+// last Process
+func (m *Matrix) Normalized(dst DestinationID) float64 {
+	if dst < 0 || int(dst) >= len(m.destinations) {
+		return 0
+	}
+	return m.destinations[dst].normalized
+}
+
+// Amount returns the summed modulation of a destination in normalized
+// units, before clamping, e.g. to draw a modulation ring around a knob
+func (m *Matrix) Amount(dst DestinationID) float64 {
+	if dst < 0 || int(dst) >= len(m.destinations) {
+		return 0
+	}
+	return m.destinations[dst].amount
+}
+
+// SourceValue returns the value a source produced in the last Process
+func (m *Matrix) SourceValue(src SourceID) float64 {
+	if src < 0 || int(src) >= len(m.sources) {
+		return 0
+	}
+	return m.sources[src].value
+}
+
+// SourceName returns the name of a source
+func (m *Matrix) SourceName(src SourceID) string {
+	if src < 0 || int(src) >= len(m.sources) {
+		return ""
+	}
+	return m.sources[src].name
+}
+
+// DestinationName returns the name of a destination
+func (m *Matrix) DestinationName(dst DestinationID) string {
+	if dst < 0 || int(dst) >= len(m.destinations) {
+		return ""
+	}
+	return m.destinations[dst].name
+}
+
+// NumSources returns the number of sources
+func (m *Matrix) NumSources() int {
+	return len(m.sources)
+}
+
+// NumDestinations returns the number of destinations
+func (m *Matrix) NumDestinations() int {
+	return len(m.destinations)
+}
+
+// NumConnections returns the number of connections
+func (m *Matrix) NumConnections() int {
+	return len(m.connections)
+}
+
+// normalize maps a plain value of a setter destination to 0-1
+func (d *destination) normalize(plain float64) float64 {
+	if d.max == d.min {
+		return 0
+	}
+	return clamp((plain-d.min)/(d.max-d.min), 0, 1)
+}
+
+func clamp(x, lo, hi float64) float64 {
+	if x < lo {
+		return lo
+	}
+	if x > hi {
+		return hi
+	}
+	return x
+}
@@ -0,0 +1,247 @@
+package modmatrix
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/midi"
+)
+
+const (
+	paramCutoff = iota
+	paramMacro
+	paramDepth
+)
+
+// constant is a source with a fixed value
+type constant float64
+
+func (c constant) Value(*process.Context, int) float64 { return float64(c) }
+
+func newTestContext(t *testing.T) (*process.Context, *param.Registry) {
+	t.Helper()
+	params := param.NewRegistry()
+	if err := params.Add(
+		param.New(paramCutoff, "Cutoff").Range(0, 1000).Default(500).Build(),
+		param.New(paramMacro, "Macro").Default(0).Build(),
+		param.New(paramDepth, "Depth").Default(0.5).Build(),
+	); err != nil {
+		t.Fatal(err)
+	}
+	ctx := process.NewContext(64, params)
+	ctx.Input = [][]float32{make([]float32, 64)}
+	ctx.CaptureParams()
+	return ctx, params
+}
+
+func TestParameterDestination(t *testing.T) {
+	ctx, params := newTestContext(t)
+	m := New(params)
+
+	var got []float64
+	cutoff, err := m.AddParameter(paramCutoff, func(plain float64) { got = append(got, plain) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	up := m.AddSource("Up", constant(1))
+	down := m.AddSource("Down", constant(-0.5))
+	if _, err := m.Connect(up, cutoff, 0.2, CurveLinear); err != nil {
+		t.Fatal(err)
+	}
+	c, err := m.Connect(down, cutoff, 0.2, CurveLinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 0.5 + 0.2 - 0.1 of the range
+	m.Process(ctx)
+	if v := m.Value(cutoff); math.Abs(v-600) > 1e-9 {
+		t.Errorf("modulated cutoff %g, want 600", v)
+	}
+	if a := m.Amount(cutoff); math.Abs(a-0.1) > 1e-12 {
+		t.Errorf("amount %g, want 0.1", a)
+	}
+
+	// Setters only run when the value changes
+	m.Process(ctx)
+	if len(got) != 1 {
+		t.Errorf("setter called %d times for an unchanged value", len(got))
+	}
+
+	// Clamped to the parameter range
+	if err := m.SetDepth(c, -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetEnabled(0, false); err != nil {
+		t.Fatal(err)
+	}
+	m.Process(ctx)
+	if v := m.Value(cutoff); v != 1000 {
+		t.Errorf("modulated cutoff %g, want 1000 at the top of the range", v)
+	}
+	if len(got) != 2 || got[1] != 1000 {
+		t.Errorf("setter saw %v", got)
+	}
+
+	// The base follows the parameter
+	params.Get(paramCutoff).SetValue(0)
+	ctx.CaptureParams()
+	if err := m.SetDepth(c, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	m.Process(ctx)
+	if v := m.Value(cutoff); v != 0 {
+		t.Errorf("modulated cutoff %g, want 0", v)
+	}
+}
+
+func TestSetterDestination(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	m := New(nil)
+
+	var rate float64
+	dst := m.AddSetter("Rate", 0.1, 10.1, 5.1, func(plain float64) { rate = plain })
+	src := m.AddSource("Half", constant(0.5))
+	if _, err := m.Connect(src, dst, 0.4, CurveExponential); err != nil {
+		t.Fatal(err)
+	}
+	m.Process(ctx)
+	// 0.5 + 0.4 * 0.25 of the range
+	if math.Abs(rate-6.1) > 1e-9 {
+		t.Errorf("rate %g, want 6.1", rate)
+	}
+
+	if err := m.SetBase(dst, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	m.Process(ctx)
+	if math.Abs(rate-1.1) > 1e-9 {
+		t.Errorf("rate %g after SetBase, want 1.1", rate)
+	}
+}
+
+func TestDepthParameter(t *testing.T) {
+	ctx, params := newTestContext(t)
+	m := New(params)
+	dst := m.AddSetter("Amount", 0, 1, 0.5, nil)
+	c, err := m.Connect(m.AddSource("One", constant(1)), dst, 0, CurveLinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetDepthParameter(c, paramDepth); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Process(ctx)
+	if v := m.Value(dst); v != 0.5 {
+		t.Errorf("centered depth moved the value to %g", v)
+	}
+	params.Get(paramDepth).SetValue(0.75)
+	ctx.CaptureParams()
+	m.Process(ctx)
+	if v := m.Value(dst); v != 1 {
+		t.Errorf("value %g at depth 0.5, want 1", v)
+	}
+}
+
+func TestCurves(t *testing.T) {
+	for _, tc := range []struct {
+		curve Curve
+		in    float64
+		want  float64
+	}{
+		{CurveLinear, -0.5, -0.5},
+		{CurveLinear, 2, 1},
+		{CurveExponential, -0.5, -0.25},
+		{CurveLogarithmic, 0.25, 0.5},
+		{CurveSCurve, 0.5, 0.5},
+		{CurveSCurve, -0.25, -0.15625},
+	} {
+		if got := tc.curve.Apply(tc.in); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%v.Apply(%g) = %g, want %g", tc.curve, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestMIDISources(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	m := New(nil)
+	wheel := m.AddSource("Mod Wheel", NewCCSource(midi.CCModWheel))
+	bend := m.AddSource("Bend", NewExpressionSource(ExpressionPitchBend))
+	vel := m.AddSource("Velocity", NewExpressionSource(ExpressionVelocity))
+	key := m.AddSource("Key", NewExpressionSource(ExpressionKeyTrack))
+	poly := m.AddSource("Poly", NewExpressionSource(ExpressionPolyPressure))
+	ch2 := NewCCSource(midi.CCModWheel)
+	ch2.SetChannel(2)
+	other := m.AddSource("Wheel ch 3", ch2)
+
+	ctx.AddInputEvent(midi.ControlChangeEvent{Controller: midi.CCModWheel, Value: 127})
+	ctx.AddInputEvent(midi.PitchBendEvent{BaseEvent: midi.BaseEvent{Offset: 3}, Value: -4096})
+	ctx.AddInputEvent(midi.NoteOnEvent{BaseEvent: midi.BaseEvent{Offset: 5}, NoteNumber: 72, Velocity: 127})
+	ctx.AddInputEvent(midi.PolyPressureEvent{BaseEvent: midi.BaseEvent{Offset: 6}, NoteNumber: 72, Pressure: 127})
+	m.Process(ctx)
+
+	for src, want := range map[SourceID]float64{wheel: 1, bend: -0.5, vel: 1, key: 0.2, poly: 1, other: 0} {
+		if got := m.SourceValue(src); math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s = %g, want %g", m.SourceName(src), got, want)
+		}
+	}
+
+	// Values hold across blocks without events
+	ctx.ClearInputEvents()
+	m.Process(ctx)
+	if got := m.SourceValue(wheel); got != 1 {
+		t.Errorf("mod wheel %g in the next block, want 1", got)
+	}
+}
+
+func TestMacroSource(t *testing.T) {
+	ctx, params := newTestContext(t)
+	m := New(params)
+	uni := m.AddSource("Macro", NewMacroSource(paramMacro, false))
+	bi := m.AddSource("Macro ±", NewMacroSource(paramMacro, true))
+	params.Get(paramMacro).SetValue(0.25)
+	ctx.CaptureParams()
+	m.Process(ctx)
+	if m.SourceValue(uni) != 0.25 || m.SourceValue(bi) != -0.5 {
+		t.Errorf("macro values %g and %g, want 0.25 and -0.5", m.SourceValue(uni), m.SourceValue(bi))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, params := newTestContext(t)
+	m := New(params)
+	if _, err := m.AddParameter(99, nil); !errors.Is(err, ErrUnknownDestination) {
+		t.Errorf("AddParameter(99): %v", err)
+	}
+	src := m.AddSource("One", constant(1))
+	if _, err := m.Connect(src, 0, 1, CurveLinear); !errors.Is(err, ErrUnknownDestination) {
+		t.Errorf("Connect to nothing: %v", err)
+	}
+	dst, _ := m.AddParameter(paramCutoff, nil)
+	if _, err := m.Connect(5, dst, 1, CurveLinear); !errors.Is(err, ErrUnknownSource) {
+		t.Errorf("Connect from nothing: %v", err)
+	}
+	if err := m.SetDepth(3, 1); !errors.Is(err, ErrUnknownConnection) {
+		t.Errorf("SetDepth(3): %v", err)
+	}
+	if err := m.SetBase(dst, 1); !errors.Is(err, ErrUnknownDestination) {
+		t.Errorf("SetBase on a parameter: %v", err)
+	}
+}
+
+func TestProcessDoesNotAllocate(t *testing.T) {
+	ctx, params := newTestContext(t)
+	m := New(params)
+	dst, _ := m.AddParameter(paramCutoff, func(float64) {})
+	for i := 0; i < 8; i++ {
+		m.Connect(m.AddSource("Wheel", NewCCSource(midi.CCModWheel)), dst, 0.1, Curve(i%4))
+	}
+	ctx.AddInputEvent(midi.ControlChangeEvent{Controller: midi.CCModWheel, Value: 64})
+	if allocs := testing.AllocsPerRun(100, func() { m.Process(ctx) }); allocs != 0 {
+		t.Errorf("Process allocates %g times", allocs)
+	}
+}
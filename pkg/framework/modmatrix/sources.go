@@ -0,0 +1,202 @@
+package modmatrix
+
+import (
+	"github.com/justyntemme/vst3go/pkg/dsp/envelope"
+	"github.com/justyntemme/vst3go/pkg/dsp/modulation"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/midi"
+)
+
+// LFOSource drives modulation from an LFO, a bipolar source
+type LFOSource struct {
+	lfo *modulation.LFO
+}
+
+// NewLFOSource creates a source from an LFO. The matrix advances it, so it
+// should not be processed elsewhere.
+func NewLFOSource(lfo *modulation.LFO) *LFOSource {
+	return &LFOSource{lfo: lfo}
+}
+
+// Value returns the LFO at the start of the block and advances it by n
+// samples
+func (s *LFOSource) Value(ctx *process.Context, n int) float64 {
+	v := s.lfo.Process()
+	for i := 1; i < n; i++ {
+		s.lfo.Process()
+	}
+	return v
+}
+
+// EnvelopeSource drives modulation from an ADSR, a unipolar source
+type EnvelopeSource struct {
+	env *envelope.ADSR
+}
+
+// NewEnvelopeSource creates a source from an envelope. Trigger and release
+// it as usual; the matrix advances it.
+func NewEnvelopeSource(env *envelope.ADSR) *EnvelopeSource {
+	return &EnvelopeSource{env: env}
+}
+
+// Value returns the envelope at the start of the block and advances it by
+// n samples
+func (s *EnvelopeSource) Value(ctx *process.Context, n int) float64 {
+	v := s.env.Next()
+	for i := 1; i < n; i++ {
+		s.env.Next()
+	}
+	return float64(v)
+}
+
+// MacroSource follows a parameter, typically a macro knob
+type MacroSource struct {
+	id      uint32
+	bipolar bool
+}
+
+// NewMacroSource creates a source from a parameter's normalized value.
+// Bipolar macros map 0..1 to -1..1, centered at the knob's midpoint.
+func NewMacroSource(paramID uint32, bipolar bool) *MacroSource {
+	return &MacroSource{id: paramID, bipolar: bipolar}
+}
+
+// Value returns the parameter value captured for the block
+func (s *MacroSource) Value(ctx *process.Context, n int) float64 {
+	v := ctx.Param(s.id)
+	if s.bipolar {
+		return 2*v - 1
+	}
+	return v
+}
+
+// OmniChannel makes MIDI sources respond to every channel
+const OmniChannel = -1
+
+// CCSource follows a MIDI continuous controller, a unipolar source
+type CCSource struct {
+	controller uint8
+	channel    int
+	value      float64
+	events     []midi.Event // Reused each block
+}
+
+// NewCCSource creates a source for a controller number on every channel
+func NewCCSource(controller uint8) *CCSource {
+	return &CCSource{controller: controller, channel: OmniChannel}
+}
+
+// SetChannel limits the source to one MIDI channel, 0-15, or OmniChannel
+func (s *CCSource) SetChannel(channel int) {
+	s.channel = channel
+}
+
+// Value returns the last controller value received, 0 until the first
+func (s *CCSource) Value(ctx *process.Context, n int) float64 {
+	s.events = ctx.AppendInputEvents(s.events[:0])
+	for _, event := range s.events {
+		if cc, ok := event.(midi.ControlChangeEvent); ok && cc.Controller == s.controller && matchChannel(s.channel, cc.Channel()) {
+			s.value = float64(cc.Value) / 127
+		}
+	}
+	return s.value
+}
+
+// Expression selects the per-note or per-channel gesture an
+// ExpressionSource follows
+type Expression int
+
+const (
+	// ExpressionPitchBend follows the pitch wheel, bipolar
+	ExpressionPitchBend Expression = iota
+	// ExpressionChannelPressure follows channel aftertouch
+	ExpressionChannelPressure
+	// ExpressionPolyPressure follows the aftertouch of the last note played
+	ExpressionPolyPressure
+	// ExpressionVelocity holds the velocity of the last note on
+	ExpressionVelocity
+	// ExpressionKeyTrack follows the last note played, bipolar around
+	// middle C with one unit per five octaves
+	ExpressionKeyTrack
+)
+
+// String returns the expression name
+func (e Expression) String() string {
+	switch e {
+	case ExpressionPitchBend:
+		return "Pitch Bend"
+	case ExpressionChannelPressure:
+		return "Channel Pressure"
+	case ExpressionPolyPressure:
+		return "Poly Pressure"
+	case ExpressionVelocity:
+		return "Velocity"
+	case ExpressionKeyTrack:
+		return "Key Track"
+	default:
+		return "Unknown"
+	}
+}
+
+// ExpressionSource follows note expression: pitch bend, aftertouch,
+// velocity or key tracking
+type ExpressionSource struct {
+	kind    Expression
+	channel int
+	note    int // Last note on, -1 before the first
+	value   float64
+	events  []midi.Event // Reused each block
+}
+
+// NewExpressionSource creates a source for an expression on every channel
+func NewExpressionSource(kind Expression) *ExpressionSource {
+	return &ExpressionSource{kind: kind, channel: OmniChannel, note: -1}
+}
+
+// SetChannel limits the source to one MIDI channel, 0-15, or OmniChannel
+func (s *ExpressionSource) SetChannel(channel int) {
+	s.channel = channel
+}
+
+// Value returns the latest expression value
+func (s *ExpressionSource) Value(ctx *process.Context, n int) float64 {
+	s.events = ctx.AppendInputEvents(s.events[:0])
+	for _, event := range s.events {
+		if !matchChannel(s.channel, event.Channel()) {
+			continue
+		}
+		switch e := event.(type) {
+		case midi.NoteOnEvent:
+			if e.Velocity == 0 {
+				continue
+			}
+			s.note = int(e.NoteNumber)
+			switch s.kind {
+			case ExpressionVelocity:
+				s.value = float64(e.Velocity) / 127
+			case ExpressionKeyTrack:
+				s.value = clamp(float64(s.note-60)/60, -1, 1)
+			case ExpressionPolyPressure:
+				s.value = 0
+			}
+		case midi.PitchBendEvent:
+			if s.kind == ExpressionPitchBend {
+				s.value = e.NormalizedValue()
+			}
+		case midi.ChannelPressureEvent:
+			if s.kind == ExpressionChannelPressure {
+				s.value = float64(e.Pressure) / 127
+			}
+		case midi.PolyPressureEvent:
+			if s.kind == ExpressionPolyPressure && int(e.NoteNumber) == s.note {
+				s.value = float64(e.Pressure) / 127
+			}
+		}
+	}
+	return s.value
+}
+
+// matchChannel reports whether a source listening on channel hears ch
+func matchChannel(channel int, ch uint8) bool {
+	return channel == OmniChannel || channel == int(ch)
+}
@@ -1,5 +1,10 @@
 package param
 
+import (
+	"math"
+	"time"
+)
+
 // Builder provides a fluent API for creating parameters
 type Builder struct {
 	param *Parameter
@@ -49,12 +54,42 @@ func (b *Builder) Unit(unit string) *Builder {
 	return b
 }
 
-// Steps sets the number of discrete steps
+// Description sets the help text shown in generated documentation
+func (b *Builder) Description(text string) *Builder {
+	b.param.Description = text
+	return b
+}
+
+// Group sets the section the parameter is shown in, such as "Filter" or
+// "Envelope"
+func (b *Builder) Group(name string) *Builder {
+	b.param.Group = name
+	return b
+}
+
+// DisplayOrder sets the position in generic layouts; lower values come first
+func (b *Builder) DisplayOrder(order int) *Builder {
+	b.param.DisplayOrder = order
+	return b
+}
+
+// Steps sets the number of discrete steps. A parameter with n values has
+// n-1 steps, so a range of 1 to 32 with 31 steps has a value per whole
+// number.
 func (b *Builder) Steps(count int32) *Builder {
 	b.param.StepCount = count
 	return b
 }
 
+// StepSize makes the parameter stepped with a plain distance of size
+// between values, 1 for whole numbers. Call it after Range.
+func (b *Builder) StepSize(size float64) *Builder {
+	if size > 0 && b.param.Max > b.param.Min {
+		b.param.StepCount = int32(math.Round((b.param.Max - b.param.Min) / size))
+	}
+	return b
+}
+
 // Flags sets parameter flags
 func (b *Builder) Flags(flags uint32) *Builder {
 	b.param.Flags = flags
@@ -89,6 +124,12 @@ func (b *Builder) Bypass() *Builder {
 	return b
 }
 
+// NoRandomize keeps Registry.Randomize from changing the parameter
+func (b *Builder) NoRandomize() *Builder {
+	b.param.NoRandomize = true
+	return b
+}
+
 // Formatter sets custom value formatting and parsing
 func (b *Builder) Formatter(format func(float64) string, parse func(string) (float64, error)) *Builder {
 	b.param.formatFunc = format
@@ -96,9 +137,16 @@ func (b *Builder) Formatter(format func(float64) string, parse func(string) (flo
 	return b
 }
 
+// Smooth enables block-rate smoothing of the plain value over the given time
+func (b *Builder) Smooth(d time.Duration) *Builder {
+	b.param.SetSmoothTime(d)
+	return b
+}
+
 // Build returns the configured parameter
 func (b *Builder) Build() *Parameter {
-	// Initialize with default value
+	// Initialize with default value, snapped to its step
 	b.param.SetValue(b.param.DefaultValue)
+	b.param.DefaultValue = b.param.GetValue()
 	return b.param
 }
@@ -64,7 +64,7 @@ func Choice(id uint32, name string, options []ChoiceOption) *Builder {
 
 	return New(id, name).
 		Range(minVal, maxVal).
-		Steps(int32(len(options))).
+		Steps(int32(max(0, len(options)-1))).
 		Default(options[0].Value).
 		Formatter(formatter, parser)
 }
@@ -332,6 +332,25 @@ func BypassParameter(id uint32, name string) *Builder {
 	})
 }
 
+// TapParameter creates a momentary tap button; each change from Idle to Tap
+// is one tap
+func TapParameter(id uint32, name string) *Builder {
+	return Choice(id, name, []ChoiceOption{
+		{Value: 0, Name: "Idle"},
+		{Value: 1, Name: "Tap"},
+	})
+}
+
+// RandomizeParameter creates a randomize button for
+// Registry.SetRandomizeTrigger; each change from Idle to Randomize rolls
+// new values
+func RandomizeParameter(id uint32, name string) *Builder {
+	return Choice(id, name, []ChoiceOption{
+		{Value: 0, Name: "Idle"},
+		{Value: 1, Name: "Randomize"},
+	}).NoRandomize()
+}
+
 // Helper function to parse float with error handling
 func parseFloat(s string) (float64, error) {
 	var value float64
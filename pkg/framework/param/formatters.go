@@ -161,9 +161,18 @@ func PanParser(str string) (float64, error) {
 // NoteFormatter formats MIDI note numbers
 func NoteFormatter(noteNumber float64) string {
 	notes := []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
-	note := int(noteNumber) % 12
-	octave := int(noteNumber)/12 - 1
-	return fmt.Sprintf("%s%d", notes[note], octave)
+	if math.IsNaN(noteNumber) || math.IsInf(noteNumber, 0) {
+		return "---"
+	}
+	// Floor so negative notes wrap into the octave below
+	n := int(math.Floor(noteNumber))
+	note := n % 12
+	octave := n / 12
+	if note < 0 {
+		note += 12
+		octave--
+	}
+	return fmt.Sprintf("%s%d", notes[note], octave-1)
 }
 
 // NoteParser parses note names to MIDI numbers
@@ -0,0 +1,124 @@
+package param
+
+import (
+	"math"
+	"testing"
+)
+
+// fuzzParsers are the package parsers, which receive text typed by users
+var fuzzParsers = map[string]func(string) (float64, error){
+	"Frequency":  FrequencyParser,
+	"Decibel":    DecibelParser,
+	"Percent":    PercentParser,
+	"Time":       TimeParser,
+	"Ratio":      RatioParser,
+	"Pan":        PanParser,
+	"Note":       NoteParser,
+	"OnOff":      OnOffParser,
+	"FilterType": FilterTypeParser,
+	"GateType":   GateTypeParser,
+}
+
+// fuzzFormatters are the package formatters, which receive host-supplied values
+var fuzzFormatters = map[string]func(float64) string{
+	"Frequency":  FrequencyFormatter,
+	"Decibel":    DecibelFormatter,
+	"Percent":    PercentFormatter,
+	"Time":       TimeFormatter,
+	"Ratio":      RatioFormatter,
+	"Pan":        PanFormatter,
+	"Note":       NoteFormatter,
+	"OnOff":      OnOffFormatter,
+	"FilterType": FilterTypeFormatter,
+	"GateType":   GateTypeFormatter,
+}
+
+// fuzzParameters covers the builder helpers and their custom formatters
+func fuzzParameters() []*Parameter {
+	return []*Parameter{
+		New(0, "Plain").Range(-10, 10).Build(),
+		New(1, "Stepped").Range(0, 4).Steps(4).Build(),
+		New(2, "Note").Range(0, 127).Formatter(NoteFormatter, NoteParser).Build(),
+		GainParameter(3, "Gain").Build(),
+		MixParameter(4, "Mix").Build(),
+		FrequencyParameter(5, "Freq", 20, 20000, 1000).Build(),
+		TimeParameter(6, "Time", 0.1, 5000, 100).Build(),
+		RatioParameter(7, "Ratio", 1, 20, 4).Build(),
+		QParameter(8, "Q", 0.1, 10, 0.707).Build(),
+		PanParameter(9, "Pan").Build(),
+		PhaseParameter(10, "Phase").Build(),
+		ResonanceParameter(11, "Res").Build(),
+		ThresholdParameter(12, "Threshold", -60, 0, -20).Build(),
+		RateParameter(13, "Rate", 0.01, 20, 1).Build(),
+		BypassParameter(14, "Bypass").Build(),
+		Choice(15, "Mode", []ChoiceOption{{Value: 0, Name: "A"}, {Value: 1, Name: "B", Aliases: []string{"bee"}}}).Build(),
+	}
+}
+
+func FuzzParsers(f *testing.F) {
+	for _, seed := range []string{
+		"", "0", "1.5 kHz", "440 Hz", "-6.0 dB", "-∞ dB", "50%", "250 µs", "10 ms", "1.2 s",
+		"4.0:1", "C", "25L", "100R", "C#4", "Bb-1", "on", "lpf", "expander", "NaN", "Inf", "-1e309",
+	} {
+		f.Add(seed)
+	}
+
+	params := fuzzParameters()
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, parse := range fuzzParsers {
+			parse(s)
+		}
+		for _, p := range params {
+			v, err := p.ParseValue(s)
+			if err == nil && !(v >= 0 && v <= 1) {
+				t.Errorf("%s.ParseValue(%q) = %v, outside 0-1", p.Name, s, v)
+			}
+		}
+	})
+}
+
+func FuzzFormatters(f *testing.F) {
+	for _, seed := range []float64{0, 0.5, 1, -1, 127, -60, 999.9, 1e12, -1e12, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		f.Add(seed)
+	}
+
+	params := fuzzParameters()
+	f.Fuzz(func(t *testing.T, v float64) {
+		for _, format := range fuzzFormatters {
+			format(v)
+		}
+		for _, p := range params {
+			p.FormatValue(v)
+		}
+	})
+}
+
+func TestNoteFormatterOutOfRange(t *testing.T) {
+	tests := []struct {
+		note     float64
+		expected string
+	}{
+		{60, "C4"},
+		{0, "C-1"},
+		{-1, "B-2"},
+		{-12, "C-2"},
+	}
+	for _, test := range tests {
+		if got := NoteFormatter(test.note); got != test.expected {
+			t.Errorf("NoteFormatter(%v) = %s, want %s", test.note, got, test.expected)
+		}
+	}
+}
+
+func TestParseValueRejectsNaN(t *testing.T) {
+	p := FrequencyParameter(0, "Freq", 20, 20000, 1000).Build()
+	if _, err := p.ParseValue("NaN Hz"); err == nil {
+		t.Error("Expected an error parsing NaN")
+	}
+
+	p.SetValue(0.25)
+	p.SetValue(math.NaN())
+	if p.GetValue() != 0.25 {
+		t.Errorf("Expected NaN to be ignored, got %v", p.GetValue())
+	}
+}
@@ -0,0 +1,308 @@
+package param
+
+import "sync"
+
+// DefaultUndoLimit is the number of edits each A/B slot keeps for undo
+const DefaultUndoLimit = 100
+
+// Slot identifies one of the two A/B compare settings
+type Slot int
+
+// A/B compare slots
+const (
+	SlotA Slot = iota
+	SlotB
+)
+
+// String returns "A" or "B"
+func (s Slot) String() string {
+	if s == SlotB {
+		return "B"
+	}
+	return "A"
+}
+
+// historyEntry is one undoable change of one or more parameters
+type historyEntry struct {
+	ids    []uint32
+	before []float64
+	after  []float64
+	open   bool // A gesture on ids[0] is still merging edits
+}
+
+// history is the undo and redo stack of one slot
+type history struct {
+	undo []*historyEntry
+	redo []*historyEntry
+}
+
+// editState holds the A/B slots and their histories. It has its own lock,
+// taken before r.mu, so applying a recall can reuse ApplySnapshot.
+type editState struct {
+	mu      sync.Mutex
+	active  Slot
+	stored  [2]map[uint32]float64 // Values of the inactive slot
+	history [2]history
+	limit   int
+	trigger *randomizeTrigger
+}
+
+// edits returns the edit state, creating it on first use
+func (r *Registry) edits() *editState {
+	r.editOnce.Do(func() {
+		r.editState = &editState{limit: DefaultUndoLimit}
+	})
+	return r.editState
+}
+
+// push adds an entry to the undo stack of the active slot, dropping the
+// oldest beyond the limit and clearing redo
+func (e *editState) push(entry *historyEntry) {
+	h := &e.history[e.active]
+	h.undo = append(h.undo, entry)
+	if e.limit > 0 && len(h.undo) > e.limit {
+		n := len(h.undo) - e.limit
+		copy(h.undo, h.undo[n:])
+		clear(h.undo[len(h.undo)-n:])
+		h.undo = h.undo[:len(h.undo)-n]
+	}
+	clear(h.redo)
+	h.redo = h.redo[:0]
+}
+
+// top returns the newest undo entry of the active slot, or nil
+func (e *editState) top() *historyEntry {
+	h := &e.history[e.active]
+	if len(h.undo) == 0 {
+		return nil
+	}
+	return h.undo[len(h.undo)-1]
+}
+
+// SetUndoLimit sets how many edits each slot keeps; 0 keeps all of them
+func (r *Registry) SetUndoLimit(limit int) {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.limit = max(0, limit)
+}
+
+// BeginEdit starts a gesture on a parameter: edits until EndEdit merge
+// into one undo step, so dragging a knob undoes in one go. The edit handler,
+// if any, is told so the host can record automation.
+func (r *Registry) BeginEdit(id uint32) {
+	p := r.Get(id)
+	if p == nil {
+		return
+	}
+	if h := r.getEditHandler(); h != nil {
+		h.BeginEdit(id)
+	}
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if top := e.top(); top != nil && top.open && top.ids[0] == id {
+		return
+	}
+	value := p.GetValue()
+	e.push(&historyEntry{
+		ids:    []uint32{id},
+		before: []float64{value},
+		after:  []float64{value},
+		open:   true,
+	})
+}
+
+// Edit sets the normalized value of a parameter as a user edit that can be
+// undone. Call from the UI or controller thread. Returns false for unknown
+// and read-only parameters.
+func (r *Registry) Edit(id uint32, value float64) bool {
+	p := r.Get(id)
+	if p == nil || p.Flags&IsReadOnly != 0 {
+		return false
+	}
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	before := p.GetValue()
+	p.SetValue(value)
+	after := p.GetValue()
+
+	if top := e.top(); top != nil && top.open && top.ids[0] == id {
+		top.after[0] = after
+		return true
+	}
+	if after != before {
+		e.push(&historyEntry{
+			ids:    []uint32{id},
+			before: []float64{before},
+			after:  []float64{after},
+		})
+	}
+	return true
+}
+
+// EndEdit ends the gesture started by BeginEdit. A gesture that left the
+// value unchanged is dropped from the history.
+func (r *Registry) EndEdit(id uint32) {
+	if h := r.getEditHandler(); h != nil && r.Get(id) != nil {
+		h.EndEdit(id)
+	}
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	top := e.top()
+	if top == nil || !top.open || top.ids[0] != id {
+		return
+	}
+	top.open = false
+	if top.after[0] == top.before[0] {
+		h := &e.history[e.active]
+		h.undo[len(h.undo)-1] = nil
+		h.undo = h.undo[:len(h.undo)-1]
+	}
+}
+
+// CanUndo returns whether the active slot has an edit to undo
+func (r *Registry) CanUndo() bool {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.history[e.active].undo) > 0
+}
+
+// CanRedo returns whether the active slot has an undone edit to redo
+func (r *Registry) CanRedo() bool {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.history[e.active].redo) > 0
+}
+
+// Undo reverts the newest edit of the active slot and tells the host.
+// Returns false if there is nothing to undo.
+func (r *Registry) Undo() bool {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	h := &e.history[e.active]
+	if len(h.undo) == 0 {
+		return false
+	}
+	entry := h.undo[len(h.undo)-1]
+	h.undo[len(h.undo)-1] = nil
+	h.undo = h.undo[:len(h.undo)-1]
+	entry.open = false
+	h.redo = append(h.redo, entry)
+	r.ApplySnapshot(entryValues(entry.ids, entry.before), true)
+	return true
+}
+
+// Redo reapplies the newest undone edit of the active slot and tells the
+// host. Returns false if there is nothing to redo.
+func (r *Registry) Redo() bool {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	h := &e.history[e.active]
+	if len(h.redo) == 0 {
+		return false
+	}
+	entry := h.redo[len(h.redo)-1]
+	h.redo[len(h.redo)-1] = nil
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, entry)
+	r.ApplySnapshot(entryValues(entry.ids, entry.after), true)
+	return true
+}
+
+// ClearHistory drops the undo and redo stacks of both slots
+func (r *Registry) ClearHistory() {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.history = [2]history{}
+}
+
+// entryValues builds the snapshot map for an entry
+func entryValues(ids []uint32, values []float64) map[uint32]float64 {
+	m := make(map[uint32]float64, len(ids))
+	for i, id := range ids {
+		m[id] = values[i]
+	}
+	return m
+}
+
+// ActiveSlot returns the A/B slot the current values belong to
+func (r *Registry) ActiveSlot() Slot {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.active
+}
+
+// Toggle stores the current values in the active slot and recalls the
+// other one, which starts as a copy of the first. Each slot keeps its own
+// undo history. Returns the slot now active.
+func (r *Registry) Toggle() Slot {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	current := r.Values()
+	if top := e.top(); top != nil {
+		top.open = false
+	}
+	e.stored[e.active] = current
+	e.active ^= 1
+	if recall := e.stored[e.active]; recall != nil {
+		r.ApplySnapshot(recall, true)
+	}
+	e.stored[e.active] = nil
+	return e.active
+}
+
+// CopyAtoB copies the settings of slot A into slot B
+func (r *Registry) CopyAtoB() {
+	r.copySlot(SlotA, SlotB)
+}
+
+// CopyBtoA copies the settings of slot B into slot A
+func (r *Registry) CopyBtoA() {
+	r.copySlot(SlotB, SlotA)
+}
+
+// copySlot copies one slot into the other. Copying into the active slot
+// changes the current values and can be undone.
+func (r *Registry) copySlot(from, to Slot) {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if from == e.active {
+		e.stored[to] = r.Values()
+		return
+	}
+	source := e.stored[from]
+	if source == nil {
+		return // The other slot was never used and equals this one
+	}
+
+	entry := &historyEntry{}
+	idx := r.view()
+	for _, p := range idx.list {
+		value, ok := source[p.ID]
+		if !ok || p.Flags&IsReadOnly != 0 || value == p.GetValue() {
+			continue
+		}
+		entry.ids = append(entry.ids, p.ID)
+		entry.before = append(entry.before, p.GetValue())
+		entry.after = append(entry.after, value)
+	}
+	if len(entry.ids) == 0 {
+		return
+	}
+	e.push(entry)
+	r.ApplySnapshot(entryValues(entry.ids, entry.after), true)
+}
@@ -0,0 +1,130 @@
+package param
+
+import "testing"
+
+func newHistoryRegistry() *Registry {
+	reg := NewRegistry()
+	reg.Add(
+		New(1, "Gain").Range(-24, 24).Default(0).Build(),
+		New(2, "Mix").Range(0, 100).Default(50).Build(),
+		New(3, "Meter").Range(0, 1).Flags(IsReadOnly).Build(),
+	)
+	return reg
+}
+
+func TestUndoRedo(t *testing.T) {
+	reg := newHistoryRegistry()
+	notified := 0
+	reg.OnValuesChanged(func() { notified++ })
+
+	if reg.CanUndo() || reg.Undo() || reg.Redo() {
+		t.Fatal("new registry should have no history")
+	}
+	if reg.Edit(3, 1) || reg.Edit(99, 1) {
+		t.Error("read-only and unknown parameters should not be editable")
+	}
+
+	reg.Edit(1, 0.75)
+	reg.Edit(2, 0.2)
+	if !reg.Undo() || reg.Get(2).GetValue() != 0.5 || reg.Get(1).GetValue() != 0.75 {
+		t.Errorf("after undo: %g, %g", reg.Get(1).GetValue(), reg.Get(2).GetValue())
+	}
+	if !reg.CanRedo() || !reg.Redo() || reg.Get(2).GetValue() != 0.2 {
+		t.Errorf("after redo: %g", reg.Get(2).GetValue())
+	}
+	if notified != 2 {
+		t.Errorf("host notified %d times, want 2", notified)
+	}
+
+	// A new edit clears redo
+	reg.Undo()
+	reg.Edit(1, 0.1)
+	if reg.CanRedo() {
+		t.Error("a new edit should clear redo")
+	}
+}
+
+func TestUndoGesture(t *testing.T) {
+	reg := newHistoryRegistry()
+
+	// A drag undoes in one step
+	reg.BeginEdit(1)
+	for _, v := range []float64{0.55, 0.6, 0.7, 0.9} {
+		reg.Edit(1, v)
+	}
+	reg.EndEdit(1)
+	if !reg.Undo() || reg.Get(1).GetValue() != 0.5 || reg.CanUndo() {
+		t.Errorf("gesture undo left %g", reg.Get(1).GetValue())
+	}
+
+	// A gesture that changed nothing leaves no history
+	reg.ClearHistory()
+	reg.BeginEdit(2)
+	reg.Edit(2, 0.5)
+	reg.EndEdit(2)
+	if reg.CanUndo() {
+		t.Error("unchanged gesture should not be recorded")
+	}
+}
+
+func TestUndoLimit(t *testing.T) {
+	reg := newHistoryRegistry()
+	reg.SetUndoLimit(3)
+	for i := 1; i <= 5; i++ {
+		reg.Edit(2, float64(i)/10)
+	}
+	steps := 0
+	for reg.Undo() {
+		steps++
+	}
+	if steps != 3 || reg.Get(2).GetValue() != 0.2 {
+		t.Errorf("undid %d steps to %g, want 3 steps to 0.2", steps, reg.Get(2).GetValue())
+	}
+}
+
+func TestABCompare(t *testing.T) {
+	reg := newHistoryRegistry()
+	if reg.ActiveSlot() != SlotA || SlotB.String() != "B" {
+		t.Fatal("registry should start on slot A")
+	}
+
+	reg.Edit(1, 0.8)
+	// B starts as a copy of A
+	if reg.Toggle() != SlotB || reg.Get(1).GetValue() != 0.8 {
+		t.Fatalf("slot B = %g, want a copy of A", reg.Get(1).GetValue())
+	}
+	reg.Edit(1, 0.2)
+	if !reg.Undo() || reg.Get(1).GetValue() != 0.8 {
+		t.Errorf("undo on B = %g", reg.Get(1).GetValue())
+	}
+	reg.Redo()
+
+	// Each slot keeps its values and history
+	if reg.Toggle() != SlotA || reg.Get(1).GetValue() != 0.8 {
+		t.Errorf("slot A = %g, want 0.8", reg.Get(1).GetValue())
+	}
+	if !reg.Undo() || reg.Get(1).GetValue() != 0.5 {
+		t.Errorf("undo on A = %g, want 0.5", reg.Get(1).GetValue())
+	}
+	reg.Toggle()
+	if reg.Get(1).GetValue() != 0.2 {
+		t.Errorf("slot B = %g, want 0.2", reg.Get(1).GetValue())
+	}
+
+	// Copying into the active slot changes the values and can be undone
+	reg.CopyAtoB()
+	if reg.Get(1).GetValue() != 0.5 {
+		t.Errorf("after A to B = %g, want 0.5", reg.Get(1).GetValue())
+	}
+	reg.Undo()
+	if reg.Get(1).GetValue() != 0.2 {
+		t.Errorf("undo of copy = %g, want 0.2", reg.Get(1).GetValue())
+	}
+
+	// Copying out of the active slot stores it in the other
+	reg.CopyBtoA()
+	reg.Toggle()
+	if reg.Get(1).GetValue() != 0.2 {
+		t.Errorf("slot A after B to A = %g, want 0.2", reg.Get(1).GetValue())
+	}
+}
@@ -0,0 +1,91 @@
+package param
+
+import "errors"
+
+var (
+	// ErrUnknownParameter is returned for a parameter ID not in the registry
+	ErrUnknownParameter = errors.New("unknown parameter")
+
+	// ErrNoContextMenu is returned by ShowContextMenu when the host offers
+	// no context menus
+	ErrNoContextMenu = errors.New("host has no context menu")
+)
+
+// EditHandler passes edits made by the plugin's editor on to the host, so
+// they are recorded as automation and can be undone in the host. The plugin
+// wrapper installs one that calls the host's IComponentHandler.
+type EditHandler interface {
+	BeginEdit(id uint32)
+	PerformEdit(id uint32, value float64)
+	EndEdit(id uint32)
+}
+
+// MenuItem is an entry the plugin adds to a parameter's context menu, after
+// the host's own entries such as automation and MIDI learn
+type MenuItem struct {
+	Name      string
+	Checked   bool
+	Disabled  bool
+	Separator bool // Draws a divider; the other fields are ignored
+
+	// Action is called on the UI thread when the entry is chosen
+	Action func()
+}
+
+// ContextMenuFunc shows the host's context menu for a parameter, with the
+// plugin's items appended, at x, y in editor coordinates
+type ContextMenuFunc func(id uint32, x, y int32, items []MenuItem) error
+
+// SetEditHandler sets the handler told about BeginEdit, PerformEdit and
+// EndEdit; nil stops forwarding
+func (r *Registry) SetEditHandler(h EditHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.editHandler = h
+}
+
+// getEditHandler returns the current edit handler or nil
+func (r *Registry) getEditHandler() EditHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.editHandler
+}
+
+// PerformEdit sets the normalized value of a parameter from the editor,
+// between BeginEdit and EndEdit. Like Edit it records the change for undo,
+// and it also sends the new value to the host. Returns false for unknown
+// and read-only parameters.
+func (r *Registry) PerformEdit(id uint32, value float64) bool {
+	if !r.Edit(id, value) {
+		return false
+	}
+	if h := r.getEditHandler(); h != nil {
+		h.PerformEdit(id, r.Get(id).GetValue())
+	}
+	return true
+}
+
+// OnContextMenu sets the function ShowContextMenu calls. The plugin wrapper
+// uses it to open the host's menu through IComponentHandler3.
+func (r *Registry) OnContextMenu(fn ContextMenuFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.contextMenu = fn
+}
+
+// ShowContextMenu opens the host's context menu for a parameter, typically
+// when the user right-clicks its control, at x, y in editor coordinates.
+// The host fills in its own entries and items are added after them. Call
+// from the UI thread.
+func (r *Registry) ShowContextMenu(id uint32, x, y int32, items ...MenuItem) error {
+	if r.Get(id) == nil {
+		return ErrUnknownParameter
+	}
+	r.mu.RLock()
+	fn := r.contextMenu
+	r.mu.RUnlock()
+	if fn == nil {
+		return ErrNoContextMenu
+	}
+	return fn(id, x, y, items)
+}
@@ -0,0 +1,96 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// recordingHandler logs the edits it is told about
+type recordingHandler struct {
+	calls []string
+}
+
+func (h *recordingHandler) BeginEdit(id uint32) {
+	h.calls = append(h.calls, fmt.Sprintf("begin %d", id))
+}
+
+func (h *recordingHandler) PerformEdit(id uint32, value float64) {
+	h.calls = append(h.calls, fmt.Sprintf("perform %d %g", id, value))
+}
+
+func (h *recordingHandler) EndEdit(id uint32) {
+	h.calls = append(h.calls, fmt.Sprintf("end %d", id))
+}
+
+func TestEditHandler(t *testing.T) {
+	reg := newHistoryRegistry()
+
+	// Without a handler edits only touch the registry
+	reg.BeginEdit(1)
+	reg.PerformEdit(1, 0.25)
+	reg.EndEdit(1)
+	if reg.Get(1).GetValue() != 0.25 {
+		t.Fatalf("value %g after edit", reg.Get(1).GetValue())
+	}
+
+	h := &recordingHandler{}
+	reg.SetEditHandler(h)
+	reg.BeginEdit(1)
+	reg.PerformEdit(1, 2) // Clamped to 1
+	reg.PerformEdit(1, 0.5)
+	reg.EndEdit(1)
+	if reg.PerformEdit(3, 1) || reg.PerformEdit(99, 1) {
+		t.Error("read-only and unknown parameters should not be editable")
+	}
+	reg.BeginEdit(99)
+	reg.EndEdit(99)
+
+	want := []string{"begin 1", "perform 1 1", "perform 1 0.5", "end 1"}
+	if fmt.Sprint(h.calls) != fmt.Sprint(want) {
+		t.Errorf("host told %v, want %v", h.calls, want)
+	}
+
+	// The gesture is one undo step, back to before it started
+	if !reg.Undo() || reg.Get(1).GetValue() != 0.25 {
+		t.Errorf("value %g after undoing the gesture, want 0.25", reg.Get(1).GetValue())
+	}
+
+	// Plain edits and undo are not sent as host edits
+	reg.Edit(2, 0.1)
+	if len(h.calls) != len(want) {
+		t.Errorf("host told about a plain edit or undo: %v", h.calls[len(want):])
+	}
+
+	reg.SetEditHandler(nil)
+	reg.BeginEdit(1)
+	reg.EndEdit(1)
+	if len(h.calls) != len(want) {
+		t.Error("removed handler still told about edits")
+	}
+}
+
+func TestShowContextMenu(t *testing.T) {
+	reg := newHistoryRegistry()
+	if err := reg.ShowContextMenu(1, 0, 0); !errors.Is(err, ErrNoContextMenu) {
+		t.Errorf("error %v without a host menu, want ErrNoContextMenu", err)
+	}
+
+	var shown uint32
+	var got []MenuItem
+	reg.OnContextMenu(func(id uint32, x, y int32, items []MenuItem) error {
+		shown, got = id, items
+		return nil
+	})
+	if err := reg.ShowContextMenu(99, 0, 0); !errors.Is(err, ErrUnknownParameter) {
+		t.Errorf("error %v for an unknown parameter, want ErrUnknownParameter", err)
+	}
+
+	reset := MenuItem{Name: "Reset", Action: func() { reg.Edit(2, reg.Get(2).DefaultValue) }}
+	if err := reg.ShowContextMenu(2, 10, 20, reset, MenuItem{Separator: true}); err != nil {
+		t.Fatal(err)
+	}
+	if shown != 2 || len(got) != 2 || got[0].Name != "Reset" || !got[1].Separator {
+		t.Errorf("menu for %d with %+v", shown, got)
+	}
+}
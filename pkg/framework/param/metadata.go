@@ -0,0 +1,99 @@
+package param
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Metadata is the serializable description of a parameter for tooling such
+// as generic editors, documentation generators and preset converters.
+// Values are in the plain range.
+type Metadata struct {
+	ID           uint32  `json:"id"`
+	Name         string  `json:"name"`
+	ShortName    string  `json:"shortName,omitempty"`
+	Unit         string  `json:"unit,omitempty"`
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	Default      float64 `json:"default"`
+	DefaultText  string  `json:"defaultText"`
+	Steps        int32   `json:"steps,omitempty"`
+	Description  string  `json:"description,omitempty"`
+	Group        string  `json:"group,omitempty"`
+	DisplayOrder int     `json:"displayOrder,omitempty"`
+	Automatable  bool    `json:"automatable"`
+	ReadOnly     bool    `json:"readOnly,omitempty"`
+	Hidden       bool    `json:"hidden,omitempty"`
+	Bypass       bool    `json:"bypass,omitempty"`
+}
+
+// Metadata returns the parameter's description for tooling
+func (p *Parameter) Metadata() Metadata {
+	return Metadata{
+		ID:           p.ID,
+		Name:         p.Name,
+		ShortName:    p.ShortName,
+		Unit:         p.Unit,
+		Min:          p.Min,
+		Max:          p.Max,
+		Default:      p.Denormalize(p.DefaultValue),
+		DefaultText:  p.FormatValue(p.DefaultValue),
+		Steps:        p.StepCount,
+		Description:  p.Description,
+		Group:        p.Group,
+		DisplayOrder: p.DisplayOrder,
+		Automatable:  p.Flags&CanAutomate != 0,
+		ReadOnly:     p.Flags&IsReadOnly != 0,
+		Hidden:       p.Flags&IsHidden != 0,
+		Bypass:       p.Flags&IsBypass != 0,
+	}
+}
+
+// Ordered returns all parameters in display order. Parameters with equal
+// DisplayOrder keep their registration order, so a registry that sets none
+// lists as All does.
+func (r *Registry) Ordered() []*Parameter {
+	list := r.All()
+	slices.SortStableFunc(list, func(a, b *Parameter) int {
+		return a.DisplayOrder - b.DisplayOrder
+	})
+	return list
+}
+
+// Groups returns the group names in display order, each once. Ungrouped
+// parameters appear as the empty name.
+func (r *Registry) Groups() []string {
+	var groups []string
+	for _, p := range r.Ordered() {
+		if !slices.Contains(groups, p.Group) {
+			groups = append(groups, p.Group)
+		}
+	}
+	return groups
+}
+
+// InGroup returns the parameters of one group in display order
+func (r *Registry) InGroup(group string) []*Parameter {
+	var params []*Parameter
+	for _, p := range r.Ordered() {
+		if p.Group == group {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// Metadata returns the description of every parameter in display order
+func (r *Registry) Metadata() []Metadata {
+	ordered := r.Ordered()
+	meta := make([]Metadata, len(ordered))
+	for i, p := range ordered {
+		meta[i] = p.Metadata()
+	}
+	return meta
+}
+
+// MarshalMetadata encodes the parameter descriptions as indented JSON
+func (r *Registry) MarshalMetadata() ([]byte, error) {
+	return json.MarshalIndent(r.Metadata(), "", "  ")
+}
@@ -0,0 +1,71 @@
+package param
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestRegistryDisplayOrderAndGroups(t *testing.T) {
+	r := NewRegistry()
+	r.Add(
+		New(0, "Cutoff").Group("Filter").DisplayOrder(2).Build(),
+		New(1, "Attack").Group("Envelope").DisplayOrder(1).Build(),
+		New(2, "Resonance").Group("Filter").DisplayOrder(2).Build(),
+		New(3, "Volume").Build(),
+	)
+
+	var names []string
+	for _, p := range r.Ordered() {
+		names = append(names, p.Name)
+	}
+	if want := []string{"Volume", "Attack", "Cutoff", "Resonance"}; !slices.Equal(names, want) {
+		t.Errorf("Ordered = %v, want %v", names, want)
+	}
+	if groups := r.Groups(); !slices.Equal(groups, []string{"", "Envelope", "Filter"}) {
+		t.Errorf("Groups = %q", groups)
+	}
+	if filter := r.InGroup("Filter"); len(filter) != 2 || filter[1].Name != "Resonance" {
+		t.Errorf("Filter group = %d parameters", len(filter))
+	}
+
+	// Registration order is untouched for host indexing
+	if r.GetByIndex(0).Name != "Cutoff" {
+		t.Error("Display order should not change the host index")
+	}
+}
+
+func TestRegistryMetadata(t *testing.T) {
+	r := NewRegistry()
+	r.Add(
+		New(7, "Drive").
+			Range(0, 24).
+			Default(6).
+			Unit("dB").
+			Description("Input gain into the saturator").
+			Group("Tone").
+			Formatter(DecibelFormatter, DecibelParser).
+			Build(),
+		New(8, "Bypass").Toggle().Bypass().Hidden().Build(),
+	)
+
+	data, err := r.MarshalMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta []Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatal(err)
+	}
+	if len(meta) != 2 {
+		t.Fatalf("Got %d entries, want 2", len(meta))
+	}
+	drive := meta[0]
+	if drive.ID != 7 || drive.Default != 6 || drive.DefaultText != "6.0 dB" || drive.Group != "Tone" ||
+		drive.Description != "Input gain into the saturator" || !drive.Automatable {
+		t.Errorf("Drive metadata = %+v", drive)
+	}
+	if bypass := meta[1]; !bypass.Bypass || !bypass.Hidden || bypass.Steps != 1 {
+		t.Errorf("Bypass metadata = %+v", bypass)
+	}
+}
@@ -3,8 +3,10 @@ package param
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -20,6 +22,10 @@ type Parameter struct {
 	StepCount    int32
 	Flags        uint32
 	UnitID       int32
+	Description  string // User-facing help text, used in generated docs
+	Group        string // Section the parameter belongs to in layouts and docs
+	DisplayOrder int    // Position in generic layouts; lower first, ties keep registration order
+	NoRandomize  bool   // Left alone by Registry.Randomize
 
 	// Atomic value for lock-free access in audio thread
 	value uint64 // Store as uint64 for atomic operations
@@ -27,6 +33,10 @@ type Parameter struct {
 	// Value formatting
 	formatFunc func(float64) string
 	parseFunc  func(string) (float64, error)
+
+	// Block-rate smoothing (audio thread only)
+	smoothTime time.Duration
+	smooth     blockSmoother
 }
 
 // Flags for parameters
@@ -46,22 +56,57 @@ func (p *Parameter) GetValue() float64 {
 	return float64frombits(bits)
 }
 
-// SetValue sets the normalized value (0-1)
+// SetValue sets the normalized value (0-1). NaN is ignored. Stepped
+// parameters store the value of the step it falls in.
 func (p *Parameter) SetValue(value float64) {
+	if math.IsNaN(value) {
+		return
+	}
+
 	// Clamp to 0-1
 	if value < 0 {
 		value = 0
 	} else if value > 1 {
 		value = 1
 	}
+	if p.StepCount > 0 {
+		value = float64(p.step(value)) / float64(p.StepCount)
+	}
 
 	atomic.StoreUint64(&p.value, float64bits(value))
 }
 
 // GetPlainValue converts normalized to plain value
 func (p *Parameter) GetPlainValue() float64 {
-	normalized := p.GetValue()
-	return p.Min + normalized*(p.Max-p.Min)
+	return p.Denormalize(p.GetValue())
+}
+
+// GetStep returns the index of the current step, 0 to StepCount. It is 0
+// for continuous parameters.
+func (p *Parameter) GetStep() int {
+	if p.StepCount <= 0 {
+		return 0
+	}
+	return p.step(p.GetValue())
+}
+
+// step returns the step a normalized value falls in. Like the VST3 SDK,
+// the range is split into StepCount+1 equal bins, so every step is as easy
+// to reach with a knob and hosts show the same value the plugin uses.
+func (p *Parameter) step(normalized float64) int {
+	n := int(p.StepCount)
+	return max(0, min(n, int(normalized*float64(n+1))))
+}
+
+// stepSize returns the plain distance between steps
+func (p *Parameter) stepSize() float64 {
+	return (p.Max - p.Min) / float64(p.StepCount)
+}
+
+// Quantize returns plain clamped to the range and, for stepped parameters,
+// snapped to the nearest step
+func (p *Parameter) Quantize(plain float64) float64 {
+	return p.Denormalize(p.Normalize(plain))
 }
 
 // SetPlainValue converts plain to normalized value
@@ -99,18 +144,20 @@ func (p *Parameter) FormatValue(normalized float64) string {
 
 // ParseValue parses string to normalized value
 func (p *Parameter) ParseValue(str string) (float64, error) {
+	var plain float64
+	var err error
 	if p.parseFunc != nil {
-		plain, err := p.parseFunc(str)
-		if err != nil {
-			return 0, err
-		}
-		return p.Normalize(plain), nil
+		plain, err = p.parseFunc(str)
+	} else {
+		// Default parsing
+		plain, err = strconv.ParseFloat(str, 64)
 	}
-	// Default parsing
-	plain, err := strconv.ParseFloat(str, 64)
 	if err != nil {
 		return 0, err
 	}
+	if math.IsNaN(plain) {
+		return 0, fmt.Errorf("invalid value: %s", str)
+	}
 	return p.Normalize(plain), nil
 }
 
@@ -126,11 +173,25 @@ func (p *Parameter) Normalize(plain float64) float64 {
 	if normalized > 1 {
 		return 1
 	}
+	if p.StepCount > 0 {
+		// Snap to the nearest step; k/StepCount lies inside bin k, so the
+		// result converts back to the same step
+		return math.Round(normalized*float64(p.StepCount)) / float64(p.StepCount)
+	}
 	return normalized
 }
 
-// Denormalize converts normalized (0-1) to plain value
+// Denormalize converts normalized (0-1) to plain value. Stepped parameters
+// return the exact plain value of the step, such as a whole number of bits
+// for a range of 1 to 32 with 31 steps.
 func (p *Parameter) Denormalize(normalized float64) float64 {
+	if p.StepCount > 0 {
+		k := p.step(normalized)
+		if k == int(p.StepCount) {
+			return p.Max
+		}
+		return p.Min + float64(k)*p.stepSize()
+	}
 	return p.Min + normalized*(p.Max-p.Min)
 }
 
@@ -0,0 +1,141 @@
+package param
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSteppedRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max float64
+		steps    int32
+	}{
+		{"Toggle", 0, 1, 1},
+		{"Voices", 1, 4, 3},
+		{"Bend", 0, 12, 12},
+		{"Bit Depth", 1, 32, 31},
+		{"Half Steps", -2, 2, 8},
+		{"Fine", 0, 1, 1000},
+	}
+
+	for _, test := range tests {
+		p := New(0, test.name).Range(test.min, test.max).Steps(test.steps).Build()
+		size := (test.max - test.min) / float64(test.steps)
+
+		for k := 0; k <= int(test.steps); k++ {
+			want := test.min + float64(k)*size
+			if k == int(test.steps) {
+				want = test.max
+			}
+
+			// Plain -> normalized -> plain gives the step back exactly
+			normalized := p.Normalize(want)
+			if plain := p.Denormalize(normalized); plain != want {
+				t.Errorf("%s step %d: %g round trips to %g", test.name, k, want, plain)
+			}
+
+			// Stored values report the same step and plain value
+			p.SetPlainValue(want)
+			if got := p.GetStep(); got != k {
+				t.Errorf("%s: step of %g = %d, want %d", test.name, want, got, k)
+			}
+			if plain := p.GetPlainValue(); plain != want {
+				t.Errorf("%s: GetPlainValue after setting %g = %g", test.name, want, plain)
+			}
+		}
+	}
+}
+
+func TestSteppedBins(t *testing.T) {
+	// 1 to 4 voices: each value owns a quarter of the normalized range
+	p := New(0, "Voices").Range(1, 4).Steps(3).Build()
+	tests := []struct {
+		normalized float64
+		plain      float64
+	}{
+		{0, 1},
+		{0.2499, 1},
+		{0.25, 2},
+		{0.4999, 2},
+		{0.5, 3},
+		{0.7499, 3},
+		{0.75, 4},
+		{0.999, 4},
+		{1, 4},
+	}
+	for _, test := range tests {
+		if got := p.Denormalize(test.normalized); got != test.plain {
+			t.Errorf("Denormalize(%g) = %g, want %g", test.normalized, got, test.plain)
+		}
+
+		// SetValue keeps the step's own normalized value
+		p.SetValue(test.normalized)
+		if got, want := p.GetValue(), p.Normalize(test.plain); got != want {
+			t.Errorf("SetValue(%g) stored %g, want %g", test.normalized, got, want)
+		}
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	stepped := New(0, "Bit Depth").Range(1, 32).StepSize(1).Build()
+	if stepped.StepCount != 31 {
+		t.Fatalf("StepSize(1) over 1-32 gave %d steps, want 31", stepped.StepCount)
+	}
+	tests := []struct {
+		plain, want float64
+	}{
+		{-5, 1},
+		{1.4, 1},
+		{1.6, 2},
+		{15.5, 16},
+		{31.9, 32},
+		{40, 32},
+	}
+	for _, test := range tests {
+		if got := stepped.Quantize(test.plain); got != test.want {
+			t.Errorf("Quantize(%g) = %g, want %g", test.plain, got, test.want)
+		}
+	}
+
+	// Continuous parameters are only clamped
+	continuous := New(1, "Mix").Range(0, 100).Build()
+	if got := continuous.Quantize(33.3); math.Abs(got-33.3) > 1e-9 {
+		t.Errorf("Continuous Quantize(33.3) = %g", got)
+	}
+	if got := continuous.Quantize(120); got != 100 {
+		t.Errorf("Continuous Quantize(120) = %g, want 100", got)
+	}
+	if continuous.GetStep() != 0 {
+		t.Errorf("Continuous GetStep() = %d, want 0", continuous.GetStep())
+	}
+}
+
+func TestSteppedDefault(t *testing.T) {
+	// A default between steps snaps to the nearest one
+	p := New(0, "Voices").Range(1, 4).Steps(3).Default(2.4).Build()
+	if got := p.GetPlainValue(); got != 2 {
+		t.Errorf("Default 2.4 gave %g, want 2", got)
+	}
+	if p.DefaultValue != p.GetValue() {
+		t.Errorf("DefaultValue %g differs from the built value %g", p.DefaultValue, p.GetValue())
+	}
+}
+
+func TestChoiceSteps(t *testing.T) {
+	p := Choice(0, "Mode", []ChoiceOption{
+		{Value: 0, Name: "A"}, {Value: 1, Name: "B"}, {Value: 2, Name: "C"},
+	}).Build()
+	if p.StepCount != 2 {
+		t.Fatalf("Three options gave %d steps, want 2", p.StepCount)
+	}
+	for _, name := range []string{"A", "B", "C"} {
+		normalized, err := p.ParseValue(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.FormatValue(normalized); got != name {
+			t.Errorf("%s formats back as %s", name, got)
+		}
+	}
+}
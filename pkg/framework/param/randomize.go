@@ -0,0 +1,161 @@
+package param
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// RandomRange limits randomization of a parameter to plain values from Min
+// to Max
+type RandomRange struct {
+	Min, Max float64
+}
+
+// RandomizeOptions selects which parameters Randomize changes and how far
+type RandomizeOptions struct {
+	// Include limits randomization to these parameters; empty means all
+	Include []uint32
+	// Exclude leaves these parameters alone
+	Exclude []uint32
+	// Ranges limits the plain values of individual parameters
+	Ranges map[uint32]RandomRange
+
+	// Amount is how far values move, as a fraction of their range: 1 picks
+	// anywhere in the range and smaller amounts humanize around the current
+	// value. 0, the zero value, means 1.
+	Amount float64
+	// Amounts overrides Amount for individual parameters; 0 leaves the
+	// parameter unchanged
+	Amounts map[uint32]float64
+
+	// Rand is the random source; nil uses the shared generator. Use
+	// NewRandom for repeatable results.
+	Rand *rand.Rand
+
+	// NotifyHost tells the host once that values changed
+	NotifyHost bool
+}
+
+// NewRandom returns a random source that always produces the same
+// sequence for a seed
+func NewRandom(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// randomizable reports whether Randomize may change a parameter at all.
+// Read-only, hidden, bypass and program change parameters are never
+// randomized.
+func randomizable(p *Parameter) bool {
+	const fixed = IsReadOnly | IsHidden | IsBypass | IsProgramChange
+	return !p.NoRandomize && p.Flags&fixed == 0
+}
+
+// Randomize sets new random values in one batch and returns the number of
+// parameters changed. The change is one undo step. Call from the UI or
+// controller thread.
+func (r *Registry) Randomize(opts RandomizeOptions) int {
+	uniform := rand.Float64
+	if opts.Rand != nil {
+		uniform = opts.Rand.Float64
+	}
+	amount := opts.Amount
+	if amount <= 0 {
+		amount = 1
+	}
+
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entry := &historyEntry{}
+	values := make(map[uint32]float64)
+	for _, p := range r.view().list {
+		if !randomizable(p) || slices.Contains(opts.Exclude, p.ID) ||
+			(len(opts.Include) > 0 && !slices.Contains(opts.Include, p.ID)) {
+			continue
+		}
+		a := amount
+		if v, ok := opts.Amounts[p.ID]; ok {
+			a = v
+		}
+		if a <= 0 {
+			continue
+		}
+
+		lo, hi := 0.0, 1.0
+		if rng, ok := opts.Ranges[p.ID]; ok {
+			lo, hi = p.Normalize(rng.Min), p.Normalize(rng.Max)
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+		}
+		current := p.GetValue()
+		var value float64
+		if a >= 1 {
+			value = lo + uniform()*(hi-lo)
+		} else {
+			value = current + a*(hi-lo)*(2*uniform()-1)
+			value = max(lo, min(hi, value))
+		}
+		values[p.ID] = value
+		entry.ids = append(entry.ids, p.ID)
+		entry.before = append(entry.before, current)
+	}
+	if len(values) == 0 {
+		return 0
+	}
+
+	changed := r.ApplySnapshot(values, opts.NotifyHost)
+	if changed > 0 {
+		for _, id := range entry.ids {
+			entry.after = append(entry.after, r.Get(id).GetValue())
+		}
+		e.push(entry)
+	}
+	return changed
+}
+
+// randomizeTrigger is a parameter that runs Randomize when switched on
+type randomizeTrigger struct {
+	id   uint32
+	opts RandomizeOptions
+	on   bool
+}
+
+// SetRandomizeTrigger makes a parameter, usually a RandomizeParameter, run
+// Randomize with opts each time it changes from off to on. The plugin
+// wrapper calls CheckTrigger when the host sets a value.
+func (r *Registry) SetRandomizeTrigger(id uint32, opts RandomizeOptions) {
+	e := r.edits()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	on := false
+	if p := r.Get(id); p != nil {
+		on = p.GetValue() >= 0.5
+	}
+	e.trigger = &randomizeTrigger{id: id, opts: opts, on: on}
+}
+
+// CheckTrigger runs the randomize trigger if the parameter is the trigger
+// and was just switched on. Returns true if it randomized. Call from the
+// controller thread after a parameter change.
+func (r *Registry) CheckTrigger(id uint32) bool {
+	e := r.edits()
+	e.mu.Lock()
+	t := e.trigger
+	if t == nil || t.id != id {
+		e.mu.Unlock()
+		return false
+	}
+	p := r.Get(id)
+	on := p != nil && p.GetValue() >= 0.5
+	fire := on && !t.on
+	t.on = on
+	opts := t.opts
+	e.mu.Unlock()
+
+	if fire {
+		r.Randomize(opts)
+	}
+	return fire
+}
@@ -0,0 +1,104 @@
+package param
+
+import (
+	"math"
+	"testing"
+)
+
+func newRandomRegistry() *Registry {
+	reg := NewRegistry()
+	reg.Add(
+		New(1, "Cutoff").Range(20, 20000).Default(1000).Build(),
+		New(2, "Resonance").Range(0, 1).Default(0.5).Build(),
+		New(3, "Volume").Range(-60, 0).Default(0).NoRandomize().Build(),
+		BypassParameter(4, "Bypass").Bypass().Build(),
+		New(5, "Meter").Range(0, 1).ReadOnly().Build(),
+		RandomizeParameter(6, "Randomize").Build(),
+	)
+	return reg
+}
+
+func TestRandomizeSeeded(t *testing.T) {
+	a, b := newRandomRegistry(), newRandomRegistry()
+	if a.Randomize(RandomizeOptions{Rand: NewRandom(7)}) != 2 {
+		t.Fatal("Randomize should change the two free parameters")
+	}
+	b.Randomize(RandomizeOptions{Rand: NewRandom(7)})
+	for _, id := range []uint32{1, 2} {
+		if a.Get(id).GetValue() != b.Get(id).GetValue() {
+			t.Errorf("parameter %d differs for the same seed", id)
+		}
+	}
+	for _, id := range []uint32{3, 4, 5, 6} {
+		if a.Get(id).GetValue() != a.Get(id).DefaultValue {
+			t.Errorf("parameter %d should not be randomized", id)
+		}
+	}
+
+	// One undo step restores everything
+	if !a.Undo() || a.Get(1).GetPlainValue() != 1000 || a.Get(2).GetPlainValue() != 0.5 || a.CanUndo() {
+		t.Error("undo should restore all randomized values")
+	}
+}
+
+func TestRandomizeConstraints(t *testing.T) {
+	reg := newRandomRegistry()
+	rng := NewRandom(1)
+	for i := 0; i < 200; i++ {
+		reg.Randomize(RandomizeOptions{
+			Include: []uint32{1, 2},
+			Exclude: []uint32{2},
+			Ranges:  map[uint32]RandomRange{1: {Min: 5000, Max: 200}},
+			Rand:    rng,
+		})
+		if v := reg.Get(1).GetPlainValue(); v < 200-1e-9 || v > 5000+1e-9 {
+			t.Fatalf("cutoff %g outside 200-5000", v)
+		}
+	}
+	if reg.Get(2).GetPlainValue() != 0.5 {
+		t.Error("excluded parameter changed")
+	}
+}
+
+func TestRandomizeHumanize(t *testing.T) {
+	reg := newRandomRegistry()
+	rng := NewRandom(3)
+	for i := 0; i < 100; i++ {
+		reg.Get(2).SetValue(0.5)
+		reg.Randomize(RandomizeOptions{
+			Amount:  0.1,
+			Amounts: map[uint32]float64{1: 0},
+			Rand:    rng,
+		})
+		if d := math.Abs(reg.Get(2).GetValue() - 0.5); d > 0.1+1e-12 {
+			t.Fatalf("humanize moved %g, want at most 0.1", d)
+		}
+	}
+	if reg.Get(1).GetPlainValue() != 1000 {
+		t.Error("zero per-parameter amount should leave the parameter alone")
+	}
+}
+
+func TestRandomizeTrigger(t *testing.T) {
+	reg := newRandomRegistry()
+	notified := 0
+	reg.OnValuesChanged(func() { notified++ })
+	reg.SetRandomizeTrigger(6, RandomizeOptions{Include: []uint32{2}, Rand: NewRandom(5), NotifyHost: true})
+
+	if reg.CheckTrigger(2) || reg.CheckTrigger(6) {
+		t.Fatal("no rising edge yet")
+	}
+	reg.Get(6).SetValue(1)
+	if !reg.CheckTrigger(6) || reg.Get(2).GetValue() == 0.5 || notified != 1 {
+		t.Errorf("trigger should randomize once: %g, notified %d", reg.Get(2).GetValue(), notified)
+	}
+	if reg.CheckTrigger(6) {
+		t.Error("holding the trigger should not randomize again")
+	}
+	reg.Get(6).SetValue(0)
+	reg.CheckTrigger(6)
+	reg.Get(6).SetValue(1)
+	if !reg.CheckTrigger(6) {
+		t.Error("second press should randomize")
+	}
+}
@@ -1,22 +1,78 @@
 package param
 
 import (
+	"math"
 	"sync"
+	"sync/atomic"
 )
 
-// Registry manages plugin parameters
+// Registry manages plugin parameters. Lookups read an immutable index that
+// is republished on every change, so the audio thread never takes a lock.
 type Registry struct {
 	params map[uint32]*Parameter
 	order  []uint32 // Maintain order for indexed access
 	mu     sync.RWMutex
+
+	index atomic.Pointer[registryIndex]
+
+	// Odd while ApplySnapshot is writing, so captures can skip half-applied
+	// batches
+	batchSeq atomic.Uint64
+
+	// Called after a batch update that should be announced to the host
+	onValuesChanged func()
+
+	// Host integration for edits and context menus made by the editor
+	editHandler EditHandler
+	contextMenu ContextMenuFunc
+
+	// A/B slots and undo history, created on first use
+	editOnce  sync.Once
+	editState *editState
+}
+
+// registryIndex is a read-only view of the registry
+type registryIndex struct {
+	byID map[uint32]*Parameter
+	pos  map[uint32]int // Position of each ID in list
+	list []*Parameter
 }
 
 // NewRegistry creates a new parameter registry
 func NewRegistry() *Registry {
-	return &Registry{
+	r := &Registry{
 		params: make(map[uint32]*Parameter),
 		order:  make([]uint32, 0),
 	}
+	r.publish()
+	return r
+}
+
+// publish rebuilds the lookup index; r.mu must be held for writing
+func (r *Registry) publish() {
+	idx := &registryIndex{
+		byID: make(map[uint32]*Parameter, len(r.order)),
+		pos:  make(map[uint32]int, len(r.order)),
+		list: make([]*Parameter, len(r.order)),
+	}
+	for i, id := range r.order {
+		p := r.params[id]
+		idx.byID[id] = p
+		idx.pos[id] = i
+		idx.list[i] = p
+	}
+	r.index.Store(idx)
+}
+
+// emptyIndex is the view of a registry that has not published yet
+var emptyIndex = &registryIndex{}
+
+// view returns the current lookup index
+func (r *Registry) view() *registryIndex {
+	if idx := r.index.Load(); idx != nil {
+		return idx
+	}
+	return emptyIndex
 }
 
 // Add registers a new parameter
@@ -31,48 +87,113 @@ func (r *Registry) Add(params ...*Parameter) error {
 		r.params[p.ID] = p
 		r.order = append(r.order, p.ID)
 	}
+	r.publish()
 
 	return nil
 }
 
 // Get retrieves a parameter by ID
 func (r *Registry) Get(id uint32) *Parameter {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	return r.params[id]
+	return r.view().byID[id]
 }
 
 // GetByIndex retrieves a parameter by index
 func (r *Registry) GetByIndex(index int32) *Parameter {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	if index < 0 || index >= int32(len(r.order)) {
+	list := r.view().list
+	if index < 0 || index >= int32(len(list)) {
 		return nil
 	}
-
-	id := r.order[index]
-	return r.params[id]
+	return list[index]
 }
 
 // Count returns the number of parameters
 func (r *Registry) Count() int32 {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	return int32(len(r.order))
+	return int32(len(r.view().list))
 }
 
 // All returns all parameters in order
 func (r *Registry) All() []*Parameter {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	list := r.view().list
+	result := make([]*Parameter, len(list))
+	copy(result, list)
+	return result
+}
 
-	result := make([]*Parameter, len(r.order))
-	for i, id := range r.order {
-		result[i] = r.params[id]
+// GetBypass returns the parameter flagged IsBypass, or nil if there is none
+func (r *Registry) GetBypass() *Parameter {
+	for _, p := range r.view().list {
+		if p.Flags&IsBypass != 0 {
+			return p
+		}
 	}
+	return nil
+}
 
-	return result
+// AdvanceSmoothing moves all smoothed parameters forward by one processing
+// block. Call once per block from the audio thread before reading values.
+func (r *Registry) AdvanceSmoothing(sampleRate float64, numSamples int) {
+	for _, p := range r.view().list {
+		if p.IsSmoothed() {
+			p.advanceSmoothing(sampleRate, numSamples)
+		}
+	}
+}
+
+// ResetSmoothing snaps all smoothed parameters to their current values
+func (r *Registry) ResetSmoothing() {
+	for _, p := range r.view().list {
+		p.resetSmoothing()
+	}
+}
+
+// OnValuesChanged sets the function ApplySnapshot calls to tell the host
+// that many values changed at once. The plugin wrapper uses it to issue a
+// single restartComponent(kParamValuesChanged).
+func (r *Registry) OnValuesChanged(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onValuesChanged = fn
+}
+
+// Values returns the normalized value of every parameter, for storing a
+// preset to apply later with ApplySnapshot
+func (r *Registry) Values() map[uint32]float64 {
+	list := r.view().list
+	values := make(map[uint32]float64, len(list))
+	for _, p := range list {
+		values[p.ID] = p.GetValue()
+	}
+	return values
+}
+
+// ApplySnapshot sets many normalized values at once, typically a preset,
+// without a host edit per parameter. A process call sees either none or
+// all of the new values. Unknown IDs, read-only parameters and NaN values
+// are skipped. If notifyHost is set and any value changed, the host is told
+// once to re-read all values. Returns the number of values changed. Call
+// from the UI or controller thread, not the audio thread.
+func (r *Registry) ApplySnapshot(values map[uint32]float64, notifyHost bool) int {
+	r.mu.Lock()
+	idx := r.view()
+	changed := 0
+	r.batchSeq.Add(1)
+	for id, value := range values {
+		p := idx.byID[id]
+		if p == nil || p.Flags&IsReadOnly != 0 || math.IsNaN(value) {
+			continue
+		}
+		old := p.GetValue()
+		p.SetValue(value)
+		if p.GetValue() != old {
+			changed++
+		}
+	}
+	r.batchSeq.Add(1)
+	notify := r.onValuesChanged
+	r.mu.Unlock()
+
+	if notifyHost && changed > 0 && notify != nil {
+		notify()
+	}
+	return changed
 }
@@ -40,6 +40,7 @@ func (r *AutoRegistry) Register(params ...*Parameter) error {
 			// Update existing parameter
 			p.ID = existingID
 			r.params[existingID] = p
+			r.publish()
 			continue
 		}
 		
@@ -58,6 +59,7 @@ func (r *AutoRegistry) Register(params ...*Parameter) error {
 		r.order = append(r.order, p.ID)
 		r.nameToID[p.Name] = p.ID
 	}
+	r.publish()
 	
 	return nil
 }
@@ -84,6 +86,7 @@ func (r *AutoRegistry) RegisterWithID(id uint32, param *Parameter) error {
 	r.params[id] = param
 	r.order = append(r.order, id)
 	r.nameToID[param.Name] = id
+	r.publish()
 	
 	return nil
 }
@@ -119,6 +122,7 @@ func (r *AutoRegistry) Clear() {
 	r.order = make([]uint32, 0)
 	r.nameToID = make(map[string]uint32)
 	r.nextID.Store(0)
+	r.publish()
 }
 
 // Reserve reserves a range of IDs for manual assignment
@@ -3,6 +3,7 @@ package param
 
 import (
 	"math"
+	"time"
 )
 
 // SmoothingType defines different parameter smoothing algorithms.
@@ -273,4 +274,85 @@ func (ps *ParameterSmoother) SetValue(id uint32, value float64) {
 func (ps *ParameterSmoother) Get(id uint32) (*SmoothedParameter, bool) {
 	sp, ok := ps.smoothers[id]
 	return sp, ok
-}
\ No newline at end of file
+}
+
+// blockSmoother ramps a parameter's plain value linearly towards its target,
+// advancing once per processing block. It is driven by Registry.AdvanceSmoothing.
+type blockSmoother struct {
+	current   float64
+	target    float64
+	step      float64 // change per sample
+	remaining int     // samples left in the current ramp
+	primed    bool
+}
+
+// SmoothTime returns the smoothing time configured for the parameter
+func (p *Parameter) SmoothTime() time.Duration {
+	return p.smoothTime
+}
+
+// SetSmoothTime sets the smoothing time (0 disables smoothing)
+func (p *Parameter) SetSmoothTime(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	p.smoothTime = d
+	p.smooth.primed = false
+}
+
+// IsSmoothed returns true if block smoothing is enabled for the parameter
+func (p *Parameter) IsSmoothed() bool {
+	return p.smoothTime > 0
+}
+
+// GetSmoothedPlainValue returns the smoothed plain value for the current block.
+// Parameters without smoothing return their plain value. Audio thread only.
+func (p *Parameter) GetSmoothedPlainValue() float64 {
+	if p.smoothTime <= 0 || !p.smooth.primed {
+		return p.GetPlainValue()
+	}
+	return p.smooth.current
+}
+
+// advanceSmoothing moves the smoothed value forward by numSamples
+func (p *Parameter) advanceSmoothing(sampleRate float64, numSamples int) {
+	s := &p.smooth
+	target := p.GetPlainValue()
+
+	if !s.primed || sampleRate <= 0 {
+		s.current = target
+		s.target = target
+		s.remaining = 0
+		s.primed = true
+		return
+	}
+
+	// Start a new ramp whenever the target moves
+	if target != s.target {
+		rampSamples := int(p.smoothTime.Seconds() * sampleRate)
+		if rampSamples < 1 {
+			rampSamples = 1
+		}
+		s.target = target
+		s.remaining = rampSamples
+		s.step = (target - s.current) / float64(rampSamples)
+	}
+
+	if s.remaining <= 0 {
+		return
+	}
+
+	if numSamples >= s.remaining {
+		s.current = s.target
+		s.remaining = 0
+		return
+	}
+
+	s.current += s.step * float64(numSamples)
+	s.remaining -= numSamples
+}
+
+// resetSmoothing jumps the smoothed value straight to the current value
+func (p *Parameter) resetSmoothing() {
+	p.smooth.primed = false
+}
@@ -3,6 +3,7 @@ package param
 import (
 	"math"
 	"testing"
+	"time"
 )
 
 func TestSmoother(t *testing.T) {
@@ -305,4 +306,46 @@ func BenchmarkSmoother(b *testing.B) {
 			})
 		}
 	})
-}
\ No newline at end of file
+}
+
+func TestBlockSmoothing(t *testing.T) {
+	p := New(1, "Gain").Range(0, 10).Default(0).Smooth(10 * time.Millisecond).Build()
+	registry := NewRegistry()
+	registry.Add(p)
+
+	if p.SmoothTime() != 10*time.Millisecond {
+		t.Errorf("Expected smooth time 10ms, got %v", p.SmoothTime())
+	}
+
+	// First block primes the smoother at the current value
+	registry.AdvanceSmoothing(1000, 5)
+	if v := p.GetSmoothedPlainValue(); v != 0 {
+		t.Errorf("Expected primed value 0, got %f", v)
+	}
+
+	// 10ms at 1kHz is a 10 sample ramp
+	p.SetPlainValue(10)
+	registry.AdvanceSmoothing(1000, 5)
+	if v := p.GetSmoothedPlainValue(); math.Abs(v-5) > 1e-9 {
+		t.Errorf("Expected halfway value 5, got %f", v)
+	}
+
+	registry.AdvanceSmoothing(1000, 5)
+	if v := p.GetSmoothedPlainValue(); v != 10 {
+		t.Errorf("Expected target 10, got %f", v)
+	}
+
+	// Reset jumps straight to the new value
+	p.SetPlainValue(2)
+	registry.ResetSmoothing()
+	registry.AdvanceSmoothing(1000, 1)
+	if v := p.GetSmoothedPlainValue(); v != 2 {
+		t.Errorf("Expected reset value 2, got %f", v)
+	}
+
+	// Unsmoothed parameters report their plain value directly
+	plain := New(2, "Mix").Range(0, 100).Default(50).Build()
+	if plain.IsSmoothed() || plain.GetSmoothedPlainValue() != 50 {
+		t.Errorf("Expected unsmoothed value 50, got %f", plain.GetSmoothedPlainValue())
+	}
+}
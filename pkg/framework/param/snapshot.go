@@ -0,0 +1,154 @@
+package param
+
+import (
+	"sync"
+
+	"github.com/justyntemme/vst3go/pkg/rt"
+)
+
+// Snapshot is a copy of all normalized parameter values taken at the start of
+// a process call, so the audio thread sees one consistent set of values for
+// the whole block even while the host or GUI keeps changing them
+type Snapshot struct {
+	index  *registryIndex
+	values []rt.Float64 // In registry order
+}
+
+// newSnapshot creates a snapshot for a registry view. A snapshot's index
+// never changes, so readers on other threads only race on atomic values.
+func newSnapshot(idx *registryIndex) *Snapshot {
+	return &Snapshot{index: idx, values: make([]rt.Float64, len(idx.list))}
+}
+
+// Len returns the number of values in the snapshot
+func (s *Snapshot) Len() int {
+	return len(s.index.list)
+}
+
+// Lookup returns the normalized value of a parameter and false if the
+// parameter was not registered when the snapshot was taken
+func (s *Snapshot) Lookup(id uint32) (float64, bool) {
+	i, ok := s.index.pos[id]
+	if !ok {
+		return 0, false
+	}
+	return s.values[i].Load(), true
+}
+
+// Value returns the normalized value of a parameter, or 0 if it is unknown
+func (s *Snapshot) Value(id uint32) float64 {
+	v, _ := s.Lookup(id)
+	return v
+}
+
+// Plain returns the plain value of a parameter, or 0 if it is unknown
+func (s *Snapshot) Plain(id uint32) float64 {
+	i, ok := s.index.pos[id]
+	if !ok {
+		return 0
+	}
+	return s.index.list[i].Denormalize(s.values[i].Load())
+}
+
+// ValueAt returns the normalized value at a registry index
+func (s *Snapshot) ValueAt(index int) float64 {
+	if index < 0 || index >= len(s.index.list) {
+		return 0
+	}
+	return s.values[index].Load()
+}
+
+// set overrides a value after capture, for changes applied during the block
+func (s *Snapshot) set(id uint32, value float64) {
+	if i, ok := s.index.pos[id]; ok {
+		s.values[i].Store(value)
+	}
+}
+
+// SnapshotBuffer passes snapshots of a registry through a triple buffer. The
+// audio thread captures into the back buffer and publishes it; it never
+// waits on other threads. Other threads read the latest published values
+// with CopyLatest, which never sees a capture in progress.
+type SnapshotBuffer struct {
+	registry *Registry
+	blocks   *rt.TripleBuffer[*Snapshot]
+	current  *Snapshot
+	readMu   sync.Mutex // Serializes CopyLatest, the buffer's single reader
+}
+
+// NewSnapshotBuffer creates a buffer sized for the parameters registered so
+// far. Parameters added later are picked up on the next capture at the cost
+// of one allocation.
+func NewSnapshotBuffer(registry *Registry) *SnapshotBuffer {
+	b := &SnapshotBuffer{registry: registry}
+	b.blocks = rt.NewTripleBuffer(func() *Snapshot {
+		return newSnapshot(registry.view())
+	})
+	b.Capture()
+	return b
+}
+
+// Capture copies the current parameter values into the back buffer, makes it
+// current and publishes it. Call once per process call on the audio thread.
+func (b *SnapshotBuffer) Capture() *Snapshot {
+	idx := b.registry.view()
+	back := b.blocks.Back()
+	if (*back).index != idx {
+		// Parameters were added since this block was built
+		*back = newSnapshot(idx)
+	}
+	s := *back
+
+	batch := b.registry.batchSeq.Load()
+	for i, p := range idx.list {
+		s.values[i].Store(p.GetValue())
+	}
+	if prev := b.current; prev != nil && prev.index == idx &&
+		(batch%2 == 1 || b.registry.batchSeq.Load() != batch) {
+		// A batch update overlapped the copy: keep the previous values so
+		// the block never sees half a preset; the next capture gets all of it
+		for i := range s.values {
+			s.values[i].Store(prev.values[i].Load())
+		}
+	}
+	b.blocks.Publish()
+
+	b.current = s
+	return s
+}
+
+// Current returns the snapshot of the current process call (audio thread only)
+func (b *SnapshotBuffer) Current() *Snapshot {
+	return b.current
+}
+
+// Set updates a value in the current snapshot and the parameter itself, for
+// changes the audio thread applies in the middle of a block
+func (b *SnapshotBuffer) Set(id uint32, value float64) {
+	p := b.registry.Get(id)
+	if p == nil {
+		return
+	}
+	p.SetValue(value)
+	b.current.set(id, p.GetValue())
+}
+
+// CopyLatest copies the values of the latest published snapshot into dst in
+// registry order and returns it, growing dst if needed. It never blocks the
+// audio thread; concurrent callers on other threads take turns.
+func (b *SnapshotBuffer) CopyLatest(dst []float64) []float64 {
+	b.readMu.Lock()
+	defer b.readMu.Unlock()
+
+	front, _ := b.blocks.Read()
+	s := *front
+	n := len(s.index.list)
+	if cap(dst) < n {
+		dst = make([]float64, n)
+	}
+	dst = dst[:n]
+	for i := range dst {
+		dst[i] = s.values[i].Load()
+	}
+	return dst
+}
@@ -0,0 +1,184 @@
+package param
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSnapshotConsistentWithinBlock(t *testing.T) {
+	reg := NewRegistry()
+	reg.Add(
+		New(1, "Gain").Range(-24, 24).Default(0).Build(),
+		New(2, "Mix").Range(0, 100).Default(50).Build(),
+	)
+	buf := NewSnapshotBuffer(reg)
+
+	s := buf.Capture()
+	if s.Len() != 2 || s.Value(1) != 0.5 || s.Plain(2) != 50 {
+		t.Fatalf("unexpected snapshot: len %d, %g, %g", s.Len(), s.Value(1), s.Plain(2))
+	}
+
+	// Changes from other threads are not visible until the next capture
+	reg.Get(1).SetValue(1)
+	if s.Value(1) != 0.5 || buf.Current().Value(1) != 0.5 {
+		t.Error("snapshot changed during the block")
+	}
+	if s2 := buf.Capture(); s2.Plain(1) != 24 || s2 == s {
+		t.Errorf("next capture: %g", s2.Plain(1))
+	}
+
+	// Audio thread changes update the current snapshot immediately
+	buf.Set(2, 0.25)
+	if buf.Current().Plain(2) != 25 || reg.Get(2).GetValue() != 0.25 {
+		t.Error("Set should update the snapshot and the parameter")
+	}
+
+	if _, ok := s.Lookup(99); ok || s.Value(99) != 0 || s.ValueAt(5) != 0 {
+		t.Error("unknown parameters should read as zero")
+	}
+}
+
+func TestSnapshotPicksUpNewParameters(t *testing.T) {
+	reg := NewRegistry()
+	reg.Add(New(1, "A").Build())
+	buf := NewSnapshotBuffer(reg)
+
+	reg.Add(New(2, "B").Default(1).Build())
+	s := buf.Capture()
+	if v, ok := s.Lookup(2); !ok || v != 1 {
+		t.Errorf("new parameter: %g, %v", v, ok)
+	}
+	if got := buf.CopyLatest(nil); len(got) != 2 || got[1] != 1 {
+		t.Errorf("CopyLatest: %v", got)
+	}
+}
+
+func TestSnapshotConcurrentReaders(t *testing.T) {
+	reg := NewRegistry()
+	for id := uint32(0); id < 32; id++ {
+		reg.Add(New(id, "P").Build())
+	}
+	buf := NewSnapshotBuffer(reg)
+
+	// Capture and CopyLatest must stay race free while values change
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; !stop.Load(); i++ {
+			v := float64(i%100) / 100
+			for _, p := range reg.All() {
+				p.SetValue(v)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		var dst []float64
+		for !stop.Load() {
+			dst = buf.CopyLatest(dst)
+		}
+	}()
+
+	for i := 0; i < 2000; i++ {
+		s := buf.Capture()
+		for j := 0; j < s.Len(); j++ {
+			_ = s.ValueAt(j)
+		}
+	}
+	stop.Store(true)
+	wg.Wait()
+}
+
+func TestRegistryLookupDuringAdd(t *testing.T) {
+	reg := NewRegistry()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for id := uint32(0); id < 200; id++ {
+			reg.Add(New(id, "P").Build())
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		_ = reg.Get(uint32(i % 200))
+		_ = reg.Count()
+	}
+	wg.Wait()
+	if reg.Count() != 200 || reg.GetByIndex(199).ID != 199 {
+		t.Errorf("got %d parameters", reg.Count())
+	}
+}
+
+func TestRegistryApplySnapshot(t *testing.T) {
+	reg := NewRegistry()
+	reg.Add(
+		New(1, "Gain").Build(),
+		New(2, "Mix").Default(0.5).Build(),
+		OutputLevelMeter(3, "Level").Build(),
+	)
+	notified := 0
+	reg.OnValuesChanged(func() { notified++ })
+
+	preset := reg.Values()
+	preset[1] = 0.75
+	preset[3] = 1  // Read-only, skipped
+	preset[99] = 1 // Unknown, skipped
+	if n := reg.ApplySnapshot(preset, true); n != 1 {
+		t.Errorf("expected 1 change, got %d", n)
+	}
+	if reg.Get(1).GetValue() != 0.75 || reg.Get(2).GetValue() != 0.5 || reg.Get(3).GetValue() != 0 {
+		t.Error("values not applied as expected")
+	}
+	if notified != 1 {
+		t.Errorf("host should be notified once, got %d", notified)
+	}
+
+	// Nothing changed or no notification requested: the host is not told
+	reg.ApplySnapshot(preset, true)
+	reg.ApplySnapshot(map[uint32]float64{2: 0.1}, false)
+	if notified != 1 {
+		t.Errorf("unexpected notifications: %d", notified)
+	}
+}
+
+func TestSnapshotApplyIsAtomic(t *testing.T) {
+	reg := NewRegistry()
+	const count = 64
+	for id := uint32(0); id < count; id++ {
+		reg.Add(New(id, "P").Build())
+	}
+	buf := NewSnapshotBuffer(reg)
+
+	// Alternate between two presets while the audio thread captures
+	presets := [2]map[uint32]float64{{}, {}}
+	for id := uint32(0); id < count; id++ {
+		presets[0][id] = 0
+		presets[1][id] = 1
+	}
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; !stop.Load(); i++ {
+			reg.ApplySnapshot(presets[i%2], false)
+		}
+	}()
+
+	for i := 0; i < 5000; i++ {
+		s := buf.Capture()
+		first := s.ValueAt(0)
+		for j := 1; j < count; j++ {
+			if s.ValueAt(j) != first {
+				stop.Store(true)
+				wg.Wait()
+				t.Fatalf("capture %d saw a half-applied preset", i)
+			}
+		}
+	}
+	stop.Store(true)
+	wg.Wait()
+}
@@ -0,0 +1,219 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+// SafeModeSettings are the conservative options a plugin should use after a crash
+type SafeModeSettings struct {
+	ExtraBuffering bool // Use larger internal buffers
+	HighQuality    bool // Enable oversampling and other expensive modes
+	GUIEnabled     bool // Open the editor
+}
+
+// DefaultSettings are the settings for a normal session
+var DefaultSettings = SafeModeSettings{
+	ExtraBuffering: false,
+	HighQuality:    true,
+	GUIEnabled:     true,
+}
+
+// SafeSettings are the settings for a session following a crash
+var SafeSettings = SafeModeSettings{
+	ExtraBuffering: true,
+	HighQuality:    false,
+	GUIEnabled:     false,
+}
+
+// sentinelRefs counts the running instances per sentinel file in this process;
+// sharedSafeMode lets later instances in the same process inherit safe mode
+var (
+	sentinelMu     sync.Mutex
+	sentinelRefs   = make(map[string]int)
+	sharedSafeMode = make(map[string]bool)
+)
+
+// SafeMode detects a crash in the previous session through a sentinel file.
+// The file is written when the first instance starts and removed when the last
+// one stops cleanly; if it already exists on start, the previous session
+// crashed and the plugin should start in safe mode. A sentinel written by
+// another process that is still running, such as a second host, is not a
+// crash; the newer session takes it over.
+type SafeMode struct {
+	path      string
+	active    bool
+	started   bool
+	indicator *param.Parameter
+	mu        sync.Mutex
+}
+
+// NewSafeMode creates a safe mode detector for a plugin ID, keeping its
+// sentinel file in the user cache directory
+func NewSafeMode(pluginID string) *SafeMode {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, pluginID)
+	return NewSafeModeAt(filepath.Join(dir, "vst3go", "safemode", name+".running"))
+}
+
+// NewSafeModeAt creates a safe mode detector using a specific sentinel path
+func NewSafeModeAt(path string) *SafeMode {
+	return &SafeMode{path: path}
+}
+
+// Start checks for a leftover sentinel and writes a new one. Call it when the
+// plugin is initialized.
+func (s *SafeMode) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return nil
+	}
+
+	sentinelMu.Lock()
+	defer sentinelMu.Unlock()
+
+	// Only the first instance in this process can see a crashed session
+	if sentinelRefs[s.path] == 0 {
+		if data, err := os.ReadFile(s.path); err == nil {
+			owner, ok := sentinelPID(data)
+			s.active = !ok || owner == os.Getpid() || !processAlive(owner)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+			return fmt.Errorf("failed to create sentinel directory: %w", err)
+		}
+		content := fmt.Sprintf("pid=%d\nstarted=%s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+		if err := os.WriteFile(s.path, []byte(content), 0o600); err != nil {
+			return fmt.Errorf("failed to write sentinel: %w", err)
+		}
+	} else {
+		s.active = sharedSafeMode[s.path]
+	}
+
+	sentinelRefs[s.path]++
+	sharedSafeMode[s.path] = s.active
+	s.started = true
+
+	if s.indicator != nil && s.active {
+		s.indicator.SetValue(1)
+	}
+	return nil
+}
+
+// Stop removes the sentinel when the last instance shuts down cleanly.
+// Call it when the plugin is terminated.
+func (s *SafeMode) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		return nil
+	}
+	s.started = false
+
+	sentinelMu.Lock()
+	defer sentinelMu.Unlock()
+
+	sentinelRefs[s.path]--
+	if sentinelRefs[s.path] > 0 {
+		return nil
+	}
+	delete(sentinelRefs, s.path)
+	delete(sharedSafeMode, s.path)
+
+	// Leave a sentinel another process took over to that process
+	if data, err := os.ReadFile(s.path); err == nil {
+		if owner, ok := sentinelPID(data); ok && owner != os.Getpid() {
+			return nil
+		}
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove sentinel: %w", err)
+	}
+	return nil
+}
+
+// sentinelPID returns the process ID recorded in a sentinel file
+func sentinelPID(data []byte) (int, bool) {
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "pid="); ok {
+			pid, err := strconv.Atoi(value)
+			return pid, err == nil && pid > 0
+		}
+	}
+	return 0, false
+}
+
+// processAlive reports whether a process with the given ID is running
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	defer p.Release()
+	if runtime.GOOS == "windows" {
+		// FindProcess only opens live processes on Windows
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// IsActive returns true if the previous session crashed
+func (s *SafeMode) IsActive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.active
+}
+
+// Settings returns the settings the plugin should run with
+func (s *SafeMode) Settings() SafeModeSettings {
+	if s.IsActive() {
+		return SafeSettings
+	}
+	return DefaultSettings
+}
+
+// Path returns the sentinel file path
+func (s *SafeMode) Path() string {
+	return s.path
+}
+
+// Parameter builds a read-only parameter reporting safe mode to the host.
+// It is updated when Start detects a crashed session.
+func (s *SafeMode) Parameter(id uint32) *param.Parameter {
+	p := param.Choice(id, "Safe Mode", []param.ChoiceOption{
+		{Value: 0, Name: "Off"},
+		{Value: 1, Name: "On"},
+	}).ReadOnly().Build()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.indicator = p
+	if s.active {
+		p.SetValue(1)
+	}
+	return p
+}
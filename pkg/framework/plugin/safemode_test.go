@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+func TestSafeModeDetectsCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.running")
+
+	// Clean session: no safe mode, sentinel removed on stop
+	sm := NewSafeModeAt(path)
+	indicator := sm.Parameter(100)
+	if err := sm.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if sm.IsActive() || indicator.GetValue() != 0 {
+		t.Error("Expected normal mode on first start")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected sentinel file: %v", err)
+	}
+	if err := sm.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected sentinel to be removed after clean stop")
+	}
+
+	// Crashed session: sentinel left behind
+	crashed := NewSafeModeAt(path)
+	if err := crashed.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	// Simulate a crash by forgetting the instance without stopping it
+	sentinelMu.Lock()
+	delete(sentinelRefs, path)
+	delete(sharedSafeMode, path)
+	sentinelMu.Unlock()
+
+	sm = NewSafeModeAt(path)
+	indicator = sm.Parameter(100)
+	if err := sm.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sm.Stop()
+
+	if !sm.IsActive() {
+		t.Error("Expected safe mode after crash")
+	}
+	if indicator.GetValue() != 1 {
+		t.Error("Expected safe mode parameter to be on")
+	}
+	if settings := sm.Settings(); settings.GUIEnabled || settings.HighQuality || !settings.ExtraBuffering {
+		t.Errorf("Unexpected safe mode settings: %+v", settings)
+	}
+	if indicator.Flags&param.IsReadOnly == 0 {
+		t.Error("Expected read-only indicator parameter")
+	}
+
+	// A second instance in the same process inherits safe mode
+	other := NewSafeModeAt(path)
+	if err := other.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer other.Stop()
+	if !other.IsActive() {
+		t.Error("Expected second instance to inherit safe mode")
+	}
+}
+
+func TestSafeModeIgnoresLiveSentinel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.running")
+
+	// Another host process still running wrote the sentinel
+	other := fmt.Sprintf("pid=%d\nstarted=%s\n", os.Getppid(), time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(path, []byte(other), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewSafeModeAt(path)
+	if err := sm.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if sm.IsActive() {
+		t.Error("Expected normal mode while the sentinel's process is running")
+	}
+	if err := sm.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the taken-over sentinel to be removed after clean stop")
+	}
+
+	// A sentinel without a process ID still counts as a crash
+	if err := os.WriteFile(path, []byte("started=yesterday\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sm = NewSafeModeAt(path)
+	if err := sm.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sm.Stop()
+	if !sm.IsActive() {
+		t.Error("Expected safe mode for a sentinel without a process ID")
+	}
+}
@@ -0,0 +1,101 @@
+package preset
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonFormat identifies vst3go JSON presets
+const (
+	jsonFormat  = "vst3go-preset"
+	jsonVersion = 1
+)
+
+// jsonPreset is the JSON preset document. Values are plain values so the
+// file can be read and edited by hand; custom state is base64.
+type jsonPreset struct {
+	Format     string      `json:"format"`
+	Version    int         `json:"version"`
+	ClassID    string      `json:"classId"`
+	Name       string      `json:"name,omitempty"`
+	Category   string      `json:"category,omitempty"`
+	Author     string      `json:"author,omitempty"`
+	Comment    string      `json:"comment,omitempty"`
+	Parameters []jsonParam `json:"parameters"`
+	Custom     []byte      `json:"custom,omitempty"`
+}
+
+type jsonParam struct {
+	ID    uint32  `json:"id"`
+	Name  string  `json:"name,omitempty"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// encodeJSON writes a preset as indented JSON with plain values
+func (s *Store) encodeJSON(p *Preset) ([]byte, error) {
+	doc := jsonPreset{
+		Format:   jsonFormat,
+		Version:  jsonVersion,
+		ClassID:  classIDString(p.ClassID),
+		Name:     p.Name,
+		Category: p.Category,
+		Author:   p.Author,
+		Comment:  p.Comment,
+		Custom:   p.Custom,
+	}
+	for _, v := range s.orderedValues(p) {
+		param := s.registry.Get(v.ID)
+		if param == nil {
+			continue // Plain values need the parameter's range
+		}
+		doc.Parameters = append(doc.Parameters, jsonParam{
+			ID:    v.ID,
+			Name:  param.Name,
+			Value: param.Denormalize(v.Value),
+			Unit:  param.Unit,
+		})
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// decodeJSON reads a JSON preset, normalizing values of known parameters;
+// names are informational and values of unknown IDs are dropped
+func (s *Store) decodeJSON(data []byte) (*Preset, error) {
+	var doc jsonPreset
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+	}
+	if doc.Format != jsonFormat {
+		return nil, fmt.Errorf("%w: format %q", ErrInvalidPreset, doc.Format)
+	}
+	if doc.Version > jsonVersion {
+		return nil, fmt.Errorf("%w: version %d is newer than supported version %d", ErrInvalidPreset, doc.Version, jsonVersion)
+	}
+
+	p := &Preset{
+		Name:     doc.Name,
+		Category: doc.Category,
+		Author:   doc.Author,
+		Comment:  doc.Comment,
+		Values:   make(map[uint32]float64, len(doc.Parameters)),
+		Custom:   doc.Custom,
+	}
+	if len(doc.ClassID) != 32 {
+		return nil, fmt.Errorf("%w: bad class ID", ErrInvalidPreset)
+	}
+	if _, err := hex.Decode(p.ClassID[:], []byte(doc.ClassID)); err != nil {
+		return nil, fmt.Errorf("%w: bad class ID", ErrInvalidPreset)
+	}
+	for _, jp := range doc.Parameters {
+		if param := s.registry.Get(jp.ID); param != nil {
+			p.Values[jp.ID] = param.Normalize(jp.Value)
+		}
+	}
+	return p, nil
+}
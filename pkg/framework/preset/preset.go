@@ -0,0 +1,197 @@
+// Package preset reads and writes plugin presets as standard VST3
+// .vstpreset files and as human-readable JSON, and scans preset folders.
+package preset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+// Errors returned by preset decoding
+var (
+	ErrInvalidPreset = errors.New("invalid preset")
+	ErrWrongPlugin   = errors.New("preset belongs to another plugin")
+	ErrUnknownFormat = errors.New("unknown preset format")
+)
+
+// Format is a preset file format
+type Format int
+
+// Preset file formats
+const (
+	FormatVSTPreset Format = iota
+	FormatJSON
+)
+
+// File extensions of the preset formats
+const (
+	ExtVSTPreset = ".vstpreset"
+	ExtJSON      = ".json"
+)
+
+// FormatForPath returns the format matching a file extension
+func FormatForPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ExtVSTPreset:
+		return FormatVSTPreset, nil
+	case ExtJSON:
+		return FormatJSON, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownFormat, filepath.Ext(path))
+}
+
+// CustomState is implemented by processors that save state beyond parameter
+// values; plugin.StatefulProcessor satisfies it
+type CustomState interface {
+	SaveCustomState(w io.Writer) error
+	LoadCustomState(r io.Reader) error
+}
+
+// Preset is a named set of parameter values and custom state
+type Preset struct {
+	Name     string
+	Category string
+	Author   string
+	Comment  string
+	ClassID  [16]byte
+
+	// Normalized parameter values by ID
+	Values map[uint32]float64
+
+	// Custom state saved by the processor, nil if it has none
+	Custom []byte
+}
+
+// Store captures, applies, reads and writes the presets of one plugin
+type Store struct {
+	registry   *param.Registry
+	classID    [16]byte
+	custom     CustomState
+	pluginName string
+}
+
+// NewStore creates a preset store for a plugin's parameters. classID is the
+// plugin's UID, written into .vstpreset files and checked on load. custom
+// may be nil.
+func NewStore(registry *param.Registry, classID [16]byte, custom CustomState) *Store {
+	return &Store{
+		registry: registry,
+		classID:  classID,
+		custom:   custom,
+	}
+}
+
+// SetPluginName sets the plugin name written into .vstpreset metadata
+func (s *Store) SetPluginName(name string) {
+	s.pluginName = name
+}
+
+// Capture returns the current parameter values and custom state as a preset
+func (s *Store) Capture(name string) (*Preset, error) {
+	p := &Preset{
+		Name:    name,
+		ClassID: s.classID,
+		Values:  s.registry.Values(),
+	}
+	if s.custom != nil {
+		var buf bytes.Buffer
+		if err := s.custom.SaveCustomState(&buf); err != nil {
+			return nil, fmt.Errorf("saving custom state: %w", err)
+		}
+		p.Custom = buf.Bytes()
+	}
+	return p, nil
+}
+
+// Apply sets the preset's parameter values in one batch and loads its
+// custom state. Read-only and unknown parameters are skipped. Call from the
+// UI or controller thread.
+func (s *Store) Apply(p *Preset, notifyHost bool) error {
+	if p.ClassID != s.classID {
+		return ErrWrongPlugin
+	}
+	s.registry.ApplySnapshot(p.Values, notifyHost)
+	if s.custom != nil && p.Custom != nil {
+		if err := s.custom.LoadCustomState(bytes.NewReader(p.Custom)); err != nil {
+			return fmt.Errorf("loading custom state: %w", err)
+		}
+	}
+	return nil
+}
+
+// Encode writes a preset in the given format
+func (s *Store) Encode(p *Preset, format Format) ([]byte, error) {
+	switch format {
+	case FormatVSTPreset:
+		return s.encodeVSTPreset(p)
+	case FormatJSON:
+		return s.encodeJSON(p)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Decode reads a preset in the given format. Presets of other plugins are
+// rejected with ErrWrongPlugin.
+func (s *Store) Decode(data []byte, format Format) (*Preset, error) {
+	var p *Preset
+	var err error
+	switch format {
+	case FormatVSTPreset:
+		p, err = decodeVSTPreset(data)
+	case FormatJSON:
+		p, err = s.decodeJSON(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.ClassID != s.classID {
+		return nil, ErrWrongPlugin
+	}
+	return p, nil
+}
+
+// Save writes a preset to a file, choosing the format from the extension
+func (s *Store) Save(path string, p *Preset) error {
+	format, err := FormatForPath(path)
+	if err != nil {
+		return err
+	}
+	data, err := s.Encode(p, format)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load reads a preset file, choosing the format from the extension. A
+// preset without a name is named after the file.
+func (s *Store) Load(path string) (*Preset, error) {
+	format, err := FormatForPath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.Decode(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return p, nil
+}
@@ -0,0 +1,203 @@
+package preset
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+var testClassID = [16]byte{0xde, 0xad, 0xbe, 0xef, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+// blob is custom state holding raw bytes
+type blob struct{ data []byte }
+
+func (b *blob) SaveCustomState(w io.Writer) error {
+	_, err := w.Write(b.data)
+	return err
+}
+
+func (b *blob) LoadCustomState(r io.Reader) error {
+	var err error
+	b.data, err = io.ReadAll(r)
+	return err
+}
+
+func newTestStore() (*Store, *param.Registry, *blob) {
+	reg := param.NewRegistry()
+	reg.Add(
+		param.New(1, "Gain").Range(-24, 24).Default(0).Unit("dB").Build(),
+		param.New(2, "Mix").Range(0, 100).Default(100).Unit("%").Build(),
+	)
+	custom := &blob{}
+	s := NewStore(reg, testClassID, custom)
+	s.SetPluginName("Test Plugin")
+	return s, reg, custom
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatVSTPreset, FormatJSON} {
+		s, reg, custom := newTestStore()
+		reg.Get(1).SetValue(0.25)
+		reg.Get(2).SetValue(0.75)
+		custom.data = []byte{0, 1, 2, 255}
+
+		p, err := s.Capture("Warm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Category, p.Author, p.Comment = "Bass", "tester", "a <comment> & more"
+		data, err := s.Encode(p, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s2, reg2, custom2 := newTestStore()
+		loaded, err := s2.Decode(data, format)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if loaded.Name != "Warm" || loaded.Category != "Bass" || loaded.Author != "tester" || loaded.Comment != p.Comment {
+			t.Errorf("format %d: metadata %+v", format, loaded)
+		}
+		if err := s2.Apply(loaded, false); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(reg2.Get(1).GetValue()-0.25) > 1e-12 || math.Abs(reg2.Get(2).GetValue()-0.75) > 1e-12 {
+			t.Errorf("format %d: values %g, %g", format, reg2.Get(1).GetValue(), reg2.Get(2).GetValue())
+		}
+		if !bytes.Equal(custom2.data, custom.data) {
+			t.Errorf("format %d: custom state %v", format, custom2.data)
+		}
+	}
+}
+
+func TestJSONIsReadable(t *testing.T) {
+	s, reg, _ := newTestStore()
+	reg.Get(1).SetPlainValue(-6)
+	p, _ := s.Capture("Quiet")
+	data, err := s.Encode(p, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	if !strings.Contains(text, `"name": "Gain"`) || !strings.Contains(text, `"value": -6`) {
+		t.Errorf("JSON should hold plain values:\n%s", text)
+	}
+
+	// Hand edits use plain values
+	edited := strings.Replace(text, `"value": -6`, `"value": 12`, 1)
+	loaded, err := s.Decode([]byte(edited), FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Values[1] != 0.75 {
+		t.Errorf("edited value = %g, want 0.75", loaded.Values[1])
+	}
+}
+
+func TestVSTPresetLayout(t *testing.T) {
+	s, _, _ := newTestStore()
+	p, _ := s.Capture("Init")
+	data, err := s.Encode(p, FormatVSTPreset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[:4]) != "VST3" || string(data[8:40]) != "DEADBEEF0102030405060708090A0B0C" {
+		t.Errorf("header = %q", data[:40])
+	}
+	if !bytes.Contains(data, []byte(`id="MediaType" value="VstPreset"`)) {
+		t.Error("missing Info chunk")
+	}
+
+	// Corrupt files fail cleanly
+	for _, bad := range [][]byte{data[:20], data[:len(data)-10], append([]byte("VST2"), data[4:]...)} {
+		if _, err := s.Decode(bad, FormatVSTPreset); !errors.Is(err, ErrInvalidPreset) {
+			t.Errorf("corrupt preset: err = %v", err)
+		}
+	}
+
+	other := NewStore(param.NewRegistry(), [16]byte{1}, nil)
+	if _, err := other.Decode(data, FormatVSTPreset); !errors.Is(err, ErrWrongPlugin) {
+		t.Errorf("other plugin: err = %v", err)
+	}
+}
+
+func TestVSTPresetFileChunks(t *testing.T) {
+	s, _, _ := newTestStore()
+	p, _ := s.Capture("Init")
+	data, _ := s.Encode(p, FormatVSTPreset)
+
+	f, err := ReadVSTPresetFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.ClassID != testClassID || !bytes.HasPrefix(f.Component, []byte("VST3GO")) || f.Controller != nil {
+		t.Errorf("chunks: class %x, component %q, controller %q", f.ClassID, f.Component, f.Controller)
+	}
+	meta, err := f.Metadata()
+	if err != nil || meta["Name"] != "Init" || meta["PlugInName"] != "Test Plugin" {
+		t.Errorf("metadata %v: %v", meta, err)
+	}
+
+	// Rewritten files keep every chunk, including a controller state
+	f.Controller = []byte("controller")
+	again, err := ReadVSTPresetFile(f.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Component, f.Component) || string(again.Controller) != "controller" ||
+		!bytes.Equal(again.MetaInfo, f.MetaInfo) {
+		t.Error("chunks changed in a round trip")
+	}
+	if _, err := ReadVSTPresetFile(data[:30]); !errors.Is(err, ErrInvalidPreset) {
+		t.Errorf("truncated file: err = %v", err)
+	}
+}
+
+func TestSaveLoadScan(t *testing.T) {
+	dir := t.TempDir()
+	s, reg, _ := newTestStore()
+
+	for _, file := range []string{"Leads/Bright.vstpreset", "Leads/Airy.json", "Default.vstpreset"} {
+		p, _ := s.Capture("")
+		if err := s.Save(filepath.Join(dir, file), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o644)
+	os.WriteFile(filepath.Join(dir, "broken.vstpreset"), []byte("VST3"), 0o644)
+	foreign := NewStore(reg, [16]byte{9}, nil)
+	fp, _ := foreign.Capture("Foreign")
+	foreign.Save(filepath.Join(dir, "foreign.vstpreset"), fp)
+
+	if err := s.Save(filepath.Join(dir, "x.fxp"), &Preset{}); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Save .fxp: err = %v", err)
+	}
+
+	entries, err := s.Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Category+"/"+e.Name)
+	}
+	if strings.Join(got, ",") != "/Default,Leads/Airy,Leads/Bright" {
+		t.Errorf("Scan = %v", got)
+	}
+
+	if entries, err := s.Scan(filepath.Join(dir, "missing")); err != nil || len(entries) != 0 {
+		t.Errorf("missing folder: %v, %v", entries, err)
+	}
+
+	if d, err := UserDir("Vendor", "Plug"); err != nil || !strings.HasSuffix(d, filepath.Join("Vendor", "Plug")) {
+		t.Errorf("UserDir = %q, %v", d, err)
+	}
+}
@@ -0,0 +1,86 @@
+package preset
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Entry describes a preset file found by Scan
+type Entry struct {
+	Path     string
+	Name     string
+	Category string
+	Format   Format
+}
+
+// Scan walks a preset folder and its subfolders and returns the presets of
+// this plugin sorted by category and name. Files in other formats, presets
+// of other plugins and unreadable files are skipped; a missing folder
+// returns no entries.
+func (s *Store) Scan(dir string) ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		format, err := FormatForPath(path)
+		if err != nil {
+			return nil
+		}
+		p, err := s.Load(path)
+		if err != nil {
+			return nil
+		}
+		category := p.Category
+		if category == "" {
+			// Subfolders of the bank act as categories
+			if rel, err := filepath.Rel(dir, filepath.Dir(path)); err == nil && rel != "." {
+				category = filepath.ToSlash(rel)
+			}
+		}
+		entries = append(entries, Entry{Path: path, Name: p.Name, Category: category, Format: format})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Category != entries[j].Category {
+			return entries[i].Category < entries[j].Category
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+	return entries, nil
+}
+
+// UserDir returns the standard VST3 user preset folder of a plugin:
+// ~/.vst3/presets on Linux, ~/Library/Audio/Presets on macOS and
+// Documents/VST3 Presets on Windows, each followed by vendor and plugin
+func UserDir(vendor, plugin string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	var base string
+	switch runtime.GOOS {
+	case "darwin":
+		base = filepath.Join(home, "Library", "Audio", "Presets")
+	case "windows":
+		base = filepath.Join(home, "Documents", "VST3 Presets")
+	default:
+		base = filepath.Join(home, ".vst3", "presets")
+	}
+	return filepath.Join(base, vendor, plugin), nil
+}
@@ -0,0 +1,309 @@
+package preset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/framework/state"
+)
+
+// .vstpreset layout: a header naming the plugin class and the offset of a
+// chunk list at the end of the file, which locates the component state
+// ("Comp"), an optional controller state ("Cont") and XML metadata ("Info")
+const (
+	vstPresetVersion    = 1
+	vstPresetHeaderSize = 4 + 4 + 32 + 8
+	vstPresetEntrySize  = 4 + 8 + 8
+)
+
+// Chunk IDs
+var (
+	chunkHeader     = [4]byte{'V', 'S', 'T', '3'}
+	chunkList       = [4]byte{'L', 'i', 's', 't'}
+	chunkComponent  = [4]byte{'C', 'o', 'm', 'p'}
+	chunkController = [4]byte{'C', 'o', 'n', 't'}
+	chunkInfo       = [4]byte{'I', 'n', 'f', 'o'}
+)
+
+// Metadata attribute IDs, as used by the VST3 SDK's PresetFile
+const (
+	metaMediaType = "MediaType"
+	metaPlugIn    = "PlugInName"
+	metaName      = "Name"
+	metaCategory  = "MusicalCategory"
+	metaAuthor    = "Author"
+	metaComment   = "Comment"
+)
+
+// metaInfo is the XML document stored in the Info chunk
+type metaInfo struct {
+	XMLName xml.Name   `xml:"MetaInfo"`
+	Attrs   []metaAttr `xml:"Attr"`
+}
+
+type metaAttr struct {
+	ID    string `xml:"id,attr"`
+	Value string `xml:"value,attr"`
+	Type  string `xml:"type,attr"`
+	Flags string `xml:"flags,attr,omitempty"`
+}
+
+// chunkEntry locates one chunk in the file
+type chunkEntry struct {
+	id     [4]byte
+	offset int64
+	size   int64
+}
+
+// VSTPresetFile holds the raw chunks of a .vstpreset file, for tools that
+// inspect or rewrite plugin state without knowing the plugin
+type VSTPresetFile struct {
+	ClassID [16]byte
+
+	// Component is the state the plugin returns from getState
+	Component []byte
+
+	// Controller is the edit controller state, nil if the file has none
+	Controller []byte
+
+	// MetaInfo is the XML metadata document, nil if the file has none
+	MetaInfo []byte
+}
+
+// ReadVSTPresetFile splits a .vstpreset file into its chunks. The chunks
+// share memory with data.
+func ReadVSTPresetFile(data []byte) (*VSTPresetFile, error) {
+	if len(data) < vstPresetHeaderSize || !bytes.Equal(data[:4], chunkHeader[:]) {
+		return nil, fmt.Errorf("%w: missing VST3 header", ErrInvalidPreset)
+	}
+	f := &VSTPresetFile{}
+	if _, err := hex.Decode(f.ClassID[:], data[8:40]); err != nil {
+		return nil, fmt.Errorf("%w: bad class ID", ErrInvalidPreset)
+	}
+
+	entries, err := readChunkList(data, int64(binary.LittleEndian.Uint64(data[40:48])))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		chunk := data[e.offset : e.offset+e.size]
+		switch e.id {
+		case chunkComponent:
+			f.Component = chunk
+		case chunkController:
+			f.Controller = chunk
+		case chunkInfo:
+			f.MetaInfo = chunk
+		}
+	}
+	if f.Component == nil {
+		return nil, fmt.Errorf("%w: no component state", ErrInvalidPreset)
+	}
+	return f, nil
+}
+
+// Encode writes the chunks as a .vstpreset file
+func (f *VSTPresetFile) Encode() []byte {
+	var buf bytes.Buffer
+	buf.Write(chunkHeader[:])
+	binary.Write(&buf, binary.LittleEndian, int32(vstPresetVersion))
+	buf.WriteString(classIDString(f.ClassID))
+	binary.Write(&buf, binary.LittleEndian, int64(0)) // List offset, patched below
+
+	var entries []chunkEntry
+	for _, chunk := range []struct {
+		id   [4]byte
+		data []byte
+	}{
+		{chunkComponent, f.Component},
+		{chunkController, f.Controller},
+		{chunkInfo, f.MetaInfo},
+	} {
+		if chunk.data == nil && chunk.id != chunkComponent {
+			continue
+		}
+		start := int64(buf.Len())
+		buf.Write(chunk.data)
+		entries = append(entries, chunkEntry{chunk.id, start, int64(len(chunk.data))})
+	}
+
+	listOffset := int64(buf.Len())
+	buf.Write(chunkList[:])
+	binary.Write(&buf, binary.LittleEndian, int32(len(entries)))
+	for _, e := range entries {
+		buf.Write(e.id[:])
+		binary.Write(&buf, binary.LittleEndian, e.offset)
+		binary.Write(&buf, binary.LittleEndian, e.size)
+	}
+
+	out := buf.Bytes()
+	binary.LittleEndian.PutUint64(out[vstPresetHeaderSize-8:], uint64(listOffset))
+	return out
+}
+
+// Metadata returns the metadata attributes by ID, such as "Name" and
+// "PlugInName"
+func (f *VSTPresetFile) Metadata() (map[string]string, error) {
+	meta := make(map[string]string)
+	if f.MetaInfo == nil {
+		return meta, nil
+	}
+	var info metaInfo
+	if err := xml.Unmarshal(f.MetaInfo, &info); err != nil {
+		return nil, fmt.Errorf("%w: metadata: %v", ErrInvalidPreset, err)
+	}
+	for _, attr := range info.Attrs {
+		meta[attr.ID] = attr.Value
+	}
+	return meta, nil
+}
+
+// classIDString formats a class ID the way .vstpreset headers store it
+func classIDString(id [16]byte) string {
+	return strings.ToUpper(hex.EncodeToString(id[:]))
+}
+
+// orderedValues returns the preset values in registry order, followed by
+// values of unknown parameters in ID order
+func (s *Store) orderedValues(p *Preset) []state.Value {
+	values := make([]state.Value, 0, len(p.Values))
+	seen := make(map[uint32]bool, len(p.Values))
+	for _, param := range s.registry.All() {
+		if v, ok := p.Values[param.ID]; ok {
+			values = append(values, state.Value{ID: param.ID, Value: v})
+			seen[param.ID] = true
+		}
+	}
+	var rest []uint32
+	for id := range p.Values {
+		if !seen[id] {
+			rest = append(rest, id)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+	for _, id := range rest {
+		values = append(values, state.Value{ID: id, Value: p.Values[id]})
+	}
+	return values
+}
+
+// SetMetadata replaces the metadata with the given attributes, sorted by
+// ID. The media type is always set.
+func (f *VSTPresetFile) SetMetadata(meta map[string]string) error {
+	info := metaInfo{Attrs: []metaAttr{
+		{ID: metaMediaType, Value: "VstPreset", Type: "string", Flags: "writeProtected"},
+	}}
+	ids := make([]string, 0, len(meta))
+	for id := range meta {
+		if id != metaMediaType {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		info.Attrs = append(info.Attrs, metaAttr{ID: id, Value: meta[id], Type: "string"})
+	}
+	xmlData, err := xml.MarshalIndent(info, "", "\t")
+	if err != nil {
+		return err
+	}
+	f.MetaInfo = append([]byte(xml.Header), xmlData...)
+	return nil
+}
+
+// encodeVSTPreset writes a .vstpreset file whose component state is what
+// the plugin returns from getState, so hosts load it like their own presets
+func (s *Store) encodeVSTPreset(p *Preset) ([]byte, error) {
+	var component bytes.Buffer
+	data := &state.Data{Values: s.orderedValues(p), Custom: p.Custom}
+	if err := data.Encode(&component); err != nil {
+		return nil, err
+	}
+
+	info := metaInfo{Attrs: []metaAttr{
+		{ID: metaMediaType, Value: "VstPreset", Type: "string", Flags: "writeProtected"},
+	}}
+	for _, attr := range [][2]string{
+		{metaPlugIn, s.pluginName},
+		{metaName, p.Name},
+		{metaCategory, p.Category},
+		{metaAuthor, p.Author},
+		{metaComment, p.Comment},
+	} {
+		if attr[1] != "" {
+			info.Attrs = append(info.Attrs, metaAttr{ID: attr[0], Value: attr[1], Type: "string"})
+		}
+	}
+	xmlData, err := xml.MarshalIndent(info, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	f := &VSTPresetFile{
+		ClassID:   p.ClassID,
+		Component: component.Bytes(),
+		MetaInfo:  append([]byte(xml.Header), xmlData...),
+	}
+	return f.Encode(), nil
+}
+
+// decodeVSTPreset reads a .vstpreset file
+func decodeVSTPreset(data []byte) (*Preset, error) {
+	f, err := ReadVSTPresetFile(data)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := f.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	p := &Preset{
+		Name:     meta[metaName],
+		Category: meta[metaCategory],
+		Author:   meta[metaAuthor],
+		Comment:  meta[metaComment],
+		ClassID:  f.ClassID,
+		Values:   make(map[uint32]float64),
+	}
+
+	st, err := state.Decode(bytes.NewReader(f.Component))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+	}
+	for _, v := range st.Values {
+		p.Values[v.ID] = v.Value
+	}
+	p.Custom = st.Custom
+	return p, nil
+}
+
+// readChunkList reads and bounds-checks the chunk list
+func readChunkList(data []byte, offset int64) ([]chunkEntry, error) {
+	if offset < vstPresetHeaderSize || offset > int64(len(data))-8 ||
+		!bytes.Equal(data[offset:offset+4], chunkList[:]) {
+		return nil, fmt.Errorf("%w: missing chunk list", ErrInvalidPreset)
+	}
+	count := int64(int32(binary.LittleEndian.Uint32(data[offset+4:])))
+	pos := offset + 8
+	if count < 0 || count > (int64(len(data))-pos)/vstPresetEntrySize {
+		return nil, fmt.Errorf("%w: bad chunk count %d", ErrInvalidPreset, count)
+	}
+
+	entries := make([]chunkEntry, count)
+	for i := range entries {
+		e := &entries[i]
+		copy(e.id[:], data[pos:pos+4])
+		e.offset = int64(binary.LittleEndian.Uint64(data[pos+4:]))
+		e.size = int64(binary.LittleEndian.Uint64(data[pos+12:]))
+		if e.offset < 0 || e.size < 0 || e.offset > int64(len(data)) || e.size > int64(len(data))-e.offset {
+			return nil, fmt.Errorf("%w: chunk %q out of range", ErrInvalidPreset, e.id[:])
+		}
+		pos += vstPresetEntrySize
+	}
+	return entries, nil
+}
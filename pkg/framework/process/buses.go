@@ -0,0 +1,48 @@
+package process
+
+// InputBus returns the channels of one input bus, sliced from Input by the
+// bus layout the host recorded for this block. Without a recorded layout
+// all of Input is bus 0. Out of range buses return nil.
+func (c *Context) InputBus(bus int) [][]float32 {
+	return busChannels(c.Input, c.silence.inputs[:c.silence.numInputs], bus)
+}
+
+// OutputBus returns the channels of one output bus, sliced from Output by
+// the recorded bus layout
+func (c *Context) OutputBus(bus int) [][]float32 {
+	return busChannels(c.Output, c.silence.outputs[:c.silence.numOutputs], bus)
+}
+
+// InputBusChannels returns the channel count of an input bus
+func (c *Context) InputBusChannels(bus int) int {
+	return len(c.InputBus(bus))
+}
+
+// OutputBusChannels returns the channel count of an output bus
+func (c *Context) OutputBusChannels(bus int) int {
+	return len(c.OutputBus(bus))
+}
+
+// busChannels slices a flat channel list to one bus of a layout
+func busChannels(channels [][]float32, layout []busState, bus int) [][]float32 {
+	if len(layout) == 0 {
+		if bus == 0 && len(channels) > 0 {
+			return channels
+		}
+		return nil
+	}
+	if bus < 0 || bus >= len(layout) {
+		return nil
+	}
+	start := 0
+	for _, b := range layout[:bus] {
+		start += b.numChannels
+	}
+	// Hosts may hand over fewer buffers than the layout declares
+	start = min(start, len(channels))
+	end := min(start+layout[bus].numChannels, len(channels))
+	if start == end {
+		return nil
+	}
+	return channels[start:end:end]
+}
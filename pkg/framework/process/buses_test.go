@@ -0,0 +1,56 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+func TestContextBusChannels(t *testing.T) {
+	ctx := NewContext(16, param.NewRegistry())
+	channels := make([][]float32, 5)
+	for ch := range channels {
+		channels[ch] = make([]float32, 16)
+		channels[ch][0] = float32(ch)
+	}
+	ctx.Input = channels
+	ctx.Output = channels[:2]
+
+	// Without a recorded layout everything is bus 0
+	ctx.ResetBuses()
+	if got := ctx.InputBusChannels(0); got != 5 {
+		t.Errorf("Bus 0 without a layout has %d channels, want 5", got)
+	}
+	if ctx.InputBus(1) != nil {
+		t.Error("Bus 1 without a layout should be nil")
+	}
+
+	// Stereo main, mono aux and stereo aux
+	ctx.AddInputBus(2, 0)
+	ctx.AddInputBus(1, 0)
+	ctx.AddInputBus(2, 0)
+	ctx.AddOutputBus(2)
+	for bus, want := range [][]float32{{0, 1}, {2}, {3, 4}} {
+		got := ctx.InputBus(bus)
+		if len(got) != len(want) {
+			t.Fatalf("Bus %d has %d channels, want %d", bus, len(got), len(want))
+		}
+		for ch := range want {
+			if got[ch][0] != want[ch] {
+				t.Errorf("Bus %d channel %d is input %v, want %v", bus, ch, got[ch][0], want[ch])
+			}
+		}
+	}
+	if ctx.InputBus(3) != nil || ctx.InputBus(-1) != nil {
+		t.Error("Out of range buses should be nil")
+	}
+	if ctx.OutputBusChannels(0) != 2 || ctx.OutputBus(1) != nil {
+		t.Error("Output bus layout mismatch")
+	}
+
+	// A bus the host gave no buffers for is empty, not a neighbour's channels
+	ctx.Input = channels[:3]
+	if ctx.InputBus(2) != nil {
+		t.Error("Bus without buffers should be nil")
+	}
+}
@@ -0,0 +1,223 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+// Input calibration defaults and limits
+const (
+	DefaultCalibrationTarget   = -18.0 // dBFS RMS, the usual analog nominal level
+	DefaultCalibrationDuration = 5.0   // Seconds
+	DefaultCalibrationFloor    = -50.0 // dBFS, quieter windows are ignored
+	MinCalibrationDuration     = 0.5   // Seconds
+	MaxCalibrationDuration     = 60.0  // Seconds
+	calibrationWindow          = 0.05  // Seconds per gated measurement window
+)
+
+// ErrNoTrimParameter is returned when input calibration has no trim to set
+var ErrNoTrimParameter = errors.New("no trim parameter")
+
+// InputCalibration sets an input trim so the program arrives at a nominal
+// level, which is where level-sensitive stages such as tube and tape
+// emulations sound as intended. It registers a writable Calibrate trigger;
+// while the trigger is on it listens to the input for the set duration,
+// measures the RMS level over windows above the floor so pauses do not
+// drag it down, then sets the trim parameter (in dB) to the offset from the
+// target and turns the trigger off. Call Process at the start of
+// ProcessAudio, before the trim is applied; it only reads the input.
+type InputCalibration struct {
+	trim       *param.Parameter
+	trigger    *param.Parameter
+	sampleRate float64
+
+	target   float64 // dBFS RMS
+	duration float64 // Seconds
+	floor    float64 // dBFS
+
+	// Measurement state
+	listening   bool
+	remaining   int // Samples left to listen
+	windowLen   int
+	windowCount int
+	windowSum   float64 // Mean channel power summed over the current window
+	gatedSum    float64 // Power summed over the windows above the floor
+	gatedCount  int
+
+	measured   float64 // dBFS RMS of the last completed calibration
+	calibrated bool
+}
+
+// NewInputCalibration registers the Calibrate trigger under triggerID and
+// returns a calibration that writes its result to trim, a parameter in dB
+// such as one built with param.GainParameter
+func NewInputCalibration(registry *param.Registry, triggerID uint32, trim *param.Parameter, sampleRate float64) (*InputCalibration, error) {
+	if trim == nil {
+		return nil, fmt.Errorf("input calibration: %w", ErrNoTrimParameter)
+	}
+	if registry.Get(triggerID) != nil {
+		return nil, fmt.Errorf("input calibration: %w: %d", ErrParameterIDInUse, triggerID)
+	}
+	c := &InputCalibration{
+		trim:     trim,
+		target:   DefaultCalibrationTarget,
+		duration: DefaultCalibrationDuration,
+		floor:    DefaultCalibrationFloor,
+		trigger: param.Choice(triggerID, "Calibrate", []param.ChoiceOption{
+			{Value: 0, Name: "Idle"},
+			{Value: 1, Name: "Listening"},
+		}).Build(),
+	}
+	c.SetSampleRate(sampleRate)
+	if err := registry.Add(c.trigger); err != nil {
+		return nil, fmt.Errorf("input calibration: %w", err)
+	}
+	return c, nil
+}
+
+// TriggerID returns the ID of the Calibrate trigger
+func (c *InputCalibration) TriggerID() uint32 {
+	return c.trigger.ID
+}
+
+// SetSampleRate updates the sample rate used for the duration and windows
+func (c *InputCalibration) SetSampleRate(sampleRate float64) {
+	c.sampleRate = sampleRate
+	c.windowLen = max(1, int(calibrationWindow*sampleRate))
+}
+
+// SetTarget sets the nominal level in dBFS RMS
+func (c *InputCalibration) SetTarget(dB float64) {
+	c.target = math.Min(0, dB)
+}
+
+// GetTarget returns the nominal level in dBFS RMS
+func (c *InputCalibration) GetTarget() float64 {
+	return c.target
+}
+
+// SetDuration sets how long to listen in seconds (0.5-60). It applies from
+// the next calibration.
+func (c *InputCalibration) SetDuration(seconds float64) {
+	c.duration = math.Max(MinCalibrationDuration, math.Min(MaxCalibrationDuration, seconds))
+}
+
+// GetDuration returns the listening time in seconds
+func (c *InputCalibration) GetDuration() float64 {
+	return c.duration
+}
+
+// SetFloor sets the level in dBFS below which windows are left out of the
+// measurement
+func (c *InputCalibration) SetFloor(dB float64) {
+	c.floor = dB
+}
+
+// Start begins listening, restarting any calibration in progress
+func (c *InputCalibration) Start() {
+	c.listening = true
+	c.remaining = int(c.duration * c.sampleRate)
+	c.windowCount, c.windowSum = 0, 0
+	c.gatedSum, c.gatedCount = 0, 0
+	c.trigger.SetValue(1)
+}
+
+// Cancel stops listening and leaves the trim unchanged
+func (c *InputCalibration) Cancel() {
+	c.listening = false
+	c.trigger.SetValue(0)
+}
+
+// Listening returns true while a calibration is in progress
+func (c *InputCalibration) Listening() bool {
+	return c.listening
+}
+
+// Progress returns the completed fraction of the current calibration
+func (c *InputCalibration) Progress() float64 {
+	if !c.listening {
+		return 0
+	}
+	total := c.duration * c.sampleRate
+	return 1 - float64(c.remaining)/total
+}
+
+// Result returns the level measured by the last calibration in dBFS RMS and
+// the trim it called for, before the trim's range was applied. ok is false
+// if no calibration has completed or the input stayed below the floor.
+func (c *InputCalibration) Result() (measured, offset float64, ok bool) {
+	if !c.calibrated {
+		return 0, 0, false
+	}
+	return c.measured, c.target - c.measured, true
+}
+
+// Process follows the trigger and measures the input of the current block.
+// When the calibration completes the trim is set; with modulation output
+// enabled the change is also reported to the host.
+func (c *InputCalibration) Process(ctx *Context) {
+	switch on := c.trigger.GetValue() >= 0.5; {
+	case on && !c.listening:
+		c.Start()
+	case !on && c.listening:
+		c.Cancel()
+	}
+	if !c.listening {
+		return
+	}
+
+	numSamples := min(ctx.NumSamples(), c.remaining)
+	channels := len(ctx.Input)
+	for i := 0; i < numSamples; i++ {
+		power := 0.0
+		for _, in := range ctx.Input {
+			if i < len(in) {
+				power += float64(in[i]) * float64(in[i])
+			}
+		}
+		if channels > 0 {
+			power /= float64(channels)
+		}
+		c.windowSum += power
+		c.windowCount++
+		if c.windowCount == c.windowLen {
+			c.closeWindow()
+		}
+	}
+	c.remaining -= numSamples
+	if c.remaining <= 0 {
+		c.finish(ctx, max(0, numSamples-1))
+	}
+}
+
+// closeWindow adds the current window to the measurement if it is above
+// the floor
+func (c *InputCalibration) closeWindow() {
+	if c.windowCount > 0 {
+		mean := c.windowSum / float64(c.windowCount)
+		if mean > 0 && 10*math.Log10(mean) >= c.floor {
+			c.gatedSum += c.windowSum
+			c.gatedCount += c.windowCount
+		}
+	}
+	c.windowSum, c.windowCount = 0, 0
+}
+
+// finish sets the trim from the measurement and turns the trigger off
+func (c *InputCalibration) finish(ctx *Context, sampleOffset int) {
+	c.closeWindow()
+	c.listening = false
+	c.trigger.SetValue(0)
+	ctx.WriteModulation(c.trigger.ID, 0, sampleOffset)
+	if c.gatedCount == 0 {
+		c.calibrated = false
+		return
+	}
+	c.measured = 10 * math.Log10(c.gatedSum/float64(c.gatedCount))
+	c.calibrated = true
+	c.trim.SetValue(c.trim.Normalize(c.target - c.measured))
+	ctx.WriteModulation(c.trim.ID, c.trim.GetValue(), sampleOffset)
+}
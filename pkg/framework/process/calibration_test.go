@@ -0,0 +1,85 @@
+package process
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+// runCalibration feeds blocks of a stereo sine at the given peak level,
+// with every other second silent, until the calibration completes
+func runCalibration(t *testing.T, c *InputCalibration, ctx *Context, peak float64) {
+	t.Helper()
+	const block = 480
+	ctx.Input = [][]float32{make([]float32, block), make([]float32, block)}
+	n := 0
+	for blocks := 0; blocks < 1000; blocks++ {
+		for i := 0; i < block; i++ {
+			v := 0.0
+			if (n/48000)%2 == 0 {
+				v = peak * math.Sin(2*math.Pi*1000*float64(n)/48000)
+			}
+			ctx.Input[0][i], ctx.Input[1][i] = float32(v), float32(v)
+			n++
+		}
+		c.Process(ctx)
+		if !c.Listening() {
+			return
+		}
+	}
+	t.Fatal("Calibration did not complete")
+}
+
+func TestInputCalibration(t *testing.T) {
+	registry := param.NewRegistry()
+	trim := param.GainParameter(1, "Input Trim").Build()
+	registry.Add(trim)
+	c, err := NewInputCalibration(registry, 2, trim, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetDuration(2)
+	ctx := NewContext(480, registry)
+
+	// Idle until the trigger is pressed
+	c.Process(ctx)
+	if c.Listening() {
+		t.Fatal("Calibration should wait for the trigger")
+	}
+	registry.Get(c.TriggerID()).SetValue(1)
+
+	// A sine peaking at -12 dBFS is -15 dBFS RMS; the silent second is
+	// ignored, so the trim is -3 dB
+	runCalibration(t, c, ctx, math.Pow(10, -12.0/20))
+	measured, offset, ok := c.Result()
+	if !ok || math.Abs(measured+15.01) > 0.05 || math.Abs(offset+2.99) > 0.05 {
+		t.Errorf("Result = %.2f dBFS, %.2f dB, %v, want -15.01, -2.99", measured, offset, ok)
+	}
+	if got := trim.GetPlainValue(); math.Abs(got+2.99) > 0.05 {
+		t.Errorf("Trim = %.2f dB, want -2.99", got)
+	}
+	if registry.Get(c.TriggerID()).GetValue() != 0 {
+		t.Error("Trigger should return to Idle")
+	}
+
+	// Silence leaves the trim alone
+	c.Start()
+	runCalibration(t, c, ctx, 0)
+	if _, _, ok := c.Result(); ok || math.Abs(trim.GetPlainValue()+2.99) > 0.05 {
+		t.Errorf("Silent calibration changed the trim to %.2f dB", trim.GetPlainValue())
+	}
+}
+
+func TestInputCalibrationErrors(t *testing.T) {
+	registry := param.NewRegistry()
+	trim := param.GainParameter(1, "Input Trim").Build()
+	registry.Add(trim)
+	if _, err := NewInputCalibration(registry, 1, trim, 48000); !errors.Is(err, ErrParameterIDInUse) {
+		t.Errorf("Duplicate ID error = %v", err)
+	}
+	if _, err := NewInputCalibration(registry, 2, nil, 48000); !errors.Is(err, ErrNoTrimParameter) {
+		t.Errorf("Missing trim error = %v", err)
+	}
+}
@@ -110,52 +110,117 @@ type Context struct {
 	Input      [][]float32
 	Output     [][]float32
 	SampleRate float64
+	Mode       ProcessMode // Set up by the host; see IsOffline
+
+	// Double precision buffers, set instead of Input/Output when the host
+	// processes 64-bit audio (see plugin.Processor64)
+	Input64  [][]float64
+	Output64 [][]float64
 
 	// Pre-allocated work buffers
 	workBuffer []float32
 	tempBuffer []float32
 
 	// Parameter access
-	params *param.Registry
+	params    *param.Registry
+	snapshots *param.SnapshotBuffer // Per-block parameter values, nil without a registry
 
 	// Sample-accurate automation
 	paramChanges []ParameterChange // Pre-allocated slice for parameter changes
 	changeCount  int               // Number of active parameter changes
 
+	// Automation ramps
+	rampStarts  []ParameterChange // Values at the start of the host block, one per changed parameter
+	rampCount   int
+	blockOffset int // Offset of the current sub-block within the host block
+
 	// Transport and timing information
 	Transport *TransportInfo
 
 	// MIDI event processing
 	eventBuffer *midi.EventBuffer
+
+	// Bus layout and silence flags
+	silence silenceState
+
+	// Internal modulation reported to the host
+	modOutput modulationOutput
 }
 
 // NewContext creates a new process context with pre-allocated buffers
 func NewContext(maxBlockSize int, params *param.Registry) *Context {
+	var snapshots *param.SnapshotBuffer
+	if params != nil {
+		snapshots = param.NewSnapshotBuffer(params)
+	}
 	return &Context{
+		snapshots:    snapshots,
 		workBuffer:   make([]float32, maxBlockSize),
 		tempBuffer:   make([]float32, maxBlockSize),
 		params:       params,
 		paramChanges: make([]ParameterChange, 128), // Pre-allocate space for parameter changes
 		changeCount:  0,
+		rampStarts:   make([]ParameterChange, 128),
 		Transport:    &TransportInfo{}, // Initialize transport info
 		eventBuffer:  midi.NewEventBuffer(),
+		modOutput:    modulationOutput{changes: make([]ParameterChange, maxOutputParameterChanges)},
 	}
 }
 
-// Param returns the current value of a parameter (0-1 normalized)
+// Param returns the value of a parameter (0-1 normalized) as captured at the
+// start of this block
 func (c *Context) Param(id uint32) float64 {
+	if c.snapshots != nil {
+		return c.snapshots.Current().Value(id)
+	}
 	if p := c.params.Get(id); p != nil {
 		return p.GetValue()
 	}
 	return 0
 }
 
-// ParamPlain returns the current plain value of a parameter
+// ParamPlain returns the plain value of a parameter as captured at the start
+// of this block. Parameters built with Smooth return their smoothed value.
 func (c *Context) ParamPlain(id uint32) float64 {
-	if p := c.params.Get(id); p != nil {
-		return p.GetPlainValue()
+	p := c.params.Get(id)
+	if p == nil {
+		return 0
+	}
+	if c.snapshots != nil && !p.IsSmoothed() {
+		return c.snapshots.Current().Plain(id)
+	}
+	return p.GetSmoothedPlainValue()
+}
+
+// Params returns the parameter snapshot for this block, or nil if the context
+// has no registry
+func (c *Context) Params() *param.Snapshot {
+	if c.snapshots == nil {
+		return nil
+	}
+	return c.snapshots.Current()
+}
+
+// ParamSnapshots returns the snapshot buffer, from which other threads can
+// read the values the audio thread last used
+func (c *Context) ParamSnapshots() *param.SnapshotBuffer {
+	return c.snapshots
+}
+
+// CaptureParams takes the parameter snapshot for the next block. The host
+// wrapper calls this before every ProcessAudio call.
+func (c *Context) CaptureParams() {
+	if c.snapshots != nil {
+		c.snapshots.Capture()
+	}
+}
+
+// AdvanceSmoothing steps parameter smoothing forward by the current block size.
+// The host wrapper calls this before every ProcessAudio call.
+func (c *Context) AdvanceSmoothing() {
+	if c.params != nil {
+		c.params.AdvanceSmoothing(c.SampleRate, c.NumSamples())
 	}
-	return 0
 }
 
 // NumSamples returns the number of samples to process
@@ -166,9 +231,20 @@ func (c *Context) NumSamples() int {
 	if len(c.Output) > 0 && len(c.Output[0]) > 0 {
 		return len(c.Output[0])
 	}
+	if len(c.Input64) > 0 && len(c.Input64[0]) > 0 {
+		return len(c.Input64[0])
+	}
+	if len(c.Output64) > 0 && len(c.Output64[0]) > 0 {
+		return len(c.Output64[0])
+	}
 	return 0
 }
 
+// Is64Bit returns true if the current block carries double precision audio
+func (c *Context) Is64Bit() bool {
+	return len(c.Input64) > 0 || len(c.Output64) > 0
+}
+
 // NumInputChannels returns the number of input channels
 func (c *Context) NumInputChannels() int {
 	return len(c.Input)
@@ -201,6 +277,11 @@ func (c *Context) PassThrough() {
 	for ch := 0; ch < numChannels; ch++ {
 		copy(c.Output[ch], c.Input[ch])
 	}
+
+	numChannels = min(len(c.Input64), len(c.Output64))
+	for ch := 0; ch < numChannels; ch++ {
+		copy(c.Output64[ch], c.Input64[ch])
+	}
 }
 
 // Clear zeros the output buffers
@@ -210,6 +291,11 @@ func (c *Context) Clear() {
 			c.Output[ch][i] = 0
 		}
 	}
+	for ch := range c.Output64 {
+		for i := range c.Output64[ch] {
+			c.Output64[ch][i] = 0
+		}
+	}
 }
 
 // SetParameterAtOffset sets a parameter value at a specific sample offset within the current block
@@ -242,16 +328,56 @@ func (c *Context) AddParameterChange(paramID uint32, value float64, sampleOffset
 // ResetParameterChanges clears the parameter change list for the next processing block
 func (c *Context) ResetParameterChanges() {
 	c.changeCount = 0
+	c.rampCount = 0
+	c.blockOffset = 0
 }
 
-// SortParameterChanges sorts parameter changes by sample offset for processing
+// SortParameterChanges sorts parameter changes by sample offset for processing.
+// It also records each changed parameter's value at the start of the block,
+// which ParamRamp interpolates from.
 func (c *Context) SortParameterChanges() {
 	if c.changeCount > 1 {
-		// Sort only the active portion of the slice
-		sort.Slice(c.paramChanges[:c.changeCount], func(i, j int) bool {
+		// Sort only the active portion of the slice (stable keeps host point order)
+		sort.SliceStable(c.paramChanges[:c.changeCount], func(i, j int) bool {
 			return c.paramChanges[i].SampleOffset < c.paramChanges[j].SampleOffset
 		})
 	}
+	c.captureRampStarts()
+}
+
+// captureRampStarts stores the pre-change value of every parameter with changes
+func (c *Context) captureRampStarts() {
+	c.rampCount = 0
+	for _, change := range c.paramChanges[:c.changeCount] {
+		if _, found := c.rampStart(change.ParamID); found || c.rampCount >= len(c.rampStarts) {
+			continue
+		}
+		if p := c.params.Get(change.ParamID); p != nil {
+			c.rampStarts[c.rampCount] = ParameterChange{ParamID: change.ParamID, Value: p.GetValue()}
+			c.rampCount++
+		}
+	}
+}
+
+// rampStart returns the recorded block-start value for a parameter
+func (c *Context) rampStart(id uint32) (float64, bool) {
+	for i := 0; i < c.rampCount; i++ {
+		if c.rampStarts[i].ParamID == id {
+			return c.rampStarts[i].Value, true
+		}
+	}
+	return 0, false
+}
+
+// SetBlockOffset sets the offset of the current sub-block within the host block.
+// The host wrapper updates this while splitting blocks at parameter changes.
+func (c *Context) SetBlockOffset(offset int) {
+	c.blockOffset = offset
+}
+
+// BlockOffset returns the offset of the current sub-block within the host block
+func (c *Context) BlockOffset() int {
+	return c.blockOffset
 }
 
 // GetParameterChanges returns the active parameter changes for this block
@@ -266,11 +392,89 @@ func (c *Context) HasParameterChanges() bool {
 
 // ApplyParameterChange applies a parameter change immediately
 func (c *Context) ApplyParameterChange(change ParameterChange) {
+	if c.snapshots != nil {
+		c.snapshots.Set(change.ParamID, change.Value)
+		return
+	}
 	if param := c.params.Get(change.ParamID); param != nil {
 		param.SetValue(change.Value)
 	}
 }
 
+// Ramp describes a parameter moving linearly across the current block.
+// Values are plain values; sample positions are relative to the current block.
+type Ramp struct {
+	Start       float64 // Value at StartSample
+	End         float64 // Value at EndSample
+	StartSample int
+	EndSample   int // May lie beyond the block when the ramp continues into the next one
+}
+
+// IsRamping returns true if the value changes across the ramp
+func (r Ramp) IsRamping() bool {
+	return r.Start != r.End && r.EndSample > r.StartSample
+}
+
+// ValueAt returns the interpolated value at a sample position in the block
+func (r Ramp) ValueAt(sample int) float64 {
+	if !r.IsRamping() || sample <= r.StartSample {
+		return r.Start
+	}
+	if sample >= r.EndSample {
+		return r.End
+	}
+	t := float64(sample-r.StartSample) / float64(r.EndSample-r.StartSample)
+	return r.Start + (r.End-r.Start)*t
+}
+
+// Increment returns the per-sample change of the ramp
+func (r Ramp) Increment() float64 {
+	if !r.IsRamping() {
+		return 0
+	}
+	return (r.End - r.Start) / float64(r.EndSample-r.StartSample)
+}
+
+// ParamRamp returns the linear automation ramp of a parameter for the current block.
+// Host automation points are joined with straight lines, so a processor can
+// interpolate gains or filter sweeps per sample instead of stepping at change points.
+func (c *Context) ParamRamp(id uint32) Ramp {
+	p := c.params.Get(id)
+	if p == nil {
+		return Ramp{}
+	}
+
+	numSamples := c.NumSamples()
+	startValue, found := c.rampStart(id)
+	if !found {
+		plain := p.GetPlainValue()
+		return Ramp{Start: plain, End: plain, EndSample: numSamples}
+	}
+
+	prevOffset, prevValue := 0, startValue
+	for _, change := range c.paramChanges[:c.changeCount] {
+		if change.ParamID != id {
+			continue
+		}
+		if change.SampleOffset <= c.blockOffset {
+			prevOffset, prevValue = change.SampleOffset, change.Value
+			continue
+		}
+
+		// Interpolate the value at the start of this block from the surrounding points
+		t := float64(c.blockOffset-prevOffset) / float64(change.SampleOffset-prevOffset)
+		value := prevValue + (change.Value-prevValue)*t
+		return Ramp{
+			Start:     p.Denormalize(value),
+			End:       p.Denormalize(change.Value),
+			EndSample: change.SampleOffset - c.blockOffset,
+		}
+	}
+
+	plain := p.Denormalize(prevValue)
+	return Ramp{Start: plain, End: plain, EndSample: numSamples}
+}
+
 // Event processing methods
 
 // AddInputEvent adds a MIDI event to the input queue
@@ -278,7 +482,8 @@ func (c *Context) AddInputEvent(event midi.Event) {
 	c.eventBuffer.AddInputEvent(event)
 }
 
-// AddOutputEvent adds a MIDI event to the output queue
+// AddOutputEvent adds a MIDI event to the output queue. Its offset is
+// relative to the host block; the Send methods account for sub-blocks.
 func (c *Context) AddOutputEvent(event midi.Event) {
 	c.eventBuffer.AddOutputEvent(event)
 }
@@ -293,6 +498,13 @@ func (c *Context) GetAllInputEvents() []midi.Event {
 	return c.eventBuffer.GetInputEvents(0, int32(c.NumSamples()))
 }
 
+// AppendInputEvents appends this block's input events to dst and returns
+// the result. Unlike GetAllInputEvents it does not allocate once dst has
+// grown to fit, so it is safe to call on every block.
+func (c *Context) AppendInputEvents(dst []midi.Event) []midi.Event {
+	return c.eventBuffer.AppendInputEvents(dst, 0, int32(c.NumSamples()))
+}
+
 // GetOutputEvents returns all output events generated during processing
 func (c *Context) GetOutputEvents() []midi.Event {
 	return c.eventBuffer.GetOutputEvents()
@@ -0,0 +1,201 @@
+package process
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+func TestContextSmoothedParamPlain(t *testing.T) {
+	registry := param.NewRegistry()
+	registry.Add(param.New(0, "Gain").Range(-24, 24).Default(0).Smooth(4 * time.Millisecond).Build())
+
+	ctx := NewContext(64, registry)
+	ctx.SampleRate = 1000
+	ctx.Input = [][]float32{make([]float32, 2)}
+	ctx.Output = [][]float32{make([]float32, 2)}
+
+	ctx.AdvanceSmoothing()
+	if v := ctx.ParamPlain(0); v != 0 {
+		t.Errorf("Expected 0 dB, got %f", v)
+	}
+
+	// 4ms at 1kHz ramps over 4 samples, two blocks of 2 samples
+	registry.Get(0).SetPlainValue(12)
+	if v := ctx.ParamPlain(0); v != 0 {
+		t.Errorf("Expected value to hold until the next block, got %f", v)
+	}
+
+	ctx.AdvanceSmoothing()
+	if v := ctx.ParamPlain(0); math.Abs(v-6) > 1e-9 {
+		t.Errorf("Expected 6 dB halfway through the ramp, got %f", v)
+	}
+
+	ctx.AdvanceSmoothing()
+	if v := ctx.ParamPlain(0); math.Abs(v-12) > 1e-9 {
+		t.Errorf("Expected 12 dB at the end of the ramp, got %f", v)
+	}
+}
+
+func TestContextParamSnapshot(t *testing.T) {
+	registry := param.NewRegistry()
+	registry.Add(param.New(0, "Mix").Range(0, 100).Default(50).Build())
+
+	ctx := NewContext(64, registry)
+	ctx.CaptureParams()
+	if ctx.Param(0) != 0.5 || ctx.ParamPlain(0) != 50 {
+		t.Fatalf("Expected 50%%, got %f (%f)", ctx.ParamPlain(0), ctx.Param(0))
+	}
+
+	// A controller change mid-block is not seen until the next capture
+	registry.Get(0).SetValue(1)
+	if ctx.ParamPlain(0) != 50 {
+		t.Errorf("Expected value to hold for the block, got %f", ctx.ParamPlain(0))
+	}
+	ctx.CaptureParams()
+	if ctx.ParamPlain(0) != 100 {
+		t.Errorf("Expected 100 after capture, got %f", ctx.ParamPlain(0))
+	}
+
+	// Changes applied by the audio thread are seen immediately
+	ctx.ApplyParameterChange(ParameterChange{ParamID: 0, Value: 0.2})
+	if ctx.Params().Plain(0) != 20 || registry.Get(0).GetValue() != 0.2 {
+		t.Errorf("Expected 20 after applying a change, got %f", ctx.Params().Plain(0))
+	}
+}
+
+func TestContextParamRamp(t *testing.T) {
+	registry := param.NewRegistry()
+	registry.Add(param.New(0, "Cutoff").Range(0, 1000).Default(0).Build())
+	registry.Add(param.New(1, "Mix").Range(0, 100).Default(50).Build())
+
+	ctx := NewContext(64, registry)
+	ctx.Input = [][]float32{make([]float32, 64)}
+	ctx.Output = [][]float32{make([]float32, 64)}
+
+	// No automation: a flat ramp at the current value
+	ramp := ctx.ParamRamp(1)
+	if ramp.IsRamping() || ramp.Start != 50 || ramp.ValueAt(10) != 50 {
+		t.Errorf("Expected flat ramp at 50, got %+v", ramp)
+	}
+
+	// Cutoff moves from 0 to 1000 at sample 32, then to 500 at sample 48
+	ctx.ResetParameterChanges()
+	ctx.AddParameterChange(0, 0.5, 48)
+	ctx.AddParameterChange(0, 1.0, 32)
+	ctx.SortParameterChanges()
+
+	// First sub-block [0, 32)
+	ramp = ctx.ParamRamp(0)
+	if ramp.Start != 0 || ramp.End != 1000 || ramp.EndSample != 32 {
+		t.Errorf("Unexpected first ramp: %+v", ramp)
+	}
+	if v := ramp.ValueAt(16); math.Abs(v-500) > 1e-9 {
+		t.Errorf("Expected 500 halfway up the ramp, got %f", v)
+	}
+	if inc := ramp.Increment(); math.Abs(inc-1000.0/32) > 1e-9 {
+		t.Errorf("Unexpected increment %f", inc)
+	}
+
+	// Second sub-block [32, 48), as the host wrapper would present it
+	ctx.ApplyParameterChange(ctx.GetParameterChanges()[0])
+	ctx.SetBlockOffset(32)
+	ramp = ctx.ParamRamp(0)
+	if ramp.Start != 1000 || ramp.End != 500 || ramp.EndSample != 16 {
+		t.Errorf("Unexpected second ramp: %+v", ramp)
+	}
+
+	// A sub-block split by another parameter starts mid-ramp
+	ctx.SetBlockOffset(40)
+	ramp = ctx.ParamRamp(0)
+	if math.Abs(ramp.Start-750) > 1e-9 || ramp.End != 500 || ramp.EndSample != 8 {
+		t.Errorf("Unexpected mid-ramp: %+v", ramp)
+	}
+
+	// After the last point the value holds
+	ctx.SetBlockOffset(48)
+	ramp = ctx.ParamRamp(0)
+	if ramp.IsRamping() || ramp.Start != 500 {
+		t.Errorf("Expected flat ramp at 500, got %+v", ramp)
+	}
+}
+
+func TestContext64BitBuffers(t *testing.T) {
+	ctx := NewContext(64, param.NewRegistry())
+
+	in := []float64{0.1, 0.2, 0.3, 0.4}
+	out := make([]float64, len(in))
+	ctx.Input64 = [][]float64{in}
+	ctx.Output64 = [][]float64{out}
+
+	if !ctx.Is64Bit() {
+		t.Error("Expected 64-bit block")
+	}
+	if ctx.NumSamples() != len(in) {
+		t.Errorf("Expected %d samples, got %d", len(in), ctx.NumSamples())
+	}
+
+	ctx.PassThrough()
+	for i := range in {
+		if out[i] != in[i] {
+			t.Errorf("PassThrough sample %d: expected %f, got %f", i, in[i], out[i])
+		}
+	}
+
+	ctx.Clear()
+	for i := range out {
+		if out[i] != 0 {
+			t.Errorf("Clear sample %d: expected 0, got %f", i, out[i])
+		}
+	}
+}
+
+func TestContextWriteModulation(t *testing.T) {
+	registry := param.NewRegistry()
+	registry.Add(param.New(0, "Cutoff").Range(20, 20020).Default(1020).Build())
+	ctx := NewContext(64, registry)
+
+	// Disabled by default
+	ctx.WriteModulation(0, 0.5, 0)
+	if len(ctx.GetOutputParameterChanges()) != 0 {
+		t.Fatal("Expected no output changes while modulation output is disabled")
+	}
+
+	ctx.SetModulationOutput(true)
+	ctx.SetBlockOffset(16)
+	ctx.WriteModulation(0, 0.5, 0)
+	ctx.WriteModulation(0, 0.5, 4) // Repeat is skipped
+	ctx.WriteModulationPlain(0, 5020, 8)
+
+	changes := ctx.GetOutputParameterChanges()
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 output changes, got %d", len(changes))
+	}
+	if changes[0].SampleOffset != 16 || changes[0].Value != 0.5 {
+		t.Errorf("Unexpected first change: %+v", changes[0])
+	}
+	if changes[1].SampleOffset != 24 || math.Abs(changes[1].Value-0.25) > 1e-9 {
+		t.Errorf("Unexpected second change: %+v", changes[1])
+	}
+
+	ctx.ResetOutputParameterChanges()
+	if len(ctx.GetOutputParameterChanges()) != 0 {
+		t.Error("Expected output changes to be cleared")
+	}
+}
+
+func TestContextProcessMode(t *testing.T) {
+	ctx := NewContext(64, nil)
+	if ctx.Mode != ProcessRealtime || ctx.IsOffline() {
+		t.Error("New contexts should be realtime")
+	}
+	ctx.Mode = ProcessOffline
+	if !ctx.IsOffline() {
+		t.Error("Expected offline mode")
+	}
+	if ProcessPrefetch.String() != "prefetch" || ProcessMode(7).String() != "unknown" {
+		t.Errorf("Unexpected mode names %q, %q", ProcessPrefetch, ProcessMode(7))
+	}
+}
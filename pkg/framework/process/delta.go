@@ -0,0 +1,74 @@
+package process
+
+// DeltaMonitor outputs the difference between the processed and the
+// latency-aligned dry signal, so only what the processor adds or removes is
+// heard. This is useful when tuning compressors and EQs.
+type DeltaMonitor struct {
+	dry     *DryPath
+	enabled bool
+}
+
+// NewDeltaMonitor creates a delta monitor for up to maxChannels channels
+func NewDeltaMonitor(maxChannels, maxBlockSize int) *DeltaMonitor {
+	return &DeltaMonitor{
+		dry: NewDryPath(maxChannels, maxBlockSize),
+	}
+}
+
+// SetEnabled turns delta monitoring on or off
+func (m *DeltaMonitor) SetEnabled(enabled bool) {
+	m.enabled = enabled
+}
+
+// IsEnabled returns true if delta monitoring is on
+func (m *DeltaMonitor) IsEnabled() bool {
+	return m.enabled
+}
+
+// SetLatency sets the processor latency the dry signal is aligned to.
+// This allocates and must not be called from the audio thread.
+func (m *DeltaMonitor) SetLatency(samples int) {
+	m.dry.SetLatency(samples)
+}
+
+// Capture stores the dry input. Call it before processing, every block,
+// so the dry delay line stays primed while delta monitoring is off.
+func (m *DeltaMonitor) Capture(ctx *Context) {
+	m.dry.Capture(ctx)
+}
+
+// Apply replaces the output with processed minus dry when enabled
+func (m *DeltaMonitor) Apply(ctx *Context) {
+	if !m.enabled {
+		return
+	}
+
+	numChannels := m.dry.NumChannels()
+	if ctx.NumOutputChannels() < numChannels {
+		numChannels = ctx.NumOutputChannels()
+	}
+
+	for ch := 0; ch < numChannels; ch++ {
+		dry := m.dry.Channel(ch)
+		out := ctx.Output[ch]
+		n := len(dry)
+		if len(out) < n {
+			n = len(out)
+		}
+		for i := 0; i < n; i++ {
+			out[i] -= dry[i]
+		}
+	}
+}
+
+// Process captures the dry signal, runs fn and applies delta monitoring
+func (m *DeltaMonitor) Process(ctx *Context, fn func(ctx *Context)) {
+	m.Capture(ctx)
+	fn(ctx)
+	m.Apply(ctx)
+}
+
+// Reset clears the dry delay line
+func (m *DeltaMonitor) Reset() {
+	m.dry.Reset()
+}
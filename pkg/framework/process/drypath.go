@@ -0,0 +1,151 @@
+package process
+
+// DryPath keeps a latency-compensated copy of the input signal so it can be
+// compared or mixed against the processed output without comb filtering.
+// Capture must be called before the processor writes its output, since hosts
+// may process in place with shared input and output buffers.
+type DryPath struct {
+	latency  int
+	delay    [][]float32 // Per-channel ring buffers of length latency
+	writePos int
+	buffers  [][]float32 // Per-channel dry block (pre-allocated to maxBlockSize)
+	channels int         // Channels captured in the current block
+	samples  int         // Samples captured in the current block
+
+	// 64-bit counterparts, nil until EnableDouble
+	delay64   [][]float64
+	buffers64 [][]float64
+}
+
+// NewDryPath creates a dry path for up to maxChannels channels
+func NewDryPath(maxChannels, maxBlockSize int) *DryPath {
+	d := &DryPath{
+		delay:   make([][]float32, maxChannels),
+		buffers: make([][]float32, maxChannels),
+	}
+	for ch := range d.buffers {
+		d.buffers[ch] = make([]float32, maxBlockSize)
+	}
+	return d
+}
+
+// SetLatency sets the dry path delay in samples to match the plugin latency.
+// This allocates and must not be called from the audio thread.
+func (d *DryPath) SetLatency(samples int) {
+	if samples < 0 {
+		samples = 0
+	}
+	if samples == d.latency {
+		return
+	}
+	d.latency = samples
+	for ch := range d.delay {
+		d.delay[ch] = make([]float32, samples)
+	}
+	for ch := range d.delay64 {
+		d.delay64[ch] = make([]float64, samples)
+	}
+	d.writePos = 0
+}
+
+// EnableDouble allocates the buffers Capture64 needs for 64-bit blocks.
+// This allocates and must not be called from the audio thread.
+func (d *DryPath) EnableDouble() {
+	if d.buffers64 != nil {
+		return
+	}
+	d.delay64 = make([][]float64, len(d.delay))
+	d.buffers64 = make([][]float64, len(d.buffers))
+	for ch := range d.buffers64 {
+		d.delay64[ch] = make([]float64, d.latency)
+		d.buffers64[ch] = make([]float64, len(d.buffers[ch]))
+	}
+}
+
+// Latency returns the dry path delay in samples
+func (d *DryPath) Latency() int {
+	return d.latency
+}
+
+// Capture stores the current input block, delayed by the latency
+func (d *DryPath) Capture(ctx *Context) {
+	d.channels, d.samples, d.writePos = captureDry(ctx.Input, d.buffers, d.delay, ctx.NumSamples(), d.latency, d.writePos)
+}
+
+// Capture64 stores the current 64-bit input block, delayed by the latency.
+// It captures nothing unless EnableDouble was called.
+func (d *DryPath) Capture64(ctx *Context) {
+	d.channels, d.samples, d.writePos = captureDry(ctx.Input64, d.buffers64, d.delay64, ctx.NumSamples(), d.latency, d.writePos)
+}
+
+// captureDry copies input into the dry buffers through the delay lines and
+// returns the channels and samples captured and the new write position
+func captureDry[T float32 | float64](input, buffers, delay [][]T, numSamples, latency, writePos int) (int, int, int) {
+	if len(buffers) > 0 && numSamples > len(buffers[0]) {
+		numSamples = len(buffers[0])
+	}
+	channels := min(len(input), len(buffers))
+
+	for ch := 0; ch < channels; ch++ {
+		in := input[ch]
+		dry := buffers[ch][:numSamples]
+
+		if latency == 0 {
+			copy(dry, in)
+			continue
+		}
+
+		line := delay[ch]
+		pos := writePos
+		for i := 0; i < numSamples; i++ {
+			dry[i] = line[pos]
+			line[pos] = in[i]
+			pos++
+			if pos >= latency {
+				pos = 0
+			}
+		}
+	}
+
+	if latency > 0 {
+		writePos = (writePos + numSamples) % latency
+	}
+	return channels, numSamples, writePos
+}
+
+// Channel returns the captured dry block for a channel (nil if not captured)
+func (d *DryPath) Channel(ch int) []float32 {
+	if ch < 0 || ch >= d.channels {
+		return nil
+	}
+	return d.buffers[ch][:d.samples]
+}
+
+// Channel64 returns the captured 64-bit dry block for a channel (nil if not
+// captured)
+func (d *DryPath) Channel64(ch int) []float64 {
+	if ch < 0 || ch >= d.channels || ch >= len(d.buffers64) {
+		return nil
+	}
+	return d.buffers64[ch][:d.samples]
+}
+
+// NumChannels returns the number of channels captured in the current block
+func (d *DryPath) NumChannels() int {
+	return d.channels
+}
+
+// Reset clears the delay lines
+func (d *DryPath) Reset() {
+	for ch := range d.delay {
+		for i := range d.delay[ch] {
+			d.delay[ch][i] = 0
+		}
+	}
+	for ch := range d.delay64 {
+		clear(d.delay64[ch])
+	}
+	d.writePos = 0
+	d.channels = 0
+	d.samples = 0
+}
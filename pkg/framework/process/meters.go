@@ -0,0 +1,230 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+// Output meter defaults
+const (
+	DefaultPeakHoldTime  = 1.0  // Seconds a peak is held before it decays
+	DefaultPeakDecayRate = 20.0 // dB per second after the hold
+	DefaultClipHoldTime  = 0.0  // Seconds a clip stays lit, 0 latches until reset
+	DefaultClipLevel     = 0.0  // dBFS at or above which a sample clips
+	meterFloorDB         = -60.0
+)
+
+// ErrParameterIDInUse is returned when a meter parameter ID is already
+// registered
+var ErrParameterIDInUse = errors.New("parameter ID already in use")
+
+// OutputMeters publishes per-channel peak and clip indicators of the output
+// bus as read-only parameters, plus a writable clip reset trigger. For N
+// channels it registers N peak meters from firstID, N clip indicators after
+// them and the reset at firstID+2N. Call Process at the end of ProcessAudio.
+type OutputMeters struct {
+	firstID    uint32
+	channels   int
+	sampleRate float64
+
+	peakParams []*param.Parameter
+	clipParams []*param.Parameter
+	resetParam *param.Parameter
+
+	holdTime      float64
+	decayRate     float64
+	clipHoldTime  float64
+	clipThreshold float32 // Linear
+
+	// Per-channel state
+	peakDB   []float64
+	holdLeft []int // Samples until the peak starts decaying
+	clipped  []bool
+	clipLeft []int // Samples until the clip indicator goes out
+}
+
+// NewOutputMeters registers the meter parameters for an output bus with the
+// given number of channels
+func NewOutputMeters(registry *param.Registry, firstID uint32, channels int, sampleRate float64) (*OutputMeters, error) {
+	m := &OutputMeters{
+		firstID:       firstID,
+		channels:      channels,
+		sampleRate:    sampleRate,
+		peakParams:    make([]*param.Parameter, channels),
+		clipParams:    make([]*param.Parameter, channels),
+		holdTime:      DefaultPeakHoldTime,
+		decayRate:     DefaultPeakDecayRate,
+		clipHoldTime:  DefaultClipHoldTime,
+		clipThreshold: float32(math.Pow(10, DefaultClipLevel/20)),
+		peakDB:        make([]float64, channels),
+		holdLeft:      make([]int, channels),
+		clipped:       make([]bool, channels),
+		clipLeft:      make([]int, channels),
+	}
+
+	for ch := 0; ch < channels; ch++ {
+		suffix := channelSuffix(ch, channels)
+		m.peakParams[ch] = param.OutputLevelMeter(m.PeakID(ch), "Peak"+suffix).Build()
+		m.clipParams[ch] = param.Choice(m.ClipID(ch), "Clip"+suffix, []param.ChoiceOption{
+			{Value: 0, Name: "Off"},
+			{Value: 1, Name: "Clip"},
+		}).Flags(param.IsReadOnly).Build()
+		m.peakDB[ch] = meterFloorDB
+	}
+	m.resetParam = param.Choice(m.ResetID(), "Clip Reset", []param.ChoiceOption{
+		{Value: 0, Name: "Idle"},
+		{Value: 1, Name: "Reset"},
+	}).Build()
+
+	all := make([]*param.Parameter, 0, 2*channels+1)
+	all = append(all, m.peakParams...)
+	all = append(all, m.clipParams...)
+	all = append(all, m.resetParam)
+	for _, p := range all {
+		if registry.Get(p.ID) != nil {
+			return nil, fmt.Errorf("output meters: %w: %d", ErrParameterIDInUse, p.ID)
+		}
+	}
+	if err := registry.Add(all...); err != nil {
+		return nil, fmt.Errorf("output meters: %w", err)
+	}
+	return m, nil
+}
+
+// channelSuffix names a channel: none for mono, L/R for stereo, numbers
+// otherwise
+func channelSuffix(ch, channels int) string {
+	switch channels {
+	case 1:
+		return ""
+	case 2:
+		return [2]string{" L", " R"}[ch]
+	default:
+		return fmt.Sprintf(" %d", ch+1)
+	}
+}
+
+// PeakID returns the ID of a channel's peak meter
+func (m *OutputMeters) PeakID(ch int) uint32 {
+	return m.firstID + uint32(ch)
+}
+
+// ClipID returns the ID of a channel's clip indicator
+func (m *OutputMeters) ClipID(ch int) uint32 {
+	return m.firstID + uint32(m.channels+ch)
+}
+
+// ResetID returns the ID of the clip reset trigger
+func (m *OutputMeters) ResetID() uint32 {
+	return m.firstID + uint32(2*m.channels)
+}
+
+// SetSampleRate updates the sample rate used for hold times and decay
+func (m *OutputMeters) SetSampleRate(sampleRate float64) {
+	m.sampleRate = sampleRate
+}
+
+// SetHoldTime sets how long a peak is held before it decays, in seconds
+func (m *OutputMeters) SetHoldTime(seconds float64) {
+	m.holdTime = math.Max(0, seconds)
+}
+
+// SetDecayRate sets the peak decay after the hold in dB per second
+func (m *OutputMeters) SetDecayRate(dbPerSecond float64) {
+	m.decayRate = math.Max(0, dbPerSecond)
+}
+
+// SetClipHoldTime sets how long a clip indicator stays lit in seconds;
+// 0 latches it until the reset trigger or Reset
+func (m *OutputMeters) SetClipHoldTime(seconds float64) {
+	m.clipHoldTime = math.Max(0, seconds)
+}
+
+// SetClipLevel sets the level in dBFS at or above which a sample counts as
+// clipped
+func (m *OutputMeters) SetClipLevel(dB float64) {
+	m.clipThreshold = float32(math.Pow(10, dB/20))
+}
+
+// Process measures the output buffers of the current block and updates the
+// meter parameters
+func (m *OutputMeters) Process(ctx *Context) {
+	if m.resetParam.GetValue() >= 0.5 {
+		m.Reset()
+		// Return the trigger to Idle so the next press resets again
+		m.resetParam.SetValue(0)
+	}
+
+	numSamples := ctx.NumSamples()
+	elapsed := float64(numSamples) / m.sampleRate
+	for ch := 0; ch < m.channels; ch++ {
+		peak := float32(0)
+		if ch < len(ctx.Output) {
+			out := ctx.Output[ch]
+			for i := 0; i < numSamples && i < len(out); i++ {
+				if s := float32(math.Abs(float64(out[i]))); s > peak {
+					peak = s
+				}
+			}
+		}
+
+		// Peak with hold and linear dB decay
+		blockDB := meterFloorDB
+		if peak > 0 {
+			blockDB = math.Max(meterFloorDB, 20*math.Log10(float64(peak)))
+		}
+		if blockDB >= m.peakDB[ch] {
+			m.peakDB[ch] = blockDB
+			m.holdLeft[ch] = int(m.holdTime * m.sampleRate)
+		} else if m.holdLeft[ch] > 0 {
+			m.holdLeft[ch] -= numSamples
+		} else {
+			m.peakDB[ch] = math.Max(blockDB, m.peakDB[ch]-m.decayRate*elapsed)
+		}
+		m.peakParams[ch].SetValue(m.peakParams[ch].Normalize(math.Min(0, m.peakDB[ch])))
+
+		// Clip indicator
+		if peak >= m.clipThreshold {
+			m.clipped[ch] = true
+			m.clipLeft[ch] = int(m.clipHoldTime * m.sampleRate)
+		} else if m.clipped[ch] && m.clipHoldTime > 0 {
+			m.clipLeft[ch] -= numSamples
+			if m.clipLeft[ch] <= 0 {
+				m.clipped[ch] = false
+			}
+		}
+		clip := 0.0
+		if m.clipped[ch] {
+			clip = 1
+		}
+		m.clipParams[ch].SetValue(clip)
+	}
+}
+
+// PeakDB returns the held peak of a channel in dBFS
+func (m *OutputMeters) PeakDB(ch int) float64 {
+	if ch < 0 || ch >= m.channels {
+		return meterFloorDB
+	}
+	return m.peakDB[ch]
+}
+
+// Clipped returns true while a channel's clip indicator is lit
+func (m *OutputMeters) Clipped(ch int) bool {
+	return ch >= 0 && ch < m.channels && m.clipped[ch]
+}
+
+// Reset clears the peaks and clip indicators
+func (m *OutputMeters) Reset() {
+	for ch := 0; ch < m.channels; ch++ {
+		m.peakDB[ch] = meterFloorDB
+		m.holdLeft[ch] = 0
+		m.clipped[ch] = false
+		m.clipLeft[ch] = 0
+		m.peakParams[ch].SetValue(0)
+		m.clipParams[ch].SetValue(0)
+	}
+}
@@ -0,0 +1,128 @@
+package process
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+func TestOutputMetersParameters(t *testing.T) {
+	registry := param.NewRegistry()
+	m, err := NewOutputMeters(registry, 100, 2, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if registry.Count() != 5 {
+		t.Fatalf("Expected 5 parameters, got %d", registry.Count())
+	}
+	for id, name := range map[uint32]string{100: "Peak L", 101: "Peak R", 102: "Clip L", 103: "Clip R", 104: "Clip Reset"} {
+		p := registry.Get(id)
+		if p == nil || p.Name != name {
+			t.Errorf("Parameter %d should be %q, got %v", id, name, p)
+		}
+	}
+	if registry.Get(m.PeakID(1)).Flags&param.IsReadOnly == 0 || registry.Get(m.ClipID(0)).Flags&param.IsReadOnly == 0 {
+		t.Error("Peak and clip parameters should be read-only")
+	}
+	if registry.Get(m.ResetID()).Flags&param.IsReadOnly != 0 {
+		t.Error("Reset trigger should be writable")
+	}
+
+	// IDs must not collide with existing parameters
+	if _, err := NewOutputMeters(registry, 104, 1, 48000); err == nil {
+		t.Error("Expected an error for a duplicate parameter ID")
+	}
+}
+
+func TestOutputMetersPeakAndClip(t *testing.T) {
+	const block = 480
+	registry := param.NewRegistry()
+	m, err := NewOutputMeters(registry, 0, 2, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetHoldTime(0.1)
+
+	ctx := NewContext(block, registry)
+	ctx.Output = [][]float32{make([]float32, block), make([]float32, block)}
+
+	// Left at -6 dBFS, right clipping
+	ctx.Output[0][10] = 0.5
+	ctx.Output[1][20] = -1.2
+	m.Process(ctx)
+
+	if math.Abs(m.PeakDB(0)+6.02) > 0.01 {
+		t.Errorf("Left peak should be -6.02 dB, got %.2f", m.PeakDB(0))
+	}
+	if got := registry.Get(m.PeakID(0)).GetPlainValue(); math.Abs(got+6.02) > 0.01 {
+		t.Errorf("Left peak parameter should be -6.02 dB, got %.2f", got)
+	}
+	if m.Clipped(0) || !m.Clipped(1) {
+		t.Errorf("Clip flags wrong: L=%v R=%v", m.Clipped(0), m.Clipped(1))
+	}
+	if registry.Get(m.ClipID(1)).GetValue() != 1 {
+		t.Error("Right clip parameter should be lit")
+	}
+
+	// Silence: the peak holds for 100ms, then decays; the clip stays latched
+	ctx.Output[0][10] = 0
+	ctx.Output[1][20] = 0
+	for i := 0; i < 10; i++ {
+		m.Process(ctx)
+	}
+	if math.Abs(m.PeakDB(0)+6.02) > 0.01 {
+		t.Errorf("Peak should be held, got %.2f", m.PeakDB(0))
+	}
+	for i := 0; i < 20; i++ {
+		m.Process(ctx)
+	}
+	if m.PeakDB(0) > -6.5 {
+		t.Errorf("Peak should decay after the hold, got %.2f", m.PeakDB(0))
+	}
+	if !m.Clipped(1) {
+		t.Error("Clip should stay latched without a clip hold time")
+	}
+
+	// The reset trigger clears the clip and returns to Idle
+	registry.Get(m.ResetID()).SetValue(1)
+	m.Process(ctx)
+	if m.Clipped(1) || registry.Get(m.ClipID(1)).GetValue() != 0 {
+		t.Error("Reset trigger should clear the clip indicator")
+	}
+	if registry.Get(m.ResetID()).GetValue() != 0 {
+		t.Error("Reset trigger should return to Idle")
+	}
+}
+
+func TestOutputMetersClipHoldTime(t *testing.T) {
+	const block = 480
+	registry := param.NewRegistry()
+	m, err := NewOutputMeters(registry, 0, 1, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetClipHoldTime(0.05)
+	m.SetClipLevel(-1)
+
+	ctx := NewContext(block, registry)
+	ctx.Output = [][]float32{make([]float32, block)}
+	ctx.Output[0][0] = 0.95 // Above -1 dBFS
+	m.Process(ctx)
+	if !m.Clipped(0) {
+		t.Fatal("Sample above the clip level should clip")
+	}
+
+	ctx.Output[0][0] = 0
+	for i := 0; i < 4; i++ {
+		m.Process(ctx)
+	}
+	if !m.Clipped(0) {
+		t.Error("Clip should still be lit within the hold time")
+	}
+	m.Process(ctx)
+	if m.Clipped(0) {
+		t.Error("Clip should go out after the hold time")
+	}
+}
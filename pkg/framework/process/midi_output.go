@@ -0,0 +1,92 @@
+package process
+
+import "github.com/justyntemme/vst3go/pkg/midi"
+
+// The Send methods queue MIDI events for the host's output event list. The
+// sample offset is relative to the current block, like input event offsets,
+// and is shifted by the block offset during sample-accurate sub-blocks. The
+// plugin needs an event output bus (bus.Builder.WithEventOutput) for the host
+// to route them. Output events are sent and cleared after each block.
+
+// SendNoteOn queues a note on with a MIDI velocity of 1-127
+func (c *Context) SendNoteOn(channel, note, velocity uint8, sampleOffset int) {
+	c.eventBuffer.AddOutputEvent(midi.NoteOnEvent{
+		BaseEvent:  c.outputBase(channel, sampleOffset),
+		NoteNumber: note,
+		Velocity:   velocity,
+	})
+}
+
+// SendNoteOff queues a note off
+func (c *Context) SendNoteOff(channel, note, velocity uint8, sampleOffset int) {
+	c.eventBuffer.AddOutputEvent(midi.NoteOffEvent{
+		BaseEvent:  c.outputBase(channel, sampleOffset),
+		NoteNumber: note,
+		Velocity:   velocity,
+	})
+}
+
+// SendControlChange queues a continuous controller value of 0-127
+func (c *Context) SendControlChange(channel, controller, value uint8, sampleOffset int) {
+	c.eventBuffer.AddOutputEvent(midi.ControlChangeEvent{
+		BaseEvent:  c.outputBase(channel, sampleOffset),
+		Controller: controller,
+		Value:      value,
+	})
+}
+
+// SendPitchBend queues a pitch bend of -8192 to 8191, 0 is center
+func (c *Context) SendPitchBend(channel uint8, value int16, sampleOffset int) {
+	c.eventBuffer.AddOutputEvent(midi.PitchBendEvent{
+		BaseEvent: c.outputBase(channel, sampleOffset),
+		Value:     value,
+	})
+}
+
+// SendChannelPressure queues channel aftertouch
+func (c *Context) SendChannelPressure(channel, pressure uint8, sampleOffset int) {
+	c.eventBuffer.AddOutputEvent(midi.ChannelPressureEvent{
+		BaseEvent: c.outputBase(channel, sampleOffset),
+		Pressure:  pressure,
+	})
+}
+
+// SendPolyPressure queues aftertouch for one note
+func (c *Context) SendPolyPressure(channel, note, pressure uint8, sampleOffset int) {
+	c.eventBuffer.AddOutputEvent(midi.PolyPressureEvent{
+		BaseEvent:  c.outputBase(channel, sampleOffset),
+		NoteNumber: note,
+		Pressure:   pressure,
+	})
+}
+
+// SendProgramChange queues a program change
+func (c *Context) SendProgramChange(channel, program uint8, sampleOffset int) {
+	c.eventBuffer.AddOutputEvent(midi.ProgramChangeEvent{
+		BaseEvent: c.outputBase(channel, sampleOffset),
+		Program:   program,
+	})
+}
+
+// AppendOutputEvents appends the queued output events, in offset order, to
+// dst and returns the result. Offsets are relative to the host block. It
+// does not allocate once dst has grown.
+func (c *Context) AppendOutputEvents(dst []midi.Event) []midi.Event {
+	return c.eventBuffer.AppendOutputEvents(dst)
+}
+
+// outputBase places an output event on a channel at an offset within the
+// current block, clamped to the block
+func (c *Context) outputBase(channel uint8, sampleOffset int) midi.BaseEvent {
+	n := c.NumSamples()
+	if sampleOffset >= n {
+		sampleOffset = n - 1
+	}
+	if sampleOffset < 0 {
+		sampleOffset = 0
+	}
+	return midi.BaseEvent{
+		EventChannel: channel & 0x0F,
+		Offset:       int32(c.blockOffset + sampleOffset),
+	}
+}
@@ -0,0 +1,69 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/midi"
+)
+
+func TestSendEvents(t *testing.T) {
+	ctx := NewContext(64, param.NewRegistry())
+	ctx.Output = [][]float32{make([]float32, 64)}
+
+	ctx.SendControlChange(0, midi.CCModWheel, 64, 10)
+	ctx.SendNoteOn(17, 60, 100, 0)   // Channel wraps to 1
+	ctx.SendPitchBend(0, -8192, 100) // Clamped to the block
+	ctx.SendNoteOff(1, 60, 0, 32)
+
+	events := ctx.AppendOutputEvents(nil)
+	if len(events) != 4 {
+		t.Fatalf("got %d output events, want 4", len(events))
+	}
+	on, ok := events[0].(midi.NoteOnEvent)
+	if !ok || on.Channel() != 1 || on.NoteNumber != 60 || on.Velocity != 100 {
+		t.Errorf("first event %v, want the note on at 0 on channel 1", events[0])
+	}
+	if cc, ok := events[1].(midi.ControlChangeEvent); !ok || cc.SampleOffset() != 10 || cc.Value != 64 {
+		t.Errorf("second event %v, want the mod wheel at 10", events[1])
+	}
+	if _, ok := events[2].(midi.NoteOffEvent); !ok || events[2].SampleOffset() != 32 {
+		t.Errorf("third event %v, want the note off at 32", events[2])
+	}
+	if pb, ok := events[3].(midi.PitchBendEvent); !ok || pb.SampleOffset() != 63 || pb.Value != -8192 {
+		t.Errorf("last event %v, want the pitch bend clamped to 63", events[3])
+	}
+
+	ctx.ClearOutputEvents()
+	if events := ctx.AppendOutputEvents(events[:0]); len(events) != 0 {
+		t.Errorf("%d output events after clearing", len(events))
+	}
+}
+
+func TestSendEventsInSubBlock(t *testing.T) {
+	ctx := NewContext(64, param.NewRegistry())
+	out := make([]float32, 64)
+
+	// A sample-accurate sub-block covering samples 40-63
+	ctx.Output = [][]float32{out[40:]}
+	ctx.SetBlockOffset(40)
+	ctx.SendNoteOn(0, 64, 90, 5)
+	ctx.SendChannelPressure(0, 30, 50)
+
+	events := ctx.AppendOutputEvents(nil)
+	if len(events) != 2 || events[0].SampleOffset() != 45 || events[1].SampleOffset() != 63 {
+		t.Errorf("sub-block events %v, want offsets 45 and 63 in the host block", events)
+	}
+}
+
+func TestAppendOutputEventsDoesNotAllocate(t *testing.T) {
+	ctx := NewContext(64, param.NewRegistry())
+	ctx.Output = [][]float32{make([]float32, 64)}
+	ctx.AddOutputEvent(midi.ProgramChangeEvent{Program: 3})
+	ctx.AddOutputEvent(midi.PolyPressureEvent{NoteNumber: 60, Pressure: 10})
+
+	dst := make([]midi.Event, 0, 8)
+	if allocs := testing.AllocsPerRun(100, func() { dst = ctx.AppendOutputEvents(dst[:0]) }); allocs != 0 {
+		t.Errorf("AppendOutputEvents allocates %g times", allocs)
+	}
+}
@@ -0,0 +1,31 @@
+package process
+
+// ProcessMode is the processing mode the host set up, matching the VST3
+// kRealtime, kPrefetch and kOffline values
+type ProcessMode int32
+
+const (
+	ProcessRealtime ProcessMode = iota // Live playback, process calls are deadline bound
+	ProcessPrefetch                    // Rendered ahead of playback, still time bound
+	ProcessOffline                     // Bounce or export, no deadline
+)
+
+// String returns the mode name
+func (m ProcessMode) String() string {
+	switch m {
+	case ProcessRealtime:
+		return "realtime"
+	case ProcessPrefetch:
+		return "prefetch"
+	case ProcessOffline:
+		return "offline"
+	default:
+		return "unknown"
+	}
+}
+
+// IsOffline reports whether the host is rendering without a deadline, so a
+// processor may switch to slower, higher quality algorithms
+func (c *Context) IsOffline() bool {
+	return c.Mode == ProcessOffline
+}
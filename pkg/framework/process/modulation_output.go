@@ -0,0 +1,76 @@
+package process
+
+// maxOutputParameterChanges is the capacity of the output parameter change list
+const maxOutputParameterChanges = 256
+
+// modulationOutput collects internal modulation values to report to the host
+type modulationOutput struct {
+	enabled bool
+	changes []ParameterChange // Pre-allocated
+	count   int
+}
+
+// SetModulationOutput enables writing internal modulation to the host as
+// output parameter changes, so it can be recorded as automation.
+// The setting persists across blocks; it is off by default.
+func (c *Context) SetModulationOutput(enabled bool) {
+	c.modOutput.enabled = enabled
+}
+
+// ModulationOutputEnabled returns true if modulation is written to the host
+func (c *Context) ModulationOutputEnabled() bool {
+	return c.modOutput.enabled
+}
+
+// WriteModulation reports the modulated normalized value of a parameter at a
+// sample offset within the current block. It does nothing unless modulation
+// output is enabled, and skips points that repeat the previous value.
+func (c *Context) WriteModulation(paramID uint32, value float64, sampleOffset int) {
+	if !c.modOutput.enabled || c.modOutput.count >= len(c.modOutput.changes) {
+		return
+	}
+
+	if value < 0 {
+		value = 0
+	} else if value > 1 {
+		value = 1
+	}
+
+	// Skip repeats of the last point for this parameter
+	for i := c.modOutput.count - 1; i >= 0; i-- {
+		if c.modOutput.changes[i].ParamID == paramID {
+			if c.modOutput.changes[i].Value == value {
+				return
+			}
+			break
+		}
+	}
+
+	c.modOutput.changes[c.modOutput.count] = ParameterChange{
+		ParamID:      paramID,
+		Value:        value,
+		SampleOffset: c.blockOffset + sampleOffset,
+	}
+	c.modOutput.count++
+}
+
+// WriteModulationPlain reports a modulated plain value of a parameter
+func (c *Context) WriteModulationPlain(paramID uint32, plain float64, sampleOffset int) {
+	if !c.modOutput.enabled {
+		return
+	}
+	if p := c.params.Get(paramID); p != nil {
+		c.WriteModulation(paramID, p.Normalize(plain), sampleOffset)
+	}
+}
+
+// GetOutputParameterChanges returns the modulation points written this block.
+// Offsets are relative to the start of the host block.
+func (c *Context) GetOutputParameterChanges() []ParameterChange {
+	return c.modOutput.changes[:c.modOutput.count]
+}
+
+// ResetOutputParameterChanges clears the output parameter changes for the next block
+func (c *Context) ResetOutputParameterChanges() {
+	c.modOutput.count = 0
+}
@@ -0,0 +1,135 @@
+package process
+
+// MaxBuses is the number of input or output buses tracked for silence flags
+const MaxBuses = 16
+
+// busState tracks the channel count and silence flags of one bus.
+// Bit n of silenceFlags is set when channel n carries only silence.
+type busState struct {
+	numChannels  int
+	silenceFlags uint64
+}
+
+// silenceState holds per-block bus silence information (pre-allocated)
+type silenceState struct {
+	inputs      [MaxBuses]busState
+	outputs     [MaxBuses]busState
+	numInputs   int
+	numOutputs  int
+	outputsSet  bool // processor reported output silence itself
+	inputsKnown bool // host reported silence flags this block
+}
+
+// allChannelsMask returns a silence mask covering numChannels channels
+func allChannelsMask(numChannels int) uint64 {
+	if numChannels >= 64 {
+		return ^uint64(0)
+	}
+	return (uint64(1) << uint(numChannels)) - 1
+}
+
+// ResetBuses clears the bus layout and silence flags for a new block
+func (c *Context) ResetBuses() {
+	c.silence.numInputs = 0
+	c.silence.numOutputs = 0
+	c.silence.outputsSet = false
+	c.silence.inputsKnown = false
+}
+
+// AddInputBus records an input bus and the host's silence flags for it
+func (c *Context) AddInputBus(numChannels int, silenceFlags uint64) {
+	if c.silence.numInputs >= MaxBuses {
+		return
+	}
+	c.silence.inputs[c.silence.numInputs] = busState{
+		numChannels:  numChannels,
+		silenceFlags: silenceFlags & allChannelsMask(numChannels),
+	}
+	c.silence.numInputs++
+	c.silence.inputsKnown = true
+}
+
+// AddOutputBus records an output bus; its silence flags start cleared
+func (c *Context) AddOutputBus(numChannels int) {
+	if c.silence.numOutputs >= MaxBuses {
+		return
+	}
+	c.silence.outputs[c.silence.numOutputs] = busState{numChannels: numChannels}
+	c.silence.numOutputs++
+}
+
+// NumInputBuses returns the number of input buses in the current block
+func (c *Context) NumInputBuses() int {
+	return c.silence.numInputs
+}
+
+// NumOutputBuses returns the number of output buses in the current block
+func (c *Context) NumOutputBuses() int {
+	return c.silence.numOutputs
+}
+
+// InputIsSilent returns true if every channel of the input bus is flagged silent by the host
+func (c *Context) InputIsSilent(bus int) bool {
+	if bus < 0 || bus >= c.silence.numInputs {
+		return false
+	}
+	b := c.silence.inputs[bus]
+	return b.numChannels > 0 && b.silenceFlags == allChannelsMask(b.numChannels)
+}
+
+// InputChannelIsSilent returns true if a single input channel is flagged silent
+func (c *Context) InputChannelIsSilent(bus, channel int) bool {
+	if bus < 0 || bus >= c.silence.numInputs || channel < 0 || channel >= 64 {
+		return false
+	}
+	return c.silence.inputs[bus].silenceFlags&(uint64(1)<<uint(channel)) != 0
+}
+
+// AllInputsSilent returns true if the host flagged every input bus as silent.
+// It is false when there are no input buses (e.g. instruments).
+func (c *Context) AllInputsSilent() bool {
+	if !c.silence.inputsKnown || c.silence.numInputs == 0 {
+		return false
+	}
+	for bus := 0; bus < c.silence.numInputs; bus++ {
+		if c.silence.inputs[bus].numChannels > 0 && !c.InputIsSilent(bus) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetOutputSilent marks every channel of an output bus as silent (or not)
+// so the host can skip downstream processing
+func (c *Context) SetOutputSilent(bus int, silent bool) {
+	if bus < 0 || bus >= c.silence.numOutputs {
+		return
+	}
+	flags := uint64(0)
+	if silent {
+		flags = allChannelsMask(c.silence.outputs[bus].numChannels)
+	}
+	c.SetOutputSilenceFlags(bus, flags)
+}
+
+// SetOutputSilenceFlags sets the per-channel silence flags of an output bus
+func (c *Context) SetOutputSilenceFlags(bus int, flags uint64) {
+	if bus < 0 || bus >= c.silence.numOutputs {
+		return
+	}
+	c.silence.outputs[bus].silenceFlags = flags & allChannelsMask(c.silence.outputs[bus].numChannels)
+	c.silence.outputsSet = true
+}
+
+// OutputSilenceFlags returns the silence flags to report for an output bus
+func (c *Context) OutputSilenceFlags(bus int) uint64 {
+	if bus < 0 || bus >= c.silence.numOutputs {
+		return 0
+	}
+	return c.silence.outputs[bus].silenceFlags
+}
+
+// OutputSilenceReported returns true if the processor set output silence flags this block
+func (c *Context) OutputSilenceReported() bool {
+	return c.silence.outputsSet
+}
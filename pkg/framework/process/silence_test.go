@@ -0,0 +1,61 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+func TestContextSilenceFlags(t *testing.T) {
+	ctx := NewContext(64, param.NewRegistry())
+
+	// No buses: nothing is silent (instruments must always run)
+	ctx.ResetBuses()
+	if ctx.AllInputsSilent() {
+		t.Error("No inputs should not count as silent")
+	}
+
+	// Main stereo bus silent, sidechain left channel active
+	ctx.AddInputBus(2, 0x3)
+	ctx.AddInputBus(2, 0x2)
+	ctx.AddOutputBus(2)
+
+	if ctx.NumInputBuses() != 2 || ctx.NumOutputBuses() != 1 {
+		t.Fatalf("Unexpected bus counts: %d in, %d out", ctx.NumInputBuses(), ctx.NumOutputBuses())
+	}
+	if !ctx.InputIsSilent(0) {
+		t.Error("Main input should be silent")
+	}
+	if ctx.InputIsSilent(1) {
+		t.Error("Sidechain should not be silent")
+	}
+	if ctx.InputChannelIsSilent(1, 0) || !ctx.InputChannelIsSilent(1, 1) {
+		t.Error("Sidechain channel flags mismatch")
+	}
+	if ctx.AllInputsSilent() {
+		t.Error("Not all inputs are silent")
+	}
+	if ctx.InputIsSilent(5) {
+		t.Error("Out of range bus should not be silent")
+	}
+
+	// Output flags are only reported when set
+	if ctx.OutputSilenceReported() || ctx.OutputSilenceFlags(0) != 0 {
+		t.Error("Output flags should start cleared")
+	}
+	ctx.SetOutputSilent(0, true)
+	if flags := ctx.OutputSilenceFlags(0); flags != 0x3 {
+		t.Errorf("Expected output flags 0x3, got %#x", flags)
+	}
+	ctx.SetOutputSilenceFlags(0, 0xFF)
+	if flags := ctx.OutputSilenceFlags(0); flags != 0x3 {
+		t.Errorf("Flags beyond channel count should be masked, got %#x", flags)
+	}
+
+	// A new block starts clean
+	ctx.ResetBuses()
+	ctx.AddInputBus(2, 0x3)
+	if !ctx.AllInputsSilent() || ctx.OutputSilenceReported() {
+		t.Error("Expected fresh block with silent input and no output flags")
+	}
+}
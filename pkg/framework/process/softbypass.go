@@ -0,0 +1,197 @@
+package process
+
+import "github.com/justyntemme/vst3go/pkg/framework/param"
+
+// DefaultBypassRampTime is the default bypass crossfade time in seconds
+const DefaultBypassRampTime = 0.02
+
+// SoftBypass crossfades between the processed and the dry signal when bypass
+// is toggled, avoiding the click of a hard switch. The dry path is delayed by
+// the plugin latency so both signals stay aligned during the crossfade.
+type SoftBypass struct {
+	dry        *DryPath
+	sampleRate float64
+	rampTime   float64 // seconds
+	amount     float64 // current bypass amount: 0 = processed, 1 = bypassed
+	target     float64
+	param      *param.Parameter
+}
+
+// NewSoftBypass creates a soft bypass for up to maxChannels channels
+func NewSoftBypass(maxChannels, maxBlockSize int, sampleRate float64) *SoftBypass {
+	return &SoftBypass{
+		dry:        NewDryPath(maxChannels, maxBlockSize),
+		sampleRate: sampleRate,
+		rampTime:   DefaultBypassRampTime,
+	}
+}
+
+// NewParameterBypass creates a soft bypass bound to the registry's IsBypass
+// parameter, with the dry path delayed by latency samples, or returns nil
+// when there is no such parameter. Plugin wrappers use it to give the
+// host's bypass switch the standard behaviour without the processor's
+// help. It starts in the parameter's current state, without a crossfade.
+func NewParameterBypass(params *param.Registry, maxChannels, maxBlockSize int, sampleRate float64, latency int) *SoftBypass {
+	if params == nil {
+		return nil
+	}
+	p := params.GetBypass()
+	if p == nil {
+		return nil
+	}
+	b := NewSoftBypass(maxChannels, maxBlockSize, sampleRate)
+	b.BindParameter(p)
+	b.SetLatency(latency)
+	b.SetBypass(p.GetValue() > 0.5)
+	b.Reset()
+	return b
+}
+
+// SetSampleRate updates the sample rate used for the crossfade ramp
+func (b *SoftBypass) SetSampleRate(sampleRate float64) {
+	b.sampleRate = sampleRate
+}
+
+// SetRampTime sets the crossfade time in seconds (0 switches instantly)
+func (b *SoftBypass) SetRampTime(seconds float64) {
+	if seconds < 0 {
+		seconds = 0
+	}
+	b.rampTime = seconds
+}
+
+// EnableDouble allocates the dry path buffers Process64 needs for 64-bit
+// blocks. This allocates and must not be called from the audio thread.
+func (b *SoftBypass) EnableDouble() {
+	b.dry.EnableDouble()
+}
+
+// SetLatency sets the plugin latency in samples to compensate in the dry path.
+// This allocates and must not be called from the audio thread.
+func (b *SoftBypass) SetLatency(samples int) {
+	b.dry.SetLatency(samples)
+}
+
+// BindParameter makes Process follow a bypass parameter (on above 0.5),
+// typically the one built with param.BypassParameter
+func (b *SoftBypass) BindParameter(p *param.Parameter) {
+	b.param = p
+}
+
+// SetBypass starts a crossfade towards the bypassed or processed signal
+func (b *SoftBypass) SetBypass(bypass bool) {
+	if bypass {
+		b.target = 1
+	} else {
+		b.target = 0
+	}
+}
+
+// IsBypassed returns true if bypass is on (including while fading in)
+func (b *SoftBypass) IsBypassed() bool {
+	return b.target == 1
+}
+
+// IsRamping returns true while a crossfade is in progress
+func (b *SoftBypass) IsRamping() bool {
+	return b.amount != b.target
+}
+
+// Process captures the dry signal, runs fn and crossfades its output with the
+// dry signal. Once fully bypassed, fn is not called and the dry signal is output.
+func (b *SoftBypass) Process(ctx *Context, fn func(ctx *Context)) {
+	b.followParameter()
+	b.dry.Capture(ctx)
+	if b.amount == 1 && b.target == 1 {
+		outputDry(ctx.Output, b.dry.Channel)
+		return
+	}
+
+	fn(ctx)
+	b.amount = crossfade(b, ctx.Output, b.dry.Channel)
+}
+
+// Process64 is Process for 64-bit blocks. EnableDouble must have been
+// called, or the bypassed signal is silent.
+func (b *SoftBypass) Process64(ctx *Context, fn func(ctx *Context)) {
+	b.followParameter()
+	b.dry.Capture64(ctx)
+	if b.amount == 1 && b.target == 1 {
+		outputDry(ctx.Output64, b.dry.Channel64)
+		return
+	}
+
+	fn(ctx)
+	b.amount = crossfade(b, ctx.Output64, b.dry.Channel64)
+}
+
+// followParameter picks up a change of the bound bypass parameter
+func (b *SoftBypass) followParameter() {
+	if b.param != nil {
+		b.SetBypass(b.param.GetValue() > 0.5)
+	}
+}
+
+// outputDry replaces the output with the dry signal
+func outputDry[T float32 | float64](output [][]T, dry func(ch int) []T) {
+	for ch := range output {
+		if in := dry(ch); in != nil {
+			copy(output[ch], in)
+		} else {
+			clear(output[ch])
+		}
+	}
+}
+
+// crossfade mixes the dry signal into the processed output while the bypass
+// amount moves towards the target and returns the amount at the block end
+func crossfade[T float32 | float64](b *SoftBypass, output [][]T, dry func(ch int) []T) float64 {
+	if b.amount == 0 && b.target == 0 {
+		return 0
+	}
+
+	step := 1.0
+	if b.rampTime > 0 && b.sampleRate > 0 {
+		step = 1.0 / (b.rampTime * b.sampleRate)
+	}
+
+	end := b.amount
+	for ch := range output {
+		out := output[ch]
+		in := dry(ch)
+		amount := b.amount
+
+		for i := range out {
+			amount = b.advance(amount, step)
+			wet := out[i] * T(1-amount)
+			if i < len(in) {
+				wet += in[i] * T(amount)
+			}
+			out[i] = wet
+		}
+		end = amount
+	}
+	return end
+}
+
+// advance moves the bypass amount one sample towards the target
+func (b *SoftBypass) advance(amount, step float64) float64 {
+	if amount < b.target {
+		amount += step
+		if amount > b.target {
+			amount = b.target
+		}
+	} else if amount > b.target {
+		amount -= step
+		if amount < b.target {
+			amount = b.target
+		}
+	}
+	return amount
+}
+
+// Reset clears the dry path and jumps to the target state without a crossfade
+func (b *SoftBypass) Reset() {
+	b.dry.Reset()
+	b.amount = b.target
+}
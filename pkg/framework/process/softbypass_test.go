@@ -0,0 +1,144 @@
+package process
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+func TestSoftBypassCrossfade(t *testing.T) {
+	registry := param.NewRegistry()
+	registry.Add(param.BypassParameter(0, "Bypass").Bypass().Build())
+
+	ctx := NewContext(64, registry)
+	bypass := NewSoftBypass(1, 64, 1000)
+	bypass.SetRampTime(0.01) // 10 samples
+	bypass.BindParameter(registry.GetBypass())
+
+	silence := func(ctx *Context) {
+		clear(ctx.Output[0])
+	}
+
+	in := make([]float32, 20)
+	for i := range in {
+		in[i] = 1
+	}
+	out := make([]float32, len(in))
+	ctx.Input = [][]float32{in}
+	ctx.Output = [][]float32{out}
+
+	// Not bypassed: processed output only
+	bypass.Process(ctx, silence)
+	for i, v := range out {
+		if v != 0 {
+			t.Fatalf("Sample %d: expected processed output 0, got %f", i, v)
+		}
+	}
+
+	// Bypass ramps linearly towards the dry signal
+	registry.Get(0).SetValue(1)
+	bypass.Process(ctx, silence)
+	if math.Abs(float64(out[4])-0.5) > 1e-6 {
+		t.Errorf("Expected halfway crossfade at sample 4, got %f", out[4])
+	}
+	if out[19] != 1 || bypass.IsRamping() {
+		t.Errorf("Expected fully bypassed output, got %f", out[19])
+	}
+
+	// Fully bypassed blocks skip processing
+	called := false
+	bypass.Process(ctx, func(ctx *Context) { called = true })
+	if called {
+		t.Error("Processor should not run while fully bypassed")
+	}
+	if out[0] != 1 {
+		t.Errorf("Expected dry output, got %f", out[0])
+	}
+}
+
+func TestSoftBypassLatencyCompensation(t *testing.T) {
+	ctx := NewContext(64, param.NewRegistry())
+	bypass := NewSoftBypass(1, 64, 1000)
+	bypass.SetRampTime(0)
+	bypass.SetLatency(2)
+	bypass.SetBypass(true)
+
+	in := []float32{1, 2, 3, 4}
+	out := make([]float32, len(in))
+	ctx.Input = [][]float32{in}
+	ctx.Output = [][]float32{out}
+
+	bypass.Process(ctx, func(ctx *Context) {})
+	expected := []float32{0, 0, 1, 2}
+	for i := range expected {
+		if out[i] != expected[i] {
+			t.Errorf("Sample %d: expected %f, got %f", i, expected[i], out[i])
+		}
+	}
+}
+
+func TestNewParameterBypass(t *testing.T) {
+	registry := param.NewRegistry()
+	registry.Add(param.New(0, "Gain").Build())
+	if b := NewParameterBypass(registry, 2, 64, 48000, 0); b != nil {
+		t.Error("Registry without a bypass parameter should give no bypass")
+	}
+	if b := NewParameterBypass(nil, 2, 64, 48000, 0); b != nil {
+		t.Error("Nil registry should give no bypass")
+	}
+
+	// A plugin restored bypassed starts bypassed, without a crossfade
+	registry.Add(param.BypassParameter(1, "Bypass").Bypass().Build())
+	registry.Get(1).SetValue(1)
+	b := NewParameterBypass(registry, 1, 64, 48000, 3)
+	if b == nil || !b.IsBypassed() || b.IsRamping() {
+		t.Fatalf("Bypass = %+v, want bypassed and settled", b)
+	}
+
+	ctx := NewContext(64, registry)
+	in := []float32{1, 2, 3, 4, 5}
+	out := make([]float32, len(in))
+	ctx.Input = [][]float32{in}
+	ctx.Output = [][]float32{out}
+	b.Process(ctx, func(*Context) { t.Error("Processor ran while bypassed") })
+
+	// The dry signal is delayed by the latency
+	want := []float32{0, 0, 0, 1, 2}
+	for i := range out {
+		if out[i] != want[i] {
+			t.Fatalf("out = %v, want %v", out, want)
+		}
+	}
+}
+
+func TestSoftBypassProcess64(t *testing.T) {
+	ctx := NewContext(64, param.NewRegistry())
+	bypass := NewSoftBypass(1, 64, 1000)
+	bypass.SetRampTime(0.004) // 4 samples
+	bypass.SetLatency(1)
+	bypass.EnableDouble()
+
+	in := []float64{1, 2, 3, 4, 5, 6}
+	out := make([]float64, len(in))
+	ctx.Input64 = [][]float64{in}
+	ctx.Output64 = [][]float64{out}
+
+	// Bypass crossfades from the processed output to the delayed input
+	bypass.SetBypass(true)
+	process := func(ctx *Context) { clear(ctx.Output64[0]) }
+	bypass.Process64(ctx, process)
+	want := []float64{0, 0.5, 1.5, 3, 4, 5}
+	for i := range want {
+		if math.Abs(out[i]-want[i]) > 1e-9 {
+			t.Fatalf("out = %v, want %v", out, want)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		bypass.Process64(ctx, process)
+	})
+	if allocs != 0 {
+		t.Errorf("Process64 allocated %v times per block", allocs)
+	}
+}
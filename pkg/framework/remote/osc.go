@@ -0,0 +1,166 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrMalformed is returned for packets that are not valid OSC
+var ErrMalformed = errors.New("remote: malformed OSC packet")
+
+// bundleTag starts an OSC bundle
+const bundleTag = "#bundle"
+
+// Message is an OSC message. Arguments are int32, float32 or string values;
+// other OSC types are rejected when decoding.
+type Message struct {
+	Address string
+	Args    []interface{}
+}
+
+// Float returns argument i as a float64, converting int32 arguments
+func (m Message) Float(i int) (float64, bool) {
+	if i >= len(m.Args) {
+		return 0, false
+	}
+	switch v := m.Args[i].(type) {
+	case float32:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// MarshalBinary encodes the message as an OSC packet
+func (m Message) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	writeString(&buf, m.Address)
+	tags := []byte{','}
+	for _, arg := range m.Args {
+		switch arg.(type) {
+		case int32:
+			tags = append(tags, 'i')
+		case float32:
+			tags = append(tags, 'f')
+		case string:
+			tags = append(tags, 's')
+		default:
+			return nil, fmt.Errorf("remote: unsupported OSC argument type %T", arg)
+		}
+	}
+	writeString(&buf, string(tags))
+	for _, arg := range m.Args {
+		switch v := arg.(type) {
+		case int32:
+			binary.Write(&buf, binary.BigEndian, v)
+		case float32:
+			binary.Write(&buf, binary.BigEndian, math.Float32bits(v))
+		case string:
+			writeString(&buf, v)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeString writes a null-terminated string padded to four bytes
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteString(s)
+	buf.Write(make([]byte, 4-len(s)%4))
+}
+
+// readString reads a padded string and returns it with the rest of data
+func readString(data []byte) (string, []byte, error) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return "", nil, ErrMalformed
+	}
+	next := (end + 4) &^ 3
+	if next > len(data) {
+		return "", nil, ErrMalformed
+	}
+	return string(data[:end]), data[next:], nil
+}
+
+// ParsePacket decodes an OSC packet into its messages, flattening bundles.
+// Bundle time tags are ignored and messages are returned in packet order.
+func ParsePacket(data []byte) ([]Message, error) {
+	if len(data) >= 8 && string(data[:8]) == bundleTag+"\x00" {
+		return parseBundle(data)
+	}
+	m, err := parseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{m}, nil
+}
+
+// parseBundle decodes the elements of a bundle
+func parseBundle(data []byte) ([]Message, error) {
+	if len(data) < 16 {
+		return nil, ErrMalformed
+	}
+	data = data[16:] // Tag and time tag
+	var messages []Message
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, ErrMalformed
+		}
+		size := int(binary.BigEndian.Uint32(data))
+		data = data[4:]
+		if size < 0 || size > len(data) {
+			return nil, ErrMalformed
+		}
+		element, err := ParsePacket(data[:size])
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, element...)
+		data = data[size:]
+	}
+	return messages, nil
+}
+
+// parseMessage decodes a single OSC message
+func parseMessage(data []byte) (Message, error) {
+	address, data, err := readString(data)
+	if err != nil || len(address) == 0 || address[0] != '/' {
+		return Message{}, ErrMalformed
+	}
+	m := Message{Address: address}
+	if len(data) == 0 {
+		return m, nil // Old implementations omit the type tags
+	}
+	tags, data, err := readString(data)
+	if err != nil || len(tags) == 0 || tags[0] != ',' {
+		return Message{}, ErrMalformed
+	}
+	for _, tag := range tags[1:] {
+		switch tag {
+		case 'i', 'f':
+			if len(data) < 4 {
+				return Message{}, ErrMalformed
+			}
+			bits := binary.BigEndian.Uint32(data)
+			data = data[4:]
+			if tag == 'i' {
+				m.Args = append(m.Args, int32(bits))
+			} else {
+				m.Args = append(m.Args, math.Float32frombits(bits))
+			}
+		case 's':
+			var s string
+			if s, data, err = readString(data); err != nil {
+				return Message{}, err
+			}
+			m.Args = append(m.Args, s)
+		default:
+			return Message{}, fmt.Errorf("%w: unsupported type tag %q", ErrMalformed, tag)
+		}
+	}
+	return m, nil
+}
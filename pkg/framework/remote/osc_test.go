@@ -0,0 +1,69 @@
+package remote
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	in := Message{Address: "/param/info", Args: []interface{}{int32(7), "Gain", float32(0.25), "-6.0 dB"}}
+	data, err := in.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data)%4 != 0 {
+		t.Errorf("packet of %d bytes is not padded to four", len(data))
+	}
+	out, err := ParsePacket(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || !reflect.DeepEqual(out[0], in) {
+		t.Errorf("decoded %+v, want %+v", out, in)
+	}
+
+	if _, err := (Message{Address: "/x", Args: []interface{}{1.5}}).MarshalBinary(); err == nil {
+		t.Error("float64 argument encoded")
+	}
+}
+
+func TestParseBundle(t *testing.T) {
+	a, _ := Message{Address: "/param/1", Args: []interface{}{float32(0.5)}}.MarshalBinary()
+	b, _ := Message{Address: "/undo"}.MarshalBinary()
+
+	bundle := append([]byte(bundleTag+"\x00"), make([]byte, 8)...)
+	for _, element := range [][]byte{a, b} {
+		bundle = binary.BigEndian.AppendUint32(bundle, uint32(len(element)))
+		bundle = append(bundle, element...)
+	}
+	messages, err := ParsePacket(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[0].Address != "/param/1" || messages[1].Address != "/undo" {
+		t.Errorf("bundle decoded to %+v", messages)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	packets := map[string][]byte{
+		"empty":        nil,
+		"no slash":     []byte("abc\x00"),
+		"unterminated": []byte("/abc"),
+		"short arg":    []byte("/a\x00\x00,f\x00\x00\x00\x00"),
+		"bad tags":     []byte("/a\x00\x00x\x00\x00\x00"),
+		"short bundle": []byte(bundleTag + "\x00"),
+	}
+	for name, data := range packets {
+		if _, err := ParsePacket(data); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: error %v, want ErrMalformed", name, err)
+		}
+	}
+
+	// Unsupported argument types are rejected rather than misread
+	if _, err := ParsePacket([]byte("/a\x00\x00,d\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")); !errors.Is(err, ErrMalformed) {
+		t.Errorf("double argument: error %v", err)
+	}
+}
@@ -0,0 +1,314 @@
+// Package remote exposes a plugin's parameter registry over OSC, so external
+// UIs, controllers and test harnesses can read and tweak a plugin running
+// inside a DAW. The server is opt-in and reads requests on its own goroutine,
+// never on the audio thread; remote changes go through the registry's edit
+// methods, so the host records them as automation like edits in the
+// plugin's editor. Hosts only accept those edits on their UI thread, so a
+// deferred server queues requests until Dispatch is called there.
+//
+// The server understands these OSC addresses, replying to the sender:
+//
+//	/params                    reply /param/info i s f s (ID, name, normalized value, display) per parameter
+//	/param/<id>                reply /param/<id> f with the normalized value
+//	/param/<id> f              set the normalized value, reply with the new value
+//	/param/<id>/plain          reply /param/<id>/plain f with the plain value
+//	/param/<id>/plain f        set the plain value, reply with the new value
+//	/param/<id>/touch i        1 starts a gesture, 0 ends it; sets in between undo in one step
+//	/undo, /redo               undo or redo the last edit
+//
+// Failed requests are answered with /error s.
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+// EnvAddress is the environment variable ListenFromEnv reads the address
+// from, e.g. VST3GO_REMOTE=127.0.0.1:9000
+const EnvAddress = "VST3GO_REMOTE"
+
+// maxPacketSize is the largest UDP packet the server reads
+const maxPacketSize = 65536
+
+// maxPending is the most packets a deferred server queues for Dispatch
+const maxPending = 1024
+
+var (
+	// ErrListening is returned by Listen when the server is already running
+	ErrListening = errors.New("remote: server already listening")
+	// ErrClosed is returned by Listen after Close
+	ErrClosed = errors.New("remote: server closed")
+)
+
+// Server serves a parameter registry over OSC on UDP
+type Server struct {
+	params *param.Registry
+
+	mu       sync.Mutex
+	conn     net.PacketConn
+	closed   bool
+	deferred bool
+	pending  []func()        // Requests waiting for Dispatch
+	touched  map[uint32]bool // Parameters in a remote gesture, handling thread only
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a server for a registry; it does nothing until Listen
+func NewServer(params *param.Registry) *Server {
+	return &Server{
+		params:  params,
+		touched: make(map[uint32]bool),
+	}
+}
+
+// ListenFromEnv creates a deferred server listening on the address in
+// EnvAddress, for plugins running in a host. It returns nil and no error
+// when the variable is not set, so plugins can call it unconditionally.
+func ListenFromEnv(params *param.Registry) (*Server, error) {
+	address := os.Getenv(EnvAddress)
+	if address == "" {
+		return nil, nil
+	}
+	s := NewServer(params)
+	s.SetDeferred(true)
+	if err := s.Listen(address); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetDeferred makes the server queue requests for Dispatch instead of
+// handling them on its own goroutine. Set it before Listen.
+func (s *Server) SetDeferred(deferred bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deferred = deferred
+}
+
+// Dispatch handles the requests a deferred server has queued and returns
+// how many packets it handled. Call it from the host's UI thread: the plugin
+// wrapper calls it whenever the host calls the controller, and an editor
+// can call it from its timer for prompter replies.
+func (s *Server) Dispatch() int {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	for _, handle := range pending {
+		handle()
+	}
+	return len(pending)
+}
+
+// Listen starts serving on a UDP address such as "127.0.0.1:9000". Use a
+// loopback address unless remote machines should control the plugin.
+func (s *Server) Listen(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.conn != nil {
+		return ErrListening
+	}
+
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return fmt.Errorf("remote: listen on %s: %w", address, err)
+	}
+	s.conn = conn
+	s.wg.Add(1)
+	go s.serve(conn)
+	return nil
+}
+
+// Addr returns the address the server listens on, or nil before Listen
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.LocalAddr()
+}
+
+// Close stops the server and waits for the request in progress. Requests
+// waiting for Dispatch are dropped and gestures left open by clients are
+// ended, so call it from the thread that dispatches.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	conn := s.conn
+	s.closed = true
+	s.pending = nil
+	s.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+
+	err := conn.Close()
+	s.wg.Wait()
+	for id := range s.touched {
+		s.params.EndEdit(id)
+		delete(s.touched, id)
+	}
+	return err
+}
+
+// serve reads and answers packets until the connection is closed
+func (s *Server) serve(conn net.PacketConn) {
+	defer s.wg.Done()
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		messages, err := ParsePacket(buf[:n])
+		if err != nil {
+			s.reply(conn, from, errorMessage(err))
+			continue
+		}
+		handle := func() {
+			for _, m := range messages {
+				for _, r := range s.handle(m) {
+					s.reply(conn, from, r)
+				}
+			}
+		}
+		if !s.enqueue(handle) {
+			handle()
+		}
+	}
+}
+
+// enqueue queues a packet's handling for Dispatch on a deferred server and
+// reports whether it did. A full queue drops the packet.
+func (s *Server) enqueue(handle func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.deferred {
+		return false
+	}
+	if len(s.pending) < maxPending && !s.closed {
+		s.pending = append(s.pending, handle)
+	}
+	return true
+}
+
+// reply sends a message back to a client, ignoring clients that went away
+func (s *Server) reply(conn net.PacketConn, to net.Addr, m Message) {
+	data, err := m.MarshalBinary()
+	if err != nil {
+		return
+	}
+	_, _ = conn.WriteTo(data, to)
+}
+
+// errorMessage returns the /error reply for err
+func errorMessage(err error) Message {
+	return Message{Address: "/error", Args: []interface{}{err.Error()}}
+}
+
+// handle answers one request
+func (s *Server) handle(m Message) []Message {
+	switch m.Address {
+	case "/params":
+		return s.list()
+	case "/undo":
+		s.params.Undo()
+		return nil
+	case "/redo":
+		s.params.Redo()
+		return nil
+	}
+
+	rest, ok := strings.CutPrefix(m.Address, "/param/")
+	if !ok {
+		return []Message{errorMessage(fmt.Errorf("unknown address %s", m.Address))}
+	}
+	idText, command, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseUint(idText, 10, 32)
+	p := s.params.Get(uint32(id))
+	if err != nil || p == nil {
+		return []Message{errorMessage(fmt.Errorf("unknown parameter %s", idText))}
+	}
+
+	switch command {
+	case "":
+		if value, ok := m.Float(0); ok {
+			if err := s.edit(p.ID, value); err != nil {
+				return []Message{errorMessage(err)}
+			}
+		}
+		return []Message{{Address: m.Address, Args: []interface{}{float32(p.GetValue())}}}
+	case "plain":
+		if value, ok := m.Float(0); ok {
+			if err := s.edit(p.ID, p.Normalize(value)); err != nil {
+				return []Message{errorMessage(err)}
+			}
+		}
+		return []Message{{Address: m.Address, Args: []interface{}{float32(p.GetPlainValue())}}}
+	case "touch":
+		s.touch(p.ID, m)
+		return nil
+	default:
+		return []Message{errorMessage(fmt.Errorf("unknown address %s", m.Address))}
+	}
+}
+
+// list describes every parameter
+func (s *Server) list() []Message {
+	params := s.params.All()
+	replies := make([]Message, 0, len(params))
+	for _, p := range params {
+		value := p.GetValue()
+		replies = append(replies, Message{
+			Address: "/param/info",
+			Args:    []interface{}{int32(p.ID), p.Name, float32(value), p.FormatValue(value)},
+		})
+	}
+	return replies
+}
+
+// edit sets a normalized value, as a single undo step unless the parameter
+// is in a remote gesture
+func (s *Server) edit(id uint32, value float64) error {
+	if s.touched[id] {
+		if !s.params.PerformEdit(id, value) {
+			return fmt.Errorf("parameter %d is read-only", id)
+		}
+		return nil
+	}
+	s.params.BeginEdit(id)
+	ok := s.params.PerformEdit(id, value)
+	s.params.EndEdit(id)
+	if !ok {
+		return fmt.Errorf("parameter %d is read-only", id)
+	}
+	return nil
+}
+
+// touch starts or ends a remote gesture
+func (s *Server) touch(id uint32, m Message) {
+	value, _ := m.Float(0)
+	switch {
+	case value != 0 && !s.touched[id]:
+		s.touched[id] = true
+		s.params.BeginEdit(id)
+	case value == 0 && s.touched[id]:
+		delete(s.touched, id)
+		s.params.EndEdit(id)
+	}
+}
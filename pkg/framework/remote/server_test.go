@@ -0,0 +1,191 @@
+package remote
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+// client talks to a server over loopback UDP
+type client struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+func newTestServer(t *testing.T) (*param.Registry, *Server, *client) {
+	t.Helper()
+	return newServer(t, false)
+}
+
+// newServer starts a server, deferred or not, with a connected client
+func newServer(t *testing.T, deferred bool) (*param.Registry, *Server, *client) {
+	t.Helper()
+	reg := param.NewRegistry()
+	reg.Add(
+		param.New(1, "Gain").Range(-24, 24).Default(0).Build(),
+		param.New(2, "Meter").Range(0, 1).Flags(param.IsReadOnly).Build(),
+	)
+	s := NewServer(reg)
+	s.SetDeferred(deferred)
+	if err := s.Listen("127.0.0.1:0"); err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	conn, err := net.Dial("udp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return reg, s, &client{t: t, conn: conn}
+}
+
+// send sends a message without waiting for a reply
+func (c *client) send(address string, args ...interface{}) {
+	c.t.Helper()
+	data, err := Message{Address: address, Args: args}.MarshalBinary()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := c.conn.Write(data); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// receive reads the next reply
+func (c *client) receive() Message {
+	c.t.Helper()
+	buf := make([]byte, maxPacketSize)
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	messages, err := ParsePacket(buf[:n])
+	if err != nil || len(messages) != 1 {
+		c.t.Fatalf("reply %v: %v", messages, err)
+	}
+	return messages[0]
+}
+
+// request sends a message and returns the reply
+func (c *client) request(address string, args ...interface{}) Message {
+	c.t.Helper()
+	c.send(address, args...)
+	return c.receive()
+}
+
+func TestServerParameters(t *testing.T) {
+	reg, _, c := newTestServer(t)
+
+	if v, _ := c.request("/param/1").Float(0); v != 0.5 {
+		t.Errorf("queried value %g, want 0.5", v)
+	}
+	if v, _ := c.request("/param/1", float32(0.75)).Float(0); v != 0.75 || reg.Get(1).GetValue() != 0.75 {
+		t.Errorf("set value replied %g, registry holds %g", v, reg.Get(1).GetValue())
+	}
+	if v, _ := c.request("/param/1/plain", float32(-12)).Float(0); v != -12 {
+		t.Errorf("plain value %g, want -12", v)
+	}
+	if v, _ := c.request("/param/1/plain", int32(6)).Float(0); v != 6 {
+		t.Errorf("plain value %g from an int argument, want 6", v)
+	}
+
+	for _, address := range []string{"/param/2", "/param/99", "/param/x", "/nowhere"} {
+		var args []interface{}
+		if address == "/param/2" {
+			args = []interface{}{float32(1)}
+		}
+		if r := c.request(address, args...); r.Address != "/error" {
+			t.Errorf("%s answered with %+v, want an error", address, r)
+		}
+	}
+
+	info := c.request("/params")
+	if info.Address != "/param/info" || info.Args[0] != int32(1) || info.Args[1] != "Gain" {
+		t.Errorf("first parameter described as %+v", info)
+	}
+	if meter := c.receive(); meter.Args[1] != "Meter" {
+		t.Errorf("second parameter described as %+v", meter)
+	}
+}
+
+func TestServerGestureAndUndo(t *testing.T) {
+	reg, _, c := newTestServer(t)
+
+	// Sets inside a touch undo in one step
+	c.send("/param/1/touch", int32(1))
+	c.request("/param/1", float32(0.6))
+	c.request("/param/1", float32(0.7))
+	c.send("/param/1/touch", int32(0))
+	c.send("/undo")
+	if v, _ := c.request("/param/1").Float(0); v != 0.5 {
+		t.Errorf("value %g after undoing the gesture, want 0.5", v)
+	}
+	c.send("/redo")
+	if v, _ := c.request("/param/1").Float(0); v != float64(float32(0.7)) {
+		t.Errorf("value %g after redo, want 0.7", v)
+	}
+	if reg.CanRedo() {
+		t.Error("redo left in history")
+	}
+}
+
+// recordingHandler records the edits passed on to the host
+type recordingHandler struct {
+	edits []string
+}
+
+func (h *recordingHandler) BeginEdit(id uint32)              { h.edits = append(h.edits, "begin") }
+func (h *recordingHandler) PerformEdit(id uint32, v float64) { h.edits = append(h.edits, "perform") }
+func (h *recordingHandler) EndEdit(id uint32)                { h.edits = append(h.edits, "end") }
+
+func TestServerDeferred(t *testing.T) {
+	reg, s, c := newServer(t, true)
+	handler := &recordingHandler{}
+	reg.SetEditHandler(handler)
+
+	c.send("/param/1", float32(0.75))
+	deadline := time.Now().Add(2 * time.Second)
+	for reg.Get(1).GetValue() == 0.5 && time.Now().Before(deadline) {
+		// Nothing reaches the registry or the host until Dispatch
+		if len(handler.edits) != 0 {
+			t.Fatalf("edits %v before Dispatch", handler.edits)
+		}
+		s.Dispatch()
+		time.Sleep(time.Millisecond)
+	}
+	if v, _ := c.receive().Float(0); v != 0.75 {
+		t.Errorf("set value replied %g, want 0.75", v)
+	}
+	if len(handler.edits) != 3 {
+		t.Errorf("host saw %v, want begin, perform, end", handler.edits)
+	}
+
+	c.send("/param/1", float32(0.25))
+	s.Close()
+	if s.Dispatch() != 0 || reg.Get(1).GetValue() != 0.75 {
+		t.Error("request handled after Close")
+	}
+}
+
+func TestServerLifecycle(t *testing.T) {
+	_, s, _ := newTestServer(t)
+	if err := s.Listen("127.0.0.1:0"); !errors.Is(err, ErrListening) {
+		t.Errorf("second Listen returned %v, want ErrListening", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := s.Listen("127.0.0.1:0"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Listen after Close returned %v, want ErrClosed", err)
+	}
+
+	t.Setenv(EnvAddress, "")
+	if s, err := ListenFromEnv(param.NewRegistry()); s != nil || err != nil {
+		t.Errorf("ListenFromEnv without %s returned %v, %v", EnvAddress, s, err)
+	}
+}
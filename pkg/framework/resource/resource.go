@@ -0,0 +1,333 @@
+// Package resource provides a registry for plugin assets such as impulse
+// responses, wavetables and tuning files. Assets are looked up by name in one
+// or more file systems (typically a go:embed FS), decoded lazily on first use,
+// reference counted and cached per sample rate.
+package resource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/justyntemme/vst3go/pkg/audiofile"
+	"github.com/justyntemme/vst3go/pkg/dsp/oscillator"
+)
+
+// Built-in decoder kinds
+const (
+	// KindRaw returns the file contents as []byte
+	KindRaw = "raw"
+	// KindAudio decodes WAV/AIFF into *audiofile.Audio resampled to the requested rate
+	KindAudio = "audio"
+	// KindWavetable decodes .wav or .wt files into *oscillator.Wavetable
+	KindWavetable = "wavetable"
+)
+
+var (
+	// ErrNotFound is returned when no source contains the named resource
+	ErrNotFound = errors.New("resource not found")
+	// ErrUnknownKind is returned when no decoder is registered for a kind
+	ErrUnknownKind = errors.New("unknown resource kind")
+	// ErrReleased is returned when a handle is used after Release
+	ErrReleased = errors.New("resource handle released")
+)
+
+// Decoder turns raw file contents into a decoded value
+type Decoder struct {
+	// Decode builds the value. sampleRate is 0 unless RateDependent is set.
+	Decode func(name string, data []byte, sampleRate float64) (interface{}, error)
+	// RateDependent caches a separate value for each sample rate
+	RateDependent bool
+}
+
+// cacheKey identifies one decoded value
+type cacheKey struct {
+	kind       string
+	name       string
+	sampleRate float64
+}
+
+// entry is a decoded value shared by all handles with the same key
+type entry struct {
+	ready chan struct{} // Closed once value and err are set
+	value interface{}
+	err   error
+	refs  int
+}
+
+// Registry resolves resource names to decoded values
+type Registry struct {
+	mu       sync.Mutex
+	sources  []fs.FS
+	decoders map[string]Decoder
+	cache    map[cacheKey]*entry
+}
+
+// NewRegistry creates an empty registry with the built-in decoders
+func NewRegistry() *Registry {
+	r := &Registry{
+		decoders: make(map[string]Decoder),
+		cache:    make(map[cacheKey]*entry),
+	}
+	r.RegisterDecoder(KindRaw, Decoder{Decode: decodeRaw})
+	r.RegisterDecoder(KindAudio, Decoder{Decode: decodeAudio, RateDependent: true})
+	r.RegisterDecoder(KindWavetable, Decoder{Decode: decodeWavetable})
+	return r
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// Default returns the shared registry used by DSP modules
+func Default() *Registry {
+	defaultRegistryOnce.Do(func() {
+		defaultRegistry = NewRegistry()
+	})
+	return defaultRegistry
+}
+
+// AddFS adds a source of resources, e.g. an embed.FS. Sources added later
+// take precedence, so a directory added after the embedded assets can
+// override them.
+func (r *Registry) AddFS(fsys fs.FS) {
+	r.mu.Lock()
+	r.sources = append(r.sources, fsys)
+	r.mu.Unlock()
+}
+
+// AddDir adds a directory on disk as a source of resources
+func (r *Registry) AddDir(dir string) {
+	r.AddFS(os.DirFS(dir))
+}
+
+// RegisterDecoder adds or replaces the decoder for a kind. Values already
+// cached are not affected.
+func (r *Registry) RegisterDecoder(kind string, d Decoder) {
+	r.mu.Lock()
+	r.decoders[kind] = d
+	r.mu.Unlock()
+}
+
+// Exists returns true if a source contains name
+func (r *Registry) Exists(name string) bool {
+	_, err := r.readFile(name)
+	return err == nil
+}
+
+// Names returns all resource names under dir in all sources, sorted. Use "."
+// for everything.
+func (r *Registry) Names(dir string) []string {
+	r.mu.Lock()
+	sources := append([]fs.FS(nil), r.sources...)
+	r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, fsys := range sources {
+		fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				seen[p] = true
+			}
+			return nil
+		})
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadFile returns the undecoded contents of a resource without caching
+func (r *Registry) ReadFile(name string) ([]byte, error) {
+	return r.readFile(name)
+}
+
+// cleanName returns the canonical form of a resource name, so "a.wav",
+// "/a.wav" and "./a.wav" name the same resource
+func cleanName(name string) string {
+	return path.Clean(strings.TrimPrefix(name, "/"))
+}
+
+// readFile reads name from the newest source that has it
+func (r *Registry) readFile(name string) ([]byte, error) {
+	name = cleanName(name)
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("%w: invalid name %q", ErrNotFound, name)
+	}
+
+	r.mu.Lock()
+	sources := append([]fs.FS(nil), r.sources...)
+	r.mu.Unlock()
+
+	for i := len(sources) - 1; i >= 0; i-- {
+		data, err := fs.ReadFile(sources[i], name)
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Acquire returns a handle to the decoded resource, decoding it if no other
+// handle holds it. sampleRate is ignored for rate-independent kinds. Call
+// Release when done; the value is dropped when the last handle is released.
+// Acquire may read files and allocate, so call it from Initialize or
+// SetupProcessing rather than Process.
+func (r *Registry) Acquire(kind, name string, sampleRate float64) (*Handle, error) {
+	r.mu.Lock()
+	dec, ok := r.decoders[kind]
+	if !ok {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKind, kind)
+	}
+	if !dec.RateDependent {
+		sampleRate = 0
+	}
+
+	name = cleanName(name)
+	key := cacheKey{kind: kind, name: name, sampleRate: sampleRate}
+	e, cached := r.cache[key]
+	if !cached {
+		e = &entry{ready: make(chan struct{})}
+		r.cache[key] = e
+	}
+	e.refs++
+	r.mu.Unlock()
+
+	if cached {
+		<-e.ready
+	} else {
+		// Decode outside the lock; concurrent requests for the key wait on ready
+		e.value, e.err = r.decode(dec, name, sampleRate)
+		close(e.ready)
+	}
+
+	h := &Handle{registry: r, key: key, entry: e}
+	if e.err != nil {
+		h.Release()
+		return nil, e.err
+	}
+	return h, nil
+}
+
+// decode reads and decodes one resource
+func (r *Registry) decode(dec Decoder, name string, sampleRate float64) (interface{}, error) {
+	data, err := r.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := dec.Decode(name, data, sampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return value, nil
+}
+
+// release drops one reference and evicts the entry when none are left
+func (r *Registry) release(key cacheKey, e *entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e.refs--
+	if e.refs == 0 && r.cache[key] == e {
+		delete(r.cache, key)
+	}
+}
+
+// Cached returns the number of decoded values currently held
+func (r *Registry) Cached() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.cache)
+}
+
+// Audio acquires a WAV or AIFF file resampled to sampleRate
+func (r *Registry) Audio(name string, sampleRate float64) (*audiofile.Audio, *Handle, error) {
+	h, err := r.Acquire(KindAudio, name, sampleRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h.Value().(*audiofile.Audio), h, nil
+}
+
+// Wavetable acquires a .wav or .wt wavetable
+func (r *Registry) Wavetable(name string) (*oscillator.Wavetable, *Handle, error) {
+	h, err := r.Acquire(KindWavetable, name, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h.Value().(*oscillator.Wavetable), h, nil
+}
+
+// Handle is a reference to a decoded resource
+type Handle struct {
+	registry *Registry
+	key      cacheKey
+	entry    *entry
+	once     sync.Once
+	released bool
+}
+
+// Name returns the resource name
+func (h *Handle) Name() string {
+	return h.key.name
+}
+
+// Value returns the decoded value, or nil after Release. The value is shared
+// between handles and must not be modified.
+func (h *Handle) Value() interface{} {
+	if h.released {
+		return nil
+	}
+	return h.entry.value
+}
+
+// Release drops the reference. Calling it more than once is safe.
+func (h *Handle) Release() {
+	h.once.Do(func() {
+		h.released = true
+		h.registry.release(h.key, h.entry)
+	})
+}
+
+// decodeRaw returns the contents unchanged
+func decodeRaw(_ string, data []byte, _ float64) (interface{}, error) {
+	return data, nil
+}
+
+// decodeAudio decodes a WAV or AIFF file and resamples it if needed
+func decodeAudio(_ string, data []byte, sampleRate float64) (interface{}, error) {
+	audio, err := audiofile.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if sampleRate > 0 && sampleRate != audio.SampleRate {
+		audio = audio.Resample(sampleRate)
+	}
+	return audio, nil
+}
+
+// decodeWavetable decodes a wavetable by extension
+func decodeWavetable(name string, data []byte, _ float64) (interface{}, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".wt":
+		return oscillator.LoadWavetableWT(bytes.NewReader(data))
+	case ".wav", ".wave":
+		return oscillator.LoadWavetableWAV(bytes.NewReader(data), 0)
+	default:
+		return nil, fmt.Errorf("%w: %s", oscillator.ErrUnsupportedFormat, path.Ext(name))
+	}
+}
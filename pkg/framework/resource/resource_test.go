@@ -0,0 +1,227 @@
+package resource
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/justyntemme/vst3go/pkg/audiofile"
+)
+
+// wavBytes returns a mono 16-bit WAV of frames samples
+func wavBytes(t *testing.T, frames int, sampleRate float64) []byte {
+	t.Helper()
+	audio := audiofile.NewAudio(1, frames, sampleRate)
+	for i := range audio.Data[0] {
+		audio.Data[0][i] = 0.5
+	}
+	path := filepath.Join(t.TempDir(), "ir.wav")
+	if err := audiofile.Save(path, audio, audiofile.PCM16); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestAcquireReferenceCounting(t *testing.T) {
+	r := NewRegistry()
+	var decodes atomic.Int32
+	r.RegisterDecoder("count", Decoder{Decode: func(_ string, data []byte, _ float64) (interface{}, error) {
+		decodes.Add(1)
+		return string(data), nil
+	}})
+	r.AddFS(fstest.MapFS{"a.txt": {Data: []byte("hello")}})
+
+	if r.Cached() != 0 || decodes.Load() != 0 {
+		t.Fatal("resources should be decoded lazily")
+	}
+
+	h1, err := r.Acquire("count", "a.txt", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, err := r.Acquire("count", "a.txt", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decodes.Load() != 1 {
+		t.Errorf("expected one decode, got %d", decodes.Load())
+	}
+	if h1.Value() != "hello" || h2.Value() != "hello" {
+		t.Errorf("unexpected values %v, %v", h1.Value(), h2.Value())
+	}
+
+	h1.Release()
+	h1.Release() // Double release must not drop h2's reference
+	if r.Cached() != 1 {
+		t.Fatalf("value dropped while still referenced")
+	}
+	if h1.Value() != nil {
+		t.Error("released handle should return nil")
+	}
+
+	h2.Release()
+	if r.Cached() != 0 {
+		t.Fatalf("value kept after last release")
+	}
+
+	h3, err := r.Acquire("count", "a.txt", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h3.Release()
+	if decodes.Load() != 2 {
+		t.Errorf("expected a fresh decode after eviction, got %d decodes", decodes.Load())
+	}
+}
+
+func TestAcquireCleansNames(t *testing.T) {
+	r := NewRegistry()
+	r.AddFS(fstest.MapFS{"irs/a.wav": {Data: []byte("ir")}})
+
+	var handles []*Handle
+	for _, name := range []string{"irs/a.wav", "/irs/a.wav", "./irs/a.wav", "irs//a.wav"} {
+		h, err := r.Acquire(KindRaw, name, 0)
+		if err != nil {
+			t.Fatalf("%q: %v", name, err)
+		}
+		if h.Name() != "irs/a.wav" {
+			t.Errorf("%q: handle name %q", name, h.Name())
+		}
+		handles = append(handles, h)
+	}
+	if r.Cached() != 1 {
+		t.Errorf("expected one cached value for all spellings, got %d", r.Cached())
+	}
+	for _, h := range handles {
+		h.Release()
+	}
+	if r.Cached() != 0 {
+		t.Error("value kept after last release")
+	}
+}
+
+func TestAudioPerSampleRate(t *testing.T) {
+	r := NewRegistry()
+	r.AddFS(fstest.MapFS{"irs/room.wav": {Data: wavBytes(t, 4800, 48000)}})
+
+	native, h48, err := r.Audio("irs/room.wav", 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h48.Release()
+	if native.Len() != 4800 || native.SampleRate != 48000 {
+		t.Errorf("native: got %d frames at %g Hz", native.Len(), native.SampleRate)
+	}
+
+	half, h24, err := r.Audio("irs/room.wav", 24000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h24.Release()
+	if half.SampleRate != 24000 || half.Len() < 2390 || half.Len() > 2410 {
+		t.Errorf("resampled: got %d frames at %g Hz", half.Len(), half.SampleRate)
+	}
+
+	if r.Cached() != 2 {
+		t.Errorf("expected one cache entry per sample rate, got %d", r.Cached())
+	}
+
+	// Rate-independent kinds share one entry
+	raw1, _ := r.Acquire(KindRaw, "irs/room.wav", 44100)
+	raw2, _ := r.Acquire(KindRaw, "irs/room.wav", 96000)
+	defer raw1.Release()
+	defer raw2.Release()
+	if r.Cached() != 3 {
+		t.Errorf("raw resources should ignore the sample rate, got %d entries", r.Cached())
+	}
+}
+
+func TestSourcePrecedence(t *testing.T) {
+	r := NewRegistry()
+	r.AddFS(fstest.MapFS{
+		"tunings/a.scl": {Data: []byte("embedded")},
+		"tunings/b.scl": {Data: []byte("embedded")},
+	})
+	r.AddFS(fstest.MapFS{"tunings/a.scl": {Data: []byte("user")}})
+
+	data, err := r.ReadFile("tunings/a.scl")
+	if err != nil || string(data) != "user" {
+		t.Errorf("later source should override: got %q, %v", data, err)
+	}
+	data, err = r.ReadFile("/tunings/b.scl")
+	if err != nil || string(data) != "embedded" {
+		t.Errorf("fallback to earlier source: got %q, %v", data, err)
+	}
+
+	names := r.Names("tunings")
+	if len(names) != 2 || names[0] != "tunings/a.scl" || names[1] != "tunings/b.scl" {
+		t.Errorf("unexpected names %v", names)
+	}
+}
+
+func TestAcquireErrors(t *testing.T) {
+	r := NewRegistry()
+	r.AddFS(fstest.MapFS{"bad.wav": {Data: []byte("not a wav file")}})
+
+	if _, err := r.Acquire(KindAudio, "missing.wav", 48000); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := r.Acquire("nope", "bad.wav", 0); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("expected ErrUnknownKind, got %v", err)
+	}
+	if _, _, err := r.Audio("bad.wav", 48000); !errors.Is(err, audiofile.ErrUnsupportedFormat) {
+		t.Errorf("expected decode error, got %v", err)
+	}
+	if r.Cached() != 0 {
+		t.Errorf("failed loads should not stay cached, got %d", r.Cached())
+	}
+}
+
+func TestConcurrentAcquire(t *testing.T) {
+	r := NewRegistry()
+	var decodes atomic.Int32
+	r.RegisterDecoder("slow", Decoder{Decode: func(_ string, data []byte, _ float64) (interface{}, error) {
+		decodes.Add(1)
+		return len(data), nil
+	}})
+	r.AddFS(fstest.MapFS{"x": {Data: []byte("12345")}})
+
+	var wg sync.WaitGroup
+	handles := make([]*Handle, 16)
+	for i := range handles {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h, err := r.Acquire("slow", "x", 0)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if h.Value() != 5 {
+				t.Errorf("unexpected value %v", h.Value())
+			}
+			handles[i] = h
+		}(i)
+	}
+	wg.Wait()
+
+	if decodes.Load() != 1 {
+		t.Errorf("expected one decode, got %d", decodes.Load())
+	}
+	for _, h := range handles {
+		if h != nil {
+			h.Release()
+		}
+	}
+	if r.Cached() != 0 {
+		t.Errorf("expected empty cache, got %d", r.Cached())
+	}
+}
@@ -0,0 +1,288 @@
+package sequencer
+
+import (
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+// MaxHeldNotes is the most notes an arpeggiator or step sequencer holds
+const MaxHeldNotes = 32
+
+// MaxOctaves is the widest octave range of an arpeggio
+const MaxOctaves = 4
+
+// Pattern is the order an arpeggiator plays the held notes in
+type Pattern int
+
+const (
+	// PatternUp plays from the lowest note up
+	PatternUp Pattern = iota
+	// PatternDown plays from the highest note down
+	PatternDown
+	// PatternUpDown plays up then down without repeating the ends
+	PatternUpDown
+	// PatternRandom plays a random held note each step
+	PatternRandom
+	// PatternAsPlayed plays the notes in the order they were pressed
+	PatternAsPlayed
+)
+
+// String returns the pattern name
+func (p Pattern) String() string {
+	switch p {
+	case PatternUp:
+		return "Up"
+	case PatternDown:
+		return "Down"
+	case PatternUpDown:
+		return "Up/Down"
+	case PatternRandom:
+		return "Random"
+	case PatternAsPlayed:
+		return "As Played"
+	default:
+		return "Unknown"
+	}
+}
+
+// PatternOptions returns choices for a pattern parameter, in Pattern order
+func PatternOptions() []param.ChoiceOption {
+	options := make([]param.ChoiceOption, 0, int(PatternAsPlayed)+1)
+	for p := PatternUp; p <= PatternAsPlayed; p++ {
+		options = append(options, param.ChoiceOption{Value: float64(p), Name: p.String()})
+	}
+	return options
+}
+
+// heldNote is a key held down, or latched
+type heldNote struct {
+	note     uint8
+	velocity uint8
+}
+
+// Arpeggiator plays the held notes one at a time in a pattern, spread over
+// one or more octaves. Call Process once per block; it reads the block's
+// input notes and sends the arpeggio as output events.
+type Arpeggiator struct {
+	engine
+
+	pattern  Pattern
+	octaves  int
+	velocity uint8 // 0 plays the velocity of each key
+	latch    bool
+
+	played  [MaxHeldNotes]heldNote // In the order pressed
+	sorted  [MaxHeldNotes]heldNote // By pitch
+	count   int
+	down    [128]bool // Keys physically held
+	pressed int
+
+	index int    // Steps since the pattern started
+	rng   uint32 // xorshift state for PatternRandom
+}
+
+// NewArpeggiator creates an arpeggiator playing up one octave in 16th
+// notes with a half-step gate
+func NewArpeggiator(sampleRate float64) *Arpeggiator {
+	return &Arpeggiator{
+		engine:  newEngine(sampleRate),
+		octaves: 1,
+		rng:     0x9E3779B9,
+	}
+}
+
+// SetPattern sets the note order
+func (a *Arpeggiator) SetPattern(pattern Pattern) {
+	if pattern < PatternUp || pattern > PatternAsPlayed {
+		pattern = PatternUp
+	}
+	a.pattern = pattern
+}
+
+// GetPattern returns the note order
+func (a *Arpeggiator) GetPattern() Pattern {
+	return a.pattern
+}
+
+// SetOctaves sets how many octaves the arpeggio climbs (1-4)
+func (a *Arpeggiator) SetOctaves(octaves int) {
+	a.octaves = max(1, min(MaxOctaves, octaves))
+}
+
+// GetOctaves returns the octave range
+func (a *Arpeggiator) GetOctaves() int {
+	return a.octaves
+}
+
+// SetVelocity plays every note at a fixed velocity (1-127), or at the
+// velocity of its key when 0
+func (a *Arpeggiator) SetVelocity(velocity uint8) {
+	a.velocity = min(velocity, 127)
+}
+
+// SetLatch sets whether notes keep playing after the keys are released,
+// until a new chord is pressed. Turning latch off drops released notes.
+func (a *Arpeggiator) SetLatch(latch bool) {
+	a.latch = latch
+	if latch {
+		return
+	}
+	for i := 0; i < a.count; {
+		if a.down[a.played[i].note] {
+			i++
+			continue
+		}
+		a.remove(a.played[i].note)
+	}
+}
+
+// SetSeed sets the random pattern's seed so it can be repeated
+func (a *Arpeggiator) SetSeed(seed uint32) {
+	if seed == 0 {
+		seed = 0x9E3779B9
+	}
+	a.rng = seed
+}
+
+// HeldNotes returns the number of notes in the arpeggio
+func (a *Arpeggiator) HeldNotes() int {
+	return a.count
+}
+
+// Process reads the block's input notes and sends the arpeggio
+func (a *Arpeggiator) Process(ctx *process.Context) {
+	a.engine.process(ctx, a)
+}
+
+// Stop ends the sounding note and forgets the held notes, e.g. on a panic
+// or when the arpeggiator is switched off
+func (a *Arpeggiator) Stop(ctx *process.Context) {
+	a.engine.stop(ctx, 0)
+	a.clear()
+}
+
+// Reset forgets all notes without sending note offs, for when the host
+// resets processing
+func (a *Arpeggiator) Reset() {
+	a.engine.reset()
+	a.clear()
+}
+
+// clear drops every held note
+func (a *Arpeggiator) clear() {
+	a.count = 0
+	a.pressed = 0
+	a.down = [128]bool{}
+	a.index = 0
+}
+
+func (a *Arpeggiator) noteOn(note, velocity uint8) {
+	if a.latch && a.pressed == 0 {
+		// A new chord replaces the latched one
+		a.count = 0
+	}
+	if !a.down[note] {
+		a.down[note] = true
+		a.pressed++
+	}
+
+	for i := 0; i < a.count; i++ {
+		if a.played[i].note == note {
+			a.played[i].velocity = velocity
+			a.sort()
+			return
+		}
+	}
+	if a.count < MaxHeldNotes {
+		a.played[a.count] = heldNote{note: note, velocity: velocity}
+		a.count++
+		a.sort()
+	}
+}
+
+func (a *Arpeggiator) noteOff(note uint8) {
+	if !a.down[note] {
+		return
+	}
+	a.down[note] = false
+	a.pressed--
+	if !a.latch {
+		a.remove(note)
+	}
+}
+
+func (a *Arpeggiator) held() bool {
+	return a.count > 0
+}
+
+func (a *Arpeggiator) restart() {
+	a.index = 0
+}
+
+func (a *Arpeggiator) next() (note, velocity uint8, gate float64, ok bool) {
+	if a.count == 0 {
+		return 0, 0, 0, false
+	}
+
+	length := a.count * a.octaves
+	step := a.index
+	a.index++
+
+	notes := &a.sorted
+	var i int
+	switch a.pattern {
+	case PatternDown:
+		i = length - 1 - step%length
+	case PatternUpDown:
+		if length > 1 {
+			period := 2*length - 2
+			i = step % period
+			if i >= length {
+				i = period - i
+			}
+		}
+	case PatternRandom:
+		a.rng ^= a.rng << 13
+		a.rng ^= a.rng >> 17
+		a.rng ^= a.rng << 5
+		i = int(a.rng % uint32(length))
+	case PatternAsPlayed:
+		notes = &a.played
+		i = step % length
+	default:
+		i = step % length
+	}
+
+	held := notes[i%a.count]
+	pitch := int(held.note) + 12*(i/a.count)
+	for pitch > 127 {
+		pitch -= 12
+	}
+	velocity = a.velocity
+	if velocity == 0 {
+		velocity = held.velocity
+	}
+	return uint8(pitch), velocity, 0, true
+}
+
+// remove drops a note from the arpeggio
+func (a *Arpeggiator) remove(note uint8) {
+	for i := 0; i < a.count; i++ {
+		if a.played[i].note == note {
+			copy(a.played[i:a.count], a.played[i+1:a.count])
+			a.count--
+			a.sort()
+			return
+		}
+	}
+}
+
+// sort refreshes the pitch-ordered copy of the held notes
+func (a *Arpeggiator) sort() {
+	copy(a.sorted[:a.count], a.played[:a.count])
+	for i := 1; i < a.count; i++ {
+		for j := i; j > 0 && a.sorted[j].note < a.sorted[j-1].note; j-- {
+			a.sorted[j], a.sorted[j-1] = a.sorted[j-1], a.sorted[j]
+		}
+	}
+}
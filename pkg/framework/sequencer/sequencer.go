@@ -0,0 +1,348 @@
+// Package sequencer generates notes in time with the host. An Arpeggiator
+// and a StepSequencer consume the notes a plugin receives and send their own
+// note events, placed sample-accurately on the host's beat grid, through the
+// process context. The plugin needs an event output bus for the host to
+// route them.
+package sequencer
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/midi"
+)
+
+// DefaultTempo is used while the host reports no tempo
+const DefaultTempo = 120.0
+
+// maxSwing is how far swing at 100% delays the off-beat steps, as a
+// fraction of a step: a third gives a triplet feel
+const maxSwing = 1.0 / 3
+
+// Rate is a step length as a note division
+type Rate int
+
+const (
+	// RateQuarter plays quarter notes
+	RateQuarter Rate = iota
+	// RateEighth plays eighth notes
+	RateEighth
+	// RateEighthTriplet plays eighth-note triplets
+	RateEighthTriplet
+	// RateSixteenth plays sixteenth notes, the default
+	RateSixteenth
+	// RateSixteenthTriplet plays sixteenth-note triplets
+	RateSixteenthTriplet
+	// RateThirtySecond plays thirty-second notes
+	RateThirtySecond
+)
+
+// Beats returns the length of a step in quarter notes
+func (r Rate) Beats() float64 {
+	switch r {
+	case RateQuarter:
+		return 1
+	case RateEighth:
+		return 0.5
+	case RateEighthTriplet:
+		return 1.0 / 3
+	case RateSixteenthTriplet:
+		return 1.0 / 6
+	case RateThirtySecond:
+		return 0.125
+	default:
+		return 0.25
+	}
+}
+
+// String returns the division name
+func (r Rate) String() string {
+	switch r {
+	case RateQuarter:
+		return "1/4"
+	case RateEighth:
+		return "1/8"
+	case RateEighthTriplet:
+		return "1/8T"
+	case RateSixteenth:
+		return "1/16"
+	case RateSixteenthTriplet:
+		return "1/16T"
+	case RateThirtySecond:
+		return "1/32"
+	default:
+		return "Unknown"
+	}
+}
+
+// RateOptions returns choices for a rate parameter, in Rate order
+func RateOptions() []param.ChoiceOption {
+	options := make([]param.ChoiceOption, 0, int(RateThirtySecond)+1)
+	for r := RateQuarter; r <= RateThirtySecond; r++ {
+		options = append(options, param.ChoiceOption{Value: float64(r), Name: r.String()})
+	}
+	return options
+}
+
+// player chooses the notes an engine plays
+type player interface {
+	noteOn(note, velocity uint8)
+	noteOff(note uint8)
+	// held reports whether there are notes to play
+	held() bool
+	// next returns the note for the next step; ok is false for a rest.
+	// A gate of 0 uses the engine's gate.
+	next() (note, velocity uint8, gate float64, ok bool)
+	// restart returns to the start of the pattern
+	restart()
+}
+
+// engine runs the step clock shared by the arpeggiator and step sequencer.
+// While the host is playing, steps follow its song position; otherwise the
+// clock runs free from the first key press.
+type engine struct {
+	sampleRate float64
+	tempo      float64 // Used when the host reports none
+	stepBeats  float64
+	swing      float64 // 0-1
+	gate       float64 // Fraction of a step, 0-1
+	channel    uint8
+	thru       bool
+
+	position float64 // Beats at the start of the block
+	running  bool    // Keys are held and steps are playing
+	note     int     // Sounding note, -1 for none
+	offAt    int     // Samples from the block start to the note off
+
+	events []midi.Event // Reused each block
+}
+
+func newEngine(sampleRate float64) engine {
+	return engine{
+		sampleRate: sampleRate,
+		tempo:      DefaultTempo,
+		stepBeats:  RateSixteenth.Beats(),
+		gate:       0.5,
+		thru:       true,
+		note:       -1,
+		events:     make([]midi.Event, 0, 128),
+	}
+}
+
+// SetSampleRate sets the sample rate
+func (e *engine) SetSampleRate(sampleRate float64) {
+	e.sampleRate = sampleRate
+}
+
+// SetTempo sets the tempo in BPM used while the host reports none
+func (e *engine) SetTempo(bpm float64) {
+	if bpm > 0 && !math.IsInf(bpm, 0) {
+		e.tempo = bpm
+	}
+}
+
+// SetRate sets the step length to a note division
+func (e *engine) SetRate(rate Rate) {
+	e.stepBeats = rate.Beats()
+}
+
+// SetStepLength sets the length of a step in beats, e.g. 0.25 for 16th
+// notes
+func (e *engine) SetStepLength(beats float64) {
+	if beats > 0 {
+		e.stepBeats = beats
+	}
+}
+
+// GetStepLength returns the length of a step in beats
+func (e *engine) GetStepLength() float64 {
+	return e.stepBeats
+}
+
+// SetSwing delays every second step (0-1); full swing moves it a third of
+// a step later
+func (e *engine) SetSwing(swing float64) {
+	e.swing = math.Max(0, math.Min(1, swing))
+}
+
+// SetGate sets how long notes sound, as a fraction of their step (0-1).
+// At 1 notes run into the next step.
+func (e *engine) SetGate(gate float64) {
+	e.gate = math.Max(0.01, math.Min(1, gate))
+}
+
+// SetChannel sets the MIDI channel (0-15) notes are sent on
+func (e *engine) SetChannel(channel uint8) {
+	e.channel = channel & 0x0F
+}
+
+// SetThru sets whether incoming events other than notes, such as
+// controllers and pitch bend, are passed to the output. It is on by
+// default.
+func (e *engine) SetThru(thru bool) {
+	e.thru = thru
+}
+
+// IsRunning reports whether steps are playing
+func (e *engine) IsRunning() bool {
+	return e.running
+}
+
+// stepStart returns the position of step k in beats. Steps come in pairs;
+// swing moves the second step of each pair later.
+func (e *engine) stepStart(k int64) float64 {
+	start := float64(k) * e.stepBeats
+	if k&1 != 0 {
+		start += e.swing * maxSwing * e.stepBeats
+	}
+	return start
+}
+
+// firstStep returns the first step that starts at or after a position. A
+// step half a sample early still counts so rounding never plays a step
+// twice or skips one across blocks.
+func (e *engine) firstStep(position, samplesPerBeat float64) int64 {
+	limit := position - 0.5/samplesPerBeat
+	k := int64(math.Floor(limit/e.stepBeats)) - 1
+	for e.stepStart(k) < limit {
+		k++
+	}
+	return k
+}
+
+// process handles a block: it reads the input notes, plays the steps that
+// fall in the block and ends notes when their gate runs out
+func (e *engine) process(ctx *process.Context, p player) {
+	n := ctx.NumSamples()
+	if n == 0 {
+		return
+	}
+
+	tempo := e.tempo
+	t := ctx.Transport
+	if t != nil && t.HasTempo && t.Tempo > 0 {
+		tempo = t.Tempo
+	}
+	samplesPerBeat := 60 / tempo * e.sampleRate
+	synced := t != nil && t.IsPlaying && t.HasMusicalTime
+	if synced {
+		e.position = t.ProjectTimeMusic + float64(ctx.BlockOffset())/samplesPerBeat
+	}
+
+	// Notes can disappear between blocks, e.g. when latch is turned off
+	if e.running && !p.held() {
+		e.stop(ctx, 0)
+	}
+
+	e.events = ctx.AppendInputEvents(e.events[:0])
+	k := e.firstStep(e.position, samplesPerBeat)
+	i := 0
+	for {
+		at := n
+		if e.running {
+			if offset := int(math.Round((e.stepStart(k) - e.position) * samplesPerBeat)); offset < n {
+				at = max(offset, 0)
+			}
+		}
+
+		// Input at a step's offset comes first so a chord on the beat plays
+		if i < len(e.events) && int(e.events[i].SampleOffset()) <= at {
+			event := e.events[i]
+			i++
+			offset := max(0, min(n-1, int(event.SampleOffset())))
+			e.endNote(ctx, offset)
+			if e.input(ctx, p, event, offset) && !synced {
+				// Free-running clocks start on the key press
+				e.position = -float64(offset) / samplesPerBeat
+			}
+			if e.running {
+				k = e.firstStep(e.position+float64(offset)/samplesPerBeat, samplesPerBeat)
+			}
+			continue
+		}
+		if at >= n {
+			break
+		}
+
+		e.endNote(ctx, at)
+		e.playStep(ctx, p, k, at, samplesPerBeat)
+		k++
+	}
+
+	e.endNote(ctx, n-1)
+	if e.note >= 0 {
+		e.offAt -= n
+	}
+	e.position += float64(n) / samplesPerBeat
+}
+
+// input applies an incoming event and reports whether it started the steps
+func (e *engine) input(ctx *process.Context, p player, event midi.Event, offset int) bool {
+	switch ev := event.(type) {
+	case midi.NoteOnEvent:
+		if ev.Velocity > 0 {
+			p.noteOn(ev.NoteNumber, ev.Velocity)
+			if !e.running {
+				e.running = true
+				p.restart()
+				return true
+			}
+			return false
+		}
+		p.noteOff(ev.NoteNumber)
+	case midi.NoteOffEvent:
+		p.noteOff(ev.NoteNumber)
+	default:
+		if e.thru {
+			ctx.AddOutputEvent(event)
+		}
+		return false
+	}
+	if e.running && !p.held() {
+		e.stop(ctx, offset)
+	}
+	return false
+}
+
+// playStep sends the note for step k at a sample offset
+func (e *engine) playStep(ctx *process.Context, p player, k int64, offset int, samplesPerBeat float64) {
+	note, velocity, gate, ok := p.next()
+	if !ok {
+		return
+	}
+	if gate <= 0 {
+		gate = e.gate
+	}
+	if e.note >= 0 {
+		ctx.SendNoteOff(e.channel, uint8(e.note), 0, offset)
+	}
+	ctx.SendNoteOn(e.channel, note, velocity, offset)
+	length := (e.stepStart(k+1) - e.stepStart(k)) * samplesPerBeat * math.Min(gate, 1)
+	e.note = int(note)
+	e.offAt = offset + max(1, int(length))
+}
+
+// endNote sends the note off if its gate ends by offset
+func (e *engine) endNote(ctx *process.Context, offset int) {
+	if e.note >= 0 && e.offAt <= offset {
+		ctx.SendNoteOff(e.channel, uint8(e.note), 0, max(e.offAt, 0))
+		e.note = -1
+	}
+}
+
+// stop ends the sounding note at offset and stops the steps
+func (e *engine) stop(ctx *process.Context, offset int) {
+	if e.note >= 0 {
+		ctx.SendNoteOff(e.channel, uint8(e.note), 0, offset)
+		e.note = -1
+	}
+	e.running = false
+}
+
+// reset forgets the sounding note and stops without sending anything
+func (e *engine) reset() {
+	e.position = 0
+	e.running = false
+	e.note = -1
+}
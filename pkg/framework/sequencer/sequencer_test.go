@@ -0,0 +1,310 @@
+package sequencer
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/midi"
+)
+
+// At 1 kHz and 60 BPM a beat is 1000 samples and a 16th note 250
+const (
+	testRate  = 1000
+	testBlock = 100
+)
+
+// sent is an output event at an absolute sample position
+type sent struct {
+	at   int
+	kind string
+	note uint8
+}
+
+func (s sent) String() string {
+	return fmt.Sprintf("%s %d@%d", s.kind, s.note, s.at)
+}
+
+// harness runs a sequencer block by block, feeding input at absolute
+// positions and collecting the output
+type harness struct {
+	ctx    *process.Context
+	input  map[int][]midi.Event // By block
+	clock  int
+	output []sent
+	other  []midi.Event
+}
+
+func newHarness() *harness {
+	ctx := process.NewContext(testBlock, nil)
+	ctx.Output = [][]float32{make([]float32, testBlock)}
+	ctx.Transport.Tempo = 60
+	ctx.Transport.HasTempo = true
+	return &harness{ctx: ctx, input: map[int][]midi.Event{}}
+}
+
+func (h *harness) noteOn(at int, note uint8) {
+	h.add(at, midi.NoteOnEvent{BaseEvent: midi.BaseEvent{Offset: int32(at % testBlock)}, NoteNumber: note, Velocity: 100})
+}
+
+func (h *harness) noteOff(at int, note uint8) {
+	h.add(at, midi.NoteOffEvent{BaseEvent: midi.BaseEvent{Offset: int32(at % testBlock)}, NoteNumber: note})
+}
+
+func (h *harness) add(at int, event midi.Event) {
+	h.input[at/testBlock] = append(h.input[at/testBlock], event)
+}
+
+// run processes blocks until the absolute position reaches end
+func (h *harness) run(process func(*process.Context), end int) {
+	for ; h.clock < end; h.clock += testBlock {
+		h.ctx.ClearAllEvents()
+		for _, event := range h.input[h.clock/testBlock] {
+			h.ctx.AddInputEvent(event)
+		}
+		process(h.ctx)
+		for _, event := range h.ctx.AppendOutputEvents(nil) {
+			at := h.clock + int(event.SampleOffset())
+			switch e := event.(type) {
+			case midi.NoteOnEvent:
+				h.output = append(h.output, sent{at, "on", e.NoteNumber})
+			case midi.NoteOffEvent:
+				h.output = append(h.output, sent{at, "off", e.NoteNumber})
+			default:
+				h.other = append(h.other, event)
+			}
+		}
+		if h.ctx.Transport.IsPlaying {
+			h.ctx.Transport.ProjectTimeMusic += float64(testBlock) / testRate
+		}
+	}
+}
+
+// ons returns the note ons sent
+func (h *harness) ons() []sent {
+	var result []sent
+	for _, s := range h.output {
+		if s.kind == "on" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func TestArpeggiatorFreeRunning(t *testing.T) {
+	h := newHarness()
+	arp := NewArpeggiator(testRate)
+	h.noteOn(10, 64)
+	h.noteOn(10, 60)
+	h.noteOn(10, 67)
+	h.noteOff(800, 60)
+	h.noteOff(800, 64)
+	h.noteOff(800, 67)
+	h.run(arp.Process, 1200)
+
+	// Steps start at the key press, every 250 samples, with a half gate
+	want := []sent{
+		{10, "on", 60}, {135, "off", 60},
+		{260, "on", 64}, {385, "off", 64},
+		{510, "on", 67}, {635, "off", 67},
+		{760, "on", 60}, {800, "off", 60},
+	}
+	if !reflect.DeepEqual(h.output, want) {
+		t.Errorf("output %v\nwant %v", h.output, want)
+	}
+	if arp.IsRunning() || arp.HeldNotes() != 0 {
+		t.Error("arpeggiator still running after every key was released")
+	}
+}
+
+func TestArpeggiatorHostSync(t *testing.T) {
+	h := newHarness()
+	h.ctx.Transport.IsPlaying = true
+	h.ctx.Transport.HasMusicalTime = true
+	h.ctx.Transport.ProjectTimeMusic = 0.1 // Song position at sample 0
+
+	arp := NewArpeggiator(testRate)
+	arp.SetGate(1)
+	h.noteOn(20, 60)
+	h.run(arp.Process, 700)
+
+	// Steps wait for the 16th-note grid: beats 0.25 and 0.5 fall at 150 and 400
+	want := []sent{{150, "on", 60}, {400, "off", 60}, {400, "on", 60}, {650, "off", 60}, {650, "on", 60}}
+	if !reflect.DeepEqual(h.output, want) {
+		t.Errorf("output %v\nwant %v", h.output, want)
+	}
+}
+
+func TestArpeggiatorSwing(t *testing.T) {
+	h := newHarness()
+	arp := NewArpeggiator(testRate)
+	arp.SetSwing(1)
+	h.noteOn(0, 60)
+	h.run(arp.Process, 1000)
+
+	// Every second step moves a third of a step later
+	var got []int
+	for _, s := range h.ons() {
+		got = append(got, s.at)
+	}
+	if want := []int{0, 333, 500, 833}; !reflect.DeepEqual(got, want) {
+		t.Errorf("swung steps at %v, want %v", got, want)
+	}
+}
+
+func TestArpeggiatorPatterns(t *testing.T) {
+	for _, tc := range []struct {
+		pattern Pattern
+		octaves int
+		want    []uint8
+	}{
+		{PatternUp, 2, []uint8{60, 64, 67, 72, 76, 79, 60}},
+		{PatternDown, 1, []uint8{67, 64, 60, 67}},
+		{PatternUpDown, 1, []uint8{60, 64, 67, 64, 60, 64}},
+		{PatternAsPlayed, 1, []uint8{64, 60, 67, 64}},
+	} {
+		arp := NewArpeggiator(testRate)
+		arp.SetPattern(tc.pattern)
+		arp.SetOctaves(tc.octaves)
+		for _, note := range []uint8{64, 60, 67} {
+			arp.noteOn(note, 100)
+		}
+		var got []uint8
+		for range tc.want {
+			note, _, _, _ := arp.next()
+			got = append(got, note)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v over %d octaves: %v, want %v", tc.pattern, tc.octaves, got, tc.want)
+		}
+	}
+}
+
+func TestArpeggiatorRandom(t *testing.T) {
+	play := func(seed uint32) []uint8 {
+		arp := NewArpeggiator(testRate)
+		arp.SetPattern(PatternRandom)
+		arp.SetOctaves(2)
+		arp.SetSeed(seed)
+		arp.noteOn(60, 100)
+		arp.noteOn(64, 100)
+		var notes []uint8
+		for i := 0; i < 64; i++ {
+			note, _, _, _ := arp.next()
+			notes = append(notes, note)
+		}
+		return notes
+	}
+
+	notes := play(7)
+	seen := map[uint8]bool{}
+	for _, note := range notes {
+		seen[note] = true
+	}
+	for _, note := range []uint8{60, 64, 72, 76} {
+		if !seen[note] {
+			t.Errorf("random pattern never played %d: %v", note, notes)
+		}
+	}
+	if len(seen) != 4 {
+		t.Errorf("random pattern played notes outside the arpeggio: %v", notes)
+	}
+	if !reflect.DeepEqual(notes, play(7)) {
+		t.Error("random pattern differs with the same seed")
+	}
+}
+
+func TestArpeggiatorLatch(t *testing.T) {
+	h := newHarness()
+	arp := NewArpeggiator(testRate)
+	arp.SetLatch(true)
+	h.noteOn(0, 60)
+	h.noteOff(50, 60)
+	h.run(arp.Process, 600)
+	if len(h.ons()) != 3 {
+		t.Fatalf("latched arpeggio played %v", h.output)
+	}
+
+	// A new chord replaces the latched notes
+	h.noteOn(600, 62)
+	h.noteOn(600, 65)
+	h.noteOff(610, 62)
+	h.noteOff(610, 65)
+	h.run(arp.Process, 1100)
+	if arp.HeldNotes() != 2 {
+		t.Errorf("%d notes latched, want 2", arp.HeldNotes())
+	}
+	for _, s := range h.ons()[3:] {
+		if s.note != 62 && s.note != 65 {
+			t.Errorf("played %v from the old chord", s)
+		}
+	}
+
+	// Turning latch off releases them
+	arp.SetLatch(false)
+	h.run(arp.Process, 1200)
+	if arp.IsRunning() {
+		t.Error("still running after latch was turned off")
+	}
+	if last := h.output[len(h.output)-1]; last.kind != "off" {
+		t.Errorf("last event %v, want a note off", last)
+	}
+}
+
+func TestArpeggiatorThru(t *testing.T) {
+	h := newHarness()
+	arp := NewArpeggiator(testRate)
+	h.add(30, midi.ControlChangeEvent{BaseEvent: midi.BaseEvent{Offset: 30}, Controller: midi.CCModWheel, Value: 90})
+	h.run(arp.Process, testBlock)
+	if len(h.other) != 1 || h.other[0].SampleOffset() != 30 {
+		t.Errorf("passed through %v, want the mod wheel at 30", h.other)
+	}
+
+	arp.SetThru(false)
+	h.other = nil
+	h.add(130, midi.ControlChangeEvent{BaseEvent: midi.BaseEvent{Offset: 30}, Controller: midi.CCModWheel, Value: 90})
+	h.run(arp.Process, 2*testBlock)
+	if len(h.other) != 0 {
+		t.Errorf("passed through %v with thru off", h.other)
+	}
+}
+
+func TestArpeggiatorStop(t *testing.T) {
+	h := newHarness()
+	arp := NewArpeggiator(testRate)
+	arp.SetGate(1)
+	h.noteOn(0, 60)
+	h.run(arp.Process, testBlock)
+
+	h.ctx.ClearOutputEvents()
+	arp.Stop(h.ctx)
+	events := h.ctx.AppendOutputEvents(nil)
+	if len(events) != 1 {
+		t.Fatalf("Stop sent %v, want one note off", events)
+	}
+	if _, ok := events[0].(midi.NoteOffEvent); !ok {
+		t.Errorf("Stop sent %v, want a note off", events)
+	}
+	if arp.IsRunning() || arp.HeldNotes() != 0 {
+		t.Error("arpeggiator still running after Stop")
+	}
+}
+
+func TestFirstStep(t *testing.T) {
+	e := newEngine(testRate)
+	e.SetSwing(0.5)
+	for _, position := range []float64{-1.3, -0.25, 0, 0.1, 0.25, 0.3, 1.99} {
+		k := e.firstStep(position, testRate)
+		if e.stepStart(k) < position-0.5/testRate || e.stepStart(k-1) >= position-0.5/testRate {
+			t.Errorf("firstStep(%g) = %d starting at %g", position, k, e.stepStart(k))
+		}
+	}
+	if got := RateEighthTriplet.Beats() * 3; math.Abs(got-1) > 1e-12 {
+		t.Errorf("three eighth-note triplets last %g beats", got)
+	}
+	if options := RateOptions(); len(options) != 6 || options[RateSixteenth].Name != "1/16" {
+		t.Errorf("rate options %v", options)
+	}
+}
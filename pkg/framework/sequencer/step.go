@@ -0,0 +1,139 @@
+package sequencer
+
+import "github.com/justyntemme/vst3go/pkg/framework/process"
+
+// MaxSteps is the longest step sequence
+const MaxSteps = 32
+
+// Step is one step of a sequence. The zero value plays the held key at its
+// own velocity with the sequencer's gate.
+type Step struct {
+	Transpose int     // Semitones from the held key
+	Velocity  uint8   // 1-127, or 0 for the key's velocity
+	Gate      float64 // Fraction of the step, or 0 for the sequencer's gate
+	Rest      bool    // Plays nothing
+}
+
+// StepSequencer plays a sequence of steps transposed to the most recent held
+// key, like a monophonic hardware sequencer. Call Process once per block; it
+// reads the block's input notes and sends the sequence as output events.
+type StepSequencer struct {
+	engine
+
+	steps  [MaxSteps]Step
+	length int
+	index  int // Steps since the sequence started
+
+	keys  [MaxHeldNotes]heldNote // Most recent last
+	count int
+}
+
+// NewStepSequencer creates a 16-step sequencer of 16th notes, every step
+// playing the held key
+func NewStepSequencer(sampleRate float64) *StepSequencer {
+	return &StepSequencer{
+		engine: newEngine(sampleRate),
+		length: 16,
+	}
+}
+
+// SetLength sets the sequence length in steps (1-32)
+func (s *StepSequencer) SetLength(steps int) {
+	s.length = max(1, min(MaxSteps, steps))
+}
+
+// GetLength returns the sequence length in steps
+func (s *StepSequencer) GetLength() int {
+	return s.length
+}
+
+// SetStep sets one step
+func (s *StepSequencer) SetStep(index int, step Step) {
+	if index >= 0 && index < MaxSteps {
+		s.steps[index] = step
+	}
+}
+
+// GetStep returns one step
+func (s *StepSequencer) GetStep(index int) Step {
+	if index < 0 || index >= MaxSteps {
+		return Step{}
+	}
+	return s.steps[index]
+}
+
+// SetSteps sets the steps from the start of the sequence and the length to
+// match
+func (s *StepSequencer) SetSteps(steps []Step) {
+	copy(s.steps[:], steps)
+	s.SetLength(len(steps))
+}
+
+// CurrentStep returns the step that plays next
+func (s *StepSequencer) CurrentStep() int {
+	return s.index % s.length
+}
+
+// Process reads the block's input notes and sends the sequence
+func (s *StepSequencer) Process(ctx *process.Context) {
+	s.engine.process(ctx, s)
+}
+
+// Stop ends the sounding note and forgets the held keys
+func (s *StepSequencer) Stop(ctx *process.Context) {
+	s.engine.stop(ctx, 0)
+	s.count = 0
+	s.index = 0
+}
+
+// Reset forgets all notes without sending note offs, for when the host
+// resets processing
+func (s *StepSequencer) Reset() {
+	s.engine.reset()
+	s.count = 0
+	s.index = 0
+}
+
+func (s *StepSequencer) noteOn(note, velocity uint8) {
+	s.noteOff(note)
+	if s.count == MaxHeldNotes {
+		copy(s.keys[:], s.keys[1:])
+		s.count--
+	}
+	s.keys[s.count] = heldNote{note: note, velocity: velocity}
+	s.count++
+}
+
+func (s *StepSequencer) noteOff(note uint8) {
+	for i := 0; i < s.count; i++ {
+		if s.keys[i].note == note {
+			copy(s.keys[i:s.count], s.keys[i+1:s.count])
+			s.count--
+			return
+		}
+	}
+}
+
+func (s *StepSequencer) held() bool {
+	return s.count > 0
+}
+
+func (s *StepSequencer) restart() {
+	s.index = 0
+}
+
+func (s *StepSequencer) next() (note, velocity uint8, gate float64, ok bool) {
+	step := s.steps[s.index%s.length]
+	s.index++
+	if step.Rest || s.count == 0 {
+		return 0, 0, 0, false
+	}
+
+	key := s.keys[s.count-1]
+	pitch := max(0, min(127, int(key.note)+step.Transpose))
+	velocity = step.Velocity
+	if velocity == 0 {
+		velocity = key.velocity
+	}
+	return uint8(pitch), min(velocity, 127), step.Gate, true
+}
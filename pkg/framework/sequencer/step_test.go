@@ -0,0 +1,59 @@
+package sequencer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStepSequencer(t *testing.T) {
+	h := newHarness()
+	seq := NewStepSequencer(testRate)
+	seq.SetSteps([]Step{
+		{},
+		{Transpose: 7, Velocity: 127},
+		{Rest: true},
+		{Transpose: 12, Gate: 1},
+	})
+
+	h.noteOn(0, 48)
+	h.noteOn(600, 50) // The newest key transposes the sequence
+	h.noteOff(1500, 50)
+	h.run(seq.Process, 1400)
+
+	want := []sent{
+		{0, "on", 48}, {125, "off", 48},
+		{250, "on", 55}, {375, "off", 55},
+		{750, "on", 62}, {1000, "off", 62},
+		{1000, "on", 50}, {1125, "off", 50},
+		{1250, "on", 57}, {1375, "off", 57},
+	}
+	if !reflect.DeepEqual(h.output, want) {
+		t.Errorf("output %v\nwant %v", h.output, want)
+	}
+	if seq.CurrentStep() != 2 {
+		t.Errorf("current step %d, want 2", seq.CurrentStep())
+	}
+
+	// Releasing the newest key falls back to the one still held; 1500 is
+	// the rest
+	h.run(seq.Process, 1800)
+	if last := h.ons()[len(h.ons())-1]; last.note != 48+12 || last.at != 1750 {
+		t.Errorf("last note %v, want 60 at 1750", last)
+	}
+}
+
+func TestStepSequencerVelocity(t *testing.T) {
+	seq := NewStepSequencer(testRate)
+	seq.SetStep(1, Step{Velocity: 20})
+	seq.noteOn(60, 90)
+	_, v0, _, _ := seq.next()
+	_, v1, _, _ := seq.next()
+	if v0 != 90 || v1 != 20 {
+		t.Errorf("velocities %d and %d, want the key's 90 then 20", v0, v1)
+	}
+
+	seq.noteOff(60)
+	if _, _, _, ok := seq.next(); ok {
+		t.Error("played a step with no key held")
+	}
+}
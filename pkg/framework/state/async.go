@@ -0,0 +1,262 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/justyntemme/vst3go/pkg/framework/worker"
+	"github.com/justyntemme/vst3go/pkg/rt"
+)
+
+// ErrLoadCanceled is returned by Wait when a load was canceled or superseded
+var ErrLoadCanceled = errors.New("state load canceled")
+
+// LoadStatus describes the progress of an asynchronous state load
+type LoadStatus int32
+
+const (
+	// LoadIdle means no load has been started
+	LoadIdle LoadStatus = iota
+	// LoadRunning means custom state is being prepared in the background
+	LoadRunning
+	// LoadDone means the last load was committed
+	LoadDone
+	// LoadFailed means the last load returned an error
+	LoadFailed
+	// LoadCanceled means the last load was canceled or superseded
+	LoadCanceled
+)
+
+// String returns the status name
+func (s LoadStatus) String() string {
+	switch s {
+	case LoadIdle:
+		return "idle"
+	case LoadRunning:
+		return "loading"
+	case LoadDone:
+		return "done"
+	case LoadFailed:
+		return "failed"
+	case LoadCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// PrepareFunc decodes custom state and builds heavy resources (samples, IRs)
+// without touching the live ones. It should return early when ctx is done and
+// may report progress from 0 to 1. The returned commit function swaps the
+// prepared resources in and must be quick, e.g. a few pointer assignments.
+type PrepareFunc func(ctx context.Context, data []byte, progress func(float64)) (commit func(), err error)
+
+// AsyncLoader restores custom state on a background goroutine so that
+// SetState returns immediately. A new load cancels the one in flight, and
+// results of a superseded load are never committed.
+type AsyncLoader struct {
+	prepare PrepareFunc
+
+	mu         sync.Mutex
+	pool       *worker.Pool
+	committer  func(commit func())
+	generation uint64
+	cancel     context.CancelFunc
+	pending    []byte
+	done       chan struct{}
+	err        error
+
+	status     atomic.Int32
+	progress   rt.Float64
+	onProgress atomic.Pointer[func(float64)]
+}
+
+// NewAsyncLoader creates a loader that prepares state with prepare
+func NewAsyncLoader(prepare PrepareFunc) *AsyncLoader {
+	return &AsyncLoader{prepare: prepare}
+}
+
+// SetPool runs loads on a worker pool instead of a dedicated goroutine
+func (l *AsyncLoader) SetPool(pool *worker.Pool) {
+	l.mu.Lock()
+	l.pool = pool
+	l.mu.Unlock()
+}
+
+// SetCommitter sets how commit functions are run. The plugin component uses
+// this to commit between process calls. By default commits run directly on
+// the loading goroutine. The committer must not call back into the loader.
+func (l *AsyncLoader) SetCommitter(committer func(commit func())) {
+	l.mu.Lock()
+	l.committer = committer
+	l.mu.Unlock()
+}
+
+// SetProgressCallback sets a function called with progress from 0 to 1, e.g.
+// to update a GUI. It is called from the loading goroutine.
+func (l *AsyncLoader) SetProgressCallback(fn func(float64)) {
+	if fn == nil {
+		l.onProgress.Store(nil)
+		return
+	}
+	l.onProgress.Store(&fn)
+}
+
+// Load starts restoring data in the background, canceling any load in flight.
+// The data is kept until the load commits so that PendingData can return it.
+func (l *AsyncLoader) Load(data []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cancel != nil {
+		l.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.generation++
+	gen := l.generation
+	done := make(chan struct{})
+
+	l.cancel = cancel
+	l.pending = data
+	l.done = done
+	l.err = nil
+	l.status.Store(int32(LoadRunning))
+	l.setProgress(0)
+
+	job := func(poolCtx context.Context) {
+		// Also stop if the pool shuts down
+		stop := context.AfterFunc(poolCtx, cancel)
+		defer stop()
+		l.run(ctx, gen, data, done)
+	}
+
+	if l.pool == nil {
+		go job(context.Background())
+		return nil
+	}
+	if err := l.pool.Submit(job); err != nil {
+		cancel()
+		l.finish(gen, LoadFailed, err, done)
+		return err
+	}
+	return nil
+}
+
+// run prepares one load and commits it if it is still current
+func (l *AsyncLoader) run(ctx context.Context, gen uint64, data []byte, done chan struct{}) {
+	commit, err := l.prepare(ctx, data, func(p float64) {
+		if l.isCurrent(gen) {
+			l.setProgress(p)
+		}
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case gen != l.generation || ctx.Err() != nil:
+		l.finish(gen, LoadCanceled, ErrLoadCanceled, done)
+	case err != nil:
+		l.finish(gen, LoadFailed, err, done)
+	default:
+		if commit != nil {
+			if l.committer != nil {
+				l.committer(commit)
+			} else {
+				commit()
+			}
+		}
+		l.setProgress(1)
+		l.finish(gen, LoadDone, nil, done)
+	}
+}
+
+// finish records the outcome of a load; l.mu must be held
+func (l *AsyncLoader) finish(gen uint64, status LoadStatus, err error, done chan struct{}) {
+	if gen == l.generation {
+		l.status.Store(int32(status))
+		l.err = err
+		l.pending = nil
+		l.cancel = nil
+	}
+	close(done)
+}
+
+// isCurrent returns true if gen is the latest load
+func (l *AsyncLoader) isCurrent(gen uint64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return gen == l.generation
+}
+
+// setProgress stores progress and notifies the callback
+func (l *AsyncLoader) setProgress(p float64) {
+	p = math.Max(0, math.Min(1, p))
+	l.progress.Store(p)
+	if fn := l.onProgress.Load(); fn != nil {
+		(*fn)(p)
+	}
+}
+
+// Cancel stops the load in flight, leaving the current state in place
+func (l *AsyncLoader) Cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cancel != nil {
+		l.cancel()
+		// Invalidate the running load so it cannot commit
+		l.generation++
+		l.status.Store(int32(LoadCanceled))
+		l.err = ErrLoadCanceled
+		l.pending = nil
+		l.cancel = nil
+	}
+}
+
+// Wait blocks until the latest load finishes and returns its error
+func (l *AsyncLoader) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	done := l.done
+	l.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return l.Err()
+}
+
+// Status returns the state of the latest load
+func (l *AsyncLoader) Status() LoadStatus {
+	return LoadStatus(l.status.Load())
+}
+
+// Progress returns the progress of the latest load from 0 to 1
+func (l *AsyncLoader) Progress() float64 {
+	return l.progress.Load()
+}
+
+// Err returns the error of the latest load, if any
+func (l *AsyncLoader) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// PendingData returns the custom state of a load that has not committed yet.
+// Saving state during a load should write this instead of the live state, so
+// the host gets back what it set.
+func (l *AsyncLoader) PendingData() ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pending, l.pending != nil
+}
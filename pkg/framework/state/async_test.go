@@ -0,0 +1,138 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/justyntemme/vst3go/pkg/framework/worker"
+)
+
+func waitLoad(t *testing.T, l *AsyncLoader) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := l.Wait(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Load did not finish")
+	}
+	return err
+}
+
+func TestAsyncLoaderCommit(t *testing.T) {
+	var live atomic.Pointer[string]
+	var progress []float64
+
+	l := NewAsyncLoader(func(ctx context.Context, data []byte, report func(float64)) (func(), error) {
+		report(0.5)
+		decoded := string(data)
+		return func() { live.Store(&decoded) }, nil
+	})
+	l.SetProgressCallback(func(p float64) { progress = append(progress, p) })
+
+	if err := l.Load([]byte("sample data")); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := waitLoad(t, l); err != nil {
+		t.Fatalf("Wait returned %v", err)
+	}
+
+	if got := live.Load(); got == nil || *got != "sample data" {
+		t.Errorf("State was not committed: %v", got)
+	}
+	if l.Status() != LoadDone || l.Progress() != 1 {
+		t.Errorf("Expected done at progress 1, got %v at %v", l.Status(), l.Progress())
+	}
+	if len(progress) != 3 || progress[1] != 0.5 {
+		t.Errorf("Unexpected progress reports: %v", progress)
+	}
+	if _, ok := l.PendingData(); ok {
+		t.Error("No data should be pending after commit")
+	}
+}
+
+func TestAsyncLoaderSupersede(t *testing.T) {
+	release := make(chan struct{})
+	var committed atomic.Int32
+
+	l := NewAsyncLoader(func(ctx context.Context, data []byte, report func(float64)) (func(), error) {
+		if string(data) == "slow" {
+			// Ignore cancellation to check that stale results are discarded
+			<-release
+		}
+		return func() { committed.Add(1) }, nil
+	})
+
+	l.Load([]byte("slow"))
+	if data, ok := l.PendingData(); !ok || string(data) != "slow" {
+		t.Errorf("Expected pending data during load, got %q", data)
+	}
+
+	l.Load([]byte("fast"))
+	if err := waitLoad(t, l); err != nil {
+		t.Fatalf("Wait returned %v", err)
+	}
+	close(release)
+
+	// Give the stale load time to finish; it must not commit
+	time.Sleep(20 * time.Millisecond)
+	if committed.Load() != 1 {
+		t.Errorf("Expected only the latest load to commit, got %d commits", committed.Load())
+	}
+	if l.Status() != LoadDone {
+		t.Errorf("Expected done, got %v", l.Status())
+	}
+}
+
+func TestAsyncLoaderCancel(t *testing.T) {
+	started := make(chan struct{})
+	var committed atomic.Bool
+
+	l := NewAsyncLoader(func(ctx context.Context, data []byte, report func(float64)) (func(), error) {
+		close(started)
+		<-ctx.Done()
+		return func() { committed.Store(true) }, ctx.Err()
+	})
+
+	l.Load([]byte("large"))
+	<-started
+	l.Cancel()
+
+	if err := waitLoad(t, l); !errors.Is(err, ErrLoadCanceled) {
+		t.Errorf("Expected ErrLoadCanceled, got %v", err)
+	}
+	if committed.Load() || l.Status() != LoadCanceled {
+		t.Errorf("Canceled load committed or has status %v", l.Status())
+	}
+}
+
+func TestAsyncLoaderFailureAndPool(t *testing.T) {
+	pool := worker.NewPool(1, 1)
+	defer pool.Close()
+
+	errCorrupt := errors.New("corrupt sample")
+	var committerCalls int
+	l := NewAsyncLoader(func(ctx context.Context, data []byte, report func(float64)) (func(), error) {
+		if len(data) == 0 {
+			return nil, errCorrupt
+		}
+		return func() {}, nil
+	})
+	l.SetPool(pool)
+	l.SetCommitter(func(commit func()) {
+		committerCalls++
+		commit()
+	})
+
+	l.Load(nil)
+	if err := waitLoad(t, l); !errors.Is(err, errCorrupt) || l.Status() != LoadFailed {
+		t.Errorf("Expected failure, got %v with status %v", err, l.Status())
+	}
+
+	l.Load([]byte{1})
+	if err := waitLoad(t, l); err != nil || committerCalls != 1 {
+		t.Errorf("Expected one commit through the committer, got %d (err %v)", committerCalls, err)
+	}
+}
@@ -0,0 +1,136 @@
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// stateVersion is the version of the state format written by this package
+const stateVersion = 1
+
+// Value is one saved parameter value
+type Value struct {
+	ID    uint32
+	Value float64 // Normalized
+}
+
+// Data is plugin state decoded without applying it, for preset files and
+// tools that read or rewrite state outside the plugin
+type Data struct {
+	Values []Value
+	Custom []byte // Custom state, nil if the state has none
+}
+
+// Decode reads state written by Manager.Save or Data.Encode
+func Decode(r io.Reader) (*Data, error) {
+	values, err := readValues(r, stateVersion)
+	if err != nil {
+		return nil, err
+	}
+	d := &Data{Values: values}
+
+	var hasCustom uint32
+	if err := binary.Read(r, binary.LittleEndian, &hasCustom); err != nil {
+		return nil, err
+	}
+	if hasCustom != 0 {
+		custom, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		d.Custom = append([]byte{}, custom...)
+	}
+	return d, nil
+}
+
+// Encode writes the state in the format Manager.Load reads
+func (d *Data) Encode(w io.Writer) error {
+	if err := writeValues(w, stateVersion, d.Values); err != nil {
+		return err
+	}
+	if d.Custom == nil {
+		return binary.Write(w, binary.LittleEndian, uint32(0))
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(1)); err != nil {
+		return err
+	}
+	_, err := w.Write(d.Custom)
+	return err
+}
+
+// writeValues writes the header and parameter values
+func writeValues(w io.Writer, version uint32, values []Value) error {
+	// Write magic header
+	if _, err := w.Write([]byte("VST3GO")); err != nil {
+		return err
+	}
+
+	// Write version
+	if err := binary.Write(w, binary.LittleEndian, version); err != nil {
+		return err
+	}
+
+	// Write parameter count
+	if err := binary.Write(w, binary.LittleEndian, int32(len(values))); err != nil {
+		return err
+	}
+
+	// Write each parameter
+	for _, v := range values {
+		if err := binary.Write(w, binary.LittleEndian, v.ID); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, v.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readValues reads the header and parameter values, rejecting versions
+// newer than maxVersion
+func readValues(r io.Reader, maxVersion uint32) ([]Value, error) {
+	// Read and verify magic header
+	header := make([]byte, magicHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if string(header) != "VST3GO" {
+		return nil, fmt.Errorf("invalid state format")
+	}
+
+	// Read version
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, err
+	}
+
+	// Handle version compatibility
+	if version > maxVersion {
+		return nil, fmt.Errorf("state version %d is newer than supported version %d", version, maxVersion)
+	}
+
+	// Read parameter count
+	var paramCount int32
+	if err := binary.Read(r, binary.LittleEndian, &paramCount); err != nil {
+		return nil, err
+	}
+	if paramCount < 0 {
+		return nil, fmt.Errorf("invalid parameter count %d", paramCount)
+	}
+
+	// Read each parameter
+	values := make([]Value, 0, min(int(paramCount), 4096))
+	for i := int32(0); i < paramCount; i++ {
+		var v Value
+		if err := binary.Read(r, binary.LittleEndian, &v.ID); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &v.Value); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
@@ -3,7 +3,6 @@ package state
 
 import (
 	"encoding/binary"
-	"fmt"
 	"io"
 
 	"github.com/justyntemme/vst3go/pkg/framework/param"
@@ -31,7 +30,7 @@ type CustomLoadFunc func(r io.Reader) error
 // NewManager creates a new state manager
 func NewManager(registry *param.Registry) *Manager {
 	return &Manager{
-		version:  1,
+		version:  stateVersion,
 		registry: registry,
 	}
 }
@@ -48,36 +47,16 @@ func (m *Manager) SetCustomLoadFunc(fn CustomLoadFunc) {
 
 // Save writes the plugin state to a writer
 func (m *Manager) Save(w io.Writer) error {
-	// Write magic header
-	if _, err := w.Write([]byte("VST3GO")); err != nil {
-		return err
+	// Write parameter values
+	params := m.registry.All()
+	values := make([]Value, len(params))
+	for i, param := range params {
+		values[i] = Value{ID: param.ID, Value: param.GetValue()}
 	}
-
-	// Write version
-	if err := binary.Write(w, binary.LittleEndian, m.version); err != nil {
+	if err := writeValues(w, m.version, values); err != nil {
 		return err
 	}
 
-	// Write parameter count
-	paramCount := m.registry.Count()
-	if err := binary.Write(w, binary.LittleEndian, paramCount); err != nil {
-		return err
-	}
-
-	// Write each parameter
-	for _, param := range m.registry.All() {
-		// Write ID
-		if err := binary.Write(w, binary.LittleEndian, param.ID); err != nil {
-			return err
-		}
-
-		// Write value
-		value := param.GetValue()
-		if err := binary.Write(w, binary.LittleEndian, value); err != nil {
-			return err
-		}
-	}
-
 	// Write custom state if provided
 	if m.customSave != nil {
 		// Mark that custom data follows
@@ -93,47 +72,15 @@ func (m *Manager) Save(w io.Writer) error {
 
 // Load reads the plugin state from a reader
 func (m *Manager) Load(r io.Reader) error {
-	// Read and verify magic header
-	header := make([]byte, magicHeaderSize)
-	if _, err := io.ReadFull(r, header); err != nil {
+	values, err := readValues(r, m.version)
+	if err != nil {
 		return err
 	}
-	if string(header) != "VST3GO" {
-		return fmt.Errorf("invalid state format")
-	}
-
-	// Read version
-	var version uint32
-	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
-		return err
-	}
-
-	// Handle version compatibility
-	if version > m.version {
-		return fmt.Errorf("state version %d is newer than supported version %d", version, m.version)
-	}
-
-	// Read parameter count
-	var paramCount int32
-	if err := binary.Read(r, binary.LittleEndian, &paramCount); err != nil {
-		return err
-	}
-
-	// Read each parameter
-	for i := int32(0); i < paramCount; i++ {
-		var id uint32
-		if err := binary.Read(r, binary.LittleEndian, &id); err != nil {
-			return err
-		}
-
-		var value float64
-		if err := binary.Read(r, binary.LittleEndian, &value); err != nil {
-			return err
-		}
 
+	for _, v := range values {
 		// Set parameter value if it exists
-		if param := m.registry.Get(id); param != nil {
-			param.SetValue(value)
+		if param := m.registry.Get(v.ID); param != nil {
+			param.SetValue(v.Value)
 		}
 		// Ignore unknown parameters for forward compatibility
 	}
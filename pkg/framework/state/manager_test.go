@@ -0,0 +1,92 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+func newTestRegistry() *param.Registry {
+	registry := param.NewRegistry()
+	registry.Add(
+		param.New(0, "Gain").Range(-24, 24).Default(0).Build(),
+		param.New(1, "Mix").Range(0, 100).Default(100).Build(),
+	)
+	return registry
+}
+
+func TestManagerRoundTrip(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Get(0).SetValue(0.25)
+	registry.Get(1).SetValue(0.75)
+
+	m := NewManager(registry)
+	m.SetCustomSaveFunc(func(w io.Writer) error {
+		_, err := w.Write([]byte("custom"))
+		return err
+	})
+
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := newTestRegistry()
+	var custom []byte
+	lm := NewManager(loaded)
+	lm.SetCustomLoadFunc(func(r io.Reader) error {
+		var err error
+		custom, err = io.ReadAll(r)
+		return err
+	})
+	if err := lm.Load(&buf); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if loaded.Get(0).GetValue() != 0.25 || loaded.Get(1).GetValue() != 0.75 {
+		t.Errorf("Parameters not restored: %v, %v", loaded.Get(0).GetValue(), loaded.Get(1).GetValue())
+	}
+	if string(custom) != "custom" {
+		t.Errorf("Expected custom data, got %q", custom)
+	}
+}
+
+func FuzzManagerLoad(f *testing.F) {
+	var valid bytes.Buffer
+	NewManager(newTestRegistry()).Save(&valid)
+	f.Add(valid.Bytes())
+	f.Add(valid.Bytes()[:valid.Len()/2])
+	f.Add([]byte("VST3GO"))
+	f.Add([]byte{})
+
+	// A hostile chunk with a huge parameter count and a NaN value
+	var hostile bytes.Buffer
+	hostile.WriteString("VST3GO")
+	binary.Write(&hostile, binary.LittleEndian, uint32(1))
+	binary.Write(&hostile, binary.LittleEndian, int32(math.MaxInt32))
+	binary.Write(&hostile, binary.LittleEndian, uint32(0))
+	binary.Write(&hostile, binary.LittleEndian, math.NaN())
+	f.Add(hostile.Bytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		registry := newTestRegistry()
+		m := NewManager(registry)
+		m.SetCustomLoadFunc(func(r io.Reader) error {
+			_, err := io.Copy(io.Discard, r)
+			return err
+		})
+
+		// Errors are expected; panics, hangs and out-of-range values are not
+		m.Load(bytes.NewReader(data))
+
+		for _, p := range registry.All() {
+			if v := p.GetValue(); !(v >= 0 && v <= 1) {
+				t.Errorf("Parameter %d loaded out of range: %v", p.ID, v)
+			}
+		}
+	})
+}
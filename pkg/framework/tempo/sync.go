@@ -0,0 +1,176 @@
+// Package tempo selects the tempo that tempo-synced effects follow: the
+// host's, a tapped one, or one detected from the audio.
+package tempo
+
+import (
+	"math"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+// DefaultTempo is used when no tempo source has a value
+const DefaultTempo = 120.0
+
+// Source selects where the tempo comes from
+type Source int
+
+const (
+	// SourceHost follows the host tempo, detecting from audio if the host has none
+	SourceHost Source = iota
+	// SourceTap follows a tempo set by tapping
+	SourceTap
+	// SourceAudio follows the tempo detected in the input
+	SourceAudio
+)
+
+// String returns the source name
+func (s Source) String() string {
+	switch s {
+	case SourceHost:
+		return "Host"
+	case SourceTap:
+		return "Tap"
+	case SourceAudio:
+		return "Audio"
+	default:
+		return "Unknown"
+	}
+}
+
+// SourceOptions returns choices for a sync source parameter, in Source order
+func SourceOptions() []param.ChoiceOption {
+	return []param.ChoiceOption{
+		{Value: float64(SourceHost), Name: SourceHost.String()},
+		{Value: float64(SourceTap), Name: SourceTap.String()},
+		{Value: float64(SourceAudio), Name: SourceAudio.String()},
+	}
+}
+
+// Sync tracks the tempo from the selected source. Call Process once per
+// block before using Tempo.
+type Sync struct {
+	sampleRate    float64
+	source        Source
+	detector      *analysis.TempoDetector
+	audioFallback bool
+	tapTempo      float64
+	defaultTempo  float64
+	tempo         float64
+	fromSource    Source // Source the current tempo came from
+}
+
+// NewSync creates a sync that follows the host, falling back to audio
+// detection when the host reports no tempo
+func NewSync(sampleRate float64) *Sync {
+	return &Sync{
+		sampleRate:    sampleRate,
+		detector:      analysis.NewTempoDetector(sampleRate),
+		audioFallback: true,
+		defaultTempo:  DefaultTempo,
+		tempo:         DefaultTempo,
+	}
+}
+
+// SetSource selects the tempo source
+func (s *Sync) SetSource(source Source) {
+	if source < SourceHost || source > SourceAudio {
+		source = SourceHost
+	}
+	s.source = source
+}
+
+// Source returns the selected tempo source
+func (s *Sync) Source() Source {
+	return s.source
+}
+
+// SetAudioFallback sets whether SourceHost detects tempo from the audio when
+// the host reports none
+func (s *Sync) SetAudioFallback(enabled bool) {
+	s.audioFallback = enabled
+}
+
+// SetTapTempo sets the tempo used by SourceTap
+func (s *Sync) SetTapTempo(bpm float64) {
+	if bpm > 0 && !math.IsInf(bpm, 0) {
+		s.tapTempo = bpm
+	}
+}
+
+// SetDefaultTempo sets the tempo used until a source provides one
+func (s *Sync) SetDefaultTempo(bpm float64) {
+	if bpm > 0 && !math.IsInf(bpm, 0) {
+		s.defaultTempo = bpm
+	}
+}
+
+// Detector returns the audio tempo detector, e.g. to set its range
+func (s *Sync) Detector() *analysis.TempoDetector {
+	return s.detector
+}
+
+// Process updates the tempo for a block and returns it. Input audio is only
+// analyzed when detection may be needed.
+func (s *Sync) Process(ctx *process.Context) float64 {
+	hostTempo := 0.0
+	if ctx.Transport != nil && ctx.Transport.HasTempo && ctx.Transport.Tempo > 0 {
+		hostTempo = ctx.Transport.Tempo
+	}
+
+	detect := s.source == SourceAudio || (s.source == SourceHost && hostTempo == 0 && s.audioFallback)
+	if detect && len(ctx.Input) > 0 {
+		if len(ctx.Input) >= 2 {
+			s.detector.ProcessStereo(ctx.Input[0], ctx.Input[1])
+		} else {
+			s.detector.Process(ctx.Input[0])
+		}
+	}
+
+	s.update(hostTempo, detect)
+	return s.tempo
+}
+
+// update picks the tempo from the first source that has one
+func (s *Sync) update(hostTempo float64, detect bool) {
+	switch {
+	case s.source == SourceHost && hostTempo > 0:
+		s.tempo, s.fromSource = hostTempo, SourceHost
+	case s.source == SourceTap && s.tapTempo > 0:
+		s.tempo, s.fromSource = s.tapTempo, SourceTap
+	case detect && s.detector.Tempo() > 0:
+		s.tempo, s.fromSource = s.detector.Tempo(), SourceAudio
+	case hostTempo > 0:
+		// Audio or tap selected but nothing known yet
+		s.tempo, s.fromSource = hostTempo, SourceHost
+	default:
+		// Keep the last tempo so a dropout does not jump back to the default
+		if s.tempo <= 0 {
+			s.tempo = s.defaultTempo
+		}
+	}
+}
+
+// Tempo returns the current tempo in BPM
+func (s *Sync) Tempo() float64 {
+	return s.tempo
+}
+
+// ActiveSource returns the source the current tempo came from, which differs
+// from Source when falling back
+func (s *Sync) ActiveSource() Source {
+	return s.fromSource
+}
+
+// SamplesPerBeat returns the length of a beat at the current tempo
+func (s *Sync) SamplesPerBeat() float64 {
+	return 60 / s.tempo * s.sampleRate
+}
+
+// Reset clears the detector and returns to the default tempo
+func (s *Sync) Reset() {
+	s.detector.Reset()
+	s.tempo = s.defaultTempo
+	s.fromSource = SourceHost
+}
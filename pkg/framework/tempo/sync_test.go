@@ -0,0 +1,97 @@
+package tempo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+// clicks renders a short burst every beat at bpm
+func clicks(bpm, sampleRate, seconds float64) []float32 {
+	out := make([]float32, int(seconds*sampleRate))
+	period := 60 / bpm * sampleRate
+	for beat := 0; int(float64(beat)*period) < len(out); beat++ {
+		start := int(float64(beat) * period)
+		for i := 0; i < 400 && start+i < len(out); i++ {
+			sign := float32(1)
+			if i%2 == 1 {
+				sign = -1
+			}
+			out[start+i] = sign * float32(math.Exp(-float64(i)/80))
+		}
+	}
+	return out
+}
+
+// run feeds audio through s in blocks
+func run(s *Sync, ctx *process.Context, audio []float32) {
+	const block = 256
+	for i := 0; i+block <= len(audio); i += block {
+		ctx.Input = [][]float32{audio[i : i+block]}
+		s.Process(ctx)
+	}
+}
+
+func TestSyncHost(t *testing.T) {
+	s := NewSync(48000)
+	ctx := process.NewContext(256, nil)
+	ctx.Transport.HasTempo = true
+	ctx.Transport.Tempo = 93
+
+	run(s, ctx, clicks(140, 48000, 10))
+	if s.Tempo() != 93 || s.ActiveSource() != SourceHost {
+		t.Errorf("got %g from %v", s.Tempo(), s.ActiveSource())
+	}
+	if s.Detector().Tempo() != 0 {
+		t.Error("audio should not be analyzed while the host has a tempo")
+	}
+	if got := s.SamplesPerBeat(); math.Abs(got-60.0/93*48000) > 1e-9 {
+		t.Errorf("samples per beat %g", got)
+	}
+}
+
+func TestSyncHostFallsBackToAudio(t *testing.T) {
+	s := NewSync(48000)
+	ctx := process.NewContext(256, nil)
+
+	run(s, ctx, clicks(140, 48000, 12))
+	if math.Abs(s.Tempo()-140) > 1 || s.ActiveSource() != SourceAudio {
+		t.Errorf("got %g from %v", s.Tempo(), s.ActiveSource())
+	}
+
+	s.Reset()
+	s.SetAudioFallback(false)
+	run(s, ctx, clicks(140, 48000, 12))
+	if s.Tempo() != DefaultTempo {
+		t.Errorf("without fallback expected the default tempo, got %g", s.Tempo())
+	}
+}
+
+func TestSyncTapAndAudio(t *testing.T) {
+	s := NewSync(48000)
+	ctx := process.NewContext(256, nil)
+	ctx.Transport.HasTempo = true
+	ctx.Transport.Tempo = 100
+
+	s.SetSource(SourceTap)
+	run(s, ctx, clicks(140, 48000, 1))
+	if s.Tempo() != 100 {
+		t.Errorf("tap without taps should follow the host, got %g", s.Tempo())
+	}
+	s.SetTapTempo(87.5)
+	run(s, ctx, clicks(140, 48000, 1))
+	if s.Tempo() != 87.5 || s.ActiveSource() != SourceTap {
+		t.Errorf("got %g from %v", s.Tempo(), s.ActiveSource())
+	}
+
+	s.SetSource(SourceAudio)
+	run(s, ctx, clicks(140, 48000, 12))
+	if math.Abs(s.Tempo()-140) > 1 || s.ActiveSource() != SourceAudio {
+		t.Errorf("got %g from %v", s.Tempo(), s.ActiveSource())
+	}
+
+	if opts := SourceOptions(); len(opts) != 3 || opts[2].Name != "Audio" {
+		t.Errorf("unexpected options %v", opts)
+	}
+}
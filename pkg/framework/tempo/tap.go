@@ -0,0 +1,170 @@
+package tempo
+
+import (
+	"time"
+
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+// Tap tempo defaults
+const (
+	DefaultTapTimeout   = 2 * time.Second // Gap that starts a new tap sequence
+	DefaultTapIntervals = 4               // Intervals averaged into the tempo
+	maxTapIntervals     = 16
+	tapOutlierRatio     = 1.5 // Interval change that restarts averaging
+	minTapBPM           = 20.0
+	maxTapBPM           = 400.0
+)
+
+// TapTempo turns presses of a tap parameter into a tempo. Taps are
+// timestamped in samples from the parameter change offsets and the host's
+// continuous sample position, so the tempo does not depend on block size.
+type TapTempo struct {
+	paramID    uint32
+	sampleRate float64
+	timeout    int64 // Samples
+	average    int
+	sync       *Sync
+
+	clock     int64 // Position of the current block
+	lastValue float64
+	lastTap   int64
+	hasTap    bool
+	intervals [maxTapIntervals]float64
+	count     int // Valid intervals, newest at count-1
+	tempo     float64
+}
+
+// NewTapTempo creates a tap tempo for the tap parameter paramID, usually a
+// param.TapParameter
+func NewTapTempo(paramID uint32, sampleRate float64) *TapTempo {
+	t := &TapTempo{
+		paramID:    paramID,
+		sampleRate: sampleRate,
+		average:    DefaultTapIntervals,
+	}
+	t.SetTimeout(DefaultTapTimeout)
+	return t
+}
+
+// SetTimeout sets the gap after which the next tap starts a new sequence
+func (t *TapTempo) SetTimeout(d time.Duration) {
+	if d > 0 {
+		t.timeout = int64(d.Seconds() * t.sampleRate)
+	}
+}
+
+// SetAveraging sets how many of the latest intervals are averaged (1-16)
+func (t *TapTempo) SetAveraging(intervals int) {
+	t.average = max(1, min(maxTapIntervals, intervals))
+}
+
+// SetSync sets a tempo sync that receives every new tap tempo
+func (t *TapTempo) SetSync(s *Sync) {
+	t.sync = s
+	if s != nil && t.tempo > 0 {
+		s.SetTapTempo(t.tempo)
+	}
+}
+
+// Process looks for taps in the current block and returns true if the tempo
+// changed. Call once per process call, before reading Tempo.
+func (t *TapTempo) Process(ctx *process.Context) bool {
+	// Follow the host's sample clock at the start of each host block
+	if ctx.BlockOffset() == 0 && ctx.Transport != nil && ctx.Transport.ContinuousTimeSamples > 0 {
+		t.clock = ctx.Transport.ContinuousTimeSamples
+	}
+
+	start := ctx.BlockOffset()
+	end := start + ctx.NumSamples()
+	changed := false
+	seen := false
+	for _, change := range ctx.GetParameterChanges() {
+		if change.ParamID != t.paramID || change.SampleOffset < start || change.SampleOffset >= end {
+			continue
+		}
+		seen = true
+		if t.edge(change.Value) && t.Tap(t.clock+int64(change.SampleOffset-start)) {
+			changed = true
+		}
+	}
+
+	// Taps from the editor arrive as plain value changes
+	if !seen && t.edge(ctx.Param(t.paramID)) && t.Tap(t.clock) {
+		changed = true
+	}
+
+	t.clock += int64(ctx.NumSamples())
+	return changed
+}
+
+// edge tracks the tap parameter and reports a press
+func (t *TapTempo) edge(value float64) bool {
+	pressed := value >= 0.5 && t.lastValue < 0.5
+	t.lastValue = value
+	return pressed
+}
+
+// Tap records a tap at an absolute sample position and returns true if the
+// tempo changed
+func (t *TapTempo) Tap(position int64) bool {
+	if !t.hasTap || position <= t.lastTap || position-t.lastTap > t.timeout {
+		t.lastTap, t.hasTap = position, true
+		t.count = 0
+		return false
+	}
+
+	interval := float64(position - t.lastTap)
+	t.lastTap = position
+	bpm := 60 * t.sampleRate / interval
+	if bpm < minTapBPM || bpm > maxTapBPM {
+		t.count = 0
+		return false
+	}
+
+	// A much longer or shorter interval means the player changed tempo
+	if t.count > 0 {
+		last := t.intervals[t.count-1]
+		if interval > last*tapOutlierRatio || interval*tapOutlierRatio < last {
+			t.count = 0
+		}
+	}
+	if t.count == maxTapIntervals {
+		copy(t.intervals[:], t.intervals[1:])
+		t.count--
+	}
+	t.intervals[t.count] = interval
+	t.count++
+
+	n := min(t.count, t.average)
+	sum := 0.0
+	for _, v := range t.intervals[t.count-n : t.count] {
+		sum += v
+	}
+	t.tempo = 60 * t.sampleRate / (sum / float64(n))
+	if t.sync != nil {
+		t.sync.SetTapTempo(t.tempo)
+	}
+	return true
+}
+
+// Tempo returns the tapped tempo in BPM, or 0 before two taps
+func (t *TapTempo) Tempo() float64 {
+	return t.tempo
+}
+
+// Taps returns the number of taps in the current sequence
+func (t *TapTempo) Taps() int {
+	if !t.hasTap {
+		return 0
+	}
+	return t.count + 1
+}
+
+// Reset forgets all taps and the tapped tempo
+func (t *TapTempo) Reset() {
+	t.clock, t.lastValue, t.lastTap = 0, 0, 0
+	t.hasTap = false
+	t.count = 0
+	t.tempo = 0
+}
@@ -0,0 +1,131 @@
+package tempo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+const tapID = 7
+
+func TestTapTempoAveraging(t *testing.T) {
+	tap := NewTapTempo(tapID, 48000)
+
+	if tap.Tap(0) || tap.Tempo() != 0 {
+		t.Fatal("a single tap should not set a tempo")
+	}
+	// 120 BPM with a little jitter
+	positions := []int64{24000, 48300, 71800, 96000}
+	for _, pos := range positions {
+		if !tap.Tap(pos) {
+			t.Fatalf("tap at %d did not update the tempo", pos)
+		}
+	}
+	if math.Abs(tap.Tempo()-120) > 0.01 {
+		t.Errorf("expected 120 BPM, got %g", tap.Tempo())
+	}
+	if tap.Taps() != 5 {
+		t.Errorf("expected 5 taps, got %d", tap.Taps())
+	}
+
+	// A new, much slower tempo restarts averaging
+	tap.Tap(96000 + 40000)
+	if math.Abs(tap.Tempo()-72) > 0.01 {
+		t.Errorf("expected 72 BPM after tempo change, got %g", tap.Tempo())
+	}
+}
+
+func TestTapTempoTimeout(t *testing.T) {
+	tap := NewTapTempo(tapID, 48000)
+	tap.SetTimeout(time.Second)
+
+	tap.Tap(0)
+	tap.Tap(24000)
+	if tap.Tempo() != 120 {
+		t.Fatalf("expected 120 BPM, got %g", tap.Tempo())
+	}
+
+	// Two seconds later starts a new sequence and keeps the tempo
+	if tap.Tap(24000+96000) || tap.Taps() != 1 || tap.Tempo() != 120 {
+		t.Errorf("tap after timeout: taps %d, tempo %g", tap.Taps(), tap.Tempo())
+	}
+	tap.Tap(24000 + 96000 + 32000)
+	if tap.Tempo() != 90 {
+		t.Errorf("expected 90 BPM, got %g", tap.Tempo())
+	}
+}
+
+func TestTapTempoProcess(t *testing.T) {
+	registry := param.NewRegistry()
+	registry.Add(param.TapParameter(tapID, "Tap").Build())
+
+	const block = 512
+	ctx := process.NewContext(block, registry)
+	ctx.SampleRate = 48000
+	ctx.Output = [][]float32{make([]float32, block)}
+
+	sync := NewSync(48000)
+	sync.SetSource(SourceTap)
+	tap := NewTapTempo(tapID, 48000)
+	tap.SetSync(sync)
+
+	// Press and release the tap every 22050 samples (~130.6 BPM), at sample
+	// offsets the block size does not divide
+	const interval = 22050
+	next := int64(1000)
+	for pos := int64(0); pos < 6*interval; pos += block {
+		ctx.ResetParameterChanges()
+		ctx.Transport.ContinuousTimeSamples = pos + 1 // Host clock starts at 1
+		if next >= pos && next < pos+block {
+			offset := int(next - pos)
+			ctx.AddParameterChange(tapID, 1, offset)
+			if offset+10 < block {
+				ctx.AddParameterChange(tapID, 0, offset+10)
+			} else {
+				ctx.AddParameterChange(tapID, 0, block-1)
+			}
+			next += interval
+		}
+		ctx.SortParameterChanges()
+		ctx.CaptureParams()
+		tap.Process(ctx)
+		sync.Process(ctx)
+	}
+
+	want := 60 * 48000.0 / interval
+	if math.Abs(tap.Tempo()-want) > 1e-9 {
+		t.Errorf("expected %g BPM, got %g", want, tap.Tempo())
+	}
+	if sync.Tempo() != tap.Tempo() || sync.ActiveSource() != SourceTap {
+		t.Errorf("sync got %g from %v", sync.Tempo(), sync.ActiveSource())
+	}
+}
+
+func TestTapTempoEditorValue(t *testing.T) {
+	registry := param.NewRegistry()
+	p := param.TapParameter(tapID, "Tap").Build()
+	registry.Add(p)
+
+	const block = 480
+	ctx := process.NewContext(block, registry)
+	ctx.Output = [][]float32{make([]float32, block)}
+	tap := NewTapTempo(tapID, 48000)
+
+	// The editor presses the button every 50 blocks (0.5 s) without automation
+	for i := 0; i < 200; i++ {
+		switch i % 50 {
+		case 0:
+			p.SetValue(1)
+		case 1:
+			p.SetValue(0)
+		}
+		ctx.CaptureParams()
+		tap.Process(ctx)
+	}
+	if tap.Tempo() != 120 {
+		t.Errorf("expected 120 BPM, got %g", tap.Tempo())
+	}
+}
@@ -0,0 +1,141 @@
+package tuning
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Unmapped marks a key without a scale degree in a keyboard mapping
+const Unmapped = -1
+
+// KeyboardMapping is a Scala (.kbm) keyboard mapping. It assigns scale
+// degrees to MIDI keys and fixes the reference pitch.
+type KeyboardMapping struct {
+	FirstNote          int     // Lowest key to map
+	LastNote           int     // Highest key to map
+	MiddleNote         int     // Key where the first mapping entry (degree 0) is
+	ReferenceNote      int     // Key tuned to ReferenceFrequency
+	ReferenceFrequency float64 // Frequency of ReferenceNote in Hz
+	OctaveDegree       int     // Degree of the formal octave, 0 for the scale length
+	Mapping            []int   // Degree per key in the pattern, or Unmapped. Empty maps linearly.
+}
+
+// DefaultKeyboardMapping maps keys linearly with the root on middle C and
+// A4 at 440 Hz
+func DefaultKeyboardMapping() *KeyboardMapping {
+	return &KeyboardMapping{
+		FirstNote:          0,
+		LastNote:           127,
+		MiddleNote:         60,
+		ReferenceNote:      69,
+		ReferenceFrequency: 440,
+	}
+}
+
+// Degree returns the scale degree of a key relative to the root and false if
+// the key is unmapped. scaleLen is used when OctaveDegree is 0.
+func (k *KeyboardMapping) Degree(note, scaleLen int) (int, bool) {
+	offset := note - k.MiddleNote
+	if len(k.Mapping) == 0 {
+		return offset, true
+	}
+
+	octave := k.OctaveDegree
+	if octave == 0 {
+		octave = scaleLen
+	}
+	n := len(k.Mapping)
+	entry := k.Mapping[floorMod(offset, n)]
+	if entry == Unmapped {
+		return 0, false
+	}
+	return entry + floorDiv(offset, n)*octave, true
+}
+
+// LoadKeyboardMapping reads a .kbm file
+func LoadKeyboardMapping(path string) (*KeyboardMapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	k, err := ParseKeyboardMapping(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+// ParseKeyboardMapping parses the Scala keyboard mapping format: map size,
+// first, last and middle key, reference key and frequency, formal octave
+// degree, then one degree or x (unmapped) per line
+func ParseKeyboardMapping(r io.Reader) (*KeyboardMapping, error) {
+	lines := scalaLines(r)
+
+	var ints [7]int
+	var freq float64
+	for i := range ints {
+		line, ok := lines()
+		if !ok {
+			return nil, fmt.Errorf("%w: keyboard mapping header truncated", ErrInvalidFile)
+		}
+		field := firstField(line)
+		if i == 5 {
+			f, err := strconv.ParseFloat(field, 64)
+			if err != nil || !(f > 0) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("%w: bad reference frequency %q", ErrInvalidFile, field)
+			}
+			freq = f
+			continue
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad value %q", ErrInvalidFile, field)
+		}
+		ints[i] = v
+	}
+
+	size := ints[0]
+	if size < 0 || size > maxScaleNotes {
+		return nil, fmt.Errorf("%w: bad map size %d", ErrInvalidFile, size)
+	}
+	k := &KeyboardMapping{
+		FirstNote:          ints[1],
+		LastNote:           ints[2],
+		MiddleNote:         ints[3],
+		ReferenceNote:      ints[4],
+		ReferenceFrequency: freq,
+		OctaveDegree:       ints[6],
+	}
+	if k.FirstNote < 0 || k.LastNote > 127 || k.FirstNote > k.LastNote {
+		return nil, fmt.Errorf("%w: bad key range %d-%d", ErrInvalidFile, k.FirstNote, k.LastNote)
+	}
+	if k.OctaveDegree < 0 || k.OctaveDegree > maxScaleNotes {
+		return nil, fmt.Errorf("%w: bad octave degree %d", ErrInvalidFile, k.OctaveDegree)
+	}
+
+	// Missing trailing entries are unmapped
+	k.Mapping = make([]int, size)
+	for i := range k.Mapping {
+		k.Mapping[i] = Unmapped
+		line, ok := lines()
+		if !ok {
+			continue
+		}
+		field := strings.ToLower(firstField(line))
+		if field == "x" || field == "" {
+			continue
+		}
+		degree, err := strconv.Atoi(field)
+		if err != nil || degree < 0 {
+			return nil, fmt.Errorf("%w: bad mapping entry %q", ErrInvalidFile, field)
+		}
+		k.Mapping[i] = degree
+	}
+	return k, nil
+}
@@ -0,0 +1,109 @@
+package tuning
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrMTSUnavailable is returned when the MTS-ESP library is not installed
+var ErrMTSUnavailable = errors.New("MTS-ESP library not available")
+
+// mtsLibrary is the subset of the MTS-ESP client API used by MTSClient.
+// Tables point into memory owned by the library and are updated in place by
+// the master.
+type mtsLibrary interface {
+	registerClient()
+	deregisterClient()
+	hasMaster() bool
+	shouldFilterNote(note, channel int8) bool
+	tuningTable() *[128]float64
+	useMultiChannelTuning(channel int8) bool
+	multiChannelTuningTable(channel int8) *[128]float64
+	scaleName() string
+}
+
+// MTSClient follows the tuning of an MTS-ESP master, such as a tuning
+// plugin in the same host, and falls back to another tuning when no master
+// is connected. Lookups read the shared table directly and are safe on the
+// audio thread.
+type MTSClient struct {
+	mu       sync.Mutex
+	lib      mtsLibrary
+	fallback Tuning
+}
+
+// NewMTSClient registers with the MTS-ESP library if it is installed. If it
+// is not, the client is still usable and always uses fallback. A nil
+// fallback means Standard.
+func NewMTSClient(fallback Tuning) (*MTSClient, error) {
+	lib, err := openMTS()
+	c := newMTSClient(lib, fallback)
+	return c, err
+}
+
+// newMTSClient wraps a loaded library, which may be nil
+func newMTSClient(lib mtsLibrary, fallback Tuning) *MTSClient {
+	if fallback == nil {
+		fallback = Standard
+	}
+	if lib != nil {
+		lib.registerClient()
+	}
+	return &MTSClient{lib: lib, fallback: fallback}
+}
+
+// HasMaster returns true if an MTS-ESP master is providing tuning
+func (c *MTSClient) HasMaster() bool {
+	return c.lib != nil && c.lib.hasMaster()
+}
+
+// NoteToFrequency returns the master's frequency for note, or the fallback
+// tuning when no master is connected
+func (c *MTSClient) NoteToFrequency(note, channel uint8) float64 {
+	if note > 127 || !c.HasMaster() {
+		return c.fallback.NoteToFrequency(note, channel)
+	}
+
+	var table *[128]float64
+	if ch := int8(channel); channel < 16 && c.lib.useMultiChannelTuning(ch) {
+		table = c.lib.multiChannelTuningTable(ch)
+	} else {
+		table = c.lib.tuningTable()
+	}
+	if table == nil {
+		return c.fallback.NoteToFrequency(note, channel)
+	}
+	return table[note]
+}
+
+// ShouldFilterNote returns true if the master leaves the note unmapped
+func (c *MTSClient) ShouldFilterNote(note, channel uint8) bool {
+	if note > 127 || !c.HasMaster() {
+		return ShouldFilter(c.fallback, note, channel)
+	}
+	ch := int8(-1)
+	if channel < 16 {
+		ch = int8(channel)
+	}
+	return c.lib.shouldFilterNote(int8(note), ch)
+}
+
+// ScaleName returns the name of the master's scale, or "" without a master
+func (c *MTSClient) ScaleName() string {
+	if !c.HasMaster() {
+		return ""
+	}
+	return c.lib.scaleName()
+}
+
+// Close deregisters the client, typically from Terminate. It must not run
+// while the audio thread is using the client; lookups fall back afterwards.
+func (c *MTSClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lib != nil {
+		c.lib.deregisterClient()
+		c.lib = nil
+	}
+}
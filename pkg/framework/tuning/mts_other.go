@@ -0,0 +1,8 @@
+//go:build !windows && !(cgo && (linux || darwin))
+
+package tuning
+
+// openMTS reports that MTS-ESP cannot be loaded on this platform or without cgo
+func openMTS() (mtsLibrary, error) {
+	return nil, ErrMTSUnavailable
+}
@@ -0,0 +1,127 @@
+//go:build cgo && (linux || darwin)
+
+package tuning
+
+/*
+#cgo linux LDFLAGS: -ldl
+#include <dlfcn.h>
+#include <stdbool.h>
+#include <stdlib.h>
+
+typedef void (*mts_void)(void);
+typedef bool (*mts_bool)(void);
+typedef bool (*mts_bcc)(char, char);
+typedef const double *(*mts_cd)(void);
+typedef const double *(*mts_cdc)(char);
+typedef bool (*mts_bc)(char);
+typedef const char *(*mts_pcc)(void);
+
+typedef struct {
+	void *handle;
+	mts_void registerClient;
+	mts_void deregisterClient;
+	mts_bool hasMaster;
+	mts_bcc shouldFilterNote;
+	mts_cd getTuningTable;
+	mts_bc useMultiChannelTuning;
+	mts_cdc getMultiChannelTuningTable;
+	mts_pcc getScaleName;
+} mts_lib;
+
+static int mts_open(const char *path, mts_lib *lib) {
+	lib->handle = dlopen(path, RTLD_NOW | RTLD_LOCAL);
+	if (!lib->handle) return 0;
+	lib->registerClient = (mts_void)dlsym(lib->handle, "MTS_RegisterClient");
+	lib->deregisterClient = (mts_void)dlsym(lib->handle, "MTS_DeregisterClient");
+	lib->hasMaster = (mts_bool)dlsym(lib->handle, "MTS_HasMaster");
+	lib->shouldFilterNote = (mts_bcc)dlsym(lib->handle, "MTS_ShouldFilterNoteMultiChannel");
+	lib->getTuningTable = (mts_cd)dlsym(lib->handle, "MTS_GetTuningTable");
+	lib->useMultiChannelTuning = (mts_bc)dlsym(lib->handle, "MTS_UseMultiChannelTuning");
+	lib->getMultiChannelTuningTable = (mts_cdc)dlsym(lib->handle, "MTS_GetMultiChannelTuningTable");
+	lib->getScaleName = (mts_pcc)dlsym(lib->handle, "MTS_GetScaleName");
+	if (!lib->registerClient || !lib->deregisterClient || !lib->hasMaster ||
+	    !lib->shouldFilterNote || !lib->getTuningTable || !lib->useMultiChannelTuning ||
+	    !lib->getMultiChannelTuningTable || !lib->getScaleName) {
+		dlclose(lib->handle);
+		lib->handle = NULL;
+		return 0;
+	}
+	return 1;
+}
+
+static void mts_call_void(mts_void fn) { fn(); }
+static bool mts_call_bool(mts_bool fn) { return fn(); }
+static bool mts_call_bcc(mts_bcc fn, char a, char b) { return fn(a, b); }
+static const double *mts_call_cd(mts_cd fn) { return fn(); }
+static bool mts_call_bc(mts_bc fn, char a) { return fn(a); }
+static const double *mts_call_cdc(mts_cdc fn, char a) { return fn(a); }
+static const char *mts_call_pcc(mts_pcc fn) { return fn(); }
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// mtsLibraryPath returns where the MTS-ESP installer puts libMTS
+func mtsLibraryPath() string {
+	if runtime.GOOS == "darwin" {
+		return "/Library/Application Support/MTS-ESP/libMTS.dylib"
+	}
+	return "/usr/local/lib/libMTS.so"
+}
+
+// dlMTS calls libMTS through function pointers loaded with dlopen
+type dlMTS struct {
+	lib C.mts_lib
+}
+
+// openMTS loads libMTS if it is installed
+func openMTS() (mtsLibrary, error) {
+	path := C.CString(mtsLibraryPath())
+	defer C.free(unsafe.Pointer(path))
+
+	m := &dlMTS{}
+	if C.mts_open(path, &m.lib) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMTSUnavailable, mtsLibraryPath())
+	}
+	return m, nil
+}
+
+func (m *dlMTS) registerClient() {
+	C.mts_call_void(m.lib.registerClient)
+}
+
+func (m *dlMTS) deregisterClient() {
+	C.mts_call_void(m.lib.deregisterClient)
+}
+
+func (m *dlMTS) hasMaster() bool {
+	return bool(C.mts_call_bool(m.lib.hasMaster))
+}
+
+func (m *dlMTS) shouldFilterNote(note, channel int8) bool {
+	return bool(C.mts_call_bcc(m.lib.shouldFilterNote, C.char(note), C.char(channel)))
+}
+
+func (m *dlMTS) tuningTable() *[128]float64 {
+	return (*[128]float64)(unsafe.Pointer(C.mts_call_cd(m.lib.getTuningTable)))
+}
+
+func (m *dlMTS) useMultiChannelTuning(channel int8) bool {
+	return bool(C.mts_call_bc(m.lib.useMultiChannelTuning, C.char(channel)))
+}
+
+func (m *dlMTS) multiChannelTuningTable(channel int8) *[128]float64 {
+	return (*[128]float64)(unsafe.Pointer(C.mts_call_cdc(m.lib.getMultiChannelTuningTable, C.char(channel))))
+}
+
+func (m *dlMTS) scaleName() string {
+	name := C.mts_call_pcc(m.lib.getScaleName)
+	if name == nil {
+		return ""
+	}
+	return C.GoString(name)
+}
@@ -0,0 +1,112 @@
+//go:build windows
+
+package tuning
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// winMTS calls LIBMTS.dll through syscall. Every function used returns a
+// bool or pointer in a general-purpose register, so no cgo is needed.
+type winMTS struct {
+	procRegisterClient          *syscall.LazyProc
+	procDeregisterClient        *syscall.LazyProc
+	procHasMaster               *syscall.LazyProc
+	procShouldFilterNote        *syscall.LazyProc
+	procTuningTable             *syscall.LazyProc
+	procUseMultiChannelTuning   *syscall.LazyProc
+	procMultiChannelTuningTable *syscall.LazyProc
+	procScaleName               *syscall.LazyProc
+}
+
+// openMTS loads LIBMTS.dll from Common Files if it is installed
+func openMTS() (mtsLibrary, error) {
+	common := os.Getenv("CommonProgramFiles")
+	if common == "" {
+		common = `C:\Program Files\Common Files`
+	}
+	path := filepath.Join(common, "MTS-ESP", "LIBMTS.dll")
+
+	dll := syscall.NewLazyDLL(path)
+	if err := dll.Load(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMTSUnavailable, err)
+	}
+	m := &winMTS{
+		procRegisterClient:          dll.NewProc("MTS_RegisterClient"),
+		procDeregisterClient:        dll.NewProc("MTS_DeregisterClient"),
+		procHasMaster:               dll.NewProc("MTS_HasMaster"),
+		procShouldFilterNote:        dll.NewProc("MTS_ShouldFilterNoteMultiChannel"),
+		procTuningTable:             dll.NewProc("MTS_GetTuningTable"),
+		procUseMultiChannelTuning:   dll.NewProc("MTS_UseMultiChannelTuning"),
+		procMultiChannelTuningTable: dll.NewProc("MTS_GetMultiChannelTuningTable"),
+		procScaleName:               dll.NewProc("MTS_GetScaleName"),
+	}
+	for _, p := range []*syscall.LazyProc{
+		m.procRegisterClient, m.procDeregisterClient, m.procHasMaster, m.procShouldFilterNote,
+		m.procTuningTable, m.procUseMultiChannelTuning, m.procMultiChannelTuningTable, m.procScaleName,
+	} {
+		if err := p.Find(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMTSUnavailable, err)
+		}
+	}
+	return m, nil
+}
+
+func (m *winMTS) registerClient() {
+	m.procRegisterClient.Call()
+}
+
+func (m *winMTS) deregisterClient() {
+	m.procDeregisterClient.Call()
+}
+
+func (m *winMTS) hasMaster() bool {
+	r, _, _ := m.procHasMaster.Call()
+	return byte(r) != 0
+}
+
+func (m *winMTS) shouldFilterNote(note, channel int8) bool {
+	r, _, _ := m.procShouldFilterNote.Call(uintptr(note), uintptr(channel))
+	return byte(r) != 0
+}
+
+func (m *winMTS) tuningTable() *[128]float64 {
+	r, _, _ := m.procTuningTable.Call()
+	return tablePointer(r)
+}
+
+func (m *winMTS) useMultiChannelTuning(channel int8) bool {
+	r, _, _ := m.procUseMultiChannelTuning.Call(uintptr(channel))
+	return byte(r) != 0
+}
+
+func (m *winMTS) multiChannelTuningTable(channel int8) *[128]float64 {
+	r, _, _ := m.procMultiChannelTuningTable.Call(uintptr(channel))
+	return tablePointer(r)
+}
+
+func (m *winMTS) scaleName() string {
+	r, _, _ := m.procScaleName.Call()
+	if r == 0 {
+		return ""
+	}
+	p := *(**byte)(unsafe.Pointer(&r))
+	var b []byte
+	for i := uintptr(0); ; i++ {
+		c := *(*byte)(unsafe.Add(unsafe.Pointer(p), i))
+		if c == 0 {
+			return string(b)
+		}
+		b = append(b, c)
+	}
+}
+
+// tablePointer converts a pointer returned by the DLL. The memory is owned
+// by the DLL, so the uintptr is reinterpreted rather than converted.
+func tablePointer(r uintptr) *[128]float64 {
+	return *(**[128]float64)(unsafe.Pointer(&r))
+}
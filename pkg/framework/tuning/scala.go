@@ -0,0 +1,169 @@
+package tuning
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFile is returned for malformed Scala files
+var ErrInvalidFile = errors.New("invalid tuning file")
+
+// maxScaleNotes bounds the note count of a scale file
+const maxScaleNotes = 1024
+
+// Scale is a Scala (.scl) scale. Degrees are in cents above the root; the
+// last degree is the period, usually an octave of 1200 cents.
+type Scale struct {
+	Description string
+	Cents       []float64
+}
+
+// NewEqualScale creates a scale dividing period cents into n equal steps
+func NewEqualScale(n int, period float64) *Scale {
+	if n < 1 {
+		n = 1
+	}
+	s := &Scale{
+		Description: fmt.Sprintf("%d equal divisions of %g cents", n, period),
+		Cents:       make([]float64, n),
+	}
+	for i := range s.Cents {
+		s.Cents[i] = period * float64(i+1) / float64(n)
+	}
+	return s
+}
+
+// Len returns the number of degrees per period
+func (s *Scale) Len() int {
+	return len(s.Cents)
+}
+
+// Period returns the size of the period in cents
+func (s *Scale) Period() float64 {
+	return s.Cents[len(s.Cents)-1]
+}
+
+// DegreeCents returns the cents of a scale degree relative to the root,
+// extending the scale by periods in both directions
+func (s *Scale) DegreeCents(degree int) float64 {
+	n := len(s.Cents)
+	periods := floorDiv(degree, n)
+	idx := floorMod(degree, n)
+	cents := float64(periods) * s.Period()
+	if idx > 0 {
+		cents += s.Cents[idx-1]
+	}
+	return cents
+}
+
+// LoadScale reads a .scl file
+func LoadScale(path string) (*Scale, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := ParseScale(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ParseScale parses the Scala scale format: a description line, a note
+// count, then one pitch per line as cents (containing a period) or a ratio
+// like 3/2 or 2. Lines starting with ! are comments.
+func ParseScale(r io.Reader) (*Scale, error) {
+	lines := scalaLines(r)
+
+	var s Scale
+	desc, ok := lines()
+	if !ok {
+		return nil, fmt.Errorf("%w: missing description", ErrInvalidFile)
+	}
+	s.Description = strings.TrimSpace(desc)
+
+	countLine, ok := lines()
+	if !ok {
+		return nil, fmt.Errorf("%w: missing note count", ErrInvalidFile)
+	}
+	count, err := strconv.Atoi(firstField(countLine))
+	if err != nil || count < 0 || count > maxScaleNotes {
+		return nil, fmt.Errorf("%w: bad note count %q", ErrInvalidFile, strings.TrimSpace(countLine))
+	}
+
+	for len(s.Cents) < count {
+		line, ok := lines()
+		if !ok {
+			return nil, fmt.Errorf("%w: expected %d notes, found %d", ErrInvalidFile, count, len(s.Cents))
+		}
+		cents, err := parsePitch(firstField(line))
+		if err != nil {
+			return nil, err
+		}
+		s.Cents = append(s.Cents, cents)
+	}
+
+	if count == 0 {
+		// A scale with no notes is a single degree repeating every octave
+		s.Cents = []float64{1200}
+	}
+	if s.Period() <= 0 {
+		return nil, fmt.Errorf("%w: period must be positive", ErrInvalidFile)
+	}
+	return &s, nil
+}
+
+// parsePitch converts a cents value or ratio to cents
+func parsePitch(field string) (float64, error) {
+	if strings.Contains(field, ".") {
+		cents, err := strconv.ParseFloat(field, 64)
+		if err != nil || math.IsNaN(cents) || math.IsInf(cents, 0) {
+			return 0, fmt.Errorf("%w: bad cents value %q", ErrInvalidFile, field)
+		}
+		return cents, nil
+	}
+
+	num, den := field, "1"
+	if i := strings.IndexByte(field, '/'); i >= 0 {
+		num, den = field[:i], field[i+1:]
+	}
+	n, err1 := strconv.ParseUint(num, 10, 63)
+	d, err2 := strconv.ParseUint(den, 10, 63)
+	if err1 != nil || err2 != nil || n == 0 || d == 0 {
+		return 0, fmt.Errorf("%w: bad ratio %q", ErrInvalidFile, field)
+	}
+	return 1200 * math.Log2(float64(n)/float64(d)), nil
+}
+
+// scalaLines returns an iterator over the non-comment lines of a Scala file
+func scalaLines(r io.Reader) func() (string, bool) {
+	scanner := bufio.NewScanner(r)
+	return func() (string, bool) {
+		for scanner.Scan() {
+			line := strings.TrimRight(scanner.Text(), "\r")
+			if strings.HasPrefix(line, "!") {
+				continue
+			}
+			return line, true
+		}
+		return "", false
+	}
+}
+
+// firstField returns the first whitespace-separated field of a line, since
+// Scala allows trailing text after values
+func firstField(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
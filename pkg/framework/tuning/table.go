@@ -0,0 +1,58 @@
+package tuning
+
+import "math"
+
+// Table is a precomputed frequency per MIDI note. Lookups are allocation
+// free, so a Table can be used on the audio thread.
+type Table struct {
+	Name        string
+	Frequencies [128]float64 // 0 for unmapped keys
+}
+
+// NewTable builds a table from a scale and keyboard mapping. A nil mapping
+// uses DefaultKeyboardMapping.
+func NewTable(scale *Scale, kbm *KeyboardMapping) *Table {
+	if kbm == nil {
+		kbm = DefaultKeyboardMapping()
+	}
+	t := &Table{Name: scale.Description}
+
+	// The reference key is tuned even if it is unmapped
+	refDegree, ok := kbm.Degree(kbm.ReferenceNote, scale.Len())
+	if !ok {
+		refDegree = kbm.ReferenceNote - kbm.MiddleNote
+	}
+	refCents := scale.DegreeCents(refDegree)
+
+	for note := kbm.FirstNote; note <= kbm.LastNote; note++ {
+		degree, ok := kbm.Degree(note, scale.Len())
+		if !ok {
+			continue
+		}
+		cents := scale.DegreeCents(degree) - refCents
+		t.Frequencies[note] = kbm.ReferenceFrequency * math.Exp2(cents/1200)
+	}
+	return t
+}
+
+// NewEqualTable builds a 12-TET table with A4 at a4 Hz
+func NewEqualTable(a4 float64) *Table {
+	t := &Table{Name: "12-TET"}
+	for note := range t.Frequencies {
+		t.Frequencies[note] = EqualTemperament(a4).NoteToFrequency(uint8(note), 0)
+	}
+	return t
+}
+
+// NoteToFrequency returns the frequency of note, or 0 if it is unmapped
+func (t *Table) NoteToFrequency(note, _ uint8) float64 {
+	if note > 127 {
+		return 0
+	}
+	return t.Frequencies[note]
+}
+
+// ShouldFilterNote returns true for unmapped keys
+func (t *Table) ShouldFilterNote(note, _ uint8) bool {
+	return note > 127 || t.Frequencies[note] == 0
+}
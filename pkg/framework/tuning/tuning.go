@@ -0,0 +1,57 @@
+// Package tuning provides microtonal tuning for instruments: Scala scale and
+// keyboard mapping files, and real-time tuning from an MTS-ESP master.
+package tuning
+
+import "math"
+
+// Tuning maps MIDI notes to frequencies
+type Tuning interface {
+	// NoteToFrequency returns the frequency in Hz of a note on a MIDI
+	// channel (0-15). Tunings that are not per-channel ignore channel.
+	NoteToFrequency(note, channel uint8) float64
+}
+
+// Filter is implemented by tunings that leave some notes unmapped. Unmapped
+// notes should not be played.
+type Filter interface {
+	// ShouldFilterNote returns true if the note should be ignored
+	ShouldFilterNote(note, channel uint8) bool
+}
+
+// EqualTemperament is standard 12-tone equal temperament with A4 (note 69)
+// at the given frequency
+type EqualTemperament float64
+
+// Standard is 12-TET with A4 at 440 Hz
+const Standard EqualTemperament = 440
+
+// NoteToFrequency returns the 12-TET frequency of note
+func (a EqualTemperament) NoteToFrequency(note, _ uint8) float64 {
+	ref := float64(a)
+	if ref <= 0 {
+		ref = 440
+	}
+	return ref * math.Exp2((float64(note)-69)/12)
+}
+
+// ShouldFilter returns true if t filters the note
+func ShouldFilter(t Tuning, note, channel uint8) bool {
+	if f, ok := t.(Filter); ok {
+		return f.ShouldFilterNote(note, channel)
+	}
+	return false
+}
+
+// floorDiv divides rounding toward negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// floorMod returns a modulo b in [0, b)
+func floorMod(a, b int) int {
+	return a - floorDiv(a, b)*b
+}
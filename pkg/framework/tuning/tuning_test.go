@@ -0,0 +1,238 @@
+package tuning
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+const twelveTET = `! 12tet.scl
+!
+12 tone equal temperament
+ 12
+!
+ 100.0
+ 200.
+ 300.0
+ 400.0
+ 500.0
+ 600.0
+ 700.0
+ 800.0
+ 900.0
+ 1000.0
+ 1100.0
+ 2/1
+`
+
+const justMajor = `! just.scl
+5-limit just major, with trailing text
+7
+9/8 major second
+5/4
+4/3
+3/2
+5/3
+15/8
+2
+`
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6*math.Max(1, math.Abs(b))
+}
+
+func TestParseScale(t *testing.T) {
+	s, err := ParseScale(strings.NewReader(twelveTET))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Description != "12 tone equal temperament" || s.Len() != 12 {
+		t.Errorf("got %q with %d notes", s.Description, s.Len())
+	}
+	if !near(s.Period(), 1200) || !near(s.Cents[1], 200) {
+		t.Errorf("unexpected cents %v", s.Cents)
+	}
+	if !near(s.DegreeCents(-1), -100) || !near(s.DegreeCents(13), 1300) {
+		t.Errorf("degrees outside one period: %g, %g", s.DegreeCents(-1), s.DegreeCents(13))
+	}
+
+	just, err := ParseScale(strings.NewReader(justMajor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(just.Cents[3], 1200*math.Log2(1.5)) {
+		t.Errorf("3/2 should be %g cents, got %g", 1200*math.Log2(1.5), just.Cents[3])
+	}
+
+	for _, bad := range []string{
+		"",
+		"desc\nabc\n",
+		"desc\n3\n100.0\n",
+		"desc\n1\n0/1\n",
+		"desc\n1\n-2\n",
+		"desc\n1\nNaN.\n",
+		"desc\n1\n-1200.0\n",
+	} {
+		if _, err := ParseScale(strings.NewReader(bad)); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("%q: expected ErrInvalidFile, got %v", bad, err)
+		}
+	}
+}
+
+func TestTableMatchesEqualTemperament(t *testing.T) {
+	s, err := ParseScale(strings.NewReader(twelveTET))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := NewTable(s, nil)
+	for note := 0; note < 128; note++ {
+		want := Standard.NoteToFrequency(uint8(note), 0)
+		if got := table.NoteToFrequency(uint8(note), 0); !near(got, want) {
+			t.Fatalf("note %d: got %g, want %g", note, got, want)
+		}
+	}
+	if table.ShouldFilterNote(60, 0) {
+		t.Error("linear mapping should map every key")
+	}
+}
+
+func TestKeyboardMapping(t *testing.T) {
+	// Map the 7-note just scale to the white keys with C4 at 261.6256 Hz
+	const kbm = `! white keys
+12
+0
+127
+60
+60
+261.6256
+7
+0
+x
+1
+x
+2
+3
+x
+4
+x
+5
+x
+6
+`
+	k, err := ParseKeyboardMapping(strings.NewReader(kbm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseScale(strings.NewReader(justMajor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := NewTable(s, k)
+
+	checks := map[uint8]float64{
+		60: 261.6256,
+		62: 261.6256 * 9 / 8,
+		67: 261.6256 * 3 / 2,
+		71: 261.6256 * 15 / 8,
+		72: 261.6256 * 2,
+		48: 261.6256 / 2,
+		55: 261.6256 * 3 / 4,
+	}
+	for note, want := range checks {
+		if got := table.NoteToFrequency(note, 0); !near(got, want) {
+			t.Errorf("note %d: got %g, want %g", note, got, want)
+		}
+	}
+	for _, black := range []uint8{61, 63, 66, 68, 70} {
+		if !table.ShouldFilterNote(black, 0) {
+			t.Errorf("note %d should be unmapped", black)
+		}
+	}
+
+	if _, err := ParseKeyboardMapping(strings.NewReader("12\n0\n127\n60\n69\n-440\n12\n")); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("expected ErrInvalidFile for negative frequency, got %v", err)
+	}
+}
+
+func TestEqualScaleTable(t *testing.T) {
+	// 19-EDO with the default mapping keeps A4 at 440 Hz
+	table := NewTable(NewEqualScale(19, 1200), nil)
+	if got := table.NoteToFrequency(69, 0); !near(got, 440) {
+		t.Errorf("A4: got %g", got)
+	}
+	if got := table.NoteToFrequency(70, 0); !near(got, 440*math.Exp2(1.0/19)) {
+		t.Errorf("one step above A4: got %g", got)
+	}
+}
+
+// fakeMTS is an in-process stand-in for libMTS
+type fakeMTS struct {
+	registered bool
+	master     bool
+	table      [128]float64
+	channel5   [128]float64
+	filtered   map[int8]bool
+}
+
+func (f *fakeMTS) registerClient()                 { f.registered = true }
+func (f *fakeMTS) deregisterClient()               { f.registered = false }
+func (f *fakeMTS) hasMaster() bool                 { return f.master }
+func (f *fakeMTS) shouldFilterNote(n, _ int8) bool { return f.filtered[n] }
+func (f *fakeMTS) tuningTable() *[128]float64      { return &f.table }
+func (f *fakeMTS) useMultiChannelTuning(ch int8) bool {
+	return ch == 5
+}
+func (f *fakeMTS) multiChannelTuningTable(int8) *[128]float64 { return &f.channel5 }
+func (f *fakeMTS) scaleName() string                          { return "fake" }
+
+func TestMTSClient(t *testing.T) {
+	lib := &fakeMTS{filtered: map[int8]bool{61: true}}
+	for i := range lib.table {
+		lib.table[i] = float64(i) * 10
+		lib.channel5[i] = float64(i) * 20
+	}
+
+	c := newMTSClient(lib, nil)
+	if !lib.registered {
+		t.Fatal("client should register")
+	}
+
+	// Without a master the fallback tuning is used
+	if got := c.NoteToFrequency(69, 0); !near(got, 440) {
+		t.Errorf("fallback: got %g", got)
+	}
+	if c.ShouldFilterNote(61, 0) || c.ScaleName() != "" {
+		t.Error("fallback should not filter or name a scale")
+	}
+
+	lib.master = true
+	if got := c.NoteToFrequency(69, 0); got != 690 {
+		t.Errorf("master table: got %g", got)
+	}
+	if got := c.NoteToFrequency(69, 5); got != 1380 {
+		t.Errorf("multichannel table: got %g", got)
+	}
+	if !c.ShouldFilterNote(61, 0) || c.ScaleName() != "fake" {
+		t.Error("master filtering and scale name not forwarded")
+	}
+
+	c.Close()
+	if lib.registered {
+		t.Error("Close should deregister")
+	}
+	if got := c.NoteToFrequency(69, 0); !near(got, 440) {
+		t.Errorf("after Close: got %g", got)
+	}
+}
+
+func TestNewMTSClientWithoutLibrary(t *testing.T) {
+	c, err := NewMTSClient(EqualTemperament(432))
+	if err != nil && !errors.Is(err, ErrMTSUnavailable) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer c.Close()
+	if err != nil && !near(c.NoteToFrequency(69, 0), 432) {
+		t.Errorf("fallback: got %g", c.NoteToFrequency(69, 0))
+	}
+}
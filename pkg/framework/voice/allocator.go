@@ -1,6 +1,7 @@
 package voice
 
 import (
+	"github.com/justyntemme/vst3go/pkg/framework/tuning"
 	"github.com/justyntemme/vst3go/pkg/midi"
 )
 
@@ -56,6 +57,21 @@ type Voice interface {
 	Process(output []float32)
 }
 
+// LegatoVoice is a Voice that can move to a new note without retriggering.
+// In legato mode the allocator calls GlideTo for overlapping notes; voices
+// without it keep their first note until released.
+type LegatoVoice interface {
+	Voice
+	// GlideTo changes the note while the envelopes keep running
+	GlideTo(note uint8, velocity uint8)
+}
+
+// heldNote is a key held down in mono or legato mode
+type heldNote struct {
+	note     uint8
+	velocity uint8
+}
+
 // Allocator manages voice allocation for polyphonic synthesis
 type Allocator struct {
 	voices        []Voice
@@ -77,6 +93,11 @@ type Allocator struct {
 	previousNote uint8
 	glideTime    float64
 	glideActive  bool
+	heldNotes    []heldNote // Keys down, oldest first
+
+	// Tuning state
+	tuning  tuning.Tuning
+	channel uint8 // Channel of the last note on
 }
 
 // NewAllocator creates a new voice allocator
@@ -88,6 +109,8 @@ func NewAllocator(voices []Voice) *Allocator {
 		maxVoices:      len(voices),
 		noteToVoice:    make(map[uint8][]int),
 		sustainedNotes: make(map[uint8]bool),
+		heldNotes:      make([]heldNote, 0, 16),
+		tuning:         tuning.Standard,
 	}
 }
 
@@ -124,11 +147,38 @@ func (a *Allocator) SetGlideTime(seconds float64) {
 	a.glideTime = seconds
 }
 
+// GlideTime returns the glide time in seconds, for voices to read when
+// they glide
+func (a *Allocator) GlideTime() float64 {
+	return a.glideTime
+}
+
+// SetTuning sets the tuning used by NoteToFrequency. Notes the tuning leaves
+// unmapped are ignored. A nil tuning restores 12-TET at 440 Hz.
+func (a *Allocator) SetTuning(t tuning.Tuning) {
+	if t == nil {
+		t = tuning.Standard
+	}
+	a.tuning = t
+}
+
+// Tuning returns the current tuning
+func (a *Allocator) Tuning() tuning.Tuning {
+	return a.tuning
+}
+
+// NoteToFrequency returns the frequency of a note in the current tuning on
+// the channel of the last note on. Voices call this from TriggerNote.
+func (a *Allocator) NoteToFrequency(note uint8) float64 {
+	return a.tuning.NoteToFrequency(note, a.channel)
+}
+
 // ProcessEvent handles a MIDI event
 func (a *Allocator) ProcessEvent(event midi.Event) {
 	switch e := event.(type) {
 	case midi.NoteOnEvent:
 		if e.Velocity > 0 {
+			a.channel = e.Channel()
 			a.NoteOn(e.NoteNumber, e.Velocity)
 		} else {
 			// Note on with velocity 0 is treated as note off
@@ -145,6 +195,10 @@ func (a *Allocator) ProcessEvent(event midi.Event) {
 
 // NoteOn handles a note on event
 func (a *Allocator) NoteOn(note uint8, velocity uint8) {
+	if tuning.ShouldFilter(a.tuning, note, a.channel) {
+		return
+	}
+
 	switch a.mode {
 	case ModePoly:
 		a.noteOnPoly(note, velocity)
@@ -199,6 +253,7 @@ func (a *Allocator) Reset() {
 	a.currentNote = 0
 	a.previousNote = 0
 	a.glideActive = false
+	a.heldNotes = a.heldNotes[:0]
 }
 
 // GetActiveVoiceCount returns the number of active voices
@@ -251,6 +306,12 @@ func (a *Allocator) noteOffPoly(note uint8, velocity uint8) {
 
 // noteOnMono handles mono mode note on
 func (a *Allocator) noteOnMono(note uint8, velocity uint8) {
+	a.holdNote(note, velocity)
+	a.triggerMono(note, velocity)
+}
+
+// triggerMono restarts the mono voice on a note
+func (a *Allocator) triggerMono(note uint8, velocity uint8) {
 	// Stop all other voices
 	for i := 0; i < a.maxVoices && i < 1; i++ {
 		if a.voices[i].IsActive() {
@@ -266,21 +327,57 @@ func (a *Allocator) noteOnMono(note uint8, velocity uint8) {
 
 // noteOnLegato handles legato mode note on
 func (a *Allocator) noteOnLegato(note uint8, velocity uint8) {
+	a.holdNote(note, velocity)
 	if a.currentNote == 0 {
 		// First note, trigger normally
-		a.noteOnMono(note, velocity)
+		a.triggerMono(note, velocity)
 	} else {
-		// Legato transition - change pitch without retriggering
-		a.previousNote = a.currentNote
-		a.currentNote = note
-		a.glideActive = true
-		// Voice implementation should handle the pitch change
-		a.noteToVoice = map[uint8][]int{note: {0}}
+		a.glideMono(note, velocity)
+	}
+}
+
+// glideMono moves the mono voice to a note without retriggering
+func (a *Allocator) glideMono(note uint8, velocity uint8) {
+	a.previousNote = a.currentNote
+	a.currentNote = note
+	a.glideActive = true
+	if v, ok := a.voices[0].(LegatoVoice); ok {
+		v.GlideTo(note, velocity)
+	}
+	a.noteToVoice = map[uint8][]int{note: {0}}
+}
+
+// holdNote adds a key to the held notes, moving it to the top if it is
+// already down
+func (a *Allocator) holdNote(note uint8, velocity uint8) {
+	a.releaseHeld(note)
+	a.heldNotes = append(a.heldNotes, heldNote{note: note, velocity: velocity})
+}
+
+// releaseHeld removes a key from the held notes
+func (a *Allocator) releaseHeld(note uint8) {
+	for i, h := range a.heldNotes {
+		if h.note == note {
+			a.heldNotes = append(a.heldNotes[:i], a.heldNotes[i+1:]...)
+			return
+		}
 	}
 }
 
-// noteOffMono handles mono/legato mode note off
+// noteOffMono handles mono/legato mode note off. Releasing the sounding
+// note returns to the most recent key still held, last note priority as on
+// a monophonic synth.
 func (a *Allocator) noteOffMono(note uint8, velocity uint8) {
+	a.releaseHeld(note)
+	if note == a.currentNote && len(a.heldNotes) > 0 {
+		h := a.heldNotes[len(a.heldNotes)-1]
+		if a.mode == ModeLegato {
+			a.glideMono(h.note, h.velocity)
+		} else {
+			a.triggerMono(h.note, h.velocity)
+		}
+		return
+	}
 	if note == a.currentNote {
 		a.voices[0].ReleaseNote()
 		delete(a.noteToVoice, note)
@@ -1,8 +1,11 @@
 package voice
 
 import (
+	"math"
 	"testing"
 
+	"github.com/justyntemme/vst3go/pkg/framework/tuning"
+
 	"github.com/justyntemme/vst3go/pkg/midi"
 )
 
@@ -133,6 +136,78 @@ func TestAllocatorLegatoMode(t *testing.T) {
 	}
 }
 
+// legatoTestVoice records glides as well as triggers
+type legatoTestVoice struct {
+	TestVoice
+	triggers int
+	glides   int
+}
+
+func (v *legatoTestVoice) TriggerNote(note uint8, velocity uint8) {
+	v.TestVoice.TriggerNote(note, velocity)
+	v.triggers++
+}
+
+func (v *legatoTestVoice) GlideTo(note uint8, velocity uint8) {
+	v.note = note
+	v.velocity = velocity
+	v.glides++
+}
+
+func TestAllocatorLegatoGlide(t *testing.T) {
+	v := &legatoTestVoice{}
+	allocator := NewAllocator([]Voice{v})
+	allocator.SetMode(ModeLegato)
+
+	allocator.NoteOn(60, 100)
+	allocator.NoteOn(64, 90)
+	if v.triggers != 1 || v.glides != 1 {
+		t.Fatalf("Expected 1 trigger and 1 glide, got %d and %d", v.triggers, v.glides)
+	}
+	if v.note != 64 || v.velocity != 90 {
+		t.Errorf("Expected glide to note 64 at velocity 90, got %d at %d", v.note, v.velocity)
+	}
+
+	// Releasing the top note glides back to the one still held
+	allocator.NoteOff(64, 0)
+	if v.note != 60 || !v.active || v.glides != 2 {
+		t.Errorf("Expected glide back to held note 60, got note %d active %v glides %d", v.note, v.active, v.glides)
+	}
+
+	allocator.NoteOff(60, 0)
+	if v.active {
+		t.Error("Voice should release when no keys are held")
+	}
+}
+
+func TestAllocatorMonoLastNotePriority(t *testing.T) {
+	voices := createTestVoices(1)
+	allocator := NewAllocator(voices)
+	allocator.SetMode(ModeMono)
+	v0 := voices[0].(*TestVoice)
+
+	allocator.NoteOn(60, 100)
+	allocator.NoteOn(64, 100)
+	allocator.NoteOn(67, 100)
+
+	// Releasing a key that is not sounding changes nothing
+	allocator.NoteOff(64, 0)
+	if v0.note != 67 {
+		t.Errorf("Expected note 67 to keep sounding, got %d", v0.note)
+	}
+
+	// Releasing the sounding key retriggers the last key still held
+	allocator.NoteOff(67, 0)
+	if !v0.active || v0.note != 60 {
+		t.Errorf("Expected retrigger of held note 60, got note %d active %v", v0.note, v0.active)
+	}
+
+	allocator.NoteOff(60, 0)
+	if v0.active {
+		t.Error("Voice should release when no keys are held")
+	}
+}
+
 func TestAllocatorUnisonMode(t *testing.T) {
 	voices := createTestVoices(4)
 	allocator := NewAllocator(voices)
@@ -314,4 +389,38 @@ func TestReset(t *testing.T) {
 	if len(allocator.noteToVoice) != 0 {
 		t.Error("Reset should clear note mappings")
 	}
-}
\ No newline at end of file
+}
+
+func TestAllocatorTuning(t *testing.T) {
+	voices := make([]Voice, 2)
+	for i := range voices {
+		voices[i] = &TestVoice{}
+	}
+	alloc := NewAllocator(voices)
+
+	if got := alloc.NoteToFrequency(69); math.Abs(got-440) > 1e-9 {
+		t.Errorf("default tuning: got %g", got)
+	}
+
+	// A 432 Hz table with one unmapped key
+	table := tuning.NewEqualTable(432)
+	table.Frequencies[61] = 0
+	alloc.SetTuning(table)
+
+	if got := alloc.NoteToFrequency(69); math.Abs(got-432) > 1e-9 {
+		t.Errorf("table tuning: got %g", got)
+	}
+	alloc.NoteOn(61, 100)
+	if alloc.GetActiveVoiceCount() != 0 {
+		t.Error("unmapped note should be ignored")
+	}
+	alloc.NoteOn(60, 100)
+	if alloc.GetActiveVoiceCount() != 1 {
+		t.Error("mapped note should play")
+	}
+
+	alloc.SetTuning(nil)
+	if alloc.Tuning() != tuning.Standard {
+		t.Error("nil tuning should restore the standard tuning")
+	}
+}
@@ -0,0 +1,284 @@
+// Package worker provides a managed pool for work that must stay off the audio thread.
+package worker
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	fwdebug "github.com/justyntemme/vst3go/pkg/framework/debug"
+)
+
+var (
+	// ErrQueueFull is returned when a job cannot be queued without blocking.
+	ErrQueueFull = errors.New("worker: queue full")
+	// ErrPoolClosed is returned when submitting to a pool that has shut down.
+	ErrPoolClosed = errors.New("worker: pool closed")
+)
+
+// Job is a unit of background work. The context is cancelled when the pool
+// shuts down, so long-running jobs should check it and return early.
+type Job func(ctx context.Context)
+
+// PanicHandler receives panics recovered from jobs.
+type PanicHandler func(recovered interface{}, stack []byte)
+
+// Priority orders queued jobs. Workers always take the highest priority job
+// waiting; jobs of the same priority run in submission order.
+type Priority int
+
+// Job priorities
+const (
+	// PriorityHigh is for work the user is waiting on, such as loading a
+	// sample they just picked.
+	PriorityHigh Priority = iota
+	// PriorityNormal is the priority of Submit and SubmitWait.
+	PriorityNormal
+	// PriorityLow is for housekeeping such as preset scans and exports.
+	PriorityLow
+
+	numPriorities
+)
+
+// Pool runs jobs on a fixed number of goroutines fed by bounded queues, one
+// per priority. A panicking job is recovered and reported without taking
+// down the pool.
+type Pool struct {
+	queues  [numPriorities]chan Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.RWMutex // Guards onPanic
+	onPanic PanicHandler
+
+	// Submitters check closed with submitting raised, so once Shutdown has
+	// set closed and seen submitting drop to zero no job can be queued.
+	closed     atomic.Bool
+	submitting atomic.Int32
+	stop       chan struct{} // Closed when Shutdown starts, wakes SubmitWait
+	drained    chan struct{} // Closed once no more jobs can be queued
+
+	completed atomic.Uint64
+	panics    atomic.Uint64
+}
+
+// NewPool creates a pool with the given number of workers. Each priority
+// has its own queue with the given capacity.
+func NewPool(workers, queueSize int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		ctx:     ctx,
+		cancel:  cancel,
+		stop:    make(chan struct{}),
+		drained: make(chan struct{}),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan Job, queueSize)
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.run()
+	}
+
+	return p
+}
+
+// SetPanicHandler sets the function called when a job panics. Without one,
+// panics are logged through the framework logger.
+func (p *Pool) SetPanicHandler(handler PanicHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onPanic = handler
+}
+
+// Submit queues a job at normal priority without blocking. It is safe to
+// call from the audio thread; ErrQueueFull is returned instead of waiting
+// for space.
+func (p *Pool) Submit(job Job) error {
+	return p.SubmitPriority(PriorityNormal, job)
+}
+
+// SubmitPriority queues a job at the given priority without blocking. Like
+// Submit it takes no locks and is safe to call from the audio thread.
+func (p *Pool) SubmitPriority(priority Priority, job Job) error {
+	queue := p.queue(priority)
+	p.submitting.Add(1)
+	defer p.submitting.Add(-1)
+
+	if p.closed.Load() {
+		return ErrPoolClosed
+	}
+
+	select {
+	case queue <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// SubmitWait queues a job at normal priority, blocking until there is space
+// or ctx is done. Never call this from the audio thread.
+func (p *Pool) SubmitWait(ctx context.Context, job Job) error {
+	p.submitting.Add(1)
+	defer p.submitting.Add(-1)
+
+	if p.closed.Load() {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.queues[PriorityNormal] <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.stop:
+		return ErrPoolClosed
+	}
+}
+
+// queue returns the queue for a priority, clamping unknown priorities
+func (p *Pool) queue(priority Priority) chan Job {
+	if priority < PriorityHigh {
+		priority = PriorityHigh
+	}
+	if priority > PriorityLow {
+		priority = PriorityLow
+	}
+	return p.queues[priority]
+}
+
+// run is the worker loop
+func (p *Pool) run() {
+	defer p.wg.Done()
+
+	for {
+		job, ok := p.next()
+		if !ok {
+			return
+		}
+		p.execute(job)
+	}
+}
+
+// next waits for the highest priority queued job. It returns false once
+// the pool has shut down and the queues are empty.
+func (p *Pool) next() (Job, bool) {
+	if job, ok := p.poll(); ok {
+		return job, true
+	}
+
+	select {
+	case job := <-p.queues[PriorityHigh]:
+		return job, true
+	case job := <-p.queues[PriorityNormal]:
+		return job, true
+	case job := <-p.queues[PriorityLow]:
+		return job, true
+	case <-p.drained:
+		return p.poll()
+	}
+}
+
+// poll returns the highest priority queued job without waiting
+func (p *Pool) poll() (Job, bool) {
+	for _, queue := range p.queues {
+		select {
+		case job := <-queue:
+			return job, true
+		default:
+		}
+	}
+	return nil, false
+}
+
+// execute runs a single job with panic isolation
+func (p *Pool) execute(job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.panics.Add(1)
+
+			p.mu.RLock()
+			handler := p.onPanic
+			p.mu.RUnlock()
+
+			if handler != nil {
+				handler(r, debug.Stack())
+			} else {
+				fwdebug.Error("[WORKER] Recovered panic in job: %v\n%s", r, debug.Stack())
+			}
+		}
+	}()
+
+	job(p.ctx)
+	p.completed.Add(1)
+}
+
+// Pending returns the number of queued jobs not yet started.
+func (p *Pool) Pending() int {
+	n := 0
+	for _, queue := range p.queues {
+		n += len(queue)
+	}
+	return n
+}
+
+// Completed returns the number of jobs that finished without panicking.
+func (p *Pool) Completed() uint64 {
+	return p.completed.Load()
+}
+
+// Panics returns the number of jobs that panicked.
+func (p *Pool) Panics() uint64 {
+	return p.panics.Load()
+}
+
+// Shutdown stops accepting jobs and waits for queued jobs to finish.
+// If ctx expires first, running jobs are cancelled and ctx.Err() is returned
+// at once; jobs that ignore cancellation are left running in the
+// background.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	if p.closed.Swap(true) {
+		return nil
+	}
+	close(p.stop)
+
+	// Submitters that saw the pool open finish their non-blocking send
+	for p.submitting.Load() > 0 {
+		runtime.Gosched()
+	}
+	close(p.drained)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// Close cancels running jobs, drains the queue and waits for workers to exit.
+func (p *Pool) Close() error {
+	p.cancel()
+	return p.Shutdown(context.Background())
+}
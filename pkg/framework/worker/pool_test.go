@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolRunsJobs(t *testing.T) {
+	pool := NewPool(4, 64)
+
+	var count atomic.Int32
+	for i := 0; i < 50; i++ {
+		if err := pool.Submit(func(ctx context.Context) { count.Add(1) }); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if count.Load() != 50 || pool.Completed() != 50 {
+		t.Errorf("Expected 50 completed jobs, got %d (%d)", count.Load(), pool.Completed())
+	}
+
+	if err := pool.Submit(func(ctx context.Context) {}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPoolQueueFull(t *testing.T) {
+	pool := NewPool(1, 1)
+	defer pool.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func(ctx context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+
+	// One job fits in the queue, the next one does not
+	if err := pool.Submit(func(ctx context.Context) {}); err != nil {
+		t.Fatalf("Expected queued job, got %v", err)
+	}
+	if err := pool.Submit(func(ctx context.Context) {}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	close(release)
+}
+
+func TestPoolPanicIsolation(t *testing.T) {
+	pool := NewPool(1, 4)
+
+	recovered := make(chan interface{}, 1)
+	pool.SetPanicHandler(func(r interface{}, stack []byte) {
+		recovered <- r
+	})
+
+	pool.Submit(func(ctx context.Context) { panic("boom") })
+	pool.Submit(func(ctx context.Context) {})
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if r := <-recovered; r != "boom" {
+		t.Errorf("Expected recovered panic, got %v", r)
+	}
+	if pool.Panics() != 1 || pool.Completed() != 1 {
+		t.Errorf("Expected 1 panic and 1 completed job, got %d and %d", pool.Panics(), pool.Completed())
+	}
+}
+
+func TestPoolShutdownCancelsOnDeadline(t *testing.T) {
+	pool := NewPool(1, 1)
+
+	pool.Submit(func(ctx context.Context) {
+		<-ctx.Done() // Long-running job that honors cancellation
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestPoolShutdownDoesNotWaitForStuckJobs(t *testing.T) {
+	pool := NewPool(1, 1)
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	pool.Submit(func(ctx context.Context) {
+		close(started)
+		<-release // Ignores cancellation
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	returned := make(chan error, 1)
+	go func() { returned <- pool.Shutdown(ctx) }()
+	select {
+	case err := <-returned:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown waited for a job that ignores cancellation")
+	}
+}
+
+func TestPoolPriorities(t *testing.T) {
+	pool := NewPool(1, 4)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func(ctx context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+
+	// Queued while the worker is busy, so they run by priority
+	var order []Priority
+	for _, priority := range []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityLow} {
+		priority := priority
+		if err := pool.SubmitPriority(priority, func(ctx context.Context) {
+			order = append(order, priority)
+		}); err != nil {
+			t.Fatalf("SubmitPriority failed: %v", err)
+		}
+	}
+	if pool.Pending() != 4 {
+		t.Errorf("Expected 4 pending jobs, got %d", pool.Pending())
+	}
+	close(release)
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	want := []Priority{PriorityHigh, PriorityNormal, PriorityLow, PriorityLow}
+	if len(order) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, order)
+		}
+	}
+	if err := pool.SubmitPriority(PriorityHigh, func(ctx context.Context) {}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPoolSubmitDuringShutdown(t *testing.T) {
+	pool := NewPool(2, 64)
+
+	var accepted, ran atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10000; i++ {
+			if pool.Submit(func(ctx context.Context) { ran.Add(1) }) == nil {
+				accepted.Add(1)
+			}
+		}
+	}()
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	<-done
+
+	// Every accepted job runs, none are dropped by the shutdown
+	if ran.Load() != accepted.Load() {
+		t.Errorf("Accepted %d jobs but ran %d", accepted.Load(), ran.Load())
+	}
+}
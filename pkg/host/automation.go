@@ -0,0 +1,181 @@
+package host
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+// ErrInvalidAutomation is returned for malformed automation timelines
+var ErrInvalidAutomation = errors.New("invalid automation")
+
+// Automation is a parameter timeline, usually loaded from JSON:
+//
+//	{
+//	  "points": [
+//	    {"param": "Gain", "time": 0, "plain": -12},
+//	    {"param": "Gain", "time": 2.5, "plain": 0, "ramp": true},
+//	    {"param": 1, "sample": 96000, "value": 1}
+//	  ]
+//	}
+//
+// Parameters are named by ID, name or short name. Points are placed in
+// seconds or samples and set a normalized value or a plain value. A ramp
+// point moves linearly from the previous point; the host sends ramps as one
+// point per block, like most DAWs.
+type Automation struct {
+	Points []Point `json:"points"`
+}
+
+// Point is one automation point
+type Point struct {
+	Param  ParamKey `json:"param"`
+	Time   float64  `json:"time,omitempty"`   // Seconds, used when Sample is nil
+	Sample *int64   `json:"sample,omitempty"` // Position in samples
+	Value  *float64 `json:"value,omitempty"`  // Normalized value
+	Plain  *float64 `json:"plain,omitempty"`  // Plain value, used when Value is nil
+	Ramp   bool     `json:"ramp,omitempty"`
+}
+
+// ParamKey names a parameter by ID, name or short name. In JSON it may be a
+// number or a string.
+type ParamKey string
+
+// UnmarshalJSON accepts numbers as well as strings
+func (k *ParamKey) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*k = ParamKey(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("%w: param must be a name or ID, got %s", ErrInvalidAutomation, data)
+	}
+	*k = ParamKey(n.String())
+	return nil
+}
+
+// LoadAutomation reads an automation timeline from a JSON file
+func LoadAutomation(path string) (*Automation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAutomation(bytes.NewReader(data))
+}
+
+// ParseAutomation reads an automation timeline from JSON
+func ParseAutomation(r io.Reader) (*Automation, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var a Automation
+	if err := dec.Decode(&a); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAutomation, err)
+	}
+	return &a, nil
+}
+
+// timeline is an automation resolved against a plugin's parameters
+type timeline struct {
+	lanes []lane
+}
+
+// lane holds the points of one parameter in time order
+type lane struct {
+	id     uint32
+	points []timedValue
+}
+
+// timedValue is a resolved point
+type timedValue struct {
+	sample int64
+	value  float64 // Normalized
+	ramp   bool
+}
+
+// resolve converts points to sample positions and normalized values
+func (a *Automation) resolve(h *Host) (*timeline, error) {
+	t := &timeline{}
+	if a == nil {
+		return t, nil
+	}
+
+	lanes := make(map[uint32]int)
+	for i, pt := range a.Points {
+		p, err := h.FindParameter(string(pt.Param))
+		if err != nil {
+			return nil, fmt.Errorf("%w: point %d: %v", ErrInvalidAutomation, i, err)
+		}
+
+		var tv timedValue
+		switch {
+		case pt.Sample != nil:
+			tv.sample = *pt.Sample
+		default:
+			tv.sample = int64(math.Round(pt.Time * h.config.SampleRate))
+		}
+		if tv.sample < 0 {
+			return nil, fmt.Errorf("%w: point %d is before the start", ErrInvalidAutomation, i)
+		}
+
+		switch {
+		case pt.Value != nil:
+			tv.value = math.Max(0, math.Min(1, *pt.Value))
+		case pt.Plain != nil:
+			tv.value = p.Normalize(*pt.Plain)
+		default:
+			return nil, fmt.Errorf("%w: point %d has no value", ErrInvalidAutomation, i)
+		}
+		tv.ramp = pt.Ramp
+
+		idx, ok := lanes[p.ID]
+		if !ok {
+			idx = len(t.lanes)
+			lanes[p.ID] = idx
+			t.lanes = append(t.lanes, lane{id: p.ID})
+		}
+		t.lanes[idx].points = append(t.lanes[idx].points, tv)
+	}
+
+	for i := range t.lanes {
+		points := t.lanes[i].points
+		sort.SliceStable(points, func(a, b int) bool { return points[a].sample < points[b].sample })
+	}
+	return t, nil
+}
+
+// changes appends the parameter changes for the block [start, start+n)
+func (t *timeline) changes(start int64, n int, dst []process.ParameterChange) []process.ParameterChange {
+	end := start + int64(n)
+	for _, l := range t.lanes {
+		// First point at or after the block start
+		i := sort.Search(len(l.points), func(i int) bool { return l.points[i].sample >= start })
+
+		// Inside a ramp, send the interpolated value at the block start
+		if i > 0 && i < len(l.points) && l.points[i].ramp && l.points[i].sample > start {
+			prev, next := l.points[i-1], l.points[i]
+			frac := float64(start-prev.sample) / float64(next.sample-prev.sample)
+			dst = append(dst, process.ParameterChange{
+				ParamID: l.id,
+				Value:   prev.value + frac*(next.value-prev.value),
+			})
+		}
+
+		for ; i < len(l.points) && l.points[i].sample < end; i++ {
+			dst = append(dst, process.ParameterChange{
+				ParamID:      l.id,
+				Value:        l.points[i].value,
+				SampleOffset: int(l.points[i].sample - start),
+			})
+		}
+	}
+	return dst
+}
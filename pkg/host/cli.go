@@ -0,0 +1,217 @@
+package host
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/audiofile"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// paramFlags collects repeated -param key=value flags
+type paramFlags []string
+
+func (p *paramFlags) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *paramFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected name=value, got %q", value)
+	}
+	*p = append(*p, value)
+	return nil
+}
+
+// nameFlags collects a repeated flag
+type nameFlags []string
+
+func (n *nameFlags) String() string {
+	return strings.Join(*n, ",")
+}
+
+func (n *nameFlags) Set(value string) error {
+	*n = append(*n, value)
+	return nil
+}
+
+// Main runs the render command line on the plugins registered in this
+// program and exits on error. A render tool is a main package that imports
+// the plugin packages and calls Main:
+//
+//	import (
+//		"github.com/justyntemme/vst3go/pkg/host"
+//		_ "example.com/myplugin"
+//	)
+//
+//	func main() { host.Main() }
+func Main() {
+	if err := Run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// Run parses render command line arguments and runs them against the
+// registered plugins
+func Run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("vst3go-render", flag.ContinueOnError)
+	var (
+		in         = fs.String("in", "", "input WAV or AIFF file")
+		out        = fs.String("out", "", "output file; the format follows the extension")
+		automation = fs.String("automation", "", "JSON automation timeline")
+		name       = fs.String("plugin", "", "plugin name or ID, needed when several are registered")
+		rate       = fs.Float64("rate", DefaultSampleRate, "sample rate in Hz; other input rates are resampled")
+		block      = fs.Int("block", DefaultBlockSize, "block size in samples")
+		tempo      = fs.Float64("tempo", DefaultTempo, "transport tempo in BPM")
+		encoding   = fs.String("encoding", "float32", "output encoding: pcm16, pcm24, pcm32, float32 or float64")
+		compensate = fs.Bool("compensate", false, "remove the plugin's latency from the output")
+		tail       = fs.Bool("tail", false, "render the plugin's tail after the input ends")
+		offline    = fs.Bool("offline", false, "report offline process mode so plugins use their offline-quality algorithms")
+		list       = fs.Bool("list", false, "list the registered plugins and their parameters")
+		meterPath  = fs.String("meters", "", "log meters during the render to a CSV or JSON file")
+		interval   = fs.Float64("meter-interval", 0, "seconds between meter log entries; 0 logs every block")
+		params     paramFlags
+		meters     nameFlags
+	)
+	fs.Var(&params, "param", "set a parameter before rendering, as name=value in plain units (repeatable)")
+	fs.Var(&meters, "meter", "meter to log: momentary, short-term, integrated, true-peak, peak or a parameter "+
+		"(repeatable; default loudness, true peak and read-only parameters)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := Config{
+		SampleRate:        *rate,
+		BlockSize:         *block,
+		Tempo:             *tempo,
+		CompensateLatency: *compensate,
+		RenderTail:        *tail,
+	}
+	if *offline {
+		cfg.Mode = process.ProcessOffline
+	}
+
+	if *list {
+		return listPlugins(stdout, cfg)
+	}
+	if *in == "" || *out == "" {
+		return errors.New("both -in and -out are required")
+	}
+
+	enc, err := parseEncoding(*encoding)
+	if err != nil {
+		return err
+	}
+	p, err := FindPlugin(*name)
+	if err != nil {
+		return err
+	}
+
+	var a *Automation
+	if *automation != "" {
+		if a, err = LoadAutomation(*automation); err != nil {
+			return err
+		}
+	}
+
+	h, err := New(p, cfg)
+	if err != nil {
+		return err
+	}
+	for _, kv := range params {
+		key, value, _ := strings.Cut(kv, "=")
+		param, err := h.FindParameter(key)
+		if err != nil {
+			return err
+		}
+		normalized, err := param.ParseValue(value)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+		param.SetValue(normalized)
+	}
+
+	if *meterPath != "" {
+		format, ok := LogFormatFromPath(*meterPath)
+		if !ok {
+			return fmt.Errorf("meter log %s must end in .csv or .json", *meterPath)
+		}
+		file, err := os.Create(*meterPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		h.SetMeterLog(&MeterLog{Writer: file, Format: format, Meters: meters, Interval: *interval})
+	}
+
+	if err := h.RenderFile(*in, *out, enc, a); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "rendered %s through %s to %s\n", *in, p.GetInfo().Name, *out)
+	return nil
+}
+
+// FindPlugin returns the registered plugin with the given name or ID. An
+// empty key selects the only registered plugin.
+func FindPlugin(key string) (plugin.Plugin, error) {
+	plugins := plugin.Registered()
+	if key == "" {
+		switch len(plugins) {
+		case 0:
+			return nil, ErrNoPlugin
+		case 1:
+			return plugins[0], nil
+		default:
+			return nil, fmt.Errorf("%d plugins registered, choose one by name or ID", len(plugins))
+		}
+	}
+	for _, p := range plugins {
+		info := p.GetInfo()
+		if info.ID == key || strings.EqualFold(info.Name, key) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoPlugin, key)
+}
+
+// parseEncoding converts an encoding name such as "pcm24"
+func parseEncoding(name string) (audiofile.Encoding, error) {
+	for enc := audiofile.PCM8; enc <= audiofile.Float64; enc++ {
+		if strings.EqualFold(enc.String(), name) {
+			return enc, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: encoding %q", audiofile.ErrUnsupportedFormat, name)
+}
+
+// listPlugins prints each registered plugin with its parameters
+func listPlugins(w io.Writer, cfg Config) error {
+	plugins := plugin.Registered()
+	if len(plugins) == 0 {
+		return ErrNoPlugin
+	}
+	for _, p := range plugins {
+		info := p.GetInfo()
+		fmt.Fprintf(w, "%s (%s)\n", info.Name, info.ID)
+
+		h, err := New(p, cfg)
+		if err != nil {
+			return err
+		}
+		if params := h.Parameters(); params != nil {
+			for _, param := range params.All() {
+				fmt.Fprintf(w, "  %4d  %-24s %g to %g %s, default %s\n", param.ID, param.Name,
+					param.Min, param.Max, param.Unit, param.FormatValue(param.DefaultValue))
+			}
+		}
+	}
+	return nil
+}
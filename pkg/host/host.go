@@ -0,0 +1,388 @@
+// Package host runs Go plugins without a DAW. It drives a processor the way
+// the VST3 wrapper does, including sample-accurate parameter automation, so
+// renders match what a host would produce; use it for offline processing and
+// DSP regression tests.
+package host
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/denormal"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// Host defaults
+const (
+	DefaultSampleRate = 48000.0
+	DefaultBlockSize  = 512
+	DefaultTempo      = 120.0
+)
+
+var (
+	// ErrNoPlugin is returned when no plugin is registered or matches a name
+	ErrNoPlugin = errors.New("no plugin found")
+	// ErrUnknownParameter is returned for parameters the plugin does not have
+	ErrUnknownParameter = errors.New("unknown parameter")
+	// ErrInvalidConfig is returned for invalid sample rates or block sizes
+	ErrInvalidConfig = errors.New("invalid host configuration")
+)
+
+// Config sets up the simulated host
+type Config struct {
+	SampleRate float64 // Zero uses DefaultSampleRate
+	BlockSize  int     // Samples per process call; zero uses DefaultBlockSize
+	Tempo      float64 // Transport tempo in BPM; zero uses DefaultTempo
+
+	// Mode is the process mode reported to the processor. The zero value
+	// renders as realtime playback would; ProcessOffline lets plugins pick
+	// their offline-quality algorithms, as in a DAW bounce.
+	Mode process.ProcessMode
+
+	// CompensateLatency drops the plugin's reported latency from the start
+	// of a render so the output lines up with the input
+	CompensateLatency bool
+	// RenderTail keeps processing silence after the input ends for the
+	// plugin's reported tail length
+	RenderTail bool
+	// KeepDenormals processes without flush-to-zero. The VST3 bridge turns
+	// it on for every process call, and so does the host by default.
+	KeepDenormals bool
+}
+
+// Host runs one processor instance
+type Host struct {
+	plugin    plugin.Plugin
+	processor plugin.Processor
+	config    Config
+	ctx       *process.Context
+
+	numInputs   int
+	numOutputs  int
+	inputBuses  []int // Channels per active input bus
+	outputBuses []int // Channels per active output bus
+	position    int64 // Samples processed since activation
+	active      bool
+	meterLog    *MeterLog
+
+	// Bypass as the VST3 component wires it, nil without a bypass parameter
+	bypass       *process.SoftBypass
+	processAudio func(ctx *process.Context)
+
+	// Block buffers, sized for BlockSize
+	inputs  [][]float32
+	outputs [][]float32
+}
+
+// New creates a processor from p and initializes it for cfg
+func New(p plugin.Plugin, cfg Config) (*Host, error) {
+	if p == nil {
+		return nil, ErrNoPlugin
+	}
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = DefaultSampleRate
+	}
+	if cfg.BlockSize == 0 {
+		cfg.BlockSize = DefaultBlockSize
+	}
+	if cfg.Tempo == 0 {
+		cfg.Tempo = DefaultTempo
+	}
+	if cfg.SampleRate < 0 || cfg.BlockSize < 0 || cfg.Tempo < 0 {
+		return nil, fmt.Errorf("%w: sample rate %g, block size %d, tempo %g",
+			ErrInvalidConfig, cfg.SampleRate, cfg.BlockSize, cfg.Tempo)
+	}
+
+	processor := p.CreateProcessor()
+	if err := processor.Initialize(cfg.SampleRate, int32(cfg.BlockSize)); err != nil {
+		return nil, fmt.Errorf("initialize %s: %w", p.GetInfo().Name, err)
+	}
+
+	h := &Host{
+		plugin:    p,
+		processor: processor,
+		config:    cfg,
+		ctx:       process.NewContext(cfg.BlockSize, processor.GetParameters()),
+
+		processAudio: processor.ProcessAudio,
+	}
+	h.ctx.SampleRate = cfg.SampleRate
+	h.ctx.Mode = cfg.Mode
+
+	if buses := processor.GetBuses(); buses != nil {
+		h.numInputs = int(buses.GetActiveInputChannelCount())
+		h.numOutputs = int(buses.GetActiveOutputChannelCount())
+		h.inputBuses = busChannelCounts(buses, bus.DirectionInput)
+		h.outputBuses = busChannelCounts(buses, bus.DirectionOutput)
+	}
+	h.inputs = makeChannels(h.numInputs, cfg.BlockSize)
+	h.outputs = makeChannels(h.numOutputs, cfg.BlockSize)
+	return h, nil
+}
+
+// busChannelCounts returns the channel count of each active audio bus
+func busChannelCounts(buses *bus.Configuration, direction bus.Direction) []int {
+	var counts []int
+	for _, info := range buses.GetActiveBuses(bus.MediaTypeAudio, direction) {
+		counts = append(counts, int(info.ChannelCount))
+	}
+	return counts
+}
+
+// makeChannels allocates silent channel buffers
+func makeChannels(channels, frames int) [][]float32 {
+	data := make([][]float32, channels)
+	for ch := range data {
+		data[ch] = make([]float32, frames)
+	}
+	return data
+}
+
+// Plugin returns the plugin the processor was created from
+func (h *Host) Plugin() plugin.Plugin {
+	return h.plugin
+}
+
+// Processor returns the processor being hosted
+func (h *Host) Processor() plugin.Processor {
+	return h.processor
+}
+
+// Config returns the host configuration with defaults filled in
+func (h *Host) Config() Config {
+	return h.config
+}
+
+// NumInputs returns the number of active input channels
+func (h *Host) NumInputs() int {
+	return h.numInputs
+}
+
+// NumOutputs returns the number of active output channels
+func (h *Host) NumOutputs() int {
+	return h.numOutputs
+}
+
+// Parameters returns the processor's parameter registry
+func (h *Host) Parameters() *param.Registry {
+	return h.processor.GetParameters()
+}
+
+// FindParameter looks a parameter up by ID, name or short name. Names are
+// matched case-insensitively.
+func (h *Host) FindParameter(key string) (*param.Parameter, error) {
+	params := h.Parameters()
+	if params == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownParameter, key)
+	}
+	if id, err := strconv.ParseUint(key, 10, 32); err == nil {
+		if p := params.Get(uint32(id)); p != nil {
+			return p, nil
+		}
+	}
+	for _, p := range params.All() {
+		if strings.EqualFold(p.Name, key) || strings.EqualFold(p.ShortName, key) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownParameter, key)
+}
+
+// SetParameter sets a normalized parameter value outside of processing, as
+// the host does when it restores state or the user moves a control
+func (h *Host) SetParameter(id uint32, normalized float64) error {
+	params := h.Parameters()
+	if params == nil || params.Get(id) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownParameter, id)
+	}
+	params.Get(id).SetValue(normalized)
+	return nil
+}
+
+// SetSampleRate moves the processor to a new sample rate, as a DAW does
+// when the project rate changes: processors that implement
+// plugin.SampleRateProcessor keep their state, others are initialized
+// again. The host must be inactive.
+func (h *Host) SetSampleRate(sampleRate float64) error {
+	if sampleRate <= 0 {
+		return fmt.Errorf("%w: sample rate %g", ErrInvalidConfig, sampleRate)
+	}
+	if h.active {
+		return fmt.Errorf("%w: sample rate change while active", ErrInvalidConfig)
+	}
+	if err := plugin.Reconfigure(h.processor, sampleRate, int32(h.config.BlockSize)); err != nil {
+		return fmt.Errorf("reconfigure %s: %w", h.plugin.GetInfo().Name, err)
+	}
+	h.config.SampleRate = sampleRate
+	h.ctx.SampleRate = sampleRate
+	return nil
+}
+
+// Activate starts processing. Render activates automatically.
+func (h *Host) Activate() error {
+	if h.active {
+		return nil
+	}
+	if params := h.Parameters(); params != nil {
+		params.ResetSmoothing()
+	}
+	if err := h.processor.SetActive(true); err != nil {
+		return err
+	}
+	h.bypass = plugin.NewBypass(h.processor, max(h.numInputs, h.numOutputs), h.config.BlockSize, h.config.SampleRate)
+	h.active = true
+	h.position = 0
+	return nil
+}
+
+// Deactivate stops processing
+func (h *Host) Deactivate() error {
+	if !h.active {
+		return nil
+	}
+	h.active = false
+	return h.processor.SetActive(false)
+}
+
+// ProcessBlock runs one process call. input and output hold one slice per
+// channel of equal length, at most BlockSize; missing input channels are
+// silent. changes are applied sample-accurately like host automation.
+func (h *Host) ProcessBlock(input, output [][]float32, changes []process.ParameterChange) error {
+	if !h.active {
+		if err := h.Activate(); err != nil {
+			return err
+		}
+	}
+
+	n := blockLength(input, output)
+	if n > h.config.BlockSize {
+		return fmt.Errorf("%w: block of %d samples exceeds block size %d", ErrInvalidConfig, n, h.config.BlockSize)
+	}
+
+	// Point the context at block buffers so processors see every bus channel
+	ctx := h.ctx
+	ctx.Input = ctx.Input[:0]
+	for ch := 0; ch < h.numInputs; ch++ {
+		buf := h.inputs[ch][:n]
+		if ch < len(input) {
+			copy(buf, input[ch])
+		} else {
+			clear(buf)
+		}
+		ctx.Input = append(ctx.Input, buf)
+	}
+	ctx.Output = ctx.Output[:0]
+	for ch := 0; ch < h.numOutputs; ch++ {
+		buf := h.outputs[ch][:n]
+		clear(buf)
+		ctx.Output = append(ctx.Output, buf)
+	}
+	ctx.ResetBuses()
+	for _, channels := range h.inputBuses {
+		ctx.AddInputBus(channels, 0)
+	}
+	for _, channels := range h.outputBuses {
+		ctx.AddOutputBus(channels)
+	}
+
+	transport := ctx.Transport
+	transport.IsPlaying = true
+	transport.HasTempo = true
+	transport.Tempo = h.config.Tempo
+	transport.HasTimeSignature = true
+	transport.TimeSigNumerator, transport.TimeSigDenominator = 4, 4
+	transport.HasMusicalTime = true
+	transport.ProjectTimeMusic = float64(h.position) / h.config.SampleRate * h.config.Tempo / 60
+	transport.ProjectTimeSamples = h.position
+	transport.ContinuousTimeSamples = h.position
+
+	ctx.ResetParameterChanges()
+	ctx.ResetOutputParameterChanges()
+	for _, change := range changes {
+		if change.SampleOffset >= 0 && change.SampleOffset < n {
+			ctx.AddParameterChange(change.ParamID, change.Value, change.SampleOffset)
+		}
+	}
+
+	if !h.config.KeepDenormals {
+		mode := denormal.Enable()
+		defer denormal.Restore(mode)
+	}
+	if ctx.HasParameterChanges() {
+		ctx.SortParameterChanges()
+		h.processSampleAccurate(n)
+	} else {
+		h.processBlock()
+	}
+
+	for ch := 0; ch < len(output) && ch < h.numOutputs; ch++ {
+		copy(output[ch], h.outputs[ch][:n])
+	}
+	h.position += int64(n)
+	return nil
+}
+
+// blockLength returns the number of frames in a block
+func blockLength(input, output [][]float32) int {
+	if len(output) > 0 {
+		return len(output[0])
+	}
+	if len(input) > 0 {
+		return len(input[0])
+	}
+	return 0
+}
+
+// processSampleAccurate splits the block at parameter changes, as the VST3
+// wrapper does
+func (h *Host) processSampleAccurate(n int) {
+	ctx := h.ctx
+	origInput, origOutput := ctx.Input, ctx.Output
+	lastOffset := 0
+
+	for _, change := range ctx.GetParameterChanges() {
+		if change.SampleOffset > lastOffset {
+			ctx.Input = subSlices(origInput, lastOffset, change.SampleOffset)
+			ctx.Output = subSlices(origOutput, lastOffset, change.SampleOffset)
+			ctx.SetBlockOffset(lastOffset)
+			h.processBlock()
+			lastOffset = change.SampleOffset
+		}
+		ctx.ApplyParameterChange(change)
+	}
+
+	if lastOffset < n {
+		ctx.Input = subSlices(origInput, lastOffset, n)
+		ctx.Output = subSlices(origOutput, lastOffset, n)
+		ctx.SetBlockOffset(lastOffset)
+		h.processBlock()
+	}
+
+	ctx.Input, ctx.Output = origInput, origOutput
+	ctx.SetBlockOffset(0)
+}
+
+// subSlices returns each channel sliced to [start, end)
+func subSlices(channels [][]float32, start, end int) [][]float32 {
+	result := make([][]float32, len(channels))
+	for i, ch := range channels {
+		result[i] = ch[start:end]
+	}
+	return result
+}
+
+// processBlock captures parameters and runs the processor
+func (h *Host) processBlock() {
+	h.ctx.CaptureParams()
+	h.ctx.AdvanceSmoothing()
+	if h.bypass != nil {
+		h.bypass.Process(h.ctx, h.processAudio)
+		return
+	}
+	h.processor.ProcessAudio(h.ctx)
+}
@@ -0,0 +1,461 @@
+package host
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/audiofile"
+	"github.com/justyntemme/vst3go/pkg/dsp/denormal"
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	fwplugin "github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// testPlugin is a stereo gain with optional latency, tail, extra buses and
+// bypass parameter
+type testPlugin struct {
+	latency int
+	tail    int
+	buses   *bus.Configuration
+	bypass  bool
+}
+
+func (p *testPlugin) GetInfo() fwplugin.Info {
+	return fwplugin.Info{ID: "com.vst3go.test.hostgain", Name: "Host Gain", Category: "Fx"}
+}
+
+func (p *testPlugin) CreateProcessor() plugin.Processor {
+	params := param.NewRegistry()
+	params.Add(param.New(0, "Gain").ShortName("Gn").Range(0, 2).Default(1).Build())
+	if p.bypass {
+		params.Add(param.BypassParameter(1, "Bypass").Bypass().Build())
+	}
+	buses := p.buses
+	if buses == nil {
+		buses = bus.NewStereoConfiguration()
+	}
+	return &testProcessor{
+		params:  params,
+		buses:   buses,
+		latency: p.latency,
+		tail:    p.tail,
+	}
+}
+
+type testProcessor struct {
+	params  *param.Registry
+	buses   *bus.Configuration
+	latency int
+	tail    int
+	lines   [2][]float32
+	pos     int
+}
+
+func (p *testProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	for ch := range p.lines {
+		p.lines[ch] = make([]float32, p.latency+1)
+	}
+	return nil
+}
+
+func (p *testProcessor) ProcessAudio(ctx *process.Context) {
+	gain := float32(ctx.ParamPlain(0))
+	for i := 0; i < ctx.NumSamples(); i++ {
+		for ch := range ctx.Output {
+			line := p.lines[ch]
+			line[p.pos] = ctx.Input[ch][i]
+			ctx.Output[ch][i] = gain * line[(p.pos+1)%len(line)]
+		}
+		p.pos = (p.pos + 1) % len(p.lines[0])
+	}
+}
+
+func (p *testProcessor) GetParameters() *param.Registry { return p.params }
+func (p *testProcessor) GetBuses() *bus.Configuration   { return p.buses }
+func (p *testProcessor) GetLatencySamples() int32       { return int32(p.latency) }
+func (p *testProcessor) GetTailSamples() int32          { return int32(p.tail) }
+
+func (p *testProcessor) SetActive(active bool) error {
+	for ch := range p.lines {
+		clear(p.lines[ch])
+	}
+	p.pos = 0
+	return nil
+}
+
+// ones returns stereo input of constant 1
+func ones(frames int) [][]float32 {
+	input := makeChannels(2, frames)
+	for _, ch := range input {
+		for i := range ch {
+			ch[i] = 1
+		}
+	}
+	return input
+}
+
+func TestRenderSampleAccurateAutomation(t *testing.T) {
+	h, err := New(&testPlugin{}, Config{SampleRate: 48000, BlockSize: 512})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := ParseAutomation(strings.NewReader(`{"points": [
+		{"param": "gain", "sample": 700, "plain": 2},
+		{"param": 0, "time": 0.03125, "value": 0}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := h.Render(ones(2048), a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || len(out[0]) != 2048 {
+		t.Fatalf("output %dx%d", len(out), len(out[0]))
+	}
+	for _, ch := range out {
+		if ch[699] != 1 || ch[700] != 2 || ch[1499] != 2 || ch[1500] != 0 {
+			t.Errorf("changes not sample accurate: %g %g %g %g", ch[699], ch[700], ch[1499], ch[1500])
+		}
+	}
+}
+
+func TestProcessBlockBusLayout(t *testing.T) {
+	buses := bus.NewBuilder().
+		WithStereoInput("Main").
+		WithAuxInput("Inactive", 2).
+		WithAuxInput("Key", 1).
+		SetBusActive(bus.MediaTypeAudio, bus.DirectionInput, 2, true).
+		WithStereoOutput("Output").
+		MustBuild()
+	h, err := New(&testPlugin{buses: buses}, Config{BlockSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.NumInputs() != 3 {
+		t.Fatalf("NumInputs = %d, want 3", h.NumInputs())
+	}
+
+	input := makeChannels(3, 64)
+	input[2][0] = 0.5
+	if err := h.ProcessBlock(input, makeChannels(2, 64), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each active bus is recorded, so processors can address the key input
+	ctx := h.ctx
+	if ctx.NumInputBuses() != 2 || ctx.NumOutputBuses() != 1 {
+		t.Fatalf("Buses = %d in, %d out, want 2 and 1", ctx.NumInputBuses(), ctx.NumOutputBuses())
+	}
+	if key := ctx.InputBus(1); len(key) != 1 || key[0][0] != 0.5 {
+		t.Errorf("Key bus = %v channels, want the mono key input", len(key))
+	}
+}
+
+func TestRenderRamp(t *testing.T) {
+	h, err := New(&testPlugin{}, Config{BlockSize: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Automation{}
+	zero, one := 0.0, 1.0
+	start, end := int64(0), int64(1024)
+	a.Points = append(a.Points,
+		Point{Param: "Gain", Sample: &start, Value: &zero},
+		Point{Param: "Gain", Sample: &end, Value: &one, Ramp: true},
+	)
+
+	out, err := h.Render(ones(2048), a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ramps move once per block; normalized 0.5 is a gain of 1
+	if out[0][0] != 0 || out[0][512] != 1 || out[0][1100] != 2 {
+		t.Errorf("ramp values %g %g %g", out[0][0], out[0][512], out[0][1100])
+	}
+}
+
+func TestRenderLatencyAndTail(t *testing.T) {
+	input := makeChannels(2, 1000)
+	for i := range input[0] {
+		input[0][i] = float32(i)
+		input[1][i] = -float32(i)
+	}
+
+	h, err := New(&testPlugin{latency: 100, tail: 300}, Config{BlockSize: 128, CompensateLatency: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := h.Render(input, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out[0]) != 1000 {
+		t.Fatalf("compensated output has %d samples, want 1000", len(out[0]))
+	}
+	for i := range out[0] {
+		if out[0][i] != input[0][i] || out[1][i] != input[1][i] {
+			t.Fatalf("sample %d not aligned: %g", i, out[0][i])
+		}
+	}
+
+	h, err = New(&testPlugin{latency: 100, tail: 300}, Config{BlockSize: 128, RenderTail: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = h.Render(input, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out[0]) != 1300 || out[0][100] != 0 || out[0][1099] != 999 {
+		t.Errorf("tail render has %d samples", len(out[0]))
+	}
+}
+
+func TestRenderFile(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.wav")
+	outPath := filepath.Join(dir, "out.aiff")
+
+	mono := audiofile.NewAudio(1, 3000, 48000)
+	for i := range mono.Data[0] {
+		mono.Data[0][i] = 0.25
+	}
+	if err := audiofile.Save(inPath, mono, audiofile.Float32); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := New(&testPlugin{}, Config{BlockSize: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetParameter(0, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RenderFile(inPath, outPath, audiofile.Float32, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := audiofile.Load(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.NumChannels() != 2 || out.Len() != 3000 || out.SampleRate != 48000 {
+		t.Fatalf("output %d channels, %d frames at %g Hz", out.NumChannels(), out.Len(), out.SampleRate)
+	}
+	// A mono file feeds both inputs
+	if out.Data[0][2999] != 0.125 || out.Data[1][0] != 0.125 {
+		t.Errorf("output %g %g, want 0.125", out.Data[0][2999], out.Data[1][0])
+	}
+}
+
+func TestAutomationErrors(t *testing.T) {
+	if _, err := ParseAutomation(strings.NewReader(`{"points": [{"param": "Gain", "level": 1}]}`)); !errors.Is(err, ErrInvalidAutomation) {
+		t.Errorf("unknown field: %v", err)
+	}
+
+	h, err := New(&testPlugin{}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range []string{
+		`{"points": [{"param": "Level", "value": 1}]}`,
+		`{"points": [{"param": "Gain"}]}`,
+		`{"points": [{"param": "Gain", "sample": -1, "value": 1}]}`,
+	} {
+		a, err := ParseAutomation(strings.NewReader(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := h.Render(ones(16), a); !errors.Is(err, ErrInvalidAutomation) {
+			t.Errorf("%s: expected ErrInvalidAutomation, got %v", doc, err)
+		}
+	}
+
+	if _, err := h.FindParameter("Gn"); err != nil {
+		t.Errorf("short name lookup: %v", err)
+	}
+	if err := h.SetParameter(9, 1); !errors.Is(err, ErrUnknownParameter) {
+		t.Errorf("SetParameter on missing ID: %v", err)
+	}
+}
+
+func TestRunCommandLine(t *testing.T) {
+	plugin.Register(&testPlugin{})
+	if p, err := FindPlugin("host gain"); err != nil || p.GetInfo().ID != "com.vst3go.test.hostgain" {
+		t.Fatalf("FindPlugin: %v", err)
+	}
+
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.wav")
+	outPath := filepath.Join(dir, "out.wav")
+	if err := audiofile.Save(inPath, &audiofile.Audio{Data: ones(1000), SampleRate: 48000}, audiofile.PCM24); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout strings.Builder
+	metersPath := filepath.Join(dir, "meters.csv")
+	err := Run([]string{"-in", inPath, "-out", outPath, "-plugin", "com.vst3go.test.hostgain",
+		"-param", "Gain=0.5", "-encoding", "pcm16", "-block", "64",
+		"-meters", metersPath, "-meter", "peak", "-meter", "Gain"}, &stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log, err := os.ReadFile(metersPath); err != nil || !strings.HasPrefix(string(log), "time,peak,Gain\n") {
+		t.Errorf("meter log: %v\n%s", err, log)
+	}
+	r, err := audiofile.Open(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if info := r.Info(); info.Encoding != audiofile.PCM16 || info.Frames != 1000 {
+		t.Errorf("output %v with %d frames", info.Encoding, info.Frames)
+	}
+
+	stdout.Reset()
+	if err := Run([]string{"-list"}, &stdout); err != nil || !strings.Contains(stdout.String(), "Gain") {
+		t.Errorf("list: %v\n%s", err, stdout.String())
+	}
+	if err := Run([]string{"-in", inPath}, &stdout); err == nil {
+		t.Error("missing -out should fail")
+	}
+}
+
+// manualBypassProcessor bypasses itself, so the host must leave it alone
+type manualBypassProcessor struct {
+	*testProcessor
+}
+
+func (p *manualBypassProcessor) HandlesBypass() bool { return true }
+
+func TestRenderHostBypass(t *testing.T) {
+	// Bypass after 1000 samples; the gain of 2 makes processed audio stand out
+	a, err := ParseAutomation(strings.NewReader(`{"points": [
+		{"param": "Gain", "sample": 0, "plain": 2},
+		{"param": "Bypass", "sample": 1000, "value": 1}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := New(&testPlugin{latency: 50, bypass: true}, Config{SampleRate: 1000, BlockSize: 128, CompensateLatency: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := h.Render(ones(2000), a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The crossfade takes DefaultBypassRampTime; the dry signal is delayed
+	// by the latency like the processed one, so the level goes straight
+	// from 2 to 1
+	fadeEnd := 1000 - 50 + int(process.DefaultBypassRampTime*1000)
+	for _, i := range []int{100, 900, fadeEnd + 1, 1999} {
+		want := float32(2)
+		if i > fadeEnd {
+			want = 1
+		}
+		if out[0][i] != want {
+			t.Errorf("out[%d] = %g, want %g", i, out[0][i], want)
+		}
+	}
+	for i := 900; i < 2000; i++ {
+		if out[0][i] < 1 || out[0][i] > 2 {
+			t.Fatalf("out[%d] = %g, outside the crossfade", i, out[0][i])
+		}
+	}
+
+	// A processor that handles bypass itself gets the parameter unchanged
+	h.processor = &manualBypassProcessor{h.processor.(*testProcessor)}
+	if out, err = h.Render(ones(2000), a); err != nil {
+		t.Fatal(err)
+	}
+	if h.bypass != nil || out[0][1999] != 2 {
+		t.Errorf("Manual bypass processor was bypassed by the host: out = %g", out[0][1999])
+	}
+}
+
+// rateProcessor follows sample rate changes without being initialized again
+type rateProcessor struct {
+	*testProcessor
+	inits int
+	rates []float64
+}
+
+func (p *rateProcessor) Initialize(sampleRate float64, maxBlockSize int32) error {
+	p.inits++
+	return p.testProcessor.Initialize(sampleRate, maxBlockSize)
+}
+
+func (p *rateProcessor) SampleRateChanged(sampleRate float64, maxBlockSize int32) error {
+	p.rates = append(p.rates, sampleRate)
+	return nil
+}
+
+func TestSetSampleRate(t *testing.T) {
+	h, err := New(&testPlugin{}, Config{SampleRate: 44100, BlockSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := &rateProcessor{testProcessor: h.processor.(*testProcessor)}
+	h.processor = rp
+
+	if err := h.SetSampleRate(96000); err != nil {
+		t.Fatal(err)
+	}
+	if rp.inits != 0 || len(rp.rates) != 1 || rp.rates[0] != 96000 {
+		t.Errorf("Initialize called %d times, SampleRateChanged with %v", rp.inits, rp.rates)
+	}
+	if h.Config().SampleRate != 96000 || h.ctx.SampleRate != 96000 {
+		t.Errorf("Sample rate %g, context %g after the change", h.Config().SampleRate, h.ctx.SampleRate)
+	}
+
+	// Processors without the hook are initialized again
+	h.processor = rp.testProcessor
+	if err := h.SetSampleRate(48000); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rate cannot change while processing
+	if err := h.Activate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetSampleRate(44100); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("SetSampleRate while active: %v, want ErrInvalidConfig", err)
+	}
+	if err := h.SetSampleRate(0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("SetSampleRate(0): %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestFlushToZero(t *testing.T) {
+	const tiny = 1e-39 // Subnormal in float32
+	for _, keep := range []bool{false, true} {
+		h, err := New(&testPlugin{}, Config{BlockSize: 4, KeepDenormals: keep})
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := [][]float32{{tiny, tiny, tiny, tiny}, {tiny, tiny, tiny, tiny}}
+		out := [][]float32{make([]float32, 4), make([]float32, 4)}
+		if err := h.ProcessBlock(in, out, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		flushed := out[0][3] == 0
+		if want := denormal.Supported() && !keep; flushed != want {
+			t.Errorf("KeepDenormals %v: output %g, want flushed %v", keep, out[0][3], want)
+		}
+	}
+	if denormal.Enabled() {
+		t.Error("flush-to-zero left on after ProcessBlock")
+	}
+}
@@ -0,0 +1,311 @@
+package host
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/dsp/analysis"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+)
+
+// ErrUnknownMeter is returned for meter names that are neither a host meter
+// nor a plugin parameter
+var ErrUnknownMeter = errors.New("unknown meter")
+
+// Host meters measured on the rendered output. Any other meter name selects
+// a plugin parameter, such as a compressor's read-only "Gain Reduction",
+// logged in plain units.
+const (
+	MeterMomentary  = "momentary"  // Momentary loudness (400 ms) in LUFS
+	MeterShortTerm  = "short-term" // Short-term loudness (3 s) in LUFS
+	MeterIntegrated = "integrated" // Gated loudness since the start in LUFS
+	MeterTruePeak   = "true-peak"  // Highest true peak since the previous row in dBTP
+	MeterPeak       = "peak"       // Highest sample peak since the previous row in dBFS
+)
+
+// LogFormat is the file format of a meter log
+type LogFormat int
+
+const (
+	LogCSV  LogFormat = iota // Header row, then one row per entry
+	LogJSON                  // Array of objects keyed by meter name
+)
+
+// String returns the name of the format
+func (f LogFormat) String() string {
+	switch f {
+	case LogCSV:
+		return "CSV"
+	case LogJSON:
+		return "JSON"
+	default:
+		return "Unknown"
+	}
+}
+
+// LogFormatFromPath returns the log format for a file extension
+func LogFormatFromPath(path string) (LogFormat, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return LogCSV, true
+	case ".json":
+		return LogJSON, true
+	}
+	return LogCSV, false
+}
+
+// MeterLog records meters while the host renders, so loudness and gain
+// reduction can be charted against the source. Each entry holds the output
+// time in seconds at the end of the measured stretch, then the meters in
+// the order given. Loudness is -Inf (null in JSON) until enough audio has
+// been measured.
+type MeterLog struct {
+	Writer io.Writer
+	Format LogFormat
+
+	// Meters names the columns: host meters and parameters by ID, name or
+	// short name. Empty logs momentary and short-term loudness, true peak
+	// and every read-only parameter.
+	Meters []string
+
+	// Interval is the time between entries in seconds; zero logs every
+	// block
+	Interval float64
+}
+
+// SetMeterLog logs meters during the following renders; nil turns logging
+// off
+func (h *Host) SetMeterLog(log *MeterLog) {
+	h.meterLog = log
+}
+
+// column is one resolved meter
+type column struct {
+	name  string
+	meter string           // Host meter, empty for parameters
+	param *param.Parameter // Logged parameter
+}
+
+// meterLogger measures one render for a MeterLog
+type meterLogger struct {
+	log        *MeterLog
+	columns    []column
+	sampleRate float64
+	channels   int
+	csv        *csv.Writer
+	record     []string
+	keys       [][]byte // JSON-encoded column names
+	rows       int
+
+	// Loudness is measured in 100 ms chunks, the gating block hop
+	lufs     *analysis.LUFSMeter
+	chunk    []float64
+	chunkPos int
+
+	detectors []analysis.TruePeakDetector
+	truePeak  float64 // Linear, since the previous row
+	peak      float64 // Linear, since the previous row
+
+	frames int64 // Output frames measured
+	next   int64 // Frame of the next row
+	step   int64 // Frames between rows
+}
+
+// start resolves the log's meters against the host for a render
+func (l *MeterLog) start(h *Host) (*meterLogger, error) {
+	if l == nil {
+		return nil, nil
+	}
+	if l.Writer == nil {
+		return nil, errors.New("meter log has no writer")
+	}
+
+	m := &meterLogger{
+		log:        l,
+		sampleRate: h.config.SampleRate,
+		channels:   h.numOutputs,
+		detectors:  make([]analysis.TruePeakDetector, h.numOutputs),
+		step:       int64(math.Round(l.Interval * h.config.SampleRate)),
+	}
+	m.next = m.step
+
+	names := l.Meters
+	if len(names) == 0 {
+		names = []string{MeterMomentary, MeterShortTerm, MeterTruePeak}
+		if params := h.Parameters(); params != nil {
+			for _, p := range params.All() {
+				if p.Flags&param.IsReadOnly != 0 {
+					names = append(names, strconv.FormatUint(uint64(p.ID), 10))
+				}
+			}
+		}
+	}
+
+	for _, name := range names {
+		switch key := strings.ToLower(name); key {
+		case MeterMomentary, MeterShortTerm, MeterIntegrated, MeterTruePeak, MeterPeak:
+			m.columns = append(m.columns, column{name: key, meter: key})
+			if key != MeterTruePeak && key != MeterPeak && m.lufs == nil && m.channels > 0 {
+				m.lufs = analysis.NewLUFSMeter(m.sampleRate, m.channels)
+				m.chunk = make([]float64, int(0.1*m.sampleRate)*m.channels)
+			}
+		default:
+			p, err := h.FindParameter(name)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s is neither a host meter nor a parameter", ErrUnknownMeter, name)
+			}
+			m.columns = append(m.columns, column{name: p.Name, param: p})
+		}
+	}
+
+	if err := m.header(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// header writes the column names, or the opening of the JSON array
+func (m *meterLogger) header() error {
+	switch m.log.Format {
+	case LogCSV:
+		m.csv = csv.NewWriter(m.log.Writer)
+		m.record = make([]string, len(m.columns)+1)
+		m.record[0] = "time"
+		for i, c := range m.columns {
+			m.record[i+1] = c.name
+		}
+		m.csv.Write(m.record)
+		return m.csv.Error()
+	case LogJSON:
+		m.keys = make([][]byte, len(m.columns))
+		for i, c := range m.columns {
+			m.keys[i], _ = json.Marshal(c.name)
+		}
+		_, err := io.WriteString(m.log.Writer, "[")
+		return err
+	default:
+		return fmt.Errorf("unknown meter log format %d", m.log.Format)
+	}
+}
+
+// block measures a block of rendered output and writes an entry when one
+// is due
+func (m *meterLogger) block(output [][]float32) error {
+	n := 0
+	if len(output) > 0 {
+		n = len(output[0])
+	}
+	for i := 0; i < n; i++ {
+		for ch, data := range output {
+			x := float64(data[i])
+			m.peak = max(m.peak, math.Abs(x))
+			m.truePeak = max(m.truePeak, m.detectors[ch].Process(x))
+			if m.lufs != nil {
+				m.chunk[m.chunkPos] = x
+				m.chunkPos++
+			}
+		}
+		if m.lufs != nil && m.chunkPos == len(m.chunk) {
+			m.lufs.Process(m.chunk)
+			m.chunkPos = 0
+		}
+	}
+	m.frames += int64(n)
+
+	if m.frames < m.next || n == 0 {
+		return nil
+	}
+	m.next = m.frames + m.step
+	return m.write()
+}
+
+// value returns the current reading of a column
+func (m *meterLogger) value(c column) float64 {
+	switch c.meter {
+	case "":
+		return c.param.GetPlainValue()
+	case MeterMomentary:
+		return m.lufs.GetMomentaryLUFS()
+	case MeterShortTerm:
+		return m.lufs.GetShortTermLUFS()
+	case MeterIntegrated:
+		return m.lufs.GetIntegratedLUFS()
+	case MeterTruePeak:
+		return linearToDB(m.truePeak)
+	default:
+		return linearToDB(m.peak)
+	}
+}
+
+// write writes one entry and starts the next peak span
+func (m *meterLogger) write() error {
+	seconds := float64(m.frames) / m.sampleRate
+
+	var err error
+	switch m.log.Format {
+	case LogCSV:
+		m.record[0] = strconv.FormatFloat(seconds, 'f', 4, 64)
+		for i, c := range m.columns {
+			m.record[i+1] = strconv.FormatFloat(m.value(c), 'f', 4, 64)
+		}
+		m.csv.Write(m.record)
+		err = m.csv.Error()
+	case LogJSON:
+		buf := make([]byte, 0, 32*(len(m.columns)+1))
+		if m.rows > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, "\n  {\"time\":"...)
+		buf = strconv.AppendFloat(buf, seconds, 'f', 4, 64)
+		for i, c := range m.columns {
+			buf = append(buf, ',')
+			buf = append(buf, m.keys[i]...)
+			buf = append(buf, ':')
+			if v := m.value(c); math.IsInf(v, 0) || math.IsNaN(v) {
+				buf = append(buf, "null"...)
+			} else {
+				buf = strconv.AppendFloat(buf, v, 'f', 4, 64)
+			}
+		}
+		buf = append(buf, '}')
+		_, err = m.log.Writer.Write(buf)
+	}
+
+	m.rows++
+	m.peak = 0
+	m.truePeak = 0
+	return err
+}
+
+// finish writes the entry for any output measured since the last one and
+// closes the log
+func (m *meterLogger) finish() error {
+	if m.frames > m.next-m.step && m.frames > 0 {
+		if err := m.write(); err != nil {
+			return err
+		}
+	}
+	switch m.log.Format {
+	case LogCSV:
+		m.csv.Flush()
+		return m.csv.Error()
+	default:
+		_, err := io.WriteString(m.log.Writer, "\n]\n")
+		return err
+	}
+}
+
+// linearToDB converts a linear level to dB, -Inf for silence
+func linearToDB(level float64) float64 {
+	if level > 0 {
+		return 20 * math.Log10(level)
+	}
+	return math.Inf(-1)
+}
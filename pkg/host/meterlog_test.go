@@ -0,0 +1,116 @@
+package host
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sine returns a stereo 1 kHz sine at 48 kHz
+func sine(frames int, amplitude float32) [][]float32 {
+	input := makeChannels(2, frames)
+	for i := range input[0] {
+		x := amplitude * float32(math.Sin(2*math.Pi*1000*float64(i)/48000))
+		input[0][i], input[1][i] = x, x
+	}
+	return input
+}
+
+func TestMeterLogCSV(t *testing.T) {
+	h, err := New(&testPlugin{}, Config{SampleRate: 48000, BlockSize: 512})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	h.SetMeterLog(&MeterLog{Writer: &buf, Meters: []string{"peak", "Gn"}, Interval: 0.1})
+
+	a, err := ParseAutomation(strings.NewReader(`{"points": [{"param": "Gain", "time": 0.5, "plain": 2}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Render(sine(48000, 0.25), a); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(records[0], ","); got != "time,peak,Gain" {
+		t.Errorf("Header = %q", got)
+	}
+	// An entry every 4800 samples, the last at the end of the render
+	rows := records[1:]
+	if len(rows) != 10 {
+		t.Fatalf("%d entries, want 10", len(rows))
+	}
+	if rows[len(rows)-1][0] != "1.0000" {
+		t.Errorf("Last entry at %s s, want 1", rows[len(rows)-1][0])
+	}
+	for _, row := range rows {
+		at, _ := strconv.ParseFloat(row[0], 64)
+		peak, _ := strconv.ParseFloat(row[1], 64)
+		wantGain, wantPeak := "1.0000", -12.04
+		if at > 0.55 {
+			wantGain, wantPeak = "2.0000", -6.02
+		}
+		if at < 0.45 || at > 0.55 {
+			if row[2] != wantGain || math.Abs(peak-wantPeak) > 0.1 {
+				t.Errorf("At %s s: peak %s dB, gain %s; want %.2f dB, gain %s", row[0], row[1], row[2], wantPeak, wantGain)
+			}
+		}
+	}
+}
+
+func TestMeterLogJSON(t *testing.T) {
+	h, err := New(&testPlugin{}, Config{SampleRate: 48000, BlockSize: 480})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	h.SetMeterLog(&MeterLog{Writer: &buf, Format: LogJSON, Meters: []string{MeterMomentary, MeterTruePeak}})
+	if _, err := h.Render(sine(48000, 0.5), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []map[string]*float64
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("%v in %s", err, buf.String())
+	}
+	// An entry every block
+	if len(entries) != 100 {
+		t.Fatalf("%d entries, want 100", len(entries))
+	}
+
+	// Momentary loudness needs 400 ms of audio
+	if entries[0]["momentary"] != nil {
+		t.Errorf("Momentary loudness after one block = %g, want null", *entries[0]["momentary"])
+	}
+	last := entries[len(entries)-1]
+	if *last["time"] != 1 {
+		t.Errorf("Last entry at %g s, want 1", *last["time"])
+	}
+	// A 1 kHz sine at -6 dBFS in both channels reads about -6 LUFS
+	if m := last["momentary"]; m == nil || math.Abs(*m+6) > 0.5 {
+		t.Errorf("Momentary loudness = %v, want about -6 LUFS", m)
+	}
+	if tp := last["true-peak"]; tp == nil || math.Abs(*tp+6.02) > 0.1 {
+		t.Errorf("True peak = %v, want -6.02 dBTP", tp)
+	}
+}
+
+func TestMeterLogUnknownMeter(t *testing.T) {
+	h, err := New(&testPlugin{}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetMeterLog(&MeterLog{Writer: &bytes.Buffer{}, Meters: []string{"Loudness War"}})
+	if _, err := h.Render(ones(64), nil); !errors.Is(err, ErrUnknownMeter) {
+		t.Errorf("err = %v, want ErrUnknownMeter", err)
+	}
+}
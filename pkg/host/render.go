@@ -0,0 +1,196 @@
+package host
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/justyntemme/vst3go/pkg/audiofile"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+// maxTailSeconds caps the rendered tail, since plugins may report an
+// infinite tail
+const maxTailSeconds = 30
+
+// Render restarts the processor, runs input through it with the automation
+// applied and returns the output, one slice per output channel. automation
+// may be nil.
+func (h *Host) Render(input [][]float32, automation *Automation) ([][]float32, error) {
+	frames := 0
+	if len(input) > 0 {
+		frames = len(input[0])
+	}
+
+	pos := 0
+	read := func(dst [][]float32) (int, error) {
+		n := min(len(dst[0]), frames-pos)
+		for ch := range dst {
+			copy(dst[ch][:n], input[ch][pos:pos+n])
+		}
+		pos += n
+		return n, nil
+	}
+
+	output := make([][]float32, h.numOutputs)
+	for ch := range output {
+		output[ch] = make([]float32, 0, frames)
+	}
+	write := func(src [][]float32) error {
+		for ch := range output {
+			output[ch] = append(output[ch], src[ch]...)
+		}
+		return nil
+	}
+
+	if err := h.run(len(input), read, write, automation); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// RenderFile streams a WAV or AIFF file through the processor and writes the
+// result to outPath in the format given by its extension. Input at another
+// sample rate is resampled to the host rate first.
+func (h *Host) RenderFile(inPath, outPath string, enc audiofile.Encoding, automation *Automation) error {
+	format, ok := audiofile.FormatFromPath(outPath)
+	if !ok {
+		return fmt.Errorf("%w: unknown extension in %s", audiofile.ErrUnsupportedFormat, outPath)
+	}
+
+	r, err := audiofile.Open(inPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	channels := r.Info().Channels
+	read := func(dst [][]float32) (int, error) {
+		n, err := r.Read(dst)
+		if err == io.EOF {
+			return n, nil
+		}
+		return n, err
+	}
+
+	if r.Info().SampleRate != h.config.SampleRate {
+		audio, err := audiofile.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		audio = audio.Resample(h.config.SampleRate)
+
+		pos := 0
+		read = func(dst [][]float32) (int, error) {
+			n := min(len(dst[0]), audio.Len()-pos)
+			for ch := range dst {
+				copy(dst[ch][:n], audio.Data[ch][pos:pos+n])
+			}
+			pos += n
+			return n, nil
+		}
+	}
+
+	w, err := audiofile.Create(outPath, format, enc, h.config.SampleRate, h.numOutputs)
+	if err != nil {
+		return err
+	}
+	if err := h.run(channels, read, w.Write, automation); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// run restarts the processor and pulls blocks from read until it returns no
+// frames, passing each processed block to write. A mono source feeds every
+// input channel. With latency compensation the first latency samples are
+// dropped and as many extra samples rendered at the end, so the output lines
+// up with the input; with RenderTail the plugin's tail is rendered as well.
+// A meter log set with SetMeterLog measures each written block.
+func (h *Host) run(channels int, read func([][]float32) (int, error), write func([][]float32) error, automation *Automation) error {
+	tl, err := automation.resolve(h)
+	if err != nil {
+		return err
+	}
+	meters, err := h.meterLog.start(h)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Deactivate(); err != nil {
+		return err
+	}
+	if err := h.Activate(); err != nil {
+		return err
+	}
+	defer h.Deactivate()
+
+	var skip, extra int64
+	if h.config.CompensateLatency {
+		skip = int64(max(0, h.processor.GetLatencySamples()))
+	}
+	extra = skip
+	if h.config.RenderTail {
+		tail := int64(max(0, h.processor.GetTailSamples()))
+		extra += min(tail, int64(maxTailSeconds*h.config.SampleRate))
+	}
+
+	block := h.config.BlockSize
+	source := makeChannels(channels, block)
+	output := makeChannels(h.numOutputs, block)
+	input := make([][]float32, h.numInputs)
+	for ch := range input {
+		switch {
+		case ch < channels:
+			input[ch] = source[ch]
+		case channels == 1:
+			input[ch] = source[0]
+		default:
+			input[ch] = make([]float32, block)
+		}
+	}
+	var changes []process.ParameterChange
+
+	inputDone := false
+	for {
+		n := 0
+		if !inputDone {
+			if n, err = read(source); err != nil {
+				return err
+			}
+			inputDone = n == 0
+		}
+		if inputDone {
+			if extra == 0 {
+				break
+			}
+			n = int(min(int64(block), extra))
+			extra -= int64(n)
+			for _, ch := range source {
+				clear(ch[:n])
+			}
+		}
+
+		changes = tl.changes(h.position, n, changes[:0])
+		if err := h.ProcessBlock(subSlices(input, 0, n), subSlices(output, 0, n), changes); err != nil {
+			return err
+		}
+
+		start := int(min(skip, int64(n)))
+		skip -= int64(start)
+		if start < n {
+			if err := write(subSlices(output, start, n)); err != nil {
+				return err
+			}
+			if meters != nil {
+				if err := meters.block(subSlices(output, start, n)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if meters != nil {
+		return meters.finish()
+	}
+	return nil
+}
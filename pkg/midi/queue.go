@@ -39,6 +39,13 @@ func (q *EventQueue) AddMultiple(events []Event) {
 }
 
 func (q *EventQueue) GetEventsInRange(startSample, endSample int32) []Event {
+	return q.AppendEventsInRange(nil, startSample, endSample)
+}
+
+// AppendEventsInRange appends the events in [startSample, endSample) to dst
+// and returns the result. It only allocates when dst is too small, so the
+// audio thread can reuse one slice across blocks.
+func (q *EventQueue) AppendEventsInRange(dst []Event, startSample, endSample int32) []Event {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
 
@@ -51,7 +58,7 @@ func (q *EventQueue) GetEventsInRange(startSample, endSample int32) []Event {
 	}
 
 	if len(q.events) == 0 {
-		return nil
+		return dst
 	}
 
 	// Binary search for start position
@@ -60,7 +67,7 @@ func (q *EventQueue) GetEventsInRange(startSample, endSample int32) []Event {
 	})
 
 	if startIdx >= len(q.events) {
-		return nil
+		return dst
 	}
 
 	// Find end position
@@ -69,14 +76,7 @@ func (q *EventQueue) GetEventsInRange(startSample, endSample int32) []Event {
 		endIdx++
 	}
 
-	if startIdx == endIdx {
-		return nil
-	}
-
-	// Return a copy of the relevant events
-	result := make([]Event, endIdx-startIdx)
-	copy(result, q.events[startIdx:endIdx])
-	return result
+	return append(dst, q.events[startIdx:endIdx]...)
 }
 
 func (q *EventQueue) GetAllEvents() []Event {
@@ -96,6 +96,18 @@ func (q *EventQueue) GetAllEvents() []Event {
 	return result
 }
 
+// AppendAllEvents appends every queued event, in offset order, to dst and
+// returns the result without allocating when dst has room
+func (q *EventQueue) AppendAllEvents(dst []Event) []Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.sorted {
+		q.sortEvents()
+	}
+	return append(dst, q.events...)
+}
+
 func (q *EventQueue) Clear() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -221,10 +233,22 @@ func (b *EventBuffer) GetInputEvents(startSample, endSample int32) []Event {
 	return b.inputQueue.GetEventsInRange(startSample, endSample)
 }
 
+// AppendInputEvents appends the input events in [startSample, endSample)
+// to dst without allocating when dst has room
+func (b *EventBuffer) AppendInputEvents(dst []Event, startSample, endSample int32) []Event {
+	return b.inputQueue.AppendEventsInRange(dst, startSample, endSample)
+}
+
 func (b *EventBuffer) GetOutputEvents() []Event {
 	return b.outputQueue.GetAllEvents()
 }
 
+// AppendOutputEvents appends the output events to dst without allocating
+// when dst has room
+func (b *EventBuffer) AppendOutputEvents(dst []Event) []Event {
+	return b.outputQueue.AppendAllEvents(dst)
+}
+
 func (b *EventBuffer) ClearInput() {
 	b.inputQueue.Clear()
 }
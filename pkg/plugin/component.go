@@ -6,45 +6,137 @@ package plugin
 import "C"
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
+	"github.com/justyntemme/vst3go/pkg/dsp/debug"
 	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	fwdebug "github.com/justyntemme/vst3go/pkg/framework/debug"
+	"github.com/justyntemme/vst3go/pkg/framework/message"
 	"github.com/justyntemme/vst3go/pkg/framework/process"
 	"github.com/justyntemme/vst3go/pkg/framework/state"
 	"github.com/justyntemme/vst3go/pkg/midi"
 	"github.com/justyntemme/vst3go/pkg/vst3"
 )
 
+// workerShutdownTimeout bounds how long Terminate waits for background jobs
+const workerShutdownTimeout = 2 * time.Second
+
 // componentImpl wraps a Processor to implement VST3 interfaces
 type componentImpl struct {
 	processor    Processor
 	processCtx   *process.Context
 	sampleRate   float64
+	processMode  process.ProcessMode
 	maxBlockSize int32
 	active       bool
 	processing   bool
+	inProcess    atomic.Bool // Guards against overlapping process calls
 	mu           sync.RWMutex
 	wrapper      *componentWrapper // Reference to wrapper for notifications
+
+	silence   silenceTracker // Consecutive samples with all inputs flagged silent
+	auditName string         // Call name in real-time safety audits (debug builds)
+
+	// Double precision support
+	processor64 Processor64 // nil if the processor only handles 32-bit audio
+	use64       bool        // current block uses 64-bit buffers
+
+	// Host bypass, nil if the processor has no bypass parameter or handles
+	// it itself. The process functions are bound once so the bypass does not
+	// allocate.
+	bypass         *process.SoftBypass
+	processAudio   func(ctx *process.Context)
+	processAudio64 func(ctx *process.Context)
+
+	// Channel slices pointing into the block's buffers for sample-accurate
+	// sub-blocks, sized in SetActive so splitting a block does not allocate
+	subInput    [][]float32
+	subOutput   [][]float32
+	subInput64  [][]float64
+	subOutput64 [][]float64
+
+	outputEvents []midi.Event // Reused each block to send output events
 }
 
 // newComponent creates a new component implementation
 func newComponent(processor Processor) *componentImpl {
 	params := processor.GetParameters()
-	return &componentImpl{
+	c := &componentImpl{
 		processor:    processor,
 		processCtx:   process.NewContext(8192, params), // Default max block size
 		maxBlockSize: 8192,
+		auditName:    fmt.Sprintf("%T.ProcessAudio", processor),
+		processAudio: processor.ProcessAudio,
+	}
+	if p64, ok := processor.(Processor64); ok {
+		c.processor64 = p64
+		c.processAudio64 = p64.ProcessAudio64
+	}
+	if ap, ok := processor.(AsyncStateProcessor); ok && ap.StateLoader() != nil {
+		// Commit under the write lock so the swap lands between process calls
+		ap.StateLoader().SetCommitter(func(commit func()) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			commit()
+		})
 	}
+	return c
+}
+
+// deliverMessage passes a controller message to the processor under the
+// write lock, so it lands between process calls
+func (c *componentImpl) deliverMessage(receiver MessageReceiver, m message.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	receiver.ReceiveMessage(m)
 }
 
 // IComponent implementation
 func (c *componentImpl) Initialize(_ interface{}) error {
+	// Detect a crashed previous session before the processor configures itself
+	if sp, ok := c.processor.(SafeModeProcessor); ok {
+		if err := sp.SafeMode().Start(); err != nil {
+			fwdebug.Warn("[SAFE_MODE] Failed to start crash sentinel: %v", err)
+		}
+	}
 	return c.processor.Initialize(48000, c.maxBlockSize) // Default sample rate
 }
 
 func (c *componentImpl) Terminate() error {
+	if ap, ok := c.processor.(AsyncStateProcessor); ok && ap.StateLoader() != nil {
+		ap.StateLoader().Cancel()
+	}
+
+	// Let background jobs finish before the plugin is unloaded
+	if wp, ok := c.processor.(WorkerProcessor); ok && wp.Workers() != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), workerShutdownTimeout)
+		err := wp.Workers().Shutdown(ctx)
+		cancel()
+		if err != nil {
+			fwdebug.Warn("[WORKER] Shutdown timed out, cancelled running jobs: %v", err)
+		}
+	}
+
+	if rp, ok := c.processor.(RemoteProcessor); ok && rp.Remote() != nil {
+		if err := rp.Remote().Close(); err != nil {
+			fwdebug.Warn("[REMOTE] Failed to close server: %v", err)
+		}
+	}
+
+	// Debug builds with the audit enabled report violations on unload
+	if debug.RealtimeAuditEnabled() && len(debug.GetAuditViolations()) > 0 {
+		fwdebug.Warn("[RT_AUDIT] %s", debug.GetAuditReport())
+	}
+
+	if sp, ok := c.processor.(SafeModeProcessor); ok {
+		return sp.SafeMode().Stop()
+	}
 	return nil
 }
 
@@ -63,6 +155,10 @@ func (c *componentImpl) GetBusCount(mediaType, direction int32) int32 {
 }
 
 func (c *componentImpl) GetBusInfo(mediaType, direction, index int32) (*vst3.BusInfo, error) {
+	if err := c.checkBusIndex(mediaType, direction, index); err != nil {
+		return nil, err
+	}
+
 	buses := c.processor.GetBuses()
 	info := buses.GetBusInfo(bus.MediaType(mediaType), bus.Direction(direction), index)
 	if info == nil {
@@ -88,7 +184,22 @@ func (c *componentImpl) GetRoutingInfo(inInfo, outInfo interface{}) error {
 	return vst3.ErrNotImplemented
 }
 
+// ActivateBus records the host's bus activation in the bus configuration so
+// the processor can see which optional buses are in use
 func (c *componentImpl) ActivateBus(mediaType, direction, index int32, state bool) error {
+	if err := c.checkBusIndex(mediaType, direction, index); err != nil {
+		return err
+	}
+	return c.processor.GetBuses().SetBusActive(bus.MediaType(mediaType), bus.Direction(direction), index, state)
+}
+
+// checkBusIndex returns ErrBusIndexOutOfRange if the bus does not exist
+func (c *componentImpl) checkBusIndex(mediaType, direction, index int32) error {
+	count := c.GetBusCount(mediaType, direction)
+	if index < 0 || index >= count {
+		return fmt.Errorf("%w: index %d, %d buses (media type %d, direction %d)",
+			vst3.ErrBusIndexOutOfRange, index, count, mediaType, direction)
+	}
 	return nil
 }
 
@@ -97,7 +208,35 @@ func (c *componentImpl) SetActive(active bool) error {
 	defer c.mu.Unlock()
 
 	c.active = active
-	return c.processor.SetActive(active)
+	c.silence.reset()
+	if params := c.processor.GetParameters(); active && params != nil {
+		params.ResetSmoothing()
+	}
+	if err := c.processor.SetActive(active); err != nil {
+		return err
+	}
+
+	// Channel counts and latency are settled once the processor is active
+	c.bypass = nil
+	if active {
+		channels := c.maxChannels()
+		c.bypass = NewBypass(c.processor, channels, int(c.maxBlockSize), c.sampleRate)
+		c.subInput = make([][]float32, 0, channels)
+		c.subOutput = make([][]float32, 0, channels)
+		c.subInput64 = make([][]float64, 0, channels)
+		c.subOutput64 = make([][]float64, 0, channels)
+	}
+	return nil
+}
+
+// maxChannels returns the larger of the active input and output channel
+// counts
+func (c *componentImpl) maxChannels() int {
+	buses := c.processor.GetBuses()
+	if buses == nil {
+		return 0
+	}
+	return int(max(buses.GetActiveInputChannelCount(), buses.GetActiveOutputChannelCount()))
 }
 
 func (c *componentImpl) SetState(stateData []byte) error {
@@ -114,8 +253,16 @@ func (c *componentImpl) SetState(stateData []byte) error {
 	// Create state manager and configure custom state handling
 	stateManager := state.NewManager(params)
 
-	// Check if processor implements StatefulProcessor
-	if stateful, ok := c.processor.(StatefulProcessor); ok {
+	// Hand heavy custom state to the async loader, otherwise load it inline
+	if ap, ok := c.processor.(AsyncStateProcessor); ok && ap.StateLoader() != nil {
+		stateManager.SetCustomLoadFunc(func(r io.Reader) error {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			return ap.StateLoader().Load(data)
+		})
+	} else if stateful, ok := c.processor.(StatefulProcessor); ok {
 		stateManager.SetCustomLoadFunc(stateful.LoadCustomState)
 	}
 
@@ -142,6 +289,16 @@ func (c *componentImpl) GetState() ([]byte, error) {
 		stateManager.SetCustomSaveFunc(stateful.SaveCustomState)
 	}
 
+	// While a load is in flight, save what the host set rather than stale state
+	if ap, ok := c.processor.(AsyncStateProcessor); ok && ap.StateLoader() != nil {
+		if pending, ok := ap.StateLoader().PendingData(); ok {
+			stateManager.SetCustomSaveFunc(func(w io.Writer) error {
+				_, err := w.Write(pending)
+				return err
+			})
+		}
+	}
+
 	var buf bytes.Buffer
 	if err := stateManager.Save(&buf); err != nil {
 		return nil, err
@@ -152,18 +309,46 @@ func (c *componentImpl) GetState() ([]byte, error) {
 
 // IAudioProcessor implementation
 func (c *componentImpl) SetBusArrangements(inputs, outputs []int64) error {
-	return nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Channel counts may only change while inactive
+	if c.active {
+		return fmt.Errorf("%w: bus arrangements set while active", vst3.ErrInvalidState)
+	}
+
+	toArrangements := func(arrs []int64) []bus.SpeakerArrangement {
+		out := make([]bus.SpeakerArrangement, len(arrs))
+		for i, a := range arrs {
+			out[i] = bus.SpeakerArrangement(a)
+		}
+		return out
+	}
+
+	// A rejection returns kResultFalse and the host asks for our arrangements
+	return c.processor.GetBuses().NegotiateArrangements(toArrangements(inputs), toArrangements(outputs))
 }
 
 func (c *componentImpl) GetBusArrangement(direction, index int32) (int64, error) {
-	// Return stereo by default
-	return int64(3), nil // Left + Right
+	if err := c.checkBusIndex(int32(bus.MediaTypeAudio), direction, index); err != nil {
+		return 0, err
+	}
+	arr, err := c.processor.GetBuses().GetArrangement(bus.Direction(direction), index)
+	if err != nil {
+		return 0, err
+	}
+	return int64(arr), nil
 }
 
 func (c *componentImpl) CanProcessSampleSize(symbolicSampleSize int32) error {
-	// We only support 32-bit float
-	if symbolicSampleSize == 0 { // kSample32
+	switch symbolicSampleSize {
+	case vst3.SampleSize32:
 		return nil
+	case vst3.SampleSize64:
+		// Double precision requires a processor that implements Processor64
+		if c.processor64 != nil {
+			return nil
+		}
 	}
 	return vst3.ErrNotImplemented
 }
@@ -176,7 +361,13 @@ func (c *componentImpl) SetupProcessing(setup *vst3.ProcessSetup) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// The spec only allows setupProcessing while the processor is stopped
+	if c.processing {
+		return fmt.Errorf("%w: setupProcessing called while processing", vst3.ErrInvalidState)
+	}
+
 	c.sampleRate = setup.SampleRate
+	c.processMode = process.ProcessMode(setup.ProcessMode)
 	if setup.MaxSamplesPerBlock > 0 {
 		c.maxBlockSize = setup.MaxSamplesPerBlock
 		// Recreate process context with new max block size
@@ -184,18 +375,28 @@ func (c *componentImpl) SetupProcessing(setup *vst3.ProcessSetup) error {
 		c.processCtx = process.NewContext(int(c.maxBlockSize), params)
 	}
 
-	return c.processor.Initialize(c.sampleRate, c.maxBlockSize)
+	return Reconfigure(c.processor, c.sampleRate, c.maxBlockSize)
 }
 
 func (c *componentImpl) SetProcessing(state bool) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if state && !c.active {
+		return fmt.Errorf("%w: setProcessing(true) called before setActive(true)", vst3.ErrInvalidState)
+	}
+
 	c.processing = state
 	return nil
 }
 
 func (c *componentImpl) Process(data unsafe.Pointer) error {
+	// The read lock lets overlapping process calls through, so catch them here
+	if !c.inProcess.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: overlapping process calls", vst3.ErrWrongThread)
+	}
+	defer c.inProcess.Store(false)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -208,6 +409,7 @@ func (c *componentImpl) Process(data unsafe.Pointer) error {
 
 	// Update context with current buffers
 	c.processCtx.SampleRate = c.sampleRate
+	c.processCtx.Mode = c.processMode
 
 	// Update transport information if available
 	if processData.processContext != nil {
@@ -267,11 +469,24 @@ func (c *componentImpl) Process(data unsafe.Pointer) error {
 	// Clear slices (no allocation, just updating slice headers)
 	c.processCtx.Input = c.processCtx.Input[:0]
 	c.processCtx.Output = c.processCtx.Output[:0]
+	c.processCtx.Input64 = c.processCtx.Input64[:0]
+	c.processCtx.Output64 = c.processCtx.Output64[:0]
+
+	// Double precision blocks are only routed to processors that support them
+	c.use64 = int32(processData.symbolicSampleSize) == vst3.SampleSize64 && c.processor64 != nil
+
+	// Reset bus layout and silence flags for this block
+	c.processCtx.ResetBuses()
 
 	// Map input buffers
 	if processData.numInputs > 0 && processData.inputs != nil {
 		inputBuses := (*[1]C.struct_Steinberg_Vst_AudioBusBuffers)(unsafe.Pointer(processData.inputs))[:processData.numInputs:processData.numInputs]
 		for _, bus := range inputBuses {
+			c.processCtx.AddInputBus(int(bus.numChannels), uint64(bus.silenceFlags))
+			if c.use64 {
+				c.processCtx.Input64 = appendChannels64(c.processCtx.Input64, &bus, numSamples)
+				continue
+			}
 			channelBuffers32 := getChannelBuffers32(&bus)
 			if bus.numChannels > 0 && channelBuffers32 != nil {
 				channels := (*[16]*float32)(unsafe.Pointer(channelBuffers32))[:bus.numChannels:bus.numChannels]
@@ -287,9 +502,15 @@ func (c *componentImpl) Process(data unsafe.Pointer) error {
 	}
 
 	// Map output buffers
+	var outputBuses []C.struct_Steinberg_Vst_AudioBusBuffers
 	if processData.numOutputs > 0 && processData.outputs != nil {
-		outputBuses := (*[1]C.struct_Steinberg_Vst_AudioBusBuffers)(unsafe.Pointer(processData.outputs))[:processData.numOutputs:processData.numOutputs]
+		outputBuses = (*[1]C.struct_Steinberg_Vst_AudioBusBuffers)(unsafe.Pointer(processData.outputs))[:processData.numOutputs:processData.numOutputs]
 		for _, bus := range outputBuses {
+			c.processCtx.AddOutputBus(int(bus.numChannels))
+			if c.use64 {
+				c.processCtx.Output64 = appendChannels64(c.processCtx.Output64, &bus, numSamples)
+				continue
+			}
 			channelBuffers32 := getChannelBuffers32(&bus)
 			if bus.numChannels > 0 && channelBuffers32 != nil {
 				channels := (*[16]*float32)(unsafe.Pointer(channelBuffers32))[:bus.numChannels:bus.numChannels]
@@ -306,6 +527,10 @@ func (c *componentImpl) Process(data unsafe.Pointer) error {
 
 	// Reset parameter changes for this processing block
 	c.processCtx.ResetParameterChanges()
+	c.processCtx.ResetOutputParameterChanges()
+
+	// Drop the previous block's events before reading this block's
+	c.processCtx.ClearAllEvents()
 
 	// Process input events (MIDI)
 	if processData.inputEvents != nil {
@@ -343,6 +568,18 @@ func (c *componentImpl) Process(data unsafe.Pointer) error {
 		}
 	}
 
+	// Skip processing while all inputs are silent and the latency and tail
+	// have run out
+	busy := c.processCtx.HasParameterChanges() || c.processCtx.HasInputEvents()
+	if c.silence.canSkip(c.processor, numSamples, c.processCtx.AllInputsSilent(), busy) {
+		c.processCtx.Clear()
+		for i := range outputBuses {
+			c.processCtx.SetOutputSilent(i, true)
+		}
+		c.writeOutputSilenceFlags(outputBuses)
+		return nil
+	}
+
 	// Process audio with sample-accurate parameter automation
 	if c.processCtx.HasParameterChanges() {
 		// Sort parameter changes by sample offset
@@ -352,12 +589,80 @@ func (c *componentImpl) Process(data unsafe.Pointer) error {
 		c.processSampleAccurate()
 	} else {
 		// No parameter changes - process entire block
-		c.processor.ProcessAudio(c.processCtx)
+		c.processBlock()
+	}
+
+	c.writeOutputSilenceFlags(outputBuses)
+
+	// Report internal modulation so the host can record it as automation
+	if processData.outputParameterChanges != nil {
+		c.writeOutputParameterChanges(unsafe.Pointer(processData.outputParameterChanges))
+	}
+
+	// Send MIDI generated by the processor
+	if processData.outputEvents != nil {
+		c.writeOutputEvents(unsafe.Pointer(processData.outputEvents))
 	}
 
 	return nil
 }
 
+// writeOutputParameterChanges writes modulation points from the context to the host's output queues
+func (c *componentImpl) writeOutputParameterChanges(outputChanges unsafe.Pointer) {
+	for _, change := range c.processCtx.GetOutputParameterChanges() {
+		paramQueue := C.addParameterData(outputChanges, C.uint32_t(change.ParamID))
+		if paramQueue != nil {
+			C.addPoint(paramQueue, C.int32_t(change.SampleOffset), C.double(change.Value))
+		}
+	}
+}
+
+// Controller numbers VST3 uses in legacy MIDI CC output events for messages
+// that are not continuous controllers
+const (
+	legacyCCAfterTouch    = 128
+	legacyCCPitchBend     = 129
+	legacyCCProgramChange = 130
+)
+
+// writeOutputEvents converts the context's output MIDI events and adds them
+// to the host's output event list. Notes and poly pressure become native
+// VST3 events; controllers, pitch bend, channel pressure and program
+// changes go out as legacy MIDI CC events.
+func (c *componentImpl) writeOutputEvents(eventList unsafe.Pointer) {
+	c.outputEvents = c.processCtx.AppendOutputEvents(c.outputEvents[:0])
+	for _, event := range c.outputEvents {
+		offset := C.int32_t(event.SampleOffset())
+		channel := event.Channel()
+		switch e := event.(type) {
+		case midi.NoteOnEvent:
+			C.addNoteOnEvent(eventList, 0, offset, C.int16_t(channel), C.int16_t(e.NoteNumber), C.float(float32(e.Velocity)/127), -1)
+		case midi.NoteOffEvent:
+			C.addNoteOffEvent(eventList, 0, offset, C.int16_t(channel), C.int16_t(e.NoteNumber), C.float(float32(e.Velocity)/127), -1)
+		case midi.PolyPressureEvent:
+			C.addPolyPressureEvent(eventList, 0, offset, C.int16_t(channel), C.int16_t(e.NoteNumber), C.float(float32(e.Pressure)/127), -1)
+		case midi.ControlChangeEvent:
+			C.addMidiCCOutEvent(eventList, 0, offset, C.uint8_t(e.Controller), C.int8_t(channel), C.int8_t(e.Value&0x7F), 0)
+		case midi.ChannelPressureEvent:
+			C.addMidiCCOutEvent(eventList, 0, offset, legacyCCAfterTouch, C.int8_t(channel), C.int8_t(e.Pressure&0x7F), 0)
+		case midi.ProgramChangeEvent:
+			C.addMidiCCOutEvent(eventList, 0, offset, legacyCCProgramChange, C.int8_t(channel), C.int8_t(e.Program&0x7F), 0)
+		case midi.PitchBendEvent:
+			// 14-bit value split into LSB and MSB, 8192 is center
+			bend := int(e.Value) + 8192
+			C.addMidiCCOutEvent(eventList, 0, offset, legacyCCPitchBend, C.int8_t(channel), C.int8_t(bend&0x7F), C.int8_t(bend>>7&0x7F))
+		}
+	}
+	c.processCtx.ClearOutputEvents()
+}
+
+// writeOutputSilenceFlags reports output silence flags back to the host
+func (c *componentImpl) writeOutputSilenceFlags(outputBuses []C.struct_Steinberg_Vst_AudioBusBuffers) {
+	for i := range outputBuses {
+		outputBuses[i].silenceFlags = C.Steinberg_uint64(c.processCtx.OutputSilenceFlags(i))
+	}
+}
+
 func (c *componentImpl) GetTailSamples() uint32 {
 	return uint32(c.processor.GetTailSamples())
 }
@@ -489,6 +794,7 @@ func (c *componentImpl) SetParamNormalized(id uint32, value float64) error {
 		fmt.Printf("[PARAM_CHANGE] SetParamNormalized: id=%d, value=%.3f, plain=%.1f\n",
 			id, value, p.Min+value*(p.Max-p.Min))
 		p.SetValue(value)
+		c.processor.GetParameters().CheckTrigger(id)
 		return nil
 	}
 	return vst3.ErrInvalidArgument
@@ -504,21 +810,93 @@ func (c *componentImpl) CreateView(name string) (interface{}, error) {
 
 // SetParamNormalizedWithNotification sets a parameter value and notifies the host
 // This should be used when the plugin changes a parameter value internally
+// The change is recorded in the registry's undo history
 func (c *componentImpl) SetParamNormalizedWithNotification(id uint32, value float64) error {
-	if p := c.processor.GetParameters().Get(id); p != nil {
-		// Notify host of parameter change
-		if c.wrapper != nil {
-			c.wrapper.notifyParamBeginEdit(id)
-			p.SetValue(value)
-			c.wrapper.notifyParamPerformEdit(id, value)
-			c.wrapper.notifyParamEndEdit(id)
-		} else {
-			// Fallback if no wrapper available
-			p.SetValue(value)
+	params := c.processor.GetParameters()
+	if params.Get(id) == nil {
+		return vst3.ErrInvalidArgument
+	}
+	// The registry passes the edit on to the host through the wrapper
+	params.BeginEdit(id)
+	params.PerformEdit(id, value)
+	params.EndEdit(id)
+	return nil
+}
+
+// processBlock advances parameter smoothing and runs the processor on the
+// current buffers. Debug builds audit the call for real-time safety.
+func (c *componentImpl) processBlock() {
+	c.processCtx.CaptureParams()
+	c.processCtx.AdvanceSmoothing()
+	debug.AuditProcess(c.auditName, func() {
+		if c.use64 {
+			if c.bypass != nil {
+				c.bypass.Process64(c.processCtx, c.processAudio64)
+				return
+			}
+			c.processor64.ProcessAudio64(c.processCtx)
+			return
 		}
-		return nil
+		if c.bypass != nil {
+			c.bypass.Process(c.processCtx, c.processAudio)
+			return
+		}
+		c.processor.ProcessAudio(c.processCtx)
+	})
+}
+
+// appendChannels64 appends the 64-bit channel buffers of a bus to dst (no allocation beyond slice growth)
+func appendChannels64(dst [][]float64, bus *C.struct_Steinberg_Vst_AudioBusBuffers, numSamples int) [][]float64 {
+	channelBuffers64 := getChannelBuffers64(bus)
+	if bus.numChannels <= 0 || channelBuffers64 == nil {
+		return dst
 	}
-	return vst3.ErrInvalidArgument
+	channels := (*[16]*float64)(unsafe.Pointer(channelBuffers64))[:bus.numChannels:bus.numChannels]
+	for _, channel := range channels {
+		if channel != nil {
+			// Create slice from pointer without allocation
+			samples := (*[vst3.MaxArraySize]float64)(unsafe.Pointer(channel))[:numSamples:numSamples]
+			dst = append(dst, samples)
+		}
+	}
+	return dst
+}
+
+// subSlices32 reslices dst to the [start, end) range of each channel buffer
+func subSlices32(dst, buffers [][]float32, start, end int) [][]float32 {
+	dst = dst[:0]
+	for ch := range buffers {
+		if start < len(buffers[ch]) {
+			dst = append(dst, buffers[ch][start:min(end, len(buffers[ch]))])
+		}
+	}
+	return dst
+}
+
+// subSlices64 reslices dst to the [start, end) range of each 64-bit channel
+// buffer
+func subSlices64(dst, buffers [][]float64, start, end int) [][]float64 {
+	dst = dst[:0]
+	for ch := range buffers {
+		if start < len(buffers[ch]) {
+			dst = append(dst, buffers[ch][start:min(end, len(buffers[ch]))])
+		}
+	}
+	return dst
+}
+
+// setSubBlock points the context buffers at the [start, end) range of the
+// block's buffers
+func (c *componentImpl) setSubBlock(input, output [][]float32, input64, output64 [][]float64, start, end int) {
+	c.subInput = subSlices32(c.subInput, input, start, end)
+	c.subOutput = subSlices32(c.subOutput, output, start, end)
+	c.subInput64 = subSlices64(c.subInput64, input64, start, end)
+	c.subOutput64 = subSlices64(c.subOutput64, output64, start, end)
+
+	c.processCtx.Input = c.subInput
+	c.processCtx.Output = c.subOutput
+	c.processCtx.Input64 = c.subInput64
+	c.processCtx.Output64 = c.subOutput64
 }
 
 // processSampleAccurate processes audio with sample-accurate parameter automation
@@ -530,41 +908,18 @@ func (c *componentImpl) processSampleAccurate() {
 	// Store original buffers
 	origInput := c.processCtx.Input
 	origOutput := c.processCtx.Output
+	origInput64 := c.processCtx.Input64
+	origOutput64 := c.processCtx.Output64
 
 	// Process each chunk between parameter changes
 	for _, change := range changes {
 		if change.SampleOffset > lastOffset {
-			// Process chunk from lastOffset to change.SampleOffset
-			chunkSize := change.SampleOffset - lastOffset
-
-			// Temporarily update context buffers to point to sub-slices (no allocation)
-			c.processCtx.Input = nil
-			c.processCtx.Output = nil
-
-			// Set up input sub-slices
-			for ch := 0; ch < len(origInput); ch++ {
-				if lastOffset < len(origInput[ch]) {
-					endOffset := lastOffset + chunkSize
-					if endOffset > len(origInput[ch]) {
-						endOffset = len(origInput[ch])
-					}
-					c.processCtx.Input = append(c.processCtx.Input, origInput[ch][lastOffset:endOffset])
-				}
-			}
-
-			// Set up output sub-slices
-			for ch := 0; ch < len(origOutput); ch++ {
-				if lastOffset < len(origOutput[ch]) {
-					endOffset := lastOffset + chunkSize
-					if endOffset > len(origOutput[ch]) {
-						endOffset = len(origOutput[ch])
-					}
-					c.processCtx.Output = append(c.processCtx.Output, origOutput[ch][lastOffset:endOffset])
-				}
-			}
+			// Temporarily point context buffers at sub-slices
+			c.setSubBlock(origInput, origOutput, origInput64, origOutput64, lastOffset, change.SampleOffset)
 
 			// Process this chunk
-			c.processor.ProcessAudio(c.processCtx)
+			c.processCtx.SetBlockOffset(lastOffset)
+			c.processBlock()
 
 			lastOffset = change.SampleOffset
 		}
@@ -581,29 +936,17 @@ func (c *componentImpl) processSampleAccurate() {
 
 	// Process final chunk if there are samples remaining
 	if lastOffset < numSamples {
-		// Temporarily update context buffers for final chunk
-		c.processCtx.Input = nil
-		c.processCtx.Output = nil
-
-		// Set up input sub-slices for final chunk
-		for ch := 0; ch < len(origInput); ch++ {
-			if lastOffset < len(origInput[ch]) {
-				c.processCtx.Input = append(c.processCtx.Input, origInput[ch][lastOffset:])
-			}
-		}
-
-		// Set up output sub-slices for final chunk
-		for ch := 0; ch < len(origOutput); ch++ {
-			if lastOffset < len(origOutput[ch]) {
-				c.processCtx.Output = append(c.processCtx.Output, origOutput[ch][lastOffset:])
-			}
-		}
+		c.setSubBlock(origInput, origOutput, origInput64, origOutput64, lastOffset, numSamples)
 
 		// Process final chunk
-		c.processor.ProcessAudio(c.processCtx)
+		c.processCtx.SetBlockOffset(lastOffset)
+		c.processBlock()
 	}
 
 	// Restore original buffers
 	c.processCtx.Input = origInput
 	c.processCtx.Output = origOutput
+	c.processCtx.Input64 = origInput64
+	c.processCtx.Output64 = origOutput64
+	c.processCtx.SetBlockOffset(0)
 }
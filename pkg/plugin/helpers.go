@@ -7,6 +7,11 @@ package plugin
 // static inline float** getChannelBuffers32(struct Steinberg_Vst_AudioBusBuffers* bus) {
 //     return bus->Steinberg_Vst_AudioBusBuffers_channelBuffers32;
 // }
+//
+// // Helper to access channelBuffers64 from the union
+// static inline double** getChannelBuffers64(struct Steinberg_Vst_AudioBusBuffers* bus) {
+//     return bus->Steinberg_Vst_AudioBusBuffers_channelBuffers64;
+// }
 import "C"
 import "unsafe"
 
@@ -15,6 +20,11 @@ func getChannelBuffers32(bus *C.struct_Steinberg_Vst_AudioBusBuffers) **C.float
 	return C.getChannelBuffers32(bus)
 }
 
+// getChannelBuffers64 extracts the 64-bit channel buffers from an audio bus
+func getChannelBuffers64(bus *C.struct_Steinberg_Vst_AudioBusBuffers) **C.double {
+	return C.getChannelBuffers64(bus)
+}
+
 // copyStringToTChar copies a Go string to a VST3 TChar (UTF16) buffer
 func copyStringToTChar(src string, dst *C.Steinberg_Vst_TChar, maxLen int) {
 	// Convert to runes for proper Unicode handling
@@ -2,12 +2,17 @@
 package plugin
 
 import (
+	"image"
 	"io"
 
 	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/message"
 	"github.com/justyntemme/vst3go/pkg/framework/param"
 	"github.com/justyntemme/vst3go/pkg/framework/plugin"
 	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/framework/remote"
+	"github.com/justyntemme/vst3go/pkg/framework/state"
+	"github.com/justyntemme/vst3go/pkg/framework/worker"
 )
 
 // Plugin is the main interface that users implement
@@ -43,6 +48,125 @@ type Processor interface {
 	GetTailSamples() int32
 }
 
+// Processor64 extends Processor with double precision processing.
+// When the host runs in 64-bit mode, ProcessAudio64 is called instead of
+// ProcessAudio and the context carries its audio in Input64 and Output64.
+type Processor64 interface {
+	Processor
+
+	// ProcessAudio64 processes 64-bit audio - ZERO ALLOCATIONS!
+	ProcessAudio64(ctx *process.Context)
+}
+
+// ManualBypassProcessor is implemented by processors that handle their
+// IsBypass parameter themselves. For every other processor with a bypass
+// parameter the component crossfades to the input, delayed by the
+// processor's latency, when the host bypasses the plugin, and stops calling
+// ProcessAudio or ProcessAudio64 once the crossfade is done.
+type ManualBypassProcessor interface {
+	Processor
+
+	// HandlesBypass reports whether the processor bypasses itself
+	HandlesBypass() bool
+}
+
+// NewBypass returns the soft bypass a wrapper runs around p's ProcessAudio,
+// and ProcessAudio64 for a Processor64, or nil when p has no bypass
+// parameter or handles bypass itself. Call it on activation, when the
+// channel counts and latency are settled.
+func NewBypass(p Processor, maxChannels, maxBlockSize int, sampleRate float64) *process.SoftBypass {
+	if mp, ok := p.(ManualBypassProcessor); ok && mp.HandlesBypass() {
+		return nil
+	}
+	b := process.NewParameterBypass(p.GetParameters(), maxChannels, maxBlockSize, sampleRate, int(p.GetLatencySamples()))
+	if _, ok := p.(Processor64); ok && b != nil {
+		b.EnableDouble()
+	}
+	return b
+}
+
+// SampleRateProcessor is implemented by processors that can follow a new
+// sample rate or block size without being rebuilt. The component calls
+// Initialize once and SampleRateChanged on every later setupProcessing, so
+// LFO phases and other long-running state carry on across re-activation
+// instead of restarting.
+type SampleRateProcessor interface {
+	Processor
+
+	// SampleRateChanged reconfigures the processor for a new sample rate
+	// and maximum block size. The values may be unchanged.
+	SampleRateChanged(sampleRate float64, maxBlockSize int32) error
+}
+
+// Reconfigure applies a new sample rate and block size to an initialized
+// processor, through SampleRateChanged if p implements SampleRateProcessor
+// and Initialize otherwise
+func Reconfigure(p Processor, sampleRate float64, maxBlockSize int32) error {
+	if sp, ok := p.(SampleRateProcessor); ok {
+		return sp.SampleRateChanged(sampleRate, maxBlockSize)
+	}
+	return p.Initialize(sampleRate, maxBlockSize)
+}
+
+// SafeModeProcessor is implemented by processors that support crash recovery.
+// The component starts the sentinel on Initialize and removes it on Terminate,
+// so a crash leaves it behind and the next session starts in safe mode.
+type SafeModeProcessor interface {
+	Processor
+
+	// SafeMode returns the processor's safe mode detector
+	SafeMode() *plugin.SafeMode
+}
+
+// WorkerProcessor is implemented by processors that run background jobs.
+// The component shuts the pool down cleanly on Terminate.
+type WorkerProcessor interface {
+	Processor
+
+	// Workers returns the processor's worker pool
+	Workers() *worker.Pool
+}
+
+// RemoteProcessor is implemented by processors that serve their parameters
+// to external tools, usually from a server started with
+// remote.ListenFromEnv in Initialize, so processors that are created but
+// never initialized do not open sockets. The wrapper dispatches queued
+// requests when the host calls the controller and closes the server on
+// Terminate.
+type RemoteProcessor interface {
+	Processor
+
+	// Remote returns the processor's remote control server, or nil
+	Remote() *remote.Server
+}
+
+// MessageProcessor is implemented by processors that send metering or
+// analysis data to the controller. From a successful initialize to
+// terminate the component drains the channel whenever the host calls it on
+// its UI thread, in getParamNormalized, setParamNormalized and notify, and
+// forwards each message as an IMessage to the peer the host connected.
+// Hosts only connect plugins with a separate controller, so for
+// single-component plugins the messages stay queued for an in-process editor
+// to Receive, and Send drops them once the channel is full.
+type MessageProcessor interface {
+	Processor
+
+	// Messages returns the processor's outgoing message channel
+	Messages() *message.Channel
+}
+
+// MessageReceiver is implemented by processors that accept messages from
+// the controller, such as a request to reset a loudness meter. Messages
+// arrive on a host thread and are delivered between process calls, never
+// during ProcessAudio; the audio thread waits while ReceiveMessage runs, so
+// keep it short. Their data is the processor's to keep.
+type MessageReceiver interface {
+	Processor
+
+	// ReceiveMessage handles a message sent by the controller
+	ReceiveMessage(m message.Message)
+}
+
 // StatefulProcessor extends Processor with custom state save/load capabilities
 // Processors can optionally implement this interface to save custom state
 // beyond parameter values (e.g., delay buffer contents, filter states)
@@ -57,3 +181,44 @@ type StatefulProcessor interface {
 	// This is called after all parameters have been loaded
 	LoadCustomState(r io.Reader) error
 }
+
+// AsyncStateProcessor is implemented by stateful processors whose custom state
+// is slow to restore (sample data, impulse responses). Parameters are still
+// restored synchronously, but the custom data is handed to the loader and
+// SetState returns at once. The loader commits between process calls; saving
+// state before then returns the pending data. LoadCustomState is not used.
+type AsyncStateProcessor interface {
+	StatefulProcessor
+
+	// StateLoader returns the processor's asynchronous state loader
+	StateLoader() *state.AsyncLoader
+}
+
+// Preset is a named set of parameter values that ships with a plugin
+type Preset struct {
+	Name     string
+	Category string
+
+	// Values holds plain parameter values by ID; parameters left out keep
+	// their defaults
+	Values map[uint32]float64
+}
+
+// PresetProcessor is implemented by processors that ship factory presets.
+// Tools such as the docs generator read them to render preset cards.
+type PresetProcessor interface {
+	Processor
+
+	// FactoryPresets returns the factory presets in browser order
+	FactoryPresets() []Preset
+}
+
+// ScreenshotProcessor is implemented by processors whose editor can draw
+// itself offscreen. Preset cards use the screenshot in place of the
+// generated parameter summary.
+type ScreenshotProcessor interface {
+	Processor
+
+	// Screenshot renders the editor with the current parameter values
+	Screenshot() (image.Image, error)
+}
@@ -0,0 +1,32 @@
+package plugin
+
+import "sync"
+
+// Global plugin instance exported through the VST3 factory
+var globalPlugin Plugin
+
+var (
+	registered   []Plugin
+	registeredMu sync.Mutex
+)
+
+// Register sets the global plugin instance. Plugins call it from init; the
+// VST3 factory exports the last registered plugin.
+func Register(p Plugin) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	globalPlugin = p
+	registered = append(registered, p)
+}
+
+// Registered returns all plugins registered in this program, in
+// registration order, so hosts such as pkg/host can run them without a DAW
+func Registered() []Plugin {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	result := make([]Plugin, len(registered))
+	copy(result, registered)
+	return result
+}
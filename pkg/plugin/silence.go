@@ -0,0 +1,30 @@
+package plugin
+
+// silenceTracker counts the consecutive samples in which the host flagged
+// every input silent, to tell when a processor's output has fully decayed
+type silenceTracker struct {
+	samples int64
+}
+
+// reset forgets the current silent run
+func (s *silenceTracker) reset() {
+	s.samples = 0
+}
+
+// canSkip records a block and reports whether p can be skipped for it. The
+// silent run before the block must cover p's latency, during which real
+// input is still coming out, and then its tail. Busy blocks carry parameter
+// changes or events and are never skipped.
+func (s *silenceTracker) canSkip(p Processor, numSamples int, inputsSilent, busy bool) bool {
+	if !inputsSilent {
+		s.samples = 0
+		return false
+	}
+
+	before := s.samples
+	s.samples += int64(numSamples)
+	if busy {
+		return false
+	}
+	return before >= int64(p.GetLatencySamples())+int64(p.GetTailSamples())
+}
@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+)
+
+// delayProcessor reports a fixed latency and tail like a lookahead plugin
+type delayProcessor struct {
+	latency, tail int32
+}
+
+func (p *delayProcessor) Initialize(float64, int32) error { return nil }
+func (p *delayProcessor) ProcessAudio(*process.Context)   {}
+func (p *delayProcessor) GetParameters() *param.Registry  { return nil }
+func (p *delayProcessor) GetBuses() *bus.Configuration    { return nil }
+func (p *delayProcessor) SetActive(bool) error            { return nil }
+func (p *delayProcessor) GetLatencySamples() int32        { return p.latency }
+func (p *delayProcessor) GetTailSamples() int32           { return p.tail }
+
+func TestSilenceSkipWaitsForLatencyAndTail(t *testing.T) {
+	p := &delayProcessor{latency: 300, tail: 100}
+	var s silenceTracker
+
+	// 400 samples of delayed input and tail are still coming out
+	for block := 0; block < 4; block++ {
+		if s.canSkip(p, 100, true, false) {
+			t.Fatalf("block %d skipped with %d samples of latency and tail left", block, 400-block*100)
+		}
+	}
+	if !s.canSkip(p, 100, true, false) {
+		t.Error("block after latency and tail not skipped")
+	}
+
+	// Events keep the processor running, input restarts the count
+	if s.canSkip(p, 100, true, true) {
+		t.Error("block with events skipped")
+	}
+	if s.canSkip(p, 100, false, false) || s.canSkip(p, 100, true, false) {
+		t.Error("skipped right after input returned")
+	}
+
+	// Without latency or tail the first silent block is already silent
+	s.reset()
+	if !s.canSkip(&delayProcessor{}, 64, true, false) {
+		t.Error("silent block without latency or tail not skipped")
+	}
+}
@@ -28,11 +28,19 @@ package plugin
 //     }
 //     return Steinberg_kResultFalse;
 // }
+//
+// static inline Steinberg_tresult componentHandler_restartComponent(struct Steinberg_Vst_IComponentHandler* handler, Steinberg_int32 flags) {
+//     if (handler && handler->lpVtbl && handler->lpVtbl->restartComponent) {
+//         return handler->lpVtbl->restartComponent(handler, flags);
+//     }
+//     return Steinberg_kResultFalse;
+// }
 import "C"
 import (
 	"sync"
 	"unsafe"
 
+	"github.com/justyntemme/vst3go/pkg/framework/message"
 	"github.com/justyntemme/vst3go/pkg/vst3"
 )
 
@@ -46,10 +54,17 @@ type Component interface {
 // componentWrapper wraps a Go component for C callbacks
 type componentWrapper struct {
 	component        Component
+	processor        Processor
 	handle           unsafe.Pointer
 	id               uintptr
 	componentHandler unsafe.Pointer // IComponentHandler from host
 	handlerMu        sync.RWMutex   // Protects componentHandler access
+
+	// IConnectionPoint state
+	hostContext unsafe.Pointer   // Host context from initialize, creates messages
+	peer        unsafe.Pointer   // Connected IConnectionPoint
+	connMu      sync.Mutex       // Protects hostContext, peer and forwardCh
+	forwardCh   *message.Channel // Processor messages forwarded to peer
 }
 
 var (
@@ -59,9 +74,6 @@ var (
 	nextID       uintptr = 1
 )
 
-// Global plugin instance
-var globalPlugin Plugin
-
 // Factory info
 type FactoryInfo struct {
 	Vendor string
@@ -75,11 +87,6 @@ var globalFactoryInfo = FactoryInfo{
 	Email:  "info@vst3go.dev",
 }
 
-// Register sets the global plugin instance
-func Register(p Plugin) {
-	globalPlugin = p
-}
-
 // SetFactoryInfo sets the factory information
 func SetFactoryInfo(info FactoryInfo) {
 	globalFactoryInfo = info
@@ -170,6 +177,33 @@ func (w *componentWrapper) notifyParamEndEdit(paramID uint32) {
 	C.componentHandler_endEdit((*C.Steinberg_Vst_IComponentHandler)(handler), C.Steinberg_Vst_ParamID(paramID))
 }
 
+// notifyRestartComponent asks the host to re-read the state given by flags
+func (w *componentWrapper) notifyRestartComponent(flags int32) {
+	w.handlerMu.RLock()
+	handler := w.componentHandler
+	w.handlerMu.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	C.componentHandler_restartComponent((*C.Steinberg_Vst_IComponentHandler)(handler), C.Steinberg_int32(flags))
+}
+
+// BeginEdit, PerformEdit and EndEdit pass edits made through the parameter
+// registry on to the host, implementing param.EditHandler
+func (w *componentWrapper) BeginEdit(id uint32) {
+	w.notifyParamBeginEdit(id)
+}
+
+func (w *componentWrapper) PerformEdit(id uint32, value float64) {
+	w.notifyParamPerformEdit(id, value)
+}
+
+func (w *componentWrapper) EndEdit(id uint32) {
+	w.notifyParamEndEdit(id)
+}
+
 //export GoGetFactoryInfo
 func GoGetFactoryInfo(vendor, url, email *C.char, flags *C.int32_t) {
 	C.strcpy(vendor, C.CString(globalFactoryInfo.Vendor))
@@ -234,11 +268,25 @@ func GoCreateInstance(cid *C.char, iid *C.char) unsafe.Pointer {
 	// Create wrapper
 	wrapper := &componentWrapper{
 		component: component,
+		processor: processor,
 	}
 
 	// Set wrapper reference in component for notifications
 	component.wrapper = wrapper
 
+	if params := processor.GetParameters(); params != nil {
+		// Batch parameter updates (presets) ask the host to re-read all
+		// values once instead of one edit per parameter
+		params.OnValuesChanged(func() {
+			wrapper.notifyRestartComponent(vst3.RestartParamValuesChanged)
+		})
+
+		// Editor edits and context menus go through the host's component
+		// handler
+		params.SetEditHandler(wrapper)
+		params.OnContextMenu(wrapper.showContextMenu)
+	}
+
 	// Register and get ID
 	id := registerComponent(wrapper)
 
@@ -276,9 +324,14 @@ func GoComponentInitialize(componentPtr unsafe.Pointer, context unsafe.Pointer)
 		return C.Steinberg_tresult(vst3.ResultFalse)
 	}
 
+	wrapper.setHostContext(context)
 	err := wrapper.component.Initialize(context)
 	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
+		wrapper.setHostContext(nil)
+		return C.Steinberg_tresult(vst3.ReportError("GoComponentInitialize", err))
+	}
+	if mp, ok := wrapper.processor.(MessageProcessor); ok && mp.Messages() != nil {
+		wrapper.startForwarding(mp.Messages())
 	}
 	return C.Steinberg_tresult(vst3.ResultOK)
 }
@@ -292,11 +345,11 @@ func GoComponentTerminate(componentPtr unsafe.Pointer) C.Steinberg_tresult {
 		return C.Steinberg_tresult(vst3.ResultFalse)
 	}
 
+	wrapper.stopForwarding()
+	wrapper.disconnect()
+	wrapper.setHostContext(nil)
 	err := wrapper.component.Terminate()
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoComponentTerminate", err))
 }
 
 //export GoComponentGetControllerClassId
@@ -318,10 +371,7 @@ func GoComponentSetIoMode(componentPtr unsafe.Pointer, mode C.int32_t) C.Steinbe
 	}
 
 	err := wrapper.component.SetIOMode(int32(mode))
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoComponentSetIoMode", err))
 }
 
 //export GoComponentGetBusCount
@@ -342,7 +392,10 @@ func GoComponentGetBusInfo(componentPtr unsafe.Pointer, mediaType, dir, index C.
 	}
 
 	info, err := wrapper.component.GetBusInfo(int32(mediaType), int32(dir), int32(index))
-	if err != nil || info == nil {
+	if err != nil {
+		return C.Steinberg_tresult(vst3.ReportError("GoComponentGetBusInfo", err))
+	}
+	if info == nil {
 		return C.Steinberg_tresult(vst3.ResultFalse)
 	}
 
@@ -376,10 +429,7 @@ func GoComponentActivateBus(componentPtr unsafe.Pointer, mediaType, dir, index,
 	}
 
 	err := wrapper.component.ActivateBus(int32(mediaType), int32(dir), int32(index), state != 0)
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoComponentActivateBus", err))
 }
 
 //export GoComponentSetActive
@@ -390,10 +440,7 @@ func GoComponentSetActive(componentPtr unsafe.Pointer, state C.int32_t) C.Steinb
 	}
 
 	err := wrapper.component.SetActive(state != 0)
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoComponentSetActive", err))
 }
 
 //export GoComponentSetState
@@ -411,12 +458,12 @@ func GoComponentSetState(componentPtr unsafe.Pointer, state unsafe.Pointer) C.St
 
 	stateData, err := streamWrapper.ReadAll()
 	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
+		return C.Steinberg_tresult(vst3.ReportError("GoComponentSetState", err))
 	}
 
 	// Apply state to component
 	if err := wrapper.component.SetState(stateData); err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
+		return C.Steinberg_tresult(vst3.ReportError("GoComponentSetState", err))
 	}
 
 	return C.Steinberg_tresult(vst3.ResultOK)
@@ -432,7 +479,7 @@ func GoComponentGetState(componentPtr unsafe.Pointer, state unsafe.Pointer) C.St
 	// Get state from component
 	stateData, err := wrapper.component.GetState()
 	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
+		return C.Steinberg_tresult(vst3.ReportError("GoComponentGetState", err))
 	}
 
 	// Write to VST3 stream
@@ -443,7 +490,7 @@ func GoComponentGetState(componentPtr unsafe.Pointer, state unsafe.Pointer) C.St
 
 	_, err = streamWrapper.Write(stateData)
 	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
+		return C.Steinberg_tresult(vst3.ReportError("GoComponentGetState", err))
 	}
 
 	return C.Steinberg_tresult(vst3.ResultOK)
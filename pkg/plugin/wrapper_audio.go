@@ -30,10 +30,7 @@ func GoAudioSetBusArrangements(componentPtr unsafe.Pointer, inputs unsafe.Pointe
 	}
 
 	err := wrapper.component.SetBusArrangements(inputArrs, outputArrs)
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoAudioSetBusArrangements", err))
 }
 
 //export GoAudioGetBusArrangement
@@ -45,7 +42,7 @@ func GoAudioGetBusArrangement(componentPtr unsafe.Pointer, dir, index C.int32_t,
 
 	arrangement, err := wrapper.component.GetBusArrangement(int32(dir), int32(index))
 	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
+		return C.Steinberg_tresult(vst3.ReportError("GoAudioGetBusArrangement", err))
 	}
 
 	*(*C.Steinberg_Vst_SpeakerArrangement)(arr) = C.Steinberg_Vst_SpeakerArrangement(arrangement)
@@ -59,6 +56,7 @@ func GoAudioCanProcessSampleSize(componentPtr unsafe.Pointer, symbolicSampleSize
 		return C.Steinberg_tresult(vst3.ResultFalse)
 	}
 
+	// An unsupported sample size is a normal answer, not an error to report
 	err := wrapper.component.CanProcessSampleSize(int32(symbolicSampleSize))
 	if err != nil {
 		return C.Steinberg_tresult(vst3.ResultFalse)
@@ -93,10 +91,7 @@ func GoAudioSetupProcessing(componentPtr unsafe.Pointer, setup unsafe.Pointer) C
 	}
 
 	err := wrapper.component.SetupProcessing(goSetup)
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoAudioSetupProcessing", err))
 }
 
 //export GoAudioSetProcessing
@@ -107,10 +102,7 @@ func GoAudioSetProcessing(componentPtr unsafe.Pointer, state C.int32_t) C.Steinb
 	}
 
 	err := wrapper.component.SetProcessing(state != 0)
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoAudioSetProcessing", err))
 }
 
 //export GoAudioProcess
@@ -123,10 +115,7 @@ func GoAudioProcess(componentPtr unsafe.Pointer, data unsafe.Pointer) C.Steinber
 	}
 
 	err := wrapper.component.Process(data)
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoAudioProcess", err))
 }
 
 //export GoAudioGetTailSamples
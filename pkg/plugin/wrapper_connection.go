@@ -0,0 +1,163 @@
+package plugin
+
+// #cgo CFLAGS: -I../../include
+// #include "../../include/vst3/vst3_c_api.h"
+// #include "../../bridge/bridge.h"
+// #include <stdlib.h>
+import "C"
+import (
+	"unsafe"
+
+	"github.com/justyntemme/vst3go/pkg/framework/message"
+	"github.com/justyntemme/vst3go/pkg/vst3"
+)
+
+// dataAttribute is the IMessage attribute name, allocated once for all
+// messages
+var dataAttribute = C.CString(message.DataAttribute)
+
+// setHostContext keeps the host context passed to initialize, which creates
+// outgoing messages, releasing the previous one
+func (w *componentWrapper) setHostContext(context unsafe.Pointer) {
+	if context != nil {
+		C.addRefObject(context)
+	}
+	w.connMu.Lock()
+	old := w.hostContext
+	w.hostContext = context
+	w.connMu.Unlock()
+	if old != nil {
+		C.releaseObject(old)
+	}
+}
+
+// startForwarding forwards the processor's message channel to the peer
+// until stopForwarding. It runs from a successful initialize to terminate,
+// so messages flow whenever a peer is connected.
+func (w *componentWrapper) startForwarding(ch *message.Channel) {
+	w.connMu.Lock()
+	w.forwardCh = ch
+	w.connMu.Unlock()
+}
+
+// stopForwarding stops forwarding, leaving unsent messages queued
+func (w *componentWrapper) stopForwarding() {
+	w.connMu.Lock()
+	w.forwardCh = nil
+	w.connMu.Unlock()
+}
+
+// forwardMessages sends every queued message to the peer as an IMessage.
+// createInstance and notify are only allowed on the host's UI thread, so it
+// runs from the controller and connection callbacks the host makes there,
+// like dispatchRemote. Without a peer the messages stay queued for an
+// in-process editor.
+func (w *componentWrapper) forwardMessages() {
+	w.connMu.Lock()
+	defer w.connMu.Unlock()
+
+	if w.forwardCh == nil || w.peer == nil || w.hostContext == nil {
+		return
+	}
+	w.forwardCh.Receive(func(m message.Message) {
+		msg := C.createMessage(w.hostContext)
+		if msg == nil {
+			return
+		}
+		id := C.CString(m.ID)
+		var data unsafe.Pointer
+		if len(m.Data) > 0 {
+			data = unsafe.Pointer(&m.Data[0])
+		}
+		C.sendMessage(w.peer, msg, id, dataAttribute, data, C.uint32_t(len(m.Data)))
+		C.free(unsafe.Pointer(id))
+		C.releaseObject(msg)
+	})
+}
+
+// disconnect releases the peer
+func (w *componentWrapper) disconnect() {
+	w.connMu.Lock()
+	peer := w.peer
+	w.peer = nil
+	w.connMu.Unlock()
+	if peer != nil {
+		C.releaseObject(peer)
+	}
+}
+
+// IConnectionPoint callbacks
+//
+//export GoConnectionConnect
+func GoConnectionConnect(componentPtr unsafe.Pointer, other unsafe.Pointer) C.Steinberg_tresult {
+	defer recoverPanic("GoConnectionConnect")
+
+	wrapper := getComponent(uintptr(componentPtr))
+	if wrapper == nil || other == nil {
+		return C.Steinberg_tresult(vst3.ResultInvalidArgument)
+	}
+
+	wrapper.connMu.Lock()
+	if wrapper.peer != nil {
+		// Already connected
+		wrapper.connMu.Unlock()
+		return C.Steinberg_tresult(vst3.ResultFalse)
+	}
+	C.addRefObject(other)
+	wrapper.peer = other
+	wrapper.connMu.Unlock()
+	return C.Steinberg_tresult(vst3.ResultOK)
+}
+
+//export GoConnectionDisconnect
+func GoConnectionDisconnect(componentPtr unsafe.Pointer, other unsafe.Pointer) C.Steinberg_tresult {
+	defer recoverPanic("GoConnectionDisconnect")
+
+	wrapper := getComponent(uintptr(componentPtr))
+	if wrapper == nil {
+		return C.Steinberg_tresult(vst3.ResultFalse)
+	}
+
+	wrapper.connMu.Lock()
+	connected := wrapper.peer != nil && wrapper.peer == other
+	wrapper.connMu.Unlock()
+	if !connected {
+		return C.Steinberg_tresult(vst3.ResultFalse)
+	}
+
+	wrapper.disconnect()
+	return C.Steinberg_tresult(vst3.ResultOK)
+}
+
+//export GoConnectionNotify
+func GoConnectionNotify(componentPtr unsafe.Pointer, msg unsafe.Pointer) C.Steinberg_tresult {
+	defer recoverPanic("GoConnectionNotify")
+
+	wrapper := getComponent(uintptr(componentPtr))
+	if wrapper == nil || msg == nil {
+		return C.Steinberg_tresult(vst3.ResultInvalidArgument)
+	}
+
+	wrapper.forwardMessages()
+
+	receiver, ok := wrapper.processor.(MessageReceiver)
+	component, isImpl := wrapper.component.(*componentImpl)
+	if !ok || !isImpl {
+		return C.Steinberg_tresult(vst3.ResultFalse)
+	}
+
+	id := C.getMessageID(msg)
+	if id == nil {
+		return C.Steinberg_tresult(vst3.ResultFalse)
+	}
+
+	m := message.Message{ID: C.GoString(id)}
+	var data unsafe.Pointer
+	var size C.uint32_t
+	if C.getMessageBinary(msg, dataAttribute, &data, &size) == C.int32_t(vst3.ResultOK) && data != nil {
+		m.Data = C.GoBytes(data, C.int(size))
+	}
+
+	component.deliverMessage(receiver, m)
+	return C.Steinberg_tresult(vst3.ResultOK)
+}
@@ -0,0 +1,111 @@
+package plugin
+
+// #cgo CFLAGS: -I../../include
+// #include "../../include/vst3/vst3_c_api.h"
+// #include "../../bridge/bridge.h"
+import "C"
+import (
+	"fmt"
+	"sync"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/vst3"
+)
+
+var (
+	// Items of context menus the host still holds, indexed by target ID
+	menuTargets      = make(map[uintptr][]param.MenuItem)
+	menuTargetsMu    sync.Mutex
+	nextMenuTargetID uintptr = 1
+)
+
+// registerMenuTarget keeps a menu's items until the host releases its target
+func registerMenuTarget(items []param.MenuItem) uintptr {
+	menuTargetsMu.Lock()
+	defer menuTargetsMu.Unlock()
+	id := nextMenuTargetID
+	nextMenuTargetID++
+	menuTargets[id] = items
+	return id
+}
+
+// menuItemFlags returns the IContextMenuItem flags for an item
+func menuItemFlags(item param.MenuItem) int32 {
+	var flags int32
+	if item.Separator {
+		flags |= vst3.ContextMenuItemIsSeparator
+	}
+	if item.Disabled {
+		flags |= vst3.ContextMenuItemIsDisabled
+	}
+	if item.Checked {
+		flags |= vst3.ContextMenuItemIsChecked
+	}
+	return flags
+}
+
+// showContextMenu opens the host's context menu for a parameter through
+// IComponentHandler3, appending the plugin's items, implementing
+// param.ContextMenuFunc
+func (w *componentWrapper) showContextMenu(id uint32, x, y int32, items []param.MenuItem) error {
+	w.handlerMu.RLock()
+	handler := w.componentHandler
+	w.handlerMu.RUnlock()
+
+	if handler == nil {
+		return param.ErrNoContextMenu
+	}
+
+	// There is no plug view until the framework creates editors
+	menu := C.createContextMenu(handler, nil, C.uint32_t(id))
+	if menu == nil {
+		return param.ErrNoContextMenu
+	}
+	defer C.releaseObject(menu)
+
+	if len(items) > 0 {
+		targetID := registerMenuTarget(items)
+		target := C.createContextMenuTarget(unsafe.Pointer(targetID))
+		if target == nil {
+			GoContextMenuRelease(unsafe.Pointer(targetID))
+			return fmt.Errorf("context menu for parameter %d: %w", id, vst3.ErrInvalidState)
+		}
+		var name [128]uint16
+		for tag, item := range items {
+			clear(name[:])
+			copy(name[:len(name)-1], utf16.Encode([]rune(item.Name)))
+			C.addContextMenuItem(menu, (*C.uint16_t)(unsafe.Pointer(&name[0])), C.int32_t(tag), C.int32_t(menuItemFlags(item)), target)
+		}
+		// The menu holds its own references to the target
+		C.releaseObject(target)
+	}
+
+	if result := C.popupContextMenu(menu, C.int32_t(x), C.int32_t(y)); result != C.int32_t(vst3.ResultOK) {
+		return fmt.Errorf("context menu for parameter %d: popup returned %d", id, int32(result))
+	}
+	return nil
+}
+
+//export GoContextMenuExecute
+func GoContextMenuExecute(targetPtr unsafe.Pointer, tag C.int32_t) C.Steinberg_tresult {
+	defer recoverPanic("GoContextMenuExecute")
+
+	menuTargetsMu.Lock()
+	items := menuTargets[uintptr(targetPtr)]
+	menuTargetsMu.Unlock()
+
+	if tag < 0 || int(tag) >= len(items) || items[tag].Action == nil {
+		return C.Steinberg_tresult(vst3.ResultFalse)
+	}
+	items[tag].Action()
+	return C.Steinberg_tresult(vst3.ResultOK)
+}
+
+//export GoContextMenuRelease
+func GoContextMenuRelease(targetPtr unsafe.Pointer) {
+	menuTargetsMu.Lock()
+	defer menuTargetsMu.Unlock()
+	delete(menuTargets, uintptr(targetPtr))
+}
@@ -47,7 +47,10 @@ func GoEditControllerGetParameterInfo(componentPtr unsafe.Pointer, paramIndex C.
 	}
 
 	paramInfo, err := wrapper.component.GetParameterInfo(int32(paramIndex))
-	if err != nil || paramInfo == nil {
+	if err != nil {
+		return C.Steinberg_tresult(vst3.ReportError("GoEditControllerGetParameterInfo", err))
+	}
+	if paramInfo == nil {
 		return C.Steinberg_tresult(vst3.ResultFalse)
 	}
 
@@ -106,7 +109,7 @@ func GoEditControllerGetParamStringByValue(componentPtr unsafe.Pointer, id C.Ste
 	// Get the formatted string
 	str, err := wrapper.component.GetParamStringByValue(uint32(id), float64(valueNormalized))
 	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
+		return C.Steinberg_tresult(vst3.ReportError("GoEditControllerGetParamStringByValue", err))
 	}
 
 	// Convert to UTF16 for VST3
@@ -128,7 +131,7 @@ func GoEditControllerGetParamValueByString(componentPtr unsafe.Pointer, id C.Ste
 	// Parse the value
 	value, err := wrapper.component.GetParamValueByString(uint32(id), str)
 	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
+		return C.Steinberg_tresult(vst3.ReportError("GoEditControllerGetParamValueByString", err))
 	}
 
 	*valueNormalized = C.Steinberg_Vst_ParamValue(value)
@@ -161,6 +164,8 @@ func GoEditControllerGetParamNormalized(componentPtr unsafe.Pointer, id C.uint32
 	if wrapper == nil {
 		return 0
 	}
+	wrapper.dispatchRemote()
+	wrapper.forwardMessages()
 
 	return C.double(wrapper.component.GetParamNormalized(uint32(id)))
 }
@@ -171,12 +176,11 @@ func GoEditControllerSetParamNormalized(componentPtr unsafe.Pointer, id C.uint32
 	if wrapper == nil {
 		return C.Steinberg_tresult(vst3.ResultFalse)
 	}
+	wrapper.dispatchRemote()
+	wrapper.forwardMessages()
 
 	err := wrapper.component.SetParamNormalized(uint32(id), float64(value))
-	if err != nil {
-		return C.Steinberg_tresult(vst3.ResultFalse)
-	}
-	return C.Steinberg_tresult(vst3.ResultOK)
+	return C.Steinberg_tresult(vst3.ReportError("GoEditControllerSetParamNormalized", err))
 }
 
 //export GoEditControllerSetComponentHandler
@@ -199,3 +203,13 @@ func GoEditControllerCreateView(componentPtr unsafe.Pointer, name *C.char) unsaf
 	// No GUI support
 	return nil
 }
+
+// dispatchRemote handles queued remote control requests. The host calls the
+// controller on its UI thread, the only thread it accepts edits on, and
+// calls getParamNormalized and setParamNormalized often enough to keep
+// remote edits flowing during playback and while its editor is open.
+func (w *componentWrapper) dispatchRemote() {
+	if rp, ok := w.processor.(RemoteProcessor); ok && rp.Remote() != nil {
+		rp.Remote().Dispatch()
+	}
+}
@@ -0,0 +1,68 @@
+package rt
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Float64 is an atomic float64, for meter values and other numbers one
+// thread writes and others read
+type Float64 struct {
+	bits atomic.Uint64
+}
+
+// Load returns the value
+func (f *Float64) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+// Store sets the value
+func (f *Float64) Store(v float64) {
+	f.bits.Store(math.Float64bits(v))
+}
+
+// Swap sets the value and returns the previous one
+func (f *Float64) Swap(v float64) float64 {
+	return math.Float64frombits(f.bits.Swap(math.Float64bits(v)))
+}
+
+// StoreMax raises the value to v if v is larger, for peak meters that
+// several blocks update before the reader resets them with Swap
+func (f *Float64) StoreMax(v float64) {
+	for {
+		old := f.bits.Load()
+		if math.Float64frombits(old) >= v || f.bits.CompareAndSwap(old, math.Float64bits(v)) {
+			return
+		}
+	}
+}
+
+// Float32 is an atomic float32
+type Float32 struct {
+	bits atomic.Uint32
+}
+
+// Load returns the value
+func (f *Float32) Load() float32 {
+	return math.Float32frombits(f.bits.Load())
+}
+
+// Store sets the value
+func (f *Float32) Store(v float32) {
+	f.bits.Store(math.Float32bits(v))
+}
+
+// Swap sets the value and returns the previous one
+func (f *Float32) Swap(v float32) float32 {
+	return math.Float32frombits(f.bits.Swap(math.Float32bits(v)))
+}
+
+// StoreMax raises the value to v if v is larger
+func (f *Float32) StoreMax(v float32) {
+	for {
+		old := f.bits.Load()
+		if math.Float32frombits(old) >= v || f.bits.CompareAndSwap(old, math.Float32bits(v)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package rt
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFloat64(t *testing.T) {
+	var f Float64
+	f.Store(0.5)
+	if f.Load() != 0.5 || f.Swap(0.25) != 0.5 || f.Load() != 0.25 {
+		t.Error("Store, Swap and Load disagree")
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				f.StoreMax(float64(g*1000 + i))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if f.Load() != 3999 {
+		t.Errorf("StoreMax = %g, want 3999", f.Load())
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	var f Float32
+	f.Store(-1)
+	f.StoreMax(-2)
+	if f.Load() != -1 {
+		t.Errorf("StoreMax lowered the value to %g", f.Load())
+	}
+	f.StoreMax(3)
+	if f.Swap(0) != 3 || f.Load() != 0 {
+		t.Error("Swap should return the peak and reset it")
+	}
+}
+
+func BenchmarkFloat64(b *testing.B) {
+	var f Float64
+	for i := 0; i < b.N; i++ {
+		f.StoreMax(float64(i & 1023))
+		if i&1023 == 1023 {
+			f.Swap(0)
+		}
+		_ = f.Load()
+	}
+}
+
+func BenchmarkFloat32(b *testing.B) {
+	var f Float32
+	for i := 0; i < b.N; i++ {
+		f.StoreMax(float32(i & 1023))
+		if i&1023 == 1023 {
+			f.Swap(0)
+		}
+		_ = f.Load()
+	}
+}
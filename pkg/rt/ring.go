@@ -0,0 +1,111 @@
+// Package rt provides lock-free data structures that are safe to use from
+// the audio thread: a single-producer single-consumer ring buffer, a triple
+// buffer for handing complete values between threads, and atomic floats.
+// None of them allocate, lock or block after construction.
+package rt
+
+import "sync/atomic"
+
+// cacheLine pads fields written by different threads onto separate cache
+// lines so the producer and consumer do not slow each other down
+const cacheLine = 64
+
+// Ring is a bounded FIFO for exactly one producer and one consumer, each on
+// its own thread. Push and Pop never block: Push fails when the ring is
+// full and Pop when it is empty.
+type Ring[T any] struct {
+	buf  []T
+	mask uint64
+
+	_    [cacheLine]byte
+	head atomic.Uint64 // Next slot to read, written by the consumer
+	_    [cacheLine - 8]byte
+	tail atomic.Uint64 // Next slot to write, written by the producer
+	_    [cacheLine - 8]byte
+}
+
+// NewRing creates a ring holding at least capacity values; the capacity is
+// rounded up to a power of two
+func NewRing[T any](capacity int) *Ring[T] {
+	size := 1
+	for size < capacity {
+		size <<= 1
+	}
+	return &Ring[T]{
+		buf:  make([]T, size),
+		mask: uint64(size - 1),
+	}
+}
+
+// Cap returns the number of values the ring holds
+func (r *Ring[T]) Cap() int {
+	return len(r.buf)
+}
+
+// Len returns the number of values waiting to be read. From threads other
+// than the producer and consumer it is only an estimate.
+func (r *Ring[T]) Len() int {
+	return int(r.tail.Load() - r.head.Load())
+}
+
+// Push appends a value and returns false if the ring is full (producer only)
+func (r *Ring[T]) Push(v T) bool {
+	tail := r.tail.Load()
+	if tail-r.head.Load() == uint64(len(r.buf)) {
+		return false
+	}
+	r.buf[tail&r.mask] = v
+	r.tail.Store(tail + 1)
+	return true
+}
+
+// Pop removes the oldest value and returns false if the ring is empty
+// (consumer only)
+func (r *Ring[T]) Pop() (T, bool) {
+	var zero T
+	head := r.head.Load()
+	if head == r.tail.Load() {
+		return zero, false
+	}
+	v := r.buf[head&r.mask]
+	r.buf[head&r.mask] = zero // Release references for the collector
+	r.head.Store(head + 1)
+	return v, true
+}
+
+// Write appends as many values from src as fit and returns the number
+// written (producer only)
+func (r *Ring[T]) Write(src []T) int {
+	tail := r.tail.Load()
+	n := min(len(src), len(r.buf)-int(tail-r.head.Load()))
+	for i := 0; i < n; i++ {
+		r.buf[(tail+uint64(i))&r.mask] = src[i]
+	}
+	r.tail.Store(tail + uint64(n))
+	return n
+}
+
+// Read removes up to len(dst) values into dst and returns the number read
+// (consumer only)
+func (r *Ring[T]) Read(dst []T) int {
+	var zero T
+	head := r.head.Load()
+	n := min(len(dst), int(r.tail.Load()-head))
+	for i := 0; i < n; i++ {
+		slot := (head + uint64(i)) & r.mask
+		dst[i] = r.buf[slot]
+		r.buf[slot] = zero
+	}
+	r.head.Store(head + uint64(n))
+	return n
+}
+
+// Drop discards every value waiting to be read (consumer only)
+func (r *Ring[T]) Drop() {
+	var zero T
+	head, tail := r.head.Load(), r.tail.Load()
+	for i := head; i != tail; i++ {
+		r.buf[i&r.mask] = zero
+	}
+	r.head.Store(tail)
+}
@@ -0,0 +1,117 @@
+package rt
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestRingBasics(t *testing.T) {
+	r := NewRing[int](5)
+	if r.Cap() != 8 {
+		t.Fatalf("Cap = %d, want 8", r.Cap())
+	}
+	if _, ok := r.Pop(); ok {
+		t.Error("Pop on an empty ring should fail")
+	}
+	for i := 0; i < 8; i++ {
+		if !r.Push(i) {
+			t.Fatalf("Push %d failed", i)
+		}
+	}
+	if r.Push(8) || r.Len() != 8 {
+		t.Errorf("full ring: Push succeeded or Len = %d", r.Len())
+	}
+	for i := 0; i < 8; i++ {
+		if v, ok := r.Pop(); !ok || v != i {
+			t.Fatalf("Pop = %d, %v, want %d", v, ok, i)
+		}
+	}
+
+	// Bulk transfers wrap around the end of the buffer
+	if n := r.Write([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}); n != 8 {
+		t.Errorf("Write = %d, want 8", n)
+	}
+	dst := make([]int, 5)
+	if n := r.Read(dst); n != 5 || dst[0] != 1 || dst[4] != 5 {
+		t.Errorf("Read = %d, %v", n, dst)
+	}
+	r.Drop()
+	if r.Len() != 0 || r.Read(dst) != 0 {
+		t.Error("Drop should empty the ring")
+	}
+}
+
+func TestRingConcurrent(t *testing.T) {
+	const count = 100000
+	r := NewRing[int](64)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < count; {
+			if r.Push(i) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	dst := make([]int, 16)
+	for next := 0; next < count; {
+		n := r.Read(dst)
+		if n == 0 {
+			runtime.Gosched()
+		}
+		for _, v := range dst[:n] {
+			if v != next {
+				t.Fatalf("got %d, want %d", v, next)
+			}
+			next++
+		}
+	}
+	wg.Wait()
+}
+
+func TestRingPushPopAllocs(t *testing.T) {
+	r := NewRing[float64](16)
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Push(1)
+		r.Pop()
+	})
+	if allocs != 0 {
+		t.Errorf("Push and Pop allocate %.1f times", allocs)
+	}
+}
+
+func BenchmarkRingPushPop(b *testing.B) {
+	r := NewRing[float32](1024)
+	for i := 0; i < b.N; i++ {
+		r.Push(float32(i))
+		r.Pop()
+	}
+}
+
+func BenchmarkRingConcurrent(b *testing.B) {
+	r := NewRing[int](1024)
+	done := make(chan struct{})
+	go func() {
+		for received := 0; received < b.N; {
+			if _, ok := r.Pop(); ok {
+				received++
+			} else {
+				runtime.Gosched()
+			}
+		}
+		close(done)
+	}()
+	for i := 0; i < b.N; {
+		if r.Push(i) {
+			i++
+		} else {
+			runtime.Gosched()
+		}
+	}
+	<-done
+}
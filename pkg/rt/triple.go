@@ -0,0 +1,92 @@
+package rt
+
+import "sync/atomic"
+
+// Triple buffer state: the index of the shared middle buffer, with a flag
+// set when it holds a value the consumer has not seen yet
+const (
+	tripleIndexMask = 3
+	tripleFresh     = 4
+)
+
+// TripleBuffer hands complete values from one producer to one consumer.
+// The producer fills the back buffer and publishes it; the consumer reads
+// the newest published value. Neither side ever waits, and each owns its
+// buffer until it swaps, so a value is never read while half written.
+// Values published faster than the consumer reads are skipped.
+type TripleBuffer[T any] struct {
+	bufs  [3]T
+	back  int // Producer owned
+	_     [cacheLine]byte
+	front int // Consumer owned
+	_     [cacheLine]byte
+	state atomic.Uint32
+}
+
+// NewTripleBuffer creates a triple buffer whose three buffers are made by
+// newValue, so slices and maps are not shared between them. A nil
+// newValue leaves them at the zero value.
+func NewTripleBuffer[T any](newValue func() T) *TripleBuffer[T] {
+	t := &TripleBuffer[T]{back: 0, front: 1}
+	if newValue != nil {
+		for i := range t.bufs {
+			t.bufs[i] = newValue()
+		}
+	}
+	t.state.Store(2)
+	return t
+}
+
+// Back returns the buffer the producer fills before calling Publish
+// (producer only)
+func (t *TripleBuffer[T]) Back() *T {
+	return &t.bufs[t.back]
+}
+
+// Publish makes the back buffer the newest value and gives the producer
+// another buffer to fill (producer only). The new back buffer holds an
+// older value, so fill it completely.
+func (t *TripleBuffer[T]) Publish() {
+	old := t.state.Swap(uint32(t.back) | tripleFresh)
+	t.back = int(old & tripleIndexMask)
+}
+
+// Read returns the newest published value and whether it is new since the
+// last Read (consumer only). The value stays valid until the next Read.
+func (t *TripleBuffer[T]) Read() (*T, bool) {
+	if t.state.Load()&tripleFresh == 0 {
+		return &t.bufs[t.front], false
+	}
+	old := t.state.Swap(uint32(t.front))
+	t.front = int(old & tripleIndexMask)
+	return &t.bufs[t.front], true
+}
+
+// Front returns the value of the last Read (consumer only)
+func (t *TripleBuffer[T]) Front() *T {
+	return &t.bufs[t.front]
+}
+
+// Snapshot passes copies of a value from one writer to one reader through
+// a triple buffer. T should be a plain value type: slices or pointers
+// inside it would be shared by the writer and reader.
+type Snapshot[T any] struct {
+	buf *TripleBuffer[T]
+}
+
+// NewSnapshot creates a snapshot holding the zero value
+func NewSnapshot[T any]() *Snapshot[T] {
+	return &Snapshot[T]{buf: NewTripleBuffer[T](nil)}
+}
+
+// Store publishes a copy of v (writer only)
+func (s *Snapshot[T]) Store(v T) {
+	*s.buf.Back() = v
+	s.buf.Publish()
+}
+
+// Load returns the newest stored value (reader only)
+func (s *Snapshot[T]) Load() T {
+	v, _ := s.buf.Read()
+	return *v
+}
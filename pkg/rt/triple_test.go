@@ -0,0 +1,105 @@
+package rt
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestTripleBuffer(t *testing.T) {
+	tb := NewTripleBuffer(func() []int { return make([]int, 4) })
+	if v, fresh := tb.Read(); fresh || len(*v) != 4 {
+		t.Fatal("nothing should be published yet")
+	}
+
+	back := *tb.Back()
+	for i := range back {
+		back[i] = 1
+	}
+	tb.Publish()
+	// Buffers are separate allocations
+	(*tb.Back())[0] = 9
+
+	v, fresh := tb.Read()
+	if !fresh || (*v)[0] != 1 || (*v)[3] != 1 {
+		t.Errorf("Read = %v, %v", *v, fresh)
+	}
+	if _, fresh := tb.Read(); fresh {
+		t.Error("second Read should not be fresh")
+	}
+	if tb.Front() != v {
+		t.Error("Front should return the last read buffer")
+	}
+
+	// Only the newest of several publishes is read
+	for i := 2; i <= 4; i++ {
+		(*tb.Back())[0] = i
+		tb.Publish()
+	}
+	if v, _ := tb.Read(); (*v)[0] != 4 {
+		t.Errorf("Read = %d, want the newest value 4", (*v)[0])
+	}
+}
+
+func TestTripleBufferConcurrent(t *testing.T) {
+	type frame struct{ a, b [8]int }
+	const count = 50000
+	tb := NewTripleBuffer[frame](nil)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= count; i++ {
+			f := tb.Back()
+			for j := range f.a {
+				f.a[j], f.b[j] = i, -i
+			}
+			tb.Publish()
+		}
+	}()
+
+	last := 0
+	for last < count {
+		f, fresh := tb.Read()
+		if !fresh {
+			runtime.Gosched()
+			continue
+		}
+		v := f.a[0]
+		for j := range f.a {
+			if f.a[j] != v || f.b[j] != -v {
+				t.Fatalf("torn frame %v", *f)
+			}
+		}
+		if v < last {
+			t.Fatalf("went back from %d to %d", last, v)
+		}
+		last = v
+	}
+	wg.Wait()
+}
+
+func TestSnapshot(t *testing.T) {
+	type meter struct{ peak, rms float64 }
+	s := NewSnapshot[meter]()
+	if s.Load() != (meter{}) {
+		t.Error("new snapshot should hold the zero value")
+	}
+	s.Store(meter{0.5, 0.25})
+	s.Store(meter{0.8, 0.4})
+	if got := s.Load(); got != (meter{0.8, 0.4}) {
+		t.Errorf("Load = %v", got)
+	}
+	if got := s.Load(); got.peak != 0.8 {
+		t.Errorf("repeated Load = %v", got)
+	}
+}
+
+func BenchmarkTripleBufferPublishRead(b *testing.B) {
+	tb := NewTripleBuffer(func() []float32 { return make([]float32, 512) })
+	for i := 0; i < b.N; i++ {
+		(*tb.Back())[0] = float32(i)
+		tb.Publish()
+		tb.Read()
+	}
+}
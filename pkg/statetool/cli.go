@@ -0,0 +1,244 @@
+package statetool
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/host"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// usage is printed for a missing or unknown subcommand
+const usage = `usage: vst3go-state <command> [flags] FILE
+
+commands:
+  dump     print the parameter values and custom state in FILE
+  convert  write the state in FILE in another format
+  inject   replace the state in a preset with the state in FILE`
+
+// Main runs the state command line on the plugins registered in this
+// program and exits on error. The plugins only name and format parameter
+// values; state is read and written without them.
+func Main() {
+	if err := Run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// Run parses state command line arguments and runs them
+func Run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", usage)
+	}
+	switch args[0] {
+	case "dump":
+		return runDump(args[1:], stdout)
+	case "convert":
+		return runConvert(args[1:], stdout)
+	case "inject":
+		return runInject(args[1:], stdout)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprintln(stdout, usage)
+		return flag.ErrHelp
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
+
+// parseFile parses subcommand flags followed by a single file argument
+func parseFile(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		return "", fmt.Errorf("%s needs exactly one state file", fs.Name())
+	}
+	return fs.Arg(0), nil
+}
+
+func runDump(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	var (
+		name   = fs.String("plugin", "", "plugin name or ID for parameter names; default the plugin matching the class ID")
+		asJSON = fs.Bool("json", false, "print the dump as JSON")
+	)
+	path, err := parseFile(fs, args)
+	if err != nil {
+		return err
+	}
+	b, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	params, err := findParameters(*name, b)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		data, err := b.Encode(FormatJSON, params)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(data)
+		return err
+	}
+	d, err := b.Dump(params)
+	if err != nil {
+		return err
+	}
+	return printDump(stdout, d)
+}
+
+// printDump writes a dump as aligned text
+func printDump(w io.Writer, d *Dump) error {
+	if d.ClassID != "" {
+		fmt.Fprintf(w, "class  %s\n", d.ClassID)
+	}
+	keys := make([]string, 0, len(d.Meta))
+	for key := range d.Meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "meta   %s = %s\n", key, d.Meta[key])
+	}
+	fmt.Fprintf(w, "%d values, %d bytes custom state\n", len(d.Values), len(d.Custom))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, v := range d.Values {
+		fmt.Fprintf(tw, "%d\t%s\t%.6f\t%s\n", v.ID, v.Name, v.Value, v.Display)
+	}
+	return tw.Flush()
+}
+
+func runConvert(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	var (
+		out   = fs.String("out", "", "output file; .vstpreset, .json, .b64 or .txt for base64, else bare state")
+		name  = fs.String("plugin", "", "plugin name or ID, for the class ID and parameter names")
+		class = fs.String("class", "", "plugin class ID as 32 hex digits, for .vstpreset output")
+	)
+	path, err := parseFile(fs, args)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("-out is required")
+	}
+	b, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if *class != "" {
+		if err := setClassID(b, *class); err != nil {
+			return err
+		}
+	}
+	params, err := findParameters(*name, b)
+	if err != nil {
+		return err
+	}
+	return writeBlob(stdout, b, *out, params)
+}
+
+func runInject(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("inject", flag.ContinueOnError)
+	var (
+		from = fs.String("state", "", "state file whose values and custom state to inject")
+		out  = fs.String("out", "", "output file; default overwrites the preset")
+	)
+	path, err := parseFile(fs, args)
+	if err != nil {
+		return err
+	}
+	if *from == "" {
+		return errors.New("-state is required")
+	}
+	if *out == "" {
+		*out = path
+	}
+	b, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	src, err := ReadFile(*from)
+	if err != nil {
+		return err
+	}
+	b.Inject(src)
+	params, err := findParameters("", b)
+	if err != nil {
+		return err
+	}
+	return writeBlob(stdout, b, *out, params)
+}
+
+// writeBlob writes state to a file in the format its extension selects
+func writeBlob(stdout io.Writer, b *Blob, path string, params *param.Registry) error {
+	format := FormatForPath(path)
+	data, err := b.Encode(format, params)
+	if errors.Is(err, ErrNoClassID) {
+		return fmt.Errorf("%w; pass -plugin or -class", err)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote %d values to %s\n", len(b.State.Values), path)
+	return nil
+}
+
+// setClassID sets the class ID from hex digits, ignoring dashes and braces
+func setClassID(b *Blob, text string) error {
+	clean := strings.NewReplacer("-", "", "{", "", "}", "").Replace(text)
+	id, err := hex.DecodeString(clean)
+	if err != nil || len(id) != len(b.ClassID) {
+		return fmt.Errorf("class ID %q is not 32 hex digits", text)
+	}
+	copy(b.ClassID[:], id)
+	return nil
+}
+
+// findParameters returns the parameters of the plugin named by key, or of
+// the registered plugin whose class ID matches the state when key is empty.
+// The plugin's class ID fills in a missing one. It returns nil when no
+// plugin matches, as state can be handled without its parameters.
+func findParameters(key string, b *Blob) (*param.Registry, error) {
+	var p plugin.Plugin
+	if key != "" {
+		var err error
+		if p, err = host.FindPlugin(key); err != nil {
+			return nil, err
+		}
+	} else if b.HasClassID() {
+		for _, candidate := range plugin.Registered() {
+			info := candidate.GetInfo()
+			if info.UID() == b.ClassID {
+				p = candidate
+				break
+			}
+		}
+	}
+	if p == nil {
+		return nil, nil
+	}
+
+	if !b.HasClassID() {
+		info := p.GetInfo()
+		b.ClassID = info.UID()
+	}
+	return p.CreateProcessor().GetParameters(), nil
+}
@@ -0,0 +1,275 @@
+// Package statetool reads, dumps and rewrites plugin state outside a host,
+// for debugging state issues and writing state migration tests. It accepts
+// state in every form it turns up in: .vstpreset files, the bare component
+// state a plugin returns from getState as DAWs embed it in projects, either
+// of those as base64 text, and the JSON dumps it writes itself, which can be
+// edited by hand and converted back.
+package statetool
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/preset"
+	"github.com/justyntemme/vst3go/pkg/framework/state"
+)
+
+var (
+	// ErrUnknownState is returned for data that is not plugin state in a
+	// supported form
+	ErrUnknownState = errors.New("not plugin state")
+	// ErrNoClassID is returned when writing a .vstpreset file for state
+	// that does not name its plugin
+	ErrNoClassID = errors.New("state has no plugin class ID")
+)
+
+// Format is a form plugin state is written in
+type Format int
+
+// State formats
+const (
+	// FormatState is the bare component state as returned by getState
+	FormatState Format = iota
+	// FormatBase64 is the bare state as base64 text
+	FormatBase64
+	// FormatVSTPreset is a .vstpreset file
+	FormatVSTPreset
+	// FormatJSON is a readable JSON dump
+	FormatJSON
+)
+
+// FormatForPath returns the format matching a file extension: .vstpreset,
+// .json, .b64 or .txt for base64, and bare state for anything else
+func FormatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case preset.ExtVSTPreset:
+		return FormatVSTPreset
+	case preset.ExtJSON:
+		return FormatJSON
+	case ".b64", ".txt":
+		return FormatBase64
+	}
+	return FormatState
+}
+
+// Blob is decoded plugin state with the preset chunks that came with it
+type Blob struct {
+	// ClassID is the plugin class, zero if the state did not name it
+	ClassID [16]byte
+
+	// State holds the parameter values and custom state
+	State *state.Data
+
+	// Controller and MetaInfo are the other .vstpreset chunks, nil if the
+	// state did not come from a preset file
+	Controller []byte
+	MetaInfo   []byte
+}
+
+// HasClassID reports whether the blob names its plugin
+func (b *Blob) HasClassID() bool {
+	return b.ClassID != [16]byte{}
+}
+
+// Metadata returns the preset metadata attributes by ID, empty for bare
+// state
+func (b *Blob) Metadata() (map[string]string, error) {
+	f := &preset.VSTPresetFile{MetaInfo: b.MetaInfo}
+	return f.Metadata()
+}
+
+// Parse decodes plugin state in any supported form, detecting which
+func Parse(data []byte) (*Blob, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("VST3GO")):
+		st, err := state.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownState, err)
+		}
+		return &Blob{State: st}, nil
+	case bytes.HasPrefix(data, []byte("VST3")):
+		return parseVSTPreset(data)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var d Dump
+		if err := json.Unmarshal(trimmed, &d); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownState, err)
+		}
+		return d.Blob()
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(trimmed))
+	if err != nil || len(decoded) == 0 || bytes.HasPrefix(bytes.TrimSpace(decoded), []byte("{")) {
+		return nil, ErrUnknownState
+	}
+	return Parse(decoded)
+}
+
+// parseVSTPreset decodes the state in a .vstpreset file
+func parseVSTPreset(data []byte) (*Blob, error) {
+	f, err := preset.ReadVSTPresetFile(data)
+	if err != nil {
+		return nil, err
+	}
+	st, err := state.Decode(bytes.NewReader(f.Component))
+	if err != nil {
+		return nil, fmt.Errorf("%w: component state: %v", preset.ErrInvalidPreset, err)
+	}
+	return &Blob{
+		ClassID:    f.ClassID,
+		State:      st,
+		Controller: f.Controller,
+		MetaInfo:   f.MetaInfo,
+	}, nil
+}
+
+// ReadFile reads and decodes a state file
+func ReadFile(path string) (*Blob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return b, nil
+}
+
+// Component returns the bare component state, the bytes a plugin's
+// setState reads
+func (b *Blob) Component() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := b.State.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the state in a format. Parameter names and display values
+// in JSON dumps come from params, which may be nil.
+func (b *Blob) Encode(format Format, params *param.Registry) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		d, err := b.Dump(params)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+
+	component, err := b.Component()
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatState:
+		return component, nil
+	case FormatBase64:
+		return []byte(base64.StdEncoding.EncodeToString(component) + "\n"), nil
+	case FormatVSTPreset:
+		if !b.HasClassID() {
+			return nil, ErrNoClassID
+		}
+		f := &preset.VSTPresetFile{
+			ClassID:    b.ClassID,
+			Component:  component,
+			Controller: b.Controller,
+			MetaInfo:   b.MetaInfo,
+		}
+		if f.MetaInfo == nil {
+			if err := f.SetMetadata(nil); err != nil {
+				return nil, err
+			}
+		}
+		return f.Encode(), nil
+	}
+	return nil, fmt.Errorf("unknown state format %d", format)
+}
+
+// Inject replaces the parameter values and custom state of b with those of
+// src, keeping b's class ID and preset chunks
+func (b *Blob) Inject(src *Blob) {
+	b.State = src.State
+}
+
+// Dump is the JSON form of plugin state
+type Dump struct {
+	ClassID string            `json:"classID,omitempty"`
+	Meta    map[string]string `json:"meta,omitempty"`
+	Values  []DumpValue       `json:"values"`
+
+	// Custom is the processor's custom state, base64 in JSON
+	Custom []byte `json:"custom,omitempty"`
+}
+
+// DumpValue is one parameter value in a dump. Name and Display are for
+// reading and are ignored when the dump is converted back.
+type DumpValue struct {
+	ID      uint32  `json:"id"`
+	Name    string  `json:"name,omitempty"`
+	Value   float64 `json:"value"`
+	Display string  `json:"display,omitempty"`
+}
+
+// Dump returns the readable form of the state, naming and formatting the
+// values of parameters found in params, which may be nil
+func (b *Blob) Dump(params *param.Registry) (*Dump, error) {
+	meta, err := b.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	d := &Dump{Values: make([]DumpValue, 0, len(b.State.Values)), Custom: b.State.Custom}
+	if b.HasClassID() {
+		d.ClassID = strings.ToUpper(hex.EncodeToString(b.ClassID[:]))
+	}
+	if len(meta) > 0 {
+		d.Meta = meta
+	}
+	for _, v := range b.State.Values {
+		dv := DumpValue{ID: v.ID, Value: v.Value}
+		if params != nil {
+			if p := params.Get(v.ID); p != nil {
+				dv.Name = p.Name
+				dv.Display = p.FormatValue(v.Value)
+			}
+		}
+		d.Values = append(d.Values, dv)
+	}
+	return d, nil
+}
+
+// Blob converts a dump back to state
+func (d *Dump) Blob() (*Blob, error) {
+	b := &Blob{State: &state.Data{Values: make([]state.Value, len(d.Values)), Custom: d.Custom}}
+	if d.ClassID != "" {
+		id, err := hex.DecodeString(d.ClassID)
+		if err != nil || len(id) != len(b.ClassID) {
+			return nil, fmt.Errorf("%w: bad class ID %q", ErrUnknownState, d.ClassID)
+		}
+		copy(b.ClassID[:], id)
+	}
+	if len(d.Meta) > 0 {
+		f := &preset.VSTPresetFile{}
+		if err := f.SetMetadata(d.Meta); err != nil {
+			return nil, err
+		}
+		b.MetaInfo = f.MetaInfo
+	}
+	for i, v := range d.Values {
+		b.State.Values[i] = state.Value{ID: v.ID, Value: v.Value}
+	}
+	return b, nil
+}
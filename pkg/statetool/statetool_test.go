@@ -0,0 +1,148 @@
+package statetool
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/preset"
+	"github.com/justyntemme/vst3go/pkg/framework/state"
+)
+
+var testClassID = [16]byte{0x12, 0x34, 0x56, 0x78, 0x9A, 0xBC, 0xDE, 0xF0, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}
+
+func testBlob() *Blob {
+	return &Blob{
+		ClassID: testClassID,
+		State: &state.Data{
+			Values: []state.Value{{ID: 1, Value: 0.25}, {ID: 7, Value: 1}},
+			Custom: []byte("custom"),
+		},
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	want := testBlob()
+	for _, format := range []Format{FormatState, FormatBase64, FormatVSTPreset, FormatJSON} {
+		data, err := want.Encode(format, nil)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		got, err := Parse(data)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if !reflect.DeepEqual(got.State, want.State) {
+			t.Errorf("format %d decoded %+v, want %+v", format, got.State, want.State)
+		}
+		if named := format == FormatVSTPreset || format == FormatJSON; named != (got.ClassID == testClassID) {
+			t.Errorf("format %d decoded class ID %X", format, got.ClassID)
+		}
+	}
+
+	for _, data := range []string{"", "hello", "VST3GO", "{not json", "bm90IHN0YXRl"} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%q parsed as state", data)
+		}
+	}
+	if _, err := (&Blob{State: &state.Data{}}).Encode(FormatVSTPreset, nil); !errors.Is(err, ErrNoClassID) {
+		t.Errorf("preset without class ID: error %v, want ErrNoClassID", err)
+	}
+}
+
+func TestDumpAndInject(t *testing.T) {
+	reg := param.NewRegistry()
+	reg.Add(param.New(1, "Gain").Range(-24, 24).Unit("dB").Build())
+
+	f := &preset.VSTPresetFile{ClassID: testClassID, Controller: []byte("ctrl")}
+	if err := f.SetMetadata(map[string]string{"Name": "Init"}); err != nil {
+		t.Fatal(err)
+	}
+	f.Component, _ = testBlob().Component()
+	b, err := Parse(f.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := b.Dump(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Meta["Name"] != "Init" || d.Values[0].Name != "Gain" || d.Values[0].Display == "" || d.Values[1].Name != "" {
+		t.Errorf("dump %+v", d)
+	}
+
+	src := &Blob{State: &state.Data{Values: []state.Value{{ID: 1, Value: 0.5}}}}
+	b.Inject(src)
+	data, err := b.Encode(FormatVSTPreset, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := preset.ReadVSTPresetFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	component, _ := src.Component()
+	if !bytes.Equal(out.Component, component) || string(out.Controller) != "ctrl" || out.ClassID != testClassID {
+		t.Errorf("injected preset %+v", out)
+	}
+	if meta, _ := out.Metadata(); meta["Name"] != "Init" {
+		t.Errorf("injection lost metadata %v", meta)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	bare := filepath.Join(dir, "state.bin")
+	data, _ := testBlob().Encode(FormatState, nil)
+	if err := os.WriteFile(bare, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := Run([]string{"dump", bare}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "2 values, 6 bytes custom state") {
+		t.Errorf("dump printed %q", out.String())
+	}
+
+	presetPath := filepath.Join(dir, "init.vstpreset")
+	if err := Run([]string{"convert", "-out", presetPath, bare}, &out); !errors.Is(err, ErrNoClassID) {
+		t.Errorf("convert without a class ID: error %v", err)
+	}
+	if err := Run([]string{"convert", "-out", presetPath, "-class", "12345678-9ABCDEF0-11223344-55667788", bare}, &out); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ReadFile(presetPath)
+	if err != nil || b.ClassID != testClassID {
+		t.Fatalf("converted preset %+v: %v", b, err)
+	}
+
+	jsonPath := filepath.Join(dir, "edit.json")
+	if err := Run([]string{"convert", "-out", jsonPath, presetPath}, &out); err != nil {
+		t.Fatal(err)
+	}
+	edited, _ := os.ReadFile(jsonPath)
+	edited = bytes.Replace(edited, []byte(`"value": 0.25`), []byte(`"value": 0.75`), 1)
+	if err := os.WriteFile(jsonPath, edited, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run([]string{"inject", "-state", jsonPath, presetPath}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ReadFile(presetPath); b.State.Values[0].Value != 0.75 {
+		t.Errorf("injected value %g, want 0.75", b.State.Values[0].Value)
+	}
+
+	for _, args := range [][]string{nil, {"rewind"}, {"dump"}, {"convert", bare}, {"inject", presetPath}} {
+		if err := Run(args, &out); err == nil {
+			t.Errorf("%v succeeded", args)
+		}
+	}
+}
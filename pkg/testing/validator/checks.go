@@ -0,0 +1,278 @@
+package validator
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// CheckParameters verifies parameter ranges, defaults and that values
+// survive conversion to and from plain values and text
+func CheckParameters(t testing.TB, p plugin.Plugin, opts Options) {
+	t.Helper()
+	opts = opts.withDefaults()
+	r := newRunner(t, p, opts, opts.SampleRates[0])
+
+	params := r.host.Parameters()
+	if params == nil {
+		return
+	}
+	bypass := 0
+	for _, prm := range params.All() {
+		name := describe(prm.ID, prm.Name)
+		if prm.Name == "" {
+			t.Errorf("%s has no name", name)
+		}
+		if prm.Max < prm.Min {
+			t.Errorf("%s has range %g to %g", name, prm.Min, prm.Max)
+		}
+		if prm.DefaultValue < 0 || prm.DefaultValue > 1 {
+			t.Errorf("%s has normalized default %g", name, prm.DefaultValue)
+		}
+		if prm.StepCount < 0 {
+			t.Errorf("%s has %d steps", name, prm.StepCount)
+		}
+		if prm.Flags&param.IsBypass != 0 {
+			bypass++
+		}
+
+		for _, v := range []float64{0, 0.25, 0.5, 0.75, 1} {
+			if back := prm.Normalize(prm.Denormalize(v)); prm.StepCount == 0 && math.Abs(back-v) > 1e-9 {
+				t.Errorf("%s: normalized %g converts back to %g", name, v, back)
+			}
+			if plain := prm.Denormalize(v); prm.StepCount > 0 && prm.Denormalize(prm.Normalize(plain)) != plain {
+				t.Errorf("%s: step value %g does not convert back to itself", name, plain)
+			}
+
+			text := prm.FormatValue(v)
+			parsed, err := prm.ParseValue(text)
+			if err != nil {
+				t.Errorf("%s: cannot parse its own text %q: %v", name, text, err)
+				continue
+			}
+			if again := prm.FormatValue(parsed); again != text {
+				t.Errorf("%s: %q parses to a value shown as %q", name, text, again)
+			}
+		}
+	}
+	if bypass > 1 {
+		t.Errorf("%d bypass parameters, at most one is allowed", bypass)
+	}
+}
+
+// CheckBlockSizes processes noise in blocks of random size, including single
+// samples and the maximum, at each sample rate
+func CheckBlockSizes(t testing.TB, p plugin.Plugin, opts Options) {
+	t.Helper()
+	opts = opts.withDefaults()
+	for _, rate := range opts.SampleRates {
+		r := newRunner(t, p, opts, rate)
+		for i := 0; i < opts.Blocks; i++ {
+			n := r.blockSize()
+			r.noise(n)
+			r.process(n, nil)
+		}
+		r.setActive(false)
+	}
+}
+
+// CheckActivation cycles processing on and off between blocks and, unless
+// the plugin is nondeterministic, checks that a reactivated processor renders
+// the same output for the same input
+func CheckActivation(t testing.TB, p plugin.Plugin, opts Options) {
+	t.Helper()
+	opts = opts.withDefaults()
+	r := newRunner(t, p, opts, opts.SampleRates[0])
+
+	for cycle := 0; cycle < 10; cycle++ {
+		r.setActive(true)
+		for i := 0; i < 1+r.rng.Intn(5); i++ {
+			n := r.blockSize()
+			r.noise(n)
+			r.process(n, nil)
+		}
+		r.setActive(false)
+		// Deactivating twice must be harmless
+		r.setActive(false)
+	}
+
+	if opts.Nondeterministic {
+		return
+	}
+	first := r.render(opts.Seed)
+	r.setActive(false)
+	second := r.render(opts.Seed)
+	for ch := range first {
+		for i := range first[ch] {
+			if first[ch][i] != second[ch][i] {
+				t.Fatalf("output differs after reactivation at channel %d sample %d: %g vs %g",
+					ch, i, first[ch][i], second[ch][i])
+			}
+		}
+	}
+}
+
+// render activates the processor and records its output for noise generated
+// from seed
+func (r *runner) render(seed int64) [][]float32 {
+	r.t.Helper()
+	r.setActive(true)
+	rng := rand.New(rand.NewSource(seed))
+	result := make([][]float32, len(r.output))
+	for i := 0; i < r.opts.Blocks/4+1; i++ {
+		n := 1 + rng.Intn(r.opts.MaxBlockSize)
+		r.fill(n, func(int, int) float32 { return float32(rng.Float64() - 0.5) })
+		r.process(n, nil)
+		for ch := range result {
+			result[ch] = append(result[ch], r.output[ch][:n]...)
+		}
+	}
+	return result
+}
+
+// CheckState saves the state of a processor with random settings, loads it
+// into a new instance and checks that both match; truncated state must be
+// rejected without a panic
+func CheckState(t testing.TB, p plugin.Plugin, opts Options) {
+	t.Helper()
+	opts = opts.withDefaults()
+	src := newRunner(t, p, opts, opts.SampleRates[0])
+	src.randomizeParameters()
+	for i := 0; i < 10; i++ {
+		n := src.blockSize()
+		src.noise(n)
+		src.process(n, nil)
+	}
+
+	data, err := saveState(src.host)
+	if err != nil {
+		t.Fatalf("save state: %v", err)
+	}
+
+	dst := newRunner(t, p, opts, opts.SampleRates[0])
+	if err := loadState(dst.host, data); err != nil {
+		t.Fatalf("load state: %v", err)
+	}
+	if params := src.host.Parameters(); params != nil {
+		for _, prm := range params.All() {
+			if got := dst.host.Parameters().Get(prm.ID); got == nil || got.GetValue() != prm.GetValue() {
+				t.Errorf("%s not restored", describe(prm.ID, prm.Name))
+			}
+		}
+	}
+
+	again, err := saveState(dst.host)
+	if err != nil {
+		t.Fatalf("save restored state: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Errorf("restored state saves differently: %d bytes vs %d", len(data), len(again))
+	}
+
+	// The restored processor must still run
+	for i := 0; i < 10; i++ {
+		n := dst.blockSize()
+		dst.noise(n)
+		dst.process(n, nil)
+	}
+
+	for _, size := range []int{0, len(data) / 2, len(data) - 1} {
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					t.Errorf("loading %d of %d state bytes panicked: %v", size, len(data), err)
+				}
+			}()
+			fresh := newRunner(t, p, opts, opts.SampleRates[0])
+			loadState(fresh.host, data[:size])
+		}()
+	}
+}
+
+// CheckParameterFuzz sends random parameter changes, including extremes and
+// several changes per block, at random sample offsets
+func CheckParameterFuzz(t testing.TB, p plugin.Plugin, opts Options) {
+	t.Helper()
+	opts = opts.withDefaults()
+	r := newRunner(t, p, opts, opts.SampleRates[0])
+
+	params := r.host.Parameters()
+	if params == nil || params.Count() == 0 {
+		return
+	}
+	all := params.All()
+
+	var changes []process.ParameterChange
+	for i := 0; i < opts.Blocks; i++ {
+		n := r.blockSize()
+		changes = changes[:0]
+		for c := r.rng.Intn(9); c > 0; c-- {
+			prm := all[r.rng.Intn(len(all))]
+			if prm.Flags&param.IsReadOnly != 0 {
+				continue
+			}
+			value := r.rng.Float64()
+			switch r.rng.Intn(4) {
+			case 0:
+				value = 0
+			case 1:
+				value = 1
+			}
+			changes = append(changes, process.ParameterChange{
+				ParamID:      prm.ID,
+				Value:        value,
+				SampleOffset: r.rng.Intn(n),
+			})
+		}
+		r.noise(n)
+		r.process(n, changes)
+	}
+}
+
+// CheckDenormals feeds subnormal input followed by silence and checks that
+// the output settles to zero or normal values instead of staying subnormal,
+// which costs a lot of CPU on most processors
+func CheckDenormals(t testing.TB, p plugin.Plugin, opts Options) {
+	t.Helper()
+	opts = opts.withDefaults()
+	r := newRunner(t, p, opts, opts.SampleRates[0])
+
+	const tiny = 1e-40 // Subnormal in float32
+	for i := 0; i < opts.Blocks/2; i++ {
+		n := r.blockSize()
+		r.fill(n, func(int, int) float32 {
+			if r.rng.Intn(2) == 0 {
+				return -tiny
+			}
+			return tiny
+		})
+		r.process(n, nil)
+	}
+
+	// A burst of noise then silence, so feedback paths decay
+	n := opts.MaxBlockSize
+	r.noise(n)
+	r.process(n, nil)
+	r.fill(n, func(int, int) float32 { return 0 })
+	silence := int(denormalSilenceSeconds * opts.SampleRates[0])
+	for done := 0; done < silence; done += n {
+		r.process(n, nil)
+	}
+
+	if opts.AllowDenormals {
+		return
+	}
+	for ch, out := range r.output {
+		for i, x := range out[:n] {
+			if abs := math.Abs(float64(x)); abs != 0 && abs < smallestNormal {
+				t.Fatalf("channel %d sample %d is still subnormal (%g) after %d s of silence",
+					ch, i, x, denormalSilenceSeconds)
+			}
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Package validator exercises a plugin the way Steinberg's validator does,
+// from go test: random block sizes, activation cycles, state round trips,
+// parameter fuzzing and denormal injection. Processing runs through
+// pkg/host, so the processor sees the same calls as under the VST3 wrapper.
+//
+//	func TestValidator(t *testing.T) {
+//		validator.Validate(t, &MyPlugin{}, validator.Options{})
+//	}
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/framework/state"
+	"github.com/justyntemme/vst3go/pkg/host"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// Validation defaults
+const (
+	DefaultMaxBlockSize = 1024
+	DefaultBlocks       = 200
+	DefaultMaxAmplitude = 64.0 // About +36 dBFS
+)
+
+// DefaultSampleRates are the rates the block size check runs at
+var DefaultSampleRates = []float64{44100, 48000, 96000}
+
+// Denormal check settings
+const (
+	smallestNormal         = 0x1p-126 // Smallest positive normal float32
+	denormalSilenceSeconds = 10       // Silence before output must leave the subnormal range
+)
+
+// Options tunes the checks. The zero value uses the defaults.
+type Options struct {
+	SampleRates  []float64 // Rates for the block size check
+	MaxBlockSize int       // Largest block; also passed to Initialize
+	Blocks       int       // Process calls per check
+	Seed         int64     // Random seed; zero uses 1
+
+	// MaxAmplitude is the largest output sample accepted, to catch runaway
+	// feedback
+	MaxAmplitude float64
+	// Nondeterministic skips the check that a reactivated processor renders
+	// the same output again, for plugins with free-running randomness
+	Nondeterministic bool
+	// AllowDenormals skips the check that output leaves the subnormal range
+	// once the input falls silent
+	AllowDenormals bool
+}
+
+// withDefaults fills in unset options
+func (o Options) withDefaults() Options {
+	if len(o.SampleRates) == 0 {
+		o.SampleRates = DefaultSampleRates
+	}
+	if o.MaxBlockSize == 0 {
+		o.MaxBlockSize = DefaultMaxBlockSize
+	}
+	if o.Blocks == 0 {
+		o.Blocks = DefaultBlocks
+	}
+	if o.Seed == 0 {
+		o.Seed = 1
+	}
+	if o.MaxAmplitude == 0 {
+		o.MaxAmplitude = DefaultMaxAmplitude
+	}
+	return o
+}
+
+// Validate runs every check as a subtest
+func Validate(t *testing.T, p plugin.Plugin, opts Options) {
+	t.Helper()
+	checks := []struct {
+		name  string
+		check func(testing.TB, plugin.Plugin, Options)
+	}{
+		{"Parameters", CheckParameters},
+		{"BlockSizes", CheckBlockSizes},
+		{"Activation", CheckActivation},
+		{"State", CheckState},
+		{"ParameterFuzz", CheckParameterFuzz},
+		{"Denormals", CheckDenormals},
+	}
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			c.check(t, p, opts)
+		})
+	}
+}
+
+// runner drives one processor instance with random input
+type runner struct {
+	t    testing.TB
+	opts Options
+	host *host.Host
+	rng  *rand.Rand
+
+	input  [][]float32
+	output [][]float32
+	block  int // Process calls so far, for failure messages
+}
+
+// newRunner creates and initializes a processor
+func newRunner(t testing.TB, p plugin.Plugin, opts Options, sampleRate float64) *runner {
+	t.Helper()
+	// Without flush-to-zero, so CheckDenormals sees what the plugin's own
+	// code produces on any FPU
+	h, err := host.New(p, host.Config{SampleRate: sampleRate, BlockSize: opts.MaxBlockSize, KeepDenormals: true})
+	if err != nil {
+		t.Fatalf("create processor: %v", err)
+	}
+	r := &runner{
+		t:      t,
+		opts:   opts,
+		host:   h,
+		rng:    rand.New(rand.NewSource(opts.Seed)),
+		input:  make([][]float32, h.NumInputs()),
+		output: make([][]float32, h.NumOutputs()),
+	}
+	for ch := range r.input {
+		r.input[ch] = make([]float32, opts.MaxBlockSize)
+	}
+	for ch := range r.output {
+		r.output[ch] = make([]float32, opts.MaxBlockSize)
+	}
+	return r
+}
+
+// blockSize returns a random block size, favoring the edge cases
+func (r *runner) blockSize() int {
+	switch r.rng.Intn(8) {
+	case 0:
+		return 1
+	case 1:
+		return r.opts.MaxBlockSize
+	default:
+		return 1 + r.rng.Intn(r.opts.MaxBlockSize)
+	}
+}
+
+// fill writes n samples into the input buffers
+func (r *runner) fill(n int, sample func(ch, i int) float32) {
+	for ch := range r.input {
+		for i := 0; i < n; i++ {
+			r.input[ch][i] = sample(ch, i)
+		}
+	}
+}
+
+// noise fills the input with white noise at about -6 dBFS
+func (r *runner) noise(n int) {
+	r.fill(n, func(int, int) float32 { return float32(r.rng.Float64() - 0.5) })
+}
+
+// process runs one block, turning panics into test failures, and checks
+// the output
+func (r *runner) process(n int, changes []process.ParameterChange) {
+	r.t.Helper()
+	r.block++
+
+	input := make([][]float32, len(r.input))
+	for ch := range input {
+		input[ch] = r.input[ch][:n]
+	}
+	output := make([][]float32, len(r.output))
+	for ch := range output {
+		output[ch] = r.output[ch][:n]
+	}
+
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				r.t.Fatalf("block %d (%d samples): processor panicked: %v", r.block, n, err)
+			}
+		}()
+		if err := r.host.ProcessBlock(input, output, changes); err != nil {
+			r.t.Fatalf("block %d: %v", r.block, err)
+		}
+	}()
+	r.checkOutput(n)
+}
+
+// checkOutput fails on invalid output samples
+func (r *runner) checkOutput(n int) {
+	r.t.Helper()
+	for ch, out := range r.output {
+		for i, x := range out[:n] {
+			abs := math.Abs(float64(x))
+			switch {
+			case math.IsNaN(abs) || math.IsInf(abs, 0):
+				r.t.Fatalf("block %d: channel %d sample %d is %g", r.block, ch, i, x)
+			case abs > r.opts.MaxAmplitude:
+				r.t.Fatalf("block %d: channel %d sample %d is %g, above the limit of %g",
+					r.block, ch, i, x, r.opts.MaxAmplitude)
+			}
+		}
+	}
+}
+
+// setActive switches processing, turning panics into test failures
+func (r *runner) setActive(active bool) {
+	r.t.Helper()
+	defer func() {
+		if err := recover(); err != nil {
+			r.t.Fatalf("SetActive(%v) panicked: %v", active, err)
+		}
+	}()
+	var err error
+	if active {
+		err = r.host.Activate()
+	} else {
+		err = r.host.Deactivate()
+	}
+	if err != nil {
+		r.t.Fatalf("SetActive(%v): %v", active, err)
+	}
+}
+
+// randomizeParameters sets every writable parameter to a random value
+func (r *runner) randomizeParameters() {
+	params := r.host.Parameters()
+	if params == nil {
+		return
+	}
+	for _, p := range params.All() {
+		if p.Flags&param.IsReadOnly == 0 {
+			p.SetValue(r.rng.Float64())
+		}
+	}
+}
+
+// saveState serializes the processor state like the VST3 wrapper
+func saveState(h *host.Host) ([]byte, error) {
+	m := state.NewManager(h.Parameters())
+	if sp, ok := h.Processor().(plugin.StatefulProcessor); ok {
+		m.SetCustomSaveFunc(sp.SaveCustomState)
+	}
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// loadState restores state saved by saveState
+func loadState(h *host.Host, data []byte) error {
+	m := state.NewManager(h.Parameters())
+	if sp, ok := h.Processor().(plugin.StatefulProcessor); ok {
+		m.SetCustomLoadFunc(sp.LoadCustomState)
+	}
+	return m.Load(bytes.NewReader(data))
+}
+
+// describe names a parameter in failure messages
+func describe(id uint32, name string) string {
+	return fmt.Sprintf("parameter %d (%s)", id, name)
+}
@@ -0,0 +1,168 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/justyntemme/vst3go/pkg/framework/bus"
+	"github.com/justyntemme/vst3go/pkg/framework/param"
+	fwplugin "github.com/justyntemme/vst3go/pkg/framework/plugin"
+	"github.com/justyntemme/vst3go/pkg/framework/process"
+	"github.com/justyntemme/vst3go/pkg/plugin"
+)
+
+// bug selects a defect in the test plugin
+type bug int
+
+const (
+	noBug         bug = iota
+	nanAtMax          // Outputs NaN with the parameter at its maximum
+	noReset           // Keeps its filter state across activation
+	stuckDenormal     // Feedback that stays in the subnormal range
+)
+
+// testPlugin is a stereo one-pole smoother with a gain parameter
+type testPlugin struct {
+	bug bug
+}
+
+func (p *testPlugin) GetInfo() fwplugin.Info {
+	return fwplugin.Info{ID: "com.vst3go.test.validator", Name: "Validator Test"}
+}
+
+func (p *testPlugin) CreateProcessor() plugin.Processor {
+	params := param.NewRegistry()
+	params.Add(
+		param.New(0, "Gain").Range(0, 2).Default(1).Unit("x").Build(),
+		param.New(1, "Bypass").Toggle().Bypass().Build(),
+	)
+	return &testProcessor{bug: p.bug, params: params, buses: bus.NewStereoConfiguration()}
+}
+
+type testProcessor struct {
+	bug    bug
+	params *param.Registry
+	buses  *bus.Configuration
+	state  [2]float32
+}
+
+func (p *testProcessor) Initialize(sampleRate float64, maxBlockSize int32) error { return nil }
+func (p *testProcessor) GetParameters() *param.Registry                          { return p.params }
+func (p *testProcessor) GetBuses() *bus.Configuration                            { return p.buses }
+func (p *testProcessor) GetLatencySamples() int32                                { return 0 }
+func (p *testProcessor) GetTailSamples() int32                                   { return 0 }
+
+func (p *testProcessor) SetActive(active bool) error {
+	if p.bug != noReset {
+		p.state = [2]float32{}
+	}
+	return nil
+}
+
+func (p *testProcessor) ProcessAudio(ctx *process.Context) {
+	gain := float32(ctx.ParamPlain(0))
+	if p.bug == nanAtMax && ctx.Param(0) == 1 {
+		gain = float32(math.NaN())
+	}
+	for ch := range ctx.Output {
+		y := p.state[ch]
+		for i, x := range ctx.Input[ch] {
+			abs := math.Abs(float64(y))
+			switch {
+			case p.bug == stuckDenormal && abs != 0 && abs < smallestNormal:
+				y += x // Stops decaying, like a feedback path whose gain rounds to one
+			case p.bug != stuckDenormal && abs < 1e-30:
+				y = 0.5 * x
+			default:
+				y = 0.5*y + 0.5*x
+			}
+			ctx.Output[ch][i] = gain * y
+		}
+		p.state[ch] = y
+	}
+}
+
+// recorder captures failures so tests can check that a defect is caught
+type recorder struct {
+	testing.TB
+	mu       sync.Mutex
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// run runs a check on its own goroutine so Fatalf can stop it
+func run(t *testing.T, check func(testing.TB, plugin.Plugin, Options), p plugin.Plugin, opts Options) []string {
+	r := &recorder{TB: t}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		check(r, p, opts)
+	}()
+	wg.Wait()
+	return r.failures
+}
+
+// fast keeps the checks short
+var fast = Options{SampleRates: []float64{48000}, MaxBlockSize: 256, Blocks: 50}
+
+func TestValidatePasses(t *testing.T) {
+	Validate(t, &testPlugin{}, fast)
+}
+
+func TestValidatorCatchesBugs(t *testing.T) {
+	tests := []struct {
+		name  string
+		bug   bug
+		check func(testing.TB, plugin.Plugin, Options)
+		want  string
+	}{
+		{"nan", nanAtMax, CheckParameterFuzz, "NaN"},
+		{"reset", noReset, CheckActivation, "differs after reactivation"},
+		{"denormal", stuckDenormal, CheckDenormals, "subnormal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := run(t, tt.check, &testPlugin{bug: tt.bug}, fast)
+			if len(failures) == 0 || !strings.Contains(failures[0], tt.want) {
+				t.Errorf("expected a failure mentioning %q, got %q", tt.want, failures)
+			}
+		})
+	}
+}
+
+func TestValidatorOptions(t *testing.T) {
+	opts := fast
+	opts.Nondeterministic = true
+	if failures := run(t, CheckActivation, &testPlugin{bug: noReset}, opts); len(failures) != 0 {
+		t.Errorf("Nondeterministic should skip the reactivation check: %q", failures)
+	}
+
+	opts = fast
+	opts.AllowDenormals = true
+	if failures := run(t, CheckDenormals, &testPlugin{bug: stuckDenormal}, opts); len(failures) != 0 {
+		t.Errorf("AllowDenormals should skip the subnormal check: %q", failures)
+	}
+
+	defaults := Options{}.withDefaults()
+	if defaults.MaxBlockSize != DefaultMaxBlockSize || len(defaults.SampleRates) != len(DefaultSampleRates) {
+		t.Errorf("defaults not applied: %+v", defaults)
+	}
+}
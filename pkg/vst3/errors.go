@@ -0,0 +1,93 @@
+package vst3
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Error is a bridge error that maps onto a VST3 tresult code
+type Error int
+
+const (
+	ErrNotImplemented  Error = -1
+	ErrInvalidArgument Error = -2
+	// ErrBusIndexOutOfRange is returned for a bus index the plugin does not have
+	ErrBusIndexOutOfRange Error = -3
+	// ErrInvalidState is returned when the host calls a method in the wrong
+	// lifecycle state, e.g. setProcessing(true) before setActive(true)
+	ErrInvalidState Error = -4
+	// ErrWrongThread is returned when a call overlaps one that must not run
+	// concurrently, e.g. two process calls at once
+	ErrWrongThread Error = -5
+)
+
+func (e Error) Error() string {
+	switch e {
+	case ErrNotImplemented:
+		return "not implemented"
+	case ErrInvalidArgument:
+		return "invalid argument"
+	case ErrBusIndexOutOfRange:
+		return "bus index out of range"
+	case ErrInvalidState:
+		return "invalid state"
+	case ErrWrongThread:
+		return "called from wrong thread"
+	default:
+		return "unknown error"
+	}
+}
+
+// Result returns the tresult code reported to the host for the error
+func (e Error) Result() int32 {
+	switch e {
+	case ErrNotImplemented:
+		return ResultNotImplemented
+	case ErrInvalidArgument, ErrBusIndexOutOfRange:
+		return ResultInvalidArgument
+	case ErrInvalidState:
+		return ResultNotInitialized
+	default:
+		return ResultFalse
+	}
+}
+
+// ResultFromError converts an error, possibly wrapped, to a tresult code.
+// Errors outside the taxonomy map to ResultFalse.
+func ResultFromError(err error) int32 {
+	if err == nil {
+		return ResultOK
+	}
+	var e Error
+	if errors.As(err, &e) {
+		return e.Result()
+	}
+	return ResultFalse
+}
+
+// ErrorHook receives every error returned to the host, with the name of the
+// bridge method that produced it
+type ErrorHook func(method string, err error)
+
+var errorHook atomic.Pointer[ErrorHook]
+
+// SetErrorHook installs a hook for logging bridge errors; nil removes it.
+// The hook may be called from the audio thread and must not block.
+func SetErrorHook(hook ErrorHook) {
+	if hook == nil {
+		errorHook.Store(nil)
+		return
+	}
+	errorHook.Store(&hook)
+}
+
+// ReportError passes a non-nil error to the error hook and returns its tresult code
+func ReportError(method string, err error) int32 {
+	if err == nil {
+		return ResultOK
+	}
+	if hook := errorHook.Load(); hook != nil {
+		(*hook)(method, err)
+	}
+	return ResultFromError(err)
+}
@@ -0,0 +1,49 @@
+package vst3
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestResultFromError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int32
+	}{
+		{nil, ResultOK},
+		{ErrNotImplemented, ResultNotImplemented},
+		{ErrBusIndexOutOfRange, ResultInvalidArgument},
+		{fmt.Errorf("bus 3: %w", ErrBusIndexOutOfRange), ResultInvalidArgument},
+		{ErrInvalidState, ResultNotInitialized},
+		{ErrWrongThread, ResultFalse},
+		{errors.New("plain"), ResultFalse},
+	}
+
+	for _, tt := range tests {
+		if got := ResultFromError(tt.err); got != tt.want {
+			t.Errorf("ResultFromError(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrorHook(t *testing.T) {
+	var gotMethod string
+	var gotErr error
+	SetErrorHook(func(method string, err error) {
+		gotMethod, gotErr = method, err
+	})
+	defer SetErrorHook(nil)
+
+	if ReportError("GoAudioProcess", nil) != ResultOK || gotErr != nil {
+		t.Error("Nil errors should not reach the hook")
+	}
+
+	err := fmt.Errorf("setProcessing: %w", ErrInvalidState)
+	if code := ReportError("GoAudioSetProcessing", err); code != ResultNotInitialized {
+		t.Errorf("Expected ResultNotInitialized, got %d", code)
+	}
+	if gotMethod != "GoAudioSetProcessing" || !errors.Is(gotErr, ErrInvalidState) {
+		t.Errorf("Hook got %q, %v", gotMethod, gotErr)
+	}
+}
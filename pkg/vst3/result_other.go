@@ -0,0 +1,13 @@
+//go:build !windows
+
+package vst3
+
+// tresult codes on platforms without COM compatibility
+const (
+	ResultNoInterface     = -1
+	ResultInvalidArgument = 2
+	ResultNotImplemented  = 3
+	ResultInternalError   = 4
+	ResultNotInitialized  = 5
+	ResultOutOfMemory     = 6
+)
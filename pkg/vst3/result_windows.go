@@ -0,0 +1,13 @@
+//go:build windows
+
+package vst3
+
+// tresult codes on Windows, which follow COM HRESULT values
+const (
+	ResultNoInterface     = -2147467262 // 0x80004002
+	ResultInvalidArgument = -2147024809 // 0x80070057
+	ResultNotImplemented  = -2147467263 // 0x80004001
+	ResultInternalError   = -2147467259 // 0x80004005
+	ResultNotInitialized  = -2147418113 // 0x8000FFFF
+	ResultOutOfMemory     = -2147024882 // 0x8007000E
+)
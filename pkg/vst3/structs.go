@@ -62,6 +62,25 @@ const (
 	BusTypeAux  = C.Steinberg_Vst_BusTypes_kAux
 )
 
+// Constants for symbolic sample sizes
+const (
+	SampleSize32 = C.Steinberg_Vst_SymbolicSampleSizes_kSample32
+	SampleSize64 = C.Steinberg_Vst_SymbolicSampleSizes_kSample64
+)
+
+// Constants for component restart flags
+const (
+	RestartParamValuesChanged = C.Steinberg_Vst_RestartFlags_kParamValuesChanged
+	RestartLatencyChanged     = C.Steinberg_Vst_RestartFlags_kLatencyChanged
+)
+
+// Constants for context menu item flags
+const (
+	ContextMenuItemIsSeparator = C.Steinberg_Vst_IContextMenuItem_Flags_kIsSeparator
+	ContextMenuItemIsDisabled  = C.Steinberg_Vst_IContextMenuItem_Flags_kIsDisabled
+	ContextMenuItemIsChecked   = C.Steinberg_Vst_IContextMenuItem_Flags_kIsChecked
+)
+
 // Constants for parameter flags
 const (
 	ParameterIsReadOnly   = C.Steinberg_Vst_ParameterInfo_ParameterFlags_kIsReadOnly
@@ -40,23 +40,6 @@ const (
 	CategoryAudioEffect = "Audio Module Class"
 )
 
-// Error codes
-type Error int
-
-const (
-	ErrNotImplemented  Error = -1
-	ErrInvalidArgument Error = -2
-)
-
-func (e Error) Error() string {
-	switch e {
-	case ErrNotImplemented:
-		return "not implemented"
-	default:
-		return "unknown error"
-	}
-}
-
 // Helper to convert Go interface ID to C TUID
 func ToTUID(iid [16]byte) unsafe.Pointer {
 	return unsafe.Pointer(&iid[0])